@@ -0,0 +1,162 @@
+package pgdoctor
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/fresha/pgdoctor/check"
+)
+
+// baselineVersion is the format version written to baseline files.
+const baselineVersion = 1
+
+// Baseline records findings that have been accepted as known debt, so later
+// runs report only regressions, the way linters treat legacy code. A
+// finding is matched by its fingerprint (check, finding, and object) and
+// stays suppressed while its severity does not rise above the recorded one
+// and, for findings with fixes, no object appears that the baseline did not
+// list.
+type Baseline struct {
+	Version   int             `json:"version"`
+	CreatedAt time.Time       `json:"created_at"`
+	Findings  []BaselineEntry `json:"findings"`
+
+	byFingerprint map[string]BaselineEntry
+	suppressed    int
+}
+
+// BaselineEntry is one accepted finding.
+type BaselineEntry struct {
+	CheckID     string `json:"check_id"`
+	FindingID   string `json:"finding_id"`
+	Object      string `json:"object,omitempty"`
+	Fingerprint string `json:"fingerprint"`
+	Severity    string `json:"severity"`
+	// Objects are the objects the finding's fixes covered, for findings
+	// that list several tables or indexes under one fingerprint.
+	Objects []string `json:"objects,omitempty"`
+}
+
+// NewBaseline records every WARN and FAIL finding in reports.
+func NewBaseline(reports []*check.Report, now time.Time) *Baseline {
+	b := &Baseline{Version: baselineVersion, CreatedAt: now.UTC(), Findings: []BaselineEntry{}}
+	for _, r := range reports {
+		for _, f := range r.Results {
+			if f.Severity < check.SeverityWarn {
+				continue
+			}
+			b.Findings = append(b.Findings, BaselineEntry{
+				CheckID:     r.CheckID,
+				FindingID:   f.ID,
+				Object:      f.Object,
+				Fingerprint: f.Fingerprint,
+				Severity:    f.Severity.String(),
+				Objects:     fixObjects(f),
+			})
+		}
+	}
+	b.index()
+	return b
+}
+
+// ReadBaseline reads a baseline written by Baseline.Write.
+func ReadBaseline(r io.Reader) (*Baseline, error) {
+	var b Baseline
+	if err := json.NewDecoder(r).Decode(&b); err != nil {
+		return nil, fmt.Errorf("decoding baseline: %w", err)
+	}
+	if b.Version != baselineVersion {
+		return nil, fmt.Errorf("unsupported baseline version %d", b.Version)
+	}
+	for _, e := range b.Findings {
+		if _, err := check.ParseSeverity(e.Severity); err != nil {
+			return nil, fmt.Errorf("baseline entry %s/%s: %w", e.CheckID, e.FindingID, err)
+		}
+	}
+	b.index()
+	return &b, nil
+}
+
+// Write writes the baseline as indented JSON.
+func (b *Baseline) Write(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(b); err != nil {
+		return fmt.Errorf("encoding baseline: %w", err)
+	}
+	return nil
+}
+
+// Suppressed returns how many findings Apply has suppressed so far.
+func (b *Baseline) Suppressed() int {
+	return b.suppressed
+}
+
+func (b *Baseline) index() {
+	b.byFingerprint = make(map[string]BaselineEntry, len(b.Findings))
+	for _, e := range b.Findings {
+		b.byFingerprint[e.Fingerprint] = e
+	}
+}
+
+// Apply lowers the findings of report that the baseline accepts to OK,
+// noting the accepted severity in their details, and recomputes the
+// report's severity.
+func (b *Baseline) Apply(report *check.Report) {
+	changed := false
+	for i := range report.Results {
+		f := &report.Results[i]
+		if f.Severity < check.SeverityWarn {
+			continue
+		}
+		entry, ok := b.byFingerprint[f.Fingerprint]
+		if !ok {
+			continue
+		}
+		accepted, _ := check.ParseSeverity(entry.Severity)
+		if f.Severity > accepted {
+			continue
+		}
+		if !isSubset(fixObjects(*f), entry.Objects) {
+			continue
+		}
+
+		f.Details = strings.TrimSpace(f.Details + fmt.Sprintf("\nAccepted in baseline at %s on %s", strings.ToUpper(entry.Severity), b.CreatedAt.Format(time.DateOnly)))
+		f.Severity = check.SeverityOK
+		f.Fixes = nil
+		b.suppressed++
+		changed = true
+	}
+
+	if changed {
+		report.Severity = check.SeverityOK
+		for _, f := range report.Results {
+			report.Severity = max(report.Severity, f.Severity)
+		}
+	}
+}
+
+// fixObjects returns the sorted, distinct objects of a finding's fixes.
+func fixObjects(f check.Finding) []string {
+	var objects []string
+	for _, fix := range f.Fixes {
+		if fix.Object != "" && !slices.Contains(objects, fix.Object) {
+			objects = append(objects, fix.Object)
+		}
+	}
+	slices.Sort(objects)
+	return objects
+}
+
+func isSubset(items, of []string) bool {
+	for _, item := range items {
+		if !slices.Contains(of, item) {
+			return false
+		}
+	}
+	return true
+}
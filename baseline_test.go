@@ -0,0 +1,83 @@
+package pgdoctor
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/fresha/pgdoctor/check"
+)
+
+// unusedIndexReport reports the given indexes as unused at severity, with a
+// drop fix per index, and a passing sibling finding.
+func unusedIndexReport(severity check.Severity, indexes ...string) *check.Report {
+	r := check.NewReport(check.Metadata{CheckID: "index-usage", Name: "Index Usage", Category: check.CategoryIndexes})
+	f := check.Finding{ID: "unused-indexes", Name: "Unused Indexes", Severity: severity, Details: "unused"}
+	for _, idx := range indexes {
+		f.Fixes = append(f.Fixes, check.Fix{Object: idx, SQL: "DROP INDEX CONCURRENTLY " + idx, Lock: check.LockOnline})
+	}
+	r.AddFinding(f)
+	r.AddFinding(check.Finding{ID: "invalid-indexes", Name: "Invalid Indexes", Severity: check.SeverityOK})
+	return r
+}
+
+func TestBaseline_Apply(t *testing.T) {
+	t.Parallel()
+
+	created := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	recorded := NewBaseline([]*check.Report{unusedIndexReport(check.SeverityWarn, "public.b_idx", "public.a_idx")}, created)
+	require.Len(t, recorded.Findings, 1)
+	assert.Equal(t, []string{"public.a_idx", "public.b_idx"}, recorded.Findings[0].Objects)
+
+	tests := []struct {
+		name       string
+		report     *check.Report
+		suppressed bool
+	}{
+		{name: "same finding", report: unusedIndexReport(check.SeverityWarn, "public.a_idx", "public.b_idx"), suppressed: true},
+		{name: "fewer objects", report: unusedIndexReport(check.SeverityWarn, "public.a_idx"), suppressed: true},
+		{name: "new object", report: unusedIndexReport(check.SeverityWarn, "public.a_idx", "public.c_idx")},
+		{name: "worse severity", report: unusedIndexReport(check.SeverityFail, "public.a_idx")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var buf bytes.Buffer
+			require.NoError(t, recorded.Write(&buf))
+			b, err := ReadBaseline(&buf)
+			require.NoError(t, err)
+
+			before := tt.report.Results[0].Severity
+			b.Apply(tt.report)
+
+			f := tt.report.Results[0]
+			if !tt.suppressed {
+				assert.Equal(t, before, f.Severity)
+				assert.Equal(t, before, tt.report.Severity)
+				assert.Equal(t, 0, b.Suppressed())
+				return
+			}
+			assert.Equal(t, check.SeverityOK, f.Severity)
+			assert.Equal(t, check.SeverityOK, tt.report.Severity)
+			assert.Empty(t, f.Fixes)
+			assert.Contains(t, f.Details, "Accepted in baseline at WARN on 2026-10-01")
+			assert.Equal(t, 1, b.Suppressed())
+		})
+	}
+}
+
+func TestReadBaseline_Invalid(t *testing.T) {
+	t.Parallel()
+
+	_, err := ReadBaseline(strings.NewReader(`{"version": 2, "findings": []}`))
+	assert.ErrorContains(t, err, "unsupported baseline version 2")
+
+	_, err = ReadBaseline(strings.NewReader(`{"version": 1, "findings": [{"check_id": "a", "finding_id": "b", "severity": "bad"}]}`))
+	assert.ErrorContains(t, err, "a/b")
+}
@@ -3,6 +3,7 @@ package check
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"github.com/fresha/pgdoctor/db"
@@ -37,6 +38,42 @@ func (s Severity) String() string {
 	}
 }
 
+// ParseSeverity parses the string form of a severity, as written in JSON
+// reports.
+func ParseSeverity(s string) (Severity, error) {
+	for _, sev := range []Severity{SeverityOK, SeverityWarn, SeverityFail, SeveritySkip} {
+		if s == sev.String() {
+			return sev, nil
+		}
+	}
+	return SeverityOK, fmt.Errorf("unknown severity %q", s)
+}
+
+// Confidence says how far a finding can be trusted without verification.
+// Findings read directly from catalogs and counters are high confidence;
+// those built on estimates or on matching SQL text are lower, and users
+// should confirm them before acting.
+type Confidence int
+
+const (
+	ConfidenceHigh   Confidence = iota // Read directly from catalogs, settings, or counters
+	ConfidenceMedium                   // Derived from planner statistics or size estimates
+	ConfidenceLow                      // Heuristic, such as pattern matching on query text
+)
+
+func (c Confidence) String() string {
+	switch c {
+	case ConfidenceHigh:
+		return "high"
+	case ConfidenceMedium:
+		return "medium"
+	case ConfidenceLow:
+		return "low"
+	default:
+		return "unknown"
+	}
+}
+
 type Category string
 
 const (
@@ -45,8 +82,88 @@ const (
 	CategoryVacuum      Category = "vacuum"
 	CategorySchema      Category = "schema"
 	CategoryPerformance Category = "performance"
+	CategorySecurity    Category = "security"
+)
+
+// StatsResetFinding is the statistics-freshness finding that reports when
+// cumulative statistics were last reset. Findings built on counters since
+// that reset point readers to it.
+const StatsResetFinding = "statistics-freshness/stats-reset-times"
+
+// RuntimeClass estimates how expensive a check's queries are on large databases.
+// Operators can cap the runtime class on a first production run to exclude
+// checks that scan catalogs per column or per index.
+type RuntimeClass int
+
+const (
+	RuntimeFast   RuntimeClass = iota // Reads settings or a handful of stats rows
+	RuntimeMedium                     // Scans per-table statistics or catalog rows
+	RuntimeHeavy                      // Joins catalogs per column/index or reads pg_stats/pg_stat_statements
 )
 
+func (r RuntimeClass) String() string {
+	switch r {
+	case RuntimeFast:
+		return "fast"
+	case RuntimeMedium:
+		return "medium"
+	case RuntimeHeavy:
+		return "heavy"
+	default:
+		return "unknown"
+	}
+}
+
+// ParseRuntimeClass converts a runtime class name (fast, medium, heavy) to a RuntimeClass.
+func ParseRuntimeClass(s string) (RuntimeClass, error) {
+	switch s {
+	case "fast":
+		return RuntimeFast, nil
+	case "medium":
+		return RuntimeMedium, nil
+	case "heavy":
+		return RuntimeHeavy, nil
+	default:
+		return 0, fmt.Errorf("unknown runtime class %q (expected fast, medium, or heavy)", s)
+	}
+}
+
+// Priority orders check execution so the most urgent verdicts stream first.
+type Priority int
+
+const (
+	PriorityDeferred Priority = iota - 1 // Runs after everything else
+	PriorityNormal                       // Default
+	PriorityCritical                     // Wraparound, replication, and connection verdicts; runs first
+)
+
+func (p Priority) String() string {
+	switch p {
+	case PriorityDeferred:
+		return "deferred"
+	case PriorityNormal:
+		return "normal"
+	case PriorityCritical:
+		return "critical"
+	default:
+		return "unknown"
+	}
+}
+
+// ParsePriority converts a priority name (critical, normal, deferred) to a Priority.
+func ParsePriority(s string) (Priority, error) {
+	switch s {
+	case "critical":
+		return PriorityCritical, nil
+	case "normal":
+		return PriorityNormal, nil
+	case "deferred":
+		return PriorityDeferred, nil
+	default:
+		return 0, fmt.Errorf("unknown priority %q (expected critical, normal, or deferred)", s)
+	}
+}
+
 type Checker interface {
 	Metadata() Metadata
 	Check(context.Context) (*Report, error)
@@ -71,6 +188,29 @@ type Metadata struct {
 	Description string
 	Readme      string
 	SQL         string // SQL query used by this check
+
+	// RuntimeClass is the estimated cost of the check's queries on large catalogs.
+	RuntimeClass RuntimeClass
+	// ProductionSafe marks checks whose queries are read-only, take no locks
+	// beyond AccessShare, and are bounded by statement_timeout.
+	ProductionSafe bool
+	// Priority moves the check earlier or later in a run. Heavy checks left at
+	// PriorityNormal are treated as deferred.
+	Priority Priority
+	// Privileges lists what the check needs beyond a login role to see
+	// everything it inspects. pgdoctor grants turns it into GRANT statements.
+	Privileges []Privilege
+
+	// Findings lists every finding ID the check can emit. gendocs renders it
+	// as the Findings table on the check's documentation page.
+	Findings []FindingSpec
+}
+
+// FindingSpec documents a single finding a check can report.
+type FindingSpec struct {
+	ID          string
+	Description string
+	Thresholds  string // Default WARN/FAIL thresholds; empty for informational findings
 }
 
 // Report holds check-level metadata and all subcheck findings for a single check.
@@ -91,6 +231,12 @@ func NewReport(metadata Metadata) *Report {
 }
 
 func (r *Report) AddFinding(res Finding) {
+	if res.DocsURL == "" {
+		res.DocsURL = DocsURL(r.CheckID, res.ID)
+	}
+	if res.Fingerprint == "" {
+		res.Fingerprint = Fingerprint(r.CheckID, res.ID, res.Object)
+	}
 	r.Results = append(r.Results, res)
 
 	if res.Severity > r.Severity {
@@ -109,12 +255,46 @@ type Finding struct {
 	Name     string
 	Severity Severity
 	Details  string
+	// Impact states in one plain sentence what goes wrong if the finding is
+	// left alone, naming the worst object (e.g. "public.orders.id has used
+	// 92% of the integer range; inserts fail once it runs out"). Reports open
+	// with the top risks' impacts; findings without one fall back to Details.
+	Impact string
 	// Table contains optional structured tabular data.
 	// If set, the CLI will render this as a formatted table.
 	Table *Table
 	// Debug contains debug information like SQL queries, timing info, etc.
 	// Only shown when --debug flag is used.
 	Debug string
+	// DocsURL links to the explanation and remediation guidance for this finding.
+	// AddFinding fills it in from the check and finding IDs when left empty;
+	// contrib checks documented elsewhere should set it explicitly.
+	DocsURL string
+	// Object identifies the database object a finding is about (e.g. "public.orders")
+	// when a check reports one finding per object. Leave empty for findings that
+	// cover the whole check.
+	Object string
+	// Fingerprint identifies this finding across runs. AddFinding computes it
+	// from the check ID, finding ID, and Object when left empty.
+	Fingerprint string
+	// Confidence defaults to high; checks lower it for findings based on
+	// estimates or heuristics.
+	Confidence Confidence
+	// Fixes lists statements that resolve the finding, for pgdoctor fix.
+	Fixes []Fix
+}
+
+// DocsBaseURL is the published documentation site generated by internal/gendocs.
+const DocsBaseURL = "https://fresha.github.io/pgdoctor/"
+
+// DocsURL returns the documentation link for a check, or for one of its findings
+// when findingID differs from checkID. The site resolves #check-id/finding-id to
+// the finding's section of the check README.
+func DocsURL(checkID, findingID string) string {
+	if findingID == "" || findingID == checkID {
+		return DocsBaseURL + "#" + checkID
+	}
+	return DocsBaseURL + "#" + checkID + "/" + findingID
 }
 
 type Table struct {
@@ -168,6 +348,40 @@ type InstanceMetadata struct {
 	DeletionProtection      bool
 	BackupRetentionDays     int
 	AutoMinorVersionUpgrade bool
+
+	// Recent host-level metrics from the provider's monitoring service (nil
+	// when not collected)
+	HostMetrics *HostMetrics
+}
+
+// HostMetrics are host-level measurements of an instance over a recent
+// window, so checks can tell database symptoms caused by pressure on the
+// host from those caused by the workload.
+type HostMetrics struct {
+	Window              time.Duration // Period the values cover, ending when they were read
+	CPUPercent          float64       // Average CPU utilization
+	MaxCPUPercent       float64       // Peak CPU utilization
+	FreeableMemoryBytes int64         // Lowest freeable memory
+	ReadIOPS            float64       // Average read operations per second
+	WriteIOPS           float64       // Average write operations per second
+	ReplicaLagSeconds   float64       // Peak lag behind the source; 0 unless the instance is a replica
+}
+
+// HostCPUBusyPercent is the average CPU utilization above which checks treat
+// the host as CPU bound.
+const HostCPUBusyPercent = 80
+
+// Summary describes the metrics in one line, e.g. "CPU 45% avg (92% peak),
+// 3.2GiB freeable memory at lowest, 1.2K read / 3.4K write IOPS over the last
+// 1h".
+func (m *HostMetrics) Summary() string {
+	s := fmt.Sprintf("CPU %.0f%% avg (%.0f%% peak), %s freeable memory at lowest, %s read / %s write IOPS",
+		m.CPUPercent, m.MaxCPUPercent, FormatBytes(m.FreeableMemoryBytes),
+		FormatNumber(int64(m.ReadIOPS)), FormatNumber(int64(m.WriteIOPS)))
+	if m.ReplicaLagSeconds > 0 {
+		s += fmt.Sprintf(", replica lag %s peak", FormatDurationMs(m.ReplicaLagSeconds*1000))
+	}
+	return s + " over the last " + FormatDurationSec(int64(m.Window.Seconds()))
 }
 
 type instanceMetadataKey struct{}
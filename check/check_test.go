@@ -0,0 +1,123 @@
+package check
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDocsURL(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "https://fresha.github.io/pgdoctor/#sequence-health", DocsURL("sequence-health", ""))
+	assert.Equal(t, "https://fresha.github.io/pgdoctor/#sequence-health", DocsURL("sequence-health", "sequence-health"))
+	assert.Equal(t, "https://fresha.github.io/pgdoctor/#sequence-health/near-exhaustion", DocsURL("sequence-health", "near-exhaustion"))
+}
+
+func TestAddFinding_DocsURL(t *testing.T) {
+	t.Parallel()
+
+	report := NewReport(Metadata{CheckID: "sequence-health"})
+	report.AddFinding(Finding{ID: "near-exhaustion", Severity: SeverityFail})
+	report.AddFinding(Finding{ID: "custom", Severity: SeverityWarn, DocsURL: "https://wiki.example.com/runbook"})
+
+	assert.Equal(t, DocsURL("sequence-health", "near-exhaustion"), report.Results[0].DocsURL)
+	assert.Equal(t, "https://wiki.example.com/runbook", report.Results[1].DocsURL)
+	assert.Equal(t, SeverityFail, report.Severity)
+}
+
+func TestFingerprint(t *testing.T) {
+	t.Parallel()
+
+	fp := Fingerprint("sequence-health", "near-exhaustion", "")
+	assert.Len(t, fp, 16)
+	assert.Equal(t, fp, Fingerprint("sequence-health", "near-exhaustion", ""))
+	assert.NotEqual(t, fp, Fingerprint("sequence-health", "near-exhaustion", "public.orders_id_seq"))
+	assert.NotEqual(t, fp, Fingerprint("sequence-health", "type-mismatch", ""))
+	// Field boundaries are unambiguous.
+	assert.NotEqual(t, Fingerprint("a", "bc", ""), Fingerprint("ab", "c", ""))
+}
+
+func TestAddFinding_Fingerprint(t *testing.T) {
+	t.Parallel()
+
+	r := NewReport(Metadata{CheckID: "table-bloat"})
+	r.AddFinding(Finding{ID: "high-dead-tuples", Details: "3 tables"})
+	r.AddFinding(Finding{ID: "high-dead-tuples", Details: "5 tables", Object: "public.orders"})
+	r.AddFinding(Finding{ID: "stale-vacuum", Fingerprint: "custom"})
+
+	assert.Equal(t, Fingerprint("table-bloat", "high-dead-tuples", ""), r.Results[0].Fingerprint)
+	assert.Equal(t, Fingerprint("table-bloat", "high-dead-tuples", "public.orders"), r.Results[1].Fingerprint)
+	assert.Equal(t, "custom", r.Results[2].Fingerprint)
+}
+
+func TestConfigFloat(t *testing.T) {
+	t.Parallel()
+
+	cfg := Config{"replication-lag": {"physical_warn_seconds": "0.5", "logical_warn_seconds": "-1"}}
+
+	assert.InDelta(t, 0.5, cfg.Float("replication-lag", "physical_warn_seconds", 0.25), 1e-9)
+	assert.InDelta(t, 20.0, cfg.Float("replication-lag", "logical_warn_seconds", 20), 1e-9)
+	assert.InDelta(t, 75.0, Config(nil).Float("sequence-health", "near_exhaustion_warn_percent", 75), 1e-9)
+}
+
+func TestConfigString(t *testing.T) {
+	t.Parallel()
+
+	cfg := Config{"timezone": {"expected_timezone": "Europe/London", "empty": ""}}
+	assert.Equal(t, "Europe/London", cfg.String("timezone", "expected_timezone", "UTC"))
+	assert.Equal(t, "UTC", cfg.String("timezone", "empty", "UTC"))
+	assert.Equal(t, "UTC", cfg.String("other", "expected_timezone", "UTC"))
+	assert.Equal(t, "UTC", Config(nil).String("timezone", "expected_timezone", "UTC"))
+}
+
+func TestObserve(t *testing.T) {
+	t.Parallel()
+
+	// No observer attached: Observe is a no-op.
+	Observe(context.Background(), Observation{CheckID: "x"})
+
+	var got []Observation
+	ctx := ContextWithObserver(context.Background(), func(o Observation) { got = append(got, o) })
+	Observe(ctx, Observation{CheckID: "replication-lag", Key: "physical_warn_seconds", Value: 0.3})
+
+	assert.Equal(t, []Observation{{CheckID: "replication-lag", Key: "physical_warn_seconds", Value: 0.3}}, got)
+}
+
+func TestContextWithObserver_Chains(t *testing.T) {
+	t.Parallel()
+
+	var outer, inner int
+	ctx := ContextWithObserver(context.Background(), func(Observation) { outer++ })
+	ctx = ContextWithObserver(ctx, func(Observation) { inner++ })
+	Observe(ctx, Observation{CheckID: "x"})
+
+	assert.Equal(t, 1, outer)
+	assert.Equal(t, 1, inner)
+}
+
+func TestParseSeverity(t *testing.T) {
+	t.Parallel()
+
+	for _, sev := range []Severity{SeverityOK, SeverityWarn, SeverityFail, SeveritySkip} {
+		got, err := ParseSeverity(sev.String())
+		require.NoError(t, err)
+		assert.Equal(t, sev, got)
+	}
+
+	_, err := ParseSeverity("critical")
+	assert.Error(t, err)
+}
+
+func TestHostMetricsSummary(t *testing.T) {
+	t.Parallel()
+
+	m := &HostMetrics{Window: time.Hour, CPUPercent: 45.2, MaxCPUPercent: 92, FreeableMemoryBytes: 3 << 30, ReadIOPS: 1200, WriteIOPS: 3400}
+	assert.Equal(t, "CPU 45% avg (92% peak), 3.0GiB freeable memory at lowest, 1.2K read / 3.4K write IOPS over the last 1h", m.Summary())
+
+	m.ReplicaLagSeconds = 12
+	assert.Equal(t, "CPU 45% avg (92% peak), 3.0GiB freeable memory at lowest, 1.2K read / 3.4K write IOPS, replica lag 12.0s peak over the last 1h", m.Summary())
+}
@@ -0,0 +1,116 @@
+// Package checktest compares check reports with golden files, so a check's
+// tests can state the rows its mock queryer returns and keep the expected
+// report in testdata instead of in assertions. Run the tests with -update to
+// write the golden files; threshold and wording changes then show up as
+// diffs of the files in review.
+//
+//	func TestIndexUsage_Unused(t *testing.T) {
+//		q := &mockQueryer{rows: []db.IndexUsageRow{...}}
+//		checktest.Golden(t, "unused", indexusage.New(q))
+//	}
+//
+// Custom checks built on package check can use it the same way.
+package checktest
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/fresha/pgdoctor/check"
+	"github.com/fresha/pgdoctor/internal/report"
+)
+
+var update = flag.Bool("update", false, "rewrite golden files with the reports checks produce")
+
+// Dir is the directory golden files are read from and written to, relative
+// to the package under test.
+const Dir = "testdata"
+
+// Golden runs c and compares its report with the golden file
+// testdata/<name>.golden.json, failing the test on any difference. With
+// -update it writes the file instead.
+func Golden(t testing.TB, name string, c check.Checker) {
+	t.Helper()
+
+	r, err := c.Check(t.Context())
+	require.NoError(t, err)
+	GoldenReport(t, name, r)
+}
+
+// GoldenReport compares r with the golden file testdata/<name>.golden.json.
+// Use it for reports built without running a checker, or from a context
+// carrying instance metadata.
+func GoldenReport(t testing.TB, name string, r *check.Report) {
+	t.Helper()
+
+	got, err := Marshal(r)
+	require.NoError(t, err)
+
+	path := filepath.Join(Dir, name+".golden.json")
+	if *update {
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, got, 0o644))
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		t.Fatalf("golden file %s does not exist; run go test -update to create it", path)
+	}
+	require.NoError(t, err)
+	assert.JSONEq(t, string(want), string(got), "report differs from %s; run go test -update if the change is intended", path)
+}
+
+// golden is the golden file form of a report: the JSON report without the
+// pgdoctor version, which changes with every build. The outer field hides
+// the embedded one.
+type golden struct {
+	report.Report
+	PgdoctorVersion string `json:"pgdoctor_version,omitempty"`
+}
+
+// Marshal renders r as it is stored in a golden file: the --output json
+// form of the report, indented, without the pgdoctor version.
+func Marshal(r *check.Report) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false) // keep <, > and & in details readable
+	if err := enc.Encode(golden{Report: report.FromChecks([]*check.Report{r})[0]}); err != nil {
+		return nil, fmt.Errorf("encoding report: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// Finding returns the finding of r with id, failing the test when r has none.
+func Finding(t testing.TB, r *check.Report, id string) check.Finding {
+	t.Helper()
+
+	for _, f := range r.Results {
+		if f.ID == id {
+			return f
+		}
+	}
+	t.Fatalf("finding %q not found in %s", id, r.CheckID)
+	return check.Finding{}
+}
+
+// NoFinding fails the test when r has a finding with id.
+func NoFinding(t testing.TB, r *check.Report, id string) {
+	t.Helper()
+
+	for _, f := range r.Results {
+		if f.ID == id {
+			t.Errorf("unexpected finding %q in %s", id, r.CheckID)
+			return
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package checktest
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/fresha/pgdoctor/check"
+)
+
+type fakeChecker struct {
+	severity check.Severity
+}
+
+func (f fakeChecker) Metadata() check.Metadata {
+	return check.Metadata{CheckID: "fake", Name: "Fake", Category: check.CategorySchema}
+}
+
+func (f fakeChecker) Check(context.Context) (*check.Report, error) {
+	r := check.NewReport(f.Metadata())
+	r.AddFinding(check.Finding{
+		ID:       "fake",
+		Name:     "Fake",
+		Severity: f.severity,
+		Details:  "rows < 10 & growing",
+	})
+	return r, nil
+}
+
+func TestMarshal(t *testing.T) {
+	t.Parallel()
+
+	r, err := fakeChecker{severity: check.SeverityWarn}.Check(context.Background())
+	require.NoError(t, err)
+
+	got, err := Marshal(r)
+	require.NoError(t, err)
+	assert.NotContains(t, string(got), "pgdoctor_version")
+	assert.Contains(t, string(got), `"details": "rows < 10 & growing"`)
+	assert.Contains(t, string(got), `"severity": "warn"`)
+}
+
+// TestGolden writes a golden file with -update semantics, then compares a
+// second run against it. It changes directory and the update flag, so it
+// does not run in parallel.
+func TestGolden(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	*update = true
+	Golden(t, "nested/warn", fakeChecker{severity: check.SeverityWarn})
+	*update = false
+
+	written, err := os.ReadFile(filepath.Join(Dir, "nested", "warn.golden.json"))
+	require.NoError(t, err)
+	assert.Contains(t, string(written), `"check_id": "fake"`)
+
+	Golden(t, "nested/warn", fakeChecker{severity: check.SeverityWarn})
+
+	mismatch := &testing.T{}
+	GoldenReport(mismatch, "nested/warn", mustCheck(t, fakeChecker{severity: check.SeverityFail}))
+	assert.True(t, mismatch.Failed())
+}
+
+func mustCheck(t *testing.T, c check.Checker) *check.Report {
+	t.Helper()
+	r, err := c.Check(context.Background())
+	require.NoError(t, err)
+	return r
+}
+
+func TestFinding(t *testing.T) {
+	t.Parallel()
+
+	r, err := fakeChecker{severity: check.SeverityWarn}.Check(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, check.SeverityWarn, Finding(t, r, "fake").Severity)
+	NoFinding(t, r, "other")
+}
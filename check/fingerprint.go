@@ -0,0 +1,15 @@
+package check
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// Fingerprint identifies the same logical finding across runs. It hashes only
+// the check ID, finding ID, and object identity, never the details text or
+// severity, so baselines and history diffs keep matching as numbers change.
+// object is empty for findings that summarise a whole check.
+func Fingerprint(checkID, findingID, object string) string {
+	sum := sha256.Sum256([]byte(checkID + "\x00" + findingID + "\x00" + object))
+	return hex.EncodeToString(sum[:8])
+}
@@ -0,0 +1,68 @@
+package check
+
+// Risk classifies how safe a Fix is to apply from pgdoctor fix. The zero
+// value is RiskHigh, so a fix is only offered once a check marks it low risk.
+type Risk int
+
+const (
+	RiskHigh Risk = iota // Rewrites, long or heavy locks, or a setting someone chose deliberately
+	RiskLow              // Online, brief locks, and easy to undo
+)
+
+func (r Risk) String() string {
+	switch r {
+	case RiskLow:
+		return "low"
+	case RiskHigh:
+		return "high"
+	default:
+		return "unknown"
+	}
+}
+
+// LockImpact classifies what a Fix blocks while it runs. The zero value is
+// LockExclusive, so a fix only counts as safe during traffic once a check
+// says so.
+type LockImpact int
+
+const (
+	LockExclusive LockImpact = iota // ACCESS EXCLUSIVE: blocks reads and writes
+	LockWrites                      // SHARE or stronger: blocks writes
+	LockOnline                      // SHARE UPDATE EXCLUSIVE or weaker: reads and writes continue
+)
+
+func (l LockImpact) String() string {
+	switch l {
+	case LockExclusive:
+		return "exclusive"
+	case LockWrites:
+		return "writes"
+	case LockOnline:
+		return "online"
+	default:
+		return "unknown"
+	}
+}
+
+// Fix is one SQL statement that resolves part of a finding. Checks attach
+// fixes only for prescriptions they can state exactly; pgdoctor fix
+// re-runs the check after applying one and treats the fix as resolved once
+// the check no longer offers the same SQL.
+type Fix struct {
+	// Object is the database object the statement changes.
+	Object string
+	// Description says what the statement does and what to confirm first.
+	Description string
+	// SQL is a single statement, run outside a transaction so that
+	// CONCURRENTLY variants work.
+	SQL  string
+	Risk Risk
+	// Lock is what the statement blocks while it runs.
+	Lock LockImpact
+}
+
+// NeedsMaintenanceWindow reports whether the fix blocks reads or writes
+// and should wait for a configured maintenance window.
+func (f Fix) NeedsMaintenanceWindow() bool {
+	return f.Lock != LockOnline
+}
@@ -0,0 +1,258 @@
+package check
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// IgnoreRules lists patterns for database objects that checks leave out of
+// their analysis, such as extension-managed schemas or tables scheduled for
+// removal. A pattern containing a dot is matched against the qualified
+// schema.name; one without is matched against the unqualified name. In
+// patterns, * and % match any run of characters and ? matches one. A
+// pattern starting with re: is a regular expression that must match the
+// whole name, qualified or not.
+type IgnoreRules struct {
+	Schemas   []string `yaml:"schemas,omitempty" json:"schemas,omitempty"`
+	Tables    []string `yaml:"tables,omitempty" json:"tables,omitempty"`
+	Indexes   []string `yaml:"indexes,omitempty" json:"indexes,omitempty"`
+	Sequences []string `yaml:"sequences,omitempty" json:"sequences,omitempty"`
+}
+
+// Empty reports whether no patterns are set.
+func (r IgnoreRules) Empty() bool {
+	return len(r.Schemas) == 0 && len(r.Tables) == 0 && len(r.Indexes) == 0 && len(r.Sequences) == 0
+}
+
+// Compile returns the filter for the rules, or nil when they are empty.
+func (r IgnoreRules) Compile() (*ObjectFilter, error) {
+	if r.Empty() {
+		return nil, nil
+	}
+	var f ObjectFilter
+	var err error
+	if f.schemas, err = compilePatterns("schemas", r.Schemas); err != nil {
+		return nil, err
+	}
+	if f.tables, err = compilePatterns("tables", r.Tables); err != nil {
+		return nil, err
+	}
+	if f.indexes, err = compilePatterns("indexes", r.Indexes); err != nil {
+		return nil, err
+	}
+	if f.sequences, err = compilePatterns("sequences", r.Sequences); err != nil {
+		return nil, err
+	}
+	return &f, nil
+}
+
+// ObjectFilter decides which objects checks ignore. A nil filter ignores
+// nothing, so checks can call it without testing for one.
+type ObjectFilter struct {
+	// scope, when set, is the only schema whose objects are checked.
+	scope string
+
+	schemas   []objectPattern
+	tables    []objectPattern
+	indexes   []objectPattern
+	sequences []objectPattern
+}
+
+type objectPattern struct {
+	re *regexp.Regexp
+	// qualified patterns match schema.name, others the bare name; regular
+	// expressions may match either.
+	qualified bool
+	regex     bool
+}
+
+func compilePatterns(kind string, patterns []string) ([]objectPattern, error) {
+	compiled := make([]objectPattern, 0, len(patterns))
+	for _, p := range patterns {
+		expr, isRegex := strings.CutPrefix(p, "re:")
+		if !isRegex {
+			expr = globToRegexp(p)
+		}
+		re, err := regexp.Compile("^(?:" + expr + ")$")
+		if err != nil {
+			return nil, fmt.Errorf("%s pattern %q: %w", kind, p, err)
+		}
+		compiled = append(compiled, objectPattern{re: re, qualified: strings.Contains(p, "."), regex: isRegex})
+	}
+	return compiled, nil
+}
+
+func globToRegexp(glob string) string {
+	var b strings.Builder
+	for _, r := range glob {
+		switch r {
+		case '*', '%':
+			b.WriteString(".*")
+		case '?':
+			b.WriteString(".")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	return b.String()
+}
+
+func matchAny(patterns []objectPattern, schema, name string) bool {
+	for _, p := range patterns {
+		switch {
+		case p.regex:
+			if p.re.MatchString(name) || p.re.MatchString(schema+"."+name) {
+				return true
+			}
+		case p.qualified:
+			if p.re.MatchString(schema + "." + name) {
+				return true
+			}
+		default:
+			if p.re.MatchString(name) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ScopedToSchema returns a copy of f that also ignores every schema other
+// than schema, so checks of tables, indexes, and sequences look at one
+// tenant of a schema-per-tenant database and their totals cover only it.
+func (f *ObjectFilter) ScopedToSchema(schema string) *ObjectFilter {
+	var scoped ObjectFilter
+	if f != nil {
+		scoped = *f
+	}
+	scoped.scope = schema
+	return &scoped
+}
+
+// Scope returns the schema f is scoped to, or "" when it is not.
+func (f *ObjectFilter) Scope() string {
+	if f == nil {
+		return ""
+	}
+	return f.scope
+}
+
+// Schema reports whether objects in schema are ignored.
+func (f *ObjectFilter) Schema(schema string) bool {
+	if f == nil {
+		return false
+	}
+	if f.scope != "" && schema != f.scope {
+		return true
+	}
+	return matchAny(f.schemas, "", schema)
+}
+
+// Table reports whether a table is ignored, by its own pattern or its schema's.
+func (f *ObjectFilter) Table(schema, table string) bool {
+	if f == nil {
+		return false
+	}
+	return f.Schema(schema) || matchAny(f.tables, schema, table)
+}
+
+// Index reports whether an index is ignored, by its own pattern or those of
+// its table or schema. table may be empty when the caller does not know it.
+func (f *ObjectFilter) Index(schema, table, index string) bool {
+	if f == nil {
+		return false
+	}
+	if table != "" && f.Table(schema, table) {
+		return true
+	}
+	return f.Schema(schema) || matchAny(f.indexes, schema, index)
+}
+
+// Sequence reports whether a sequence is ignored, by its own pattern or its
+// schema's.
+func (f *ObjectFilter) Sequence(schema, sequence string) bool {
+	if f == nil {
+		return false
+	}
+	return f.Schema(schema) || matchAny(f.sequences, schema, sequence)
+}
+
+// QualifiedTable is Table for a name that may be schema-qualified, as
+// regclass prints it. Unqualified names are taken to be in public.
+func (f *ObjectFilter) QualifiedTable(name string) bool {
+	if f == nil {
+		return false
+	}
+	schema, table := SplitQualifiedName(name)
+	return f.Table(schema, table)
+}
+
+// QualifiedTableIndex is Index for an unqualified index on a table whose
+// name may be schema-qualified.
+func (f *ObjectFilter) QualifiedTableIndex(table, index string) bool {
+	if f == nil {
+		return false
+	}
+	schema, table := SplitQualifiedName(table)
+	return f.Index(schema, table, index)
+}
+
+// QualifiedIndex is Index for a name that may be schema-qualified.
+func (f *ObjectFilter) QualifiedIndex(name string) bool {
+	if f == nil {
+		return false
+	}
+	schema, index := SplitQualifiedName(name)
+	return f.Index(schema, "", index)
+}
+
+// SplitQualifiedName splits a possibly quoted schema.name into its parts.
+// Names without a schema are taken to be in public.
+func SplitQualifiedName(name string) (schema, object string) {
+	inQuotes := false
+	for i, r := range name {
+		switch {
+		case r == '"':
+			inQuotes = !inQuotes
+		case r == '.' && !inQuotes:
+			return unquoteIdent(name[:i]), unquoteIdent(name[i+1:])
+		}
+	}
+	return "public", unquoteIdent(name)
+}
+
+func unquoteIdent(s string) string {
+	if len(s) >= 2 && s[0] == '"' && s[len(s)-1] == '"' {
+		return strings.ReplaceAll(s[1:len(s)-1], `""`, `"`)
+	}
+	return s
+}
+
+type objectFilterKey struct{}
+
+// ContextWithObjectFilter returns a context whose checks skip the objects f
+// ignores.
+func ContextWithObjectFilter(ctx context.Context, f *ObjectFilter) context.Context {
+	return context.WithValue(ctx, objectFilterKey{}, f)
+}
+
+// SchemaFromContext returns the schema the run is scoped to, or def when it
+// is not. Queries that look at a single schema use it in place of their
+// default, so a scoped run reads the tenant's own rows.
+func SchemaFromContext(ctx context.Context, def string) string {
+	if scope := ObjectFilterFromContext(ctx).Scope(); scope != "" {
+		return scope
+	}
+	return def
+}
+
+// ObjectFilterFromContext returns the context's object filter, or nil when
+// none is set.
+func ObjectFilterFromContext(ctx context.Context) *ObjectFilter {
+	if f, ok := ctx.Value(objectFilterKey{}).(*ObjectFilter); ok {
+		return f
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+package check
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestObjectFilter(t *testing.T) {
+	t.Parallel()
+
+	f, err := IgnoreRules{
+		Schemas:   []string{"partman", "pg_temp_*"},
+		Tables:    []string{"legacy_%", "audit.events_?", "re:public\\.tmp_[0-9]+"},
+		Indexes:   []string{"*_old_idx"},
+		Sequences: []string{"billing.invoice_seq"},
+	}.Compile()
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		ignored bool
+	}{
+		{name: "schema", ignored: f.Schema("partman")},
+		{name: "temp schema glob", ignored: f.Schema("pg_temp_12")},
+		{name: "table in ignored schema", ignored: f.Table("partman", "template_public_orders")},
+		{name: "unqualified table pattern in any schema", ignored: f.Table("sales", "legacy_orders")},
+		{name: "qualified table pattern", ignored: f.Table("audit", "events_1")},
+		{name: "regular expression on qualified name", ignored: f.Table("public", "tmp_42")},
+		{name: "index pattern", ignored: f.Index("public", "orders", "orders_customer_old_idx")},
+		{name: "index of ignored table", ignored: f.Index("public", "legacy_orders", "legacy_orders_pkey")},
+		{name: "sequence", ignored: f.Sequence("billing", "invoice_seq")},
+		{name: "regclass name", ignored: f.QualifiedTable("audit.events_2")},
+	}
+	for _, tt := range tests {
+		assert.True(t, tt.ignored, tt.name)
+	}
+
+	assert.False(t, f.Schema("public"))
+	assert.False(t, f.Table("public", "orders"))
+	assert.False(t, f.Table("other", "events_1"), "qualified pattern needs its schema")
+	assert.False(t, f.Table("audit", "events_10"), "? matches one character")
+	assert.False(t, f.Table("public", "tmp_x"))
+	assert.False(t, f.QualifiedTable(`"Audit".events_2`), "names are case-sensitive")
+	assert.False(t, f.Index("public", "orders", "orders_pkey"))
+	assert.False(t, f.Sequence("public", "invoice_seq"))
+}
+
+func TestObjectFilter_Nil(t *testing.T) {
+	t.Parallel()
+
+	f, err := IgnoreRules{}.Compile()
+	require.NoError(t, err)
+	require.Nil(t, f)
+
+	assert.False(t, f.Schema("public"))
+	assert.False(t, f.Table("public", "orders"))
+	assert.False(t, f.Index("public", "orders", "orders_pkey"))
+	assert.False(t, f.QualifiedTableIndex("public.orders", "orders_pkey"))
+	assert.Nil(t, ObjectFilterFromContext(context.Background()))
+}
+
+func TestObjectFilter_ScopedToSchema(t *testing.T) {
+	t.Parallel()
+
+	var none *ObjectFilter
+	f := none.ScopedToSchema("tenant_42")
+	assert.Equal(t, "tenant_42", f.Scope())
+	assert.Empty(t, none.Scope())
+	assert.False(t, f.Table("tenant_42", "orders"))
+	assert.True(t, f.Table("tenant_7", "orders"))
+	assert.True(t, f.QualifiedTable("orders"), "unqualified names are in public")
+	assert.True(t, f.Sequence("public", "orders_id_seq"))
+
+	rules, err := IgnoreRules{Tables: []string{"tmp_*"}}.Compile()
+	require.NoError(t, err)
+	f = rules.ScopedToSchema("tenant_42")
+	assert.True(t, f.Table("tenant_42", "tmp_import"), "ignore rules still apply within the schema")
+	assert.False(t, f.Index("tenant_42", "orders", "orders_pkey"))
+	assert.Empty(t, rules.Scope(), "the original filter is unchanged")
+}
+
+func TestObjectFilter_InvalidRegexp(t *testing.T) {
+	t.Parallel()
+
+	_, err := IgnoreRules{Indexes: []string{"re:orders_("}}.Compile()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `indexes pattern "re:orders_("`)
+}
+
+func TestSplitQualifiedName(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		in, schema, name string
+	}{
+		{"public.orders", "public", "orders"},
+		{"orders", "public", "orders"},
+		{`"My.Schema"."Orders"`, "My.Schema", "Orders"},
+		{`sales."say ""hi"""`, "sales", `say "hi"`},
+	}
+	for _, tt := range tests {
+		schema, name := SplitQualifiedName(tt.in)
+		assert.Equal(t, tt.schema, schema, tt.in)
+		assert.Equal(t, tt.name, name, tt.in)
+	}
+}
+
+func TestContextWithObjectFilter(t *testing.T) {
+	t.Parallel()
+
+	f, err := IgnoreRules{Tables: []string{"legacy_*"}}.Compile()
+	require.NoError(t, err)
+
+	ctx := ContextWithObjectFilter(context.Background(), f)
+	assert.True(t, ObjectFilterFromContext(ctx).Table("public", "legacy_users"))
+}
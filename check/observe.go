@@ -0,0 +1,69 @@
+package check
+
+import (
+	"context"
+	"strconv"
+)
+
+// Observation is a metric value a check compared against a configurable
+// WARN threshold. pgdoctor calibrate collects observations to propose
+// thresholds that sit just above a database's steady state.
+type Observation struct {
+	CheckID   string
+	FindingID string
+	// Key is the Config key that overrides the WARN threshold.
+	Key   string
+	Value float64
+	// Warn and Fail are the thresholds in effect for this run.
+	Warn float64
+	Fail float64
+}
+
+// Observer receives observations as checks run.
+type Observer func(Observation)
+
+type observerKey struct{}
+
+// ContextWithObserver returns a context whose checks report their observed
+// metric values to fn, in addition to any observer ctx already carries.
+func ContextWithObserver(ctx context.Context, fn Observer) context.Context {
+	if parent, ok := ctx.Value(observerKey{}).(Observer); ok {
+		next := fn
+		fn = func(o Observation) {
+			parent(o)
+			next(o)
+		}
+	}
+	return context.WithValue(ctx, observerKey{}, fn)
+}
+
+// Observe reports an observation to the context's observer, if any.
+// Checks call it for every metric that has a configurable threshold.
+func Observe(ctx context.Context, o Observation) {
+	if fn, ok := ctx.Value(observerKey{}).(Observer); ok {
+		fn(o)
+	}
+}
+
+// Float returns the positive number configured for checkID under key, or def
+// when it is unset or invalid.
+func (c Config) Float(checkID, key string, def float64) float64 {
+	v, ok := c[checkID][key]
+	if !ok {
+		return def
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil || f <= 0 {
+		return def
+	}
+	return f
+}
+
+// String returns the value configured for checkID under key, or def when it
+// is unset or empty.
+func (c Config) String(checkID, key, def string) string {
+	if v := c[checkID][key]; v != "" {
+		return v
+	}
+	return def
+}
@@ -0,0 +1,95 @@
+package check
+
+// Privilege is access a check needs beyond a plain login role to see
+// everything it inspects. Without it the check still runs, but rows are
+// hidden from it or findings are skipped with a note saying what is missing.
+type Privilege string
+
+const (
+	// PrivilegeReadAllStats shows other roles' sessions, statements, and
+	// replication details in pg_stat_activity, pg_stat_statements, and
+	// pg_stat_replication.
+	PrivilegeReadAllStats Privilege = "pg_read_all_stats"
+	// PrivilegeReadAllSettings shows superuser-only settings such as
+	// primary_conninfo and archive_command.
+	PrivilegeReadAllSettings Privilege = "pg_read_all_settings"
+	// PrivilegeStatScanTables allows pgstattuple functions, which read
+	// whole relations.
+	PrivilegeStatScanTables Privilege = "pg_stat_scan_tables"
+	// PrivilegeMonitor allows pg_ls_waldir, pg_ls_tmpdir, and
+	// pg_buffercache. It includes the three roles above.
+	PrivilegeMonitor Privilege = "pg_monitor"
+	// PrivilegeReadServerFiles allows reading files on the database server,
+	// such as the TLS certificate.
+	PrivilegeReadServerFiles Privilege = "pg_read_server_files"
+	// PrivilegeReadSchemas is SELECT on user tables and sequences. pg_stats
+	// only shows columns of tables the role can read, and pg_sequences
+	// hides last_value without it.
+	PrivilegeReadSchemas Privilege = "read-schemas"
+	// PrivilegeHbaRules is read access to pg_hba_file_rules, which is
+	// superuser-only by default.
+	PrivilegeHbaRules Privilege = "hba-rules"
+	// PrivilegeBtreeMetapage is EXECUTE on pageinspect's bt_metap, which is
+	// superuser-only by default.
+	PrivilegeBtreeMetapage Privilege = "bt-metap"
+)
+
+// Privileges lists every privilege in the order grants are printed.
+var Privileges = []Privilege{
+	PrivilegeMonitor,
+	PrivilegeReadAllStats,
+	PrivilegeReadAllSettings,
+	PrivilegeStatScanTables,
+	PrivilegeReadServerFiles,
+	PrivilegeReadSchemas,
+	PrivilegeHbaRules,
+	PrivilegeBtreeMetapage,
+}
+
+// Description says what the privilege lets a check see, for documentation.
+func (p Privilege) Description() string {
+	switch p {
+	case PrivilegeReadAllStats:
+		return "Other roles' sessions, statement text, and replication details"
+	case PrivilegeReadAllSettings:
+		return "Superuser-only settings such as primary_conninfo and archive_command"
+	case PrivilegeStatScanTables:
+		return "pgstattuple measurements"
+	case PrivilegeMonitor:
+		return "pg_ls_waldir, pg_ls_tmpdir, pg_buffercache, and everything pg_read_all_stats, pg_read_all_settings, and pg_stat_scan_tables allow"
+	case PrivilegeReadServerFiles:
+		return "Files on the database server, such as the TLS certificate"
+	case PrivilegeReadSchemas:
+		return "SELECT on user tables and sequences, needed for pg_stats rows and sequence values"
+	case PrivilegeHbaRules:
+		return "pg_hba_file_rules"
+	case PrivilegeBtreeMetapage:
+		return "pageinspect's bt_metap, to read B-tree versions"
+	default:
+		return string(p)
+	}
+}
+
+// IsPredefinedRole reports whether the privilege is granted by membership in
+// the PostgreSQL predefined role of the same name.
+func (p Privilege) IsPredefinedRole() bool {
+	switch p {
+	case PrivilegeReadAllStats, PrivilegeReadAllSettings, PrivilegeStatScanTables, PrivilegeMonitor, PrivilegeReadServerFiles:
+		return true
+	default:
+		return false
+	}
+}
+
+// IncludedIn reports whether membership in other already grants p.
+func (p Privilege) IncludedIn(other Privilege) bool {
+	if other != PrivilegeMonitor {
+		return false
+	}
+	switch p {
+	case PrivilegeReadAllStats, PrivilegeReadAllSettings, PrivilegeStatScanTables:
+		return true
+	default:
+		return false
+	}
+}
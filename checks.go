@@ -5,32 +5,55 @@ package pgdoctor
 
 import (
 	"github.com/fresha/pgdoctor/check"
+	"github.com/fresha/pgdoctor/checks/accessmethods"
+	"github.com/fresha/pgdoctor/checks/advisorylocks"
+	"github.com/fresha/pgdoctor/checks/buffercache"
 	"github.com/fresha/pgdoctor/checks/cacheefficiency"
+	"github.com/fresha/pgdoctor/checks/checkpointhealth"
 	"github.com/fresha/pgdoctor/checks/connectionefficiency"
 	"github.com/fresha/pgdoctor/checks/connectionhealth"
+	"github.com/fresha/pgdoctor/checks/correlation"
 	"github.com/fresha/pgdoctor/checks/duplicateindexes"
+	"github.com/fresha/pgdoctor/checks/durability"
+	"github.com/fresha/pgdoctor/checks/encodinglocale"
+	"github.com/fresha/pgdoctor/checks/eventtriggers"
+	"github.com/fresha/pgdoctor/checks/failoverreadiness"
 	"github.com/fresha/pgdoctor/checks/freezeage"
 	"github.com/fresha/pgdoctor/checks/indexbloat"
 	"github.com/fresha/pgdoctor/checks/indexusage"
 	"github.com/fresha/pgdoctor/checks/invalidindexes"
+	"github.com/fresha/pgdoctor/checks/lockcontention"
+	"github.com/fresha/pgdoctor/checks/orphanedtemp"
+	"github.com/fresha/pgdoctor/checks/partialindexes"
 	"github.com/fresha/pgdoctor/checks/partitioning"
 	"github.com/fresha/pgdoctor/checks/partitionusage"
 	"github.com/fresha/pgdoctor/checks/pgversion"
 	"github.com/fresha/pgdoctor/checks/pktypes"
+	"github.com/fresha/pgdoctor/checks/preparedxacts"
+	"github.com/fresha/pgdoctor/checks/querypatterns"
+	"github.com/fresha/pgdoctor/checks/queuetables"
 	"github.com/fresha/pgdoctor/checks/replicationlag"
 	"github.com/fresha/pgdoctor/checks/replicationslots"
+	"github.com/fresha/pgdoctor/checks/securitysettings"
 	"github.com/fresha/pgdoctor/checks/sequencehealth"
 	"github.com/fresha/pgdoctor/checks/sessionsettings"
+	"github.com/fresha/pgdoctor/checks/standbyrecovery"
 	"github.com/fresha/pgdoctor/checks/statisticsfreshness"
 	"github.com/fresha/pgdoctor/checks/tableactivity"
 	"github.com/fresha/pgdoctor/checks/tablebloat"
 	"github.com/fresha/pgdoctor/checks/tableseqscans"
+	"github.com/fresha/pgdoctor/checks/tablespaceplacement"
 	"github.com/fresha/pgdoctor/checks/tablevacuumhealth"
 	"github.com/fresha/pgdoctor/checks/tempusage"
+	"github.com/fresha/pgdoctor/checks/timezone"
+	"github.com/fresha/pgdoctor/checks/tlscerts"
 	"github.com/fresha/pgdoctor/checks/toaststorage"
+	"github.com/fresha/pgdoctor/checks/txnrates"
 	"github.com/fresha/pgdoctor/checks/uuiddefaults"
 	"github.com/fresha/pgdoctor/checks/uuidtypes"
 	"github.com/fresha/pgdoctor/checks/vacuumsettings"
+	"github.com/fresha/pgdoctor/checks/walcompression"
+	"github.com/fresha/pgdoctor/checks/walsize"
 	"github.com/fresha/pgdoctor/db"
 )
 
@@ -38,12 +61,36 @@ import (
 // Consumers call .Metadata() for check information or .New(conn, cfg) to instantiate checkers.
 func AllChecks() []check.Package {
 	return []check.Package{
+		{
+			Metadata: accessmethods.Metadata,
+			New: func(conn db.DBTX, cfg check.Config) check.Checker {
+				return accessmethods.New(db.New(conn), cfg)
+			},
+		},
+		{
+			Metadata: advisorylocks.Metadata,
+			New: func(conn db.DBTX, cfg check.Config) check.Checker {
+				return advisorylocks.New(db.New(conn), cfg)
+			},
+		},
+		{
+			Metadata: buffercache.Metadata,
+			New: func(conn db.DBTX, cfg check.Config) check.Checker {
+				return buffercache.New(db.New(conn), cfg)
+			},
+		},
 		{
 			Metadata: cacheefficiency.Metadata,
 			New: func(conn db.DBTX, cfg check.Config) check.Checker {
 				return cacheefficiency.New(db.New(conn), cfg)
 			},
 		},
+		{
+			Metadata: checkpointhealth.Metadata,
+			New: func(conn db.DBTX, cfg check.Config) check.Checker {
+				return checkpointhealth.New(db.New(conn), cfg)
+			},
+		},
 		{
 			Metadata: connectionefficiency.Metadata,
 			New: func(conn db.DBTX, cfg check.Config) check.Checker {
@@ -56,12 +103,42 @@ func AllChecks() []check.Package {
 				return connectionhealth.New(db.New(conn), cfg)
 			},
 		},
+		{
+			Metadata: correlation.Metadata,
+			New: func(conn db.DBTX, cfg check.Config) check.Checker {
+				return correlation.New(db.New(conn), cfg)
+			},
+		},
 		{
 			Metadata: duplicateindexes.Metadata,
 			New: func(conn db.DBTX, cfg check.Config) check.Checker {
 				return duplicateindexes.New(db.New(conn), cfg)
 			},
 		},
+		{
+			Metadata: durability.Metadata,
+			New: func(conn db.DBTX, cfg check.Config) check.Checker {
+				return durability.New(db.New(conn), cfg)
+			},
+		},
+		{
+			Metadata: encodinglocale.Metadata,
+			New: func(conn db.DBTX, cfg check.Config) check.Checker {
+				return encodinglocale.New(db.New(conn), cfg)
+			},
+		},
+		{
+			Metadata: eventtriggers.Metadata,
+			New: func(conn db.DBTX, cfg check.Config) check.Checker {
+				return eventtriggers.New(db.New(conn), cfg)
+			},
+		},
+		{
+			Metadata: failoverreadiness.Metadata,
+			New: func(conn db.DBTX, cfg check.Config) check.Checker {
+				return failoverreadiness.New(db.New(conn), cfg)
+			},
+		},
 		{
 			Metadata: freezeage.Metadata,
 			New: func(conn db.DBTX, cfg check.Config) check.Checker {
@@ -86,6 +163,24 @@ func AllChecks() []check.Package {
 				return invalidindexes.New(db.New(conn), cfg)
 			},
 		},
+		{
+			Metadata: lockcontention.Metadata,
+			New: func(conn db.DBTX, cfg check.Config) check.Checker {
+				return lockcontention.New(db.New(conn), cfg)
+			},
+		},
+		{
+			Metadata: orphanedtemp.Metadata,
+			New: func(conn db.DBTX, cfg check.Config) check.Checker {
+				return orphanedtemp.New(db.New(conn), cfg)
+			},
+		},
+		{
+			Metadata: partialindexes.Metadata,
+			New: func(conn db.DBTX, cfg check.Config) check.Checker {
+				return partialindexes.New(db.New(conn), cfg)
+			},
+		},
 		{
 			Metadata: partitioning.Metadata,
 			New: func(conn db.DBTX, cfg check.Config) check.Checker {
@@ -110,6 +205,24 @@ func AllChecks() []check.Package {
 				return pktypes.New(db.New(conn), cfg)
 			},
 		},
+		{
+			Metadata: preparedxacts.Metadata,
+			New: func(conn db.DBTX, cfg check.Config) check.Checker {
+				return preparedxacts.New(db.New(conn), cfg)
+			},
+		},
+		{
+			Metadata: querypatterns.Metadata,
+			New: func(conn db.DBTX, cfg check.Config) check.Checker {
+				return querypatterns.New(db.New(conn), cfg)
+			},
+		},
+		{
+			Metadata: queuetables.Metadata,
+			New: func(conn db.DBTX, cfg check.Config) check.Checker {
+				return queuetables.New(db.New(conn), cfg)
+			},
+		},
 		{
 			Metadata: replicationlag.Metadata,
 			New: func(conn db.DBTX, cfg check.Config) check.Checker {
@@ -122,6 +235,12 @@ func AllChecks() []check.Package {
 				return replicationslots.New(db.New(conn), cfg)
 			},
 		},
+		{
+			Metadata: securitysettings.Metadata,
+			New: func(conn db.DBTX, cfg check.Config) check.Checker {
+				return securitysettings.New(db.New(conn), cfg)
+			},
+		},
 		{
 			Metadata: sequencehealth.Metadata,
 			New: func(conn db.DBTX, cfg check.Config) check.Checker {
@@ -134,6 +253,12 @@ func AllChecks() []check.Package {
 				return sessionsettings.New(db.New(conn), cfg)
 			},
 		},
+		{
+			Metadata: standbyrecovery.Metadata,
+			New: func(conn db.DBTX, cfg check.Config) check.Checker {
+				return standbyrecovery.New(db.New(conn), cfg)
+			},
+		},
 		{
 			Metadata: statisticsfreshness.Metadata,
 			New: func(conn db.DBTX, cfg check.Config) check.Checker {
@@ -158,6 +283,12 @@ func AllChecks() []check.Package {
 				return tableseqscans.New(db.New(conn), cfg)
 			},
 		},
+		{
+			Metadata: tablespaceplacement.Metadata,
+			New: func(conn db.DBTX, cfg check.Config) check.Checker {
+				return tablespaceplacement.New(db.New(conn), cfg)
+			},
+		},
 		{
 			Metadata: tablevacuumhealth.Metadata,
 			New: func(conn db.DBTX, cfg check.Config) check.Checker {
@@ -170,12 +301,30 @@ func AllChecks() []check.Package {
 				return tempusage.New(db.New(conn), cfg)
 			},
 		},
+		{
+			Metadata: timezone.Metadata,
+			New: func(conn db.DBTX, cfg check.Config) check.Checker {
+				return timezone.New(db.New(conn), cfg)
+			},
+		},
+		{
+			Metadata: tlscerts.Metadata,
+			New: func(conn db.DBTX, cfg check.Config) check.Checker {
+				return tlscerts.New(db.New(conn), cfg)
+			},
+		},
 		{
 			Metadata: toaststorage.Metadata,
 			New: func(conn db.DBTX, cfg check.Config) check.Checker {
 				return toaststorage.New(db.New(conn), cfg)
 			},
 		},
+		{
+			Metadata: txnrates.Metadata,
+			New: func(conn db.DBTX, cfg check.Config) check.Checker {
+				return txnrates.New(db.New(conn), cfg)
+			},
+		},
 		{
 			Metadata: uuiddefaults.Metadata,
 			New: func(conn db.DBTX, cfg check.Config) check.Checker {
@@ -194,5 +343,17 @@ func AllChecks() []check.Package {
 				return vacuumsettings.New(db.New(conn), cfg)
 			},
 		},
+		{
+			Metadata: walcompression.Metadata,
+			New: func(conn db.DBTX, cfg check.Config) check.Checker {
+				return walcompression.New(db.New(conn), cfg)
+			},
+		},
+		{
+			Metadata: walsize.Metadata,
+			New: func(conn db.DBTX, cfg check.Config) check.Checker {
+				return walsize.New(db.New(conn), cfg)
+			},
+		},
 	}
 }
@@ -0,0 +1,411 @@
+// Package accessmethods implements checks for indexes that a newer or
+// different access method would serve better: hash indexes left in the
+// pre-PostgreSQL 10 format, B-tree indexes that predate deduplication, and
+// B-tree indexes on append-only timestamps that BRIN could replace.
+package accessmethods
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"math"
+	"slices"
+
+	"github.com/fresha/pgdoctor/check"
+	"github.com/fresha/pgdoctor/db"
+	"github.com/fresha/pgdoctor/internal/pgident"
+)
+
+//go:embed query.sql
+var querySQL string
+
+//go:embed README.md
+var readme string
+
+const (
+	// B-tree metapage version that supports deduplication (PostgreSQL 13+).
+	dedupBtreeVersion = 4
+	// Flag indexes that deduplication would shrink by at least this fraction.
+	minDedupSavings = 0.3
+	// A posting list holds at most a few hundred heap TIDs before it is split
+	// across several index tuples.
+	maxPostingTIDs = 200
+	// bt_metap reads one page per index; cap the lookups per run anyway.
+	maxVersionLookups = 50
+
+	// An append-only column in near-perfect physical order, indexed by a
+	// B-tree this large, is a strong BRIN candidate.
+	brinMinCorrelation = 0.95
+	brinMinIndexBytes  = int64(100 * 1024 * 1024) // 100MiB
+	// Updates and deletes at most this fraction of inserts count as append-only.
+	appendOnlyMaxChurn = 0.01
+	appendOnlyMinRows  = int64(10000)
+
+	pageBytes         = 8192
+	brinPagesPerRange = 128
+	// Approximate minmax summaries per BRIN page and range pointers per
+	// revmap page.
+	brinTuplesPerPage   = 200
+	brinRangesPerRevmap = 1360
+)
+
+type AccessMethodsQueries interface {
+	AccessMethodHashIndexes(context.Context) ([]db.AccessMethodHashIndexesRow, error)
+	AccessMethodBtreeCandidates(context.Context) ([]db.AccessMethodBtreeCandidatesRow, error)
+	AccessMethodPageinspectAvailable(context.Context) (bool, error)
+	AccessMethodBtreeVersion(context.Context, db.AccessMethodBtreeVersionParams) (int32, error)
+	AccessMethodBrinCandidates(context.Context) ([]db.AccessMethodBrinCandidatesRow, error)
+}
+
+type checker struct {
+	queries AccessMethodsQueries
+}
+
+func Metadata() check.Metadata {
+	return check.Metadata{
+		Category:       check.CategoryIndexes,
+		CheckID:        "access-methods",
+		Name:           "Access Methods",
+		Description:    "Flags hash indexes in the pre-PostgreSQL 10 format, B-tree indexes that would shrink with deduplication, and append-only timestamp indexes that BRIN could replace",
+		Readme:         readme,
+		SQL:            querySQL,
+		RuntimeClass:   check.RuntimeMedium,
+		ProductionSafe: true,
+		Privileges:     []check.Privilege{check.PrivilegeReadSchemas, check.PrivilegeBtreeMetapage},
+		Findings: []check.FindingSpec{
+			{ID: "hash-indexes", Description: "Hash indexes; invalid ones are typically left in the pre-PostgreSQL 10 format by pg_upgrade", Thresholds: "FAIL if invalid"},
+			{ID: "btree-deduplication", Description: "Non-unique B-tree indexes (>= 10MiB) that deduplication would shrink by 30%+ but that were built before PostgreSQL 13 or have deduplicate_items = off", Thresholds: "WARN"},
+			{ID: "timestamp-brin", Description: "B-tree indexes (>= 100MiB) on append-only timestamp or date columns in near-perfect physical order (|correlation| >= 0.95), with the expected saving from BRIN"},
+		},
+	}
+}
+
+func New(queries AccessMethodsQueries, _ ...check.Config) check.Checker {
+	return &checker{
+		queries: queries,
+	}
+}
+
+func (c *checker) Metadata() check.Metadata {
+	return Metadata()
+}
+
+func (c *checker) Check(ctx context.Context) (*check.Report, error) {
+	report := check.NewReport(Metadata())
+	ignore := check.ObjectFilterFromContext(ctx)
+
+	hashIndexes, err := c.queries.AccessMethodHashIndexes(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("running %s/%s (hash): %w", report.Category, report.CheckID, err)
+	}
+	hashIndexes = slices.DeleteFunc(hashIndexes, func(row db.AccessMethodHashIndexesRow) bool {
+		return ignore.Index(row.SchemaName, row.TableName, row.IndexName)
+	})
+
+	btrees, err := c.queries.AccessMethodBtreeCandidates(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("running %s/%s (btree): %w", report.Category, report.CheckID, err)
+	}
+	btrees = slices.DeleteFunc(btrees, func(row db.AccessMethodBtreeCandidatesRow) bool {
+		return ignore.Index(row.SchemaName, row.TableName, row.IndexName)
+	})
+
+	versions, available, err := c.btreeVersions(ctx, btrees)
+	if err != nil {
+		return nil, fmt.Errorf("running %s/%s (bt_metap): %w", report.Category, report.CheckID, err)
+	}
+
+	brin, err := c.queries.AccessMethodBrinCandidates(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("running %s/%s (brin): %w", report.Category, report.CheckID, err)
+	}
+	brin = slices.DeleteFunc(brin, func(row db.AccessMethodBrinCandidatesRow) bool {
+		return ignore.Index(row.SchemaName, row.TableName, row.IndexName)
+	})
+
+	checkHashIndexes(hashIndexes, report)
+	checkDeduplication(btrees, versions, available, report)
+	checkTimestampBrin(brin, report)
+
+	return report, nil
+}
+
+func indexName(schema, index string) string {
+	return schema + "." + index
+}
+
+// btreeVersions reads the metapage version of the indexes deduplication
+// would shrink, largest first. It returns a nil map when pageinspect is
+// unavailable.
+func (c *checker) btreeVersions(ctx context.Context, rows []db.AccessMethodBtreeCandidatesRow) (map[string]int32, bool, error) {
+	var lookups []db.AccessMethodBtreeCandidatesRow
+	for _, row := range rows {
+		if !row.DedupDisabled && dedupSavings(row) >= minDedupSavings {
+			lookups = append(lookups, row)
+		}
+	}
+	if len(lookups) == 0 {
+		return nil, true, nil
+	}
+
+	available, err := c.queries.AccessMethodPageinspectAvailable(ctx)
+	if err != nil || !available {
+		return nil, false, err
+	}
+
+	versions := make(map[string]int32, min(len(lookups), maxVersionLookups))
+	for _, row := range lookups[:min(len(lookups), maxVersionLookups)] {
+		version, err := c.queries.AccessMethodBtreeVersion(ctx, db.AccessMethodBtreeVersionParams{SchemaName: row.SchemaName, IndexName: row.IndexName})
+		if err != nil {
+			return nil, true, fmt.Errorf("%s: %w", indexName(row.SchemaName, row.IndexName), err)
+		}
+		versions[indexName(row.SchemaName, row.IndexName)] = version
+	}
+	return versions, true, nil
+}
+
+func checkHashIndexes(rows []db.AccessMethodHashIndexesRow, report *check.Report) {
+	if len(rows) == 0 {
+		report.AddFinding(check.Finding{
+			ID:       "hash-indexes",
+			Name:     "Hash Indexes",
+			Severity: check.SeverityOK,
+			Details:  "No hash indexes",
+		})
+		return
+	}
+
+	var tableRows []check.TableRow
+	var fixes []check.Fix
+	invalid := 0
+	for _, row := range rows {
+		name := indexName(row.SchemaName, row.IndexName)
+		status, severity := "valid", check.SeverityOK
+		if !row.IsValid {
+			invalid++
+			status, severity = "invalid", check.SeverityFail
+			fixes = append(fixes, check.Fix{
+				Object:      name,
+				Description: fmt.Sprintf("Rebuild %s in the current hash index format", name),
+				SQL:         "REINDEX INDEX CONCURRENTLY " + pgident.Quote(row.SchemaName, row.IndexName),
+				Risk:        check.RiskLow,
+				Lock:        check.LockOnline,
+			})
+		}
+		tableRows = append(tableRows, check.TableRow{
+			Cells:    []string{name, indexName(row.SchemaName, row.TableName), check.FormatBytes(row.IndexBytes), check.FormatNumber(row.IdxScan), status},
+			Severity: severity,
+		})
+	}
+	table := &check.Table{
+		Headers: []string{"Index", "Table", "Size", "Scans", "Status"},
+		Rows:    tableRows,
+	}
+
+	if invalid == 0 {
+		report.AddFinding(check.Finding{
+			ID:       "hash-indexes",
+			Name:     "Hash Indexes",
+			Severity: check.SeverityOK,
+			Details: fmt.Sprintf("%d hash index(es), all valid. Hash indexes are crash-safe and replicated since PostgreSQL 10, "+
+				"but only serve equality lookups: they cannot enforce uniqueness, cover several columns, return rows in order, or support index-only scans", len(rows)),
+			Table: table,
+		})
+		return
+	}
+
+	report.AddFinding(check.Finding{
+		ID:       "hash-indexes",
+		Name:     "Hash Indexes",
+		Severity: check.SeverityFail,
+		Details: fmt.Sprintf("%d hash index(es) are invalid, so the planner ignores them. pg_upgrade from before PostgreSQL 10 marks hash indexes invalid "+
+			"because their on-disk format changed (the old format was not WAL-logged); they stay unusable until rebuilt. "+
+			"If the index only serves equality lookups a rebuild restores it; otherwise consider replacing it with a B-tree", invalid),
+		Table: table,
+		Fixes: fixes,
+	})
+}
+
+// rowsPerValue is the average number of rows sharing each indexed value, or
+// 0 when the column has no statistics.
+func rowsPerValue(row db.AccessMethodBtreeCandidatesRow) float64 {
+	switch {
+	case row.NDistinct < 0:
+		return -1 / row.NDistinct
+	case row.NDistinct > 0 && row.EstimatedRows > 0:
+		return float64(row.EstimatedRows) / row.NDistinct
+	default:
+		return 0
+	}
+}
+
+func maxAlign(n float64) float64 {
+	return math.Ceil(n/8) * 8
+}
+
+// dedupSavings estimates the fraction of an index without deduplication that
+// a deduplicated rebuild would save. Each leaf entry costs an 8-byte tuple
+// header (which holds the heap TID), the aligned key and a 4-byte line
+// pointer; deduplication stores the key once per posting list and 6 bytes
+// per additional TID.
+func dedupSavings(row db.AccessMethodBtreeCandidatesRow) float64 {
+	perValue := min(rowsPerValue(row), maxPostingTIDs)
+	if perValue < 2 || row.AvgWidth <= 0 {
+		return 0
+	}
+	width := float64(row.AvgWidth)
+	plain := maxAlign(8+width) + 4
+	deduplicated := (maxAlign(8+width+6*perValue) + 4) / perValue
+	return max(0, 1-deduplicated/plain)
+}
+
+func checkDeduplication(rows []db.AccessMethodBtreeCandidatesRow, versions map[string]int32, available bool, report *check.Report) {
+	var tableRows []check.TableRow
+	var fixes []check.Fix
+	var unchecked int
+	var total int64
+	for _, row := range rows {
+		savings := dedupSavings(row)
+		if savings < minDedupSavings {
+			continue
+		}
+		name := indexName(row.SchemaName, row.IndexName)
+
+		var reason string
+		var fix check.Fix
+		switch version, known := versions[name]; {
+		case row.DedupDisabled:
+			reason = "deduplicate_items = off"
+			fix = check.Fix{
+				Object: name,
+				Description: fmt.Sprintf("Re-enable deduplication on %s. New duplicates are merged as pages fill; "+
+					"run REINDEX INDEX CONCURRENTLY afterwards to compact existing entries", name),
+				SQL:  fmt.Sprintf("ALTER INDEX %s RESET (deduplicate_items)", pgident.Quote(row.SchemaName, row.IndexName)),
+				Risk: check.RiskLow,
+				Lock: check.LockOnline,
+			}
+		case !available:
+			unchecked++
+			continue
+		case !known || version >= dedupBtreeVersion:
+			continue
+		default:
+			reason = fmt.Sprintf("version %d (built before PostgreSQL 13)", version)
+			fix = check.Fix{
+				Object:      name,
+				Description: fmt.Sprintf("Rebuild %s in the current B-tree format so it can deduplicate", name),
+				SQL:         "REINDEX INDEX CONCURRENTLY " + pgident.Quote(row.SchemaName, row.IndexName),
+				Risk:        check.RiskLow,
+				Lock:        check.LockOnline,
+			}
+		}
+
+		saved := int64(float64(row.IndexBytes) * savings)
+		total += saved
+		tableRows = append(tableRows, check.TableRow{
+			Cells: []string{
+				name,
+				row.ColumnName,
+				check.FormatBytes(row.IndexBytes),
+				fmt.Sprintf("%.0f", rowsPerValue(row)),
+				fmt.Sprintf("~%s (%.0f%%)", check.FormatBytes(saved), savings*100),
+				reason,
+			},
+			Severity: check.SeverityWarn,
+		})
+		fixes = append(fixes, fix)
+	}
+
+	var note string
+	if unchecked > 0 {
+		note = fmt.Sprintf(". %d index(es) that deduplication would shrink by %.0f%% or more could not be checked: "+
+			"install pageinspect (CREATE EXTENSION pageinspect) and grant EXECUTE on bt_metap to read their B-tree version", unchecked, minDedupSavings*100)
+	}
+
+	if len(tableRows) == 0 {
+		report.AddFinding(check.Finding{
+			ID:       "btree-deduplication",
+			Name:     "B-tree Deduplication",
+			Severity: check.SeverityOK,
+			Details:  "No B-tree index with many duplicate keys is missing deduplication" + note,
+		})
+		return
+	}
+
+	report.AddFinding(check.Finding{
+		ID:       "btree-deduplication",
+		Name:     "B-tree Deduplication",
+		Severity: check.SeverityWarn,
+		Details: fmt.Sprintf("%d index(es) store every duplicate key separately; deduplication would save about %s. "+
+			"Indexes built before PostgreSQL 13 (and carried over by pg_upgrade) cannot deduplicate until rebuilt with REINDEX. "+
+			"Savings are estimated from column statistics for an index without bloat; see index-bloat for space lost to dead entries",
+			len(tableRows), check.FormatBytes(total)) + note,
+		Table: &check.Table{
+			Headers: []string{"Index", "Column", "Size", "Rows/Value", "Expected Saving", "Reason"},
+			Rows:    tableRows,
+		},
+		Fixes: fixes,
+	})
+}
+
+// estimatedBrinBytes approximates the size of a minmax BRIN index with the
+// default pages_per_range: a metapage, the range map, and one summary per
+// block range.
+func estimatedBrinBytes(tableBytes int64) int64 {
+	ranges := (tableBytes/pageBytes + brinPagesPerRange - 1) / brinPagesPerRange
+	pages := 1 + (ranges+brinRangesPerRevmap-1)/brinRangesPerRevmap + (ranges+brinTuplesPerPage-1)/brinTuplesPerPage
+	return pages * pageBytes
+}
+
+func isAppendOnly(row db.AccessMethodBrinCandidatesRow) bool {
+	return row.Inserts >= appendOnlyMinRows && float64(row.UpdatesDeletes) <= float64(row.Inserts)*appendOnlyMaxChurn
+}
+
+func checkTimestampBrin(rows []db.AccessMethodBrinCandidatesRow, report *check.Report) {
+	var tableRows []check.TableRow
+	var total int64
+	for _, row := range rows {
+		if math.Abs(row.Correlation) < brinMinCorrelation || row.IndexBytes < brinMinIndexBytes || !isAppendOnly(row) {
+			continue
+		}
+		brinBytes := estimatedBrinBytes(row.TableBytes)
+		saved := row.IndexBytes - brinBytes
+		total += saved
+		tableRows = append(tableRows, check.TableRow{
+			Cells: []string{
+				indexName(row.SchemaName, row.TableName),
+				row.IndexName,
+				row.ColumnName,
+				fmt.Sprintf("%.2f", row.Correlation),
+				check.FormatBytes(row.IndexBytes),
+				"~" + check.FormatBytes(brinBytes),
+				"~" + check.FormatBytes(saved),
+			},
+			Severity: check.SeverityOK,
+		})
+	}
+
+	if len(tableRows) == 0 {
+		report.AddFinding(check.Finding{
+			ID:       "timestamp-brin",
+			Name:     "Timestamp BRIN Candidates",
+			Severity: check.SeverityOK,
+			Details:  "No large B-tree indexes on append-only timestamp columns",
+		})
+		return
+	}
+
+	report.AddFinding(check.Finding{
+		ID:       "timestamp-brin",
+		Name:     "Timestamp BRIN Candidates",
+		Severity: check.SeverityOK,
+		Details: fmt.Sprintf("%d B-tree index(es) cover timestamp or date columns of append-only tables stored in near-perfect order. "+
+			"A BRIN index would answer the same range predicates and save about %s. "+
+			"Keep the B-tree if queries rely on it for point lookups or ORDER BY ... LIMIT, which BRIN cannot serve",
+			len(tableRows), check.FormatBytes(total)),
+		Table: &check.Table{
+			Headers: []string{"Table", "Index", "Column", "Correlation", "B-tree Size", "BRIN Size", "Saving"},
+			Rows:    tableRows,
+		},
+	})
+}
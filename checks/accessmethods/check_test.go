@@ -0,0 +1,237 @@
+package accessmethods_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/fresha/pgdoctor/check"
+	"github.com/fresha/pgdoctor/check/checktest"
+	"github.com/fresha/pgdoctor/checks/accessmethods"
+	"github.com/fresha/pgdoctor/db"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockQueryer struct {
+	hash       []db.AccessMethodHashIndexesRow
+	btree      []db.AccessMethodBtreeCandidatesRow
+	available  bool
+	versions   map[string]int32
+	brin       []db.AccessMethodBrinCandidatesRow
+	err        error
+	versionErr error
+}
+
+func (m *mockQueryer) AccessMethodHashIndexes(context.Context) ([]db.AccessMethodHashIndexesRow, error) {
+	return m.hash, m.err
+}
+
+func (m *mockQueryer) AccessMethodBtreeCandidates(context.Context) ([]db.AccessMethodBtreeCandidatesRow, error) {
+	return m.btree, nil
+}
+
+func (m *mockQueryer) AccessMethodPageinspectAvailable(context.Context) (bool, error) {
+	return m.available, nil
+}
+
+func (m *mockQueryer) AccessMethodBtreeVersion(_ context.Context, arg db.AccessMethodBtreeVersionParams) (int32, error) {
+	return m.versions[arg.IndexName], m.versionErr
+}
+
+func (m *mockQueryer) AccessMethodBrinCandidates(context.Context) ([]db.AccessMethodBrinCandidatesRow, error) {
+	return m.brin, nil
+}
+
+// statusIndex returns a 1GiB index on a low-cardinality integer column that
+// deduplication would shrink substantially.
+func statusIndex() db.AccessMethodBtreeCandidatesRow {
+	return db.AccessMethodBtreeCandidatesRow{
+		SchemaName:    "public",
+		TableName:     "orders",
+		IndexName:     "orders_status_idx",
+		ColumnName:    "status",
+		IndexBytes:    1 << 30,
+		EstimatedRows: 50000000,
+		NDistinct:     5,
+		AvgWidth:      4,
+	}
+}
+
+func TestAccessMethods_Healthy(t *testing.T) {
+	t.Parallel()
+
+	report, err := accessmethods.New(&mockQueryer{}).Check(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, check.SeverityOK, report.Severity)
+	assert.Len(t, report.Results, 3)
+}
+
+func TestAccessMethods_HashIndexes(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		valid    bool
+		expected check.Severity
+		fixes    int
+	}{
+		{name: "valid", valid: true, expected: check.SeverityOK},
+		{name: "invalid", valid: false, expected: check.SeverityFail, fixes: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			q := &mockQueryer{hash: []db.AccessMethodHashIndexesRow{
+				{SchemaName: "public", TableName: "sessions", IndexName: "sessions_token_hash", IndexBytes: 64 << 20, IsValid: tt.valid},
+			}}
+
+			report, err := accessmethods.New(q).Check(context.Background())
+			require.NoError(t, err)
+
+			f := checktest.Finding(t, report, "hash-indexes")
+			assert.Equal(t, tt.expected, f.Severity)
+			require.NotNil(t, f.Table)
+			require.Len(t, f.Fixes, tt.fixes)
+			if tt.fixes > 0 {
+				assert.Equal(t, `REINDEX INDEX CONCURRENTLY "public"."sessions_token_hash"`, f.Fixes[0].SQL)
+				assert.Equal(t, check.LockOnline, f.Fixes[0].Lock)
+			}
+		})
+	}
+}
+
+func TestAccessMethods_Deduplication(t *testing.T) {
+	t.Parallel()
+
+	unique := statusIndex()
+	unique.NDistinct = -1
+	disabled := statusIndex()
+	disabled.DedupDisabled = true
+
+	tests := []struct {
+		name      string
+		row       db.AccessMethodBtreeCandidatesRow
+		available bool
+		version   int32
+		expected  check.Severity
+		fixSQL    string
+		contains  string
+	}{
+		{
+			name:      "built before PostgreSQL 13",
+			row:       statusIndex(),
+			available: true,
+			version:   3,
+			expected:  check.SeverityWarn,
+			fixSQL:    `REINDEX INDEX CONCURRENTLY "public"."orders_status_idx"`,
+		},
+		{name: "already deduplicating", row: statusIndex(), available: true, version: 4, expected: check.SeverityOK},
+		{name: "distinct values", row: unique, available: true, version: 3, expected: check.SeverityOK},
+		{
+			name:     "deduplication off",
+			row:      disabled,
+			expected: check.SeverityWarn,
+			fixSQL:   `ALTER INDEX "public"."orders_status_idx" RESET (deduplicate_items)`,
+		},
+		{name: "pageinspect unavailable", row: statusIndex(), expected: check.SeverityOK, contains: "could not be checked"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			q := &mockQueryer{
+				btree:     []db.AccessMethodBtreeCandidatesRow{tt.row},
+				available: tt.available,
+				versions:  map[string]int32{"orders_status_idx": tt.version},
+			}
+
+			report, err := accessmethods.New(q).Check(context.Background())
+			require.NoError(t, err)
+
+			f := checktest.Finding(t, report, "btree-deduplication")
+			assert.Equal(t, tt.expected, f.Severity)
+			if tt.fixSQL != "" {
+				require.Len(t, f.Fixes, 1)
+				assert.Equal(t, tt.fixSQL, f.Fixes[0].SQL)
+				assert.Equal(t, check.RiskLow, f.Fixes[0].Risk)
+			} else {
+				assert.Empty(t, f.Fixes)
+			}
+			if tt.contains != "" {
+				assert.Contains(t, f.Details, tt.contains)
+			}
+		})
+	}
+}
+
+func TestAccessMethods_TimestampBrin(t *testing.T) {
+	t.Parallel()
+
+	const gb = int64(1 << 30)
+
+	tests := []struct {
+		name        string
+		correlation float64
+		churn       int64
+		listed      bool
+	}{
+		{name: "append-only and ordered", correlation: 0.99, listed: true},
+		{name: "descending order", correlation: -0.98, listed: true},
+		{name: "poorly ordered", correlation: 0.6},
+		{name: "updated rows", correlation: 0.99, churn: 5000000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			q := &mockQueryer{brin: []db.AccessMethodBrinCandidatesRow{{
+				SchemaName:     "public",
+				TableName:      "events",
+				IndexName:      "events_created_at_idx",
+				ColumnName:     "created_at",
+				Correlation:    tt.correlation,
+				TableBytes:     100 * gb,
+				IndexBytes:     20 * gb,
+				Inserts:        200000000,
+				UpdatesDeletes: tt.churn,
+			}}}
+
+			report, err := accessmethods.New(q).Check(context.Background())
+			require.NoError(t, err)
+
+			f := checktest.Finding(t, report, "timestamp-brin")
+			assert.Equal(t, check.SeverityOK, f.Severity)
+			if !tt.listed {
+				assert.Nil(t, f.Table)
+				return
+			}
+			require.NotNil(t, f.Table)
+			require.Len(t, f.Table.Rows, 1)
+			assert.Equal(t, "~4.6MiB", f.Table.Rows[0].Cells[5])
+			assert.Contains(t, f.Details, "save about 20.0GiB")
+		})
+	}
+}
+
+func TestAccessMethods_QueryErrors(t *testing.T) {
+	t.Parallel()
+
+	_, err := accessmethods.New(&mockQueryer{err: errors.New("connection refused")}).Check(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "access-methods")
+
+	q := &mockQueryer{
+		btree:      []db.AccessMethodBtreeCandidatesRow{statusIndex()},
+		available:  true,
+		versionErr: errors.New("permission denied"),
+	}
+	_, err = accessmethods.New(q).Check(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "public.orders_status_idx")
+}
@@ -0,0 +1,111 @@
+-- name: AccessMethodHashIndexes :many
+-- Hash indexes with their validity. pg_upgrade from before PostgreSQL 10
+-- marks hash indexes invalid because their on-disk format changed.
+SELECT
+  n.nspname::text AS schema_name
+  , t.relname::text AS table_name
+  , i.relname::text AS index_name
+  , PG_RELATION_SIZE(i.oid)::bigint AS index_bytes
+  , x.indisvalid AS is_valid
+  , COALESCE(s.idx_scan, 0)::bigint AS idx_scan
+FROM pg_index AS x
+INNER JOIN pg_class AS i ON x.indexrelid = i.oid
+INNER JOIN pg_am AS am ON i.relam = am.oid
+INNER JOIN pg_class AS t ON x.indrelid = t.oid
+INNER JOIN pg_namespace AS n ON t.relnamespace = n.oid
+LEFT JOIN pg_stat_user_indexes AS s ON x.indexrelid = s.indexrelid
+WHERE
+  am.amname = 'hash'
+  AND n.nspname NOT IN ('pg_catalog', 'information_schema')
+  AND n.nspname !~ '^pg_toast'
+ORDER BY PG_RELATION_SIZE(i.oid) DESC, i.relname;
+
+-- name: AccessMethodBtreeCandidates :many
+-- Non-unique single-column B-tree indexes of 10MiB or more on types that
+-- support deduplication, with the column statistics needed to estimate how
+-- much deduplication would shrink them.
+SELECT
+  n.nspname::text AS schema_name
+  , t.relname::text AS table_name
+  , i.relname::text AS index_name
+  , a.attname::text AS column_name
+  , PG_RELATION_SIZE(i.oid)::bigint AS index_bytes
+  , GREATEST(i.reltuples, 0)::bigint AS estimated_rows
+  , COALESCE(s.n_distinct, 0)::float8 AS n_distinct
+  , COALESCE(s.avg_width, 0)::integer AS avg_width
+  , COALESCE(ARRAY_TO_STRING(i.reloptions, ',') ~ 'deduplicate_items=(off|false|no|0)', false) AS dedup_disabled
+FROM pg_index AS x
+INNER JOIN pg_class AS i ON x.indexrelid = i.oid
+INNER JOIN pg_am AS am ON i.relam = am.oid
+INNER JOIN pg_class AS t ON x.indrelid = t.oid
+INNER JOIN pg_namespace AS n ON t.relnamespace = n.oid
+INNER JOIN pg_attribute AS a ON a.attrelid = t.oid AND a.attnum = x.indkey[0]
+INNER JOIN pg_type AS ty ON a.atttypid = ty.oid
+LEFT JOIN pg_collation AS coll ON x.indcollation[0] = coll.oid
+LEFT JOIN pg_stats AS s
+  ON s.schemaname = n.nspname AND s.tablename = t.relname AND s.attname = a.attname AND NOT s.inherited
+WHERE
+  am.amname = 'btree'
+  AND NOT x.indisunique
+  AND x.indisvalid
+  AND x.indnatts = 1
+  AND x.indkey[0] <> 0
+  AND ty.typname NOT IN ('numeric', 'float4', 'float8', 'jsonb', 'interval')
+  AND ty.typcategory NOT IN ('A', 'C')
+  AND COALESCE(coll.collisdeterministic, true)
+  AND PG_RELATION_SIZE(i.oid) >= 10 * 1024 * 1024
+  AND n.nspname NOT IN ('pg_catalog', 'information_schema')
+  AND n.nspname !~ '^pg_toast'
+ORDER BY PG_RELATION_SIZE(i.oid) DESC, i.relname
+LIMIT 100;
+
+-- name: AccessMethodPageinspectAvailable :one
+-- Checks whether pageinspect's bt_metap is installed and executable by the current role.
+SELECT EXISTS(
+  SELECT 1
+  FROM pg_proc
+  WHERE proname = 'bt_metap' AND HAS_FUNCTION_PRIVILEGE(oid, 'EXECUTE')
+) AS available;
+
+-- name: AccessMethodBtreeVersion :one
+-- Reads the B-tree metapage. Version 4 indexes (built on PostgreSQL 13 or
+-- later) can use deduplication; older versions need a REINDEX first.
+SELECT version::integer AS version
+FROM BT_METAP(FORMAT('%I.%I', sqlc.arg(schema_name)::text, sqlc.arg(index_name)::text));
+
+-- name: AccessMethodBrinCandidates :many
+-- Single-column B-tree indexes on timestamp and date columns of tables of
+-- 1GiB or more, with the physical-order correlation and write counters
+-- needed to tell append-only tables.
+SELECT
+  n.nspname::text AS schema_name
+  , t.relname::text AS table_name
+  , i.relname::text AS index_name
+  , a.attname::text AS column_name
+  , s.correlation::float8 AS correlation
+  , PG_RELATION_SIZE(t.oid)::bigint AS table_bytes
+  , PG_RELATION_SIZE(i.oid)::bigint AS index_bytes
+  , COALESCE(pst.n_tup_ins, 0)::bigint AS inserts
+  , (COALESCE(pst.n_tup_upd, 0) + COALESCE(pst.n_tup_del, 0))::bigint AS updates_deletes
+FROM pg_index AS x
+INNER JOIN pg_class AS i ON x.indexrelid = i.oid
+INNER JOIN pg_am AS am ON i.relam = am.oid
+INNER JOIN pg_class AS t ON x.indrelid = t.oid
+INNER JOIN pg_namespace AS n ON t.relnamespace = n.oid
+INNER JOIN pg_attribute AS a ON a.attrelid = t.oid AND a.attnum = x.indkey[0]
+INNER JOIN pg_stats AS s
+  ON s.schemaname = n.nspname AND s.tablename = t.relname AND s.attname = a.attname
+LEFT JOIN pg_stat_user_tables AS pst ON t.oid = pst.relid
+WHERE
+  am.amname = 'btree'
+  AND NOT x.indisunique
+  AND x.indnkeyatts = 1
+  AND x.indkey[0] <> 0
+  AND a.atttypid IN ('timestamp'::regtype, 'timestamptz'::regtype, 'date'::regtype)
+  AND s.correlation IS NOT NULL
+  AND NOT s.inherited
+  AND PG_RELATION_SIZE(t.oid) >= 1024 * 1024 * 1024
+  AND n.nspname NOT IN ('pg_catalog', 'information_schema')
+  AND n.nspname !~ '^pg_toast'
+ORDER BY PG_RELATION_SIZE(i.oid) DESC, i.relname
+LIMIT 100;
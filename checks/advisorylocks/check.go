@@ -0,0 +1,234 @@
+// Package advisorylocks implements checks for held and leaked advisory locks.
+package advisorylocks
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+
+	"github.com/fresha/pgdoctor/check"
+	"github.com/fresha/pgdoctor/db"
+)
+
+//go:embed query.sql
+var querySQL string
+
+//go:embed README.md
+var readme string
+
+const (
+	// Share of the shared lock table taken by advisory locks.
+	lockTableWarnPercent = 25.0
+	lockTableFailPercent = 50.0
+
+	// How long a holder may sit outside an active query before its locks
+	// look leaked.
+	staleHolderWarnSeconds = int64(60 * 60)      // 1h
+	staleHolderFailSeconds = int64(24 * 60 * 60) // 24h
+
+	// Advisory locks held by one session.
+	manyLocksWarn = int64(100)
+	manyLocksFail = int64(1000)
+)
+
+type AdvisoryLocksQueries interface {
+	AdvisoryLockSummary(context.Context) (db.AdvisoryLockSummaryRow, error)
+	AdvisoryLockHolders(context.Context) ([]db.AdvisoryLockHoldersRow, error)
+}
+
+type checker struct {
+	queries AdvisoryLocksQueries
+}
+
+func Metadata() check.Metadata {
+	return check.Metadata{
+		Category:       check.CategoryPerformance,
+		CheckID:        "advisory-locks",
+		Name:           "Advisory Locks",
+		Description:    "Reports held advisory locks, sessions that appear to have leaked them, and sessions holding many",
+		Readme:         readme,
+		SQL:            querySQL,
+		RuntimeClass:   check.RuntimeFast,
+		ProductionSafe: true,
+		Privileges:     []check.Privilege{check.PrivilegeReadAllStats},
+		Findings: []check.FindingSpec{
+			{ID: "advisory-lock-count", Description: "Advisory locks held cluster-wide as a share of the shared lock table", Thresholds: "WARN >= 25%, FAIL >= 50%"},
+			{ID: "stale-advisory-locks", Description: "Sessions outside an active query that have held advisory locks for a long time", Thresholds: "WARN >= 1h, FAIL >= 24h or >= 1h with sessions waiting"},
+			{ID: "advisory-lock-hoarders", Description: "Sessions holding many advisory locks at once", Thresholds: "WARN >= 100, FAIL >= 1,000"},
+		},
+	}
+}
+
+func New(queries AdvisoryLocksQueries, _ ...check.Config) check.Checker {
+	return &checker{
+		queries: queries,
+	}
+}
+
+func (c *checker) Metadata() check.Metadata {
+	return Metadata()
+}
+
+func (c *checker) Check(ctx context.Context) (*check.Report, error) {
+	report := check.NewReport(Metadata())
+
+	summary, err := c.queries.AdvisoryLockSummary(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("running %s/%s (summary): %w", report.Category, report.CheckID, err)
+	}
+
+	var holders []db.AdvisoryLockHoldersRow
+	if summary.HeldLocks > 0 {
+		holders, err = c.queries.AdvisoryLockHolders(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("running %s/%s (holders): %w", report.Category, report.CheckID, err)
+		}
+	}
+
+	checkLockCount(summary, holders, report)
+	checkStaleLocks(holders, report)
+	checkHoarders(holders, report)
+
+	return report, nil
+}
+
+func holderRow(h db.AdvisoryLockHoldersRow, severity check.Severity) check.TableRow {
+	return check.TableRow{
+		Cells: []string{
+			fmt.Sprintf("%d", h.Pid),
+			h.Username,
+			h.ApplicationName,
+			h.State,
+			check.FormatNumber(h.HeldLocks),
+			check.FormatNumber(h.WaitingSessions),
+			check.FormatDurationSec(h.StateAgeSeconds),
+		},
+		Severity: severity,
+	}
+}
+
+var holderHeaders = []string{"PID", "User", "Application", "State", "Locks", "Waiters", "Age"}
+
+func checkLockCount(summary db.AdvisoryLockSummaryRow, holders []db.AdvisoryLockHoldersRow, report *check.Report) {
+	var percent float64
+	if summary.LockTableCapacity > 0 {
+		percent = 100 * float64(summary.HeldLocks) / float64(summary.LockTableCapacity)
+	}
+
+	details := fmt.Sprintf("%s advisory lock(s) held by %s session(s), %s awaited; %.1f%% of the %s-entry lock table",
+		check.FormatNumber(summary.HeldLocks), check.FormatNumber(summary.HolderSessions),
+		check.FormatNumber(summary.WaitingLocks), percent, check.FormatNumber(summary.LockTableCapacity))
+
+	// pg_locks has no acquisition time; the holder's time in its current
+	// state is a lower bound for locks taken before that state began.
+	var oldest int64
+	for _, h := range holders {
+		oldest = max(oldest, h.StateAgeSeconds)
+	}
+	if len(holders) > 0 {
+		details += fmt.Sprintf("; oldest holder unchanged for %s", check.FormatDurationSec(oldest))
+	}
+
+	severity := check.SeverityOK
+	switch {
+	case percent >= lockTableFailPercent:
+		severity = check.SeverityFail
+	case percent >= lockTableWarnPercent:
+		severity = check.SeverityWarn
+	}
+	if severity > check.SeverityOK {
+		details += ". Advisory locks share the lock table with regular locks; when it fills, any query needing a lock fails with \"out of shared memory\""
+	}
+
+	report.AddFinding(check.Finding{
+		ID:       "advisory-lock-count",
+		Name:     "Advisory Lock Count",
+		Severity: severity,
+		Details:  details,
+	})
+}
+
+func checkStaleLocks(holders []db.AdvisoryLockHoldersRow, report *check.Report) {
+	var tableRows []check.TableRow
+	severity := check.SeverityOK
+	var oldest int64
+
+	for _, h := range holders {
+		if h.State == "active" || h.StateAgeSeconds < staleHolderWarnSeconds {
+			continue
+		}
+		rowSeverity := check.SeverityWarn
+		if h.StateAgeSeconds >= staleHolderFailSeconds || h.WaitingSessions > 0 {
+			rowSeverity = check.SeverityFail
+		}
+		severity = max(severity, rowSeverity)
+		oldest = max(oldest, h.StateAgeSeconds)
+		tableRows = append(tableRows, holderRow(h, rowSeverity))
+	}
+
+	if len(tableRows) == 0 {
+		report.AddFinding(check.Finding{
+			ID:       "stale-advisory-locks",
+			Name:     "Stale Advisory Locks",
+			Severity: check.SeverityOK,
+			Details:  fmt.Sprintf("No session has held advisory locks outside an active query for %s or more", check.FormatDurationSec(staleHolderWarnSeconds)),
+		})
+		return
+	}
+
+	report.AddFinding(check.Finding{
+		ID:       "stale-advisory-locks",
+		Name:     "Stale Advisory Locks",
+		Severity: severity,
+		Details: fmt.Sprintf("%d session(s) have held advisory locks while idle for %s or more (oldest %s). "+
+			"Session-level advisory locks survive the end of a transaction and are only released by pg_advisory_unlock or disconnect, "+
+			"so a job that crashed or forgot to unlock keeps every other worker using the same key waiting (Waiters) or skipping work. "+
+			"Terminate the holder with pg_terminate_backend(pid) once confirmed leaked",
+			len(tableRows), check.FormatDurationSec(staleHolderWarnSeconds), check.FormatDurationSec(oldest)),
+		Table: &check.Table{
+			Headers: holderHeaders,
+			Rows:    tableRows,
+		},
+	})
+}
+
+func checkHoarders(holders []db.AdvisoryLockHoldersRow, report *check.Report) {
+	var tableRows []check.TableRow
+	severity := check.SeverityOK
+
+	for _, h := range holders {
+		if h.HeldLocks < manyLocksWarn {
+			continue
+		}
+		rowSeverity := check.SeverityWarn
+		if h.HeldLocks >= manyLocksFail {
+			rowSeverity = check.SeverityFail
+		}
+		severity = max(severity, rowSeverity)
+		tableRows = append(tableRows, holderRow(h, rowSeverity))
+	}
+
+	if len(tableRows) == 0 {
+		report.AddFinding(check.Finding{
+			ID:       "advisory-lock-hoarders",
+			Name:     "Advisory Lock Hoarders",
+			Severity: check.SeverityOK,
+			Details:  fmt.Sprintf("No session holds %d or more advisory locks", manyLocksWarn),
+		})
+		return
+	}
+
+	report.AddFinding(check.Finding{
+		ID:       "advisory-lock-hoarders",
+		Name:     "Advisory Lock Hoarders",
+		Severity: severity,
+		Details: fmt.Sprintf("%d session(s) hold %d or more advisory locks. This usually means a lock is taken per item in a loop and never released, "+
+			"or pg_advisory_lock is called repeatedly for the same key (each call stacks and needs its own unlock). "+
+			"Use transaction-level pg_advisory_xact_lock where possible so locks are released at commit",
+			len(tableRows), manyLocksWarn),
+		Table: &check.Table{
+			Headers: holderHeaders,
+			Rows:    tableRows,
+		},
+	})
+}
@@ -0,0 +1,175 @@
+package advisorylocks_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/fresha/pgdoctor/check"
+	"github.com/fresha/pgdoctor/check/checktest"
+	"github.com/fresha/pgdoctor/checks/advisorylocks"
+	"github.com/fresha/pgdoctor/db"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockQueryer struct {
+	summary db.AdvisoryLockSummaryRow
+	holders []db.AdvisoryLockHoldersRow
+	err     error
+
+	holdersCalled bool
+}
+
+func (m *mockQueryer) AdvisoryLockSummary(context.Context) (db.AdvisoryLockSummaryRow, error) {
+	return m.summary, m.err
+}
+
+func (m *mockQueryer) AdvisoryLockHolders(context.Context) ([]db.AdvisoryLockHoldersRow, error) {
+	m.holdersCalled = true
+	return m.holders, nil
+}
+
+func withHolders(holders ...db.AdvisoryLockHoldersRow) *mockQueryer {
+	var held int64
+	for _, h := range holders {
+		held += h.HeldLocks
+	}
+	return &mockQueryer{
+		summary: db.AdvisoryLockSummaryRow{HeldLocks: held, HolderSessions: int64(len(holders)), LockTableCapacity: 64 * 110},
+		holders: holders,
+	}
+}
+
+func TestAdvisoryLocks_NoLocks(t *testing.T) {
+	t.Parallel()
+
+	q := &mockQueryer{summary: db.AdvisoryLockSummaryRow{LockTableCapacity: 64 * 110}}
+	report, err := advisorylocks.New(q).Check(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, check.SeverityOK, report.Severity)
+	assert.Len(t, report.Results, 3)
+	assert.False(t, q.holdersCalled, "holders are only listed when locks are held")
+}
+
+func TestAdvisoryLocks_LockCount(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		held     int64
+		expected check.Severity
+	}{
+		{name: "few", held: 10, expected: check.SeverityOK},
+		{name: "quarter of lock table", held: 1760, expected: check.SeverityWarn},
+		{name: "half of lock table", held: 3520, expected: check.SeverityFail},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			q := &mockQueryer{summary: db.AdvisoryLockSummaryRow{HeldLocks: tt.held, HolderSessions: 1, LockTableCapacity: 64 * 110}}
+			report, err := advisorylocks.New(q).Check(context.Background())
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.expected, checktest.Finding(t, report, "advisory-lock-count").Severity)
+		})
+	}
+}
+
+func TestAdvisoryLocks_OldestHolder(t *testing.T) {
+	t.Parallel()
+
+	q := withHolders(
+		db.AdvisoryLockHoldersRow{Pid: 1, State: "active", HeldLocks: 1, StateAgeSeconds: 5},
+		db.AdvisoryLockHoldersRow{Pid: 2, State: "idle", HeldLocks: 1, StateAgeSeconds: 7200},
+	)
+	report, err := advisorylocks.New(q).Check(context.Background())
+	require.NoError(t, err)
+
+	assert.Contains(t, checktest.Finding(t, report, "advisory-lock-count").Details, "oldest holder unchanged for 2h")
+}
+
+func TestAdvisoryLocks_StaleLocks(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		holder   db.AdvisoryLockHoldersRow
+		expected check.Severity
+	}{
+		{name: "recently idle", holder: db.AdvisoryLockHoldersRow{State: "idle", HeldLocks: 1, StateAgeSeconds: 60}, expected: check.SeverityOK},
+		{name: "long running query", holder: db.AdvisoryLockHoldersRow{State: "active", HeldLocks: 1, StateAgeSeconds: 2 * 86400}, expected: check.SeverityOK},
+		{name: "idle for hours", holder: db.AdvisoryLockHoldersRow{State: "idle", HeldLocks: 1, StateAgeSeconds: 3 * 3600}, expected: check.SeverityWarn},
+		{name: "idle for days", holder: db.AdvisoryLockHoldersRow{State: "idle", HeldLocks: 1, StateAgeSeconds: 2 * 86400}, expected: check.SeverityFail},
+		{name: "idle with waiters", holder: db.AdvisoryLockHoldersRow{State: "idle in transaction", HeldLocks: 1, WaitingSessions: 3, StateAgeSeconds: 3600}, expected: check.SeverityFail},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			tt.holder.Pid = 4242
+			tt.holder.Username = "jobs"
+			report, err := advisorylocks.New(withHolders(tt.holder)).Check(context.Background())
+			require.NoError(t, err)
+
+			f := checktest.Finding(t, report, "stale-advisory-locks")
+			assert.Equal(t, tt.expected, f.Severity)
+			if tt.expected != check.SeverityOK {
+				require.NotNil(t, f.Table)
+				require.Len(t, f.Table.Rows, 1)
+				assert.Equal(t, "4242", f.Table.Rows[0].Cells[0])
+				assert.Equal(t, "jobs", f.Table.Rows[0].Cells[1])
+			}
+		})
+	}
+}
+
+func TestAdvisoryLocks_Hoarders(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		held     int64
+		expected check.Severity
+	}{
+		{name: "few", held: 5, expected: check.SeverityOK},
+		{name: "hundreds", held: 250, expected: check.SeverityWarn},
+		{name: "thousands", held: 1500, expected: check.SeverityFail},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			q := withHolders(db.AdvisoryLockHoldersRow{Pid: 1, State: "active", HeldLocks: tt.held})
+			report, err := advisorylocks.New(q).Check(context.Background())
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.expected, checktest.Finding(t, report, "advisory-lock-hoarders").Severity)
+		})
+	}
+}
+
+func TestAdvisoryLocks_QueryError(t *testing.T) {
+	t.Parallel()
+
+	q := &mockQueryer{err: errors.New("connection refused")}
+
+	_, err := advisorylocks.New(q).Check(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "advisory-locks")
+}
+
+func TestMetadata(t *testing.T) {
+	t.Parallel()
+
+	meta := advisorylocks.Metadata()
+	assert.Equal(t, "advisory-locks", meta.CheckID)
+	assert.Equal(t, check.CategoryPerformance, meta.Category)
+	assert.NotEmpty(t, meta.SQL)
+	assert.NotEmpty(t, meta.Readme)
+}
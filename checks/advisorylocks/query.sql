@@ -0,0 +1,48 @@
+-- name: AdvisoryLockSummary :one
+-- Granted and awaited advisory locks across the cluster, and the size of the
+-- shared lock table they are allocated from.
+SELECT
+  COUNT(*) FILTER (WHERE l.granted)::bigint AS held_locks
+  , COUNT(*) FILTER (WHERE NOT l.granted)::bigint AS waiting_locks
+  , COUNT(DISTINCT l.pid) FILTER (WHERE l.granted)::bigint AS holder_sessions
+  , (
+    current_setting('max_locks_per_transaction')::bigint
+    * (current_setting('max_connections')::bigint + current_setting('max_prepared_transactions')::bigint)
+  )::bigint AS lock_table_capacity
+FROM pg_locks AS l
+WHERE l.locktype = 'advisory';
+
+-- name: AdvisoryLockHolders :many
+-- Sessions holding advisory locks, with how many sessions are queued behind
+-- them and how long they have been in their current state.
+WITH held AS (
+  SELECT
+    pid
+    , COUNT(*) AS held_locks
+  FROM pg_locks
+  WHERE locktype = 'advisory' AND granted AND pid IS NOT NULL
+  GROUP BY pid
+)
+
+, waiters AS (
+  SELECT
+    w.pid AS waiter
+    , UNNEST(PG_BLOCKING_PIDS(w.pid)) AS blocker
+  FROM pg_locks AS w
+  WHERE w.locktype = 'advisory' AND NOT w.granted
+)
+
+SELECT
+  h.pid::integer AS pid
+  , COALESCE(a.usename, '')::text AS username
+  , COALESCE(a.datname, '')::text AS database_name
+  , COALESCE(a.application_name, '')::text AS application_name
+  , COALESCE(a.state, '')::text AS state
+  , h.held_locks::bigint AS held_locks
+  , (SELECT COUNT(DISTINCT wt.waiter) FROM waiters AS wt WHERE wt.blocker = h.pid)::bigint AS waiting_sessions
+  , COALESCE(EXTRACT(EPOCH FROM NOW() - a.state_change), 0)::bigint AS state_age_seconds
+  , COALESCE(EXTRACT(EPOCH FROM NOW() - a.backend_start), 0)::bigint AS session_age_seconds
+FROM held AS h
+LEFT JOIN pg_stat_activity AS a ON h.pid = a.pid
+ORDER BY h.held_locks DESC, state_age_seconds DESC
+LIMIT 100;
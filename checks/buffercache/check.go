@@ -0,0 +1,303 @@
+// Package buffercache implements checks for shared buffer composition using pg_buffercache.
+package buffercache
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"regexp"
+	"slices"
+
+	"github.com/fresha/pgdoctor/check"
+	"github.com/fresha/pgdoctor/db"
+)
+
+//go:embed query.sql
+var querySQL string
+
+//go:embed README.md
+var readme string
+
+const (
+	// Share of occupied buffers that are dirty.
+	dirtyWarnPercent = 20.0
+	dirtyFailPercent = 40.0
+
+	// A cache this full whose occupied buffers are mostly at usage count 0-1
+	// is evicting pages before they are reused.
+	fullCachePercent     = 95.0
+	lowUsageWarnPercent  = 60.0
+	lowValueAvgUsage     = 1.5
+	lowValueWarnPercent  = 20.0 // share of shared_buffers held by one low-value relation
+	lowValueFailPercent  = 40.0
+	compositionTableSize = 10
+)
+
+// logLikeName matches relations that usually hold append-only history rather
+// than a working set.
+var logLikeName = regexp.MustCompile(`(?i)(^|[._])(logs?|audits?|audit_log|events?|history|histories|archives?|journal)(_|$)`)
+
+type BufferCacheQueries interface {
+	BufferCacheAvailable(context.Context) (bool, error)
+	BufferCacheSummary(context.Context) (db.BufferCacheSummaryRow, error)
+	BufferCacheRelations(context.Context) ([]db.BufferCacheRelationsRow, error)
+}
+
+type checker struct {
+	queries BufferCacheQueries
+}
+
+func Metadata() check.Metadata {
+	return check.Metadata{
+		Category:       check.CategoryPerformance,
+		CheckID:        "buffer-cache",
+		Name:           "Buffer Cache",
+		Description:    "Summarizes shared_buffers by relation, dirty ratio, and usage count using pg_buffercache",
+		Readme:         readme,
+		SQL:            querySQL,
+		RuntimeClass:   check.RuntimeMedium,
+		ProductionSafe: false, // pg_buffercache cost grows with shared_buffers
+		Privileges:     []check.Privilege{check.PrivilegeMonitor},
+		Findings: []check.FindingSpec{
+			{ID: "cache-composition", Description: "Relations holding the most shared buffers"},
+			{ID: "dirty-buffers", Description: "Share of occupied shared buffers waiting to be written", Thresholds: "WARN >= 20%, FAIL >= 40%"},
+			{ID: "usage-distribution", Description: "Usage-count distribution of occupied buffers; a full cache of rarely reused pages is churning", Thresholds: "WARN >= 60% at usage count 0-1 with the cache >= 95% full"},
+			{ID: "low-value-relations", Description: "A rarely reused or log-like relation occupying a large share of shared_buffers", Thresholds: "WARN >= 20%, FAIL >= 40% of shared_buffers"},
+		},
+	}
+}
+
+func New(queries BufferCacheQueries, _ ...check.Config) check.Checker {
+	return &checker{
+		queries: queries,
+	}
+}
+
+func (c *checker) Metadata() check.Metadata {
+	return Metadata()
+}
+
+func (c *checker) Check(ctx context.Context) (*check.Report, error) {
+	report := check.NewReport(Metadata())
+
+	available, err := c.queries.BufferCacheAvailable(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("running %s/%s (extension): %w", report.Category, report.CheckID, err)
+	}
+	if !available {
+		for _, spec := range Metadata().Findings {
+			report.AddFinding(check.Finding{
+				ID:       spec.ID,
+				Name:     findingName(spec.ID),
+				Severity: check.SeverityOK,
+				Details:  "Inspecting shared buffers needs the pg_buffercache extension, which is not installed",
+			})
+		}
+		return report, nil
+	}
+
+	summary, err := c.queries.BufferCacheSummary(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("running %s/%s (summary): %w", report.Category, report.CheckID, err)
+	}
+
+	relations, err := c.queries.BufferCacheRelations(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("running %s/%s (relations): %w", report.Category, report.CheckID, err)
+	}
+	ignore := check.ObjectFilterFromContext(ctx)
+	relations = slices.DeleteFunc(relations, func(row db.BufferCacheRelationsRow) bool {
+		if row.Relkind == "i" || row.Relkind == "I" {
+			return ignore.QualifiedIndex(row.RelationName)
+		}
+		return ignore.QualifiedTable(row.RelationName)
+	})
+
+	checkComposition(summary, relations, report)
+	checkDirtyBuffers(summary, report)
+	checkUsageDistribution(summary, report)
+	checkLowValueRelations(summary, relations, report)
+
+	return report, nil
+}
+
+func findingName(id string) string {
+	switch id {
+	case "cache-composition":
+		return "Cache Composition"
+	case "dirty-buffers":
+		return "Dirty Buffers"
+	case "usage-distribution":
+		return "Usage Distribution"
+	case "low-value-relations":
+		return "Low-Value Relations"
+	default:
+		return id
+	}
+}
+
+func percentOf(part, whole int64) float64 {
+	if whole == 0 {
+		return 0
+	}
+	return 100 * float64(part) / float64(whole)
+}
+
+func relkindName(relkind string) string {
+	switch relkind {
+	case "r", "p":
+		return "table"
+	case "i", "I":
+		return "index"
+	case "t":
+		return "toast"
+	case "m":
+		return "matview"
+	case "S":
+		return "sequence"
+	default:
+		return relkind
+	}
+}
+
+func relationRow(r db.BufferCacheRelationsRow, summary db.BufferCacheSummaryRow, severity check.Severity) check.TableRow {
+	cachedBytes := r.Buffers * summary.BlockSize
+	return check.TableRow{
+		Cells: []string{
+			r.RelationName,
+			relkindName(r.Relkind),
+			check.FormatBytes(cachedBytes),
+			fmt.Sprintf("%.1f%%", percentOf(r.Buffers, summary.TotalBuffers)),
+			fmt.Sprintf("%.0f%%", min(100, percentOf(cachedBytes, r.RelationBytes))),
+			check.FormatNumber(r.DirtyBuffers),
+			fmt.Sprintf("%.1f", r.AvgUsageCount),
+		},
+		Severity: severity,
+	}
+}
+
+var relationHeaders = []string{"Relation", "Kind", "Cached", "Of Cache", "Of Relation", "Dirty", "Avg Usage"}
+
+func checkComposition(summary db.BufferCacheSummaryRow, relations []db.BufferCacheRelationsRow, report *check.Report) {
+	details := fmt.Sprintf("%s of %s shared buffers in use (%.1f%%)",
+		check.FormatNumber(summary.UsedBuffers), check.FormatNumber(summary.TotalBuffers),
+		percentOf(summary.UsedBuffers, summary.TotalBuffers))
+
+	finding := check.Finding{
+		ID:       "cache-composition",
+		Name:     "Cache Composition",
+		Severity: check.SeverityOK,
+		Details:  details,
+	}
+
+	if len(relations) > 0 {
+		var top int64
+		rows := make([]check.TableRow, 0, compositionTableSize)
+		for i, r := range relations {
+			if i < 5 {
+				top += r.Buffers
+			}
+			if i < compositionTableSize {
+				rows = append(rows, relationRow(r, summary, check.SeverityOK))
+			}
+		}
+		finding.Details += fmt.Sprintf("; the top %d relations of this database hold %.1f%% of the cache",
+			min(5, len(relations)), percentOf(top, summary.TotalBuffers))
+		finding.Table = &check.Table{Headers: relationHeaders, Rows: rows}
+	}
+
+	report.AddFinding(finding)
+}
+
+func checkDirtyBuffers(summary db.BufferCacheSummaryRow, report *check.Report) {
+	percent := percentOf(summary.DirtyBuffers, summary.UsedBuffers)
+	details := fmt.Sprintf("%s of %s occupied buffers are dirty (%.1f%%)",
+		check.FormatNumber(summary.DirtyBuffers), check.FormatNumber(summary.UsedBuffers), percent)
+
+	severity := check.SeverityOK
+	switch {
+	case percent >= dirtyFailPercent:
+		severity = check.SeverityFail
+	case percent >= dirtyWarnPercent:
+		severity = check.SeverityWarn
+	}
+	if severity > check.SeverityOK {
+		details += ". Backends that need a clean buffer must write dirty pages themselves, and the next checkpoint has that much more to flush. " +
+			"Make the background writer more aggressive (bgwriter_lru_maxpages, bgwriter_lru_multiplier) or spread checkpoints " +
+			"(checkpoint_completion_target, max_wal_size)"
+	}
+
+	report.AddFinding(check.Finding{
+		ID:       "dirty-buffers",
+		Name:     "Dirty Buffers",
+		Severity: severity,
+		Details:  details,
+	})
+}
+
+func checkUsageDistribution(summary db.BufferCacheSummaryRow, report *check.Report) {
+	lowPercent := percentOf(summary.UsageLow, summary.UsedBuffers)
+	fullPercent := percentOf(summary.UsedBuffers, summary.TotalBuffers)
+	details := fmt.Sprintf("Usage count of occupied buffers: 0-1 %.1f%%, 2-3 %.1f%%, 4-5 %.1f%%",
+		lowPercent, percentOf(summary.UsageMid, summary.UsedBuffers), percentOf(summary.UsageHigh, summary.UsedBuffers))
+
+	severity := check.SeverityOK
+	if fullPercent >= fullCachePercent && lowPercent >= lowUsageWarnPercent {
+		severity = check.SeverityWarn
+		details += fmt.Sprintf(". The cache is %.1f%% full and most pages are evicted before being reused, so reads keep going to the OS cache or disk. "+
+			"Look for queries reading wide ranges of cold data through indexes, or consider a larger shared_buffers", fullPercent)
+	}
+
+	report.AddFinding(check.Finding{
+		ID:       "usage-distribution",
+		Name:     "Usage Distribution",
+		Severity: severity,
+		Details:  details,
+	})
+}
+
+func checkLowValueRelations(summary db.BufferCacheSummaryRow, relations []db.BufferCacheRelationsRow, report *check.Report) {
+	var tableRows []check.TableRow
+	severity := check.SeverityOK
+
+	for _, r := range relations {
+		share := percentOf(r.Buffers, summary.TotalBuffers)
+		if share < lowValueWarnPercent {
+			continue
+		}
+		if r.AvgUsageCount >= lowValueAvgUsage && !logLikeName.MatchString(r.RelationName) {
+			continue
+		}
+		rowSeverity := check.SeverityWarn
+		if share >= lowValueFailPercent {
+			rowSeverity = check.SeverityFail
+		}
+		severity = max(severity, rowSeverity)
+		tableRows = append(tableRows, relationRow(r, summary, rowSeverity))
+	}
+
+	if len(tableRows) == 0 {
+		report.AddFinding(check.Finding{
+			ID:       "low-value-relations",
+			Name:     "Low-Value Relations",
+			Severity: check.SeverityOK,
+			Details:  fmt.Sprintf("No rarely reused or log-like relation holds %.0f%% or more of shared_buffers", lowValueWarnPercent),
+		})
+		return
+	}
+
+	report.AddFinding(check.Finding{
+		ID:       "low-value-relations",
+		Name:     "Low-Value Relations",
+		Severity: severity,
+		Details: fmt.Sprintf("%d relation(s) with a low average usage count or a log-like name hold %.0f%% or more of shared_buffers, "+
+			"crowding out the working set of hot tables and indexes. Find the queries scanning them (pg_stat_statements), "+
+			"move history to a partitioned or separate store, or drop unused indexes on them",
+			len(tableRows), lowValueWarnPercent),
+		Table: &check.Table{
+			Headers: relationHeaders,
+			Rows:    tableRows,
+		},
+		Confidence: check.ConfidenceMedium,
+	})
+}
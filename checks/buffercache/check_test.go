@@ -0,0 +1,185 @@
+package buffercache_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/fresha/pgdoctor/check"
+	"github.com/fresha/pgdoctor/check/checktest"
+	"github.com/fresha/pgdoctor/checks/buffercache"
+	"github.com/fresha/pgdoctor/db"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockQueryer struct {
+	available bool
+	summary   db.BufferCacheSummaryRow
+	relations []db.BufferCacheRelationsRow
+	err       error
+
+	summaryCalled bool
+}
+
+func (m *mockQueryer) BufferCacheAvailable(context.Context) (bool, error) {
+	return m.available, m.err
+}
+
+func (m *mockQueryer) BufferCacheSummary(context.Context) (db.BufferCacheSummaryRow, error) {
+	m.summaryCalled = true
+	return m.summary, nil
+}
+
+func (m *mockQueryer) BufferCacheRelations(context.Context) ([]db.BufferCacheRelationsRow, error) {
+	return m.relations, nil
+}
+
+// healthy is a 16,384-buffer (128MB) cache, 80% used, mostly hot and clean.
+func healthy() db.BufferCacheSummaryRow {
+	return db.BufferCacheSummaryRow{
+		TotalBuffers: 16384,
+		UsedBuffers:  13107,
+		DirtyBuffers: 500,
+		UsageLow:     2000,
+		UsageMid:     4000,
+		UsageHigh:    7107,
+		BlockSize:    8192,
+	}
+}
+
+func TestBufferCache_ExtensionMissing(t *testing.T) {
+	t.Parallel()
+
+	q := &mockQueryer{}
+	report, err := buffercache.New(q).Check(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, check.SeverityOK, report.Severity)
+	assert.Len(t, report.Results, len(buffercache.Metadata().Findings))
+	assert.Contains(t, report.Results[0].Details, "pg_buffercache")
+	assert.False(t, q.summaryCalled)
+}
+
+func TestBufferCache_Healthy(t *testing.T) {
+	t.Parallel()
+
+	q := &mockQueryer{
+		available: true,
+		summary:   healthy(),
+		relations: []db.BufferCacheRelationsRow{
+			{RelationName: "public.orders", Relkind: "r", Buffers: 4096, DirtyBuffers: 100, AvgUsageCount: 4.2, RelationBytes: 64 << 20},
+			{RelationName: "public.orders_pkey", Relkind: "i", Buffers: 2048, AvgUsageCount: 4.8, RelationBytes: 16 << 20},
+		},
+	}
+	report, err := buffercache.New(q).Check(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, check.SeverityOK, report.Severity)
+
+	composition := checktest.Finding(t, report, "cache-composition")
+	assert.Contains(t, composition.Details, "13.1K of 16.4K shared buffers in use (80.0%)")
+	assert.Contains(t, composition.Details, "top 2 relations of this database hold 37.5% of the cache")
+	require.Len(t, composition.Table.Rows, 2)
+	assert.Equal(t, []string{"public.orders", "table", "32.0MiB", "25.0%", "50%", "100", "4.2"}, composition.Table.Rows[0].Cells)
+	assert.Equal(t, "index", composition.Table.Rows[1].Cells[1])
+}
+
+func TestBufferCache_DirtyBuffers(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		dirty    int64
+		expected check.Severity
+	}{
+		{name: "clean", dirty: 500, expected: check.SeverityOK},
+		{name: "20 percent", dirty: 2622, expected: check.SeverityWarn},
+		{name: "40 percent", dirty: 5243, expected: check.SeverityFail},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			summary := healthy()
+			summary.DirtyBuffers = tt.dirty
+			report, err := buffercache.New(&mockQueryer{available: true, summary: summary}).Check(context.Background())
+			require.NoError(t, err)
+
+			f := checktest.Finding(t, report, "dirty-buffers")
+			assert.Equal(t, tt.expected, f.Severity)
+			if tt.expected > check.SeverityOK {
+				assert.Contains(t, f.Details, "bgwriter_lru_maxpages")
+			}
+		})
+	}
+}
+
+func TestBufferCache_UsageDistribution(t *testing.T) {
+	t.Parallel()
+
+	churning := db.BufferCacheSummaryRow{
+		TotalBuffers: 16384,
+		UsedBuffers:  16384,
+		UsageLow:     12000,
+		UsageMid:     3000,
+		UsageHigh:    1384,
+		BlockSize:    8192,
+	}
+	report, err := buffercache.New(&mockQueryer{available: true, summary: churning}).Check(context.Background())
+	require.NoError(t, err)
+	f := checktest.Finding(t, report, "usage-distribution")
+	assert.Equal(t, check.SeverityWarn, f.Severity)
+	assert.Contains(t, f.Details, "0-1 73.2%")
+
+	notFull := churning
+	notFull.UsedBuffers, notFull.UsageLow = 8000, 6000
+	report, err = buffercache.New(&mockQueryer{available: true, summary: notFull}).Check(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, check.SeverityOK, checktest.Finding(t, report, "usage-distribution").Severity,
+		"cold pages in a cache with free space are not churn")
+}
+
+func TestBufferCache_LowValueRelations(t *testing.T) {
+	t.Parallel()
+
+	q := &mockQueryer{
+		available: true,
+		summary:   healthy(),
+		relations: []db.BufferCacheRelationsRow{
+			{RelationName: "public.request_log", Relkind: "r", Buffers: 7000, AvgUsageCount: 3.5, RelationBytes: 4 << 30},
+			{RelationName: "public.orders", Relkind: "r", Buffers: 4000, AvgUsageCount: 4.5, RelationBytes: 64 << 20},
+			{RelationName: "public.snapshots", Relkind: "r", Buffers: 3500, AvgUsageCount: 1.0, RelationBytes: 1 << 30},
+		},
+	}
+	report, err := buffercache.New(q).Check(context.Background())
+	require.NoError(t, err)
+
+	f := checktest.Finding(t, report, "low-value-relations")
+	assert.Equal(t, check.SeverityFail, f.Severity)
+	assert.Equal(t, check.ConfidenceMedium, f.Confidence)
+	require.Len(t, f.Table.Rows, 2, "hot relations are not flagged however large")
+	assert.Equal(t, "public.request_log", f.Table.Rows[0].Cells[0])
+	assert.Equal(t, check.SeverityFail, f.Table.Rows[0].Severity)
+	assert.Equal(t, "public.snapshots", f.Table.Rows[1].Cells[0])
+	assert.Equal(t, check.SeverityWarn, f.Table.Rows[1].Severity)
+}
+
+func TestBufferCache_QueryError(t *testing.T) {
+	t.Parallel()
+
+	_, err := buffercache.New(&mockQueryer{err: errors.New("permission denied for function pg_buffercache_pages")}).Check(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "performance/buffer-cache")
+}
+
+func TestMetadata(t *testing.T) {
+	t.Parallel()
+
+	meta := buffercache.Metadata()
+	assert.Equal(t, "buffer-cache", meta.CheckID)
+	assert.Equal(t, check.CategoryPerformance, meta.Category)
+	assert.NotEmpty(t, meta.Readme)
+	assert.NotEmpty(t, meta.SQL)
+}
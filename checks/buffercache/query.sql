@@ -0,0 +1,46 @@
+-- name: BufferCacheAvailable :one
+-- Checks if the pg_buffercache extension is installed in this database.
+SELECT EXISTS(
+  SELECT 1 FROM pg_extension
+  WHERE extname = 'pg_buffercache'
+) AS available;
+
+-- name: BufferCacheSummary :one
+-- Shared buffer occupancy, dirty buffers, and the usage-count distribution
+-- of occupied buffers across the whole cluster.
+SELECT
+  COUNT(*)::bigint AS total_buffers
+  , COUNT(*) FILTER (WHERE b.relfilenode IS NOT NULL)::bigint AS used_buffers
+  , COUNT(*) FILTER (WHERE b.isdirty)::bigint AS dirty_buffers
+  , COUNT(*) FILTER (WHERE b.usagecount <= 1)::bigint AS usage_low
+  , COUNT(*) FILTER (WHERE b.usagecount BETWEEN 2 AND 3)::bigint AS usage_mid
+  , COUNT(*) FILTER (WHERE b.usagecount >= 4)::bigint AS usage_high
+  , CURRENT_SETTING('block_size')::bigint AS block_size
+FROM pg_buffercache AS b;
+
+-- name: BufferCacheRelations :many
+-- Relations of the current database (and shared catalogs) holding the most
+-- shared buffers, with their dirty buffers and average usage count.
+WITH cached AS (
+  SELECT
+    b.relfilenode
+    , COUNT(*) AS buffers
+    , COUNT(*) FILTER (WHERE b.isdirty) AS dirty
+    , AVG(b.usagecount) AS avg_usage
+  FROM pg_buffercache AS b
+  WHERE b.reldatabase IN (0, (SELECT d.oid FROM pg_database AS d WHERE d.datname = CURRENT_DATABASE()))
+  GROUP BY b.relfilenode
+)
+
+SELECT
+  (n.nspname || '.' || c.relname)::text AS relation_name
+  , c.relkind::text AS relkind
+  , cached.buffers::bigint AS buffers
+  , cached.dirty::bigint AS dirty_buffers
+  , COALESCE(cached.avg_usage, 0)::float8 AS avg_usage_count
+  , PG_RELATION_SIZE(c.oid)::bigint AS relation_bytes
+FROM cached
+INNER JOIN pg_class AS c ON PG_RELATION_FILENODE(c.oid) = cached.relfilenode
+INNER JOIN pg_namespace AS n ON c.relnamespace = n.oid
+ORDER BY cached.buffers DESC
+LIMIT 20;
@@ -19,10 +19,28 @@ var readme string
 const (
 	cacheLowThreshold  = 90.0
 	cacheWarnThreshold = 95.0
+
+	// Average time client backends wait for a relation read that missed
+	// shared_buffers. Reads served by the OS page cache pull the average
+	// down, so a high one means storage itself is slow or saturated.
+	readLatencyWarnMs = 5.0
+	readLatencyFailMs = 20.0
+	// Too few reads since the stats reset to judge latency.
+	minTimedReads = 10000
+
+	// pg_stat_io, and with it read_time per backend type, appeared in 16.
+	ioStatsMinVersion = 160000
+	// PostgreSQL 18 replaced op_bytes with read_bytes and added per-backend
+	// I/O statistics.
+	backendIOMinVersion = 180000
 )
 
 type CacheEfficiencyQueries interface {
 	DatabaseCacheEfficiency(context.Context) (db.DatabaseCacheEfficiencyRow, error)
+	IOTimingSettings(context.Context) (db.IOTimingSettingsRow, error)
+	IOTiming(context.Context) (db.IOTimingRow, error)
+	LegacyIOTiming(context.Context) (db.LegacyIOTimingRow, error)
+	BackendIOTiming(context.Context) ([]db.BackendIOTimingRow, error)
 }
 
 type checker struct {
@@ -31,12 +49,18 @@ type checker struct {
 
 func Metadata() check.Metadata {
 	return check.Metadata{
-		Category:    check.CategoryPerformance,
-		CheckID:     "cache-efficiency",
-		Name:        "Cache Efficiency",
-		Description: "Analyzes database-wide buffer cache hit ratio",
-		Readme:      readme,
-		SQL:         querySQL,
+		Category:       check.CategoryPerformance,
+		CheckID:        "cache-efficiency",
+		Name:           "Cache Efficiency",
+		Description:    "Analyzes database-wide buffer cache hit ratio and how long reads that miss it take",
+		Readme:         readme,
+		SQL:            querySQL,
+		RuntimeClass:   check.RuntimeFast,
+		ProductionSafe: true,
+		Findings: []check.FindingSpec{
+			{ID: "cache-hit-ratio", Description: "Database-wide buffer cache hit ratio", Thresholds: "WARN < 95%, FAIL < 90%"},
+			{ID: "io-timing", Description: "Average time client backends wait for relation reads that miss shared_buffers, with the slowest sessions on PostgreSQL 18+ (needs PostgreSQL 16+, track_io_timing, and 10,000 reads)", Thresholds: "WARN >= 5ms, FAIL >= 20ms"},
+		},
 	}
 }
 
@@ -60,9 +84,41 @@ func (c *checker) Check(ctx context.Context) (*check.Report, error) {
 
 	checkCacheHitRatio(row, report)
 
+	settings, err := c.queries.IOTimingSettings(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("running %s/%s (io settings): %w", report.Category, report.CheckID, err)
+	}
+	if settings.ServerVersionNum < ioStatsMinVersion || !settings.TrackIoTiming {
+		checkIOTiming(settings, db.IOTimingRow{}, nil, report)
+		return report, nil
+	}
+
+	timing, err := c.fetchIOTiming(ctx, settings.ServerVersionNum)
+	if err != nil {
+		return nil, fmt.Errorf("running %s/%s (io timing): %w", report.Category, report.CheckID, err)
+	}
+	var backends []db.BackendIOTimingRow
+	if settings.ServerVersionNum >= backendIOMinVersion {
+		backends, err = c.queries.BackendIOTiming(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("running %s/%s (backend io): %w", report.Category, report.CheckID, err)
+		}
+	}
+	checkIOTiming(settings, timing, backends, report)
+
 	return report, nil
 }
 
+// Reads pg_stat_io with read_bytes on PG18+ and derives bytes from op_bytes
+// on PG16 and 17.
+func (c *checker) fetchIOTiming(ctx context.Context, serverVersion int32) (db.IOTimingRow, error) {
+	if serverVersion >= backendIOMinVersion {
+		return c.queries.IOTiming(ctx)
+	}
+	row, err := c.queries.LegacyIOTiming(ctx)
+	return db.IOTimingRow(row), err
+}
+
 func checkCacheHitRatio(row db.DatabaseCacheEfficiencyRow, report *check.Report) {
 	if !row.CacheHitRatio.Valid {
 		report.AddFinding(check.Finding{
@@ -102,3 +158,72 @@ func checkCacheHitRatio(row db.DatabaseCacheEfficiencyRow, report *check.Report)
 		Details:  details,
 	})
 }
+
+func checkIOTiming(settings db.IOTimingSettingsRow, timing db.IOTimingRow, backends []db.BackendIOTimingRow, report *check.Report) {
+	finding := check.Finding{
+		ID:       "io-timing",
+		Name:     "I/O Timing",
+		Severity: check.SeverityOK,
+	}
+
+	switch {
+	case settings.ServerVersionNum < ioStatsMinVersion:
+		finding.Details = "Read latency comes from pg_stat_io, which needs PostgreSQL 16+"
+		report.AddFinding(finding)
+		return
+	case !settings.TrackIoTiming:
+		finding.Details = "track_io_timing is off, so read latency is not measured. " +
+			"Turn it on to see how long queries wait for storage; pg_test_timing shows its overhead on this host."
+		report.AddFinding(finding)
+		return
+	case timing.Reads < minTimedReads:
+		finding.Details = fmt.Sprintf("Only %s relation read(s) by client backends since the stats reset; not enough to judge read latency",
+			check.FormatNumber(timing.Reads))
+		report.AddFinding(finding)
+		return
+	}
+
+	avgMs := timing.ReadTimeMs / float64(timing.Reads)
+	finding.Details = fmt.Sprintf("Client backends read %s block(s) (%s) that missed shared_buffers over %s, averaging %.2fms per read (%s waiting in total)",
+		check.FormatNumber(timing.Reads), check.FormatBytes(timing.ReadBytes),
+		check.FormatDurationSec(timing.SecondsSinceReset), avgMs, check.FormatDurationMs(timing.ReadTimeMs))
+
+	if avgMs >= readLatencyFailMs {
+		finding.Severity = check.SeverityFail
+	} else if avgMs >= readLatencyWarnMs {
+		finding.Severity = check.SeverityWarn
+	}
+	if finding.Severity == check.SeverityOK {
+		report.AddFinding(finding)
+		return
+	}
+
+	finding.Details += ". Reads this slow usually mean storage is at its IOPS or throughput limit, or is network-attached with high latency; " +
+		"compare with the volume's provisioned performance, and raise the cache hit ratio so fewer reads reach it."
+	if len(backends) > 0 {
+		rows := make([]check.TableRow, 0, len(backends))
+		for _, b := range backends {
+			rowSeverity := check.SeverityOK
+			if b.Reads > 0 {
+				switch ms := b.ReadTimeMs / float64(b.Reads); {
+				case ms >= readLatencyFailMs:
+					rowSeverity = check.SeverityFail
+				case ms >= readLatencyWarnMs:
+					rowSeverity = check.SeverityWarn
+				}
+			}
+			rows = append(rows, check.TableRow{
+				Cells: []string{
+					fmt.Sprint(b.Pid), b.Usename, b.ApplicationName, check.FormatNumber(b.Reads),
+					fmt.Sprintf("%.2fms", b.ReadTimeMs/float64(max(b.Reads, 1))), check.FormatDurationMs(b.ReadTimeMs),
+				},
+				Severity: rowSeverity,
+			})
+		}
+		finding.Table = &check.Table{
+			Headers: []string{"PID", "User", "Application", "Reads", "Avg Read", "Read Time"},
+			Rows:    rows,
+		}
+	}
+	report.AddFinding(finding)
+}
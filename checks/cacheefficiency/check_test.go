@@ -13,8 +13,15 @@ import (
 )
 
 type mockCacheEfficiencyQueryer struct {
-	row db.DatabaseCacheEfficiencyRow
-	err error
+	row      db.DatabaseCacheEfficiencyRow
+	err      error
+	settings db.IOTimingSettingsRow
+	timing   db.IOTimingRow
+	backends []db.BackendIOTimingRow
+
+	timingCalled   bool
+	legacyCalled   bool
+	backendsCalled bool
 }
 
 func (m *mockCacheEfficiencyQueryer) DatabaseCacheEfficiency(context.Context) (db.DatabaseCacheEfficiencyRow, error) {
@@ -24,6 +31,25 @@ func (m *mockCacheEfficiencyQueryer) DatabaseCacheEfficiency(context.Context) (d
 	return m.row, nil
 }
 
+func (m *mockCacheEfficiencyQueryer) IOTimingSettings(context.Context) (db.IOTimingSettingsRow, error) {
+	return m.settings, nil
+}
+
+func (m *mockCacheEfficiencyQueryer) IOTiming(context.Context) (db.IOTimingRow, error) {
+	m.timingCalled = true
+	return m.timing, nil
+}
+
+func (m *mockCacheEfficiencyQueryer) LegacyIOTiming(context.Context) (db.LegacyIOTimingRow, error) {
+	m.legacyCalled = true
+	return db.LegacyIOTimingRow(m.timing), nil
+}
+
+func (m *mockCacheEfficiencyQueryer) BackendIOTiming(context.Context) ([]db.BackendIOTimingRow, error) {
+	m.backendsCalled = true
+	return m.backends, nil
+}
+
 func newMockQueryer(row db.DatabaseCacheEfficiencyRow) *mockCacheEfficiencyQueryer {
 	return &mockCacheEfficiencyQueryer{row: row}
 }
@@ -102,7 +128,7 @@ func Test_CacheEfficiency(t *testing.T) {
 			require.NoError(t, err)
 
 			results := report.Results
-			require.Equal(t, 1, len(results), "Should have exactly 1 result")
+			require.Equal(t, 2, len(results), "Should have cache hit ratio and I/O timing results")
 
 			result := results[0]
 			require.Equal(t, tc.ExpectedID, result.ID, "Result ID should match")
@@ -128,7 +154,7 @@ func Test_CacheEfficiency_DetailsContent(t *testing.T) {
 	require.NoError(t, err)
 
 	results := report.Results
-	require.Equal(t, 1, len(results), "Should have exactly 1 result")
+	require.Equal(t, 2, len(results), "Should have cache hit ratio and I/O timing results")
 
 	result := results[0]
 	require.Equal(t, check.SeverityFail, result.Severity)
@@ -154,7 +180,7 @@ func Test_CacheEfficiency_OKResult(t *testing.T) {
 	require.NoError(t, err)
 
 	results := report.Results
-	require.Equal(t, 1, len(results), "Should have exactly 1 result")
+	require.Equal(t, 2, len(results), "Should have cache hit ratio and I/O timing results")
 
 	result := results[0]
 	require.Equal(t, check.SeverityOK, result.Severity, "Should be OK when cache ratio is healthy")
@@ -238,7 +264,7 @@ func Test_CacheEfficiency_ThresholdBoundaries(t *testing.T) {
 			require.NoError(t, err)
 
 			results := report.Results
-			require.Equal(t, 1, len(results))
+			require.Equal(t, 2, len(results))
 
 			result := results[0]
 			require.Equal(t, tc.ExpectedSeverity, result.Severity, "Severity should match expected")
@@ -262,9 +288,125 @@ func Test_CacheEfficiency_NoActivityHandling(t *testing.T) {
 	require.NoError(t, err)
 
 	results := report.Results
-	require.Equal(t, 1, len(results))
+	require.Equal(t, 2, len(results))
 
 	result := results[0]
 	require.Equal(t, check.SeverityOK, result.Severity, "Should be OK when no cache activity")
 	require.Contains(t, result.Details, "Insufficient cache activity", "Details should explain no activity")
 }
+
+func Test_CacheEfficiency_IOTiming(t *testing.T) {
+	t.Parallel()
+
+	type testCase struct {
+		Name             string
+		Version          int32
+		TrackIOTiming    bool
+		Reads            int64
+		ReadTimeMs       float64
+		ExpectedSeverity check.Severity
+		ExpectedDetails  string
+		ExpectLegacy     bool
+		ExpectBackends   bool
+	}
+
+	testCases := []testCase{
+		{
+			Name:             "PG15 has no pg_stat_io - OK",
+			Version:          150008,
+			TrackIOTiming:    true,
+			ExpectedSeverity: check.SeverityOK,
+			ExpectedDetails:  "PostgreSQL 16+",
+		},
+		{
+			Name:             "track_io_timing off - OK",
+			Version:          180000,
+			ExpectedSeverity: check.SeverityOK,
+			ExpectedDetails:  "track_io_timing is off",
+		},
+		{
+			Name:             "too few reads - OK",
+			Version:          180000,
+			TrackIOTiming:    true,
+			Reads:            500,
+			ReadTimeMs:       50000,
+			ExpectedSeverity: check.SeverityOK,
+			ExpectedDetails:  "not enough",
+			ExpectBackends:   true,
+		},
+		{
+			Name:             "fast reads on PG18 - OK",
+			Version:          180000,
+			TrackIOTiming:    true,
+			Reads:            1000000,
+			ReadTimeMs:       400000,
+			ExpectedSeverity: check.SeverityOK,
+			ExpectedDetails:  "averaging 0.40ms per read",
+			ExpectBackends:   true,
+		},
+		{
+			Name:             "slow reads on PG17 use op_bytes query - WARN",
+			Version:          170004,
+			TrackIOTiming:    true,
+			Reads:            100000,
+			ReadTimeMs:       800000,
+			ExpectedSeverity: check.SeverityWarn,
+			ExpectedDetails:  "averaging 8.00ms per read",
+			ExpectLegacy:     true,
+		},
+		{
+			Name:             "very slow reads on PG18 - FAIL",
+			Version:          180001,
+			TrackIOTiming:    true,
+			Reads:            100000,
+			ReadTimeMs:       2500000,
+			ExpectedSeverity: check.SeverityFail,
+			ExpectedDetails:  "IOPS or throughput limit",
+			ExpectBackends:   true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+
+			queryer := newMockQueryer(db.DatabaseCacheEfficiencyRow{CacheHitRatio: makeNumeric(99.0)})
+			queryer.settings = db.IOTimingSettingsRow{ServerVersionNum: tc.Version, TrackIoTiming: tc.TrackIOTiming}
+			queryer.timing = db.IOTimingRow{Reads: tc.Reads, ReadBytes: tc.Reads * 8192, ReadTimeMs: tc.ReadTimeMs, SecondsSinceReset: 7 * 86400}
+
+			report, err := cacheefficiency.New(queryer).Check(context.Background())
+			require.NoError(t, err)
+			require.Equal(t, 2, len(report.Results))
+
+			result := report.Results[1]
+			require.Equal(t, "io-timing", result.ID)
+			require.Equal(t, tc.ExpectedSeverity, result.Severity)
+			require.Contains(t, result.Details, tc.ExpectedDetails)
+			require.Equal(t, tc.ExpectLegacy, queryer.legacyCalled, "PG16 and 17 read op_bytes")
+			require.Equal(t, tc.ExpectBackends, queryer.backendsCalled, "per-backend statistics need PG18")
+		})
+	}
+}
+
+func Test_CacheEfficiency_IOTimingBackends(t *testing.T) {
+	t.Parallel()
+
+	queryer := newMockQueryer(db.DatabaseCacheEfficiencyRow{CacheHitRatio: makeNumeric(99.0)})
+	queryer.settings = db.IOTimingSettingsRow{ServerVersionNum: 180000, TrackIoTiming: true}
+	queryer.timing = db.IOTimingRow{Reads: 100000, ReadBytes: 100000 * 8192, ReadTimeMs: 1000000}
+	queryer.backends = []db.BackendIOTimingRow{
+		{Pid: 4242, Usename: "reporting", ApplicationName: "metabase", Reads: 20000, ReadTimeMs: 600000},
+		{Pid: 4343, Usename: "app", ApplicationName: "api", Reads: 50000, ReadTimeMs: 100000},
+	}
+
+	report, err := cacheefficiency.New(queryer).Check(context.Background())
+	require.NoError(t, err)
+
+	result := report.Results[1]
+	require.Equal(t, check.SeverityWarn, result.Severity)
+	require.NotNil(t, result.Table)
+	require.Len(t, result.Table.Rows, 2)
+	require.Equal(t, []string{"4242", "reporting", "metabase", "20.0K", "30.00ms", "10.0m"}, result.Table.Rows[0].Cells)
+	require.Equal(t, check.SeverityFail, result.Table.Rows[0].Severity)
+	require.Equal(t, check.SeverityOK, result.Table.Rows[1].Severity)
+}
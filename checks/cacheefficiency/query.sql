@@ -15,3 +15,52 @@ SELECT
   ) AS stats_age_days
 FROM pg_stat_database
 WHERE datname = current_database();
+
+-- name: IOTimingSettings :one
+-- Server version, to pick the pg_stat_io query, and whether I/O timing is
+-- collected at all.
+SELECT
+  current_setting('server_version_num')::integer AS server_version_num
+  , current_setting('track_io_timing')::boolean AS track_io_timing;
+
+-- name: IOTiming :one
+-- Relation reads by client backends on PostgreSQL 18+, where pg_stat_io
+-- reports bytes directly and no longer has op_bytes.
+SELECT
+  coalesce(sum(reads), 0)::bigint AS reads
+  , coalesce(sum(read_bytes), 0)::bigint AS read_bytes
+  , coalesce(sum(read_time), 0)::double precision AS read_time_ms
+  , coalesce(extract(EPOCH FROM (now() - min(stats_reset))), 0)::bigint AS seconds_since_reset
+FROM pg_stat_io
+WHERE backend_type = 'client backend' AND object = 'relation';
+
+-- name: LegacyIOTiming :one
+-- Relation reads by client backends on PostgreSQL 16 and 17, with bytes
+-- derived from op_bytes.
+SELECT
+  coalesce(sum(reads), 0)::bigint AS reads
+  , coalesce(sum(reads * op_bytes), 0)::bigint AS read_bytes
+  , coalesce(sum(read_time), 0)::double precision AS read_time_ms
+  , coalesce(extract(EPOCH FROM (now() - min(stats_reset))), 0)::bigint AS seconds_since_reset
+FROM pg_stat_io
+WHERE backend_type = 'client backend' AND object = 'relation';
+
+-- name: BackendIOTiming :many
+-- Connected client backends of this database that spent the most time
+-- reading relations, from the per-backend I/O statistics of PostgreSQL 18+.
+SELECT
+  a.pid
+  , coalesce(a.usename, '')::text AS usename
+  , coalesce(a.application_name, '')::text AS application_name
+  , sum(io.reads)::bigint AS reads
+  , sum(io.read_time)::double precision AS read_time_ms
+FROM pg_stat_activity AS a
+CROSS JOIN LATERAL pg_stat_get_backend_io(a.pid) AS io
+WHERE
+  a.backend_type = 'client backend'
+  AND a.datname = current_database()
+  AND io.object = 'relation'
+GROUP BY a.pid, a.usename, a.application_name
+HAVING sum(io.reads) > 0
+ORDER BY read_time_ms DESC
+LIMIT 5;
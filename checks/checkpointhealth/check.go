@@ -0,0 +1,209 @@
+// Package checkpointhealth implements checks for checkpoint frequency and for
+// buffer writes that fall on client backends instead of the checkpointer and
+// background writer.
+package checkpointhealth
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+
+	"github.com/fresha/pgdoctor/check"
+	"github.com/fresha/pgdoctor/db"
+)
+
+//go:embed query.sql
+var querySQL string
+
+//go:embed README.md
+var readme string
+
+const (
+	// Average time between checkpoints. Every checkpoint makes the next
+	// change to each page write a full-page image to WAL.
+	intervalWarnSeconds = int64(5 * 60)
+	intervalFailSeconds = int64(60)
+	// Too few checkpoints since the stats reset to judge the interval.
+	minCheckpoints = 10
+	// Share of checkpoints forced by WAL volume above which max_wal_size,
+	// rather than checkpoint_timeout, is what sets the interval.
+	requestedDominantPercent = 50.0
+	// checkpoint_timeout proposed when timed checkpoints are too frequent.
+	recommendedTimeoutSeconds = int64(15 * 60)
+
+	// Share of buffer writes done by client backends.
+	backendWarnPercent = 25.0
+	backendFailPercent = 50.0
+	// Too few buffers written since the stats reset to judge the split.
+	minBufferWrites = 10000
+
+	// pg_stat_checkpointer and pg_stat_io replaced the pg_stat_bgwriter columns.
+	checkpointerMinVersion = 170000
+)
+
+type CheckpointHealthQueries interface {
+	CheckpointSettings(context.Context) (db.CheckpointSettingsRow, error)
+	CheckpointActivity(context.Context) (db.CheckpointActivityRow, error)
+	LegacyCheckpointActivity(context.Context) (db.LegacyCheckpointActivityRow, error)
+}
+
+type checker struct {
+	queries CheckpointHealthQueries
+}
+
+func Metadata() check.Metadata {
+	return check.Metadata{
+		Category:       check.CategoryConfigs,
+		CheckID:        "checkpoint-health",
+		Name:           "Checkpoint Health",
+		Description:    "Checks how often checkpoints run and whether client backends are writing dirty buffers themselves",
+		Readme:         readme,
+		SQL:            querySQL,
+		RuntimeClass:   check.RuntimeFast,
+		ProductionSafe: true,
+		Findings: []check.FindingSpec{
+			{ID: "checkpoint-interval", Description: "Average time between checkpoints, with the max_wal_size or checkpoint_timeout change to lengthen it (minimum 10 checkpoints)", Thresholds: "WARN < 5m, FAIL < 1m"},
+			{ID: "backend-writes", Description: "Share of buffer writes done by client backends rather than the checkpointer and background writer (minimum 10,000 buffers)", Thresholds: "WARN >= 25%, FAIL >= 50% or any backend fsync"},
+		},
+	}
+}
+
+func New(queries CheckpointHealthQueries, _ ...check.Config) check.Checker {
+	return &checker{
+		queries: queries,
+	}
+}
+
+func (c *checker) Metadata() check.Metadata {
+	return Metadata()
+}
+
+func (c *checker) Check(ctx context.Context) (*check.Report, error) {
+	report := check.NewReport(Metadata())
+
+	settings, err := c.queries.CheckpointSettings(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("running %s/%s (settings): %w", report.Category, report.CheckID, err)
+	}
+
+	activity, err := c.fetchActivity(ctx, settings.ServerVersionNum)
+	if err != nil {
+		return nil, fmt.Errorf("running %s/%s (activity): %w", report.Category, report.CheckID, err)
+	}
+
+	checkCheckpointInterval(settings, activity, report)
+	checkBackendWrites(settings, activity, report)
+
+	return report, nil
+}
+
+// Reads counters from pg_stat_checkpointer and pg_stat_io on PG17+ and from
+// pg_stat_bgwriter on older versions.
+func (c *checker) fetchActivity(ctx context.Context, serverVersion int32) (db.CheckpointActivityRow, error) {
+	if serverVersion >= checkpointerMinVersion {
+		return c.queries.CheckpointActivity(ctx)
+	}
+	row, err := c.queries.LegacyCheckpointActivity(ctx)
+	return db.CheckpointActivityRow(row), err
+}
+
+// suggestedMaxWalSize scales max_wal_size so WAL-triggered checkpoints come
+// no more often than checkpoint_timeout, rounded up to a whole GiB.
+func suggestedMaxWalSize(current, intervalSeconds, timeoutSeconds int64) int64 {
+	const gib = int64(1 << 30)
+	want := int64(float64(current) * float64(timeoutSeconds) / float64(max(intervalSeconds, 1)))
+	return (want + gib - 1) / gib * gib
+}
+
+func checkCheckpointInterval(settings db.CheckpointSettingsRow, stats db.CheckpointActivityRow, report *check.Report) {
+	total := stats.CheckpointsTimed + stats.CheckpointsRequested
+	if total < minCheckpoints || stats.SecondsSinceReset <= 0 {
+		report.AddFinding(check.Finding{
+			ID:       "checkpoint-interval",
+			Name:     "Checkpoint Interval",
+			Severity: check.SeverityOK,
+			Details:  fmt.Sprintf("Only %d checkpoint(s) since the stats reset; not enough to judge the interval", total),
+		})
+		return
+	}
+
+	interval := stats.SecondsSinceReset / total
+	requestedPct := float64(stats.CheckpointsRequested) / float64(total) * 100
+	details := fmt.Sprintf("One checkpoint every %s on average over %d checkpoints (checkpoint_timeout %s, %.0f%% forced by WAL volume)",
+		check.FormatDurationSec(interval), total, check.FormatDurationSec(settings.CheckpointTimeoutSeconds), requestedPct)
+
+	severity := check.SeverityOK
+	if interval < intervalFailSeconds {
+		severity = check.SeverityFail
+	} else if interval < intervalWarnSeconds {
+		severity = check.SeverityWarn
+	}
+
+	if severity > check.SeverityOK {
+		details += ". After each checkpoint the first change to every page writes a full-page image to WAL, so frequent checkpoints multiply WAL volume and I/O"
+		if requestedPct >= requestedDominantPercent {
+			details += fmt.Sprintf(". Most checkpoints are forced by WAL volume: raise max_wal_size from %s to about %s so they wait for checkpoint_timeout (see wal-size for disk headroom)",
+				check.FormatBytes(settings.MaxWalSizeBytes),
+				check.FormatBytes(suggestedMaxWalSize(settings.MaxWalSizeBytes, interval, settings.CheckpointTimeoutSeconds)))
+		} else if settings.CheckpointTimeoutSeconds < recommendedTimeoutSeconds {
+			details += fmt.Sprintf(". Most checkpoints are timed: raise checkpoint_timeout from %s to %s, and max_wal_size with it if checkpoints then become forced",
+				check.FormatDurationSec(settings.CheckpointTimeoutSeconds), check.FormatDurationSec(recommendedTimeoutSeconds))
+		}
+	}
+
+	report.AddFinding(check.Finding{
+		ID:       "checkpoint-interval",
+		Name:     "Checkpoint Interval",
+		Severity: severity,
+		Details:  details,
+	})
+}
+
+func checkBackendWrites(settings db.CheckpointSettingsRow, stats db.CheckpointActivityRow, report *check.Report) {
+	total := stats.BuffersCheckpoint + stats.BuffersClean + stats.BuffersBackend
+	if total < minBufferWrites {
+		report.AddFinding(check.Finding{
+			ID:       "backend-writes",
+			Name:     "Backend Buffer Writes",
+			Severity: check.SeverityOK,
+			Details:  fmt.Sprintf("Only %s buffer(s) written since the stats reset; not enough to judge who writes them", check.FormatNumber(total)),
+		})
+		return
+	}
+
+	pct := func(n int64) float64 { return float64(n) / float64(total) * 100 }
+	backendPct := pct(stats.BuffersBackend)
+	details := fmt.Sprintf("Client backends wrote %.0f%% of %s buffers (checkpointer %.0f%%, background writer %.0f%%)",
+		backendPct, check.FormatNumber(total), pct(stats.BuffersCheckpoint), pct(stats.BuffersClean))
+
+	severity := check.SeverityOK
+	if backendPct >= backendFailPercent {
+		severity = check.SeverityFail
+	} else if backendPct >= backendWarnPercent {
+		severity = check.SeverityWarn
+	}
+
+	if stats.BackendFsyncs > 0 {
+		severity = check.SeverityFail
+		details += fmt.Sprintf(". Backends ran %s fsync(s) themselves because the checkpointer's request queue was full, stalling queries on disk flushes",
+			check.FormatNumber(stats.BackendFsyncs))
+	}
+
+	if severity > check.SeverityOK {
+		details += ". Queries that need a free buffer are writing out dirty pages themselves and wait on that I/O. Bulk loads and VACUUM also count here, so compare with the workload"
+		if stats.MaxwrittenClean > 0 {
+			details += fmt.Sprintf(". The background writer stopped %s time(s) after writing bgwriter_lru_maxpages (%d) buffers in a round: raise bgwriter_lru_maxpages",
+				check.FormatNumber(stats.MaxwrittenClean), settings.BgwriterLruMaxpages)
+		} else {
+			details += fmt.Sprintf(". shared_buffers (%s) may be too small for the working set, or the background writer too slow: lower bgwriter_delay or raise bgwriter_lru_multiplier",
+				check.FormatBytes(settings.SharedBuffersBytes))
+		}
+	}
+
+	report.AddFinding(check.Finding{
+		ID:       "backend-writes",
+		Name:     "Backend Buffer Writes",
+		Severity: severity,
+		Details:  details,
+	})
+}
@@ -0,0 +1,183 @@
+package checkpointhealth_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/fresha/pgdoctor/check"
+	"github.com/fresha/pgdoctor/check/checktest"
+	"github.com/fresha/pgdoctor/checks/checkpointhealth"
+	"github.com/fresha/pgdoctor/db"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const gb = int64(1 << 30)
+
+type mockQueryer struct {
+	settings db.CheckpointSettingsRow
+	activity db.CheckpointActivityRow
+	legacy   db.LegacyCheckpointActivityRow
+	err      error
+
+	legacyCalled bool
+}
+
+func (m *mockQueryer) CheckpointSettings(context.Context) (db.CheckpointSettingsRow, error) {
+	return m.settings, m.err
+}
+
+func (m *mockQueryer) CheckpointActivity(context.Context) (db.CheckpointActivityRow, error) {
+	return m.activity, nil
+}
+
+func (m *mockQueryer) LegacyCheckpointActivity(context.Context) (db.LegacyCheckpointActivityRow, error) {
+	m.legacyCalled = true
+	return m.legacy, nil
+}
+
+func healthyQueryer() *mockQueryer {
+	return &mockQueryer{
+		settings: db.CheckpointSettingsRow{
+			ServerVersionNum:         170002,
+			CheckpointTimeoutSeconds: 900,
+			MaxWalSizeBytes:          gb,
+			SharedBuffersBytes:       4 * gb,
+			BgwriterLruMaxpages:      100,
+		},
+		activity: db.CheckpointActivityRow{
+			CheckpointsTimed:     95,
+			CheckpointsRequested: 5,
+			BuffersCheckpoint:    800_000,
+			BuffersClean:         150_000,
+			BuffersBackend:       50_000,
+			SecondsSinceReset:    100 * 900,
+		},
+	}
+}
+
+func TestCheckpointHealth_Healthy(t *testing.T) {
+	t.Parallel()
+
+	q := healthyQueryer()
+	report, err := checkpointhealth.New(q).Check(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, check.SeverityOK, report.Severity)
+	assert.Len(t, report.Results, 2)
+	assert.False(t, q.legacyCalled, "PostgreSQL 17 reads pg_stat_checkpointer")
+}
+
+func TestCheckpointHealth_Interval(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		timeout   int64
+		timed     int64
+		requested int64
+		seconds   int64
+		expected  check.Severity
+		contains  string
+	}{
+		{name: "too few checkpoints", timeout: 60, timed: 5, seconds: 300, expected: check.SeverityOK, contains: "not enough"},
+		{name: "every 15 minutes", timeout: 900, timed: 100, seconds: 100 * 900, expected: check.SeverityOK},
+		{name: "timed every 2 minutes", timeout: 120, timed: 100, seconds: 100 * 120, expected: check.SeverityWarn, contains: "raise checkpoint_timeout from 2m to 15m"},
+		{name: "forced every 2 minutes", timeout: 900, timed: 10, requested: 90, seconds: 100 * 120, expected: check.SeverityWarn, contains: "raise max_wal_size from 1.0GiB to about 8.0GiB"},
+		{name: "forced every 30 seconds", timeout: 300, requested: 100, seconds: 100 * 30, expected: check.SeverityFail, contains: "raise max_wal_size"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			q := healthyQueryer()
+			q.settings.CheckpointTimeoutSeconds = tt.timeout
+			q.activity.CheckpointsTimed = tt.timed
+			q.activity.CheckpointsRequested = tt.requested
+			q.activity.SecondsSinceReset = tt.seconds
+
+			report, err := checkpointhealth.New(q).Check(context.Background())
+			require.NoError(t, err)
+
+			f := checktest.Finding(t, report, "checkpoint-interval")
+			assert.Equal(t, tt.expected, f.Severity)
+			assert.Contains(t, f.Details, tt.contains)
+		})
+	}
+}
+
+func TestCheckpointHealth_BackendWrites(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		backend    int64
+		fsyncs     int64
+		maxwritten int64
+		expected   check.Severity
+		contains   string
+	}{
+		{name: "mostly checkpointer", backend: 50_000, expected: check.SeverityOK},
+		{name: "quarter by backends", backend: 400_000, expected: check.SeverityWarn, contains: "shared_buffers (4.0GiB)"},
+		{name: "bgwriter capped", backend: 400_000, maxwritten: 1200, expected: check.SeverityWarn, contains: "raise bgwriter_lru_maxpages"},
+		{name: "majority by backends", backend: 1_000_000, expected: check.SeverityFail},
+		{name: "backend fsyncs", backend: 50_000, fsyncs: 3, expected: check.SeverityFail, contains: "3 fsync(s)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			q := healthyQueryer()
+			q.activity.BuffersBackend = tt.backend
+			q.activity.BackendFsyncs = tt.fsyncs
+			q.activity.MaxwrittenClean = tt.maxwritten
+
+			report, err := checkpointhealth.New(q).Check(context.Background())
+			require.NoError(t, err)
+
+			f := checktest.Finding(t, report, "backend-writes")
+			assert.Equal(t, tt.expected, f.Severity)
+			assert.Contains(t, f.Details, tt.contains)
+		})
+	}
+}
+
+func TestCheckpointHealth_FewWrites(t *testing.T) {
+	t.Parallel()
+
+	q := healthyQueryer()
+	q.activity = db.CheckpointActivityRow{BuffersBackend: 900, BuffersCheckpoint: 100}
+	report, err := checkpointhealth.New(q).Check(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, check.SeverityOK, checktest.Finding(t, report, "backend-writes").Severity)
+}
+
+func TestCheckpointHealth_LegacyViews(t *testing.T) {
+	t.Parallel()
+
+	q := healthyQueryer()
+	q.settings.ServerVersionNum = 160004
+	q.legacy = db.LegacyCheckpointActivityRow{
+		CheckpointsRequested: 100,
+		BuffersCheckpoint:    100_000,
+		BuffersBackend:       10_000,
+		SecondsSinceReset:    100 * 30,
+	}
+
+	report, err := checkpointhealth.New(q).Check(context.Background())
+	require.NoError(t, err)
+
+	assert.True(t, q.legacyCalled)
+	assert.Equal(t, check.SeverityFail, checktest.Finding(t, report, "checkpoint-interval").Severity)
+}
+
+func TestCheckpointHealth_QueryError(t *testing.T) {
+	t.Parallel()
+
+	_, err := checkpointhealth.New(&mockQueryer{err: errors.New("boom")}).Check(context.Background())
+	require.ErrorContains(t, err, "checkpoint-health")
+}
@@ -0,0 +1,47 @@
+-- name: CheckpointSettings :one
+-- Settings that decide when checkpoints run and how the background writer
+-- keeps up, with the server version to pick the statistics views.
+SELECT
+  CURRENT_SETTING('server_version_num')::integer AS server_version_num
+  , (SELECT setting::bigint FROM pg_settings WHERE name = 'checkpoint_timeout') AS checkpoint_timeout_seconds
+  , PG_SIZE_BYTES(CURRENT_SETTING('max_wal_size'))::bigint AS max_wal_size_bytes
+  , PG_SIZE_BYTES(CURRENT_SETTING('shared_buffers'))::bigint AS shared_buffers_bytes
+  , CURRENT_SETTING('bgwriter_lru_maxpages')::integer AS bgwriter_lru_maxpages;
+
+-- name: CheckpointActivity :one
+-- Checkpoint and buffer write counters on PostgreSQL 17+, where checkpoints
+-- moved to pg_stat_checkpointer and backend writes to pg_stat_io.
+SELECT
+  c.num_timed::bigint AS checkpoints_timed
+  , c.num_requested::bigint AS checkpoints_requested
+  , c.buffers_written::bigint AS buffers_checkpoint
+  , b.buffers_clean::bigint AS buffers_clean
+  , b.maxwritten_clean::bigint AS maxwritten_clean
+  , COALESCE(io.writes, 0)::bigint AS buffers_backend
+  , COALESCE(io.fsyncs, 0)::bigint AS backend_fsyncs
+  , c.write_time::double precision AS write_time_ms
+  , COALESCE(EXTRACT(EPOCH FROM (NOW() - c.stats_reset)), 0)::bigint AS seconds_since_reset
+FROM pg_stat_checkpointer AS c
+CROSS JOIN pg_stat_bgwriter AS b
+CROSS JOIN (
+  SELECT
+    SUM(writes) AS writes
+    , SUM(fsyncs) AS fsyncs
+  FROM pg_stat_io
+  WHERE backend_type = 'client backend' AND object = 'relation'
+) AS io;
+
+-- name: LegacyCheckpointActivity :one
+-- Checkpoint and buffer write counters from pg_stat_bgwriter (before
+-- PostgreSQL 17).
+SELECT
+  checkpoints_timed::bigint AS checkpoints_timed
+  , checkpoints_req::bigint AS checkpoints_requested
+  , buffers_checkpoint::bigint AS buffers_checkpoint
+  , buffers_clean::bigint AS buffers_clean
+  , maxwritten_clean::bigint AS maxwritten_clean
+  , buffers_backend::bigint AS buffers_backend
+  , buffers_backend_fsync::bigint AS backend_fsyncs
+  , checkpoint_write_time::double precision AS write_time_ms
+  , COALESCE(EXTRACT(EPOCH FROM (NOW() - stats_reset)), 0)::bigint AS seconds_since_reset
+FROM pg_stat_bgwriter;
@@ -38,12 +38,20 @@ type checker struct {
 
 func Metadata() check.Metadata {
 	return check.Metadata{
-		Category:    check.CategoryConfigs,
-		CheckID:     "connection-efficiency",
-		Name:        "Connection Efficiency",
-		Description: "Analyzes PostgreSQL 14+ session statistics for connection pool efficiency",
-		Readme:      readme,
-		SQL:         querySQL,
+		Category:       check.CategoryConfigs,
+		CheckID:        "connection-efficiency",
+		Name:           "Connection Efficiency",
+		Description:    "Analyzes PostgreSQL 14+ session statistics for connection pool efficiency",
+		Readme:         readme,
+		SQL:            querySQL,
+		RuntimeClass:   check.RuntimeFast,
+		ProductionSafe: true,
+		Findings: []check.FindingSpec{
+			{ID: "busy-ratio", Description: "Share of session time spent executing queries rather than idle", Thresholds: "WARN < 20%"},
+			{ID: "sessions-abandoned", Description: "Sessions ended by the client disconnecting without terminating", Thresholds: "WARN > 1%, FAIL > 5% of sessions"},
+			{ID: "sessions-fatal", Description: "Sessions ended by a fatal error", Thresholds: "WARN > 1%, FAIL > 5% of sessions"},
+			{ID: "sessions-killed", Description: "Sessions terminated by an operator or timeout", Thresholds: "WARN > 1%, FAIL > 5% of sessions"},
+		},
 	}
 }
 
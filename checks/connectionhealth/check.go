@@ -5,6 +5,7 @@ import (
 	"context"
 	_ "embed"
 	"fmt"
+	"strings"
 
 	"github.com/fresha/pgdoctor/check"
 	"github.com/fresha/pgdoctor/db"
@@ -34,6 +35,18 @@ const (
 	poolPressureMinIdleWarn   = 3    // AND fewer than 3 idle connections
 	poolPressureMinIdleFail   = 1    // Critical when only 0-1 idle connections
 	poolPressureMinTotalConns = 10   // Skip check if fewer than 10 total connections
+
+	// Per-role connection limits.
+	roleLimitWarnPercent = 90.0 // Role close to its CONNECTION LIMIT
+	// Share of max_connections an unlimited role may hold before one runaway
+	// client of that role could starve everyone else.
+	unlimitedRoleWarnPercent = 25.0
+
+	// A database is inactive when it has no open connections and averaged
+	// fewer transactions than this per day since its statistics reset, over
+	// at least inactiveMinStatsDays days.
+	inactiveMaxTransactionsPerDay = 10.0
+	inactiveMinStatsDays          = 7.0
 )
 
 const (
@@ -45,27 +58,53 @@ type ConnectionHealthQueries interface {
 	ConnectionStats(context.Context) (db.ConnectionStatsRow, error)
 	IdleInTransaction(context.Context) ([]db.IdleInTransactionRow, error)
 	LongIdleConnections(context.Context) ([]db.LongIdleConnectionsRow, error)
+	ReservedConnections(context.Context) (db.ReservedConnectionsRow, error)
+	RoleConnections(context.Context) ([]db.RoleConnectionsRow, error)
+	InactiveDatabases(context.Context) ([]db.InactiveDatabasesRow, error)
 }
 
 type checker struct {
-	queries ConnectionHealthQueries
+	queries        ConnectionHealthQueries
+	saturationWarn float64
 }
 
 func Metadata() check.Metadata {
 	return check.Metadata{
-		Category:    check.CategoryConfigs,
-		CheckID:     "connection-health",
-		Name:        "Connection Health",
-		Description: "Monitors connection pool saturation, idle ratios, and stuck transactions",
-		Readme:      readme,
-		SQL:         querySQL,
+		Category:       check.CategoryConfigs,
+		CheckID:        "connection-health",
+		Name:           "Connection Health",
+		Description:    "Monitors connection pool saturation, idle ratios, and stuck transactions",
+		Readme:         readme,
+		SQL:            querySQL,
+		RuntimeClass:   check.RuntimeFast,
+		ProductionSafe: true,
+		Priority:       check.PriorityCritical,
+		Privileges:     []check.Privilege{check.PrivilegeReadAllStats},
+		Findings: []check.FindingSpec{
+			{ID: "connection-overview", Description: "Summary of connection counts by state"},
+			{ID: "connection-saturation", Description: "Connections in use relative to max_connections", Thresholds: "WARN > 70%, FAIL > 85%"},
+			{ID: "pool-pressure", Description: "Active connections crowding out idle headroom (skipped below 10 connections)", Thresholds: "WARN > 90% active and < 3 idle, FAIL <= 1 idle"},
+			{ID: "idle-ratio", Description: "Share of connections sitting idle (minimum 20 connections)", Thresholds: "WARN > 50%, FAIL > 75%"},
+			{ID: "idle-in-transaction", Description: "Sessions idle inside an open transaction", Thresholds: "WARN at 50%, FAIL at 100% of idle_in_transaction_session_timeout (5m when unset)"},
+			{ID: "long-idle", Description: "Connections idle for more than 30 minutes", Thresholds: "WARN >= 10, FAIL >= 50 connections"},
+			{ID: "role-connection-limits", Description: "Roles near their CONNECTION LIMIT, and unlimited roles holding a large share of max_connections", Thresholds: "WARN >= 90% of role limit or unlimited role >= 25% of max_connections, FAIL at role limit"},
+			{ID: "reserved-connections", Description: "Connection slots reserved for superusers and, on PG16+, for pg_use_reserved_connections members", Thresholds: "FAIL superuser_reserved_connections = 0, WARN reserved_connections = 0 or granted outside pg_monitor (PG16+)"},
+			{ID: "inactive-databases", Description: "Other databases in the cluster with no connections and almost no transactions, with their size", Thresholds: "WARN 0 connections and < 10 transactions/day over >= 7 days of statistics"},
+		},
 	}
 }
 
-func New(queries ConnectionHealthQueries, _ ...check.Config) check.Checker {
-	return &checker{
-		queries: queries,
+// New creates the check. The saturation WARN threshold can be raised with
+// the saturation_warn_percent config key.
+func New(queries ConnectionHealthQueries, cfg ...check.Config) check.Checker {
+	c := &checker{
+		queries:        queries,
+		saturationWarn: saturationWarnPercent,
+	}
+	if len(cfg) > 0 {
+		c.saturationWarn = cfg[0].Float(Metadata().CheckID, "saturation_warn_percent", saturationWarnPercent)
 	}
+	return c
 }
 
 func (c *checker) Metadata() check.Metadata {
@@ -90,13 +129,31 @@ func (c *checker) Check(ctx context.Context) (*check.Report, error) {
 		return nil, fmt.Errorf("running %s/%s (long-idle): %w", check.CategoryConfigs, report.CheckID, err)
 	}
 
+	roles, err := c.queries.RoleConnections(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("running %s/%s (roles): %w", check.CategoryConfigs, report.CheckID, err)
+	}
+
+	reserved, err := c.queries.ReservedConnections(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("running %s/%s (reserved): %w", check.CategoryConfigs, report.CheckID, err)
+	}
+
+	databases, err := c.queries.InactiveDatabases(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("running %s/%s (databases): %w", check.CategoryConfigs, report.CheckID, err)
+	}
+
 	addConnectionOverview(stats, report)
 
-	checkConnectionSaturation(stats, report)
-	checkPoolPressure(stats, report)
+	checkConnectionSaturation(ctx, stats, c.saturationWarn, report)
+	checkPoolPressure(ctx, stats, report)
 	checkIdleRatio(stats, report)
 	checkIdleInTransaction(idleTxns, report)
 	checkLongIdleConnections(longIdle, report)
+	checkRoleConnectionLimits(roles, stats, report)
+	checkReservedConnections(reserved, report)
+	checkInactiveDatabases(databases, report)
 
 	return report, nil
 }
@@ -127,15 +184,23 @@ func addConnectionOverview(stats db.ConnectionStatsRow, report *check.Report) {
 }
 
 // checkConnectionSaturation checks if we're running out of available connections.
-func checkConnectionSaturation(stats db.ConnectionStatsRow, report *check.Report) {
+func checkConnectionSaturation(ctx context.Context, stats db.ConnectionStatsRow, warnPercent float64, report *check.Report) {
 	maxConns := stats.MaxConnections.Int32
 	reserved := stats.ReservedConnections.Int32
 	available := maxConns - reserved
 	used := stats.TotalConnections.Int64
 
 	saturationPercent := float64(used) / float64(available) * 100
+	check.Observe(ctx, check.Observation{
+		CheckID:   Metadata().CheckID,
+		FindingID: "connection-saturation",
+		Key:       "saturation_warn_percent",
+		Value:     saturationPercent,
+		Warn:      warnPercent,
+		Fail:      saturationFailPercent,
+	})
 
-	if saturationPercent < saturationWarnPercent {
+	if saturationPercent < warnPercent {
 		report.AddFinding(check.Finding{
 			ID:       "connection-saturation",
 			Name:     "Connection Saturation",
@@ -154,14 +219,14 @@ func checkConnectionSaturation(stats db.ConnectionStatsRow, report *check.Report
 		ID:       "connection-saturation",
 		Name:     "Connection Saturation",
 		Severity: severity,
-		Details:  fmt.Sprintf("Connection usage at %.1f%% (%d/%d available)", saturationPercent, used, available),
+		Details:  fmt.Sprintf("Connection usage at %.1f%% (%d/%d available)", saturationPercent, used, available) + hostContext(ctx),
 	})
 }
 
 // checkPoolPressure detects when the pool has minimal idle capacity and new queries may queue.
 // This is different from saturation (approaching max_connections) - pool pressure means
 // all available connections are busy even if we haven't hit the limit.
-func checkPoolPressure(stats db.ConnectionStatsRow, report *check.Report) {
+func checkPoolPressure(ctx context.Context, stats db.ConnectionStatsRow, report *check.Report) {
 	total := stats.TotalConnections.Int64
 	active := stats.ActiveConnections.Int64
 	idle := stats.IdleConnections.Int64
@@ -200,10 +265,30 @@ func checkPoolPressure(stats db.ConnectionStatsRow, report *check.Report) {
 		ID:       "pool-pressure",
 		Name:     "Connection Pool Pressure",
 		Severity: severity,
-		Details:  fmt.Sprintf("Pool under pressure: %d active (%.1f%%), only %d idle - new queries may wait", active, activePercent, idle),
+		Details:  fmt.Sprintf("Pool under pressure: %d active (%.1f%%), only %d idle - new queries may wait", active, activePercent, idle) + hostContext(ctx),
 	})
 }
 
+// hostContext tells whether the instance's CPU explains busy connections,
+// when host metrics were collected. A CPU-bound host needs less work, not
+// more connections; an idle one points at locks, slow queries, or clients
+// holding connections.
+func hostContext(ctx context.Context) string {
+	meta := check.InstanceMetadataFromContext(ctx)
+	if meta == nil || meta.HostMetrics == nil {
+		return ""
+	}
+	m := meta.HostMetrics
+	if m.CPUPercent >= check.HostCPUBusyPercent {
+		return fmt.Sprintf(". Host CPU averaged %.0f%% (peak %.0f%%) over the last %s: the instance is CPU bound, so sessions are queuing for CPU. "+
+			"More connections will not help; reduce query load or move to a larger instance class",
+			m.CPUPercent, m.MaxCPUPercent, check.FormatDurationSec(int64(m.Window.Seconds())))
+	}
+	return fmt.Sprintf(". Host CPU averaged %.0f%% (peak %.0f%%) over the last %s, so the host has headroom: "+
+		"sessions are more likely waiting on locks, I/O, or clients that hold connections open",
+		m.CPUPercent, m.MaxCPUPercent, check.FormatDurationSec(int64(m.Window.Seconds())))
+}
+
 // checkIdleRatio detects when too many connections are idle (potential pool misconfiguration).
 func checkIdleRatio(stats db.ConnectionStatsRow, report *check.Report) {
 	total := stats.TotalConnections.Int64
@@ -344,6 +429,202 @@ func checkLongIdleConnections(longIdle []db.LongIdleConnectionsRow, report *chec
 	})
 }
 
+// checkRoleConnectionLimits flags roles about to be refused connections and
+// unlimited roles large enough to starve the rest of the pool.
+func checkRoleConnectionLimits(roles []db.RoleConnectionsRow, stats db.ConnectionStatsRow, report *check.Report) {
+	maxConns := int64(stats.MaxConnections.Int32)
+
+	var tableRows []check.TableRow
+	severity := check.SeverityOK
+	var unlimited []string
+
+	for _, role := range roles {
+		var rowSeverity check.Severity
+		var limit, usage string
+
+		if role.ConnectionLimit < 0 {
+			if maxConns <= 0 {
+				continue
+			}
+			pct := float64(role.Connections) / float64(maxConns) * 100
+			if pct < unlimitedRoleWarnPercent {
+				continue
+			}
+			rowSeverity = check.SeverityWarn
+			limit = "unlimited"
+			usage = fmt.Sprintf("%.0f%% of max_connections", pct)
+			unlimited = append(unlimited, role.RoleName)
+		} else {
+			limitConns := int64(role.ConnectionLimit)
+			if limitConns > 0 && float64(role.Connections) < float64(limitConns)*roleLimitWarnPercent/100 {
+				continue
+			}
+			rowSeverity = check.SeverityWarn
+			if role.Connections >= limitConns {
+				rowSeverity = check.SeverityFail
+			}
+			limit = fmt.Sprintf("%d", role.ConnectionLimit)
+			usage = fmt.Sprintf("%d/%d", role.Connections, role.ConnectionLimit)
+		}
+
+		severity = max(severity, rowSeverity)
+		tableRows = append(tableRows, check.TableRow{
+			Cells:    []string{role.RoleName, fmt.Sprintf("%d", role.Connections), limit, usage},
+			Severity: rowSeverity,
+		})
+	}
+
+	if len(tableRows) == 0 {
+		report.AddFinding(check.Finding{
+			ID:       "role-connection-limits",
+			Name:     "Role Connection Limits",
+			Severity: check.SeverityOK,
+			Details:  fmt.Sprintf("%d role(s) connected; none near a connection limit or dominating max_connections", len(roles)),
+		})
+		return
+	}
+
+	details := fmt.Sprintf("%d role(s) at or near a connection ceiling", len(tableRows))
+	if severity == check.SeverityFail {
+		details += ". Roles at their CONNECTION LIMIT have new connections refused"
+	}
+	if len(unlimited) > 0 {
+		details += fmt.Sprintf(". Unlimited role(s) %s hold a large share of max_connections: a runaway job under one role can starve the application pool. Set ALTER ROLE ... CONNECTION LIMIT for batch and reporting roles",
+			strings.Join(unlimited, ", "))
+	}
+
+	report.AddFinding(check.Finding{
+		ID:       "role-connection-limits",
+		Name:     "Role Connection Limits",
+		Severity: severity,
+		Details:  details,
+		Table: &check.Table{
+			Headers: []string{"Role", "Connections", "Limit", "Usage"},
+			Rows:    tableRows,
+		},
+	})
+}
+
+// checkReservedConnections verifies that ordinary roles cannot take every
+// slot in max_connections, which would lock out administrators during an
+// incident.
+func checkReservedConnections(row db.ReservedConnectionsRow, report *check.Report) {
+	ordinarySlots := row.MaxConnections - row.SuperuserReservedConnections - row.ReservedConnections
+	details := fmt.Sprintf("max_connections=%d, superuser_reserved_connections=%d", row.MaxConnections, row.SuperuserReservedConnections)
+	if row.SupportsReservedConnections {
+		details += fmt.Sprintf(", reserved_connections=%d", row.ReservedConnections)
+	}
+	details += fmt.Sprintf(": ordinary roles can open at most %d connection(s)", ordinarySlots)
+
+	if row.SuperuserReservedConnections == 0 {
+		report.AddFinding(check.Finding{
+			ID:       "reserved-connections",
+			Name:     "Reserved Connections",
+			Severity: check.SeverityFail,
+			Details: details + ". superuser_reserved_connections is 0, so non-superuser roles can fill max_connections and lock superusers out. " +
+				"Set superuser_reserved_connections to at least 3",
+		})
+		return
+	}
+
+	if row.SupportsReservedConnections && row.ReservedConnections == 0 {
+		report.AddFinding(check.Finding{
+			ID:       "reserved-connections",
+			Name:     "Reserved Connections",
+			Severity: check.SeverityWarn,
+			Details: details + ". reserved_connections is 0: monitoring and admin roles that are not superusers compete with applications for slots. " +
+				"Reserve a few and grant pg_use_reserved_connections to those roles",
+		})
+		return
+	}
+
+	if row.ReservedPoolRoles != "" {
+		report.AddFinding(check.Finding{
+			ID:       "reserved-connections",
+			Name:     "Reserved Connections",
+			Severity: check.SeverityWarn,
+			Details: details + fmt.Sprintf(". Login roles outside pg_monitor can use the reserved pool via pg_use_reserved_connections (%s); application roles there can exhaust the slots kept for administrators",
+				row.ReservedPoolRoles),
+		})
+		return
+	}
+
+	report.AddFinding(check.Finding{
+		ID:       "reserved-connections",
+		Name:     "Reserved Connections",
+		Severity: check.SeverityOK,
+		Details:  details,
+	})
+}
+
+// checkInactiveDatabases lists databases nobody appears to use, as
+// candidates for archiving and removal. pg_stat_database covers the whole
+// cluster, so one connection sees every database.
+func checkInactiveDatabases(rows []db.InactiveDatabasesRow, report *check.Report) {
+	var inactive []db.InactiveDatabasesRow
+	var tooRecent int
+	var totalBytes int64
+	for _, row := range rows {
+		days := row.StatsAgeSeconds / 86400
+		if days < inactiveMinStatsDays {
+			tooRecent++
+			continue
+		}
+		if float64(row.Transactions)/days >= inactiveMaxTransactionsPerDay {
+			continue
+		}
+		inactive = append(inactive, row)
+		totalBytes += row.SizeBytes.Int64
+	}
+
+	if len(inactive) == 0 {
+		details := "No other database is idle"
+		if tooRecent > 0 {
+			details += fmt.Sprintf("; %d database(s) without connections have less than %.0f days of statistics to judge", tooRecent, inactiveMinStatsDays)
+		}
+		report.AddFinding(check.Finding{
+			ID:       "inactive-databases",
+			Name:     "Inactive Databases",
+			Severity: check.SeverityOK,
+			Details:  details,
+		})
+		return
+	}
+
+	tableRows := make([]check.TableRow, 0, len(inactive))
+	for _, row := range inactive {
+		size := "unknown"
+		if row.SizeBytes.Valid {
+			size = check.FormatBytes(row.SizeBytes.Int64)
+		}
+		days := row.StatsAgeSeconds / 86400
+		tableRows = append(tableRows, check.TableRow{
+			Cells: []string{
+				row.DatabaseName,
+				size,
+				check.FormatNumber(row.Transactions),
+				fmt.Sprintf("%.1f", float64(row.Transactions)/days),
+				fmt.Sprintf("%.0f", days),
+			},
+			Severity: check.SeverityWarn,
+		})
+	}
+
+	report.AddFinding(check.Finding{
+		ID:       "inactive-databases",
+		Name:     "Inactive Databases",
+		Severity: check.SeverityWarn,
+		Details: fmt.Sprintf("%d database(s) have no connections and averaged fewer than %.0f transactions per day since their statistics reset, holding %s. "+
+			"They are candidates for archiving with pg_dump and DROP DATABASE; confirm with their owners first, as periodic jobs may still use them",
+			len(inactive), inactiveMaxTransactionsPerDay, check.FormatBytes(totalBytes)),
+		Table: &check.Table{
+			Headers: []string{"Database", "Size", "Transactions", "Per Day", "Stats Age (days)"},
+			Rows:    tableRows,
+		},
+		Confidence: check.ConfidenceMedium,
+	})
+}
+
 func formatDuration(seconds int64) string {
 	if seconds < 60 {
 		return fmt.Sprintf("%ds", seconds)
@@ -4,8 +4,10 @@ import (
 	"context"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/fresha/pgdoctor/check"
+	"github.com/fresha/pgdoctor/check/checktest"
 	"github.com/fresha/pgdoctor/checks/connectionhealth"
 	"github.com/fresha/pgdoctor/db"
 	"github.com/jackc/pgx/v5/pgtype"
@@ -17,6 +19,9 @@ type mockQueries struct {
 	stats       db.ConnectionStatsRow
 	idleTxns    []db.IdleInTransactionRow
 	longIdle    []db.LongIdleConnectionsRow
+	reserved    *db.ReservedConnectionsRow
+	roles       []db.RoleConnectionsRow
+	databases   []db.InactiveDatabasesRow
 	statsErr    error
 	idleTxnsErr error
 	longIdleErr error
@@ -34,6 +39,21 @@ func (m *mockQueries) LongIdleConnections(context.Context) ([]db.LongIdleConnect
 	return m.longIdle, m.longIdleErr
 }
 
+func (m *mockQueries) RoleConnections(context.Context) ([]db.RoleConnectionsRow, error) {
+	return m.roles, nil
+}
+
+func (m *mockQueries) ReservedConnections(context.Context) (db.ReservedConnectionsRow, error) {
+	if m.reserved == nil {
+		return healthyReserved(), nil
+	}
+	return *m.reserved, nil
+}
+
+func (m *mockQueries) InactiveDatabases(context.Context) ([]db.InactiveDatabasesRow, error) {
+	return m.databases, nil
+}
+
 // ctxWithPgVersion creates a context with instance metadata containing the specified PG version.
 func ctxWithPgVersion(major int) context.Context {
 	return check.ContextWithInstanceMetadata(context.Background(), &check.InstanceMetadata{
@@ -89,6 +109,15 @@ func healthyStats() db.ConnectionStatsRow {
 	}
 }
 
+func healthyReserved() db.ReservedConnectionsRow {
+	return db.ReservedConnectionsRow{
+		MaxConnections:               100,
+		SuperuserReservedConnections: 3,
+		ReservedConnections:          2,
+		SupportsReservedConnections:  true,
+	}
+}
+
 // hasResult checks if a finding with the given ID and severity exists.
 func hasResult(results []check.Finding, id string, severity check.Severity) bool {
 	for _, r := range results {
@@ -99,16 +128,6 @@ func hasResult(results []check.Finding, id string, severity check.Severity) bool
 	return false
 }
 
-// getFinding returns the finding with the given ID.
-func getFinding(results []check.Finding, id string) *check.Finding {
-	for _, r := range results {
-		if r.ID == id {
-			return &r
-		}
-	}
-	return nil
-}
-
 func Test_Metadata(t *testing.T) {
 	t.Parallel()
 
@@ -138,7 +157,7 @@ func Test_ConnectionHealth_AllOK(t *testing.T) {
 	require.NotNil(t, report)
 
 	// All 6 subchecks should report OK (overview + 5 checks).
-	require.Len(t, report.Results, 6)
+	require.Len(t, report.Results, 9)
 	require.True(t, hasResult(report.Results, "connection-overview", check.SeverityOK))
 	require.True(t, hasResult(report.Results, "connection-saturation", check.SeverityOK))
 	require.True(t, hasResult(report.Results, "pool-pressure", check.SeverityOK))
@@ -209,6 +228,43 @@ func Test_ConnectionHealth_Saturation(t *testing.T) {
 	}
 }
 
+func Test_ConnectionHealth_HostCPU(t *testing.T) {
+	t.Parallel()
+
+	stats := healthyStats()
+	stats.TotalConnections = int64Val(90)
+
+	tests := []struct {
+		name     string
+		cpu      float64
+		contains string
+	}{
+		{name: "cpu bound", cpu: 91, contains: "Host CPU averaged 91% (peak 99%) over the last 1h: the instance is CPU bound"},
+		{name: "headroom", cpu: 30, contains: "Host CPU averaged 30% (peak 99%) over the last 1h, so the host has headroom"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := check.ContextWithInstanceMetadata(context.Background(), &check.InstanceMetadata{
+				EngineVersionMajor: 17,
+				HostMetrics:        &check.HostMetrics{Window: time.Hour, CPUPercent: tt.cpu, MaxCPUPercent: 99},
+			})
+			report, err := connectionhealth.New(&mockQueries{stats: stats}).Check(ctx)
+			require.NoError(t, err)
+
+			finding := checktest.Finding(t, report, "connection-saturation")
+			require.Equal(t, check.SeverityFail, finding.Severity)
+			require.Contains(t, finding.Details, tt.contains)
+		})
+	}
+
+	report, err := connectionhealth.New(&mockQueries{stats: stats}).Check(ctxWithPgVersion(17))
+	require.NoError(t, err)
+	require.NotContains(t, checktest.Finding(t, report, "connection-saturation").Details, "Host CPU", "no host context without metrics")
+}
+
 func Test_ConnectionHealth_PoolPressure(t *testing.T) {
 	t.Parallel()
 
@@ -543,8 +599,7 @@ func Test_ConnectionHealth_TableDetails(t *testing.T) {
 
 		require.NoError(t, err)
 
-		finding := getFinding(report.Results, "idle-in-transaction")
-		require.NotNil(t, finding)
+		finding := checktest.Finding(t, report, "idle-in-transaction")
 		require.NotNil(t, finding.Table)
 		require.Equal(t, []string{"PID", "User", "Database", "Duration", "Query"}, finding.Table.Headers)
 	})
@@ -561,8 +616,7 @@ func Test_ConnectionHealth_TableDetails(t *testing.T) {
 
 		require.NoError(t, err)
 
-		finding := getFinding(report.Results, "connection-overview")
-		require.NotNil(t, finding)
+		finding := checktest.Finding(t, report, "connection-overview")
 		require.Nil(t, finding.Table) // No table, metrics are in Details
 		require.Contains(t, finding.Details, "Connections:")
 	})
@@ -582,8 +636,7 @@ func Test_ConnectionHealth_TableDetails(t *testing.T) {
 
 		require.NoError(t, err)
 
-		finding := getFinding(report.Results, "connection-saturation")
-		require.NotNil(t, finding)
+		finding := checktest.Finding(t, report, "connection-saturation")
 		require.Nil(t, finding.Table) // No table anymore
 		require.Contains(t, finding.Details, "80/97")
 	})
@@ -604,8 +657,7 @@ func Test_ConnectionHealth_TableDetails(t *testing.T) {
 
 		require.NoError(t, err)
 
-		finding := getFinding(report.Results, "idle-ratio")
-		require.NotNil(t, finding)
+		finding := checktest.Finding(t, report, "idle-ratio")
 		require.Nil(t, finding.Table) // Table was removed as redundant
 	})
 }
@@ -719,3 +771,190 @@ func Test_ConnectionHealth_ReportSeverity(t *testing.T) {
 		})
 	}
 }
+
+func TestReservedConnections(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		modify   func(*db.ReservedConnectionsRow)
+		expected check.Severity
+		contains string
+	}{
+		{
+			name:     "both pools reserved",
+			modify:   func(*db.ReservedConnectionsRow) {},
+			expected: check.SeverityOK,
+			contains: "at most 95 connection(s)",
+		},
+		{
+			name:     "no superuser reserve",
+			modify:   func(r *db.ReservedConnectionsRow) { r.SuperuserReservedConnections = 0; r.ReservedConnections = 0 },
+			expected: check.SeverityFail,
+			contains: "at most 100 connection(s)",
+		},
+		{
+			name:     "no reserved_connections on PG16",
+			modify:   func(r *db.ReservedConnectionsRow) { r.ReservedConnections = 0 },
+			expected: check.SeverityWarn,
+			contains: "reserved_connections is 0",
+		},
+		{
+			name: "reserved_connections not available before PG16",
+			modify: func(r *db.ReservedConnectionsRow) {
+				r.ReservedConnections = 0
+				r.SupportsReservedConnections = false
+			},
+			expected: check.SeverityOK,
+			contains: "superuser_reserved_connections=3:",
+		},
+		{
+			name:     "application role in reserved pool",
+			modify:   func(r *db.ReservedConnectionsRow) { r.ReservedPoolRoles = "app_rw" },
+			expected: check.SeverityWarn,
+			contains: "app_rw",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			row := healthyReserved()
+			tt.modify(&row)
+			mock := &mockQueries{stats: healthyStats(), reserved: &row}
+
+			report, err := connectionhealth.New(mock).Check(context.Background())
+			require.NoError(t, err)
+			require.True(t, hasResult(report.Results, "reserved-connections", tt.expected))
+
+			for _, r := range report.Results {
+				if r.ID == "reserved-connections" {
+					require.Contains(t, r.Details, tt.contains)
+				}
+			}
+		})
+	}
+}
+
+func TestRoleConnectionLimits(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		roles    []db.RoleConnectionsRow
+		expected check.Severity
+		contains string
+	}{
+		{
+			name:     "no roles connected",
+			expected: check.SeverityOK,
+		},
+		{
+			name:     "well within limits",
+			roles:    []db.RoleConnectionsRow{{RoleName: "app", ConnectionLimit: 50, Connections: 20}, {RoleName: "etl", ConnectionLimit: -1, Connections: 10}},
+			expected: check.SeverityOK,
+		},
+		{
+			name:     "near role limit",
+			roles:    []db.RoleConnectionsRow{{RoleName: "app", ConnectionLimit: 50, Connections: 46}},
+			expected: check.SeverityWarn,
+			contains: "1 role(s)",
+		},
+		{
+			name:     "at role limit",
+			roles:    []db.RoleConnectionsRow{{RoleName: "app", ConnectionLimit: 50, Connections: 50}},
+			expected: check.SeverityFail,
+			contains: "refused",
+		},
+		{
+			name:     "unlimited role dominating max_connections",
+			roles:    []db.RoleConnectionsRow{{RoleName: "etl", ConnectionLimit: -1, Connections: 30}},
+			expected: check.SeverityWarn,
+			contains: "Unlimited role(s) etl",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mock := &mockQueries{stats: healthyStats(), roles: tt.roles}
+
+			report, err := connectionhealth.New(mock).Check(context.Background())
+			require.NoError(t, err)
+			require.True(t, hasResult(report.Results, "role-connection-limits", tt.expected))
+
+			for _, r := range report.Results {
+				if r.ID == "role-connection-limits" {
+					require.Contains(t, r.Details, tt.contains)
+				}
+			}
+		})
+	}
+}
+
+func TestInactiveDatabases(t *testing.T) {
+	t.Parallel()
+
+	const day = 86400.0
+
+	tests := []struct {
+		name     string
+		rows     []db.InactiveDatabasesRow
+		expected check.Severity
+		contains string
+		tableLen int
+	}{
+		{
+			name:     "no idle databases",
+			expected: check.SeverityOK,
+			contains: "No other database is idle",
+		},
+		{
+			name:     "busy database without current connections",
+			rows:     []db.InactiveDatabasesRow{{DatabaseName: "reports", SizeBytes: int64Val(1 << 30), Transactions: 5000, StatsAgeSeconds: 30 * day}},
+			expected: check.SeverityOK,
+		},
+		{
+			name:     "statistics too recent",
+			rows:     []db.InactiveDatabasesRow{{DatabaseName: "legacy", SizeBytes: int64Val(1 << 30), StatsAgeSeconds: 2 * day}},
+			expected: check.SeverityOK,
+			contains: "1 database(s) without connections have less than 7 days",
+		},
+		{
+			name: "idle databases",
+			rows: []db.InactiveDatabasesRow{
+				{DatabaseName: "legacy", SizeBytes: int64Val(3 << 30), Transactions: 12, StatsAgeSeconds: 60 * day},
+				{DatabaseName: "scratch", Transactions: 0, StatsAgeSeconds: 60 * day},
+				{DatabaseName: "reports", SizeBytes: int64Val(1 << 30), Transactions: 5000, StatsAgeSeconds: 30 * day},
+			},
+			expected: check.SeverityWarn,
+			contains: "2 database(s) have no connections",
+			tableLen: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mock := &mockQueries{stats: healthyStats(), databases: tt.rows}
+
+			report, err := connectionhealth.New(mock).Check(context.Background())
+			require.NoError(t, err)
+			require.True(t, hasResult(report.Results, "inactive-databases", tt.expected))
+
+			finding := checktest.Finding(t, report, "inactive-databases")
+			require.Contains(t, finding.Details, tt.contains)
+			if tt.tableLen == 0 {
+				require.Nil(t, finding.Table)
+				return
+			}
+			require.Len(t, finding.Table.Rows, tt.tableLen)
+			require.Equal(t, []string{"legacy", "3.0GiB", "12", "0.2", "60"}, finding.Table.Rows[0].Cells)
+			require.Equal(t, "unknown", finding.Table.Rows[1].Cells[1])
+			require.Equal(t, check.ConfidenceMedium, finding.Confidence)
+		})
+	}
+}
@@ -51,3 +51,59 @@ WHERE
   AND pid != pg_backend_pid()
   AND (now() - state_change) > interval '30 minutes'
 ORDER BY state_change ASC;
+
+-- name: ReservedConnections :one
+-- Connection slots held back from ordinary roles, and the non-superuser login
+-- roles outside pg_monitor that may use the PG16+ reserved_connections pool.
+SELECT
+  current_setting('max_connections')::integer AS max_connections
+  , current_setting('superuser_reserved_connections')::integer AS superuser_reserved_connections
+  , COALESCE(current_setting('reserved_connections', true), '0')::integer AS reserved_connections
+  , (current_setting('server_version_num')::integer >= 160000) AS supports_reserved_connections
+  , COALESCE((
+    SELECT string_agg(r.rolname, ', ' ORDER BY r.rolname)
+    FROM pg_roles AS r
+    WHERE
+      r.rolcanlogin
+      AND NOT r.rolsuper
+      AND to_regrole('pg_use_reserved_connections') IS NOT NULL
+      AND pg_has_role(r.oid, to_regrole('pg_use_reserved_connections'), 'MEMBER')
+      AND NOT pg_has_role(r.oid, 'pg_monitor', 'MEMBER')
+  ), '')::text AS reserved_pool_roles;
+
+-- name: RoleConnections :many
+-- Current connections per non-superuser login role against its CONNECTION LIMIT
+-- (-1 = unlimited). Superusers are not subject to role connection limits.
+SELECT
+  r.rolname::text AS role_name
+  , r.rolconnlimit::integer AS connection_limit
+  , COUNT(a.pid)::bigint AS connections
+FROM pg_roles AS r
+LEFT JOIN pg_stat_activity AS a ON a.usesysid = r.oid AND a.backend_type = 'client backend'
+WHERE
+  r.rolcanlogin
+  AND NOT r.rolsuper
+GROUP BY r.rolname, r.rolconnlimit
+HAVING COUNT(a.pid) > 0
+ORDER BY connections DESC, role_name;
+
+-- name: InactiveDatabases :many
+-- Connectable databases other than the current one with no open connections,
+-- with their transactions and the age of their statistics. The size is NULL
+-- when the role can neither connect to the database nor read all stats.
+SELECT
+  d.datname::text AS database_name
+  , CASE
+    WHEN HAS_DATABASE_PRIVILEGE(d.oid, 'CONNECT') OR PG_HAS_ROLE('pg_read_all_stats', 'MEMBER')
+      THEN PG_DATABASE_SIZE(d.oid)
+  END::bigint AS size_bytes
+  , (sd.xact_commit + sd.xact_rollback)::bigint AS transactions
+  , EXTRACT(EPOCH FROM NOW() - COALESCE(sd.stats_reset, PG_POSTMASTER_START_TIME()))::float8 AS stats_age_seconds
+FROM pg_database AS d
+INNER JOIN pg_stat_database AS sd ON d.oid = sd.datid
+WHERE
+  NOT d.datistemplate
+  AND d.datallowconn
+  AND d.datname NOT IN ('postgres', CURRENT_DATABASE())
+  AND sd.numbackends = 0
+ORDER BY d.datname;
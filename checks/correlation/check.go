@@ -0,0 +1,202 @@
+// Package correlation implements checks for physical row order versus index order.
+package correlation
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"math"
+	"slices"
+
+	"github.com/fresha/pgdoctor/check"
+	"github.com/fresha/pgdoctor/db"
+)
+
+//go:embed query.sql
+var querySQL string
+
+//go:embed README.md
+var readme string
+
+const (
+	// Below this size the table is likely cached and random heap access is cheap.
+	minTableBytes = int64(1024 * 1024 * 1024) // 1GiB
+	// Average tuples read per index scan at which an index serves range scans
+	// rather than point lookups.
+	minTuplesPerScan = 10.0
+
+	// Absolute correlation of the leading index column with heap order.
+	correlationWarn = 0.5
+	correlationFail = 0.2
+
+	// A B-tree on a column this well ordered could be a much smaller BRIN index.
+	brinMinCorrelation = 0.95
+	brinMinIndexBytes  = int64(100 * 1024 * 1024) // 100MiB
+)
+
+type CorrelationQueries interface {
+	IndexCorrelation(context.Context) ([]db.IndexCorrelationRow, error)
+}
+
+type checker struct {
+	queries CorrelationQueries
+}
+
+func Metadata() check.Metadata {
+	return check.Metadata{
+		Category:       check.CategoryPerformance,
+		CheckID:        "correlation",
+		Name:           "Index Correlation",
+		Description:    "Detects range-scanned indexes whose column order no longer matches the physical row order",
+		Readme:         readme,
+		SQL:            querySQL,
+		RuntimeClass:   check.RuntimeMedium,
+		ProductionSafe: true,
+		Privileges:     []check.Privilege{check.PrivilegeReadSchemas},
+		Findings: []check.FindingSpec{
+			{ID: "decorrelated-range-scans", Description: "Range-scanned B-tree indexes (>= 10 tuples/scan) on tables >= 1GiB whose leading column is poorly correlated with heap order", Thresholds: "WARN |correlation| < 0.5, FAIL < 0.2"},
+			{ID: "brin-candidates", Description: "Large range-scanned B-tree indexes on columns in near-perfect physical order that BRIN could replace (|correlation| >= 0.95, index >= 100MiB)"},
+		},
+	}
+}
+
+func New(queries CorrelationQueries, _ ...check.Config) check.Checker {
+	return &checker{
+		queries: queries,
+	}
+}
+
+func (c *checker) Metadata() check.Metadata {
+	return Metadata()
+}
+
+func (c *checker) Check(ctx context.Context) (*check.Report, error) {
+	report := check.NewReport(Metadata())
+	ignore := check.ObjectFilterFromContext(ctx)
+
+	rows, err := c.queries.IndexCorrelation(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("running %s/%s: %w", report.Category, report.CheckID, err)
+	}
+	rows = slices.DeleteFunc(rows, func(row db.IndexCorrelationRow) bool {
+		return ignore.QualifiedTableIndex(row.TableName, row.IndexName)
+	})
+
+	var rangeScanned []db.IndexCorrelationRow
+	for _, row := range rows {
+		if isRangeScanned(row) {
+			rangeScanned = append(rangeScanned, row)
+		}
+	}
+
+	checkDecorrelated(rangeScanned, report)
+	checkBrinCandidates(rangeScanned, report)
+
+	return report, nil
+}
+
+func tuplesPerScan(row db.IndexCorrelationRow) float64 {
+	if row.IdxScan == 0 {
+		return 0
+	}
+	return float64(row.IdxTupRead) / float64(row.IdxScan)
+}
+
+func isRangeScanned(row db.IndexCorrelationRow) bool {
+	return row.TableBytes >= minTableBytes && tuplesPerScan(row) >= minTuplesPerScan
+}
+
+func checkDecorrelated(rows []db.IndexCorrelationRow, report *check.Report) {
+	var tableRows []check.TableRow
+	severity := check.SeverityOK
+
+	for _, row := range rows {
+		corr := math.Abs(row.Correlation)
+		if corr >= correlationWarn {
+			continue
+		}
+		rowSeverity := check.SeverityWarn
+		if corr < correlationFail {
+			rowSeverity = check.SeverityFail
+		}
+		severity = max(severity, rowSeverity)
+
+		tableRows = append(tableRows, check.TableRow{
+			Cells: []string{
+				row.TableName,
+				row.IndexName,
+				row.ColumnName,
+				fmt.Sprintf("%.2f", row.Correlation),
+				fmt.Sprintf("%.0f", tuplesPerScan(row)),
+				check.FormatBytes(row.TableBytes),
+				check.FormatNumber(row.TableChurn),
+			},
+			Severity: rowSeverity,
+		})
+	}
+
+	if len(tableRows) == 0 {
+		report.AddFinding(check.Finding{
+			ID:       "decorrelated-range-scans",
+			Name:     "Decorrelated Range Scans",
+			Severity: check.SeverityOK,
+			Details:  fmt.Sprintf("All %d range-scanned index(es) on large tables follow the physical row order closely", len(rows)),
+		})
+		return
+	}
+
+	report.AddFinding(check.Finding{
+		ID:       "decorrelated-range-scans",
+		Name:     "Decorrelated Range Scans",
+		Severity: severity,
+		Details: fmt.Sprintf("%d range-scanned index(es) read rows scattered across the table: each matching row can cost a separate heap page read. "+
+			"Reorder the table with pg_repack --order-by (online) or CLUSTER (ACCESS EXCLUSIVE lock); on tables with heavy update/delete churn the order decays again",
+			len(tableRows)),
+		Table: &check.Table{
+			Headers: []string{"Table", "Index", "Column", "Correlation", "Tuples/Scan", "Table Size", "Updates+Deletes"},
+			Rows:    tableRows,
+		},
+	})
+}
+
+func checkBrinCandidates(rows []db.IndexCorrelationRow, report *check.Report) {
+	var tableRows []check.TableRow
+	for _, row := range rows {
+		if math.Abs(row.Correlation) < brinMinCorrelation || row.IndexBytes < brinMinIndexBytes {
+			continue
+		}
+		tableRows = append(tableRows, check.TableRow{
+			Cells: []string{
+				row.TableName,
+				row.IndexName,
+				row.ColumnName,
+				fmt.Sprintf("%.2f", row.Correlation),
+				check.FormatBytes(row.IndexBytes),
+			},
+			Severity: check.SeverityOK,
+		})
+	}
+
+	if len(tableRows) == 0 {
+		report.AddFinding(check.Finding{
+			ID:       "brin-candidates",
+			Name:     "BRIN Candidates",
+			Severity: check.SeverityOK,
+			Details:  "No large range-scanned B-tree indexes on naturally ordered columns",
+		})
+		return
+	}
+
+	report.AddFinding(check.Finding{
+		ID:       "brin-candidates",
+		Name:     "BRIN Candidates",
+		Severity: check.SeverityOK,
+		Details: fmt.Sprintf("%d large B-tree index(es) serve range scans on columns stored in near-perfect order (typically append-only timestamps or IDs). "+
+			"A BRIN index is orders of magnitude smaller for the same range queries, if point lookups and uniqueness are not needed",
+			len(tableRows)),
+		Table: &check.Table{
+			Headers: []string{"Table", "Index", "Column", "Correlation", "Index Size"},
+			Rows:    tableRows,
+		},
+	})
+}
@@ -0,0 +1,115 @@
+package correlation_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/fresha/pgdoctor/check"
+	"github.com/fresha/pgdoctor/check/checktest"
+	"github.com/fresha/pgdoctor/checks/correlation"
+	"github.com/fresha/pgdoctor/db"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	mb = int64(1024 * 1024)
+	gb = 1024 * mb
+)
+
+type mockQueryer struct {
+	rows []db.IndexCorrelationRow
+	err  error
+}
+
+func (m *mockQueryer) IndexCorrelation(context.Context) ([]db.IndexCorrelationRow, error) {
+	return m.rows, m.err
+}
+
+func rangeScanned(corr float64) db.IndexCorrelationRow {
+	return db.IndexCorrelationRow{
+		TableName:   "public.bookings",
+		IndexName:   "bookings_starts_at_idx",
+		ColumnName:  "starts_at",
+		Correlation: corr,
+		TableBytes:  20 * gb,
+		IndexBytes:  50 * mb,
+		IdxScan:     1000,
+		IdxTupRead:  500_000,
+	}
+}
+
+func TestCorrelation_Empty(t *testing.T) {
+	t.Parallel()
+
+	report, err := correlation.New(&mockQueryer{}).Check(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, check.SeverityOK, report.Severity)
+	assert.Len(t, report.Results, 2)
+}
+
+func TestCorrelation_Decorrelated(t *testing.T) {
+	t.Parallel()
+
+	smallTable := rangeScanned(0.01)
+	smallTable.TableBytes = 100 * mb
+
+	pointLookups := rangeScanned(0.01)
+	pointLookups.IdxTupRead = 1000
+
+	tests := []struct {
+		name     string
+		row      db.IndexCorrelationRow
+		expected check.Severity
+	}{
+		{name: "well ordered", row: rangeScanned(0.9), expected: check.SeverityOK},
+		{name: "descending order counts as ordered", row: rangeScanned(-0.9), expected: check.SeverityOK},
+		{name: "partially decorrelated", row: rangeScanned(0.35), expected: check.SeverityWarn},
+		{name: "random order", row: rangeScanned(-0.05), expected: check.SeverityFail},
+		{name: "small table ignored", row: smallTable, expected: check.SeverityOK},
+		{name: "point lookups ignored", row: pointLookups, expected: check.SeverityOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			report, err := correlation.New(&mockQueryer{rows: []db.IndexCorrelationRow{tt.row}}).Check(context.Background())
+			require.NoError(t, err)
+
+			f := checktest.Finding(t, report, "decorrelated-range-scans")
+			assert.Equal(t, tt.expected, f.Severity)
+			if tt.expected != check.SeverityOK {
+				require.NotNil(t, f.Table)
+				assert.Equal(t, "bookings_starts_at_idx", f.Table.Rows[0].Cells[1])
+				assert.Contains(t, f.Details, "pg_repack")
+			}
+		})
+	}
+}
+
+func TestCorrelation_BrinCandidates(t *testing.T) {
+	t.Parallel()
+
+	candidate := rangeScanned(0.99)
+	candidate.IndexBytes = 2 * gb
+
+	report, err := correlation.New(&mockQueryer{rows: []db.IndexCorrelationRow{candidate, rangeScanned(0.99)}}).Check(context.Background())
+	require.NoError(t, err)
+
+	f := checktest.Finding(t, report, "brin-candidates")
+	assert.Equal(t, check.SeverityOK, f.Severity)
+	require.NotNil(t, f.Table)
+	assert.Len(t, f.Table.Rows, 1)
+	assert.Equal(t, "2.0GiB", f.Table.Rows[0].Cells[4])
+}
+
+func TestCorrelation_QueryError(t *testing.T) {
+	t.Parallel()
+
+	_, err := correlation.New(&mockQueryer{err: errors.New("connection refused")}).Check(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "correlation")
+}
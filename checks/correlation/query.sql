@@ -0,0 +1,33 @@
+-- name: IndexCorrelation :many
+-- Physical-order correlation of the leading column of each B-tree index on the
+-- largest tables, with scan statistics to tell range scans from point lookups.
+-- Excludes: expression indexes and columns without statistics.
+SELECT
+  (n.nspname || '.' || t.relname)::text AS table_name
+  , i.relname::text AS index_name
+  , a.attname::text AS column_name
+  , s.correlation::float8 AS correlation
+  , pg_table_size(t.oid)::bigint AS table_bytes
+  , pg_relation_size(i.oid)::bigint AS index_bytes
+  , COALESCE(psi.idx_scan, 0)::bigint AS idx_scan
+  , COALESCE(psi.idx_tup_read, 0)::bigint AS idx_tup_read
+  , (COALESCE(pst.n_tup_upd, 0) + COALESCE(pst.n_tup_del, 0))::bigint AS table_churn
+FROM pg_index AS x
+INNER JOIN pg_class AS i ON x.indexrelid = i.oid
+INNER JOIN pg_am AS am ON i.relam = am.oid
+INNER JOIN pg_class AS t ON x.indrelid = t.oid
+INNER JOIN pg_namespace AS n ON t.relnamespace = n.oid
+INNER JOIN pg_attribute AS a ON a.attrelid = t.oid AND a.attnum = x.indkey[0]
+INNER JOIN pg_stats AS s
+  ON s.schemaname = n.nspname AND s.tablename = t.relname AND s.attname = a.attname
+LEFT JOIN pg_stat_user_indexes AS psi ON x.indexrelid = psi.indexrelid
+LEFT JOIN pg_stat_user_tables AS pst ON t.oid = pst.relid
+WHERE
+  am.amname = 'btree'
+  AND x.indkey[0] <> 0
+  AND s.correlation IS NOT NULL
+  AND NOT s.inherited
+  AND n.nspname NOT IN ('pg_catalog', 'information_schema')
+  AND n.nspname !~ '^pg_toast'
+ORDER BY pg_table_size(t.oid) DESC, i.relname
+LIMIT 100;
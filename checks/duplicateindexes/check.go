@@ -5,6 +5,7 @@ import (
 	"context"
 	_ "embed"
 	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/fresha/pgdoctor/check"
@@ -31,12 +32,18 @@ type checker struct {
 
 func Metadata() check.Metadata {
 	return check.Metadata{
-		Category:    check.CategoryIndexes,
-		CheckID:     "duplicate-indexes",
-		Name:        "Duplicate Indexes",
-		Description: "Identifies exact and prefix duplicate indexes wasting disk space",
-		Readme:      readme,
-		SQL:         querySQL,
+		Category:       check.CategoryIndexes,
+		CheckID:        "duplicate-indexes",
+		Name:           "Duplicate Indexes",
+		Description:    "Identifies exact and prefix duplicate indexes wasting disk space",
+		Readme:         readme,
+		SQL:            querySQL,
+		RuntimeClass:   check.RuntimeHeavy,
+		ProductionSafe: true,
+		Findings: []check.FindingSpec{
+			{ID: "exact-duplicates", Description: "Indexes with identical definitions on the same table", Thresholds: "WARN"},
+			{ID: "prefix-duplicates", Description: "Indexes whose columns are a leading prefix of another index", Thresholds: "WARN"},
+		},
 	}
 }
 
@@ -52,11 +59,16 @@ func (c *checker) Metadata() check.Metadata {
 
 func (c *checker) Check(ctx context.Context) (*check.Report, error) {
 	report := check.NewReport(Metadata())
+	ignore := check.ObjectFilterFromContext(ctx)
 
 	rows, err := c.queries.DuplicateIndexes(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("running %s/%s: %w", report.Category, report.CheckID, err)
 	}
+	rows = slices.DeleteFunc(rows, func(row db.DuplicateIndexesRow) bool {
+		return ignore.QualifiedTableIndex(row.TableName.String, row.IndexNameA.String) ||
+			ignore.QualifiedTableIndex(row.TableName.String, row.IndexNameB.String)
+	})
 
 	if len(rows) == 0 {
 		report.AddFinding(check.Finding{
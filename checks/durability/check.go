@@ -0,0 +1,252 @@
+// Package durability implements checks for settings and table options that
+// trade crash safety or space for speed: unlogged tables, fillfactor, and
+// synchronous_commit = off.
+package durability
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"slices"
+
+	"github.com/fresha/pgdoctor/check"
+	"github.com/fresha/pgdoctor/db"
+	"github.com/fresha/pgdoctor/internal/pgident"
+)
+
+//go:embed query.sql
+var querySQL string
+
+//go:embed README.md
+var readme string
+
+const (
+	// A fillfactor this low leaves most of every page empty.
+	lowFillfactor = 50
+
+	// Tables with a reduced fillfactor whose updates are rare compared with
+	// inserts never use the space it reserves. Only tables large enough for
+	// the waste to matter, with enough writes to judge, are flagged.
+	unusedFillfactorUpdateRatio = 0.05
+	unusedFillfactorMinInserts  = int64(10000)
+	unusedFillfactorMinBytes    = int64(100 * 1024 * 1024)
+)
+
+type DurabilityQueries interface {
+	DurabilityTables(context.Context) ([]db.DurabilityTablesRow, error)
+	DurabilitySynchronousCommit(context.Context) ([]db.DurabilitySynchronousCommitRow, error)
+}
+
+type checker struct {
+	queries DurabilityQueries
+}
+
+func Metadata() check.Metadata {
+	return check.Metadata{
+		Category:       check.CategoryConfigs,
+		CheckID:        "durability",
+		Name:           "Durability",
+		Description:    "Flags unlogged tables, fillfactor settings that waste space, and synchronous_commit = off at the server, database, or role level",
+		Readme:         readme,
+		SQL:            querySQL,
+		RuntimeClass:   check.RuntimeMedium,
+		ProductionSafe: true,
+		Findings: []check.FindingSpec{
+			{ID: "unlogged-tables", Description: "Unlogged tables, which are emptied by crash recovery and are empty on standbys after failover", Thresholds: "WARN"},
+			{ID: "fillfactor", Description: "Tables with fillfactor below 50, or below 100 on 100MB+ tables that are rarely updated", Thresholds: "WARN"},
+			{ID: "synchronous-commit-off", Description: "synchronous_commit = off in the server configuration or a database or role override", Thresholds: "WARN"},
+		},
+	}
+}
+
+func New(queries DurabilityQueries, _ ...check.Config) check.Checker {
+	return &checker{
+		queries: queries,
+	}
+}
+
+func (c *checker) Metadata() check.Metadata {
+	return Metadata()
+}
+
+func (c *checker) Check(ctx context.Context) (*check.Report, error) {
+	report := check.NewReport(Metadata())
+	ignore := check.ObjectFilterFromContext(ctx)
+
+	tables, err := c.queries.DurabilityTables(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("running %s/%s (tables): %w", report.Category, report.CheckID, err)
+	}
+	tables = slices.DeleteFunc(tables, func(row db.DurabilityTablesRow) bool {
+		return ignore.Table(row.SchemaName, row.TableName)
+	})
+
+	syncCommit, err := c.queries.DurabilitySynchronousCommit(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("running %s/%s (synchronous_commit): %w", report.Category, report.CheckID, err)
+	}
+
+	checkUnloggedTables(tables, report)
+	checkFillfactor(tables, report)
+	checkSynchronousCommit(syncCommit, report)
+
+	return report, nil
+}
+
+func tableName(row db.DurabilityTablesRow) string {
+	return row.SchemaName + "." + row.TableName
+}
+
+func checkUnloggedTables(tables []db.DurabilityTablesRow, report *check.Report) {
+	var tableRows []check.TableRow
+	var fixes []check.Fix
+	var total int64
+	for _, t := range tables {
+		if !t.IsUnlogged {
+			continue
+		}
+		total += t.TableSizeBytes
+		tableRows = append(tableRows, check.TableRow{
+			Cells:    []string{tableName(t), check.FormatBytes(t.TableSizeBytes), check.FormatNumber(t.EstimatedRows)},
+			Severity: check.SeverityWarn,
+		})
+		fixes = append(fixes, check.Fix{
+			Object: tableName(t),
+			Description: fmt.Sprintf("Make %s crash-safe and replicated. This rewrites the table and writes all of it to WAL under an ACCESS EXCLUSIVE lock; "+
+				"skip it if the table only holds data that can be rebuilt, such as a cache", tableName(t)),
+			SQL: fmt.Sprintf("ALTER TABLE %s SET LOGGED", pgident.Quote(t.SchemaName, t.TableName)),
+		})
+	}
+
+	if len(tableRows) == 0 {
+		report.AddFinding(check.Finding{
+			ID:       "unlogged-tables",
+			Name:     "Unlogged Tables",
+			Severity: check.SeverityOK,
+			Details:  "No unlogged tables",
+		})
+		return
+	}
+
+	report.AddFinding(check.Finding{
+		ID:       "unlogged-tables",
+		Name:     "Unlogged Tables",
+		Severity: check.SeverityWarn,
+		Details: fmt.Sprintf("%d unlogged table(s) hold %s. Writes to them skip WAL, so crash recovery empties them, physical standbys only have empty copies "+
+			"(a failover or promoted replica starts with no rows), and backups restored by WAL replay do not contain their data. "+
+			"Keep them only for data that can be rebuilt; see orphaned-temp for tables already emptied by a crash", len(tableRows), check.FormatBytes(total)),
+		Table: &check.Table{
+			Headers: []string{"Table", "Size", "Rows"},
+			Rows:    tableRows,
+		},
+		Fixes: fixes,
+	})
+}
+
+func checkFillfactor(tables []db.DurabilityTablesRow, report *check.Report) {
+	var tableRows []check.TableRow
+	var fixes []check.Fix
+	for _, t := range tables {
+		if t.Fillfactor <= 0 || t.Fillfactor >= 100 {
+			continue
+		}
+
+		var issue string
+		switch {
+		case t.Fillfactor < lowFillfactor:
+			issue = fmt.Sprintf("every page is left %d%% empty", 100-t.Fillfactor)
+		case t.TableSizeBytes >= unusedFillfactorMinBytes && t.Inserts >= unusedFillfactorMinInserts &&
+			float64(t.Updates) < float64(t.Inserts)*unusedFillfactorUpdateRatio:
+			issue = "rarely updated, so the reserved space is never used"
+			fixes = append(fixes, check.Fix{
+				Object: tableName(t),
+				Description: fmt.Sprintf("Fill new pages of %s completely. Existing pages keep their free space until the table is rewritten (VACUUM FULL or pg_repack)",
+					tableName(t)),
+				SQL:  fmt.Sprintf("ALTER TABLE %s SET (fillfactor = 100)", pgident.Quote(t.SchemaName, t.TableName)),
+				Risk: check.RiskLow,
+				Lock: check.LockOnline,
+			})
+		default:
+			continue
+		}
+
+		tableRows = append(tableRows, check.TableRow{
+			Cells: []string{
+				tableName(t),
+				fmt.Sprintf("%d", t.Fillfactor),
+				check.FormatBytes(t.TableSizeBytes),
+				check.FormatNumber(t.Inserts),
+				check.FormatNumber(t.Updates),
+				issue,
+			},
+			Severity: check.SeverityWarn,
+		})
+	}
+
+	if len(tableRows) == 0 {
+		report.AddFinding(check.Finding{
+			ID:       "fillfactor",
+			Name:     "Fillfactor",
+			Severity: check.SeverityOK,
+			Details:  "No table has a fillfactor that wastes space",
+		})
+		return
+	}
+
+	report.AddFinding(check.Finding{
+		ID:       "fillfactor",
+		Name:     "Fillfactor",
+		Severity: check.SeverityWarn,
+		Details: fmt.Sprintf("%d table(s) leave page space that is unlikely to pay off. A fillfactor below 100 reserves room on each page for HOT updates; "+
+			"on tables that are mostly inserted into it only makes the table and every sequential scan larger. "+
+			"Values below %d are rarely justified even for update-heavy tables; compare with the HOT ratio in table-activity before lowering one further",
+			len(tableRows), lowFillfactor),
+		Table: &check.Table{
+			Headers: []string{"Table", "Fillfactor", "Size", "Inserts", "Updates", "Issue"},
+			Rows:    tableRows,
+		},
+		Fixes: fixes,
+	})
+}
+
+func checkSynchronousCommit(rows []db.DurabilitySynchronousCommitRow, report *check.Report) {
+	if len(rows) == 0 {
+		report.AddFinding(check.Finding{
+			ID:       "synchronous-commit-off",
+			Name:     "Asynchronous Commit",
+			Severity: check.SeverityOK,
+			Details:  "synchronous_commit is not off at the server, database, or role level",
+		})
+		return
+	}
+
+	var tableRows []check.TableRow
+	for _, r := range rows {
+		database, role := r.DatabaseName, r.RoleName
+		if database == "" {
+			database = "-"
+		}
+		if role == "" {
+			role = "-"
+		}
+		tableRows = append(tableRows, check.TableRow{
+			Cells:    []string{r.Scope, database, role, r.Source},
+			Severity: check.SeverityWarn,
+		})
+	}
+
+	details := fmt.Sprintf("synchronous_commit is off in %d place(s). ", len(rows)) + "Commits return before their WAL is flushed, " +
+		"so a server crash loses up to 3 x wal_writer_delay of transactions the application was told were committed (the database stays consistent). " +
+		"Keep it off only for sessions whose writes can be replayed, setting it per transaction with SET LOCAL synchronous_commit = off rather than for a whole database or role"
+
+	report.AddFinding(check.Finding{
+		ID:       "synchronous-commit-off",
+		Name:     "Asynchronous Commit",
+		Severity: check.SeverityWarn,
+		Details:  details,
+		Table: &check.Table{
+			Headers: []string{"Scope", "Database", "Role", "Source"},
+			Rows:    tableRows,
+		},
+	})
+}
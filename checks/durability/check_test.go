@@ -0,0 +1,159 @@
+package durability_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/fresha/pgdoctor/check"
+	"github.com/fresha/pgdoctor/check/checktest"
+	"github.com/fresha/pgdoctor/checks/durability"
+	"github.com/fresha/pgdoctor/db"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockQueryer struct {
+	tables     []db.DurabilityTablesRow
+	syncCommit []db.DurabilitySynchronousCommitRow
+	err        error
+}
+
+func (m *mockQueryer) DurabilityTables(context.Context) ([]db.DurabilityTablesRow, error) {
+	return m.tables, m.err
+}
+
+func (m *mockQueryer) DurabilitySynchronousCommit(context.Context) ([]db.DurabilitySynchronousCommitRow, error) {
+	return m.syncCommit, nil
+}
+
+func TestDurability_Healthy(t *testing.T) {
+	t.Parallel()
+
+	q := &mockQueryer{tables: []db.DurabilityTablesRow{
+		{SchemaName: "public", TableName: "accounts", Fillfactor: 80, TableSizeBytes: 500 << 20, Inserts: 100000, Updates: 900000},
+	}}
+
+	report, err := durability.New(q).Check(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, check.SeverityOK, report.Severity)
+	assert.Len(t, report.Results, 3)
+}
+
+func TestDurability_UnloggedTables(t *testing.T) {
+	t.Parallel()
+
+	q := &mockQueryer{tables: []db.DurabilityTablesRow{
+		{SchemaName: "public", TableName: "session_cache", IsUnlogged: true, TableSizeBytes: 2 << 30, EstimatedRows: 1500000},
+		{SchemaName: "public", TableName: "accounts", Fillfactor: 90},
+	}}
+
+	report, err := durability.New(q).Check(context.Background())
+	require.NoError(t, err)
+
+	f := checktest.Finding(t, report, "unlogged-tables")
+	assert.Equal(t, check.SeverityWarn, f.Severity)
+	assert.Contains(t, f.Details, "1 unlogged table(s) hold 2.0GiB")
+	assert.Contains(t, f.Details, "failover")
+	require.NotNil(t, f.Table)
+	assert.Len(t, f.Table.Rows, 1)
+	require.Len(t, f.Fixes, 1)
+	assert.Equal(t, `ALTER TABLE "public"."session_cache" SET LOGGED`, f.Fixes[0].SQL)
+	assert.Equal(t, check.LockExclusive, f.Fixes[0].Lock)
+	assert.Equal(t, check.RiskHigh, f.Fixes[0].Risk)
+}
+
+func TestDurability_Fillfactor(t *testing.T) {
+	t.Parallel()
+
+	const mb = int64(1 << 20)
+
+	tests := []struct {
+		name     string
+		row      db.DurabilityTablesRow
+		expected check.Severity
+		fixes    int
+		contains string
+	}{
+		{
+			name:     "default",
+			row:      db.DurabilityTablesRow{Fillfactor: 0, TableSizeBytes: 500 * mb, Inserts: 1000000},
+			expected: check.SeverityOK,
+		},
+		{
+			name:     "very low",
+			row:      db.DurabilityTablesRow{Fillfactor: 30, TableSizeBytes: 10 * mb, Updates: 1000000},
+			expected: check.SeverityWarn,
+			contains: "every page is left 70% empty",
+		},
+		{
+			name:     "insert only",
+			row:      db.DurabilityTablesRow{Fillfactor: 70, TableSizeBytes: 500 * mb, Inserts: 1000000, Updates: 100},
+			expected: check.SeverityWarn,
+			fixes:    1,
+			contains: "rarely updated",
+		},
+		{
+			name:     "insert only but small",
+			row:      db.DurabilityTablesRow{Fillfactor: 70, TableSizeBytes: 10 * mb, Inserts: 1000000},
+			expected: check.SeverityOK,
+		},
+		{
+			name:     "update heavy",
+			row:      db.DurabilityTablesRow{Fillfactor: 70, TableSizeBytes: 500 * mb, Inserts: 1000000, Updates: 500000},
+			expected: check.SeverityOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			row := tt.row
+			row.SchemaName, row.TableName = "public", "events"
+
+			report, err := durability.New(&mockQueryer{tables: []db.DurabilityTablesRow{row}}).Check(context.Background())
+			require.NoError(t, err)
+
+			f := checktest.Finding(t, report, "fillfactor")
+			assert.Equal(t, tt.expected, f.Severity)
+			require.Len(t, f.Fixes, tt.fixes)
+			if tt.fixes > 0 {
+				assert.Equal(t, `ALTER TABLE "public"."events" SET (fillfactor = 100)`, f.Fixes[0].SQL)
+				assert.Equal(t, check.LockOnline, f.Fixes[0].Lock)
+			}
+			if tt.contains != "" {
+				require.NotNil(t, f.Table)
+				assert.Contains(t, f.Table.Rows[0].Cells[5], tt.contains)
+			}
+		})
+	}
+}
+
+func TestDurability_SynchronousCommit(t *testing.T) {
+	t.Parallel()
+
+	q := &mockQueryer{syncCommit: []db.DurabilitySynchronousCommitRow{
+		{Scope: "server", Source: "configuration file"},
+		{Scope: "role", RoleName: "batch_loader", Source: "ALTER ... SET"},
+	}}
+
+	report, err := durability.New(q).Check(context.Background())
+	require.NoError(t, err)
+
+	f := checktest.Finding(t, report, "synchronous-commit-off")
+	assert.Equal(t, check.SeverityWarn, f.Severity)
+	assert.Contains(t, f.Details, "off in 2 place(s)")
+	require.NotNil(t, f.Table)
+	assert.Equal(t, []string{"server", "-", "-", "configuration file"}, f.Table.Rows[0].Cells)
+	assert.Equal(t, []string{"role", "-", "batch_loader", "ALTER ... SET"}, f.Table.Rows[1].Cells)
+}
+
+func TestDurability_QueryError(t *testing.T) {
+	t.Parallel()
+
+	_, err := durability.New(&mockQueryer{err: errors.New("connection refused")}).Check(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "durability")
+}
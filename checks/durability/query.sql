@@ -0,0 +1,55 @@
+-- name: DurabilityTables :many
+-- User tables that are unlogged or have a fillfactor set, with the write
+-- counters needed to tell whether the reserved page space is used.
+SELECT
+  n.nspname::text AS schema_name
+  , c.relname::text AS table_name
+  , (c.relpersistence = 'u') AS is_unlogged
+  , COALESCE(SUBSTRING(ARRAY_TO_STRING(c.reloptions, ',') FROM 'fillfactor=([0-9]+)')::integer, 0)::integer AS fillfactor
+  , PG_TABLE_SIZE(c.oid)::bigint AS table_size_bytes
+  , GREATEST(c.reltuples, 0)::bigint AS estimated_rows
+  , COALESCE(s.n_tup_ins, 0)::bigint AS inserts
+  , COALESCE(s.n_tup_upd, 0)::bigint AS updates
+FROM pg_class AS c
+INNER JOIN pg_namespace AS n ON c.relnamespace = n.oid
+LEFT JOIN pg_stat_user_tables AS s ON c.oid = s.relid
+WHERE
+  c.relkind IN ('r', 'p')
+  AND n.nspname NOT IN ('pg_catalog', 'information_schema')
+  AND n.nspname !~ '^pg_'
+  AND (
+    c.relpersistence = 'u'
+    OR ARRAY_TO_STRING(c.reloptions, ',') LIKE '%fillfactor=%'
+  )
+ORDER BY PG_TABLE_SIZE(c.oid) DESC
+LIMIT 200;
+
+-- name: DurabilitySynchronousCommit :many
+-- Places where synchronous_commit is off: the server setting (unless it
+-- comes from a database or role override) and ALTER DATABASE/ROLE ... SET.
+SELECT
+  'server'::text AS scope
+  , ''::text AS database_name
+  , ''::text AS role_name
+  , s.source::text AS source
+FROM pg_settings AS s
+WHERE
+  s.name = 'synchronous_commit'
+  AND s.setting = 'off'
+  AND s.source NOT IN ('database', 'user', 'database user', 'session', 'client')
+UNION ALL
+SELECT
+  (CASE
+    WHEN d.datname IS NOT NULL AND r.rolname IS NOT NULL THEN 'database and role'
+    WHEN d.datname IS NOT NULL THEN 'database'
+    ELSE 'role'
+  END)::text AS scope
+  , COALESCE(d.datname, '')::text AS database_name
+  , COALESCE(r.rolname, '')::text AS role_name
+  , 'ALTER ... SET'::text AS source
+FROM pg_db_role_setting AS rs
+CROSS JOIN LATERAL UNNEST(rs.setconfig) AS cfg (setting)
+LEFT JOIN pg_database AS d ON rs.setdatabase = d.oid
+LEFT JOIN pg_roles AS r ON rs.setrole = r.oid
+WHERE cfg.setting = 'synchronous_commit=off'
+ORDER BY scope DESC, database_name, role_name;
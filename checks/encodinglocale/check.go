@@ -0,0 +1,225 @@
+// Package encodinglocale implements checks for database encodings, locale
+// consistency across databases, and nondeterministic collations on indexes.
+package encodinglocale
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/fresha/pgdoctor/check"
+	"github.com/fresha/pgdoctor/db"
+)
+
+//go:embed query.sql
+var querySQL string
+
+//go:embed README.md
+var readme string
+
+type EncodingLocaleQueries interface {
+	DatabaseLocales(context.Context) ([]db.DatabaseLocalesRow, error)
+	NondeterministicIndexedColumns(context.Context) ([]db.NondeterministicIndexedColumnsRow, error)
+}
+
+type checker struct {
+	queries EncodingLocaleQueries
+}
+
+func Metadata() check.Metadata {
+	return check.Metadata{
+		Category:       check.CategoryConfigs,
+		CheckID:        "encoding-locale",
+		Name:           "Encoding and Locale",
+		Description:    "Checks database encodings, locale consistency across databases, and nondeterministic collations on indexed columns",
+		Readme:         readme,
+		SQL:            querySQL,
+		RuntimeClass:   check.RuntimeFast,
+		ProductionSafe: true,
+		Findings: []check.FindingSpec{
+			{ID: "sql-ascii-encoding", Description: "Databases using SQL_ASCII, which stores bytes without validating them against any encoding", Thresholds: "FAIL"},
+			{ID: "mixed-locales", Description: "Databases with different locale settings, or with LC_COLLATE differing from LC_CTYPE", Thresholds: "WARN"},
+			{ID: "nondeterministic-collations", Description: "Index key columns using a nondeterministic (e.g. case-insensitive ICU) collation", Thresholds: "WARN"},
+		},
+	}
+}
+
+func New(queries EncodingLocaleQueries, _ ...check.Config) check.Checker {
+	return &checker{
+		queries: queries,
+	}
+}
+
+func (c *checker) Metadata() check.Metadata {
+	return Metadata()
+}
+
+func (c *checker) Check(ctx context.Context) (*check.Report, error) {
+	report := check.NewReport(Metadata())
+	ignore := check.ObjectFilterFromContext(ctx)
+
+	databases, err := c.queries.DatabaseLocales(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("running %s/%s (databases): %w", report.Category, report.CheckID, err)
+	}
+
+	columns, err := c.queries.NondeterministicIndexedColumns(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("running %s/%s (collations): %w", report.Category, report.CheckID, err)
+	}
+	columns = slices.DeleteFunc(columns, func(row db.NondeterministicIndexedColumnsRow) bool {
+		return ignore.Index(row.SchemaName, row.TableName, row.IndexName)
+	})
+
+	checkSQLASCII(databases, report)
+	checkMixedLocales(databases, report)
+	checkNondeterministicCollations(columns, report)
+
+	return report, nil
+}
+
+func checkSQLASCII(databases []db.DatabaseLocalesRow, report *check.Report) {
+	var names []string
+	for _, d := range databases {
+		if d.Encoding == "SQL_ASCII" {
+			names = append(names, d.DatabaseName)
+		}
+	}
+
+	if len(names) == 0 {
+		report.AddFinding(check.Finding{
+			ID:       "sql-ascii-encoding",
+			Name:     "SQL_ASCII Encoding",
+			Severity: check.SeverityOK,
+			Details:  "No database uses SQL_ASCII",
+		})
+		return
+	}
+
+	report.AddFinding(check.Finding{
+		ID:       "sql-ascii-encoding",
+		Name:     "SQL_ASCII Encoding",
+		Severity: check.SeverityFail,
+		Details: fmt.Sprintf("%d database(s) use SQL_ASCII: %s. SQL_ASCII accepts any bytes without validation, so text in several encodings can end up mixed "+
+			"in one column, and upper(), lower() and pattern matching only understand ASCII. The encoding cannot be changed in place: "+
+			"dump the database, find and convert invalid sequences (e.g. with iconv), then restore into a new database created with ENCODING 'UTF8'. "+
+			"Expect restore errors for every row that is not valid UTF-8",
+			len(names), strings.Join(names, ", ")),
+	})
+}
+
+// localeKey identifies the settings that decide how a database sorts and
+// classifies text.
+func localeKey(d db.DatabaseLocalesRow) string {
+	key := d.LocaleProvider + " " + d.LcCollate + "/" + d.LcCtype
+	if d.IcuLocale != "" {
+		key += " " + d.IcuLocale
+	}
+	return key
+}
+
+func checkMixedLocales(databases []db.DatabaseLocalesRow, report *check.Report) {
+	keys := map[string]bool{}
+	splitLocale := 0
+	for _, d := range databases {
+		keys[localeKey(d)] = true
+		if d.LcCollate != d.LcCtype {
+			splitLocale++
+		}
+	}
+
+	if len(keys) <= 1 && splitLocale == 0 {
+		details := "No databases found"
+		if len(databases) > 0 {
+			details = fmt.Sprintf("All %d database(s) use %s", len(databases), localeKey(databases[0]))
+		}
+		report.AddFinding(check.Finding{
+			ID:       "mixed-locales",
+			Name:     "Mixed Locales",
+			Severity: check.SeverityOK,
+			Details:  details,
+		})
+		return
+	}
+
+	var problems []string
+	if len(keys) > 1 {
+		problems = append(problems, fmt.Sprintf("databases use %d different locale settings, so the same query sorts and compares text differently depending on the database, "+
+			"and data moved between them (pg_dump, logical replication) lands in indexes ordered by different rules", len(keys)))
+	}
+	if splitLocale > 0 {
+		problems = append(problems, fmt.Sprintf("%d database(s) have LC_COLLATE different from LC_CTYPE, so sort order and character classification (upper(), ILIKE, regex classes) follow different rules", splitLocale))
+	}
+
+	var tableRows []check.TableRow
+	for _, d := range databases {
+		icu := d.IcuLocale
+		if icu == "" {
+			icu = "-"
+		}
+		tableRows = append(tableRows, check.TableRow{
+			Cells: []string{d.DatabaseName, d.Encoding, d.LocaleProvider, d.LcCollate, d.LcCtype, icu},
+		})
+	}
+
+	report.AddFinding(check.Finding{
+		ID:       "mixed-locales",
+		Name:     "Mixed Locales",
+		Severity: check.SeverityWarn,
+		Details: "Locale settings are inconsistent: " + strings.Join(problems, "; ") + ". " +
+			"A database's locale is fixed at CREATE DATABASE. Aligning one means creating a new database with the intended LOCALE (or ICU_LOCALE) and moving the data with pg_dump or logical replication; " +
+			"every index on text columns is rebuilt in the process, and queries relying on the old sort order (ORDER BY, range conditions on text keys) may return rows in a different order",
+		Table: &check.Table{
+			Headers: []string{"Database", "Encoding", "Provider", "LC_COLLATE", "LC_CTYPE", "ICU Locale"},
+			Rows:    tableRows,
+		},
+	})
+}
+
+func checkNondeterministicCollations(columns []db.NondeterministicIndexedColumnsRow, report *check.Report) {
+	if len(columns) == 0 {
+		report.AddFinding(check.Finding{
+			ID:       "nondeterministic-collations",
+			Name:     "Nondeterministic Collations",
+			Severity: check.SeverityOK,
+			Details:  "No index key column uses a nondeterministic collation",
+		})
+		return
+	}
+
+	var tableRows []check.TableRow
+	unique := 0
+	for _, c := range columns {
+		isUnique := "no"
+		if c.IsUnique {
+			isUnique = "yes"
+			unique++
+		}
+		tableRows = append(tableRows, check.TableRow{
+			Cells:    []string{c.SchemaName + "." + c.TableName, c.IndexName, c.ColumnName, c.CollationName, isUnique},
+			Severity: check.SeverityWarn,
+		})
+	}
+
+	details := fmt.Sprintf("%d index key column(s) use a nondeterministic collation. Such collations treat strings that differ in bytes as equal (for example by case or accents), "+
+		"which changes more than sort order: LIKE and regular expressions are not supported on them before PostgreSQL 18, "+
+		"B-tree deduplication is disabled, and the indexes cannot serve text_pattern_ops-style prefix searches", len(columns))
+	if unique > 0 {
+		details += fmt.Sprintf(". %d belong to unique indexes, which reject values equal under the collation (e.g. 'Alice' and 'alice'); confirm that is intended", unique)
+	}
+	details += ". Switching a column to a deterministic collation needs ALTER COLUMN ... TYPE ... COLLATE, which rewrites the table and its indexes under an ACCESS EXCLUSIVE lock, " +
+		"and a unique index may then admit values it used to reject"
+
+	report.AddFinding(check.Finding{
+		ID:       "nondeterministic-collations",
+		Name:     "Nondeterministic Collations",
+		Severity: check.SeverityWarn,
+		Details:  details,
+		Table: &check.Table{
+			Headers: []string{"Table", "Index", "Column", "Collation", "Unique"},
+			Rows:    tableRows,
+		},
+	})
+}
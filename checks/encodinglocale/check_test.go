@@ -0,0 +1,222 @@
+package encodinglocale_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/fresha/pgdoctor/check"
+	"github.com/fresha/pgdoctor/check/checktest"
+	"github.com/fresha/pgdoctor/checks/encodinglocale"
+	"github.com/fresha/pgdoctor/db"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockQueryer struct {
+	databases []db.DatabaseLocalesRow
+	columns   []db.NondeterministicIndexedColumnsRow
+	err       error
+}
+
+func (m *mockQueryer) DatabaseLocales(context.Context) ([]db.DatabaseLocalesRow, error) {
+	return m.databases, m.err
+}
+
+func (m *mockQueryer) NondeterministicIndexedColumns(context.Context) ([]db.NondeterministicIndexedColumnsRow, error) {
+	return m.columns, nil
+}
+
+func database(name, encoding, collate, ctype string) db.DatabaseLocalesRow {
+	return db.DatabaseLocalesRow{
+		DatabaseName:   name,
+		Encoding:       encoding,
+		LcCollate:      collate,
+		LcCtype:        ctype,
+		LocaleProvider: "libc",
+	}
+}
+
+func healthyQueryer() *mockQueryer {
+	return &mockQueryer{
+		databases: []db.DatabaseLocalesRow{
+			database("app", "UTF8", "en_US.UTF-8", "en_US.UTF-8"),
+			database("postgres", "UTF8", "en_US.UTF-8", "en_US.UTF-8"),
+		},
+	}
+}
+
+func TestEncodingLocale_Healthy(t *testing.T) {
+	t.Parallel()
+
+	report, err := encodinglocale.New(healthyQueryer()).Check(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, check.SeverityOK, report.Severity)
+	assert.Len(t, report.Results, 3)
+	assert.Contains(t, checktest.Finding(t, report, "mixed-locales").Details, "All 2 database(s) use libc en_US.UTF-8/en_US.UTF-8")
+}
+
+func TestEncodingLocale_SQLASCII(t *testing.T) {
+	t.Parallel()
+
+	q := healthyQueryer()
+	q.databases = append(q.databases, database("legacy", "SQL_ASCII", "en_US.UTF-8", "en_US.UTF-8"))
+
+	report, err := encodinglocale.New(q).Check(context.Background())
+	require.NoError(t, err)
+
+	f := checktest.Finding(t, report, "sql-ascii-encoding")
+	assert.Equal(t, check.SeverityFail, f.Severity)
+	assert.Contains(t, f.Details, "1 database(s) use SQL_ASCII: legacy")
+	assert.Contains(t, f.Details, "ENCODING 'UTF8'")
+	assert.Equal(t, check.SeverityFail, report.Severity)
+}
+
+func TestEncodingLocale_MixedLocales(t *testing.T) {
+	t.Parallel()
+
+	icu := database("reports", "UTF8", "en_US.UTF-8", "en_US.UTF-8")
+	icu.LocaleProvider = "icu"
+	icu.IcuLocale = "und"
+
+	tests := []struct {
+		name      string
+		databases []db.DatabaseLocalesRow
+		expected  check.Severity
+		contains  []string
+	}{
+		{
+			name: "same locale",
+			databases: []db.DatabaseLocalesRow{
+				database("app", "UTF8", "C.UTF-8", "C.UTF-8"),
+			},
+			expected: check.SeverityOK,
+		},
+		{
+			name: "different collate",
+			databases: []db.DatabaseLocalesRow{
+				database("app", "UTF8", "en_US.UTF-8", "en_US.UTF-8"),
+				database("billing", "UTF8", "C", "C"),
+			},
+			expected: check.SeverityWarn,
+			contains: []string{"2 different locale settings", "CREATE DATABASE"},
+		},
+		{
+			name: "different provider",
+			databases: []db.DatabaseLocalesRow{
+				database("app", "UTF8", "en_US.UTF-8", "en_US.UTF-8"),
+				icu,
+			},
+			expected: check.SeverityWarn,
+			contains: []string{"2 different locale settings"},
+		},
+		{
+			name: "collate differs from ctype",
+			databases: []db.DatabaseLocalesRow{
+				database("app", "UTF8", "C", "en_US.UTF-8"),
+			},
+			expected: check.SeverityWarn,
+			contains: []string{"1 database(s) have LC_COLLATE different from LC_CTYPE"},
+		},
+		{
+			name:     "no databases",
+			expected: check.SeverityOK,
+			contains: []string{"No databases found"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			q := healthyQueryer()
+			q.databases = tt.databases
+
+			report, err := encodinglocale.New(q).Check(context.Background())
+			require.NoError(t, err)
+
+			f := checktest.Finding(t, report, "mixed-locales")
+			assert.Equal(t, tt.expected, f.Severity)
+			for _, s := range tt.contains {
+				assert.Contains(t, f.Details, s)
+			}
+			if tt.expected == check.SeverityWarn {
+				require.NotNil(t, f.Table)
+				assert.Len(t, f.Table.Rows, len(tt.databases))
+			}
+		})
+	}
+}
+
+func TestEncodingLocale_NondeterministicCollations(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		columns  []db.NondeterministicIndexedColumnsRow
+		expected check.Severity
+		contains []string
+		excludes []string
+	}{
+		{
+			name:     "none",
+			expected: check.SeverityOK,
+		},
+		{
+			name: "plain index",
+			columns: []db.NondeterministicIndexedColumnsRow{
+				{SchemaName: "public", TableName: "users", IndexName: "users_name_idx", ColumnName: "name", CollationName: "case_insensitive"},
+			},
+			expected: check.SeverityWarn,
+			contains: []string{"1 index key column(s)", "PostgreSQL 18", "ACCESS EXCLUSIVE"},
+			excludes: []string{"unique indexes"},
+		},
+		{
+			name: "unique index",
+			columns: []db.NondeterministicIndexedColumnsRow{
+				{SchemaName: "public", TableName: "users", IndexName: "users_email_key", ColumnName: "email", CollationName: "case_insensitive", IsUnique: true},
+				{SchemaName: "public", TableName: "users", IndexName: "users_name_idx", ColumnName: "name", CollationName: "case_insensitive"},
+			},
+			expected: check.SeverityWarn,
+			contains: []string{"2 index key column(s)", "1 belong to unique indexes"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			q := healthyQueryer()
+			q.columns = tt.columns
+
+			report, err := encodinglocale.New(q).Check(context.Background())
+			require.NoError(t, err)
+
+			f := checktest.Finding(t, report, "nondeterministic-collations")
+			assert.Equal(t, tt.expected, f.Severity)
+			for _, s := range tt.contains {
+				assert.Contains(t, f.Details, s)
+			}
+			for _, s := range tt.excludes {
+				assert.NotContains(t, f.Details, s)
+			}
+			if tt.expected == check.SeverityWarn {
+				require.NotNil(t, f.Table)
+				assert.Len(t, f.Table.Rows, len(tt.columns))
+				assert.Equal(t, "public.users", f.Table.Rows[0].Cells[0])
+			}
+		})
+	}
+}
+
+func TestEncodingLocale_QueryError(t *testing.T) {
+	t.Parallel()
+
+	q := healthyQueryer()
+	q.err = errors.New("connection refused")
+
+	_, err := encodinglocale.New(q).Check(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "encoding-locale")
+}
@@ -0,0 +1,42 @@
+-- name: DatabaseLocales :many
+-- Encoding and locale of every non-template database. The locale provider
+-- and ICU locale are read through to_jsonb because the columns appeared in
+-- PostgreSQL 15 (daticulocale) and were renamed in 17 (datlocale).
+SELECT
+  d.datname::text AS database_name
+  , PG_ENCODING_TO_CHAR(d.encoding)::text AS encoding
+  , d.datcollate::text AS lc_collate
+  , d.datctype::text AS lc_ctype
+  , (CASE COALESCE(TO_JSONB(d) ->> 'datlocprovider', 'c')
+    WHEN 'i' THEN 'icu'
+    WHEN 'b' THEN 'builtin'
+    ELSE 'libc'
+  END)::text AS locale_provider
+  , COALESCE(TO_JSONB(d) ->> 'datlocale', TO_JSONB(d) ->> 'daticulocale', '')::text AS icu_locale
+FROM pg_database AS d
+WHERE NOT d.datistemplate
+ORDER BY d.datname;
+
+-- name: NondeterministicIndexedColumns :many
+-- Index key columns that use a nondeterministic collation, such as a
+-- case-insensitive ICU collation, in this database.
+SELECT
+  n.nspname::text AS schema_name
+  , t.relname::text AS table_name
+  , i.relname::text AS index_name
+  , COALESCE(a.attname::text, '(expression)')::text AS column_name
+  , coll.collname::text AS collation_name
+  , x.indisunique AS is_unique
+FROM pg_index AS x
+INNER JOIN pg_class AS i ON x.indexrelid = i.oid
+INNER JOIN pg_class AS t ON x.indrelid = t.oid
+INNER JOIN pg_namespace AS n ON t.relnamespace = n.oid
+CROSS JOIN LATERAL UNNEST(x.indkey::int2 [], x.indcollation::oid []) AS k (attnum, collid)
+INNER JOIN pg_collation AS coll ON k.collid = coll.oid
+LEFT JOIN pg_attribute AS a ON t.oid = a.attrelid AND k.attnum = a.attnum AND k.attnum > 0
+WHERE
+  NOT coll.collisdeterministic
+  AND n.nspname NOT IN ('pg_catalog', 'information_schema')
+  AND n.nspname !~ '^pg_toast'
+ORDER BY n.nspname, t.relname, i.relname, column_name
+LIMIT 100;
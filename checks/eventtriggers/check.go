@@ -0,0 +1,241 @@
+// Package eventtriggers implements checks for event trigger security and DDL handling under logical replication.
+package eventtriggers
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/fresha/pgdoctor/check"
+	"github.com/fresha/pgdoctor/db"
+)
+
+//go:embed query.sql
+var querySQL string
+
+//go:embed README.md
+var readme string
+
+// Event trigger events that fire on DDL, as opposed to login.
+var ddlEvents = []string{"ddl_command_start", "ddl_command_end", "sql_drop", "table_rewrite"}
+
+type EventTriggerQueries interface {
+	EventTriggers(context.Context) ([]db.EventTriggersRow, error)
+	LogicalReplicationUsage(context.Context) (db.LogicalReplicationUsageRow, error)
+}
+
+type checker struct {
+	queries EventTriggerQueries
+}
+
+func Metadata() check.Metadata {
+	return check.Metadata{
+		Category:       check.CategorySchema,
+		CheckID:        "event-triggers",
+		Name:           "Event Triggers",
+		Description:    "Inventories event triggers, flags privilege escalation risks, and checks DDL handling under logical replication",
+		Readme:         readme,
+		SQL:            querySQL,
+		RuntimeClass:   check.RuntimeFast,
+		ProductionSafe: true,
+		Findings: []check.FindingSpec{
+			{ID: "event-trigger-inventory", Description: "Event triggers installed in the database, their events and enabled state"},
+			{ID: "event-trigger-security", Description: "Event trigger functions owned by non-superusers, or triggers whose owner lost superuser", Thresholds: "FAIL non-superuser function owner, WARN non-superuser trigger owner"},
+			{ID: "ddl-replication", Description: "Logical replication in use without an enabled DDL event trigger to capture schema changes", Thresholds: "WARN"},
+		},
+	}
+}
+
+func New(queries EventTriggerQueries, _ ...check.Config) check.Checker {
+	return &checker{
+		queries: queries,
+	}
+}
+
+func (c *checker) Metadata() check.Metadata {
+	return Metadata()
+}
+
+func (c *checker) Check(ctx context.Context) (*check.Report, error) {
+	report := check.NewReport(Metadata())
+
+	triggers, err := c.queries.EventTriggers(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("running %s/%s (event triggers): %w", check.CategorySchema, report.CheckID, err)
+	}
+
+	usage, err := c.queries.LogicalReplicationUsage(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("running %s/%s (logical replication): %w", check.CategorySchema, report.CheckID, err)
+	}
+
+	checkInventory(triggers, report)
+	checkSecurity(triggers, report)
+	checkDDLReplication(triggers, usage, report)
+
+	return report, nil
+}
+
+// enabledLabel translates pg_event_trigger.evtenabled.
+func enabledLabel(enabled string) string {
+	switch enabled {
+	case "O":
+		return "origin"
+	case "R":
+		return "replica"
+	case "A":
+		return "always"
+	case "D":
+		return "disabled"
+	default:
+		return enabled
+	}
+}
+
+func checkInventory(triggers []db.EventTriggersRow, report *check.Report) {
+	if len(triggers) == 0 {
+		report.AddFinding(check.Finding{
+			ID:       "event-trigger-inventory",
+			Name:     "Event Trigger Inventory",
+			Severity: check.SeverityOK,
+			Details:  "No event triggers installed",
+		})
+		return
+	}
+
+	var tableRows []check.TableRow
+	disabled := 0
+	for _, t := range triggers {
+		if t.Enabled == "D" {
+			disabled++
+		}
+		tags := t.Tags
+		if tags == "" {
+			tags = "(all)"
+		}
+		tableRows = append(tableRows, check.TableRow{
+			Cells:    []string{t.TriggerName, t.Event, enabledLabel(t.Enabled), tags, t.FunctionName},
+			Severity: check.SeverityOK,
+		})
+	}
+
+	details := fmt.Sprintf("%d event trigger(s) installed", len(triggers))
+	if disabled > 0 {
+		details += fmt.Sprintf(", %d disabled", disabled)
+	}
+
+	report.AddFinding(check.Finding{
+		ID:       "event-trigger-inventory",
+		Name:     "Event Trigger Inventory",
+		Severity: check.SeverityOK,
+		Details:  details,
+		Table: &check.Table{
+			Headers: []string{"Trigger", "Event", "Enabled", "Tags", "Function"},
+			Rows:    tableRows,
+		},
+	})
+}
+
+func checkSecurity(triggers []db.EventTriggersRow, report *check.Report) {
+	var tableRows []check.TableRow
+	severity := check.SeverityOK
+
+	for _, t := range triggers {
+		var issues []string
+		rowSeverity := check.SeverityOK
+		if !t.FunctionOwnerIsSuperuser {
+			// Event triggers run as whoever issues the DDL, including
+			// superusers, so the function owner can rewrite code that
+			// will run with superuser privileges.
+			issues = append(issues, fmt.Sprintf("function owned by non-superuser %s", t.FunctionOwnerName))
+			rowSeverity = check.SeverityFail
+		}
+		if !t.OwnerIsSuperuser {
+			issues = append(issues, fmt.Sprintf("trigger owner %s is no longer superuser", t.OwnerName))
+			rowSeverity = max(rowSeverity, check.SeverityWarn)
+		}
+		if len(issues) == 0 {
+			continue
+		}
+		severity = max(severity, rowSeverity)
+
+		definer := "invoker"
+		if t.SecurityDefiner {
+			definer = "definer"
+		}
+		tableRows = append(tableRows, check.TableRow{
+			Cells:    []string{t.TriggerName, t.FunctionName, definer, strings.Join(issues, "; ")},
+			Severity: rowSeverity,
+		})
+	}
+
+	if len(tableRows) == 0 {
+		report.AddFinding(check.Finding{
+			ID:       "event-trigger-security",
+			Name:     "Event Trigger Security",
+			Severity: check.SeverityOK,
+			Details:  fmt.Sprintf("All %d event trigger(s) and their functions are owned by superusers", len(triggers)),
+		})
+		return
+	}
+
+	details := fmt.Sprintf("%d event trigger(s) depend on non-superuser roles", len(tableRows))
+	if severity == check.SeverityFail {
+		details += ". Event trigger functions run for any role issuing DDL, including superusers: the owner of the function can replace its body and escalate to superuser"
+	}
+
+	report.AddFinding(check.Finding{
+		ID:       "event-trigger-security",
+		Name:     "Event Trigger Security",
+		Severity: severity,
+		Details:  details,
+		Table: &check.Table{
+			Headers: []string{"Trigger", "Function", "Security", "Issue"},
+			Rows:    tableRows,
+		},
+	})
+}
+
+func checkDDLReplication(triggers []db.EventTriggersRow, usage db.LogicalReplicationUsageRow, report *check.Report) {
+	inUse := usage.PublicationCount > 0 || usage.SubscriptionCount > 0 || usage.LogicalSlotCount > 0
+	if !inUse {
+		report.AddFinding(check.Finding{
+			ID:       "ddl-replication",
+			Name:     "DDL Under Logical Replication",
+			Severity: check.SeverityOK,
+			Details:  "Logical replication is not in use in this database",
+		})
+		return
+	}
+
+	usageDetails := fmt.Sprintf("%d publication(s), %d subscription(s), %d logical slot(s)",
+		usage.PublicationCount, usage.SubscriptionCount, usage.LogicalSlotCount)
+
+	var capturing []string
+	for _, t := range triggers {
+		if t.Enabled != "D" && slices.Contains(ddlEvents, t.Event) {
+			capturing = append(capturing, t.TriggerName)
+		}
+	}
+
+	if len(capturing) > 0 {
+		report.AddFinding(check.Finding{
+			ID:       "ddl-replication",
+			Name:     "DDL Under Logical Replication",
+			Severity: check.SeverityOK,
+			Details: fmt.Sprintf("Logical replication in use (%s); DDL event trigger(s) %s can capture schema changes",
+				usageDetails, strings.Join(capturing, ", ")),
+		})
+		return
+	}
+
+	report.AddFinding(check.Finding{
+		ID:       "ddl-replication",
+		Name:     "DDL Under Logical Replication",
+		Severity: check.SeverityWarn,
+		Details: fmt.Sprintf("Logical replication in use (%s) but no enabled event trigger captures DDL. Logical replication does not replicate schema changes: apply DDL to subscribers first or replication stops on the first missing column",
+			usageDetails),
+	})
+}
@@ -0,0 +1,145 @@
+package eventtriggers_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/fresha/pgdoctor/check"
+	"github.com/fresha/pgdoctor/check/checktest"
+	"github.com/fresha/pgdoctor/checks/eventtriggers"
+	"github.com/fresha/pgdoctor/db"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockQueryer struct {
+	triggers []db.EventTriggersRow
+	usage    db.LogicalReplicationUsageRow
+	err      error
+}
+
+func (m *mockQueryer) EventTriggers(context.Context) ([]db.EventTriggersRow, error) {
+	return m.triggers, m.err
+}
+
+func (m *mockQueryer) LogicalReplicationUsage(context.Context) (db.LogicalReplicationUsageRow, error) {
+	return m.usage, nil
+}
+
+func ddlTrigger() db.EventTriggersRow {
+	return db.EventTriggersRow{
+		TriggerName:              "capture_ddl",
+		Event:                    "ddl_command_end",
+		Enabled:                  "O",
+		OwnerName:                "postgres",
+		OwnerIsSuperuser:         true,
+		FunctionName:             "audit.capture_ddl",
+		FunctionOwnerName:        "postgres",
+		FunctionOwnerIsSuperuser: true,
+	}
+}
+
+func TestEventTriggers_None(t *testing.T) {
+	t.Parallel()
+
+	report, err := eventtriggers.New(&mockQueryer{}).Check(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, check.SeverityOK, report.Severity)
+	assert.Len(t, report.Results, 3)
+	assert.Contains(t, checktest.Finding(t, report, "event-trigger-inventory").Details, "No event triggers")
+}
+
+func TestEventTriggers_Inventory(t *testing.T) {
+	t.Parallel()
+
+	disabled := ddlTrigger()
+	disabled.TriggerName = "old_trigger"
+	disabled.Enabled = "D"
+	disabled.Tags = "CREATE TABLE, ALTER TABLE"
+
+	report, err := eventtriggers.New(&mockQueryer{triggers: []db.EventTriggersRow{ddlTrigger(), disabled}}).Check(context.Background())
+	require.NoError(t, err)
+
+	f := checktest.Finding(t, report, "event-trigger-inventory")
+	assert.Equal(t, check.SeverityOK, f.Severity)
+	assert.Contains(t, f.Details, "1 disabled")
+	require.NotNil(t, f.Table)
+	assert.Equal(t, []string{"capture_ddl", "ddl_command_end", "origin", "(all)", "audit.capture_ddl"}, f.Table.Rows[0].Cells)
+	assert.Equal(t, "disabled", f.Table.Rows[1].Cells[2])
+}
+
+func TestEventTriggers_Security(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		modify   func(*db.EventTriggersRow)
+		expected check.Severity
+	}{
+		{name: "superuser owned", modify: func(*db.EventTriggersRow) {}, expected: check.SeverityOK},
+		{name: "function owned by app role", modify: func(r *db.EventTriggersRow) {
+			r.FunctionOwnerName = "app"
+			r.FunctionOwnerIsSuperuser = false
+		}, expected: check.SeverityFail},
+		{name: "trigger owner demoted", modify: func(r *db.EventTriggersRow) {
+			r.OwnerName = "former_admin"
+			r.OwnerIsSuperuser = false
+		}, expected: check.SeverityWarn},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			row := ddlTrigger()
+			tt.modify(&row)
+
+			report, err := eventtriggers.New(&mockQueryer{triggers: []db.EventTriggersRow{row}}).Check(context.Background())
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, checktest.Finding(t, report, "event-trigger-security").Severity)
+		})
+	}
+}
+
+func TestEventTriggers_DDLReplication(t *testing.T) {
+	t.Parallel()
+
+	login := ddlTrigger()
+	login.Event = "login"
+
+	disabled := ddlTrigger()
+	disabled.Enabled = "D"
+
+	tests := []struct {
+		name     string
+		triggers []db.EventTriggersRow
+		usage    db.LogicalReplicationUsageRow
+		expected check.Severity
+	}{
+		{name: "no logical replication", expected: check.SeverityOK},
+		{name: "publication without ddl capture", usage: db.LogicalReplicationUsageRow{PublicationCount: 1}, expected: check.SeverityWarn},
+		{name: "subscription with ddl capture", triggers: []db.EventTriggersRow{ddlTrigger()}, usage: db.LogicalReplicationUsageRow{SubscriptionCount: 1}, expected: check.SeverityOK},
+		{name: "login trigger does not capture ddl", triggers: []db.EventTriggersRow{login}, usage: db.LogicalReplicationUsageRow{LogicalSlotCount: 2}, expected: check.SeverityWarn},
+		{name: "disabled trigger does not capture ddl", triggers: []db.EventTriggersRow{disabled}, usage: db.LogicalReplicationUsageRow{PublicationCount: 1}, expected: check.SeverityWarn},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			report, err := eventtriggers.New(&mockQueryer{triggers: tt.triggers, usage: tt.usage}).Check(context.Background())
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, checktest.Finding(t, report, "ddl-replication").Severity)
+		})
+	}
+}
+
+func TestEventTriggers_QueryError(t *testing.T) {
+	t.Parallel()
+
+	_, err := eventtriggers.New(&mockQueryer{err: errors.New("connection refused")}).Check(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "event-triggers")
+}
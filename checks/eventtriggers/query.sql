@@ -0,0 +1,32 @@
+-- name: EventTriggers :many
+-- Event triggers with their owner and the owner and security mode of the function they call.
+SELECT
+  e.evtname::text AS trigger_name
+  , e.evtevent::text AS event
+  , e.evtenabled::text AS enabled
+  , COALESCE(ARRAY_TO_STRING(e.evttags, ', '), '')::text AS tags
+  , owner.rolname::text AS owner_name
+  , owner.rolsuper AS owner_is_superuser
+  , (n.nspname || '.' || p.proname)::text AS function_name
+  , fowner.rolname::text AS function_owner_name
+  , fowner.rolsuper AS function_owner_is_superuser
+  , p.prosecdef AS security_definer
+FROM pg_event_trigger AS e
+INNER JOIN pg_roles AS owner ON e.evtowner = owner.oid
+INNER JOIN pg_proc AS p ON e.evtfoid = p.oid
+INNER JOIN pg_namespace AS n ON p.pronamespace = n.oid
+INNER JOIN pg_roles AS fowner ON p.proowner = fowner.oid
+ORDER BY e.evtname;
+
+-- name: LogicalReplicationUsage :one
+-- Whether this database publishes or subscribes via logical replication.
+SELECT
+  (SELECT COUNT(*) FROM pg_publication)::bigint AS publication_count
+  , (
+    SELECT COUNT(*) FROM pg_subscription
+    WHERE subdbid = (SELECT oid FROM pg_database WHERE datname = CURRENT_DATABASE())
+  )::bigint AS subscription_count
+  , (
+    SELECT COUNT(*) FROM pg_replication_slots
+    WHERE slot_type = 'logical' AND database = CURRENT_DATABASE()
+  )::bigint AS logical_slot_count;
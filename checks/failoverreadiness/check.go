@@ -0,0 +1,418 @@
+// Package failoverreadiness implements a check that answers whether the
+// cluster can fail over safely right now.
+package failoverreadiness
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"strings"
+
+	"github.com/fresha/pgdoctor/check"
+	"github.com/fresha/pgdoctor/db"
+)
+
+//go:embed query.sql
+var querySQL string
+
+//go:embed README.md
+var readme string
+
+const (
+	// An async standby this close to the primary loses little on promotion.
+	standbyLagWarnSeconds = 30.0
+	standbyLagWarnBytes   = int64(256 * 1024 * 1024) // 256MiB
+
+	// A standby that has not replied for this long may be gone.
+	standbyReplyStaleSeconds = 60.0
+
+	// PostgreSQL 17 can synchronize logical slots to standbys.
+	failoverSlotsMinVersion = 170000
+)
+
+type FailoverReadinessQueries interface {
+	FailoverSettings(context.Context) (db.FailoverSettingsRow, error)
+	FailoverStandbys(context.Context) ([]db.FailoverStandbysRow, error)
+	FailoverParameterChecks(context.Context) ([]db.FailoverParameterChecksRow, error)
+	FailoverSlots(context.Context) ([]db.FailoverSlotsRow, error)
+}
+
+type checker struct {
+	queries FailoverReadinessQueries
+}
+
+func Metadata() check.Metadata {
+	return check.Metadata{
+		Category:       check.CategoryConfigs,
+		CheckID:        "failover-readiness",
+		Name:           "Failover Readiness",
+		Description:    "Combines standby, archiving, recovery settings, and slot checks into a single safe-to-fail-over verdict",
+		Readme:         readme,
+		SQL:            querySQL,
+		RuntimeClass:   check.RuntimeFast,
+		ProductionSafe: true,
+		Privileges:     []check.Privilege{check.PrivilegeReadAllStats, check.PrivilegeReadAllSettings},
+		Findings: []check.FindingSpec{
+			{ID: "standby-available", Description: "At least one streaming standby is synchronous or close behind the primary", Thresholds: "WARN all lag > 30s or 256MiB, FAIL no streaming standby"},
+			{ID: "wal-archiving", Description: "WAL archiving is enabled and the archiver is not failing", Thresholds: "WARN archive_mode off, FAIL archiver failing or no archive command"},
+			{ID: "recovery-settings", Description: "On a standby: settings at least the primary's, and restore_command or a slot to catch up", Thresholds: "WARN no restore_command and no slot, FAIL settings below the primary's"},
+			{ID: "slot-failover", Description: "Logical slots survive failover (PG17+ failover slots) and no slot has lost WAL", Thresholds: "WARN logical slot not synced or WAL unreserved, FAIL WAL lost"},
+			{ID: "failover-verdict", Description: "Overall verdict: can the cluster fail over safely right now", Thresholds: "Worst of the above"},
+		},
+	}
+}
+
+func New(queries FailoverReadinessQueries, _ ...check.Config) check.Checker {
+	return &checker{
+		queries: queries,
+	}
+}
+
+func (c *checker) Metadata() check.Metadata {
+	return Metadata()
+}
+
+func (c *checker) Check(ctx context.Context) (*check.Report, error) {
+	report := check.NewReport(Metadata())
+
+	settings, err := c.queries.FailoverSettings(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("running %s/%s (settings): %w", report.Category, report.CheckID, err)
+	}
+
+	standbys, err := c.queries.FailoverStandbys(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("running %s/%s (standbys): %w", report.Category, report.CheckID, err)
+	}
+
+	// The control file only holds the primary's values on a standby.
+	var params []db.FailoverParameterChecksRow
+	if settings.InRecovery {
+		params, err = c.queries.FailoverParameterChecks(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("running %s/%s (parameters): %w", report.Category, report.CheckID, err)
+		}
+	}
+
+	slots, err := c.queries.FailoverSlots(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("running %s/%s (slots): %w", report.Category, report.CheckID, err)
+	}
+
+	checkStandbyAvailable(settings, standbys, report)
+	checkWALArchiving(settings, report)
+	checkRecoverySettings(settings, params, report)
+	checkSlotFailover(settings, slots, report)
+	addVerdict(report)
+
+	return report, nil
+}
+
+func isSync(s db.FailoverStandbysRow) bool {
+	return s.SyncState == "sync" || s.SyncState == "quorum"
+}
+
+// isHealthy reports whether a standby could be promoted with little or no
+// data loss: streaming, recently heard from, and synchronous or close behind.
+func isHealthy(s db.FailoverStandbysRow) bool {
+	if s.State != "streaming" {
+		return false
+	}
+	if s.ReplyAgeSeconds > standbyReplyStaleSeconds {
+		return false
+	}
+	if isSync(s) {
+		return true
+	}
+	return s.ReplayLagSeconds <= standbyLagWarnSeconds && s.ReplayLagBytes >= 0 && s.ReplayLagBytes <= standbyLagWarnBytes
+}
+
+func checkStandbyAvailable(settings db.FailoverSettingsRow, standbys []db.FailoverStandbysRow, report *check.Report) {
+	if settings.InRecovery {
+		report.AddFinding(check.Finding{
+			ID:       "standby-available",
+			Name:     "Standby Available",
+			Severity: check.SeverityOK,
+			Details:  "This server is a standby; run against the primary to assess its standbys",
+		})
+		return
+	}
+
+	var streaming, healthy int
+	var tableRows []check.TableRow
+	for _, s := range standbys {
+		rowSeverity := check.SeverityWarn
+		if isHealthy(s) {
+			healthy++
+			rowSeverity = check.SeverityOK
+		}
+		if s.State == "streaming" {
+			streaming++
+		}
+
+		lag := "unknown"
+		if s.ReplayLagBytes >= 0 {
+			lag = check.FormatBytes(s.ReplayLagBytes)
+		}
+		tableRows = append(tableRows, check.TableRow{
+			Cells: []string{
+				s.ApplicationName,
+				s.State,
+				s.SyncState,
+				lag,
+				check.FormatDurationSec(int64(s.ReplayLagSeconds)),
+			},
+			Severity: rowSeverity,
+		})
+	}
+	table := &check.Table{
+		Headers: []string{"Standby", "State", "Sync", "Replay Lag", "Replay Delay"},
+		Rows:    tableRows,
+	}
+
+	switch {
+	case streaming == 0:
+		report.AddFinding(check.Finding{
+			ID:       "standby-available",
+			Name:     "Standby Available",
+			Severity: check.SeverityFail,
+			Details: "No streaming standby is connected, so there is nothing to fail over to. " +
+				"If high availability is provided by the platform (RDS Multi-AZ, Cloud SQL HA, Azure zone-redundant HA), its standby is not visible in pg_stat_replication",
+		})
+	case healthy == 0:
+		report.AddFinding(check.Finding{
+			ID:       "standby-available",
+			Name:     "Standby Available",
+			Severity: check.SeverityWarn,
+			Details: fmt.Sprintf("%d standby(ies) streaming, but none is synchronous or within %.0fs / %s of the primary: promoting now would lose the unreplayed WAL or wait for it to catch up",
+				streaming, standbyLagWarnSeconds, check.FormatBytes(standbyLagWarnBytes)),
+			Table: table,
+		})
+	default:
+		report.AddFinding(check.Finding{
+			ID:       "standby-available",
+			Name:     "Standby Available",
+			Severity: check.SeverityOK,
+			Details:  fmt.Sprintf("%d of %d standby(ies) are synchronous or close behind the primary", healthy, len(standbys)),
+			Table:    table,
+		})
+	}
+}
+
+func checkWALArchiving(settings db.FailoverSettingsRow, report *check.Report) {
+	// On a standby, archive_mode=on archives nothing until promotion.
+	if settings.InRecovery && settings.ArchiveMode != "always" {
+		report.AddFinding(check.Finding{
+			ID:       "wal-archiving",
+			Name:     "WAL Archiving",
+			Severity: check.SeverityOK,
+			Details:  fmt.Sprintf("This server is a standby (archive_mode=%s); archiving is assessed on the primary", settings.ArchiveMode),
+		})
+		return
+	}
+
+	if settings.ArchiveMode == "off" {
+		report.AddFinding(check.Finding{
+			ID:       "wal-archiving",
+			Name:     "WAL Archiving",
+			Severity: check.SeverityWarn,
+			Details: "archive_mode is off: a standby that falls behind cannot refill gaps from an archive, " +
+				"and there is no point-in-time recovery if the failover goes wrong",
+		})
+		return
+	}
+
+	if !settings.ArchiverConfigured {
+		report.AddFinding(check.Finding{
+			ID:       "wal-archiving",
+			Name:     "WAL Archiving",
+			Severity: check.SeverityFail,
+			Details:  fmt.Sprintf("archive_mode is %s but neither archive_command nor archive_library is set: WAL segments accumulate in pg_wal until one is configured", settings.ArchiveMode),
+		})
+		return
+	}
+
+	// The archiver is failing when its last failure is more recent than its
+	// last success.
+	failing := settings.LastFailedAgeSeconds >= 0 &&
+		(settings.LastArchivedAgeSeconds < 0 || settings.LastFailedAgeSeconds < settings.LastArchivedAgeSeconds)
+	if failing {
+		report.AddFinding(check.Finding{
+			ID:       "wal-archiving",
+			Name:     "WAL Archiving",
+			Severity: check.SeverityFail,
+			Details: fmt.Sprintf("The archiver is failing: last failure %s ago, last success %s. Segments that are not archived are lost for standbys and PITR if the primary fails now",
+				check.FormatDurationSec(settings.LastFailedAgeSeconds), archivedAgo(settings.LastArchivedAgeSeconds)),
+		})
+		return
+	}
+
+	report.AddFinding(check.Finding{
+		ID:       "wal-archiving",
+		Name:     "WAL Archiving",
+		Severity: check.SeverityOK,
+		Details: fmt.Sprintf("Archiving is enabled (archive_mode=%s), last segment archived %s, %s failure(s) since stats reset",
+			settings.ArchiveMode, archivedAgo(settings.LastArchivedAgeSeconds), check.FormatNumber(settings.FailedCount)),
+	})
+}
+
+func archivedAgo(ageSeconds int64) string {
+	if ageSeconds < 0 {
+		return "never"
+	}
+	return check.FormatDurationSec(ageSeconds) + " ago"
+}
+
+func checkRecoverySettings(settings db.FailoverSettingsRow, params []db.FailoverParameterChecksRow, report *check.Report) {
+	if !settings.InRecovery {
+		report.AddFinding(check.Finding{
+			ID:       "recovery-settings",
+			Name:     "Recovery Settings",
+			Severity: check.SeverityOK,
+			Details:  "This server is the primary; run against each standby to compare its settings with the primary's",
+		})
+		return
+	}
+
+	var tableRows []check.TableRow
+	for _, p := range params {
+		if p.LocalValue >= p.PrimaryValue {
+			continue
+		}
+		tableRows = append(tableRows, check.TableRow{
+			Cells:    []string{p.SettingName, check.FormatNumber(p.LocalValue), check.FormatNumber(p.PrimaryValue)},
+			Severity: check.SeverityFail,
+		})
+	}
+	if len(tableRows) > 0 {
+		report.AddFinding(check.Finding{
+			ID:       "recovery-settings",
+			Name:     "Recovery Settings",
+			Severity: check.SeverityFail,
+			Details: fmt.Sprintf("%d setting(s) are lower than on the primary. A hot standby pauses recovery (or refuses to start) when they are, so it cannot be relied on for failover. "+
+				"Raise them on the standby before raising them on the primary",
+				len(tableRows)),
+			Table: &check.Table{
+				Headers: []string{"Setting", "Standby", "Primary"},
+				Rows:    tableRows,
+			},
+		})
+		return
+	}
+
+	if settings.RestoreCommand == "" && settings.PrimarySlotName == "" {
+		report.AddFinding(check.Finding{
+			ID:       "recovery-settings",
+			Name:     "Recovery Settings",
+			Severity: check.SeverityWarn,
+			Details: "This standby has neither restore_command nor primary_slot_name: if it falls behind past wal_keep_size it can never catch up and must be rebuilt. " +
+				"Set primary_slot_name, or restore_command to read from the WAL archive",
+		})
+		return
+	}
+
+	var sources []string
+	if settings.PrimarySlotName != "" {
+		sources = append(sources, "slot "+settings.PrimarySlotName)
+	}
+	if settings.RestoreCommand != "" {
+		sources = append(sources, "restore_command")
+	}
+	report.AddFinding(check.Finding{
+		ID:       "recovery-settings",
+		Name:     "Recovery Settings",
+		Severity: check.SeverityOK,
+		Details:  fmt.Sprintf("Settings match or exceed the primary's; this standby catches up through %s", strings.Join(sources, " and ")),
+	})
+}
+
+func checkSlotFailover(settings db.FailoverSettingsRow, slots []db.FailoverSlotsRow, report *check.Report) {
+	var tableRows []check.TableRow
+	severity := check.SeverityOK
+	failoverSlots := settings.ServerVersionNum >= failoverSlotsMinVersion
+
+	for _, slot := range slots {
+		var problem string
+		rowSeverity := check.SeverityWarn
+		switch {
+		case slot.WalStatus == "lost":
+			problem = "WAL already removed"
+			rowSeverity = check.SeverityFail
+		case slot.WalStatus == "unreserved":
+			problem = "WAL about to be removed"
+		case slot.SlotType == "logical" && !slot.Failover:
+			problem = "not synchronized to standbys"
+		default:
+			continue
+		}
+		severity = max(severity, rowSeverity)
+
+		tableRows = append(tableRows, check.TableRow{
+			Cells:    []string{slot.SlotName, slot.SlotType, problem, check.FormatBytes(slot.RetainedBytes)},
+			Severity: rowSeverity,
+		})
+	}
+
+	if len(tableRows) == 0 {
+		report.AddFinding(check.Finding{
+			ID:       "slot-failover",
+			Name:     "Slot Failover Safety",
+			Severity: check.SeverityOK,
+			Details:  fmt.Sprintf("All %d slot(s) retain their WAL and survive failover", len(slots)),
+		})
+		return
+	}
+
+	details := fmt.Sprintf("%d slot(s) would break or lose data on failover.", len(tableRows))
+	if failoverSlots {
+		details += " Logical slots are only recreated on the promoted standby when created with failover = true and sync_replication_slots is on there; otherwise consumers must be resynchronized from scratch"
+	} else {
+		details += " Before PostgreSQL 17 logical slots exist only on the primary: after failover, logical consumers (CDC, logical replicas) must be recreated unless an extension such as pg_failover_slots copies them"
+	}
+
+	report.AddFinding(check.Finding{
+		ID:       "slot-failover",
+		Name:     "Slot Failover Safety",
+		Severity: severity,
+		Details:  details,
+		Table: &check.Table{
+			Headers: []string{"Slot", "Type", "Problem", "Retained WAL"},
+			Rows:    tableRows,
+		},
+	})
+}
+
+// addVerdict summarizes the other findings into a single answer.
+func addVerdict(report *check.Report) {
+	severity := check.SeverityOK
+	var blockers, warnings []string
+	for _, f := range report.Results {
+		severity = max(severity, f.Severity)
+		switch f.Severity {
+		case check.SeverityFail:
+			blockers = append(blockers, f.ID)
+		case check.SeverityWarn:
+			warnings = append(warnings, f.ID)
+		}
+	}
+
+	var details string
+	switch severity {
+	case check.SeverityFail:
+		details = fmt.Sprintf("Not safe to fail over: %s", strings.Join(blockers, ", "))
+		if len(warnings) > 0 {
+			details += fmt.Sprintf(" (also review %s)", strings.Join(warnings, ", "))
+		}
+	case check.SeverityWarn:
+		details = fmt.Sprintf("Failover is possible but risky: review %s before planned maintenance", strings.Join(warnings, ", "))
+	default:
+		details = "Safe to fail over: a healthy standby is available, archiving works, and slots will survive promotion"
+	}
+
+	report.AddFinding(check.Finding{
+		ID:       "failover-verdict",
+		Name:     "Failover Verdict",
+		Severity: severity,
+		Details:  details,
+	})
+}
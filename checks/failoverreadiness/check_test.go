@@ -0,0 +1,344 @@
+package failoverreadiness_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/fresha/pgdoctor/check"
+	"github.com/fresha/pgdoctor/check/checktest"
+	"github.com/fresha/pgdoctor/checks/failoverreadiness"
+	"github.com/fresha/pgdoctor/db"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockQueryer struct {
+	settings db.FailoverSettingsRow
+	standbys []db.FailoverStandbysRow
+	params   []db.FailoverParameterChecksRow
+	slots    []db.FailoverSlotsRow
+	err      error
+
+	paramsCalled bool
+}
+
+func (m *mockQueryer) FailoverSettings(context.Context) (db.FailoverSettingsRow, error) {
+	return m.settings, m.err
+}
+
+func (m *mockQueryer) FailoverStandbys(context.Context) ([]db.FailoverStandbysRow, error) {
+	return m.standbys, nil
+}
+
+func (m *mockQueryer) FailoverParameterChecks(context.Context) ([]db.FailoverParameterChecksRow, error) {
+	m.paramsCalled = true
+	return m.params, nil
+}
+
+func (m *mockQueryer) FailoverSlots(context.Context) ([]db.FailoverSlotsRow, error) {
+	return m.slots, nil
+}
+
+func healthyPrimary() *mockQueryer {
+	return &mockQueryer{
+		settings: db.FailoverSettingsRow{
+			ServerVersionNum:       170002,
+			ArchiveMode:            "on",
+			ArchiverConfigured:     true,
+			ArchivedCount:          1200,
+			LastArchivedAgeSeconds: 30,
+			LastFailedAgeSeconds:   -1,
+		},
+		standbys: []db.FailoverStandbysRow{
+			{ApplicationName: "standby1", State: "streaming", SyncState: "async", ReplayLagBytes: 1024, ReplayLagSeconds: 0.2, ReplyAgeSeconds: 1},
+		},
+		slots: []db.FailoverSlotsRow{
+			{SlotName: "standby1", SlotType: "physical", Active: true, WalStatus: "reserved"},
+			{SlotName: "cdc", SlotType: "logical", Active: true, WalStatus: "reserved", Failover: true},
+		},
+	}
+}
+
+func TestFailoverReadiness_Healthy(t *testing.T) {
+	t.Parallel()
+
+	q := healthyPrimary()
+	report, err := failoverreadiness.New(q).Check(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, check.SeverityOK, report.Severity)
+	assert.Len(t, report.Results, 5)
+	assert.False(t, q.paramsCalled, "control file settings are only compared on a standby")
+	assert.Contains(t, checktest.Finding(t, report, "failover-verdict").Details, "Safe to fail over")
+}
+
+func TestFailoverReadiness_StandbyAvailable(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		standbys []db.FailoverStandbysRow
+		expected check.Severity
+	}{
+		{name: "no standbys", expected: check.SeverityFail},
+		{
+			name: "not streaming",
+			standbys: []db.FailoverStandbysRow{
+				{ApplicationName: "s1", State: "catchup", SyncState: "async", ReplayLagBytes: 1 << 30, ReplyAgeSeconds: 1},
+			},
+			expected: check.SeverityFail,
+		},
+		{
+			name: "async lagging",
+			standbys: []db.FailoverStandbysRow{
+				{ApplicationName: "s1", State: "streaming", SyncState: "async", ReplayLagBytes: 1 << 30, ReplayLagSeconds: 120, ReplyAgeSeconds: 1},
+			},
+			expected: check.SeverityWarn,
+		},
+		{
+			name: "stale feedback",
+			standbys: []db.FailoverStandbysRow{
+				{ApplicationName: "s1", State: "streaming", SyncState: "async", ReplayLagBytes: 0, ReplyAgeSeconds: 600},
+			},
+			expected: check.SeverityWarn,
+		},
+		{
+			name: "sync standby lagging replay",
+			standbys: []db.FailoverStandbysRow{
+				{ApplicationName: "s1", State: "streaming", SyncState: "sync", ReplayLagBytes: 1 << 30, ReplayLagSeconds: 120, ReplyAgeSeconds: 1},
+			},
+			expected: check.SeverityOK,
+		},
+		{
+			name: "one healthy of two",
+			standbys: []db.FailoverStandbysRow{
+				{ApplicationName: "s1", State: "streaming", SyncState: "async", ReplayLagBytes: 1 << 30, ReplayLagSeconds: 120, ReplyAgeSeconds: 1},
+				{ApplicationName: "s2", State: "streaming", SyncState: "async", ReplayLagBytes: 0, ReplyAgeSeconds: 1},
+			},
+			expected: check.SeverityOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			q := healthyPrimary()
+			q.standbys = tt.standbys
+
+			report, err := failoverreadiness.New(q).Check(context.Background())
+			require.NoError(t, err)
+
+			f := checktest.Finding(t, report, "standby-available")
+			assert.Equal(t, tt.expected, f.Severity)
+			assert.Equal(t, tt.expected, checktest.Finding(t, report, "failover-verdict").Severity)
+		})
+	}
+}
+
+func TestFailoverReadiness_WALArchiving(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		mode       string
+		configured bool
+		archived   int64
+		failed     int64
+		inRecovery bool
+		expected   check.Severity
+	}{
+		{name: "archiving", mode: "on", configured: true, archived: 30, failed: -1, expected: check.SeverityOK},
+		{name: "old failure", mode: "on", configured: true, archived: 30, failed: 3600, expected: check.SeverityOK},
+		{name: "off", mode: "off", archived: -1, failed: -1, expected: check.SeverityWarn},
+		{name: "no command", mode: "on", archived: -1, failed: -1, expected: check.SeverityFail},
+		{name: "failing", mode: "on", configured: true, archived: 3600, failed: 10, expected: check.SeverityFail},
+		{name: "never succeeded", mode: "on", configured: true, archived: -1, failed: 10, expected: check.SeverityFail},
+		{name: "standby with archive_mode on", mode: "on", archived: -1, failed: -1, inRecovery: true, expected: check.SeverityOK},
+		{name: "standby with archive_mode always", mode: "always", archived: -1, failed: -1, inRecovery: true, expected: check.SeverityFail},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			q := healthyPrimary()
+			q.settings.ArchiveMode = tt.mode
+			q.settings.ArchiverConfigured = tt.configured
+			q.settings.LastArchivedAgeSeconds = tt.archived
+			q.settings.LastFailedAgeSeconds = tt.failed
+			q.settings.InRecovery = tt.inRecovery
+
+			report, err := failoverreadiness.New(q).Check(context.Background())
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.expected, checktest.Finding(t, report, "wal-archiving").Severity)
+		})
+	}
+}
+
+func TestFailoverReadiness_RecoverySettings(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		params   []db.FailoverParameterChecksRow
+		restore  string
+		slot     string
+		expected check.Severity
+		rows     int
+	}{
+		{
+			name:     "matching with slot",
+			params:   []db.FailoverParameterChecksRow{{SettingName: "max_connections", LocalValue: 200, PrimaryValue: 200}},
+			slot:     "standby1",
+			expected: check.SeverityOK,
+		},
+		{
+			name: "lower than primary",
+			params: []db.FailoverParameterChecksRow{
+				{SettingName: "max_connections", LocalValue: 100, PrimaryValue: 200},
+				{SettingName: "max_worker_processes", LocalValue: 8, PrimaryValue: 8},
+			},
+			slot:     "standby1",
+			expected: check.SeverityFail,
+			rows:     1,
+		},
+		{
+			name:     "no way to catch up",
+			params:   []db.FailoverParameterChecksRow{{SettingName: "max_connections", LocalValue: 200, PrimaryValue: 100}},
+			expected: check.SeverityWarn,
+		},
+		{
+			name:     "restore command only",
+			restore:  "pgbackrest --stanza=main archive-get %f %p",
+			expected: check.SeverityOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			q := healthyPrimary()
+			q.settings.InRecovery = true
+			q.settings.RestoreCommand = tt.restore
+			q.settings.PrimarySlotName = tt.slot
+			q.params = tt.params
+
+			report, err := failoverreadiness.New(q).Check(context.Background())
+			require.NoError(t, err)
+			assert.True(t, q.paramsCalled)
+
+			f := checktest.Finding(t, report, "recovery-settings")
+			assert.Equal(t, tt.expected, f.Severity)
+			if tt.rows > 0 {
+				require.NotNil(t, f.Table)
+				assert.Len(t, f.Table.Rows, tt.rows)
+			}
+			assert.Equal(t, check.SeverityOK, checktest.Finding(t, report, "standby-available").Severity)
+		})
+	}
+}
+
+func TestFailoverReadiness_SlotFailover(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		version  int32
+		slots    []db.FailoverSlotsRow
+		expected check.Severity
+		contains string
+	}{
+		{
+			name:     "no slots",
+			version:  170002,
+			expected: check.SeverityOK,
+		},
+		{
+			name:     "logical without failover",
+			version:  170002,
+			slots:    []db.FailoverSlotsRow{{SlotName: "cdc", SlotType: "logical", WalStatus: "reserved"}},
+			expected: check.SeverityWarn,
+			contains: "failover = true",
+		},
+		{
+			name:     "logical before 17",
+			version:  160004,
+			slots:    []db.FailoverSlotsRow{{SlotName: "cdc", SlotType: "logical", WalStatus: "reserved"}},
+			expected: check.SeverityWarn,
+			contains: "pg_failover_slots",
+		},
+		{
+			name:     "physical unreserved",
+			version:  170002,
+			slots:    []db.FailoverSlotsRow{{SlotName: "standby1", SlotType: "physical", WalStatus: "unreserved"}},
+			expected: check.SeverityWarn,
+		},
+		{
+			name:     "lost",
+			version:  170002,
+			slots:    []db.FailoverSlotsRow{{SlotName: "cdc", SlotType: "logical", WalStatus: "lost", Failover: true}},
+			expected: check.SeverityFail,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			q := healthyPrimary()
+			q.settings.ServerVersionNum = tt.version
+			q.slots = tt.slots
+
+			report, err := failoverreadiness.New(q).Check(context.Background())
+			require.NoError(t, err)
+
+			f := checktest.Finding(t, report, "slot-failover")
+			assert.Equal(t, tt.expected, f.Severity)
+			if tt.contains != "" {
+				assert.Contains(t, f.Details, tt.contains)
+			}
+		})
+	}
+}
+
+func TestFailoverReadiness_Verdict(t *testing.T) {
+	t.Parallel()
+
+	q := healthyPrimary()
+	q.standbys = nil
+	q.settings.ArchiveMode = "off"
+
+	report, err := failoverreadiness.New(q).Check(context.Background())
+	require.NoError(t, err)
+
+	f := checktest.Finding(t, report, "failover-verdict")
+	assert.Equal(t, check.SeverityFail, f.Severity)
+	assert.Contains(t, f.Details, "standby-available")
+	assert.Contains(t, f.Details, "wal-archiving")
+	assert.Equal(t, "failover-verdict", report.Results[len(report.Results)-1].ID)
+}
+
+func TestFailoverReadiness_QueryError(t *testing.T) {
+	t.Parallel()
+
+	q := healthyPrimary()
+	q.err = errors.New("connection refused")
+
+	_, err := failoverreadiness.New(q).Check(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failover-readiness")
+}
+
+func TestMetadata(t *testing.T) {
+	t.Parallel()
+
+	meta := failoverreadiness.Metadata()
+	assert.Equal(t, "failover-readiness", meta.CheckID)
+	assert.Equal(t, check.CategoryConfigs, meta.Category)
+	assert.NotEmpty(t, meta.SQL)
+	assert.NotEmpty(t, meta.Readme)
+}
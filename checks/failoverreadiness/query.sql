@@ -0,0 +1,68 @@
+-- name: FailoverSettings :one
+-- Server role, WAL archiving, and recovery settings relevant to failover.
+SELECT
+  PG_IS_IN_RECOVERY() AS in_recovery
+  , current_setting('server_version_num')::integer AS server_version_num
+  , current_setting('archive_mode')::text AS archive_mode
+  , (
+    current_setting('archive_command') NOT IN ('', '(disabled)')
+    OR COALESCE(current_setting('archive_library', true), '') <> ''
+  ) AS archiver_configured
+  , COALESCE(current_setting('restore_command', true), '')::text AS restore_command
+  , COALESCE(current_setting('primary_slot_name', true), '')::text AS primary_slot_name
+  , a.archived_count::bigint AS archived_count
+  , a.failed_count::bigint AS failed_count
+  , COALESCE(EXTRACT(EPOCH FROM NOW() - a.last_archived_time), -1)::bigint AS last_archived_age_seconds
+  , COALESCE(EXTRACT(EPOCH FROM NOW() - a.last_failed_time), -1)::bigint AS last_failed_age_seconds
+FROM pg_stat_archiver AS a;
+
+-- name: FailoverStandbys :many
+-- Streaming standbys with their sync role, replay lag, and last feedback.
+SELECT
+  application_name::text AS application_name
+  , state::text AS state
+  , COALESCE(sync_state, 'async')::text AS sync_state
+  , COALESCE(PG_WAL_LSN_DIFF(
+    CASE WHEN PG_IS_IN_RECOVERY() THEN PG_LAST_WAL_REPLAY_LSN() ELSE PG_CURRENT_WAL_LSN() END
+    , replay_lsn
+  ), -1)::bigint AS replay_lag_bytes
+  , COALESCE(EXTRACT(EPOCH FROM replay_lag), 0)::float8 AS replay_lag_seconds
+  , COALESCE(EXTRACT(EPOCH FROM NOW() - reply_time), -1)::float8 AS reply_age_seconds
+FROM pg_stat_replication
+ORDER BY replay_lag_bytes, application_name;
+
+-- name: FailoverParameterChecks :many
+-- On a standby, the control file records the primary's values of the
+-- settings a hot standby must match or exceed.
+SELECT
+  v.name::text AS setting_name
+  , s.setting::bigint AS local_value
+  , v.primary_value::bigint AS primary_value
+FROM PG_CONTROL_RECOVERY() AS c
+CROSS JOIN LATERAL (
+  VALUES
+  ('max_connections', c.max_connections_setting)
+  , ('max_worker_processes', c.max_worker_processes_setting)
+  , ('max_wal_senders', c.max_wal_senders_setting)
+  , ('max_prepared_transactions', c.max_prepared_xacts_setting)
+  , ('max_locks_per_transaction', c.max_locks_per_xact_setting)
+) AS v (name, primary_value)
+INNER JOIN pg_settings AS s ON v.name = s.name
+ORDER BY v.name;
+
+-- name: FailoverSlots :many
+-- Replication slots with WAL retention status and, on PostgreSQL 17+,
+-- whether logical slots are synchronized to standbys for failover.
+SELECT
+  rs.slot_name::text AS slot_name
+  , rs.slot_type::text AS slot_type
+  , rs.active
+  , COALESCE(rs.wal_status, '')::text AS wal_status
+  , COALESCE((TO_JSONB(rs) ->> 'failover')::boolean, false) AS failover
+  , COALESCE(PG_WAL_LSN_DIFF(
+    CASE WHEN PG_IS_IN_RECOVERY() THEN PG_LAST_WAL_REPLAY_LSN() ELSE PG_CURRENT_WAL_LSN() END
+    , rs.restart_lsn
+  ), 0)::bigint AS retained_bytes
+FROM pg_replication_slots AS rs
+WHERE NOT rs.temporary
+ORDER BY rs.slot_type, rs.slot_name;
@@ -2,12 +2,15 @@
 package freezeage
 
 import (
+	"cmp"
 	"context"
 	_ "embed"
 	"fmt"
+	"slices"
 
 	"github.com/fresha/pgdoctor/check"
 	"github.com/fresha/pgdoctor/db"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 //go:embed query.sql
@@ -18,7 +21,9 @@ var readme string
 
 type FreezeAgeQueries interface {
 	DatabaseFreezeAge(context.Context) ([]db.DatabaseFreezeAgeRow, error)
-	TableFreezeAge(context.Context) ([]db.TableFreezeAgeRow, error)
+	TableFreezeAge(context.Context, string) ([]db.TableFreezeAgeRow, error)
+	FailsafeFreezeAge(context.Context, string) ([]db.FailsafeFreezeAgeRow, error)
+	VacuumFailsafeAge(context.Context) (db.VacuumFailsafeAgeRow, error)
 }
 
 type checker struct {
@@ -35,16 +40,34 @@ const (
 	// Table-level thresholds (lower since tables can be vacuumed individually).
 	tableAgeWarnThreshold = int64(400_000_000)
 	tableAgeFailThreshold = int64(800_000_000)
+
+	// Failsafe proximity thresholds, as a fraction of the effective failsafe
+	// age. Once a table crosses it, vacuum disables cost-based delay and
+	// skips index cleanup, which can saturate I/O without warning.
+	failsafeWarnRatio = 0.70
+	failsafeFailRatio = 0.90
+
+	// PostgreSQL silently raises vacuum_failsafe_age to 105% of
+	// autovacuum_freeze_max_age when it is set lower (vacuum_xid_failsafe_check).
+	failsafeFreezeMaxAgeRatio = 1.05
 )
 
 func Metadata() check.Metadata {
 	return check.Metadata{
-		Category:    check.CategoryVacuum,
-		CheckID:     "freeze-age",
-		Name:        "Transaction ID Freeze Age",
-		Description: "Monitors transaction ID age to prevent wraparound issues",
-		Readme:      readme,
-		SQL:         querySQL,
+		Category:       check.CategoryVacuum,
+		CheckID:        "freeze-age",
+		Name:           "Transaction ID Freeze Age",
+		Description:    "Monitors transaction ID age to prevent wraparound issues",
+		Readme:         readme,
+		SQL:            querySQL,
+		RuntimeClass:   check.RuntimeMedium,
+		ProductionSafe: true,
+		Priority:       check.PriorityCritical,
+		Findings: []check.FindingSpec{
+			{ID: "database-freeze-age", Description: "Transaction ID age of each database", Thresholds: "WARN > 500M, FAIL > 1B"},
+			{ID: "table-freeze-age", Description: "Transaction ID age of individual tables", Thresholds: "WARN > 400M, FAIL > 800M"},
+			{ID: "failsafe-proximity", Description: "Table transaction ID age relative to vacuum_failsafe_age (PostgreSQL 14+)", Thresholds: "WARN >= 70%, FAIL >= 90% of max(vacuum_failsafe_age, 105% of autovacuum_freeze_max_age)"},
+		},
 	}
 }
 
@@ -60,20 +83,44 @@ func (c *checker) Metadata() check.Metadata {
 
 func (c *checker) Check(ctx context.Context) (*check.Report, error) {
 	report := check.NewReport(Metadata())
+	ignore := check.ObjectFilterFromContext(ctx)
 
 	dbRows, err := c.queries.DatabaseFreezeAge(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("running %s/%s (database): %w", check.CategoryVacuum, report.CheckID, err)
 	}
 
-	tableRows, err := c.queries.TableFreezeAge(ctx)
+	tableRows, err := c.queries.TableFreezeAge(ctx, check.SchemaFromContext(ctx, "public"))
 	if err != nil {
 		return nil, fmt.Errorf("running %s/%s (tables): %w", check.CategoryVacuum, report.CheckID, err)
 	}
+	tableRows = slices.DeleteFunc(tableRows, func(row db.TableFreezeAgeRow) bool {
+		return ignore.QualifiedTable(row.TableName.String)
+	})
+
+	failsafe, err := c.queries.VacuumFailsafeAge(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("running %s/%s (failsafe): %w", check.CategoryVacuum, report.CheckID, err)
+	}
+
+	var failsafeRows []db.FailsafeFreezeAgeRow
+	failsafeAge := effectiveFailsafeAge(failsafe)
+	if failsafeAge > 0 {
+		// The failsafe is cluster-wide, so look past the public default
+		// unless the run is scoped to one schema.
+		failsafeRows, err = c.queries.FailsafeFreezeAge(ctx, check.SchemaFromContext(ctx, ""))
+		if err != nil {
+			return nil, fmt.Errorf("running %s/%s (failsafe tables): %w", check.CategoryVacuum, report.CheckID, err)
+		}
+		failsafeRows = slices.DeleteFunc(failsafeRows, func(row db.FailsafeFreezeAgeRow) bool {
+			return ignore.Table(row.SchemaName.String, row.Relname.String)
+		})
+	}
 
 	// Run subchecks.
 	checkDatabaseFreezeAge(dbRows, report)
 	checkTableFreezeAge(tableRows, report)
+	checkFailsafeProximity(failsafeRows, failsafeAge, report)
 
 	return report, nil
 }
@@ -146,11 +193,17 @@ func checkDatabaseFreezeAge(rows []db.DatabaseFreezeAgeRow, report *check.Report
 		severity = check.SeverityFail
 	}
 
+	oldest := slices.MaxFunc(append(critical, warning...), func(a, b db.DatabaseFreezeAgeRow) int {
+		return cmp.Compare(a.FreezeAge.Int32, b.FreezeAge.Int32)
+	})
+
 	report.AddFinding(check.Finding{
 		ID:       "database-freeze-age",
 		Name:     "Database Freeze Age",
 		Severity: severity,
 		Details:  fmt.Sprintf("Found %d database(s) with high transaction ID age", len(critical)+len(warning)),
+		Impact: fmt.Sprintf("Database %s is %.0f%% of the way to transaction ID wraparound; PostgreSQL stops accepting writes before it gets there",
+			oldest.DatabaseName.String, float64(oldest.FreezeAge.Int32)/2_000_000_000*100),
 		Table: &check.Table{
 			Headers: []string{"Database", "Age", "% to Limit", "Freeze Max Age"},
 			Rows:    tableRows,
@@ -189,7 +242,7 @@ func checkTableFreezeAge(rows []db.TableFreezeAgeRow, report *check.Report) {
 				row.TableName.String,
 				formatAge(int64(row.FreezeAge.Int32)),
 				check.FormatBytes(row.TableSizeBytes.Int64),
-				formatVacuumTime(row),
+				formatVacuumTime(row.LastAutovacuum, row.LastVacuum),
 				fmt.Sprintf("%d", row.AutovacuumCount.Int64+row.VacuumCount.Int64),
 			},
 			Severity: check.SeverityFail,
@@ -202,7 +255,7 @@ func checkTableFreezeAge(rows []db.TableFreezeAgeRow, report *check.Report) {
 				row.TableName.String,
 				formatAge(int64(row.FreezeAge.Int32)),
 				check.FormatBytes(row.TableSizeBytes.Int64),
-				formatVacuumTime(row),
+				formatVacuumTime(row.LastAutovacuum, row.LastVacuum),
 				fmt.Sprintf("%d", row.AutovacuumCount.Int64+row.VacuumCount.Int64),
 			},
 			Severity: check.SeverityWarn,
@@ -214,11 +267,17 @@ func checkTableFreezeAge(rows []db.TableFreezeAgeRow, report *check.Report) {
 		severity = check.SeverityFail
 	}
 
+	oldest := slices.MaxFunc(append(critical, warning...), func(a, b db.TableFreezeAgeRow) int {
+		return cmp.Compare(a.FreezeAge.Int32, b.FreezeAge.Int32)
+	})
+
 	report.AddFinding(check.Finding{
 		ID:       "table-freeze-age",
 		Name:     "Table Freeze Age",
 		Severity: severity,
 		Details:  fmt.Sprintf("Found %d table(s) with high transaction ID age", len(critical)+len(warning)),
+		Impact: fmt.Sprintf("%s has gone %s transactions without being frozen; until vacuum freezes it, it holds the database's wraparound age up",
+			oldest.TableName.String, formatAge(int64(oldest.FreezeAge.Int32))),
 		Table: &check.Table{
 			Headers: []string{"Table", "Age", "Size", "Last Vacuum", "Vacuum Count"},
 			Rows:    tableRows,
@@ -226,6 +285,82 @@ func checkTableFreezeAge(rows []db.TableFreezeAgeRow, report *check.Report) {
 	})
 }
 
+// effectiveFailsafeAge returns the age at which vacuum enters failsafe mode:
+// vacuum_failsafe_age, or 105% of autovacuum_freeze_max_age when that is
+// higher. It returns 0 before PostgreSQL 14, where there is no failsafe.
+func effectiveFailsafeAge(row db.VacuumFailsafeAgeRow) int64 {
+	if !row.VacuumFailsafeAge.Valid {
+		return 0
+	}
+	age := row.VacuumFailsafeAge.Int64
+	if row.AutovacuumFreezeMaxAge.Valid {
+		age = max(age, int64(float64(row.AutovacuumFreezeMaxAge.Int64)*failsafeFreezeMaxAgeRatio))
+	}
+	return age
+}
+
+// checkFailsafeProximity compares relation freeze ages against the effective
+// failsafe age. The failsafe only exists on PostgreSQL 14+, so the subcheck
+// is omitted when failsafeAge is 0.
+func checkFailsafeProximity(rows []db.FailsafeFreezeAgeRow, failsafeAge int64, report *check.Report) {
+	if failsafeAge <= 0 {
+		return
+	}
+
+	warnAge := int64(float64(failsafeAge) * failsafeWarnRatio)
+	failAge := int64(float64(failsafeAge) * failsafeFailRatio)
+
+	var tableRows []check.TableRow
+	severity := check.SeverityOK
+
+	// Rows are ordered by age descending, so critical tables come first.
+	for _, row := range rows {
+		age := int64(row.FreezeAge.Int32)
+		if age < warnAge {
+			continue
+		}
+
+		rowSeverity := check.SeverityWarn
+		if age >= failAge {
+			rowSeverity = check.SeverityFail
+		}
+		severity = max(severity, rowSeverity)
+
+		tableRows = append(tableRows, check.TableRow{
+			Cells: []string{
+				row.TableName.String,
+				formatAge(age),
+				fmt.Sprintf("%.1f%%", float64(age)/float64(failsafeAge)*100),
+				formatVacuumTime(row.LastAutovacuum, row.LastVacuum),
+			},
+			Severity: rowSeverity,
+		})
+	}
+
+	if len(tableRows) == 0 {
+		report.AddFinding(check.Finding{
+			ID:       "failsafe-proximity",
+			Name:     "Vacuum Failsafe Proximity",
+			Severity: check.SeverityOK,
+			Details:  fmt.Sprintf("All tables below 70%% of the effective failsafe age (%s)", formatAge(failsafeAge)),
+		})
+		return
+	}
+
+	report.AddFinding(check.Finding{
+		ID:       "failsafe-proximity",
+		Name:     "Vacuum Failsafe Proximity",
+		Severity: severity,
+		Details: fmt.Sprintf("Found %d table(s) approaching the effective failsafe age (%s)\n\n"+
+			"At the failsafe threshold, vacuum ignores cost delay and skips index cleanup,\n"+
+			"which can saturate I/O during business hours.", len(tableRows), formatAge(failsafeAge)),
+		Table: &check.Table{
+			Headers: []string{"Table", "Age", "% of Failsafe", "Last Vacuum"},
+			Rows:    tableRows,
+		},
+	})
+}
+
 // Helper functions.
 
 func formatAge(age int64) string {
@@ -241,12 +376,12 @@ func formatAge(age int64) string {
 	return fmt.Sprintf("%d", age)
 }
 
-func formatVacuumTime(row db.TableFreezeAgeRow) string {
-	if row.LastAutovacuum.Valid {
-		return row.LastAutovacuum.Time.Format("2006-01-02 15:04")
+func formatVacuumTime(lastAutovacuum, lastVacuum pgtype.Timestamptz) string {
+	if lastAutovacuum.Valid {
+		return lastAutovacuum.Time.Format("2006-01-02 15:04")
 	}
-	if row.LastVacuum.Valid {
-		return row.LastVacuum.Time.Format("2006-01-02 15:04") + " (manual)"
+	if lastVacuum.Valid {
+		return lastVacuum.Time.Format("2006-01-02 15:04") + " (manual)"
 	}
 	return "never"
 }
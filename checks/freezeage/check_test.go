@@ -3,10 +3,12 @@ package freezeage_test
 import (
 	"context"
 	"fmt"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/fresha/pgdoctor/check"
+	"github.com/fresha/pgdoctor/check/checktest"
 	"github.com/fresha/pgdoctor/checks/freezeage"
 	"github.com/fresha/pgdoctor/db"
 	"github.com/jackc/pgx/v5/pgtype"
@@ -17,6 +19,7 @@ import (
 const (
 	findingIDDatabaseFreezeAge = "database-freeze-age"
 	findingIDTableFreezeAge    = "table-freeze-age"
+	findingIDFailsafe          = "failsafe-proximity"
 )
 
 type mockQueryer struct {
@@ -24,6 +27,15 @@ type mockQueryer struct {
 	tableRows []db.TableFreezeAgeRow
 	dbErr     error
 	tableErr  error
+
+	failsafeAge  pgtype.Int8
+	freezeMaxAge pgtype.Int8
+	failsafeRows []db.FailsafeFreezeAgeRow
+
+	// tableSchema and failsafeSchema are the schemas TableFreezeAge and
+	// FailsafeFreezeAge were asked for.
+	tableSchema    string
+	failsafeSchema string
 }
 
 func (m *mockQueryer) DatabaseFreezeAge(context.Context) ([]db.DatabaseFreezeAgeRow, error) {
@@ -33,13 +45,23 @@ func (m *mockQueryer) DatabaseFreezeAge(context.Context) ([]db.DatabaseFreezeAge
 	return m.dbRows, nil
 }
 
-func (m *mockQueryer) TableFreezeAge(context.Context) ([]db.TableFreezeAgeRow, error) {
+func (m *mockQueryer) TableFreezeAge(_ context.Context, schema string) ([]db.TableFreezeAgeRow, error) {
+	m.tableSchema = schema
 	if m.tableErr != nil {
 		return nil, m.tableErr
 	}
 	return m.tableRows, nil
 }
 
+func (m *mockQueryer) FailsafeFreezeAge(_ context.Context, schema string) ([]db.FailsafeFreezeAgeRow, error) {
+	m.failsafeSchema = schema
+	return m.failsafeRows, nil
+}
+
+func (m *mockQueryer) VacuumFailsafeAge(context.Context) (db.VacuumFailsafeAgeRow, error) {
+	return db.VacuumFailsafeAgeRow{VacuumFailsafeAge: m.failsafeAge, AutovacuumFreezeMaxAge: m.freezeMaxAge}, nil
+}
+
 func makeDatabaseRow(dbName string, freezeAge int32, freezeMaxAge int64) db.DatabaseFreezeAgeRow {
 	return db.DatabaseFreezeAgeRow{
 		DatabaseName: pgtype.Text{String: dbName, Valid: true},
@@ -49,6 +71,15 @@ func makeDatabaseRow(dbName string, freezeAge int32, freezeMaxAge int64) db.Data
 	}
 }
 
+func makeFailsafeRow(schemaName, tableName string, freezeAge int32) db.FailsafeFreezeAgeRow {
+	return db.FailsafeFreezeAgeRow{
+		SchemaName: pgtype.Text{String: schemaName, Valid: true},
+		Relname:    pgtype.Text{String: strings.TrimSuffix(tableName, " (TOAST)"), Valid: true},
+		TableName:  pgtype.Text{String: schemaName + "." + tableName, Valid: true},
+		FreezeAge:  pgtype.Int4{Int32: freezeAge, Valid: true},
+	}
+}
+
 func makeTableRow(
 	schemaName, tableName string,
 	freezeAge int32,
@@ -651,3 +682,187 @@ func TestFreezeAge_AgeFormatting(t *testing.T) {
 		})
 	}
 }
+
+func TestFreezeAge_FailsafeProximity(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name             string
+		freezeAge        int32
+		expectedSeverity check.Severity
+	}{
+		{name: "below 70%", freezeAge: 1_000_000_000, expectedSeverity: check.SeverityOK},
+		{name: "exactly 70%", freezeAge: 1_120_000_000, expectedSeverity: check.SeverityWarn},
+		{name: "exactly 90%", freezeAge: 1_440_000_000, expectedSeverity: check.SeverityFail},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			queryer := &mockQueryer{
+				dbRows: []db.DatabaseFreezeAgeRow{
+					makeDatabaseRow("test_db", 100_000_000, 200_000_000),
+				},
+				failsafeRows: []db.FailsafeFreezeAgeRow{
+					makeFailsafeRow("public", "events", tt.freezeAge),
+				},
+				failsafeAge: pgtype.Int8{Int64: 1_600_000_000, Valid: true},
+			}
+
+			report, err := freezeage.New(queryer).Check(context.Background())
+			require.NoError(t, err)
+
+			finding := checktest.Finding(t, report, findingIDFailsafe)
+			assert.Equal(t, tt.expectedSeverity, finding.Severity)
+			if tt.expectedSeverity != check.SeverityOK {
+				require.NotNil(t, finding.Table)
+				assert.Equal(t, "public.events", finding.Table.Rows[0].Cells[0])
+			}
+		})
+	}
+}
+
+func TestFreezeAge_FailsafeProximity_LoweredSetting(t *testing.T) {
+	t.Parallel()
+
+	// A table well inside table-freeze-age limits can still be near a lowered failsafe.
+	queryer := &mockQueryer{
+		dbRows: []db.DatabaseFreezeAgeRow{
+			makeDatabaseRow("test_db", 100_000_000, 200_000_000),
+		},
+		failsafeRows: []db.FailsafeFreezeAgeRow{
+			makeFailsafeRow("public", "events", 300_000_000),
+		},
+		failsafeAge: pgtype.Int8{Int64: 400_000_000, Valid: true},
+	}
+
+	report, err := freezeage.New(queryer).Check(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, check.SeverityOK, checktest.Finding(t, report, findingIDTableFreezeAge).Severity)
+	assert.Equal(t, check.SeverityWarn, checktest.Finding(t, report, findingIDFailsafe).Severity)
+}
+
+func TestFreezeAge_FailsafeProximity_RaisedByFreezeMaxAge(t *testing.T) {
+	t.Parallel()
+
+	// vacuum_failsafe_age below autovacuum_freeze_max_age is raised to 105%
+	// of it: 210M here, so 180M is 85.7% (WARN) rather than 120% (FAIL).
+	queryer := &mockQueryer{
+		dbRows: []db.DatabaseFreezeAgeRow{
+			makeDatabaseRow("test_db", 100_000_000, 200_000_000),
+		},
+		failsafeRows: []db.FailsafeFreezeAgeRow{
+			makeFailsafeRow("public", "events", 180_000_000),
+		},
+		failsafeAge:  pgtype.Int8{Int64: 150_000_000, Valid: true},
+		freezeMaxAge: pgtype.Int8{Int64: 200_000_000, Valid: true},
+	}
+
+	report, err := freezeage.New(queryer).Check(context.Background())
+	require.NoError(t, err)
+
+	finding := checktest.Finding(t, report, findingIDFailsafe)
+	assert.Equal(t, check.SeverityWarn, finding.Severity)
+	assert.Contains(t, finding.Details, "effective failsafe age (210.0M)")
+	assert.Equal(t, "85.7%", finding.Table.Rows[0].Cells[2])
+}
+
+func TestFreezeAge_FailsafeProximity_PrePG14(t *testing.T) {
+	t.Parallel()
+
+	queryer := &mockQueryer{
+		dbRows: []db.DatabaseFreezeAgeRow{
+			makeDatabaseRow("test_db", 100_000_000, 200_000_000),
+		},
+		failsafeRows: []db.FailsafeFreezeAgeRow{
+			makeFailsafeRow("public", "events", 1_500_000_000),
+		},
+	}
+
+	report, err := freezeage.New(queryer).Check(context.Background())
+	require.NoError(t, err)
+	checktest.NoFinding(t, report, findingIDFailsafe)
+}
+
+func TestFreezeAge_ScopedToSchema(t *testing.T) {
+	t.Parallel()
+
+	lastVacuum := time.Now().Add(-24 * time.Hour)
+	queryer := &mockQueryer{
+		dbRows: []db.DatabaseFreezeAgeRow{
+			makeDatabaseRow("postgres", 100_000_000, 200_000_000),
+		},
+		tableRows: []db.TableFreezeAgeRow{
+			makeTableRow("tenant_42", "bookings", 500_000_000, 1024*1024*1024, &lastVacuum, nil, 10, 2),
+		},
+	}
+	ctx := check.ContextWithObjectFilter(context.Background(), (*check.ObjectFilter)(nil).ScopedToSchema("tenant_42"))
+
+	report, err := freezeage.New(queryer).Check(ctx)
+	require.NoError(t, err)
+
+	assert.Equal(t, "tenant_42", queryer.tableSchema, "the query is filtered to the scoped schema before its LIMIT")
+	var tableFinding *check.Finding
+	for i := range report.Results {
+		if report.Results[i].ID == findingIDTableFreezeAge {
+			tableFinding = &report.Results[i]
+			break
+		}
+	}
+	require.NotNil(t, tableFinding)
+	assert.Equal(t, check.SeverityWarn, tableFinding.Severity)
+	require.NotNil(t, tableFinding.Table)
+	require.Len(t, tableFinding.Table.Rows, 1)
+	assert.Contains(t, tableFinding.Table.Rows[0].Cells, "tenant_42.bookings")
+}
+
+func TestFreezeAge_FailsafeProximity_AllSchemas(t *testing.T) {
+	t.Parallel()
+
+	// table-freeze-age looks at public; the failsafe fires on any relation,
+	// including another schema's table and a TOAST table.
+	queryer := &mockQueryer{
+		dbRows: []db.DatabaseFreezeAgeRow{
+			makeDatabaseRow("test_db", 100_000_000, 200_000_000),
+		},
+		failsafeRows: []db.FailsafeFreezeAgeRow{
+			makeFailsafeRow("billing", "invoices (TOAST)", 1_500_000_000),
+			makeFailsafeRow("audit", "log", 1_200_000_000),
+		},
+		failsafeAge: pgtype.Int8{Int64: 1_600_000_000, Valid: true},
+	}
+
+	report, err := freezeage.New(queryer).Check(context.Background())
+	require.NoError(t, err)
+
+	assert.Empty(t, queryer.failsafeSchema, "an unscoped run covers every schema")
+	finding := checktest.Finding(t, report, findingIDFailsafe)
+	assert.Equal(t, check.SeverityFail, finding.Severity)
+	require.Len(t, finding.Table.Rows, 2)
+	assert.Equal(t, "billing.invoices (TOAST)", finding.Table.Rows[0].Cells[0])
+	assert.Equal(t, "audit.log", finding.Table.Rows[1].Cells[0])
+}
+
+func TestFreezeAge_FailsafeProximity_Scoped(t *testing.T) {
+	t.Parallel()
+
+	queryer := &mockQueryer{
+		dbRows: []db.DatabaseFreezeAgeRow{
+			makeDatabaseRow("test_db", 100_000_000, 200_000_000),
+		},
+		failsafeRows: []db.FailsafeFreezeAgeRow{
+			makeFailsafeRow("tenant_42", "bookings (TOAST)", 1_500_000_000),
+		},
+		failsafeAge: pgtype.Int8{Int64: 1_600_000_000, Valid: true},
+	}
+	filter := (*check.ObjectFilter)(nil).ScopedToSchema("tenant_42")
+
+	report, err := freezeage.New(queryer).Check(check.ContextWithObjectFilter(context.Background(), filter))
+	require.NoError(t, err)
+
+	assert.Equal(t, "tenant_42", queryer.failsafeSchema)
+	assert.Equal(t, check.SeverityFail, checktest.Finding(t, report, findingIDFailsafe).Severity,
+		"a TOAST table is scoped by its owner's schema")
+}
@@ -28,7 +28,49 @@ INNER JOIN pg_namespace AS n ON c.relnamespace = n.oid
 LEFT JOIN pg_stat_user_tables AS s ON c.oid = s.relid
 WHERE
   c.relkind = 'r'
-  AND n.nspname = 'public'
+  AND n.nspname = sqlc.arg(schema_name)::text
   AND c.relfrozenxid != '0'
 ORDER BY age(c.relfrozenxid) DESC
 LIMIT 50;
+
+-- name: FailsafeFreezeAge :many
+-- Gets the relations with the oldest frozen XIDs across all user schemas, or
+-- only schema_name when it is set. Vacuum's failsafe triggers on any relation
+-- with a relfrozenxid, so this covers materialized views and TOAST tables,
+-- which are named and scoped by the table that owns them.
+SELECT
+  n.nspname::text AS schema_name
+  , COALESCE(owner.relname, c.relname)::text AS relname
+  , (
+    n.nspname || '.' || COALESCE(owner.relname, c.relname)
+    || CASE WHEN c.relkind = 't' THEN ' (TOAST)' ELSE '' END
+  )::text AS table_name
+  , s.last_autovacuum
+  , s.last_vacuum
+  , age(c.relfrozenxid) AS freeze_age
+FROM pg_class AS c
+LEFT JOIN pg_class AS owner ON c.relkind = 't' AND owner.reltoastrelid = c.oid
+INNER JOIN pg_namespace AS n ON COALESCE(owner.relnamespace, c.relnamespace) = n.oid
+LEFT JOIN pg_stat_all_tables AS s ON COALESCE(owner.oid, c.oid) = s.relid
+WHERE
+  c.relkind IN ('r', 'm', 't')
+  AND c.relfrozenxid != '0'
+  AND n.nspname NOT IN ('pg_catalog', 'information_schema')
+  AND n.nspname !~ '^pg_(toast|temp_)'
+  AND (sqlc.arg(schema_name)::text = '' OR n.nspname = sqlc.arg(schema_name)::text)
+ORDER BY age(c.relfrozenxid) DESC
+LIMIT 50;
+
+-- name: VacuumFailsafeAge :one
+-- Gets vacuum_failsafe_age (PostgreSQL 14+, NULL on older versions) and
+-- autovacuum_freeze_max_age, which together set the age the failsafe
+-- triggers at.
+SELECT
+  (
+    SELECT s.setting::bigint FROM pg_settings AS s
+    WHERE s.name = 'vacuum_failsafe_age'
+  ) AS vacuum_failsafe_age
+  , (
+    SELECT s.setting::bigint FROM pg_settings AS s
+    WHERE s.name = 'autovacuum_freeze_max_age'
+  ) AS autovacuum_freeze_max_age;
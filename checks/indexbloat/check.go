@@ -5,9 +5,16 @@ import (
 	"context"
 	_ "embed"
 	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/fresha/pgdoctor/check"
 	"github.com/fresha/pgdoctor/db"
+	"github.com/fresha/pgdoctor/internal/pgident"
 )
 
 //go:embed query.sql
@@ -18,27 +25,78 @@ var readme string
 
 type IndexBloatQueries interface {
 	IndexBloat(context.Context) ([]db.IndexBloatRow, error)
+	PgstatindexAvailable(context.Context) (bool, error)
+	IndexLeafDensity(context.Context, db.IndexLeafDensityParams) (db.IndexLeafDensityRow, error)
+	WithStatementTimeout(context.Context, time.Duration, func() error) error
 }
 
 type checker struct {
-	queries IndexBloatQueries
+	queries     IndexBloatQueries
+	deepTop     int           // indexes to measure with pgstatindex; 0 trusts the estimate
+	deepTimeout time.Duration // statement_timeout for each measurement
 }
 
+// measurement is the bloat pgstatindex leaf density implies for one index.
+type measurement struct {
+	bloatPercent float64
+	bloatBytes   int64
+}
+
+const (
+	// REINDEX candidates: same bar as large-bloat.
+	reindexMinBloatPercent = 30.0
+	reindexMinBloatBytes   = int64(100 * 1024 * 1024)
+
+	// Indexes averaging more scans than this over the stats window are needed
+	// constantly; rebuilding them doubles their footprint in shared buffers while
+	// both copies are hot, so they are left for a planned maintenance window.
+	hotIndexScansPerSecond = 100.0
+
+	// Cap on REINDEX statements printed in the plan.
+	maxReindexStatements = 10
+
+	// statement_timeout for each pgstatindex call. It reads every page of the
+	// index, so it needs longer than the session default allows on large ones.
+	defaultDeepTimeout = 30 * time.Second
+)
+
 func Metadata() check.Metadata {
 	return check.Metadata{
-		Category:    check.CategoryIndexes,
-		CheckID:     "index-bloat",
-		Name:        "Index Bloat",
-		Description: "Estimates B-tree index bloat to identify indexes needing maintenance",
-		Readme:      readme,
-		SQL:         querySQL,
+		Category:       check.CategoryIndexes,
+		CheckID:        "index-bloat",
+		Name:           "Index Bloat",
+		Description:    "Estimates B-tree index bloat to identify indexes needing maintenance",
+		Readme:         readme,
+		SQL:            querySQL,
+		RuntimeClass:   check.RuntimeHeavy,
+		ProductionSafe: true,
+		Privileges:     []check.Privilege{check.PrivilegeReadSchemas},
+		Findings: []check.FindingSpec{
+			{ID: "high-bloat", Description: "Indexes with a high estimated bloat percentage", Thresholds: "WARN > 50%, FAIL > 70%"},
+			{ID: "large-bloat", Description: "Indexes wasting a large absolute amount of space (> 30% bloat)", Thresholds: "WARN > 100MB, FAIL > 1GB wasted"},
+			{ID: "reindex-schedule", Description: "Ranked REINDEX CONCURRENTLY plan, largest recoverable space first, excluding constantly-used indexes", Thresholds: "WARN when any index has > 30% and > 100MB bloat"},
+			{ID: "deep-verification", Description: "Estimated vs pgstatindex-measured bloat for the worst indexes (requires deep_bloat_top)"},
+		},
 	}
 }
 
-func New(queries IndexBloatQueries, _ ...check.Config) check.Checker {
-	return &checker{
-		queries: queries,
+func New(queries IndexBloatQueries, cfg ...check.Config) check.Checker {
+	c := &checker{queries: queries, deepTimeout: defaultDeepTimeout}
+	if len(cfg) > 0 && cfg[0] != nil {
+		if myCfg, ok := cfg[0][Metadata().CheckID]; ok {
+			if v, ok := myCfg["deep_bloat_top"]; ok {
+				if n, err := strconv.Atoi(v); err == nil && n > 0 {
+					c.deepTop = n
+				}
+			}
+			if v, ok := myCfg["deep_bloat_timeout_ms"]; ok {
+				if n, err := strconv.Atoi(v); err == nil && n > 0 {
+					c.deepTimeout = time.Duration(n) * time.Millisecond
+				}
+			}
+		}
 	}
+	return c
 }
 
 func (c *checker) Metadata() check.Metadata {
@@ -47,11 +105,15 @@ func (c *checker) Metadata() check.Metadata {
 
 func (c *checker) Check(ctx context.Context) (*check.Report, error) {
 	report := check.NewReport(Metadata())
+	ignore := check.ObjectFilterFromContext(ctx)
 
 	rows, err := c.queries.IndexBloat(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("running %s/%s: %w", check.CategoryIndexes, report.CheckID, err)
 	}
+	rows = slices.DeleteFunc(rows, func(row db.IndexBloatRow) bool {
+		return ignore.Index(row.Schemaname.String, row.Tablename.String, row.Indexname.String)
+	})
 
 	if len(rows) == 0 {
 		report.AddFinding(check.Finding{
@@ -63,158 +125,495 @@ func (c *checker) Check(ctx context.Context) (*check.Report, error) {
 		return report, nil
 	}
 
+	measured, unmeasured, available, err := c.measure(ctx, rows)
+	if err != nil {
+		return nil, fmt.Errorf("running %s/%s (pgstatindex): %w", check.CategoryIndexes, report.CheckID, err)
+	}
+
 	// Run subchecks
-	checkHighBloatIndexes(rows, report)
-	checkLargeBloatedIndexes(rows, report)
+	checkHighBloatIndexes(rows, measured, report)
+	checkLargeBloatedIndexes(rows, measured, report)
+	checkReindexSchedule(rows, measured, report)
+	if c.deepTop > 0 {
+		checkDeepVerification(rows, measured, unmeasured, available, report)
+	}
 
 	return report, nil
 }
 
+// measure runs pgstatindex on the deepTop indexes the estimate flags, likely
+// failures first, each under its own deepTimeout. An index whose measurement
+// fails, by timing out or lacking permission, is returned in unmeasured with
+// the reason and keeps its estimate. measured is nil when deep verification
+// is off or pgstattuple is unavailable.
+func (c *checker) measure(ctx context.Context, rows []db.IndexBloatRow) (measured map[string]measurement, unmeasured map[string]string, available bool, err error) {
+	if c.deepTop == 0 {
+		return nil, nil, false, nil
+	}
+	available, err = c.queries.PgstatindexAvailable(ctx)
+	if err != nil || !available {
+		return nil, nil, false, err
+	}
+
+	var candidates []db.IndexBloatRow
+	for _, row := range rows {
+		if estimatedSeverity(row) > check.SeverityOK {
+			candidates = append(candidates, row)
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		si, sj := estimatedSeverity(candidates[i]), estimatedSeverity(candidates[j])
+		if si != sj {
+			return si > sj
+		}
+		return candidates[i].BloatBytes.Int64 > candidates[j].BloatBytes.Int64
+	})
+
+	measured = make(map[string]measurement, min(len(candidates), c.deepTop))
+	unmeasured = make(map[string]string)
+	for _, row := range candidates[:min(len(candidates), c.deepTop)] {
+		var stats db.IndexLeafDensityRow
+		err := c.queries.WithStatementTimeout(ctx, c.deepTimeout, func() error {
+			var err error
+			stats, err = c.queries.IndexLeafDensity(ctx, db.IndexLeafDensityParams{
+				SchemaName: row.Schemaname.String,
+				IndexName:  row.Indexname.String,
+			})
+			return err
+		})
+		if ctx.Err() != nil {
+			return nil, nil, true, ctx.Err()
+		}
+		if err != nil {
+			unmeasured[qualifiedIndexName(row)] = err.Error()
+			continue
+		}
+		measured[qualifiedIndexName(row)] = measureLeafDensity(stats, row.FillFactor)
+	}
+	return measured, unmeasured, true, nil
+}
+
+// measureLeafDensity treats leaf space beyond what the fillfactor leaves free,
+// plus empty and deleted pages, as bloat.
+func measureLeafDensity(stats db.IndexLeafDensityRow, fillFactor int32) measurement {
+	if stats.IndexSize == 0 {
+		return measurement{}
+	}
+	if fillFactor <= 0 {
+		fillFactor = 90
+	}
+	density := stats.AvgLeafDensity
+	if math.IsNaN(density) {
+		density = 0
+	}
+
+	leafBytes := float64(stats.LeafPages * stats.BlockSize)
+	wasted := leafBytes*max(0, 1-density/float64(fillFactor)) + float64((stats.EmptyPages+stats.DeletedPages)*stats.BlockSize)
+	return measurement{
+		bloatPercent: 100 * wasted / float64(stats.IndexSize),
+		bloatBytes:   int64(wasted),
+	}
+}
+
+// bloat returns an index's bloat, measured when available.
+func bloat(row db.IndexBloatRow, measured map[string]measurement) (float64, int64, bool) {
+	if m, ok := measured[qualifiedIndexName(row)]; ok {
+		return m.bloatPercent, m.bloatBytes, true
+	}
+	return getBloatPercent(row), row.BloatBytes.Int64, false
+}
+
+// estimatedSeverity is the worst severity the estimate gives an index in
+// high-bloat or large-bloat.
+func estimatedSeverity(row db.IndexBloatRow) check.Severity {
+	return max(highBloatSeverity(getBloatPercent(row)), largeBloatSeverity(getBloatPercent(row), row.BloatBytes.Int64))
+}
+
+func highBloatSeverity(pct float64) check.Severity {
+	switch {
+	case pct >= 70:
+		return check.SeverityFail
+	case pct >= 50:
+		return check.SeverityWarn
+	default:
+		return check.SeverityOK
+	}
+}
+
+// largeBloatSeverity only considers indexes with at least 30% bloat, to avoid
+// false positives on very large indexes.
+func largeBloatSeverity(pct float64, bloatBytes int64) check.Severity {
+	const oneGB = int64(1024 * 1024 * 1024)
+	const oneHundredMB = int64(100 * 1024 * 1024)
+
+	switch {
+	case pct < 30:
+		return check.SeverityOK
+	case bloatBytes >= oneGB:
+		return check.SeverityFail
+	case bloatBytes >= oneHundredMB:
+		return check.SeverityWarn
+	default:
+		return check.SeverityOK
+	}
+}
+
+// basis labels a value as measured by pgstatindex or estimated from
+// statistics.
+func basis(measured bool) string {
+	if measured {
+		return "measured"
+	}
+	return "estimated"
+}
+
 // checkHighBloatIndexes identifies indexes with high bloat percentage.
-func checkHighBloatIndexes(rows []db.IndexBloatRow, report *check.Report) {
-	var critical []db.IndexBloatRow // >70%
-	var warning []db.IndexBloatRow  // >50%
+// Estimates never fail the finding; a FAIL row confirmed by pgstatindex does.
+func checkHighBloatIndexes(rows []db.IndexBloatRow, measured map[string]measurement, report *check.Report) {
+	var critical []check.TableRow // >70%
+	var warning []check.TableRow  // >50%
+	confirmed := false
 
 	for _, row := range rows {
-		pct := getBloatPercent(row)
-		if pct >= 70 {
-			critical = append(critical, row)
-		} else if pct >= 50 {
-			warning = append(warning, row)
+		pct, bloatBytes, isMeasured := bloat(row, measured)
+		severity := highBloatSeverity(pct)
+		if severity == check.SeverityOK {
+			continue
+		}
+
+		cells := []string{
+			row.Tablename.String,
+			row.Indexname.String,
+			fmt.Sprintf("%.1f%%", pct),
+			check.FormatBytes(bloatBytes),
+			check.FormatBytes(row.ActualBytes.Int64),
+		}
+		if measured != nil {
+			cells = append(cells, basis(isMeasured))
+		}
+		tableRow := check.TableRow{Cells: cells, Severity: severity}
+
+		if severity == check.SeverityFail {
+			critical = append(critical, tableRow)
+			confirmed = confirmed || isMeasured
+		} else {
+			warning = append(warning, tableRow)
 		}
 	}
 
 	if len(critical) == 0 && len(warning) == 0 {
 		report.AddFinding(check.Finding{
-			ID:       "high-bloat",
-			Name:     "Index Bloat Percentage",
-			Severity: check.SeverityOK,
-			Details:  "No indexes with excessive bloat (>50%) detected",
+			ID:         "high-bloat",
+			Confidence: check.ConfidenceMedium,
+			Name:       "Index Bloat Percentage",
+			Severity:   check.SeverityOK,
+			Details:    "No indexes with excessive bloat (>50%) detected",
 		})
 		return
 	}
 
 	headers := []string{"Table", "Index", "Bloat %", "Bloat Size", "Actual Size"}
-	var tableRows []check.TableRow
+	if measured != nil {
+		headers = append(headers, "Basis")
+	}
 
-	for _, row := range critical {
-		tableRows = append(tableRows, check.TableRow{
-			Cells: []string{
-				row.Tablename.String,
-				row.Indexname.String,
-				fmt.Sprintf("%.1f%%", getBloatPercent(row)),
-				check.FormatBytes(row.BloatBytes.Int64),
-				check.FormatBytes(row.ActualBytes.Int64),
-			},
-			Severity: check.SeverityFail,
-		})
+	severity, confidence := confirmedSeverity(confirmed)
+	report.AddFinding(check.Finding{
+		ID:         "high-bloat",
+		Confidence: confidence,
+		Name:       "Index Bloat Percentage",
+		Severity:   severity,
+		Details:    fmt.Sprintf("Found %d index(es) with high bloat (>50%%)", len(critical)+len(warning)),
+		Table: &check.Table{
+			Headers: headers,
+			Rows:    append(critical, warning...),
+		},
+	})
+}
+
+// checkLargeBloatedIndexes identifies large indexes with notable bloat.
+func checkLargeBloatedIndexes(rows []db.IndexBloatRow, measured map[string]measurement, report *check.Report) {
+	var critical []check.TableRow // >1GB bloat
+	var warning []check.TableRow  // >100MB bloat
+	var totalWasted int64
+	confirmed := false
+
+	for _, row := range rows {
+		pct, bloatBytes, isMeasured := bloat(row, measured)
+		severity := largeBloatSeverity(pct, bloatBytes)
+		if severity == check.SeverityOK {
+			continue
+		}
+
+		cells := []string{
+			row.Tablename.String,
+			row.Indexname.String,
+			check.FormatBytes(bloatBytes),
+			fmt.Sprintf("%.1f%%", pct),
+			check.FormatBytes(row.ActualBytes.Int64),
+		}
+		if measured != nil {
+			cells = append(cells, basis(isMeasured))
+		}
+		tableRow := check.TableRow{Cells: cells, Severity: severity}
+		totalWasted += bloatBytes
+
+		if severity == check.SeverityFail {
+			critical = append(critical, tableRow)
+			confirmed = confirmed || isMeasured
+		} else {
+			warning = append(warning, tableRow)
+		}
 	}
 
-	for _, row := range warning {
-		tableRows = append(tableRows, check.TableRow{
-			Cells: []string{
-				row.Tablename.String,
-				row.Indexname.String,
-				fmt.Sprintf("%.1f%%", getBloatPercent(row)),
-				check.FormatBytes(row.BloatBytes.Int64),
-				check.FormatBytes(row.ActualBytes.Int64),
-			},
-			Severity: check.SeverityWarn,
+	if len(critical) == 0 && len(warning) == 0 {
+		report.AddFinding(check.Finding{
+			ID:         "large-bloat",
+			Confidence: check.ConfidenceMedium,
+			Name:       "Large Bloated Indexes",
+			Severity:   check.SeverityOK,
+			Details:    "No large bloated indexes (>100MB wasted) detected",
 		})
+		return
+	}
+
+	headers := []string{"Table", "Index", "Bloat Size", "Bloat %", "Actual Size"}
+	if measured != nil {
+		headers = append(headers, "Basis")
 	}
 
+	severity, confidence := confirmedSeverity(confirmed)
 	report.AddFinding(check.Finding{
-		ID:       "high-bloat",
-		Name:     "Index Bloat Percentage",
-		Severity: check.SeverityWarn,
-		Details:  fmt.Sprintf("Found %d index(es) with high bloat (>50%%)", len(critical)+len(warning)),
+		ID:         "large-bloat",
+		Confidence: confidence,
+		Name:       "Large Bloated Indexes",
+		Severity:   severity,
+		Details:    fmt.Sprintf("Found %d index(es) wasting significant disk space (total: %s)", len(critical)+len(warning), check.FormatBytes(totalWasted)),
 		Table: &check.Table{
 			Headers: headers,
-			Rows:    tableRows,
+			Rows:    append(critical, warning...),
 		},
 	})
 }
 
-// checkLargeBloatedIndexes identifies large indexes with notable bloat.
-func checkLargeBloatedIndexes(rows []db.IndexBloatRow, report *check.Report) {
-	const oneGB = int64(1024 * 1024 * 1024)
-	const oneHundredMB = int64(100 * 1024 * 1024)
+// confirmedSeverity is WARN at medium confidence for estimates, and FAIL at
+// high confidence once a measurement confirms a failing row.
+func confirmedSeverity(confirmed bool) (check.Severity, check.Confidence) {
+	if confirmed {
+		return check.SeverityFail, check.ConfidenceHigh
+	}
+	return check.SeverityWarn, check.ConfidenceMedium
+}
 
-	var critical []db.IndexBloatRow // >1GB bloat
-	var warning []db.IndexBloatRow  // >100MB bloat
+// checkReindexSchedule turns bloated indexes into an ordered REINDEX CONCURRENTLY plan.
+// Indexes are ranked by recoverable space so the first rebuilds reclaim the most disk.
+func checkReindexSchedule(rows []db.IndexBloatRow, measured map[string]measurement, report *check.Report) {
+	type candidate struct {
+		row         db.IndexBloatRow
+		recoverable int64
+		measured    bool
+	}
+	var candidates []candidate
+	var hot int
 
 	for _, row := range rows {
-		bloatBytes := row.BloatBytes.Int64
-		pct := getBloatPercent(row)
-
-		// Only consider if bloat is at least 30% to avoid false positives
-		if pct < 30 {
+		pct, bloatBytes, isMeasured := bloat(row, measured)
+		if pct < reindexMinBloatPercent || bloatBytes < reindexMinBloatBytes {
 			continue
 		}
-
-		if bloatBytes >= oneGB {
-			critical = append(critical, row)
-		} else if bloatBytes >= oneHundredMB {
-			warning = append(warning, row)
+		if scansPerSecond(row) > hotIndexScansPerSecond {
+			hot++
+			continue
 		}
+		candidates = append(candidates, candidate{row: row, recoverable: bloatBytes, measured: isMeasured})
 	}
 
-	if len(critical) == 0 && len(warning) == 0 {
+	hotNote := ""
+	if hot > 0 {
+		hotNote = fmt.Sprintf("\n\n%d bloated index(es) averaging >%.0f scans/s were excluded; rebuild them in a maintenance window.", hot, hotIndexScansPerSecond)
+	}
+
+	if len(candidates) == 0 {
 		report.AddFinding(check.Finding{
-			ID:       "large-bloat",
-			Name:     "Large Bloated Indexes",
-			Severity: check.SeverityOK,
-			Details:  "No large bloated indexes (>100MB wasted) detected",
+			ID:         "reindex-schedule",
+			Confidence: check.ConfidenceMedium,
+			Name:       "REINDEX Schedule",
+			Severity:   check.SeverityOK,
+			Details:    "No indexes need rebuilding" + hotNote,
 		})
 		return
 	}
 
-	headers := []string{"Table", "Index", "Bloat Size", "Bloat %", "Actual Size"}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].recoverable > candidates[j].recoverable
+	})
+
 	var tableRows []check.TableRow
+	var statements []string
+	var totalRecoverable int64
+
+	for i, c := range candidates {
+		totalRecoverable += c.recoverable
+		cells := []string{
+			fmt.Sprintf("%d", i+1),
+			qualifiedIndexName(c.row),
+			check.FormatBytes(c.recoverable),
+			check.FormatBytes(c.row.ActualBytes.Int64),
+			reindexDurationClass(c.row.ActualBytes.Int64),
+		}
+		if measured != nil {
+			cells = append(cells, basis(c.measured))
+		}
+		tableRows = append(tableRows, check.TableRow{Cells: cells, Severity: check.SeverityWarn})
+		if i < maxReindexStatements {
+			statements = append(statements, fmt.Sprintf("  REINDEX INDEX CONCURRENTLY %s;", pgident.Quote(c.row.Schemaname.String, c.row.Indexname.String)))
+		}
+	}
 
-	for _, row := range critical {
-		tableRows = append(tableRows, check.TableRow{
-			Cells: []string{
-				row.Tablename.String,
-				row.Indexname.String,
-				check.FormatBytes(row.BloatBytes.Int64),
-				fmt.Sprintf("%.1f%%", getBloatPercent(row)),
-				check.FormatBytes(row.ActualBytes.Int64),
-			},
-			Severity: check.SeverityFail,
+	if len(candidates) > maxReindexStatements {
+		statements = append(statements, fmt.Sprintf("  -- ... and %d more, in table order", len(candidates)-maxReindexStatements))
+	}
+
+	headers := []string{"#", "Index", "Recoverable", "Actual Size", "Duration"}
+	if measured != nil {
+		headers = append(headers, "Basis")
+	}
+
+	report.AddFinding(check.Finding{
+		ID:         "reindex-schedule",
+		Confidence: check.ConfidenceMedium,
+		Name:       "REINDEX Schedule",
+		Severity:   check.SeverityWarn,
+		Details: fmt.Sprintf("Rebuilding %d index(es) would recover about %s. Run one at a time, in order:\n\n%s%s",
+			len(candidates), check.FormatBytes(totalRecoverable), strings.Join(statements, "\n"), hotNote),
+		Table: &check.Table{
+			Headers: headers,
+			Rows:    tableRows,
+		},
+	})
+}
+
+// checkDeepVerification compares the estimate with pgstatindex for every
+// measured index, including those the measurement cleared, and lists the
+// indexes it could not measure.
+func checkDeepVerification(rows []db.IndexBloatRow, measured map[string]measurement, unmeasured map[string]string, available bool, report *check.Report) {
+	if !available {
+		report.AddFinding(check.Finding{
+			ID:       "deep-verification",
+			Name:     "Deep Bloat Verification",
+			Severity: check.SeverityOK,
+			Details: "Deep verification was requested, but pgstatindex is not installed or not executable by this role, " +
+				"so all values are estimates. Run CREATE EXTENSION pgstattuple and grant the role pg_stat_scan_tables",
 		})
+		return
+	}
+	if len(measured) == 0 && len(unmeasured) == 0 {
+		report.AddFinding(check.Finding{
+			ID:       "deep-verification",
+			Name:     "Deep Bloat Verification",
+			Severity: check.SeverityOK,
+			Details:  "The estimate flagged no indexes, so none were measured",
+		})
+		return
 	}
 
-	for _, row := range warning {
+	var tableRows []check.TableRow
+	cleared := 0
+	for _, row := range rows {
+		if reason, ok := unmeasured[qualifiedIndexName(row)]; ok {
+			tableRows = append(tableRows, check.TableRow{
+				Cells: []string{
+					qualifiedIndexName(row),
+					fmt.Sprintf("%.1f%%", getBloatPercent(row)),
+					check.FormatBytes(row.BloatBytes.Int64),
+					"-", "-",
+					"not measured: " + reason,
+				},
+				Severity: check.SeverityOK,
+			})
+			continue
+		}
+		m, ok := measured[qualifiedIndexName(row)]
+		if !ok {
+			continue
+		}
+		estimated := estimatedSeverity(row)
+		actual := max(highBloatSeverity(m.bloatPercent), largeBloatSeverity(m.bloatPercent, m.bloatBytes))
+		verdict := "confirmed"
+		switch {
+		case actual == check.SeverityOK:
+			verdict = "cleared"
+			cleared++
+		case actual < estimated:
+			verdict = "downgraded"
+		case actual > estimated:
+			verdict = "upgraded"
+		}
 		tableRows = append(tableRows, check.TableRow{
 			Cells: []string{
-				row.Tablename.String,
-				row.Indexname.String,
-				check.FormatBytes(row.BloatBytes.Int64),
+				qualifiedIndexName(row),
 				fmt.Sprintf("%.1f%%", getBloatPercent(row)),
-				check.FormatBytes(row.ActualBytes.Int64),
+				check.FormatBytes(row.BloatBytes.Int64),
+				fmt.Sprintf("%.1f%%", m.bloatPercent),
+				check.FormatBytes(m.bloatBytes),
+				verdict,
 			},
-			Severity: check.SeverityWarn,
+			Severity: check.SeverityOK,
 		})
 	}
 
-	totalWasted := int64(0)
-	for _, row := range append(critical, warning...) {
-		totalWasted += row.BloatBytes.Int64
-	}
-
 	report.AddFinding(check.Finding{
-		ID:       "large-bloat",
-		Name:     "Large Bloated Indexes",
-		Severity: check.SeverityWarn,
-		Details:  fmt.Sprintf("Found %d index(es) wasting significant disk space (total: %s)", len(critical)+len(warning), check.FormatBytes(totalWasted)),
+		ID:       "deep-verification",
+		Name:     "Deep Bloat Verification",
+		Severity: check.SeverityOK,
+		Details: fmt.Sprintf("Measured %d index(es) the estimate flagged with pgstatindex; %d measured below every threshold%s",
+			len(measured), cleared, unmeasuredNote(len(unmeasured))),
 		Table: &check.Table{
-			Headers: headers,
+			Headers: []string{"Index", "Bloat % (est)", "Bloat (est)", "Bloat % (measured)", "Bloat (measured)", "Verdict"},
 			Rows:    tableRows,
 		},
 	})
 }
 
+// unmeasuredNote tells the reader how many flagged indexes kept their
+// estimate because measuring them failed.
+func unmeasuredNote(n int) string {
+	if n == 0 {
+		return ""
+	}
+	return fmt.Sprintf("; %d could not be measured and keep their estimate (raise deep_bloat_timeout_ms if they timed out)", n)
+}
+
+// reindexDurationClass gives a rough REINDEX CONCURRENTLY duration from index size.
+// It builds the index from a full table scan and waits for concurrent transactions,
+// so these are deliberately coarse.
+func reindexDurationClass(actualBytes int64) string {
+	const oneGB = int64(1024 * 1024 * 1024)
+
+	switch {
+	case actualBytes < oneGB:
+		return "short (minutes)"
+	case actualBytes < 10*oneGB:
+		return "medium (< 1 hour)"
+	default:
+		return "long (hours)"
+	}
+}
+
+func scansPerSecond(row db.IndexBloatRow) float64 {
+	if !row.StatsAgeSeconds.Valid || row.StatsAgeSeconds.Int64 <= 0 {
+		return 0
+	}
+	return float64(row.IdxScan.Int64) / float64(row.StatsAgeSeconds.Int64)
+}
+
+func qualifiedIndexName(row db.IndexBloatRow) string {
+	if row.Schemaname.String == "" {
+		return row.Indexname.String
+	}
+	return row.Schemaname.String + "." + row.Indexname.String
+}
+
 func getBloatPercent(row db.IndexBloatRow) float64 {
 	if !row.BloatPercent.Valid {
 		return 0
@@ -2,8 +2,10 @@ package indexbloat_test
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/fresha/pgdoctor/check"
 	"github.com/fresha/pgdoctor/checks/indexbloat"
@@ -14,14 +16,33 @@ import (
 )
 
 type mockQueryer struct {
-	rows []db.IndexBloatRow
-	err  error
+	rows        []db.IndexBloatRow
+	err         error
+	pgstattuple bool
+	density     map[string]db.IndexLeafDensityRow // keyed by index name
+	densityErr  map[string]error                  // keyed by index name
+	measured    []string
+	timeouts    []time.Duration
 }
 
 func (m *mockQueryer) IndexBloat(ctx context.Context) ([]db.IndexBloatRow, error) {
 	return m.rows, m.err
 }
 
+func (m *mockQueryer) PgstatindexAvailable(context.Context) (bool, error) {
+	return m.pgstattuple, nil
+}
+
+func (m *mockQueryer) IndexLeafDensity(_ context.Context, arg db.IndexLeafDensityParams) (db.IndexLeafDensityRow, error) {
+	m.measured = append(m.measured, arg.IndexName)
+	return m.density[arg.IndexName], m.densityErr[arg.IndexName]
+}
+
+func (m *mockQueryer) WithStatementTimeout(_ context.Context, timeout time.Duration, fn func() error) error {
+	m.timeouts = append(m.timeouts, timeout)
+	return fn()
+}
+
 func makeIndexRow(tableName, indexName string, bloatPct float64, bloatBytes, actualBytes int64) db.IndexBloatRow {
 	var bloatNumeric pgtype.Numeric
 	_ = bloatNumeric.Scan(fmt.Sprintf("%.2f", bloatPct))
@@ -50,11 +71,13 @@ func TestIndexBloat_AllHealthy(t *testing.T) {
 
 	require.NoError(t, err)
 	assert.Equal(t, check.SeverityOK, report.Severity)
-	assert.Len(t, report.Results, 2)
+	assert.Len(t, report.Results, 3)
 	assert.Equal(t, "high-bloat", report.Results[0].ID)
 	assert.Equal(t, "large-bloat", report.Results[1].ID)
 	assert.Equal(t, check.SeverityOK, report.Results[0].Severity)
 	assert.Equal(t, check.SeverityOK, report.Results[1].Severity)
+	assert.Equal(t, "reindex-schedule", report.Results[2].ID)
+	assert.Equal(t, check.SeverityOK, report.Results[2].Severity)
 }
 
 func TestIndexBloat_HighPercentageWarning(t *testing.T) {
@@ -71,7 +94,7 @@ func TestIndexBloat_HighPercentageWarning(t *testing.T) {
 
 	require.NoError(t, err)
 	assert.Equal(t, check.SeverityWarn, report.Severity)
-	assert.Len(t, report.Results, 2)
+	assert.Len(t, report.Results, 3)
 
 	highBloatFinding := report.Results[0]
 	assert.Equal(t, "high-bloat", highBloatFinding.ID)
@@ -294,3 +317,158 @@ func TestIndexBloat_Metadata(t *testing.T) {
 	assert.NotEmpty(t, metadata.Readme)
 	assert.NotEmpty(t, metadata.Description)
 }
+
+func TestIndexBloat_ReindexSchedule_RankedByRecoverableSpace(t *testing.T) {
+	t.Parallel()
+
+	const mb = int64(1024 * 1024)
+	const gb = 1024 * mb
+
+	small := makeIndexRow("public.users", "users_email_idx", 40.0, 200*mb, 500*mb)
+	small.Schemaname = pgtype.Text{String: "public", Valid: true}
+	large := makeIndexRow("public.orders", "orders_created_idx", 35.0, 5*gb, 15*gb)
+	large.Schemaname = pgtype.Text{String: "public", Valid: true}
+	ignored := makeIndexRow("public.items", "items_pkey", 20.0, 500*mb, 2*gb)
+
+	queryer := &mockQueryer{rows: []db.IndexBloatRow{small, large, ignored}}
+	report, err := indexbloat.New(queryer).Check(context.Background())
+	require.NoError(t, err)
+
+	finding := report.Results[2]
+	require.Equal(t, "reindex-schedule", finding.ID)
+	assert.Equal(t, check.SeverityWarn, finding.Severity)
+	require.NotNil(t, finding.Table)
+	require.Len(t, finding.Table.Rows, 2)
+	assert.Equal(t, "public.orders_created_idx", finding.Table.Rows[0].Cells[1])
+	assert.Equal(t, "long (hours)", finding.Table.Rows[0].Cells[4])
+	assert.Equal(t, "public.users_email_idx", finding.Table.Rows[1].Cells[1])
+	assert.Equal(t, "short (minutes)", finding.Table.Rows[1].Cells[4])
+	assert.Contains(t, finding.Details, "REINDEX INDEX CONCURRENTLY \"public\".\"orders_created_idx\";")
+}
+
+func TestIndexBloat_ReindexSchedule_ExcludesHotIndexes(t *testing.T) {
+	t.Parallel()
+
+	hot := makeIndexRow("public.users", "users_pkey", 50.0, 300*1024*1024, 600*1024*1024)
+	hot.IdxScan = pgtype.Int8{Int64: 1_000_000_000, Valid: true}
+	hot.StatsAgeSeconds = pgtype.Int8{Int64: 86_400, Valid: true}
+
+	queryer := &mockQueryer{rows: []db.IndexBloatRow{hot}}
+	report, err := indexbloat.New(queryer).Check(context.Background())
+	require.NoError(t, err)
+
+	finding := report.Results[2]
+	require.Equal(t, "reindex-schedule", finding.ID)
+	assert.Equal(t, check.SeverityOK, finding.Severity)
+	assert.Contains(t, finding.Details, "1 bloated index(es)")
+}
+
+func TestIndexBloat_DeepVerification(t *testing.T) {
+	t.Parallel()
+
+	const mb = int64(1024 * 1024)
+	const gb = 1024 * mb
+	const pages = gb / 8192
+
+	withSchema := func(row db.IndexBloatRow) db.IndexBloatRow {
+		row.Schemaname = pgtype.Text{String: "public", Valid: true}
+		row.FillFactor = 90
+		return row
+	}
+	rows := []db.IndexBloatRow{
+		withSchema(makeIndexRow("orders", "orders_status_idx", 75, 3*gb, 4*gb)),
+		withSchema(makeIndexRow("orders", "orders_created_idx", 72, 2*gb, 3*gb)),
+		withSchema(makeIndexRow("users", "users_email_idx", 55, 200*mb, 400*mb)),
+	}
+
+	t.Run("measures the worst indexes and confirms FAIL", func(t *testing.T) {
+		t.Parallel()
+
+		queryer := &mockQueryer{
+			rows:        rows,
+			pgstattuple: true,
+			density: map[string]db.IndexLeafDensityRow{
+				// Leaves a quarter full: two thirds of the fillfactor-adjusted leaf space is wasted.
+				"orders_status_idx": {IndexSize: 4 * gb, LeafPages: 4 * pages, AvgLeafDensity: 22.5, BlockSize: 8192},
+				// Leaves packed to the fillfactor: the estimate was wrong.
+				"orders_created_idx": {IndexSize: 3 * gb, LeafPages: 3 * pages, AvgLeafDensity: 90, BlockSize: 8192},
+			},
+		}
+
+		report, err := indexbloat.New(queryer, check.Config{"index-bloat": {"deep_bloat_top": "2"}}).Check(context.Background())
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"orders_status_idx", "orders_created_idx"}, queryer.measured)
+		assert.Equal(t, check.SeverityFail, report.Severity)
+
+		high := report.Results[0]
+		assert.Equal(t, check.SeverityFail, high.Severity)
+		assert.Equal(t, check.ConfidenceHigh, high.Confidence)
+		require.Len(t, high.Table.Rows, 2, "orders_created_idx was cleared by its measurement")
+		assert.Equal(t, []string{"orders", "orders_status_idx", "75.0%", "3.0GiB", "4.0GiB", "measured"}, high.Table.Rows[0].Cells)
+		assert.Equal(t, "estimated", high.Table.Rows[1].Cells[5])
+
+		deep := report.Results[len(report.Results)-1]
+		assert.Equal(t, "deep-verification", deep.ID)
+		assert.Contains(t, deep.Details, "1 measured below every threshold")
+		require.Len(t, deep.Table.Rows, 2)
+		assert.Equal(t, "confirmed", deep.Table.Rows[0].Cells[5])
+		assert.Equal(t, "cleared", deep.Table.Rows[1].Cells[5])
+	})
+
+	t.Run("a failed measurement keeps the estimate", func(t *testing.T) {
+		t.Parallel()
+
+		queryer := &mockQueryer{
+			rows:        rows,
+			pgstattuple: true,
+			density: map[string]db.IndexLeafDensityRow{
+				"orders_created_idx": {IndexSize: 3 * gb, LeafPages: 3 * pages, AvgLeafDensity: 90, BlockSize: 8192},
+			},
+			densityErr: map[string]error{
+				"orders_status_idx": errors.New("permission denied for function pgstatindex"),
+			},
+		}
+
+		report, err := indexbloat.New(queryer, check.Config{"index-bloat": {"deep_bloat_top": "2"}}).Check(context.Background())
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"orders_status_idx", "orders_created_idx"}, queryer.measured)
+		assert.Equal(t, []time.Duration{30 * time.Second, 30 * time.Second}, queryer.timeouts, "default budget")
+		assert.Equal(t, check.SeverityWarn, report.Severity, "an unmeasured index cannot confirm FAIL")
+
+		high := report.Results[0]
+		require.Len(t, high.Table.Rows, 2)
+		assert.Equal(t, []string{"orders", "orders_status_idx", "75.0%", "3.0GiB", "4.0GiB", "estimated"}, high.Table.Rows[0].Cells)
+
+		deep := report.Results[len(report.Results)-1]
+		assert.Equal(t, "deep-verification", deep.ID)
+		assert.Contains(t, deep.Details, "1 could not be measured")
+		require.Len(t, deep.Table.Rows, 2)
+		assert.Equal(t, "not measured: permission denied for function pgstatindex", deep.Table.Rows[0].Cells[5])
+		assert.Equal(t, "cleared", deep.Table.Rows[1].Cells[5])
+	})
+
+	t.Run("estimates alone only warn", func(t *testing.T) {
+		t.Parallel()
+
+		report, err := indexbloat.New(&mockQueryer{rows: rows}).Check(context.Background())
+		require.NoError(t, err)
+
+		assert.Equal(t, check.SeverityWarn, report.Severity)
+		assert.Len(t, report.Results, 3)
+	})
+
+	t.Run("pgstattuple unavailable", func(t *testing.T) {
+		t.Parallel()
+
+		queryer := &mockQueryer{rows: rows}
+		report, err := indexbloat.New(queryer, check.Config{"index-bloat": {"deep_bloat_top": "5"}}).Check(context.Background())
+		require.NoError(t, err)
+
+		assert.Empty(t, queryer.measured)
+		deep := report.Results[len(report.Results)-1]
+		assert.Equal(t, "deep-verification", deep.ID)
+		assert.Contains(t, deep.Details, "CREATE EXTENSION pgstattuple")
+	})
+}
@@ -7,6 +7,7 @@ WITH index_info AS (
     , t.relname::text AS tablename
     , i.relname::text AS indexname
     , t.oid AS table_oid
+    , ix.indexrelid AS index_oid
     , i.relpages AS actual_pages
     , i.reltuples
     , ix.indkey
@@ -33,6 +34,7 @@ WITH index_info AS (
     ii.schemaname
     , ii.tablename
     , ii.indexname
+    , ii.index_oid
     , ii.actual_pages
     , ii.reltuples
     , ii.fill_factor
@@ -61,9 +63,11 @@ WITH index_info AS (
     schemaname
     , tablename
     , indexname
+    , index_oid
     , actual_pages
     , reltuples
     , bs
+    , fill_factor
     , data_width
     -- Index tuple size: ItemPointer(6) + info(2) + data ≈ 8 + data_width
     -- Simplified: skip per-column MAXALIGN, add ~20% padding estimate
@@ -78,8 +82,10 @@ WITH index_info AS (
     schemaname
     , tablename
     , indexname
+    , index_oid
     , actual_pages
     , bs
+    , fill_factor
     -- Expected pages = ceil(tuples / (usable_space / (line_pointer(4) + tuple_size)))
     , GREATEST(1, CEIL(reltuples / FLOOR(usable_space / (4 + tuple_size))))::bigint AS est_pages
     , (actual_pages::bigint * bs) AS actual_bytes
@@ -88,18 +94,43 @@ WITH index_info AS (
 )
 
 SELECT
-  schemaname
-  , tablename
-  , indexname
-  , actual_pages
-  , est_pages
-  , actual_bytes
-  , ((actual_pages - est_pages)::bigint * bs) AS bloat_bytes
+  be.schemaname
+  , be.tablename
+  , be.indexname
+  , be.actual_pages
+  , be.est_pages
+  , be.actual_bytes
+  , ((be.actual_pages - be.est_pages)::bigint * be.bs) AS bloat_bytes
   , CASE
-    WHEN actual_pages > 0 AND actual_pages > est_pages
-      THEN ROUND(100.0 * (actual_pages - est_pages) / actual_pages, 1)
+    WHEN be.actual_pages > 0 AND be.actual_pages > be.est_pages
+      THEN ROUND(100.0 * (be.actual_pages - be.est_pages) / be.actual_pages, 1)
     ELSE 0
   END AS bloat_percent
-FROM bloat_estimate
-WHERE actual_pages > est_pages
+  -- Usage over the stats window, used to keep constantly-hit indexes out of the REINDEX schedule
+  , COALESCE(sui.idx_scan, 0)::bigint AS idx_scan
+  , EXTRACT(EPOCH FROM NOW() - COALESCE(sd.stats_reset, PG_POSTMASTER_START_TIME()))::bigint AS stats_age_seconds
+  , be.fill_factor
+FROM bloat_estimate AS be
+LEFT JOIN pg_stat_user_indexes AS sui ON be.index_oid = sui.indexrelid
+LEFT JOIN pg_stat_database AS sd ON sd.datname = CURRENT_DATABASE()
+WHERE be.actual_pages > be.est_pages
 ORDER BY bloat_percent DESC, bloat_bytes DESC;
+
+-- name: PgstatindexAvailable :one
+-- Checks whether pgstatindex is installed and executable by the current role.
+SELECT EXISTS(
+  SELECT 1
+  FROM pg_proc
+  WHERE proname = 'pgstatindex' AND HAS_FUNCTION_PRIVILEGE(oid, 'EXECUTE')
+) AS available;
+
+-- name: IndexLeafDensity :one
+-- Measures a B-tree index's leaf density with pgstatindex, which reads every page of the index.
+SELECT
+  index_size
+  , leaf_pages
+  , empty_pages
+  , deleted_pages
+  , avg_leaf_density
+  , CURRENT_SETTING('block_size')::bigint AS block_size
+FROM PGSTATINDEX(FORMAT('%I.%I', sqlc.arg(schema_name)::text, sqlc.arg(index_name)::text)::regclass);
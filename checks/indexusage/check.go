@@ -5,10 +5,13 @@ import (
 	"context"
 	_ "embed"
 	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/fresha/pgdoctor/check"
 	"github.com/fresha/pgdoctor/db"
+	"github.com/fresha/pgdoctor/internal/indexsafety"
+	"github.com/fresha/pgdoctor/internal/pgident"
 )
 
 //go:embed query.sql
@@ -25,10 +28,20 @@ const (
 	cacheWarnThreshold     = 95.0
 	cacheMinSizeMB         = 10
 	cacheFailSizeMB        = 100
+
+	// A B-tree on a column with this few distinct values cannot narrow a scan
+	// much, yet is maintained on every insert and non-HOT update.
+	lowCardinalityMaxDistinct = 10.0
+	lowCardinalityMinWrites   = int64(100000)
+	// When one value dominates, a partial index on the rare values keeps
+	// the lookups and drops most of the maintenance.
+	partialIndexMinTopFraction = 0.9
 )
 
 type IndexUsageQueries interface {
-	IndexUsageStats(context.Context) ([]db.IndexUsageStatsRow, error)
+	IndexUsageStats(context.Context, string) ([]db.IndexUsageStatsRow, error)
+	LowCardinalityIndexes(context.Context, string) ([]db.LowCardinalityIndexesRow, error)
+	IndexDropSafety(context.Context, string) ([]db.IndexDropSafetyRow, error)
 }
 
 type checker struct {
@@ -37,12 +50,21 @@ type checker struct {
 
 func Metadata() check.Metadata {
 	return check.Metadata{
-		Category:    check.CategoryIndexes,
-		CheckID:     "index-usage",
-		Name:        "Index Usage",
-		Description: "Identifies unused and inefficient indexes based on usage statistics",
-		Readme:      readme,
-		SQL:         querySQL,
+		Category:       check.CategoryIndexes,
+		CheckID:        "index-usage",
+		Name:           "Index Usage",
+		Description:    "Identifies unused and inefficient indexes based on usage statistics",
+		Readme:         readme,
+		SQL:            querySQL,
+		RuntimeClass:   check.RuntimeMedium,
+		ProductionSafe: true,
+		Privileges:     []check.Privilege{check.PrivilegeReadSchemas},
+		Findings: []check.FindingSpec{
+			{ID: "unused-indexes", Description: "Non-unique indexes larger than 10MB with zero scans", Thresholds: "FAIL"},
+			{ID: "low-usage-indexes", Description: "Indexes with few scans but heavy write maintenance", Thresholds: "WARN < 1,000 scans with > 10,000 writes"},
+			{ID: "index-cache-ratio", Description: "Indexes with a low buffer cache hit ratio", Thresholds: "WARN < 95% (> 10MB), FAIL < 90% (> 100MB)"},
+			{ID: "low-cardinality-indexes", Description: "Single-column B-tree indexes on boolean or very low-cardinality columns of write-heavy tables", Thresholds: "WARN <= 10 distinct values with >= 100,000 index writes"},
+		},
 	}
 }
 
@@ -58,11 +80,40 @@ func (c *checker) Metadata() check.Metadata {
 
 func (c *checker) Check(ctx context.Context) (*check.Report, error) {
 	report := check.NewReport(Metadata())
+	ignore := check.ObjectFilterFromContext(ctx)
+	schema := check.SchemaFromContext(ctx, "public")
 
-	rows, err := c.queries.IndexUsageStats(ctx)
+	rows, err := c.queries.IndexUsageStats(ctx, schema)
 	if err != nil {
 		return nil, fmt.Errorf("running %s/%s: %w", report.Category, report.CheckID, err)
 	}
+	rows = slices.DeleteFunc(rows, func(row db.IndexUsageStatsRow) bool {
+		return ignore.QualifiedTableIndex(row.TableName.String, row.IndexName.String)
+	})
+
+	lowCardinality, err := c.queries.LowCardinalityIndexes(ctx, schema)
+	if err != nil {
+		return nil, fmt.Errorf("running %s/%s (low cardinality): %w", report.Category, report.CheckID, err)
+	}
+	lowCardinality = slices.DeleteFunc(lowCardinality, func(row db.LowCardinalityIndexesRow) bool {
+		return ignore.QualifiedTableIndex(row.TableName, row.IndexName)
+	})
+
+	safetyRows, err := c.queries.IndexDropSafety(ctx, schema)
+	if err != nil {
+		return nil, fmt.Errorf("running %s/%s (drop safety): %w", report.Category, report.CheckID, err)
+	}
+	// Keyed by the quoted name, which stays unambiguous when a schema or
+	// index name contains a dot.
+	safety := make(map[string]indexsafety.Index, len(safetyRows))
+	for _, row := range safetyRows {
+		safety[pgident.Quote(row.SchemaName, row.IndexName)] = indexsafety.Index{
+			Constraints:         row.Constraints,
+			ReplicaIdentity:     row.IsReplicaIdentity,
+			PartitionOf:         row.PartitionOf,
+			SoleForeignKeyIndex: row.SoleForeignKeyIndex,
+		}
+	}
 
 	if len(rows) == 0 {
 		report.AddFinding(check.Finding{
@@ -73,15 +124,19 @@ func (c *checker) Check(ctx context.Context) (*check.Report, error) {
 		return report, nil
 	}
 
-	checkUnusedIndexes(rows, report)
+	checkUnusedIndexes(rows, safety, report)
 	checkLowUsageIndexes(rows, report)
 	checkIndexCacheRatio(rows, report)
+	checkLowCardinalityIndexes(lowCardinality, safety, report)
 
 	return report, nil
 }
 
-func checkUnusedIndexes(rows []db.IndexUsageStatsRow, report *check.Report) {
-	var unusedIndexes []string
+// checkUnusedIndexes reports unused indexes and prescribes dropping those
+// that indexsafety clears.
+func checkUnusedIndexes(rows []db.IndexUsageStatsRow, safety map[string]indexsafety.Index, report *check.Report) {
+	var unusedIndexes, keptIndexes []string
+	var fixes []check.Fix
 	unusedCount := 0
 
 	for _, row := range rows {
@@ -97,6 +152,11 @@ func checkUnusedIndexes(rows []db.IndexUsageStatsRow, report *check.Report) {
 			if len(unusedIndexes) < 10 {
 				unusedIndexes = append(unusedIndexes, fmt.Sprintf("%s.%s (%.1f MB)", row.TableName.String, row.IndexName.String, sizeMB))
 			}
+			if blockers := indexsafety.Verify(safety[pgident.Quote(row.SchemaName.String, row.IndexName.String)]); len(blockers) > 0 {
+				keptIndexes = append(keptIndexes, fmt.Sprintf("%s.%s %s", row.TableName.String, row.IndexName.String, indexsafety.Reasons(blockers)))
+				continue
+			}
+			fixes = append(fixes, dropIndexFix(row))
 		}
 	}
 
@@ -117,15 +177,35 @@ func checkUnusedIndexes(rows []db.IndexUsageStatsRow, report *check.Report) {
 	if unusedCount > len(unusedIndexes) {
 		details += fmt.Sprintf("\n... and %d more", unusedCount-len(unusedIndexes))
 	}
+	if len(keptIndexes) > 0 {
+		details += fmt.Sprintf("\n\nNot safe to drop despite 0 scans (%d):\n%s", len(keptIndexes), strings.Join(keptIndexes, "\n"))
+	}
+	details += fmt.Sprintf("\n\nZero scans means none since the last statistics reset; an index used only by monthly jobs can look unused after a recent reset (%s)", check.StatsResetFinding)
 
 	report.AddFinding(check.Finding{
 		ID:       "unused-indexes",
 		Name:     "Unused Indexes",
 		Severity: check.SeverityWarn,
 		Details:  details,
+		Fixes:    fixes,
 	})
 }
 
+// dropIndexFix drops an unused index without blocking writes. Scans on
+// standbys are not counted on the primary, so the description asks for
+// that to be confirmed first.
+func dropIndexFix(row db.IndexUsageStatsRow) check.Fix {
+	return check.Fix{
+		Object: row.SchemaName.String + "." + row.IndexName.String,
+		Description: fmt.Sprintf("Drop %s on %s (0 scans since the statistics reset, %s). Confirm it is unused on standbys too; "+
+			"recreate it from its definition if needed: %s", row.IndexName.String, row.TableName.String,
+			check.FormatBytes(row.IndexSizeBytes.Int64), row.Indexdef.String),
+		SQL:  "DROP INDEX CONCURRENTLY " + pgident.Quote(row.SchemaName.String, row.IndexName.String),
+		Risk: check.RiskLow,
+		Lock: check.LockOnline,
+	}
+}
+
 func checkLowUsageIndexes(rows []db.IndexUsageStatsRow, report *check.Report) {
 	var lowUsageIndexes []string
 	lowUsageCount := 0
@@ -160,6 +240,7 @@ func checkLowUsageIndexes(rows []db.IndexUsageStatsRow, report *check.Report) {
 	if lowUsageCount > len(lowUsageIndexes) {
 		details += fmt.Sprintf("\n... and %d more", lowUsageCount-len(lowUsageIndexes))
 	}
+	details += fmt.Sprintf("\n\nScans and writes are counted since the last statistics reset (%s)", check.StatsResetFinding)
 
 	report.AddFinding(check.Finding{
 		ID:       "low-usage-indexes",
@@ -223,3 +304,86 @@ func checkIndexCacheRatio(rows []db.IndexUsageStatsRow, report *check.Report) {
 		Details:  details,
 	})
 }
+
+// writesSaved estimates the index entry writes avoided over the statistics
+// window by replacing the index with a partial one on the rare values, or by
+// dropping it.
+func writesSaved(row db.LowCardinalityIndexesRow) (int64, string) {
+	if row.TopValueFraction >= partialIndexMinTopFraction {
+		return int64(float64(row.IndexEntryWrites) * row.TopValueFraction), "partial index"
+	}
+	return row.IndexEntryWrites, "drop"
+}
+
+func checkLowCardinalityIndexes(rows []db.LowCardinalityIndexesRow, safety map[string]indexsafety.Index, report *check.Report) {
+	var tableRows []check.TableRow
+	var totalSaved int64
+	var kept []string
+
+	for _, row := range rows {
+		if row.DistinctValues <= 0 || row.DistinctValues > lowCardinalityMaxDistinct || row.IndexEntryWrites < lowCardinalityMinWrites {
+			continue
+		}
+
+		// A partial index cannot stand in for one that backs a constraint,
+		// replica identity, or a foreign key either, so neither suggestion applies.
+		if blockers := indexsafety.Verify(safety[pgident.Quote(row.SchemaName, row.IndexName)]); len(blockers) > 0 {
+			kept = append(kept, fmt.Sprintf("%s %s", row.IndexName, indexsafety.Reasons(blockers)))
+			continue
+		}
+
+		saved, suggestion := writesSaved(row)
+		totalSaved += saved
+
+		share := ""
+		if row.TableIndexCount > 0 {
+			share = fmt.Sprintf(" (%.0f%% of index upkeep)", 100*float64(saved)/float64(row.IndexEntryWrites*row.TableIndexCount))
+		}
+
+		tableRows = append(tableRows, check.TableRow{
+			Cells: []string{
+				row.TableName,
+				row.IndexName,
+				fmt.Sprintf("%s (%s)", row.ColumnName, row.ColumnType),
+				fmt.Sprintf("%.0f", row.DistinctValues),
+				fmt.Sprintf("%.0f%%", 100*row.TopValueFraction),
+				check.FormatNumber(row.IdxScan),
+				check.FormatNumber(row.IndexEntryWrites),
+				check.FormatNumber(saved) + share,
+				suggestion,
+			},
+			Severity: check.SeverityWarn,
+		})
+	}
+
+	keptNote := ""
+	if len(kept) > 0 {
+		keptNote = fmt.Sprintf("\n\nLeft out because they cannot be dropped or replaced (%d):\n%s", len(kept), strings.Join(kept, "\n"))
+	}
+
+	if len(tableRows) == 0 {
+		report.AddFinding(check.Finding{
+			ID:         "low-cardinality-indexes",
+			Confidence: check.ConfidenceMedium,
+			Name:       "Low Cardinality Indexes",
+			Severity:   check.SeverityOK,
+			Details:    strings.TrimPrefix(keptNote, "\n\n"),
+		})
+		return
+	}
+
+	report.AddFinding(check.Finding{
+		ID:         "low-cardinality-indexes",
+		Confidence: check.ConfidenceMedium,
+		Name:       "Low Cardinality Indexes",
+		Severity:   check.SeverityWarn,
+		Details: fmt.Sprintf("Found %d B-tree indexes on columns with %.0f or fewer distinct values on write-heavy tables. "+
+			"Such an index rarely beats a sequential scan except for a rare value, but every insert and non-HOT update writes to it. "+
+			"Replacing or dropping them would avoid an estimated %s index entry writes over the current statistics window",
+			len(tableRows), lowCardinalityMaxDistinct, check.FormatNumber(totalSaved)) + keptNote,
+		Table: &check.Table{
+			Headers: []string{"Table", "Index", "Column", "Distinct", "Top Value", "Scans", "Index Writes", "Est. Writes Saved", "Suggestion"},
+			Rows:    tableRows,
+		},
+	})
+}
@@ -3,6 +3,8 @@ package indexusage_test
 import (
 	"context"
 	"fmt"
+	"slices"
+	"strings"
 	"testing"
 
 	"github.com/fresha/pgdoctor/check"
@@ -17,15 +19,52 @@ const (
 )
 
 type mockIndexUsageQueryer struct {
-	rows []db.IndexUsageStatsRow
-	err  error
+	rows           []db.IndexUsageStatsRow
+	lowCardinality []db.LowCardinalityIndexesRow
+	safety         []db.IndexDropSafetyRow
+	err            error
+
+	// schemas records the schema each query was asked for.
+	schemas []string
 }
 
-func (m *mockIndexUsageQueryer) IndexUsageStats(context.Context) ([]db.IndexUsageStatsRow, error) {
+// The mock fills in the schema columns from the "schema.name" strings the
+// test cases use, as the queries return both.
+
+func (m *mockIndexUsageQueryer) IndexUsageStats(_ context.Context, schema string) ([]db.IndexUsageStatsRow, error) {
+	m.schemas = append(m.schemas, schema)
 	if m.err != nil {
 		return nil, m.err
 	}
-	return m.rows, nil
+	rows := slices.Clone(m.rows)
+	for i, row := range rows {
+		if schema, _, ok := strings.Cut(row.TableName.String, "."); ok && !row.SchemaName.Valid {
+			rows[i].SchemaName = pgtype.Text{String: schema, Valid: true}
+		}
+	}
+	return rows, nil
+}
+
+func (m *mockIndexUsageQueryer) LowCardinalityIndexes(_ context.Context, schema string) ([]db.LowCardinalityIndexesRow, error) {
+	m.schemas = append(m.schemas, schema)
+	rows := slices.Clone(m.lowCardinality)
+	for i, row := range rows {
+		if schema, _, ok := strings.Cut(row.TableName, "."); ok && row.SchemaName == "" {
+			rows[i].SchemaName = schema
+		}
+	}
+	return rows, nil
+}
+
+func (m *mockIndexUsageQueryer) IndexDropSafety(_ context.Context, schema string) ([]db.IndexDropSafetyRow, error) {
+	m.schemas = append(m.schemas, schema)
+	rows := slices.Clone(m.safety)
+	for i, row := range rows {
+		if schema, index, ok := strings.Cut(row.IndexName, "."); ok && row.SchemaName == "" {
+			rows[i].SchemaName, rows[i].IndexName = schema, index
+		}
+	}
+	return rows, nil
 }
 
 func newMockQueryer(rows []db.IndexUsageStatsRow) *mockIndexUsageQueryer {
@@ -74,7 +113,7 @@ func Test_IndexUsage(t *testing.T) {
 				},
 			},
 			ExpectedSeverity: check.SeverityWarn,
-			ExpectedFindings: 3,
+			ExpectedFindings: 4,
 		},
 		{
 			Name: "low usage index (<1000 scans, >10k writes) - WARN",
@@ -91,7 +130,7 @@ func Test_IndexUsage(t *testing.T) {
 				},
 			},
 			ExpectedSeverity: check.SeverityWarn,
-			ExpectedFindings: 3,
+			ExpectedFindings: 4,
 		},
 		{
 			Name: "low cache ratio large index (>100MB) - FAIL",
@@ -108,7 +147,7 @@ func Test_IndexUsage(t *testing.T) {
 				},
 			},
 			ExpectedSeverity: check.SeverityWarn,
-			ExpectedFindings: 3,
+			ExpectedFindings: 4,
 		},
 		{
 			Name: "mixed issues - FAIL",
@@ -135,7 +174,7 @@ func Test_IndexUsage(t *testing.T) {
 				},
 			},
 			ExpectedSeverity: check.SeverityWarn,
-			ExpectedFindings: 3,
+			ExpectedFindings: 4,
 		},
 	}
 
@@ -201,6 +240,46 @@ func Test_IndexUsage_UnusedIndexes(t *testing.T) {
 	require.Equal(t, check.SeverityWarn, unusedResult.Severity)
 	require.Contains(t, unusedResult.Details, "2 unused indexes")
 	require.Contains(t, unusedResult.Details, "idx_users_unused_1")
+	require.Contains(t, unusedResult.Details, check.StatsResetFinding)
+}
+
+func Test_IndexUsage_UnusedIndexes_Fixes(t *testing.T) {
+	t.Parallel()
+
+	rows := []db.IndexUsageStatsRow{
+		{
+			TableName:      pgtype.Text{String: "public.Orders", Valid: true},
+			IndexName:      pgtype.Text{String: "idx_Orders_status", Valid: true},
+			IdxScan:        pgtype.Int8{Int64: 0, Valid: true},
+			IndexSizeBytes: pgtype.Int8{Int64: 20971520, Valid: true},
+			TableWrites:    pgtype.Int8{Int64: 50000, Valid: true},
+			Indexdef:       pgtype.Text{String: `CREATE INDEX "idx_Orders_status" ON public."Orders" USING btree (status)`, Valid: true},
+		},
+		{
+			TableName:      pgtype.Text{String: "public.users", Valid: true},
+			IndexName:      pgtype.Text{String: "users_pkey", Valid: true},
+			IdxScan:        pgtype.Int8{Int64: 0, Valid: true},
+			IndexSizeBytes: pgtype.Int8{Int64: 20971520, Valid: true},
+			IsPrimary:      true,
+		},
+	}
+
+	report, err := indexusage.New(newMockQueryer(rows)).Check(context.Background())
+	require.NoError(t, err)
+
+	var unused *check.Finding
+	for i := range report.Results {
+		if report.Results[i].ID == "unused-indexes" {
+			unused = &report.Results[i]
+		}
+	}
+	require.NotNil(t, unused)
+	require.Len(t, unused.Fixes, 1, "primary keys are never offered")
+	fix := unused.Fixes[0]
+	require.Equal(t, "public.idx_Orders_status", fix.Object)
+	require.Equal(t, `DROP INDEX CONCURRENTLY "public"."idx_Orders_status"`, fix.SQL)
+	require.Equal(t, check.RiskLow, fix.Risk)
+	require.Contains(t, fix.Description, "CREATE INDEX")
 }
 
 func Test_IndexUsage_LowUsageIndexes(t *testing.T) {
@@ -551,3 +630,166 @@ func Test_IndexUsage_OKResult(t *testing.T) {
 	require.Equal(t, "index-usage", result.ID, "ID should be index-usage")
 	require.Empty(t, result.Details, "Details should be empty for OK result")
 }
+
+func Test_IndexUsage_LowCardinalityIndexes(t *testing.T) {
+	t.Parallel()
+
+	type testCase struct {
+		Name               string
+		Row                db.LowCardinalityIndexesRow
+		ExpectedSeverity   check.Severity
+		Safety             db.IndexDropSafetyRow
+		ExpectedSuggestion string
+		ExpectedSaved      string
+	}
+
+	testCases := []testCase{
+		{
+			Name:               "boolean skewed towards one value - partial index",
+			Row:                db.LowCardinalityIndexesRow{DistinctValues: 2, TopValueFraction: 0.98, IndexEntryWrites: 1_000_000, TableIndexCount: 4},
+			ExpectedSeverity:   check.SeverityWarn,
+			ExpectedSuggestion: "partial index",
+			ExpectedSaved:      "980.0K (24% of index upkeep)",
+		},
+		{
+			Name:               "evenly distributed status - drop",
+			Row:                db.LowCardinalityIndexesRow{DistinctValues: 5, TopValueFraction: 0.3, IndexEntryWrites: 200_000, TableIndexCount: 2},
+			ExpectedSeverity:   check.SeverityWarn,
+			ExpectedSuggestion: "drop",
+			ExpectedSaved:      "200.0K (50% of index upkeep)",
+		},
+		{
+			Name:             "only index for a foreign key - left out",
+			Row:              db.LowCardinalityIndexesRow{DistinctValues: 5, TopValueFraction: 0.3, IndexEntryWrites: 200_000, TableIndexCount: 2},
+			Safety:           db.IndexDropSafetyRow{SoleForeignKeyIndex: []string{"bookings_status_id_fkey"}},
+			ExpectedSeverity: check.SeverityOK,
+		},
+		{
+			Name:             "few writes - OK",
+			Row:              db.LowCardinalityIndexesRow{DistinctValues: 2, TopValueFraction: 0.5, IndexEntryWrites: 1_000, TableIndexCount: 2},
+			ExpectedSeverity: check.SeverityOK,
+		},
+		{
+			Name:             "many distinct values - OK",
+			Row:              db.LowCardinalityIndexesRow{DistinctValues: 5_000, TopValueFraction: 0.01, IndexEntryWrites: 1_000_000, TableIndexCount: 2},
+			ExpectedSeverity: check.SeverityOK,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+
+			tc.Row.TableName = "public.bookings"
+			tc.Row.IndexName = "bookings_cancelled_idx"
+			tc.Row.ColumnName = "cancelled"
+			tc.Row.ColumnType = "boolean"
+
+			queryer := newMockQueryer([]db.IndexUsageStatsRow{
+				{IndexSizeBytes: pgtype.Int8{Int64: 1024, Valid: true}},
+			})
+			queryer.lowCardinality = []db.LowCardinalityIndexesRow{tc.Row}
+			tc.Safety.IndexName = "public.bookings_cancelled_idx"
+			queryer.safety = []db.IndexDropSafetyRow{tc.Safety}
+
+			report, err := indexusage.New(queryer).Check(context.Background())
+			require.NoError(t, err)
+
+			var result *check.Finding
+			for i := range report.Results {
+				if report.Results[i].ID == "low-cardinality-indexes" {
+					result = &report.Results[i]
+				}
+			}
+			require.NotNil(t, result)
+			require.Equal(t, tc.ExpectedSeverity, result.Severity)
+
+			if tc.ExpectedSeverity == check.SeverityOK {
+				require.Nil(t, result.Table)
+				if len(tc.Safety.SoleForeignKeyIndex) > 0 {
+					require.Contains(t, result.Details, "bookings_cancelled_idx is the only index for foreign key bookings_status_id_fkey")
+				}
+				return
+			}
+			require.NotNil(t, result.Table)
+			require.Len(t, result.Table.Rows, 1)
+			cells := result.Table.Rows[0].Cells
+			require.Equal(t, tc.ExpectedSaved, cells[7])
+			require.Equal(t, tc.ExpectedSuggestion, cells[8])
+		})
+	}
+}
+
+func Test_IndexUsage_UnusedIndexes_DropSafety(t *testing.T) {
+	t.Parallel()
+
+	unused := func(table, index string) db.IndexUsageStatsRow {
+		return db.IndexUsageStatsRow{
+			TableName:      pgtype.Text{String: table, Valid: true},
+			IndexName:      pgtype.Text{String: index, Valid: true},
+			IdxScan:        pgtype.Int8{Int64: 0, Valid: true},
+			IndexSizeBytes: pgtype.Int8{Int64: 20971520, Valid: true},
+		}
+	}
+
+	queryer := newMockQueryer([]db.IndexUsageStatsRow{
+		unused("public.orders", "orders_customer_id_idx"),
+		unused("public.orders", "orders_note_idx"),
+		unused("public.events_2026", "events_2026_created_at_idx"),
+		unused("public.accounts", "accounts_ref_idx"),
+	})
+	queryer.safety = []db.IndexDropSafetyRow{
+		{IndexName: "public.orders_customer_id_idx", SoleForeignKeyIndex: []string{"orders_customer_id_fkey"}},
+		{IndexName: "public.orders_note_idx"},
+		{IndexName: "public.events_2026_created_at_idx", PartitionOf: "public.events_created_at_idx"},
+		{IndexName: "public.accounts_ref_idx", IsReplicaIdentity: true},
+	}
+
+	report, err := indexusage.New(queryer).Check(context.Background())
+	require.NoError(t, err)
+
+	var finding *check.Finding
+	for i := range report.Results {
+		if report.Results[i].ID == "unused-indexes" {
+			finding = &report.Results[i]
+		}
+	}
+	require.NotNil(t, finding)
+	require.Equal(t, check.SeverityWarn, finding.Severity)
+	require.Contains(t, finding.Details, "Found 4 unused indexes")
+	require.Contains(t, finding.Details, "Not safe to drop despite 0 scans (3):")
+	require.Contains(t, finding.Details, "public.orders.orders_customer_id_idx is the only index for foreign key orders_customer_id_fkey")
+	require.Contains(t, finding.Details, "is a partition of public.events_created_at_idx")
+	require.Contains(t, finding.Details, "is the table's replica identity")
+
+	require.Len(t, finding.Fixes, 1, "only the index that passes every safety rule gets a DROP")
+	require.Equal(t, `DROP INDEX CONCURRENTLY "public"."orders_note_idx"`, finding.Fixes[0].SQL)
+}
+
+func Test_IndexUsage_ScopedToSchema(t *testing.T) {
+	t.Parallel()
+
+	rows := []db.IndexUsageStatsRow{
+		{
+			SchemaName:     pgtype.Text{String: "tenant_42", Valid: true},
+			TableName:      pgtype.Text{String: "tenant_42.bookings", Valid: true},
+			IndexName:      pgtype.Text{String: "idx_bookings_unused", Valid: true},
+			IdxScan:        pgtype.Int8{Int64: 0, Valid: true},
+			IndexSizeBytes: pgtype.Int8{Int64: 20971520, Valid: true},
+			TableWrites:    pgtype.Int8{Int64: 50000, Valid: true},
+			CacheHitRatio:  makeNumeric(98.0),
+		},
+	}
+
+	queryer := newMockQueryer(rows)
+	ctx := check.ContextWithObjectFilter(context.Background(), (*check.ObjectFilter)(nil).ScopedToSchema("tenant_42"))
+
+	report, err := indexusage.New(queryer).Check(ctx)
+	require.NoError(t, err)
+
+	require.Equal(t, []string{"tenant_42", "tenant_42", "tenant_42"}, queryer.schemas, "every query reads the scoped schema")
+	idx := slices.IndexFunc(report.Results, func(f check.Finding) bool { return f.ID == "unused-indexes" })
+	require.GreaterOrEqual(t, idx, 0)
+	require.Equal(t, check.SeverityWarn, report.Results[idx].Severity)
+	require.Contains(t, report.Results[idx].Details, "idx_bookings_unused")
+}
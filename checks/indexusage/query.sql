@@ -5,6 +5,7 @@
 SELECT
   (n.nspname || '.' || tbl.relname)::text AS table_name
   , psai.indexrelname::text AS index_name
+  , n.nspname::text AS schema_name
   , c.reltuples::bigint AS num_rows
   , x.indisprimary AS is_primary
   , x.indisunique AS is_unique
@@ -31,6 +32,99 @@ LEFT JOIN pg_class AS c ON psai.relid = c.oid
 LEFT JOIN pg_stat_user_tables AS ut ON tbl.oid = ut.relid
 LEFT JOIN pg_statio_user_indexes AS psaio ON psai.indexrelid = psaio.indexrelid
 WHERE
-  n.nspname = 'public'
+  n.nspname = sqlc.arg(schema_name)::text
 ORDER BY
   pg_relation_size(psai.indexrelid) DESC;
+
+-- name: LowCardinalityIndexes :many
+-- Single-column, non-unique, non-partial B-tree indexes whose column has very
+-- few distinct values (per pg_stats), with the write activity that maintains them.
+-- Returns data for subcheck: low-cardinality-indexes.
+SELECT
+  (n.nspname || '.' || tbl.relname)::text AS table_name
+  , psai.indexrelname::text AS index_name
+  , n.nspname::text AS schema_name
+  , a.attname::text AS column_name
+  , format_type(a.atttypid, a.atttypmod)::text AS column_type
+  , (CASE
+    WHEN s.n_distinct >= 0 THEN s.n_distinct
+    ELSE -s.n_distinct * greatest(tbl.reltuples, 0)
+  END)::float8 AS distinct_values
+  , coalesce(s.most_common_freqs[1], 0)::float8 AS top_value_fraction
+  , coalesce(s.most_common_vals::text, '')::text AS common_values
+  , pg_relation_size(psai.indexrelid)::bigint AS index_size_bytes
+  , coalesce(psai.idx_scan, 0)::bigint AS idx_scan
+  , (coalesce(ut.n_tup_ins, 0) + coalesce(ut.n_tup_upd, 0) + coalesce(ut.n_tup_del, 0))::bigint AS table_writes
+  -- Inserts and non-HOT updates add an entry to every index on the table.
+  , (coalesce(ut.n_tup_ins, 0) + coalesce(ut.n_tup_upd, 0) - coalesce(ut.n_tup_hot_upd, 0))::bigint AS index_entry_writes
+  , (SELECT count(*) FROM pg_index AS o WHERE o.indrelid = tbl.oid)::bigint AS table_index_count
+FROM pg_stat_user_indexes AS psai
+INNER JOIN pg_index AS x ON psai.indexrelid = x.indexrelid
+INNER JOIN pg_class AS ic ON x.indexrelid = ic.oid
+INNER JOIN pg_am AS am ON ic.relam = am.oid
+INNER JOIN pg_class AS tbl ON x.indrelid = tbl.oid
+INNER JOIN pg_namespace AS n ON tbl.relnamespace = n.oid
+INNER JOIN pg_attribute AS a ON tbl.oid = a.attrelid AND x.indkey[0] = a.attnum
+INNER JOIN pg_stats AS s ON n.nspname = s.schemaname AND tbl.relname = s.tablename AND a.attname = s.attname
+LEFT JOIN pg_stat_user_tables AS ut ON tbl.oid = ut.relid
+WHERE
+  n.nspname = sqlc.arg(schema_name)::text
+  AND am.amname = 'btree'
+  AND x.indnatts = 1
+  AND x.indpred IS NULL
+  AND x.indexprs IS NULL
+  AND NOT x.indisunique
+  AND NOT x.indisprimary
+  AND NOT s.inherited
+ORDER BY
+  index_entry_writes DESC;
+
+-- name: IndexDropSafety :many
+-- Catalog facts that make dropping an index unsafe: the constraints it
+-- enforces or that reference it, replica identity, the partitioned index it is
+-- a partition of, and foreign keys for which it is the only supporting index.
+-- Returns data for subchecks: unused-indexes, low-cardinality-indexes.
+SELECT
+  n.nspname::text AS schema_name
+  , ic.relname::text AS index_name
+  , coalesce((
+    SELECT array_agg(con.conname::text ORDER BY con.conname)
+    FROM pg_constraint AS con
+    WHERE con.conindid = x.indexrelid
+  ), '{}')::text [] AS constraints
+  , x.indisreplident AS is_replica_identity
+  , coalesce((
+    SELECT pn.nspname || '.' || pc.relname
+    FROM pg_inherits AS inh
+    INNER JOIN pg_class AS pc ON inh.inhparent = pc.oid
+    INNER JOIN pg_namespace AS pn ON pc.relnamespace = pn.oid
+    WHERE inh.inhrelid = x.indexrelid
+  ), '')::text AS partition_of
+  -- A foreign key is supported by an index whose leading columns are exactly
+  -- its referencing columns; indkey is zero-based.
+  , coalesce((
+    SELECT array_agg(fk.conname::text ORDER BY fk.conname)
+    FROM pg_constraint AS fk
+    WHERE
+      fk.contype = 'f'
+      AND fk.conrelid = x.indrelid
+      AND x.indpred IS NULL
+      AND (x.indkey::int2 [])[0:cardinality(fk.conkey) - 1] @> fk.conkey
+      AND (x.indkey::int2 [])[0:cardinality(fk.conkey) - 1] <@ fk.conkey
+      AND NOT EXISTS (
+        SELECT 1
+        FROM pg_index AS o
+        WHERE
+          o.indrelid = x.indrelid
+          AND o.indexrelid <> x.indexrelid
+          AND o.indisvalid
+          AND o.indpred IS NULL
+          AND (o.indkey::int2 [])[0:cardinality(fk.conkey) - 1] @> fk.conkey
+          AND (o.indkey::int2 [])[0:cardinality(fk.conkey) - 1] <@ fk.conkey
+      )
+  ), '{}')::text [] AS sole_foreign_key_index
+FROM pg_index AS x
+INNER JOIN pg_class AS ic ON x.indexrelid = ic.oid
+INNER JOIN pg_namespace AS n ON ic.relnamespace = n.oid
+WHERE
+  n.nspname = sqlc.arg(schema_name)::text;
@@ -1,14 +1,18 @@
-// Package invalidindexes implements a check for identifying PostgreSQL indexes in an invalid state.
+// Package invalidindexes implements a check for identifying PostgreSQL indexes
+// in an invalid state, including partitioned indexes with partitions that lack
+// a valid child index.
 package invalidindexes
 
 import (
 	"context"
 	_ "embed"
 	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/fresha/pgdoctor/check"
 	"github.com/fresha/pgdoctor/db"
+	"github.com/fresha/pgdoctor/internal/pgident"
 )
 
 //go:embed query.sql
@@ -19,20 +23,30 @@ var readme string
 
 type InvalidIndexesQueries interface {
 	BrokenIndexes(context.Context) ([]db.BrokenIndexesRow, error)
+	PartitionedIndexGaps(context.Context) ([]db.PartitionedIndexGapsRow, error)
 }
 
+// maxIdentifierLength is PostgreSQL's NAMEDATALEN - 1.
+const maxIdentifierLength = 63
+
 type checker struct {
 	queries InvalidIndexesQueries
 }
 
 func Metadata() check.Metadata {
 	return check.Metadata{
-		Category:    check.CategoryIndexes,
-		CheckID:     "invalid-indexes",
-		Name:        "Invalid Indexes",
-		Description: "Identifies indexes in invalid state that need rebuilding",
-		Readme:      readme,
-		SQL:         querySQL,
+		Category:       check.CategoryIndexes,
+		CheckID:        "invalid-indexes",
+		Name:           "Invalid Indexes",
+		Description:    "Identifies indexes in invalid state that need rebuilding",
+		Readme:         readme,
+		SQL:            querySQL,
+		RuntimeClass:   check.RuntimeFast,
+		ProductionSafe: true,
+		Findings: []check.FindingSpec{
+			{ID: "invalid-indexes", Description: "Indexes left invalid by a failed concurrent build", Thresholds: "FAIL"},
+			{ID: "partitioned-index-gaps", Description: "Partitions with no valid index attached to a partitioned index, so queries on those partitions cannot use it", Thresholds: "WARN, FAIL for unique indexes"},
+		},
 	}
 }
 
@@ -48,19 +62,38 @@ func (c *checker) Metadata() check.Metadata {
 
 func (c *checker) Check(ctx context.Context) (*check.Report, error) {
 	report := check.NewReport(Metadata())
+	ignore := check.ObjectFilterFromContext(ctx)
 
 	invalidIndexes, err := c.queries.BrokenIndexes(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("running %s/%s: %w", check.CategoryIndexes, report.CheckID, err)
 	}
+	invalidIndexes = slices.DeleteFunc(invalidIndexes, func(row db.BrokenIndexesRow) bool {
+		return ignore.Index(row.SchemaName, row.TableName, row.IndexName)
+	})
+
+	gaps, err := c.queries.PartitionedIndexGaps(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("running %s/%s: %w", check.CategoryIndexes, report.CheckID, err)
+	}
+	gaps = slices.DeleteFunc(gaps, func(row db.PartitionedIndexGapsRow) bool {
+		return ignore.Index(row.SchemaName, row.TableName, row.IndexName)
+	})
+
+	checkInvalidIndexes(invalidIndexes, report)
+	checkPartitionedIndexGaps(gaps, report)
+
+	return report, nil
+}
 
+func checkInvalidIndexes(invalidIndexes []db.BrokenIndexesRow, report *check.Report) {
 	if len(invalidIndexes) == 0 {
 		report.AddFinding(check.Finding{
 			ID:       report.CheckID,
 			Name:     report.Name,
 			Severity: check.SeverityOK,
 		})
-		return report, nil
+		return
 	}
 
 	lines := []string{}
@@ -74,6 +107,137 @@ func (c *checker) Check(ctx context.Context) (*check.Report, error) {
 		Severity: check.SeverityWarn,
 		Details:  fmt.Sprintf("There are %d invalid indexes.\n%s\n", len(invalidIndexes), strings.Join(lines, "\n")),
 	})
+}
 
-	return report, nil
+// checkPartitionedIndexGaps reports partitions that a partitioned index does
+// not cover. The planner cannot use the index on those partitions, so queries
+// that are fast on most partitions fall back to sequential scans on the rest,
+// and a unique index does not enforce uniqueness there.
+func checkPartitionedIndexGaps(gaps []db.PartitionedIndexGapsRow, report *check.Report) {
+	if len(gaps) == 0 {
+		report.AddFinding(check.Finding{
+			ID:       "partitioned-index-gaps",
+			Name:     "Partitioned Index Gaps",
+			Severity: check.SeverityOK,
+			Details:  "Every partition has a valid index attached to each partitioned index",
+		})
+		return
+	}
+
+	severity := check.SeverityWarn
+	indexes := map[string]struct{}{}
+	var rows []check.TableRow
+	var fixes []check.Fix
+	for _, gap := range gaps {
+		indexes[gap.SchemaName+"."+gap.IndexName] = struct{}{}
+
+		rowSeverity := check.SeverityWarn
+		if gap.IsUnique {
+			rowSeverity = check.SeverityFail
+			severity = check.SeverityFail
+		}
+
+		state := "missing"
+		if gap.ChildIndexName.Valid {
+			state = "invalid (" + gap.ChildIndexName.String + ")"
+		}
+
+		rows = append(rows, check.TableRow{
+			Cells:    []string{gap.SchemaName + "." + gap.IndexName, gap.PartitionSchema + "." + gap.PartitionName, state, uniqueLabel(gap.IsUnique)},
+			Severity: rowSeverity,
+		})
+		fixes = append(fixes, gapFixes(gap)...)
+	}
+
+	details := fmt.Sprintf("%d partition(s) lack a valid index attached to %d partitioned index(es). "+
+		"The partitioned index stays invalid, and queries that use it on other partitions fall back to sequential scans on these", len(gaps), len(indexes))
+	if severity == check.SeverityFail {
+		details += ". Unique indexes do not enforce uniqueness on the uncovered partitions"
+	}
+
+	report.AddFinding(check.Finding{
+		ID:       "partitioned-index-gaps",
+		Name:     "Partitioned Index Gaps",
+		Severity: severity,
+		Details:  details,
+		Table: &check.Table{
+			Headers: []string{"Partitioned Index", "Partition", "Child Index", "Unique"},
+			Rows:    rows,
+		},
+		Fixes: fixes,
+	})
+}
+
+func uniqueLabel(unique bool) string {
+	if unique {
+		return "yes"
+	}
+	return "no"
+}
+
+// gapFixes covers a partition: an invalid child index is rebuilt in place,
+// and a missing one is built concurrently on the partition and then attached
+// to the partitioned index, which becomes valid once every partition is
+// covered.
+func gapFixes(gap db.PartitionedIndexGapsRow) []check.Fix {
+	parent := pgident.Quote(gap.SchemaName, gap.IndexName)
+	object := gap.PartitionSchema + "." + gap.PartitionName
+
+	if gap.ChildIndexName.Valid {
+		return []check.Fix{{
+			Object: object,
+			Description: fmt.Sprintf("Rebuild the invalid index %s on %s. If %s is still invalid afterwards, "+
+				"check that %s is attached to it (ALTER INDEX ... ATTACH PARTITION)", gap.ChildIndexName.String, object, parent, gap.ChildIndexName.String),
+			SQL:  "REINDEX INDEX CONCURRENTLY " + pgident.Quote(gap.PartitionSchema, gap.ChildIndexName.String),
+			Risk: check.RiskLow,
+			Lock: check.LockOnline,
+		}}
+	}
+
+	// pg_get_indexdef renders "CREATE [UNIQUE] INDEX name ON ONLY table USING
+	// method (...)"; everything from USING on is reused for the partition.
+	_, method, ok := strings.Cut(gap.IndexDef, " USING ")
+	if !ok {
+		return nil
+	}
+
+	name := childIndexName(gap)
+	child := pgident.Quote(gap.PartitionSchema, name)
+	create := "CREATE INDEX CONCURRENTLY "
+	if gap.IsUnique {
+		create = "CREATE UNIQUE INDEX CONCURRENTLY "
+	}
+
+	return []check.Fix{
+		{
+			Object:      object,
+			Description: fmt.Sprintf("Build the missing index for %s on partition %s without blocking writes", parent, object),
+			SQL:         create + pgident.Quote(name) + " ON " + pgident.Quote(gap.PartitionSchema, gap.PartitionName) + " USING " + method,
+			Risk:        check.RiskLow,
+			Lock:        check.LockOnline,
+		},
+		{
+			Object: object,
+			Description: fmt.Sprintf("Attach the new index to %s once it is built, so the partitioned index covers %s. "+
+				"The attach itself is quick but takes a brief exclusive lock", parent, object),
+			SQL:  "ALTER INDEX " + parent + " ATTACH PARTITION " + child,
+			Risk: check.RiskLow,
+			Lock: check.LockExclusive,
+		},
+	}
+}
+
+// childIndexName names the index for a partition the way PostgreSQL would
+// when cascading the index: the partition name followed by the parent
+// index's suffix after its table name, truncated to the identifier limit.
+func childIndexName(gap db.PartitionedIndexGapsRow) string {
+	suffix := strings.TrimPrefix(gap.IndexName, gap.TableName)
+	if suffix == gap.IndexName {
+		suffix = "_" + gap.IndexName
+	}
+	name := gap.PartitionName + suffix
+	if len(name) > maxIdentifierLength {
+		name = name[:maxIdentifierLength]
+	}
+	return name
 }
@@ -3,17 +3,21 @@ package invalidindexes_test
 import (
 	"context"
 	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/fresha/pgdoctor/check"
+	"github.com/fresha/pgdoctor/check/checktest"
 	"github.com/fresha/pgdoctor/checks/invalidindexes"
 	"github.com/fresha/pgdoctor/db"
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/stretchr/testify/require"
 )
 
 // Mock queryer for testing.
 type mockInvalidIndexesQueryer struct {
 	indexes []db.BrokenIndexesRow
+	gaps    []db.PartitionedIndexGapsRow
 	err     error
 }
 
@@ -24,6 +28,13 @@ func (m *mockInvalidIndexesQueryer) BrokenIndexes(context.Context) ([]db.BrokenI
 	return m.indexes, nil
 }
 
+func (m *mockInvalidIndexesQueryer) PartitionedIndexGaps(context.Context) ([]db.PartitionedIndexGapsRow, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	return m.gaps, nil
+}
+
 func newMockQueryer(indexes []db.BrokenIndexesRow) *mockInvalidIndexesQueryer {
 	return &mockInvalidIndexesQueryer{indexes: indexes}
 }
@@ -80,7 +91,7 @@ func Test_InvalidIndexes(t *testing.T) {
 			require.NoError(t, err)
 
 			results := report.Results
-			require.Equal(t, 1, len(results), "Should have exactly 1 result")
+			require.Equal(t, 2, len(results), "Should have one result per subcheck")
 
 			result := results[0]
 			require.Equal(t, tc.ExpectedID, result.ID, "Result ID should match")
@@ -105,7 +116,7 @@ func Test_InvalidIndexes_DetailsContent(t *testing.T) {
 	require.NoError(t, err)
 
 	results := report.Results
-	require.Equal(t, 1, len(results), "Should have exactly 1 result")
+	require.Equal(t, 2, len(results), "Should have one result per subcheck")
 
 	result := results[0]
 	require.Equal(t, check.SeverityWarn, result.Severity)
@@ -134,7 +145,7 @@ func Test_InvalidIndexes_PrescriptionContent(t *testing.T) {
 	require.NoError(t, err)
 
 	results := report.Results
-	require.Equal(t, 1, len(results), "Should have exactly 1 result")
+	require.Equal(t, 2, len(results), "Should have one result per subcheck")
 
 	result := results[0]
 	require.NotEmpty(t, result.Details, "Details should not be empty")
@@ -151,7 +162,7 @@ func Test_InvalidIndexes_OKResult(t *testing.T) {
 	require.NoError(t, err)
 
 	results := report.Results
-	require.Equal(t, 1, len(results), "Should have exactly 1 result")
+	require.Equal(t, 2, len(results), "Should have one result per subcheck")
 
 	result := results[0]
 	require.Equal(t, check.SeverityOK, result.Severity, "Should be OK when no invalid indexes")
@@ -192,7 +203,7 @@ func Test_InvalidIndexes_CategoryFiltering(t *testing.T) {
 
 	// Should still run and add result when not filtered
 	results := report.Results
-	require.Equal(t, 1, len(results), "Should have result when not filtered")
+	require.Equal(t, 2, len(results), "Should have results when not filtered")
 }
 
 func Test_InvalidIndexes_Metadata(t *testing.T) {
@@ -222,7 +233,7 @@ func Test_InvalidIndexes_ResultStructure(t *testing.T) {
 	require.NoError(t, err)
 
 	results := report.Results
-	require.Equal(t, 1, len(results))
+	require.Equal(t, 2, len(results))
 
 	result := results[0]
 	require.Equal(t, "Invalid Indexes", result.Name, "Name should match")
@@ -280,10 +291,109 @@ func Test_InvalidIndexes_CountAccuracy(t *testing.T) {
 			require.NoError(t, err)
 
 			results := report.Results
-			require.Equal(t, 1, len(results))
+			require.Equal(t, 2, len(results))
 
 			result := results[0]
 			require.Contains(t, result.Details, tc.ExpectedCount, "Details should contain accurate count")
 		})
 	}
 }
+
+func Test_PartitionedIndexGaps_OK(t *testing.T) {
+	t.Parallel()
+
+	report, err := invalidindexes.New(newMockQueryer(nil)).Check(context.Background())
+	require.NoError(t, err)
+
+	f := checktest.Finding(t, report, "partitioned-index-gaps")
+	require.Equal(t, check.SeverityOK, f.Severity)
+	require.Empty(t, f.Fixes)
+}
+
+func Test_PartitionedIndexGaps_MissingChild(t *testing.T) {
+	t.Parallel()
+
+	queryer := &mockInvalidIndexesQueryer{gaps: []db.PartitionedIndexGapsRow{{
+		SchemaName:      "public",
+		TableName:       "orders",
+		IndexName:       "orders_created_at_idx",
+		PartitionSchema: "public",
+		PartitionName:   "orders_2026_01",
+		IndexDef:        "CREATE INDEX orders_created_at_idx ON ONLY public.orders USING btree (created_at) WHERE (deleted_at IS NULL)",
+	}}}
+
+	report, err := invalidindexes.New(queryer).Check(context.Background())
+	require.NoError(t, err)
+
+	f := checktest.Finding(t, report, "partitioned-index-gaps")
+	require.Equal(t, check.SeverityWarn, f.Severity)
+	require.Contains(t, f.Details, "1 partition(s) lack a valid index attached to 1 partitioned index(es)")
+	require.NotContains(t, f.Details, "uniqueness")
+	require.Equal(t, []string{"public.orders_created_at_idx", "public.orders_2026_01", "missing", "no"}, f.Table.Rows[0].Cells)
+
+	require.Len(t, f.Fixes, 2)
+	require.Equal(t, `CREATE INDEX CONCURRENTLY "orders_2026_01_created_at_idx" ON "public"."orders_2026_01" USING btree (created_at) WHERE (deleted_at IS NULL)`, f.Fixes[0].SQL)
+	require.Equal(t, check.LockOnline, f.Fixes[0].Lock)
+	require.Equal(t, `ALTER INDEX "public"."orders_created_at_idx" ATTACH PARTITION "public"."orders_2026_01_created_at_idx"`, f.Fixes[1].SQL)
+	require.Equal(t, check.LockExclusive, f.Fixes[1].Lock)
+}
+
+func Test_PartitionedIndexGaps_InvalidUniqueChild(t *testing.T) {
+	t.Parallel()
+
+	queryer := &mockInvalidIndexesQueryer{gaps: []db.PartitionedIndexGapsRow{
+		{
+			SchemaName:      "billing",
+			TableName:       "invoices",
+			IndexName:       "invoices_number_key",
+			PartitionSchema: "billing",
+			PartitionName:   "invoices_2026",
+			ChildIndexName:  pgtype.Text{String: "invoices_2026_number_key", Valid: true},
+			IsUnique:        true,
+			IndexDef:        "CREATE UNIQUE INDEX invoices_number_key ON ONLY billing.invoices USING btree (number, issued_on)",
+		},
+		{
+			SchemaName:      "billing",
+			TableName:       "invoices",
+			IndexName:       "invoices_number_key",
+			PartitionSchema: "billing",
+			PartitionName:   "invoices_2027",
+			IsUnique:        true,
+			IndexDef:        "CREATE UNIQUE INDEX invoices_number_key ON ONLY billing.invoices USING btree (number, issued_on)",
+		},
+	}}
+
+	report, err := invalidindexes.New(queryer).Check(context.Background())
+	require.NoError(t, err)
+
+	f := checktest.Finding(t, report, "partitioned-index-gaps")
+	require.Equal(t, check.SeverityFail, f.Severity)
+	require.Contains(t, f.Details, "2 partition(s) lack a valid index attached to 1 partitioned index(es)")
+	require.Contains(t, f.Details, "do not enforce uniqueness")
+	require.Equal(t, "invalid (invoices_2026_number_key)", f.Table.Rows[0].Cells[2])
+
+	require.Len(t, f.Fixes, 3)
+	require.Equal(t, `REINDEX INDEX CONCURRENTLY "billing"."invoices_2026_number_key"`, f.Fixes[0].SQL)
+	require.Equal(t, check.RiskLow, f.Fixes[0].Risk)
+	require.Equal(t, `CREATE UNIQUE INDEX CONCURRENTLY "invoices_2027_number_key" ON "billing"."invoices_2027" USING btree (number, issued_on)`, f.Fixes[1].SQL)
+}
+
+func Test_PartitionedIndexGaps_LongChildName(t *testing.T) {
+	t.Parallel()
+
+	partition := strings.Repeat("p", 60)
+	queryer := &mockInvalidIndexesQueryer{gaps: []db.PartitionedIndexGapsRow{{
+		SchemaName:      "public",
+		TableName:       "events",
+		IndexName:       "events_tenant_id_idx",
+		PartitionSchema: "public",
+		PartitionName:   partition,
+		IndexDef:        "CREATE INDEX events_tenant_id_idx ON ONLY public.events USING btree (tenant_id)",
+	}}}
+
+	report, err := invalidindexes.New(queryer).Check(context.Background())
+	require.NoError(t, err)
+
+	f := checktest.Finding(t, report, "partitioned-index-gaps")
+	require.Contains(t, f.Fixes[0].SQL, `"`+partition+`_te" ON`)
+}
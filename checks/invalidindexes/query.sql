@@ -2,7 +2,51 @@
 SELECT
   tblclass.relname AS table_name
   , idxclass.relname AS index_name
+  , idxns.nspname::text AS schema_name
 FROM pg_index
 INNER JOIN pg_class AS idxclass ON pg_index.indexrelid = idxclass.oid
 INNER JOIN pg_class AS tblclass ON pg_index.indrelid = tblclass.oid
+INNER JOIN pg_namespace AS idxns ON idxclass.relnamespace = idxns.oid
 WHERE NOT pg_index.indisvalid;
+
+-- name: PartitionedIndexGaps :many
+-- Lists partitions of partitioned tables that lack a valid index attached to
+-- each partitioned index: either no child index is attached (missing) or the
+-- attached one is invalid, e.g. after a failed CREATE INDEX CONCURRENTLY.
+-- Only direct partitions are listed; sub-partitioned children carry their own
+-- partitioned index and are checked at their level. Foreign-table partitions
+-- cannot hold indexes and are skipped.
+SELECT
+  pn.nspname::text AS schema_name
+  , pt.relname::text AS table_name
+  , pic.relname::text AS index_name
+  , cn.nspname::text AS partition_schema
+  , ct.relname::text AS partition_name
+  , child.index_name AS child_index_name
+  , COALESCE(child.is_valid, false) AS child_valid
+  , pi.indisunique AS is_unique
+  , pg_catalog.pg_get_indexdef(pi.indexrelid)::text AS index_def
+FROM pg_catalog.pg_index AS pi
+INNER JOIN pg_catalog.pg_class AS pic ON pi.indexrelid = pic.oid
+INNER JOIN pg_catalog.pg_class AS pt ON pi.indrelid = pt.oid
+INNER JOIN pg_catalog.pg_namespace AS pn ON pt.relnamespace = pn.oid
+INNER JOIN pg_catalog.pg_inherits AS tinh ON pt.oid = tinh.inhparent
+INNER JOIN pg_catalog.pg_class AS ct ON tinh.inhrelid = ct.oid
+INNER JOIN pg_catalog.pg_namespace AS cn ON ct.relnamespace = cn.oid
+LEFT JOIN LATERAL (
+  SELECT
+    ci.relname::text AS index_name
+    , cx.indisvalid AS is_valid
+  FROM pg_catalog.pg_inherits AS iinh
+  INNER JOIN pg_catalog.pg_index AS cx ON iinh.inhrelid = cx.indexrelid
+  INNER JOIN pg_catalog.pg_class AS ci ON cx.indexrelid = ci.oid
+  WHERE
+    iinh.inhparent = pi.indexrelid
+    AND cx.indrelid = ct.oid
+) AS child ON true
+WHERE
+  pic.relkind = 'I'
+  AND ct.relkind IN ('r', 'p')
+  AND pn.nspname NOT IN ('pg_catalog', 'information_schema')
+  AND (child.index_name IS NULL OR NOT child.is_valid)
+ORDER BY pn.nspname, pt.relname, pic.relname, cn.nspname, ct.relname;
@@ -0,0 +1,402 @@
+// Package lockcontention implements checks for sessions blocked on
+// heavyweight locks, the sessions blocking them, and long-held
+// AccessExclusive locks.
+package lockcontention
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/fresha/pgdoctor/check"
+	"github.com/fresha/pgdoctor/db"
+)
+
+//go:embed query.sql
+var querySQL string
+
+//go:embed README.md
+var readme string
+
+const (
+	// How long a session has been waiting for a lock.
+	waitWarnSeconds = int64(30)
+	waitFailSeconds = int64(5 * 60)
+
+	// Sessions queued, directly or through a chain, behind one root blocker.
+	pileupWarnSessions = 5
+	pileupFailSessions = 20
+
+	// How long the transaction holding an AccessExclusiveLock has been open.
+	exclusiveWarnSeconds = int64(10)
+	exclusiveFailSeconds = int64(60)
+
+	// pg_blocking_pids reports 0 for a prepared transaction.
+	preparedTransactionPid = int32(0)
+)
+
+type LockContentionQueries interface {
+	LockWaiters(context.Context) ([]db.LockWaitersRow, error)
+	LockHolders(context.Context) ([]db.LockHoldersRow, error)
+}
+
+type checker struct {
+	queries LockContentionQueries
+}
+
+func Metadata() check.Metadata {
+	return check.Metadata{
+		Category:       check.CategoryPerformance,
+		CheckID:        "lock-contention",
+		Name:           "Lock Contention",
+		Description:    "Reports sessions blocked on locks, the sessions at the head of blocking chains, and long-held AccessExclusive locks",
+		Readme:         readme,
+		SQL:            querySQL,
+		RuntimeClass:   check.RuntimeFast,
+		ProductionSafe: true,
+		Privileges:     []check.Privilege{check.PrivilegeReadAllStats},
+		Findings: []check.FindingSpec{
+			{ID: "blocked-sessions", Description: "Sessions waiting for a lock held by another session", Thresholds: "WARN >= 30s, FAIL >= 5m waiting"},
+			{ID: "lock-blockers", Description: "Sessions at the head of a blocking chain and how many sessions are queued behind them", Thresholds: "WARN >= 5 queued or idle in transaction, FAIL >= 20 queued"},
+			{ID: "access-exclusive-locks", Description: "Sessions holding an AccessExclusiveLock on a table or index, which blocks even reads", Thresholds: "WARN >= 10s, FAIL >= 1m in the holding transaction"},
+		},
+	}
+}
+
+func New(queries LockContentionQueries, _ ...check.Config) check.Checker {
+	return &checker{
+		queries: queries,
+	}
+}
+
+func (c *checker) Metadata() check.Metadata {
+	return Metadata()
+}
+
+func (c *checker) Check(ctx context.Context) (*check.Report, error) {
+	report := check.NewReport(Metadata())
+
+	waiters, err := c.queries.LockWaiters(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("running %s/%s (waiters): %w", report.Category, report.CheckID, err)
+	}
+
+	holders, err := c.queries.LockHolders(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("running %s/%s (holders): %w", report.Category, report.CheckID, err)
+	}
+
+	checkBlockedSessions(waiters, report)
+	checkBlockers(waiters, holders, report)
+	checkAccessExclusive(holders, report)
+
+	return report, nil
+}
+
+// blockingPids parses pg_blocking_pids output, skipping anything malformed.
+func blockingPids(w db.LockWaitersRow) []int32 {
+	pids := make([]int32, 0, len(w.BlockingPids))
+	for _, s := range w.BlockingPids {
+		if pid, err := strconv.ParseInt(s, 10, 32); err == nil {
+			pids = append(pids, int32(pid))
+		}
+	}
+	return pids
+}
+
+func formatPid(pid int32) string {
+	if pid == preparedTransactionPid {
+		return "prepared xact"
+	}
+	return strconv.Itoa(int(pid))
+}
+
+func lockDescription(w db.LockWaitersRow) string {
+	target := w.RelationName
+	if target == "" {
+		target = w.LockType
+	}
+	if w.LockMode == "" {
+		return target
+	}
+	return w.LockMode + " on " + target
+}
+
+func checkBlockedSessions(waiters []db.LockWaitersRow, report *check.Report) {
+	if len(waiters) == 0 {
+		report.AddFinding(check.Finding{
+			ID:       "blocked-sessions",
+			Name:     "Blocked Sessions",
+			Severity: check.SeverityOK,
+			Details:  "No session is waiting for a lock held by another session",
+		})
+		return
+	}
+
+	severity := check.SeverityOK
+	var longest int64
+	var tableRows []check.TableRow
+	for _, w := range waiters {
+		rowSeverity := check.SeverityOK
+		switch {
+		case w.WaitSeconds >= waitFailSeconds:
+			rowSeverity = check.SeverityFail
+		case w.WaitSeconds >= waitWarnSeconds:
+			rowSeverity = check.SeverityWarn
+		}
+		severity = max(severity, rowSeverity)
+		longest = max(longest, w.WaitSeconds)
+
+		blockers := make([]string, 0, len(w.BlockingPids))
+		for _, pid := range blockingPids(w) {
+			blockers = append(blockers, formatPid(pid))
+		}
+
+		tableRows = append(tableRows, check.TableRow{
+			Cells: []string{
+				strconv.Itoa(int(w.Pid)),
+				w.Username,
+				w.ApplicationName,
+				lockDescription(w),
+				strings.Join(blockers, ", "),
+				check.FormatDurationSec(w.WaitSeconds),
+				w.Query,
+			},
+			Severity: rowSeverity,
+		})
+	}
+
+	details := fmt.Sprintf("%d session(s) waiting for locks, the longest for %s", len(waiters), check.FormatDurationSec(longest))
+	if severity > check.SeverityOK {
+		details += ". Waiting sessions hold their connections and any locks they already took, so a long wait spreads to every query queued behind them. " +
+			"See lock-blockers for the sessions to resolve"
+	}
+
+	report.AddFinding(check.Finding{
+		ID:       "blocked-sessions",
+		Name:     "Blocked Sessions",
+		Severity: severity,
+		Details:  details,
+		Table: &check.Table{
+			Headers: []string{"PID", "User", "Application", "Waiting For", "Blocked By", "Waiting", "Query"},
+			Rows:    tableRows,
+		},
+	})
+}
+
+// blockingChain is a session that blocks others without waiting itself, and
+// the sessions queued behind it directly or through other waiters.
+type blockingChain struct {
+	root   int32
+	queued int
+	depth  int
+}
+
+// blockingChains walks the waits-for graph from every root blocker. A waiter
+// blocked by several sessions is counted under each root it waits on.
+func blockingChains(waiters []db.LockWaitersRow) []blockingChain {
+	blockedBy := map[int32][]int32{} // blocker -> sessions waiting on it
+	waiting := map[int32]bool{}
+	for _, w := range waiters {
+		waiting[w.Pid] = true
+		for _, pid := range blockingPids(w) {
+			blockedBy[pid] = append(blockedBy[pid], w.Pid)
+		}
+	}
+
+	depths := map[int32]int{}
+	var chains []blockingChain
+	for root := range blockedBy {
+		if waiting[root] {
+			continue
+		}
+
+		seen := map[int32]bool{root: true}
+		frontier := []int32{root}
+		for len(frontier) > 0 {
+			var next []int32
+			for _, pid := range frontier {
+				for _, w := range blockedBy[pid] {
+					if !seen[w] {
+						seen[w] = true
+						next = append(next, w)
+					}
+				}
+			}
+			frontier = next
+		}
+		chains = append(chains, blockingChain{
+			root:   root,
+			queued: len(seen) - 1,
+			depth:  chainDepth(root, blockedBy, map[int32]bool{}, depths),
+		})
+	}
+
+	sort.Slice(chains, func(i, j int) bool {
+		if chains[i].queued != chains[j].queued {
+			return chains[i].queued > chains[j].queued
+		}
+		return chains[i].root < chains[j].root
+	})
+	return chains
+}
+
+// chainDepth is the longest path from pid to a waiter. Lock waits cannot
+// form a lasting cycle (the deadlock detector breaks them), but a snapshot
+// taken mid-detection might, so sessions already on the path are skipped.
+// Depths are memoized so shared sub-chains are walked once.
+func chainDepth(pid int32, blockedBy map[int32][]int32, onPath map[int32]bool, depths map[int32]int) int {
+	if d, ok := depths[pid]; ok {
+		return d
+	}
+	onPath[pid] = true
+	defer delete(onPath, pid)
+
+	depth := 0
+	for _, w := range blockedBy[pid] {
+		if !onPath[w] {
+			depth = max(depth, 1+chainDepth(w, blockedBy, onPath, depths))
+		}
+	}
+	depths[pid] = depth
+	return depth
+}
+
+func checkBlockers(waiters []db.LockWaitersRow, holders []db.LockHoldersRow, report *check.Report) {
+	chains := blockingChains(waiters)
+	if len(chains) == 0 {
+		report.AddFinding(check.Finding{
+			ID:       "lock-blockers",
+			Name:     "Lock Blockers",
+			Severity: check.SeverityOK,
+			Details:  "No session is blocking another",
+		})
+		return
+	}
+
+	byPid := make(map[int32]db.LockHoldersRow, len(holders))
+	for _, h := range holders {
+		byPid[h.Pid] = h
+	}
+
+	severity := check.SeverityOK
+	idleInTransaction := 0
+	var tableRows []check.TableRow
+	for _, chain := range chains {
+		h, known := byPid[chain.root]
+
+		rowSeverity := check.SeverityOK
+		switch {
+		case chain.queued >= pileupFailSessions:
+			rowSeverity = check.SeverityFail
+		case chain.queued >= pileupWarnSessions:
+			rowSeverity = check.SeverityWarn
+		}
+		if known && strings.HasPrefix(h.State, "idle in transaction") {
+			idleInTransaction++
+			rowSeverity = max(rowSeverity, check.SeverityWarn)
+		}
+		severity = max(severity, rowSeverity)
+
+		cells := []string{formatPid(chain.root), "", "", "", "", "", strconv.Itoa(chain.queued), strconv.Itoa(chain.depth)}
+		if chain.root == preparedTransactionPid {
+			cells[3] = "prepared"
+		}
+		if known {
+			cells[1], cells[2], cells[3] = h.Username, h.ApplicationName, h.State
+			cells[4] = check.FormatDurationSec(h.XactAgeSeconds)
+			cells[5] = h.Query
+		}
+		tableRows = append(tableRows, check.TableRow{Cells: cells, Severity: rowSeverity})
+	}
+
+	details := fmt.Sprintf("%d session(s) at the head of blocking chains, with up to %d session(s) queued behind one",
+		len(chains), chains[0].queued)
+	if idleInTransaction > 0 {
+		details += fmt.Sprintf(". %d blocker(s) are idle in transaction: they hold their locks until the application commits or rolls back, "+
+			"which may never happen if it is stuck or leaked the connection", idleInTransaction)
+	}
+	if severity > check.SeverityOK {
+		details += ". Resolve the blocker rather than the waiters; if it cannot finish, cancel its query with pg_cancel_backend(pid) " +
+			"or end the session with pg_terminate_backend(pid)"
+	}
+
+	report.AddFinding(check.Finding{
+		ID:       "lock-blockers",
+		Name:     "Lock Blockers",
+		Severity: severity,
+		Details:  details,
+		Table: &check.Table{
+			Headers: []string{"PID", "User", "Application", "State", "Transaction Age", "Query", "Queued", "Chain Depth"},
+			Rows:    tableRows,
+		},
+	})
+}
+
+func checkAccessExclusive(holders []db.LockHoldersRow, report *check.Report) {
+	severity := check.SeverityOK
+	var tableRows []check.TableRow
+	for _, h := range holders {
+		if h.AccessExclusiveRelations == "" {
+			continue
+		}
+
+		rowSeverity := check.SeverityOK
+		switch {
+		case h.XactAgeSeconds >= exclusiveFailSeconds:
+			rowSeverity = check.SeverityFail
+		case h.XactAgeSeconds >= exclusiveWarnSeconds:
+			rowSeverity = check.SeverityWarn
+		}
+		severity = max(severity, rowSeverity)
+
+		blocking := "no"
+		if h.IsBlocking {
+			blocking = "yes"
+		}
+		tableRows = append(tableRows, check.TableRow{
+			Cells: []string{
+				strconv.Itoa(int(h.Pid)),
+				h.Username,
+				h.ApplicationName,
+				h.State,
+				h.AccessExclusiveRelations,
+				check.FormatDurationSec(h.XactAgeSeconds),
+				blocking,
+			},
+			Severity: rowSeverity,
+		})
+	}
+
+	if len(tableRows) == 0 {
+		report.AddFinding(check.Finding{
+			ID:       "access-exclusive-locks",
+			Name:     "AccessExclusive Locks",
+			Severity: check.SeverityOK,
+			Details:  "No session holds an AccessExclusiveLock on a relation",
+		})
+		return
+	}
+
+	details := fmt.Sprintf("%d session(s) hold AccessExclusiveLock on relations", len(tableRows))
+	if severity > check.SeverityOK {
+		details += fmt.Sprintf(", in transactions open for %s or more. AccessExclusiveLock, taken by most ALTER TABLE forms, DROP, TRUNCATE, "+
+			"REINDEX and VACUUM FULL, blocks every query on the relation including SELECT, and the lock is held until the transaction ends. "+
+			"Run such DDL in short transactions with lock_timeout set, so it gives up instead of queueing everyone behind it",
+			check.FormatDurationSec(exclusiveWarnSeconds))
+	}
+
+	report.AddFinding(check.Finding{
+		ID:       "access-exclusive-locks",
+		Name:     "AccessExclusive Locks",
+		Severity: severity,
+		Details:  details,
+		Table: &check.Table{
+			Headers: []string{"PID", "User", "Application", "State", "Relations", "Transaction Age", "Blocking"},
+			Rows:    tableRows,
+		},
+	})
+}
@@ -0,0 +1,210 @@
+package lockcontention_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/fresha/pgdoctor/check"
+	"github.com/fresha/pgdoctor/check/checktest"
+	"github.com/fresha/pgdoctor/checks/lockcontention"
+	"github.com/fresha/pgdoctor/db"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockQueryer struct {
+	waiters    []db.LockWaitersRow
+	holders    []db.LockHoldersRow
+	waitersErr error
+	holdersErr error
+}
+
+func (m *mockQueryer) LockWaiters(context.Context) ([]db.LockWaitersRow, error) {
+	return m.waiters, m.waitersErr
+}
+
+func (m *mockQueryer) LockHolders(context.Context) ([]db.LockHoldersRow, error) {
+	return m.holders, m.holdersErr
+}
+
+func waiter(pid int32, waitSeconds int64, blockers ...string) db.LockWaitersRow {
+	return db.LockWaitersRow{
+		Pid:          pid,
+		Username:     "app",
+		BlockingPids: blockers,
+		LockType:     "relation",
+		LockMode:     "AccessShareLock",
+		RelationName: "public.orders",
+		WaitSeconds:  waitSeconds,
+		Query:        "SELECT * FROM orders",
+	}
+}
+
+func TestLockContention_NoContention(t *testing.T) {
+	t.Parallel()
+
+	report, err := lockcontention.New(&mockQueryer{}).Check(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, check.SeverityOK, report.Severity)
+	assert.Len(t, report.Results, 3)
+	for _, f := range report.Results {
+		assert.Equal(t, check.SeverityOK, f.Severity, f.ID)
+	}
+}
+
+func TestLockContention_BlockedSessions(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		wait     int64
+		expected check.Severity
+	}{
+		{name: "brief wait", wait: 2, expected: check.SeverityOK},
+		{name: "30 seconds", wait: 30, expected: check.SeverityWarn},
+		{name: "5 minutes", wait: 300, expected: check.SeverityFail},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			q := &mockQueryer{waiters: []db.LockWaitersRow{waiter(200, tt.wait, "100")}}
+			report, err := lockcontention.New(q).Check(context.Background())
+			require.NoError(t, err)
+
+			f := checktest.Finding(t, report, "blocked-sessions")
+			assert.Equal(t, tt.expected, f.Severity)
+			require.NotNil(t, f.Table)
+			require.Len(t, f.Table.Rows, 1)
+			assert.Equal(t, "AccessShareLock on public.orders", f.Table.Rows[0].Cells[3])
+			assert.Equal(t, "100", f.Table.Rows[0].Cells[4])
+		})
+	}
+}
+
+func TestLockContention_BlockingChains(t *testing.T) {
+	t.Parallel()
+
+	// 100 blocks 200 and 201; 200 blocks 300 (waiting on both 200 and 100,
+	// as a queued lock request does). 500 blocks 600 independently.
+	q := &mockQueryer{
+		waiters: []db.LockWaitersRow{
+			waiter(200, 5, "100"),
+			waiter(201, 5, "100"),
+			waiter(300, 5, "200", "100"),
+			waiter(600, 5, "500"),
+		},
+		holders: []db.LockHoldersRow{
+			{Pid: 100, Username: "migrator", State: "active", IsBlocking: true, XactAgeSeconds: 12, Query: "ALTER TABLE orders ADD COLUMN x int"},
+			{Pid: 200, Username: "app", State: "active", IsBlocking: true},
+			{Pid: 500, Username: "app", State: "active", IsBlocking: true},
+		},
+	}
+	report, err := lockcontention.New(q).Check(context.Background())
+	require.NoError(t, err)
+
+	f := checktest.Finding(t, report, "lock-blockers")
+	assert.Equal(t, check.SeverityOK, f.Severity)
+	require.NotNil(t, f.Table)
+	require.Len(t, f.Table.Rows, 2, "only root blockers are listed")
+
+	root := f.Table.Rows[0].Cells
+	assert.Equal(t, "100", root[0])
+	assert.Equal(t, "migrator", root[1])
+	assert.Equal(t, "3", root[6], "queued counts the whole chain once")
+	assert.Equal(t, "2", root[7], "chain depth")
+
+	assert.Equal(t, "500", f.Table.Rows[1].Cells[0])
+	assert.Equal(t, "1", f.Table.Rows[1].Cells[6])
+}
+
+func TestLockContention_BlockerSeverity(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		queued   int
+		state    string
+		expected check.Severity
+	}{
+		{name: "one waiter", queued: 1, state: "active", expected: check.SeverityOK},
+		{name: "idle in transaction", queued: 1, state: "idle in transaction", expected: check.SeverityWarn},
+		{name: "pile-up", queued: 5, state: "active", expected: check.SeverityWarn},
+		{name: "large pile-up", queued: 20, state: "active", expected: check.SeverityFail},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			q := &mockQueryer{holders: []db.LockHoldersRow{{Pid: 100, State: tt.state, IsBlocking: true}}}
+			for i := range tt.queued {
+				q.waiters = append(q.waiters, waiter(int32(200+i), 1, "100"))
+			}
+			report, err := lockcontention.New(q).Check(context.Background())
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.expected, checktest.Finding(t, report, "lock-blockers").Severity)
+		})
+	}
+}
+
+func TestLockContention_PreparedTransactionBlocker(t *testing.T) {
+	t.Parallel()
+
+	q := &mockQueryer{waiters: []db.LockWaitersRow{waiter(200, 1, "0")}}
+	report, err := lockcontention.New(q).Check(context.Background())
+	require.NoError(t, err)
+
+	f := checktest.Finding(t, report, "lock-blockers")
+	require.NotNil(t, f.Table)
+	require.Len(t, f.Table.Rows, 1)
+	assert.Equal(t, "prepared xact", f.Table.Rows[0].Cells[0])
+	assert.Equal(t, "prepared", f.Table.Rows[0].Cells[3])
+}
+
+func TestLockContention_AccessExclusive(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		xactAge  int64
+		expected check.Severity
+	}{
+		{name: "brief", xactAge: 1, expected: check.SeverityOK},
+		{name: "10 seconds", xactAge: 10, expected: check.SeverityWarn},
+		{name: "1 minute", xactAge: 60, expected: check.SeverityFail},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			q := &mockQueryer{holders: []db.LockHoldersRow{
+				{Pid: 100, State: "idle in transaction", AccessExclusiveRelations: "public.orders", XactAgeSeconds: tt.xactAge},
+				{Pid: 101, State: "active", IsBlocking: true, XactAgeSeconds: 3600},
+			}}
+			report, err := lockcontention.New(q).Check(context.Background())
+			require.NoError(t, err)
+
+			f := checktest.Finding(t, report, "access-exclusive-locks")
+			assert.Equal(t, tt.expected, f.Severity)
+			require.NotNil(t, f.Table)
+			require.Len(t, f.Table.Rows, 1, "sessions without AccessExclusiveLock are not listed")
+			assert.Equal(t, "public.orders", f.Table.Rows[0].Cells[4])
+		})
+	}
+}
+
+func TestLockContention_QueryErrors(t *testing.T) {
+	t.Parallel()
+
+	_, err := lockcontention.New(&mockQueryer{waitersErr: errors.New("boom")}).Check(context.Background())
+	require.ErrorContains(t, err, "lock-contention")
+
+	_, err = lockcontention.New(&mockQueryer{holdersErr: errors.New("boom")}).Check(context.Background())
+	require.ErrorContains(t, err, "lock-contention")
+}
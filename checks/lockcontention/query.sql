@@ -0,0 +1,76 @@
+-- name: LockWaiters :many
+-- Sessions waiting for a heavyweight lock that another session holds, with
+-- the sessions blocking them and the lock they are waiting for. Wait start
+-- comes from pg_locks.waitstart on PostgreSQL 14+ (read through to_jsonb so
+-- the query also runs on older versions) and falls back to query start.
+WITH waiting AS (
+  SELECT
+    a.pid
+    , PG_BLOCKING_PIDS(a.pid) AS blockers
+  FROM pg_stat_activity AS a
+  WHERE a.wait_event_type = 'Lock'
+)
+
+SELECT
+  a.pid::integer AS pid
+  , COALESCE(a.usename, '')::text AS username
+  , COALESCE(a.application_name, '')::text AS application_name
+  , w.blockers::text [] AS blocking_pids
+  , COALESCE(l.locktype, '')::text AS lock_type
+  , COALESCE(l.mode, '')::text AS lock_mode
+  , COALESCE(l.relation::regclass::text, '')::text AS relation_name
+  , COALESCE(EXTRACT(EPOCH FROM NOW() - COALESCE((TO_JSONB(l) ->> 'waitstart')::timestamptz, a.query_start)), 0)::bigint AS wait_seconds
+  , LEFT(COALESCE(a.query, ''), 200)::text AS query
+FROM waiting AS w
+INNER JOIN pg_stat_activity AS a ON w.pid = a.pid
+LEFT JOIN LATERAL (
+  SELECT *
+  FROM pg_locks AS pl
+  WHERE pl.pid = a.pid AND NOT pl.granted
+  LIMIT 1
+) AS l ON true
+WHERE CARDINALITY(w.blockers) > 0
+ORDER BY wait_seconds DESC
+LIMIT 200;
+
+-- name: LockHolders :many
+-- Sessions that block at least one other session, and sessions holding an
+-- AccessExclusiveLock on a relation, which blocks even plain reads. Lock
+-- acquisition time is not recorded, so transaction age is the best bound on
+-- how long the locks have been held.
+WITH blockers AS (
+  SELECT DISTINCT UNNEST(PG_BLOCKING_PIDS(a.pid)) AS pid
+  FROM pg_stat_activity AS a
+  WHERE a.wait_event_type = 'Lock'
+)
+
+, exclusive AS (
+  SELECT
+    l.pid
+    , STRING_AGG(DISTINCT l.relation::regclass::text, ', ') AS relations
+  FROM pg_locks AS l
+  WHERE
+    l.locktype = 'relation'
+    AND l.mode = 'AccessExclusiveLock'
+    AND l.granted
+    AND l.pid IS NOT NULL
+    AND l.pid <> PG_BACKEND_PID()
+  GROUP BY l.pid
+)
+
+SELECT
+  a.pid::integer AS pid
+  , COALESCE(a.usename, '')::text AS username
+  , COALESCE(a.application_name, '')::text AS application_name
+  , COALESCE(a.state, '')::text AS state
+  , (b.pid IS NOT NULL) AS is_blocking
+  , COALESCE(e.relations, '')::text AS access_exclusive_relations
+  , COALESCE(EXTRACT(EPOCH FROM NOW() - a.xact_start), 0)::bigint AS xact_age_seconds
+  , COALESCE(EXTRACT(EPOCH FROM NOW() - a.state_change), 0)::bigint AS state_age_seconds
+  , LEFT(COALESCE(a.query, ''), 200)::text AS query
+FROM pg_stat_activity AS a
+LEFT JOIN blockers AS b ON a.pid = b.pid
+LEFT JOIN exclusive AS e ON a.pid = e.pid
+WHERE b.pid IS NOT NULL OR e.pid IS NOT NULL
+ORDER BY xact_age_seconds DESC
+LIMIT 200;
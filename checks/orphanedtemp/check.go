@@ -0,0 +1,260 @@
+// Package orphanedtemp implements checks for temporary objects left behind by crashed backends.
+package orphanedtemp
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"slices"
+
+	"github.com/fresha/pgdoctor/check"
+	"github.com/fresha/pgdoctor/db"
+)
+
+//go:embed query.sql
+var querySQL string
+
+//go:embed README.md
+var readme string
+
+const (
+	// Leftover temp files above this total are a disk-space problem, not just clutter.
+	leftoverFilesFailBytes = int64(10 * 1024 * 1024 * 1024) // 10GiB
+)
+
+type OrphanedTempQueries interface {
+	TempSchemas(context.Context) ([]db.TempSchemasRow, error)
+	TempFileAccess(context.Context) (db.TempFileAccessRow, error)
+	LeftoverTempFiles(context.Context) ([]db.LeftoverTempFilesRow, error)
+	ResetUnloggedTables(context.Context) ([]db.ResetUnloggedTablesRow, error)
+}
+
+type checker struct {
+	queries OrphanedTempQueries
+}
+
+func Metadata() check.Metadata {
+	return check.Metadata{
+		Category:       check.CategoryVacuum,
+		CheckID:        "orphaned-temp",
+		Name:           "Orphaned Temporary Objects",
+		Description:    "Detects temp schemas, temp files, and unlogged tables left behind by crashed backends",
+		Readme:         readme,
+		SQL:            querySQL,
+		RuntimeClass:   check.RuntimeMedium,
+		ProductionSafe: true,
+		Privileges:     []check.Privilege{check.PrivilegeReadAllStats, check.PrivilegeMonitor},
+		Findings: []check.FindingSpec{
+			{ID: "orphaned-temp-schemas", Description: "pg_temp_N schemas holding tables with no owning backend (PG16+; XID age heuristic before)", Thresholds: "WARN any, FAIL when XID age > autovacuum_freeze_max_age"},
+			{ID: "leftover-temp-files", Description: "Temporary files whose creating backend no longer exists (requires pg_monitor)", Thresholds: "WARN any, FAIL >= 10GiB"},
+			{ID: "unlogged-reset", Description: "Unlogged tables emptied by crash recovery (empty main fork, non-zero reltuples)", Thresholds: "WARN"},
+		},
+	}
+}
+
+func New(queries OrphanedTempQueries, _ ...check.Config) check.Checker {
+	return &checker{
+		queries: queries,
+	}
+}
+
+func (c *checker) Metadata() check.Metadata {
+	return Metadata()
+}
+
+func (c *checker) Check(ctx context.Context) (*check.Report, error) {
+	report := check.NewReport(Metadata())
+	ignore := check.ObjectFilterFromContext(ctx)
+
+	schemas, err := c.queries.TempSchemas(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("running %s/%s (temp schemas): %w", check.CategoryVacuum, report.CheckID, err)
+	}
+	schemas = slices.DeleteFunc(schemas, func(row db.TempSchemasRow) bool {
+		return ignore.Schema(row.SchemaName)
+	})
+
+	access, err := c.queries.TempFileAccess(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("running %s/%s (temp file access): %w", check.CategoryVacuum, report.CheckID, err)
+	}
+
+	var files []db.LeftoverTempFilesRow
+	if access.CanListTmpDir {
+		files, err = c.queries.LeftoverTempFiles(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("running %s/%s (temp files): %w", check.CategoryVacuum, report.CheckID, err)
+		}
+	}
+
+	unlogged, err := c.queries.ResetUnloggedTables(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("running %s/%s (unlogged): %w", check.CategoryVacuum, report.CheckID, err)
+	}
+	unlogged = slices.DeleteFunc(unlogged, func(row db.ResetUnloggedTablesRow) bool {
+		return ignore.Table(row.SchemaName, row.TableName)
+	})
+
+	checkOrphanedTempSchemas(schemas, report)
+	checkLeftoverTempFiles(access, files, report)
+	checkUnloggedReset(unlogged, report)
+
+	return report, nil
+}
+
+// isOrphaned decides whether a temp schema has lost its backend. Before PG16
+// backend IDs cannot be matched to schemas, so a schema is only suspected
+// once its XID age shows no session has used it for a long time.
+func isOrphaned(row db.TempSchemasRow) bool {
+	if row.BackendIdsReliable {
+		return !row.HasBackend
+	}
+	return row.MaxXidAge > row.AutovacuumFreezeMaxAge
+}
+
+func checkOrphanedTempSchemas(rows []db.TempSchemasRow, report *check.Report) {
+	var tableRows []check.TableRow
+	severity := check.SeverityOK
+	heuristic := false
+
+	for _, row := range rows {
+		if !isOrphaned(row) {
+			continue
+		}
+		heuristic = heuristic || !row.BackendIdsReliable
+
+		rowSeverity := check.SeverityWarn
+		if row.MaxXidAge > row.AutovacuumFreezeMaxAge {
+			rowSeverity = check.SeverityFail
+		}
+		severity = max(severity, rowSeverity)
+
+		tableRows = append(tableRows, check.TableRow{
+			Cells: []string{
+				row.SchemaName,
+				check.FormatNumber(row.TableCount),
+				check.FormatBytes(row.TotalBytes),
+				check.FormatNumber(row.MaxXidAge),
+			},
+			Severity: rowSeverity,
+		})
+	}
+
+	if len(tableRows) == 0 {
+		report.AddFinding(check.Finding{
+			ID:       "orphaned-temp-schemas",
+			Name:     "Orphaned Temp Schemas",
+			Severity: check.SeverityOK,
+			Details:  fmt.Sprintf("All %d temp schema(s) with tables belong to live sessions", len(rows)),
+		})
+		return
+	}
+
+	details := fmt.Sprintf("%d temp schema(s) hold tables with no owning session. Autovacuum cannot vacuum temp tables, so they hold back datfrozenxid", len(tableRows))
+	if severity == check.SeverityFail {
+		details += "; some are already past autovacuum_freeze_max_age and push the database toward wraparound"
+	}
+	if heuristic {
+		details += ". Before PostgreSQL 16 orphans are inferred from XID age"
+	}
+
+	report.AddFinding(check.Finding{
+		ID:       "orphaned-temp-schemas",
+		Name:     "Orphaned Temp Schemas",
+		Severity: severity,
+		Details:  details,
+		Table: &check.Table{
+			Headers: []string{"Schema", "Tables", "Size", "Max XID Age"},
+			Rows:    tableRows,
+		},
+	})
+}
+
+func checkLeftoverTempFiles(access db.TempFileAccessRow, files []db.LeftoverTempFilesRow, report *check.Report) {
+	if !access.CanListTmpDir {
+		report.AddFinding(check.Finding{
+			ID:       "leftover-temp-files",
+			Name:     "Leftover Temp Files",
+			Severity: check.SeverityOK,
+			Details:  "Listing temp files needs pg_ls_tmpdir(), which requires pg_monitor",
+		})
+		return
+	}
+
+	if len(files) == 0 {
+		report.AddFinding(check.Finding{
+			ID:       "leftover-temp-files",
+			Name:     "Leftover Temp Files",
+			Severity: check.SeverityOK,
+			Details:  "Every temp file belongs to a running backend",
+		})
+		return
+	}
+
+	var total int64
+	var tableRows []check.TableRow
+	for _, f := range files {
+		total += f.SizeBytes
+		tableRows = append(tableRows, check.TableRow{
+			Cells:    []string{f.FileName, check.FormatBytes(f.SizeBytes), check.FormatDurationSec(f.AgeSeconds)},
+			Severity: check.SeverityWarn,
+		})
+	}
+
+	severity := check.SeverityWarn
+	if total >= leftoverFilesFailBytes {
+		severity = check.SeverityFail
+	}
+
+	details := fmt.Sprintf("%d temp file(s) totalling %s belong to backends that no longer exist", len(files), check.FormatBytes(total))
+	if access.RemoveTempFilesAfterCrash == "off" {
+		details += ". remove_temp_files_after_crash is off, so crashes leave files behind until they are removed by hand"
+	}
+
+	report.AddFinding(check.Finding{
+		ID:       "leftover-temp-files",
+		Name:     "Leftover Temp Files",
+		Severity: severity,
+		Details:  details,
+		Table: &check.Table{
+			Headers: []string{"File", "Size", "Age"},
+			Rows:    tableRows,
+		},
+	})
+}
+
+func checkUnloggedReset(rows []db.ResetUnloggedTablesRow, report *check.Report) {
+	if len(rows) == 0 {
+		report.AddFinding(check.Finding{
+			ID:       "unlogged-reset",
+			Name:     "Unlogged Tables Reset by Recovery",
+			Severity: check.SeverityOK,
+			Details:  "No unlogged tables appear to have been emptied by crash recovery",
+		})
+		return
+	}
+
+	var tableRows []check.TableRow
+	for _, row := range rows {
+		tableRows = append(tableRows, check.TableRow{
+			Cells: []string{
+				row.SchemaName + "." + row.TableName,
+				check.FormatNumber(row.EstimatedRows),
+				check.FormatBytes(row.InitForkBytes),
+			},
+			Severity: check.SeverityWarn,
+		})
+	}
+
+	report.AddFinding(check.Finding{
+		ID:       "unlogged-reset",
+		Name:     "Unlogged Tables Reset by Recovery",
+		Severity: check.SeverityWarn,
+		Details: fmt.Sprintf("%d unlogged table(s) are empty although statistics recorded rows: crash recovery truncates unlogged tables to their init fork. Repopulate them or ANALYZE if the truncation was intentional",
+			len(rows)),
+		Table: &check.Table{
+			Headers: []string{"Table", "Rows Before", "Init Fork"},
+			Rows:    tableRows,
+		},
+	})
+}
@@ -0,0 +1,180 @@
+package orphanedtemp_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/fresha/pgdoctor/check"
+	"github.com/fresha/pgdoctor/check/checktest"
+	"github.com/fresha/pgdoctor/checks/orphanedtemp"
+	"github.com/fresha/pgdoctor/db"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const gb = int64(1024 * 1024 * 1024)
+
+type mockQueryer struct {
+	schemas  []db.TempSchemasRow
+	access   db.TempFileAccessRow
+	files    []db.LeftoverTempFilesRow
+	unlogged []db.ResetUnloggedTablesRow
+	err      error
+
+	filesCalled bool
+}
+
+func (m *mockQueryer) TempSchemas(context.Context) ([]db.TempSchemasRow, error) {
+	return m.schemas, m.err
+}
+
+func (m *mockQueryer) TempFileAccess(context.Context) (db.TempFileAccessRow, error) {
+	return m.access, nil
+}
+
+func (m *mockQueryer) LeftoverTempFiles(context.Context) ([]db.LeftoverTempFilesRow, error) {
+	m.filesCalled = true
+	return m.files, nil
+}
+
+func (m *mockQueryer) ResetUnloggedTables(context.Context) ([]db.ResetUnloggedTablesRow, error) {
+	return m.unlogged, nil
+}
+
+func healthyQueryer() *mockQueryer {
+	return &mockQueryer{
+		schemas: []db.TempSchemasRow{
+			{SchemaName: "pg_temp_3", TableCount: 2, TotalBytes: 16384, MaxXidAge: 1000, HasBackend: true, BackendIdsReliable: true, AutovacuumFreezeMaxAge: 200_000_000},
+		},
+		access: db.TempFileAccessRow{CanListTmpDir: true, RemoveTempFilesAfterCrash: "on"},
+	}
+}
+
+func TestOrphanedTemp_Healthy(t *testing.T) {
+	t.Parallel()
+
+	report, err := orphanedtemp.New(healthyQueryer()).Check(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, check.SeverityOK, report.Severity)
+	assert.Len(t, report.Results, 3)
+}
+
+func TestOrphanedTemp_Schemas(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		row        db.TempSchemasRow
+		expected   check.Severity
+		heuristics bool
+	}{
+		{
+			name:     "live backend",
+			row:      db.TempSchemasRow{SchemaName: "pg_temp_5", MaxXidAge: 500_000_000, HasBackend: true, BackendIdsReliable: true, AutovacuumFreezeMaxAge: 200_000_000},
+			expected: check.SeverityOK,
+		},
+		{
+			name:     "no backend",
+			row:      db.TempSchemasRow{SchemaName: "pg_temp_5", MaxXidAge: 1000, BackendIdsReliable: true, AutovacuumFreezeMaxAge: 200_000_000},
+			expected: check.SeverityWarn,
+		},
+		{
+			name:     "no backend past freeze max age",
+			row:      db.TempSchemasRow{SchemaName: "pg_temp_5", MaxXidAge: 300_000_000, BackendIdsReliable: true, AutovacuumFreezeMaxAge: 200_000_000},
+			expected: check.SeverityFail,
+		},
+		{
+			name:     "pre-PG16 young schema is not suspected",
+			row:      db.TempSchemasRow{SchemaName: "pg_temp_5", MaxXidAge: 1000, AutovacuumFreezeMaxAge: 200_000_000},
+			expected: check.SeverityOK,
+		},
+		{
+			name:       "pre-PG16 old schema is suspected",
+			row:        db.TempSchemasRow{SchemaName: "pg_temp_5", MaxXidAge: 300_000_000, AutovacuumFreezeMaxAge: 200_000_000},
+			expected:   check.SeverityFail,
+			heuristics: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			q := healthyQueryer()
+			q.schemas = []db.TempSchemasRow{tt.row}
+
+			report, err := orphanedtemp.New(q).Check(context.Background())
+			require.NoError(t, err)
+
+			f := checktest.Finding(t, report, "orphaned-temp-schemas")
+			assert.Equal(t, tt.expected, f.Severity)
+			if tt.expected != check.SeverityOK {
+				require.NotNil(t, f.Table)
+				assert.Equal(t, "pg_temp_5", f.Table.Rows[0].Cells[0])
+			}
+			if tt.heuristics {
+				assert.Contains(t, f.Details, "inferred from XID age")
+			}
+		})
+	}
+}
+
+func TestOrphanedTemp_LeftoverFiles(t *testing.T) {
+	t.Parallel()
+
+	q := healthyQueryer()
+	q.access.RemoveTempFilesAfterCrash = "off"
+	q.files = []db.LeftoverTempFilesRow{
+		{FileName: "pgsql_tmp1234.0", SizeBytes: 8 * gb, AgeSeconds: 86400},
+		{FileName: "pgsql_tmp1234.1", SizeBytes: 4 * gb, AgeSeconds: 86400},
+	}
+
+	report, err := orphanedtemp.New(q).Check(context.Background())
+	require.NoError(t, err)
+
+	f := checktest.Finding(t, report, "leftover-temp-files")
+	assert.Equal(t, check.SeverityFail, f.Severity)
+	assert.Contains(t, f.Details, "remove_temp_files_after_crash is off")
+	assert.Len(t, f.Table.Rows, 2)
+}
+
+func TestOrphanedTemp_WithoutPgMonitor(t *testing.T) {
+	t.Parallel()
+
+	q := healthyQueryer()
+	q.access.CanListTmpDir = false
+
+	report, err := orphanedtemp.New(q).Check(context.Background())
+	require.NoError(t, err)
+
+	assert.False(t, q.filesCalled)
+	f := checktest.Finding(t, report, "leftover-temp-files")
+	assert.Equal(t, check.SeverityOK, f.Severity)
+	assert.Contains(t, f.Details, "pg_monitor")
+}
+
+func TestOrphanedTemp_UnloggedReset(t *testing.T) {
+	t.Parallel()
+
+	q := healthyQueryer()
+	q.unlogged = []db.ResetUnloggedTablesRow{
+		{SchemaName: "public", TableName: "session_cache", EstimatedRows: 50000, InitForkBytes: 0},
+	}
+
+	report, err := orphanedtemp.New(q).Check(context.Background())
+	require.NoError(t, err)
+
+	f := checktest.Finding(t, report, "unlogged-reset")
+	assert.Equal(t, check.SeverityWarn, f.Severity)
+	assert.Equal(t, "public.session_cache", f.Table.Rows[0].Cells[0])
+}
+
+func TestOrphanedTemp_QueryError(t *testing.T) {
+	t.Parallel()
+
+	_, err := orphanedtemp.New(&mockQueryer{err: errors.New("connection refused")}).Check(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "orphaned-temp")
+}
@@ -0,0 +1,69 @@
+-- name: TempSchemas :many
+-- Temporary schemas holding tables, and whether a backend connected to this
+-- database still owns them. pg_temp_N is named after the owning backend ID.
+WITH live_backends AS (
+  SELECT backend_id
+  FROM pg_stat_get_backend_idset() AS backend_id
+  WHERE pg_stat_get_backend_dbid(backend_id) = (
+    SELECT oid FROM pg_database
+    WHERE datname = CURRENT_DATABASE()
+  )
+)
+
+SELECT
+  n.nspname::text AS schema_name
+  , COUNT(c.oid)::bigint AS table_count
+  , COALESCE(SUM(pg_total_relation_size(c.oid)), 0)::bigint AS total_bytes
+  , COALESCE(MAX(AGE(c.relfrozenxid)), 0)::bigint AS max_xid_age
+  , EXISTS (
+    SELECT 1 FROM live_backends AS lb
+    WHERE lb.backend_id = SUBSTRING(n.nspname FROM 'pg_temp_(\d+)')::integer
+  ) AS has_backend
+  -- Before PostgreSQL 16, pg_stat_get_backend_idset() returned positions, not backend IDs
+  , (current_setting('server_version_num')::integer >= 160000)::boolean AS backend_ids_reliable
+  , current_setting('autovacuum_freeze_max_age')::bigint AS autovacuum_freeze_max_age
+FROM pg_namespace AS n
+INNER JOIN pg_class AS c ON n.oid = c.relnamespace
+WHERE
+  n.nspname ~ '^pg_temp_\d+$'
+  AND c.relkind = 'r'
+GROUP BY n.nspname
+ORDER BY max_xid_age DESC;
+
+-- name: TempFileAccess :one
+-- Whether pg_ls_tmpdir() can be called (superuser or pg_monitor) and whether
+-- the server removes temporary files after a backend crash (PostgreSQL 14+).
+SELECT
+  (current_setting('is_superuser')::boolean OR pg_has_role('pg_monitor', 'MEMBER'))::boolean AS can_list_tmp_dir
+  , COALESCE(current_setting('remove_temp_files_after_crash', true), 'on')::text AS remove_temp_files_after_crash;
+
+-- name: LeftoverTempFiles :many
+-- Temporary files in the default tablespace whose creating backend (pgsql_tmpPID.N)
+-- no longer exists.
+SELECT
+  t.name::text AS file_name
+  , t.size::bigint AS size_bytes
+  , EXTRACT(EPOCH FROM (NOW() - t.modification))::bigint AS age_seconds
+FROM pg_ls_tmpdir() AS t
+WHERE NOT EXISTS (
+  SELECT 1 FROM pg_stat_activity AS a
+  WHERE a.pid = SUBSTRING(t.name FROM '^pgsql_tmp(\d+)')::integer
+)
+ORDER BY t.size DESC;
+
+-- name: ResetUnloggedTables :many
+-- Unlogged tables whose main fork is empty although the planner statistics
+-- recorded rows: crash recovery resets unlogged tables to their init fork.
+SELECT
+  n.nspname::text AS schema_name
+  , c.relname::text AS table_name
+  , c.reltuples::bigint AS estimated_rows
+  , pg_relation_size(c.oid, 'init')::bigint AS init_fork_bytes
+FROM pg_class AS c
+INNER JOIN pg_namespace AS n ON c.relnamespace = n.oid
+WHERE
+  c.relpersistence = 'u'
+  AND c.relkind = 'r'
+  AND c.reltuples > 0
+  AND pg_relation_size(c.oid, 'main') = 0
+ORDER BY c.reltuples DESC;
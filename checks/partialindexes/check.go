@@ -0,0 +1,411 @@
+// Package partialindexes implements checks for partial and expression index usage.
+package partialindexes
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/fresha/pgdoctor/check"
+	"github.com/fresha/pgdoctor/db"
+)
+
+//go:embed query.sql
+var querySQL string
+
+//go:embed README.md
+var readme string
+
+// Statement text shown in tables is cut to this many characters.
+const maxQueryLen = 80
+
+var (
+	castPattern      = regexp.MustCompile(`::(?:character varying|timestamp with(?:out)? time zone|double precision|[a-z_][a-z0-9_]*)(?:\[\])?`)
+	qualifierPattern = regexp.MustCompile(`\b[a-z_][a-z0-9_]*\.`)
+	literalPattern   = regexp.MustCompile(`'(?:[^']|'')*'|\$\d+|\b\d+(\.\d+)?\b|\btrue\b|\bfalse\b`)
+	spacePattern     = regexp.MustCompile(`\s+`)
+)
+
+type PartialIndexesQueries interface {
+	PartialExpressionIndexes(context.Context) ([]db.PartialExpressionIndexesRow, error)
+	PartialIndexStatStatementsAvailable(context.Context) (bool, error)
+	PartialIndexStatements(context.Context) ([]db.PartialIndexStatementsRow, error)
+}
+
+type checker struct {
+	queries PartialIndexesQueries
+}
+
+func Metadata() check.Metadata {
+	return check.Metadata{
+		Category:       check.CategoryIndexes,
+		CheckID:        "partial-indexes",
+		Name:           "Partial and Expression Indexes",
+		Description:    "Validates that partial and expression indexes are used and that queries match their predicates",
+		Readme:         readme,
+		SQL:            querySQL,
+		RuntimeClass:   check.RuntimeMedium,
+		ProductionSafe: true,
+		Privileges:     []check.Privilege{check.PrivilegeReadAllStats},
+		Findings: []check.FindingSpec{
+			{ID: "index-inventory", Description: "Partial and expression indexes with scan counts and the number of pg_stat_statements entries matching them", Thresholds: "Informational"},
+			{ID: "unused-expression-indexes", Description: "Non-unique expression indexes that have never been scanned but are recomputed on every write", Thresholds: "FAIL"},
+			{ID: "predicate-near-misses", Description: "Statements that filter on a partial index's predicate columns, or an expression index's columns, without the exact predicate or expression (requires pg_stat_statements)", Thresholds: "WARN"},
+		},
+	}
+}
+
+func New(queries PartialIndexesQueries, _ ...check.Config) check.Checker {
+	return &checker{
+		queries: queries,
+	}
+}
+
+func (c *checker) Metadata() check.Metadata {
+	return Metadata()
+}
+
+func (c *checker) Check(ctx context.Context) (*check.Report, error) {
+	report := check.NewReport(Metadata())
+	ignore := check.ObjectFilterFromContext(ctx)
+
+	indexes, err := c.queries.PartialExpressionIndexes(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("running %s/%s (indexes): %w", report.Category, report.CheckID, err)
+	}
+	indexes = slices.DeleteFunc(indexes, func(row db.PartialExpressionIndexesRow) bool {
+		return ignore.QualifiedTableIndex(row.TableName, row.IndexName)
+	})
+
+	available, err := c.queries.PartialIndexStatStatementsAvailable(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("running %s/%s (extension): %w", report.Category, report.CheckID, err)
+	}
+
+	var statements []db.PartialIndexStatementsRow
+	if available && len(indexes) > 0 {
+		statements, err = c.queries.PartialIndexStatements(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("running %s/%s (statements): %w", report.Category, report.CheckID, err)
+		}
+	}
+
+	matches := make([]indexMatches, len(indexes))
+	for i, idx := range indexes {
+		matches[i] = matchStatements(idx, statements)
+	}
+
+	checkInventory(indexes, matches, available, report)
+	checkUnusedExpressionIndexes(indexes, report)
+	checkNearMisses(indexes, matches, available, report)
+
+	return report, nil
+}
+
+// indexMatches holds the statements that use an index's predicate or
+// expressions verbatim, and those that filter on the same columns without them.
+type indexMatches struct {
+	matching   int
+	nearMisses []db.PartialIndexStatementsRow
+}
+
+// normalize reduces SQL text to a form where an index predicate or expression
+// can be found inside a pg_stat_statements entry: lower case, no casts, table
+// qualifiers, parentheses, or quoting, and every constant replaced by $n since
+// pg_stat_statements does the same.
+func normalize(sql string) string {
+	s := strings.ToLower(sql)
+	s = castPattern.ReplaceAllString(s, "")
+	s = literalPattern.ReplaceAllString(s, "$$n")
+	s = qualifierPattern.ReplaceAllString(s, "")
+	s = strings.NewReplacer("(", " ", ")", " ", `"`, "").Replace(s)
+	return strings.TrimSpace(spacePattern.ReplaceAllString(s, " "))
+}
+
+// splitTopLevel splits s on sep where it is not nested inside parentheses,
+// after removing parentheses that wrap the whole expression.
+func splitTopLevel(s, sep string) []string {
+	s = strings.TrimSpace(s)
+	for len(s) > 1 && s[0] == '(' && closingParen(s) == len(s)-1 {
+		s = strings.TrimSpace(s[1 : len(s)-1])
+	}
+
+	var parts []string
+	depth, start := 0, 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		default:
+			if depth == 0 && strings.HasPrefix(s[i:], sep) {
+				parts = append(parts, s[start:i])
+				start = i + len(sep)
+				i += len(sep) - 1
+			}
+		}
+	}
+	return append(parts, s[start:])
+}
+
+// closingParen returns the index of the parenthesis closing the one at s[0].
+func closingParen(s string) int {
+	depth := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// filtersOn reports whether the WHERE clause of a normalized statement
+// mentions every one of the given columns.
+func filtersOn(normalizedQuery string, columns []string) bool {
+	_, where, ok := strings.Cut(normalizedQuery, " where ")
+	if !ok || len(columns) == 0 {
+		return false
+	}
+	for _, col := range columns {
+		if !regexp.MustCompile(`\b` + regexp.QuoteMeta(col) + `\b`).MatchString(where) {
+			return false
+		}
+	}
+	return true
+}
+
+func containsAll(normalizedQuery string, parts []string) bool {
+	for _, p := range parts {
+		if !strings.Contains(" "+normalizedQuery+" ", " "+normalize(p)+" ") {
+			return false
+		}
+	}
+	return true
+}
+
+func splitColumns(columns string) []string {
+	if columns == "" {
+		return nil
+	}
+	return strings.Split(columns, ",")
+}
+
+func matchStatements(idx db.PartialExpressionIndexesRow, statements []db.PartialIndexStatementsRow) indexMatches {
+	var m indexMatches
+	table := regexp.MustCompile(`(?i)\b` + regexp.QuoteMeta(idx.TableShortName) + `\b`)
+
+	var conjuncts, expressions []string
+	if idx.Predicate != "" {
+		conjuncts = splitTopLevel(idx.Predicate, " AND ")
+	}
+	if idx.Expressions != "" {
+		expressions = splitTopLevel(idx.Expressions, ", ")
+	}
+	predicateCols := splitColumns(idx.PredicateColumns)
+	expressionCols := splitColumns(idx.ExpressionColumns)
+
+	for _, stmt := range statements {
+		if !table.MatchString(stmt.Query) {
+			continue
+		}
+		q := normalize(stmt.Query)
+
+		predicateOK := len(conjuncts) == 0 || containsAll(q, conjuncts)
+		expressionOK := len(expressions) == 0 || containsAll(q, expressions)
+		if predicateOK && expressionOK {
+			m.matching++
+			continue
+		}
+
+		if (!predicateOK && filtersOn(q, predicateCols)) || (!expressionOK && filtersOn(q, expressionCols)) {
+			m.nearMisses = append(m.nearMisses, stmt)
+		}
+	}
+	return m
+}
+
+func indexKind(idx db.PartialExpressionIndexesRow) string {
+	switch {
+	case idx.Predicate != "" && idx.Expressions != "":
+		return "partial expression"
+	case idx.Predicate != "":
+		return "partial"
+	default:
+		return "expression"
+	}
+}
+
+func definition(idx db.PartialExpressionIndexesRow) string {
+	switch {
+	case idx.Predicate != "" && idx.Expressions != "":
+		return fmt.Sprintf("(%s) WHERE %s", idx.Expressions, idx.Predicate)
+	case idx.Predicate != "":
+		return "WHERE " + idx.Predicate
+	default:
+		return "(" + idx.Expressions + ")"
+	}
+}
+
+func truncate(s string) string {
+	if len(s) <= maxQueryLen {
+		return s
+	}
+	return s[:maxQueryLen-3] + "..."
+}
+
+func checkInventory(indexes []db.PartialExpressionIndexesRow, matches []indexMatches, available bool, report *check.Report) {
+	if len(indexes) == 0 {
+		report.AddFinding(check.Finding{
+			ID:       "index-inventory",
+			Name:     "Partial and Expression Indexes",
+			Severity: check.SeverityOK,
+			Details:  "No partial or expression indexes",
+		})
+		return
+	}
+
+	var tableRows []check.TableRow
+	for i, idx := range indexes {
+		matching := "n/a"
+		if available {
+			matching = check.FormatNumber(int64(matches[i].matching))
+		}
+		tableRows = append(tableRows, check.TableRow{
+			Cells: []string{
+				idx.TableName,
+				idx.IndexName,
+				indexKind(idx),
+				definition(idx),
+				check.FormatNumber(idx.IdxScan),
+				check.FormatBytes(idx.IndexBytes),
+				matching,
+			},
+			Severity: check.SeverityOK,
+		})
+	}
+
+	details := fmt.Sprintf("%d partial or expression index(es)", len(indexes))
+	if !available {
+		details += "; install pg_stat_statements to see which statements match them"
+	}
+
+	report.AddFinding(check.Finding{
+		ID:       "index-inventory",
+		Name:     "Partial and Expression Indexes",
+		Severity: check.SeverityOK,
+		Details:  details,
+		Table: &check.Table{
+			Headers: []string{"Table", "Index", "Kind", "Definition", "Scans", "Size", "Matching Queries"},
+			Rows:    tableRows,
+		},
+	})
+}
+
+func checkUnusedExpressionIndexes(indexes []db.PartialExpressionIndexesRow, report *check.Report) {
+	var tableRows []check.TableRow
+	for _, idx := range indexes {
+		// Unique expression indexes enforce constraints even when never scanned.
+		if idx.Expressions == "" || idx.IsUnique || idx.IdxScan > 0 {
+			continue
+		}
+		tableRows = append(tableRows, check.TableRow{
+			Cells: []string{
+				idx.TableName,
+				idx.IndexName,
+				idx.Expressions,
+				check.FormatBytes(idx.IndexBytes),
+				check.FormatNumber(idx.TableWrites),
+			},
+			Severity: check.SeverityFail,
+		})
+	}
+
+	if len(tableRows) == 0 {
+		report.AddFinding(check.Finding{
+			ID:       "unused-expression-indexes",
+			Name:     "Unused Expression Indexes",
+			Severity: check.SeverityOK,
+			Details:  "Every non-unique expression index has been scanned",
+		})
+		return
+	}
+
+	report.AddFinding(check.Finding{
+		ID:       "unused-expression-indexes",
+		Name:     "Unused Expression Indexes",
+		Severity: check.SeverityFail,
+		Details: fmt.Sprintf("%d expression index(es) have never been scanned. The expression is evaluated on every insert and non-HOT update, "+
+			"so these cost write throughput for nothing. Queries only use an expression index when they repeat the expression exactly; "+
+			"check the near-miss finding, then fix the queries or DROP INDEX CONCURRENTLY (after confirming on replicas)",
+			len(tableRows)),
+		Table: &check.Table{
+			Headers: []string{"Table", "Index", "Expression", "Size", "Table Writes"},
+			Rows:    tableRows,
+		},
+	})
+}
+
+func checkNearMisses(indexes []db.PartialExpressionIndexesRow, matches []indexMatches, available bool, report *check.Report) {
+	if !available {
+		report.AddFinding(check.Finding{
+			ID:         "predicate-near-misses",
+			Confidence: check.ConfidenceLow,
+			Name:       "Predicate Near Misses",
+			Severity:   check.SeverityOK,
+			Details:    "Matching statements against index predicates needs the pg_stat_statements extension, which is not installed",
+		})
+		return
+	}
+
+	var tableRows []check.TableRow
+	for i, idx := range indexes {
+		for _, stmt := range matches[i].nearMisses {
+			tableRows = append(tableRows, check.TableRow{
+				Cells: []string{
+					idx.IndexName,
+					definition(idx),
+					truncate(stmt.Query),
+					check.FormatNumber(stmt.Calls),
+				},
+				Severity: check.SeverityWarn,
+			})
+		}
+	}
+
+	if len(tableRows) == 0 {
+		report.AddFinding(check.Finding{
+			ID:         "predicate-near-misses",
+			Confidence: check.ConfidenceLow,
+			Name:       "Predicate Near Misses",
+			Severity:   check.SeverityOK,
+			Details:    "No frequent statements filter on partial or expression index columns without using the index's predicate or expression",
+		})
+		return
+	}
+
+	report.AddFinding(check.Finding{
+		ID:         "predicate-near-misses",
+		Confidence: check.ConfidenceLow,
+		Name:       "Predicate Near Misses",
+		Severity:   check.SeverityWarn,
+		Details: fmt.Sprintf("%d statement(s) filter on the columns of a partial or expression index without matching it. "+
+			"The planner only uses a partial index when it can prove the query's WHERE clause implies the index predicate, "+
+			"and an expression index only when the query contains the same expression (email = $1 cannot use an index on lower(email)). "+
+			"IN lists, comparisons written with a different operator, and other equivalent rewrites all miss",
+			len(tableRows)),
+		Table: &check.Table{
+			Headers: []string{"Index", "Definition", "Query", "Calls"},
+			Rows:    tableRows,
+		},
+	})
+}
@@ -0,0 +1,259 @@
+package partialindexes_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/fresha/pgdoctor/check"
+	"github.com/fresha/pgdoctor/check/checktest"
+	"github.com/fresha/pgdoctor/checks/partialindexes"
+	"github.com/fresha/pgdoctor/db"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockQueryer struct {
+	indexes    []db.PartialExpressionIndexesRow
+	available  bool
+	statements []db.PartialIndexStatementsRow
+	err        error
+
+	statementsCalled bool
+}
+
+func (m *mockQueryer) PartialExpressionIndexes(context.Context) ([]db.PartialExpressionIndexesRow, error) {
+	return m.indexes, m.err
+}
+
+func (m *mockQueryer) PartialIndexStatStatementsAvailable(context.Context) (bool, error) {
+	return m.available, nil
+}
+
+func (m *mockQueryer) PartialIndexStatements(context.Context) ([]db.PartialIndexStatementsRow, error) {
+	m.statementsCalled = true
+	return m.statements, nil
+}
+
+var (
+	pendingJobsIndex = db.PartialExpressionIndexesRow{
+		TableName:        "public.jobs",
+		TableShortName:   "jobs",
+		IndexName:        "jobs_pending_idx",
+		Predicate:        "(status = 'pending'::text)",
+		PredicateColumns: "status",
+		IdxScan:          500,
+	}
+	activeUsersIndex = db.PartialExpressionIndexesRow{
+		TableName:        "public.users",
+		TableShortName:   "users",
+		IndexName:        "users_active_idx",
+		Predicate:        "((deleted_at IS NULL) AND (NOT archived))",
+		PredicateColumns: "deleted_at,archived",
+		IdxScan:          10,
+	}
+	lowerEmailIndex = db.PartialExpressionIndexesRow{
+		TableName:         "public.users",
+		TableShortName:    "users",
+		IndexName:         "users_lower_email_idx",
+		Expressions:       "lower((email)::text)",
+		ExpressionColumns: "email",
+		IdxScan:           0,
+		TableWrites:       50_000,
+	}
+)
+
+func TestPartialIndexes_NoIndexes(t *testing.T) {
+	t.Parallel()
+
+	q := &mockQueryer{available: true}
+	report, err := partialindexes.New(q).Check(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, check.SeverityOK, report.Severity)
+	assert.Len(t, report.Results, 3)
+	assert.False(t, q.statementsCalled, "statements are only read when there are indexes to match")
+}
+
+func TestPartialIndexes_Inventory(t *testing.T) {
+	t.Parallel()
+
+	q := &mockQueryer{
+		indexes:   []db.PartialExpressionIndexesRow{pendingJobsIndex, lowerEmailIndex},
+		available: true,
+		statements: []db.PartialIndexStatementsRow{
+			{Query: "SELECT id FROM jobs WHERE status = $1 ORDER BY created_at LIMIT $2", Calls: 1000},
+			{Query: "SELECT * FROM public.users u WHERE lower(u.email) = $1", Calls: 200},
+		},
+	}
+	report, err := partialindexes.New(q).Check(context.Background())
+	require.NoError(t, err)
+
+	f := checktest.Finding(t, report, "index-inventory")
+	assert.Equal(t, check.SeverityOK, f.Severity)
+	require.NotNil(t, f.Table)
+	require.Len(t, f.Table.Rows, 2)
+	assert.Equal(t, []string{"public.jobs", "jobs_pending_idx", "partial", "WHERE (status = 'pending'::text)", "500", "0B", "1"}, f.Table.Rows[0].Cells)
+	assert.Equal(t, "expression", f.Table.Rows[1].Cells[2])
+	assert.Equal(t, "1", f.Table.Rows[1].Cells[6])
+}
+
+func TestPartialIndexes_UnusedExpressionIndexes(t *testing.T) {
+	t.Parallel()
+
+	unique := lowerEmailIndex
+	unique.IndexName = "users_lower_email_key"
+	unique.IsUnique = true
+
+	used := lowerEmailIndex
+	used.IdxScan = 42
+
+	tests := []struct {
+		name     string
+		index    db.PartialExpressionIndexesRow
+		expected check.Severity
+	}{
+		{name: "never scanned", index: lowerEmailIndex, expected: check.SeverityFail},
+		{name: "unique constraint", index: unique, expected: check.SeverityOK},
+		{name: "scanned", index: used, expected: check.SeverityOK},
+		{name: "unused partial index", index: db.PartialExpressionIndexesRow{IndexName: "p", Predicate: "(a IS NULL)"}, expected: check.SeverityOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			q := &mockQueryer{indexes: []db.PartialExpressionIndexesRow{tt.index}}
+			report, err := partialindexes.New(q).Check(context.Background())
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.expected, checktest.Finding(t, report, "unused-expression-indexes").Severity)
+		})
+	}
+}
+
+func TestPartialIndexes_NearMisses(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		index    db.PartialExpressionIndexesRow
+		query    string
+		expected check.Severity
+	}{
+		{
+			name:     "matching predicate",
+			index:    pendingJobsIndex,
+			query:    "SELECT id FROM jobs WHERE status = $1 AND created_at < $2",
+			expected: check.SeverityOK,
+		},
+		{
+			name:     "IN list instead of equality",
+			index:    pendingJobsIndex,
+			query:    "SELECT id FROM jobs WHERE status IN ($1, $2)",
+			expected: check.SeverityWarn,
+		},
+		{
+			name:     "ANY array",
+			index:    pendingJobsIndex,
+			query:    "SELECT id FROM jobs WHERE status = ANY($1)",
+			expected: check.SeverityWarn,
+		},
+		{
+			name:     "column not filtered",
+			index:    pendingJobsIndex,
+			query:    "SELECT status FROM jobs WHERE id = $1",
+			expected: check.SeverityOK,
+		},
+		{
+			name:     "other table",
+			index:    pendingJobsIndex,
+			query:    "SELECT id FROM tasks WHERE status = ANY($1)",
+			expected: check.SeverityOK,
+		},
+		{
+			name:     "all conjuncts present",
+			index:    activeUsersIndex,
+			query:    "SELECT * FROM users WHERE NOT archived AND deleted_at IS NULL AND id = $1",
+			expected: check.SeverityOK,
+		},
+		{
+			name:     "one conjunct rewritten",
+			index:    activeUsersIndex,
+			query:    "SELECT * FROM users WHERE archived = $1 AND deleted_at IS NULL",
+			expected: check.SeverityWarn,
+		},
+		{
+			name:     "expression used",
+			index:    lowerEmailIndex,
+			query:    "SELECT * FROM users WHERE LOWER(email) = $1",
+			expected: check.SeverityOK,
+		},
+		{
+			name:     "bare column instead of expression",
+			index:    lowerEmailIndex,
+			query:    "SELECT * FROM users WHERE email = $1",
+			expected: check.SeverityWarn,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			q := &mockQueryer{
+				indexes:    []db.PartialExpressionIndexesRow{tt.index},
+				available:  true,
+				statements: []db.PartialIndexStatementsRow{{QueryID: 1, Query: tt.query, Calls: 100}},
+			}
+			report, err := partialindexes.New(q).Check(context.Background())
+			require.NoError(t, err)
+
+			f := checktest.Finding(t, report, "predicate-near-misses")
+			assert.Equal(t, tt.expected, f.Severity)
+			assert.Equal(t, check.ConfidenceLow, f.Confidence)
+			if tt.expected == check.SeverityWarn {
+				require.NotNil(t, f.Table)
+				assert.Len(t, f.Table.Rows, 1)
+			}
+		})
+	}
+}
+
+func TestPartialIndexes_WithoutStatStatements(t *testing.T) {
+	t.Parallel()
+
+	q := &mockQueryer{indexes: []db.PartialExpressionIndexesRow{pendingJobsIndex}}
+	report, err := partialindexes.New(q).Check(context.Background())
+	require.NoError(t, err)
+
+	assert.False(t, q.statementsCalled)
+
+	f := checktest.Finding(t, report, "predicate-near-misses")
+	assert.Equal(t, check.SeverityOK, f.Severity)
+	assert.Contains(t, f.Details, "pg_stat_statements")
+
+	inventory := checktest.Finding(t, report, "index-inventory")
+	require.NotNil(t, inventory.Table)
+	assert.Equal(t, "n/a", inventory.Table.Rows[0].Cells[6])
+}
+
+func TestPartialIndexes_QueryError(t *testing.T) {
+	t.Parallel()
+
+	q := &mockQueryer{err: errors.New("connection refused")}
+
+	_, err := partialindexes.New(q).Check(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "partial-indexes")
+}
+
+func TestMetadata(t *testing.T) {
+	t.Parallel()
+
+	meta := partialindexes.Metadata()
+	assert.Equal(t, "partial-indexes", meta.CheckID)
+	assert.Equal(t, check.CategoryIndexes, meta.Category)
+	assert.NotEmpty(t, meta.SQL)
+	assert.NotEmpty(t, meta.Readme)
+}
@@ -0,0 +1,80 @@
+-- name: PartialExpressionIndexes :many
+-- Partial and expression indexes with their predicate or expressions, the
+-- table columns those reference, and usage statistics.
+-- Excludes: system schemas.
+SELECT
+  (n.nspname || '.' || t.relname)::text AS table_name
+  , t.relname::text AS table_short_name
+  , i.relname::text AS index_name
+  , x.indisunique AS is_unique
+  , COALESCE(PG_GET_EXPR(x.indpred, x.indrelid), '')::text AS predicate
+  , COALESCE(PG_GET_EXPR(x.indexprs, x.indrelid), '')::text AS expressions
+  , COALESCE((
+    SELECT STRING_AGG(a.attname, ',' ORDER BY a.attnum)
+    FROM pg_attribute AS a
+    WHERE
+      a.attrelid = t.oid
+      AND a.attnum > 0
+      AND NOT a.attisdropped
+      AND PG_GET_EXPR(x.indpred, x.indrelid) ~ ('\m' || a.attname || '\M')
+  ), '')::text AS predicate_columns
+  , COALESCE((
+    SELECT STRING_AGG(a.attname, ',' ORDER BY a.attnum)
+    FROM pg_attribute AS a
+    WHERE
+      a.attrelid = t.oid
+      AND a.attnum > 0
+      AND NOT a.attisdropped
+      AND PG_GET_EXPR(x.indexprs, x.indrelid) ~ ('\m' || a.attname || '\M')
+  ), '')::text AS expression_columns
+  , PG_RELATION_SIZE(i.oid)::bigint AS index_bytes
+  , COALESCE(psi.idx_scan, 0)::bigint AS idx_scan
+  , (COALESCE(pst.n_tup_ins, 0) + COALESCE(pst.n_tup_upd, 0) + COALESCE(pst.n_tup_del, 0))::bigint AS table_writes
+FROM pg_index AS x
+INNER JOIN pg_class AS i ON x.indexrelid = i.oid
+INNER JOIN pg_class AS t ON x.indrelid = t.oid
+INNER JOIN pg_namespace AS n ON t.relnamespace = n.oid
+LEFT JOIN pg_stat_user_indexes AS psi ON x.indexrelid = psi.indexrelid
+LEFT JOIN pg_stat_user_tables AS pst ON t.oid = pst.relid
+WHERE
+  (x.indpred IS NOT NULL OR x.indexprs IS NOT NULL)
+  AND n.nspname NOT IN ('pg_catalog', 'information_schema')
+  AND n.nspname !~ '^pg_toast'
+ORDER BY t.relname, i.relname;
+
+-- name: PartialIndexStatStatementsAvailable :one
+-- Checks if the pg_stat_statements extension is installed in this database.
+SELECT EXISTS(
+  SELECT 1 FROM pg_extension
+  WHERE extname = 'pg_stat_statements'
+) AS available;
+
+-- name: PartialIndexStatements :many
+-- Frequent DML statements that mention a table carrying a partial or
+-- expression index, for matching against index predicates and expressions.
+WITH indexed_tables AS (
+  SELECT DISTINCT t.relname
+  FROM pg_index AS x
+  INNER JOIN pg_class AS t ON x.indrelid = t.oid
+  INNER JOIN pg_namespace AS n ON t.relnamespace = n.oid
+  WHERE
+    (x.indpred IS NOT NULL OR x.indexprs IS NOT NULL)
+    AND n.nspname NOT IN ('pg_catalog', 'information_schema')
+    AND n.nspname !~ '^pg_toast'
+)
+
+SELECT
+  s.queryid::bigint AS query_id
+  , REGEXP_REPLACE(s.query, '\s+', ' ', 'g')::text AS query
+  , s.calls::bigint AS calls
+  , s.total_exec_time::float8 AS total_exec_time
+FROM pg_stat_statements AS s
+WHERE
+  s.calls > 10
+  AND s.query ~* '^\s*(SELECT|UPDATE|DELETE|WITH)'
+  AND EXISTS (
+    SELECT 1 FROM indexed_tables AS it
+    WHERE s.query ~* ('\m' || it.relname || '\M')
+  )
+ORDER BY s.total_exec_time DESC
+LIMIT 500;
@@ -5,9 +5,13 @@ import (
 	"context"
 	_ "embed"
 	"fmt"
+	"slices"
+	"strings"
 
 	"github.com/fresha/pgdoctor/check"
 	"github.com/fresha/pgdoctor/db"
+	"github.com/fresha/pgdoctor/internal/accesspattern"
+	"github.com/fresha/pgdoctor/internal/pgident"
 )
 
 //go:embed query.sql
@@ -18,6 +22,7 @@ var readme string
 
 type PartitioningQueries interface {
 	LargeTables(context.Context) ([]db.LargeTablesRow, error)
+	PartitionedForeignKeys(context.Context) ([]db.PartitionedForeignKeysRow, error)
 }
 
 type checker struct {
@@ -33,19 +38,30 @@ const (
 	activityAwareFailRows = int64(25_000_000)
 	activityAwareWarnRows = int64(10_000_000)
 
-	// Activity thresholds for determining table write patterns.
-	insertHeavyRatio = 0.80 // >80% of DML operations are inserts
-	highDeleteRatio  = 0.20 // >20% deletes relative to inserts
+	// Foreign key amplification thresholds, in referencing rows scanned for
+	// each DELETE or key UPDATE on the referenced table when the referencing
+	// columns are unindexed.
+	fkScanWarnRows = int64(100_000)
+	fkScanFailRows = int64(10_000_000)
 )
 
 func Metadata() check.Metadata {
 	return check.Metadata{
-		Category:    check.CategorySchema,
-		CheckID:     "partitioning",
-		Name:        "Table Partitioning",
-		Description: "Validates large and transient tables are properly partitioned",
-		Readme:      readme,
-		SQL:         querySQL,
+		Category:       check.CategorySchema,
+		CheckID:        "partitioning",
+		Name:           "Table Partitioning",
+		Description:    "Validates large and transient tables are properly partitioned and foreign keys on partitioned tables are indexed",
+		Readme:         readme,
+		SQL:            querySQL,
+		RuntimeClass:   check.RuntimeMedium,
+		ProductionSafe: true,
+		Findings: []check.FindingSpec{
+			{ID: "large-unpartitioned", Description: "Large business tables that are not partitioned", Thresholds: "WARN >= 25M, FAIL >= 50M rows (10M/25M for write-heavy tables)"},
+			{ID: "transient-unpartitioned", Description: "Large outbox, inbox, job, and event tables that are not partitioned", Thresholds: "FAIL"},
+			{ID: "inefficient-partitions", Description: "Individual partitions that have grown too large", Thresholds: "WARN >= 10M rows"},
+			{ID: "fk-to-partitioned", Description: "Foreign keys referencing a partitioned table whose referencing columns are unindexed", Thresholds: "WARN >= 100K referencing rows, FAIL >= 10M with deletes or updates on the referenced table"},
+			{ID: "fk-from-partitioned", Description: "Foreign keys from a partitioned table whose referencing columns are unindexed", Thresholds: "WARN >= 100K referencing rows, FAIL >= 10M with deletes or updates on the referenced table"},
+		},
 	}
 }
 
@@ -61,11 +77,15 @@ func (c *checker) Metadata() check.Metadata {
 
 func (c *checker) Check(ctx context.Context) (*check.Report, error) {
 	report := check.NewReport(Metadata())
+	ignore := check.ObjectFilterFromContext(ctx)
 
 	rows, err := c.queries.LargeTables(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("running %s/%s: %w", check.CategorySchema, report.CheckID, err)
 	}
+	rows = slices.DeleteFunc(rows, func(row db.LargeTablesRow) bool {
+		return ignore.QualifiedTable(row.TableName.String)
+	})
 
 	var largeUnpartitioned []db.LargeTablesRow
 	var transientUnpartitioned []db.LargeTablesRow
@@ -90,50 +110,53 @@ func (c *checker) Check(ctx context.Context) (*check.Report, error) {
 		}
 	}
 
+	foreignKeys, err := c.queries.PartitionedForeignKeys(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("running %s/%s: %w", check.CategorySchema, report.CheckID, err)
+	}
+	foreignKeys = slices.DeleteFunc(foreignKeys, func(row db.PartitionedForeignKeysRow) bool {
+		return ignore.Table(row.SchemaName, row.TableName)
+	})
+
+	var toPartitioned, fromPartitioned []db.PartitionedForeignKeysRow
+	for _, fk := range foreignKeys {
+		if fk.ReferencedPartitioned {
+			toPartitioned = append(toPartitioned, fk)
+		} else {
+			fromPartitioned = append(fromPartitioned, fk)
+		}
+	}
+
 	// Run subchecks.
 	checkLargeUnpartitioned(largeUnpartitioned, report)
 	checkTransientUnpartitioned(transientUnpartitioned, report)
 	checkInefficientPartitions(inefficientPartitions, report)
+	checkForeignKeys("fk-to-partitioned", "Foreign Keys to Partitioned Tables", toPartitioned, report)
+	checkForeignKeys("fk-from-partitioned", "Foreign Keys from Partitioned Tables", fromPartitioned, report)
 
 	return report, nil
 }
 
-// isInsertHeavy returns true if >80% of DML operations are inserts.
-func isInsertHeavy(row db.LargeTablesRow) bool {
-	ins := row.NTupIns.Int64
-	upd := row.NTupUpd.Int64
-	del := row.NTupDel.Int64
-	total := ins + upd + del
-	if total == 0 {
-		return false
-	}
-	return float64(ins)/float64(total) > insertHeavyRatio
-}
-
-// isHighDelete returns true if deletes are >20% of inserts.
-func isHighDelete(row db.LargeTablesRow) bool {
-	ins := row.NTupIns.Int64
-	del := row.NTupDel.Int64
-	if ins == 0 {
-		return false
-	}
-	return float64(del)/float64(ins) > highDeleteRatio
+// writePattern classifies the table from its write counters.
+func writePattern(row db.LargeTablesRow) accesspattern.Pattern {
+	return accesspattern.Classify(accesspattern.Counters{
+		Inserts:  row.NTupIns.Int64,
+		Updates:  row.NTupUpd.Int64,
+		Deletes:  row.NTupDel.Int64,
+		LiveRows: row.EstimatedRows.Int64,
+	})
 }
 
-// isActivityAware returns true if the table qualifies for lower thresholds.
+// isActivityAware returns true if the table qualifies for lower thresholds:
+// tables that keep growing or shed old rows, which partitions turn into
+// DROP PARTITION instead of DELETE.
 func isActivityAware(row db.LargeTablesRow) bool {
-	return isInsertHeavy(row) || isHighDelete(row)
-}
-
-// activityReason returns a human-readable reason for activity-aware flagging.
-func activityReason(row db.LargeTablesRow) string {
-	if isInsertHeavy(row) {
-		return "Insert-heavy"
-	}
-	if isHighDelete(row) {
-		return "High-delete"
+	switch writePattern(row) {
+	case accesspattern.AppendOnly, accesspattern.DeleteHeavy, accesspattern.QueueLike:
+		return true
+	default:
+		return false
 	}
-	return "Large table"
 }
 
 func checkLargeUnpartitioned(rows []db.LargeTablesRow, report *check.Report) {
@@ -177,7 +200,7 @@ func checkLargeUnpartitioned(rows []db.LargeTablesRow, report *check.Report) {
 				row.TableName.String,
 				check.FormatBytes(row.TableSizeBytes.Int64),
 				check.FormatNumber(row.EstimatedRows.Int64),
-				activityReason(row),
+				string(writePattern(row)),
 				"MUST partition",
 			},
 			Severity: check.SeverityFail,
@@ -190,7 +213,7 @@ func checkLargeUnpartitioned(rows []db.LargeTablesRow, report *check.Report) {
 				row.TableName.String,
 				check.FormatBytes(row.TableSizeBytes.Int64),
 				check.FormatNumber(row.EstimatedRows.Int64),
-				activityReason(row),
+				string(writePattern(row)),
 				"Approaching threshold",
 			},
 			Severity: check.SeverityWarn,
@@ -208,7 +231,7 @@ func checkLargeUnpartitioned(rows []db.LargeTablesRow, report *check.Report) {
 		Severity: severity,
 		Details:  fmt.Sprintf("Found %d large table(s) that should be partitioned", len(rows)),
 		Table: &check.Table{
-			Headers: []string{"Table", "Size", "Est. Rows", "Reason", "Status"},
+			Headers: []string{"Table", "Size", "Est. Rows", "Write Pattern", "Status"},
 			Rows:    tableRows,
 		},
 	})
@@ -284,3 +307,128 @@ func checkInefficientPartitions(rows []db.LargeTablesRow, report *check.Report)
 		},
 	})
 }
+
+// fkRisk scores how much work one DELETE or key UPDATE on the referenced table
+// causes on the referencing side. With an index the referencing rows are found
+// by lookup; without one every partition of the referencing table is scanned,
+// so the cost grows with its size. Referenced writes count every update, not
+// only key updates, so they overstate how often the scan happens.
+func fkRisk(fk db.PartitionedForeignKeysRow) check.Severity {
+	switch {
+	case fk.HasIndex:
+		return check.SeverityOK
+	case fk.ReferencingRows >= fkScanFailRows && fk.ReferencedWrites > 0:
+		return check.SeverityFail
+	case fk.ReferencingRows >= fkScanWarnRows:
+		return check.SeverityWarn
+	default:
+		return check.SeverityOK
+	}
+}
+
+// checkForeignKeys reports foreign keys involving a partitioned table whose
+// referencing columns are unindexed, ranked by the rows each referenced-side
+// DELETE or key UPDATE scans.
+func checkForeignKeys(id, name string, fks []db.PartitionedForeignKeysRow, report *check.Report) {
+	severity := check.SeverityOK
+	var tableRows []check.TableRow
+	var fixes []check.Fix
+	for _, fk := range fks {
+		risk := fkRisk(fk)
+		if risk == check.SeverityOK {
+			continue
+		}
+		severity = max(severity, risk)
+
+		tableRows = append(tableRows, check.TableRow{
+			Cells: []string{
+				fk.ConstraintName,
+				fmt.Sprintf("%s.%s (%s)", fk.SchemaName, fk.TableName, strings.Join(fk.Columns, ", ")),
+				fk.ReferencedSchema + "." + fk.ReferencedTable,
+				check.FormatNumber(fk.ReferencingRows),
+				check.FormatNumber(fk.ReferencedWrites),
+				onDeleteAction(fk.OnDelete),
+			},
+			Severity: risk,
+		})
+		fixes = append(fixes, fkIndexFix(fk))
+	}
+
+	if severity == check.SeverityOK {
+		report.AddFinding(check.Finding{
+			ID:       id,
+			Name:     name,
+			Severity: check.SeverityOK,
+			Details:  fmt.Sprintf("%d foreign key(s) checked; referencing columns are indexed or the referencing tables are small", len(fks)),
+		})
+		return
+	}
+
+	details := fmt.Sprintf("%d foreign key(s) have unindexed referencing columns. Each DELETE or key UPDATE on the referenced table "+
+		"scans every partition of the referencing table to find matching rows, about as many rows as the table holds, "+
+		"while holding locks; ON DELETE CASCADE and SET NULL then write to each match", len(tableRows))
+	if id == "fk-to-partitioned" {
+		details += ". Detaching or dropping a partition of the referenced table has to run the same check for all of its rows"
+	}
+
+	report.AddFinding(check.Finding{
+		ID:         id,
+		Name:       name,
+		Severity:   severity,
+		Details:    details,
+		Confidence: check.ConfidenceMedium,
+		Table: &check.Table{
+			Headers: []string{"Foreign Key", "Referencing Columns", "References", "Rows Scanned per Delete", "Referenced Deletes+Updates", "On Delete"},
+			Rows:    tableRows,
+		},
+		Fixes: fixes,
+	})
+}
+
+func onDeleteAction(code string) string {
+	switch code {
+	case "r":
+		return "RESTRICT"
+	case "c":
+		return "CASCADE"
+	case "n":
+		return "SET NULL"
+	case "d":
+		return "SET DEFAULT"
+	default:
+		return "NO ACTION"
+	}
+}
+
+// fkIndexFix indexes the referencing columns. CREATE INDEX CONCURRENTLY is
+// not supported on a partitioned table, so there the fix blocks writes to
+// every partition for the whole build and is marked high risk; build it per
+// partition and attach instead when that is too long.
+func fkIndexFix(fk db.PartitionedForeignKeysRow) check.Fix {
+	columns := make([]string, len(fk.Columns))
+	for i, col := range fk.Columns {
+		columns[i] = pgident.Quote(col)
+	}
+	table := pgident.Quote(fk.SchemaName, fk.TableName)
+	object := fk.SchemaName + "." + fk.TableName
+
+	if fk.ReferencingPartitioned {
+		return check.Fix{
+			Object: object,
+			Description: fmt.Sprintf("Index the referencing columns of %s. This blocks writes to every partition of %s while it builds; "+
+				"to avoid that, create the index ON ONLY %s, build it on each partition with CREATE INDEX CONCURRENTLY, and attach each one",
+				fk.ConstraintName, object, object),
+			SQL:  fmt.Sprintf("CREATE INDEX ON %s (%s)", table, strings.Join(columns, ", ")),
+			Risk: check.RiskHigh,
+			Lock: check.LockWrites,
+		}
+	}
+
+	return check.Fix{
+		Object:      object,
+		Description: fmt.Sprintf("Index the referencing columns of %s without blocking writes", fk.ConstraintName),
+		SQL:         fmt.Sprintf("CREATE INDEX CONCURRENTLY ON %s (%s)", table, strings.Join(columns, ", ")),
+		Risk:        check.RiskLow,
+		Lock:        check.LockOnline,
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/fresha/pgdoctor/check"
+	"github.com/fresha/pgdoctor/check/checktest"
 	"github.com/fresha/pgdoctor/checks/partitioning"
 	"github.com/fresha/pgdoctor/db"
 	"github.com/jackc/pgx/v5/pgtype"
@@ -14,8 +15,9 @@ import (
 
 // Mock queryer for testing.
 type mockQueryer struct {
-	tables []db.LargeTablesRow
-	err    error
+	tables      []db.LargeTablesRow
+	foreignKeys []db.PartitionedForeignKeysRow
+	err         error
 }
 
 func (m *mockQueryer) LargeTables(context.Context) ([]db.LargeTablesRow, error) {
@@ -25,6 +27,13 @@ func (m *mockQueryer) LargeTables(context.Context) ([]db.LargeTablesRow, error)
 	return m.tables, nil
 }
 
+func (m *mockQueryer) PartitionedForeignKeys(context.Context) ([]db.PartitionedForeignKeysRow, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	return m.foreignKeys, nil
+}
+
 func newMockQueryer(tables []db.LargeTablesRow) *mockQueryer {
 	return &mockQueryer{tables: tables}
 }
@@ -38,6 +47,8 @@ const (
 	findingIDLargeUnpartitioned     = "large-unpartitioned"
 	findingIDTransientUnpartitioned = "transient-unpartitioned"
 	findingIDInefficientPartitions  = "inefficient-partitions"
+	findingIDFKToPartitioned        = "fk-to-partitioned"
+	findingIDFKFromPartitioned      = "fk-from-partitioned"
 )
 
 // Helper to create a LargeTablesRow with common defaults.
@@ -96,8 +107,8 @@ func Test_Partitioning_NoLargeTables(t *testing.T) {
 	report, err := checker.Check(context.Background())
 	require.NoError(t, err)
 
-	// Should have 2 findings (large-unpartitioned and transient-unpartitioned)
-	require.Equal(t, 2, len(report.Results))
+	// Should have 4 findings (large-unpartitioned, transient-unpartitioned and both foreign key subchecks)
+	require.Equal(t, 4, len(report.Results))
 
 	// Both should be OK
 	for _, result := range report.Results {
@@ -120,7 +131,7 @@ func Test_Partitioning_AllPartitioned(t *testing.T) {
 	report, err := checker.Check(context.Background())
 	require.NoError(t, err)
 
-	require.Equal(t, 2, len(report.Results))
+	require.Equal(t, 4, len(report.Results))
 	for _, result := range report.Results {
 		require.Equal(t, check.SeverityOK, result.Severity)
 	}
@@ -224,7 +235,7 @@ func Test_Partitioning_MixedResults(t *testing.T) {
 	report, err := checker.Check(context.Background())
 	require.NoError(t, err)
 
-	require.Equal(t, 2, len(report.Results))
+	require.Equal(t, 4, len(report.Results))
 	require.Equal(t, check.SeverityFail, report.Severity)
 
 	// Check large-unpartitioned finding
@@ -449,7 +460,7 @@ func Test_Partitioning_ActivityAwareThresholds(t *testing.T) {
 			updates:          50_000,
 			deletes:          50_000,
 			expectedSeverity: check.SeverityWarn,
-			expectedReason:   "Insert-heavy",
+			expectedReason:   "append-only",
 		},
 		{
 			name:             "insert-heavy 25M - fail (would be warning without activity)",
@@ -458,7 +469,7 @@ func Test_Partitioning_ActivityAwareThresholds(t *testing.T) {
 			updates:          100_000,
 			deletes:          50_000,
 			expectedSeverity: check.SeverityFail,
-			expectedReason:   "Insert-heavy",
+			expectedReason:   "append-only",
 		},
 		{
 			name:             "high-delete 12M - warning (would be OK without activity)",
@@ -467,7 +478,7 @@ func Test_Partitioning_ActivityAwareThresholds(t *testing.T) {
 			updates:          50_000,
 			deletes:          25_000, // 25% delete ratio
 			expectedSeverity: check.SeverityWarn,
-			expectedReason:   "High-delete",
+			expectedReason:   "delete-heavy",
 		},
 		{
 			name:             "high-delete 30M - fail (would be warning without activity)",
@@ -476,7 +487,7 @@ func Test_Partitioning_ActivityAwareThresholds(t *testing.T) {
 			updates:          50_000,
 			deletes:          30_000, // 30% delete ratio
 			expectedSeverity: check.SeverityFail,
-			expectedReason:   "High-delete",
+			expectedReason:   "delete-heavy",
 		},
 		{
 			name:             "regular table 15M - OK (no activity-aware)",
@@ -530,3 +541,107 @@ func Test_Partitioning_ActivityAwareThresholds(t *testing.T) {
 		})
 	}
 }
+
+func makeForeignKey(name string, referencingPartitioned, referencedPartitioned, hasIndex bool, rows, writes int64) db.PartitionedForeignKeysRow {
+	return db.PartitionedForeignKeysRow{
+		ConstraintName:         name,
+		SchemaName:             "public",
+		TableName:              "order_items",
+		ReferencedSchema:       "public",
+		ReferencedTable:        "orders",
+		Columns:                []string{"order_id", "order_date"},
+		ReferencingPartitioned: referencingPartitioned,
+		ReferencedPartitioned:  referencedPartitioned,
+		OnDelete:               "c",
+		HasIndex:               hasIndex,
+		ReferencingRows:        rows,
+		ReferencedWrites:       writes,
+	}
+}
+
+func Test_Partitioning_ForeignKeyRisk(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		fk       db.PartitionedForeignKeysRow
+		finding  string
+		severity check.Severity
+	}{
+		{
+			name:     "indexed referencing columns",
+			fk:       makeForeignKey("fk_items_order", true, true, true, 500_000_000, 1_000_000),
+			finding:  findingIDFKToPartitioned,
+			severity: check.SeverityOK,
+		},
+		{
+			name:     "small unindexed table",
+			fk:       makeForeignKey("fk_items_order", false, true, false, 50_000, 1_000),
+			finding:  findingIDFKToPartitioned,
+			severity: check.SeverityOK,
+		},
+		{
+			name:     "unindexed medium table",
+			fk:       makeForeignKey("fk_items_order", false, true, false, 2_000_000, 1_000),
+			finding:  findingIDFKToPartitioned,
+			severity: check.SeverityWarn,
+		},
+		{
+			name:     "huge unindexed partitioned table with referenced deletes",
+			fk:       makeForeignKey("fk_items_order", true, false, false, 50_000_000, 10),
+			finding:  findingIDFKFromPartitioned,
+			severity: check.SeverityFail,
+		},
+		{
+			name:     "huge unindexed table but referenced side never changes",
+			fk:       makeForeignKey("fk_items_order", true, false, false, 50_000_000, 0),
+			finding:  findingIDFKFromPartitioned,
+			severity: check.SeverityWarn,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			queryer := &mockQueryer{foreignKeys: []db.PartitionedForeignKeysRow{tt.fk}}
+			report, err := partitioning.New(queryer).Check(context.Background())
+			require.NoError(t, err)
+
+			f := checktest.Finding(t, report, tt.finding)
+			require.Equal(t, tt.severity, f.Severity)
+			if tt.severity == check.SeverityOK {
+				require.Nil(t, f.Table)
+				return
+			}
+			require.Equal(t, check.ConfidenceMedium, f.Confidence)
+			require.Len(t, f.Table.Rows, 1)
+			require.Equal(t, "public.order_items (order_id, order_date)", f.Table.Rows[0].Cells[1])
+			require.Equal(t, "CASCADE", f.Table.Rows[0].Cells[5])
+			require.Len(t, f.Fixes, 1)
+		})
+	}
+}
+
+func Test_Partitioning_ForeignKeyFixes(t *testing.T) {
+	t.Parallel()
+
+	queryer := &mockQueryer{foreignKeys: []db.PartitionedForeignKeysRow{
+		makeForeignKey("fk_to", false, true, false, 1_000_000, 0),
+		makeForeignKey("fk_from", true, false, false, 1_000_000, 0),
+	}}
+	report, err := partitioning.New(queryer).Check(context.Background())
+	require.NoError(t, err)
+
+	to := checktest.Finding(t, report, findingIDFKToPartitioned)
+	require.Contains(t, to.Details, "Detaching or dropping a partition")
+	require.Equal(t, `CREATE INDEX CONCURRENTLY ON "public"."order_items" ("order_id", "order_date")`, to.Fixes[0].SQL)
+	require.Equal(t, check.RiskLow, to.Fixes[0].Risk)
+	require.Equal(t, check.LockOnline, to.Fixes[0].Lock)
+
+	from := checktest.Finding(t, report, findingIDFKFromPartitioned)
+	require.NotContains(t, from.Details, "Detaching")
+	require.Equal(t, `CREATE INDEX ON "public"."order_items" ("order_id", "order_date")`, from.Fixes[0].SQL)
+	require.Equal(t, check.RiskHigh, from.Fixes[0].Risk)
+	require.Equal(t, check.LockWrites, from.Fixes[0].Lock)
+}
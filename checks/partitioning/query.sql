@@ -31,3 +31,58 @@ WHERE
   c.relkind IN ('r', 'p')
   AND n.nspname NOT IN ('pg_catalog', 'information_schema', 'pg_toast', 'pgpartman', 'debezium', 'cron')
   AND COALESCE(s.n_live_tup, 0) >= 10000000;
+
+-- name: PartitionedForeignKeys :many
+-- Lists foreign keys where either side is a partitioned table, with what is
+-- needed to score how much work a DELETE or key UPDATE on the referenced side
+-- causes: whether the referencing columns are indexed, how many rows the
+-- referencing table holds across all partitions, and how many deletes and
+-- updates the referenced table has seen. Constraints cloned onto partitions
+-- (conparentid <> 0) are folded into their parent.
+SELECT
+  con.conname::text AS constraint_name
+  , rn.nspname::text AS schema_name
+  , r.relname::text AS table_name
+  , fn.nspname::text AS referenced_schema
+  , f.relname::text AS referenced_table
+  , (
+    SELECT array_agg(a.attname::text ORDER BY k.n)
+    FROM unnest(con.conkey) WITH ORDINALITY AS k (attnum, n)
+    INNER JOIN pg_catalog.pg_attribute AS a ON a.attrelid = con.conrelid AND k.attnum = a.attnum
+  )::text [] AS columns
+  , (r.relkind = 'p') AS referencing_partitioned
+  , (f.relkind = 'p') AS referenced_partitioned
+  , con.confdeltype::text AS on_delete
+  -- An index supports the key when its leading columns are exactly the
+  -- referencing columns; indkey is zero-based.
+  , EXISTS (
+    SELECT 1
+    FROM pg_catalog.pg_index AS x
+    WHERE
+      x.indrelid = con.conrelid
+      AND x.indisvalid
+      AND x.indpred IS NULL
+      AND (x.indkey::int2 [])[0:cardinality(con.conkey) - 1] @> con.conkey
+      AND (x.indkey::int2 [])[0:cardinality(con.conkey) - 1] <@ con.conkey
+  ) AS has_index
+  , (
+    SELECT coalesce(sum(s.n_live_tup), 0)
+    FROM pg_partition_tree(con.conrelid) AS t
+    INNER JOIN pg_stat_user_tables AS s ON t.relid = s.relid
+  )::bigint AS referencing_rows
+  , (
+    SELECT coalesce(sum(s.n_tup_del + s.n_tup_upd), 0)
+    FROM pg_partition_tree(con.confrelid) AS t
+    INNER JOIN pg_stat_user_tables AS s ON t.relid = s.relid
+  )::bigint AS referenced_writes
+FROM pg_catalog.pg_constraint AS con
+INNER JOIN pg_catalog.pg_class AS r ON con.conrelid = r.oid
+INNER JOIN pg_catalog.pg_namespace AS rn ON r.relnamespace = rn.oid
+INNER JOIN pg_catalog.pg_class AS f ON con.confrelid = f.oid
+INNER JOIN pg_catalog.pg_namespace AS fn ON f.relnamespace = fn.oid
+WHERE
+  con.contype = 'f'
+  AND con.conparentid = 0
+  AND (r.relkind = 'p' OR f.relkind = 'p')
+  AND rn.nspname NOT IN ('pg_catalog', 'information_schema')
+ORDER BY referencing_rows DESC, rn.nspname, r.relname, con.conname;
@@ -5,6 +5,7 @@ import (
 	"context"
 	_ "embed"
 	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/fresha/pgdoctor/check"
@@ -21,6 +22,8 @@ type PartitionUsageQueries interface {
 	HasPgStatStatements(context.Context) (bool, error)
 	PartitionedTablesWithKeys(context.Context) ([]db.PartitionedTablesWithKeysRow, error)
 	QueryStatsFromStatStatements(context.Context) ([]db.QueryStatsFromStatStatementsRow, error)
+	HasPgStatMonitor(context.Context) (bool, error)
+	QueryStatsFromStatMonitor(context.Context) ([]db.QueryStatsFromStatMonitorRow, error)
 }
 
 type checker struct {
@@ -29,12 +32,22 @@ type checker struct {
 
 func Metadata() check.Metadata {
 	return check.Metadata{
-		Category:    check.CategoryPerformance,
-		CheckID:     "partition-usage",
-		Name:        "Partition Key Usage",
-		Description: "Detects queries on partitioned tables that don't use partition keys",
-		Readme:      readme,
-		SQL:         querySQL,
+		Category:       check.CategoryPerformance,
+		CheckID:        "partition-usage",
+		Name:           "Partition Key Usage",
+		Description:    "Detects queries on partitioned tables that don't use partition keys",
+		Readme:         readme,
+		SQL:            querySQL,
+		RuntimeClass:   check.RuntimeHeavy,
+		ProductionSafe: true,
+		Privileges:     []check.Privilege{check.PrivilegeReadAllStats},
+		Findings: []check.FindingSpec{
+			{ID: "partition-key-unused", Description: "Frequent queries on partitioned tables that omit the partition key", Thresholds: "WARN > 100 calls or > 5m total time, FAIL > 1,000 calls or > 1h"},
+			{ID: "high-seq-scan-ratio", Description: "Partitioned tables scanned sequentially far more than by index (>= 1,000 seq scans)", Thresholds: "WARN > 10:1, FAIL > 100:1"},
+			{ID: "join-missing-partition-key", Description: "JOINs on partitioned tables without the partition key", Thresholds: "WARN > 100 calls or > 5m total time, FAIL > 1,000 calls or > 1h"},
+			{ID: "latency-outliers", Description: "Queries without the partition key whose worst-case latency far exceeds their mean (pg_stat_monitor only)", Thresholds: "WARN max >= 1s and >= 10x mean, FAIL max >= 10s"},
+			{ID: "extension-unavailable", Description: "Neither pg_stat_statements nor pg_stat_monitor is installed, so query-level subchecks are skipped", Thresholds: "WARN"},
+		},
 	}
 }
 
@@ -59,60 +72,92 @@ const (
 	minSeqScansWarn   = int64(1000)
 	seqToIdxRatioWarn = int64(10)
 	seqToIdxRatioFail = int64(100)
+
+	// Latency outlier thresholds (pg_stat_monitor only). A query that usually
+	// prunes to one partition but occasionally scans all of them shows a
+	// worst case far above its mean.
+	maxExecTimeWarnMs  = float64(1_000)
+	maxExecTimeFailMs  = float64(10_000)
+	maxToMeanRatioWarn = float64(10)
 )
 
 func (c *checker) Check(ctx context.Context) (*check.Report, error) {
 	report := check.NewReport(Metadata())
+	ignore := check.ObjectFilterFromContext(ctx)
 
 	partitionedTables, err := c.queries.PartitionedTablesWithKeys(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("running %s/%s (partitioned tables): %w", report.Category, report.CheckID, err)
 	}
+	partitionedTables = slices.DeleteFunc(partitionedTables, func(row db.PartitionedTablesWithKeysRow) bool {
+		return ignore.Table(row.SchemaName.String, row.TableName.String)
+	})
 
 	if len(partitionedTables) == 0 {
 		report.AddFinding(check.Finding{
-			ID:       "partition-key-unused",
-			Name:     "Partition Key Usage Analysis",
-			Severity: check.SeverityOK,
-			Details:  "No partitioned tables found",
+			ID:         "partition-key-unused",
+			Confidence: check.ConfidenceLow,
+			Name:       "Partition Key Usage Analysis",
+			Severity:   check.SeverityOK,
+			Details:    "No partitioned tables found",
 		})
 		return report, nil
 	}
 
 	checkSequentialScans(partitionedTables, report)
 
-	hasExtension, err := c.queries.HasPgStatStatements(ctx)
+	hasStatements, err := c.queries.HasPgStatStatements(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("checking pg_stat_statements extension: %w", err)
 	}
 
-	if !hasExtension {
-		report.AddFinding(check.Finding{
-			ID:       "extension-unavailable",
-			Name:     "pg_stat_statements Extension Not Available",
-			Severity: check.SeverityWarn,
-			Details:  fmt.Sprintf("Found %d partitioned table(s) but cannot analyze query patterns without pg_stat_statements extension", len(partitionedTables)),
-		})
+	var queryStats []db.QueryStatsFromStatStatementsRow
+	var monitorStats []db.QueryStatsFromStatMonitorRow
 
-		return report, nil
-	}
+	if hasStatements {
+		// Full query pattern analysis with pg_stat_statements
+		queryStats, err = c.queries.QueryStatsFromStatStatements(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("querying pg_stat_statements: %w", err)
+		}
+	} else {
+		hasMonitor, err := c.queries.HasPgStatMonitor(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("checking pg_stat_monitor extension: %w", err)
+		}
 
-	// Full query pattern analysis with pg_stat_statements
-	queryStats, err := c.queries.QueryStatsFromStatStatements(ctx)
-	if err != nil {
-		return nil, fmt.Errorf("querying pg_stat_statements: %w", err)
+		if !hasMonitor {
+			report.AddFinding(check.Finding{
+				ID:       "extension-unavailable",
+				Name:     "pg_stat_statements Extension Not Available",
+				Severity: check.SeverityWarn,
+				Details:  fmt.Sprintf("Found %d partitioned table(s) but cannot analyze query patterns without the pg_stat_statements or pg_stat_monitor extension", len(partitionedTables)),
+			})
+
+			return report, nil
+		}
+
+		monitorStats, err = c.queries.QueryStatsFromStatMonitor(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("querying pg_stat_monitor: %w", err)
+		}
+		queryStats = statementsFromMonitor(monitorStats)
 	}
 
 	if len(queryStats) == 0 {
 		report.AddFinding(check.Finding{
-			ID:       "partition-key-unused",
-			Name:     "Partition Key Usage Analysis",
-			Severity: check.SeverityOK,
-			Details:  "No query statistics available (pg_stat_statements may be empty)",
+			ID:         "partition-key-unused",
+			Confidence: check.ConfidenceLow,
+			Name:       "Partition Key Usage Analysis",
+			Severity:   check.SeverityOK,
+			Details:    "No query statistics available (pg_stat_statements may be empty)",
 		})
 	} else {
 		checkPartitionKeyUsage(partitionedTables, queryStats, report)
 		checkJoinsMissingPartitionKey(partitionedTables, queryStats, report)
+		if monitorStats != nil {
+			checkLatencyOutliers(partitionedTables, monitorStats, report)
+		}
 	}
 
 	return report, nil
@@ -198,10 +243,11 @@ func checkPartitionKeyUsage(
 
 	if len(tableRows) == 0 {
 		report.AddFinding(check.Finding{
-			ID:       "partition-key-unused",
-			Name:     "Partition Key Usage Analysis",
-			Severity: check.SeverityOK,
-			Details:  fmt.Sprintf("All queries on %d partitioned table(s) properly use partition keys", len(tables)),
+			ID:         "partition-key-unused",
+			Confidence: check.ConfidenceLow,
+			Name:       "Partition Key Usage Analysis",
+			Severity:   check.SeverityOK,
+			Details:    fmt.Sprintf("All queries on %d partitioned table(s) properly use partition keys", len(tables)),
 		})
 		return
 	}
@@ -212,10 +258,11 @@ func checkPartitionKeyUsage(
 	}
 
 	report.AddFinding(check.Finding{
-		ID:       "partition-key-unused",
-		Name:     "Partition Key Usage Analysis",
-		Severity: overallSeverity,
-		Details:  fmt.Sprintf("Found %d partitioned table(s) with queries not using partition key", len(tableRows)),
+		ID:         "partition-key-unused",
+		Confidence: check.ConfidenceLow,
+		Name:       "Partition Key Usage Analysis",
+		Severity:   overallSeverity,
+		Details:    fmt.Sprintf("Found %d partitioned table(s) with queries not using partition key", len(tableRows)),
 		Table: &check.Table{
 			Headers: []string{"Table", "Partition Key", "Partitions", "Problem Queries", "Total Calls", "Total Time"},
 			Rows:    tableRows,
@@ -223,6 +270,103 @@ func checkPartitionKeyUsage(
 	})
 }
 
+// statementsFromMonitor adapts pg_stat_monitor rows to the pg_stat_statements
+// shape so the pattern subchecks work with either extension.
+func statementsFromMonitor(rows []db.QueryStatsFromStatMonitorRow) []db.QueryStatsFromStatStatementsRow {
+	out := make([]db.QueryStatsFromStatStatementsRow, 0, len(rows))
+	for _, r := range rows {
+		out = append(out, db.QueryStatsFromStatStatementsRow{
+			QueryID:       r.QueryID,
+			Query:         r.Query,
+			Calls:         r.Calls,
+			TotalExecTime: r.TotalExecTime,
+			MeanExecTime:  r.MeanExecTime,
+			RowsReturned:  r.RowsReturned,
+		})
+	}
+	return out
+}
+
+// checkLatencyOutliers uses pg_stat_monitor's per-query worst case to find
+// queries on partitioned tables without the partition key whose latency
+// occasionally explodes, typically when they scan cold partitions.
+func checkLatencyOutliers(
+	tables []db.PartitionedTablesWithKeysRow,
+	queries []db.QueryStatsFromStatMonitorRow,
+	report *check.Report,
+) {
+	var tableRows []check.TableRow
+	severity := check.SeverityOK
+
+	for _, table := range tables {
+		if table.HasExpressionKey.Valid && table.HasExpressionKey.Bool {
+			continue
+		}
+		if !table.PartitionKeyColumns.Valid || table.PartitionKeyColumns.String == "" {
+			continue
+		}
+
+		partitionKeys := strings.Split(table.PartitionKeyColumns.String, ",")
+		schemaName := table.SchemaName.String
+		tableName := table.TableName.String
+
+		for _, q := range queries {
+			queryText := strings.ToLower(q.Query.String)
+			if !queryReferencesTable(queryText, schemaName, tableName) || queryUsesPartitionKey(queryText, partitionKeys) {
+				continue
+			}
+
+			maxMs := q.MaxExecTime.Float64
+			meanMs := q.MeanExecTime.Float64
+			if maxMs < maxExecTimeWarnMs || meanMs <= 0 || maxMs/meanMs < maxToMeanRatioWarn {
+				continue
+			}
+
+			rowSeverity := check.SeverityWarn
+			if maxMs >= maxExecTimeFailMs {
+				rowSeverity = check.SeverityFail
+			}
+			severity = max(severity, rowSeverity)
+
+			tableRows = append(tableRows, check.TableRow{
+				Cells: []string{
+					fmt.Sprintf("%s.%s", schemaName, tableName),
+					q.Query.String,
+					fmt.Sprintf("%d", q.Calls.Int64),
+					check.FormatDurationMs(meanMs),
+					check.FormatDurationMs(maxMs),
+				},
+				Severity: rowSeverity,
+			})
+		}
+	}
+
+	if len(tableRows) == 0 {
+		report.AddFinding(check.Finding{
+			ID:         "latency-outliers",
+			Confidence: check.ConfidenceLow,
+			Name:       "Partitioned Query Latency Outliers",
+			Severity:   check.SeverityOK,
+			Details:    "No queries on partitioned tables with extreme worst-case latency",
+		})
+		return
+	}
+
+	report.AddFinding(check.Finding{
+		ID:         "latency-outliers",
+		Confidence: check.ConfidenceLow,
+		Name:       "Partitioned Query Latency Outliers",
+		Severity:   severity,
+		Details: fmt.Sprintf("Found %d query(ies) without the partition key whose worst case is at least %.0fx their mean.\n"+
+			"Queries that cannot prune partitions are fast while data is cached and slow when they reach cold partitions.",
+			len(tableRows), maxToMeanRatioWarn),
+		Table: &check.Table{
+			Headers: []string{"Table", "Query", "Calls", "Mean", "Max"},
+			Rows:    tableRows,
+		},
+	})
+}
+
 // queryReferencesTable checks if a query text references a specific table.
 func queryReferencesTable(queryText, schemaName, tableName string) bool {
 	patterns := []string{
@@ -370,10 +514,11 @@ func checkJoinsMissingPartitionKey(
 	}
 
 	report.AddFinding(check.Finding{
-		ID:       "join-missing-partition-key",
-		Name:     "JOINs Missing Partition Key",
-		Severity: overallSeverity,
-		Details:  fmt.Sprintf("Found %d partitioned table(s) with JOINs not using partition key", len(tableRows)),
+		ID:         "join-missing-partition-key",
+		Confidence: check.ConfidenceLow,
+		Name:       "JOINs Missing Partition Key",
+		Severity:   overallSeverity,
+		Details:    fmt.Sprintf("Found %d partitioned table(s) with JOINs not using partition key", len(tableRows)),
 		Table: &check.Table{
 			Headers: []string{"Table", "Partition Key", "Problem JOINs", "Total Calls", "Total Time"},
 			Rows:    tableRows,
@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/fresha/pgdoctor/check"
+	"github.com/fresha/pgdoctor/check/checktest"
 	"github.com/fresha/pgdoctor/checks/partitionusage"
 	"github.com/fresha/pgdoctor/db"
 	"github.com/jackc/pgx/v5/pgtype"
@@ -28,6 +29,9 @@ type mockQueryer struct {
 	tablesErr    error
 	statsErr     error
 	extensionErr error
+
+	hasMonitor   bool
+	monitorStats []db.QueryStatsFromStatMonitorRow
 }
 
 func (m *mockQueryer) HasPgStatStatements(context.Context) (bool, error) {
@@ -55,6 +59,14 @@ func (m *mockQueryer) QueryStatsFromStatStatements(context.Context) ([]db.QueryS
 	return m.queryStats, nil
 }
 
+func (m *mockQueryer) HasPgStatMonitor(context.Context) (bool, error) {
+	return m.hasMonitor, nil
+}
+
+func (m *mockQueryer) QueryStatsFromStatMonitor(context.Context) ([]db.QueryStatsFromStatMonitorRow, error) {
+	return m.monitorStats, nil
+}
+
 // Helper to create a PartitionedTablesWithKeysRow.
 func makePartitionedTable(schema, name, partitionKey string, partitionCount int64) db.PartitionedTablesWithKeysRow {
 	return db.PartitionedTablesWithKeysRow{
@@ -683,3 +695,65 @@ func Test_PartitionUsage_JoinMissingPartitionKey_Fail(t *testing.T) {
 	require.NotNil(t, joinFinding)
 	require.Equal(t, check.SeverityFail, joinFinding.Severity)
 }
+
+func makeMonitorStats(query string, calls int64, meanMs, maxMs float64) db.QueryStatsFromStatMonitorRow {
+	return db.QueryStatsFromStatMonitorRow{
+		QueryID:       pgtype.Int8{Int64: 12345, Valid: true},
+		Query:         pgtype.Text{String: query, Valid: true},
+		Calls:         pgtype.Int8{Int64: calls, Valid: true},
+		TotalExecTime: pgtype.Float8{Float64: meanMs * float64(calls), Valid: true},
+		MeanExecTime:  pgtype.Float8{Float64: meanMs, Valid: true},
+		MaxExecTime:   pgtype.Float8{Float64: maxMs, Valid: true},
+		RowsReturned:  pgtype.Int8{Int64: calls, Valid: true},
+	}
+}
+
+func Test_PartitionUsage_PgStatMonitorFallback(t *testing.T) {
+	t.Parallel()
+
+	hasExtFalse := false
+	queryer := &mockQueryer{
+		tables: []db.PartitionedTablesWithKeysRow{
+			makePartitionedTable("public", "events", "created_at", 12),
+		},
+		hasExtension: &hasExtFalse,
+		hasMonitor:   true,
+		monitorStats: []db.QueryStatsFromStatMonitorRow{
+			makeMonitorStats("SELECT * FROM events WHERE user_id = $1", 5000, 20, 15_000),
+			makeMonitorStats("SELECT * FROM events WHERE account_id = $1", 200, 50, 100),
+			makeMonitorStats("SELECT * FROM events WHERE created_at > $1", 5000, 5, 2_000),
+		},
+	}
+
+	report, err := partitionusage.New(queryer).Check(context.Background())
+	require.NoError(t, err)
+
+	checktest.NoFinding(t, report, findingIDExtensionUnavailable)
+
+	// pg_stat_monitor rows feed the existing pattern analysis.
+	keyUnused := checktest.Finding(t, report, findingIDPartitionKeyUnused)
+	require.Equal(t, check.SeverityFail, keyUnused.Severity)
+
+	// Only the query with a heavy tail and no partition key is an outlier.
+	outliers := checktest.Finding(t, report, "latency-outliers")
+	require.Equal(t, check.SeverityFail, outliers.Severity)
+	require.Len(t, outliers.Table.Rows, 1)
+	require.Contains(t, outliers.Table.Rows[0].Cells[1], "user_id")
+}
+
+func Test_PartitionUsage_LatencyOutliersOnlyWithMonitor(t *testing.T) {
+	t.Parallel()
+
+	queryer := &mockQueryer{
+		tables: []db.PartitionedTablesWithKeysRow{
+			makePartitionedTable("public", "events", "created_at", 12),
+		},
+		queryStats: []db.QueryStatsFromStatStatementsRow{
+			makeQueryStats("SELECT * FROM events WHERE user_id = $1", 5000, 100_000),
+		},
+	}
+
+	report, err := partitionusage.New(queryer).Check(context.Background())
+	require.NoError(t, err)
+	checktest.NoFinding(t, report, "latency-outliers")
+}
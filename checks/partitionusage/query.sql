@@ -72,3 +72,34 @@ WHERE
   AND (query ILIKE '%SELECT%' OR query ILIKE '%UPDATE%' OR query ILIKE '%DELETE%')
 ORDER BY total_exec_time DESC
 LIMIT 500;
+
+-- name: HasPgStatMonitor :one
+-- Checks if the pg_stat_monitor extension (Percona) is installed.
+SELECT EXISTS(
+  SELECT 1 FROM pg_extension
+  WHERE extname = 'pg_stat_monitor'
+);
+
+-- name: QueryStatsFromStatMonitor :many
+-- Gets query statistics from pg_stat_monitor, aggregated across its time buckets.
+-- Used when pg_stat_statements is not installed; adds worst-case latency per query.
+SELECT
+  queryid::bigint AS query_id
+  , LEFT(REGEXP_REPLACE(MIN(query), '\s+', ' ', 'g'), 80)::text AS query
+  , SUM(calls)::bigint AS calls
+  , SUM(total_exec_time)::double precision AS total_exec_time
+  , (SUM(total_exec_time) / NULLIF(SUM(calls), 0))::double precision AS mean_exec_time
+  , MAX(max_exec_time)::double precision AS max_exec_time
+  , SUM(rows)::bigint AS rows_returned
+FROM pg_stat_monitor
+WHERE
+  query NOT LIKE 'COPY%'
+  AND query NOT LIKE 'SET %'
+  AND query !~ '^(BEGIN|COMMIT|ROLLBACK|SAVEPOINT|PREPARE|DEALLOCATE)'
+  AND query !~ '^(VACUUM|ANALYZE|REINDEX|CLUSTER)'
+  AND query !~ '^(CREATE|DROP|ALTER|TRUNCATE)'
+  AND (query ILIKE '%SELECT%' OR query ILIKE '%UPDATE%' OR query ILIKE '%DELETE%')
+GROUP BY queryid
+HAVING SUM(calls) > 10
+ORDER BY SUM(total_exec_time) DESC
+LIMIT 500;
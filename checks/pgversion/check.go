@@ -26,12 +26,17 @@ type checker struct {
 
 func Metadata() check.Metadata {
 	return check.Metadata{
-		Category:    check.CategoryConfigs,
-		CheckID:     "pg-version",
-		Name:        "PostgreSQL Version",
-		Description: "Checks if PostgreSQL version is supported and up to date",
-		Readme:      readme,
-		SQL:         querySQL,
+		Category:       check.CategoryConfigs,
+		CheckID:        "pg-version",
+		Name:           "PostgreSQL Version",
+		Description:    "Checks if PostgreSQL version is supported and up to date",
+		Readme:         readme,
+		SQL:            querySQL,
+		RuntimeClass:   check.RuntimeFast,
+		ProductionSafe: true,
+		Findings: []check.FindingSpec{
+			{ID: "pg-version", Description: "Server major version is still supported", Thresholds: "FAIL < 14"},
+		},
 	}
 }
 
@@ -5,6 +5,7 @@ import (
 	"context"
 	_ "embed"
 	"fmt"
+	"slices"
 
 	"github.com/fresha/pgdoctor/check"
 	"github.com/fresha/pgdoctor/db"
@@ -30,12 +31,18 @@ const (
 
 func Metadata() check.Metadata {
 	return check.Metadata{
-		Category:    check.CategorySchema,
-		CheckID:     "pk-types",
-		Name:        "Primary Key Type Validation",
-		Description: "Validates primary keys use bigint or UUID for sufficient growth capacity",
-		Readme:      readme,
-		SQL:         querySQL,
+		Category:       check.CategorySchema,
+		CheckID:        "pk-types",
+		Name:           "Primary Key Type Validation",
+		Description:    "Validates primary keys use bigint or UUID for sufficient growth capacity",
+		Readme:         readme,
+		SQL:            querySQL,
+		RuntimeClass:   check.RuntimeHeavy,
+		ProductionSafe: true,
+		Privileges:     []check.Privilege{check.PrivilegeReadSchemas},
+		Findings: []check.FindingSpec{
+			{ID: "pk-types", Description: "Integer primary keys approaching the limit of their type", Thresholds: "FAIL >= 50% of capacity"},
+		},
 	}
 }
 
@@ -51,11 +58,15 @@ func (c *checker) Metadata() check.Metadata {
 
 func (c *checker) Check(ctx context.Context) (*check.Report, error) {
 	report := check.NewReport(Metadata())
+	ignore := check.ObjectFilterFromContext(ctx)
 
 	rows, err := c.queries.InvalidPrimaryKeyTypes(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to check primary key types: %w", err)
 	}
+	rows = slices.DeleteFunc(rows, func(row db.InvalidPrimaryKeyTypesRow) bool {
+		return ignore.QualifiedTable(row.TableName.String)
+	})
 
 	if len(rows) == 0 {
 		report.AddFinding(check.Finding{
@@ -0,0 +1,158 @@
+// Package preparedxacts implements checks for prepared (two-phase)
+// transactions left uncommitted.
+package preparedxacts
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+
+	"github.com/fresha/pgdoctor/check"
+	"github.com/fresha/pgdoctor/db"
+)
+
+//go:embed query.sql
+var querySQL string
+
+//go:embed README.md
+var readme string
+
+const (
+	// A transaction manager normally commits or rolls back a prepared
+	// transaction within seconds. One left this long has likely been orphaned.
+	staleWarnSeconds = 5 * 60.0  // 5m
+	staleFailSeconds = 60 * 60.0 // 1h
+)
+
+type PreparedXactsQueries interface {
+	PreparedXacts(context.Context) ([]db.PreparedXactsRow, error)
+}
+
+type checker struct {
+	queries   PreparedXactsQueries
+	staleWarn float64
+	staleFail float64
+}
+
+func Metadata() check.Metadata {
+	return check.Metadata{
+		Category:       check.CategoryVacuum,
+		CheckID:        "prepared-xacts",
+		Name:           "Prepared Transactions",
+		Description:    "Detects prepared (two-phase) transactions left uncommitted, which hold locks and block vacuum until resolved",
+		Readme:         readme,
+		SQL:            querySQL,
+		RuntimeClass:   check.RuntimeFast,
+		ProductionSafe: true,
+		Priority:       check.PriorityCritical,
+		Findings: []check.FindingSpec{
+			{ID: "stale-prepared-xacts", Description: "Entries in pg_prepared_xacts older than the threshold", Thresholds: "WARN >= 5m, FAIL >= 1h"},
+		},
+	}
+}
+
+func New(queries PreparedXactsQueries, cfg ...check.Config) check.Checker {
+	c := &checker{
+		queries:   queries,
+		staleWarn: staleWarnSeconds,
+		staleFail: staleFailSeconds,
+	}
+	if len(cfg) > 0 {
+		checkID := Metadata().CheckID
+		c.staleWarn = cfg[0].Float(checkID, "stale_warn_seconds", staleWarnSeconds)
+		c.staleFail = max(cfg[0].Float(checkID, "stale_fail_seconds", staleFailSeconds), c.staleWarn)
+	}
+	return c
+}
+
+func (c *checker) Metadata() check.Metadata {
+	return Metadata()
+}
+
+func (c *checker) Check(ctx context.Context) (*check.Report, error) {
+	report := check.NewReport(Metadata())
+
+	rows, err := c.queries.PreparedXacts(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("running %s/%s: %w", report.Category, report.CheckID, err)
+	}
+
+	observeOldest(ctx, rows, c.staleWarn, c.staleFail)
+	checkStalePreparedXacts(rows, c.staleWarn, c.staleFail, report)
+
+	return report, nil
+}
+
+// observeOldest reports the age of the oldest prepared transaction for calibration.
+func observeOldest(ctx context.Context, rows []db.PreparedXactsRow, warn, fail float64) {
+	var oldest float64
+	for _, row := range rows {
+		oldest = max(oldest, row.AgeSeconds)
+	}
+	check.Observe(ctx, check.Observation{
+		CheckID:   Metadata().CheckID,
+		FindingID: "stale-prepared-xacts",
+		Key:       "stale_warn_seconds",
+		Value:     oldest,
+		Warn:      warn,
+		Fail:      fail,
+	})
+}
+
+func checkStalePreparedXacts(rows []db.PreparedXactsRow, warnSeconds, failSeconds float64, report *check.Report) {
+	var tableRows []check.TableRow
+	severity := check.SeverityOK
+	var locks int64
+	for _, row := range rows {
+		if row.AgeSeconds < warnSeconds {
+			continue
+		}
+		rowSeverity := check.SeverityWarn
+		if row.AgeSeconds >= failSeconds {
+			rowSeverity = check.SeverityFail
+		}
+		severity = max(severity, rowSeverity)
+		locks += row.LockCount
+
+		tableRows = append(tableRows, check.TableRow{
+			Cells: []string{
+				row.Gid,
+				row.Owner,
+				row.DatabaseName,
+				check.FormatDurationSec(int64(row.AgeSeconds)),
+				check.FormatNumber(row.XidAge),
+				check.FormatNumber(row.LockCount),
+			},
+			Severity: rowSeverity,
+		})
+	}
+
+	if len(tableRows) == 0 {
+		details := "No prepared transactions"
+		if len(rows) > 0 {
+			details = fmt.Sprintf("%d prepared transaction(s), all younger than %s", len(rows), check.FormatDurationSec(int64(warnSeconds)))
+		}
+		report.AddFinding(check.Finding{
+			ID:       "stale-prepared-xacts",
+			Name:     "Stale Prepared Transactions",
+			Severity: check.SeverityOK,
+			Details:  details,
+		})
+		return
+	}
+
+	report.AddFinding(check.Finding{
+		ID:       "stale-prepared-xacts",
+		Name:     "Stale Prepared Transactions",
+		Severity: severity,
+		Details: fmt.Sprintf("%d prepared transaction(s) have waited %s or more for COMMIT PREPARED or ROLLBACK PREPARED, holding %d lock(s). "+
+			"A prepared transaction survives disconnects and restarts: until resolved it keeps its locks and holds back the xmin horizon, "+
+			"so vacuum cannot remove dead rows or freeze anywhere in the cluster. Ask the transaction manager that owns the GID how it ended; "+
+			"if it is gone, resolve the entry by hand while connected to its database",
+			len(tableRows), check.FormatDurationSec(int64(warnSeconds)), locks),
+		Table: &check.Table{
+			Headers: []string{"GID", "Owner", "Database", "Age", "XID Age", "Locks"},
+			Rows:    tableRows,
+		},
+	})
+}
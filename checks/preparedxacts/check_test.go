@@ -0,0 +1,140 @@
+package preparedxacts_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/fresha/pgdoctor/check"
+	"github.com/fresha/pgdoctor/checks/preparedxacts"
+	"github.com/fresha/pgdoctor/db"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockQueryer struct {
+	rows []db.PreparedXactsRow
+	err  error
+}
+
+func (m *mockQueryer) PreparedXacts(context.Context) ([]db.PreparedXactsRow, error) {
+	return m.rows, m.err
+}
+
+func preparedXact(gid string, ageSeconds float64) db.PreparedXactsRow {
+	return db.PreparedXactsRow{
+		Gid:          gid,
+		Owner:        "app",
+		DatabaseName: "orders",
+		AgeSeconds:   ageSeconds,
+		XidAge:       1500000,
+		LockCount:    3,
+	}
+}
+
+func TestPreparedXacts_None(t *testing.T) {
+	t.Parallel()
+
+	report, err := preparedxacts.New(&mockQueryer{}).Check(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, check.SeverityOK, report.Severity)
+	require.Len(t, report.Results, 1)
+	assert.Equal(t, "No prepared transactions", report.Results[0].Details)
+}
+
+func TestPreparedXacts_Age(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		ages     []float64
+		cfg      []check.Config
+		expected check.Severity
+		listed   int
+	}{
+		{name: "in flight", ages: []float64{2}, expected: check.SeverityOK},
+		{name: "stale", ages: []float64{2, 600}, expected: check.SeverityWarn, listed: 1},
+		{name: "orphaned", ages: []float64{600, 86400}, expected: check.SeverityFail, listed: 2},
+		{
+			name:     "raised threshold",
+			ages:     []float64{600},
+			cfg:      []check.Config{{"prepared-xacts": {"stale_warn_seconds": "900"}}},
+			expected: check.SeverityOK,
+		},
+		{
+			name:     "fail below warn is raised",
+			ages:     []float64{600},
+			cfg:      []check.Config{{"prepared-xacts": {"stale_warn_seconds": "300", "stale_fail_seconds": "60"}}},
+			expected: check.SeverityFail,
+			listed:   1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var rows []db.PreparedXactsRow
+			for i, age := range tt.ages {
+				rows = append(rows, preparedXact(string(rune('a'+i)), age))
+			}
+
+			report, err := preparedxacts.New(&mockQueryer{rows: rows}, tt.cfg...).Check(context.Background())
+			require.NoError(t, err)
+
+			f := report.Results[0]
+			assert.Equal(t, tt.expected, f.Severity)
+			if tt.listed == 0 {
+				assert.Nil(t, f.Table)
+				return
+			}
+			require.NotNil(t, f.Table)
+			assert.Len(t, f.Table.Rows, tt.listed)
+			assert.Contains(t, f.Details, "holding")
+		})
+	}
+}
+
+func TestPreparedXacts_TableContents(t *testing.T) {
+	t.Parallel()
+
+	q := &mockQueryer{rows: []db.PreparedXactsRow{preparedXact("xa:1234", 7200)}}
+
+	report, err := preparedxacts.New(q).Check(context.Background())
+	require.NoError(t, err)
+
+	f := report.Results[0]
+	require.NotNil(t, f.Table)
+	assert.Equal(t, []string{"GID", "Owner", "Database", "Age", "XID Age", "Locks"}, f.Table.Headers)
+	assert.Equal(t, "xa:1234", f.Table.Rows[0].Cells[0])
+	assert.Equal(t, "app", f.Table.Rows[0].Cells[1])
+	assert.Equal(t, "orders", f.Table.Rows[0].Cells[2])
+	assert.Equal(t, check.SeverityFail, f.Table.Rows[0].Severity)
+	assert.Empty(t, f.Fixes)
+}
+
+func TestPreparedXacts_Observe(t *testing.T) {
+	t.Parallel()
+
+	var observed []check.Observation
+	ctx := check.ContextWithObserver(context.Background(), func(o check.Observation) {
+		observed = append(observed, o)
+	})
+
+	q := &mockQueryer{rows: []db.PreparedXactsRow{preparedXact("a", 30), preparedXact("b", 120)}}
+	_, err := preparedxacts.New(q).Check(ctx)
+	require.NoError(t, err)
+
+	require.Len(t, observed, 1)
+	assert.Equal(t, "stale_warn_seconds", observed[0].Key)
+	assert.InDelta(t, 120.0, observed[0].Value, 0.001)
+}
+
+func TestPreparedXacts_QueryError(t *testing.T) {
+	t.Parallel()
+
+	_, err := preparedxacts.New(&mockQueryer{err: errors.New("connection refused")}).Check(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "prepared-xacts")
+}
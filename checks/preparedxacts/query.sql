@@ -0,0 +1,16 @@
+-- name: PreparedXacts :many
+-- Prepared (two-phase) transactions across all databases, oldest first, with
+-- the XID age they pin and the locks they still hold.
+SELECT
+  p.gid::text AS gid
+  , p.owner::text AS owner
+  , p.database::text AS database_name
+  , EXTRACT(EPOCH FROM (NOW() - p.prepared))::float8 AS age_seconds
+  , AGE(p.transaction)::bigint AS xid_age
+  , (
+    SELECT COUNT(*)
+    FROM pg_locks AS l
+    WHERE l.pid IS NULL AND l.virtualtransaction = '-1/' || p.transaction::text
+  )::bigint AS lock_count
+FROM pg_prepared_xacts AS p
+ORDER BY p.prepared;
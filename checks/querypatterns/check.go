@@ -0,0 +1,252 @@
+// Package querypatterns implements checks for costly query shapes seen in
+// pg_stat_statements, or pg_stat_monitor when that is installed instead.
+package querypatterns
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+
+	"github.com/fresha/pgdoctor/check"
+	"github.com/fresha/pgdoctor/db"
+)
+
+//go:embed query.sql
+var querySQL string
+
+//go:embed README.md
+var readme string
+
+const (
+	// Bind parameters per statement. Each one is parsed, type-resolved and
+	// planned separately, and every IN-list length is a distinct statement
+	// to the plan cache and pg_stat_statements.
+	paramCountWarn = int32(100)
+	paramCountFail = int32(1000)
+
+	// Mean rows per call above which an `= ANY($n)` lookup is assumed to be
+	// fed a large generated array.
+	anyArrayRowsPerCall = int64(1000)
+)
+
+type QueryPatternsQueries interface {
+	StatStatementsAvailable(context.Context) (bool, error)
+	LargeParameterQueries(context.Context) ([]db.LargeParameterQueriesRow, error)
+	HasPgStatMonitor(context.Context) (bool, error)
+	StatMonitorNormalized(context.Context) (bool, error)
+	LargeParameterQueriesFromStatMonitor(context.Context) ([]db.LargeParameterQueriesFromStatMonitorRow, error)
+}
+
+type checker struct {
+	queries QueryPatternsQueries
+}
+
+func Metadata() check.Metadata {
+	return check.Metadata{
+		Category:       check.CategoryPerformance,
+		CheckID:        "query-patterns",
+		Name:           "Query Patterns",
+		Description:    "Detects queries with huge IN lists or ANY($1) arrays that inflate parse, plan, and plan cache overhead",
+		Readme:         readme,
+		SQL:            querySQL,
+		RuntimeClass:   check.RuntimeMedium,
+		ProductionSafe: true,
+		Privileges:     []check.Privilege{check.PrivilegeReadAllStats},
+		Findings: []check.FindingSpec{
+			{ID: "large-parameter-lists", Description: "Statements with hundreds of bind parameters, typically generated IN lists (requires pg_stat_statements or pg_stat_monitor)", Thresholds: "WARN >= 100 parameters, FAIL >= 1,000"},
+			{ID: "large-any-arrays", Description: "`= ANY($n)` lookups returning >= 1,000 rows per call, suggesting huge generated arrays (requires pg_stat_statements or pg_stat_monitor)", Thresholds: "WARN"},
+		},
+	}
+}
+
+func New(queries QueryPatternsQueries, _ ...check.Config) check.Checker {
+	return &checker{
+		queries: queries,
+	}
+}
+
+func (c *checker) Metadata() check.Metadata {
+	return Metadata()
+}
+
+func (c *checker) Check(ctx context.Context) (*check.Report, error) {
+	report := check.NewReport(Metadata())
+
+	rows, skipped, err := c.statements(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("running %s/%s: %w", report.Category, report.CheckID, err)
+	}
+	if skipped != "" {
+		for _, spec := range Metadata().Findings {
+			report.AddFinding(check.Finding{
+				ID:       spec.ID,
+				Name:     findingName(spec.ID),
+				Severity: check.SeverityOK,
+				Details:  skipped,
+			})
+		}
+		return report, nil
+	}
+
+	checkLargeParameterLists(rows, report)
+	checkLargeAnyArrays(rows, report)
+
+	return report, nil
+}
+
+// statements reads candidate statements from pg_stat_statements, or from
+// pg_stat_monitor when only that is installed. skipped explains why neither
+// could be used.
+func (c *checker) statements(ctx context.Context) (rows []db.LargeParameterQueriesRow, skipped string, err error) {
+	available, err := c.queries.StatStatementsAvailable(ctx)
+	if err != nil {
+		return nil, "", fmt.Errorf("checking pg_stat_statements extension: %w", err)
+	}
+	if available {
+		rows, err = c.queries.LargeParameterQueries(ctx)
+		if err != nil {
+			return nil, "", fmt.Errorf("querying pg_stat_statements: %w", err)
+		}
+		return rows, "", nil
+	}
+
+	hasMonitor, err := c.queries.HasPgStatMonitor(ctx)
+	if err != nil {
+		return nil, "", fmt.Errorf("checking pg_stat_monitor extension: %w", err)
+	}
+	if !hasMonitor {
+		return nil, "Analyzing query patterns needs the pg_stat_statements or pg_stat_monitor extension, neither of which is installed", nil
+	}
+	normalized, err := c.queries.StatMonitorNormalized(ctx)
+	if err != nil {
+		return nil, "", fmt.Errorf("checking pg_stat_monitor settings: %w", err)
+	}
+	if !normalized {
+		return nil, "pg_stat_monitor records literal values rather than $n parameters, so parameter lists cannot be counted. " +
+			"Set pg_stat_monitor.pgsm_normalized_query = on to analyze query patterns", nil
+	}
+
+	monitorRows, err := c.queries.LargeParameterQueriesFromStatMonitor(ctx)
+	if err != nil {
+		return nil, "", fmt.Errorf("querying pg_stat_monitor: %w", err)
+	}
+	rows = make([]db.LargeParameterQueriesRow, len(monitorRows))
+	for i, row := range monitorRows {
+		rows[i] = db.LargeParameterQueriesRow(row)
+	}
+	return rows, "", nil
+}
+
+func findingName(id string) string {
+	switch id {
+	case "large-parameter-lists":
+		return "Large Parameter Lists"
+	case "large-any-arrays":
+		return "Large ANY Arrays"
+	}
+	return id
+}
+
+func meanMs(total float64, calls int64) float64 {
+	if calls == 0 {
+		return 0
+	}
+	return total / float64(calls)
+}
+
+func checkLargeParameterLists(rows []db.LargeParameterQueriesRow, report *check.Report) {
+	var tableRows []check.TableRow
+	severity := check.SeverityOK
+
+	for _, row := range rows {
+		if row.ParamCount < paramCountWarn {
+			continue
+		}
+		rowSeverity := check.SeverityWarn
+		if row.ParamCount >= paramCountFail {
+			rowSeverity = check.SeverityFail
+		}
+		severity = max(severity, rowSeverity)
+
+		tableRows = append(tableRows, check.TableRow{
+			Cells: []string{
+				row.Query,
+				check.FormatNumber(int64(row.ParamCount)),
+				check.FormatNumber(row.Variants),
+				check.FormatNumber(row.Calls),
+				check.FormatDurationMs(meanMs(row.TotalPlanTime, row.Calls)),
+				check.FormatDurationMs(meanMs(row.TotalExecTime, row.Calls)),
+			},
+			Severity: rowSeverity,
+		})
+	}
+
+	if len(tableRows) == 0 {
+		report.AddFinding(check.Finding{
+			ID:       "large-parameter-lists",
+			Name:     findingName("large-parameter-lists"),
+			Severity: check.SeverityOK,
+			Details:  fmt.Sprintf("No frequent statements with %d or more bind parameters", paramCountWarn),
+		})
+		return
+	}
+
+	report.AddFinding(check.Finding{
+		ID:       "large-parameter-lists",
+		Name:     findingName("large-parameter-lists"),
+		Severity: severity,
+		Details: fmt.Sprintf("%d statement(s) bind %d or more parameters, usually a generated IN ($1, $2, ...) list. "+
+			"Every parameter is parsed and planned on each execution, and each list length is a separate prepared statement and pg_stat_statements entry (Variants). "+
+			"Pass the values as one array with = ANY($1::bigint[]), join against unnest($1) or a temp table, or batch the list",
+			len(tableRows), paramCountWarn),
+		Table: &check.Table{
+			Headers: []string{"Query", "Params", "Variants", "Calls", "Mean Plan", "Mean Exec"},
+			Rows:    tableRows,
+		},
+	})
+}
+
+func checkLargeAnyArrays(rows []db.LargeParameterQueriesRow, report *check.Report) {
+	var tableRows []check.TableRow
+
+	for _, row := range rows {
+		if !row.AnyArray || row.Calls == 0 || row.RowsReturned/row.Calls < anyArrayRowsPerCall {
+			continue
+		}
+		tableRows = append(tableRows, check.TableRow{
+			Cells: []string{
+				row.Query,
+				check.FormatNumber(row.Calls),
+				check.FormatNumber(row.RowsReturned / row.Calls),
+				check.FormatDurationMs(meanMs(row.TotalExecTime, row.Calls)),
+			},
+			Severity: check.SeverityWarn,
+		})
+	}
+
+	if len(tableRows) == 0 {
+		report.AddFinding(check.Finding{
+			ID:         "large-any-arrays",
+			Confidence: check.ConfidenceLow,
+			Name:       findingName("large-any-arrays"),
+			Severity:   check.SeverityOK,
+			Details:    "No = ANY($n) lookups return large result sets per call",
+		})
+		return
+	}
+
+	report.AddFinding(check.Finding{
+		ID:         "large-any-arrays",
+		Confidence: check.ConfidenceLow,
+		Name:       findingName("large-any-arrays"),
+		Severity:   check.SeverityWarn,
+		Details: fmt.Sprintf("%d = ANY($n) lookup(s) return %d or more rows per call, which usually means arrays with thousands of elements. "+
+			"The planner estimates selectivity element by element and probes the index once per element; "+
+			"for large sets load the keys into a temp table (or JOIN (VALUES ...)) and ANALYZE it so the join is planned with real row counts",
+			len(tableRows), anyArrayRowsPerCall),
+		Table: &check.Table{
+			Headers: []string{"Query", "Calls", "Rows/Call", "Mean Exec"},
+			Rows:    tableRows,
+		},
+	})
+}
@@ -0,0 +1,192 @@
+package querypatterns_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/fresha/pgdoctor/check"
+	"github.com/fresha/pgdoctor/check/checktest"
+	"github.com/fresha/pgdoctor/checks/querypatterns"
+	"github.com/fresha/pgdoctor/db"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockQueryer struct {
+	available bool
+	rows      []db.LargeParameterQueriesRow
+	err       error
+
+	monitor           bool
+	monitorNormalized bool
+	monitorRows       []db.LargeParameterQueriesFromStatMonitorRow
+
+	rowsCalled bool
+}
+
+func (m *mockQueryer) StatStatementsAvailable(context.Context) (bool, error) {
+	return m.available, m.err
+}
+
+func (m *mockQueryer) LargeParameterQueries(context.Context) ([]db.LargeParameterQueriesRow, error) {
+	m.rowsCalled = true
+	return m.rows, nil
+}
+
+func (m *mockQueryer) HasPgStatMonitor(context.Context) (bool, error) {
+	return m.monitor, nil
+}
+
+func (m *mockQueryer) StatMonitorNormalized(context.Context) (bool, error) {
+	return m.monitorNormalized, nil
+}
+
+func (m *mockQueryer) LargeParameterQueriesFromStatMonitor(context.Context) ([]db.LargeParameterQueriesFromStatMonitorRow, error) {
+	m.rowsCalled = true
+	return m.monitorRows, nil
+}
+
+func TestQueryPatterns_Healthy(t *testing.T) {
+	t.Parallel()
+
+	report, err := querypatterns.New(&mockQueryer{available: true}).Check(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, check.SeverityOK, report.Severity)
+	assert.Len(t, report.Results, 2)
+}
+
+func TestQueryPatterns_ExtensionUnavailable(t *testing.T) {
+	t.Parallel()
+
+	q := &mockQueryer{}
+	report, err := querypatterns.New(q).Check(context.Background())
+	require.NoError(t, err)
+
+	assert.False(t, q.rowsCalled)
+	assert.Equal(t, check.SeverityOK, report.Severity)
+	require.Len(t, report.Results, 2)
+	for _, f := range report.Results {
+		assert.Contains(t, f.Details, "pg_stat_statements")
+	}
+}
+
+func TestQueryPatterns_StatMonitorFallback(t *testing.T) {
+	t.Parallel()
+
+	q := &mockQueryer{
+		monitor:           true,
+		monitorNormalized: true,
+		monitorRows: []db.LargeParameterQueriesFromStatMonitorRow{
+			{Query: "SELECT * FROM orders WHERE id IN ($1, $2, ...)", ParamCount: 1000, Variants: 3, Calls: 500, TotalExecTime: 1000, RowsReturned: 5000},
+		},
+	}
+	report, err := querypatterns.New(q).Check(context.Background())
+	require.NoError(t, err)
+
+	assert.True(t, q.rowsCalled)
+	assert.Equal(t, check.SeverityFail, checktest.Finding(t, report, "large-parameter-lists").Severity)
+}
+
+func TestQueryPatterns_StatMonitorNotNormalized(t *testing.T) {
+	t.Parallel()
+
+	q := &mockQueryer{monitor: true}
+	report, err := querypatterns.New(q).Check(context.Background())
+	require.NoError(t, err)
+
+	assert.False(t, q.rowsCalled)
+	for _, f := range report.Results {
+		assert.Equal(t, check.SeverityOK, f.Severity)
+		assert.Contains(t, f.Details, "pgsm_normalized_query")
+	}
+}
+
+func TestQueryPatterns_LargeParameterLists(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		params   int32
+		expected check.Severity
+	}{
+		{name: "few parameters", params: 12, expected: check.SeverityOK},
+		{name: "at warn threshold", params: 100, expected: check.SeverityWarn},
+		{name: "at fail threshold", params: 1000, expected: check.SeverityFail},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			q := &mockQueryer{
+				available: true,
+				rows: []db.LargeParameterQueriesRow{
+					{Query: "SELECT * FROM orders WHERE id IN ($1, $2, ...)", ParamCount: tt.params, Variants: 37, Calls: 500, TotalExecTime: 1000, TotalPlanTime: 4000, RowsReturned: 5000},
+				},
+			}
+			report, err := querypatterns.New(q).Check(context.Background())
+			require.NoError(t, err)
+
+			f := checktest.Finding(t, report, "large-parameter-lists")
+			assert.Equal(t, tt.expected, f.Severity)
+			if tt.expected != check.SeverityOK {
+				require.NotNil(t, f.Table)
+				require.Len(t, f.Table.Rows, 1)
+				assert.Equal(t, "37", f.Table.Rows[0].Cells[2])
+			}
+		})
+	}
+}
+
+func TestQueryPatterns_LargeAnyArrays(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		anyArray bool
+		rows     int64
+		expected check.Severity
+	}{
+		{name: "small array", anyArray: true, rows: 100 * 50, expected: check.SeverityOK},
+		{name: "large array", anyArray: true, rows: 100 * 5000, expected: check.SeverityWarn},
+		{name: "not an array lookup", anyArray: false, rows: 100 * 5000, expected: check.SeverityOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			q := &mockQueryer{
+				available: true,
+				rows: []db.LargeParameterQueriesRow{
+					{Query: "SELECT * FROM orders WHERE id = ANY($1)", ParamCount: 1, Variants: 1, AnyArray: tt.anyArray, Calls: 100, RowsReturned: tt.rows},
+				},
+			}
+			report, err := querypatterns.New(q).Check(context.Background())
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.expected, checktest.Finding(t, report, "large-any-arrays").Severity)
+			assert.Equal(t, check.SeverityOK, checktest.Finding(t, report, "large-parameter-lists").Severity)
+		})
+	}
+}
+
+func TestQueryPatterns_QueryError(t *testing.T) {
+	t.Parallel()
+
+	_, err := querypatterns.New(&mockQueryer{err: errors.New("permission denied")}).Check(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "query-patterns")
+}
+
+func TestMetadata(t *testing.T) {
+	t.Parallel()
+
+	meta := querypatterns.Metadata()
+	assert.Equal(t, "query-patterns", meta.CheckID)
+	assert.Equal(t, check.CategoryPerformance, meta.Category)
+	assert.NotEmpty(t, meta.SQL)
+	assert.NotEmpty(t, meta.Readme)
+}
@@ -0,0 +1,115 @@
+-- name: StatStatementsAvailable :one
+-- Checks if the pg_stat_statements extension is installed in this database.
+SELECT EXISTS(
+  SELECT 1 FROM pg_extension
+  WHERE extname = 'pg_stat_statements'
+) AS available;
+
+-- name: LargeParameterQueries :many
+-- Statements with hundreds of bind parameters (typically generated IN lists)
+-- or with an `= ANY($n)` array lookup that returns many rows per call.
+-- pg_stat_statements records one entry per IN-list length, so entries that
+-- differ only in list length are counted as variants of the same template.
+WITH stmts AS (
+  SELECT
+    queryid
+    , query
+    , calls
+    , total_exec_time
+    , total_plan_time
+    , rows
+    , (
+      SELECT COALESCE(MAX(m[1]::integer), 0)
+      FROM REGEXP_MATCHES(query, '\$(\d+)', 'g') AS m
+    ) AS param_count
+    , REGEXP_REPLACE(query, '\$\d+(\s*,\s*\$\d+)+', '$n, ...', 'g') AS template
+  FROM pg_stat_statements
+  WHERE
+    calls > 10
+    AND query !~* '^\s*(COPY|SET|BEGIN|COMMIT|ROLLBACK|SAVEPOINT|PREPARE|DEALLOCATE)'
+)
+
+, with_variants AS (
+  SELECT
+    *
+    , COUNT(*) OVER (PARTITION BY template) AS variants
+    , query ~* '=\s*any\s*\(\s*\$\d+' AS any_array
+  FROM stmts
+)
+
+SELECT
+  queryid::bigint AS query_id
+  , LEFT(REGEXP_REPLACE(query, '\s+', ' ', 'g'), 80)::text AS query
+  , param_count::integer AS param_count
+  , variants::bigint AS variants
+  , any_array
+  , calls::bigint AS calls
+  , total_exec_time::double precision AS total_exec_time
+  , total_plan_time::double precision AS total_plan_time
+  , rows::bigint AS rows_returned
+FROM with_variants
+WHERE
+  param_count >= 100
+  OR (any_array AND rows >= calls * 1000)
+ORDER BY total_exec_time + total_plan_time DESC
+LIMIT 50;
+
+-- name: LargeParameterQueriesFromStatMonitor :many
+-- LargeParameterQueries for Percona's pg_stat_monitor, used when
+-- pg_stat_statements is not installed. pg_stat_monitor keeps a row per query
+-- per time bucket, so buckets are summed per queryid before the templates
+-- are compared. Parameters are only visible when pgsm_normalized_query is on.
+WITH buckets AS (
+  SELECT
+    queryid
+    , MIN(query) AS query
+    , SUM(calls) AS calls
+    , SUM(total_exec_time) AS total_exec_time
+    , SUM(total_plan_time) AS total_plan_time
+    , SUM(rows) AS rows
+  FROM pg_stat_monitor
+  WHERE query !~* '^\s*(COPY|SET|BEGIN|COMMIT|ROLLBACK|SAVEPOINT|PREPARE|DEALLOCATE)'
+  GROUP BY queryid
+  HAVING SUM(calls) > 10
+)
+
+, stmts AS (
+  SELECT
+    *
+    , (
+      SELECT COALESCE(MAX(m[1]::integer), 0)
+      FROM REGEXP_MATCHES(query, '\$(\d+)', 'g') AS m
+    ) AS param_count
+    , REGEXP_REPLACE(query, '\$\d+(\s*,\s*\$\d+)+', '$n, ...', 'g') AS template
+  FROM buckets
+)
+
+, with_variants AS (
+  SELECT
+    *
+    , COUNT(*) OVER (PARTITION BY template) AS variants
+    , query ~* '=\s*any\s*\(\s*\$\d+' AS any_array
+  FROM stmts
+)
+
+SELECT
+  queryid::bigint AS query_id
+  , LEFT(REGEXP_REPLACE(query, '\s+', ' ', 'g'), 80)::text AS query
+  , param_count::integer AS param_count
+  , variants::bigint AS variants
+  , any_array
+  , calls::bigint AS calls
+  , total_exec_time::double precision AS total_exec_time
+  , total_plan_time::double precision AS total_plan_time
+  , rows::bigint AS rows_returned
+FROM with_variants
+WHERE
+  param_count >= 100
+  OR (any_array AND rows >= calls * 1000)
+ORDER BY total_exec_time + total_plan_time DESC
+LIMIT 50;
+
+-- name: StatMonitorNormalized :one
+-- Checks whether pg_stat_monitor records normalized query text ($1, $2, ...)
+-- rather than the literal values of each call.
+SELECT COALESCE(current_setting('pg_stat_monitor.pgsm_normalized_query', true), '') = 'on' AS normalized;
@@ -0,0 +1,360 @@
+// Package queuetables implements checks for queue-like tables (job queues,
+// outboxes) and the hygiene they need to stay small and fast to poll.
+package queuetables
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/fresha/pgdoctor/check"
+	"github.com/fresha/pgdoctor/db"
+	"github.com/fresha/pgdoctor/internal/accesspattern"
+	"github.com/fresha/pgdoctor/internal/pgident"
+)
+
+//go:embed query.sql
+var querySQL string
+
+//go:embed README.md
+var readme string
+
+const (
+	defaultNamePatterns = "*_jobs, *_queue, outbox_*, *_outbox"
+
+	// A table that is not named like a queue is treated as one when its
+	// write pattern is queue-like and it has seen enough inserts to tell.
+	churnMinInserts = int64(100000)
+
+	// Dead rows left behind by consumers.
+	deadRowsWarn   = int64(10000)
+	deadRowsFail   = int64(100000)
+	deadToLiveFail = int64(10)
+	// Fixed autovacuum threshold proposed for queue tables, replacing the
+	// scale factor that only triggers once a fifth of the table is dead.
+	queueVacuumThreshold = 1000
+
+	// Tables that update at least half as many rows as they insert mark
+	// rows processed in place, and need a partial index once this large.
+	statusMinRows     = int64(10000)
+	statusUpdateRatio = 0.5
+
+	// Live rows in a queue that is not partitioned.
+	liveRowsWarn = int64(1000000)
+	liveRowsFail = int64(10000000)
+	// Share of inserts deleted below which the table has no retention.
+	retentionMinDeleteRatio = 0.1
+)
+
+type QueueTablesQueries interface {
+	QueueTableCandidates(context.Context) ([]db.QueueTableCandidatesRow, error)
+}
+
+type checker struct {
+	queries  QueueTablesQueries
+	patterns []string
+}
+
+func Metadata() check.Metadata {
+	return check.Metadata{
+		Category:       check.CategoryVacuum,
+		CheckID:        "queue-tables",
+		Name:           "Queue Tables",
+		Description:    "Finds queue and outbox tables by name or insert/delete churn and checks they are vacuumed, indexed for polling, and kept small",
+		Readme:         readme,
+		SQL:            querySQL,
+		RuntimeClass:   check.RuntimeMedium,
+		ProductionSafe: true,
+		Findings: []check.FindingSpec{
+			{ID: "queue-tables", Description: "Tables treated as queues: names matching name_patterns, or at least 100K inserts with half deleted and 10x turnover of live rows"},
+			{ID: "queue-vacuum", Description: "Dead rows left in queue tables by consumers, with per-table autovacuum settings to clear them", Thresholds: "WARN >= 10K dead rows and more dead than live, FAIL >= 100K and 10x live"},
+			{ID: "queue-status-index", Description: "Queue tables that mark rows processed by UPDATE but have no partial index for polling pending rows", Thresholds: "WARN >= 10K live rows"},
+			{ID: "queue-size", Description: "Live rows in unpartitioned queue tables, and whether processed rows are ever deleted", Thresholds: "WARN >= 1M, FAIL >= 10M"},
+		},
+	}
+}
+
+// New creates the check. name_patterns (comma-separated shell globs matched
+// against table names) replaces the default queue naming patterns.
+func New(queries QueueTablesQueries, cfg ...check.Config) check.Checker {
+	patterns := defaultNamePatterns
+	if len(cfg) > 0 {
+		patterns = cfg[0].String(Metadata().CheckID, "name_patterns", defaultNamePatterns)
+	}
+	c := &checker{
+		queries: queries,
+	}
+	for _, p := range strings.Split(patterns, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			c.patterns = append(c.patterns, p)
+		}
+	}
+	return c
+}
+
+func (c *checker) Metadata() check.Metadata {
+	return Metadata()
+}
+
+func (c *checker) Check(ctx context.Context) (*check.Report, error) {
+	report := check.NewReport(Metadata())
+	ignore := check.ObjectFilterFromContext(ctx)
+
+	rows, err := c.queries.QueueTableCandidates(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("running %s/%s: %w", report.Category, report.CheckID, err)
+	}
+	rows = slices.DeleteFunc(rows, func(row db.QueueTableCandidatesRow) bool {
+		return ignore.Table(row.SchemaName, row.TableName)
+	})
+
+	var queues []queueTable
+	for _, row := range rows {
+		if reason := c.matchReason(row); reason != "" {
+			queues = append(queues, queueTable{row: row, reason: reason})
+		}
+	}
+
+	c.checkQueueTables(queues, report)
+	checkQueueVacuum(queues, report)
+	checkStatusIndex(queues, report)
+	checkQueueSize(queues, report)
+
+	return report, nil
+}
+
+type queueTable struct {
+	row    db.QueueTableCandidatesRow
+	reason string
+}
+
+func (q queueTable) name() string {
+	return q.row.SchemaName + "." + q.row.TableName
+}
+
+// matchReason says why a table is treated as a queue, or returns "" when
+// it is not one. Partitions match on their parent's name.
+func (c *checker) matchReason(row db.QueueTableCandidatesRow) string {
+	for _, p := range c.patterns {
+		for _, name := range []string{row.TableName, row.ParentName} {
+			if name == "" {
+				continue
+			}
+			if ok, _ := path.Match(p, name); ok {
+				return "name " + p
+			}
+		}
+	}
+	pattern := accesspattern.Classify(accesspattern.Counters{
+		Inserts:  row.Inserts,
+		Updates:  row.Updates,
+		Deletes:  row.Deletes,
+		LiveRows: row.LiveRows,
+	})
+	if row.Inserts >= churnMinInserts && pattern == accesspattern.QueueLike {
+		return "churn"
+	}
+	return ""
+}
+
+func perHour(n, statsAgeSeconds int64) string {
+	if statsAgeSeconds <= 0 {
+		return "-"
+	}
+	return check.FormatNumber(n * 3600 / statsAgeSeconds)
+}
+
+func (c *checker) checkQueueTables(queues []queueTable, report *check.Report) {
+	if len(queues) == 0 {
+		report.AddFinding(check.Finding{
+			ID:       "queue-tables",
+			Name:     "Queue Tables",
+			Severity: check.SeverityOK,
+			Details:  fmt.Sprintf("No queue-like tables found (name patterns: %s, or insert/delete churn)", strings.Join(c.patterns, ", ")),
+		})
+		return
+	}
+
+	var tableRows []check.TableRow
+	for _, q := range queues {
+		tableRows = append(tableRows, check.TableRow{
+			Cells: []string{
+				q.name(),
+				q.reason,
+				check.FormatNumber(q.row.LiveRows),
+				check.FormatNumber(q.row.DeadRows),
+				perHour(q.row.Inserts, q.row.StatsAgeSeconds),
+				perHour(q.row.Deletes, q.row.StatsAgeSeconds),
+				check.FormatBytes(q.row.TableSizeBytes),
+			},
+		})
+	}
+
+	report.AddFinding(check.Finding{
+		ID:       "queue-tables",
+		Name:     "Queue Tables",
+		Severity: check.SeverityOK,
+		Details:  fmt.Sprintf("%d table(s) treated as queues; the findings below check their hygiene", len(queues)),
+		Table: &check.Table{
+			Headers: []string{"Table", "Matched By", "Live Rows", "Dead Rows", "Inserts/h", "Deletes/h", "Size"},
+			Rows:    tableRows,
+		},
+	})
+}
+
+func checkQueueVacuum(queues []queueTable, report *check.Report) {
+	severity := check.SeverityOK
+	var tableRows []check.TableRow
+	var fixes []check.Fix
+
+	for _, q := range queues {
+		if q.row.DeadRows < deadRowsWarn || q.row.DeadRows < q.row.LiveRows {
+			continue
+		}
+		rowSeverity := check.SeverityWarn
+		if q.row.DeadRows >= deadRowsFail && q.row.DeadRows >= deadToLiveFail*max(q.row.LiveRows, 1) {
+			rowSeverity = check.SeverityFail
+		}
+		severity = max(severity, rowSeverity)
+
+		lastVacuum := "never"
+		if q.row.LastVacuumAgeSeconds >= 0 {
+			lastVacuum = check.FormatDurationSec(q.row.LastVacuumAgeSeconds) + " ago"
+		}
+		tableRows = append(tableRows, check.TableRow{
+			Cells:    []string{q.name(), check.FormatNumber(q.row.LiveRows), check.FormatNumber(q.row.DeadRows), lastVacuum},
+			Severity: rowSeverity,
+		})
+
+		if !strings.Contains(q.row.Reloptions, "autovacuum_vacuum_scale_factor") {
+			fixes = append(fixes, check.Fix{
+				Object:      q.name(),
+				Description: fmt.Sprintf("Vacuum %s after every %d dead rows instead of a fraction of its size", q.name(), queueVacuumThreshold),
+				SQL: fmt.Sprintf("ALTER TABLE %s SET (autovacuum_vacuum_scale_factor = 0, autovacuum_vacuum_threshold = %d)",
+					pgident.Quote(q.row.SchemaName, q.row.TableName), queueVacuumThreshold),
+				Risk: check.RiskLow,
+				Lock: check.LockOnline,
+			})
+		}
+	}
+
+	if len(tableRows) == 0 {
+		report.AddFinding(check.Finding{
+			ID:       "queue-vacuum",
+			Name:     "Queue Vacuum",
+			Severity: check.SeverityOK,
+			Details:  "No queue table holds more dead rows than live ones",
+		})
+		return
+	}
+
+	report.AddFinding(check.Finding{
+		ID:       "queue-vacuum",
+		Name:     "Queue Vacuum",
+		Severity: severity,
+		Details: fmt.Sprintf("%d queue table(s) hold more dead rows than live ones. Consumers polling with ORDER BY ... LIMIT or SKIP LOCKED walk past every dead row in the index and heap before finding work, "+
+			"so poll latency grows between vacuums. Set a fixed autovacuum threshold on these tables so they are vacuumed after a small number of deletes; "+
+			"if vacuum still cannot keep up, look for long-running transactions holding back the xmin horizon", len(tableRows)),
+		Table: &check.Table{
+			Headers: []string{"Table", "Live Rows", "Dead Rows", "Last Vacuum"},
+			Rows:    tableRows,
+		},
+		Fixes: fixes,
+	})
+}
+
+func checkStatusIndex(queues []queueTable, report *check.Report) {
+	var tableRows []check.TableRow
+	for _, q := range queues {
+		if q.row.HasPartialIndex || q.row.LiveRows < statusMinRows ||
+			float64(q.row.Updates) < float64(q.row.Inserts)*statusUpdateRatio {
+			continue
+		}
+		tableRows = append(tableRows, check.TableRow{
+			Cells:    []string{q.name(), check.FormatNumber(q.row.LiveRows), check.FormatNumber(q.row.Updates)},
+			Severity: check.SeverityWarn,
+		})
+	}
+
+	if len(tableRows) == 0 {
+		report.AddFinding(check.Finding{
+			ID:       "queue-status-index",
+			Name:     "Queue Status Index",
+			Severity: check.SeverityOK,
+			Details:  "Every queue table that marks rows by UPDATE has a partial index, or is small",
+		})
+		return
+	}
+
+	report.AddFinding(check.Finding{
+		ID:       "queue-status-index",
+		Name:     "Queue Status Index",
+		Severity: check.SeverityWarn,
+		Details: fmt.Sprintf("%d queue table(s) mark rows processed with UPDATE but have no partial index. Workers looking for pending rows then scan an index over every row, "+
+			"processed or not. Index only the rows workers poll, e.g. CREATE INDEX CONCURRENTLY ON jobs (run_at) WHERE status = 'pending', "+
+			"so the index stays as small as the backlog", len(tableRows)),
+		Table: &check.Table{
+			Headers: []string{"Table", "Live Rows", "Updates"},
+			Rows:    tableRows,
+		},
+	})
+}
+
+func checkQueueSize(queues []queueTable, report *check.Report) {
+	severity := check.SeverityOK
+	noRetention := 0
+	var tableRows []check.TableRow
+
+	for _, q := range queues {
+		if q.row.ParentName != "" || q.row.LiveRows < liveRowsWarn {
+			continue
+		}
+		rowSeverity := check.SeverityWarn
+		if q.row.LiveRows >= liveRowsFail {
+			rowSeverity = check.SeverityFail
+		}
+		severity = max(severity, rowSeverity)
+
+		retention := "deletes"
+		if float64(q.row.Deletes) < float64(q.row.Inserts)*retentionMinDeleteRatio {
+			retention = "none"
+			noRetention++
+		}
+		tableRows = append(tableRows, check.TableRow{
+			Cells:    []string{q.name(), check.FormatNumber(q.row.LiveRows), check.FormatBytes(q.row.TableSizeBytes), retention},
+			Severity: rowSeverity,
+		})
+	}
+
+	if len(tableRows) == 0 {
+		report.AddFinding(check.Finding{
+			ID:       "queue-size",
+			Name:     "Queue Size",
+			Severity: check.SeverityOK,
+			Details:  "Every unpartitioned queue table holds fewer than 1M live rows",
+		})
+		return
+	}
+
+	details := fmt.Sprintf("%d unpartitioned queue table(s) hold at least 1M live rows. A queue that works well stays close to its backlog; a large steady state slows polling, "+
+		"makes every vacuum longer, and turns cleanup into large DELETEs that bloat the table", len(tableRows))
+	if noRetention > 0 {
+		details += fmt.Sprintf(". %d of them delete almost nothing they insert: add a retention job, or partition by creation time and drop or truncate old partitions instead of deleting rows", noRetention)
+	} else {
+		details += ". Deletes are running, so check whether consumers are keeping up, or partition by creation time and drop old partitions"
+	}
+
+	report.AddFinding(check.Finding{
+		ID:       "queue-size",
+		Name:     "Queue Size",
+		Severity: severity,
+		Details:  details,
+		Table: &check.Table{
+			Headers: []string{"Table", "Live Rows", "Size", "Retention"},
+			Rows:    tableRows,
+		},
+	})
+}
@@ -0,0 +1,228 @@
+package queuetables_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/fresha/pgdoctor/check"
+	"github.com/fresha/pgdoctor/check/checktest"
+	"github.com/fresha/pgdoctor/checks/queuetables"
+	"github.com/fresha/pgdoctor/db"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockQueryer struct {
+	rows []db.QueueTableCandidatesRow
+	err  error
+}
+
+func (m *mockQueryer) QueueTableCandidates(context.Context) ([]db.QueueTableCandidatesRow, error) {
+	return m.rows, m.err
+}
+
+// queue returns a healthy queue table: rows are deleted as fast as they are
+// inserted and only a small backlog is alive.
+func queue(name string) db.QueueTableCandidatesRow {
+	return db.QueueTableCandidatesRow{
+		SchemaName:           "public",
+		TableName:            name,
+		Inserts:              1000000,
+		Deletes:              999000,
+		LiveRows:             1000,
+		DeadRows:             500,
+		TableSizeBytes:       8 * 1024 * 1024,
+		LastVacuumAgeSeconds: 60,
+		HasPartialIndex:      true,
+		StatsAgeSeconds:      86400,
+	}
+}
+
+func TestQueueTables_NoQueues(t *testing.T) {
+	t.Parallel()
+
+	q := &mockQueryer{rows: []db.QueueTableCandidatesRow{
+		{SchemaName: "public", TableName: "users", Inserts: 5000, Updates: 20000, LiveRows: 5000},
+	}}
+
+	report, err := queuetables.New(q).Check(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, check.SeverityOK, report.Severity)
+	assert.Len(t, report.Results, 4)
+	assert.Contains(t, checktest.Finding(t, report, "queue-tables").Details, "No queue-like tables found")
+}
+
+func TestQueueTables_Detection(t *testing.T) {
+	t.Parallel()
+
+	churn := queue("events")
+	partition := queue("jobs_2024_01")
+	partition.ParentName = "email_jobs"
+	lowChurn := queue("audit_log")
+	lowChurn.Deletes = 0
+
+	tests := []struct {
+		name     string
+		rows     []db.QueueTableCandidatesRow
+		cfg      []check.Config
+		expected []string
+	}{
+		{name: "name pattern", rows: []db.QueueTableCandidatesRow{queue("email_jobs")}, expected: []string{"name *_jobs"}},
+		{name: "outbox prefix", rows: []db.QueueTableCandidatesRow{queue("outbox_messages")}, expected: []string{"name outbox_*"}},
+		{name: "churn", rows: []db.QueueTableCandidatesRow{churn}, expected: []string{"churn"}},
+		{name: "partition parent", rows: []db.QueueTableCandidatesRow{partition}, expected: []string{"name *_jobs"}},
+		{name: "no deletes", rows: []db.QueueTableCandidatesRow{lowChurn}},
+		{
+			name:     "custom patterns",
+			rows:     []db.QueueTableCandidatesRow{queue("email_jobs"), lowChurn},
+			cfg:      []check.Config{{"queue-tables": {"name_patterns": "audit_*"}}},
+			expected: []string{"churn", "name audit_*"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			report, err := queuetables.New(&mockQueryer{rows: tt.rows}, tt.cfg...).Check(context.Background())
+			require.NoError(t, err)
+
+			f := checktest.Finding(t, report, "queue-tables")
+			assert.Equal(t, check.SeverityOK, f.Severity)
+			if len(tt.expected) == 0 {
+				assert.Nil(t, f.Table)
+				return
+			}
+			require.NotNil(t, f.Table)
+			var reasons []string
+			for _, row := range f.Table.Rows {
+				reasons = append(reasons, row.Cells[1])
+			}
+			assert.Equal(t, tt.expected, reasons)
+		})
+	}
+}
+
+func TestQueueTables_Vacuum(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		live       int64
+		dead       int64
+		reloptions string
+		expected   check.Severity
+		fixes      int
+	}{
+		{name: "clean", live: 1000, dead: 500, expected: check.SeverityOK},
+		{name: "more dead than live", live: 5000, dead: 20000, expected: check.SeverityWarn, fixes: 1},
+		{name: "far more dead", live: 5000, dead: 200000, expected: check.SeverityFail, fixes: 1},
+		{name: "few dead rows", live: 10, dead: 9000, expected: check.SeverityOK},
+		{name: "already tuned", live: 5000, dead: 20000, reloptions: "autovacuum_vacuum_scale_factor=0", expected: check.SeverityWarn},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			row := queue("email_jobs")
+			row.LiveRows = tt.live
+			row.DeadRows = tt.dead
+			row.Reloptions = tt.reloptions
+
+			report, err := queuetables.New(&mockQueryer{rows: []db.QueueTableCandidatesRow{row}}).Check(context.Background())
+			require.NoError(t, err)
+
+			f := checktest.Finding(t, report, "queue-vacuum")
+			assert.Equal(t, tt.expected, f.Severity)
+			require.Len(t, f.Fixes, tt.fixes)
+			if tt.fixes > 0 {
+				assert.Equal(t, `ALTER TABLE "public"."email_jobs" SET (autovacuum_vacuum_scale_factor = 0, autovacuum_vacuum_threshold = 1000)`, f.Fixes[0].SQL)
+				assert.Equal(t, check.LockOnline, f.Fixes[0].Lock)
+				assert.Equal(t, check.RiskLow, f.Fixes[0].Risk)
+			}
+		})
+	}
+}
+
+func TestQueueTables_StatusIndex(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		updates    int64
+		live       int64
+		hasPartial bool
+		expected   check.Severity
+	}{
+		{name: "deletes processed rows", updates: 0, live: 50000, expected: check.SeverityOK},
+		{name: "status updates without partial index", updates: 1000000, live: 50000, expected: check.SeverityWarn},
+		{name: "status updates with partial index", updates: 1000000, live: 50000, hasPartial: true, expected: check.SeverityOK},
+		{name: "small table", updates: 1000000, live: 500, expected: check.SeverityOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			row := queue("email_jobs")
+			row.Updates = tt.updates
+			row.LiveRows = tt.live
+			row.HasPartialIndex = tt.hasPartial
+
+			report, err := queuetables.New(&mockQueryer{rows: []db.QueueTableCandidatesRow{row}}).Check(context.Background())
+			require.NoError(t, err)
+
+			f := checktest.Finding(t, report, "queue-status-index")
+			assert.Equal(t, tt.expected, f.Severity)
+		})
+	}
+}
+
+func TestQueueTables_Size(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		live     int64
+		deletes  int64
+		parent   string
+		expected check.Severity
+		contains string
+	}{
+		{name: "small", live: 1000, deletes: 999000, expected: check.SeverityOK},
+		{name: "backlog", live: 2000000, deletes: 900000, expected: check.SeverityWarn, contains: "check whether consumers are keeping up"},
+		{name: "no retention", live: 20000000, deletes: 0, expected: check.SeverityFail, contains: "1 of them delete almost nothing"},
+		{name: "partitioned", live: 20000000, deletes: 0, parent: "email_jobs", expected: check.SeverityOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			row := queue("email_jobs")
+			row.LiveRows = tt.live
+			row.Deletes = tt.deletes
+			row.ParentName = tt.parent
+
+			report, err := queuetables.New(&mockQueryer{rows: []db.QueueTableCandidatesRow{row}}).Check(context.Background())
+			require.NoError(t, err)
+
+			f := checktest.Finding(t, report, "queue-size")
+			assert.Equal(t, tt.expected, f.Severity)
+			if tt.contains != "" {
+				assert.Contains(t, f.Details, tt.contains)
+			}
+		})
+	}
+}
+
+func TestQueueTables_QueryError(t *testing.T) {
+	t.Parallel()
+
+	_, err := queuetables.New(&mockQueryer{err: errors.New("connection refused")}).Check(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "queue-tables")
+}
@@ -0,0 +1,36 @@
+-- name: QueueTableCandidates :many
+-- Tables with write activity since the stats reset, with the counters, vacuum
+-- history, and indexes needed to judge whether a queue-like table is kept
+-- small. Partitions report their parent so naming patterns can match it.
+WITH stats_age AS (
+  SELECT EXTRACT(EPOCH FROM (NOW() - COALESCE(d.stats_reset, PG_POSTMASTER_START_TIME())))::bigint AS seconds
+  FROM pg_stat_database AS d
+  WHERE d.datname = CURRENT_DATABASE()
+)
+
+SELECT
+  s.schemaname::text AS schema_name
+  , s.relname::text AS table_name
+  , COALESCE((
+    SELECT p.relname FROM pg_inherits AS inh
+    INNER JOIN pg_class AS p ON inh.inhparent = p.oid
+    WHERE inh.inhrelid = c.oid AND c.relispartition
+  ), '')::text AS parent_name
+  , s.n_tup_ins::bigint AS inserts
+  , s.n_tup_upd::bigint AS updates
+  , s.n_tup_del::bigint AS deletes
+  , s.n_live_tup::bigint AS live_rows
+  , s.n_dead_tup::bigint AS dead_rows
+  , PG_TABLE_SIZE(c.oid)::bigint AS table_size_bytes
+  , COALESCE(EXTRACT(EPOCH FROM (NOW() - GREATEST(s.last_vacuum, s.last_autovacuum))), -1)::bigint AS last_vacuum_age_seconds
+  , COALESCE(ARRAY_TO_STRING(c.reloptions, ','), '')::text AS reloptions
+  , EXISTS (
+    SELECT 1 FROM pg_index AS x
+    WHERE x.indrelid = c.oid AND x.indpred IS NOT NULL AND x.indisvalid
+  ) AS has_partial_index
+  , (SELECT seconds FROM stats_age)::bigint AS stats_age_seconds
+FROM pg_stat_user_tables AS s
+INNER JOIN pg_class AS c ON s.relid = c.oid
+WHERE s.n_tup_ins > 0
+ORDER BY s.n_tup_ins DESC
+LIMIT 500;
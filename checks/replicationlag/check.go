@@ -5,6 +5,7 @@ import (
 	"context"
 	_ "embed"
 	"fmt"
+	"strings"
 
 	"github.com/fresha/pgdoctor/check"
 	"github.com/fresha/pgdoctor/db"
@@ -36,27 +37,75 @@ const (
 
 type ReplicationLagQueries interface {
 	ReplicationLag(context.Context) ([]db.ReplicationLagRow, error)
+	SynchronousStandbyConfig(context.Context) (db.SynchronousStandbyConfigRow, error)
+	SynchronousCommitOverrides(context.Context) ([]db.SynchronousCommitOverridesRow, error)
 }
 
 type checker struct {
-	queries ReplicationLagQueries
+	queries      ReplicationLagQueries
+	physicalWarn float64
+	physicalFail float64
+	logicalWarn  float64
+	logicalFail  float64
+
+	// delayed holds the application_names of delayed standbys, whose
+	// physical lag is judged by flush lag instead of replay lag.
+	delayed map[string]bool
 }
 
 func Metadata() check.Metadata {
 	return check.Metadata{
-		Category:    check.CategoryPerformance,
-		CheckID:     "replication-lag",
-		Name:        "Replication Lag",
-		Description: "Monitors active replication streams for lag issues",
-		Readme:      readme,
-		SQL:         querySQL,
+		Category:       check.CategoryPerformance,
+		CheckID:        "replication-lag",
+		Name:           "Replication Lag",
+		Description:    "Monitors active replication streams for lag issues",
+		Readme:         readme,
+		SQL:            querySQL,
+		RuntimeClass:   check.RuntimeFast,
+		ProductionSafe: true,
+		Priority:       check.PriorityCritical,
+		Privileges:     []check.Privilege{check.PrivilegeReadAllStats},
+		Findings: []check.FindingSpec{
+			{ID: "no-replication", Description: "No replication is configured"},
+			{ID: "replication-state", Description: "Replication streams not in the streaming state", Thresholds: "WARN catchup, FAIL backup or stopping"},
+			{ID: "wal-retention", Description: "Replication slots at risk of losing required WAL", Thresholds: "WARN extended, FAIL unreserved or lost"},
+			{ID: "physical-replication-lag", Description: "Replay lag of physical standbys (flush lag for delayed standbys)", Thresholds: "WARN >= 250ms, FAIL >= 1s"},
+			{ID: "logical-replication-lag", Description: "Replay lag of logical subscribers", Thresholds: "WARN >= 20s, FAIL >= 35s"},
+			{ID: "sync-standbys", Description: "Standbys named in synchronous_standby_names that are not connected", Thresholds: "WARN any disconnected, FAIL fewer connected than commits wait for"},
+			{ID: "synchronous-commit-overrides", Description: "Databases or roles whose synchronous_commit differs from the cluster default (only with sync standbys)", Thresholds: "WARN"},
+		},
 	}
 }
 
-func New(queries ReplicationLagQueries, _ ...check.Config) check.Checker {
-	return &checker{
-		queries: queries,
+// New creates the check. The thresholds can be changed per database with
+// the physical_warn_seconds, physical_fail_seconds, logical_warn_seconds,
+// and logical_fail_seconds config keys. A FAIL threshold below its WARN
+// threshold is raised to match it. delayed_standbys lists, comma-separated,
+// the application_names of standbys with recovery_min_apply_delay.
+func New(queries ReplicationLagQueries, cfg ...check.Config) check.Checker {
+	c := &checker{
+		queries:      queries,
+		physicalWarn: physicalWarnSeconds,
+		physicalFail: physicalFailSeconds,
+		logicalWarn:  logicalWarnSeconds,
+		logicalFail:  logicalFailSeconds,
+	}
+	if len(cfg) > 0 {
+		checkID := Metadata().CheckID
+		c.physicalWarn = cfg[0].Float(checkID, "physical_warn_seconds", physicalWarnSeconds)
+		c.physicalFail = max(cfg[0].Float(checkID, "physical_fail_seconds", physicalFailSeconds), c.physicalWarn)
+		c.logicalWarn = cfg[0].Float(checkID, "logical_warn_seconds", logicalWarnSeconds)
+		c.logicalFail = max(cfg[0].Float(checkID, "logical_fail_seconds", logicalFailSeconds), c.logicalWarn)
+		for _, name := range strings.Split(cfg[0].String(checkID, "delayed_standbys", ""), ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				if c.delayed == nil {
+					c.delayed = make(map[string]bool)
+				}
+				c.delayed[name] = true
+			}
+		}
 	}
+	return c
 }
 
 func (c *checker) Metadata() check.Metadata {
@@ -71,6 +120,21 @@ func (c *checker) Check(ctx context.Context) (*check.Report, error) {
 		return nil, fmt.Errorf("running %s/%s: %w", check.CategoryPerformance, report.CheckID, err)
 	}
 
+	syncConfig, err := c.queries.SynchronousStandbyConfig(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("running %s/%s (sync config): %w", check.CategoryPerformance, report.CheckID, err)
+	}
+
+	overrides, err := c.queries.SynchronousCommitOverrides(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("running %s/%s (sync overrides): %w", check.CategoryPerformance, report.CheckID, err)
+	}
+
+	sync, err := parseSyncStandbyNames(syncConfig.SynchronousStandbyNames)
+	if err != nil {
+		return nil, fmt.Errorf("running %s/%s: %w", check.CategoryPerformance, report.CheckID, err)
+	}
+
 	if len(rows) == 0 {
 		report.AddFinding(check.Finding{
 			ID:       "no-replication",
@@ -78,6 +142,10 @@ func (c *checker) Check(ctx context.Context) (*check.Report, error) {
 			Severity: check.SeverityOK,
 			Details:  "No active replication streams found",
 		})
+		// A configured sync standby with no stream at all is the worst case.
+		if sync.configured() {
+			checkSync(sync, syncConfig, rows, overrides, report)
+		}
 		return report, nil
 	}
 
@@ -97,26 +165,65 @@ func (c *checker) Check(ctx context.Context) (*check.Report, error) {
 	}
 
 	if len(physicalRows) > 0 {
-		checkPhysicalReplicationLag(physicalRows, report)
+		observeMaxLag(ctx, "physical-replication-lag", "physical_warn_seconds", physicalRows, c.physicalLag, c.physicalWarn, c.physicalFail)
+		c.checkPhysicalReplicationLag(ctx, physicalRows, report)
 	}
 
 	if len(logicalRows) > 0 {
-		checkLogicalReplicationLag(logicalRows, report)
+		observeMaxLag(ctx, "logical-replication-lag", "logical_warn_seconds", logicalRows, replayLag, c.logicalWarn, c.logicalFail)
+		checkLogicalReplicationLag(ctx, logicalRows, c.logicalWarn, c.logicalFail, report)
 	}
 
+	checkSync(sync, syncConfig, rows, overrides, report)
+
 	return report, nil
 }
 
-func checkPhysicalReplicationLag(rows []db.ReplicationLagRow, report *check.Report) {
+// observeMaxLag reports the worst lag of a replication type for calibration.
+func observeMaxLag(ctx context.Context, findingID, key string, rows []db.ReplicationLagRow, lag func(db.ReplicationLagRow) float64, warn, fail float64) {
+	var maxLag float64
+	for _, row := range rows {
+		maxLag = max(maxLag, lag(row))
+	}
+	check.Observe(ctx, check.Observation{
+		CheckID:   Metadata().CheckID,
+		FindingID: findingID,
+		Key:       key,
+		Value:     maxLag,
+		Warn:      warn,
+		Fail:      fail,
+	})
+}
+
+// replayLag is the lag a stream is judged by: how long until WAL written on
+// the primary is visible on the replica.
+func replayLag(row db.ReplicationLagRow) float64 {
+	// COALESCE in query ensures this is always valid
+	return row.ReplayLagSeconds.Float64
+}
+
+// physicalLag is replayLag, except for delayed standbys: their replay lag
+// is at least recovery_min_apply_delay by design, so they are judged by
+// how quickly they receive and flush WAL.
+func (c *checker) physicalLag(row db.ReplicationLagRow) float64 {
+	if c.delayed[row.ApplicationName.String] {
+		// NULL when the standby has nothing left to flush
+		return row.FlushLagSeconds.Float64
+	}
+	return replayLag(row)
+}
+
+func (c *checker) checkPhysicalReplicationLag(ctx context.Context, rows []db.ReplicationLagRow, report *check.Report) {
+	warnSeconds, failSeconds := c.physicalWarn, c.physicalFail
 	var laggingRows []db.ReplicationLagRow
+	var undeclared []string
 	maxSeverity := check.SeverityOK
 
 	for _, row := range rows {
-		// COALESCE in query ensures these are always valid
-		lagSeconds := row.ReplayLagSeconds.Float64
-		if lagSeconds >= physicalWarnSeconds {
+		lagSeconds := c.physicalLag(row)
+		if lagSeconds >= warnSeconds {
 			laggingRows = append(laggingRows, row)
-			if lagSeconds >= physicalFailSeconds {
+			if lagSeconds >= failSeconds {
 				maxSeverity = check.SeverityFail
 			} else if maxSeverity != check.SeverityFail {
 				maxSeverity = check.SeverityWarn
@@ -136,10 +243,9 @@ func checkPhysicalReplicationLag(rows []db.ReplicationLagRow, report *check.Repo
 
 	var tableRows []check.TableRow
 	for _, row := range laggingRows {
-		// COALESCE in query ensures these are always valid
-		lagSeconds := row.ReplayLagSeconds.Float64
+		lagSeconds := c.physicalLag(row)
 		severity := check.SeverityWarn
-		if lagSeconds >= physicalFailSeconds {
+		if lagSeconds >= failSeconds {
 			severity = check.SeverityFail
 		}
 
@@ -148,11 +254,21 @@ func checkPhysicalReplicationLag(rows []db.ReplicationLagRow, report *check.Repo
 			slotName = "[no slot]"
 		}
 
+		name := row.ApplicationName.String
+		lag := fmt.Sprintf("%.2fs", lagSeconds)
+		switch {
+		case c.delayed[name]:
+			name += " (delayed standby)"
+			lag += " flush"
+		case looksDelayed(row, warnSeconds, failSeconds):
+			undeclared = append(undeclared, name)
+		}
+
 		tableRows = append(tableRows, check.TableRow{
 			Cells: []string{
-				row.ApplicationName.String,
+				name,
 				row.State.String,
-				fmt.Sprintf("%.2fs", lagSeconds),
+				lag,
 				check.FormatBytes(row.ReplayLagBytes.Int64),
 				slotName,
 			},
@@ -160,11 +276,17 @@ func checkPhysicalReplicationLag(rows []db.ReplicationLagRow, report *check.Repo
 		})
 	}
 
+	details := fmt.Sprintf("%d of %d physical replication stream(s) are lagging", len(laggingRows), len(rows))
+	if len(undeclared) > 0 {
+		details += fmt.Sprintf(". Flushed promptly but replayed late, like a delayed standby (recovery_min_apply_delay): %s. "+
+			"If the delay is deliberate, list these under delayed_standbys", strings.Join(undeclared, ", "))
+	}
+
 	report.AddFinding(check.Finding{
 		ID:       "physical-replication-lag",
 		Name:     "Physical Replication Lag",
 		Severity: maxSeverity,
-		Details:  fmt.Sprintf("%d of %d physical replication stream(s) are lagging", len(laggingRows), len(rows)),
+		Details:  details + hostContext(ctx),
 		Table: &check.Table{
 			Headers: []string{"Application", "State", "Replay Lag", "Lag Bytes", "Slot"},
 			Rows:    tableRows,
@@ -172,16 +294,23 @@ func checkPhysicalReplicationLag(rows []db.ReplicationLagRow, report *check.Repo
 	})
 }
 
-func checkLogicalReplicationLag(rows []db.ReplicationLagRow, report *check.Report) {
+// looksDelayed reports whether a stream that is not declared as a delayed
+// standby behaves like one: WAL is flushed within the WARN threshold but
+// replayed past the FAIL threshold.
+func looksDelayed(row db.ReplicationLagRow, warnSeconds, failSeconds float64) bool {
+	return row.FlushLagSeconds.Valid && row.FlushLagSeconds.Float64 < warnSeconds && replayLag(row) >= failSeconds
+}
+
+func checkLogicalReplicationLag(ctx context.Context, rows []db.ReplicationLagRow, warnSeconds, failSeconds float64, report *check.Report) {
 	var laggingRows []db.ReplicationLagRow
 	maxSeverity := check.SeverityOK
 
 	for _, row := range rows {
 		// COALESCE in query ensures these are always valid
 		lagSeconds := row.ReplayLagSeconds.Float64
-		if lagSeconds >= logicalWarnSeconds {
+		if lagSeconds >= warnSeconds {
 			laggingRows = append(laggingRows, row)
-			if lagSeconds >= logicalFailSeconds {
+			if lagSeconds >= failSeconds {
 				maxSeverity = check.SeverityFail
 			} else if maxSeverity != check.SeverityFail {
 				maxSeverity = check.SeverityWarn
@@ -204,7 +333,7 @@ func checkLogicalReplicationLag(rows []db.ReplicationLagRow, report *check.Repor
 		// COALESCE in query ensures these are always valid
 		lagSeconds := row.ReplayLagSeconds.Float64
 		severity := check.SeverityWarn
-		if lagSeconds >= logicalFailSeconds {
+		if lagSeconds >= failSeconds {
 			severity = check.SeverityFail
 		}
 
@@ -229,7 +358,7 @@ func checkLogicalReplicationLag(rows []db.ReplicationLagRow, report *check.Repor
 		ID:       "logical-replication-lag",
 		Name:     "Logical Replication Lag",
 		Severity: maxSeverity,
-		Details:  fmt.Sprintf("%d of %d logical replication stream(s) are lagging", len(laggingRows), len(rows)),
+		Details:  fmt.Sprintf("%d of %d logical replication stream(s) are lagging", len(laggingRows), len(rows)) + hostContext(ctx),
 		Table: &check.Table{
 			Headers: []string{"Application", "State", "Replay Lag", "Lag Bytes", "Slot"},
 			Rows:    tableRows,
@@ -237,6 +366,22 @@ func checkLogicalReplicationLag(rows []db.ReplicationLagRow, report *check.Repor
 	})
 }
 
+// hostContext describes the load on this instance when host metrics were
+// collected, so lag caused by a burst of writes or a saturated primary can
+// be told apart from a slow or unreachable replica.
+func hostContext(ctx context.Context) string {
+	meta := check.InstanceMetadataFromContext(ctx)
+	if meta == nil || meta.HostMetrics == nil {
+		return ""
+	}
+	m := meta.HostMetrics
+	details := ". Host: " + m.Summary()
+	if m.CPUPercent >= check.HostCPUBusyPercent {
+		details += ". The host is CPU bound, which slows WAL senders and logical decoding as well as queries"
+	}
+	return details
+}
+
 func checkReplicationState(rows []db.ReplicationLagRow, report *check.Report) {
 	var problematicRows []db.ReplicationLagRow
 
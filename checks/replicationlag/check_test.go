@@ -4,8 +4,10 @@ import (
 	"context"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/fresha/pgdoctor/check"
+	"github.com/fresha/pgdoctor/check/checktest"
 	"github.com/fresha/pgdoctor/checks/replicationlag"
 	"github.com/fresha/pgdoctor/db"
 	"github.com/jackc/pgx/v5/pgtype"
@@ -21,8 +23,10 @@ const (
 )
 
 type mockQueryer struct {
-	rows []db.ReplicationLagRow
-	err  error
+	rows      []db.ReplicationLagRow
+	sync      db.SynchronousStandbyConfigRow
+	overrides []db.SynchronousCommitOverridesRow
+	err       error
 }
 
 func (m *mockQueryer) ReplicationLag(context.Context) ([]db.ReplicationLagRow, error) {
@@ -32,6 +36,17 @@ func (m *mockQueryer) ReplicationLag(context.Context) ([]db.ReplicationLagRow, e
 	return m.rows, nil
 }
 
+func (m *mockQueryer) SynchronousStandbyConfig(context.Context) (db.SynchronousStandbyConfigRow, error) {
+	if m.sync.SynchronousCommit == "" {
+		return db.SynchronousStandbyConfigRow{SynchronousCommit: "on"}, nil
+	}
+	return m.sync, nil
+}
+
+func (m *mockQueryer) SynchronousCommitOverrides(context.Context) ([]db.SynchronousCommitOverridesRow, error) {
+	return m.overrides, nil
+}
+
 func pgText(s string) pgtype.Text {
 	return pgtype.Text{String: s, Valid: true}
 }
@@ -146,8 +161,8 @@ func TestCheck_AllHealthy(t *testing.T) {
 	report, err := checker.Check(context.Background())
 	require.NoError(t, err)
 
-	// Should have 5 findings: replication-state, wal-retention, physical-lag, logical-lag (all OK)
-	assert.Len(t, report.Results, 4)
+	// replication-state, wal-retention, physical-lag, logical-lag, sync-standbys, synchronous-commit-overrides (all OK)
+	assert.Len(t, report.Results, 6)
 	assert.Equal(t, check.SeverityOK, report.Severity)
 
 	// Verify all subchecks are OK
@@ -216,6 +231,34 @@ func TestCheck_PhysicalReplicationLag_Fail(t *testing.T) {
 	assert.Contains(t, physicalFinding.Details, "lagging")
 }
 
+func TestCheck_PhysicalReplicationLag_HostMetrics(t *testing.T) {
+	t.Parallel()
+
+	queryer := &mockQueryer{
+		rows: []db.ReplicationLagRow{
+			laggingPhysical("standby1", 2.0),
+		},
+	}
+	ctx := check.ContextWithInstanceMetadata(context.Background(), &check.InstanceMetadata{
+		HostMetrics: &check.HostMetrics{Window: time.Hour, CPUPercent: 85, MaxCPUPercent: 97, FreeableMemoryBytes: 2 << 30, ReadIOPS: 120, WriteIOPS: 3400},
+	})
+
+	report, err := replicationlag.New(queryer).Check(ctx)
+	require.NoError(t, err)
+
+	var physicalFinding *check.Finding
+	for i := range report.Results {
+		if report.Results[i].ID == findingIDPhysicalLag {
+			physicalFinding = &report.Results[i]
+			break
+		}
+	}
+
+	require.NotNil(t, physicalFinding)
+	assert.Contains(t, physicalFinding.Details, "Host: CPU 85% avg (97% peak), 2.0GiB freeable memory at lowest, 120 read / 3.4K write IOPS over the last 1h")
+	assert.Contains(t, physicalFinding.Details, "The host is CPU bound")
+}
+
 func TestCheck_PhysicalReplicationLag_Thresholds(t *testing.T) {
 	t.Parallel()
 
@@ -250,6 +293,159 @@ func TestCheck_PhysicalReplicationLag_Thresholds(t *testing.T) {
 	}
 }
 
+func TestCheck_PhysicalReplicationLag_ConfiguredWarn(t *testing.T) {
+	t.Parallel()
+
+	queryer := &mockQueryer{
+		rows: []db.ReplicationLagRow{
+			laggingPhysical("standby1", 0.5),
+			laggingPhysical("standby2", 0.3),
+		},
+	}
+	cfg := check.Config{"replication-lag": {"physical_warn_seconds": "0.6"}}
+	checker := replicationlag.New(queryer, cfg)
+
+	var observed []check.Observation
+	ctx := check.ContextWithObserver(context.Background(), func(o check.Observation) {
+		observed = append(observed, o)
+	})
+
+	report, err := checker.Check(ctx)
+	require.NoError(t, err)
+
+	// 500ms is below the configured WARN threshold.
+	assert.Equal(t, check.SeverityOK, report.Severity)
+
+	require.Len(t, observed, 1)
+	assert.Equal(t, "physical_warn_seconds", observed[0].Key)
+	assert.InDelta(t, 0.5, observed[0].Value, 1e-9)
+	assert.InDelta(t, 0.6, observed[0].Warn, 1e-9)
+	assert.InDelta(t, 1.0, observed[0].Fail, 1e-9)
+}
+
+func TestCheck_ReplicationLag_ConfiguredFail(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		cfg      check.Config
+		rows     []db.ReplicationLagRow
+		severity check.Severity
+	}{
+		{
+			name:     "physical lag below raised FAIL",
+			cfg:      check.Config{"replication-lag": {"physical_fail_seconds": "5"}},
+			rows:     []db.ReplicationLagRow{laggingPhysical("standby1", 2)},
+			severity: check.SeverityWarn,
+		},
+		{
+			name:     "logical lag above lowered FAIL",
+			cfg:      check.Config{"replication-lag": {"logical_fail_seconds": "25"}},
+			rows:     []db.ReplicationLagRow{laggingLogical("debezium", 30)},
+			severity: check.SeverityFail,
+		},
+		{
+			name:     "FAIL below WARN is raised to WARN",
+			cfg:      check.Config{"replication-lag": {"physical_warn_seconds": "2", "physical_fail_seconds": "0.5"}},
+			rows:     []db.ReplicationLagRow{laggingPhysical("standby1", 2.5)},
+			severity: check.SeverityFail,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			report, err := replicationlag.New(&mockQueryer{rows: tt.rows}, tt.cfg).Check(context.Background())
+			require.NoError(t, err)
+			assert.Equal(t, tt.severity, report.Severity)
+		})
+	}
+}
+
+func delayedPhysical(appName string, replaySeconds, flushSeconds float64) db.ReplicationLagRow {
+	row := laggingPhysical(appName, replaySeconds)
+	row.FlushLagSeconds = pgFloat8(flushSeconds)
+	return row
+}
+
+func TestCheck_PhysicalReplicationLag_DelayedStandbys(t *testing.T) {
+	t.Parallel()
+
+	cfg := check.Config{"replication-lag": {"delayed_standbys": "dr-delayed, reporting-4h"}}
+	tests := []struct {
+		name     string
+		rows     []db.ReplicationLagRow
+		severity check.Severity
+		contains string
+		// application and lag are the first table row's cells, if any.
+		application string
+		lag         string
+	}{
+		{
+			name:     "delayed standby keeping up",
+			rows:     []db.ReplicationLagRow{delayedPhysical("dr-delayed", 3600, 0.05)},
+			severity: check.SeverityOK,
+			contains: "All 1 physical replication stream(s) are healthy",
+		},
+		{
+			name:     "delayed standby idle",
+			rows:     []db.ReplicationLagRow{laggingPhysical("reporting-4h", 14400)},
+			severity: check.SeverityOK,
+		},
+		{
+			name:        "delayed standby falling behind",
+			rows:        []db.ReplicationLagRow{delayedPhysical("dr-delayed", 3600, 1.5)},
+			severity:    check.SeverityFail,
+			contains:    "1 of 1 physical replication stream(s) are lagging",
+			application: "dr-delayed (delayed standby)",
+			lag:         "1.50s flush",
+		},
+		{
+			name:     "undeclared delayed standby",
+			rows:     []db.ReplicationLagRow{delayedPhysical("standby-delayed", 3600, 0.05), healthyPhysical("standby1")},
+			severity: check.SeverityFail,
+			contains: "like a delayed standby (recovery_min_apply_delay): standby-delayed. If the delay is deliberate, list these under delayed_standbys",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			report, err := replicationlag.New(&mockQueryer{rows: tt.rows}, cfg).Check(context.Background())
+			require.NoError(t, err)
+			assert.Equal(t, tt.severity, report.Severity)
+
+			for _, f := range report.Results {
+				if f.ID == findingIDPhysicalLag {
+					assert.Contains(t, f.Details, tt.contains)
+					if tt.application != "" {
+						require.NotNil(t, f.Table)
+						assert.Equal(t, tt.application, f.Table.Rows[0].Cells[0])
+						assert.Equal(t, tt.lag, f.Table.Rows[0].Cells[2])
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestCheck_PhysicalReplicationLag_SlowReplayNotDelayed(t *testing.T) {
+	t.Parallel()
+
+	// Flush lag above WARN means the standby is not receiving WAL promptly,
+	// so it is a slow standby rather than a delayed one.
+	queryer := &mockQueryer{rows: []db.ReplicationLagRow{delayedPhysical("standby1", 5, 0.5)}}
+	report, err := replicationlag.New(queryer).Check(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, check.SeverityFail, report.Severity)
+	for _, f := range report.Results {
+		assert.NotContains(t, f.Details, "delayed_standbys")
+	}
+}
+
 func TestCheck_LogicalReplicationLag_Warning(t *testing.T) {
 	t.Parallel()
 
@@ -591,8 +787,8 @@ func TestCheck_MultipleIssues(t *testing.T) {
 	// Overall severity should be FAIL
 	assert.Equal(t, check.SeverityFail, report.Severity)
 
-	// Should have 4 findings (replication-state, wal-retention, physical-lag, logical-lag)
-	assert.Len(t, report.Results, 4)
+	// replication-state, wal-retention, physical-lag, logical-lag, plus the two sync findings
+	assert.Len(t, report.Results, 6)
 
 	// Count findings by severity
 	severityCounts := map[check.Severity]int{}
@@ -833,3 +1029,133 @@ func TestCheck_SeverityMaxCalculation(t *testing.T) {
 		})
 	}
 }
+
+func syncStandby(appName, state, syncState string) db.ReplicationLagRow {
+	row := healthyPhysical(appName)
+	row.State = pgText(state)
+	row.SyncState = pgText(syncState)
+	return row
+}
+
+func TestCheck_SyncStandbys(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name              string
+		standbyNames      string
+		synchronousCommit string
+		rows              []db.ReplicationLagRow
+		expected          check.Severity
+		detailContains    string
+	}{
+		{
+			name:              "priority standby connected",
+			standbyNames:      "FIRST 1 (standby1, standby2)",
+			synchronousCommit: "on",
+			rows:              []db.ReplicationLagRow{syncStandby("standby1", "streaming", "sync"), syncStandby("standby2", "streaming", "potential")},
+			expected:          check.SeverityOK,
+			detailContains:    "2 streaming standby(s) satisfy FIRST 1",
+		},
+		{
+			name:              "backup standby disconnected",
+			standbyNames:      "FIRST 1 (standby1, standby2)",
+			synchronousCommit: "on",
+			rows:              []db.ReplicationLagRow{syncStandby("Standby1", "streaming", "sync")},
+			expected:          check.SeverityWarn,
+			detailContains:    "1 configured synchronous standby(s) not connected",
+		},
+		{
+			name:              "quorum not met",
+			standbyNames:      "ANY 2 (a, b, c)",
+			synchronousCommit: "remote_apply",
+			rows:              []db.ReplicationLagRow{syncStandby("a", "streaming", "quorum"), syncStandby("b", "catchup", "quorum")},
+			expected:          check.SeverityFail,
+			detailContains:    "will hang",
+		},
+		{
+			name:              "no standby connected but commits do not wait",
+			standbyNames:      `"replica"`,
+			synchronousCommit: "local",
+			rows:              nil,
+			expected:          check.SeverityWarn,
+			detailContains:    "does not wait",
+		},
+		{
+			name:              "wildcard",
+			standbyNames:      "*",
+			synchronousCommit: "on",
+			rows:              []db.ReplicationLagRow{syncStandby("anything", "streaming", "sync")},
+			expected:          check.SeverityOK,
+			detailContains:    "1 streaming standby(s)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			queryer := &mockQueryer{
+				rows: tt.rows,
+				sync: db.SynchronousStandbyConfigRow{SynchronousStandbyNames: tt.standbyNames, SynchronousCommit: tt.synchronousCommit},
+			}
+			report, err := replicationlag.New(queryer).Check(context.Background())
+			require.NoError(t, err)
+
+			finding := checktest.Finding(t, report, "sync-standbys")
+			assert.Equal(t, tt.expected, finding.Severity)
+			assert.Contains(t, finding.Details, tt.detailContains)
+		})
+	}
+}
+
+func TestCheck_SynchronousCommitOverrides(t *testing.T) {
+	t.Parallel()
+
+	queryer := &mockQueryer{
+		rows: []db.ReplicationLagRow{syncStandby("standby1", "streaming", "sync")},
+		sync: db.SynchronousStandbyConfigRow{SynchronousStandbyNames: "standby1", SynchronousCommit: "on"},
+		overrides: []db.SynchronousCommitOverridesRow{
+			{DatabaseName: "app", RoleName: "", Value: "on"},
+			{DatabaseName: "", RoleName: "batch_writer", Value: "off"},
+		},
+	}
+	report, err := replicationlag.New(queryer).Check(context.Background())
+	require.NoError(t, err)
+
+	finding := checktest.Finding(t, report, "synchronous-commit-overrides")
+	assert.Equal(t, check.SeverityWarn, finding.Severity)
+	require.NotNil(t, finding.Table)
+	require.Len(t, finding.Table.Rows, 1)
+	assert.Equal(t, []string{"(all)", "batch_writer", "off"}, finding.Table.Rows[0].Cells)
+}
+
+func TestCheck_SyncFindingsOnStandby(t *testing.T) {
+	t.Parallel()
+
+	// A standby carrying the primary's synchronous_standby_names, with a
+	// cascaded standby that is not named there.
+	queryer := &mockQueryer{
+		rows: []db.ReplicationLagRow{syncStandby("cascade1", "streaming", "async")},
+		sync: db.SynchronousStandbyConfigRow{SynchronousStandbyNames: "standby1", SynchronousCommit: "on", InRecovery: true},
+		overrides: []db.SynchronousCommitOverridesRow{
+			{DatabaseName: "", RoleName: "batch_writer", Value: "off"},
+		},
+	}
+	report, err := replicationlag.New(queryer).Check(context.Background())
+	require.NoError(t, err)
+
+	standbys := checktest.Finding(t, report, "sync-standbys")
+	assert.Equal(t, check.SeverityOK, standbys.Severity)
+	assert.Contains(t, standbys.Details, "standby")
+	overrides := checktest.Finding(t, report, "synchronous-commit-overrides")
+	assert.Equal(t, check.SeverityOK, overrides.Severity)
+	assert.Nil(t, overrides.Table)
+}
+
+func TestCheck_InvalidSyncStandbyNames(t *testing.T) {
+	t.Parallel()
+
+	queryer := &mockQueryer{sync: db.SynchronousStandbyConfigRow{SynchronousStandbyNames: "ANY standby1", SynchronousCommit: "on"}}
+	_, err := replicationlag.New(queryer).Check(context.Background())
+	require.Error(t, err)
+}
@@ -19,12 +19,48 @@ SELECT
   -- Lag metrics (seconds) - cast to float8 (double precision) to get native float64
   , COALESCE(EXTRACT(EPOCH FROM sr.replay_lag), 0)::float8 AS replay_lag_seconds
 
+  -- Time to write and flush WAL on the replica, before any apply delay (NULL when idle)
+  , EXTRACT(EPOCH FROM sr.flush_lag)::float8 AS flush_lag_seconds
+
   -- Associated replication slot (NULL if no slot)
   , rs.slot_name::text AS slot_name
   , rs.wal_status::text AS wal_status
 
+  -- Synchronous replication role: async, potential, sync, or quorum
+  , sr.sync_state::text AS sync_state
+
 FROM pg_stat_replication AS sr
 LEFT JOIN pg_replication_slots AS rs ON sr.pid = rs.active_pid
 ORDER BY
   EXTRACT(EPOCH FROM sr.replay_lag) DESC NULLS LAST
   , sr.application_name;
+
+-- name: SynchronousStandbyConfig :one
+-- Configured synchronous standbys, the cluster-wide synchronous_commit default,
+-- and whether this server is a standby, where neither applies.
+SELECT
+  current_setting('synchronous_standby_names')::text AS synchronous_standby_names
+  -- reset_val includes ALTER DATABASE/ROLE settings for this session, so it is
+  -- only the cluster default when the server configuration set it.
+  , (
+    SELECT CASE
+      WHEN source IN ('database', 'user', 'database user', 'client', 'session') THEN boot_val
+      ELSE reset_val
+    END
+    FROM pg_settings
+    WHERE name = 'synchronous_commit'
+  )::text AS synchronous_commit
+  , pg_is_in_recovery()::boolean AS in_recovery;
+
+-- name: SynchronousCommitOverrides :many
+-- Per-database and per-role synchronous_commit overrides (ALTER DATABASE/ROLE ... SET).
+SELECT
+  COALESCE(d.datname, '')::text AS database_name
+  , COALESCE(r.rolname, '')::text AS role_name
+  , SPLIT_PART(cfg.setting, '=', 2)::text AS value
+FROM pg_db_role_setting AS s
+CROSS JOIN LATERAL UNNEST(s.setconfig) AS cfg (setting)
+LEFT JOIN pg_database AS d ON s.setdatabase = d.oid
+LEFT JOIN pg_roles AS r ON s.setrole = r.oid
+WHERE cfg.setting LIKE 'synchronous_commit=%'
+ORDER BY database_name, role_name;
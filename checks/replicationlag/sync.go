@@ -0,0 +1,241 @@
+package replicationlag
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/fresha/pgdoctor/check"
+	"github.com/fresha/pgdoctor/db"
+)
+
+// syncConfig is a parsed synchronous_standby_names value.
+type syncConfig struct {
+	method string // FIRST (priority) or ANY (quorum)
+	num    int    // standbys each commit waits for
+	names  []string
+}
+
+func (s syncConfig) configured() bool {
+	return len(s.names) > 0
+}
+
+// matches reports whether a standby's application_name is listed. PostgreSQL
+// compares standby names case-insensitively, and * matches any standby.
+func (s syncConfig) matches(appName string) bool {
+	for _, name := range s.names {
+		if name == "*" || strings.EqualFold(name, appName) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseSyncStandbyNames parses the FIRST n (...), ANY n (...), n (...), and
+// legacy comma-separated forms of synchronous_standby_names.
+func parseSyncStandbyNames(value string) (syncConfig, error) {
+	cfg := syncConfig{method: "FIRST", num: 1}
+	rest := strings.TrimSpace(value)
+	if rest == "" {
+		return cfg, nil
+	}
+
+	upper := strings.ToUpper(rest)
+	explicitMethod := false
+	for _, method := range []string{"FIRST", "ANY"} {
+		if strings.HasPrefix(upper, method+" ") {
+			cfg.method = method
+			rest = strings.TrimSpace(rest[len(method):])
+			explicitMethod = true
+			break
+		}
+	}
+
+	if open := strings.Index(rest, "("); open >= 0 {
+		if !strings.HasSuffix(rest, ")") {
+			return cfg, fmt.Errorf("invalid synchronous_standby_names %q", value)
+		}
+		num, err := strconv.Atoi(strings.TrimSpace(rest[:open]))
+		if err != nil || num < 1 {
+			return cfg, fmt.Errorf("invalid synchronous_standby_names %q: bad standby count", value)
+		}
+		cfg.num = num
+		rest = rest[open+1 : len(rest)-1]
+	} else if explicitMethod {
+		return cfg, fmt.Errorf("invalid synchronous_standby_names %q: missing standby list", value)
+	}
+
+	for _, name := range strings.Split(rest, ",") {
+		name = strings.Trim(strings.TrimSpace(name), `"`)
+		if name != "" {
+			cfg.names = append(cfg.names, name)
+		}
+	}
+	return cfg, nil
+}
+
+// commitsWaitForStandbys reports whether a synchronous_commit level makes
+// commits block until synchronous standbys confirm them.
+func commitsWaitForStandbys(level string) bool {
+	switch strings.ToLower(level) {
+	case "on", "remote_write", "remote_apply":
+		return true
+	default:
+		return false
+	}
+}
+
+// checkSync reports sync-standbys and synchronous-commit-overrides. A
+// standby ignores synchronous_standby_names, which it usually carries over
+// from the primary's configuration, and never waits for its own cascaded
+// standbys, so both findings only apply on the primary.
+func checkSync(cfg syncConfig, settings db.SynchronousStandbyConfigRow, rows []db.ReplicationLagRow, overrides []db.SynchronousCommitOverridesRow, report *check.Report) {
+	if settings.InRecovery {
+		report.AddFinding(check.Finding{
+			ID:       "sync-standbys",
+			Name:     "Synchronous Standbys",
+			Severity: check.SeverityOK,
+			Details:  "This server is a standby; synchronous_standby_names only takes effect on the primary, so check it there",
+		})
+		report.AddFinding(check.Finding{
+			ID:       "synchronous-commit-overrides",
+			Name:     "synchronous_commit Overrides",
+			Severity: check.SeverityOK,
+			Details:  "This server is a standby; synchronous_commit overrides only affect commits on the primary, so check them there",
+		})
+		return
+	}
+	checkSyncStandbys(cfg, settings.SynchronousCommit, rows, report)
+	checkSynchronousCommitOverrides(cfg, settings.SynchronousCommit, overrides, report)
+}
+
+func checkSyncStandbys(cfg syncConfig, synchronousCommit string, rows []db.ReplicationLagRow, report *check.Report) {
+	if !cfg.configured() {
+		report.AddFinding(check.Finding{
+			ID:       "sync-standbys",
+			Name:     "Synchronous Standbys",
+			Severity: check.SeverityOK,
+			Details:  fmt.Sprintf("synchronous_standby_names is empty; all %d stream(s) replicate asynchronously", len(rows)),
+		})
+		return
+	}
+
+	connected := 0
+	connectedNames := map[string]db.ReplicationLagRow{}
+	for _, row := range rows {
+		appName := row.ApplicationName.String
+		if row.State.String != "streaming" || !cfg.matches(appName) {
+			continue
+		}
+		connected++
+		connectedNames[strings.ToLower(appName)] = row
+	}
+
+	var tableRows []check.TableRow
+	missing := 0
+	for _, name := range cfg.names {
+		if name == "*" {
+			continue
+		}
+		row, ok := connectedNames[strings.ToLower(name)]
+		if !ok {
+			missing++
+			tableRows = append(tableRows, check.TableRow{
+				Cells:    []string{name, "not connected", "-"},
+				Severity: check.SeverityWarn,
+			})
+			continue
+		}
+		tableRows = append(tableRows, check.TableRow{
+			Cells:    []string{name, row.State.String, row.SyncState.String},
+			Severity: check.SeverityOK,
+		})
+	}
+
+	setting := fmt.Sprintf("%s %d (%s)", cfg.method, cfg.num, strings.Join(cfg.names, ", "))
+	severity := check.SeverityOK
+	var details string
+
+	switch {
+	case connected < cfg.num && commitsWaitForStandbys(synchronousCommit):
+		severity = check.SeverityFail
+		details = fmt.Sprintf("Only %d of the %d synchronous standby(s) required by %s are streaming: commits with synchronous_commit=%s will hang until one reconnects",
+			connected, cfg.num, setting, synchronousCommit)
+	case connected < cfg.num:
+		severity = check.SeverityWarn
+		details = fmt.Sprintf("Only %d of the %d synchronous standby(s) required by %s are streaming. synchronous_commit=%s does not wait, but roles that override it to on will hang",
+			connected, cfg.num, setting, synchronousCommit)
+	case missing > 0:
+		severity = check.SeverityWarn
+		details = fmt.Sprintf("%d configured synchronous standby(s) not connected; %s is still satisfied by %d streaming standby(s), with less margin for another failure",
+			missing, setting, connected)
+	default:
+		details = fmt.Sprintf("%d streaming standby(s) satisfy %s", connected, setting)
+	}
+
+	finding := check.Finding{
+		ID:       "sync-standbys",
+		Name:     "Synchronous Standbys",
+		Severity: severity,
+		Details:  details,
+	}
+	if len(tableRows) > 0 {
+		finding.Table = &check.Table{
+			Headers: []string{"Standby", "State", "Sync State"},
+			Rows:    tableRows,
+		}
+	}
+	report.AddFinding(finding)
+}
+
+func checkSynchronousCommitOverrides(cfg syncConfig, synchronousCommit string, overrides []db.SynchronousCommitOverridesRow, report *check.Report) {
+	if !cfg.configured() {
+		report.AddFinding(check.Finding{
+			ID:       "synchronous-commit-overrides",
+			Name:     "synchronous_commit Overrides",
+			Severity: check.SeverityOK,
+			Details:  "No synchronous standbys configured; synchronous_commit overrides do not affect replication",
+		})
+		return
+	}
+
+	var tableRows []check.TableRow
+	for _, o := range overrides {
+		if strings.EqualFold(o.Value, synchronousCommit) {
+			continue
+		}
+		database, role := o.DatabaseName, o.RoleName
+		if database == "" {
+			database = "(all)"
+		}
+		if role == "" {
+			role = "(all)"
+		}
+		tableRows = append(tableRows, check.TableRow{
+			Cells:    []string{database, role, o.Value},
+			Severity: check.SeverityWarn,
+		})
+	}
+
+	if len(tableRows) == 0 {
+		report.AddFinding(check.Finding{
+			ID:       "synchronous-commit-overrides",
+			Name:     "synchronous_commit Overrides",
+			Severity: check.SeverityOK,
+			Details:  fmt.Sprintf("All databases and roles use the cluster default synchronous_commit=%s", synchronousCommit),
+		})
+		return
+	}
+
+	report.AddFinding(check.Finding{
+		ID:       "synchronous-commit-overrides",
+		Name:     "synchronous_commit Overrides",
+		Severity: check.SeverityWarn,
+		Details: fmt.Sprintf("%d database/role override(s) differ from the cluster default synchronous_commit=%s. Overrides to off or local skip standby confirmation; overrides to on can stall when sync standbys disconnect",
+			len(tableRows), synchronousCommit),
+		Table: &check.Table{
+			Headers: []string{"Database", "Role", "synchronous_commit"},
+			Rows:    tableRows,
+		},
+	})
+}
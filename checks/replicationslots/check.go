@@ -2,13 +2,17 @@
 package replicationslots
 
 import (
+	"cmp"
 	"context"
 	_ "embed"
 	"fmt"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/fresha/pgdoctor/check"
 	"github.com/fresha/pgdoctor/db"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 //go:embed query.sql
@@ -20,30 +24,80 @@ var readme string
 const (
 	lagWarnThreshold = 1 * check.GiB
 	lagFailThreshold = 5 * check.GiB
+
+	// Time-to-limit thresholds for growing slots when trend sampling is enabled.
+	etaWarnThreshold = 6 * time.Hour
+	etaFailThreshold = 1 * time.Hour
+
+	// A shrinking slot is left out of high-lag only when it will drain below
+	// lagWarnThreshold within this long; a slot shrinking by a few bytes per
+	// sample is still stuck for practical purposes.
+	drainThreshold = 1 * time.Hour
 )
 
 type ReplicationSlotsQueries interface {
 	ReplicationSlots(context.Context) ([]db.ReplicationSlotsRow, error)
 	ReplicationSlotsPG15(context.Context) ([]db.ReplicationSlotsPG15Row, error)
+	MaxSlotWalKeepSize(context.Context) (pgtype.Int8, error)
 }
 
 type checker struct {
 	queryer ReplicationSlotsQueries
+	// trendInterval is the delay between two slot samples used to estimate
+	// lag growth. Zero disables trend analysis (single sample).
+	trendInterval time.Duration
+}
+
+// slotTrend is the lag growth observed for a slot between two samples.
+type slotTrend struct {
+	slot        db.ReplicationSlotsRow
+	growthBytes int64         // lag delta between samples (negative when catching up)
+	eta         time.Duration // time until limitBytes at the current growth rate
+	drain       time.Duration // time until lag falls below lagWarnThreshold when shrinking
+	limitBytes  int64
+}
+
+// drainingSoon reports whether the slot is catching up fast enough to clear
+// high-lag on its own within drainThreshold.
+func (t slotTrend) drainingSoon() bool {
+	return t.growthBytes < 0 && t.drain < drainThreshold
 }
 
 func Metadata() check.Metadata {
 	return check.Metadata{
-		Category:    check.CategoryConfigs,
-		CheckID:     "replication-slots",
-		Name:        "Replication Slots",
-		Description: "Validates replication slot configuration and health status",
-		Readme:      readme,
-		SQL:         querySQL,
+		Category:       check.CategoryConfigs,
+		CheckID:        "replication-slots",
+		Name:           "Replication Slots",
+		Description:    "Validates replication slot configuration and health status",
+		Readme:         readme,
+		SQL:            querySQL,
+		RuntimeClass:   check.RuntimeFast,
+		ProductionSafe: true,
+		Priority:       check.PriorityCritical,
+		Findings: []check.FindingSpec{
+			{ID: "invalid-slots", Description: "Slots invalidated by the server", Thresholds: "FAIL"},
+			{ID: "lost-wal-slots", Description: "Slots whose required WAL has been removed", Thresholds: "FAIL"},
+			{ID: "conflicting-slots", Description: "Logical slots invalidated by recovery conflicts", Thresholds: "WARN"},
+			{ID: "inactive-slots", Description: "Slots with no connected consumer", Thresholds: "WARN"},
+			{ID: "critical-lag", Description: "Slots retaining a critical amount of WAL", Thresholds: "FAIL >= 5GiB"},
+			{ID: "high-lag", Description: "Slots retaining a large amount of WAL", Thresholds: "WARN >= 1GiB"},
+			{ID: "lag-growth", Description: "Slots whose retained WAL is growing toward the limit (requires trend_sample_interval)", Thresholds: "WARN ETA < 6h, FAIL ETA < 1h"},
+		},
 	}
 }
 
-func New(queryer ReplicationSlotsQueries, _ ...check.Config) check.Checker {
-	return &checker{queryer: queryer}
+func New(queryer ReplicationSlotsQueries, cfg ...check.Config) check.Checker {
+	c := &checker{queryer: queryer}
+	if len(cfg) > 0 && cfg[0] != nil {
+		if myCfg, ok := cfg[0][Metadata().CheckID]; ok {
+			if v, ok := myCfg["trend_sample_interval"]; ok {
+				if d, err := time.ParseDuration(v); err == nil && d > 0 {
+					c.trendInterval = d
+				}
+			}
+		}
+	}
+	return c
 }
 
 func (c *checker) Metadata() check.Metadata {
@@ -58,6 +112,19 @@ func (c *checker) Check(ctx context.Context) (*check.Report, error) {
 		return nil, fmt.Errorf("running %s/%s: %w", report.Category, report.CheckID, err)
 	}
 
+	trends, err := c.sampleTrends(ctx, slots)
+	if err != nil {
+		return nil, fmt.Errorf("running %s/%s (trend): %w", report.Category, report.CheckID, err)
+	}
+
+	var growingSlots []slotTrend
+	for _, slot := range slots {
+		trend, ok := trends[slot.SlotName.String]
+		if ok && trend.growthBytes > 0 && trend.eta < etaWarnThreshold {
+			growingSlots = append(growingSlots, trend)
+		}
+	}
+
 	var invalidSlots []db.ReplicationSlotsRow
 	var inactiveSlots []db.ReplicationSlotsRow
 	var conflictingSlots []db.ReplicationSlotsRow
@@ -90,9 +157,12 @@ func (c *checker) Check(ctx context.Context) (*check.Report, error) {
 
 		if slot.RestartLsnLagBytes.Valid {
 			lag := slot.RestartLsnLagBytes.Int64
+			trend, sampled := trends[slot.SlotName.String]
 			if lag >= lagFailThreshold {
+				// Critical lag is reported even while shrinking: the WAL is
+				// retained now, whatever the slot does next.
 				criticalLagSlots = append(criticalLagSlots, slot)
-			} else if lag >= lagWarnThreshold {
+			} else if lag >= lagWarnThreshold && !(sampled && trend.drainingSoon()) {
 				highLagSlots = append(highLagSlots, slot)
 			}
 		}
@@ -104,6 +174,7 @@ func (c *checker) Check(ctx context.Context) (*check.Report, error) {
 	reportInactiveSlots(report, inactiveSlots)
 	reportCriticalLagSlots(report, criticalLagSlots)
 	reportHighLagSlots(report, highLagSlots)
+	reportGrowingSlots(report, growingSlots, c.trendInterval)
 
 	// If no issues found
 	if len(report.Results) == 0 {
@@ -139,6 +210,70 @@ func (c *checker) fetchSlots(ctx context.Context) ([]db.ReplicationSlotsRow, err
 	return c.queryer.ReplicationSlots(ctx)
 }
 
+// Takes a second slot sample after trendInterval and estimates, for each active
+// slot, how long until its lag reaches max_slot_wal_keep_size (or the critical
+// lag threshold when the setting is unlimited). Returns nil when trend
+// analysis is disabled.
+func (c *checker) sampleTrends(ctx context.Context, first []db.ReplicationSlotsRow) (map[string]slotTrend, error) {
+	if c.trendInterval <= 0 {
+		return nil, nil
+	}
+
+	timer := time.NewTimer(c.trendInterval)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-timer.C:
+	}
+
+	second, err := c.fetchSlots(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	maxKeep, err := c.queryer.MaxSlotWalKeepSize(ctx)
+	if err != nil {
+		return nil, err
+	}
+	limit := int64(lagFailThreshold)
+	if maxKeep.Valid && maxKeep.Int64 > 0 {
+		limit = maxKeep.Int64
+	}
+
+	before := make(map[string]int64, len(first))
+	for _, slot := range first {
+		if slot.RestartLsnLagBytes.Valid {
+			before[slot.SlotName.String] = slot.RestartLsnLagBytes.Int64
+		}
+	}
+
+	trends := make(map[string]slotTrend, len(second))
+	for _, slot := range second {
+		prev, ok := before[slot.SlotName.String]
+		if !ok || !slot.RestartLsnLagBytes.Valid || !slot.Active.Bool {
+			continue
+		}
+
+		trend := slotTrend{
+			slot:        slot,
+			growthBytes: slot.RestartLsnLagBytes.Int64 - prev,
+			limitBytes:  limit,
+		}
+		switch {
+		case trend.growthBytes > 0:
+			remaining := max(limit-slot.RestartLsnLagBytes.Int64, 0)
+			trend.eta = time.Duration(float64(remaining) / float64(trend.growthBytes) * float64(c.trendInterval))
+		case trend.growthBytes < 0:
+			excess := max(slot.RestartLsnLagBytes.Int64-lagWarnThreshold, 0)
+			trend.drain = time.Duration(float64(excess) / float64(-trend.growthBytes) * float64(c.trendInterval))
+		}
+		trends[slot.SlotName.String] = trend
+	}
+
+	return trends, nil
+}
+
 func reportInvalidSlots(report *check.Report, slots []db.ReplicationSlotsRow) {
 	if len(slots) == 0 {
 		return
@@ -216,6 +351,7 @@ func reportInactiveSlots(report *check.Report, slots []db.ReplicationSlotsRow) {
 		Name:     "Inactive Replication Slots",
 		Severity: check.SeverityWarn,
 		Details:  fmt.Sprintf("Found %d inactive slot(s):\n%s\n\nInactive slots prevent WAL cleanup and can fill disk.", len(slots), strings.Join(lines, "\n")),
+		Impact:   fmt.Sprintf("Replication slot %s has no consumer but keeps WAL from being removed; disk fills until it is dropped or its consumer returns", laggiest(slots).SlotName.String),
 	})
 }
 
@@ -228,12 +364,22 @@ func reportCriticalLagSlots(report *check.Report, slots []db.ReplicationSlotsRow
 	for _, slot := range slots {
 		lines = append(lines, fmt.Sprintf("  %s (lag: %s)", slot.SlotName.String, check.FormatBytes(slot.RestartLsnLagBytes.Int64)))
 	}
+	worst := laggiest(slots)
 
 	report.AddFinding(check.Finding{
 		ID:       "critical-lag",
 		Name:     "Critical Replication Lag",
 		Severity: check.SeverityFail,
 		Details:  fmt.Sprintf("Found %d slot(s) with critical lag (>= 5GB):\n%s\n\nConsumers are severely behind and may never catch up.", len(slots), strings.Join(lines, "\n")),
+		Impact: fmt.Sprintf("Replication slot %s is holding back %s of WAL; disk fills if its consumer does not catch up",
+			worst.SlotName.String, check.FormatBytes(worst.RestartLsnLagBytes.Int64)),
+	})
+}
+
+// laggiest returns the slot retaining the most WAL.
+func laggiest(slots []db.ReplicationSlotsRow) db.ReplicationSlotsRow {
+	return slices.MaxFunc(slots, func(a, b db.ReplicationSlotsRow) int {
+		return cmp.Compare(a.RestartLsnLagBytes.Int64, b.RestartLsnLagBytes.Int64)
 	})
 }
 
@@ -254,3 +400,44 @@ func reportHighLagSlots(report *check.Report, slots []db.ReplicationSlotsRow) {
 		Details:  fmt.Sprintf("Found %d slot(s) with high lag (>= 1GB):\n%s\n\nConsumers are falling behind.", len(slots), strings.Join(lines, "\n")),
 	})
 }
+
+func reportGrowingSlots(report *check.Report, trends []slotTrend, interval time.Duration) {
+	if len(trends) == 0 {
+		return
+	}
+
+	severity := check.SeverityWarn
+	var rows []check.TableRow
+	for _, trend := range trends {
+		rowSeverity := check.SeverityWarn
+		if trend.eta < etaFailThreshold {
+			rowSeverity = check.SeverityFail
+			severity = check.SeverityFail
+		}
+
+		perMinute := float64(trend.growthBytes) / interval.Minutes()
+		rows = append(rows, check.TableRow{
+			Cells: []string{
+				trend.slot.SlotName.String,
+				check.FormatBytes(trend.slot.RestartLsnLagBytes.Int64),
+				check.FormatBytes(int64(perMinute)) + "/min",
+				check.FormatDurationSec(int64(trend.eta.Seconds())),
+				check.FormatBytes(trend.limitBytes),
+			},
+			Severity: rowSeverity,
+		})
+	}
+
+	report.AddFinding(check.Finding{
+		ID:       "lag-growth",
+		Name:     "Growing Replication Lag",
+		Severity: severity,
+		Details: fmt.Sprintf("Found %d slot(s) whose lag is growing fast enough to reach the WAL retention limit within %s.\n\n"+
+			"Once max_slot_wal_keep_size is exceeded the slot is invalidated and the consumer must be rebuilt.",
+			len(trends), check.FormatDurationSec(int64(etaWarnThreshold.Seconds()))),
+		Table: &check.Table{
+			Headers: []string{"Slot", "Lag", "Growth", "ETA to Limit", "Limit"},
+			Rows:    rows,
+		},
+	})
+}
@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/fresha/pgdoctor/check"
+	"github.com/fresha/pgdoctor/check/checktest"
 	"github.com/fresha/pgdoctor/checks/replicationslots"
 	"github.com/fresha/pgdoctor/db"
 	"github.com/jackc/pgx/v5/pgtype"
@@ -20,6 +21,11 @@ type mockQueryer struct {
 	pg17Called bool
 	pg15Called bool
 	err        error
+
+	// Second sample returned when trend analysis is enabled.
+	pg15SlotsAfter []db.ReplicationSlotsPG15Row
+	pg15Calls      int
+	maxKeepBytes   pgtype.Int8
 }
 
 func (m *mockQueryer) ReplicationSlots(context.Context) ([]db.ReplicationSlotsRow, error) {
@@ -32,12 +38,20 @@ func (m *mockQueryer) ReplicationSlots(context.Context) ([]db.ReplicationSlotsRo
 
 func (m *mockQueryer) ReplicationSlotsPG15(context.Context) ([]db.ReplicationSlotsPG15Row, error) {
 	m.pg15Called = true
+	m.pg15Calls++
 	if m.err != nil {
 		return nil, m.err
 	}
+	if m.pg15Calls > 1 && m.pg15SlotsAfter != nil {
+		return m.pg15SlotsAfter, nil
+	}
 	return m.pg15Slots, nil
 }
 
+func (m *mockQueryer) MaxSlotWalKeepSize(context.Context) (pgtype.Int8, error) {
+	return m.maxKeepBytes, nil
+}
+
 func pgText(s string) pgtype.Text {
 	return pgtype.Text{String: s, Valid: true}
 }
@@ -664,3 +678,140 @@ func TestCheck_FormatBytes(t *testing.T) {
 		})
 	}
 }
+
+func activeSlotWithLag(name string, lagBytes int64) db.ReplicationSlotsPG15Row {
+	slot := healthySlot(name)
+	slot.RestartLsnLagBytes = pgInt8(lagBytes)
+	return db.ReplicationSlotsPG15Row(slot)
+}
+
+func trendConfig(interval string) check.Config {
+	return check.Config{"replication-slots": {"trend_sample_interval": interval}}
+}
+
+func TestCheck_Trend_GrowingSlotFails(t *testing.T) {
+	t.Parallel()
+
+	// 800MB growing 100MB per sample toward a 1GiB limit: ~2 samples left.
+	queryer := &mockQueryer{
+		pg15Slots:      []db.ReplicationSlotsPG15Row{activeSlotWithLag("cdc", 700*check.MiB)},
+		pg15SlotsAfter: []db.ReplicationSlotsPG15Row{activeSlotWithLag("cdc", 800*check.MiB)},
+		maxKeepBytes:   pgInt8(1 * check.GiB),
+	}
+	checker := replicationslots.New(queryer, trendConfig("1ms"))
+
+	report, err := checker.Check(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, check.SeverityFail, report.Severity)
+	require.Len(t, report.Results, 1)
+	assert.Equal(t, "lag-growth", report.Results[0].ID)
+	require.NotNil(t, report.Results[0].Table)
+	assert.Equal(t, "cdc", report.Results[0].Table.Rows[0].Cells[0])
+	assert.Equal(t, "1.0GiB", report.Results[0].Table.Rows[0].Cells[4])
+}
+
+func TestCheck_Trend_ShrinkingSlotIsOK(t *testing.T) {
+	t.Parallel()
+
+	// Above the high-lag threshold, but catching up.
+	queryer := &mockQueryer{
+		pg15Slots:      []db.ReplicationSlotsPG15Row{activeSlotWithLag("cdc", 2*check.GiB)},
+		pg15SlotsAfter: []db.ReplicationSlotsPG15Row{activeSlotWithLag("cdc", 1536*check.MiB)},
+		maxKeepBytes:   pgInt8(-1),
+	}
+	checker := replicationslots.New(queryer, trendConfig("1ms"))
+
+	report, err := checker.Check(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, check.SeverityOK, report.Severity)
+	require.Len(t, report.Results, 1)
+	assert.Equal(t, "replication-slots", report.Results[0].ID)
+}
+
+func TestCheck_Trend_SlowlyShrinkingSlotStillWarns(t *testing.T) {
+	t.Parallel()
+
+	// Shrinking 1 byte per 1ms sample: draining the 512MiB above the
+	// high-lag threshold takes days.
+	queryer := &mockQueryer{
+		pg15Slots:      []db.ReplicationSlotsPG15Row{activeSlotWithLag("cdc", 1536*check.MiB+1)},
+		pg15SlotsAfter: []db.ReplicationSlotsPG15Row{activeSlotWithLag("cdc", 1536*check.MiB)},
+		maxKeepBytes:   pgInt8(-1),
+	}
+	checker := replicationslots.New(queryer, trendConfig("1ms"))
+
+	report, err := checker.Check(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, check.SeverityWarn, report.Severity)
+	checktest.Finding(t, report, "high-lag")
+}
+
+func TestCheck_Trend_ShrinkingCriticalSlotStillFails(t *testing.T) {
+	t.Parallel()
+
+	queryer := &mockQueryer{
+		pg15Slots:      []db.ReplicationSlotsPG15Row{activeSlotWithLag("cdc", 8*check.GiB)},
+		pg15SlotsAfter: []db.ReplicationSlotsPG15Row{activeSlotWithLag("cdc", 6*check.GiB)},
+		maxKeepBytes:   pgInt8(-1),
+	}
+	checker := replicationslots.New(queryer, trendConfig("1ms"))
+
+	report, err := checker.Check(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, check.SeverityFail, report.Severity)
+	checktest.Finding(t, report, "critical-lag")
+}
+
+func TestCheck_Trend_SlowGrowthIgnored(t *testing.T) {
+	t.Parallel()
+
+	// Growing 100 bytes per 1ms sample toward an unlimited setting (critical-lag
+	// fallback of 5GiB): ETA is well beyond the warning horizon.
+	queryer := &mockQueryer{
+		pg15Slots:      []db.ReplicationSlotsPG15Row{activeSlotWithLag("cdc", 100*check.MiB)},
+		pg15SlotsAfter: []db.ReplicationSlotsPG15Row{activeSlotWithLag("cdc", 100*check.MiB+100)},
+		maxKeepBytes:   pgInt8(-1),
+	}
+	checker := replicationslots.New(queryer, trendConfig("1ms"))
+
+	report, err := checker.Check(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, check.SeverityOK, report.Severity)
+	assert.Equal(t, 2, queryer.pg15Calls)
+}
+
+func TestCheck_Trend_ContextCancelled(t *testing.T) {
+	t.Parallel()
+
+	queryer := &mockQueryer{
+		pg15Slots: []db.ReplicationSlotsPG15Row{activeSlotWithLag("cdc", 100*check.MiB)},
+	}
+	checker := replicationslots.New(queryer, trendConfig("1h"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := checker.Check(ctx)
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+func TestCheck_Trend_DisabledByDefault(t *testing.T) {
+	t.Parallel()
+
+	queryer := &mockQueryer{
+		pg15Slots:      []db.ReplicationSlotsPG15Row{activeSlotWithLag("cdc", 800*check.MiB)},
+		pg15SlotsAfter: []db.ReplicationSlotsPG15Row{activeSlotWithLag("cdc", 900*check.MiB)},
+	}
+	checker := replicationslots.New(queryer)
+
+	report, err := checker.Check(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, check.SeverityOK, report.Severity)
+	assert.Equal(t, 1, queryer.pg15Calls)
+}
@@ -56,3 +56,7 @@ ORDER BY
     ELSE 4
   END
   , restart_lsn_lag_bytes DESC NULLS LAST;
+
+-- name: MaxSlotWalKeepSize :one
+-- Returns max_slot_wal_keep_size in bytes (-1 means unlimited).
+SELECT PG_SIZE_BYTES(CURRENT_SETTING('max_slot_wal_keep_size'))::BIGINT AS max_slot_wal_keep_size_bytes;
@@ -0,0 +1,379 @@
+// Package securitysettings implements checks for TLS, client authentication
+// methods, password hashing, and CREATE privileges granted to PUBLIC.
+package securitysettings
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/fresha/pgdoctor/check"
+	"github.com/fresha/pgdoctor/db"
+	"github.com/fresha/pgdoctor/internal/pgident"
+)
+
+//go:embed query.sql
+var querySQL string
+
+//go:embed README.md
+var readme string
+
+// Role names listed in the password-encryption finding before truncating.
+const maxListedRoles = 10
+
+type SecuritySettingsQueries interface {
+	SecuritySettings(context.Context) (db.SecuritySettingsRow, error)
+	HbaRules(context.Context) ([]db.HbaRulesRow, error)
+	Md5PasswordRoles(context.Context) (db.Md5PasswordRolesRow, error)
+	PublicCreateSchemas(context.Context) ([]db.PublicCreateSchemasRow, error)
+}
+
+type checker struct {
+	queries SecuritySettingsQueries
+}
+
+func Metadata() check.Metadata {
+	return check.Metadata{
+		Category:       check.CategorySecurity,
+		CheckID:        "security-settings",
+		Name:           "Security Settings",
+		Description:    "Checks TLS, pg_hba.conf authentication methods, password hashing, and schemas where any role can create objects",
+		Readme:         readme,
+		SQL:            querySQL,
+		RuntimeClass:   check.RuntimeFast,
+		ProductionSafe: true,
+		Privileges:     []check.Privilege{check.PrivilegeHbaRules},
+		Findings: []check.FindingSpec{
+			{ID: "ssl", Description: "Whether TLS is enabled, the minimum protocol version, and pg_hba.conf rules that accept remote connections without it", Thresholds: "FAIL ssl off while listening beyond localhost, WARN non-TLS host rules or TLS below 1.2"},
+			{ID: "hba-auth-methods", Description: "pg_hba.conf rules using trust or cleartext password authentication (needs superuser to read)", Thresholds: "FAIL for remote non-TLS rules, WARN for local or TLS-only rules"},
+			{ID: "password-encryption", Description: "MD5 password hashing in password_encryption, stored role passwords, or pg_hba.conf", Thresholds: "WARN"},
+			{ID: "public-schema-create", Description: "Schemas where PUBLIC holds CREATE, letting any role create objects that shadow others", Thresholds: "WARN"},
+		},
+	}
+}
+
+func New(queries SecuritySettingsQueries, _ ...check.Config) check.Checker {
+	return &checker{
+		queries: queries,
+	}
+}
+
+func (c *checker) Metadata() check.Metadata {
+	return Metadata()
+}
+
+func (c *checker) Check(ctx context.Context) (*check.Report, error) {
+	report := check.NewReport(Metadata())
+	ignore := check.ObjectFilterFromContext(ctx)
+
+	settings, err := c.queries.SecuritySettings(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("running %s/%s (settings): %w", report.Category, report.CheckID, err)
+	}
+
+	var rules []db.HbaRulesRow
+	if settings.CanReadHba {
+		rules, err = c.queries.HbaRules(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("running %s/%s (hba rules): %w", report.Category, report.CheckID, err)
+		}
+	}
+
+	var md5Roles db.Md5PasswordRolesRow
+	if settings.CanReadAuthid {
+		md5Roles, err = c.queries.Md5PasswordRoles(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("running %s/%s (md5 roles): %w", report.Category, report.CheckID, err)
+		}
+	}
+
+	schemas, err := c.queries.PublicCreateSchemas(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("running %s/%s (schemas): %w", report.Category, report.CheckID, err)
+	}
+	schemas = slices.DeleteFunc(schemas, func(row db.PublicCreateSchemasRow) bool {
+		return ignore.Schema(row.SchemaName)
+	})
+
+	checkSSL(settings, rules, report)
+	checkHbaAuthMethods(settings, rules, report)
+	checkPasswordEncryption(settings, rules, md5Roles, report)
+	checkPublicSchemaCreate(schemas, report)
+
+	return report, nil
+}
+
+// listensLocallyOnly reports whether listen_addresses only binds loopback
+// interfaces or, when empty, only the Unix socket.
+func listensLocallyOnly(listenAddresses string) bool {
+	for _, addr := range strings.Split(listenAddresses, ",") {
+		switch strings.TrimSpace(addr) {
+		case "", "localhost", "127.0.0.1", "::1":
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// isLocalRule reports whether a pg_hba.conf rule only matches connections
+// from the server itself.
+func isLocalRule(r db.HbaRulesRow) bool {
+	return r.ConnectionType == "local" ||
+		r.Address == "samehost" ||
+		strings.HasPrefix(r.Address, "127.") ||
+		r.Address == "::1"
+}
+
+// allowsPlaintext reports whether a host rule accepts connections that are
+// neither TLS nor GSSAPI encrypted.
+func allowsPlaintext(r db.HbaRulesRow) bool {
+	switch r.ConnectionType {
+	case "host", "hostnossl", "hostnogssenc":
+		return true
+	}
+	return false
+}
+
+func ruleAddress(r db.HbaRulesRow) string {
+	if r.Address == "" {
+		return "-"
+	}
+	if r.Netmask != "" {
+		return r.Address + " " + r.Netmask
+	}
+	return r.Address
+}
+
+func hbaRow(r db.HbaRulesRow, severity check.Severity) check.TableRow {
+	return check.TableRow{
+		Cells:    []string{fmt.Sprintf("%d", r.LineNumber), r.ConnectionType, r.DatabaseNames, r.UserNames, ruleAddress(r), r.AuthMethod},
+		Severity: severity,
+	}
+}
+
+var hbaHeaders = []string{"Line", "Type", "Database", "User", "Address", "Method"}
+
+func checkSSL(settings db.SecuritySettingsRow, rules []db.HbaRulesRow, report *check.Report) {
+	if settings.Ssl != "on" {
+		if listensLocallyOnly(settings.ListenAddresses) {
+			report.AddFinding(check.Finding{
+				ID:       "ssl",
+				Name:     "TLS",
+				Severity: check.SeverityOK,
+				Details:  fmt.Sprintf("ssl is off, but the server only accepts local connections (listen_addresses = '%s')", settings.ListenAddresses),
+			})
+			return
+		}
+		report.AddFinding(check.Finding{
+			ID:       "ssl",
+			Name:     "TLS",
+			Severity: check.SeverityFail,
+			Details: fmt.Sprintf("ssl is off while the server listens on '%s': passwords (unless SCRAM), queries, and results cross the network unencrypted. "+
+				"Install a certificate and key (ssl_cert_file, ssl_key_file), set ssl = on and reload, then change host rules in pg_hba.conf to hostssl",
+				settings.ListenAddresses),
+		})
+		return
+	}
+
+	severity := check.SeverityOK
+	var problems []string
+	switch settings.SslMinProtocolVersion {
+	case "TLSv1", "TLSv1.1":
+		severity = check.SeverityWarn
+		problems = append(problems, fmt.Sprintf("ssl_min_protocol_version is %s, which is deprecated and allows downgrade attacks; set it to TLSv1.2 or later", settings.SslMinProtocolVersion))
+	}
+
+	var tableRows []check.TableRow
+	for _, r := range rules {
+		if r.AuthMethod == "reject" || isLocalRule(r) || !allowsPlaintext(r) {
+			continue
+		}
+		tableRows = append(tableRows, hbaRow(r, check.SeverityWarn))
+	}
+	if len(tableRows) > 0 {
+		severity = check.SeverityWarn
+		problems = append(problems, fmt.Sprintf("%d pg_hba.conf rule(s) accept remote connections without TLS, so a client that does not ask for it (sslmode=prefer falls back silently) connects in plaintext; "+
+			"change them to hostssl once every client connects with TLS", len(tableRows)))
+	}
+
+	if severity == check.SeverityOK {
+		details := "ssl is on"
+		if settings.SslMinProtocolVersion != "" {
+			details += fmt.Sprintf(" with ssl_min_protocol_version %s", settings.SslMinProtocolVersion)
+		}
+		if settings.CanReadHba {
+			details += ", and no pg_hba.conf rule accepts remote connections without encryption"
+		}
+		report.AddFinding(check.Finding{
+			ID:       "ssl",
+			Name:     "TLS",
+			Severity: check.SeverityOK,
+			Details:  details,
+		})
+		return
+	}
+
+	finding := check.Finding{
+		ID:       "ssl",
+		Name:     "TLS",
+		Severity: severity,
+		Details:  "ssl is on, but " + strings.Join(problems, "; "),
+	}
+	if len(tableRows) > 0 {
+		finding.Table = &check.Table{Headers: hbaHeaders, Rows: tableRows}
+	}
+	report.AddFinding(finding)
+}
+
+func checkHbaAuthMethods(settings db.SecuritySettingsRow, rules []db.HbaRulesRow, report *check.Report) {
+	if !settings.CanReadHba {
+		report.AddFinding(check.Finding{
+			ID:       "hba-auth-methods",
+			Name:     "Authentication Methods",
+			Severity: check.SeverityOK,
+			Details:  "Reading pg_hba_file_rules requires superuser; pg_hba.conf was not checked",
+		})
+		return
+	}
+
+	severity := check.SeverityOK
+	var tableRows []check.TableRow
+	trust, password := 0, 0
+	for _, r := range rules {
+		rowSeverity := check.SeverityWarn
+		switch r.AuthMethod {
+		case "trust":
+			trust++
+			if !isLocalRule(r) {
+				rowSeverity = check.SeverityFail
+			}
+		case "password":
+			password++
+			if !isLocalRule(r) && r.ConnectionType != "hostssl" {
+				rowSeverity = check.SeverityFail
+			}
+		default:
+			continue
+		}
+		severity = max(severity, rowSeverity)
+		tableRows = append(tableRows, hbaRow(r, rowSeverity))
+	}
+
+	if len(tableRows) == 0 {
+		report.AddFinding(check.Finding{
+			ID:       "hba-auth-methods",
+			Name:     "Authentication Methods",
+			Severity: check.SeverityOK,
+			Details:  fmt.Sprintf("None of the %d pg_hba.conf rule(s) use trust or password authentication", len(rules)),
+		})
+		return
+	}
+
+	var parts []string
+	if trust > 0 {
+		parts = append(parts, fmt.Sprintf("%d use trust, which lets anyone who can reach the server connect as any matching role without a password", trust))
+	}
+	if password > 0 {
+		parts = append(parts, fmt.Sprintf("%d use password, which sends the password in cleartext", password))
+	}
+	details := fmt.Sprintf("Of %d pg_hba.conf rule(s), %s. Rules matching remote addresses without TLS are FAIL. "+
+		"Use scram-sha-256 for password logins and peer for local Unix-socket connections, then reload (SELECT pg_reload_conf())",
+		len(rules), strings.Join(parts, "; "))
+
+	report.AddFinding(check.Finding{
+		ID:       "hba-auth-methods",
+		Name:     "Authentication Methods",
+		Severity: severity,
+		Details:  details,
+		Table:    &check.Table{Headers: hbaHeaders, Rows: tableRows},
+	})
+}
+
+func checkPasswordEncryption(settings db.SecuritySettingsRow, rules []db.HbaRulesRow, md5Roles db.Md5PasswordRolesRow, report *check.Report) {
+	md5Rules := 0
+	for _, r := range rules {
+		if r.AuthMethod == "md5" {
+			md5Rules++
+		}
+	}
+
+	var problems []string
+	if settings.PasswordEncryption == "md5" {
+		problems = append(problems, "password_encryption is md5, so every password set from now on is stored as an MD5 hash")
+	}
+	if md5Roles.RoleCount > 0 {
+		names := md5Roles.RoleNames
+		if len(names) > maxListedRoles {
+			names = append(names[:maxListedRoles:maxListedRoles], fmt.Sprintf("and %d more", len(md5Roles.RoleNames)-maxListedRoles))
+		}
+		problems = append(problems, fmt.Sprintf("%d login role(s) still have MD5 password hashes (%s); they keep using MD5 until their password is set again", md5Roles.RoleCount, strings.Join(names, ", ")))
+	}
+	if md5Rules > 0 {
+		problems = append(problems, fmt.Sprintf("%d pg_hba.conf rule(s) use md5, which accepts MD5 hashes; once no role has one, change them to scram-sha-256", md5Rules))
+	}
+
+	if len(problems) == 0 {
+		details := fmt.Sprintf("password_encryption is %s", settings.PasswordEncryption)
+		if settings.CanReadAuthid {
+			details += " and no login role has an MD5 password hash"
+		} else {
+			details += "; stored password hashes were not checked (reading pg_authid requires superuser)"
+		}
+		report.AddFinding(check.Finding{
+			ID:       "password-encryption",
+			Name:     "Password Encryption",
+			Severity: check.SeverityOK,
+			Details:  details,
+		})
+		return
+	}
+
+	report.AddFinding(check.Finding{
+		ID:       "password-encryption",
+		Name:     "Password Encryption",
+		Severity: check.SeverityWarn,
+		Details: "MD5 password authentication is still in use: " + strings.Join(problems, "; ") + ". " +
+			"An MD5 hash is enough to log in as the role if it leaks, and MD5 passwords are deprecated since PostgreSQL 18. " +
+			"Set password_encryption = 'scram-sha-256', reset each listed role's password (\\password in psql, or ALTER ROLE ... PASSWORD from a client that hashes it), " +
+			"and confirm every client driver supports SCRAM before changing md5 rules",
+	})
+}
+
+func checkPublicSchemaCreate(schemas []db.PublicCreateSchemasRow, report *check.Report) {
+	if len(schemas) == 0 {
+		report.AddFinding(check.Finding{
+			ID:       "public-schema-create",
+			Name:     "PUBLIC CREATE on Schemas",
+			Severity: check.SeverityOK,
+			Details:  "PUBLIC does not hold CREATE on any schema",
+		})
+		return
+	}
+
+	var names []string
+	var fixes []check.Fix
+	for _, s := range schemas {
+		names = append(names, s.SchemaName)
+		fixes = append(fixes, check.Fix{
+			Object: s.SchemaName,
+			Description: fmt.Sprintf("Stop roles other than the owner (%s) and explicit grantees from creating objects in %s. Grant CREATE to the roles that run migrations first if they do not own the schema",
+				s.Owner, s.SchemaName),
+			SQL:  fmt.Sprintf("REVOKE CREATE ON SCHEMA %s FROM PUBLIC", pgident.Quote(s.SchemaName)),
+			Lock: check.LockOnline,
+		})
+	}
+
+	report.AddFinding(check.Finding{
+		ID:       "public-schema-create",
+		Name:     "PUBLIC CREATE on Schemas",
+		Severity: check.SeverityWarn,
+		Details: fmt.Sprintf("PUBLIC holds CREATE on %d schema(s): %s. Any role, including read-only and application roles, can create tables and functions there; "+
+			"an object named like a built-in function or another schema's table is picked up by every session whose search_path includes the schema (CVE-2018-1058). "+
+			"PostgreSQL 15 stopped granting this on the public schema, but databases created earlier keep the old grant after an upgrade",
+			len(schemas), strings.Join(names, ", ")),
+		Fixes: fixes,
+	})
+}
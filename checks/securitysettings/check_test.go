@@ -0,0 +1,295 @@
+package securitysettings_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/fresha/pgdoctor/check"
+	"github.com/fresha/pgdoctor/check/checktest"
+	"github.com/fresha/pgdoctor/checks/securitysettings"
+	"github.com/fresha/pgdoctor/db"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockQueryer struct {
+	settings db.SecuritySettingsRow
+	rules    []db.HbaRulesRow
+	md5Roles db.Md5PasswordRolesRow
+	schemas  []db.PublicCreateSchemasRow
+	err      error
+
+	rulesCalled bool
+	rolesCalled bool
+}
+
+func (m *mockQueryer) SecuritySettings(context.Context) (db.SecuritySettingsRow, error) {
+	return m.settings, m.err
+}
+
+func (m *mockQueryer) HbaRules(context.Context) ([]db.HbaRulesRow, error) {
+	m.rulesCalled = true
+	return m.rules, nil
+}
+
+func (m *mockQueryer) Md5PasswordRoles(context.Context) (db.Md5PasswordRolesRow, error) {
+	m.rolesCalled = true
+	return m.md5Roles, nil
+}
+
+func (m *mockQueryer) PublicCreateSchemas(context.Context) ([]db.PublicCreateSchemasRow, error) {
+	return m.schemas, nil
+}
+
+func rule(line int32, connType, address, method string) db.HbaRulesRow {
+	return db.HbaRulesRow{
+		LineNumber:     line,
+		ConnectionType: connType,
+		DatabaseNames:  "all",
+		UserNames:      "all",
+		Address:        address,
+		AuthMethod:     method,
+	}
+}
+
+func healthyQueryer() *mockQueryer {
+	return &mockQueryer{
+		settings: db.SecuritySettingsRow{
+			Ssl:                   "on",
+			SslMinProtocolVersion: "TLSv1.2",
+			PasswordEncryption:    "scram-sha-256",
+			ListenAddresses:       "*",
+			ServerVersionNum:      160000,
+			CanReadHba:            true,
+			CanReadAuthid:         true,
+		},
+		rules: []db.HbaRulesRow{
+			rule(1, "local", "", "peer"),
+			rule(2, "hostssl", "10.0.0.0", "scram-sha-256"),
+			rule(3, "host", "0.0.0.0", "reject"),
+		},
+	}
+}
+
+func TestSecuritySettings_Healthy(t *testing.T) {
+	t.Parallel()
+
+	report, err := securitysettings.New(healthyQueryer()).Check(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, check.SeverityOK, report.Severity)
+	assert.Len(t, report.Results, 4)
+	assert.Equal(t, check.CategorySecurity, report.Category)
+}
+
+func TestSecuritySettings_SSL(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		ssl      string
+		minProto string
+		listen   string
+		rules    []db.HbaRulesRow
+		expected check.Severity
+		contains string
+	}{
+		{name: "on", ssl: "on", minProto: "TLSv1.2", listen: "*", expected: check.SeverityOK},
+		{name: "off remote", ssl: "off", listen: "*", expected: check.SeverityFail, contains: "listens on '*'"},
+		{name: "off local only", ssl: "off", listen: "localhost, ::1", expected: check.SeverityOK, contains: "only accepts local connections"},
+		{name: "off socket only", ssl: "off", listen: "", expected: check.SeverityOK},
+		{name: "old protocol", ssl: "on", minProto: "TLSv1", listen: "*", expected: check.SeverityWarn, contains: "TLSv1.2 or later"},
+		{
+			name: "plaintext host rule", ssl: "on", minProto: "TLSv1.2", listen: "*",
+			rules:    []db.HbaRulesRow{rule(4, "host", "10.0.0.0", "scram-sha-256")},
+			expected: check.SeverityWarn, contains: "1 pg_hba.conf rule(s) accept remote connections without TLS",
+		},
+		{
+			name: "loopback host rule", ssl: "on", minProto: "TLSv1.2", listen: "*",
+			rules:    []db.HbaRulesRow{rule(4, "host", "127.0.0.1", "scram-sha-256")},
+			expected: check.SeverityOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			q := healthyQueryer()
+			q.settings.Ssl = tt.ssl
+			q.settings.SslMinProtocolVersion = tt.minProto
+			q.settings.ListenAddresses = tt.listen
+			q.rules = append(q.rules, tt.rules...)
+
+			report, err := securitysettings.New(q).Check(context.Background())
+			require.NoError(t, err)
+
+			f := checktest.Finding(t, report, "ssl")
+			assert.Equal(t, tt.expected, f.Severity)
+			if tt.contains != "" {
+				assert.Contains(t, f.Details, tt.contains)
+			}
+		})
+	}
+}
+
+func TestSecuritySettings_HbaAuthMethods(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		rules    []db.HbaRulesRow
+		expected check.Severity
+		rows     int
+		contains string
+	}{
+		{name: "scram only", expected: check.SeverityOK},
+		{name: "local trust", rules: []db.HbaRulesRow{rule(4, "local", "", "trust")}, expected: check.SeverityWarn, rows: 1, contains: "1 use trust"},
+		{name: "remote trust", rules: []db.HbaRulesRow{rule(4, "host", "0.0.0.0", "trust")}, expected: check.SeverityFail, rows: 1},
+		{name: "password over tls", rules: []db.HbaRulesRow{rule(4, "hostssl", "10.0.0.0", "password")}, expected: check.SeverityWarn, rows: 1, contains: "cleartext"},
+		{name: "password over plaintext", rules: []db.HbaRulesRow{rule(4, "host", "10.0.0.0", "password")}, expected: check.SeverityFail, rows: 1},
+		{
+			name: "mixed",
+			rules: []db.HbaRulesRow{
+				rule(4, "local", "", "trust"),
+				rule(5, "hostnossl", "0.0.0.0", "password"),
+			},
+			expected: check.SeverityFail,
+			rows:     2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			q := healthyQueryer()
+			q.rules = append(q.rules, tt.rules...)
+
+			report, err := securitysettings.New(q).Check(context.Background())
+			require.NoError(t, err)
+
+			f := checktest.Finding(t, report, "hba-auth-methods")
+			assert.Equal(t, tt.expected, f.Severity)
+			if tt.contains != "" {
+				assert.Contains(t, f.Details, tt.contains)
+			}
+			if tt.rows > 0 {
+				require.NotNil(t, f.Table)
+				assert.Len(t, f.Table.Rows, tt.rows)
+			}
+		})
+	}
+}
+
+func TestSecuritySettings_NoHbaAccess(t *testing.T) {
+	t.Parallel()
+
+	q := healthyQueryer()
+	q.settings.CanReadHba = false
+	q.settings.CanReadAuthid = false
+
+	report, err := securitysettings.New(q).Check(context.Background())
+	require.NoError(t, err)
+
+	assert.False(t, q.rulesCalled)
+	assert.False(t, q.rolesCalled)
+
+	f := checktest.Finding(t, report, "hba-auth-methods")
+	assert.Equal(t, check.SeverityOK, f.Severity)
+	assert.Contains(t, f.Details, "requires superuser")
+
+	f = checktest.Finding(t, report, "password-encryption")
+	assert.Equal(t, check.SeverityOK, f.Severity)
+	assert.Contains(t, f.Details, "not checked")
+}
+
+func TestSecuritySettings_PasswordEncryption(t *testing.T) {
+	t.Parallel()
+
+	many := make([]string, 12)
+	for i := range many {
+		many[i] = string(rune('a' + i))
+	}
+
+	tests := []struct {
+		name       string
+		encryption string
+		md5Roles   db.Md5PasswordRolesRow
+		rules      []db.HbaRulesRow
+		expected   check.Severity
+		contains   []string
+	}{
+		{name: "scram", encryption: "scram-sha-256", expected: check.SeverityOK},
+		{name: "md5 setting", encryption: "md5", expected: check.SeverityWarn, contains: []string{"password_encryption is md5"}},
+		{
+			name: "md5 roles", encryption: "scram-sha-256",
+			md5Roles: db.Md5PasswordRolesRow{RoleCount: 2, RoleNames: []string{"app", "reporting"}},
+			expected: check.SeverityWarn, contains: []string{"2 login role(s)", "app, reporting"},
+		},
+		{
+			name: "many md5 roles", encryption: "scram-sha-256",
+			md5Roles: db.Md5PasswordRolesRow{RoleCount: 12, RoleNames: many},
+			expected: check.SeverityWarn, contains: []string{"12 login role(s)", "j, and 2 more"},
+		},
+		{
+			name: "md5 rules", encryption: "scram-sha-256",
+			rules:    []db.HbaRulesRow{rule(4, "hostssl", "10.0.0.0", "md5")},
+			expected: check.SeverityWarn, contains: []string{"1 pg_hba.conf rule(s) use md5"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			q := healthyQueryer()
+			q.settings.PasswordEncryption = tt.encryption
+			q.md5Roles = tt.md5Roles
+			q.rules = append(q.rules, tt.rules...)
+
+			report, err := securitysettings.New(q).Check(context.Background())
+			require.NoError(t, err)
+
+			f := checktest.Finding(t, report, "password-encryption")
+			assert.Equal(t, tt.expected, f.Severity)
+			for _, s := range tt.contains {
+				assert.Contains(t, f.Details, s)
+			}
+		})
+	}
+}
+
+func TestSecuritySettings_PublicSchemaCreate(t *testing.T) {
+	t.Parallel()
+
+	q := healthyQueryer()
+	q.schemas = []db.PublicCreateSchemasRow{
+		{SchemaName: "public", Owner: "postgres"},
+		{SchemaName: "Reports", Owner: "analyst"},
+	}
+
+	report, err := securitysettings.New(q).Check(context.Background())
+	require.NoError(t, err)
+
+	f := checktest.Finding(t, report, "public-schema-create")
+	assert.Equal(t, check.SeverityWarn, f.Severity)
+	assert.Contains(t, f.Details, "2 schema(s): public, Reports")
+	require.Len(t, f.Fixes, 2)
+	assert.Equal(t, `REVOKE CREATE ON SCHEMA "public" FROM PUBLIC`, f.Fixes[0].SQL)
+	assert.Equal(t, `REVOKE CREATE ON SCHEMA "Reports" FROM PUBLIC`, f.Fixes[1].SQL)
+	assert.Equal(t, check.LockOnline, f.Fixes[0].Lock)
+	assert.Equal(t, check.RiskHigh, f.Fixes[0].Risk)
+}
+
+func TestSecuritySettings_QueryError(t *testing.T) {
+	t.Parallel()
+
+	q := healthyQueryer()
+	q.err = errors.New("connection refused")
+
+	_, err := securitysettings.New(q).Check(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "security-settings")
+}
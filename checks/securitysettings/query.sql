@@ -0,0 +1,59 @@
+-- name: SecuritySettings :one
+-- TLS and password settings, and whether the current role may read
+-- pg_hba_file_rules and the password hashes in pg_authid (superuser, unless
+-- granted explicitly).
+SELECT
+  current_setting('ssl')::text AS ssl
+  , COALESCE(current_setting('ssl_min_protocol_version', true), '')::text AS ssl_min_protocol_version
+  , current_setting('password_encryption')::text AS password_encryption
+  , current_setting('listen_addresses')::text AS listen_addresses
+  , current_setting('server_version_num')::integer AS server_version_num
+  , (
+    current_setting('is_superuser')::boolean
+    OR (
+      has_table_privilege('pg_catalog.pg_hba_file_rules', 'SELECT')
+      AND has_function_privilege('pg_catalog.pg_hba_file_rules()', 'EXECUTE')
+    )
+  )::boolean AS can_read_hba
+  , (current_setting('is_superuser')::boolean OR has_table_privilege('pg_catalog.pg_authid', 'SELECT'))::boolean AS can_read_authid;
+
+-- name: HbaRules :many
+-- Parsed pg_hba.conf entries, in file order. Lines with errors are skipped:
+-- the server refuses to reload a file containing them.
+SELECT
+  h.line_number::integer AS line_number
+  , h.type::text AS connection_type
+  , ARRAY_TO_STRING(h.database, ',')::text AS database_names
+  , ARRAY_TO_STRING(h.user_name, ',')::text AS user_names
+  , COALESCE(h.address, '')::text AS address
+  , COALESCE(h.netmask, '')::text AS netmask
+  , h.auth_method::text AS auth_method
+FROM pg_hba_file_rules AS h
+WHERE h.error IS NULL
+ORDER BY h.line_number;
+
+-- name: Md5PasswordRoles :one
+-- Login roles whose stored password is still an MD5 hash.
+SELECT
+  COUNT(*)::bigint AS role_count
+  , COALESCE(ARRAY_AGG(a.rolname::text ORDER BY a.rolname), '{}')::text [] AS role_names
+FROM pg_authid AS a
+WHERE
+  a.rolcanlogin
+  AND a.rolpassword LIKE 'md5%';
+
+-- name: PublicCreateSchemas :many
+-- Schemas where PUBLIC, and so every role, may create objects. Until
+-- PostgreSQL 15 the public schema granted this by default.
+SELECT
+  n.nspname::text AS schema_name
+  , pg_get_userbyid(n.nspowner)::text AS owner
+FROM pg_namespace AS n
+WHERE
+  n.nspname NOT IN ('information_schema')
+  AND n.nspname !~ '^pg_'
+  AND EXISTS (
+    SELECT 1 FROM ACLEXPLODE(n.nspacl) AS acl
+    WHERE acl.grantee = 0 AND acl.privilege_type = 'CREATE'
+  )
+ORDER BY n.nspname;
@@ -2,9 +2,11 @@
 package sequencehealth
 
 import (
+	"cmp"
 	"context"
 	_ "embed"
 	"fmt"
+	"slices"
 
 	"github.com/fresha/pgdoctor/check"
 	"github.com/fresha/pgdoctor/db"
@@ -16,29 +18,68 @@ var querySQL string
 //go:embed README.md
 var readme string
 
+const (
+	exhaustionWarnPercent = 75.0
+	exhaustionFailPercent = 90.0
+
+	// Share of an integer column's range used before migrating it to bigint.
+	integerWarnPercent = 50.0
+	integerFailPercent = 75.0
+)
+
 type SequenceHealthQueries interface {
 	SequenceHealth(context.Context) ([]db.SequenceHealthRow, error)
 }
 
 type checker struct {
-	queries SequenceHealthQueries
+	queries     SequenceHealthQueries
+	warnPercent float64
+	failPercent float64
+
+	integerWarn float64
+	integerFail float64
 }
 
 func Metadata() check.Metadata {
 	return check.Metadata{
-		Category:    check.CategorySchema,
-		CheckID:     "sequence-health",
-		Name:        "Sequence Health",
-		Description: "Identifies sequences approaching exhaustion and integer columns needing bigint migration",
-		Readme:      readme,
-		SQL:         querySQL,
+		Category:       check.CategorySchema,
+		CheckID:        "sequence-health",
+		Name:           "Sequence Health",
+		Description:    "Identifies sequences approaching exhaustion and integer columns needing bigint migration",
+		Readme:         readme,
+		SQL:            querySQL,
+		RuntimeClass:   check.RuntimeMedium,
+		ProductionSafe: true,
+		Priority:       check.PriorityCritical,
+		Privileges:     []check.Privilege{check.PrivilegeReadSchemas},
+		Findings: []check.FindingSpec{
+			{ID: "near-exhaustion", Description: "Sequences close to their maximum value", Thresholds: "WARN >= 75%, FAIL >= 90%"},
+			{ID: "integer-columns", Description: "Sequence-backed integer columns close to the column type limit", Thresholds: "WARN >= 50%, FAIL >= 75%"},
+			{ID: "type-mismatch", Description: "Sequences whose type is wider than the column they feed", Thresholds: "FAIL"},
+		},
 	}
 }
 
-func New(queries SequenceHealthQueries, _ ...check.Config) check.Checker {
-	return &checker{
-		queries: queries,
+// New creates the check. The thresholds can be changed with the
+// near_exhaustion_warn_percent, near_exhaustion_fail_percent,
+// integer_columns_warn_percent, and integer_columns_fail_percent config keys.
+// A FAIL threshold below its WARN threshold is raised to match it.
+func New(queries SequenceHealthQueries, cfg ...check.Config) check.Checker {
+	c := &checker{
+		queries:     queries,
+		warnPercent: exhaustionWarnPercent,
+		failPercent: exhaustionFailPercent,
+		integerWarn: integerWarnPercent,
+		integerFail: integerFailPercent,
 	}
+	if len(cfg) > 0 {
+		id := Metadata().CheckID
+		c.warnPercent = cfg[0].Float(id, "near_exhaustion_warn_percent", exhaustionWarnPercent)
+		c.failPercent = max(cfg[0].Float(id, "near_exhaustion_fail_percent", exhaustionFailPercent), c.warnPercent)
+		c.integerWarn = cfg[0].Float(id, "integer_columns_warn_percent", integerWarnPercent)
+		c.integerFail = max(cfg[0].Float(id, "integer_columns_fail_percent", integerFailPercent), c.integerWarn)
+	}
+	return c
 }
 
 func (c *checker) Metadata() check.Metadata {
@@ -47,10 +88,14 @@ func (c *checker) Metadata() check.Metadata {
 
 func (c *checker) Check(ctx context.Context) (*check.Report, error) {
 	report := check.NewReport(Metadata())
+	ignore := check.ObjectFilterFromContext(ctx)
 	rows, err := c.queries.SequenceHealth(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to check sequence health: %w", err)
 	}
+	rows = slices.DeleteFunc(rows, func(row db.SequenceHealthRow) bool {
+		return ignore.Sequence(row.SchemaName.String, row.SequenceName.String)
+	})
 
 	if len(rows) == 0 {
 		report.AddFinding(check.Finding{
@@ -62,8 +107,9 @@ func (c *checker) Check(ctx context.Context) (*check.Report, error) {
 		return report, nil
 	}
 
-	checkNearExhaustion(rows, report)
-	checkIntegerShouldBeBigint(rows, report)
+	observeMaxUsage(ctx, rows, c.warnPercent, c.failPercent)
+	checkNearExhaustion(rows, c.warnPercent, c.failPercent, report)
+	checkIntegerShouldBeBigint(rows, c.integerWarn, c.integerFail, report)
 	checkSequenceTypeMismatch(rows, report)
 
 	return report, nil
@@ -77,18 +123,36 @@ func getUsagePercent(row db.SequenceHealthRow) float64 {
 	return f.Float64
 }
 
-func checkNearExhaustion(rows []db.SequenceHealthRow, report *check.Report) {
-	var critical []db.SequenceHealthRow // >90%
-	var warning []db.SequenceHealthRow  // >75%
+// observeMaxUsage reports the fullest non-cyclic sequence for calibration.
+func observeMaxUsage(ctx context.Context, rows []db.SequenceHealthRow, warnPercent, failPercent float64) {
+	var maxUsage float64
+	for _, row := range rows {
+		if !row.IsCyclic.Bool {
+			maxUsage = max(maxUsage, getUsagePercent(row))
+		}
+	}
+	check.Observe(ctx, check.Observation{
+		CheckID:   Metadata().CheckID,
+		FindingID: "near-exhaustion",
+		Key:       "near_exhaustion_warn_percent",
+		Value:     maxUsage,
+		Warn:      warnPercent,
+		Fail:      failPercent,
+	})
+}
+
+func checkNearExhaustion(rows []db.SequenceHealthRow, warnPercent, failPercent float64, report *check.Report) {
+	var critical []db.SequenceHealthRow // >= fail threshold
+	var warning []db.SequenceHealthRow  // >= warn threshold
 
 	for _, row := range rows {
 		usage := getUsagePercent(row)
 		if row.IsCyclic.Bool {
 			continue // Cyclic sequences wrap around safely
 		}
-		if usage >= 90 {
+		if usage >= failPercent {
 			critical = append(critical, row)
-		} else if usage >= 75 {
+		} else if usage >= warnPercent {
 			warning = append(warning, row)
 		}
 	}
@@ -98,7 +162,7 @@ func checkNearExhaustion(rows []db.SequenceHealthRow, report *check.Report) {
 			ID:       "near-exhaustion",
 			Name:     "Sequence Exhaustion",
 			Severity: check.SeverityOK,
-			Details:  "All sequences have sufficient headroom (<75% used)",
+			Details:  fmt.Sprintf("All sequences have sufficient headroom (<%.0f%% used)", warnPercent),
 		})
 		return
 	}
@@ -136,7 +200,19 @@ func checkNearExhaustion(rows []db.SequenceHealthRow, report *check.Report) {
 	details := fmt.Sprintf("Found %d sequence(s) nearing exhaustion", len(critical)+len(warning))
 	if len(critical) > 0 {
 		severity = check.SeverityFail
-		details = fmt.Sprintf("CRITICAL: %d sequence(s) at >90%% capacity! %d more at >75%%", len(critical), len(warning))
+		details = fmt.Sprintf("CRITICAL: %d sequence(s) at >%.0f%% capacity! %d more at >%.0f%%",
+			len(critical), failPercent, len(warning), warnPercent)
+	}
+
+	fullest := slices.MaxFunc(append(critical, warning...), func(a, b db.SequenceHealthRow) int {
+		return cmp.Compare(getUsagePercent(a), getUsagePercent(b))
+	})
+	impact := fmt.Sprintf("Sequence %s has used %.0f%% of its range (%s values left); nextval() fails once it runs out",
+		fullest.SequenceName.String, getUsagePercent(fullest), check.FormatNumber(formatRemaining(fullest.RemainingValues.Int64)))
+	if fullest.TableName.String != "" && fullest.ColumnName.String != "" {
+		impact = fmt.Sprintf("%s has used %.0f%% of its sequence's range (%s values left); inserts fail once it runs out",
+			formatTableColumn(fullest.TableName.String, fullest.ColumnName.String), getUsagePercent(fullest),
+			check.FormatNumber(formatRemaining(fullest.RemainingValues.Int64)))
 	}
 
 	report.AddFinding(check.Finding{
@@ -144,6 +220,7 @@ func checkNearExhaustion(rows []db.SequenceHealthRow, report *check.Report) {
 		Name:     "Sequence Exhaustion",
 		Severity: severity,
 		Details:  details,
+		Impact:   impact,
 		Table: &check.Table{
 			Headers: headers,
 			Rows:    tableRows,
@@ -151,11 +228,14 @@ func checkNearExhaustion(rows []db.SequenceHealthRow, report *check.Report) {
 	})
 }
 
-func checkIntegerShouldBeBigint(rows []db.SequenceHealthRow, report *check.Report) {
+// checkIntegerShouldBeBigint flags sequence-backed columns narrower than
+// bigint that have used warnPercent of their sequence's range.
+func checkIntegerShouldBeBigint(rows []db.SequenceHealthRow, warnPercent, failPercent float64, report *check.Report) {
 	var needsMigration []db.SequenceHealthRow
 
 	for _, row := range rows {
-		if row.ShouldBeBigint.Bool {
+		columnType := row.ColumnType.String
+		if columnType != "" && columnType != "bigint" && getUsagePercent(row) >= warnPercent {
 			needsMigration = append(needsMigration, row)
 		}
 	}
@@ -177,7 +257,7 @@ func checkIntegerShouldBeBigint(rows []db.SequenceHealthRow, report *check.Repor
 	for _, row := range needsMigration {
 		usage := getUsagePercent(row)
 		rowSeverity := check.SeverityWarn
-		if usage >= 75 {
+		if usage >= failPercent {
 			rowSeverity = check.SeverityFail
 			severity = check.SeverityFail
 		}
@@ -194,11 +274,17 @@ func checkIntegerShouldBeBigint(rows []db.SequenceHealthRow, report *check.Repor
 		})
 	}
 
+	fullest := slices.MaxFunc(needsMigration, func(a, b db.SequenceHealthRow) int {
+		return cmp.Compare(getUsagePercent(a), getUsagePercent(b))
+	})
+
 	report.AddFinding(check.Finding{
 		ID:       "integer-columns",
 		Name:     "Integer Column Safety",
 		Severity: severity,
-		Details:  fmt.Sprintf("Found %d integer column(s) with >50%% sequence usage that should be migrated to bigint", len(needsMigration)),
+		Details:  fmt.Sprintf("Found %d integer column(s) with >%.0f%% sequence usage that should be migrated to bigint", len(needsMigration), warnPercent),
+		Impact: fmt.Sprintf("%s (%s) has used %.0f%% of its range; inserts fail once it runs out unless it is migrated to bigint",
+			formatTableColumn(fullest.TableName.String, fullest.ColumnName.String), fullest.ColumnType.String, getUsagePercent(fullest)),
 		Table: &check.Table{
 			Headers: headers,
 			Rows:    tableRows,
@@ -35,7 +35,7 @@ func makeSequenceRow(
 	schemaName, seqName, seqDataType, tableName, columnName, columnType string,
 	currentValue, maxValue, incrementBy, remainingValues, columnMaxValue int64,
 	usagePercent float64,
-	isCyclic, sequenceExceedsColumn, shouldBeBigint, isPrimaryKey bool,
+	isCyclic, sequenceExceedsColumn, isPrimaryKey bool,
 	fkReferenceCount int64,
 ) db.SequenceHealthRow {
 	usageNumeric := &pgtype.Numeric{}
@@ -56,7 +56,6 @@ func makeSequenceRow(
 		ColumnType:            pgtype.Text{String: columnType, Valid: columnType != ""},
 		ColumnMaxValue:        pgtype.Int8{Int64: columnMaxValue, Valid: columnMaxValue > 0},
 		SequenceExceedsColumn: pgtype.Bool{Bool: sequenceExceedsColumn, Valid: true},
-		ShouldBeBigint:        pgtype.Bool{Bool: shouldBeBigint, Valid: true},
 		IsPrimaryKey:          pgtype.Bool{Bool: isPrimaryKey, Valid: true},
 		FkReferenceCount:      pgtype.Int8{Int64: fkReferenceCount, Valid: true},
 	}
@@ -83,12 +82,12 @@ func TestSequenceHealth_AllHealthy(t *testing.T) {
 		makeSequenceRow(
 			"public", "bookings_id_seq", "bigint", "bookings", "id", "bigint",
 			1000000, 9223372036854775807, 1, 9223372036853775807, 9223372036854775807,
-			0.00001, false, false, false, true, 5,
+			0.00001, false, false, true, 5,
 		),
 		makeSequenceRow(
 			"public", "users_id_seq", "bigint", "users", "id", "bigint",
 			500000, 9223372036854775807, 1, 9223372036854275807, 9223372036854775807,
-			0.000005, false, false, false, true, 3,
+			0.000005, false, false, true, 3,
 		),
 	}
 
@@ -114,7 +113,7 @@ func TestSequenceHealth_NearExhaustion_Critical(t *testing.T) {
 		makeSequenceRow(
 			"public", "orders_id_seq", "integer", "orders", "id", "integer",
 			1932735283, 2147483647, 1, 214748364, 2147483647,
-			90.0, false, false, true, true, 2,
+			90.0, false, false, true, 2,
 		),
 	}
 
@@ -142,6 +141,38 @@ func TestSequenceHealth_NearExhaustion_Critical(t *testing.T) {
 	require.NotNil(t, exhaustionFinding.Table)
 	require.Equal(t, 1, len(exhaustionFinding.Table.Rows))
 	require.Equal(t, check.SeverityFail, exhaustionFinding.Table.Rows[0].Severity)
+	require.Equal(t, "orders.id has used 90% of its sequence's range (214.7M values left); inserts fail once it runs out", exhaustionFinding.Impact)
+}
+
+func TestSequenceHealth_IgnoredObjects(t *testing.T) {
+	t.Parallel()
+
+	rows := []db.SequenceHealthRow{
+		makeSequenceRow(
+			"partman", "template_id_seq", "integer", "template", "id", "integer",
+			1932735283, 2147483647, 1, 214748364, 2147483647,
+			90.0, false, false, true, 0,
+		),
+		makeSequenceRow(
+			"public", "legacy_id_seq", "integer", "legacy", "id", "integer",
+			1932735283, 2147483647, 1, 214748364, 2147483647,
+			90.0, false, false, true, 0,
+		),
+		makeSequenceRow(
+			"public", "users_id_seq", "bigint", "users", "id", "bigint",
+			1000, 9223372036854775807, 1, 9223372036854774807, 9223372036854775807,
+			0.0, false, false, true, 0,
+		),
+	}
+
+	ignore, err := check.IgnoreRules{Schemas: []string{"partman"}, Sequences: []string{"legacy_*"}}.Compile()
+	require.NoError(t, err)
+	ctx := check.ContextWithObjectFilter(context.Background(), ignore)
+
+	report, err := sequencehealth.New(&mockQueryer{rows: rows}).Check(ctx)
+
+	require.NoError(t, err)
+	assert.Equal(t, check.SeverityOK, report.Severity)
 }
 
 func TestSequenceHealth_NearExhaustion_Warning(t *testing.T) {
@@ -151,7 +182,7 @@ func TestSequenceHealth_NearExhaustion_Warning(t *testing.T) {
 		makeSequenceRow(
 			"public", "products_id_seq", "integer", "products", "id", "integer",
 			1610612735, 2147483647, 1, 536870912, 2147483647,
-			75.0, false, false, true, true, 0,
+			75.0, false, false, true, 0,
 		),
 	}
 
@@ -161,7 +192,7 @@ func TestSequenceHealth_NearExhaustion_Warning(t *testing.T) {
 	report, err := checker.Check(context.Background())
 
 	require.NoError(t, err)
-	require.Equal(t, check.SeverityFail, report.Severity) // overall is FAIL because should_be_bigint is true
+	require.Equal(t, check.SeverityFail, report.Severity) // overall is FAIL because integer-columns fails
 
 	// Find the near-exhaustion finding
 	var exhaustionFinding *check.Finding
@@ -188,17 +219,17 @@ func TestSequenceHealth_NearExhaustion_MixedSeverity(t *testing.T) {
 		makeSequenceRow(
 			"public", "critical_seq", "integer", "critical_table", "id", "integer",
 			1932735283, 2147483647, 1, 214748364, 2147483647,
-			90.0, false, false, true, true, 0,
+			90.0, false, false, true, 0,
 		),
 		makeSequenceRow(
 			"public", "warning_seq", "integer", "warning_table", "id", "integer",
 			1610612735, 2147483647, 1, 536870912, 2147483647,
-			75.0, false, false, true, true, 0,
+			75.0, false, false, true, 0,
 		),
 		makeSequenceRow(
 			"public", "healthy_seq", "bigint", "healthy_table", "id", "bigint",
 			1000000, 9223372036854775807, 1, 9223372036853775807, 9223372036854775807,
-			0.00001, false, false, false, true, 0,
+			0.00001, false, false, true, 0,
 		),
 	}
 
@@ -237,7 +268,7 @@ func TestSequenceHealth_NearExhaustion_CyclicIgnored(t *testing.T) {
 		makeSequenceRow(
 			"public", "cyclic_seq", "integer", "cyclic_table", "id", "integer",
 			1932735283, 2147483647, 1, 214748364, 2147483647,
-			90.0, true, false, true, false, 0, // is_cyclic = true
+			90.0, true, false, false, 0, // is_cyclic = true
 		),
 	}
 
@@ -247,7 +278,7 @@ func TestSequenceHealth_NearExhaustion_CyclicIgnored(t *testing.T) {
 	report, err := checker.Check(context.Background())
 
 	require.NoError(t, err)
-	require.Equal(t, check.SeverityFail, report.Severity) // overall is FAIL because should_be_bigint is true
+	require.Equal(t, check.SeverityFail, report.Severity) // overall is FAIL because integer-columns fails
 
 	// Near-exhaustion should be OK because cyclic sequences are ignored
 	var exhaustionFinding *check.Finding
@@ -270,7 +301,7 @@ func TestSequenceHealth_IntegerShouldBeBigint_Warning(t *testing.T) {
 		makeSequenceRow(
 			"public", "users_id_seq", "integer", "users", "id", "integer",
 			1073741824, 2147483647, 1, 1073741823, 2147483647,
-			50.01, false, false, true, true, 5,
+			50.01, false, false, true, 5,
 		),
 	}
 
@@ -307,7 +338,7 @@ func TestSequenceHealth_IntegerShouldBeBigint_Critical(t *testing.T) {
 		makeSequenceRow(
 			"public", "bookings_id_seq", "integer", "bookings", "id", "integer",
 			1610612735, 2147483647, 1, 536870912, 2147483647,
-			75.0, false, false, true, true, 3,
+			75.0, false, false, true, 3,
 		),
 	}
 
@@ -342,17 +373,17 @@ func TestSequenceHealth_IntegerShouldBeBigint_Multiple(t *testing.T) {
 		makeSequenceRow(
 			"public", "critical_seq", "integer", "critical_table", "id", "integer",
 			1610612735, 2147483647, 1, 536870912, 2147483647,
-			75.0, false, false, true, true, 0,
+			75.0, false, false, true, 0,
 		),
 		makeSequenceRow(
 			"public", "warning_seq", "integer", "warning_table", "id", "integer",
 			1073741824, 2147483647, 1, 1073741823, 2147483647,
-			50.01, false, false, true, true, 0,
+			50.01, false, false, true, 0,
 		),
 		makeSequenceRow(
 			"public", "healthy_seq", "integer", "healthy_table", "id", "integer",
 			100000, 2147483647, 1, 2147383647, 2147483647,
-			0.0047, false, false, false, true, 0,
+			0.0047, false, false, true, 0,
 		),
 	}
 
@@ -389,7 +420,7 @@ func TestSequenceHealth_TypeMismatch(t *testing.T) {
 		makeSequenceRow(
 			"public", "problem_seq", "bigint", "problem_table", "id", "integer",
 			1000000, 9223372036854775807, 1, 9223372036853775807, 2147483647,
-			0.00001, false, true, false, true, 0, // sequence_exceeds_column = true
+			0.00001, false, true, true, 0, // sequence_exceeds_column = true
 		),
 	}
 
@@ -425,12 +456,12 @@ func TestSequenceHealth_TypeMismatch_Multiple(t *testing.T) {
 		makeSequenceRow(
 			"public", "problem1_seq", "bigint", "problem1_table", "id", "integer",
 			1000000, 9223372036854775807, 1, 9223372036853775807, 2147483647,
-			0.00001, false, true, false, true, 0,
+			0.00001, false, true, true, 0,
 		),
 		makeSequenceRow(
 			"public", "problem2_seq", "bigint", "problem2_table", "id", "integer",
 			500000, 9223372036854775807, 1, 9223372036854275807, 2147483647,
-			0.000005, false, true, false, true, 0,
+			0.000005, false, true, true, 0,
 		),
 	}
 
@@ -466,25 +497,25 @@ func TestSequenceHealth_ComplexScenario_AllSubchecks(t *testing.T) {
 		makeSequenceRow(
 			"public", "exhausted_seq", "integer", "exhausted_table", "id", "integer",
 			1932735283, 2147483647, 1, 214748364, 2147483647,
-			90.0, false, false, true, true, 2,
+			90.0, false, false, true, 2,
 		),
 		// Should be bigint - warning
 		makeSequenceRow(
 			"public", "needs_bigint_seq", "integer", "needs_bigint_table", "id", "integer",
 			1073741824, 2147483647, 1, 1073741823, 2147483647,
-			50.01, false, false, true, true, 0,
+			50.01, false, false, true, 0,
 		),
 		// Type mismatch
 		makeSequenceRow(
 			"public", "mismatch_seq", "bigint", "mismatch_table", "id", "integer",
 			100000, 9223372036854775807, 1, 9223372036854675807, 2147483647,
-			0.000001, false, true, false, true, 1,
+			0.000001, false, true, true, 1,
 		),
 		// Healthy
 		makeSequenceRow(
 			"public", "healthy_seq", "bigint", "healthy_table", "id", "bigint",
 			1000000, 9223372036854775807, 1, 9223372036853775807, 9223372036854775807,
-			0.00001, false, false, false, true, 0,
+			0.00001, false, false, true, 0,
 		),
 	}
 
@@ -553,7 +584,7 @@ func TestSequenceHealth_EdgeCase_ExactThresholds(t *testing.T) {
 					"public", "test_seq", "integer", "test_table", "id", "integer",
 					int64(tt.usagePercent*2147483647/100), 2147483647, 1,
 					int64((100-tt.usagePercent)*2147483647/100), 2147483647,
-					tt.usagePercent, false, false, tt.usagePercent > 50, true, 0,
+					tt.usagePercent, false, false, true, 0,
 				),
 			}
 
@@ -587,7 +618,7 @@ func TestSequenceHealth_TableFormatting_NearExhaustion(t *testing.T) {
 		makeSequenceRow(
 			"public", "bookings_id_seq", "integer", "bookings", "id", "integer",
 			1932735283, 2147483647, 1, 214748364, 2147483647,
-			90.0, false, false, true, true, 5,
+			90.0, false, false, true, 5,
 		),
 	}
 
@@ -628,7 +659,7 @@ func TestSequenceHealth_TableFormatting_IntegerColumns(t *testing.T) {
 		makeSequenceRow(
 			"public", "users_id_seq", "integer", "users", "id", "integer",
 			1073741824, 2147483647, 1, 1073741823, 2147483647,
-			50.01, false, false, true, true, 3,
+			50.01, false, false, true, 3,
 		),
 	}
 
@@ -669,7 +700,7 @@ func TestSequenceHealth_TableFormatting_TypeMismatch(t *testing.T) {
 		makeSequenceRow(
 			"public", "problem_seq", "bigint", "problem_table", "id", "integer",
 			1000000, 9223372036854775807, 1, 9223372036853775807, 2147483647,
-			0.00001, false, true, false, true, 0,
+			0.00001, false, true, true, 0,
 		),
 	}
 
@@ -710,7 +741,7 @@ func TestSequenceHealth_SequenceWithoutColumn(t *testing.T) {
 		makeSequenceRow(
 			"public", "standalone_seq", "bigint", "", "", "",
 			1932735283, 9223372036854775807, 1, 9223372035922040524, 0,
-			0.00002, false, false, false, false, 0,
+			0.00002, false, false, false, 0,
 		),
 	}
 
@@ -765,7 +796,7 @@ func TestSequenceHealth_PrescriptionContent_NearExhaustion(t *testing.T) {
 		makeSequenceRow(
 			"public", "orders_id_seq", "integer", "orders", "id", "integer",
 			1932735283, 2147483647, 1, 214748364, 2147483647,
-			90.0, false, false, true, true, 2,
+			90.0, false, false, true, 2,
 		),
 	}
 
@@ -795,7 +826,7 @@ func TestSequenceHealth_PrescriptionContent_IntegerColumns(t *testing.T) {
 		makeSequenceRow(
 			"public", "users_id_seq", "integer", "users", "id", "integer",
 			1073741824, 2147483647, 1, 1073741823, 2147483647,
-			50.01, false, false, true, true, 3,
+			50.01, false, false, true, 3,
 		),
 	}
 
@@ -825,7 +856,7 @@ func TestSequenceHealth_PrescriptionContent_TypeMismatch(t *testing.T) {
 		makeSequenceRow(
 			"public", "problem_seq", "bigint", "problem_table", "id", "integer",
 			1000000, 9223372036854775807, 1, 9223372036853775807, 2147483647,
-			0.00001, false, true, false, true, 0,
+			0.00001, false, true, true, 0,
 		),
 	}
 
@@ -855,7 +886,7 @@ func TestSequenceHealth_SmallintType(t *testing.T) {
 		makeSequenceRow(
 			"public", "lookup_id_seq", "smallint", "lookup", "id", "smallint",
 			24575, 32767, 1, 8192, 32767,
-			75.0, false, false, true, true, 0,
+			75.0, false, false, true, 0,
 		),
 	}
 
@@ -876,3 +907,66 @@ func TestSequenceHealth_SmallintType(t *testing.T) {
 	require.Equal(t, check.SeverityWarn, findingIDs[findingIDNearExhaustion])
 	require.Equal(t, check.SeverityFail, findingIDs[findingIDIntegerColumns])
 }
+
+func TestSequenceHealth_ConfiguredThresholds(t *testing.T) {
+	t.Parallel()
+
+	rows := []db.SequenceHealthRow{
+		makeSequenceRow(
+			"public", "orders_id_seq", "integer", "orders", "id", "integer",
+			858993459, 2147483647, 1, 1288490188, 2147483647,
+			40.0, false, false, true, 0,
+		),
+	}
+
+	tests := []struct {
+		name        string
+		cfg         check.Config
+		exhaustion  check.Severity
+		integerCols check.Severity
+	}{
+		{
+			name:        "defaults",
+			exhaustion:  check.SeverityOK,
+			integerCols: check.SeverityOK,
+		},
+		{
+			name:        "lowered integer-columns thresholds",
+			cfg:         check.Config{"sequence-health": {"integer_columns_warn_percent": "30", "integer_columns_fail_percent": "35"}},
+			exhaustion:  check.SeverityOK,
+			integerCols: check.SeverityFail,
+		},
+		{
+			name:        "lowered near-exhaustion thresholds",
+			cfg:         check.Config{"sequence-health": {"near_exhaustion_warn_percent": "20", "near_exhaustion_fail_percent": "50"}},
+			exhaustion:  check.SeverityWarn,
+			integerCols: check.SeverityOK,
+		},
+		{
+			name:        "FAIL below WARN is raised to WARN",
+			cfg:         check.Config{"sequence-health": {"near_exhaustion_warn_percent": "40", "near_exhaustion_fail_percent": "10"}},
+			exhaustion:  check.SeverityFail,
+			integerCols: check.SeverityOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var cfg []check.Config
+			if tt.cfg != nil {
+				cfg = append(cfg, tt.cfg)
+			}
+			report, err := sequencehealth.New(&mockQueryer{rows: rows}, cfg...).Check(context.Background())
+			require.NoError(t, err)
+
+			severities := map[string]check.Severity{}
+			for _, finding := range report.Results {
+				severities[finding.ID] = finding.Severity
+			}
+			require.Equal(t, tt.exhaustion, severities[findingIDNearExhaustion])
+			require.Equal(t, tt.integerCols, severities[findingIDIntegerColumns])
+		})
+	}
+}
@@ -84,8 +84,6 @@ SELECT
   , COALESCE(so.column_max_value, 0) AS column_max_value
   -- Flag if sequence can generate values that exceed column type
   , (so.column_max_value IS NOT NULL AND si.max_value > so.column_max_value) AS sequence_exceeds_column
-  -- Flag if this is an integer column that should probably be bigint
-  , (so.column_type != 'bigint' AND si.usage_percent > 50) AS should_be_bigint
   -- Flag if column is a primary key
   , (pk.table_oid IS NOT NULL) AS is_primary_key
   -- Count of foreign keys referencing this column
@@ -43,12 +43,17 @@ type checker struct {
 
 func Metadata() check.Metadata {
 	return check.Metadata{
-		Category:    check.CategoryConfigs,
-		CheckID:     "session-settings",
-		Name:        "PostgreSQL Session Configs",
-		Description: "Validates role-level timeout and logging configurations",
-		Readme:      readme,
-		SQL:         querySQL,
+		Category:       check.CategoryConfigs,
+		CheckID:        "session-settings",
+		Name:           "PostgreSQL Session Configs",
+		Description:    "Validates role-level timeout and logging configurations",
+		Readme:         readme,
+		SQL:            querySQL,
+		RuntimeClass:   check.RuntimeFast,
+		ProductionSafe: true,
+		Findings: []check.FindingSpec{
+			{ID: "session-settings", Description: "Role-level timeouts and logging settings", Thresholds: "Timeouts: WARN > 5000ms, FAIL > 10000ms"},
+		},
 	}
 }
 
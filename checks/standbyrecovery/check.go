@@ -0,0 +1,277 @@
+// Package standbyrecovery implements checks for the recovery settings of
+// standbys: apply delay, credentials in primary_conninfo, restore_command,
+// and recovery targets.
+package standbyrecovery
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/fresha/pgdoctor/check"
+	"github.com/fresha/pgdoctor/db"
+)
+
+//go:embed query.sql
+var querySQL string
+
+//go:embed README.md
+var readme string
+
+var (
+	// passwordKeyword finds a password in keyword/value connection strings.
+	passwordKeyword = regexp.MustCompile(`(?:^|\s)password\s*=`)
+
+	// noopCommands succeed without restoring anything.
+	noopCommands = []string{"true", "/bin/true", "/usr/bin/true", ":", "exit 0"}
+
+	// maskedFailure finds a restore_command whose last step turns any
+	// failure into success.
+	maskedFailure = regexp.MustCompile(`(?:\|\||;)\s*(?:true|/bin/true|/usr/bin/true|:|exit\s+0)\s*$`)
+)
+
+type StandbyRecoveryQueries interface {
+	StandbyRecoverySettings(context.Context) (db.StandbyRecoverySettingsRow, error)
+}
+
+type checker struct {
+	queries StandbyRecoveryQueries
+}
+
+func Metadata() check.Metadata {
+	return check.Metadata{
+		Category:       check.CategoryConfigs,
+		CheckID:        "standby-recovery",
+		Name:           "Standby Recovery",
+		Description:    "Validates delayed standbys, credentials in primary_conninfo, restore_command, and recovery targets on standbys",
+		Readme:         readme,
+		SQL:            querySQL,
+		RuntimeClass:   check.RuntimeFast,
+		ProductionSafe: true,
+		Privileges:     []check.Privilege{check.PrivilegeReadAllSettings},
+		Findings: []check.FindingSpec{
+			{ID: "apply-delay", Description: "Delayed standbys (recovery_min_apply_delay) and whether hot_standby_feedback makes the primary keep dead rows for the delay", Thresholds: "WARN delayed with hot_standby_feedback on"},
+			{ID: "primary-conninfo-password", Description: "A password stored in primary_conninfo rather than a password file", Thresholds: "WARN"},
+			{ID: "restore-command", Description: "restore_command placeholders and commands that succeed without restoring", Thresholds: "FAIL missing %f or %p, no-op, or failures masked as success"},
+			{ID: "recovery-target", Description: "Recovery targets that stop a standby from following its primary", Thresholds: "WARN any recovery_target* set on a standby"},
+		},
+	}
+}
+
+func New(queries StandbyRecoveryQueries, _ ...check.Config) check.Checker {
+	return &checker{
+		queries: queries,
+	}
+}
+
+func (c *checker) Metadata() check.Metadata {
+	return Metadata()
+}
+
+func (c *checker) Check(ctx context.Context) (*check.Report, error) {
+	report := check.NewReport(Metadata())
+
+	settings, err := c.queries.StandbyRecoverySettings(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("running %s/%s: %w", report.Category, report.CheckID, err)
+	}
+
+	if !settings.InRecovery {
+		for _, spec := range Metadata().Findings {
+			report.AddFinding(check.Finding{
+				ID:       spec.ID,
+				Name:     findingName(spec.ID),
+				Severity: check.SeverityOK,
+				Details:  "This server is the primary; run against each standby to check its recovery settings",
+			})
+		}
+		return report, nil
+	}
+
+	checkApplyDelay(settings, report)
+	checkPrimaryConninfo(settings, report)
+	checkRestoreCommand(settings, report)
+	checkRecoveryTarget(settings, report)
+
+	return report, nil
+}
+
+func findingName(id string) string {
+	switch id {
+	case "apply-delay":
+		return "Apply Delay"
+	case "primary-conninfo-password":
+		return "Password in primary_conninfo"
+	case "restore-command":
+		return "Restore Command"
+	default:
+		return "Recovery Target"
+	}
+}
+
+// checkApplyDelay describes a delayed standby. Its replay lag on the primary
+// is the delay by design, and with hot_standby_feedback it reports a
+// snapshot that is the delay old, so the primary's vacuum waits that long.
+func checkApplyDelay(settings db.StandbyRecoverySettingsRow, report *check.Report) {
+	if settings.ApplyDelayMs <= 0 {
+		report.AddFinding(check.Finding{
+			ID:       "apply-delay",
+			Name:     findingName("apply-delay"),
+			Severity: check.SeverityOK,
+			Details:  "Not a delayed standby: WAL is replayed as soon as it arrives",
+		})
+		return
+	}
+
+	delay := check.FormatDurationSec(settings.ApplyDelayMs / 1000)
+	details := fmt.Sprintf("Delayed standby: recovery_min_apply_delay holds replay %s behind the primary. "+
+		"It cannot be promoted without losing that window, and its replay lag on the primary is the delay by design: "+
+		"list its application_name under delayed_standbys for replication-lag on the primary so it is judged by how quickly it receives WAL",
+		delay)
+
+	if settings.HotStandbyFeedback {
+		report.AddFinding(check.Finding{
+			ID:       "apply-delay",
+			Name:     findingName("apply-delay"),
+			Severity: check.SeverityWarn,
+			Details: details + fmt.Sprintf(". hot_standby_feedback is on, so the primary cannot vacuum rows deleted in the last %s, and tables bloat. "+
+				"Turn hot_standby_feedback off on delayed standbys", delay),
+		})
+		return
+	}
+
+	report.AddFinding(check.Finding{
+		ID:       "apply-delay",
+		Name:     findingName("apply-delay"),
+		Severity: check.SeverityOK,
+		Details:  details,
+	})
+}
+
+func checkPrimaryConninfo(settings db.StandbyRecoverySettingsRow, report *check.Report) {
+	finding := check.Finding{
+		ID:       "primary-conninfo-password",
+		Name:     findingName("primary-conninfo-password"),
+		Severity: check.SeverityOK,
+	}
+
+	switch {
+	case !settings.PrimaryConninfo.Valid:
+		finding.Details = "primary_conninfo is not visible to this role; grant pg_read_all_settings to check it"
+	case settings.PrimaryConninfo.String == "":
+		finding.Details = "primary_conninfo is not set: this standby does not stream from a primary"
+	case hasPassword(settings.PrimaryConninfo.String):
+		finding.Severity = check.SeverityWarn
+		finding.Details = "primary_conninfo contains a password. It is stored in plain text in postgresql.auto.conf, " +
+			"copied into every base backup, and readable by any role with pg_read_all_settings. " +
+			"Move it to a password file named by passfile in primary_conninfo, or use certificate authentication"
+	default:
+		finding.Details = "primary_conninfo holds no password"
+	}
+
+	report.AddFinding(finding)
+}
+
+// hasPassword reports whether a connection string, as keyword/value pairs
+// or a URI, contains a password.
+func hasPassword(conninfo string) bool {
+	if strings.HasPrefix(conninfo, "postgres://") || strings.HasPrefix(conninfo, "postgresql://") {
+		u, err := url.Parse(conninfo)
+		if err != nil {
+			return strings.Contains(conninfo, "password=")
+		}
+		if _, ok := u.User.Password(); ok {
+			return true
+		}
+		return u.Query().Has("password")
+	}
+	return passwordKeyword.MatchString(conninfo)
+}
+
+func checkRestoreCommand(settings db.StandbyRecoverySettingsRow, report *check.Report) {
+	switch {
+	case !settings.RestoreCommand.Valid:
+		report.AddFinding(check.Finding{
+			ID:       "restore-command",
+			Name:     findingName("restore-command"),
+			Severity: check.SeverityOK,
+			Details:  "restore_command is not visible to this role; grant pg_read_all_settings to check it",
+		})
+		return
+	case settings.RestoreCommand.String == "":
+		report.AddFinding(check.Finding{
+			ID:       "restore-command",
+			Name:     findingName("restore-command"),
+			Severity: check.SeverityOK,
+			Details:  "restore_command is not set; see failover-readiness for whether this standby can catch up without it",
+		})
+		return
+	}
+
+	problems := restoreCommandProblems(settings.RestoreCommand.String)
+	if len(problems) == 0 {
+		report.AddFinding(check.Finding{
+			ID:       "restore-command",
+			Name:     findingName("restore-command"),
+			Severity: check.SeverityOK,
+			Details:  "restore_command uses %f and %p and reports its failures",
+		})
+		return
+	}
+
+	report.AddFinding(check.Finding{
+		ID:       "restore-command",
+		Name:     findingName("restore-command"),
+		Severity: check.SeverityFail,
+		Details: fmt.Sprintf("restore_command %s. Recovery relies on it to copy the requested file to %%p and to exit non-zero when the archive does not have it; "+
+			"test it by hand for a segment in the archive and one that is not",
+			strings.Join(problems, "; ")),
+	})
+}
+
+// restoreCommandProblems lists what is wrong with a restore_command.
+func restoreCommandProblems(cmd string) []string {
+	cmd = strings.TrimSpace(cmd)
+	for _, noop := range noopCommands {
+		if cmd == noop {
+			return []string{"succeeds without restoring anything"}
+		}
+	}
+
+	var problems []string
+	if !strings.Contains(cmd, "%f") {
+		problems = append(problems, "does not use %f, so it cannot tell which WAL file is wanted")
+	}
+	if !strings.Contains(cmd, "%p") {
+		problems = append(problems, "does not use %p, so the file never reaches the path recovery reads")
+	}
+	if maskedFailure.MatchString(cmd) {
+		problems = append(problems, "ends by discarding its exit status, so a missing file is reported as restored")
+	}
+	return problems
+}
+
+func checkRecoveryTarget(settings db.StandbyRecoverySettingsRow, report *check.Report) {
+	if settings.RecoveryTargets == "" {
+		report.AddFinding(check.Finding{
+			ID:       "recovery-target",
+			Name:     findingName("recovery-target"),
+			Severity: check.SeverityOK,
+			Details:  "No recovery target is set: this standby follows its primary indefinitely",
+		})
+		return
+	}
+
+	report.AddFinding(check.Finding{
+		ID:       "recovery-target",
+		Name:     findingName("recovery-target"),
+		Severity: check.SeverityWarn,
+		Details: fmt.Sprintf("Recovery target set on a standby (%s). When replay reaches it, recovery pauses, promotes, or shuts down "+
+			"according to recovery_target_action, and the standby stops following the primary. "+
+			"Targets belong to point-in-time restores; remove them from long-running standbys",
+			settings.RecoveryTargets),
+	})
+}
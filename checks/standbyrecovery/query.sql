@@ -0,0 +1,22 @@
+-- name: StandbyRecoverySettings :one
+-- Recovery settings of a standby. primary_conninfo and restore_command are
+-- hidden from pg_settings unless the role is a superuser or member of
+-- pg_read_all_settings, so they are NULL for other roles.
+SELECT
+  PG_IS_IN_RECOVERY() AS in_recovery
+  , COALESCE((
+    SELECT setting::bigint FROM pg_settings WHERE name = 'recovery_min_apply_delay'
+  ), 0)::bigint AS apply_delay_ms
+  , current_setting('hot_standby_feedback')::boolean AS hot_standby_feedback
+  , (SELECT setting FROM pg_settings WHERE name = 'primary_conninfo')::text AS primary_conninfo
+  , (SELECT setting FROM pg_settings WHERE name = 'restore_command')::text AS restore_command
+  , COALESCE((
+    SELECT STRING_AGG(name || ' = ' || setting, ', ' ORDER BY name)
+    FROM pg_settings
+    WHERE
+      name IN (
+        'recovery_target', 'recovery_target_lsn', 'recovery_target_name'
+        , 'recovery_target_time', 'recovery_target_xid'
+      )
+      AND setting <> ''
+  ), '')::text AS recovery_targets;
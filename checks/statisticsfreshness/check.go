@@ -19,10 +19,28 @@ var readme string
 
 const (
 	minStatsDaysForAccuracy = 7
+
+	// pg_stat_statements entry use before PostgreSQL 14, where no
+	// deallocation counter exists, that suggests it is evicting entries.
+	statementsFullPercent = 95.0
+
+	// Average deallocations per day since the pg_stat_statements reset.
+	// Each one evicts 5% of entries, so hourly eviction means rarely run
+	// statements never accumulate statistics.
+	deallocationsFailPerDay = 24.0
+
+	// Size of the query text file, read in full on every scan of the view.
+	// Past 1GiB PostgreSQL cannot load it and the view returns NULL texts.
+	queryTextWarnBytes = int64(100 << 20)
+	queryTextFailBytes = int64(1 << 30)
 )
 
 type StatisticsFreshnessQueries interface {
 	StatisticsFreshness(context.Context) (db.StatisticsFreshnessRow, error)
+	StatStatementsSettings(context.Context) (db.StatStatementsSettingsRow, error)
+	StatStatementsUsage(context.Context) (db.StatStatementsUsageRow, error)
+	StatStatementsDeallocations(context.Context) (db.StatStatementsDeallocationsRow, error)
+	StatsResetTimes(context.Context) (db.StatsResetTimesRow, error)
 }
 
 type checker struct {
@@ -31,12 +49,20 @@ type checker struct {
 
 func Metadata() check.Metadata {
 	return check.Metadata{
-		Category:    check.CategoryConfigs,
-		CheckID:     "statistics-freshness",
-		Name:        "Statistics Freshness",
-		Description: "Validates PostgreSQL statistics are mature enough for usage-based analysis",
-		Readme:      readme,
-		SQL:         querySQL,
+		Category:       check.CategoryConfigs,
+		CheckID:        "statistics-freshness",
+		Name:           "Statistics Freshness",
+		Description:    "Validates PostgreSQL statistics are mature enough for usage-based analysis",
+		Readme:         readme,
+		SQL:            querySQL,
+		RuntimeClass:   check.RuntimeFast,
+		ProductionSafe: true,
+		Privileges:     []check.Privilege{check.PrivilegeReadAllStats},
+		Findings: []check.FindingSpec{
+			{ID: "statistics-freshness", Description: "Age of collected statistics since the last reset", Thresholds: "WARN < 7 days"},
+			{ID: "stats-reset-times", Description: "When pg_stat_database, pg_stat_statements, and pg_stat_bgwriter statistics were last reset, and the checks that read each", Thresholds: "WARN pg_stat_statements or pg_stat_bgwriter reset < 7 days ago"},
+			{ID: "stat-statements-coverage", Description: "Whether pg_stat_statements records a representative sample of the workload: eviction, track settings, and query text size", Thresholds: "WARN on any eviction, save off, or >= 100MiB of query text; FAIL when not loaded, track or compute_query_id off, evicting hourly, or >= 1GiB of query text"},
+		},
 	}
 }
 
@@ -57,7 +83,24 @@ func (c *checker) Check(ctx context.Context) (*check.Report, error) {
 	if err != nil {
 		return nil, fmt.Errorf("running %s/%s: %w", report.Category, report.CheckID, err)
 	}
+	checkStatisticsAge(row, report)
+
+	resets, err := c.queries.StatsResetTimes(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("running %s/%s (reset times): %w", report.Category, report.CheckID, err)
+	}
+
+	statements, err := c.checkStatStatements(ctx, report)
+	if err != nil {
+		return nil, fmt.Errorf("running %s/%s (pg_stat_statements): %w", report.Category, report.CheckID, err)
+	}
 
+	checkResetTimes(resets, statements, report)
+
+	return report, nil
+}
+
+func checkStatisticsAge(row db.StatisticsFreshnessRow, report *check.Report) {
 	if !row.StatsReset.Valid {
 		// NULL stats_reset means statistics have NEVER been reset.
 		// This is actually the ideal state - maximum data accumulation for accurate analysis.
@@ -67,7 +110,7 @@ func (c *checker) Check(ctx context.Context) (*check.Report, error) {
 			Severity: check.SeverityOK,
 			Details:  "Statistics have never been reset (optimal for usage-based analysis)",
 		})
-		return report, nil
+		return
 	}
 
 	ageDays := row.AgeDays.Int32
@@ -79,7 +122,7 @@ func (c *checker) Check(ctx context.Context) (*check.Report, error) {
 			Severity: check.SeverityOK,
 			Details:  fmt.Sprintf("Statistics are %d days old (mature enough for analysis)", ageDays),
 		})
-		return report, nil
+		return
 	}
 
 	affectedChecks := []string{
@@ -97,6 +140,212 @@ func (c *checker) Check(ctx context.Context) (*check.Report, error) {
 			minStatsDaysForAccuracy,
 			strings.Join(affectedChecks, "\n")),
 	})
+}
 
-	return report, nil
+// statementsIssue is one reason pg_stat_statements may not reflect the workload.
+type statementsIssue struct {
+	severity check.Severity
+	item     string
+	value    string
+	note     string
+}
+
+// checkStatStatements reports whether pg_stat_statements sees enough of the
+// workload for the checks that analyze it to be representative. It returns
+// the pg_stat_statements_info row when the server has one.
+func (c *checker) checkStatStatements(ctx context.Context, report *check.Report) (*db.StatStatementsDeallocationsRow, error) {
+	settings, err := c.queries.StatStatementsSettings(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case !settings.Installed && !settings.Loaded:
+		report.AddFinding(check.Finding{
+			ID:       "stat-statements-coverage",
+			Name:     "pg_stat_statements Coverage",
+			Severity: check.SeverityOK,
+			Details:  "pg_stat_statements is not installed; checks that analyze statements skip their findings",
+		})
+		return nil, nil
+	case !settings.Installed:
+		report.AddFinding(check.Finding{
+			ID:       "stat-statements-coverage",
+			Name:     "pg_stat_statements Coverage",
+			Severity: check.SeverityOK,
+			Details: "pg_stat_statements is loaded but the extension is not created in this database, so its statistics cannot be read here. " +
+				"Run CREATE EXTENSION pg_stat_statements to let pgdoctor analyze statements",
+		})
+		return nil, nil
+	case !settings.Loaded:
+		report.AddFinding(check.Finding{
+			ID:       "stat-statements-coverage",
+			Name:     "pg_stat_statements Coverage",
+			Severity: check.SeverityFail,
+			Details: "The pg_stat_statements extension is created but the library is not in shared_preload_libraries, so nothing is recorded " +
+				"and reading the view fails. Add pg_stat_statements to shared_preload_libraries and restart",
+		})
+		return nil, nil
+	}
+
+	var issues []statementsIssue
+	if settings.Track == "none" {
+		issues = append(issues, statementsIssue{check.SeverityFail, "pg_stat_statements.track", settings.Track,
+			"No statements are recorded; set it to top"})
+	}
+	if settings.ComputeQueryID == "off" {
+		issues = append(issues, statementsIssue{check.SeverityFail, "compute_query_id", settings.ComputeQueryID,
+			"Statements get no query ID, so pg_stat_statements records nothing; set it to auto"})
+	}
+	if settings.Save == "off" {
+		issues = append(issues, statementsIssue{check.SeverityWarn, "pg_stat_statements.save", settings.Save,
+			"Statistics are discarded on every restart, so they only cover the time since the last one"})
+	}
+
+	usage, err := c.queries.StatStatementsUsage(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var info *db.StatStatementsDeallocationsRow
+	if settings.HasInfo {
+		dealloc, err := c.queries.StatStatementsDeallocations(ctx)
+		if err != nil {
+			return nil, err
+		}
+		info = &dealloc
+		if dealloc.Deallocations > 0 {
+			severity := check.SeverityWarn
+			value := check.FormatNumber(dealloc.Deallocations)
+			if dealloc.ResetAgeSeconds > 0 {
+				perDay := float64(dealloc.Deallocations) / (float64(dealloc.ResetAgeSeconds) / 86400)
+				if perDay >= deallocationsFailPerDay {
+					severity = check.SeverityFail
+				}
+				value += fmt.Sprintf(" (%.1f/day)", perDay)
+			}
+			issues = append(issues, statementsIssue{severity, "Deallocations", value,
+				fmt.Sprintf("All %d entries are in use and the least-used are evicted to admit new statements, so rarely run statements never accumulate statistics. "+
+					"Raise pg_stat_statements.max (requires restart)", settings.MaxEntries)})
+		}
+	} else if settings.MaxEntries > 0 {
+		if pct := float64(usage.Entries) / float64(settings.MaxEntries) * 100; pct >= statementsFullPercent {
+			issues = append(issues, statementsIssue{check.SeverityWarn, "Entries",
+				fmt.Sprintf("%d of %d", usage.Entries, settings.MaxEntries),
+				"pg_stat_statements is full and probably evicting entries; raise pg_stat_statements.max (requires restart)"})
+		}
+	}
+
+	if usage.QueryTextBytes >= queryTextWarnBytes {
+		severity := check.SeverityWarn
+		if usage.QueryTextBytes >= queryTextFailBytes {
+			severity = check.SeverityFail
+		}
+		issues = append(issues, statementsIssue{severity, "Query text", check.FormatBytes(usage.QueryTextBytes),
+			"Query texts are read in full on every scan of pg_stat_statements; past 1GiB PostgreSQL cannot load them and texts read as NULL. " +
+				"Usually caused by generated IN lists or very long statements; run pg_stat_statements_reset() and fix the query shapes (see query-patterns)"})
+	}
+
+	summary := fmt.Sprintf("pg_stat_statements holds %d of %d entries (track = %s)",
+		usage.Entries, settings.MaxEntries, settings.Track)
+	if len(issues) == 0 {
+		report.AddFinding(check.Finding{
+			ID:       "stat-statements-coverage",
+			Name:     "pg_stat_statements Coverage",
+			Severity: check.SeverityOK,
+			Details:  summary,
+		})
+		return info, nil
+	}
+
+	severity := check.SeverityOK
+	var tableRows []check.TableRow
+	for _, issue := range issues {
+		severity = max(severity, issue.severity)
+		tableRows = append(tableRows, check.TableRow{
+			Cells:    []string{issue.item, issue.value, issue.note},
+			Severity: issue.severity,
+		})
+	}
+
+	report.AddFinding(check.Finding{
+		ID:       "stat-statements-coverage",
+		Name:     "pg_stat_statements Coverage",
+		Severity: severity,
+		Details: summary + ". Checks that analyze pg_stat_statements (query-patterns, partition-usage, partial-indexes) " +
+			"may be looking at an unrepresentative sliver of the workload",
+		Table: &check.Table{
+			Headers: []string{"Item", "Value", "Issue"},
+			Rows:    tableRows,
+		},
+	})
+	return info, nil
+}
+
+// resetSource is one set of cumulative statistics and the checks reading it.
+type resetSource struct {
+	name       string
+	reset      string
+	ageSeconds int64
+	checks     string
+	// judged is false for pg_stat_database, whose age the
+	// statistics-freshness finding already grades.
+	judged bool
+}
+
+// checkResetTimes reports when each set of cumulative statistics was last
+// reset, so usage-based findings can be read against the window they cover.
+func checkResetTimes(resets db.StatsResetTimesRow, statements *db.StatStatementsDeallocationsRow, report *check.Report) {
+	sources := []resetSource{
+		{"pg_stat_database", resets.DatabaseStatsReset, resets.DatabaseResetAgeSeconds,
+			"index-usage, table-seq-scans, cache-efficiency, table-activity, txn-rates, temp-usage", false},
+	}
+	if statements != nil {
+		age := statements.ResetAgeSeconds
+		if statements.StatsReset == "" {
+			age = -1
+		}
+		sources = append(sources, resetSource{"pg_stat_statements", statements.StatsReset, age,
+			"query-patterns, partition-usage, partial-indexes, table-seq-scans", true})
+	}
+	sources = append(sources, resetSource{"pg_stat_bgwriter", resets.BgwriterStatsReset, resets.BgwriterResetAgeSeconds,
+		"checkpoint-health, wal-size", true})
+
+	severity := check.SeverityOK
+	var recent []string
+	var tableRows []check.TableRow
+	for _, src := range sources {
+		reset, age := "never", "-"
+		if src.ageSeconds >= 0 {
+			reset = src.reset
+			age = check.FormatDurationSec(src.ageSeconds)
+		}
+		rowSeverity := check.SeverityOK
+		if src.judged && src.ageSeconds >= 0 && src.ageSeconds < minStatsDaysForAccuracy*86400 {
+			rowSeverity = check.SeverityWarn
+			recent = append(recent, fmt.Sprintf("%s (%s ago)", src.name, age))
+		}
+		severity = max(severity, rowSeverity)
+		tableRows = append(tableRows, check.TableRow{
+			Cells:    []string{src.name, reset, age, src.checks},
+			Severity: rowSeverity,
+		})
+	}
+
+	details := "Usage-based findings count activity since these resets; a short window can miss weekly or monthly workloads"
+	if len(recent) > 0 {
+		details = fmt.Sprintf("Reset less than %d days ago: %s. Findings from the checks listed against them cover only that window, "+
+			"so treat unused or rarely used objects and low rates with caution until more activity accumulates", minStatsDaysForAccuracy, strings.Join(recent, ", "))
+	}
+
+	report.AddFinding(check.Finding{
+		ID:       "stats-reset-times",
+		Name:     "Statistics Reset Times",
+		Severity: severity,
+		Details:  details,
+		Table: &check.Table{
+			Headers: []string{"Statistics", "Last Reset", "Age", "Used By"},
+			Rows:    tableRows,
+		},
+	})
 }
@@ -16,6 +16,11 @@ import (
 type mockStatisticsFreshnessQueryer struct {
 	row db.StatisticsFreshnessRow
 	err error
+
+	settings db.StatStatementsSettingsRow
+	usage    db.StatStatementsUsageRow
+	dealloc  db.StatStatementsDeallocationsRow
+	resets   db.StatsResetTimesRow
 }
 
 func (m *mockStatisticsFreshnessQueryer) StatisticsFreshness(context.Context) (db.StatisticsFreshnessRow, error) {
@@ -25,8 +30,27 @@ func (m *mockStatisticsFreshnessQueryer) StatisticsFreshness(context.Context) (d
 	return m.row, nil
 }
 
+func (m *mockStatisticsFreshnessQueryer) StatStatementsSettings(context.Context) (db.StatStatementsSettingsRow, error) {
+	return m.settings, nil
+}
+
+func (m *mockStatisticsFreshnessQueryer) StatStatementsUsage(context.Context) (db.StatStatementsUsageRow, error) {
+	return m.usage, nil
+}
+
+func (m *mockStatisticsFreshnessQueryer) StatStatementsDeallocations(context.Context) (db.StatStatementsDeallocationsRow, error) {
+	return m.dealloc, nil
+}
+
+func (m *mockStatisticsFreshnessQueryer) StatsResetTimes(context.Context) (db.StatsResetTimesRow, error) {
+	return m.resets, nil
+}
+
 func newMockQueryer(row db.StatisticsFreshnessRow) *mockStatisticsFreshnessQueryer {
-	return &mockStatisticsFreshnessQueryer{row: row}
+	return &mockStatisticsFreshnessQueryer{
+		row:    row,
+		resets: db.StatsResetTimesRow{DatabaseResetAgeSeconds: -1, BgwriterResetAgeSeconds: -1},
+	}
 }
 
 func newMockQueryerWithError(err error) *mockStatisticsFreshnessQueryer {
@@ -111,7 +135,7 @@ func Test_StatisticsFreshness(t *testing.T) {
 			require.NoError(t, err)
 
 			results := report.Results
-			require.Equal(t, 1, len(results), "Should have exactly 1 result")
+			require.Equal(t, 3, len(results), "Should have the age, pg_stat_statements, and reset time results")
 
 			result := results[0]
 			require.Equal(t, tc.ExpectedID, result.ID, "Result ID should match")
@@ -136,7 +160,7 @@ func Test_StatisticsFreshness_MatureStats(t *testing.T) {
 	require.NoError(t, err)
 
 	results := report.Results
-	require.Equal(t, 1, len(results))
+	require.Equal(t, 3, len(results))
 
 	result := results[0]
 	require.Equal(t, check.SeverityOK, result.Severity)
@@ -159,7 +183,7 @@ func Test_StatisticsFreshness_ImmatureStats(t *testing.T) {
 	require.NoError(t, err)
 
 	results := report.Results
-	require.Equal(t, 1, len(results))
+	require.Equal(t, 3, len(results))
 
 	result := results[0]
 	require.Equal(t, check.SeverityWarn, result.Severity)
@@ -187,7 +211,7 @@ func Test_StatisticsFreshness_NeverReset(t *testing.T) {
 	require.NoError(t, err)
 
 	results := report.Results
-	require.Equal(t, 1, len(results))
+	require.Equal(t, 3, len(results))
 
 	result := results[0]
 	require.Equal(t, check.SeverityOK, result.Severity)
@@ -243,7 +267,7 @@ func Test_StatisticsFreshness_ThresholdBoundary(t *testing.T) {
 			require.NoError(t, err)
 
 			results := report.Results
-			require.Equal(t, 1, len(results))
+			require.Equal(t, 3, len(results))
 
 			result := results[0]
 			require.Equal(t, tc.ExpectedSeverity, result.Severity, "Severity should match expected")
@@ -266,7 +290,7 @@ func Test_StatisticsFreshness_AffectedChecks(t *testing.T) {
 	require.NoError(t, err)
 
 	results := report.Results
-	require.Equal(t, 1, len(results))
+	require.Equal(t, 3, len(results))
 
 	result := results[0]
 	require.Contains(t, result.Details, "index-usage", "Should mention index-usage check")
@@ -317,7 +341,7 @@ func Test_StatisticsFreshness_VeryOldStats(t *testing.T) {
 	require.NoError(t, err)
 
 	results := report.Results
-	require.Equal(t, 1, len(results))
+	require.Equal(t, 3, len(results))
 
 	result := results[0]
 	require.Equal(t, check.SeverityOK, result.Severity, "Very old stats should still be OK")
@@ -339,9 +363,213 @@ func Test_StatisticsFreshness_ZeroAge(t *testing.T) {
 	require.NoError(t, err)
 
 	results := report.Results
-	require.Equal(t, 1, len(results))
+	require.Equal(t, 3, len(results))
 
 	result := results[0]
 	require.Equal(t, check.SeverityWarn, result.Severity, "Just-reset stats should be WARN")
 	require.Contains(t, result.Details, "0 days ago")
 }
+
+func Test_StatisticsFreshness_StatStatementsCoverage(t *testing.T) {
+	t.Parallel()
+
+	healthy := db.StatStatementsSettingsRow{
+		Installed:      true,
+		Loaded:         true,
+		MaxEntries:     5000,
+		Track:          "top",
+		Save:           "on",
+		ComputeQueryID: "auto",
+		HasInfo:        true,
+	}
+	with := func(f func(*db.StatStatementsSettingsRow)) db.StatStatementsSettingsRow {
+		s := healthy
+		f(&s)
+		return s
+	}
+
+	type testCase struct {
+		Name             string
+		Settings         db.StatStatementsSettingsRow
+		Usage            db.StatStatementsUsageRow
+		Dealloc          db.StatStatementsDeallocationsRow
+		ExpectedSeverity check.Severity
+		ExpectedDetails  string
+	}
+
+	testCases := []testCase{
+		{
+			Name:             "not installed - OK",
+			ExpectedSeverity: check.SeverityOK,
+			ExpectedDetails:  "not installed",
+		},
+		{
+			Name:             "loaded but extension not created - OK",
+			Settings:         db.StatStatementsSettingsRow{Loaded: true},
+			ExpectedSeverity: check.SeverityOK,
+			ExpectedDetails:  "CREATE EXTENSION",
+		},
+		{
+			Name:             "created but not preloaded - FAIL",
+			Settings:         db.StatStatementsSettingsRow{Installed: true},
+			ExpectedSeverity: check.SeverityFail,
+			ExpectedDetails:  "shared_preload_libraries",
+		},
+		{
+			Name:             "healthy - OK",
+			Settings:         healthy,
+			Usage:            db.StatStatementsUsageRow{Entries: 1200, QueryTextBytes: 2 << 20},
+			ExpectedSeverity: check.SeverityOK,
+			ExpectedDetails:  "1200 of 5000 entries",
+		},
+		{
+			Name:             "track none - FAIL",
+			Settings:         with(func(s *db.StatStatementsSettingsRow) { s.Track = "none" }),
+			ExpectedSeverity: check.SeverityFail,
+		},
+		{
+			Name:             "compute_query_id off - FAIL",
+			Settings:         with(func(s *db.StatStatementsSettingsRow) { s.ComputeQueryID = "off" }),
+			ExpectedSeverity: check.SeverityFail,
+		},
+		{
+			Name:             "save off - WARN",
+			Settings:         with(func(s *db.StatStatementsSettingsRow) { s.Save = "off" }),
+			ExpectedSeverity: check.SeverityWarn,
+		},
+		{
+			Name:             "occasional deallocations - WARN",
+			Settings:         healthy,
+			Usage:            db.StatStatementsUsageRow{Entries: 5000},
+			Dealloc:          db.StatStatementsDeallocationsRow{Deallocations: 3, ResetAgeSeconds: 7 * 86400},
+			ExpectedSeverity: check.SeverityWarn,
+		},
+		{
+			Name:             "hourly deallocations - FAIL",
+			Settings:         healthy,
+			Usage:            db.StatStatementsUsageRow{Entries: 5000},
+			Dealloc:          db.StatStatementsDeallocationsRow{Deallocations: 24 * 7, ResetAgeSeconds: 7 * 86400},
+			ExpectedSeverity: check.SeverityFail,
+		},
+		{
+			Name:             "full without deallocation counter - WARN",
+			Settings:         with(func(s *db.StatStatementsSettingsRow) { s.HasInfo = false }),
+			Usage:            db.StatStatementsUsageRow{Entries: 4900},
+			ExpectedSeverity: check.SeverityWarn,
+		},
+		{
+			Name:             "large query text - WARN",
+			Settings:         healthy,
+			Usage:            db.StatStatementsUsageRow{Entries: 1000, QueryTextBytes: 200 << 20},
+			ExpectedSeverity: check.SeverityWarn,
+		},
+		{
+			Name:             "query text past 1GiB - FAIL",
+			Settings:         healthy,
+			Usage:            db.StatStatementsUsageRow{Entries: 1000, QueryTextBytes: 1 << 30},
+			ExpectedSeverity: check.SeverityFail,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+
+			queryer := newMockQueryer(db.StatisticsFreshnessRow{})
+			queryer.settings = tc.Settings
+			queryer.usage = tc.Usage
+			queryer.dealloc = tc.Dealloc
+
+			report, err := statisticsfreshness.New(queryer).Check(context.Background())
+			require.NoError(t, err)
+
+			results := report.Results
+			require.Equal(t, 3, len(results))
+
+			result := results[1]
+			require.Equal(t, "stat-statements-coverage", result.ID)
+			require.Equal(t, tc.ExpectedSeverity, result.Severity)
+			require.Contains(t, result.Details, tc.ExpectedDetails)
+		})
+	}
+}
+
+func Test_StatisticsFreshness_ResetTimes(t *testing.T) {
+	t.Parallel()
+
+	const day = int64(86400)
+
+	type testCase struct {
+		Name             string
+		Resets           db.StatsResetTimesRow
+		Settings         db.StatStatementsSettingsRow
+		Dealloc          db.StatStatementsDeallocationsRow
+		ExpectedSeverity check.Severity
+		ExpectedRows     int
+		ExpectedDetails  string
+	}
+
+	info := db.StatStatementsSettingsRow{Installed: true, Loaded: true, MaxEntries: 5000, Track: "top", Save: "on", ComputeQueryID: "auto", HasInfo: true}
+
+	testCases := []testCase{
+		{
+			Name:             "never reset - OK",
+			Resets:           db.StatsResetTimesRow{DatabaseResetAgeSeconds: -1, BgwriterResetAgeSeconds: -1},
+			ExpectedSeverity: check.SeverityOK,
+			ExpectedRows:     2,
+			ExpectedDetails:  "count activity since these resets",
+		},
+		{
+			Name: "recent database reset only - OK, graded by statistics-freshness",
+			Resets: db.StatsResetTimesRow{
+				DatabaseStatsReset: "2026-10-17 09:00:00+00", DatabaseResetAgeSeconds: day,
+				BgwriterStatsReset: "2026-01-01 00:00:00+00", BgwriterResetAgeSeconds: 200 * day,
+			},
+			ExpectedSeverity: check.SeverityOK,
+			ExpectedRows:     2,
+		},
+		{
+			Name: "recent bgwriter reset - WARN",
+			Resets: db.StatsResetTimesRow{
+				DatabaseResetAgeSeconds: -1,
+				BgwriterStatsReset:      "2026-10-16 09:00:00+00", BgwriterResetAgeSeconds: 2 * day,
+			},
+			ExpectedSeverity: check.SeverityWarn,
+			ExpectedRows:     2,
+			ExpectedDetails:  "pg_stat_bgwriter (2d ago)",
+		},
+		{
+			Name:             "recent pg_stat_statements reset - WARN",
+			Resets:           db.StatsResetTimesRow{DatabaseResetAgeSeconds: -1, BgwriterResetAgeSeconds: -1},
+			Settings:         info,
+			Dealloc:          db.StatStatementsDeallocationsRow{StatsReset: "2026-10-18 06:00:00+00", ResetAgeSeconds: 3 * 3600},
+			ExpectedSeverity: check.SeverityWarn,
+			ExpectedRows:     3,
+			ExpectedDetails:  "pg_stat_statements (3h ago)",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+
+			queryer := newMockQueryer(db.StatisticsFreshnessRow{})
+			queryer.resets = tc.Resets
+			queryer.settings = tc.Settings
+			queryer.dealloc = tc.Dealloc
+
+			report, err := statisticsfreshness.New(queryer).Check(context.Background())
+			require.NoError(t, err)
+
+			results := report.Results
+			require.Equal(t, 3, len(results))
+
+			result := results[2]
+			require.Equal(t, "stats-reset-times", result.ID)
+			require.Equal(t, tc.ExpectedSeverity, result.Severity)
+			require.Contains(t, result.Details, tc.ExpectedDetails)
+			require.NotNil(t, result.Table)
+			require.Len(t, result.Table.Rows, tc.ExpectedRows)
+		})
+	}
+}
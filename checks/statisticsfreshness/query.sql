@@ -10,3 +10,50 @@ SELECT
   , (now() - stats_reset) AS age_interval
 FROM pg_stat_database
 WHERE datname = current_database();
+
+-- name: StatStatementsSettings :one
+-- Whether pg_stat_statements is installed in this database and loaded into
+-- the server, and the settings deciding what it records. Settings read as
+-- empty when the library is not loaded. has_info is false before
+-- PostgreSQL 14 (extension 1.9), which lacks pg_stat_statements_info.
+SELECT
+  EXISTS(
+    SELECT 1 FROM pg_extension
+    WHERE extname = 'pg_stat_statements'
+  ) AS installed
+  , (CURRENT_SETTING('pg_stat_statements.max', true) IS NOT NULL) AS loaded
+  , COALESCE(CURRENT_SETTING('pg_stat_statements.max', true), '0')::bigint AS max_entries
+  , COALESCE(CURRENT_SETTING('pg_stat_statements.track', true), '')::text AS track
+  , COALESCE(CURRENT_SETTING('pg_stat_statements.save', true), '')::text AS save
+  , COALESCE(CURRENT_SETTING('compute_query_id', true), '')::text AS compute_query_id
+  , (TO_REGCLASS('pg_stat_statements_info') IS NOT NULL) AS has_info;
+
+-- name: StatStatementsUsage :one
+-- Entries in pg_stat_statements and the size of their query texts, which
+-- live in an external file read in full on every scan of the view.
+SELECT
+  COUNT(*)::bigint AS entries
+  , COALESCE(SUM(OCTET_LENGTH(query)), 0)::bigint AS query_text_bytes
+FROM pg_stat_statements;
+
+-- name: StatStatementsDeallocations :one
+-- Times pg_stat_statements evicted its least-used entries to make room for
+-- new statements, since its statistics were last reset (PostgreSQL 14+).
+SELECT
+  dealloc::bigint AS deallocations
+  , COALESCE(EXTRACT(EPOCH FROM NOW() - stats_reset), 0)::bigint AS reset_age_seconds
+  , COALESCE(stats_reset::text, '')::text AS stats_reset
+FROM pg_stat_statements_info;
+
+-- name: StatsResetTimes :one
+-- When the cumulative statistics behind usage-based checks were last reset:
+-- per-database counters (pg_stat_database) and the background writer and
+-- checkpoint counters (pg_stat_bgwriter). Ages are -1 when never reset.
+SELECT
+  COALESCE(d.stats_reset::text, '')::text AS database_stats_reset
+  , COALESCE(EXTRACT(EPOCH FROM NOW() - d.stats_reset), -1)::bigint AS database_reset_age_seconds
+  , COALESCE(b.stats_reset::text, '')::text AS bgwriter_stats_reset
+  , COALESCE(EXTRACT(EPOCH FROM NOW() - b.stats_reset), -1)::bigint AS bgwriter_reset_age_seconds
+FROM pg_stat_database AS d
+CROSS JOIN pg_stat_bgwriter AS b
+WHERE d.datname = CURRENT_DATABASE();
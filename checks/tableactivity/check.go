@@ -5,9 +5,13 @@ import (
 	"context"
 	_ "embed"
 	"fmt"
+	"slices"
+	"strings"
 
 	"github.com/fresha/pgdoctor/check"
 	"github.com/fresha/pgdoctor/db"
+	"github.com/fresha/pgdoctor/internal/accesspattern"
+	"github.com/fresha/pgdoctor/internal/pgident"
 )
 
 //go:embed query.sql
@@ -16,6 +20,14 @@ var querySQL string
 //go:embed README.md
 var readme string
 
+const (
+	// Fillfactor recommended for update-heavy tables still at the default,
+	// leaving 10% of each page for new row versions.
+	hotFillfactor = 90
+	// Unused indexes named in a prescription; the rest are counted.
+	maxListedIndexes = 3
+)
+
 type TableActivityQueries interface {
 	TableActivity(context.Context) ([]db.TableActivityRow, error)
 }
@@ -26,12 +38,18 @@ type checker struct {
 
 func Metadata() check.Metadata {
 	return check.Metadata{
-		Category:    check.CategoryPerformance,
-		CheckID:     "table-activity",
-		Name:        "Table Activity",
-		Description: "Analyzes table write activity to identify high-churn tables and HOT update efficiency issues",
-		Readme:      readme,
-		SQL:         querySQL,
+		Category:       check.CategoryPerformance,
+		CheckID:        "table-activity",
+		Name:           "Table Activity",
+		Description:    "Analyzes table write activity to identify high-churn tables and HOT update efficiency issues",
+		Readme:         readme,
+		SQL:            querySQL,
+		RuntimeClass:   check.RuntimeMedium,
+		ProductionSafe: true,
+		Findings: []check.FindingSpec{
+			{ID: "high-churn-tables", Description: "Tables receiving a very high volume of writes", Thresholds: "WARN > 1M writes"},
+			{ID: "low-hot-ratio", Description: "Update-heavy tables with few HOT updates, with a fillfactor or index prescription for each", Thresholds: "WARN < 50% HOT on tables > 1M rows"},
+		},
 	}
 }
 
@@ -47,11 +65,15 @@ func (c *checker) Metadata() check.Metadata {
 
 func (c *checker) Check(ctx context.Context) (*check.Report, error) {
 	report := check.NewReport(Metadata())
+	ignore := check.ObjectFilterFromContext(ctx)
 
 	rows, err := c.queries.TableActivity(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("running %s/%s: %w", check.CategoryPerformance, report.CheckID, err)
 	}
+	rows = slices.DeleteFunc(rows, func(row db.TableActivityRow) bool {
+		return ignore.Table(row.Schemaname.String, row.Relname.String)
+	})
 
 	if len(rows) == 0 {
 		report.AddFinding(check.Finding{
@@ -91,7 +113,7 @@ func checkHighChurnTables(rows []db.TableActivityRow, report *check.Report) {
 		return
 	}
 
-	headers := []string{"Schema", "Table", "Inserts", "Updates", "Deletes", "Total Writes", "Size"}
+	headers := []string{"Schema", "Table", "Inserts", "Updates", "Deletes", "Total Writes", "Write Pattern", "Size"}
 	var tableRows []check.TableRow
 
 	for _, row := range highChurn {
@@ -104,6 +126,7 @@ func checkHighChurnTables(rows []db.TableActivityRow, report *check.Report) {
 				check.FormatNumber(check.Int8ToInt64(row.NTupUpd)),
 				check.FormatNumber(check.Int8ToInt64(row.NTupDel)),
 				check.FormatNumber(totalWrites),
+				string(writePattern(row)),
 				check.FormatBytes(check.Int8ToInt64(row.TableSizeBytes)),
 			},
 			Severity: check.SeverityWarn,
@@ -138,6 +161,11 @@ func checkLowHOTRatio(rows []db.TableActivityRow, report *check.Report) {
 		if liveTup < minRows || nTupUpd < minUpdates {
 			continue
 		}
+		// Updates are a small share of an append-only table's writes, so
+		// its HOT ratio says little.
+		if writePattern(row) == accesspattern.AppendOnly {
+			continue
+		}
 
 		hotRatio := calculateHOTRatio(row)
 		if hotRatio < lowHOTRatio {
@@ -155,8 +183,9 @@ func checkLowHOTRatio(rows []db.TableActivityRow, report *check.Report) {
 		return
 	}
 
-	headers := []string{"Schema", "Table", "HOT Ratio", "Updates", "HOT Updates", "Live Rows"}
+	headers := []string{"Schema", "Table", "HOT Ratio", "Updates", "HOT Updates", "Live Rows", "Fillfactor", "Indexes", "Prescription"}
 	var tableRows []check.TableRow
+	var fixes []check.Fix
 
 	for _, row := range lowHOT {
 		hotRatio := calculateHOTRatio(row)
@@ -168,20 +197,71 @@ func checkLowHOTRatio(rows []db.TableActivityRow, report *check.Report) {
 				check.FormatNumber(check.Int8ToInt64(row.NTupUpd)),
 				check.FormatNumber(check.Int8ToInt64(row.NTupHotUpd)),
 				check.FormatNumber(check.Int8ToInt64(row.NLiveTup)),
+				fmt.Sprintf("%d", row.Fillfactor),
+				fmt.Sprintf("%d", row.IndexCount),
+				hotPrescription(row),
 			},
 			Severity: check.SeverityWarn,
 		})
+
+		if row.Fillfactor >= 100 {
+			table := row.Schemaname.String + "." + row.Relname.String
+			fixes = append(fixes, check.Fix{
+				Object: table,
+				Description: fmt.Sprintf("Leave %d%% of each new page of %s free for HOT updates. Existing pages only gain free space "+
+					"when the table is rewritten (pg_repack or VACUUM FULL)", 100-hotFillfactor, table),
+				SQL:  fmt.Sprintf("ALTER TABLE %s SET (fillfactor = %d)", pgident.Quote(row.Schemaname.String, row.Relname.String), hotFillfactor),
+				Risk: check.RiskLow,
+				Lock: check.LockOnline,
+			})
+		}
 	}
 
 	report.AddFinding(check.Finding{
 		ID:       "low-hot-ratio",
 		Name:     "HOT Update Efficiency",
 		Severity: check.SeverityWarn,
-		Details:  fmt.Sprintf("Found %d large table(s) with low HOT update ratio (<50%%)", len(lowHOT)),
+		Details: fmt.Sprintf("Found %d large table(s) with low HOT update ratio (<50%%). An update is HOT only when no indexed column changes "+
+			"and the new row version fits on the same page; each non-HOT update writes a new entry into every index. "+
+			"Tables at the default fillfactor have no room reserved on their pages; tables that already reserve room most likely update indexed columns, "+
+			"so every index on them, and especially unused ones, should earn its place", len(lowHOT)),
 		Table: &check.Table{
 			Headers: headers,
 			Rows:    tableRows,
 		},
+		Fixes: fixes,
+	})
+}
+
+// hotPrescription suggests how to make more of a table's updates HOT: room
+// on its pages when the fillfactor is still the default, and dropping
+// indexes nothing scans.
+func hotPrescription(row db.TableActivityRow) string {
+	var steps []string
+	if row.Fillfactor >= 100 {
+		steps = append(steps, fmt.Sprintf("set fillfactor = %d", hotFillfactor))
+	}
+
+	if n := len(row.UnusedIndexes); n > 0 {
+		names := strings.Join(row.UnusedIndexes[:min(n, maxListedIndexes)], ", ")
+		if n > maxListedIndexes {
+			names += fmt.Sprintf(" and %d more", n-maxListedIndexes)
+		}
+		steps = append(steps, fmt.Sprintf("drop unused index(es) %s", names))
+	} else if row.Fillfactor < 100 {
+		steps = append(steps, "updates likely change indexed columns: review which indexes they need")
+	}
+
+	return strings.Join(steps, "; ")
+}
+
+// writePattern classifies the table from its write counters.
+func writePattern(row db.TableActivityRow) accesspattern.Pattern {
+	return accesspattern.Classify(accesspattern.Counters{
+		Inserts:  check.Int8ToInt64(row.NTupIns),
+		Updates:  check.Int8ToInt64(row.NTupUpd),
+		Deletes:  check.Int8ToInt64(row.NTupDel),
+		LiveRows: check.Int8ToInt64(row.NLiveTup),
 	})
 }
 
@@ -0,0 +1,75 @@
+package tableactivity_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/fresha/pgdoctor/check"
+	"github.com/fresha/pgdoctor/check/checktest"
+	"github.com/fresha/pgdoctor/checks/tableactivity"
+	"github.com/fresha/pgdoctor/db"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockQueryer struct {
+	rows []db.TableActivityRow
+}
+
+func (m *mockQueryer) TableActivity(context.Context) ([]db.TableActivityRow, error) {
+	return m.rows, nil
+}
+
+// updateHeavy returns a 2M-row table where 10% of 5M updates were HOT.
+func updateHeavy(fillfactor int32, unused ...string) db.TableActivityRow {
+	return db.TableActivityRow{
+		Schemaname:     pgtype.Text{String: "public", Valid: true},
+		Relname:        pgtype.Text{String: "orders", Valid: true},
+		NTupIns:        pgtype.Int8{Int64: 2000000, Valid: true},
+		NTupUpd:        pgtype.Int8{Int64: 5000000, Valid: true},
+		NTupHotUpd:     pgtype.Int8{Int64: 500000, Valid: true},
+		NLiveTup:       pgtype.Int8{Int64: 2000000, Valid: true},
+		TableSizeBytes: pgtype.Int8{Int64: 1 << 30, Valid: true},
+		Fillfactor:     fillfactor,
+		IndexCount:     int64(2 + len(unused)),
+		UnusedIndexes:  unused,
+	}
+}
+
+// TestTableActivity_HOTPrescription pins the whole report for each
+// prescription in testdata; run go test -update after changing one.
+func TestTableActivity_HOTPrescription(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		row  db.TableActivityRow
+	}{
+		{name: "default-fillfactor", row: updateHeavy(100)},
+		{name: "default-fillfactor-unused-indexes", row: updateHeavy(100, "orders_note_idx")},
+		{name: "lowered-fillfactor-many-unused-indexes", row: updateHeavy(80, "a_idx", "b_idx", "c_idx", "d_idx")},
+		{name: "lowered-fillfactor", row: updateHeavy(80)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			checktest.Golden(t, "hot-prescription/"+tt.name, tableactivity.New(&mockQueryer{rows: []db.TableActivityRow{tt.row}}))
+		})
+	}
+}
+
+func TestTableActivity_HealthyHOTRatio(t *testing.T) {
+	t.Parallel()
+
+	row := updateHeavy(100)
+	row.NTupHotUpd = pgtype.Int8{Int64: 4500000, Valid: true}
+
+	report, err := tableactivity.New(&mockQueryer{rows: []db.TableActivityRow{row}}).Check(context.Background())
+	require.NoError(t, err)
+
+	f := checktest.Finding(t, report, "low-hot-ratio")
+	assert.Equal(t, check.SeverityOK, f.Severity)
+	assert.Empty(t, f.Fixes)
+}
@@ -1,15 +1,32 @@
 -- name: TableActivity :many
 -- Retrieves table write activity metrics from pg_stat_user_tables
 -- Used to identify high-churn tables and HOT update efficiency issues
+-- Fillfactor and unused indexes explain why updates are not HOT
 SELECT
-  schemaname
-  , relname
-  , n_tup_ins
-  , n_tup_upd
-  , n_tup_del
-  , n_tup_hot_upd
-  , n_live_tup
-  , pg_table_size(relid) AS table_size_bytes
-FROM pg_stat_user_tables
-WHERE n_tup_ins + n_tup_upd + n_tup_del > 0
-ORDER BY n_tup_ins + n_tup_upd + n_tup_del DESC;
+  s.schemaname
+  , s.relname
+  , s.n_tup_ins
+  , s.n_tup_upd
+  , s.n_tup_del
+  , s.n_tup_hot_upd
+  , s.n_live_tup
+  , pg_table_size(s.relid) AS table_size_bytes
+  , COALESCE(SUBSTRING(ARRAY_TO_STRING(c.reloptions, ',') FROM 'fillfactor=([0-9]+)')::integer, 100)::integer AS fillfactor
+  , (SELECT COUNT(*) FROM pg_index AS x WHERE x.indrelid = s.relid)::bigint AS index_count
+  -- Indexes never scanned that back no constraint: each one still has to be
+  -- updated whenever an update of its columns cannot be HOT
+  , ARRAY(
+    SELECT ui.indexrelname::text
+    FROM pg_stat_user_indexes AS ui
+    INNER JOIN pg_index AS x ON ui.indexrelid = x.indexrelid
+    WHERE
+      ui.relid = s.relid
+      AND ui.idx_scan = 0
+      AND NOT x.indisunique
+      AND NOT EXISTS (SELECT 1 FROM pg_constraint AS con WHERE con.conindid = ui.indexrelid)
+    ORDER BY pg_relation_size(ui.indexrelid) DESC
+  )::text [] AS unused_indexes
+FROM pg_stat_user_tables AS s
+INNER JOIN pg_class AS c ON s.relid = c.oid
+WHERE s.n_tup_ins + s.n_tup_upd + s.n_tup_del > 0
+ORDER BY s.n_tup_ins + s.n_tup_upd + s.n_tup_del DESC;
@@ -2,11 +2,14 @@ package tablebloat_test
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/fresha/pgdoctor/check"
+	"github.com/fresha/pgdoctor/check/checktest"
 	"github.com/fresha/pgdoctor/checks/tablebloat"
 	"github.com/fresha/pgdoctor/db"
 	"github.com/jackc/pgx/v5/pgtype"
@@ -15,14 +18,38 @@ import (
 )
 
 type mockQueryer struct {
-	rows []db.TableBloatRow
-	err  error
+	rows        []db.TableBloatRow
+	extensions  db.RepackExtensionsRow
+	err         error
+	pgstattuple bool
+	usage       map[string]db.TableSpaceUsageRow // keyed by table name
+	usageErr    map[string]error                 // keyed by table name
+	measured    []string
+	timeouts    []time.Duration
 }
 
 func (m *mockQueryer) TableBloat(ctx context.Context) ([]db.TableBloatRow, error) {
 	return m.rows, m.err
 }
 
+func (m *mockQueryer) RepackExtensions(context.Context) (db.RepackExtensionsRow, error) {
+	return m.extensions, nil
+}
+
+func (m *mockQueryer) PgstattupleApproxAvailable(context.Context) (bool, error) {
+	return m.pgstattuple, nil
+}
+
+func (m *mockQueryer) TableSpaceUsage(_ context.Context, arg db.TableSpaceUsageParams) (db.TableSpaceUsageRow, error) {
+	m.measured = append(m.measured, arg.SchemaName+"."+arg.TableName)
+	return m.usage[arg.TableName], m.usageErr[arg.TableName]
+}
+
+func (m *mockQueryer) WithStatementTimeout(_ context.Context, timeout time.Duration, fn func() error) error {
+	m.timeouts = append(m.timeouts, timeout)
+	return fn()
+}
+
 func makeTableRow(
 	tableName string,
 	liveTuples, deadTuples int64,
@@ -34,8 +61,11 @@ func makeTableRow(
 	var deadPct pgtype.Numeric
 	_ = deadPct.Scan(fmt.Sprintf("%.2f", deadTuplePct))
 
+	schema, table, _ := strings.Cut(tableName, ".")
 	row := db.TableBloatRow{
 		TableName:        pgtype.Text{String: tableName, Valid: true},
+		SchemaName:       pgtype.Text{String: schema, Valid: true},
+		Relname:          pgtype.Text{String: table, Valid: true},
 		LiveTuples:       pgtype.Int8{Int64: liveTuples, Valid: true},
 		DeadTuples:       pgtype.Int8{Int64: deadTuples, Valid: true},
 		DeadTuplePercent: deadPct,
@@ -208,6 +238,10 @@ func TestTableBloat_LargeBloated_Warning(t *testing.T) {
 	assert.Equal(t, "large-bloated-tables", largeBloatFinding.ID)
 	assert.Equal(t, check.SeverityWarn, largeBloatFinding.Severity)
 	assert.Contains(t, largeBloatFinding.Details, "Found 1 large table(s)")
+	require.Len(t, largeBloatFinding.Fixes, 1)
+	assert.Equal(t, `VACUUM FULL "public"."bookings"`, largeBloatFinding.Fixes[0].SQL)
+	assert.Equal(t, check.RiskHigh, largeBloatFinding.Fixes[0].Risk)
+	assert.True(t, largeBloatFinding.Fixes[0].NeedsMaintenanceWindow())
 }
 
 func TestTableBloat_LargeBloated_Critical(t *testing.T) {
@@ -373,3 +407,186 @@ func TestTableBloat_Metadata(t *testing.T) {
 	assert.NotEmpty(t, metadata.Readme)
 	assert.NotEmpty(t, metadata.Description)
 }
+
+func TestTableBloat_RewritePrescription(t *testing.T) {
+	t.Parallel()
+
+	const gb = int64(1024 * 1024 * 1024)
+	recentVacuum := time.Now().Add(-1 * time.Hour)
+
+	tests := []struct {
+		name       string
+		table      string
+		extensions db.RepackExtensionsRow
+		contains   []string
+	}{
+		{
+			name:     "no extensions",
+			contains: []string{"VACUUM FULL", "ACCESS EXCLUSIVE"},
+		},
+		{
+			name:       "pg_repack",
+			extensions: db.RepackExtensionsRow{HasPgRepack: true, HasPgSqueeze: true, DatabaseName: "app"},
+			contains:   []string{`pg_repack --no-order --table='"public"."orders"' -d 'app'  # needs ~15.0GiB free`},
+		},
+		{
+			name:       "pg_repack with quotes in the name",
+			table:      "Sales.O'Brien Orders",
+			extensions: db.RepackExtensionsRow{HasPgRepack: true, DatabaseName: "app"},
+			contains:   []string{`pg_repack --no-order --table='"Sales"."O'\''Brien Orders"' -d 'app'`},
+		},
+		{
+			name:       "pg_squeeze",
+			extensions: db.RepackExtensionsRow{HasPgSqueeze: true, DatabaseName: "app"},
+			contains:   []string{"SELECT squeeze.squeeze_table('public', 'orders');  -- needs ~15.0GiB free", "wal_level = logical"},
+		},
+		{
+			name:       "pg_squeeze with quotes in the name",
+			table:      "Sales.O'Brien Orders",
+			extensions: db.RepackExtensionsRow{HasPgSqueeze: true, DatabaseName: "app"},
+			contains:   []string{"SELECT squeeze.squeeze_table('Sales', 'O''Brien Orders');"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			table := tt.table
+			if table == "" {
+				table = "public.orders"
+			}
+			queryer := &mockQueryer{
+				rows: []db.TableBloatRow{
+					makeTableRow(table, 750_000, 250_000, 25, 20*gb, &recentVacuum, nil, 10),
+				},
+				extensions: tt.extensions,
+			}
+
+			report, err := tablebloat.New(queryer).Check(context.Background())
+			require.NoError(t, err)
+
+			var details string
+			for _, f := range report.Results {
+				if f.ID == "large-bloated-tables" {
+					details = f.Details
+				}
+			}
+			for _, want := range tt.contains {
+				assert.Contains(t, details, want)
+			}
+		})
+	}
+}
+
+func deepConfig(top string) check.Config {
+	return check.Config{"table-bloat": {"deep_bloat_top": top}}
+}
+
+func TestTableBloat_DeepVerification(t *testing.T) {
+	t.Parallel()
+
+	const gb = int64(1024 * 1024 * 1024)
+	recentVacuum := time.Now().Add(-1 * time.Hour)
+	rows := []db.TableBloatRow{
+		makeTableRow("public.orders", 500_000, 500_000, 50, 20*gb, &recentVacuum, nil, 10),
+		makeTableRow("public.events", 550_000, 450_000, 45, 2*gb, &recentVacuum, nil, 10),
+		makeTableRow("public.users", 750_000, 250_000, 25, 100*1024*1024, &recentVacuum, nil, 10),
+	}
+
+	t.Run("measures the worst tables and confirms FAIL", func(t *testing.T) {
+		t.Parallel()
+
+		queryer := &mockQueryer{
+			rows:        rows,
+			pgstattuple: true,
+			usage: map[string]db.TableSpaceUsageRow{
+				"orders": {TableLen: 16 * gb, DeadTupleLen: 7 * gb, DeadTuplePercent: 43.75, FreeSpace: 1 * gb, FreePercent: 6.25},
+				"events": {TableLen: 2 * gb, DeadTupleLen: 20 * 1024 * 1024, DeadTuplePercent: 1, FreeSpace: 40 * 1024 * 1024, FreePercent: 2},
+			},
+		}
+
+		report, err := tablebloat.New(queryer, deepConfig("2")).Check(context.Background())
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"public.orders", "public.events"}, queryer.measured)
+		assert.Equal(t, check.SeverityFail, report.Severity)
+
+		high := checktest.Finding(t, report, "high-dead-tuples")
+		assert.Equal(t, check.SeverityFail, high.Severity)
+		assert.Equal(t, "Basis", high.Table.Headers[len(high.Table.Headers)-1])
+		require.Len(t, high.Table.Rows, 2, "events was cleared by its measurement")
+		assert.Equal(t, []string{"public.orders", "43.8%", "500.0K", "500.0K", "20.0GiB", "measured"}, high.Table.Rows[0].Cells)
+		assert.Equal(t, "estimated", high.Table.Rows[1].Cells[5])
+
+		large := checktest.Finding(t, report, "large-bloated-tables")
+		assert.Equal(t, check.SeverityFail, large.Severity)
+		assert.Equal(t, check.ConfidenceHigh, large.Confidence)
+		require.Len(t, large.Table.Rows, 1)
+		assert.Equal(t, []string{"public.orders", "20.0GiB", "50.0%", "8.0GiB", "measured"}, large.Table.Rows[0].Cells)
+
+		deep := checktest.Finding(t, report, "deep-verification")
+		assert.Equal(t, check.SeverityOK, deep.Severity)
+		assert.Contains(t, deep.Details, "1 measured below every threshold")
+		require.Len(t, deep.Table.Rows, 2)
+		assert.Equal(t, "confirmed", deep.Table.Rows[0].Cells[5])
+		assert.Equal(t, "cleared", deep.Table.Rows[1].Cells[5])
+	})
+
+	t.Run("a failed measurement keeps the estimate", func(t *testing.T) {
+		t.Parallel()
+
+		queryer := &mockQueryer{
+			rows:        rows,
+			pgstattuple: true,
+			usage: map[string]db.TableSpaceUsageRow{
+				"events": {TableLen: 2 * gb, DeadTupleLen: 20 * 1024 * 1024, DeadTuplePercent: 1, FreeSpace: 40 * 1024 * 1024, FreePercent: 2},
+			},
+			usageErr: map[string]error{
+				"orders": errors.New("canceling statement due to statement timeout"),
+			},
+		}
+
+		cfg := check.Config{"table-bloat": {"deep_bloat_top": "2", "deep_bloat_timeout_ms": "5000"}}
+		report, err := tablebloat.New(queryer, cfg).Check(context.Background())
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"public.orders", "public.events"}, queryer.measured)
+		assert.Equal(t, []time.Duration{5 * time.Second, 5 * time.Second}, queryer.timeouts)
+		assert.Equal(t, check.SeverityWarn, report.Severity, "an unmeasured table cannot confirm FAIL")
+
+		high := checktest.Finding(t, report, "high-dead-tuples")
+		require.Len(t, high.Table.Rows, 2)
+		assert.Equal(t, []string{"public.orders", "50.0%", "500.0K", "500.0K", "20.0GiB", "estimated"}, high.Table.Rows[0].Cells)
+
+		deep := checktest.Finding(t, report, "deep-verification")
+		assert.Contains(t, deep.Details, "1 could not be measured")
+		require.Len(t, deep.Table.Rows, 2)
+		assert.Equal(t, "not measured: canceling statement due to statement timeout", deep.Table.Rows[0].Cells[5])
+		assert.Equal(t, "cleared", deep.Table.Rows[1].Cells[5])
+	})
+
+	t.Run("estimates alone only warn", func(t *testing.T) {
+		t.Parallel()
+
+		report, err := tablebloat.New(&mockQueryer{rows: rows}).Check(context.Background())
+		require.NoError(t, err)
+
+		assert.Equal(t, check.SeverityWarn, report.Severity)
+		checktest.NoFinding(t, report, "deep-verification")
+		assert.Equal(t, "Wasted Space (est)", checktest.Finding(t, report, "large-bloated-tables").Table.Headers[3])
+	})
+
+	t.Run("pgstattuple unavailable", func(t *testing.T) {
+		t.Parallel()
+
+		queryer := &mockQueryer{rows: rows}
+		report, err := tablebloat.New(queryer, deepConfig("5")).Check(context.Background())
+		require.NoError(t, err)
+
+		assert.Empty(t, queryer.measured)
+		assert.Equal(t, check.SeverityWarn, report.Severity)
+		deep := checktest.Finding(t, report, "deep-verification")
+		assert.Contains(t, deep.Details, "CREATE EXTENSION pgstattuple")
+	})
+}
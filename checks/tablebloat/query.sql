@@ -2,6 +2,8 @@
 -- Identifies tables with high dead tuple percentages indicating vacuum issues
 SELECT
   (schemaname || '.' || relname)::text AS table_name
+  , schemaname::text AS schema_name
+  , relname::text AS relname
   , n_live_tup AS live_tuples
   , n_dead_tup AS dead_tuples
   , last_autovacuum
@@ -16,9 +18,35 @@ SELECT
       THEN ROUND((n_dead_tup::numeric / (n_live_tup + n_dead_tup)::numeric) * 100, 2)
     ELSE 0
   END AS dead_tuple_percent
-  , PG_TOTAL_RELATION_SIZE(schemaname || '.' || relname) AS total_size_bytes
+  , PG_TOTAL_RELATION_SIZE(relid) AS total_size_bytes
 FROM pg_stat_user_tables
 WHERE
   schemaname NOT IN ('pg_catalog', 'information_schema')
   AND n_dead_tup > 1000  -- Ignore tiny tables with few dead tuples
 ORDER BY dead_tuple_percent DESC, n_dead_tup DESC;
+
+-- name: RepackExtensions :one
+-- Checks which online table rewrite extensions are installed.
+SELECT
+  EXISTS(SELECT 1 FROM pg_extension WHERE extname = 'pg_repack') AS has_pg_repack
+  , EXISTS(SELECT 1 FROM pg_extension WHERE extname = 'pg_squeeze') AS has_pg_squeeze
+  , CURRENT_DATABASE()::text AS database_name;
+
+-- name: PgstattupleApproxAvailable :one
+-- Checks whether pgstattuple_approx is installed and executable by the current role.
+SELECT EXISTS(
+  SELECT 1
+  FROM pg_proc
+  WHERE proname = 'pgstattuple_approx' AND HAS_FUNCTION_PRIVILEGE(oid, 'EXECUTE')
+) AS available;
+
+-- name: TableSpaceUsage :one
+-- Measures a table's dead tuples and free space with pgstattuple_approx, which
+-- reads every heap page not marked all-visible.
+SELECT
+  table_len
+  , dead_tuple_len
+  , dead_tuple_percent
+  , approx_free_space AS free_space
+  , approx_free_percent AS free_percent
+FROM PGSTATTUPLE_APPROX(FORMAT('%I.%I', sqlc.arg(schema_name)::text, sqlc.arg(table_name)::text)::regclass);
@@ -5,10 +5,12 @@ import (
 	"context"
 	_ "embed"
 	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/fresha/pgdoctor/check"
 	"github.com/fresha/pgdoctor/db"
+	"github.com/fresha/pgdoctor/internal/pgident"
 )
 
 //go:embed query.sql
@@ -22,10 +24,23 @@ const (
 	warnRatioThreshold = 10.0
 	failRowThreshold   = 50000
 	failRatioThreshold = 50.0
+
+	// A column filtered on this often, with this many distinct values but
+	// only this few MCVs at the default target, is estimated from a sample
+	// too small to see its frequent values.
+	minPredicateCalls = int64(1000)
+	minDistinctValues = int64(100_000)
+	maxMCVs           = int32(10)
+
+	// Statistics target proposed for those columns.
+	raisedStatisticsTarget = 1000
 )
 
 type TableSeqScansQueries interface {
-	HighSeqScanTables(context.Context) ([]db.HighSeqScanTablesRow, error)
+	HighSeqScanTables(context.Context, string) ([]db.HighSeqScanTablesRow, error)
+	SeqScanStatStatementsAvailable(context.Context) (bool, error)
+	LowResolutionColumns(context.Context, string) ([]db.LowResolutionColumnsRow, error)
+	PredicateStatements(context.Context) ([]db.PredicateStatementsRow, error)
 }
 
 type checker struct {
@@ -34,12 +49,20 @@ type checker struct {
 
 func Metadata() check.Metadata {
 	return check.Metadata{
-		Category:    check.CategoryPerformance,
-		CheckID:     "table-seq-scans",
-		Name:        "Table Sequential Scans",
-		Description: "Identifies tables with excessive sequential scans that may benefit from indexes",
-		Readme:      readme,
-		SQL:         querySQL,
+		Category:       check.CategoryPerformance,
+		CheckID:        "table-seq-scans",
+		Name:           "Table Sequential Scans",
+		Description:    "Identifies tables with excessive sequential scans that may benefit from indexes",
+		Readme:         readme,
+		SQL:            querySQL,
+		RuntimeClass:   check.RuntimeMedium,
+		ProductionSafe: true,
+		Privileges:     []check.Privilege{check.PrivilegeReadAllStats, check.PrivilegeReadSchemas},
+		Findings: []check.FindingSpec{
+			{ID: "high-seq-scans", Description: "Indexed tables read mostly by sequential scans", Thresholds: "FAIL > 50:1 on tables > 50,000 rows"},
+			{ID: "moderate-seq-scans", Description: "Indexed tables with an elevated sequential scan ratio", Thresholds: "WARN > 10:1 on tables > 10,000 rows"},
+			{ID: "low-statistics-target", Description: "Columns of large tables filtered on in frequent statements whose statistics are too coarse for their distinct values (requires pg_stat_statements)", Thresholds: "WARN >= 1,000 calls filtering a column with >= 100K distinct values and < 10 MCVs at the default target"},
+		},
 	}
 }
 
@@ -55,11 +78,15 @@ func (c *checker) Metadata() check.Metadata {
 
 func (c *checker) Check(ctx context.Context) (*check.Report, error) {
 	report := check.NewReport(Metadata())
+	ignore := check.ObjectFilterFromContext(ctx)
 
-	rows, err := c.queries.HighSeqScanTables(ctx)
+	rows, err := c.queries.HighSeqScanTables(ctx, check.SchemaFromContext(ctx, "public"))
 	if err != nil {
 		return nil, fmt.Errorf("running %s/%s: %w", report.Category, report.CheckID, err)
 	}
+	rows = slices.DeleteFunc(rows, func(row db.HighSeqScanTablesRow) bool {
+		return ignore.QualifiedTable(row.TableName.String)
+	})
 
 	if len(rows) == 0 {
 		report.AddFinding(check.Finding{
@@ -67,10 +94,38 @@ func (c *checker) Check(ctx context.Context) (*check.Report, error) {
 			Name:     report.Name,
 			Severity: check.SeverityOK,
 		})
+	} else {
+		checkHighSeqScans(rows, report)
+	}
+
+	available, err := c.queries.SeqScanStatStatementsAvailable(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("running %s/%s (extension): %w", report.Category, report.CheckID, err)
+	}
+	if !available {
+		report.AddFinding(check.Finding{
+			ID:       "low-statistics-target",
+			Name:     "Low Statistics Target",
+			Severity: check.SeverityOK,
+			Details:  "Finding frequently filtered columns needs the pg_stat_statements extension, which is not installed",
+		})
 		return report, nil
 	}
 
-	checkHighSeqScans(rows, report)
+	columns, err := c.queries.LowResolutionColumns(ctx, check.SchemaFromContext(ctx, ""))
+	if err != nil {
+		return nil, fmt.Errorf("running %s/%s (column statistics): %w", report.Category, report.CheckID, err)
+	}
+	columns = slices.DeleteFunc(columns, func(row db.LowResolutionColumnsRow) bool {
+		return ignore.Table(row.SchemaName, row.TableName)
+	})
+
+	statements, err := c.queries.PredicateStatements(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("running %s/%s (statements): %w", report.Category, report.CheckID, err)
+	}
+
+	checkStatisticsTargets(columns, statements, report)
 
 	return report, nil
 }
@@ -119,6 +174,7 @@ func checkHighSeqScans(rows []db.HighSeqScanTablesRow, report *check.Report) {
 		if failCount > len(failTables) {
 			details += fmt.Sprintf("\n... and %d more", failCount-len(failTables))
 		}
+		details += fmt.Sprintf("\n\nScan counts accumulate since the last statistics reset (%s)", check.StatsResetFinding)
 
 		report.AddFinding(check.Finding{
 			ID:       "high-seq-scans",
@@ -136,6 +192,7 @@ func checkHighSeqScans(rows []db.HighSeqScanTablesRow, report *check.Report) {
 		if warnCount > len(warnTables) {
 			details += fmt.Sprintf("\n... and %d more", warnCount-len(warnTables))
 		}
+		details += fmt.Sprintf("\n\nScan counts accumulate since the last statistics reset (%s)", check.StatsResetFinding)
 
 		report.AddFinding(check.Finding{
 			ID:       "moderate-seq-scans",
@@ -153,3 +210,144 @@ func checkHighSeqScans(rows []db.HighSeqScanTablesRow, report *check.Report) {
 		})
 	}
 }
+
+// checkStatisticsTargets flags columns whose default-target statistics are too
+// coarse for their distinct values and that frequent statements filter on,
+// so misestimates for them turn into bad plans often.
+func checkStatisticsTargets(columns []db.LowResolutionColumnsRow, statements []db.PredicateStatementsRow, report *check.Report) {
+	var tableRows []check.TableRow
+	var fixes []check.Fix
+	analyze := map[string][]string{}
+	var tables []db.LowResolutionColumnsRow
+
+	for _, col := range columns {
+		if col.DistinctValues < minDistinctValues || col.McvCount >= maxMCVs {
+			continue
+		}
+		// Mostly unique columns have no frequent values to miss, so the
+		// default target already estimates them well.
+		if col.DistinctValues*2 >= col.EstimatedRows {
+			continue
+		}
+
+		calls := predicateCalls(col, statements)
+		if calls < minPredicateCalls {
+			continue
+		}
+
+		table := pgident.Quote(col.SchemaName, col.TableName)
+		column := pgident.Quote(col.ColumnName)
+		if _, ok := analyze[table]; !ok {
+			tables = append(tables, col)
+		}
+		analyze[table] = append(analyze[table], column)
+
+		tableRows = append(tableRows, check.TableRow{
+			Cells: []string{
+				col.SchemaName + "." + col.TableName,
+				col.ColumnName,
+				check.FormatNumber(col.EstimatedRows),
+				check.FormatNumber(col.DistinctValues),
+				fmt.Sprintf("%d", col.McvCount),
+				fmt.Sprintf("%d", max(col.HistogramBounds-1, 0)),
+				check.FormatNumber(calls),
+			},
+			Severity: check.SeverityWarn,
+		})
+		fixes = append(fixes, check.Fix{
+			Object:      col.SchemaName + "." + col.TableName + "." + col.ColumnName,
+			Description: fmt.Sprintf("Sample %s with a statistics target of %d", col.ColumnName, raisedStatisticsTarget),
+			SQL:         fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s SET STATISTICS %d", table, column, raisedStatisticsTarget),
+			Risk:        check.RiskLow,
+			Lock:        check.LockOnline,
+		})
+	}
+
+	if len(tableRows) == 0 {
+		report.AddFinding(check.Finding{
+			ID:       "low-statistics-target",
+			Name:     "Low Statistics Target",
+			Severity: check.SeverityOK,
+		})
+		return
+	}
+
+	// The new target only takes effect once the table is analyzed again.
+	for _, t := range tables {
+		table := pgident.Quote(t.SchemaName, t.TableName)
+		fixes = append(fixes, check.Fix{
+			Object:      t.SchemaName + "." + t.TableName,
+			Description: "Rebuild statistics with the raised targets",
+			SQL:         fmt.Sprintf("ANALYZE %s (%s)", table, strings.Join(analyze[table], ", ")),
+			Risk:        check.RiskLow,
+			Lock:        check.LockOnline,
+		})
+	}
+
+	report.AddFinding(check.Finding{
+		ID:         "low-statistics-target",
+		Name:       "Low Statistics Target",
+		Severity:   check.SeverityWarn,
+		Confidence: check.ConfidenceMedium,
+		Details: fmt.Sprintf("%d column(s) on large tables are filtered on by frequent statements but have many distinct values and few most-common values at the default statistics target. "+
+			"ANALYZE samples 300 rows per target unit, too few to find the frequent values in a large table, so the planner assumes every value is equally rare "+
+			"and may pick nested loops or index scans that are wrong for the common ones. Statements are matched to columns by name, so check the listed calls before raising targets",
+			len(tableRows)),
+		Table: &check.Table{
+			Headers: []string{"Table", "Column", "Rows", "Distinct", "MCVs", "Histogram Buckets", "Predicate Calls"},
+			Rows:    tableRows,
+		},
+		Fixes: fixes,
+	})
+}
+
+// predicateCalls sums the calls of statements that reference the column's
+// table and compare the column in their WHERE clause.
+func predicateCalls(col db.LowResolutionColumnsRow, statements []db.PredicateStatementsRow) int64 {
+	table := strings.ToLower(col.TableName)
+	column := strings.ToLower(col.ColumnName)
+
+	var calls int64
+	for _, st := range statements {
+		if !strings.Contains(st.Query, table) {
+			continue
+		}
+		if whereUsesColumn(whereClause(st.Query), column) {
+			calls += st.Calls
+		}
+	}
+	return calls
+}
+
+// whereClause returns the text after the first WHERE up to the clauses that
+// follow it.
+func whereClause(query string) string {
+	_, clause, ok := strings.Cut(query, " where ")
+	if !ok {
+		return ""
+	}
+	for _, marker := range []string{" order by", " group by", " having", " limit", " offset", " returning", " for update", " for share", ";"} {
+		if idx := strings.Index(clause, marker); idx != -1 {
+			clause = clause[:idx]
+		}
+	}
+	return clause
+}
+
+// whereUsesColumn reports whether a comparison in the clause starts with the
+// column, bare, quoted, or qualified by a table alias.
+func whereUsesColumn(clause, column string) bool {
+	if clause == "" {
+		return false
+	}
+	for _, name := range []string{column, `"` + column + `"`} {
+		for _, op := range []string{" =", "=", " >", ">", " <", "<", " in ", " between ", " like ", " ilike ", " = any", " is "} {
+			for _, prefix := range []string{" ", "(", "."} {
+				if strings.Contains(" "+clause, prefix+name+op) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
@@ -3,9 +3,11 @@ package tableseqscans_test
 import (
 	"context"
 	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/fresha/pgdoctor/check"
+	"github.com/fresha/pgdoctor/check/checktest"
 	"github.com/fresha/pgdoctor/checks/tableseqscans"
 	"github.com/fresha/pgdoctor/db"
 	"github.com/jackc/pgx/v5/pgtype"
@@ -20,15 +22,38 @@ const (
 type mockTableSeqScansQueryer struct {
 	rows []db.HighSeqScanTablesRow
 	err  error
+
+	statementsAvailable bool
+	columns             []db.LowResolutionColumnsRow
+	statements          []db.PredicateStatementsRow
+
+	// tableSchema and columnSchema are the schemas HighSeqScanTables and
+	// LowResolutionColumns were asked for.
+	tableSchema  string
+	columnSchema string
 }
 
-func (m *mockTableSeqScansQueryer) HighSeqScanTables(context.Context) ([]db.HighSeqScanTablesRow, error) {
+func (m *mockTableSeqScansQueryer) HighSeqScanTables(_ context.Context, schema string) ([]db.HighSeqScanTablesRow, error) {
+	m.tableSchema = schema
 	if m.err != nil {
 		return nil, m.err
 	}
 	return m.rows, nil
 }
 
+func (m *mockTableSeqScansQueryer) SeqScanStatStatementsAvailable(context.Context) (bool, error) {
+	return m.statementsAvailable, nil
+}
+
+func (m *mockTableSeqScansQueryer) LowResolutionColumns(_ context.Context, schema string) ([]db.LowResolutionColumnsRow, error) {
+	m.columnSchema = schema
+	return m.columns, nil
+}
+
+func (m *mockTableSeqScansQueryer) PredicateStatements(context.Context) ([]db.PredicateStatementsRow, error) {
+	return m.statements, nil
+}
+
 func newMockQueryer(rows []db.HighSeqScanTablesRow) *mockTableSeqScansQueryer {
 	return &mockTableSeqScansQueryer{rows: rows}
 }
@@ -58,7 +83,7 @@ func Test_TableSeqScans(t *testing.T) {
 			Name:             "no high seq scan tables - OK",
 			Rows:             []db.HighSeqScanTablesRow{},
 			ExpectedSeverity: check.SeverityOK,
-			ExpectedFindings: 1,
+			ExpectedFindings: 2,
 		},
 		{
 			Name: "moderate seq scans (>10k rows, >10 ratio) - WARN",
@@ -74,7 +99,7 @@ func Test_TableSeqScans(t *testing.T) {
 				},
 			},
 			ExpectedSeverity: check.SeverityWarn,
-			ExpectedFindings: 1,
+			ExpectedFindings: 2,
 		},
 		{
 			Name: "high seq scans (>50k rows, >50 ratio) - FAIL",
@@ -90,7 +115,7 @@ func Test_TableSeqScans(t *testing.T) {
 				},
 			},
 			ExpectedSeverity: check.SeverityFail,
-			ExpectedFindings: 1,
+			ExpectedFindings: 2,
 		},
 		{
 			Name: "table without indexes - skipped",
@@ -106,7 +131,7 @@ func Test_TableSeqScans(t *testing.T) {
 				},
 			},
 			ExpectedSeverity: check.SeverityOK,
-			ExpectedFindings: 1,
+			ExpectedFindings: 2,
 		},
 		{
 			Name: "mixed moderate and high seq scans - FAIL",
@@ -131,7 +156,7 @@ func Test_TableSeqScans(t *testing.T) {
 				},
 			},
 			ExpectedSeverity: check.SeverityFail,
-			ExpectedFindings: 2,
+			ExpectedFindings: 3,
 		},
 	}
 
@@ -198,6 +223,7 @@ func Test_TableSeqScans_HighSeqScans(t *testing.T) {
 	require.Contains(t, highSeqResult.Details, "seq: 10000")
 	require.Contains(t, highSeqResult.Details, "idx: 100")
 	require.Contains(t, highSeqResult.Details, "ratio: 100.0")
+	require.Contains(t, highSeqResult.Details, check.StatsResetFinding)
 }
 
 func Test_TableSeqScans_ModerateSeqScans(t *testing.T) {
@@ -331,7 +357,7 @@ func Test_TableSeqScans_NoIndexCount(t *testing.T) {
 	require.NoError(t, err)
 
 	results := report.Results
-	require.Equal(t, 1, len(results))
+	require.Equal(t, 2, len(results))
 
 	result := results[0]
 	require.Equal(t, check.SeverityOK, result.Severity, "Tables without indexes should not be flagged")
@@ -475,7 +501,7 @@ func Test_TableSeqScans_OKResult(t *testing.T) {
 	require.NoError(t, err)
 
 	results := report.Results
-	require.Equal(t, 1, len(results), "Should have exactly 1 result")
+	require.Equal(t, 2, len(results), "Should have the seq scan and statistics target results")
 
 	result := results[0]
 	require.Equal(t, check.SeverityOK, result.Severity, "Should be OK when no high seq scan tables")
@@ -505,9 +531,165 @@ func Test_TableSeqScans_NoOKResultWhenIssuesFound(t *testing.T) {
 	require.NoError(t, err)
 
 	results := report.Results
-	require.Equal(t, 1, len(results), "Should have 1 result when issues found")
+	require.Equal(t, 2, len(results), "Should have no OK seq scan result when issues found")
 
 	result := results[0]
 	require.Equal(t, "high-seq-scans", result.ID)
 	require.Equal(t, check.SeverityFail, result.Severity)
 }
+
+func Test_TableSeqScans_LowStatisticsTarget(t *testing.T) {
+	t.Parallel()
+
+	column := func(name string, distinct int64, mcvs int32) db.LowResolutionColumnsRow {
+		return db.LowResolutionColumnsRow{
+			SchemaName:      "public",
+			TableName:       "orders",
+			ColumnName:      name,
+			EstimatedRows:   50_000_000,
+			DistinctValues:  distinct,
+			McvCount:        mcvs,
+			HistogramBounds: 101,
+		}
+	}
+	statements := []db.PredicateStatementsRow{
+		{Query: "select * from orders o where o.customer_id = $1 and status = $2 order by created_at", Calls: 800},
+		{Query: "select count(*) from orders where customer_id in ($1, $2)", Calls: 400},
+		{Query: "update orders set status = $1 where id = $2", Calls: 5000},
+		{Query: "select * from customers where region = $1", Calls: 9000},
+		{Query: "select * from orders where (product_id = $1 or customer_id = $2) limit $3", Calls: 1500},
+	}
+
+	type testCase struct {
+		Name             string
+		Columns          []db.LowResolutionColumnsRow
+		ExpectedSeverity check.Severity
+		ExpectedColumns  []string
+	}
+
+	testCases := []testCase{
+		{
+			Name:             "frequently filtered high-cardinality column - WARN",
+			Columns:          []db.LowResolutionColumnsRow{column("customer_id", 2_000_000, 3)},
+			ExpectedSeverity: check.SeverityWarn,
+			ExpectedColumns:  []string{"customer_id"},
+		},
+		{
+			Name:             "enough MCVs - OK",
+			Columns:          []db.LowResolutionColumnsRow{column("customer_id", 2_000_000, 100)},
+			ExpectedSeverity: check.SeverityOK,
+		},
+		{
+			Name:             "few distinct values - OK",
+			Columns:          []db.LowResolutionColumnsRow{column("customer_id", 50_000, 3)},
+			ExpectedSeverity: check.SeverityOK,
+		},
+		{
+			Name:             "rarely filtered - OK",
+			Columns:          []db.LowResolutionColumnsRow{column("status", 200_000, 3)},
+			ExpectedSeverity: check.SeverityOK,
+		},
+		{
+			Name:             "column only in SET - OK",
+			Columns:          []db.LowResolutionColumnsRow{column("created_at", 40_000_000, 0)},
+			ExpectedSeverity: check.SeverityOK,
+		},
+		{
+			Name:             "unique column - OK",
+			Columns:          []db.LowResolutionColumnsRow{column("id", 50_000_000, 0)},
+			ExpectedSeverity: check.SeverityOK,
+		},
+		{
+			Name: "only matching columns flagged",
+			Columns: []db.LowResolutionColumnsRow{
+				column("customer_id", 2_000_000, 3),
+				column("id", 50_000_000, 0),
+				column("region", 300_000, 0),
+				column("product_id", 5_000_000, 2),
+			},
+			ExpectedSeverity: check.SeverityWarn,
+			ExpectedColumns:  []string{"customer_id", "product_id"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+
+			queryer := newMockQueryer(nil)
+			queryer.statementsAvailable = true
+			queryer.columns = tc.Columns
+			queryer.statements = statements
+
+			report, err := tableseqscans.New(queryer).Check(context.Background())
+			require.NoError(t, err)
+
+			f := checktest.Finding(t, report, "low-statistics-target")
+			require.Equal(t, tc.ExpectedSeverity, f.Severity)
+			if len(tc.ExpectedColumns) == 0 {
+				require.Nil(t, f.Table)
+				return
+			}
+
+			require.NotNil(t, f.Table)
+			var got []string
+			for _, row := range f.Table.Rows {
+				got = append(got, row.Cells[1])
+			}
+			require.Equal(t, tc.ExpectedColumns, got)
+
+			require.Len(t, f.Fixes, len(tc.ExpectedColumns)+1)
+			require.Equal(t, `ALTER TABLE "public"."orders" ALTER COLUMN "customer_id" SET STATISTICS 1000`, f.Fixes[0].SQL)
+			require.Equal(t, check.LockOnline, f.Fixes[0].Lock)
+			analyze := f.Fixes[len(f.Fixes)-1]
+			require.Equal(t, `ANALYZE "public"."orders" (`+`"`+strings.Join(tc.ExpectedColumns, `", "`)+`")`, analyze.SQL)
+		})
+	}
+}
+
+func Test_TableSeqScans_LowStatisticsTargetWithoutStatements(t *testing.T) {
+	t.Parallel()
+
+	report, err := tableseqscans.New(newMockQueryer(nil)).Check(context.Background())
+	require.NoError(t, err)
+
+	f := checktest.Finding(t, report, "low-statistics-target")
+	require.Equal(t, check.SeverityOK, f.Severity)
+	require.Contains(t, f.Details, "pg_stat_statements")
+}
+
+func Test_TableSeqScans_ScopedToSchema(t *testing.T) {
+	t.Parallel()
+
+	rows := []db.HighSeqScanTablesRow{
+		{
+			TableName:      pgtype.Text{String: "tenant_42.bookings", Valid: true},
+			SeqScan:        pgtype.Int8{Int64: 10000, Valid: true},
+			IdxScan:        pgtype.Int8{Int64: 100, Valid: true},
+			SeqToIdxRatio:  makeNumeric(100.0),
+			EstimatedRows:  pgtype.Int8{Int64: 75000, Valid: true},
+			TableSizeBytes: pgtype.Int8{Int64: 78643200, Valid: true},
+			IndexCount:     pgtype.Int8{Int64: 3, Valid: true},
+		},
+	}
+
+	queryer := newMockQueryer(rows)
+	queryer.statementsAvailable = true
+	ctx := check.ContextWithObjectFilter(context.Background(), (*check.ObjectFilter)(nil).ScopedToSchema("tenant_42"))
+
+	report, err := tableseqscans.New(queryer).Check(ctx)
+	require.NoError(t, err)
+
+	require.Equal(t, "tenant_42", queryer.tableSchema)
+	require.Equal(t, "tenant_42", queryer.columnSchema, "the column query is filtered before its LIMIT")
+	var highSeqResult *check.Finding
+	for _, result := range report.Results {
+		if result.ID == highSeqScansID {
+			highSeqResult = &result
+			break
+		}
+	}
+	require.NotNil(t, highSeqResult)
+	require.Equal(t, check.SeverityFail, highSeqResult.Severity)
+	require.Contains(t, highSeqResult.Details, "bookings")
+}
@@ -27,8 +27,66 @@ LEFT JOIN pg_stat_user_tables AS s ON c.oid = s.relid
 LEFT JOIN table_indexes AS ti ON c.oid = ti.table_oid
 WHERE
   c.relkind IN ('r', 'p')
-  AND n.nspname = 'public'
+  AND n.nspname = sqlc.arg(schema_name)::text
   AND coalesce(s.n_live_tup, 0) > 10000
   AND coalesce(s.seq_scan, 0) > 100
 ORDER BY
   coalesce(s.seq_scan, 0) DESC;
+
+-- name: SeqScanStatStatementsAvailable :one
+-- Checks if the pg_stat_statements extension is installed in this database.
+SELECT EXISTS(
+  SELECT 1 FROM pg_extension
+  WHERE extname = 'pg_stat_statements'
+) AS available;
+
+-- name: LowResolutionColumns :many
+-- Columns of large tables that use the default statistics target but have
+-- many distinct values, with the size of their MCV list and histogram.
+-- attstattarget is -1 for the default before PostgreSQL 17 and NULL after.
+SELECT
+  n.nspname::text AS schema_name
+  , c.relname::text AS table_name
+  , a.attname::text AS column_name
+  , c.reltuples::bigint AS estimated_rows
+  , (CASE
+    WHEN s.n_distinct < 0 THEN -s.n_distinct * c.reltuples
+    ELSE s.n_distinct
+  END)::bigint AS distinct_values
+  , COALESCE(CARDINALITY(s.most_common_vals), 0)::integer AS mcv_count
+  , COALESCE(CARDINALITY(s.histogram_bounds), 0)::integer AS histogram_bounds
+FROM pg_class AS c
+INNER JOIN pg_namespace AS n ON c.relnamespace = n.oid
+INNER JOIN pg_attribute AS a ON c.oid = a.attrelid
+INNER JOIN pg_stats AS s
+  ON
+    n.nspname = s.schemaname
+    AND c.relname = s.tablename
+    AND a.attname = s.attname
+    AND NOT s.inherited
+WHERE
+  c.relkind = 'r'
+  AND n.nspname NOT IN ('pg_catalog', 'information_schema', 'pg_toast')
+  -- An empty schema_name covers every schema.
+  AND (sqlc.arg(schema_name)::text = '' OR n.nspname = sqlc.arg(schema_name)::text)
+  AND c.reltuples >= 1000000
+  AND a.attnum > 0
+  AND NOT a.attisdropped
+  AND COALESCE(a.attstattarget, -1) < 0
+  AND (CASE WHEN s.n_distinct < 0 THEN -s.n_distinct * c.reltuples ELSE s.n_distinct END) >= 10000
+ORDER BY c.reltuples DESC, n.nspname, c.relname, a.attnum
+LIMIT 500;
+
+-- name: PredicateStatements :many
+-- Frequently run statements with a WHERE clause, lowercased with whitespace
+-- collapsed so column references can be matched in Go.
+SELECT
+  LOWER(LEFT(REGEXP_REPLACE(query, '\s+', ' ', 'g'), 4000))::text AS query
+  , calls::bigint AS calls
+FROM pg_stat_statements
+WHERE
+  calls >= 100
+  AND query ~* '\mwhere\M'
+  AND query !~* '^\s*(COPY|SET|BEGIN|COMMIT|ROLLBACK|SAVEPOINT|PREPARE|DEALLOCATE|VACUUM|ANALYZE|CREATE|DROP|ALTER)'
+ORDER BY calls DESC
+LIMIT 1000;
@@ -0,0 +1,421 @@
+// Package tablespaceplacement implements checks for relations placed on the
+// wrong storage tier.
+package tablespaceplacement
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/fresha/pgdoctor/check"
+	"github.com/fresha/pgdoctor/db"
+	"github.com/fresha/pgdoctor/internal/pgident"
+)
+
+//go:embed query.sql
+var querySQL string
+
+//go:embed README.md
+var readme string
+
+const (
+	// A relation on slow storage is hot when it reads this many blocks
+	// from storage (missing shared_buffers) or writes this many rows per
+	// second; ten times either is FAIL.
+	defaultHotReadsPerSecond  = 50.0
+	defaultHotWritesPerSecond = 10.0
+	hotFailMultiplier         = 10.0
+
+	// A relation on fast storage is cold when it is at least this large
+	// and has had almost no scans or writes over at least a day of statistics.
+	coldMinBytes          = int64(1024 * 1024 * 1024)
+	coldMaxScansPerHour   = 1.0
+	coldMaxWritesPerHour  = 1.0
+	coldMinStatsAgeSecond = 86400.0
+
+	maxTableRows = 20
+)
+
+type TablespacePlacementQueries interface {
+	TablespaceRelations(context.Context) ([]db.TablespaceRelationsRow, error)
+}
+
+type checker struct {
+	queries TablespacePlacementQueries
+	slow    []string
+	fast    []string
+
+	hotReads  float64
+	hotWrites float64
+}
+
+func Metadata() check.Metadata {
+	return check.Metadata{
+		Category:       check.CategoryPerformance,
+		CheckID:        "tablespace-placement",
+		Name:           "Tablespace Placement",
+		Description:    "Flags hot relations on slow tablespaces and large idle relations on fast ones, per the storage tiers set in config",
+		Readme:         readme,
+		SQL:            querySQL,
+		RuntimeClass:   check.RuntimeMedium,
+		ProductionSafe: true,
+		Findings: []check.FindingSpec{
+			{ID: "tablespace-layout", Description: "Relations, size, and activity per tablespace, with the configured tier"},
+			{ID: "hot-on-slow", Description: "Tables and indexes on a slow_tablespaces tablespace with heavy storage reads or writes", Thresholds: "WARN >= 50 blocks read/s or >= 10 rows written/s, FAIL at 10x"},
+			{ID: "cold-on-fast", Description: "Tables and indexes of 1GB or more on a fast_tablespaces tablespace with almost no scans or writes", Thresholds: "WARN < 1 scan/h and < 1 write/h over >= 1 day of statistics"},
+		},
+	}
+}
+
+// New creates the check. Storage tiers come from the slow_tablespaces and
+// fast_tablespaces config keys (comma-separated names); without them only
+// the layout is reported. hot_reads_per_second and hot_writes_per_second
+// change the WARN thresholds for hot-on-slow.
+func New(queries TablespacePlacementQueries, cfg ...check.Config) check.Checker {
+	c := &checker{
+		queries:   queries,
+		hotReads:  defaultHotReadsPerSecond,
+		hotWrites: defaultHotWritesPerSecond,
+	}
+	if len(cfg) > 0 {
+		id := Metadata().CheckID
+		c.slow = splitNames(cfg[0].String(id, "slow_tablespaces", ""))
+		c.fast = splitNames(cfg[0].String(id, "fast_tablespaces", ""))
+		c.hotReads = cfg[0].Float(id, "hot_reads_per_second", defaultHotReadsPerSecond)
+		c.hotWrites = cfg[0].Float(id, "hot_writes_per_second", defaultHotWritesPerSecond)
+	}
+	return c
+}
+
+func splitNames(s string) []string {
+	var names []string
+	for _, name := range strings.Split(s, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+func (c *checker) Metadata() check.Metadata {
+	return Metadata()
+}
+
+func (c *checker) Check(ctx context.Context) (*check.Report, error) {
+	report := check.NewReport(Metadata())
+	ignore := check.ObjectFilterFromContext(ctx)
+
+	rows, err := c.queries.TablespaceRelations(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("running %s/%s: %w", report.Category, report.CheckID, err)
+	}
+	rows = slices.DeleteFunc(rows, func(row db.TablespaceRelationsRow) bool {
+		if row.RelationKind == "index" {
+			return ignore.Index(row.SchemaName, "", row.Relname)
+		}
+		return ignore.Table(row.SchemaName, row.Relname)
+	})
+
+	c.checkLayout(rows, report)
+
+	if len(c.slow) == 0 && len(c.fast) == 0 {
+		for _, id := range []string{"hot-on-slow", "cold-on-fast"} {
+			report.AddFinding(check.Finding{
+				ID:       id,
+				Name:     findingName(id),
+				Severity: check.SeverityOK,
+				Details:  "No storage tiers configured; set slow_tablespaces and fast_tablespaces for this check to compare placement with activity",
+			})
+		}
+		return report, nil
+	}
+
+	c.checkHotOnSlow(rows, report)
+	c.checkColdOnFast(rows, report)
+
+	return report, nil
+}
+
+func findingName(id string) string {
+	switch id {
+	case "tablespace-layout":
+		return "Tablespace Layout"
+	case "hot-on-slow":
+		return "Hot Relations on Slow Storage"
+	case "cold-on-fast":
+		return "Cold Relations on Fast Storage"
+	default:
+		return id
+	}
+}
+
+func (c *checker) tier(tablespace string) string {
+	switch {
+	case slices.Contains(c.slow, tablespace):
+		return "slow"
+	case slices.Contains(c.fast, tablespace):
+		return "fast"
+	default:
+		return "unclassified"
+	}
+}
+
+func perSecond(n int64, row db.TablespaceRelationsRow) float64 {
+	if row.StatsAgeSeconds <= 0 {
+		return 0
+	}
+	return float64(n) / row.StatsAgeSeconds
+}
+
+func (c *checker) checkLayout(rows []db.TablespaceRelationsRow, report *check.Report) {
+	type usage struct {
+		name               string
+		relations          int
+		size               int64
+		blocksRead, writes int64
+	}
+	byTablespace := map[string]*usage{}
+	var order []string
+	var totalReads, totalWrites int64
+	for _, row := range rows {
+		u, ok := byTablespace[row.TablespaceName]
+		if !ok {
+			u = &usage{name: row.TablespaceName}
+			byTablespace[row.TablespaceName] = u
+			order = append(order, row.TablespaceName)
+		}
+		u.relations++
+		u.size += row.SizeBytes
+		u.blocksRead += row.BlocksRead
+		u.writes += row.Writes
+		totalReads += row.BlocksRead
+		totalWrites += row.Writes
+	}
+	sort.Strings(order)
+
+	share := func(part, whole int64) string {
+		if whole == 0 {
+			return "-"
+		}
+		return fmt.Sprintf("%.0f%%", 100*float64(part)/float64(whole))
+	}
+
+	var tableRows []check.TableRow
+	for _, name := range order {
+		u := byTablespace[name]
+		tableRows = append(tableRows, check.TableRow{
+			Cells: []string{
+				u.name,
+				c.tier(u.name),
+				fmt.Sprintf("%d", u.relations),
+				check.FormatBytes(u.size),
+				share(u.blocksRead, totalReads),
+				share(u.writes, totalWrites),
+			},
+			Severity: check.SeverityOK,
+		})
+	}
+
+	finding := check.Finding{
+		ID:       "tablespace-layout",
+		Name:     "Tablespace Layout",
+		Severity: check.SeverityOK,
+		Details:  fmt.Sprintf("%d relation(s) across %d tablespace(s)", len(rows), len(order)),
+	}
+	if len(tableRows) > 0 {
+		finding.Table = &check.Table{
+			Headers: []string{"Tablespace", "Tier", "Relations", "Size", "Storage Reads", "Writes"},
+			Rows:    tableRows,
+		}
+	}
+	if unknown := c.unknownTablespaces(order); len(unknown) > 0 {
+		finding.Details += fmt.Sprintf(". Configured tablespace(s) holding no relations in this database: %s", strings.Join(unknown, ", "))
+	}
+	report.AddFinding(finding)
+}
+
+// unknownTablespaces returns configured names that hold no relations, which
+// usually means a typo in the config.
+func (c *checker) unknownTablespaces(present []string) []string {
+	var unknown []string
+	for _, name := range append(slices.Clone(c.slow), c.fast...) {
+		if !slices.Contains(present, name) {
+			unknown = append(unknown, name)
+		}
+	}
+	return unknown
+}
+
+var placementHeaders = []string{"Relation", "Kind", "Tablespace", "Size", "Storage Reads/s", "Writes/s"}
+
+func placementRow(row db.TablespaceRelationsRow, severity check.Severity) check.TableRow {
+	return check.TableRow{
+		Cells: []string{
+			row.RelationName,
+			row.RelationKind,
+			row.TablespaceName,
+			check.FormatBytes(row.SizeBytes),
+			fmt.Sprintf("%.1f", perSecond(row.BlocksRead, row)),
+			fmt.Sprintf("%.1f", perSecond(row.Writes, row)),
+		},
+		Severity: severity,
+	}
+}
+
+func (c *checker) checkHotOnSlow(rows []db.TablespaceRelationsRow, report *check.Report) {
+	if len(c.slow) == 0 {
+		report.AddFinding(check.Finding{
+			ID:       "hot-on-slow",
+			Name:     "Hot Relations on Slow Storage",
+			Severity: check.SeverityOK,
+			Details:  "No slow_tablespaces configured",
+		})
+		return
+	}
+
+	var hot []db.TablespaceRelationsRow
+	var tableRows []check.TableRow
+	severity := check.SeverityOK
+	for _, row := range rows {
+		if c.tier(row.TablespaceName) != "slow" {
+			continue
+		}
+		reads, writes := perSecond(row.BlocksRead, row), perSecond(row.Writes, row)
+		rowSeverity := check.SeverityOK
+		switch {
+		case reads >= c.hotReads*hotFailMultiplier || writes >= c.hotWrites*hotFailMultiplier:
+			rowSeverity = check.SeverityFail
+		case reads >= c.hotReads || writes >= c.hotWrites:
+			rowSeverity = check.SeverityWarn
+		}
+		if rowSeverity == check.SeverityOK {
+			continue
+		}
+		severity = max(severity, rowSeverity)
+		hot = append(hot, row)
+		if len(tableRows) < maxTableRows {
+			tableRows = append(tableRows, placementRow(row, rowSeverity))
+		}
+	}
+
+	if len(hot) == 0 {
+		report.AddFinding(check.Finding{
+			ID:       "hot-on-slow",
+			Name:     "Hot Relations on Slow Storage",
+			Severity: check.SeverityOK,
+			Details: fmt.Sprintf("No relation on %s reads %.0f blocks/s from storage or writes %.0f rows/s",
+				strings.Join(c.slow, ", "), c.hotReads, c.hotWrites),
+		})
+		return
+	}
+
+	details := fmt.Sprintf("%d relation(s) on slow storage (%s) read at least %.0f blocks/s from storage or write at least %.0f rows/s; "+
+		"their queries wait on the slower tier and checkpoints take longer to flush them. Move them to fast storage",
+		len(hot), strings.Join(c.slow, ", "), c.hotReads, c.hotWrites)
+	if len(c.fast) == 0 {
+		details += " (set fast_tablespaces to get the statements)"
+	}
+
+	report.AddFinding(check.Finding{
+		ID:       "hot-on-slow",
+		Name:     "Hot Relations on Slow Storage",
+		Severity: severity,
+		Details:  details,
+		Table:    &check.Table{Headers: placementHeaders, Rows: tableRows},
+		Fixes:    moveFixes(hot, c.fast),
+	})
+}
+
+func (c *checker) checkColdOnFast(rows []db.TablespaceRelationsRow, report *check.Report) {
+	if len(c.fast) == 0 {
+		report.AddFinding(check.Finding{
+			ID:       "cold-on-fast",
+			Name:     "Cold Relations on Fast Storage",
+			Severity: check.SeverityOK,
+			Details:  "No fast_tablespaces configured",
+		})
+		return
+	}
+	if len(rows) > 0 && rows[0].StatsAgeSeconds < coldMinStatsAgeSecond {
+		report.AddFinding(check.Finding{
+			ID:       "cold-on-fast",
+			Name:     "Cold Relations on Fast Storage",
+			Severity: check.SeverityOK,
+			Details:  "Statistics were reset less than a day ago, too recently to tell idle relations apart",
+		})
+		return
+	}
+
+	var cold []db.TablespaceRelationsRow
+	var tableRows []check.TableRow
+	var coldBytes int64
+	for _, row := range rows {
+		if c.tier(row.TablespaceName) != "fast" || row.SizeBytes < coldMinBytes {
+			continue
+		}
+		if perSecond(row.Scans, row)*3600 >= coldMaxScansPerHour || perSecond(row.Writes, row)*3600 >= coldMaxWritesPerHour {
+			continue
+		}
+		cold = append(cold, row)
+		coldBytes += row.SizeBytes
+		if len(tableRows) < maxTableRows {
+			tableRows = append(tableRows, placementRow(row, check.SeverityWarn))
+		}
+	}
+
+	if len(cold) == 0 {
+		report.AddFinding(check.Finding{
+			ID:       "cold-on-fast",
+			Name:     "Cold Relations on Fast Storage",
+			Severity: check.SeverityOK,
+			Details:  fmt.Sprintf("No relation of %s or more on %s is idle", check.FormatBytes(coldMinBytes), strings.Join(c.fast, ", ")),
+		})
+		return
+	}
+
+	details := fmt.Sprintf("%d relation(s) on fast storage (%s) hold %s but had fewer than %.0f scan and %.0f write per hour since the statistics reset. "+
+		"Moving them to slow storage frees the fast tier for the working set; confirm they are not read by periodic jobs first",
+		len(cold), strings.Join(c.fast, ", "), check.FormatBytes(coldBytes), coldMaxScansPerHour, coldMaxWritesPerHour)
+	if len(c.slow) == 0 {
+		details += " (set slow_tablespaces to get the statements)"
+	}
+
+	report.AddFinding(check.Finding{
+		ID:         "cold-on-fast",
+		Name:       "Cold Relations on Fast Storage",
+		Severity:   check.SeverityWarn,
+		Details:    details,
+		Table:      &check.Table{Headers: placementHeaders, Rows: tableRows},
+		Confidence: check.ConfidenceMedium,
+		Fixes:      moveFixes(cold, c.slow),
+	})
+}
+
+// moveFixes moves relations to the first tablespace of the target tier.
+// SET TABLESPACE copies the relation under ACCESS EXCLUSIVE, so these are
+// maintenance-window fixes; pg_repack --tablespace moves a table online.
+func moveFixes(rows []db.TablespaceRelationsRow, targets []string) []check.Fix {
+	if len(targets) == 0 {
+		return nil
+	}
+	target := targets[0]
+	fixes := make([]check.Fix, 0, len(rows))
+	for _, row := range rows {
+		keyword := "TABLE"
+		if row.RelationKind == "index" {
+			keyword = "INDEX"
+		}
+		fixes = append(fixes, check.Fix{
+			Object: row.RelationName,
+			Description: fmt.Sprintf("Move %s (%s) from %s to %s; copies it while blocking all reads and writes (pg_repack --tablespace moves tables online)",
+				row.RelationName, check.FormatBytes(row.SizeBytes), row.TablespaceName, target),
+			SQL:  fmt.Sprintf("ALTER %s %s SET TABLESPACE %s", keyword, pgident.Quote(row.SchemaName, row.Relname), pgident.Quote(target)),
+			Risk: check.RiskHigh,
+			Lock: check.LockExclusive,
+		})
+	}
+	return fixes
+}
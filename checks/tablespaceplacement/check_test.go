@@ -0,0 +1,166 @@
+package tablespaceplacement_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/fresha/pgdoctor/check"
+	"github.com/fresha/pgdoctor/check/checktest"
+	"github.com/fresha/pgdoctor/checks/tablespaceplacement"
+	"github.com/fresha/pgdoctor/db"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockQueryer struct {
+	rows []db.TablespaceRelationsRow
+	err  error
+}
+
+func (m *mockQueryer) TablespaceRelations(context.Context) ([]db.TablespaceRelationsRow, error) {
+	return m.rows, m.err
+}
+
+const (
+	gb  = int64(1024 * 1024 * 1024)
+	day = 86400.0
+)
+
+func relation(name, kind, tablespace string, size, scans, blocksRead, writes int64) db.TablespaceRelationsRow {
+	schema, relname, _ := strings.Cut(name, ".")
+	return db.TablespaceRelationsRow{
+		RelationName:    name,
+		SchemaName:      schema,
+		Relname:         relname,
+		RelationKind:    kind,
+		TablespaceName:  tablespace,
+		SizeBytes:       size,
+		Scans:           scans,
+		BlocksRead:      blocksRead,
+		Writes:          writes,
+		StatsAgeSeconds: day,
+	}
+}
+
+var tiers = check.Config{"tablespace-placement": {"slow_tablespaces": "archive, hdd", "fast_tablespaces": "nvme"}}
+
+func TestTablespacePlacement_NoTiersConfigured(t *testing.T) {
+	t.Parallel()
+
+	q := &mockQueryer{rows: []db.TablespaceRelationsRow{
+		relation("public.events", "table", "pg_default", 10*gb, 1000, 100*int64(day), 0),
+	}}
+
+	report, err := tablespaceplacement.New(q).Check(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, check.SeverityOK, report.Severity)
+	layout := checktest.Finding(t, report, "tablespace-layout")
+	require.NotNil(t, layout.Table)
+	assert.Equal(t, []string{"pg_default", "unclassified", "1", "10.0GiB", "100%", "-"}, layout.Table.Rows[0].Cells)
+	assert.Contains(t, checktest.Finding(t, report, "hot-on-slow").Details, "No storage tiers configured")
+	assert.Contains(t, checktest.Finding(t, report, "cold-on-fast").Details, "No storage tiers configured")
+}
+
+func TestTablespacePlacement_HotOnSlow(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		row      db.TablespaceRelationsRow
+		severity check.Severity
+	}{
+		{name: "quiet", row: relation("public.audit", "table", "archive", gb, 10, 10*int64(day), int64(day)), severity: check.SeverityOK},
+		{name: "reads from storage", row: relation("public.audit", "table", "archive", gb, 10, 60*int64(day), 0), severity: check.SeverityWarn},
+		{name: "write heavy", row: relation("public.audit", "table", "hdd", gb, 10, 0, 20*int64(day)), severity: check.SeverityWarn},
+		{name: "very hot", row: relation("public.audit", "table", "archive", gb, 10, 600*int64(day), 0), severity: check.SeverityFail},
+		{name: "hot on unclassified", row: relation("public.audit", "table", "pg_default", gb, 10, 600*int64(day), 0), severity: check.SeverityOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			report, err := tablespaceplacement.New(&mockQueryer{rows: []db.TablespaceRelationsRow{tt.row}}, tiers).Check(context.Background())
+			require.NoError(t, err)
+
+			finding := checktest.Finding(t, report, "hot-on-slow")
+			assert.Equal(t, tt.severity, finding.Severity)
+			if tt.severity == check.SeverityOK {
+				assert.Nil(t, finding.Fixes)
+				return
+			}
+			require.Len(t, finding.Fixes, 1)
+			assert.Equal(t, `ALTER TABLE "public"."audit" SET TABLESPACE "nvme"`, finding.Fixes[0].SQL)
+			assert.Equal(t, check.RiskHigh, finding.Fixes[0].Risk)
+			assert.True(t, finding.Fixes[0].NeedsMaintenanceWindow())
+		})
+	}
+}
+
+func TestTablespacePlacement_ColdOnFast(t *testing.T) {
+	t.Parallel()
+
+	q := &mockQueryer{rows: []db.TablespaceRelationsRow{
+		relation("public.events_2019", "table", "nvme", 40*gb, 2, 0, 0),
+		relation("public.events_2019_pkey", "index", "nvme", 2*gb, 0, 0, 0),
+		relation("public.events", "table", "nvme", 40*gb, 50000, 0, 10000),
+		relation("public.small", "table", "nvme", gb/2, 0, 0, 0),
+	}}
+
+	report, err := tablespaceplacement.New(q, tiers).Check(context.Background())
+	require.NoError(t, err)
+
+	cold := checktest.Finding(t, report, "cold-on-fast")
+	assert.Equal(t, check.SeverityWarn, cold.Severity)
+	assert.Equal(t, check.ConfidenceMedium, cold.Confidence)
+	require.Len(t, cold.Table.Rows, 2)
+	assert.Contains(t, cold.Details, "hold 42.0GiB")
+	require.Len(t, cold.Fixes, 2)
+	assert.Equal(t, `ALTER TABLE "public"."events_2019" SET TABLESPACE "archive"`, cold.Fixes[0].SQL)
+	assert.Equal(t, `ALTER INDEX "public"."events_2019_pkey" SET TABLESPACE "archive"`, cold.Fixes[1].SQL)
+
+	layout := checktest.Finding(t, report, "tablespace-layout")
+	assert.Contains(t, layout.Details, "Configured tablespace(s) holding no relations in this database: archive, hdd")
+}
+
+func TestTablespacePlacement_ColdNeedsADayOfStatistics(t *testing.T) {
+	t.Parallel()
+
+	row := relation("public.events_2019", "table", "nvme", 40*gb, 0, 0, 0)
+	row.StatsAgeSeconds = 3600
+	report, err := tablespaceplacement.New(&mockQueryer{rows: []db.TablespaceRelationsRow{row}}, tiers).Check(context.Background())
+	require.NoError(t, err)
+
+	cold := checktest.Finding(t, report, "cold-on-fast")
+	assert.Equal(t, check.SeverityOK, cold.Severity)
+	assert.Contains(t, cold.Details, "less than a day ago")
+}
+
+func TestTablespacePlacement_NoFixWithoutTargetTier(t *testing.T) {
+	t.Parallel()
+
+	cfg := check.Config{"tablespace-placement": {"slow_tablespaces": "archive"}}
+	q := &mockQueryer{rows: []db.TablespaceRelationsRow{
+		relation("public.orders", "table", "archive", gb, 10, 0, 100*int64(day)),
+	}}
+
+	report, err := tablespaceplacement.New(q, cfg).Check(context.Background())
+	require.NoError(t, err)
+
+	hot := checktest.Finding(t, report, "hot-on-slow")
+	assert.Equal(t, check.SeverityFail, hot.Severity)
+	assert.Empty(t, hot.Fixes)
+	assert.Contains(t, hot.Details, "set fast_tablespaces")
+	assert.Contains(t, checktest.Finding(t, report, "cold-on-fast").Details, "No fast_tablespaces configured")
+}
+
+func TestTablespacePlacement_QueryError(t *testing.T) {
+	t.Parallel()
+
+	_, err := tablespaceplacement.New(&mockQueryer{err: errors.New("boom")}).Check(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "tablespace-placement")
+}
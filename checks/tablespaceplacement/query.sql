@@ -0,0 +1,58 @@
+-- name: TablespaceRelations :many
+-- User tables and indexes with their tablespace, size, and activity since the
+-- statistics reset. Relations in the database's default tablespace report its name.
+-- Returns data for subchecks: tablespace-layout, hot-on-slow, cold-on-fast.
+WITH stats_window AS (
+  SELECT
+    EXTRACT(EPOCH FROM NOW() - COALESCE(sd.stats_reset, PG_POSTMASTER_START_TIME()))::float8 AS age_seconds
+  FROM pg_stat_database AS sd
+  WHERE sd.datname = CURRENT_DATABASE()
+)
+
+, default_tablespace AS (
+  SELECT d.dattablespace AS oid
+  FROM pg_database AS d
+  WHERE d.datname = CURRENT_DATABASE()
+)
+
+, relations AS (
+  SELECT
+    st.relid AS oid
+    , 'table' AS relation_kind
+    , st.seq_scan + COALESCE(st.idx_scan, 0) AS scans
+    , COALESCE(sio.heap_blks_read, 0) + COALESCE(sio.toast_blks_read, 0) AS blocks_read
+    , st.n_tup_ins + st.n_tup_upd + st.n_tup_del AS writes
+  FROM pg_stat_user_tables AS st
+  LEFT JOIN pg_statio_user_tables AS sio ON st.relid = sio.relid
+  UNION ALL
+  -- Inserts and non-HOT updates add an entry to every index on the table.
+  SELECT
+    si.indexrelid AS oid
+    , 'index' AS relation_kind
+    , si.idx_scan AS scans
+    , COALESCE(sio.idx_blks_read, 0) AS blocks_read
+    , COALESCE(st.n_tup_ins + st.n_tup_upd - st.n_tup_hot_upd, 0) AS writes
+  FROM pg_stat_user_indexes AS si
+  LEFT JOIN pg_statio_user_indexes AS sio ON si.indexrelid = sio.indexrelid
+  LEFT JOIN pg_stat_user_tables AS st ON si.relid = st.relid
+)
+
+SELECT
+  (n.nspname || '.' || c.relname)::text AS relation_name
+  , n.nspname::text AS schema_name
+  , c.relname::text AS relname
+  , r.relation_kind::text AS relation_kind
+  , ts.spcname::text AS tablespace_name
+  , PG_RELATION_SIZE(c.oid)::bigint AS size_bytes
+  , r.scans::bigint AS scans
+  , r.blocks_read::bigint AS blocks_read
+  , r.writes::bigint AS writes
+  , COALESCE(sw.age_seconds, 0)::float8 AS stats_age_seconds
+FROM relations AS r
+INNER JOIN pg_class AS c ON r.oid = c.oid
+INNER JOIN pg_namespace AS n ON c.relnamespace = n.oid
+CROSS JOIN default_tablespace AS dt
+INNER JOIN pg_tablespace AS ts ON COALESCE(NULLIF(c.reltablespace, 0), dt.oid) = ts.oid
+LEFT JOIN stats_window AS sw ON TRUE
+WHERE c.relkind IN ('r', 'm', 'i')
+ORDER BY size_bytes DESC;
@@ -2,14 +2,18 @@
 package tablevacuumhealth
 
 import (
+	"cmp"
 	"context"
 	_ "embed"
 	"fmt"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/fresha/pgdoctor/check"
 	"github.com/fresha/pgdoctor/db"
+	"github.com/fresha/pgdoctor/internal/accesspattern"
+	"github.com/fresha/pgdoctor/internal/pgident"
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
@@ -20,7 +24,9 @@ var querySQL string
 var readme string
 
 type TableVacuumHealthQueries interface {
-	TableVacuumHealth(context.Context) ([]db.TableVacuumHealthRow, error)
+	TableVacuumHealthEach(context.Context, string, func(db.TableVacuumHealthRow) error) error
+	TableVacuumServerVersion(context.Context) (int32, error)
+	TableVacuumTiming(context.Context, string) ([]db.TableVacuumTimingRow, error)
 }
 
 type checker struct {
@@ -42,16 +48,38 @@ const (
 	// Analyze needed thresholds (modifications since last analyze).
 	analyzeNeededWarn = 100_000 // Warning at 100K modifications
 	analyzeNeededFail = 500_000 // Fail at 500K modifications
+
+	// Average autovacuum run time. Dead tuples pile up and freezing falls
+	// behind while one long run holds the table's only vacuum slot.
+	slowAutovacuumWarnMs = 30 * 60 * 1000     // 30 minutes
+	slowAutovacuumFailMs = 3 * 60 * 60 * 1000 // 3 hours
+
+	// Per-table cost limit suggested for slow tables, 5x vacuum_cost_limit's
+	// default, which autovacuum uses unless told otherwise.
+	defaultCostLimit   = 200
+	suggestedCostLimit = 1000
+
+	// pg_stat_user_tables gained cumulative vacuum and analyze times in 18.
+	vacuumTimingMinVersion = 180000
 )
 
 func Metadata() check.Metadata {
 	return check.Metadata{
-		Category:    check.CategoryVacuum,
-		CheckID:     "table-vacuum-health",
-		Name:        "Table Vacuum Health",
-		Description: "Monitors per-table autovacuum configuration and activity",
-		Readme:      readme,
-		SQL:         querySQL,
+		Category:       check.CategoryVacuum,
+		CheckID:        "table-vacuum-health",
+		Name:           "Table Vacuum Health",
+		Description:    "Monitors per-table autovacuum configuration and activity",
+		Readme:         readme,
+		SQL:            querySQL,
+		RuntimeClass:   check.RuntimeMedium,
+		ProductionSafe: true,
+		Findings: []check.FindingSpec{
+			{ID: "autovacuum-disabled", Description: "Tables with autovacuum_enabled=false", Thresholds: "WARN"},
+			{ID: "large-table-defaults", Description: "Large tables relying on default autovacuum scale factors", Thresholds: "WARN > 1M rows, FAIL > 10M rows"},
+			{ID: "vacuum-stale", Description: "Tables not vacuumed or analyzed recently (minimum 1,000 rows)", Thresholds: "WARN 7+ days, FAIL 25+ days"},
+			{ID: "analyze-needed", Description: "Tables with many modifications since the last ANALYZE", Thresholds: "WARN 100K+, FAIL 500K+ modifications"},
+			{ID: "vacuum-duration", Description: "Tables whose autovacuum runs take longest on average (PostgreSQL 18+)", Thresholds: "WARN >= 30m, FAIL >= 3h per run"},
+		},
 	}
 }
 
@@ -68,25 +96,70 @@ func (c *checker) Metadata() check.Metadata {
 func (c *checker) Check(ctx context.Context) (*check.Report, error) {
 	report := check.NewReport(Metadata())
 
-	rows, err := c.queries.TableVacuumHealth(ctx)
+	// The query returns every table, so rows are streamed and only those a
+	// finding reports are kept.
+	now := time.Now()
+	ignore := check.ObjectFilterFromContext(ctx)
+	var rows []db.TableVacuumHealthRow
+	err := c.queries.TableVacuumHealthEach(ctx, check.SchemaFromContext(ctx, "public"), func(row db.TableVacuumHealthRow) error {
+		if ignore.Table(row.SchemaName.String, row.Relname.String) {
+			return nil
+		}
+		if hasAutovacuumDisabled(row.Reloptions.String) || isLargeTableDefault(row) || isVacuumStale(row, now) || needsAnalyze(row) {
+			rows = append(rows, row)
+		}
+		return nil
+	})
 	if err != nil {
 		return nil, fmt.Errorf("running %s/%s: %w", check.CategoryVacuum, report.CheckID, err)
 	}
 
 	checkAutovacuumDisabled(rows, report)
 	checkLargeTableDefaults(rows, report)
-	checkVacuumStale(rows, report)
+	checkVacuumStale(rows, now, report)
 	checkAnalyzeNeeded(rows, report)
 
+	timing, supported, err := c.fetchVacuumTiming(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("running %s/%s (timing): %w", check.CategoryVacuum, report.CheckID, err)
+	}
+	timing = slices.DeleteFunc(timing, func(row db.TableVacuumTimingRow) bool {
+		return ignore.Table(row.SchemaName, row.Relname)
+	})
+	checkVacuumDuration(timing, supported, report)
+
 	return report, nil
 }
 
+// Reads per-table vacuum timing on PG18+; older versions do not record it
+// and report supported as false.
+func (c *checker) fetchVacuumTiming(ctx context.Context) (rows []db.TableVacuumTimingRow, supported bool, err error) {
+	version, err := c.queries.TableVacuumServerVersion(ctx)
+	if err != nil || version < vacuumTimingMinVersion {
+		return nil, false, err
+	}
+	rows, err = c.queries.TableVacuumTiming(ctx, check.SchemaFromContext(ctx, "public"))
+	return rows, true, err
+}
+
 func checkAutovacuumDisabled(rows []db.TableVacuumHealthRow, report *check.Report) {
 	var tableNames []string
+	var fixes []check.Fix
 	for _, row := range rows {
-		if hasAutovacuumDisabled(row.Reloptions.String) {
-			tableNames = append(tableNames, row.TableName.String)
+		if !hasAutovacuumDisabled(row.Reloptions.String) {
+			continue
 		}
+		tableNames = append(tableNames, row.TableName.String)
+		// Re-enabling autovacuum can start a long vacuum straight away, and
+		// the setting is often deliberate, so these fixes are never applied
+		// by pgdoctor fix.
+		fixes = append(fixes, check.Fix{
+			Object:      row.TableName.String,
+			Description: fmt.Sprintf("Re-enable autovacuum on %s", row.TableName.String),
+			SQL:         "ALTER TABLE " + pgident.Quote(row.SchemaName.String, row.Relname.String) + " RESET (autovacuum_enabled)",
+			Risk:        check.RiskHigh,
+			Lock:        check.LockOnline,
+		})
 	}
 
 	if len(tableNames) == 0 {
@@ -104,13 +177,14 @@ func checkAutovacuumDisabled(rows []db.TableVacuumHealthRow, report *check.Repor
 		Name:     "Autovacuum Disabled Tables",
 		Severity: check.SeverityWarn,
 		Details:  fmt.Sprintf("Found %d table(s) with autovacuum disabled: %s", len(tableNames), strings.Join(tableNames, ", ")),
+		Fixes:    fixes,
 	})
 }
 
 func checkLargeTableDefaults(rows []db.TableVacuumHealthRow, report *check.Report) {
 	var tablesUsingDefaults []db.TableVacuumHealthRow
 	for _, row := range rows {
-		if row.EstimatedRows.Int64 >= largeTableMinRows && isUsingDefaultSettings(row.Reloptions.String) {
+		if isLargeTableDefault(row) {
 			tablesUsingDefaults = append(tablesUsingDefaults, row)
 		}
 	}
@@ -126,7 +200,11 @@ func checkLargeTableDefaults(rows []db.TableVacuumHealthRow, report *check.Repor
 	}
 
 	var tableRows []check.TableRow
+	var fixes []check.Fix
 	for _, row := range tablesUsingDefaults {
+		pattern := writePattern(row)
+		fixes = append(fixes, largeTableFix(row, pattern))
+
 		severity := check.SeverityWarn
 		if row.EstimatedRows.Int64 >= veryLargeTableMin {
 			severity = check.SeverityFail
@@ -141,6 +219,7 @@ func checkLargeTableDefaults(rows []db.TableVacuumHealthRow, report *check.Repor
 				formatRowCount(row.EstimatedRows.Int64),
 				check.FormatBytes(row.TableSizeBytes.Int64),
 				formatRowCount(pendingWork),
+				string(pattern),
 				formatTimestamp(row.LastAutovacuum),
 				fmt.Sprintf("%d", row.AutovacuumCount.Int64),
 			},
@@ -154,29 +233,45 @@ func checkLargeTableDefaults(rows []db.TableVacuumHealthRow, report *check.Repor
 		Severity: check.SeverityWarn,
 		Details:  fmt.Sprintf("Found %d large table(s) using default autovacuum settings", len(tablesUsingDefaults)),
 		Table: &check.Table{
-			Headers: []string{"Table", "Rows", "Size", "Pending Work", "Last Autovacuum", "Vacuum Count"},
+			Headers: []string{"Table", "Rows", "Size", "Pending Work", "Write Pattern", "Last Autovacuum", "Vacuum Count"},
 			Rows:    tableRows,
 		},
+		Fixes: fixes,
 	})
 }
 
-func checkVacuumStale(rows []db.TableVacuumHealthRow, report *check.Report) {
-	now := time.Now()
-	warnThreshold := now.Add(-time.Duration(staleVacuumWarnDays) * 24 * time.Hour)
+// largeTableFix lowers the scale factor that triggers vacuum on a large
+// table. Append-only tables produce few dead tuples, so they are vacuumed
+// after inserts instead (PG13+), which keeps the visibility map current
+// and freezes rows in small batches rather than one anti-wraparound pass.
+func largeTableFix(row db.TableVacuumHealthRow, pattern accesspattern.Pattern) check.Fix {
+	table := pgident.Quote(row.SchemaName.String, row.Relname.String)
+	if pattern == accesspattern.AppendOnly {
+		return check.Fix{
+			Object: row.TableName.String,
+			Description: fmt.Sprintf("Vacuum append-only %s after 1%% of its %s rows are inserted instead of 20%%",
+				row.TableName.String, formatRowCount(row.EstimatedRows.Int64)),
+			SQL:  "ALTER TABLE " + table + " SET (autovacuum_vacuum_insert_scale_factor = 0.01, autovacuum_vacuum_insert_threshold = 1000)",
+			Risk: check.RiskLow,
+			Lock: check.LockOnline,
+		}
+	}
+	return check.Fix{
+		Object: row.TableName.String,
+		Description: fmt.Sprintf("Vacuum %s after 1%% of its %s rows change instead of 20%%",
+			row.TableName.String, formatRowCount(row.EstimatedRows.Int64)),
+		SQL:  "ALTER TABLE " + table + " SET (autovacuum_vacuum_scale_factor = 0.01, autovacuum_vacuum_threshold = 1000)",
+		Risk: check.RiskLow,
+		Lock: check.LockOnline,
+	}
+}
+
+func checkVacuumStale(rows []db.TableVacuumHealthRow, now time.Time, report *check.Report) {
 	failThreshold := now.Add(-time.Duration(staleVacuumFailDays) * 24 * time.Hour)
 
 	var staleTables []db.TableVacuumHealthRow
 	for _, row := range rows {
-		// Skip tiny tables to avoid noise.
-		if row.EstimatedRows.Int64 < staleCheckMinRows {
-			continue
-		}
-
-		lastVacuum := getTimestamp(row.LastVacuumAny)
-		lastAnalyze := getTimestamp(row.LastAnalyzeAny)
-
-		// Consider stale if either vacuum or analyze is old.
-		if lastVacuum.Before(warnThreshold) || lastAnalyze.Before(warnThreshold) {
+		if isVacuumStale(row, now) {
 			staleTables = append(staleTables, row)
 		}
 	}
@@ -223,11 +318,21 @@ func checkVacuumStale(rows []db.TableVacuumHealthRow, report *check.Report) {
 		})
 	}
 
+	busiest := slices.MaxFunc(staleTables, func(a, b db.TableVacuumHealthRow) int {
+		return cmp.Compare(a.NDeadTup.Int64+a.NInsSinceVacuum.Int64, b.NDeadTup.Int64+b.NInsSinceVacuum.Int64)
+	})
+	vacuumed := "never vacuumed"
+	if last := getTimestamp(busiest.LastVacuumAny); !last.IsZero() {
+		vacuumed = "last vacuumed " + formatTimeSince(last)
+	}
+
 	report.AddFinding(check.Finding{
 		ID:       "vacuum-stale",
 		Name:     "Stale Vacuum Activity",
 		Severity: check.SeverityWarn,
 		Details:  fmt.Sprintf("Found %d table(s) with stale vacuum or analyze activity", len(tableRows)),
+		Impact: fmt.Sprintf("Autovacuum is not keeping up on %s (%s rows of pending work, %s), so it bloats and its query plans drift",
+			busiest.TableName.String, formatRowCount(busiest.NDeadTup.Int64+busiest.NInsSinceVacuum.Int64), vacuumed),
 		Table: &check.Table{
 			Headers: []string{"Table", "Rows", "Size", "Pending Work", "Last Vacuum", "Last Analyze"},
 			Rows:    tableRows,
@@ -236,19 +341,14 @@ func checkVacuumStale(rows []db.TableVacuumHealthRow, report *check.Report) {
 }
 
 func checkAnalyzeNeeded(rows []db.TableVacuumHealthRow, report *check.Report) {
-	var needsAnalyze []db.TableVacuumHealthRow
+	var stale []db.TableVacuumHealthRow
 	for _, row := range rows {
-		// Skip tiny tables to avoid noise.
-		if row.EstimatedRows.Int64 < staleCheckMinRows {
-			continue
-		}
-
-		if row.NModSinceAnalyze.Int64 >= analyzeNeededWarn {
-			needsAnalyze = append(needsAnalyze, row)
+		if needsAnalyze(row) {
+			stale = append(stale, row)
 		}
 	}
 
-	if len(needsAnalyze) == 0 {
+	if len(stale) == 0 {
 		report.AddFinding(check.Finding{
 			ID:       "analyze-needed",
 			Name:     "Table Statistics Staleness",
@@ -259,7 +359,7 @@ func checkAnalyzeNeeded(rows []db.TableVacuumHealthRow, report *check.Report) {
 	}
 
 	var tableRows []check.TableRow
-	for _, row := range needsAnalyze {
+	for _, row := range stale {
 		severity := check.SeverityWarn
 		if row.NModSinceAnalyze.Int64 >= analyzeNeededFail {
 			severity = check.SeverityFail
@@ -281,7 +381,7 @@ func checkAnalyzeNeeded(rows []db.TableVacuumHealthRow, report *check.Report) {
 		ID:       "analyze-needed",
 		Name:     "Table Statistics Staleness",
 		Severity: check.SeverityWarn,
-		Details:  fmt.Sprintf("Found %d table(s) with stale statistics (many modifications since last ANALYZE)", len(needsAnalyze)),
+		Details:  fmt.Sprintf("Found %d table(s) with stale statistics (many modifications since last ANALYZE)", len(stale)),
 		Table: &check.Table{
 			Headers: []string{"Table", "Rows", "Mods Since Analyze", "Analyze Count", "Last Analyze"},
 			Rows:    tableRows,
@@ -291,15 +391,123 @@ func checkAnalyzeNeeded(rows []db.TableVacuumHealthRow, report *check.Report) {
 
 // Helper functions.
 
+func checkVacuumDuration(rows []db.TableVacuumTimingRow, supported bool, report *check.Report) {
+	if !supported {
+		report.AddFinding(check.Finding{
+			ID:       "vacuum-duration",
+			Name:     "Autovacuum Duration",
+			Severity: check.SeverityOK,
+			Details:  "Per-table vacuum timing needs PostgreSQL 18+",
+		})
+		return
+	}
+
+	severity := check.SeverityOK
+	var tableRows []check.TableRow
+	var fixes []check.Fix
+	for _, row := range rows {
+		avgMs := row.TotalAutovacuumTimeMs / float64(row.AutovacuumCount)
+		rowSeverity := check.SeverityOK
+		switch {
+		case avgMs >= slowAutovacuumFailMs:
+			rowSeverity = check.SeverityFail
+		case avgMs >= slowAutovacuumWarnMs:
+			rowSeverity = check.SeverityWarn
+		default:
+			continue
+		}
+		severity = max(severity, rowSeverity)
+
+		avgAnalyze := "-"
+		if row.AutoanalyzeCount > 0 {
+			avgAnalyze = check.FormatDurationMs(row.TotalAutoanalyzeTimeMs / float64(row.AutoanalyzeCount))
+		}
+		tableRows = append(tableRows, check.TableRow{
+			Cells: []string{
+				row.TableName,
+				check.FormatBytes(row.TableSizeBytes),
+				fmt.Sprintf("%d", row.AutovacuumCount),
+				check.FormatDurationMs(avgMs),
+				avgAnalyze,
+				formatTimestamp(row.LastAutovacuum),
+			},
+			Severity: rowSeverity,
+		})
+		fixes = append(fixes, check.Fix{
+			Object: row.TableName,
+			Description: fmt.Sprintf("Let autovacuum on %s do %dx the default I/O per cost cycle, shortening its %s runs",
+				row.TableName, suggestedCostLimit/defaultCostLimit, check.FormatDurationMs(avgMs)),
+			SQL:  fmt.Sprintf("ALTER TABLE %s SET (autovacuum_vacuum_cost_limit = %d)", pgident.Quote(row.SchemaName, row.Relname), suggestedCostLimit),
+			Risk: check.RiskLow,
+			Lock: check.LockOnline,
+		})
+	}
+
+	if len(tableRows) == 0 {
+		report.AddFinding(check.Finding{
+			ID:       "vacuum-duration",
+			Name:     "Autovacuum Duration",
+			Severity: check.SeverityOK,
+			Details:  "No table's autovacuum runs average 30 minutes or more",
+		})
+		return
+	}
+
+	report.AddFinding(check.Finding{
+		ID:       "vacuum-duration",
+		Name:     "Autovacuum Duration",
+		Severity: severity,
+		Details: fmt.Sprintf("Found %d table(s) whose autovacuum runs average 30 minutes or more. "+
+			"While a run lasts, new dead tuples wait for the next one and freezing falls behind; "+
+			"usually the cost limit throttles it, or the table has outgrown a single vacuum and should be partitioned", len(tableRows)),
+		Table: &check.Table{
+			Headers: []string{"Table", "Size", "Autovacuums", "Avg Vacuum", "Avg Analyze", "Last Autovacuum"},
+			Rows:    tableRows,
+		},
+		Fixes: fixes,
+	})
+}
+
 func hasAutovacuumDisabled(reloptions string) bool {
 	return strings.Contains(strings.ToLower(reloptions), "autovacuum_enabled=false")
 }
 
+func isLargeTableDefault(row db.TableVacuumHealthRow) bool {
+	return row.EstimatedRows.Int64 >= largeTableMinRows && isUsingDefaultSettings(row.Reloptions.String)
+}
+
+// isVacuumStale reports tables, other than tiny ones, whose last vacuum or
+// analyze is older than the WARN threshold.
+func isVacuumStale(row db.TableVacuumHealthRow, now time.Time) bool {
+	if row.EstimatedRows.Int64 < staleCheckMinRows {
+		return false
+	}
+	warnThreshold := now.Add(-time.Duration(staleVacuumWarnDays) * 24 * time.Hour)
+	return getTimestamp(row.LastVacuumAny).Before(warnThreshold) || getTimestamp(row.LastAnalyzeAny).Before(warnThreshold)
+}
+
+// needsAnalyze reports tables, other than tiny ones, with enough
+// modifications since the last ANALYZE to WARN.
+func needsAnalyze(row db.TableVacuumHealthRow) bool {
+	return row.EstimatedRows.Int64 >= staleCheckMinRows && row.NModSinceAnalyze.Int64 >= analyzeNeededWarn
+}
+
 func isUsingDefaultSettings(reloptions string) bool {
 	if reloptions == "" {
 		return true
 	}
-	return !strings.Contains(strings.ToLower(reloptions), "autovacuum_vacuum_scale_factor")
+	lower := strings.ToLower(reloptions)
+	return !strings.Contains(lower, "autovacuum_vacuum_scale_factor") &&
+		!strings.Contains(lower, "autovacuum_vacuum_insert_scale_factor")
+}
+
+func writePattern(row db.TableVacuumHealthRow) accesspattern.Pattern {
+	return accesspattern.Classify(accesspattern.Counters{
+		Inserts:  row.NTupIns.Int64,
+		Updates:  row.NTupUpd.Int64,
+		Deletes:  row.NTupDel.Int64,
+		LiveRows: row.EstimatedRows.Int64,
+	})
 }
 
 func formatRowCount(count int64) string {
@@ -3,6 +3,7 @@ package tablevacuumhealth_test
 import (
 	"context"
 	"fmt"
+	"strings"
 	"testing"
 	"time"
 
@@ -19,18 +20,41 @@ const (
 	findingIDLargeTableDefaults = "large-table-defaults"
 	findingIDVacuumStale        = "vacuum-stale"
 	findingIDAnalyzeNeeded      = "analyze-needed"
+	findingIDVacuumDuration     = "vacuum-duration"
 )
 
 type mockQueryer struct {
-	rows []db.TableVacuumHealthRow
-	err  error
+	rows    []db.TableVacuumHealthRow
+	err     error
+	version int32
+	timing  []db.TableVacuumTimingRow
+
+	timingCalled bool
+	// schemas records the schema each query was asked for.
+	schemas []string
 }
 
-func (m *mockQueryer) TableVacuumHealth(context.Context) ([]db.TableVacuumHealthRow, error) {
+func (m *mockQueryer) TableVacuumServerVersion(context.Context) (int32, error) {
+	return m.version, nil
+}
+
+func (m *mockQueryer) TableVacuumTiming(_ context.Context, schema string) ([]db.TableVacuumTimingRow, error) {
+	m.schemas = append(m.schemas, schema)
+	m.timingCalled = true
+	return m.timing, nil
+}
+
+func (m *mockQueryer) TableVacuumHealthEach(_ context.Context, schema string, fn func(db.TableVacuumHealthRow) error) error {
+	m.schemas = append(m.schemas, schema)
 	if m.err != nil {
-		return nil, m.err
+		return m.err
 	}
-	return m.rows, nil
+	for _, row := range m.rows {
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 type rowBuilder struct {
@@ -38,9 +62,12 @@ type rowBuilder struct {
 }
 
 func makeRow(tableName string) *rowBuilder {
+	schema, table, _ := strings.Cut(tableName, ".")
 	return &rowBuilder{
 		row: db.TableVacuumHealthRow{
 			TableName:        pgtype.Text{String: tableName, Valid: true},
+			SchemaName:       pgtype.Text{String: schema, Valid: true},
+			Relname:          pgtype.Text{String: table, Valid: true},
 			EstimatedRows:    pgtype.Int8{Int64: 0, Valid: true},
 			TableSizeBytes:   pgtype.Int8{Int64: 0, Valid: true},
 			NDeadTup:         pgtype.Int8{Int64: 0, Valid: true},
@@ -108,6 +135,13 @@ func (b *rowBuilder) withInsSinceVacuum(inserts int64) *rowBuilder {
 	return b
 }
 
+func (b *rowBuilder) withWrites(inserts, updates, deletes int64) *rowBuilder {
+	b.row.NTupIns = pgtype.Int8{Int64: inserts, Valid: true}
+	b.row.NTupUpd = pgtype.Int8{Int64: updates, Valid: true}
+	b.row.NTupDel = pgtype.Int8{Int64: deletes, Valid: true}
+	return b
+}
+
 func (b *rowBuilder) build() db.TableVacuumHealthRow {
 	return b.row
 }
@@ -133,7 +167,7 @@ func TestTableVacuumHealth_AllHealthy(t *testing.T) {
 
 	require.NoError(t, err)
 	assert.Equal(t, check.SeverityOK, report.Severity)
-	assert.Len(t, report.Results, 4) // 4 subchecks now
+	assert.Len(t, report.Results, 5) // 5 subchecks now
 
 	for _, finding := range report.Results {
 		assert.Equal(t, check.SeverityOK, finding.Severity)
@@ -209,6 +243,41 @@ func TestTableVacuumHealth_AutovacuumDisabled_Found(t *testing.T) {
 	assert.Equal(t, check.SeverityWarn, disabledFinding.Severity)
 	assert.Contains(t, disabledFinding.Details, "1 table(s)")
 	assert.Contains(t, disabledFinding.Details, "public.staging_table")
+	require.Len(t, disabledFinding.Fixes, 1)
+	assert.Equal(t, `ALTER TABLE "public"."staging_table" RESET (autovacuum_enabled)`, disabledFinding.Fixes[0].SQL)
+	assert.Equal(t, check.RiskHigh, disabledFinding.Fixes[0].Risk, "re-enabling autovacuum is never applied automatically")
+}
+
+func TestTableVacuumHealth_AutovacuumDisabled_QuotesIdentifiers(t *testing.T) {
+	t.Parallel()
+
+	recentTime := time.Now().Add(-1 * time.Hour)
+	queryer := &mockQueryer{
+		rows: []db.TableVacuumHealthRow{
+			makeRow(`Billing.Order "Items"`).
+				withRows(10000).
+				withSize(1024 * 1024).
+				withReloptions("autovacuum_enabled=false").
+				withLastVacuumAny(recentTime).
+				withLastAnalyzeAny(recentTime).
+				build(),
+		},
+	}
+
+	report, err := tablevacuumhealth.New(queryer).Check(context.Background())
+	require.NoError(t, err)
+
+	var disabledFinding *check.Finding
+	for i := range report.Results {
+		if report.Results[i].ID == findingIDAutovacuumDisabled {
+			disabledFinding = &report.Results[i]
+			break
+		}
+	}
+
+	require.NotNil(t, disabledFinding)
+	require.Len(t, disabledFinding.Fixes, 1)
+	assert.Equal(t, `ALTER TABLE "Billing"."Order ""Items""" RESET (autovacuum_enabled)`, disabledFinding.Fixes[0].SQL)
 }
 
 func TestTableVacuumHealth_LargeTableDefaults_NoTables(t *testing.T) {
@@ -317,6 +386,10 @@ func TestTableVacuumHealth_LargeTableDefaults_UsingDefaults_Warning(t *testing.T
 	assert.Contains(t, largeFinding.Details, "1 large table(s)")
 	assert.NotNil(t, largeFinding.Table)
 	assert.Equal(t, check.SeverityWarn, largeFinding.Table.Rows[0].Severity)
+	require.Len(t, largeFinding.Fixes, 1)
+	assert.Equal(t, `ALTER TABLE "public"."users" SET (autovacuum_vacuum_scale_factor = 0.01, autovacuum_vacuum_threshold = 1000)`,
+		largeFinding.Fixes[0].SQL)
+	assert.Equal(t, check.RiskLow, largeFinding.Fixes[0].Risk)
 }
 
 func TestTableVacuumHealth_LargeTableDefaults_VeryLarge_Fail(t *testing.T) {
@@ -393,6 +466,71 @@ func TestTableVacuumHealth_LargeTableDefaults_PendingWorkIncludesInserts(t *test
 	assert.Equal(t, "60.0K", largeFinding.Table.Rows[0].Cells[3])
 }
 
+func TestTableVacuumHealth_LargeTableDefaults_AppendOnlyUsesInsertScaleFactor(t *testing.T) {
+	t.Parallel()
+
+	recentTime := time.Now().Add(-1 * time.Hour)
+	queryer := &mockQueryer{
+		rows: []db.TableVacuumHealthRow{
+			makeRow("public.events").
+				withRows(2_000_000).
+				withWrites(2_000_000, 10_000, 0).
+				withLastVacuumAny(recentTime).
+				withLastAnalyzeAny(recentTime).
+				build(),
+			makeRow("public.orders").
+				withRows(2_000_000).
+				withWrites(2_000_000, 3_000_000, 0).
+				withLastVacuumAny(recentTime).
+				withLastAnalyzeAny(recentTime).
+				build(),
+		},
+	}
+
+	report, err := tablevacuumhealth.New(queryer).Check(context.Background())
+	require.NoError(t, err)
+
+	var largeFinding *check.Finding
+	for i := range report.Results {
+		if report.Results[i].ID == findingIDLargeTableDefaults {
+			largeFinding = &report.Results[i]
+			break
+		}
+	}
+
+	require.NotNil(t, largeFinding)
+	require.Len(t, largeFinding.Fixes, 2)
+	assert.Equal(t, "append-only", largeFinding.Table.Rows[0].Cells[4])
+	assert.Contains(t, largeFinding.Fixes[0].SQL, "autovacuum_vacuum_insert_scale_factor = 0.01")
+	assert.Equal(t, "update-heavy", largeFinding.Table.Rows[1].Cells[4])
+	assert.Contains(t, largeFinding.Fixes[1].SQL, "autovacuum_vacuum_scale_factor = 0.01")
+}
+
+func TestTableVacuumHealth_LargeTableDefaults_InsertScaleFactorCountsAsTuned(t *testing.T) {
+	t.Parallel()
+
+	recentTime := time.Now().Add(-1 * time.Hour)
+	queryer := &mockQueryer{
+		rows: []db.TableVacuumHealthRow{
+			makeRow("public.events").
+				withRows(2_000_000).
+				withReloptions("autovacuum_vacuum_insert_scale_factor=0.01").
+				withLastVacuumAny(recentTime).
+				withLastAnalyzeAny(recentTime).
+				build(),
+		},
+	}
+
+	report, err := tablevacuumhealth.New(queryer).Check(context.Background())
+	require.NoError(t, err)
+
+	for _, f := range report.Results {
+		if f.ID == findingIDLargeTableDefaults {
+			assert.Equal(t, check.SeverityOK, f.Severity)
+		}
+	}
+}
+
 func TestTableVacuumHealth_VacuumStale_AllFresh(t *testing.T) {
 	t.Parallel()
 
@@ -764,3 +902,128 @@ func TestTableVacuumHealth_Metadata(t *testing.T) {
 	assert.NotEmpty(t, metadata.SQL)
 	assert.NotEmpty(t, metadata.Readme)
 }
+
+func TestTableVacuumHealth_VacuumDuration(t *testing.T) {
+	t.Parallel()
+
+	minutes := func(m float64) float64 { return m * 60 * 1000 }
+	timingRow := func(table string, runs int64, avgMinutes float64) db.TableVacuumTimingRow {
+		schema, relname, _ := strings.Cut(table, ".")
+		return db.TableVacuumTimingRow{
+			TableName:             table,
+			SchemaName:            schema,
+			Relname:               relname,
+			TableSizeBytes:        500 << 30,
+			AutovacuumCount:       runs,
+			TotalAutovacuumTimeMs: minutes(avgMinutes) * float64(runs),
+		}
+	}
+
+	tests := []struct {
+		name     string
+		version  int32
+		timing   []db.TableVacuumTimingRow
+		severity check.Severity
+		details  string
+		tables   []string
+	}{
+		{
+			name:     "PG17 has no timing",
+			version:  170004,
+			severity: check.SeverityOK,
+			details:  "PostgreSQL 18+",
+		},
+		{
+			name:     "fast runs",
+			version:  180000,
+			timing:   []db.TableVacuumTimingRow{timingRow("public.orders", 40, 12)},
+			severity: check.SeverityOK,
+			details:  "No table",
+		},
+		{
+			name:    "slow and very slow runs",
+			version: 180001,
+			timing: []db.TableVacuumTimingRow{
+				timingRow("public.events", 3, 240),
+				timingRow("public.orders", 10, 45),
+				timingRow("public.users", 100, 2),
+			},
+			severity: check.SeverityFail,
+			details:  "Found 2 table(s)",
+			tables:   []string{"public.events", "public.orders"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			queryer := &mockQueryer{version: tt.version, timing: tt.timing}
+			report, err := tablevacuumhealth.New(queryer).Check(context.Background())
+			require.NoError(t, err)
+			assert.Equal(t, tt.version >= 180000, queryer.timingCalled)
+
+			var finding *check.Finding
+			for i := range report.Results {
+				if report.Results[i].ID == findingIDVacuumDuration {
+					finding = &report.Results[i]
+				}
+			}
+			require.NotNil(t, finding)
+			assert.Equal(t, tt.severity, finding.Severity)
+			assert.Contains(t, finding.Details, tt.details)
+			if tt.tables == nil {
+				assert.Nil(t, finding.Table)
+				return
+			}
+
+			require.Len(t, finding.Table.Rows, len(tt.tables))
+			require.Len(t, finding.Fixes, len(tt.tables))
+			for i, table := range tt.tables {
+				assert.Equal(t, table, finding.Table.Rows[i].Cells[0])
+				assert.Equal(t, table, finding.Fixes[i].Object)
+			}
+			assert.Equal(t, "4.0h", finding.Table.Rows[0].Cells[3])
+			assert.Equal(t, check.SeverityFail, finding.Table.Rows[0].Severity)
+			assert.Equal(t, check.SeverityWarn, finding.Table.Rows[1].Severity)
+			assert.Equal(t, `ALTER TABLE "public"."events" SET (autovacuum_vacuum_cost_limit = 1000)`, finding.Fixes[0].SQL)
+			assert.False(t, finding.Fixes[0].NeedsMaintenanceWindow())
+		})
+	}
+}
+
+func TestTableVacuumHealth_ScopedToSchema(t *testing.T) {
+	t.Parallel()
+
+	recentTime := time.Now().Add(-1 * time.Hour)
+	queryer := &mockQueryer{
+		rows: []db.TableVacuumHealthRow{
+			makeRow("tenant_42.bookings").
+				withRows(10000).
+				withSize(1024 * 1024).
+				withReloptions("autovacuum_enabled=false").
+				withLastVacuumAny(recentTime).
+				withLastAnalyzeAny(recentTime).
+				build(),
+		},
+	}
+	ctx := check.ContextWithObjectFilter(context.Background(), (*check.ObjectFilter)(nil).ScopedToSchema("tenant_42"))
+
+	report, err := tablevacuumhealth.New(queryer).Check(ctx)
+	require.NoError(t, err)
+
+	require.NotEmpty(t, queryer.schemas)
+	for _, schema := range queryer.schemas {
+		assert.Equal(t, "tenant_42", schema, "every query reads the scoped schema")
+	}
+	var disabledFinding *check.Finding
+	for i := range report.Results {
+		if report.Results[i].ID == findingIDAutovacuumDisabled {
+			disabledFinding = &report.Results[i]
+			break
+		}
+	}
+	require.NotNil(t, disabledFinding)
+	assert.Equal(t, check.SeverityWarn, disabledFinding.Severity)
+	assert.Contains(t, disabledFinding.Details, "tenant_42.bookings")
+}
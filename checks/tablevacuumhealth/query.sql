@@ -3,6 +3,8 @@
 -- Used by multiple subchecks: autovacuum-disabled, large-table-defaults, vacuum-stale, analyze-needed.
 SELECT
   (n.nspname || '.' || c.relname)::text AS table_name
+  , n.nspname::text AS schema_name
+  , c.relname::text AS relname
   , s.last_autovacuum
   , COALESCE(s.n_live_tup, c.reltuples::bigint) AS estimated_rows
   , PG_TOTAL_RELATION_SIZE(c.oid) AS table_size_bytes
@@ -16,10 +18,38 @@ SELECT
   , COALESCE(s.autoanalyze_count, 0) AS autoanalyze_count
   -- PG14+ columns for insert tracking (will be 0 on older versions via COALESCE)
   , COALESCE(s.n_ins_since_vacuum, 0) AS n_ins_since_vacuum
+  -- Write counters, for the table's write pattern
+  , COALESCE(s.n_tup_ins, 0) AS n_tup_ins
+  , COALESCE(s.n_tup_upd, 0) AS n_tup_upd
+  , COALESCE(s.n_tup_del, 0) AS n_tup_del
 FROM pg_class AS c
 INNER JOIN pg_namespace AS n ON c.relnamespace = n.oid
 LEFT JOIN pg_stat_user_tables AS s ON c.oid = s.relid
 WHERE
   c.relkind IN ('r', 'p')
-  AND n.nspname = 'public'
+  AND n.nspname = sqlc.arg(schema_name)::text
 ORDER BY COALESCE(s.n_live_tup, c.reltuples::bigint) DESC;
+
+-- name: TableVacuumServerVersion :one
+-- Server version, to decide whether pg_stat_user_tables has vacuum timing.
+SELECT CURRENT_SETTING('server_version_num')::integer AS server_version_num;
+
+-- name: TableVacuumTiming :many
+-- Tables whose autovacuum runs take longest on average, from the cumulative
+-- vacuum and analyze times added to pg_stat_user_tables in PostgreSQL 18.
+SELECT
+  (s.schemaname || '.' || s.relname)::text AS table_name
+  , s.schemaname::text AS schema_name
+  , s.relname::text AS relname
+  , PG_TOTAL_RELATION_SIZE(s.relid)::bigint AS table_size_bytes
+  , s.autovacuum_count::bigint AS autovacuum_count
+  , s.total_autovacuum_time::double precision AS total_autovacuum_time_ms
+  , s.autoanalyze_count::bigint AS autoanalyze_count
+  , s.total_autoanalyze_time::double precision AS total_autoanalyze_time_ms
+  , s.last_autovacuum
+FROM pg_stat_user_tables AS s
+WHERE
+  s.schemaname = sqlc.arg(schema_name)::text
+  AND s.autovacuum_count > 0
+ORDER BY s.total_autovacuum_time / s.autovacuum_count DESC
+LIMIT 20;
@@ -8,6 +8,7 @@ import (
 
 	"github.com/fresha/pgdoctor/check"
 	"github.com/fresha/pgdoctor/db"
+	"github.com/fresha/pgdoctor/internal/pgident"
 )
 
 //go:embed query.sql
@@ -16,9 +17,20 @@ var querySQL string
 //go:embed README.md
 var readme string
 
+const (
+	// Temp data written per hour by one database, matching temp-volume-rate.
+	databaseVolumeWarnBytes = float64(1 << 30)
+	databaseVolumeFailBytes = float64(5 << 30)
+
+	// Spills averaging at most this multiple of work_mem would mostly fit in
+	// memory with a moderately higher work_mem; larger ones need query fixes.
+	workMemFixableFactor = 4.0
+)
+
 // TempUsageQueries defines the database queries needed by this check.
 type TempUsageQueries interface {
 	TempUsage(context.Context) (db.TempUsageRow, error)
+	TempUsageByDatabase(context.Context) ([]db.TempUsageByDatabaseRow, error)
 }
 
 type checker struct {
@@ -27,12 +39,19 @@ type checker struct {
 
 func Metadata() check.Metadata {
 	return check.Metadata{
-		Category:    check.CategoryConfigs,
-		CheckID:     "temp-usage",
-		Name:        "Temporary File Usage",
-		Description: "Monitors temporary file creation indicating work_mem exhaustion",
-		Readme:      readme,
-		SQL:         querySQL,
+		Category:       check.CategoryConfigs,
+		CheckID:        "temp-usage",
+		Name:           "Temporary File Usage",
+		Description:    "Monitors temporary file creation indicating work_mem exhaustion",
+		Readme:         readme,
+		SQL:            querySQL,
+		RuntimeClass:   check.RuntimeFast,
+		ProductionSafe: true,
+		Findings: []check.FindingSpec{
+			{ID: "temp-file-rate", Description: "Temporary files created per hour since the last stats reset", Thresholds: "WARN > 5/h, FAIL > 20/h"},
+			{ID: "temp-volume-rate", Description: "Temporary file volume written per hour", Thresholds: "WARN > 1GB/h, FAIL > 5GB/h"},
+			{ID: "temp-spill-by-database", Description: "Temporary file volume per hour for every database, with average spill size against the work_mem that applies to it", Thresholds: "WARN > 1GB/h, FAIL > 5GB/h per database"},
+		},
 	}
 }
 
@@ -63,12 +82,17 @@ func (c *checker) Check(ctx context.Context) (*check.Report, error) {
 			Severity: check.SeverityOK,
 			Details:  fmt.Sprintf("Statistics reset too recently (%.0f minutes ago). Need at least 1 hour of data.", secondsSinceReset/60),
 		})
-		return report, nil
+	} else {
+		// Run all subchecks
+		checkTempFileRate(row, report)
+		checkTempVolumeRate(row, report)
 	}
 
-	// Run all subchecks
-	checkTempFileRate(row, report)
-	checkTempVolumeRate(row, report)
+	databases, err := c.queries.TempUsageByDatabase(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("running %s/%s (databases): %w", check.CategoryConfigs, report.CheckID, err)
+	}
+	checkTempSpillByDatabase(databases, report)
 
 	return report, nil
 }
@@ -178,3 +202,101 @@ func checkTempVolumeRate(row db.TempUsageRow, report *check.Report) {
 		),
 	})
 }
+
+// suggestedWorkMem rounds an average spill size up to a power of two in MB,
+// the usual granularity for work_mem.
+func suggestedWorkMem(avgSpillBytes int64) int64 {
+	mb := int64(1)
+	for mb<<20 < avgSpillBytes {
+		mb <<= 1
+	}
+	return mb
+}
+
+// checkTempSpillByDatabase breaks temp file volume down by database and
+// compares the average spill with work_mem, which tells a setting problem
+// (spills slightly over work_mem) from a query problem (spills far over it).
+func checkTempSpillByDatabase(rows []db.TempUsageByDatabaseRow, report *check.Report) {
+	severity := check.SeverityOK
+	var tableRows []check.TableRow
+	var fixes []check.Fix
+	for _, row := range rows {
+		// Need at least an hour of data for a meaningful rate.
+		if row.TempFiles == 0 || row.SecondsSinceReset < 3600 {
+			continue
+		}
+
+		hours := float64(row.SecondsSinceReset) / 3600
+		bytesPerHour := float64(row.TempBytes) / hours
+		avgSpill := row.TempBytes / row.TempFiles
+
+		rowSeverity := check.SeverityOK
+		if bytesPerHour >= databaseVolumeFailBytes {
+			rowSeverity = check.SeverityFail
+		} else if bytesPerHour >= databaseVolumeWarnBytes {
+			rowSeverity = check.SeverityWarn
+		}
+		severity = max(severity, rowSeverity)
+
+		workMem := check.FormatBytes(row.WorkMemBytes)
+		if row.WorkMemOverridden {
+			workMem += " (database)"
+		}
+
+		advice := "-"
+		if row.WorkMemBytes > 0 && rowSeverity > check.SeverityOK {
+			factor := float64(avgSpill) / float64(row.WorkMemBytes)
+			if factor <= workMemFixableFactor {
+				target := suggestedWorkMem(avgSpill)
+				advice = fmt.Sprintf("Spills average %.1fx work_mem; raise work_mem to %dMB for this database", factor, target)
+				fixes = append(fixes, check.Fix{
+					Object:      row.DatabaseName,
+					Description: fmt.Sprintf("Raise work_mem to %dMB for new sessions in %s. Each sort or hash node can use this much, so check memory headroom first", target, row.DatabaseName),
+					SQL:         fmt.Sprintf("ALTER DATABASE %s SET work_mem = '%dMB'", pgident.Quote(row.DatabaseName), target),
+					Lock:        check.LockOnline,
+				})
+			} else {
+				advice = fmt.Sprintf("Spills average %.0fx work_mem; find the queries writing them (pg_stat_statements temp_blks_written) rather than raising work_mem", factor)
+			}
+		}
+
+		tableRows = append(tableRows, check.TableRow{
+			Cells: []string{
+				row.DatabaseName,
+				fmt.Sprintf("%.1f", float64(row.TempFiles)/hours),
+				check.FormatBytes(int64(bytesPerHour)),
+				check.FormatBytes(avgSpill),
+				workMem,
+				advice,
+			},
+			Severity: rowSeverity,
+		})
+	}
+
+	if len(tableRows) == 0 {
+		report.AddFinding(check.Finding{
+			ID:       "temp-spill-by-database",
+			Name:     "Temp Spill by Database",
+			Severity: check.SeverityOK,
+			Details:  "No database has written temp files since its statistics reset",
+		})
+		return
+	}
+
+	details := fmt.Sprintf("%d database(s) wrote temp files since their statistics reset", len(tableRows))
+	if severity > check.SeverityOK {
+		details += ". Role-level work_mem overrides are not shown; a role running the spilling queries may already use a different value"
+	}
+
+	report.AddFinding(check.Finding{
+		ID:       "temp-spill-by-database",
+		Name:     "Temp Spill by Database",
+		Severity: severity,
+		Details:  details,
+		Table: &check.Table{
+			Headers: []string{"Database", "Files/Hour", "Data/Hour", "Avg File", "work_mem", "Advice"},
+			Rows:    tableRows,
+		},
+		Fixes: fixes,
+	})
+}
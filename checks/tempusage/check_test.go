@@ -15,14 +15,19 @@ import (
 )
 
 type mockQueryer struct {
-	row db.TempUsageRow
-	err error
+	row       db.TempUsageRow
+	databases []db.TempUsageByDatabaseRow
+	err       error
 }
 
 func (m *mockQueryer) TempUsage(ctx context.Context) (db.TempUsageRow, error) {
 	return m.row, m.err
 }
 
+func (m *mockQueryer) TempUsageByDatabase(context.Context) ([]db.TempUsageByDatabaseRow, error) {
+	return m.databases, nil
+}
+
 func makeTempUsageRow(
 	tempFiles, tempBytes int64,
 	secondsSinceReset float64,
@@ -83,7 +88,7 @@ func TestTempUsage_StatsResetTooRecent(t *testing.T) {
 
 			require.NoError(t, err)
 			assert.Equal(t, check.SeverityOK, report.Severity)
-			assert.Len(t, report.Results, 1)
+			assert.Len(t, report.Results, 2)
 			assert.Equal(t, "temp-usage", report.Results[0].ID)
 			assert.Contains(t, report.Results[0].Details, "Statistics reset too recently")
 			assert.Contains(t, report.Results[0].Details, "Need at least 1 hour of data")
@@ -114,7 +119,7 @@ func TestTempUsage_AllHealthy(t *testing.T) {
 
 	require.NoError(t, err)
 	assert.Equal(t, check.SeverityOK, report.Severity)
-	assert.Len(t, report.Results, 2)
+	assert.Len(t, report.Results, 3)
 
 	// Both subchecks should be OK
 	assert.Equal(t, "temp-file-rate", report.Results[0].ID)
@@ -271,7 +276,7 @@ func TestTempUsage_BothHighRates(t *testing.T) {
 
 	require.NoError(t, err)
 	assert.Equal(t, check.SeverityFail, report.Severity)
-	assert.Len(t, report.Results, 2)
+	assert.Len(t, report.Results, 3)
 
 	// Both should be FAIL
 	assert.Equal(t, check.SeverityFail, report.Results[0].Severity)
@@ -385,7 +390,7 @@ func TestTempUsage_InvalidNumeric(t *testing.T) {
 	require.NoError(t, err)
 	// Should treat invalid numerics as 0 and report stats too recent
 	assert.Equal(t, check.SeverityOK, report.Severity)
-	assert.Len(t, report.Results, 1)
+	assert.Len(t, report.Results, 2)
 	assert.Contains(t, report.Results[0].Details, "Statistics reset too recently")
 }
 
@@ -403,3 +408,97 @@ func TestTempUsage_Metadata(t *testing.T) {
 	assert.NotEmpty(t, metadata.Readme)
 	assert.NotEmpty(t, metadata.Description)
 }
+
+func TestTempUsage_SpillByDatabase(t *testing.T) {
+	t.Parallel()
+
+	const mb = int64(1 << 20)
+	day := int64(24 * 3600)
+
+	tests := []struct {
+		name          string
+		databases     []db.TempUsageByDatabaseRow
+		expected      check.Severity
+		expectedRows  int
+		expectedFixes []string
+		advice        string
+	}{
+		{
+			name:     "no spills",
+			expected: check.SeverityOK,
+			databases: []db.TempUsageByDatabaseRow{
+				{DatabaseName: "app", SecondsSinceReset: day, WorkMemBytes: 4 * mb},
+			},
+		},
+		{
+			name:     "stats too recent",
+			expected: check.SeverityOK,
+			databases: []db.TempUsageByDatabaseRow{
+				{DatabaseName: "app", TempFiles: 100, TempBytes: 100 << 30, SecondsSinceReset: 600, WorkMemBytes: 4 * mb},
+			},
+		},
+		{
+			name:         "light spills",
+			expected:     check.SeverityOK,
+			expectedRows: 1,
+			databases: []db.TempUsageByDatabaseRow{
+				{DatabaseName: "app", TempFiles: 240, TempBytes: 240 * 8 * mb, SecondsSinceReset: day, WorkMemBytes: 4 * mb},
+			},
+		},
+		{
+			name:          "spills just over work_mem",
+			expected:      check.SeverityWarn,
+			expectedRows:  1,
+			expectedFixes: []string{`ALTER DATABASE "app" SET work_mem = '16MB'`},
+			advice:        "raise work_mem to 16MB",
+			databases: []db.TempUsageByDatabaseRow{
+				{DatabaseName: "app", TempFiles: 24 * 100, TempBytes: 24 * 100 * 12 * mb, SecondsSinceReset: day, WorkMemBytes: 4 * mb},
+			},
+		},
+		{
+			name:         "spills far over work_mem",
+			expected:     check.SeverityFail,
+			expectedRows: 1,
+			advice:       "rather than raising work_mem",
+			databases: []db.TempUsageByDatabaseRow{
+				{DatabaseName: "reports", TempFiles: 24 * 10, TempBytes: 24 * 10 * 1024 * mb, SecondsSinceReset: day, WorkMemBytes: 64 * mb, WorkMemOverridden: true},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			q := &mockQueryer{
+				row:       makeTempUsageRow(0, 0, 7200, 0, 0, nil),
+				databases: tt.databases,
+			}
+			report, err := tempusage.New(q).Check(context.Background())
+			require.NoError(t, err)
+
+			var f check.Finding
+			for _, r := range report.Results {
+				if r.ID == "temp-spill-by-database" {
+					f = r
+				}
+			}
+			require.Equal(t, "temp-spill-by-database", f.ID)
+			assert.Equal(t, tt.expected, f.Severity)
+
+			if tt.expectedRows == 0 {
+				assert.Nil(t, f.Table)
+				return
+			}
+			require.NotNil(t, f.Table)
+			require.Len(t, f.Table.Rows, tt.expectedRows)
+			assert.Contains(t, f.Table.Rows[0].Cells[5], tt.advice)
+
+			var fixes []string
+			for _, fix := range f.Fixes {
+				fixes = append(fixes, fix.SQL)
+			}
+			assert.Equal(t, tt.expectedFixes, fixes)
+		})
+	}
+}
@@ -60,3 +60,30 @@ SELECT
   , ROUND(ts.temp_bytes_per_hour::numeric, 0) AS temp_bytes_per_hour
 FROM temp_stats AS ts
 CROSS JOIN memory_settings AS ms;
+
+-- name: TempUsageByDatabase :many
+-- Temp file counters for every database, with the work_mem that applies to
+-- it: an ALTER DATABASE ... SET work_mem override, or the server setting.
+-- Role-level overrides are not considered. Bare numbers in an override are
+-- in kB, like the setting itself.
+SELECT
+  d.datname::text AS database_name
+  , d.temp_files::bigint AS temp_files
+  , d.temp_bytes::bigint AS temp_bytes
+  , COALESCE(EXTRACT(EPOCH FROM (NOW() - d.stats_reset)), 0)::bigint AS seconds_since_reset
+  , COALESCE(o.work_mem_bytes, (SELECT setting::bigint * 1024 FROM pg_settings WHERE name = 'work_mem'))::bigint AS work_mem_bytes
+  , (o.work_mem_bytes IS NOT NULL) AS work_mem_overridden
+FROM pg_stat_database AS d
+LEFT JOIN LATERAL (
+  SELECT
+    CASE
+      WHEN SPLIT_PART(cfg, '=', 2) ~ '^\d+$' THEN SPLIT_PART(cfg, '=', 2)::bigint * 1024
+      ELSE PG_SIZE_BYTES(SPLIT_PART(cfg, '=', 2))
+    END AS work_mem_bytes
+  FROM pg_db_role_setting AS s
+  CROSS JOIN UNNEST(s.setconfig) AS cfg
+  WHERE s.setdatabase = d.datid AND s.setrole = 0 AND cfg LIKE 'work_mem=%'
+  LIMIT 1
+) AS o ON true
+WHERE d.datname IS NOT NULL
+ORDER BY d.temp_bytes DESC;
@@ -0,0 +1,236 @@
+// Package timezone implements checks for time zone and DateStyle consistency.
+package timezone
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/fresha/pgdoctor/check"
+	"github.com/fresha/pgdoctor/db"
+)
+
+//go:embed query.sql
+var querySQL string
+
+//go:embed README.md
+var readme string
+
+const defaultExpectedTimezone = "UTC"
+
+// utcAliases are the tz database names PostgreSQL accepts for UTC.
+var utcAliases = []string{"utc", "etc/utc", "uct", "etc/uct", "universal", "etc/universal", "zulu", "etc/zulu", "gmt", "etc/gmt", "gmt0", "etc/gmt0", "greenwich", "etc/greenwich"}
+
+type TimezoneQueries interface {
+	TimezoneSettings(context.Context) (db.TimezoneSettingsRow, error)
+	TimestampColumnCounts(context.Context) (db.TimestampColumnCountsRow, error)
+	NaiveTimestampColumns(context.Context) ([]db.NaiveTimestampColumnsRow, error)
+}
+
+type checker struct {
+	queries  TimezoneQueries
+	expected string
+}
+
+func Metadata() check.Metadata {
+	return check.Metadata{
+		Category:       check.CategoryConfigs,
+		CheckID:        "timezone",
+		Name:           "Time Zone Consistency",
+		Description:    "Checks that TimeZone, log_timezone, and DateStyle are consistent and timestamp column types are not mixed",
+		Readme:         readme,
+		SQL:            querySQL,
+		RuntimeClass:   check.RuntimeFast,
+		ProductionSafe: true,
+		Findings: []check.FindingSpec{
+			{ID: "server-timezone", Description: "Server TimeZone and per-database/role overrides match the expected zone (UTC unless expected_timezone is set)", Thresholds: "WARN on mismatch"},
+			{ID: "log-timezone", Description: "log_timezone matches TimeZone so log timestamps line up with query results", Thresholds: "WARN on mismatch"},
+			{ID: "datestyle", Description: "DateStyle output format is ISO", Thresholds: "WARN when not ISO"},
+			{ID: "mixed-timestamp-types", Description: "Schema mixes timestamp (without time zone) and timestamptz columns", Thresholds: "WARN when both are used"},
+		},
+	}
+}
+
+// New creates the check. The expected zone defaults to UTC and can be changed
+// with the expected_timezone config key.
+func New(queries TimezoneQueries, cfg ...check.Config) check.Checker {
+	c := &checker{
+		queries:  queries,
+		expected: defaultExpectedTimezone,
+	}
+	if len(cfg) > 0 {
+		c.expected = cfg[0].String(Metadata().CheckID, "expected_timezone", defaultExpectedTimezone)
+	}
+	return c
+}
+
+func (c *checker) Metadata() check.Metadata {
+	return Metadata()
+}
+
+func (c *checker) Check(ctx context.Context) (*check.Report, error) {
+	report := check.NewReport(Metadata())
+
+	settings, err := c.queries.TimezoneSettings(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("running %s/%s (settings): %w", check.CategoryConfigs, report.CheckID, err)
+	}
+
+	counts, err := c.queries.TimestampColumnCounts(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("running %s/%s (column counts): %w", check.CategoryConfigs, report.CheckID, err)
+	}
+
+	var naive []db.NaiveTimestampColumnsRow
+	if counts.NaiveColumns > 0 && counts.AwareColumns > 0 {
+		naive, err = c.queries.NaiveTimestampColumns(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("running %s/%s (columns): %w", check.CategoryConfigs, report.CheckID, err)
+		}
+	}
+
+	checkServerTimezone(settings, c.expected, report)
+	checkLogTimezone(settings, report)
+	checkDatestyle(settings, report)
+	checkMixedTimestampTypes(counts, naive, report)
+
+	return report, nil
+}
+
+func isUTC(zone string) bool {
+	return slices.Contains(utcAliases, strings.ToLower(strings.TrimSpace(zone)))
+}
+
+// sameZone compares zone names, treating every UTC alias as equal.
+func sameZone(a, b string) bool {
+	if isUTC(a) && isUTC(b) {
+		return true
+	}
+	return strings.EqualFold(strings.TrimSpace(a), strings.TrimSpace(b))
+}
+
+// mismatchedOverrides returns the "database/role=zone" overrides whose zone
+// differs from expected.
+func mismatchedOverrides(overrides, expected string) []string {
+	var mismatched []string
+	for _, o := range strings.Split(overrides, ", ") {
+		if o == "" {
+			continue
+		}
+		eq := strings.LastIndex(o, "=")
+		zone := strings.Trim(o[eq+1:], `'"`)
+		if !sameZone(zone, expected) {
+			mismatched = append(mismatched, o)
+		}
+	}
+	return mismatched
+}
+
+func checkServerTimezone(settings db.TimezoneSettingsRow, expected string, report *check.Report) {
+	var problems []string
+	if !sameZone(settings.Timezone, expected) {
+		problems = append(problems, fmt.Sprintf("TimeZone is %s, expected %s", settings.Timezone, expected))
+	}
+	if mismatched := mismatchedOverrides(settings.TimezoneOverrides, expected); len(mismatched) > 0 {
+		problems = append(problems, fmt.Sprintf("ALTER DATABASE/ROLE overrides set a different zone: %s", strings.Join(mismatched, ", ")))
+	}
+
+	if len(problems) == 0 {
+		report.AddFinding(check.Finding{
+			ID:       "server-timezone",
+			Name:     "Server Time Zone",
+			Severity: check.SeverityOK,
+			Details:  fmt.Sprintf("TimeZone is %s", settings.Timezone),
+		})
+		return
+	}
+
+	report.AddFinding(check.Finding{
+		ID:       "server-timezone",
+		Name:     "Server Time Zone",
+		Severity: check.SeverityWarn,
+		Details: strings.Join(problems, "; ") +
+			". Sessions that do not set TimeZone themselves render timestamptz and convert timestamp literals in this zone, so the same instant reads differently across tools during an incident",
+	})
+}
+
+func checkLogTimezone(settings db.TimezoneSettingsRow, report *check.Report) {
+	if sameZone(settings.LogTimezone, settings.Timezone) {
+		report.AddFinding(check.Finding{
+			ID:       "log-timezone",
+			Name:     "Log Time Zone",
+			Severity: check.SeverityOK,
+			Details:  fmt.Sprintf("log_timezone matches TimeZone (%s)", settings.LogTimezone),
+		})
+		return
+	}
+
+	report.AddFinding(check.Finding{
+		ID:       "log-timezone",
+		Name:     "Log Time Zone",
+		Severity: check.SeverityWarn,
+		Details: fmt.Sprintf("log_timezone is %s but TimeZone is %s: server log timestamps will not line up with now() and query results",
+			settings.LogTimezone, settings.Timezone),
+	})
+}
+
+func checkDatestyle(settings db.TimezoneSettingsRow, report *check.Report) {
+	// DateStyle is "<output format>, <field order>", e.g. "ISO, MDY".
+	format, _, _ := strings.Cut(settings.Datestyle, ",")
+	if strings.EqualFold(strings.TrimSpace(format), "ISO") {
+		report.AddFinding(check.Finding{
+			ID:       "datestyle",
+			Name:     "DateStyle",
+			Severity: check.SeverityOK,
+			Details:  fmt.Sprintf("DateStyle is %s", settings.Datestyle),
+		})
+		return
+	}
+
+	report.AddFinding(check.Finding{
+		ID:       "datestyle",
+		Name:     "DateStyle",
+		Severity: check.SeverityWarn,
+		Details: fmt.Sprintf("DateStyle is %s: dates render in a non-ISO format (and ambiguous input like 01/02/2024 is read in that order), which breaks clients and scripts that expect ISO 8601",
+			settings.Datestyle),
+	})
+}
+
+func checkMixedTimestampTypes(counts db.TimestampColumnCountsRow, naive []db.NaiveTimestampColumnsRow, report *check.Report) {
+	if counts.NaiveColumns == 0 || counts.AwareColumns == 0 {
+		details := fmt.Sprintf("All %d timestamp column(s) use timestamptz", counts.AwareColumns)
+		if counts.NaiveColumns > 0 {
+			details = fmt.Sprintf("All %d timestamp column(s) use timestamp without time zone; values are only meaningful if every writer uses the same zone",
+				counts.NaiveColumns)
+		}
+		report.AddFinding(check.Finding{
+			ID:       "mixed-timestamp-types",
+			Name:     "Mixed Timestamp Types",
+			Severity: check.SeverityOK,
+			Details:  details,
+		})
+		return
+	}
+
+	var tableRows []check.TableRow
+	for _, col := range naive {
+		tableRows = append(tableRows, check.TableRow{
+			Cells:    []string{col.TableName, col.ColumnName},
+			Severity: check.SeverityWarn,
+		})
+	}
+
+	report.AddFinding(check.Finding{
+		ID:       "mixed-timestamp-types",
+		Name:     "Mixed Timestamp Types",
+		Severity: check.SeverityWarn,
+		Details: fmt.Sprintf("%d timestamp (without time zone) column(s) alongside %d timestamptz column(s). Comparing or joining them converts through the session TimeZone, so results change with the client's zone",
+			counts.NaiveColumns, counts.AwareColumns),
+		Table: &check.Table{
+			Headers: []string{"Table", "Column"},
+			Rows:    tableRows,
+		},
+	})
+}
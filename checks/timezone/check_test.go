@@ -0,0 +1,224 @@
+package timezone_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/fresha/pgdoctor/check"
+	"github.com/fresha/pgdoctor/check/checktest"
+	"github.com/fresha/pgdoctor/checks/timezone"
+	"github.com/fresha/pgdoctor/db"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockQueryer struct {
+	settings db.TimezoneSettingsRow
+	counts   db.TimestampColumnCountsRow
+	naive    []db.NaiveTimestampColumnsRow
+	err      error
+
+	naiveCalled bool
+}
+
+func (m *mockQueryer) TimezoneSettings(context.Context) (db.TimezoneSettingsRow, error) {
+	return m.settings, m.err
+}
+
+func (m *mockQueryer) TimestampColumnCounts(context.Context) (db.TimestampColumnCountsRow, error) {
+	return m.counts, nil
+}
+
+func (m *mockQueryer) NaiveTimestampColumns(context.Context) ([]db.NaiveTimestampColumnsRow, error) {
+	m.naiveCalled = true
+	return m.naive, nil
+}
+
+func healthyQueryer() *mockQueryer {
+	return &mockQueryer{
+		settings: db.TimezoneSettingsRow{Timezone: "UTC", LogTimezone: "Etc/UTC", Datestyle: "ISO, MDY"},
+		counts:   db.TimestampColumnCountsRow{AwareColumns: 42},
+	}
+}
+
+func TestTimezone_Healthy(t *testing.T) {
+	t.Parallel()
+
+	q := healthyQueryer()
+	report, err := timezone.New(q).Check(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, check.SeverityOK, report.Severity)
+	assert.Len(t, report.Results, 4)
+	assert.False(t, q.naiveCalled, "naive columns are only listed when types are mixed")
+}
+
+func TestTimezone_ServerTimezone(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		timezone  string
+		overrides string
+		cfg       []check.Config
+		expected  check.Severity
+		contains  string
+	}{
+		{name: "utc", timezone: "UTC", expected: check.SeverityOK},
+		{name: "utc alias", timezone: "Etc/UTC", expected: check.SeverityOK},
+		{name: "local zone", timezone: "Europe/Berlin", expected: check.SeverityWarn, contains: "expected UTC"},
+		{name: "matching override", timezone: "UTC", overrides: "app/(all)=UTC", expected: check.SeverityOK},
+		{name: "mismatched override", timezone: "UTC", overrides: "app/(all)=UTC, (all)/reporting=America/New_York", expected: check.SeverityWarn, contains: "(all)/reporting=America/New_York"},
+		{
+			name:     "configured zone",
+			timezone: "Europe/London",
+			cfg:      []check.Config{{"timezone": {"expected_timezone": "Europe/London"}}},
+			expected: check.SeverityOK,
+		},
+		{
+			name:     "configured zone mismatch",
+			timezone: "UTC",
+			cfg:      []check.Config{{"timezone": {"expected_timezone": "Europe/London"}}},
+			expected: check.SeverityWarn,
+			contains: "expected Europe/London",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			q := healthyQueryer()
+			q.settings.Timezone = tt.timezone
+			q.settings.LogTimezone = tt.timezone
+			q.settings.TimezoneOverrides = tt.overrides
+
+			report, err := timezone.New(q, tt.cfg...).Check(context.Background())
+			require.NoError(t, err)
+
+			f := checktest.Finding(t, report, "server-timezone")
+			assert.Equal(t, tt.expected, f.Severity)
+			assert.Contains(t, f.Details, tt.contains)
+		})
+	}
+}
+
+func TestTimezone_LogTimezone(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		log      string
+		expected check.Severity
+	}{
+		{name: "same", log: "UTC", expected: check.SeverityOK},
+		{name: "alias", log: "GMT", expected: check.SeverityOK},
+		{name: "different", log: "Europe/Berlin", expected: check.SeverityWarn},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			q := healthyQueryer()
+			q.settings.LogTimezone = tt.log
+
+			report, err := timezone.New(q).Check(context.Background())
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.expected, checktest.Finding(t, report, "log-timezone").Severity)
+		})
+	}
+}
+
+func TestTimezone_Datestyle(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		datestyle string
+		expected  check.Severity
+	}{
+		{name: "iso mdy", datestyle: "ISO, MDY", expected: check.SeverityOK},
+		{name: "iso dmy", datestyle: "ISO, DMY", expected: check.SeverityOK},
+		{name: "postgres", datestyle: "Postgres, MDY", expected: check.SeverityWarn},
+		{name: "sql", datestyle: "SQL, DMY", expected: check.SeverityWarn},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			q := healthyQueryer()
+			q.settings.Datestyle = tt.datestyle
+
+			report, err := timezone.New(q).Check(context.Background())
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.expected, checktest.Finding(t, report, "datestyle").Severity)
+		})
+	}
+}
+
+func TestTimezone_MixedTimestampTypes(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		counts   db.TimestampColumnCountsRow
+		expected check.Severity
+		rows     int
+	}{
+		{name: "only timestamptz", counts: db.TimestampColumnCountsRow{AwareColumns: 10}, expected: check.SeverityOK},
+		{name: "only timestamp", counts: db.TimestampColumnCountsRow{NaiveColumns: 10}, expected: check.SeverityOK},
+		{name: "no columns", expected: check.SeverityOK},
+		{name: "mixed", counts: db.TimestampColumnCountsRow{NaiveColumns: 2, AwareColumns: 10}, expected: check.SeverityWarn, rows: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			q := healthyQueryer()
+			q.counts = tt.counts
+			q.naive = []db.NaiveTimestampColumnsRow{
+				{TableName: "public.orders", ColumnName: "created_at"},
+				{TableName: "public.events", ColumnName: "occurred_at"},
+			}
+
+			report, err := timezone.New(q).Check(context.Background())
+			require.NoError(t, err)
+
+			f := checktest.Finding(t, report, "mixed-timestamp-types")
+			assert.Equal(t, tt.expected, f.Severity)
+			if tt.rows > 0 {
+				require.NotNil(t, f.Table)
+				assert.Len(t, f.Table.Rows, tt.rows)
+			} else {
+				assert.Nil(t, f.Table)
+			}
+		})
+	}
+}
+
+func TestTimezone_QueryError(t *testing.T) {
+	t.Parallel()
+
+	q := healthyQueryer()
+	q.err = errors.New("connection refused")
+
+	_, err := timezone.New(q).Check(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "timezone")
+}
+
+func TestMetadata(t *testing.T) {
+	t.Parallel()
+
+	meta := timezone.Metadata()
+	assert.Equal(t, "timezone", meta.CheckID)
+	assert.Equal(t, check.CategoryConfigs, meta.Category)
+	assert.NotEmpty(t, meta.SQL)
+	assert.NotEmpty(t, meta.Readme)
+}
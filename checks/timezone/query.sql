@@ -0,0 +1,52 @@
+-- name: TimezoneSettings :one
+-- Server time zone, log time zone, DateStyle, and per-database/role TimeZone overrides.
+SELECT
+  (SELECT reset_val FROM pg_settings WHERE name = 'TimeZone')::text AS timezone
+  , (SELECT reset_val FROM pg_settings WHERE name = 'log_timezone')::text AS log_timezone
+  , (SELECT reset_val FROM pg_settings WHERE name = 'DateStyle')::text AS datestyle
+  , COALESCE((
+    SELECT string_agg(
+      COALESCE(d.datname, '(all)') || '/' || COALESCE(r.rolname, '(all)') || '=' || SPLIT_PART(cfg.setting, '=', 2)
+      , ', ' ORDER BY d.datname, r.rolname
+    )
+    FROM pg_db_role_setting AS s
+    CROSS JOIN LATERAL UNNEST(s.setconfig) AS cfg (setting)
+    LEFT JOIN pg_database AS d ON s.setdatabase = d.oid
+    LEFT JOIN pg_roles AS r ON s.setrole = r.oid
+    WHERE LOWER(cfg.setting) LIKE 'timezone=%'
+  ), '')::text AS timezone_overrides;
+
+-- name: TimestampColumnCounts :one
+-- Counts timestamp (without time zone) and timestamptz columns in user tables.
+SELECT
+  COUNT(*) FILTER (WHERE a.atttypid = 'timestamp'::regtype)::bigint AS naive_columns
+  , COUNT(*) FILTER (WHERE a.atttypid = 'timestamptz'::regtype)::bigint AS aware_columns
+FROM pg_attribute AS a
+INNER JOIN pg_class AS c ON a.attrelid = c.oid
+INNER JOIN pg_namespace AS n ON c.relnamespace = n.oid
+WHERE
+  a.attnum > 0
+  AND NOT a.attisdropped
+  AND c.relkind IN ('r', 'p')
+  AND NOT c.relispartition
+  AND n.nspname NOT IN ('pg_catalog', 'information_schema')
+  AND n.nspname !~ '^pg_toast';
+
+-- name: NaiveTimestampColumns :many
+-- timestamp (without time zone) columns on the largest user tables.
+SELECT
+  (n.nspname || '.' || c.relname)::text AS table_name
+  , a.attname::text AS column_name
+FROM pg_attribute AS a
+INNER JOIN pg_class AS c ON a.attrelid = c.oid
+INNER JOIN pg_namespace AS n ON c.relnamespace = n.oid
+WHERE
+  a.attnum > 0
+  AND NOT a.attisdropped
+  AND a.atttypid = 'timestamp'::regtype
+  AND c.relkind IN ('r', 'p')
+  AND NOT c.relispartition
+  AND n.nspname NOT IN ('pg_catalog', 'information_schema')
+  AND n.nspname !~ '^pg_toast'
+ORDER BY pg_total_relation_size(c.oid) DESC, table_name, a.attnum
+LIMIT 20;
@@ -0,0 +1,300 @@
+// Package tlscerts implements checks for TLS certificate expiry.
+package tlscerts
+
+import (
+	"context"
+	"crypto/x509"
+	_ "embed"
+	"encoding/pem"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/fresha/pgdoctor/check"
+	"github.com/fresha/pgdoctor/db"
+)
+
+//go:embed query.sql
+var querySQL string
+
+//go:embed README.md
+var readme string
+
+const (
+	defaultWarnDays = 30.0
+	defaultFailDays = 7.0
+)
+
+type TLSCertsQueries interface {
+	TLSSettings(context.Context) (db.TLSSettingsRow, error)
+	CertificateFile(context.Context, string) ([]byte, error)
+}
+
+type checker struct {
+	queries  TLSCertsQueries
+	warnDays float64
+	failDays float64
+}
+
+func Metadata() check.Metadata {
+	return check.Metadata{
+		Category:       check.CategoryConfigs,
+		CheckID:        "tls-certs",
+		Name:           "TLS Certificates",
+		Description:    "Checks the expiry of the server, client CA, and replication certificates",
+		Readme:         readme,
+		SQL:            querySQL,
+		RuntimeClass:   check.RuntimeFast,
+		ProductionSafe: true,
+		Privileges:     []check.Privilege{check.PrivilegeReadAllSettings, check.PrivilegeReadServerFiles},
+		Findings: []check.FindingSpec{
+			{ID: "server-certificate", Description: "Certificate chain in ssl_cert_file, presented to every TLS client", Thresholds: "WARN < 30 days, FAIL < 7 days or expired"},
+			{ID: "ca-certificate", Description: "CA certificates in ssl_ca_file used to verify client certificates", Thresholds: "WARN < 30 days, FAIL < 7 days or expired"},
+			{ID: "replication-certificate", Description: "On a standby: the client certificate (sslcert) in primary_conninfo", Thresholds: "WARN < 30 days, FAIL < 7 days or expired"},
+		},
+	}
+}
+
+// New creates the check. The expiry windows default to 30 (WARN) and 7
+// (FAIL) days and can be changed with the expiry_warn_days and
+// expiry_fail_days config keys.
+func New(queries TLSCertsQueries, cfg ...check.Config) check.Checker {
+	c := &checker{
+		queries:  queries,
+		warnDays: defaultWarnDays,
+		failDays: defaultFailDays,
+	}
+	if len(cfg) > 0 {
+		c.warnDays = cfg[0].Float(Metadata().CheckID, "expiry_warn_days", defaultWarnDays)
+		c.failDays = cfg[0].Float(Metadata().CheckID, "expiry_fail_days", defaultFailDays)
+	}
+	return c
+}
+
+func (c *checker) Metadata() check.Metadata {
+	return Metadata()
+}
+
+func (c *checker) Check(ctx context.Context) (*check.Report, error) {
+	report := check.NewReport(Metadata())
+
+	settings, err := c.queries.TLSSettings(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("running %s/%s (settings): %w", report.Category, report.CheckID, err)
+	}
+
+	server := certFile{id: "server-certificate", name: "Server Certificate", setting: "ssl_cert_file", path: settings.CertFile}
+	switch {
+	case !settings.SslEnabled:
+		server.skip = "ssl is off, so the server presents no certificate"
+	case !settings.CanReadFiles:
+		server.skip = readPrivilegeNote(server)
+	}
+	c.checkCertFile(ctx, server, report)
+
+	ca := certFile{id: "ca-certificate", name: "Client CA Certificate", setting: "ssl_ca_file", path: settings.CaFile}
+	switch {
+	case !settings.SslEnabled:
+		ca.skip = "ssl is off, so no client certificates are verified"
+	case ca.path == "":
+		ca.skip = "ssl_ca_file is not set, so client certificates are not verified"
+	case !settings.CanReadFiles:
+		ca.skip = readPrivilegeNote(ca)
+	}
+	c.checkCertFile(ctx, ca, report)
+
+	replication := certFile{id: "replication-certificate", name: "Replication Certificate", setting: "sslcert in primary_conninfo"}
+	switch {
+	case !settings.InRecovery:
+		replication.skip = "Not a standby"
+	case settings.PrimaryConninfo == "":
+		replication.skip = "primary_conninfo is empty or not visible to this role (needs pg_read_all_settings)"
+	default:
+		replication.path = conninfoValue(settings.PrimaryConninfo, "sslcert")
+		switch {
+		case replication.path == "":
+			replication.skip = "The replication connection does not use a client certificate"
+		case !settings.CanReadFiles:
+			replication.skip = readPrivilegeNote(replication)
+		}
+	}
+	c.checkCertFile(ctx, replication, report)
+
+	return report, nil
+}
+
+// certFile is a certificate file one finding reports on. A non-empty skip
+// explains why the file is not inspected.
+type certFile struct {
+	id, name string
+	setting  string
+	path     string
+	skip     string
+}
+
+func readPrivilegeNote(f certFile) string {
+	return fmt.Sprintf("Reading %s (%s) needs superuser or pg_read_server_files; check its expiry on the server", f.setting, f.path)
+}
+
+func (c *checker) checkCertFile(ctx context.Context, f certFile, report *check.Report) {
+	if f.skip != "" {
+		report.AddFinding(check.Finding{
+			ID:       f.id,
+			Name:     f.name,
+			Severity: check.SeverityOK,
+			Details:  f.skip,
+		})
+		return
+	}
+
+	contents, err := c.queries.CertificateFile(ctx, f.path)
+	if err != nil {
+		report.AddFinding(check.Finding{
+			ID:       f.id,
+			Name:     f.name,
+			Severity: check.SeverityWarn,
+			Details:  fmt.Sprintf("Could not read %s (%s): %v", f.setting, f.path, err),
+		})
+		return
+	}
+
+	certs := parseCertificates(contents)
+	if len(certs) == 0 {
+		report.AddFinding(check.Finding{
+			ID:       f.id,
+			Name:     f.name,
+			Severity: check.SeverityWarn,
+			Details:  fmt.Sprintf("%s (%s) contains no PEM certificates", f.setting, f.path),
+		})
+		return
+	}
+
+	now := time.Now()
+	severity := check.SeverityOK
+	earliest := certs[0]
+	var rows []check.TableRow
+	for _, cert := range certs {
+		daysLeft := cert.NotAfter.Sub(now).Hours() / 24
+		rowSeverity := check.SeverityOK
+		switch {
+		case daysLeft < c.failDays:
+			rowSeverity = check.SeverityFail
+		case daysLeft < c.warnDays:
+			rowSeverity = check.SeverityWarn
+		}
+		severity = max(severity, rowSeverity)
+		if cert.NotAfter.Before(earliest.NotAfter) {
+			earliest = cert
+		}
+		rows = append(rows, check.TableRow{
+			Cells: []string{
+				cert.Subject.String(),
+				cert.Issuer.String(),
+				cert.NotAfter.UTC().Format(time.DateOnly),
+				formatDaysLeft(daysLeft),
+			},
+			Severity: rowSeverity,
+		})
+	}
+
+	details := fmt.Sprintf("%d certificate(s) in %s (%s); the earliest %s",
+		len(certs), f.setting, f.path, expiryPhrase(earliest, now))
+	if severity > check.SeverityOK {
+		details += fmt.Sprintf(". Renew before expiry: replace the file and run SELECT pg_reload_conf(); "+
+			"TLS connections fail verification once a certificate in the chain expires (WARN within %.0f days, FAIL within %.0f)",
+			c.warnDays, c.failDays)
+	}
+
+	report.AddFinding(check.Finding{
+		ID:       f.id,
+		Name:     f.name,
+		Severity: severity,
+		Details:  details,
+		Table: &check.Table{
+			Headers: []string{"Subject", "Issuer", "Expires", "Days Left"},
+			Rows:    rows,
+		},
+	})
+}
+
+// parseCertificates returns every certificate in a PEM bundle, skipping
+// blocks that do not parse.
+func parseCertificates(contents []byte) []*x509.Certificate {
+	var certs []*x509.Certificate
+	for {
+		var block *pem.Block
+		block, contents = pem.Decode(contents)
+		if block == nil {
+			return certs
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		if cert, err := x509.ParseCertificate(block.Bytes); err == nil {
+			certs = append(certs, cert)
+		}
+	}
+}
+
+func expiryPhrase(cert *x509.Certificate, now time.Time) string {
+	date := cert.NotAfter.UTC().Format(time.DateOnly)
+	if cert.NotAfter.Before(now) {
+		return "expired on " + date
+	}
+	return fmt.Sprintf("expires on %s, in %s days", date, formatDaysLeft(cert.NotAfter.Sub(now).Hours()/24))
+}
+
+func formatDaysLeft(days float64) string {
+	if days < 0 {
+		return "expired"
+	}
+	return fmt.Sprintf("%.0f", days)
+}
+
+// conninfoValue returns a keyword's value from a libpq connection string in
+// either keyword=value or URI form.
+func conninfoValue(conninfo, keyword string) string {
+	if strings.HasPrefix(conninfo, "postgres://") || strings.HasPrefix(conninfo, "postgresql://") {
+		u, err := url.Parse(conninfo)
+		if err != nil {
+			return ""
+		}
+		return u.Query().Get(keyword)
+	}
+
+	s := conninfo
+	for {
+		s = strings.TrimLeft(s, " \t\n")
+		key, rest, ok := strings.Cut(s, "=")
+		if !ok {
+			return ""
+		}
+		rest = strings.TrimLeft(rest, " \t\n")
+
+		var value strings.Builder
+		if strings.HasPrefix(rest, "'") {
+			rest = rest[1:]
+			for len(rest) > 0 && rest[0] != '\'' {
+				if rest[0] == '\\' && len(rest) > 1 {
+					rest = rest[1:]
+				}
+				value.WriteByte(rest[0])
+				rest = rest[1:]
+			}
+			rest = strings.TrimPrefix(rest, "'")
+		} else {
+			end := strings.IndexAny(rest, " \t\n")
+			if end < 0 {
+				end = len(rest)
+			}
+			value.WriteString(rest[:end])
+			rest = rest[end:]
+		}
+
+		if strings.TrimSpace(key) == keyword {
+			return value.String()
+		}
+		s = rest
+	}
+}
@@ -0,0 +1,262 @@
+package tlscerts_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/fresha/pgdoctor/check"
+	"github.com/fresha/pgdoctor/check/checktest"
+	"github.com/fresha/pgdoctor/checks/tlscerts"
+	"github.com/fresha/pgdoctor/db"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockQueryer struct {
+	settings db.TLSSettingsRow
+	files    map[string][]byte
+	err      error
+}
+
+func (m *mockQueryer) TLSSettings(context.Context) (db.TLSSettingsRow, error) {
+	return m.settings, m.err
+}
+
+func (m *mockQueryer) CertificateFile(_ context.Context, path string) ([]byte, error) {
+	contents, ok := m.files[path]
+	if !ok {
+		return nil, errors.New(`could not open file "` + path + `" for reading: No such file or directory`)
+	}
+	return contents, nil
+}
+
+// pemCert returns a self-signed certificate expiring after the given duration.
+func pemCert(t *testing.T, cn string, expiresIn time.Duration) []byte {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: cn},
+		NotBefore:    time.Now().Add(-365 * 24 * time.Hour),
+		NotAfter:     time.Now().Add(expiresIn),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+const day = 24 * time.Hour
+
+func TestTLSCerts_Expiry(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		expiresIn time.Duration
+		severity  check.Severity
+		daysLeft  string
+	}{
+		{name: "healthy", expiresIn: 200*day + time.Hour, severity: check.SeverityOK, daysLeft: "200"},
+		{name: "within warn window", expiresIn: 20*day + time.Hour, severity: check.SeverityWarn, daysLeft: "20"},
+		{name: "within fail window", expiresIn: 3*day + time.Hour, severity: check.SeverityFail, daysLeft: "3"},
+		{name: "expired", expiresIn: -2 * day, severity: check.SeverityFail, daysLeft: "expired"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			q := &mockQueryer{
+				settings: db.TLSSettingsRow{SslEnabled: true, CertFile: "server.crt", CanReadFiles: true},
+				files:    map[string][]byte{"server.crt": pemCert(t, "db.example.com", tt.expiresIn)},
+			}
+
+			report, err := tlscerts.New(q).Check(context.Background())
+			require.NoError(t, err)
+
+			server := checktest.Finding(t, report, "server-certificate")
+			assert.Equal(t, tt.severity, server.Severity)
+			require.NotNil(t, server.Table)
+			require.Len(t, server.Table.Rows, 1)
+			assert.Equal(t, "CN=db.example.com", server.Table.Rows[0].Cells[0])
+			assert.Equal(t, "CN=db.example.com", server.Table.Rows[0].Cells[1])
+			assert.Equal(t, tt.daysLeft, server.Table.Rows[0].Cells[3])
+			assert.Equal(t, tt.severity, report.Severity)
+		})
+	}
+}
+
+func TestTLSCerts_ChainUsesEarliestExpiry(t *testing.T) {
+	t.Parallel()
+
+	bundle := append(pemCert(t, "db.example.com", 300*day), pemCert(t, "Intermediate CA", 10*day)...)
+	q := &mockQueryer{
+		settings: db.TLSSettingsRow{SslEnabled: true, CertFile: "server.crt", CanReadFiles: true},
+		files:    map[string][]byte{"server.crt": bundle},
+	}
+
+	report, err := tlscerts.New(q).Check(context.Background())
+	require.NoError(t, err)
+
+	server := checktest.Finding(t, report, "server-certificate")
+	assert.Equal(t, check.SeverityWarn, server.Severity)
+	assert.Len(t, server.Table.Rows, 2)
+	assert.Equal(t, check.SeverityOK, server.Table.Rows[0].Severity)
+	assert.Equal(t, check.SeverityWarn, server.Table.Rows[1].Severity)
+	assert.Contains(t, server.Details, "2 certificate(s) in ssl_cert_file (server.crt)")
+	assert.Contains(t, server.Details, "pg_reload_conf()")
+}
+
+func TestTLSCerts_ConfiguredWindows(t *testing.T) {
+	t.Parallel()
+
+	q := &mockQueryer{
+		settings: db.TLSSettingsRow{SslEnabled: true, CertFile: "server.crt", CanReadFiles: true},
+		files:    map[string][]byte{"server.crt": pemCert(t, "db.example.com", 40*day)},
+	}
+	cfg := check.Config{"tls-certs": {"expiry_warn_days": "90", "expiry_fail_days": "45"}}
+
+	report, err := tlscerts.New(q, cfg).Check(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, check.SeverityFail, checktest.Finding(t, report, "server-certificate").Severity)
+}
+
+func TestTLSCerts_NotInspected(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		settings db.TLSSettingsRow
+		id       string
+		contains string
+	}{
+		{
+			name:     "ssl off",
+			settings: db.TLSSettingsRow{CertFile: "server.crt", CanReadFiles: true},
+			id:       "server-certificate",
+			contains: "ssl is off",
+		},
+		{
+			name:     "no file privilege",
+			settings: db.TLSSettingsRow{SslEnabled: true, CertFile: "server.crt"},
+			id:       "server-certificate",
+			contains: "pg_read_server_files",
+		},
+		{
+			name:     "no client CA",
+			settings: db.TLSSettingsRow{SslEnabled: true, CanReadFiles: true},
+			id:       "ca-certificate",
+			contains: "ssl_ca_file is not set",
+		},
+		{
+			name:     "primary",
+			settings: db.TLSSettingsRow{CanReadFiles: true},
+			id:       "replication-certificate",
+			contains: "Not a standby",
+		},
+		{
+			name:     "standby without client certificate",
+			settings: db.TLSSettingsRow{InRecovery: true, PrimaryConninfo: "host=primary user=replicator sslmode=verify-full", CanReadFiles: true},
+			id:       "replication-certificate",
+			contains: "does not use a client certificate",
+		},
+		{
+			name:     "primary_conninfo hidden",
+			settings: db.TLSSettingsRow{InRecovery: true, CanReadFiles: true},
+			id:       "replication-certificate",
+			contains: "pg_read_all_settings",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			report, err := tlscerts.New(&mockQueryer{settings: tt.settings}).Check(context.Background())
+			require.NoError(t, err)
+
+			f := checktest.Finding(t, report, tt.id)
+			assert.Equal(t, check.SeverityOK, f.Severity)
+			assert.Contains(t, f.Details, tt.contains)
+			assert.Nil(t, f.Table)
+		})
+	}
+}
+
+func TestTLSCerts_ReplicationCertificate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		conninfo string
+	}{
+		{name: "keyword form", conninfo: "host=primary user=replicator sslmode=verify-full sslcert='/etc/pg/repl client.crt' sslkey=/etc/pg/repl.key"},
+		{name: "URI form", conninfo: "postgresql://replicator@primary/postgres?sslmode=verify-full&sslcert=%2Fetc%2Fpg%2Frepl%20client.crt"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			q := &mockQueryer{
+				settings: db.TLSSettingsRow{InRecovery: true, PrimaryConninfo: tt.conninfo, CanReadFiles: true},
+				files:    map[string][]byte{"/etc/pg/repl client.crt": pemCert(t, "replicator", 2*day)},
+			}
+
+			report, err := tlscerts.New(q).Check(context.Background())
+			require.NoError(t, err)
+
+			f := checktest.Finding(t, report, "replication-certificate")
+			assert.Equal(t, check.SeverityFail, f.Severity)
+			assert.Equal(t, "CN=replicator", f.Table.Rows[0].Cells[0])
+		})
+	}
+}
+
+func TestTLSCerts_UnreadableFile(t *testing.T) {
+	t.Parallel()
+
+	q := &mockQueryer{
+		settings: db.TLSSettingsRow{SslEnabled: true, CertFile: "server.crt", CaFile: "root.crt", CanReadFiles: true},
+		files:    map[string][]byte{"server.crt": []byte("not a certificate")},
+	}
+
+	report, err := tlscerts.New(q).Check(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, check.SeverityWarn, checktest.Finding(t, report, "server-certificate").Severity)
+	assert.Contains(t, checktest.Finding(t, report, "server-certificate").Details, "contains no PEM certificates")
+	assert.Contains(t, checktest.Finding(t, report, "ca-certificate").Details, "No such file or directory")
+}
+
+func TestTLSCerts_QueryError(t *testing.T) {
+	t.Parallel()
+
+	_, err := tlscerts.New(&mockQueryer{err: errors.New("connection reset")}).Check(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "configs/tls-certs")
+}
+
+func TestMetadata(t *testing.T) {
+	t.Parallel()
+
+	meta := tlscerts.Metadata()
+	assert.Equal(t, "tls-certs", meta.CheckID)
+	assert.Equal(t, check.CategoryConfigs, meta.Category)
+	assert.NotEmpty(t, meta.SQL)
+	assert.NotEmpty(t, meta.Readme)
+	assert.Len(t, meta.Findings, 3)
+}
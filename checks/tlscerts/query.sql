@@ -0,0 +1,15 @@
+-- name: TLSSettings :one
+-- Reads the certificate file settings and whether this role may read the files.
+-- primary_conninfo is only visible to roles with pg_read_all_settings.
+SELECT
+  CURRENT_SETTING('ssl')::bool AS ssl_enabled
+  , CURRENT_SETTING('ssl_cert_file')::text AS cert_file
+  , CURRENT_SETTING('ssl_ca_file')::text AS ca_file
+  , COALESCE((SELECT setting FROM pg_settings WHERE name = 'primary_conninfo'), '')::text AS primary_conninfo
+  , PG_IS_IN_RECOVERY() AS in_recovery
+  , PG_HAS_ROLE(CURRENT_USER, 'pg_read_server_files', 'USAGE') AS can_read_files;
+
+-- name: CertificateFile :one
+-- Reads a PEM certificate file from the server's filesystem. Relative paths
+-- resolve against the data directory, as they do for ssl_cert_file.
+SELECT PG_READ_BINARY_FILE(sqlc.arg(path)::text) AS contents;
@@ -5,10 +5,14 @@ import (
 	"context"
 	_ "embed"
 	"fmt"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/fresha/pgdoctor/check"
 	"github.com/fresha/pgdoctor/db"
+	"github.com/fresha/pgdoctor/internal/pgident"
 )
 
 //go:embed query.sql
@@ -20,10 +24,13 @@ var readme string
 // ToastStorageQueries defines the database queries needed by this check.
 type ToastStorageQueries interface {
 	ToastStorage(context.Context) ([]db.ToastStorageRow, error)
+	ColumnStorageStrategiesEach(context.Context, func(db.ColumnStorageStrategiesRow) error) error
+	ColumnValueSample(context.Context, db.ColumnValueSampleParams) (db.ColumnValueSampleRow, error)
 }
 
 type checker struct {
-	queries ToastStorageQueries
+	queries    ToastStorageQueries
+	deepSample int // widest columns to sample values from; 0 uses the catalog only
 }
 
 const (
@@ -35,23 +42,57 @@ const (
 
 	wideColumnJSONBThreshold = 5000  // 5KB
 	wideColumnTextThreshold  = 10000 // 10KB
+
+	// Values wider than this are compressed or moved out of line (TOAST_TUPLE_THRESHOLD).
+	toastThresholdBytes = 2000
+
+	sampleRows       = 200
+	minSampledValues = 10
+	// Sampled values are treated as already compressed when this share
+	// starts with a compressed-format signature, or when compression saves
+	// less than 5% of their raw size.
+	precompressedShare     = 0.8
+	incompressibleRatio    = 0.95
+	uncompressedByteaShare = 0.2
 )
 
 func Metadata() check.Metadata {
 	return check.Metadata{
-		Category:    check.CategorySchema,
-		CheckID:     "toast-storage",
-		Name:        "TOAST Storage Analysis",
-		Description: "Analyzes TOAST storage usage for large value storage optimization",
-		Readme:      readme,
-		SQL:         querySQL,
+		Category:       check.CategorySchema,
+		CheckID:        "toast-storage",
+		Name:           "TOAST Storage Analysis",
+		Description:    "Analyzes TOAST storage usage for large value storage optimization",
+		Readme:         readme,
+		SQL:            querySQL,
+		RuntimeClass:   check.RuntimeHeavy,
+		ProductionSafe: true,
+		Privileges:     []check.Privilege{check.PrivilegeReadSchemas},
+		Findings: []check.FindingSpec{
+			{ID: "toast-ratio", Description: "Tables whose TOAST storage dominates their total size", Thresholds: "WARN > 50%, FAIL > 80%"},
+			{ID: "large-toast", Description: "Tables with very large TOAST relations", Thresholds: "WARN > 10GB, FAIL > 100GB"},
+			{ID: "toast-bloat", Description: "TOAST relations with many dead tuples", Thresholds: "WARN > 30%, FAIL > 50% dead"},
+			{ID: "wide-columns", Description: "Columns with a large average stored width", Thresholds: "WARN JSONB > 5KB or any column > 10KB"},
+			{ID: "compression-algorithm", Description: "TOAST compression still using the pglz default", Thresholds: "WARN"},
+			{ID: "storage-strategy", Description: "Columns with a non-default storage strategy, and strategies that do not fit the data (compressible text stored EXTERNAL, wide PLAIN columns, or already-compressed values stored EXTENDED when deep_sample_columns is set)", Thresholds: "WARN"},
+		},
 	}
 }
 
-func New(queries ToastStorageQueries, _ ...check.Config) check.Checker {
-	return &checker{
-		queries: queries,
+// New creates the check. deep_sample_columns samples values from that many
+// of the widest columns to tell already-compressed data from compressible
+// data; sampling reads table data, so it is off by default.
+func New(queries ToastStorageQueries, cfg ...check.Config) check.Checker {
+	c := &checker{queries: queries}
+	if len(cfg) > 0 && cfg[0] != nil {
+		if myCfg, ok := cfg[0][Metadata().CheckID]; ok {
+			if v, ok := myCfg["deep_sample_columns"]; ok {
+				if n, err := strconv.Atoi(v); err == nil && n > 0 {
+					c.deepSample = n
+				}
+			}
+		}
 	}
+	return c
 }
 
 func (c *checker) Metadata() check.Metadata {
@@ -60,11 +101,15 @@ func (c *checker) Metadata() check.Metadata {
 
 func (c *checker) Check(ctx context.Context) (*check.Report, error) {
 	report := check.NewReport(Metadata())
+	ignore := check.ObjectFilterFromContext(ctx)
 
 	rows, err := c.queries.ToastStorage(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to analyze TOAST storage: %w", err)
 	}
+	rows = slices.DeleteFunc(rows, func(row db.ToastStorageRow) bool {
+		return ignore.Table(row.SchemaName.String, row.TableName.String)
+	})
 
 	if len(rows) == 0 {
 		report.AddFinding(check.Finding{
@@ -73,15 +118,33 @@ func (c *checker) Check(ctx context.Context) (*check.Report, error) {
 			Severity: check.SeverityOK,
 			Details:  "No tables with significant TOAST storage found",
 		})
-		return report, nil
+	} else {
+		// Run all subchecks
+		checkExcessiveToastRatio(rows, report)
+		checkLargeToastTables(rows, report)
+		checkToastBloat(rows, report)
+		checkWideColumns(rows, report)
+		checkCompressionAlgorithm(ctx, rows, report)
+	}
+
+	// The query covers every schema, so rows are streamed and those of
+	// ignored tables are dropped as they are read.
+	var columns []db.ColumnStorageStrategiesRow
+	err = c.queries.ColumnStorageStrategiesEach(ctx, func(row db.ColumnStorageStrategiesRow) error {
+		if !ignore.Table(row.SchemaName, row.TableName) {
+			columns = append(columns, row)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read column storage strategies: %w", err)
 	}
 
-	// Run all subchecks
-	checkExcessiveToastRatio(rows, report)
-	checkLargeToastTables(rows, report)
-	checkToastBloat(rows, report)
-	checkWideColumns(rows, report)
-	checkCompressionAlgorithm(ctx, rows, report)
+	samples, err := c.sample(ctx, columns)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sample column values: %w", err)
+	}
+	c.checkStorageStrategy(columns, samples, report)
 
 	return report, nil
 }
@@ -351,10 +414,11 @@ func checkWideColumns(rows []db.ToastStorageRow, report *check.Report) {
 
 	if len(jsonbColumns) == 0 && len(largeTextColumns) == 0 {
 		report.AddFinding(check.Finding{
-			ID:       "wide-columns",
-			Name:     "Wide Column Analysis",
-			Severity: check.SeverityOK,
-			Details:  "No columns with excessive average width detected",
+			ID:         "wide-columns",
+			Confidence: check.ConfidenceMedium,
+			Name:       "Wide Column Analysis",
+			Severity:   check.SeverityOK,
+			Details:    "No columns with excessive average width detected",
 		})
 		return
 	}
@@ -390,10 +454,11 @@ func checkWideColumns(rows []db.ToastStorageRow, report *check.Report) {
 	}
 
 	report.AddFinding(check.Finding{
-		ID:       "wide-columns",
-		Name:     "Wide Column Analysis",
-		Severity: check.SeverityWarn,
-		Details:  fmt.Sprintf("Found %d JSONB and %d text columns with large average widths", len(jsonbColumns), len(largeTextColumns)),
+		ID:         "wide-columns",
+		Confidence: check.ConfidenceMedium,
+		Name:       "Wide Column Analysis",
+		Severity:   check.SeverityWarn,
+		Details:    fmt.Sprintf("Found %d JSONB and %d text columns with large average widths", len(jsonbColumns), len(largeTextColumns)),
 		Table: &check.Table{
 			Headers: headers,
 			Rows:    tableRows,
@@ -501,6 +566,204 @@ func checkCompressionAlgorithm(ctx context.Context, rows []db.ToastStorageRow, r
 	})
 }
 
+// columnName is the schema.table.column label used in tables and fix objects.
+func columnName(col db.ColumnStorageStrategiesRow) string {
+	return col.SchemaName + "." + col.TableName + "." + col.ColumnName
+}
+
+func isTextType(typ string) bool {
+	switch typ {
+	case "text", "varchar", "bpchar", "json", "jsonb":
+		return true
+	}
+	return false
+}
+
+// isWide reports whether the column's values are typically large enough to
+// be compressed or moved out of line.
+func isWide(col db.ColumnStorageStrategiesRow) bool {
+	return col.AvgWidth.Valid && col.AvgWidth.Int64 >= toastThresholdBytes
+}
+
+// sampleable reports whether sampled values can tell whether the column's
+// strategy fits its data. jsonb is left out: its stored binary form is not
+// comparable with its text length.
+func sampleable(col db.ColumnStorageStrategiesRow) bool {
+	if !col.CanSample || !isWide(col) || col.StorageStrategy == "PLAIN" {
+		return false
+	}
+	switch col.ColumnType {
+	case "bytea":
+		return true
+	case "text", "varchar", "bpchar":
+		return col.StorageStrategy != "EXTERNAL"
+	}
+	return false
+}
+
+// sample reads values from the deepSample widest sampleable columns. It
+// returns nil when sampling is off.
+func (c *checker) sample(ctx context.Context, columns []db.ColumnStorageStrategiesRow) (map[string]db.ColumnValueSampleRow, error) {
+	if c.deepSample == 0 {
+		return nil, nil
+	}
+
+	var candidates []db.ColumnStorageStrategiesRow
+	for _, col := range columns {
+		if sampleable(col) {
+			candidates = append(candidates, col)
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].AvgWidth.Int64 > candidates[j].AvgWidth.Int64
+	})
+
+	samples := make(map[string]db.ColumnValueSampleRow, min(len(candidates), c.deepSample))
+	for _, col := range candidates[:min(len(candidates), c.deepSample)] {
+		s, err := c.queries.ColumnValueSample(ctx, db.ColumnValueSampleParams{
+			ColumnName: col.ColumnName,
+			SchemaName: col.SchemaName,
+			TableName:  col.TableName,
+			IsBytea:    col.ColumnType == "bytea",
+			SampleRows: sampleRows,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", columnName(col), err)
+		}
+		samples[columnName(col)] = s
+	}
+	return samples, nil
+}
+
+// storageMismatch returns the strategy the column should use and why, or
+// an empty strategy when the current one fits.
+func storageMismatch(col db.ColumnStorageStrategiesRow, s db.ColumnValueSampleRow, sampled bool) (string, string) {
+	if col.StorageStrategy == "PLAIN" && isWide(col) {
+		return "EXTENDED", "wide values cannot be compressed or moved out of line; rows over 8KB fail to insert"
+	}
+	if col.StorageStrategy == "EXTERNAL" && isTextType(col.ColumnType) && isWide(col) {
+		return "EXTENDED", "compressible values are stored uncompressed"
+	}
+
+	if !sampled || s.Sampled < minSampledValues {
+		return "", ""
+	}
+	precompressed := float64(s.Precompressed) / float64(s.Sampled)
+	ratio := float64(s.StoredBytes) / float64(max(s.RawBytes, 1))
+
+	switch col.StorageStrategy {
+	case "EXTENDED", "MAIN":
+		if col.ColumnType == "bytea" && precompressed >= precompressedShare {
+			return "EXTERNAL", "values are already compressed (images or compressed payloads); compressing them again wastes CPU"
+		}
+		if ratio >= incompressibleRatio {
+			return "EXTERNAL", "compression saves under 5% of the sampled values' size, so it only costs CPU"
+		}
+	case "EXTERNAL":
+		if col.ColumnType == "bytea" && precompressed < uncompressedByteaShare {
+			return "EXTENDED", "sampled values do not look pre-compressed, so they may compress well"
+		}
+	}
+	return "", ""
+}
+
+func formatSample(s db.ColumnValueSampleRow, sampled bool) string {
+	if !sampled {
+		return "-"
+	}
+	if s.Sampled == 0 {
+		return "no values sampled"
+	}
+	return fmt.Sprintf("%d values, stored at %.0f%% of raw size, %d pre-compressed",
+		s.Sampled, 100*float64(s.StoredBytes)/float64(max(s.RawBytes, 1)), s.Precompressed)
+}
+
+// checkStorageStrategy lists columns with non-default storage strategies
+// and flags strategies that do not fit the data.
+func (c *checker) checkStorageStrategy(columns []db.ColumnStorageStrategiesRow, samples map[string]db.ColumnValueSampleRow, report *check.Report) {
+	var tableRows []check.TableRow
+	var fixes []check.Fix
+	nonDefault := 0
+
+	for _, col := range columns {
+		s, sampled := samples[columnName(col)]
+		want, reason := storageMismatch(col, s, sampled)
+		if col.StorageStrategy == col.DefaultStrategy && want == "" {
+			continue
+		}
+		if col.StorageStrategy != col.DefaultStrategy {
+			nonDefault++
+		}
+
+		avgWidth := "-"
+		if col.AvgWidth.Valid {
+			avgWidth = check.FormatBytes(col.AvgWidth.Int64)
+		}
+		issue := "-"
+		severity := check.SeverityOK
+		if want != "" {
+			issue = fmt.Sprintf("Use %s: %s", want, reason)
+			severity = check.SeverityWarn
+			fixes = append(fixes, check.Fix{
+				Object:      columnName(col),
+				Description: fmt.Sprintf("Store %s as %s; applies to values written from now on", columnName(col), want),
+				SQL: fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s SET STORAGE %s",
+					pgident.Quote(col.SchemaName, col.TableName), pgident.Quote(col.ColumnName), want),
+			})
+		}
+
+		tableRows = append(tableRows, check.TableRow{
+			Cells: []string{
+				columnName(col),
+				col.ColumnType,
+				col.StorageStrategy,
+				col.DefaultStrategy,
+				avgWidth,
+				formatSample(s, sampled),
+				issue,
+			},
+			Severity: severity,
+		})
+	}
+
+	if len(tableRows) == 0 {
+		details := "All TOAST-able columns use their type's default storage strategy"
+		if c.deepSample > 0 && len(samples) > 0 {
+			details += fmt.Sprintf("; sampled values from %d column(s) fit it", len(samples))
+		}
+		report.AddFinding(check.Finding{
+			ID:       "storage-strategy",
+			Name:     "Column Storage Strategy",
+			Severity: check.SeverityOK,
+			Details:  details,
+		})
+		return
+	}
+
+	severity := check.SeverityOK
+	details := fmt.Sprintf("%d column(s) use a non-default storage strategy", nonDefault)
+	if len(fixes) > 0 {
+		severity = check.SeverityWarn
+		details += fmt.Sprintf("; %d column(s) use a strategy that does not fit their data", len(fixes))
+	}
+	if c.deepSample == 0 {
+		details += "\nSet deep_sample_columns to sample values and detect already-compressed data stored EXTENDED"
+	}
+
+	report.AddFinding(check.Finding{
+		ID:         "storage-strategy",
+		Name:       "Column Storage Strategy",
+		Severity:   severity,
+		Details:    details,
+		Confidence: check.ConfidenceMedium,
+		Table: &check.Table{
+			Headers: []string{"Column", "Type", "Storage", "Default", "Avg Width", "Sample", "Issue"},
+			Rows:    tableRows,
+		},
+		Fixes: fixes,
+	})
+}
+
 // Helper functions
 
 func formatWideColumns(cols []string) string {
@@ -3,9 +3,11 @@ package toaststorage_test
 import (
 	"context"
 	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/fresha/pgdoctor/check"
+	"github.com/fresha/pgdoctor/check/checktest"
 	"github.com/fresha/pgdoctor/checks/toaststorage"
 	"github.com/fresha/pgdoctor/db"
 	"github.com/jackc/pgx/v5/pgtype"
@@ -18,11 +20,15 @@ const (
 	findingIDToastBloat           = "toast-bloat"
 	findingIDWideColumns          = "wide-columns"
 	findingIDCompressionAlgorithm = "compression-algorithm"
+	findingIDStorageStrategy      = "storage-strategy"
 )
 
 type mockQueryer struct {
-	rows []db.ToastStorageRow
-	err  error
+	rows    []db.ToastStorageRow
+	columns []db.ColumnStorageStrategiesRow
+	samples map[string]db.ColumnValueSampleRow
+	sampled []string
+	err     error
 }
 
 func (m *mockQueryer) ToastStorage(context.Context) ([]db.ToastStorageRow, error) {
@@ -32,6 +38,21 @@ func (m *mockQueryer) ToastStorage(context.Context) ([]db.ToastStorageRow, error
 	return m.rows, nil
 }
 
+func (m *mockQueryer) ColumnStorageStrategiesEach(_ context.Context, fn func(db.ColumnStorageStrategiesRow) error) error {
+	for _, row := range m.columns {
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *mockQueryer) ColumnValueSample(_ context.Context, arg db.ColumnValueSampleParams) (db.ColumnValueSampleRow, error) {
+	name := arg.SchemaName + "." + arg.TableName + "." + arg.ColumnName
+	m.sampled = append(m.sampled, name)
+	return m.samples[name], nil
+}
+
 func makeToastRow(schema, table, toastTable string, mainSize, toastSize, totalSize int64, toastPercent float64) db.ToastStorageRow {
 	percentNumeric := &pgtype.Numeric{}
 	_ = percentNumeric.Scan(fmt.Sprintf("%.2f", toastPercent))
@@ -62,8 +83,9 @@ func Test_ToastStorage_NoIssues(t *testing.T) {
 
 	require.NoError(t, err)
 	require.Equal(t, check.SeverityOK, report.Severity)
-	require.Equal(t, 1, len(report.Results))
+	require.Equal(t, 2, len(report.Results))
 	require.Contains(t, report.Results[0].Details, "No tables with significant TOAST storage")
+	require.Equal(t, findingIDStorageStrategy, report.Results[1].ID)
 }
 
 func Test_ToastStorage_ExcessiveRatio_FAIL(t *testing.T) {
@@ -541,3 +563,186 @@ func Test_ToastStorage_PrescriptionContent(t *testing.T) {
 	require.NoError(t, err)
 	require.NotEmpty(t, report.Results)
 }
+
+func makeColumn(name, typ, storage, def string, avgWidth int64) db.ColumnStorageStrategiesRow {
+	parts := strings.SplitN(name, ".", 3)
+	return db.ColumnStorageStrategiesRow{
+		SchemaName:      parts[0],
+		TableName:       parts[1],
+		ColumnName:      parts[2],
+		ColumnType:      typ,
+		StorageStrategy: storage,
+		DefaultStrategy: def,
+		AvgWidth:        pgtype.Int8{Int64: avgWidth, Valid: avgWidth > 0},
+		CanSample:       true,
+	}
+}
+
+func Test_ToastStorage_StorageStrategy(t *testing.T) {
+	t.Parallel()
+
+	deep := check.Config{"toast-storage": {"deep_sample_columns": "5"}}
+
+	tests := []struct {
+		name     string
+		column   db.ColumnStorageStrategiesRow
+		sample   db.ColumnValueSampleRow
+		cfg      check.Config
+		severity check.Severity
+		fixSQL   string
+		rows     int
+	}{
+		{
+			name:     "defaults only",
+			column:   makeColumn("public.docs.body", "text", "EXTENDED", "EXTENDED", 4000),
+			severity: check.SeverityOK,
+		},
+		{
+			name:     "narrow non-default column is listed",
+			column:   makeColumn("public.docs.code", "text", "MAIN", "EXTENDED", 40),
+			severity: check.SeverityOK,
+			rows:     1,
+		},
+		{
+			name:     "compressible text stored EXTERNAL",
+			column:   makeColumn("public.docs.body", "text", "EXTERNAL", "EXTENDED", 6000),
+			severity: check.SeverityWarn,
+			fixSQL:   `ALTER TABLE "public"."docs" ALTER COLUMN "body" SET STORAGE EXTENDED`,
+			rows:     1,
+		},
+		{
+			name:     "wide PLAIN column",
+			column:   makeColumn("public.docs.body", "jsonb", "PLAIN", "EXTENDED", 3000),
+			severity: check.SeverityWarn,
+			fixSQL:   `ALTER TABLE "public"."docs" ALTER COLUMN "body" SET STORAGE EXTENDED`,
+			rows:     1,
+		},
+		{
+			name:     "compressed images stored EXTENDED without sampling",
+			column:   makeColumn("public.photos.data", "bytea", "EXTENDED", "EXTENDED", 90000),
+			sample:   db.ColumnValueSampleRow{Sampled: 100, StoredBytes: 9_000_000, RawBytes: 9_000_000, Precompressed: 100},
+			severity: check.SeverityOK,
+		},
+		{
+			name:     "compressed images stored EXTENDED",
+			column:   makeColumn("public.photos.data", "bytea", "EXTENDED", "EXTENDED", 90000),
+			sample:   db.ColumnValueSampleRow{Sampled: 100, StoredBytes: 9_000_000, RawBytes: 9_000_000, Precompressed: 95},
+			cfg:      deep,
+			severity: check.SeverityWarn,
+			fixSQL:   `ALTER TABLE "public"."photos" ALTER COLUMN "data" SET STORAGE EXTERNAL`,
+			rows:     1,
+		},
+		{
+			name:     "incompressible text stored EXTENDED",
+			column:   makeColumn("public.blobs.b64", "text", "EXTENDED", "EXTENDED", 50000),
+			sample:   db.ColumnValueSampleRow{Sampled: 100, StoredBytes: 4_900_000, RawBytes: 5_000_000},
+			cfg:      deep,
+			severity: check.SeverityWarn,
+			fixSQL:   `ALTER TABLE "public"."blobs" ALTER COLUMN "b64" SET STORAGE EXTERNAL`,
+			rows:     1,
+		},
+		{
+			name:     "compressible text stored EXTENDED",
+			column:   makeColumn("public.docs.body", "text", "EXTENDED", "EXTENDED", 50000),
+			sample:   db.ColumnValueSampleRow{Sampled: 100, StoredBytes: 1_500_000, RawBytes: 5_000_000},
+			cfg:      deep,
+			severity: check.SeverityOK,
+		},
+		{
+			name:     "uncompressed bytea stored EXTERNAL",
+			column:   makeColumn("public.exports.csv", "bytea", "EXTERNAL", "EXTENDED", 50000),
+			sample:   db.ColumnValueSampleRow{Sampled: 100, StoredBytes: 5_000_000, RawBytes: 5_000_000, Precompressed: 3},
+			cfg:      deep,
+			severity: check.SeverityWarn,
+			fixSQL:   `ALTER TABLE "public"."exports" ALTER COLUMN "csv" SET STORAGE EXTENDED`,
+			rows:     1,
+		},
+		{
+			name:     "too few sampled values",
+			column:   makeColumn("public.photos.data", "bytea", "EXTENDED", "EXTENDED", 90000),
+			sample:   db.ColumnValueSampleRow{Sampled: 3, StoredBytes: 270_000, RawBytes: 270_000, Precompressed: 3},
+			cfg:      deep,
+			severity: check.SeverityOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			queryer := &mockQueryer{
+				columns: []db.ColumnStorageStrategiesRow{tt.column},
+				samples: map[string]db.ColumnValueSampleRow{
+					tt.column.SchemaName + "." + tt.column.TableName + "." + tt.column.ColumnName: tt.sample,
+				},
+			}
+			var cfg []check.Config
+			if tt.cfg != nil {
+				cfg = append(cfg, tt.cfg)
+			}
+
+			report, err := toaststorage.New(queryer, cfg...).Check(context.Background())
+			require.NoError(t, err)
+
+			f := checktest.Finding(t, report, findingIDStorageStrategy)
+			require.Equal(t, tt.severity, f.Severity)
+			if tt.rows == 0 {
+				require.Nil(t, f.Table)
+			} else {
+				require.NotNil(t, f.Table)
+				require.Len(t, f.Table.Rows, tt.rows)
+			}
+			if tt.fixSQL == "" {
+				require.Empty(t, f.Fixes)
+				return
+			}
+			require.Len(t, f.Fixes, 1)
+			require.Equal(t, tt.fixSQL, f.Fixes[0].SQL)
+			require.Equal(t, check.LockExclusive, f.Fixes[0].Lock)
+		})
+	}
+}
+
+func Test_ToastStorage_StorageStrategy_SamplesWidestColumns(t *testing.T) {
+	t.Parallel()
+
+	noAccess := makeColumn("secret.keys.blob", "bytea", "EXTENDED", "EXTENDED", 900000)
+	noAccess.CanSample = false
+
+	queryer := &mockQueryer{
+		columns: []db.ColumnStorageStrategiesRow{
+			makeColumn("public.a.small", "bytea", "EXTENDED", "EXTENDED", 3000),
+			makeColumn("public.b.large", "bytea", "EXTENDED", "EXTENDED", 80000),
+			makeColumn("public.c.narrow", "bytea", "EXTENDED", "EXTENDED", 100),
+			makeColumn("public.d.doc", "jsonb", "EXTENDED", "EXTENDED", 70000),
+			makeColumn("public.e.medium", "text", "EXTENDED", "EXTENDED", 20000),
+			noAccess,
+		},
+	}
+
+	_, err := toaststorage.New(queryer, check.Config{"toast-storage": {"deep_sample_columns": "2"}}).Check(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, []string{"public.b.large", "public.e.medium"}, queryer.sampled)
+}
+
+func Test_ToastStorage_StorageStrategy_SkipsIgnoredTables(t *testing.T) {
+	t.Parallel()
+
+	queryer := &mockQueryer{
+		columns: []db.ColumnStorageStrategiesRow{
+			makeColumn("public.docs.body", "text", "EXTERNAL", "EXTENDED", 0),
+			makeColumn("archive.docs.body", "text", "EXTERNAL", "EXTENDED", 0),
+		},
+	}
+	filter, err := check.IgnoreRules{Schemas: []string{"archive"}}.Compile()
+	require.NoError(t, err)
+	ctx := check.ContextWithObjectFilter(context.Background(), filter)
+
+	report, err := toaststorage.New(queryer).Check(ctx)
+	require.NoError(t, err)
+
+	f := checktest.Finding(t, report, findingIDStorageStrategy)
+	require.NotNil(t, f.Table)
+	require.Len(t, f.Table.Rows, 1, "streamed rows of ignored tables are dropped")
+	require.Contains(t, f.Table.Rows[0].Cells[0], "public.docs")
+}
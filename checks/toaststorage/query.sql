@@ -114,3 +114,85 @@ SELECT
   ) AS column_compression_info
 FROM toast_info AS ti
 ORDER BY ti.toast_size DESC;
+
+-- name: ColumnStorageStrategies :many
+-- Returns TOAST-able columns whose storage strategy differs from their type's
+-- default, plus wide columns (avg_width >= 2KB) whose values are likely
+-- compressed or moved out of line, so both can be checked against the data.
+SELECT
+  n.nspname::text AS schema_name
+  , c.relname::text AS table_name
+  , a.attname::text AS column_name
+  , t.typname::text AS column_type
+  , CASE a.attstorage
+    WHEN 'p' THEN 'PLAIN'
+    WHEN 'e' THEN 'EXTERNAL'
+    WHEN 'x' THEN 'EXTENDED'
+    WHEN 'm' THEN 'MAIN'
+  END AS storage_strategy
+  , CASE t.typstorage
+    WHEN 'p' THEN 'PLAIN'
+    WHEN 'e' THEN 'EXTERNAL'
+    WHEN 'x' THEN 'EXTENDED'
+    WHEN 'm' THEN 'MAIN'
+  END AS default_strategy
+  , ps.avg_width::bigint AS avg_width
+  , HAS_COLUMN_PRIVILEGE(c.oid, a.attnum, 'SELECT') AS can_sample
+FROM pg_attribute AS a
+INNER JOIN pg_class AS c ON a.attrelid = c.oid
+INNER JOIN pg_namespace AS n ON c.relnamespace = n.oid
+INNER JOIN pg_type AS t ON a.atttypid = t.oid
+LEFT JOIN pg_stats AS ps
+  ON n.nspname = ps.schemaname AND c.relname = ps.tablename AND a.attname = ps.attname
+WHERE
+  a.attnum > 0
+  AND NOT a.attisdropped
+  AND c.relkind IN ('r', 'p')
+  AND n.nspname NOT IN ('pg_catalog', 'information_schema', 'pg_toast')
+  AND t.typstorage != 'p'  -- Only variable-length types can be TOASTed
+  AND (a.attstorage != t.typstorage OR coalesce(ps.avg_width, 0) >= 2000)
+ORDER BY n.nspname, c.relname, a.attnum;
+
+-- name: ColumnValueSample :one
+-- Samples up to sample_rows non-null values of one column and reports their
+-- stored size (after any compression) against their raw size, and how many
+-- start with the signature of an already-compressed format (gzip, zstd, zip,
+-- PNG, JPEG, WebP/RIFF). Signatures are only read for bytea columns.
+-- query_to_xml runs the per-column query without dynamic SQL in the client.
+WITH sample AS (
+  SELECT
+    (xpath('/row/stored/text()', x))[1]::text::bigint AS stored
+    , (xpath('/row/raw/text()', x))[1]::text::bigint AS raw
+    , coalesce((xpath('/row/magic/text()', x))[1]::text, '') AS magic
+  FROM UNNEST(XPATH('/table/row', QUERY_TO_XML(FORMAT(
+    'SELECT pg_column_size(%1$I) AS stored, %4$s AS raw, %5$s AS magic '
+    'FROM %2$I.%3$I TABLESAMPLE SYSTEM (1) WHERE %1$I IS NOT NULL LIMIT %6$s'
+    , sqlc.arg(column_name)::text
+    , sqlc.arg(schema_name)::text
+    , sqlc.arg(table_name)::text
+    , CASE
+      WHEN sqlc.arg(is_bytea)::bool THEN FORMAT('octet_length(%I)', sqlc.arg(column_name)::text)
+      ELSE FORMAT('octet_length(%I::text)', sqlc.arg(column_name)::text)
+    END
+    , CASE
+      WHEN sqlc.arg(is_bytea)::bool THEN FORMAT('encode(substring(%I FROM 1 FOR 4), ''hex'')', sqlc.arg(column_name)::text)
+      ELSE 'NULL::text'
+    END
+    , sqlc.arg(sample_rows)::int
+  ), FALSE, FALSE, ''))) AS x
+)
+
+SELECT
+  count(*) AS sampled
+  , coalesce(sum(stored), 0)::bigint AS stored_bytes
+  , coalesce(sum(raw), 0)::bigint AS raw_bytes
+  , count(*) FILTER (
+    WHERE
+      magic LIKE '1f8b%'       -- gzip
+      OR magic = '28b52ffd'    -- zstd
+      OR magic = '504b0304'    -- zip, docx, xlsx
+      OR magic = '89504e47'    -- PNG
+      OR magic LIKE 'ffd8ff%'  -- JPEG
+      OR magic = '52494646'    -- RIFF (WebP, WAV, AVI)
+  ) AS precompressed
+FROM sample;
@@ -0,0 +1,171 @@
+// Package txnrates implements checks for transaction throughput and the
+// share of transactions that roll back.
+package txnrates
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+
+	"github.com/fresha/pgdoctor/check"
+	"github.com/fresha/pgdoctor/db"
+)
+
+//go:embed query.sql
+var querySQL string
+
+//go:embed README.md
+var readme string
+
+const (
+	// Rollback ratio thresholds, as a percentage of all finished transactions.
+	defaultRollbackWarnPercent = 5.0
+	defaultRollbackFailPercent = 20.0
+
+	// Below this many transactions the ratio is too noisy to judge.
+	minTransactions = int64(1000)
+
+	// Rates over a shorter window than this say little about normal load.
+	shortWindowSeconds = 3600.0
+)
+
+type TxnRatesQueries interface {
+	TransactionRates(context.Context) (db.TransactionRatesRow, error)
+}
+
+type checker struct {
+	queries TxnRatesQueries
+
+	rollbackWarn float64
+	rollbackFail float64
+}
+
+func Metadata() check.Metadata {
+	return check.Metadata{
+		Category:       check.CategoryPerformance,
+		CheckID:        "txn-rates",
+		Name:           "Transaction Rates",
+		Description:    "Reports commit and rollback rates since the statistics reset and flags high rollback ratios",
+		Readme:         readme,
+		SQL:            querySQL,
+		RuntimeClass:   check.RuntimeFast,
+		ProductionSafe: true,
+		Findings: []check.FindingSpec{
+			{ID: "transaction-rate", Description: "Commits and rollbacks per second since the statistics reset"},
+			{ID: "rollback-ratio", Description: "Share of finished transactions that rolled back", Thresholds: "WARN >= 5%, FAIL >= 20% (with >= 1000 transactions)"},
+		},
+	}
+}
+
+// New creates the check. rollback_warn_percent and rollback_fail_percent
+// change the rollback-ratio thresholds.
+func New(queries TxnRatesQueries, cfg ...check.Config) check.Checker {
+	c := &checker{
+		queries:      queries,
+		rollbackWarn: defaultRollbackWarnPercent,
+		rollbackFail: defaultRollbackFailPercent,
+	}
+	if len(cfg) > 0 {
+		id := Metadata().CheckID
+		c.rollbackWarn = cfg[0].Float(id, "rollback_warn_percent", defaultRollbackWarnPercent)
+		c.rollbackFail = max(cfg[0].Float(id, "rollback_fail_percent", defaultRollbackFailPercent), c.rollbackWarn)
+	}
+	return c
+}
+
+func (c *checker) Metadata() check.Metadata {
+	return Metadata()
+}
+
+func (c *checker) Check(ctx context.Context) (*check.Report, error) {
+	report := check.NewReport(Metadata())
+
+	row, err := c.queries.TransactionRates(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("running %s/%s: %w", report.Category, report.CheckID, err)
+	}
+
+	checkTransactionRate(row, report)
+	c.checkRollbackRatio(ctx, row, report)
+
+	return report, nil
+}
+
+// window describes the period the counters cover, for use in details.
+func window(row db.TransactionRatesRow) string {
+	if !row.StatsReset.Valid {
+		return "since statistics were last reset (never, so the period is unknown)"
+	}
+	return fmt.Sprintf("over the last %s", check.FormatDurationSec(int64(row.StatsAgeSeconds.Float64)))
+}
+
+func checkTransactionRate(row db.TransactionRatesRow, report *check.Report) {
+	details := fmt.Sprintf("%s commits, %s rollbacks %s",
+		check.FormatNumber(row.Commits), check.FormatNumber(row.Rollbacks), window(row))
+
+	if row.StatsReset.Valid && row.StatsAgeSeconds.Float64 > 0 {
+		age := row.StatsAgeSeconds.Float64
+		details = fmt.Sprintf("%.1f commits/s, %.1f rollbacks/s (%s commits, %s rollbacks %s)",
+			float64(row.Commits)/age, float64(row.Rollbacks)/age,
+			check.FormatNumber(row.Commits), check.FormatNumber(row.Rollbacks), window(row))
+		if age < shortWindowSeconds {
+			details += "\nThe window is under an hour, so it may not reflect normal load"
+		}
+	}
+
+	report.AddFinding(check.Finding{
+		ID:       "transaction-rate",
+		Name:     "Transaction Rate",
+		Severity: check.SeverityOK,
+		Details:  details,
+	})
+}
+
+func (c *checker) checkRollbackRatio(ctx context.Context, row db.TransactionRatesRow, report *check.Report) {
+	total := row.Commits + row.Rollbacks
+	if total < minTransactions {
+		report.AddFinding(check.Finding{
+			ID:       "rollback-ratio",
+			Name:     "Rollback Ratio",
+			Severity: check.SeverityOK,
+			Details:  fmt.Sprintf("Only %d transactions %s; too few to judge the rollback ratio", total, window(row)),
+		})
+		return
+	}
+
+	ratio := 100 * float64(row.Rollbacks) / float64(total)
+	check.Observe(ctx, check.Observation{
+		CheckID:   Metadata().CheckID,
+		FindingID: "rollback-ratio",
+		Key:       "rollback_warn_percent",
+		Value:     ratio,
+		Warn:      c.rollbackWarn,
+		Fail:      c.rollbackFail,
+	})
+	if ratio < c.rollbackWarn {
+		report.AddFinding(check.Finding{
+			ID:       "rollback-ratio",
+			Name:     "Rollback Ratio",
+			Severity: check.SeverityOK,
+			Details:  fmt.Sprintf("%.2f%% of transactions rolled back %s", ratio, window(row)),
+		})
+		return
+	}
+
+	severity := check.SeverityWarn
+	threshold := c.rollbackWarn
+	if ratio >= c.rollbackFail {
+		severity = check.SeverityFail
+		threshold = c.rollbackFail
+	}
+
+	report.AddFinding(check.Finding{
+		ID:       "rollback-ratio",
+		Name:     "Rollback Ratio",
+		Severity: severity,
+		Details: fmt.Sprintf("%.2f%% of transactions rolled back %s (threshold %.0f%%)\n%s of %s transactions rolled back; "+
+			"sustained rollbacks usually mean application errors, serialization failures, or lock timeouts",
+			ratio, window(row), threshold, check.FormatNumber(row.Rollbacks), check.FormatNumber(total)),
+		Confidence: check.ConfidenceMedium,
+	})
+}
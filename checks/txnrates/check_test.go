@@ -0,0 +1,179 @@
+package txnrates_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/fresha/pgdoctor/check"
+	"github.com/fresha/pgdoctor/check/checktest"
+	"github.com/fresha/pgdoctor/checks/txnrates"
+	"github.com/fresha/pgdoctor/db"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockQueryer struct {
+	row db.TransactionRatesRow
+	err error
+}
+
+func (m *mockQueryer) TransactionRates(context.Context) (db.TransactionRatesRow, error) {
+	return m.row, m.err
+}
+
+const day = 86400.0
+
+func makeRow(commits, rollbacks int64, ageSeconds float64) db.TransactionRatesRow {
+	return db.TransactionRatesRow{
+		Commits:         commits,
+		Rollbacks:       rollbacks,
+		StatsReset:      pgtype.Timestamptz{Time: time.Now().Add(-time.Duration(ageSeconds) * time.Second), Valid: true},
+		StatsAgeSeconds: pgtype.Float8{Float64: ageSeconds, Valid: true},
+	}
+}
+
+func TestTxnRates_RollbackRatio(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		row      db.TransactionRatesRow
+		cfg      check.Config
+		severity check.Severity
+		contains string
+	}{
+		{
+			name:     "healthy ratio",
+			row:      makeRow(990_000, 10_000, 7*day),
+			severity: check.SeverityOK,
+			contains: "1.00% of transactions rolled back over the last 7d",
+		},
+		{
+			name:     "warn at 5%",
+			row:      makeRow(950_000, 50_000, 7*day),
+			severity: check.SeverityWarn,
+			contains: "5.00% of transactions rolled back over the last 7d (threshold 5%)",
+		},
+		{
+			name:     "fail at 20%",
+			row:      makeRow(700_000, 300_000, 7*day),
+			severity: check.SeverityFail,
+			contains: "30.00% of transactions rolled back",
+		},
+		{
+			name:     "too few transactions",
+			row:      makeRow(100, 400, day),
+			severity: check.SeverityOK,
+			contains: "too few to judge",
+		},
+		{
+			name:     "configured thresholds",
+			row:      makeRow(700_000, 300_000, 7*day),
+			cfg:      check.Config{"txn-rates": {"rollback_warn_percent": "40", "rollback_fail_percent": "60"}},
+			severity: check.SeverityOK,
+		},
+		{
+			name:     "fail threshold below warn is raised to it",
+			row:      makeRow(700_000, 300_000, 7*day),
+			cfg:      check.Config{"txn-rates": {"rollback_warn_percent": "40", "rollback_fail_percent": "10"}},
+			severity: check.SeverityOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var cfg []check.Config
+			if tt.cfg != nil {
+				cfg = append(cfg, tt.cfg)
+			}
+			report, err := txnrates.New(&mockQueryer{row: tt.row}, cfg...).Check(context.Background())
+			require.NoError(t, err)
+
+			f := checktest.Finding(t, report, "rollback-ratio")
+			assert.Equal(t, tt.severity, f.Severity)
+			assert.Contains(t, f.Details, tt.contains)
+			if tt.severity > check.SeverityOK {
+				assert.Equal(t, check.ConfidenceMedium, f.Confidence)
+			}
+		})
+	}
+}
+
+func TestTxnRates_TransactionRate(t *testing.T) {
+	t.Parallel()
+
+	report, err := txnrates.New(&mockQueryer{row: makeRow(864_000, 8_640, day)}).Check(context.Background())
+	require.NoError(t, err)
+
+	f := checktest.Finding(t, report, "transaction-rate")
+	assert.Equal(t, check.SeverityOK, f.Severity)
+	assert.Contains(t, f.Details, "10.0 commits/s, 0.1 rollbacks/s")
+	assert.Contains(t, f.Details, "over the last 1d")
+	assert.NotContains(t, f.Details, "under an hour")
+}
+
+func TestTxnRates_ShortWindow(t *testing.T) {
+	t.Parallel()
+
+	report, err := txnrates.New(&mockQueryer{row: makeRow(5_000, 10, 600)}).Check(context.Background())
+	require.NoError(t, err)
+
+	rate := checktest.Finding(t, report, "transaction-rate")
+	assert.Equal(t, check.SeverityOK, rate.Severity)
+	assert.Contains(t, rate.Details, "over the last 10m")
+	assert.Contains(t, rate.Details, "under an hour")
+}
+
+func TestTxnRates_NeverReset(t *testing.T) {
+	t.Parallel()
+
+	row := db.TransactionRatesRow{Commits: 2_000_000, Rollbacks: 500_000}
+	report, err := txnrates.New(&mockQueryer{row: row}).Check(context.Background())
+	require.NoError(t, err)
+
+	rate := checktest.Finding(t, report, "transaction-rate")
+	assert.Contains(t, rate.Details, "never")
+	assert.NotContains(t, rate.Details, "/s")
+	assert.Contains(t, rate.Details, "2.0M commits")
+	assert.Equal(t, check.SeverityFail, checktest.Finding(t, report, "rollback-ratio").Severity)
+}
+
+func TestTxnRates_ObservesRollbackRatio(t *testing.T) {
+	t.Parallel()
+
+	var got []check.Observation
+	ctx := check.ContextWithObserver(context.Background(), func(o check.Observation) { got = append(got, o) })
+	cfg := check.Config{"txn-rates": {"rollback_warn_percent": "8"}}
+
+	_, err := txnrates.New(&mockQueryer{row: makeRow(950_000, 50_000, 7*day)}, cfg).Check(ctx)
+	require.NoError(t, err)
+
+	require.Len(t, got, 1)
+	assert.Equal(t, "rollback-ratio", got[0].FindingID)
+	assert.Equal(t, "rollback_warn_percent", got[0].Key)
+	assert.InDelta(t, 5.0, got[0].Value, 0.001)
+	assert.Equal(t, 8.0, got[0].Warn)
+	assert.Equal(t, 20.0, got[0].Fail)
+}
+
+func TestTxnRates_QueryError(t *testing.T) {
+	t.Parallel()
+
+	_, err := txnrates.New(&mockQueryer{err: errors.New("boom")}).Check(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "txn-rates")
+}
+
+func TestTxnRates_Metadata(t *testing.T) {
+	t.Parallel()
+
+	meta := txnrates.New(&mockQueryer{}).Metadata()
+	assert.Equal(t, "txn-rates", meta.CheckID)
+	assert.Equal(t, check.CategoryPerformance, meta.Category)
+	assert.Len(t, meta.Findings, 2)
+}
@@ -0,0 +1,12 @@
+-- name: TransactionRates :one
+-- Returns commit and rollback counters for the current database and how long
+-- they have been accumulating.
+-- stats_age_seconds is NULL when statistics have never been reset, since the
+-- counters then cover an unknown period.
+SELECT
+  xact_commit AS commits
+  , xact_rollback AS rollbacks
+  , stats_reset
+  , extract(EPOCH FROM (now() - stats_reset))::float8 AS stats_age_seconds
+FROM pg_stat_database
+WHERE datname = current_database();
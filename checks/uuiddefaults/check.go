@@ -5,6 +5,7 @@ import (
 	"context"
 	_ "embed"
 	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/fresha/pgdoctor/check"
@@ -27,12 +28,17 @@ type checker struct {
 
 func Metadata() check.Metadata {
 	return check.Metadata{
-		Category:    check.CategoryPerformance,
-		CheckID:     "uuid-defaults",
-		Name:        "UUID Default Value Analysis",
-		Description: "Detects UUID columns using random UUIDs (v4) as defaults which cause B-tree index bloat",
-		Readme:      readme,
-		SQL:         querySQL,
+		Category:       check.CategoryPerformance,
+		CheckID:        "uuid-defaults",
+		Name:           "UUID Default Value Analysis",
+		Description:    "Detects UUID columns using random UUIDs (v4) as defaults which cause B-tree index bloat",
+		Readme:         readme,
+		SQL:            querySQL,
+		RuntimeClass:   check.RuntimeMedium,
+		ProductionSafe: true,
+		Findings: []check.FindingSpec{
+			{ID: "random-uuid-indexed", Description: "Indexed columns defaulting to random UUIDs on large tables", Thresholds: "WARN > 100K rows"},
+		},
 	}
 }
 
@@ -48,11 +54,15 @@ func (c *checker) Metadata() check.Metadata {
 
 func (c *checker) Check(ctx context.Context) (*check.Report, error) {
 	report := check.NewReport(Metadata())
+	ignore := check.ObjectFilterFromContext(ctx)
 
 	rows, err := c.queries.UuidColumnDefaults(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to check UUID column defaults: %w", err)
 	}
+	rows = slices.DeleteFunc(rows, func(row db.UuidColumnDefaultsRow) bool {
+		return ignore.QualifiedTable(row.TableName.String)
+	})
 
 	var indexedRandomUUIDs []db.UuidColumnDefaultsRow
 
@@ -5,6 +5,7 @@ import (
 	"context"
 	_ "embed"
 	"fmt"
+	"slices"
 
 	"github.com/fresha/pgdoctor/check"
 	"github.com/fresha/pgdoctor/db"
@@ -26,12 +27,17 @@ type checker struct {
 
 func Metadata() check.Metadata {
 	return check.Metadata{
-		Category:    check.CategorySchema,
-		CheckID:     "uuid-types",
-		Name:        "UUID Type Validation",
-		Description: "Validates UUID columns use native uuid type instead of varchar/text",
-		Readme:      readme,
-		SQL:         querySQL,
+		Category:       check.CategorySchema,
+		CheckID:        "uuid-types",
+		Name:           "UUID Type Validation",
+		Description:    "Validates UUID columns use native uuid type instead of varchar/text",
+		Readme:         readme,
+		SQL:            querySQL,
+		RuntimeClass:   check.RuntimeMedium,
+		ProductionSafe: true,
+		Findings: []check.FindingSpec{
+			{ID: "uuid-types", Description: "UUID values stored in text or varchar columns", Thresholds: "FAIL"},
+		},
 	}
 }
 
@@ -47,11 +53,15 @@ func (c *checker) Metadata() check.Metadata {
 
 func (c *checker) Check(ctx context.Context) (*check.Report, error) {
 	report := check.NewReport(Metadata())
+	ignore := check.ObjectFilterFromContext(ctx)
 
 	rows, err := c.queries.UuidColumnsAsString(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to check UUID column types: %w", err)
 	}
+	rows = slices.DeleteFunc(rows, func(row db.UuidColumnsAsStringRow) bool {
+		return ignore.QualifiedTable(row.TableName)
+	})
 
 	if len(rows) == 0 {
 		report.AddFinding(check.Finding{
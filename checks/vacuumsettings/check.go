@@ -29,12 +29,24 @@ type checker struct {
 
 func Metadata() check.Metadata {
 	return check.Metadata{
-		Category:    check.CategoryVacuum,
-		CheckID:     "vacuum-settings",
-		Name:        "PostgreSQL Vacuum & Maintenance Configs",
-		Description: "Validates autovacuum, maintenance memory, and vacuum cost settings",
-		Readme:      readme,
-		SQL:         querySQL,
+		Category:       check.CategoryVacuum,
+		CheckID:        "vacuum-settings",
+		Name:           "PostgreSQL Vacuum & Maintenance Configs",
+		Description:    "Validates autovacuum, maintenance memory, and vacuum cost settings",
+		Readme:         readme,
+		SQL:            querySQL,
+		RuntimeClass:   check.RuntimeFast,
+		ProductionSafe: true,
+		Privileges:     []check.Privilege{check.PrivilegeReadAllStats},
+		Findings: []check.FindingSpec{
+			{ID: "autovacuum_vacuum_scale_factor", Description: "Fraction of a table that must be dead before autovacuum runs", Thresholds: "WARN > 0.2 or < 0.02"},
+			{ID: "autovacuum_analyze_scale_factor", Description: "Fraction of a table that must change before autoanalyze runs", Thresholds: "WARN > 0.1 or < 0.01"},
+			{ID: "autovacuum_max_workers", Description: "Number of concurrent autovacuum workers", Thresholds: "FAIL when 0; WARN when 1, unusually high, or 3 on large instances"},
+			{ID: "maintenance_work_mem", Description: "Memory available to each vacuum worker", Thresholds: "WARN < 32MB or > 4GB; WARN > 12.5%, FAIL > 25% of RAM across workers"},
+			{ID: "vacuum_cost_delay", Description: "Throttle delay between vacuum cost batches", Thresholds: "WARN > 20ms"},
+			{ID: "vacuum_cost_limit", Description: "Work allowed per vacuum cost batch", Thresholds: "WARN < 200 or > 10000"},
+			{ID: "work_mem", Description: "Memory available to each sort or hash operation", Thresholds: "WARN < 4MB"},
+		},
 	}
 }
 
@@ -0,0 +1,311 @@
+// Package walcompression implements checks for how much WAL full-page images
+// add and the settings that shrink them: wal_compression, full_page_writes
+// with checkpoint frequency, and wal_init_zero/wal_recycle for the storage.
+package walcompression
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"strings"
+
+	"github.com/fresha/pgdoctor/check"
+	"github.com/fresha/pgdoctor/db"
+)
+
+//go:embed query.sql
+var querySQL string
+
+//go:embed README.md
+var readme string
+
+const (
+	// Share of WAL bytes taken by full-page images above which compressing
+	// them is worth the CPU.
+	fpiCompressWarnPercent = 30.0
+	// Share of full-page image bytes wal_compression typically saves. FPIs
+	// are heap and index pages, which lz4 and pglz roughly halve.
+	compressionSavings = 0.5
+	// Too little WAL since the stats reset to judge the mix.
+	minWalBytes = int64(1 << 30)
+
+	// Share of WAL bytes taken by full-page images above which checkpoint
+	// frequency, rather than the changes themselves, drives WAL volume.
+	fpiCheckpointWarnPercent = 50.0
+	// Checkpoint interval the full-page image estimate is made against.
+	targetIntervalSeconds = int64(15 * 60)
+	// Too few checkpoints since the stats reset to judge the interval.
+	minCheckpoints = 10
+
+	// pg_stat_wal, with wal_fpi and wal_bytes, arrived in PostgreSQL 14.
+	pgStatWalMinVersion = 140000
+	// pg_stat_checkpointer replaced the checkpoint columns of pg_stat_bgwriter.
+	checkpointerMinVersion = 170000
+	// wal_compression accepts a method name (pglz, lz4, zstd) from PostgreSQL 15.
+	compressionMethodMinVersion = 150000
+)
+
+type WalCompressionQueries interface {
+	WalCompressionSettings(context.Context) (db.WalCompressionSettingsRow, error)
+	WalFpiStats(context.Context) (db.WalFpiStatsRow, error)
+	LegacyWalFpiStats(context.Context) (db.LegacyWalFpiStatsRow, error)
+}
+
+type checker struct {
+	queries WalCompressionQueries
+}
+
+func Metadata() check.Metadata {
+	return check.Metadata{
+		Category:       check.CategoryConfigs,
+		CheckID:        "wal-compression",
+		Name:           "WAL Compression",
+		Description:    "Checks how much WAL full-page images add and whether wal_compression, full_page_writes, wal_init_zero, and wal_recycle suit the workload and storage",
+		Readme:         readme,
+		SQL:            querySQL,
+		RuntimeClass:   check.RuntimeFast,
+		ProductionSafe: true,
+		Findings: []check.FindingSpec{
+			{ID: "wal-compression", Description: "wal_compression off while full-page images make up much of the WAL, with the estimated saving (PostgreSQL 14+, minimum 1GB of WAL)", Thresholds: "WARN when off and FPIs >= 30% of WAL"},
+			{ID: "full-page-writes", Description: "full_page_writes disabled, or full-page images dominating WAL because checkpoints run often (minimum 10 checkpoints)", Thresholds: "WARN when FPIs >= 50% of WAL and checkpoints < 15m apart, FAIL when off outside copy-on-write storage"},
+			{ID: "wal-init", Description: "wal_init_zero and wal_recycle for the storage type (requires instance metadata)", Thresholds: "WARN when on for copy-on-write storage (zfs, btrfs), or wal_recycle off elsewhere"},
+		},
+	}
+}
+
+func New(queries WalCompressionQueries, _ ...check.Config) check.Checker {
+	return &checker{
+		queries: queries,
+	}
+}
+
+func (c *checker) Metadata() check.Metadata {
+	return Metadata()
+}
+
+func (c *checker) Check(ctx context.Context) (*check.Report, error) {
+	report := check.NewReport(Metadata())
+
+	settings, err := c.queries.WalCompressionSettings(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("running %s/%s (settings): %w", report.Category, report.CheckID, err)
+	}
+
+	var stats *db.WalFpiStatsRow
+	if settings.ServerVersionNum >= pgStatWalMinVersion {
+		row, err := c.fetchStats(ctx, settings.ServerVersionNum)
+		if err != nil {
+			return nil, fmt.Errorf("running %s/%s (pg_stat_wal): %w", report.Category, report.CheckID, err)
+		}
+		stats = &row
+	}
+
+	storageType := ""
+	if meta := check.InstanceMetadataFromContext(ctx); meta != nil {
+		storageType = meta.StorageType
+	}
+
+	checkWalCompression(settings, stats, report)
+	checkFullPageWrites(settings, stats, storageType, report)
+	checkWalInit(settings, storageType, report)
+
+	return report, nil
+}
+
+// Reads checkpoint counters from pg_stat_checkpointer on PG17+ and from
+// pg_stat_bgwriter on older versions.
+func (c *checker) fetchStats(ctx context.Context, serverVersion int32) (db.WalFpiStatsRow, error) {
+	if serverVersion >= checkpointerMinVersion {
+		return c.queries.WalFpiStats(ctx)
+	}
+	row, err := c.queries.LegacyWalFpiStats(ctx)
+	return db.WalFpiStatsRow(row), err
+}
+
+// fpiBytes estimates the WAL taken by full-page images. Each image is at
+// most one block; the hole in the middle of a page is left out, so this is
+// an upper bound.
+func fpiBytes(stats *db.WalFpiStatsRow, blockSize int64) int64 {
+	return min(stats.WalFpi*blockSize, stats.WalBytes)
+}
+
+// perDay scales bytes written over seconds to a daily rate.
+func perDay(bytes, seconds int64) int64 {
+	if seconds <= 0 {
+		return 0
+	}
+	return int64(float64(bytes) / float64(seconds) * 86400)
+}
+
+func isCopyOnWrite(storageType string) bool {
+	switch strings.ToLower(storageType) {
+	case "zfs", "btrfs":
+		return true
+	default:
+		return false
+	}
+}
+
+func settingFix(name, value, description string) check.Fix {
+	return check.Fix{
+		Object:      name,
+		Description: description + ". Takes effect after SELECT pg_reload_conf(); on managed services, set it in the parameter group instead",
+		SQL:         fmt.Sprintf("ALTER SYSTEM SET %s = '%s'", name, value),
+		Risk:        check.RiskLow,
+		Lock:        check.LockOnline,
+	}
+}
+
+func checkWalCompression(settings db.WalCompressionSettingsRow, stats *db.WalFpiStatsRow, report *check.Report) {
+	finding := check.Finding{
+		ID:       "wal-compression",
+		Name:     "WAL Compression",
+		Severity: check.SeverityOK,
+	}
+
+	switch {
+	case settings.WalCompression != "off":
+		finding.Details = fmt.Sprintf("wal_compression is %s", settings.WalCompression)
+	case !settings.FullPageWrites:
+		finding.Details = "wal_compression is off, but so is full_page_writes: there are few full-page images to compress"
+	case stats == nil:
+		finding.Details = "wal_compression is off; measuring full-page images needs pg_stat_wal (PostgreSQL 14+)"
+	case stats.WalBytes < minWalBytes:
+		finding.Details = fmt.Sprintf("wal_compression is off; only %s of WAL since the stats reset, not enough to judge the full-page image share",
+			check.FormatBytes(stats.WalBytes))
+	default:
+		fpi := fpiBytes(stats, settings.BlockSize)
+		fpiPct := float64(fpi) / float64(stats.WalBytes) * 100
+		finding.Details = fmt.Sprintf("wal_compression is off; full-page images are up to %.0f%% of the %s of WAL written since the stats reset (%s images)",
+			fpiPct, check.FormatBytes(stats.WalBytes), check.FormatNumber(stats.WalFpi))
+		if fpiPct < fpiCompressWarnPercent {
+			break
+		}
+
+		saved := int64(float64(fpi) * compressionSavings)
+		finding.Severity = check.SeverityWarn
+		finding.Confidence = check.ConfidenceMedium
+		finding.Details += fmt.Sprintf(". Compressing them would save roughly %s (%.0f%% of WAL", check.FormatBytes(saved), float64(saved)/float64(stats.WalBytes)*100)
+		if daily := perDay(saved, stats.WalSecondsSinceReset); daily > 0 {
+			finding.Details += fmt.Sprintf(", about %s per day", check.FormatBytes(daily))
+		}
+		finding.Details += "), shrinking archives, replication traffic, and pg_wal churn for some CPU on each image"
+
+		method := "on"
+		if settings.ServerVersionNum >= compressionMethodMinVersion {
+			method = "lz4"
+		}
+		finding.Fixes = []check.Fix{settingFix("wal_compression", method,
+			fmt.Sprintf("Compress full-page images with %s. Applies to WAL written after the reload; watch CPU on write-heavy primaries", compressionMethodName(method)))}
+	}
+
+	report.AddFinding(finding)
+}
+
+func compressionMethodName(method string) string {
+	if method == "on" {
+		return "pglz"
+	}
+	return method
+}
+
+func checkFullPageWrites(settings db.WalCompressionSettingsRow, stats *db.WalFpiStatsRow, storageType string, report *check.Report) {
+	finding := check.Finding{
+		ID:       "full-page-writes",
+		Name:     "Full-Page Writes",
+		Severity: check.SeverityOK,
+	}
+
+	if !settings.FullPageWrites {
+		if isCopyOnWrite(storageType) {
+			finding.Details = fmt.Sprintf("full_page_writes is off on %s storage, which never leaves a partially written page after a crash", storageType)
+		} else {
+			finding.Severity = check.SeverityFail
+			finding.Details = "full_page_writes is off: a crash during a page write can leave a torn page that WAL replay cannot repair, silently corrupting data"
+			finding.Fixes = []check.Fix{settingFix("full_page_writes", "on",
+				"Write a full-page image after each checkpoint so crash recovery can repair torn pages. WAL volume grows; wal_compression offsets it")}
+		}
+		report.AddFinding(finding)
+		return
+	}
+
+	total := int64(0)
+	if stats != nil {
+		total = stats.CheckpointsTimed + stats.CheckpointsRequested
+	}
+	switch {
+	case stats == nil:
+		finding.Details = "full_page_writes is on; measuring full-page images needs pg_stat_wal (PostgreSQL 14+)"
+	case stats.WalBytes < minWalBytes || total < minCheckpoints || stats.CheckpointSecondsSinceReset <= 0:
+		finding.Details = fmt.Sprintf("full_page_writes is on; %s of WAL and %d checkpoint(s) since the stats reset, not enough to relate full-page images to checkpoints",
+			check.FormatBytes(stats.WalBytes), total)
+	default:
+		fpi := fpiBytes(stats, settings.BlockSize)
+		fpiPct := float64(fpi) / float64(stats.WalBytes) * 100
+		interval := stats.CheckpointSecondsSinceReset / total
+		finding.Details = fmt.Sprintf("full_page_writes is on; full-page images are up to %.0f%% of WAL with one checkpoint every %s (checkpoint_timeout %s)",
+			fpiPct, check.FormatDurationSec(interval), check.FormatDurationSec(settings.CheckpointTimeoutSeconds))
+		if fpiPct < fpiCheckpointWarnPercent || interval >= targetIntervalSeconds {
+			break
+		}
+
+		// A page changed in every cycle logs one image per checkpoint, so
+		// image volume falls in proportion to the interval. Pages changed
+		// less often gain less, which makes this an upper bound.
+		reduction := 1 - float64(interval)/float64(targetIntervalSeconds)
+		finding.Severity = check.SeverityWarn
+		finding.Confidence = check.ConfidenceMedium
+		finding.Details += fmt.Sprintf(". After each checkpoint the first change to a page logs the whole page again; checkpoints every %s would cut image volume by up to %.0f%% (%s",
+			check.FormatDurationSec(targetIntervalSeconds), reduction*100, check.FormatBytes(int64(float64(fpi)*reduction)))
+		if daily := perDay(int64(float64(fpi)*reduction), stats.WalSecondsSinceReset); daily > 0 {
+			finding.Details += fmt.Sprintf(", about %s per day", check.FormatBytes(daily))
+		}
+		finding.Details += "). See checkpoint-health for the checkpoint_timeout or max_wal_size change"
+	}
+
+	report.AddFinding(finding)
+}
+
+func checkWalInit(settings db.WalCompressionSettingsRow, storageType string, report *check.Report) {
+	finding := check.Finding{
+		ID:       "wal-init",
+		Name:     "WAL Segment Initialization",
+		Severity: check.SeverityOK,
+	}
+	current := fmt.Sprintf("wal_init_zero is %s and wal_recycle is %s", onOff(settings.WalInitZero), onOff(settings.WalRecycle))
+
+	switch {
+	case storageType == "":
+		finding.Details = current + "; storage type is unknown, so they were not compared with the filesystem"
+	case isCopyOnWrite(storageType):
+		finding.Details = fmt.Sprintf("%s on %s storage", current, storageType)
+		if !settings.WalInitZero && !settings.WalRecycle {
+			break
+		}
+		finding.Severity = check.SeverityWarn
+		finding.Details += ". A copy-on-write filesystem writes new blocks anyway, so zero-filling a segment writes it twice and a recycled segment is not reused in place; both only add I/O"
+		if settings.WalInitZero {
+			finding.Fixes = append(finding.Fixes, settingFix("wal_init_zero", "off", "Create new WAL segments without zero-filling them"))
+		}
+		if settings.WalRecycle {
+			finding.Fixes = append(finding.Fixes, settingFix("wal_recycle", "off", "Remove old WAL segments instead of renaming them for reuse"))
+		}
+	default:
+		finding.Details = fmt.Sprintf("%s on %s storage", current, storageType)
+		if settings.WalRecycle {
+			break
+		}
+		finding.Severity = check.SeverityWarn
+		finding.Details += ". On storage that overwrites in place, turning wal_recycle off makes every checkpoint delete segments and later writes create and allocate new ones"
+		finding.Fixes = []check.Fix{settingFix("wal_recycle", "on", "Rename old WAL segments for reuse instead of removing them")}
+	}
+
+	report.AddFinding(finding)
+}
+
+func onOff(b bool) string {
+	if b {
+		return "on"
+	}
+	return "off"
+}
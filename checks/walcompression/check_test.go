@@ -0,0 +1,245 @@
+package walcompression_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/fresha/pgdoctor/check"
+	"github.com/fresha/pgdoctor/check/checktest"
+	"github.com/fresha/pgdoctor/checks/walcompression"
+	"github.com/fresha/pgdoctor/db"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const gb = int64(1 << 30)
+
+type mockQueryer struct {
+	settings db.WalCompressionSettingsRow
+	stats    db.WalFpiStatsRow
+	legacy   db.LegacyWalFpiStatsRow
+	err      error
+
+	statsCalled  bool
+	legacyCalled bool
+}
+
+func (m *mockQueryer) WalCompressionSettings(context.Context) (db.WalCompressionSettingsRow, error) {
+	return m.settings, m.err
+}
+
+func (m *mockQueryer) WalFpiStats(context.Context) (db.WalFpiStatsRow, error) {
+	m.statsCalled = true
+	return m.stats, nil
+}
+
+func (m *mockQueryer) LegacyWalFpiStats(context.Context) (db.LegacyWalFpiStatsRow, error) {
+	m.legacyCalled = true
+	return m.legacy, nil
+}
+
+// healthyQueryer has compression on, checkpoints every 30 minutes, and
+// full-page images at 40% of 100GB of WAL written over 10 days.
+func healthyQueryer() *mockQueryer {
+	return &mockQueryer{
+		settings: db.WalCompressionSettingsRow{
+			ServerVersionNum:         170002,
+			WalCompression:           "lz4",
+			FullPageWrites:           true,
+			WalInitZero:              true,
+			WalRecycle:               true,
+			BlockSize:                8192,
+			CheckpointTimeoutSeconds: 1800,
+		},
+		stats: db.WalFpiStatsRow{
+			WalFpi:                      40 * gb / 8192,
+			WalBytes:                    100 * gb,
+			WalSecondsSinceReset:        10 * 86400,
+			CheckpointsTimed:            480,
+			CheckpointSecondsSinceReset: 10 * 86400,
+		},
+	}
+}
+
+func withStorage(storageType string) context.Context {
+	return check.ContextWithInstanceMetadata(context.Background(), &check.InstanceMetadata{StorageType: storageType})
+}
+
+func TestWalCompression_Healthy(t *testing.T) {
+	t.Parallel()
+
+	report, err := walcompression.New(healthyQueryer()).Check(withStorage("gp3"))
+	require.NoError(t, err)
+
+	assert.Equal(t, check.SeverityOK, report.Severity)
+	assert.Len(t, report.Results, 3)
+	assert.Contains(t, checktest.Finding(t, report, "wal-compression").Details, "wal_compression is lz4")
+	assert.Contains(t, checktest.Finding(t, report, "full-page-writes").Details, "one checkpoint every 30m")
+}
+
+func TestWalCompression_Off(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		version  int32
+		fpiGB    int64
+		expected check.Severity
+		fixSQL   string
+	}{
+		{name: "PG17 suggests lz4", version: 170002, fpiGB: 40, expected: check.SeverityWarn, fixSQL: "ALTER SYSTEM SET wal_compression = 'lz4'"},
+		{name: "PG14 has no methods", version: 140010, fpiGB: 40, expected: check.SeverityWarn, fixSQL: "ALTER SYSTEM SET wal_compression = 'on'"},
+		{name: "few full-page images", version: 170002, fpiGB: 10, expected: check.SeverityOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			q := healthyQueryer()
+			q.settings.WalCompression = "off"
+			q.settings.ServerVersionNum = tt.version
+			q.stats.WalFpi = tt.fpiGB * gb / 8192
+			q.legacy = db.LegacyWalFpiStatsRow(q.stats)
+
+			report, err := walcompression.New(q).Check(context.Background())
+			require.NoError(t, err)
+
+			f := checktest.Finding(t, report, "wal-compression")
+			assert.Equal(t, tt.expected, f.Severity)
+			if tt.fixSQL == "" {
+				assert.Empty(t, f.Fixes)
+				return
+			}
+			assert.Equal(t, check.ConfidenceMedium, f.Confidence)
+			assert.Contains(t, f.Details, "save roughly 20.0GiB (20% of WAL, about 2.0GiB per day)")
+			require.Len(t, f.Fixes, 1)
+			assert.Equal(t, tt.fixSQL, f.Fixes[0].SQL)
+			assert.False(t, f.Fixes[0].NeedsMaintenanceWindow())
+		})
+	}
+}
+
+func TestWalCompression_PG13SkipsStats(t *testing.T) {
+	t.Parallel()
+
+	q := healthyQueryer()
+	q.settings.ServerVersionNum = 130015
+	q.settings.WalCompression = "off"
+
+	report, err := walcompression.New(q).Check(context.Background())
+	require.NoError(t, err)
+
+	assert.False(t, q.statsCalled)
+	assert.False(t, q.legacyCalled)
+	assert.Equal(t, check.SeverityOK, report.Severity)
+	assert.Contains(t, checktest.Finding(t, report, "wal-compression").Details, "PostgreSQL 14+")
+}
+
+func TestWalCompression_PG16UsesBgwriter(t *testing.T) {
+	t.Parallel()
+
+	q := healthyQueryer()
+	q.settings.ServerVersionNum = 160004
+	q.legacy = db.LegacyWalFpiStatsRow(q.stats)
+
+	_, err := walcompression.New(q).Check(context.Background())
+	require.NoError(t, err)
+
+	assert.True(t, q.legacyCalled)
+	assert.False(t, q.statsCalled)
+}
+
+func TestWalCompression_FrequentCheckpoints(t *testing.T) {
+	t.Parallel()
+
+	q := healthyQueryer()
+	q.stats.WalFpi = 60 * gb / 8192
+	q.stats.CheckpointsTimed = 2880 // every 5 minutes
+
+	report, err := walcompression.New(q).Check(context.Background())
+	require.NoError(t, err)
+
+	f := checktest.Finding(t, report, "full-page-writes")
+	assert.Equal(t, check.SeverityWarn, f.Severity)
+	assert.Contains(t, f.Details, "cut image volume by up to 67% (40.0GiB, about 4.0GiB per day)")
+	assert.Contains(t, f.Details, "checkpoint-health")
+}
+
+func TestWalCompression_FullPageWritesOff(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		storage  string
+		expected check.Severity
+	}{
+		{name: "ssd", storage: "ssd", expected: check.SeverityFail},
+		{name: "unknown storage", storage: "", expected: check.SeverityFail},
+		{name: "zfs", storage: "zfs", expected: check.SeverityOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			q := healthyQueryer()
+			q.settings.FullPageWrites = false
+
+			report, err := walcompression.New(q).Check(withStorage(tt.storage))
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.expected, checktest.Finding(t, report, "full-page-writes").Severity)
+			assert.Equal(t, check.SeverityOK, checktest.Finding(t, report, "wal-compression").Severity)
+		})
+	}
+}
+
+func TestWalCompression_WalInit(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		storage    string
+		initZero   bool
+		recycle    bool
+		expected   check.Severity
+		fixObjects []string
+	}{
+		{name: "defaults on gp3", storage: "gp3", initZero: true, recycle: true, expected: check.SeverityOK},
+		{name: "unknown storage", storage: "", initZero: false, recycle: false, expected: check.SeverityOK},
+		{name: "defaults on zfs", storage: "ZFS", initZero: true, recycle: true, expected: check.SeverityWarn, fixObjects: []string{"wal_init_zero", "wal_recycle"}},
+		{name: "tuned zfs", storage: "zfs", initZero: false, recycle: false, expected: check.SeverityOK},
+		{name: "recycle off on ssd", storage: "ssd", initZero: true, recycle: false, expected: check.SeverityWarn, fixObjects: []string{"wal_recycle"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			q := healthyQueryer()
+			q.settings.WalInitZero = tt.initZero
+			q.settings.WalRecycle = tt.recycle
+
+			report, err := walcompression.New(q).Check(withStorage(tt.storage))
+			require.NoError(t, err)
+
+			f := checktest.Finding(t, report, "wal-init")
+			assert.Equal(t, tt.expected, f.Severity)
+			var objects []string
+			for _, fix := range f.Fixes {
+				objects = append(objects, fix.Object)
+			}
+			assert.Equal(t, tt.fixObjects, objects)
+		})
+	}
+}
+
+func TestWalCompression_QueryError(t *testing.T) {
+	t.Parallel()
+
+	_, err := walcompression.New(&mockQueryer{err: errors.New("connection refused")}).Check(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "wal-compression")
+}
@@ -0,0 +1,39 @@
+-- name: WalCompressionSettings :one
+-- Settings that decide how large each full-page image is and how WAL
+-- segments are created, with the server version to pick the statistics views.
+SELECT
+  CURRENT_SETTING('server_version_num')::integer AS server_version_num
+  , CURRENT_SETTING('wal_compression') AS wal_compression
+  , CURRENT_SETTING('full_page_writes')::boolean AS full_page_writes
+  , CURRENT_SETTING('wal_init_zero')::boolean AS wal_init_zero
+  , CURRENT_SETTING('wal_recycle')::boolean AS wal_recycle
+  , CURRENT_SETTING('block_size')::bigint AS block_size
+  , (SELECT setting::bigint FROM pg_settings WHERE name = 'checkpoint_timeout') AS checkpoint_timeout_seconds;
+
+-- name: WalFpiStats :one
+-- WAL volume and full-page images from pg_stat_wal with checkpoint counters
+-- from pg_stat_checkpointer (PostgreSQL 17+).
+SELECT
+  w.wal_records::bigint AS wal_records
+  , w.wal_fpi::bigint AS wal_fpi
+  , w.wal_bytes::bigint AS wal_bytes
+  , COALESCE(EXTRACT(EPOCH FROM (NOW() - w.stats_reset)), 0)::bigint AS wal_seconds_since_reset
+  , c.num_timed::bigint AS checkpoints_timed
+  , c.num_requested::bigint AS checkpoints_requested
+  , COALESCE(EXTRACT(EPOCH FROM (NOW() - c.stats_reset)), 0)::bigint AS checkpoint_seconds_since_reset
+FROM pg_stat_wal AS w
+CROSS JOIN pg_stat_checkpointer AS c;
+
+-- name: LegacyWalFpiStats :one
+-- WAL volume and full-page images from pg_stat_wal with checkpoint counters
+-- from pg_stat_bgwriter (PostgreSQL 14 to 16).
+SELECT
+  w.wal_records::bigint AS wal_records
+  , w.wal_fpi::bigint AS wal_fpi
+  , w.wal_bytes::bigint AS wal_bytes
+  , COALESCE(EXTRACT(EPOCH FROM (NOW() - w.stats_reset)), 0)::bigint AS wal_seconds_since_reset
+  , b.checkpoints_timed::bigint AS checkpoints_timed
+  , b.checkpoints_req::bigint AS checkpoints_requested
+  , COALESCE(EXTRACT(EPOCH FROM (NOW() - b.stats_reset)), 0)::bigint AS checkpoint_seconds_since_reset
+FROM pg_stat_wal AS w
+CROSS JOIN pg_stat_bgwriter AS b;
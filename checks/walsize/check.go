@@ -0,0 +1,265 @@
+// Package walsize implements checks for pg_wal growth and max_wal_size/min_wal_size fit.
+package walsize
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+
+	"github.com/fresha/pgdoctor/check"
+	"github.com/fresha/pgdoctor/db"
+)
+
+//go:embed query.sql
+var querySQL string
+
+//go:embed README.md
+var readme string
+
+const (
+	// pg_wal normally stays below max_wal_size; anything well beyond it is
+	// being retained by slots, wal_keep_size, or a failing archiver.
+	walDirWarnFactor = 2.0
+	// Share of allocated storage pg_wal may occupy before the disk is at risk.
+	walDirFailStoragePercent = 25.0
+
+	// Share of checkpoints triggered by WAL volume rather than checkpoint_timeout.
+	requestedWarnPercent = 20.0
+	requestedFailPercent = 50.0
+	// Too few checkpoints since the stats reset to judge the ratio.
+	minCheckpoints = 10
+
+	// Share of allocated storage max_wal_size may claim between checkpoints.
+	maxWalWarnStoragePercent = 10.0
+	maxWalFailStoragePercent = 25.0
+
+	// pg_stat_checkpointer replaced the checkpoint columns of pg_stat_bgwriter.
+	checkpointerMinVersion = 170000
+)
+
+type WalSizeQueries interface {
+	WalSettings(context.Context) (db.WalSettingsRow, error)
+	WalDirStats(context.Context) (db.WalDirStatsRow, error)
+	CheckpointerStats(context.Context) (db.CheckpointerStatsRow, error)
+	BgwriterCheckpointStats(context.Context) (db.BgwriterCheckpointStatsRow, error)
+}
+
+type checker struct {
+	queries WalSizeQueries
+}
+
+func Metadata() check.Metadata {
+	return check.Metadata{
+		Category:       check.CategoryConfigs,
+		CheckID:        "wal-size",
+		Name:           "WAL Size",
+		Description:    "Checks pg_wal growth and whether max_wal_size fits the workload and the disk",
+		Readme:         readme,
+		SQL:            querySQL,
+		RuntimeClass:   check.RuntimeFast,
+		ProductionSafe: true,
+		Privileges:     []check.Privilege{check.PrivilegeMonitor},
+		Findings: []check.FindingSpec{
+			{ID: "wal-dir-size", Description: "Size of pg_wal relative to max_wal_size and allocated storage (LSN estimate without pg_monitor)", Thresholds: "WARN > 2x max_wal_size, FAIL >= 25% of storage"},
+			{ID: "checkpoint-frequency", Description: "Share of checkpoints forced by WAL volume instead of checkpoint_timeout (minimum 10 checkpoints)", Thresholds: "WARN >= 20%, FAIL >= 50%"},
+			{ID: "max-wal-size-headroom", Description: "max_wal_size relative to allocated storage (requires instance metadata)", Thresholds: "WARN >= 10%, FAIL >= 25% of storage"},
+			{ID: "segment-recycling", Description: "Segments kept for reuse versus removed after checkpoints (requires pg_monitor)"},
+		},
+	}
+}
+
+func New(queries WalSizeQueries, _ ...check.Config) check.Checker {
+	return &checker{
+		queries: queries,
+	}
+}
+
+func (c *checker) Metadata() check.Metadata {
+	return Metadata()
+}
+
+func (c *checker) Check(ctx context.Context) (*check.Report, error) {
+	report := check.NewReport(Metadata())
+
+	settings, err := c.queries.WalSettings(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("running %s/%s (settings): %w", check.CategoryConfigs, report.CheckID, err)
+	}
+
+	var dir *db.WalDirStatsRow
+	if settings.CanListWalDir {
+		stats, err := c.queries.WalDirStats(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("running %s/%s (pg_wal): %w", check.CategoryConfigs, report.CheckID, err)
+		}
+		dir = &stats
+	}
+
+	checkpoints, err := c.fetchCheckpointStats(ctx, settings.ServerVersionNum)
+	if err != nil {
+		return nil, fmt.Errorf("running %s/%s (checkpoints): %w", check.CategoryConfigs, report.CheckID, err)
+	}
+
+	storageGB := 0
+	if meta := check.InstanceMetadataFromContext(ctx); meta != nil {
+		storageGB = meta.StorageGB
+	}
+
+	checkWalDirSize(settings, dir, storageGB, report)
+	checkCheckpointFrequency(settings, checkpoints, report)
+	checkMaxWalSizeHeadroom(settings, storageGB, report)
+	checkSegmentRecycling(settings, dir, report)
+
+	return report, nil
+}
+
+// Reads checkpoint counters from pg_stat_checkpointer on PG17+ and from
+// pg_stat_bgwriter on older versions.
+func (c *checker) fetchCheckpointStats(ctx context.Context, serverVersion int32) (db.CheckpointerStatsRow, error) {
+	if serverVersion >= checkpointerMinVersion {
+		return c.queries.CheckpointerStats(ctx)
+	}
+	row, err := c.queries.BgwriterCheckpointStats(ctx)
+	return db.CheckpointerStatsRow(row), err
+}
+
+func storagePercent(bytes int64, storageGB int) float64 {
+	return float64(bytes) / (float64(storageGB) * 1024 * 1024 * 1024) * 100
+}
+
+func checkWalDirSize(settings db.WalSettingsRow, dir *db.WalDirStatsRow, storageGB int, report *check.Report) {
+	var size int64
+	var details string
+	if dir != nil {
+		size = dir.TotalBytes
+		details = fmt.Sprintf("pg_wal is %s (max_wal_size %s)",
+			check.FormatBytes(size), check.FormatBytes(settings.MaxWalSizeBytes))
+	} else {
+		// Without pg_ls_waldir, the WAL a slot pins is a lower bound on pg_wal.
+		size = max(settings.SlotRetainedBytes, settings.WalKeepSizeBytes)
+		details = fmt.Sprintf("pg_wal holds at least %s retained by replication slots or wal_keep_size (max_wal_size %s). Grant pg_monitor to measure pg_wal directly",
+			check.FormatBytes(size), check.FormatBytes(settings.MaxWalSizeBytes))
+	}
+
+	severity := check.SeverityOK
+	if float64(size) > walDirWarnFactor*float64(settings.MaxWalSizeBytes) {
+		severity = check.SeverityWarn
+		details += fmt.Sprintf(". WAL beyond %.0fx max_wal_size is being retained: check inactive replication slots, archive_command failures, and wal_keep_size",
+			walDirWarnFactor)
+	}
+	if storageGB > 0 {
+		pct := storagePercent(size, storageGB)
+		details += fmt.Sprintf(" (%.1f%% of %dGB storage)", pct, storageGB)
+		if pct >= walDirFailStoragePercent {
+			severity = check.SeverityFail
+		}
+	}
+
+	report.AddFinding(check.Finding{
+		ID:       "wal-dir-size",
+		Name:     "WAL Directory Size",
+		Severity: severity,
+		Details:  details,
+	})
+}
+
+func checkCheckpointFrequency(settings db.WalSettingsRow, stats db.CheckpointerStatsRow, report *check.Report) {
+	total := stats.CheckpointsTimed + stats.CheckpointsRequested
+	if total < minCheckpoints {
+		report.AddFinding(check.Finding{
+			ID:       "checkpoint-frequency",
+			Name:     "Checkpoint Frequency",
+			Severity: check.SeverityOK,
+			Details:  fmt.Sprintf("Only %d checkpoint(s) since the stats reset; not enough to judge max_wal_size", total),
+		})
+		return
+	}
+
+	requestedPct := float64(stats.CheckpointsRequested) / float64(total) * 100
+	details := fmt.Sprintf("%.0f%% of %d checkpoints were forced by WAL volume", requestedPct, total)
+	if stats.SecondsSinceReset > 0 {
+		details += fmt.Sprintf(", one every %s on average", check.FormatDurationSec(stats.SecondsSinceReset/total))
+	}
+
+	severity := check.SeverityOK
+	if requestedPct >= requestedFailPercent {
+		severity = check.SeverityFail
+	} else if requestedPct >= requestedWarnPercent {
+		severity = check.SeverityWarn
+	}
+	if severity > check.SeverityOK {
+		details += fmt.Sprintf(". max_wal_size (%s) is too small for the write rate; frequent checkpoints multiply full-page writes and I/O",
+			check.FormatBytes(settings.MaxWalSizeBytes))
+	}
+
+	report.AddFinding(check.Finding{
+		ID:       "checkpoint-frequency",
+		Name:     "Checkpoint Frequency",
+		Severity: severity,
+		Details:  details,
+	})
+}
+
+func checkMaxWalSizeHeadroom(settings db.WalSettingsRow, storageGB int, report *check.Report) {
+	if storageGB <= 0 {
+		report.AddFinding(check.Finding{
+			ID:       "max-wal-size-headroom",
+			Name:     "max_wal_size Disk Headroom",
+			Severity: check.SeverityOK,
+			Details:  fmt.Sprintf("max_wal_size is %s; allocated storage is unknown, so disk headroom was not evaluated", check.FormatBytes(settings.MaxWalSizeBytes)),
+		})
+		return
+	}
+
+	pct := storagePercent(settings.MaxWalSizeBytes, storageGB)
+	severity := check.SeverityOK
+	if pct >= maxWalFailStoragePercent {
+		severity = check.SeverityFail
+	} else if pct >= maxWalWarnStoragePercent {
+		severity = check.SeverityWarn
+	}
+
+	details := fmt.Sprintf("max_wal_size is %s, %.1f%% of %dGB storage", check.FormatBytes(settings.MaxWalSizeBytes), pct, storageGB)
+	if severity > check.SeverityOK {
+		details += ". A burst of writes can fill this much pg_wal between checkpoints, on top of WAL retained by slots"
+	}
+
+	report.AddFinding(check.Finding{
+		ID:       "max-wal-size-headroom",
+		Name:     "max_wal_size Disk Headroom",
+		Severity: severity,
+		Details:  details,
+	})
+}
+
+func checkSegmentRecycling(settings db.WalSettingsRow, dir *db.WalDirStatsRow, report *check.Report) {
+	if dir == nil {
+		report.AddFinding(check.Finding{
+			ID:       "segment-recycling",
+			Name:     "WAL Segment Recycling",
+			Severity: check.SeverityOK,
+			Details:  "Segment counts need pg_ls_waldir(), which requires pg_monitor",
+		})
+		return
+	}
+
+	var details string
+	switch {
+	case !settings.WalRecycle:
+		details = fmt.Sprintf("wal_recycle is off: %d segment(s) in pg_wal, old segments are removed and new ones created (expected on copy-on-write filesystems)",
+			dir.SegmentCount)
+	case settings.InRecovery:
+		details = fmt.Sprintf("%d segment(s) in pg_wal; recycled segments are not counted on a standby", dir.SegmentCount)
+	default:
+		details = fmt.Sprintf("%d of %d segment(s) in pg_wal are recycled for reuse (%s); min_wal_size %s keeps at least %d segment(s) after each checkpoint",
+			dir.RecycledCount, dir.SegmentCount, check.FormatBytes(dir.RecycledCount*settings.WalSegmentSizeBytes),
+			check.FormatBytes(settings.MinWalSizeBytes), settings.MinWalSizeBytes/max(settings.WalSegmentSizeBytes, 1))
+	}
+
+	report.AddFinding(check.Finding{
+		ID:       "segment-recycling",
+		Name:     "WAL Segment Recycling",
+		Severity: check.SeverityOK,
+		Details:  details,
+	})
+}
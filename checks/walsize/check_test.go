@@ -0,0 +1,172 @@
+package walsize_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/fresha/pgdoctor/check"
+	"github.com/fresha/pgdoctor/check/checktest"
+	"github.com/fresha/pgdoctor/checks/walsize"
+	"github.com/fresha/pgdoctor/db"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	mb = int64(1024 * 1024)
+	gb = 1024 * mb
+)
+
+type mockQueryer struct {
+	settings     db.WalSettingsRow
+	dir          db.WalDirStatsRow
+	checkpointer db.CheckpointerStatsRow
+	bgwriter     db.BgwriterCheckpointStatsRow
+	err          error
+
+	dirCalled      bool
+	bgwriterCalled bool
+}
+
+func (m *mockQueryer) WalSettings(context.Context) (db.WalSettingsRow, error) {
+	return m.settings, m.err
+}
+
+func (m *mockQueryer) WalDirStats(context.Context) (db.WalDirStatsRow, error) {
+	m.dirCalled = true
+	return m.dir, nil
+}
+
+func (m *mockQueryer) CheckpointerStats(context.Context) (db.CheckpointerStatsRow, error) {
+	return m.checkpointer, nil
+}
+
+func (m *mockQueryer) BgwriterCheckpointStats(context.Context) (db.BgwriterCheckpointStatsRow, error) {
+	m.bgwriterCalled = true
+	return m.bgwriter, nil
+}
+
+func healthyQueryer() *mockQueryer {
+	return &mockQueryer{
+		settings: db.WalSettingsRow{
+			ServerVersionNum:    170002,
+			MaxWalSizeBytes:     gb,
+			MinWalSizeBytes:     80 * mb,
+			WalSegmentSizeBytes: 16 * mb,
+			WalRecycle:          true,
+			CanListWalDir:       true,
+		},
+		dir:          db.WalDirStatsRow{TotalBytes: 512 * mb, SegmentCount: 32, RecycledCount: 5},
+		checkpointer: db.CheckpointerStatsRow{CheckpointsTimed: 95, CheckpointsRequested: 5, SecondsSinceReset: 30000},
+	}
+}
+
+func TestWalSize_Healthy(t *testing.T) {
+	t.Parallel()
+
+	report, err := walsize.New(healthyQueryer()).Check(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, check.SeverityOK, report.Severity)
+	assert.Len(t, report.Results, 4)
+	assert.Contains(t, checktest.Finding(t, report, "segment-recycling").Details, "5 of 32 segment(s)")
+	assert.Contains(t, checktest.Finding(t, report, "max-wal-size-headroom").Details, "storage is unknown")
+}
+
+func TestWalSize_RetainedWAL(t *testing.T) {
+	t.Parallel()
+
+	q := healthyQueryer()
+	q.dir.TotalBytes = 3 * gb
+
+	report, err := walsize.New(q).Check(context.Background())
+	require.NoError(t, err)
+
+	f := checktest.Finding(t, report, "wal-dir-size")
+	assert.Equal(t, check.SeverityWarn, f.Severity)
+	assert.Contains(t, f.Details, "replication slots")
+}
+
+func TestWalSize_StorageThresholds(t *testing.T) {
+	t.Parallel()
+
+	q := healthyQueryer()
+	q.settings.MaxWalSizeBytes = 16 * gb
+	q.dir.TotalBytes = 30 * gb
+
+	ctx := check.ContextWithInstanceMetadata(context.Background(), &check.InstanceMetadata{StorageGB: 100})
+	report, err := walsize.New(q).Check(ctx)
+	require.NoError(t, err)
+
+	assert.Equal(t, check.SeverityFail, checktest.Finding(t, report, "wal-dir-size").Severity)
+	assert.Equal(t, check.SeverityWarn, checktest.Finding(t, report, "max-wal-size-headroom").Severity)
+}
+
+func TestWalSize_CheckpointFrequency(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		timed     int64
+		requested int64
+		expected  check.Severity
+	}{
+		{name: "mostly timed", timed: 90, requested: 10, expected: check.SeverityOK},
+		{name: "warn at 20%", timed: 80, requested: 20, expected: check.SeverityWarn},
+		{name: "fail at 50%", timed: 50, requested: 50, expected: check.SeverityFail},
+		{name: "too few checkpoints", timed: 1, requested: 5, expected: check.SeverityOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			q := healthyQueryer()
+			q.checkpointer = db.CheckpointerStatsRow{CheckpointsTimed: tt.timed, CheckpointsRequested: tt.requested, SecondsSinceReset: 3600}
+
+			report, err := walsize.New(q).Check(context.Background())
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, checktest.Finding(t, report, "checkpoint-frequency").Severity)
+		})
+	}
+}
+
+func TestWalSize_PG16UsesBgwriter(t *testing.T) {
+	t.Parallel()
+
+	q := healthyQueryer()
+	q.settings.ServerVersionNum = 160004
+	q.bgwriter = db.BgwriterCheckpointStatsRow{CheckpointsTimed: 40, CheckpointsRequested: 60}
+
+	report, err := walsize.New(q).Check(context.Background())
+	require.NoError(t, err)
+
+	assert.True(t, q.bgwriterCalled)
+	assert.Equal(t, check.SeverityFail, checktest.Finding(t, report, "checkpoint-frequency").Severity)
+}
+
+func TestWalSize_WithoutPgMonitor(t *testing.T) {
+	t.Parallel()
+
+	q := healthyQueryer()
+	q.settings.CanListWalDir = false
+	q.settings.SlotRetainedBytes = 5 * gb
+
+	report, err := walsize.New(q).Check(context.Background())
+	require.NoError(t, err)
+
+	assert.False(t, q.dirCalled)
+	f := checktest.Finding(t, report, "wal-dir-size")
+	assert.Equal(t, check.SeverityWarn, f.Severity)
+	assert.Contains(t, f.Details, "at least 5.0GiB")
+	assert.Contains(t, checktest.Finding(t, report, "segment-recycling").Details, "pg_monitor")
+}
+
+func TestWalSize_QueryError(t *testing.T) {
+	t.Parallel()
+
+	_, err := walsize.New(&mockQueryer{err: errors.New("connection refused")}).Check(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "wal-size")
+}
@@ -0,0 +1,55 @@
+-- name: WalSettings :one
+-- WAL sizing settings and the WAL retained by replication slots, from LSN math.
+SELECT
+  current_setting('server_version_num')::integer AS server_version_num
+  , pg_size_bytes(current_setting('max_wal_size'))::bigint AS max_wal_size_bytes
+  , pg_size_bytes(current_setting('min_wal_size'))::bigint AS min_wal_size_bytes
+  , pg_size_bytes(current_setting('wal_segment_size'))::bigint AS wal_segment_size_bytes
+  , pg_size_bytes(current_setting('wal_keep_size'))::bigint AS wal_keep_size_bytes
+  , current_setting('wal_recycle')::boolean AS wal_recycle
+  , pg_is_in_recovery()::boolean AS in_recovery
+  -- pg_ls_waldir() is restricted to superusers and pg_monitor members
+  , (current_setting('is_superuser')::boolean OR pg_has_role('pg_monitor', 'MEMBER'))::boolean AS can_list_wal_dir
+  , COALESCE((
+    SELECT
+      MAX(pg_wal_lsn_diff(
+        CASE WHEN pg_is_in_recovery() THEN pg_last_wal_replay_lsn() ELSE pg_current_wal_lsn() END
+        , restart_lsn
+      ))
+    FROM pg_replication_slots
+    WHERE restart_lsn IS NOT NULL
+  ), 0)::bigint AS slot_retained_bytes;
+
+-- name: WalDirStats :one
+-- Size of pg_wal and its segments. Segments named after the current insert
+-- segment have been recycled (renamed for reuse) rather than removed.
+WITH current_segment AS (
+  SELECT
+    CASE
+      WHEN pg_is_in_recovery() THEN NULL
+      ELSE pg_walfile_name(pg_current_wal_lsn())
+    END AS name
+)
+
+SELECT
+  COALESCE(SUM(w.size), 0)::bigint AS total_bytes
+  , COUNT(*) FILTER (WHERE w.name ~ '^[0-9A-F]{24}$')::bigint AS segment_count
+  , COUNT(*) FILTER (WHERE w.name ~ '^[0-9A-F]{24}$' AND w.name > cs.name)::bigint AS recycled_count
+FROM pg_ls_waldir() AS w
+CROSS JOIN current_segment AS cs;
+
+-- name: CheckpointerStats :one
+-- Checkpoint counters from pg_stat_checkpointer (PostgreSQL 17+).
+SELECT
+  num_timed::bigint AS checkpoints_timed
+  , num_requested::bigint AS checkpoints_requested
+  , COALESCE(EXTRACT(EPOCH FROM (NOW() - stats_reset)), 0)::bigint AS seconds_since_reset
+FROM pg_stat_checkpointer;
+
+-- name: BgwriterCheckpointStats :one
+-- Checkpoint counters from pg_stat_bgwriter (before PostgreSQL 17).
+SELECT
+  checkpoints_timed::bigint AS checkpoints_timed
+  , checkpoints_req::bigint AS checkpoints_requested
+  , COALESCE(EXTRACT(EPOCH FROM (NOW() - stats_reset)), 0)::bigint AS seconds_since_reset
+FROM pg_stat_bgwriter;
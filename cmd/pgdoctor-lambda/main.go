@@ -0,0 +1,8 @@
+// Package main is the AWS Lambda entry point for pgdoctor.
+package main
+
+import "github.com/fresha/pgdoctor/lambda"
+
+func main() {
+	lambda.Start()
+}
@@ -5,13 +5,13 @@ import (
 	"errors"
 	"fmt"
 	"os"
-	"runtime/debug"
 
+	"github.com/fresha/pgdoctor/internal/buildinfo"
 	"github.com/fresha/pgdoctor/internal/cli"
 )
 
 func main() {
-	if err := cli.Execute(buildVersion()); err != nil {
+	if err := cli.Execute(buildinfo.Get().String()); err != nil {
 		var silent *cli.SilentError
 		if errors.As(err, &silent) {
 			os.Exit(silent.ExitCode)
@@ -20,11 +20,3 @@ func main() {
 		os.Exit(1)
 	}
 }
-
-func buildVersion() string {
-	info, ok := debug.ReadBuildInfo()
-	if !ok || info.Main.Version == "" || info.Main.Version == "(devel)" {
-		return "dev"
-	}
-	return info.Main.Version
-}
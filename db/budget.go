@@ -0,0 +1,29 @@
+package db
+
+// This file is written by hand; sqlc generates the rest of the package.
+
+import (
+	"context"
+	"strconv"
+	"time"
+)
+
+// WithStatementTimeout runs fn in a read-only transaction whose
+// statement_timeout is timeout, so queries fn makes through q get their own
+// budget instead of the session's. The transaction is always rolled back,
+// which also clears an error that aborted it, so the connection is usable
+// whatever fn returns.
+func (q *Queries) WithStatementTimeout(ctx context.Context, timeout time.Duration, fn func() error) error {
+	if _, err := q.db.Exec(ctx, "BEGIN READ ONLY"); err != nil {
+		return err
+	}
+	defer func() {
+		_, _ = q.db.Exec(context.WithoutCancel(ctx), "ROLLBACK")
+	}()
+
+	ms := strconv.FormatInt(timeout.Milliseconds(), 10)
+	if _, err := q.db.Exec(ctx, "SELECT set_config('statement_timeout', $1, true)", ms); err != nil {
+		return err
+	}
+	return fn()
+}
@@ -11,31 +11,76 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
-const brokenIndexes = `-- name: BrokenIndexes :many
+const accessMethodBrinCandidates = `-- name: AccessMethodBrinCandidates :many
 SELECT
-  tblclass.relname AS table_name
-  , idxclass.relname AS index_name
-FROM pg_index
-INNER JOIN pg_class AS idxclass ON pg_index.indexrelid = idxclass.oid
-INNER JOIN pg_class AS tblclass ON pg_index.indrelid = tblclass.oid
-WHERE NOT pg_index.indisvalid
+  n.nspname::text AS schema_name
+  , t.relname::text AS table_name
+  , i.relname::text AS index_name
+  , a.attname::text AS column_name
+  , s.correlation::float8 AS correlation
+  , PG_RELATION_SIZE(t.oid)::bigint AS table_bytes
+  , PG_RELATION_SIZE(i.oid)::bigint AS index_bytes
+  , COALESCE(pst.n_tup_ins, 0)::bigint AS inserts
+  , (COALESCE(pst.n_tup_upd, 0) + COALESCE(pst.n_tup_del, 0))::bigint AS updates_deletes
+FROM pg_index AS x
+INNER JOIN pg_class AS i ON x.indexrelid = i.oid
+INNER JOIN pg_am AS am ON i.relam = am.oid
+INNER JOIN pg_class AS t ON x.indrelid = t.oid
+INNER JOIN pg_namespace AS n ON t.relnamespace = n.oid
+INNER JOIN pg_attribute AS a ON a.attrelid = t.oid AND a.attnum = x.indkey[0]
+INNER JOIN pg_stats AS s
+  ON s.schemaname = n.nspname AND s.tablename = t.relname AND s.attname = a.attname
+LEFT JOIN pg_stat_user_tables AS pst ON t.oid = pst.relid
+WHERE
+  am.amname = 'btree'
+  AND NOT x.indisunique
+  AND x.indnkeyatts = 1
+  AND x.indkey[0] <> 0
+  AND a.atttypid IN ('timestamp'::regtype, 'timestamptz'::regtype, 'date'::regtype)
+  AND s.correlation IS NOT NULL
+  AND NOT s.inherited
+  AND PG_RELATION_SIZE(t.oid) >= 1024 * 1024 * 1024
+  AND n.nspname NOT IN ('pg_catalog', 'information_schema')
+  AND n.nspname !~ '^pg_toast'
+ORDER BY PG_RELATION_SIZE(i.oid) DESC, i.relname
+LIMIT 100
 `
 
-type BrokenIndexesRow struct {
-	TableName string
-	IndexName string
+type AccessMethodBrinCandidatesRow struct {
+	SchemaName     string
+	TableName      string
+	IndexName      string
+	ColumnName     string
+	Correlation    float64
+	TableBytes     int64
+	IndexBytes     int64
+	Inserts        int64
+	UpdatesDeletes int64
 }
 
-func (q *Queries) BrokenIndexes(ctx context.Context) ([]BrokenIndexesRow, error) {
-	rows, err := q.db.Query(ctx, brokenIndexes)
+// Single-column B-tree indexes on timestamp and date columns of tables of
+// 1GiB or more, with the physical-order correlation and write counters
+// needed to tell append-only tables.
+func (q *Queries) AccessMethodBrinCandidates(ctx context.Context) ([]AccessMethodBrinCandidatesRow, error) {
+	rows, err := q.db.Query(ctx, accessMethodBrinCandidates)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []BrokenIndexesRow
+	var items []AccessMethodBrinCandidatesRow
 	for rows.Next() {
-		var i BrokenIndexesRow
-		if err := rows.Scan(&i.TableName, &i.IndexName); err != nil {
+		var i AccessMethodBrinCandidatesRow
+		if err := rows.Scan(
+			&i.SchemaName,
+			&i.TableName,
+			&i.IndexName,
+			&i.ColumnName,
+			&i.Correlation,
+			&i.TableBytes,
+			&i.IndexBytes,
+			&i.Inserts,
+			&i.UpdatesDeletes,
+		); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -46,124 +91,155 @@ func (q *Queries) BrokenIndexes(ctx context.Context) ([]BrokenIndexesRow, error)
 	return items, nil
 }
 
-const connectionStats = `-- name: ConnectionStats :one
+const accessMethodBtreeCandidates = `-- name: AccessMethodBtreeCandidates :many
 SELECT
-  current_setting('max_connections')::int AS max_connections
-  , current_setting('superuser_reserved_connections')::int AS reserved_connections
-  , count(*) AS total_connections
-  , count(*) FILTER (WHERE state = 'active') AS active_connections
-  , count(*) FILTER (WHERE state = 'idle') AS idle_connections
-  , count(*) FILTER (WHERE state = 'idle in transaction') AS idle_in_transaction
-  , count(*) FILTER (WHERE state = 'idle in transaction (aborted)') AS idle_in_transaction_aborted
-  , count(*) FILTER (WHERE wait_event_type IS NOT NULL AND state = 'active') AS waiting_connections
-FROM pg_stat_activity
-WHERE pid != pg_backend_pid()
+  n.nspname::text AS schema_name
+  , t.relname::text AS table_name
+  , i.relname::text AS index_name
+  , a.attname::text AS column_name
+  , PG_RELATION_SIZE(i.oid)::bigint AS index_bytes
+  , GREATEST(i.reltuples, 0)::bigint AS estimated_rows
+  , COALESCE(s.n_distinct, 0)::float8 AS n_distinct
+  , COALESCE(s.avg_width, 0)::integer AS avg_width
+  , COALESCE(ARRAY_TO_STRING(i.reloptions, ',') ~ 'deduplicate_items=(off|false|no|0)', false) AS dedup_disabled
+FROM pg_index AS x
+INNER JOIN pg_class AS i ON x.indexrelid = i.oid
+INNER JOIN pg_am AS am ON i.relam = am.oid
+INNER JOIN pg_class AS t ON x.indrelid = t.oid
+INNER JOIN pg_namespace AS n ON t.relnamespace = n.oid
+INNER JOIN pg_attribute AS a ON a.attrelid = t.oid AND a.attnum = x.indkey[0]
+INNER JOIN pg_type AS ty ON a.atttypid = ty.oid
+LEFT JOIN pg_collation AS coll ON x.indcollation[0] = coll.oid
+LEFT JOIN pg_stats AS s
+  ON s.schemaname = n.nspname AND s.tablename = t.relname AND s.attname = a.attname AND NOT s.inherited
+WHERE
+  am.amname = 'btree'
+  AND NOT x.indisunique
+  AND x.indisvalid
+  AND x.indnatts = 1
+  AND x.indkey[0] <> 0
+  AND ty.typname NOT IN ('numeric', 'float4', 'float8', 'jsonb', 'interval')
+  AND ty.typcategory NOT IN ('A', 'C')
+  AND COALESCE(coll.collisdeterministic, true)
+  AND PG_RELATION_SIZE(i.oid) >= 10 * 1024 * 1024
+  AND n.nspname NOT IN ('pg_catalog', 'information_schema')
+  AND n.nspname !~ '^pg_toast'
+ORDER BY PG_RELATION_SIZE(i.oid) DESC, i.relname
+LIMIT 100
 `
 
-type ConnectionStatsRow struct {
-	MaxConnections           pgtype.Int4
-	ReservedConnections      pgtype.Int4
-	TotalConnections         pgtype.Int8
-	ActiveConnections        pgtype.Int8
-	IdleConnections          pgtype.Int8
-	IdleInTransaction        pgtype.Int8
-	IdleInTransactionAborted pgtype.Int8
-	WaitingConnections       pgtype.Int8
+type AccessMethodBtreeCandidatesRow struct {
+	SchemaName    string
+	TableName     string
+	IndexName     string
+	ColumnName    string
+	IndexBytes    int64
+	EstimatedRows int64
+	NDistinct     float64
+	AvgWidth      int32
+	DedupDisabled bool
 }
 
-// Gets overall connection statistics including pool sizing metrics.
-func (q *Queries) ConnectionStats(ctx context.Context) (ConnectionStatsRow, error) {
-	row := q.db.QueryRow(ctx, connectionStats)
-	var i ConnectionStatsRow
-	err := row.Scan(
-		&i.MaxConnections,
-		&i.ReservedConnections,
-		&i.TotalConnections,
-		&i.ActiveConnections,
-		&i.IdleConnections,
-		&i.IdleInTransaction,
-		&i.IdleInTransactionAborted,
-		&i.WaitingConnections,
-	)
-	return i, err
+// Non-unique single-column B-tree indexes of 10MiB or more on types that
+// support deduplication, with the column statistics needed to estimate how
+// much deduplication would shrink them.
+func (q *Queries) AccessMethodBtreeCandidates(ctx context.Context) ([]AccessMethodBtreeCandidatesRow, error) {
+	rows, err := q.db.Query(ctx, accessMethodBtreeCandidates)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []AccessMethodBtreeCandidatesRow
+	for rows.Next() {
+		var i AccessMethodBtreeCandidatesRow
+		if err := rows.Scan(
+			&i.SchemaName,
+			&i.TableName,
+			&i.IndexName,
+			&i.ColumnName,
+			&i.IndexBytes,
+			&i.EstimatedRows,
+			&i.NDistinct,
+			&i.AvgWidth,
+			&i.DedupDisabled,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
 }
 
-const databaseCacheEfficiency = `-- name: DatabaseCacheEfficiency :one
-SELECT
-  blks_hit
-  , blks_read
-  , stats_reset
-  , CASE
-    WHEN blks_hit + blks_read = 0 THEN NULL
-    ELSE round(100.0 * blks_hit / (blks_hit + blks_read), 2)
-  END AS cache_hit_ratio
-  , coalesce(
-    extract(EPOCH FROM (now() - stats_reset)) / 86400
-    , 999
-  ) AS stats_age_days
-FROM pg_stat_database
-WHERE datname = current_database()
+const accessMethodBtreeVersion = `-- name: AccessMethodBtreeVersion :one
+SELECT version::integer AS version
+FROM BT_METAP(FORMAT('%I.%I', $1::text, $2::text))
 `
 
-type DatabaseCacheEfficiencyRow struct {
-	BlksHit       pgtype.Int8
-	BlksRead      pgtype.Int8
-	StatsReset    pgtype.Timestamptz
-	CacheHitRatio pgtype.Numeric
-	StatsAgeDays  pgtype.Numeric
+type AccessMethodBtreeVersionParams struct {
+	SchemaName string
+	IndexName  string
 }
 
-// Returns database-wide buffer cache hit ratio.
-// Low ratios indicate shared_buffers too small or working set exceeds memory.
-func (q *Queries) DatabaseCacheEfficiency(ctx context.Context) (DatabaseCacheEfficiencyRow, error) {
-	row := q.db.QueryRow(ctx, databaseCacheEfficiency)
-	var i DatabaseCacheEfficiencyRow
-	err := row.Scan(
-		&i.BlksHit,
-		&i.BlksRead,
-		&i.StatsReset,
-		&i.CacheHitRatio,
-		&i.StatsAgeDays,
-	)
-	return i, err
+// Reads the B-tree metapage. Version 4 indexes (built on PostgreSQL 13 or
+// later) can use deduplication; older versions need a REINDEX first.
+func (q *Queries) AccessMethodBtreeVersion(ctx context.Context, arg AccessMethodBtreeVersionParams) (int32, error) {
+	row := q.db.QueryRow(ctx, accessMethodBtreeVersion, arg.SchemaName, arg.IndexName)
+	var version int32
+	err := row.Scan(&version)
+	return version, err
 }
 
-const databaseFreezeAge = `-- name: DatabaseFreezeAge :many
+const accessMethodHashIndexes = `-- name: AccessMethodHashIndexes :many
 SELECT
-  datname::text AS database_name
-  , datfrozenxid::text AS frozen_xid
-  , age(datfrozenxid) AS freeze_age
-  , (
-    SELECT s.setting::bigint FROM pg_settings AS s
-    WHERE s.name = 'autovacuum_freeze_max_age'
-  ) AS freeze_max_age
-FROM pg_database
-WHERE datallowconn = true
-ORDER BY age(datfrozenxid) DESC
+  n.nspname::text AS schema_name
+  , t.relname::text AS table_name
+  , i.relname::text AS index_name
+  , PG_RELATION_SIZE(i.oid)::bigint AS index_bytes
+  , x.indisvalid AS is_valid
+  , COALESCE(s.idx_scan, 0)::bigint AS idx_scan
+FROM pg_index AS x
+INNER JOIN pg_class AS i ON x.indexrelid = i.oid
+INNER JOIN pg_am AS am ON i.relam = am.oid
+INNER JOIN pg_class AS t ON x.indrelid = t.oid
+INNER JOIN pg_namespace AS n ON t.relnamespace = n.oid
+LEFT JOIN pg_stat_user_indexes AS s ON x.indexrelid = s.indexrelid
+WHERE
+  am.amname = 'hash'
+  AND n.nspname NOT IN ('pg_catalog', 'information_schema')
+  AND n.nspname !~ '^pg_toast'
+ORDER BY PG_RELATION_SIZE(i.oid) DESC, i.relname
 `
 
-type DatabaseFreezeAgeRow struct {
-	DatabaseName pgtype.Text
-	FrozenXid    pgtype.Text
-	FreezeAge    pgtype.Int4
-	FreezeMaxAge pgtype.Int8
+type AccessMethodHashIndexesRow struct {
+	SchemaName string
+	TableName  string
+	IndexName  string
+	IndexBytes int64
+	IsValid    bool
+	IdxScan    int64
 }
 
-// Gets transaction ID age for all databases.
-func (q *Queries) DatabaseFreezeAge(ctx context.Context) ([]DatabaseFreezeAgeRow, error) {
-	rows, err := q.db.Query(ctx, databaseFreezeAge)
+// Hash indexes with their validity. pg_upgrade from before PostgreSQL 10
+// marks hash indexes invalid because their on-disk format changed.
+func (q *Queries) AccessMethodHashIndexes(ctx context.Context) ([]AccessMethodHashIndexesRow, error) {
+	rows, err := q.db.Query(ctx, accessMethodHashIndexes)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []DatabaseFreezeAgeRow
+	var items []AccessMethodHashIndexesRow
 	for rows.Next() {
-		var i DatabaseFreezeAgeRow
+		var i AccessMethodHashIndexesRow
 		if err := rows.Scan(
-			&i.DatabaseName,
-			&i.FrozenXid,
-			&i.FreezeAge,
-			&i.FreezeMaxAge,
+			&i.SchemaName,
+			&i.TableName,
+			&i.IndexName,
+			&i.IndexBytes,
+			&i.IsValid,
+			&i.IdxScan,
 		); err != nil {
 			return nil, err
 		}
@@ -175,141 +251,89 @@ func (q *Queries) DatabaseFreezeAge(ctx context.Context) ([]DatabaseFreezeAgeRow
 	return items, nil
 }
 
+const accessMethodPageinspectAvailable = `-- name: AccessMethodPageinspectAvailable :one
+SELECT EXISTS(
+  SELECT 1
+  FROM pg_proc
+  WHERE proname = 'bt_metap' AND HAS_FUNCTION_PRIVILEGE(oid, 'EXECUTE')
+) AS available
+`
 
-const duplicateIndexes = `-- name: DuplicateIndexes :many
-WITH index_columns AS (
-  SELECT
-    idx.indexrelid
-    , idx.indrelid
-    , i.relname AS index_name
-    , t.relname AS table_name
-    , n.nspname AS schema_name
-    , idx.indkey::int [] AS column_positions
-    , idx.indnkeyatts AS num_key_columns
-    -- Extract column list as array for prefix comparison
-    , pg_get_indexdef(idx.indexrelid) AS index_def
-    , pg_relation_size(i.oid) AS index_size_bytes
-    -- Detect expression/partial indexes (cannot reliably compare)
-    , (idx.indexprs IS NOT NULL) AS is_expression_index
-    , (idx.indpred IS NOT NULL) AS is_partial_index
-  FROM pg_index AS idx
-  INNER JOIN pg_class AS i ON idx.indexrelid = i.oid
-  INNER JOIN pg_class AS t ON idx.indrelid = t.oid
-  INNER JOIN pg_namespace AS n ON t.relnamespace = n.oid
-  WHERE
-    i.relkind = 'i'
-    AND idx.indisvalid
-    AND n.nspname NOT IN ('pg_catalog', 'information_schema', 'pg_toast', 'cron', 'pgpartman', 'debezium')
-)
+// Checks whether pageinspect's bt_metap is installed and executable by the current role.
+func (q *Queries) AccessMethodPageinspectAvailable(ctx context.Context) (bool, error) {
+	row := q.db.QueryRow(ctx, accessMethodPageinspectAvailable)
+	var available bool
+	err := row.Scan(&available)
+	return available, err
+}
 
-, exact_duplicates AS (
-  -- Find indexes with identical definitions (after removing index name)
+const advisoryLockHolders = `-- name: AdvisoryLockHolders :many
+WITH held AS (
   SELECT
-    a.schema_name
-    , a.table_name
-    , a.index_name AS index_name_a
-    , b.index_name AS index_name_b
-    , a.index_size_bytes AS size_a
-    , b.index_size_bytes AS size_b
-    , a.index_def AS definition_a
-    , 'exact' AS duplicate_type
-  FROM index_columns AS a
-  INNER JOIN index_columns AS b ON
-    a.indrelid = b.indrelid
-    AND a.indexrelid < b.indexrelid
-    AND regexp_replace(a.index_def, 'INDEX \S+ ON', 'INDEX ON', 'g')
-    = regexp_replace(b.index_def, 'INDEX \S+ ON', 'INDEX ON', 'g')
+    pid
+    , COUNT(*) AS held_locks
+  FROM pg_locks
+  WHERE locktype = 'advisory' AND granted AND pid IS NOT NULL
+  GROUP BY pid
 )
 
-, prefix_duplicates AS (
-  -- Find indexes where one is a left-prefix of another
-  -- e.g., (a) is prefix of (a, b)
+, waiters AS (
   SELECT
-    a.schema_name
-    , a.table_name
-    , a.index_name AS index_name_a
-    , b.index_name AS index_name_b
-    , a.index_size_bytes AS size_a
-    , b.index_size_bytes AS size_b
-    , a.index_def AS definition_a
-    , 'prefix' AS duplicate_type
-  FROM index_columns AS a
-  INNER JOIN index_columns AS b ON
-    a.indrelid = b.indrelid
-    AND a.indexrelid <> b.indexrelid
-    AND a.num_key_columns < b.num_key_columns
-    AND a.column_positions = b.column_positions[0:a.num_key_columns]
-    AND NOT a.is_expression_index
-    AND NOT b.is_expression_index
-    AND NOT a.is_partial_index
-    AND NOT b.is_partial_index
+    w.pid AS waiter
+    , UNNEST(PG_BLOCKING_PIDS(w.pid)) AS blocker
+  FROM pg_locks AS w
+  WHERE w.locktype = 'advisory' AND NOT w.granted
 )
 
 SELECT
-  (schema_name || '.' || table_name)::text AS table_name
-  , index_name_a::text
-  , index_name_b::text
-  , size_a
-  , size_b
-  , definition_a::text
-  , duplicate_type::text
-FROM (
-  SELECT
-    schema_name
-    , table_name
-    , index_name_a
-    , index_name_b
-    , size_a
-    , size_b
-    , definition_a
-    , duplicate_type
-  FROM exact_duplicates
-  UNION ALL
-  SELECT
-    schema_name
-    , table_name
-    , index_name_a
-    , index_name_b
-    , size_a
-    , size_b
-    , definition_a
-    , duplicate_type
-  FROM prefix_duplicates
-) AS all_duplicates
-ORDER BY
-  size_a + size_b DESC
+  h.pid::integer AS pid
+  , COALESCE(a.usename, '')::text AS username
+  , COALESCE(a.datname, '')::text AS database_name
+  , COALESCE(a.application_name, '')::text AS application_name
+  , COALESCE(a.state, '')::text AS state
+  , h.held_locks::bigint AS held_locks
+  , (SELECT COUNT(DISTINCT wt.waiter) FROM waiters AS wt WHERE wt.blocker = h.pid)::bigint AS waiting_sessions
+  , COALESCE(EXTRACT(EPOCH FROM NOW() - a.state_change), 0)::bigint AS state_age_seconds
+  , COALESCE(EXTRACT(EPOCH FROM NOW() - a.backend_start), 0)::bigint AS session_age_seconds
+FROM held AS h
+LEFT JOIN pg_stat_activity AS a ON h.pid = a.pid
+ORDER BY h.held_locks DESC, state_age_seconds DESC
+LIMIT 100
 `
 
-type DuplicateIndexesRow struct {
-	TableName     pgtype.Text
-	IndexNameA    pgtype.Text
-	IndexNameB    pgtype.Text
-	SizeA         pgtype.Int8
-	SizeB         pgtype.Int8
-	DefinitionA   pgtype.Text
-	DuplicateType pgtype.Text
+type AdvisoryLockHoldersRow struct {
+	Pid               int32
+	Username          string
+	DatabaseName      string
+	ApplicationName   string
+	State             string
+	HeldLocks         int64
+	WaitingSessions   int64
+	StateAgeSeconds   int64
+	SessionAgeSeconds int64
 }
 
-// Identifies exact and prefix duplicate indexes on the same table.
-// Uses index column positions (indkey) for prefix detection.
-// Excludes: system schemas, invalid indexes, expression/partial indexes for prefix check.
-func (q *Queries) DuplicateIndexes(ctx context.Context) ([]DuplicateIndexesRow, error) {
-	rows, err := q.db.Query(ctx, duplicateIndexes)
+// Sessions holding advisory locks, with how many sessions are queued behind
+// them and how long they have been in their current state.
+func (q *Queries) AdvisoryLockHolders(ctx context.Context) ([]AdvisoryLockHoldersRow, error) {
+	rows, err := q.db.Query(ctx, advisoryLockHolders)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []DuplicateIndexesRow
+	var items []AdvisoryLockHoldersRow
 	for rows.Next() {
-		var i DuplicateIndexesRow
+		var i AdvisoryLockHoldersRow
 		if err := rows.Scan(
-			&i.TableName,
-			&i.IndexNameA,
-			&i.IndexNameB,
-			&i.SizeA,
-			&i.SizeB,
-			&i.DefinitionA,
-			&i.DuplicateType,
+			&i.Pid,
+			&i.Username,
+			&i.DatabaseName,
+			&i.ApplicationName,
+			&i.State,
+			&i.HeldLocks,
+			&i.WaitingSessions,
+			&i.StateAgeSeconds,
+			&i.SessionAgeSeconds,
 		); err != nil {
 			return nil, err
 		}
@@ -321,84 +345,84 @@ func (q *Queries) DuplicateIndexes(ctx context.Context) ([]DuplicateIndexesRow,
 	return items, nil
 }
 
-const hasPgStatStatements = `-- name: HasPgStatStatements :one
-SELECT EXISTS(
-  SELECT 1 FROM pg_extension
-  WHERE extname = 'pg_stat_statements'
-)
+const advisoryLockSummary = `-- name: AdvisoryLockSummary :one
+SELECT
+  COUNT(*) FILTER (WHERE l.granted)::bigint AS held_locks
+  , COUNT(*) FILTER (WHERE NOT l.granted)::bigint AS waiting_locks
+  , COUNT(DISTINCT l.pid) FILTER (WHERE l.granted)::bigint AS holder_sessions
+  , (
+    current_setting('max_locks_per_transaction')::bigint
+    * (current_setting('max_connections')::bigint + current_setting('max_prepared_transactions')::bigint)
+  )::bigint AS lock_table_capacity
+FROM pg_locks AS l
+WHERE l.locktype = 'advisory'
 `
 
-// Checks if pg_stat_statements extension is installed.
-func (q *Queries) HasPgStatStatements(ctx context.Context) (bool, error) {
-	row := q.db.QueryRow(ctx, hasPgStatStatements)
-	var exists bool
-	err := row.Scan(&exists)
-	return exists, err
+type AdvisoryLockSummaryRow struct {
+	HeldLocks         int64
+	WaitingLocks      int64
+	HolderSessions    int64
+	LockTableCapacity int64
 }
 
-const highSeqScanTables = `-- name: HighSeqScanTables :many
-WITH table_indexes AS (
-  SELECT
-    idx.indrelid AS table_oid
-    , count(*) AS index_count
-  FROM pg_index AS idx
-  WHERE idx.indisvalid
-  GROUP BY idx.indrelid
-)
+// Granted and awaited advisory locks across the cluster, and the size of the
+// shared lock table they are allocated from.
+func (q *Queries) AdvisoryLockSummary(ctx context.Context) (AdvisoryLockSummaryRow, error) {
+	row := q.db.QueryRow(ctx, advisoryLockSummary)
+	var i AdvisoryLockSummaryRow
+	err := row.Scan(
+		&i.HeldLocks,
+		&i.WaitingLocks,
+		&i.HolderSessions,
+		&i.LockTableCapacity,
+	)
+	return i, err
+}
 
+const backendIOTiming = `-- name: BackendIOTiming :many
 SELECT
-  (n.nspname || '.' || c.relname)::text AS table_name
-  , coalesce(s.seq_scan, 0) AS seq_scan
-  , coalesce(s.idx_scan, 0) AS idx_scan
-  , CASE
-    WHEN coalesce(s.idx_scan, 0) = 0 THEN NULL
-    ELSE round(s.seq_scan::numeric / s.idx_scan, 2)
-  END AS seq_to_idx_ratio
-  , coalesce(s.n_live_tup, 0) AS estimated_rows
-  , pg_relation_size(c.oid) AS table_size_bytes
-  , coalesce(ti.index_count, 0) AS index_count
-FROM pg_class AS c
-INNER JOIN pg_namespace AS n ON c.relnamespace = n.oid
-LEFT JOIN pg_stat_user_tables AS s ON c.oid = s.relid
-LEFT JOIN table_indexes AS ti ON c.oid = ti.table_oid
+  a.pid
+  , coalesce(a.usename, '')::text AS usename
+  , coalesce(a.application_name, '')::text AS application_name
+  , sum(io.reads)::bigint AS reads
+  , sum(io.read_time)::double precision AS read_time_ms
+FROM pg_stat_activity AS a
+CROSS JOIN LATERAL pg_stat_get_backend_io(a.pid) AS io
 WHERE
-  c.relkind IN ('r', 'p')
-  AND n.nspname = 'public'
-  AND coalesce(s.n_live_tup, 0) > 10000
-  AND coalesce(s.seq_scan, 0) > 100
-ORDER BY
-  coalesce(s.seq_scan, 0) DESC
+  a.backend_type = 'client backend'
+  AND a.datname = current_database()
+  AND io.object = 'relation'
+GROUP BY a.pid, a.usename, a.application_name
+HAVING sum(io.reads) > 0
+ORDER BY read_time_ms DESC
+LIMIT 5
 `
 
-type HighSeqScanTablesRow struct {
-	TableName      pgtype.Text
-	SeqScan        pgtype.Int8
-	IdxScan        pgtype.Int8
-	SeqToIdxRatio  pgtype.Numeric
-	EstimatedRows  pgtype.Int8
-	TableSizeBytes pgtype.Int8
-	IndexCount     pgtype.Int8
+type BackendIOTimingRow struct {
+	Pid             int32
+	Usename         string
+	ApplicationName string
+	Reads           int64
+	ReadTimeMs      float64
 }
 
-// Identifies tables with excessive sequential scans relative to index scans.
-// Excludes: small tables, system schemas, tables with no indexes.
-func (q *Queries) HighSeqScanTables(ctx context.Context) ([]HighSeqScanTablesRow, error) {
-	rows, err := q.db.Query(ctx, highSeqScanTables)
+// Connected client backends of this database that spent the most time
+// reading relations, from the per-backend I/O statistics of PostgreSQL 18+.
+func (q *Queries) BackendIOTiming(ctx context.Context) ([]BackendIOTimingRow, error) {
+	rows, err := q.db.Query(ctx, backendIOTiming)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []HighSeqScanTablesRow
+	var items []BackendIOTimingRow
 	for rows.Next() {
-		var i HighSeqScanTablesRow
+		var i BackendIOTimingRow
 		if err := rows.Scan(
-			&i.TableName,
-			&i.SeqScan,
-			&i.IdxScan,
-			&i.SeqToIdxRatio,
-			&i.EstimatedRows,
-			&i.TableSizeBytes,
-			&i.IndexCount,
+			&i.Pid,
+			&i.Usename,
+			&i.ApplicationName,
+			&i.Reads,
+			&i.ReadTimeMs,
 		); err != nil {
 			return nil, err
 		}
@@ -410,59 +434,56 @@ func (q *Queries) HighSeqScanTables(ctx context.Context) ([]HighSeqScanTablesRow
 	return items, nil
 }
 
-const idleInTransaction = `-- name: IdleInTransaction :many
+const bgwriterCheckpointStats = `-- name: BgwriterCheckpointStats :one
 SELECT
-  pg_stat_activity.pid
-  , pg_stat_activity.usename::text AS username
-  , pg_stat_activity.datname::text AS database_name
-  , pg_stat_activity.application_name::text AS application_name
-  , pg_stat_activity.state::text AS state
-  , extract(EPOCH FROM (now() - pg_stat_activity.xact_start))::bigint AS transaction_duration_seconds
-  , left(pg_stat_activity.query, 200)::text AS query_preview
-  , coalesce((
-    SELECT pg_settings.setting::bigint
-    FROM pg_settings
-    WHERE pg_settings.name = 'idle_in_transaction_session_timeout'
-  ), 0) AS timeout_ms
-FROM pg_stat_activity
-WHERE
-  pg_stat_activity.state IN ('idle in transaction', 'idle in transaction (aborted)')
-  AND pg_stat_activity.pid != pg_backend_pid()
-ORDER BY pg_stat_activity.xact_start ASC
+  checkpoints_timed::bigint AS checkpoints_timed
+  , checkpoints_req::bigint AS checkpoints_requested
+  , COALESCE(EXTRACT(EPOCH FROM (NOW() - stats_reset)), 0)::bigint AS seconds_since_reset
+FROM pg_stat_bgwriter
 `
 
-type IdleInTransactionRow struct {
-	Pid                        pgtype.Int4
-	Username                   pgtype.Text
-	DatabaseName               pgtype.Text
-	ApplicationName            pgtype.Text
-	State                      pgtype.Text
-	TransactionDurationSeconds pgtype.Int8
-	QueryPreview               pgtype.Text
-	TimeoutMs                  pgtype.Int8
+type BgwriterCheckpointStatsRow struct {
+	CheckpointsTimed     int64
+	CheckpointsRequested int64
+	SecondsSinceReset    int64
 }
 
-// Identifies connections stuck in 'idle in transaction' state.
-// Includes the timeout setting (in ms) for threshold calculation in Go.
-func (q *Queries) IdleInTransaction(ctx context.Context) ([]IdleInTransactionRow, error) {
-	rows, err := q.db.Query(ctx, idleInTransaction)
+// Checkpoint counters from pg_stat_bgwriter (before PostgreSQL 17).
+func (q *Queries) BgwriterCheckpointStats(ctx context.Context) (BgwriterCheckpointStatsRow, error) {
+	row := q.db.QueryRow(ctx, bgwriterCheckpointStats)
+	var i BgwriterCheckpointStatsRow
+	err := row.Scan(&i.CheckpointsTimed, &i.CheckpointsRequested, &i.SecondsSinceReset)
+	return i, err
+}
+
+const brokenIndexes = `-- name: BrokenIndexes :many
+SELECT
+  tblclass.relname AS table_name
+  , idxclass.relname AS index_name
+  , idxns.nspname::text AS schema_name
+FROM pg_index
+INNER JOIN pg_class AS idxclass ON pg_index.indexrelid = idxclass.oid
+INNER JOIN pg_class AS tblclass ON pg_index.indrelid = tblclass.oid
+INNER JOIN pg_namespace AS idxns ON idxclass.relnamespace = idxns.oid
+WHERE NOT pg_index.indisvalid
+`
+
+type BrokenIndexesRow struct {
+	TableName  string
+	IndexName  string
+	SchemaName string
+}
+
+func (q *Queries) BrokenIndexes(ctx context.Context) ([]BrokenIndexesRow, error) {
+	rows, err := q.db.Query(ctx, brokenIndexes)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []IdleInTransactionRow
+	var items []BrokenIndexesRow
 	for rows.Next() {
-		var i IdleInTransactionRow
-		if err := rows.Scan(
-			&i.Pid,
-			&i.Username,
-			&i.DatabaseName,
-			&i.ApplicationName,
-			&i.State,
-			&i.TransactionDurationSeconds,
-			&i.QueryPreview,
-			&i.TimeoutMs,
-		); err != nil {
+		var i BrokenIndexesRow
+		if err := rows.Scan(&i.TableName, &i.IndexName, &i.SchemaName); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -473,142 +494,74 @@ func (q *Queries) IdleInTransaction(ctx context.Context) ([]IdleInTransactionRow
 	return items, nil
 }
 
-const indexBloat = `-- name: IndexBloat :many
-WITH index_info AS (
-  SELECT
-    n.nspname::text AS schemaname
-    , t.relname::text AS tablename
-    , i.relname::text AS indexname
-    , t.oid AS table_oid
-    , i.relpages AS actual_pages
-    , i.reltuples
-    , ix.indkey
-    , CURRENT_SETTING('block_size')::int AS bs
-    , COALESCE(
-      SUBSTRING(ARRAY_TO_STRING(i.reloptions, ' ') FROM 'fillfactor=([0-9]+)')::int
-      , 90
-    ) AS fill_factor
-  FROM pg_index AS ix
-  INNER JOIN pg_class AS i ON ix.indexrelid = i.oid
-  INNER JOIN pg_class AS t ON ix.indrelid = t.oid
-  INNER JOIN pg_namespace AS n ON i.relnamespace = n.oid
-  INNER JOIN pg_am AS am ON i.relam = am.oid
-  WHERE
-    am.amname = 'btree'
-    AND n.nspname NOT IN ('pg_catalog', 'information_schema', 'pg_toast')
-    AND i.relpages > 100  -- Skip tiny indexes (<800KB)
-    AND ix.indisvalid
-    AND i.reltuples > 0
-)
-
-, index_columns AS (
-  SELECT
-    ii.schemaname
-    , ii.tablename
-    , ii.indexname
-    , ii.actual_pages
-    , ii.reltuples
-    , ii.fill_factor
-    , ii.bs
-    -- Sum avg_width from pg_stats for indexed columns
-    -- Fallback to 24 bytes if no stats (reasonable for UUID/timestamp)
-    , COALESCE(
-      (
-        SELECT SUM(COALESCE(s.avg_width, 8))
-        FROM UNNEST(ii.indkey) WITH ORDINALITY AS u (attnum, ord)
-        INNER JOIN pg_attribute AS a ON a.attrelid = ii.table_oid AND u.attnum = a.attnum
-        LEFT JOIN pg_stats AS s
-          ON
-            s.schemaname = ii.schemaname
-            AND s.tablename = ii.tablename
-            AND a.attname = s.attname
-        WHERE u.attnum > 0
-      )
-      , 24
-    ) AS data_width
-  FROM index_info AS ii
-)
+const bufferCacheAvailable = `-- name: BufferCacheAvailable :one
+SELECT EXISTS(
+  SELECT 1 FROM pg_extension
+  WHERE extname = 'pg_buffercache'
+) AS available
+`
 
-, bloat_calc AS (
-  SELECT
-    schemaname
-    , tablename
-    , indexname
-    , actual_pages
-    , reltuples
-    , bs
-    , data_width
-    -- Index tuple size: ItemPointer(6) + info(2) + data ≈ 8 + data_width
-    -- Simplified: skip per-column MAXALIGN, add ~20% padding estimate
-    , CEIL((8 + data_width) * 1.2) AS tuple_size
-    -- Usable space: block_size - PageHeader(24) - BTPageOpaque(16), apply fill_factor
-    , FLOOR((bs - 40) * fill_factor / 100.0) AS usable_space
-  FROM index_columns
-)
+// Checks if the pg_buffercache extension is installed in this database.
+func (q *Queries) BufferCacheAvailable(ctx context.Context) (bool, error) {
+	row := q.db.QueryRow(ctx, bufferCacheAvailable)
+	var available bool
+	err := row.Scan(&available)
+	return available, err
+}
 
-, bloat_estimate AS (
+const bufferCacheRelations = `-- name: BufferCacheRelations :many
+WITH cached AS (
   SELECT
-    schemaname
-    , tablename
-    , indexname
-    , actual_pages
-    , bs
-    -- Expected pages = ceil(tuples / (usable_space / (line_pointer(4) + tuple_size)))
-    , GREATEST(1, CEIL(reltuples / FLOOR(usable_space / (4 + tuple_size))))::bigint AS est_pages
-    , (actual_pages::bigint * bs) AS actual_bytes
-  FROM bloat_calc
-  WHERE tuple_size > 0 AND usable_space > (4 + tuple_size)
+    b.relfilenode
+    , COUNT(*) AS buffers
+    , COUNT(*) FILTER (WHERE b.isdirty) AS dirty
+    , AVG(b.usagecount) AS avg_usage
+  FROM pg_buffercache AS b
+  WHERE b.reldatabase IN (0, (SELECT d.oid FROM pg_database AS d WHERE d.datname = CURRENT_DATABASE()))
+  GROUP BY b.relfilenode
 )
 
 SELECT
-  schemaname
-  , tablename
-  , indexname
-  , actual_pages
-  , est_pages
-  , actual_bytes
-  , ((actual_pages - est_pages)::bigint * bs) AS bloat_bytes
-  , CASE
-    WHEN actual_pages > 0 AND actual_pages > est_pages
-      THEN ROUND(100.0 * (actual_pages - est_pages) / actual_pages, 1)
-    ELSE 0
-  END AS bloat_percent
-FROM bloat_estimate
-WHERE actual_pages > est_pages
-ORDER BY bloat_percent DESC, bloat_bytes DESC
+  (n.nspname || '.' || c.relname)::text AS relation_name
+  , c.relkind::text AS relkind
+  , cached.buffers::bigint AS buffers
+  , cached.dirty::bigint AS dirty_buffers
+  , COALESCE(cached.avg_usage, 0)::float8 AS avg_usage_count
+  , PG_RELATION_SIZE(c.oid)::bigint AS relation_bytes
+FROM cached
+INNER JOIN pg_class AS c ON PG_RELATION_FILENODE(c.oid) = cached.relfilenode
+INNER JOIN pg_namespace AS n ON c.relnamespace = n.oid
+ORDER BY cached.buffers DESC
+LIMIT 20
 `
 
-type IndexBloatRow struct {
-	Schemaname   pgtype.Text
-	Tablename    pgtype.Text
-	Indexname    pgtype.Text
-	ActualPages  int32
-	EstPages     pgtype.Int8
-	ActualBytes  pgtype.Int8
-	BloatBytes   pgtype.Int8
-	BloatPercent pgtype.Numeric
+type BufferCacheRelationsRow struct {
+	RelationName  string
+	Relkind       string
+	Buffers       int64
+	DirtyBuffers  int64
+	AvgUsageCount float64
+	RelationBytes int64
 }
 
-// Balanced B-tree index bloat estimation using pg_stats column widths
-// Accuracy: ±15% (good enough for health checks, not precision measurement)
-func (q *Queries) IndexBloat(ctx context.Context) ([]IndexBloatRow, error) {
-	rows, err := q.db.Query(ctx, indexBloat)
+// Relations of the current database (and shared catalogs) holding the most
+// shared buffers, with their dirty buffers and average usage count.
+func (q *Queries) BufferCacheRelations(ctx context.Context) ([]BufferCacheRelationsRow, error) {
+	rows, err := q.db.Query(ctx, bufferCacheRelations)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []IndexBloatRow
+	var items []BufferCacheRelationsRow
 	for rows.Next() {
-		var i IndexBloatRow
+		var i BufferCacheRelationsRow
 		if err := rows.Scan(
-			&i.Schemaname,
-			&i.Tablename,
-			&i.Indexname,
-			&i.ActualPages,
-			&i.EstPages,
-			&i.ActualBytes,
-			&i.BloatBytes,
-			&i.BloatPercent,
+			&i.RelationName,
+			&i.Relkind,
+			&i.Buffers,
+			&i.DirtyBuffers,
+			&i.AvgUsageCount,
+			&i.RelationBytes,
 		); err != nil {
 			return nil, err
 		}
@@ -620,85 +573,3215 @@ func (q *Queries) IndexBloat(ctx context.Context) ([]IndexBloatRow, error) {
 	return items, nil
 }
 
-const indexUsageStats = `-- name: IndexUsageStats :many
+const bufferCacheSummary = `-- name: BufferCacheSummary :one
 SELECT
-  (n.nspname || '.' || tbl.relname)::text AS table_name
-  , psai.indexrelname::text AS index_name
-  , c.reltuples::bigint AS num_rows
-  , x.indisprimary AS is_primary
-  , x.indisunique AS is_unique
-  , pg_relation_size(psai.indexrelid) AS index_size_bytes
-  , coalesce(psai.idx_scan, 0) AS idx_scan
-  , coalesce(psai.idx_tup_read, 0) AS idx_tup_read
-  , coalesce(psai.idx_tup_fetch, 0) AS idx_tup_fetch
-  , coalesce(ut.n_tup_ins, 0) + coalesce(ut.n_tup_upd, 0) + coalesce(ut.n_tup_del, 0) AS table_writes
-  , coalesce(psaio.idx_blks_hit, 0) AS idx_blks_hit
-  , coalesce(psaio.idx_blks_read, 0) AS idx_blks_read
+  COUNT(*)::bigint AS total_buffers
+  , COUNT(*) FILTER (WHERE b.relfilenode IS NOT NULL)::bigint AS used_buffers
+  , COUNT(*) FILTER (WHERE b.isdirty)::bigint AS dirty_buffers
+  , COUNT(*) FILTER (WHERE b.usagecount <= 1)::bigint AS usage_low
+  , COUNT(*) FILTER (WHERE b.usagecount BETWEEN 2 AND 3)::bigint AS usage_mid
+  , COUNT(*) FILTER (WHERE b.usagecount >= 4)::bigint AS usage_high
+  , CURRENT_SETTING('block_size')::bigint AS block_size
+FROM pg_buffercache AS b
+`
+
+type BufferCacheSummaryRow struct {
+	TotalBuffers int64
+	UsedBuffers  int64
+	DirtyBuffers int64
+	UsageLow     int64
+	UsageMid     int64
+	UsageHigh    int64
+	BlockSize    int64
+}
+
+// Shared buffer occupancy, dirty buffers, and the usage-count distribution
+// of occupied buffers across the whole cluster.
+func (q *Queries) BufferCacheSummary(ctx context.Context) (BufferCacheSummaryRow, error) {
+	row := q.db.QueryRow(ctx, bufferCacheSummary)
+	var i BufferCacheSummaryRow
+	err := row.Scan(
+		&i.TotalBuffers,
+		&i.UsedBuffers,
+		&i.DirtyBuffers,
+		&i.UsageLow,
+		&i.UsageMid,
+		&i.UsageHigh,
+		&i.BlockSize,
+	)
+	return i, err
+}
+
+const certificateFile = `-- name: CertificateFile :one
+SELECT PG_READ_BINARY_FILE($1::text) AS contents
+`
+
+// Reads a PEM certificate file from the server's filesystem. Relative paths
+// resolve against the data directory, as they do for ssl_cert_file.
+func (q *Queries) CertificateFile(ctx context.Context, path string) ([]byte, error) {
+	row := q.db.QueryRow(ctx, certificateFile, path)
+	var contents []byte
+	err := row.Scan(&contents)
+	return contents, err
+}
+
+const checkpointActivity = `-- name: CheckpointActivity :one
+SELECT
+  c.num_timed::bigint AS checkpoints_timed
+  , c.num_requested::bigint AS checkpoints_requested
+  , c.buffers_written::bigint AS buffers_checkpoint
+  , b.buffers_clean::bigint AS buffers_clean
+  , b.maxwritten_clean::bigint AS maxwritten_clean
+  , COALESCE(io.writes, 0)::bigint AS buffers_backend
+  , COALESCE(io.fsyncs, 0)::bigint AS backend_fsyncs
+  , c.write_time::double precision AS write_time_ms
+  , COALESCE(EXTRACT(EPOCH FROM (NOW() - c.stats_reset)), 0)::bigint AS seconds_since_reset
+FROM pg_stat_checkpointer AS c
+CROSS JOIN pg_stat_bgwriter AS b
+CROSS JOIN (
+  SELECT
+    SUM(writes) AS writes
+    , SUM(fsyncs) AS fsyncs
+  FROM pg_stat_io
+  WHERE backend_type = 'client backend' AND object = 'relation'
+) AS io
+`
+
+type CheckpointActivityRow struct {
+	CheckpointsTimed     int64
+	CheckpointsRequested int64
+	BuffersCheckpoint    int64
+	BuffersClean         int64
+	MaxwrittenClean      int64
+	BuffersBackend       int64
+	BackendFsyncs        int64
+	WriteTimeMs          float64
+	SecondsSinceReset    int64
+}
+
+// Checkpoint and buffer write counters on PostgreSQL 17+, where checkpoints
+// moved to pg_stat_checkpointer and backend writes to pg_stat_io.
+func (q *Queries) CheckpointActivity(ctx context.Context) (CheckpointActivityRow, error) {
+	row := q.db.QueryRow(ctx, checkpointActivity)
+	var i CheckpointActivityRow
+	err := row.Scan(
+		&i.CheckpointsTimed,
+		&i.CheckpointsRequested,
+		&i.BuffersCheckpoint,
+		&i.BuffersClean,
+		&i.MaxwrittenClean,
+		&i.BuffersBackend,
+		&i.BackendFsyncs,
+		&i.WriteTimeMs,
+		&i.SecondsSinceReset,
+	)
+	return i, err
+}
+
+const checkpointSettings = `-- name: CheckpointSettings :one
+SELECT
+  CURRENT_SETTING('server_version_num')::integer AS server_version_num
+  , (SELECT setting::bigint FROM pg_settings WHERE name = 'checkpoint_timeout') AS checkpoint_timeout_seconds
+  , PG_SIZE_BYTES(CURRENT_SETTING('max_wal_size'))::bigint AS max_wal_size_bytes
+  , PG_SIZE_BYTES(CURRENT_SETTING('shared_buffers'))::bigint AS shared_buffers_bytes
+  , CURRENT_SETTING('bgwriter_lru_maxpages')::integer AS bgwriter_lru_maxpages
+`
+
+type CheckpointSettingsRow struct {
+	ServerVersionNum         int32
+	CheckpointTimeoutSeconds int64
+	MaxWalSizeBytes          int64
+	SharedBuffersBytes       int64
+	BgwriterLruMaxpages      int32
+}
+
+// Settings that decide when checkpoints run and how the background writer
+// keeps up, with the server version to pick the statistics views.
+func (q *Queries) CheckpointSettings(ctx context.Context) (CheckpointSettingsRow, error) {
+	row := q.db.QueryRow(ctx, checkpointSettings)
+	var i CheckpointSettingsRow
+	err := row.Scan(
+		&i.ServerVersionNum,
+		&i.CheckpointTimeoutSeconds,
+		&i.MaxWalSizeBytes,
+		&i.SharedBuffersBytes,
+		&i.BgwriterLruMaxpages,
+	)
+	return i, err
+}
+
+const checkpointerStats = `-- name: CheckpointerStats :one
+SELECT
+  num_timed::bigint AS checkpoints_timed
+  , num_requested::bigint AS checkpoints_requested
+  , COALESCE(EXTRACT(EPOCH FROM (NOW() - stats_reset)), 0)::bigint AS seconds_since_reset
+FROM pg_stat_checkpointer
+`
+
+type CheckpointerStatsRow struct {
+	CheckpointsTimed     int64
+	CheckpointsRequested int64
+	SecondsSinceReset    int64
+}
+
+// Checkpoint counters from pg_stat_checkpointer (PostgreSQL 17+).
+func (q *Queries) CheckpointerStats(ctx context.Context) (CheckpointerStatsRow, error) {
+	row := q.db.QueryRow(ctx, checkpointerStats)
+	var i CheckpointerStatsRow
+	err := row.Scan(&i.CheckpointsTimed, &i.CheckpointsRequested, &i.SecondsSinceReset)
+	return i, err
+}
+
+const clusterIdentity = `-- name: ClusterIdentity :one
+SELECT
+  s.system_identifier::text AS system_identifier
+  , COALESCE(
+    (SELECT r.received_tli FROM pg_stat_wal_receiver AS r WHERE r.received_tli IS NOT NULL)
+    , c.timeline_id
+  )::integer AS timeline_id
+  , PG_IS_IN_RECOVERY()::boolean AS in_recovery
+FROM pg_control_system() AS s
+CROSS JOIN pg_control_checkpoint() AS c
+`
+
+type ClusterIdentityRow struct {
+	SystemIdentifier string
+	TimelineID       int32
+	InRecovery       bool
+}
+
+// The cluster a host belongs to and the timeline it is on, so fleet runs can
+// spot hosts that stopped following their group. A standby reports the
+// timeline its WAL receiver is streaming, which moves to the new timeline
+// as soon as it follows a promoted primary.
+func (q *Queries) ClusterIdentity(ctx context.Context) (ClusterIdentityRow, error) {
+	row := q.db.QueryRow(ctx, clusterIdentity)
+	var i ClusterIdentityRow
+	err := row.Scan(&i.SystemIdentifier, &i.TimelineID, &i.InRecovery)
+	return i, err
+}
+
+const columnStorageStrategies = `-- name: ColumnStorageStrategies :many
+SELECT
+  n.nspname::text AS schema_name
+  , c.relname::text AS table_name
+  , a.attname::text AS column_name
+  , t.typname::text AS column_type
+  , CASE a.attstorage
+    WHEN 'p' THEN 'PLAIN'
+    WHEN 'e' THEN 'EXTERNAL'
+    WHEN 'x' THEN 'EXTENDED'
+    WHEN 'm' THEN 'MAIN'
+  END AS storage_strategy
+  , CASE t.typstorage
+    WHEN 'p' THEN 'PLAIN'
+    WHEN 'e' THEN 'EXTERNAL'
+    WHEN 'x' THEN 'EXTENDED'
+    WHEN 'm' THEN 'MAIN'
+  END AS default_strategy
+  , ps.avg_width::bigint AS avg_width
+  , HAS_COLUMN_PRIVILEGE(c.oid, a.attnum, 'SELECT') AS can_sample
+FROM pg_attribute AS a
+INNER JOIN pg_class AS c ON a.attrelid = c.oid
+INNER JOIN pg_namespace AS n ON c.relnamespace = n.oid
+INNER JOIN pg_type AS t ON a.atttypid = t.oid
+LEFT JOIN pg_stats AS ps
+  ON n.nspname = ps.schemaname AND c.relname = ps.tablename AND a.attname = ps.attname
+WHERE
+  a.attnum > 0
+  AND NOT a.attisdropped
+  AND c.relkind IN ('r', 'p')
+  AND n.nspname NOT IN ('pg_catalog', 'information_schema', 'pg_toast')
+  AND t.typstorage != 'p'  -- Only variable-length types can be TOASTed
+  AND (a.attstorage != t.typstorage OR coalesce(ps.avg_width, 0) >= 2000)
+ORDER BY n.nspname, c.relname, a.attnum
+`
+
+type ColumnStorageStrategiesRow struct {
+	SchemaName      string
+	TableName       string
+	ColumnName      string
+	ColumnType      string
+	StorageStrategy string
+	DefaultStrategy string
+	AvgWidth        pgtype.Int8
+	CanSample       bool
+}
+
+// Returns TOAST-able columns whose storage strategy differs from their type's
+// default, plus wide columns (avg_width >= 2KB) whose values are likely
+// compressed or moved out of line, so both can be checked against the data.
+func (q *Queries) ColumnStorageStrategies(ctx context.Context) ([]ColumnStorageStrategiesRow, error) {
+	rows, err := q.db.Query(ctx, columnStorageStrategies)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ColumnStorageStrategiesRow
+	for rows.Next() {
+		var i ColumnStorageStrategiesRow
+		if err := rows.Scan(
+			&i.SchemaName,
+			&i.TableName,
+			&i.ColumnName,
+			&i.ColumnType,
+			&i.StorageStrategy,
+			&i.DefaultStrategy,
+			&i.AvgWidth,
+			&i.CanSample,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const columnValueSample = `-- name: ColumnValueSample :one
+WITH sample AS (
+  SELECT
+    (xpath('/row/stored/text()', x))[1]::text::bigint AS stored
+    , (xpath('/row/raw/text()', x))[1]::text::bigint AS raw
+    , coalesce((xpath('/row/magic/text()', x))[1]::text, '') AS magic
+  FROM UNNEST(XPATH('/table/row', QUERY_TO_XML(FORMAT(
+    'SELECT pg_column_size(%1$I) AS stored, %4$s AS raw, %5$s AS magic '
+    'FROM %2$I.%3$I TABLESAMPLE SYSTEM (1) WHERE %1$I IS NOT NULL LIMIT %6$s'
+    , $1::text
+    , $2::text
+    , $3::text
+    , CASE
+      WHEN $4::bool THEN FORMAT('octet_length(%I)', $1::text)
+      ELSE FORMAT('octet_length(%I::text)', $1::text)
+    END
+    , CASE
+      WHEN $4::bool THEN FORMAT('encode(substring(%I FROM 1 FOR 4), ''hex'')', $1::text)
+      ELSE 'NULL::text'
+    END
+    , $5::int
+  ), FALSE, FALSE, ''))) AS x
+)
+
+SELECT
+  count(*) AS sampled
+  , coalesce(sum(stored), 0)::bigint AS stored_bytes
+  , coalesce(sum(raw), 0)::bigint AS raw_bytes
+  , count(*) FILTER (
+    WHERE
+      magic LIKE '1f8b%'       -- gzip
+      OR magic = '28b52ffd'    -- zstd
+      OR magic = '504b0304'    -- zip, docx, xlsx
+      OR magic = '89504e47'    -- PNG
+      OR magic LIKE 'ffd8ff%'  -- JPEG
+      OR magic = '52494646'    -- RIFF (WebP, WAV, AVI)
+  ) AS precompressed
+FROM sample
+`
+
+type ColumnValueSampleParams struct {
+	ColumnName string
+	SchemaName string
+	TableName  string
+	IsBytea    bool
+	SampleRows int32
+}
+
+type ColumnValueSampleRow struct {
+	Sampled       int64
+	StoredBytes   int64
+	RawBytes      int64
+	Precompressed int64
+}
+
+// Samples up to sample_rows non-null values of one column and reports their
+// stored size (after any compression) against their raw size, and how many
+// start with the signature of an already-compressed format (gzip, zstd, zip,
+// PNG, JPEG, WebP/RIFF). Signatures are only read for bytea columns.
+// query_to_xml runs the per-column query without dynamic SQL in the client.
+func (q *Queries) ColumnValueSample(ctx context.Context, arg ColumnValueSampleParams) (ColumnValueSampleRow, error) {
+	row := q.db.QueryRow(ctx, columnValueSample,
+		arg.ColumnName,
+		arg.SchemaName,
+		arg.TableName,
+		arg.IsBytea,
+		arg.SampleRows,
+	)
+	var i ColumnValueSampleRow
+	err := row.Scan(
+		&i.Sampled,
+		&i.StoredBytes,
+		&i.RawBytes,
+		&i.Precompressed,
+	)
+	return i, err
+}
+
+const connectionStats = `-- name: ConnectionStats :one
+SELECT
+  current_setting('max_connections')::int AS max_connections
+  , current_setting('superuser_reserved_connections')::int AS reserved_connections
+  , count(*) AS total_connections
+  , count(*) FILTER (WHERE state = 'active') AS active_connections
+  , count(*) FILTER (WHERE state = 'idle') AS idle_connections
+  , count(*) FILTER (WHERE state = 'idle in transaction') AS idle_in_transaction
+  , count(*) FILTER (WHERE state = 'idle in transaction (aborted)') AS idle_in_transaction_aborted
+  , count(*) FILTER (WHERE wait_event_type IS NOT NULL AND state = 'active') AS waiting_connections
+FROM pg_stat_activity
+WHERE pid != pg_backend_pid()
+`
+
+type ConnectionStatsRow struct {
+	MaxConnections           pgtype.Int4
+	ReservedConnections      pgtype.Int4
+	TotalConnections         pgtype.Int8
+	ActiveConnections        pgtype.Int8
+	IdleConnections          pgtype.Int8
+	IdleInTransaction        pgtype.Int8
+	IdleInTransactionAborted pgtype.Int8
+	WaitingConnections       pgtype.Int8
+}
+
+// Gets overall connection statistics including pool sizing metrics.
+func (q *Queries) ConnectionStats(ctx context.Context) (ConnectionStatsRow, error) {
+	row := q.db.QueryRow(ctx, connectionStats)
+	var i ConnectionStatsRow
+	err := row.Scan(
+		&i.MaxConnections,
+		&i.ReservedConnections,
+		&i.TotalConnections,
+		&i.ActiveConnections,
+		&i.IdleConnections,
+		&i.IdleInTransaction,
+		&i.IdleInTransactionAborted,
+		&i.WaitingConnections,
+	)
+	return i, err
+}
+
+const databaseCacheEfficiency = `-- name: DatabaseCacheEfficiency :one
+SELECT
+  blks_hit
+  , blks_read
+  , stats_reset
   , CASE
-    WHEN coalesce(psaio.idx_blks_hit, 0) + coalesce(psaio.idx_blks_read, 0) = 0 THEN NULL
-    ELSE round(
-      100.0 * psaio.idx_blks_hit / (psaio.idx_blks_hit + psaio.idx_blks_read)
-      , 2
-    )
+    WHEN blks_hit + blks_read = 0 THEN NULL
+    ELSE round(100.0 * blks_hit / (blks_hit + blks_read), 2)
   END AS cache_hit_ratio
-  , pg_get_indexdef(psai.indexrelid) AS indexdef
+  , coalesce(
+    extract(EPOCH FROM (now() - stats_reset)) / 86400
+    , 999
+  ) AS stats_age_days
+FROM pg_stat_database
+WHERE datname = current_database()
+`
+
+type DatabaseCacheEfficiencyRow struct {
+	BlksHit       pgtype.Int8
+	BlksRead      pgtype.Int8
+	StatsReset    pgtype.Timestamptz
+	CacheHitRatio pgtype.Numeric
+	StatsAgeDays  pgtype.Numeric
+}
+
+// Returns database-wide buffer cache hit ratio.
+// Low ratios indicate shared_buffers too small or working set exceeds memory.
+func (q *Queries) DatabaseCacheEfficiency(ctx context.Context) (DatabaseCacheEfficiencyRow, error) {
+	row := q.db.QueryRow(ctx, databaseCacheEfficiency)
+	var i DatabaseCacheEfficiencyRow
+	err := row.Scan(
+		&i.BlksHit,
+		&i.BlksRead,
+		&i.StatsReset,
+		&i.CacheHitRatio,
+		&i.StatsAgeDays,
+	)
+	return i, err
+}
+
+const databaseFreezeAge = `-- name: DatabaseFreezeAge :many
+SELECT
+  datname::text AS database_name
+  , datfrozenxid::text AS frozen_xid
+  , age(datfrozenxid) AS freeze_age
+  , (
+    SELECT s.setting::bigint FROM pg_settings AS s
+    WHERE s.name = 'autovacuum_freeze_max_age'
+  ) AS freeze_max_age
+FROM pg_database
+WHERE datallowconn = true
+ORDER BY age(datfrozenxid) DESC
+`
+
+type DatabaseFreezeAgeRow struct {
+	DatabaseName pgtype.Text
+	FrozenXid    pgtype.Text
+	FreezeAge    pgtype.Int4
+	FreezeMaxAge pgtype.Int8
+}
+
+// Gets transaction ID age for all databases.
+func (q *Queries) DatabaseFreezeAge(ctx context.Context) ([]DatabaseFreezeAgeRow, error) {
+	rows, err := q.db.Query(ctx, databaseFreezeAge)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []DatabaseFreezeAgeRow
+	for rows.Next() {
+		var i DatabaseFreezeAgeRow
+		if err := rows.Scan(
+			&i.DatabaseName,
+			&i.FrozenXid,
+			&i.FreezeAge,
+			&i.FreezeMaxAge,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const databaseLocales = `-- name: DatabaseLocales :many
+SELECT
+  d.datname::text AS database_name
+  , PG_ENCODING_TO_CHAR(d.encoding)::text AS encoding
+  , d.datcollate::text AS lc_collate
+  , d.datctype::text AS lc_ctype
+  , (CASE COALESCE(TO_JSONB(d) ->> 'datlocprovider', 'c')
+    WHEN 'i' THEN 'icu'
+    WHEN 'b' THEN 'builtin'
+    ELSE 'libc'
+  END)::text AS locale_provider
+  , COALESCE(TO_JSONB(d) ->> 'datlocale', TO_JSONB(d) ->> 'daticulocale', '')::text AS icu_locale
+FROM pg_database AS d
+WHERE NOT d.datistemplate
+ORDER BY d.datname
+`
+
+type DatabaseLocalesRow struct {
+	DatabaseName   string
+	Encoding       string
+	LcCollate      string
+	LcCtype        string
+	LocaleProvider string
+	IcuLocale      string
+}
+
+// Encoding and locale of every non-template database. The locale provider
+// and ICU locale are read through to_jsonb because the columns appeared in
+// PostgreSQL 15 (daticulocale) and were renamed in 17 (datlocale).
+func (q *Queries) DatabaseLocales(ctx context.Context) ([]DatabaseLocalesRow, error) {
+	rows, err := q.db.Query(ctx, databaseLocales)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []DatabaseLocalesRow
+	for rows.Next() {
+		var i DatabaseLocalesRow
+		if err := rows.Scan(
+			&i.DatabaseName,
+			&i.Encoding,
+			&i.LcCollate,
+			&i.LcCtype,
+			&i.LocaleProvider,
+			&i.IcuLocale,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const duplicateIndexes = `-- name: DuplicateIndexes :many
+WITH index_columns AS (
+  SELECT
+    idx.indexrelid
+    , idx.indrelid
+    , i.relname AS index_name
+    , t.relname AS table_name
+    , n.nspname AS schema_name
+    , idx.indkey::int [] AS column_positions
+    , idx.indnkeyatts AS num_key_columns
+    -- Extract column list as array for prefix comparison
+    , pg_get_indexdef(idx.indexrelid) AS index_def
+    , pg_relation_size(i.oid) AS index_size_bytes
+    -- Detect expression/partial indexes (cannot reliably compare)
+    , (idx.indexprs IS NOT NULL) AS is_expression_index
+    , (idx.indpred IS NOT NULL) AS is_partial_index
+  FROM pg_index AS idx
+  INNER JOIN pg_class AS i ON idx.indexrelid = i.oid
+  INNER JOIN pg_class AS t ON idx.indrelid = t.oid
+  INNER JOIN pg_namespace AS n ON t.relnamespace = n.oid
+  WHERE
+    i.relkind = 'i'
+    AND idx.indisvalid
+    AND n.nspname NOT IN ('pg_catalog', 'information_schema', 'pg_toast', 'cron', 'pgpartman', 'debezium')
+)
+
+, exact_duplicates AS (
+  -- Find indexes with identical definitions (after removing index name)
+  SELECT
+    a.schema_name
+    , a.table_name
+    , a.index_name AS index_name_a
+    , b.index_name AS index_name_b
+    , a.index_size_bytes AS size_a
+    , b.index_size_bytes AS size_b
+    , a.index_def AS definition_a
+    , 'exact' AS duplicate_type
+  FROM index_columns AS a
+  INNER JOIN index_columns AS b ON
+    a.indrelid = b.indrelid
+    AND a.indexrelid < b.indexrelid
+    AND regexp_replace(a.index_def, 'INDEX \S+ ON', 'INDEX ON', 'g')
+    = regexp_replace(b.index_def, 'INDEX \S+ ON', 'INDEX ON', 'g')
+)
+
+, prefix_duplicates AS (
+  -- Find indexes where one is a left-prefix of another
+  -- e.g., (a) is prefix of (a, b)
+  SELECT
+    a.schema_name
+    , a.table_name
+    , a.index_name AS index_name_a
+    , b.index_name AS index_name_b
+    , a.index_size_bytes AS size_a
+    , b.index_size_bytes AS size_b
+    , a.index_def AS definition_a
+    , 'prefix' AS duplicate_type
+  FROM index_columns AS a
+  INNER JOIN index_columns AS b ON
+    a.indrelid = b.indrelid
+    AND a.indexrelid <> b.indexrelid
+    AND a.num_key_columns < b.num_key_columns
+    AND a.column_positions = b.column_positions[0:a.num_key_columns]
+    AND NOT a.is_expression_index
+    AND NOT b.is_expression_index
+    AND NOT a.is_partial_index
+    AND NOT b.is_partial_index
+)
+
+SELECT
+  (schema_name || '.' || table_name)::text AS table_name
+  , index_name_a::text
+  , index_name_b::text
+  , size_a
+  , size_b
+  , definition_a::text
+  , duplicate_type::text
+FROM (
+  SELECT
+    schema_name
+    , table_name
+    , index_name_a
+    , index_name_b
+    , size_a
+    , size_b
+    , definition_a
+    , duplicate_type
+  FROM exact_duplicates
+  UNION ALL
+  SELECT
+    schema_name
+    , table_name
+    , index_name_a
+    , index_name_b
+    , size_a
+    , size_b
+    , definition_a
+    , duplicate_type
+  FROM prefix_duplicates
+) AS all_duplicates
+ORDER BY
+  size_a + size_b DESC
+`
+
+type DuplicateIndexesRow struct {
+	TableName     pgtype.Text
+	IndexNameA    pgtype.Text
+	IndexNameB    pgtype.Text
+	SizeA         pgtype.Int8
+	SizeB         pgtype.Int8
+	DefinitionA   pgtype.Text
+	DuplicateType pgtype.Text
+}
+
+// Identifies exact and prefix duplicate indexes on the same table.
+// Uses index column positions (indkey) for prefix detection.
+// Excludes: system schemas, invalid indexes, expression/partial indexes for prefix check.
+func (q *Queries) DuplicateIndexes(ctx context.Context) ([]DuplicateIndexesRow, error) {
+	rows, err := q.db.Query(ctx, duplicateIndexes)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []DuplicateIndexesRow
+	for rows.Next() {
+		var i DuplicateIndexesRow
+		if err := rows.Scan(
+			&i.TableName,
+			&i.IndexNameA,
+			&i.IndexNameB,
+			&i.SizeA,
+			&i.SizeB,
+			&i.DefinitionA,
+			&i.DuplicateType,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const durabilitySynchronousCommit = `-- name: DurabilitySynchronousCommit :many
+SELECT
+  'server'::text AS scope
+  , ''::text AS database_name
+  , ''::text AS role_name
+  , s.source::text AS source
+FROM pg_settings AS s
+WHERE
+  s.name = 'synchronous_commit'
+  AND s.setting = 'off'
+  AND s.source NOT IN ('database', 'user', 'database user', 'session', 'client')
+UNION ALL
+SELECT
+  (CASE
+    WHEN d.datname IS NOT NULL AND r.rolname IS NOT NULL THEN 'database and role'
+    WHEN d.datname IS NOT NULL THEN 'database'
+    ELSE 'role'
+  END)::text AS scope
+  , COALESCE(d.datname, '')::text AS database_name
+  , COALESCE(r.rolname, '')::text AS role_name
+  , 'ALTER ... SET'::text AS source
+FROM pg_db_role_setting AS rs
+CROSS JOIN LATERAL UNNEST(rs.setconfig) AS cfg (setting)
+LEFT JOIN pg_database AS d ON rs.setdatabase = d.oid
+LEFT JOIN pg_roles AS r ON rs.setrole = r.oid
+WHERE cfg.setting = 'synchronous_commit=off'
+ORDER BY scope DESC, database_name, role_name
+`
+
+type DurabilitySynchronousCommitRow struct {
+	Scope        string
+	DatabaseName string
+	RoleName     string
+	Source       string
+}
+
+// Places where synchronous_commit is off: the server setting (unless it
+// comes from a database or role override) and ALTER DATABASE/ROLE ... SET.
+func (q *Queries) DurabilitySynchronousCommit(ctx context.Context) ([]DurabilitySynchronousCommitRow, error) {
+	rows, err := q.db.Query(ctx, durabilitySynchronousCommit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []DurabilitySynchronousCommitRow
+	for rows.Next() {
+		var i DurabilitySynchronousCommitRow
+		if err := rows.Scan(
+			&i.Scope,
+			&i.DatabaseName,
+			&i.RoleName,
+			&i.Source,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const durabilityTables = `-- name: DurabilityTables :many
+SELECT
+  n.nspname::text AS schema_name
+  , c.relname::text AS table_name
+  , (c.relpersistence = 'u') AS is_unlogged
+  , COALESCE(SUBSTRING(ARRAY_TO_STRING(c.reloptions, ',') FROM 'fillfactor=([0-9]+)')::integer, 0)::integer AS fillfactor
+  , PG_TABLE_SIZE(c.oid)::bigint AS table_size_bytes
+  , GREATEST(c.reltuples, 0)::bigint AS estimated_rows
+  , COALESCE(s.n_tup_ins, 0)::bigint AS inserts
+  , COALESCE(s.n_tup_upd, 0)::bigint AS updates
+FROM pg_class AS c
+INNER JOIN pg_namespace AS n ON c.relnamespace = n.oid
+LEFT JOIN pg_stat_user_tables AS s ON c.oid = s.relid
+WHERE
+  c.relkind IN ('r', 'p')
+  AND n.nspname NOT IN ('pg_catalog', 'information_schema')
+  AND n.nspname !~ '^pg_'
+  AND (
+    c.relpersistence = 'u'
+    OR ARRAY_TO_STRING(c.reloptions, ',') LIKE '%fillfactor=%'
+  )
+ORDER BY PG_TABLE_SIZE(c.oid) DESC
+LIMIT 200
+`
+
+type DurabilityTablesRow struct {
+	SchemaName     string
+	TableName      string
+	IsUnlogged     bool
+	Fillfactor     int32
+	TableSizeBytes int64
+	EstimatedRows  int64
+	Inserts        int64
+	Updates        int64
+}
+
+// User tables that are unlogged or have a fillfactor set, with the write
+// counters needed to tell whether the reserved page space is used.
+func (q *Queries) DurabilityTables(ctx context.Context) ([]DurabilityTablesRow, error) {
+	rows, err := q.db.Query(ctx, durabilityTables)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []DurabilityTablesRow
+	for rows.Next() {
+		var i DurabilityTablesRow
+		if err := rows.Scan(
+			&i.SchemaName,
+			&i.TableName,
+			&i.IsUnlogged,
+			&i.Fillfactor,
+			&i.TableSizeBytes,
+			&i.EstimatedRows,
+			&i.Inserts,
+			&i.Updates,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const eventTriggers = `-- name: EventTriggers :many
+SELECT
+  e.evtname::text AS trigger_name
+  , e.evtevent::text AS event
+  , e.evtenabled::text AS enabled
+  , COALESCE(ARRAY_TO_STRING(e.evttags, ', '), '')::text AS tags
+  , owner.rolname::text AS owner_name
+  , owner.rolsuper AS owner_is_superuser
+  , (n.nspname || '.' || p.proname)::text AS function_name
+  , fowner.rolname::text AS function_owner_name
+  , fowner.rolsuper AS function_owner_is_superuser
+  , p.prosecdef AS security_definer
+FROM pg_event_trigger AS e
+INNER JOIN pg_roles AS owner ON e.evtowner = owner.oid
+INNER JOIN pg_proc AS p ON e.evtfoid = p.oid
+INNER JOIN pg_namespace AS n ON p.pronamespace = n.oid
+INNER JOIN pg_roles AS fowner ON p.proowner = fowner.oid
+ORDER BY e.evtname
+`
+
+type EventTriggersRow struct {
+	TriggerName              string
+	Event                    string
+	Enabled                  string
+	Tags                     string
+	OwnerName                string
+	OwnerIsSuperuser         bool
+	FunctionName             string
+	FunctionOwnerName        string
+	FunctionOwnerIsSuperuser bool
+	SecurityDefiner          bool
+}
+
+// Event triggers with their owner and the owner and security mode of the function they call.
+func (q *Queries) EventTriggers(ctx context.Context) ([]EventTriggersRow, error) {
+	rows, err := q.db.Query(ctx, eventTriggers)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []EventTriggersRow
+	for rows.Next() {
+		var i EventTriggersRow
+		if err := rows.Scan(
+			&i.TriggerName,
+			&i.Event,
+			&i.Enabled,
+			&i.Tags,
+			&i.OwnerName,
+			&i.OwnerIsSuperuser,
+			&i.FunctionName,
+			&i.FunctionOwnerName,
+			&i.FunctionOwnerIsSuperuser,
+			&i.SecurityDefiner,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const failoverParameterChecks = `-- name: FailoverParameterChecks :many
+SELECT
+  v.name::text AS setting_name
+  , s.setting::bigint AS local_value
+  , v.primary_value::bigint AS primary_value
+FROM PG_CONTROL_RECOVERY() AS c
+CROSS JOIN LATERAL (
+  VALUES
+  ('max_connections', c.max_connections_setting)
+  , ('max_worker_processes', c.max_worker_processes_setting)
+  , ('max_wal_senders', c.max_wal_senders_setting)
+  , ('max_prepared_transactions', c.max_prepared_xacts_setting)
+  , ('max_locks_per_transaction', c.max_locks_per_xact_setting)
+) AS v (name, primary_value)
+INNER JOIN pg_settings AS s ON v.name = s.name
+ORDER BY v.name
+`
+
+type FailoverParameterChecksRow struct {
+	SettingName  string
+	LocalValue   int64
+	PrimaryValue int64
+}
+
+// On a standby, the control file records the primary's values of the
+// settings a hot standby must match or exceed.
+func (q *Queries) FailoverParameterChecks(ctx context.Context) ([]FailoverParameterChecksRow, error) {
+	rows, err := q.db.Query(ctx, failoverParameterChecks)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []FailoverParameterChecksRow
+	for rows.Next() {
+		var i FailoverParameterChecksRow
+		if err := rows.Scan(&i.SettingName, &i.LocalValue, &i.PrimaryValue); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const failoverSettings = `-- name: FailoverSettings :one
+SELECT
+  PG_IS_IN_RECOVERY() AS in_recovery
+  , current_setting('server_version_num')::integer AS server_version_num
+  , current_setting('archive_mode')::text AS archive_mode
+  , (
+    current_setting('archive_command') NOT IN ('', '(disabled)')
+    OR COALESCE(current_setting('archive_library', true), '') <> ''
+  ) AS archiver_configured
+  , COALESCE(current_setting('restore_command', true), '')::text AS restore_command
+  , COALESCE(current_setting('primary_slot_name', true), '')::text AS primary_slot_name
+  , a.archived_count::bigint AS archived_count
+  , a.failed_count::bigint AS failed_count
+  , COALESCE(EXTRACT(EPOCH FROM NOW() - a.last_archived_time), -1)::bigint AS last_archived_age_seconds
+  , COALESCE(EXTRACT(EPOCH FROM NOW() - a.last_failed_time), -1)::bigint AS last_failed_age_seconds
+FROM pg_stat_archiver AS a
+`
+
+type FailoverSettingsRow struct {
+	InRecovery             bool
+	ServerVersionNum       int32
+	ArchiveMode            string
+	ArchiverConfigured     bool
+	RestoreCommand         string
+	PrimarySlotName        string
+	ArchivedCount          int64
+	FailedCount            int64
+	LastArchivedAgeSeconds int64
+	LastFailedAgeSeconds   int64
+}
+
+// Server role, WAL archiving, and recovery settings relevant to failover.
+func (q *Queries) FailoverSettings(ctx context.Context) (FailoverSettingsRow, error) {
+	row := q.db.QueryRow(ctx, failoverSettings)
+	var i FailoverSettingsRow
+	err := row.Scan(
+		&i.InRecovery,
+		&i.ServerVersionNum,
+		&i.ArchiveMode,
+		&i.ArchiverConfigured,
+		&i.RestoreCommand,
+		&i.PrimarySlotName,
+		&i.ArchivedCount,
+		&i.FailedCount,
+		&i.LastArchivedAgeSeconds,
+		&i.LastFailedAgeSeconds,
+	)
+	return i, err
+}
+
+const failoverSlots = `-- name: FailoverSlots :many
+SELECT
+  rs.slot_name::text AS slot_name
+  , rs.slot_type::text AS slot_type
+  , rs.active
+  , COALESCE(rs.wal_status, '')::text AS wal_status
+  , COALESCE((TO_JSONB(rs) ->> 'failover')::boolean, false) AS failover
+  , COALESCE(PG_WAL_LSN_DIFF(
+    CASE WHEN PG_IS_IN_RECOVERY() THEN PG_LAST_WAL_REPLAY_LSN() ELSE PG_CURRENT_WAL_LSN() END
+    , rs.restart_lsn
+  ), 0)::bigint AS retained_bytes
+FROM pg_replication_slots AS rs
+WHERE NOT rs.temporary
+ORDER BY rs.slot_type, rs.slot_name
+`
+
+type FailoverSlotsRow struct {
+	SlotName      string
+	SlotType      string
+	Active        bool
+	WalStatus     string
+	Failover      bool
+	RetainedBytes int64
+}
+
+// Replication slots with WAL retention status and, on PostgreSQL 17+,
+// whether logical slots are synchronized to standbys for failover.
+func (q *Queries) FailoverSlots(ctx context.Context) ([]FailoverSlotsRow, error) {
+	rows, err := q.db.Query(ctx, failoverSlots)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []FailoverSlotsRow
+	for rows.Next() {
+		var i FailoverSlotsRow
+		if err := rows.Scan(
+			&i.SlotName,
+			&i.SlotType,
+			&i.Active,
+			&i.WalStatus,
+			&i.Failover,
+			&i.RetainedBytes,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const failoverStandbys = `-- name: FailoverStandbys :many
+SELECT
+  application_name::text AS application_name
+  , state::text AS state
+  , COALESCE(sync_state, 'async')::text AS sync_state
+  , COALESCE(PG_WAL_LSN_DIFF(
+    CASE WHEN PG_IS_IN_RECOVERY() THEN PG_LAST_WAL_REPLAY_LSN() ELSE PG_CURRENT_WAL_LSN() END
+    , replay_lsn
+  ), -1)::bigint AS replay_lag_bytes
+  , COALESCE(EXTRACT(EPOCH FROM replay_lag), 0)::float8 AS replay_lag_seconds
+  , COALESCE(EXTRACT(EPOCH FROM NOW() - reply_time), -1)::float8 AS reply_age_seconds
+FROM pg_stat_replication
+ORDER BY replay_lag_bytes, application_name
+`
+
+type FailoverStandbysRow struct {
+	ApplicationName  string
+	State            string
+	SyncState        string
+	ReplayLagBytes   int64
+	ReplayLagSeconds float64
+	ReplyAgeSeconds  float64
+}
+
+// Streaming standbys with their sync role, replay lag, and last feedback.
+func (q *Queries) FailoverStandbys(ctx context.Context) ([]FailoverStandbysRow, error) {
+	rows, err := q.db.Query(ctx, failoverStandbys)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []FailoverStandbysRow
+	for rows.Next() {
+		var i FailoverStandbysRow
+		if err := rows.Scan(
+			&i.ApplicationName,
+			&i.State,
+			&i.SyncState,
+			&i.ReplayLagBytes,
+			&i.ReplayLagSeconds,
+			&i.ReplyAgeSeconds,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const failsafeFreezeAge = `-- name: FailsafeFreezeAge :many
+SELECT
+  n.nspname::text AS schema_name
+  , COALESCE(owner.relname, c.relname)::text AS relname
+  , (
+    n.nspname || '.' || COALESCE(owner.relname, c.relname)
+    || CASE WHEN c.relkind = 't' THEN ' (TOAST)' ELSE '' END
+  )::text AS table_name
+  , s.last_autovacuum
+  , s.last_vacuum
+  , age(c.relfrozenxid) AS freeze_age
+FROM pg_class AS c
+LEFT JOIN pg_class AS owner ON c.relkind = 't' AND owner.reltoastrelid = c.oid
+INNER JOIN pg_namespace AS n ON COALESCE(owner.relnamespace, c.relnamespace) = n.oid
+LEFT JOIN pg_stat_all_tables AS s ON COALESCE(owner.oid, c.oid) = s.relid
+WHERE
+  c.relkind IN ('r', 'm', 't')
+  AND c.relfrozenxid != '0'
+  AND n.nspname NOT IN ('pg_catalog', 'information_schema')
+  AND n.nspname !~ '^pg_(toast|temp_)'
+  AND ($1::text = '' OR n.nspname = $1::text)
+ORDER BY age(c.relfrozenxid) DESC
+LIMIT 50
+`
+
+type FailsafeFreezeAgeRow struct {
+	SchemaName     pgtype.Text
+	Relname        pgtype.Text
+	TableName      pgtype.Text
+	LastAutovacuum pgtype.Timestamptz
+	LastVacuum     pgtype.Timestamptz
+	FreezeAge      pgtype.Int4
+}
+
+// Gets the relations with the oldest frozen XIDs across all user schemas, or
+// only schema_name when it is set. Vacuum's failsafe triggers on any relation
+// with a relfrozenxid, so this covers materialized views and TOAST tables,
+// which are named and scoped by the table that owns them.
+func (q *Queries) FailsafeFreezeAge(ctx context.Context, schemaName string) ([]FailsafeFreezeAgeRow, error) {
+	rows, err := q.db.Query(ctx, failsafeFreezeAge, schemaName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []FailsafeFreezeAgeRow
+	for rows.Next() {
+		var i FailsafeFreezeAgeRow
+		if err := rows.Scan(
+			&i.SchemaName,
+			&i.Relname,
+			&i.TableName,
+			&i.LastAutovacuum,
+			&i.LastVacuum,
+			&i.FreezeAge,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const hasPgStatMonitor = `-- name: HasPgStatMonitor :one
+SELECT EXISTS(
+  SELECT 1 FROM pg_extension
+  WHERE extname = 'pg_stat_monitor'
+)
+`
+
+// Checks if the pg_stat_monitor extension (Percona) is installed.
+func (q *Queries) HasPgStatMonitor(ctx context.Context) (bool, error) {
+	row := q.db.QueryRow(ctx, hasPgStatMonitor)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
+
+const hasPgStatStatements = `-- name: HasPgStatStatements :one
+SELECT EXISTS(
+  SELECT 1 FROM pg_extension
+  WHERE extname = 'pg_stat_statements'
+)
+`
+
+// Checks if pg_stat_statements extension is installed.
+func (q *Queries) HasPgStatStatements(ctx context.Context) (bool, error) {
+	row := q.db.QueryRow(ctx, hasPgStatStatements)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
+
+const hbaRules = `-- name: HbaRules :many
+SELECT
+  h.line_number::integer AS line_number
+  , h.type::text AS connection_type
+  , ARRAY_TO_STRING(h.database, ',')::text AS database_names
+  , ARRAY_TO_STRING(h.user_name, ',')::text AS user_names
+  , COALESCE(h.address, '')::text AS address
+  , COALESCE(h.netmask, '')::text AS netmask
+  , h.auth_method::text AS auth_method
+FROM pg_hba_file_rules AS h
+WHERE h.error IS NULL
+ORDER BY h.line_number
+`
+
+type HbaRulesRow struct {
+	LineNumber     int32
+	ConnectionType string
+	DatabaseNames  string
+	UserNames      string
+	Address        string
+	Netmask        string
+	AuthMethod     string
+}
+
+// Parsed pg_hba.conf entries, in file order. Lines with errors are skipped:
+// the server refuses to reload a file containing them.
+func (q *Queries) HbaRules(ctx context.Context) ([]HbaRulesRow, error) {
+	rows, err := q.db.Query(ctx, hbaRules)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []HbaRulesRow
+	for rows.Next() {
+		var i HbaRulesRow
+		if err := rows.Scan(
+			&i.LineNumber,
+			&i.ConnectionType,
+			&i.DatabaseNames,
+			&i.UserNames,
+			&i.Address,
+			&i.Netmask,
+			&i.AuthMethod,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const highSeqScanTables = `-- name: HighSeqScanTables :many
+WITH table_indexes AS (
+  SELECT
+    idx.indrelid AS table_oid
+    , count(*) AS index_count
+  FROM pg_index AS idx
+  WHERE idx.indisvalid
+  GROUP BY idx.indrelid
+)
+
+SELECT
+  (n.nspname || '.' || c.relname)::text AS table_name
+  , coalesce(s.seq_scan, 0) AS seq_scan
+  , coalesce(s.idx_scan, 0) AS idx_scan
+  , CASE
+    WHEN coalesce(s.idx_scan, 0) = 0 THEN NULL
+    ELSE round(s.seq_scan::numeric / s.idx_scan, 2)
+  END AS seq_to_idx_ratio
+  , coalesce(s.n_live_tup, 0) AS estimated_rows
+  , pg_relation_size(c.oid) AS table_size_bytes
+  , coalesce(ti.index_count, 0) AS index_count
+FROM pg_class AS c
+INNER JOIN pg_namespace AS n ON c.relnamespace = n.oid
+LEFT JOIN pg_stat_user_tables AS s ON c.oid = s.relid
+LEFT JOIN table_indexes AS ti ON c.oid = ti.table_oid
+WHERE
+  c.relkind IN ('r', 'p')
+  AND n.nspname = $1::text
+  AND coalesce(s.n_live_tup, 0) > 10000
+  AND coalesce(s.seq_scan, 0) > 100
+ORDER BY
+  coalesce(s.seq_scan, 0) DESC
+`
+
+type HighSeqScanTablesRow struct {
+	TableName      pgtype.Text
+	SeqScan        pgtype.Int8
+	IdxScan        pgtype.Int8
+	SeqToIdxRatio  pgtype.Numeric
+	EstimatedRows  pgtype.Int8
+	TableSizeBytes pgtype.Int8
+	IndexCount     pgtype.Int8
+}
+
+// Identifies tables with excessive sequential scans relative to index scans.
+// Excludes: small tables, system schemas, tables with no indexes.
+func (q *Queries) HighSeqScanTables(ctx context.Context, schemaName string) ([]HighSeqScanTablesRow, error) {
+	rows, err := q.db.Query(ctx, highSeqScanTables, schemaName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []HighSeqScanTablesRow
+	for rows.Next() {
+		var i HighSeqScanTablesRow
+		if err := rows.Scan(
+			&i.TableName,
+			&i.SeqScan,
+			&i.IdxScan,
+			&i.SeqToIdxRatio,
+			&i.EstimatedRows,
+			&i.TableSizeBytes,
+			&i.IndexCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const iOTiming = `-- name: IOTiming :one
+SELECT
+  coalesce(sum(reads), 0)::bigint AS reads
+  , coalesce(sum(read_bytes), 0)::bigint AS read_bytes
+  , coalesce(sum(read_time), 0)::double precision AS read_time_ms
+  , coalesce(extract(EPOCH FROM (now() - min(stats_reset))), 0)::bigint AS seconds_since_reset
+FROM pg_stat_io
+WHERE backend_type = 'client backend' AND object = 'relation'
+`
+
+type IOTimingRow struct {
+	Reads             int64
+	ReadBytes         int64
+	ReadTimeMs        float64
+	SecondsSinceReset int64
+}
+
+// Relation reads by client backends on PostgreSQL 18+, where pg_stat_io
+// reports bytes directly and no longer has op_bytes.
+func (q *Queries) IOTiming(ctx context.Context) (IOTimingRow, error) {
+	row := q.db.QueryRow(ctx, iOTiming)
+	var i IOTimingRow
+	err := row.Scan(
+		&i.Reads,
+		&i.ReadBytes,
+		&i.ReadTimeMs,
+		&i.SecondsSinceReset,
+	)
+	return i, err
+}
+
+const iOTimingSettings = `-- name: IOTimingSettings :one
+SELECT
+  current_setting('server_version_num')::integer AS server_version_num
+  , current_setting('track_io_timing')::boolean AS track_io_timing
+`
+
+type IOTimingSettingsRow struct {
+	ServerVersionNum int32
+	TrackIoTiming    bool
+}
+
+// Server version, to pick the pg_stat_io query, and whether I/O timing is
+// collected at all.
+func (q *Queries) IOTimingSettings(ctx context.Context) (IOTimingSettingsRow, error) {
+	row := q.db.QueryRow(ctx, iOTimingSettings)
+	var i IOTimingSettingsRow
+	err := row.Scan(&i.ServerVersionNum, &i.TrackIoTiming)
+	return i, err
+}
+
+const idleInTransaction = `-- name: IdleInTransaction :many
+SELECT
+  pg_stat_activity.pid
+  , pg_stat_activity.usename::text AS username
+  , pg_stat_activity.datname::text AS database_name
+  , pg_stat_activity.application_name::text AS application_name
+  , pg_stat_activity.state::text AS state
+  , extract(EPOCH FROM (now() - pg_stat_activity.xact_start))::bigint AS transaction_duration_seconds
+  , left(pg_stat_activity.query, 200)::text AS query_preview
+  , coalesce((
+    SELECT pg_settings.setting::bigint
+    FROM pg_settings
+    WHERE pg_settings.name = 'idle_in_transaction_session_timeout'
+  ), 0) AS timeout_ms
+FROM pg_stat_activity
+WHERE
+  pg_stat_activity.state IN ('idle in transaction', 'idle in transaction (aborted)')
+  AND pg_stat_activity.pid != pg_backend_pid()
+ORDER BY pg_stat_activity.xact_start ASC
+`
+
+type IdleInTransactionRow struct {
+	Pid                        pgtype.Int4
+	Username                   pgtype.Text
+	DatabaseName               pgtype.Text
+	ApplicationName            pgtype.Text
+	State                      pgtype.Text
+	TransactionDurationSeconds pgtype.Int8
+	QueryPreview               pgtype.Text
+	TimeoutMs                  pgtype.Int8
+}
+
+// Identifies connections stuck in 'idle in transaction' state.
+// Includes the timeout setting (in ms) for threshold calculation in Go.
+func (q *Queries) IdleInTransaction(ctx context.Context) ([]IdleInTransactionRow, error) {
+	rows, err := q.db.Query(ctx, idleInTransaction)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []IdleInTransactionRow
+	for rows.Next() {
+		var i IdleInTransactionRow
+		if err := rows.Scan(
+			&i.Pid,
+			&i.Username,
+			&i.DatabaseName,
+			&i.ApplicationName,
+			&i.State,
+			&i.TransactionDurationSeconds,
+			&i.QueryPreview,
+			&i.TimeoutMs,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const inactiveDatabases = `-- name: InactiveDatabases :many
+SELECT
+  d.datname::text AS database_name
+  , CASE
+    WHEN HAS_DATABASE_PRIVILEGE(d.oid, 'CONNECT') OR PG_HAS_ROLE('pg_read_all_stats', 'MEMBER')
+      THEN PG_DATABASE_SIZE(d.oid)
+  END::bigint AS size_bytes
+  , (sd.xact_commit + sd.xact_rollback)::bigint AS transactions
+  , EXTRACT(EPOCH FROM NOW() - COALESCE(sd.stats_reset, PG_POSTMASTER_START_TIME()))::float8 AS stats_age_seconds
+FROM pg_database AS d
+INNER JOIN pg_stat_database AS sd ON d.oid = sd.datid
+WHERE
+  NOT d.datistemplate
+  AND d.datallowconn
+  AND d.datname NOT IN ('postgres', CURRENT_DATABASE())
+  AND sd.numbackends = 0
+ORDER BY d.datname
+`
+
+type InactiveDatabasesRow struct {
+	DatabaseName    string
+	SizeBytes       pgtype.Int8
+	Transactions    int64
+	StatsAgeSeconds float64
+}
+
+// Connectable databases other than the current one with no open connections,
+// with their transactions and the age of their statistics. The size is NULL
+// when the role can neither connect to the database nor read all stats.
+func (q *Queries) InactiveDatabases(ctx context.Context) ([]InactiveDatabasesRow, error) {
+	rows, err := q.db.Query(ctx, inactiveDatabases)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []InactiveDatabasesRow
+	for rows.Next() {
+		var i InactiveDatabasesRow
+		if err := rows.Scan(
+			&i.DatabaseName,
+			&i.SizeBytes,
+			&i.Transactions,
+			&i.StatsAgeSeconds,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const indexBloat = `-- name: IndexBloat :many
+WITH index_info AS (
+  SELECT
+    n.nspname::text AS schemaname
+    , t.relname::text AS tablename
+    , i.relname::text AS indexname
+    , t.oid AS table_oid
+    , ix.indexrelid AS index_oid
+    , i.relpages AS actual_pages
+    , i.reltuples
+    , ix.indkey
+    , CURRENT_SETTING('block_size')::int AS bs
+    , COALESCE(
+      SUBSTRING(ARRAY_TO_STRING(i.reloptions, ' ') FROM 'fillfactor=([0-9]+)')::int
+      , 90
+    ) AS fill_factor
+  FROM pg_index AS ix
+  INNER JOIN pg_class AS i ON ix.indexrelid = i.oid
+  INNER JOIN pg_class AS t ON ix.indrelid = t.oid
+  INNER JOIN pg_namespace AS n ON i.relnamespace = n.oid
+  INNER JOIN pg_am AS am ON i.relam = am.oid
+  WHERE
+    am.amname = 'btree'
+    AND n.nspname NOT IN ('pg_catalog', 'information_schema', 'pg_toast')
+    AND i.relpages > 100  -- Skip tiny indexes (<800KB)
+    AND ix.indisvalid
+    AND i.reltuples > 0
+)
+
+, index_columns AS (
+  SELECT
+    ii.schemaname
+    , ii.tablename
+    , ii.indexname
+    , ii.index_oid
+    , ii.actual_pages
+    , ii.reltuples
+    , ii.fill_factor
+    , ii.bs
+    -- Sum avg_width from pg_stats for indexed columns
+    -- Fallback to 24 bytes if no stats (reasonable for UUID/timestamp)
+    , COALESCE(
+      (
+        SELECT SUM(COALESCE(s.avg_width, 8))
+        FROM UNNEST(ii.indkey) WITH ORDINALITY AS u (attnum, ord)
+        INNER JOIN pg_attribute AS a ON a.attrelid = ii.table_oid AND u.attnum = a.attnum
+        LEFT JOIN pg_stats AS s
+          ON
+            s.schemaname = ii.schemaname
+            AND s.tablename = ii.tablename
+            AND a.attname = s.attname
+        WHERE u.attnum > 0
+      )
+      , 24
+    ) AS data_width
+  FROM index_info AS ii
+)
+
+, bloat_calc AS (
+  SELECT
+    schemaname
+    , tablename
+    , indexname
+    , index_oid
+    , actual_pages
+    , reltuples
+    , bs
+    , fill_factor
+    , data_width
+    -- Index tuple size: ItemPointer(6) + info(2) + data ≈ 8 + data_width
+    -- Simplified: skip per-column MAXALIGN, add ~20% padding estimate
+    , CEIL((8 + data_width) * 1.2) AS tuple_size
+    -- Usable space: block_size - PageHeader(24) - BTPageOpaque(16), apply fill_factor
+    , FLOOR((bs - 40) * fill_factor / 100.0) AS usable_space
+  FROM index_columns
+)
+
+, bloat_estimate AS (
+  SELECT
+    schemaname
+    , tablename
+    , indexname
+    , index_oid
+    , actual_pages
+    , bs
+    , fill_factor
+    -- Expected pages = ceil(tuples / (usable_space / (line_pointer(4) + tuple_size)))
+    , GREATEST(1, CEIL(reltuples / FLOOR(usable_space / (4 + tuple_size))))::bigint AS est_pages
+    , (actual_pages::bigint * bs) AS actual_bytes
+  FROM bloat_calc
+  WHERE tuple_size > 0 AND usable_space > (4 + tuple_size)
+)
+
+SELECT
+  be.schemaname
+  , be.tablename
+  , be.indexname
+  , be.actual_pages
+  , be.est_pages
+  , be.actual_bytes
+  , ((be.actual_pages - be.est_pages)::bigint * be.bs) AS bloat_bytes
+  , CASE
+    WHEN be.actual_pages > 0 AND be.actual_pages > be.est_pages
+      THEN ROUND(100.0 * (be.actual_pages - be.est_pages) / be.actual_pages, 1)
+    ELSE 0
+  END AS bloat_percent
+  -- Usage over the stats window, used to keep constantly-hit indexes out of the REINDEX schedule
+  , COALESCE(sui.idx_scan, 0)::bigint AS idx_scan
+  , EXTRACT(EPOCH FROM NOW() - COALESCE(sd.stats_reset, PG_POSTMASTER_START_TIME()))::bigint AS stats_age_seconds
+  , be.fill_factor
+FROM bloat_estimate AS be
+LEFT JOIN pg_stat_user_indexes AS sui ON be.index_oid = sui.indexrelid
+LEFT JOIN pg_stat_database AS sd ON sd.datname = CURRENT_DATABASE()
+WHERE be.actual_pages > be.est_pages
+ORDER BY bloat_percent DESC, bloat_bytes DESC
+`
+
+type IndexBloatRow struct {
+	Schemaname      pgtype.Text
+	Tablename       pgtype.Text
+	Indexname       pgtype.Text
+	ActualPages     int32
+	EstPages        pgtype.Int8
+	ActualBytes     pgtype.Int8
+	BloatBytes      pgtype.Int8
+	BloatPercent    pgtype.Numeric
+	IdxScan         pgtype.Int8
+	StatsAgeSeconds pgtype.Int8
+	FillFactor      int32
+}
+
+// Balanced B-tree index bloat estimation using pg_stats column widths
+// Accuracy: ±15% (good enough for health checks, not precision measurement)
+func (q *Queries) IndexBloat(ctx context.Context) ([]IndexBloatRow, error) {
+	rows, err := q.db.Query(ctx, indexBloat)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []IndexBloatRow
+	for rows.Next() {
+		var i IndexBloatRow
+		if err := rows.Scan(
+			&i.Schemaname,
+			&i.Tablename,
+			&i.Indexname,
+			&i.ActualPages,
+			&i.EstPages,
+			&i.ActualBytes,
+			&i.BloatBytes,
+			&i.BloatPercent,
+			&i.IdxScan,
+			&i.StatsAgeSeconds,
+			&i.FillFactor,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const indexCorrelation = `-- name: IndexCorrelation :many
+SELECT
+  (n.nspname || '.' || t.relname)::text AS table_name
+  , i.relname::text AS index_name
+  , a.attname::text AS column_name
+  , s.correlation::float8 AS correlation
+  , pg_table_size(t.oid)::bigint AS table_bytes
+  , pg_relation_size(i.oid)::bigint AS index_bytes
+  , COALESCE(psi.idx_scan, 0)::bigint AS idx_scan
+  , COALESCE(psi.idx_tup_read, 0)::bigint AS idx_tup_read
+  , (COALESCE(pst.n_tup_upd, 0) + COALESCE(pst.n_tup_del, 0))::bigint AS table_churn
+FROM pg_index AS x
+INNER JOIN pg_class AS i ON x.indexrelid = i.oid
+INNER JOIN pg_am AS am ON i.relam = am.oid
+INNER JOIN pg_class AS t ON x.indrelid = t.oid
+INNER JOIN pg_namespace AS n ON t.relnamespace = n.oid
+INNER JOIN pg_attribute AS a ON a.attrelid = t.oid AND a.attnum = x.indkey[0]
+INNER JOIN pg_stats AS s
+  ON s.schemaname = n.nspname AND s.tablename = t.relname AND s.attname = a.attname
+LEFT JOIN pg_stat_user_indexes AS psi ON x.indexrelid = psi.indexrelid
+LEFT JOIN pg_stat_user_tables AS pst ON t.oid = pst.relid
+WHERE
+  am.amname = 'btree'
+  AND x.indkey[0] <> 0
+  AND s.correlation IS NOT NULL
+  AND NOT s.inherited
+  AND n.nspname NOT IN ('pg_catalog', 'information_schema')
+  AND n.nspname !~ '^pg_toast'
+ORDER BY pg_table_size(t.oid) DESC, i.relname
+LIMIT 100
+`
+
+type IndexCorrelationRow struct {
+	TableName   string
+	IndexName   string
+	ColumnName  string
+	Correlation float64
+	TableBytes  int64
+	IndexBytes  int64
+	IdxScan     int64
+	IdxTupRead  int64
+	TableChurn  int64
+}
+
+// Physical-order correlation of the leading column of each B-tree index on the
+// largest tables, with scan statistics to tell range scans from point lookups.
+// Excludes: expression indexes and columns without statistics.
+func (q *Queries) IndexCorrelation(ctx context.Context) ([]IndexCorrelationRow, error) {
+	rows, err := q.db.Query(ctx, indexCorrelation)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []IndexCorrelationRow
+	for rows.Next() {
+		var i IndexCorrelationRow
+		if err := rows.Scan(
+			&i.TableName,
+			&i.IndexName,
+			&i.ColumnName,
+			&i.Correlation,
+			&i.TableBytes,
+			&i.IndexBytes,
+			&i.IdxScan,
+			&i.IdxTupRead,
+			&i.TableChurn,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const indexDropSafety = `-- name: IndexDropSafety :many
+SELECT
+  n.nspname::text AS schema_name
+  , ic.relname::text AS index_name
+  , coalesce((
+    SELECT array_agg(con.conname::text ORDER BY con.conname)
+    FROM pg_constraint AS con
+    WHERE con.conindid = x.indexrelid
+  ), '{}')::text [] AS constraints
+  , x.indisreplident AS is_replica_identity
+  , coalesce((
+    SELECT pn.nspname || '.' || pc.relname
+    FROM pg_inherits AS inh
+    INNER JOIN pg_class AS pc ON inh.inhparent = pc.oid
+    INNER JOIN pg_namespace AS pn ON pc.relnamespace = pn.oid
+    WHERE inh.inhrelid = x.indexrelid
+  ), '')::text AS partition_of
+  -- A foreign key is supported by an index whose leading columns are exactly
+  -- its referencing columns; indkey is zero-based.
+  , coalesce((
+    SELECT array_agg(fk.conname::text ORDER BY fk.conname)
+    FROM pg_constraint AS fk
+    WHERE
+      fk.contype = 'f'
+      AND fk.conrelid = x.indrelid
+      AND x.indpred IS NULL
+      AND (x.indkey::int2 [])[0:cardinality(fk.conkey) - 1] @> fk.conkey
+      AND (x.indkey::int2 [])[0:cardinality(fk.conkey) - 1] <@ fk.conkey
+      AND NOT EXISTS (
+        SELECT 1
+        FROM pg_index AS o
+        WHERE
+          o.indrelid = x.indrelid
+          AND o.indexrelid <> x.indexrelid
+          AND o.indisvalid
+          AND o.indpred IS NULL
+          AND (o.indkey::int2 [])[0:cardinality(fk.conkey) - 1] @> fk.conkey
+          AND (o.indkey::int2 [])[0:cardinality(fk.conkey) - 1] <@ fk.conkey
+      )
+  ), '{}')::text [] AS sole_foreign_key_index
+FROM pg_index AS x
+INNER JOIN pg_class AS ic ON x.indexrelid = ic.oid
+INNER JOIN pg_namespace AS n ON ic.relnamespace = n.oid
+WHERE
+  n.nspname = $1::text
+`
+
+type IndexDropSafetyRow struct {
+	SchemaName          string
+	IndexName           string
+	Constraints         []string
+	IsReplicaIdentity   bool
+	PartitionOf         string
+	SoleForeignKeyIndex []string
+}
+
+// Catalog facts that make dropping an index unsafe: the constraints it
+// enforces or that reference it, replica identity, the partitioned index it is
+// a partition of, and foreign keys for which it is the only supporting index.
+// Returns data for subchecks: unused-indexes, low-cardinality-indexes.
+func (q *Queries) IndexDropSafety(ctx context.Context, schemaName string) ([]IndexDropSafetyRow, error) {
+	rows, err := q.db.Query(ctx, indexDropSafety, schemaName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []IndexDropSafetyRow
+	for rows.Next() {
+		var i IndexDropSafetyRow
+		if err := rows.Scan(
+			&i.SchemaName,
+			&i.IndexName,
+			&i.Constraints,
+			&i.IsReplicaIdentity,
+			&i.PartitionOf,
+			&i.SoleForeignKeyIndex,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const indexLeafDensity = `-- name: IndexLeafDensity :one
+SELECT
+  index_size
+  , leaf_pages
+  , empty_pages
+  , deleted_pages
+  , avg_leaf_density
+  , CURRENT_SETTING('block_size')::bigint AS block_size
+FROM PGSTATINDEX(FORMAT('%I.%I', $1::text, $2::text)::regclass)
+`
+
+type IndexLeafDensityParams struct {
+	SchemaName string
+	IndexName  string
+}
+
+type IndexLeafDensityRow struct {
+	IndexSize      int64
+	LeafPages      int64
+	EmptyPages     int64
+	DeletedPages   int64
+	AvgLeafDensity float64
+	BlockSize      int64
+}
+
+// Measures a B-tree index's leaf density with pgstatindex, which reads every page of the index.
+func (q *Queries) IndexLeafDensity(ctx context.Context, arg IndexLeafDensityParams) (IndexLeafDensityRow, error) {
+	row := q.db.QueryRow(ctx, indexLeafDensity, arg.SchemaName, arg.IndexName)
+	var i IndexLeafDensityRow
+	err := row.Scan(
+		&i.IndexSize,
+		&i.LeafPages,
+		&i.EmptyPages,
+		&i.DeletedPages,
+		&i.AvgLeafDensity,
+		&i.BlockSize,
+	)
+	return i, err
+}
+
+const indexUsageStats = `-- name: IndexUsageStats :many
+SELECT
+  (n.nspname || '.' || tbl.relname)::text AS table_name
+  , psai.indexrelname::text AS index_name
+  , n.nspname::text AS schema_name
+  , c.reltuples::bigint AS num_rows
+  , x.indisprimary AS is_primary
+  , x.indisunique AS is_unique
+  , pg_relation_size(psai.indexrelid) AS index_size_bytes
+  , coalesce(psai.idx_scan, 0) AS idx_scan
+  , coalesce(psai.idx_tup_read, 0) AS idx_tup_read
+  , coalesce(psai.idx_tup_fetch, 0) AS idx_tup_fetch
+  , coalesce(ut.n_tup_ins, 0) + coalesce(ut.n_tup_upd, 0) + coalesce(ut.n_tup_del, 0) AS table_writes
+  , coalesce(psaio.idx_blks_hit, 0) AS idx_blks_hit
+  , coalesce(psaio.idx_blks_read, 0) AS idx_blks_read
+  , CASE
+    WHEN coalesce(psaio.idx_blks_hit, 0) + coalesce(psaio.idx_blks_read, 0) = 0 THEN NULL
+    ELSE round(
+      100.0 * psaio.idx_blks_hit / (psaio.idx_blks_hit + psaio.idx_blks_read)
+      , 2
+    )
+  END AS cache_hit_ratio
+  , pg_get_indexdef(psai.indexrelid) AS indexdef
+FROM pg_stat_user_indexes AS psai
+INNER JOIN pg_index AS x ON psai.indexrelid = x.indexrelid
+INNER JOIN pg_class AS tbl ON x.indrelid = tbl.oid
+INNER JOIN pg_namespace AS n ON tbl.relnamespace = n.oid
+LEFT JOIN pg_class AS c ON psai.relid = c.oid
+LEFT JOIN pg_stat_user_tables AS ut ON tbl.oid = ut.relid
+LEFT JOIN pg_statio_user_indexes AS psaio ON psai.indexrelid = psaio.indexrelid
+WHERE
+  n.nspname = $1::text
+ORDER BY
+  pg_relation_size(psai.indexrelid) DESC
+`
+
+type IndexUsageStatsRow struct {
+	TableName      pgtype.Text
+	IndexName      pgtype.Text
+	SchemaName     pgtype.Text
+	NumRows        pgtype.Int8
+	IsPrimary      bool
+	IsUnique       bool
+	IndexSizeBytes pgtype.Int8
+	IdxScan        pgtype.Int8
+	IdxTupRead     pgtype.Int8
+	IdxTupFetch    pgtype.Int8
+	TableWrites    pgtype.Int8
+	IdxBlksHit     pgtype.Int8
+	IdxBlksRead    pgtype.Int8
+	CacheHitRatio  pgtype.Numeric
+	Indexdef       pgtype.Text
+}
+
+// Identifies indexes with usage statistics for health analysis.
+// Excludes: system schemas.
+// Returns data for subchecks: unused-indexes, low-usage-indexes, index-cache-ratio.
+func (q *Queries) IndexUsageStats(ctx context.Context, schemaName string) ([]IndexUsageStatsRow, error) {
+	rows, err := q.db.Query(ctx, indexUsageStats, schemaName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []IndexUsageStatsRow
+	for rows.Next() {
+		var i IndexUsageStatsRow
+		if err := rows.Scan(
+			&i.TableName,
+			&i.IndexName,
+			&i.SchemaName,
+			&i.NumRows,
+			&i.IsPrimary,
+			&i.IsUnique,
+			&i.IndexSizeBytes,
+			&i.IdxScan,
+			&i.IdxTupRead,
+			&i.IdxTupFetch,
+			&i.TableWrites,
+			&i.IdxBlksHit,
+			&i.IdxBlksRead,
+			&i.CacheHitRatio,
+			&i.Indexdef,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const invalidPrimaryKeyTypes = `-- name: InvalidPrimaryKeyTypes :many
+WITH pk_tables AS (
+  SELECT
+    n.nspname::text AS schema_name
+    , c.relname::text AS table_name
+    , a.attname::text AS column_name
+    , a.attnum AS column_num
+    , t.typname::text AS column_type
+    , c.oid AS table_oid
+    , COALESCE(s.n_live_tup, 0)::bigint AS estimated_rows
+    , CASE t.typname
+      WHEN 'int2' THEN 32767::bigint
+      WHEN 'int4' THEN 2147483647::bigint
+    END AS type_max_value
+  FROM pg_catalog.pg_constraint AS con
+  INNER JOIN pg_catalog.pg_class AS c ON con.conrelid = c.oid
+  INNER JOIN pg_catalog.pg_namespace AS n ON c.relnamespace = n.oid
+  INNER JOIN pg_catalog.pg_attribute AS a
+    ON
+      con.conrelid = a.attrelid
+      AND a.attnum = ANY(con.conkey)
+  INNER JOIN pg_catalog.pg_type AS t ON a.atttypid = t.oid
+  LEFT JOIN pg_stat_user_tables AS s ON c.oid = s.relid
+  WHERE
+    con.contype = 'p'
+    AND n.nspname NOT IN ('pg_catalog', 'information_schema', 'pgpartman', 'pgjobmon', 'cron')
+    AND t.typname IN ('int2', 'int4')
+    AND NOT EXISTS (
+      SELECT 1 FROM pg_inherits AS inh
+      WHERE inh.inhrelid = c.oid
+    )
+)
+
+, sequence_values AS (
+  SELECT
+    d.refobjid AS table_oid
+    , d.refobjsubid AS column_num
+    , seq.last_value::bigint AS sequence_current
+  FROM pg_depend AS d
+  INNER JOIN pg_class AS seq_class ON d.objid = seq_class.oid
+  INNER JOIN pg_sequences AS seq ON seq_class.relname = seq.sequencename
+  WHERE
+    d.deptype = 'a'
+    AND seq_class.relkind = 'S'
+)
+
+, pk_with_usage AS (
+  SELECT
+    (p.schema_name || '.' || p.table_name)::text AS table_name
+    , p.column_name
+    , p.column_type
+    , p.estimated_rows
+    , sv.sequence_current
+    , p.type_max_value
+    , CASE
+      WHEN sv.sequence_current IS NOT NULL AND p.type_max_value > 0
+        THEN sv.sequence_current::numeric / p.type_max_value::numeric
+      WHEN p.estimated_rows > 0 AND p.type_max_value > 0
+        THEN p.estimated_rows::numeric / p.type_max_value::numeric
+      ELSE
+        0::numeric
+    END AS usage_pct
+  FROM pk_tables AS p
+  LEFT JOIN sequence_values AS sv
+    ON
+      p.table_oid = sv.table_oid
+      AND p.column_num = sv.column_num
+)
+
+SELECT
+  table_name
+  , column_name
+  , column_type
+  , estimated_rows
+  , sequence_current
+  , type_max_value
+  , usage_pct
+FROM pk_with_usage
+ORDER BY
+  usage_pct DESC NULLS LAST
+  , estimated_rows DESC NULLS LAST
+`
+
+type InvalidPrimaryKeyTypesRow struct {
+	TableName       pgtype.Text
+	ColumnName      pgtype.Text
+	ColumnType      pgtype.Text
+	EstimatedRows   pgtype.Int8
+	SequenceCurrent pgtype.Int8
+	TypeMaxValue    pgtype.Int8
+	UsagePct        pgtype.Numeric
+}
+
+// Identifies tables with integer primary keys (int2/int4) that should use bigint.
+func (q *Queries) InvalidPrimaryKeyTypes(ctx context.Context) ([]InvalidPrimaryKeyTypesRow, error) {
+	rows, err := q.db.Query(ctx, invalidPrimaryKeyTypes)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []InvalidPrimaryKeyTypesRow
+	for rows.Next() {
+		var i InvalidPrimaryKeyTypesRow
+		if err := rows.Scan(
+			&i.TableName,
+			&i.ColumnName,
+			&i.ColumnType,
+			&i.EstimatedRows,
+			&i.SequenceCurrent,
+			&i.TypeMaxValue,
+			&i.UsagePct,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const largeParameterQueries = `-- name: LargeParameterQueries :many
+WITH stmts AS (
+  SELECT
+    queryid
+    , query
+    , calls
+    , total_exec_time
+    , total_plan_time
+    , rows
+    , (
+      SELECT COALESCE(MAX(m[1]::integer), 0)
+      FROM REGEXP_MATCHES(query, '\$(\d+)', 'g') AS m
+    ) AS param_count
+    , REGEXP_REPLACE(query, '\$\d+(\s*,\s*\$\d+)+', '$n, ...', 'g') AS template
+  FROM pg_stat_statements
+  WHERE
+    calls > 10
+    AND query !~* '^\s*(COPY|SET|BEGIN|COMMIT|ROLLBACK|SAVEPOINT|PREPARE|DEALLOCATE)'
+)
+
+, with_variants AS (
+  SELECT
+    *
+    , COUNT(*) OVER (PARTITION BY template) AS variants
+    , query ~* '=\s*any\s*\(\s*\$\d+' AS any_array
+  FROM stmts
+)
+
+SELECT
+  queryid::bigint AS query_id
+  , LEFT(REGEXP_REPLACE(query, '\s+', ' ', 'g'), 80)::text AS query
+  , param_count::integer AS param_count
+  , variants::bigint AS variants
+  , any_array
+  , calls::bigint AS calls
+  , total_exec_time::double precision AS total_exec_time
+  , total_plan_time::double precision AS total_plan_time
+  , rows::bigint AS rows_returned
+FROM with_variants
+WHERE
+  param_count >= 100
+  OR (any_array AND rows >= calls * 1000)
+ORDER BY total_exec_time + total_plan_time DESC
+LIMIT 50
+`
+
+type LargeParameterQueriesRow struct {
+	QueryID       int64
+	Query         string
+	ParamCount    int32
+	Variants      int64
+	AnyArray      bool
+	Calls         int64
+	TotalExecTime float64
+	TotalPlanTime float64
+	RowsReturned  int64
+}
+
+// Statements with hundreds of bind parameters (typically generated IN lists)
+// or with an `= ANY($n)` array lookup that returns many rows per call.
+// pg_stat_statements records one entry per IN-list length, so entries that
+// differ only in list length are counted as variants of the same template.
+func (q *Queries) LargeParameterQueries(ctx context.Context) ([]LargeParameterQueriesRow, error) {
+	rows, err := q.db.Query(ctx, largeParameterQueries)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []LargeParameterQueriesRow
+	for rows.Next() {
+		var i LargeParameterQueriesRow
+		if err := rows.Scan(
+			&i.QueryID,
+			&i.Query,
+			&i.ParamCount,
+			&i.Variants,
+			&i.AnyArray,
+			&i.Calls,
+			&i.TotalExecTime,
+			&i.TotalPlanTime,
+			&i.RowsReturned,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const largeParameterQueriesFromStatMonitor = `-- name: LargeParameterQueriesFromStatMonitor :many
+WITH buckets AS (
+  SELECT
+    queryid
+    , MIN(query) AS query
+    , SUM(calls) AS calls
+    , SUM(total_exec_time) AS total_exec_time
+    , SUM(total_plan_time) AS total_plan_time
+    , SUM(rows) AS rows
+  FROM pg_stat_monitor
+  WHERE query !~* '^\s*(COPY|SET|BEGIN|COMMIT|ROLLBACK|SAVEPOINT|PREPARE|DEALLOCATE)'
+  GROUP BY queryid
+  HAVING SUM(calls) > 10
+)
+
+, stmts AS (
+  SELECT
+    *
+    , (
+      SELECT COALESCE(MAX(m[1]::integer), 0)
+      FROM REGEXP_MATCHES(query, '\$(\d+)', 'g') AS m
+    ) AS param_count
+    , REGEXP_REPLACE(query, '\$\d+(\s*,\s*\$\d+)+', '$n, ...', 'g') AS template
+  FROM buckets
+)
+
+, with_variants AS (
+  SELECT
+    *
+    , COUNT(*) OVER (PARTITION BY template) AS variants
+    , query ~* '=\s*any\s*\(\s*\$\d+' AS any_array
+  FROM stmts
+)
+
+SELECT
+  queryid::bigint AS query_id
+  , LEFT(REGEXP_REPLACE(query, '\s+', ' ', 'g'), 80)::text AS query
+  , param_count::integer AS param_count
+  , variants::bigint AS variants
+  , any_array
+  , calls::bigint AS calls
+  , total_exec_time::double precision AS total_exec_time
+  , total_plan_time::double precision AS total_plan_time
+  , rows::bigint AS rows_returned
+FROM with_variants
+WHERE
+  param_count >= 100
+  OR (any_array AND rows >= calls * 1000)
+ORDER BY total_exec_time + total_plan_time DESC
+LIMIT 50
+`
+
+type LargeParameterQueriesFromStatMonitorRow struct {
+	QueryID       int64
+	Query         string
+	ParamCount    int32
+	Variants      int64
+	AnyArray      bool
+	Calls         int64
+	TotalExecTime float64
+	TotalPlanTime float64
+	RowsReturned  int64
+}
+
+// LargeParameterQueries for Percona's pg_stat_monitor, used when
+// pg_stat_statements is not installed. pg_stat_monitor keeps a row per query
+// per time bucket, so buckets are summed per queryid before the templates
+// are compared. Parameters are only visible when pgsm_normalized_query is on.
+func (q *Queries) LargeParameterQueriesFromStatMonitor(ctx context.Context) ([]LargeParameterQueriesFromStatMonitorRow, error) {
+	rows, err := q.db.Query(ctx, largeParameterQueriesFromStatMonitor)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []LargeParameterQueriesFromStatMonitorRow
+	for rows.Next() {
+		var i LargeParameterQueriesFromStatMonitorRow
+		if err := rows.Scan(
+			&i.QueryID,
+			&i.Query,
+			&i.ParamCount,
+			&i.Variants,
+			&i.AnyArray,
+			&i.Calls,
+			&i.TotalExecTime,
+			&i.TotalPlanTime,
+			&i.RowsReturned,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const largeTables = `-- name: LargeTables :many
+WITH inheritance_info AS (
+  SELECT DISTINCT ON (i.inhrelid)
+    i.inhrelid AS child_oid
+    , (pn.nspname || '.' || pc.relname)::text AS parent_table
+  FROM pg_inherits AS i
+  INNER JOIN pg_class AS pc ON i.inhparent = pc.oid
+  INNER JOIN pg_namespace AS pn ON pc.relnamespace = pn.oid
+  ORDER BY i.inhrelid, i.inhparent
+)
+
+SELECT
+  (n.nspname || '.' || c.relname)::text AS table_name
+  , ii.parent_table
+  , pg_catalog.pg_table_size(c.oid) AS table_size_bytes
+  , COALESCE(s.n_live_tup, 0) AS estimated_rows
+  , (c.relkind = 'p') AS is_partitioned
+  , (ii.parent_table IS NOT NULL) AS is_partition
+  , (c.relname ~ '(outbox|inbox|_jobs?$|^oban_|logs|events?$)') AS is_transient
+  , COALESCE(s.n_tup_ins, 0) AS n_tup_ins
+  , COALESCE(s.n_tup_upd, 0) AS n_tup_upd
+  , COALESCE(s.n_tup_del, 0) AS n_tup_del
+FROM pg_catalog.pg_class AS c
+INNER JOIN pg_catalog.pg_namespace AS n ON c.relnamespace = n.oid
+LEFT JOIN pg_stat_user_tables AS s ON c.oid = s.relid
+LEFT JOIN inheritance_info AS ii ON c.oid = ii.child_oid
+WHERE
+  c.relkind IN ('r', 'p')
+  AND n.nspname NOT IN ('pg_catalog', 'information_schema', 'pg_toast', 'pgpartman', 'debezium', 'cron')
+  AND COALESCE(s.n_live_tup, 0) >= 10000000
+`
+
+type LargeTablesRow struct {
+	TableName      pgtype.Text
+	ParentTable    pgtype.Text
+	TableSizeBytes pgtype.Int8
+	EstimatedRows  pgtype.Int8
+	IsPartitioned  pgtype.Bool
+	IsPartition    pgtype.Bool
+	IsTransient    pgtype.Bool
+	NTupIns        pgtype.Int8
+	NTupUpd        pgtype.Int8
+	NTupDel        pgtype.Int8
+}
+
+// Identifies all large tables (>= 10M rows) with partitioning and transient status.
+// Returns both regular and partitioned tables for unified analysis.
+// Includes activity metrics (inserts/updates/deletes) for activity-aware thresholds.
+func (q *Queries) LargeTables(ctx context.Context) ([]LargeTablesRow, error) {
+	rows, err := q.db.Query(ctx, largeTables)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []LargeTablesRow
+	for rows.Next() {
+		var i LargeTablesRow
+		if err := rows.Scan(
+			&i.TableName,
+			&i.ParentTable,
+			&i.TableSizeBytes,
+			&i.EstimatedRows,
+			&i.IsPartitioned,
+			&i.IsPartition,
+			&i.IsTransient,
+			&i.NTupIns,
+			&i.NTupUpd,
+			&i.NTupDel,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const leftoverTempFiles = `-- name: LeftoverTempFiles :many
+SELECT
+  t.name::text AS file_name
+  , t.size::bigint AS size_bytes
+  , EXTRACT(EPOCH FROM (NOW() - t.modification))::bigint AS age_seconds
+FROM pg_ls_tmpdir() AS t
+WHERE NOT EXISTS (
+  SELECT 1 FROM pg_stat_activity AS a
+  WHERE a.pid = SUBSTRING(t.name FROM '^pgsql_tmp(\d+)')::integer
+)
+ORDER BY t.size DESC
+`
+
+type LeftoverTempFilesRow struct {
+	FileName   string
+	SizeBytes  int64
+	AgeSeconds int64
+}
+
+// Temporary files in the default tablespace whose creating backend (pgsql_tmpPID.N)
+// no longer exists.
+func (q *Queries) LeftoverTempFiles(ctx context.Context) ([]LeftoverTempFilesRow, error) {
+	rows, err := q.db.Query(ctx, leftoverTempFiles)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []LeftoverTempFilesRow
+	for rows.Next() {
+		var i LeftoverTempFilesRow
+		if err := rows.Scan(&i.FileName, &i.SizeBytes, &i.AgeSeconds); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const legacyCheckpointActivity = `-- name: LegacyCheckpointActivity :one
+SELECT
+  checkpoints_timed::bigint AS checkpoints_timed
+  , checkpoints_req::bigint AS checkpoints_requested
+  , buffers_checkpoint::bigint AS buffers_checkpoint
+  , buffers_clean::bigint AS buffers_clean
+  , maxwritten_clean::bigint AS maxwritten_clean
+  , buffers_backend::bigint AS buffers_backend
+  , buffers_backend_fsync::bigint AS backend_fsyncs
+  , checkpoint_write_time::double precision AS write_time_ms
+  , COALESCE(EXTRACT(EPOCH FROM (NOW() - stats_reset)), 0)::bigint AS seconds_since_reset
+FROM pg_stat_bgwriter
+`
+
+type LegacyCheckpointActivityRow struct {
+	CheckpointsTimed     int64
+	CheckpointsRequested int64
+	BuffersCheckpoint    int64
+	BuffersClean         int64
+	MaxwrittenClean      int64
+	BuffersBackend       int64
+	BackendFsyncs        int64
+	WriteTimeMs          float64
+	SecondsSinceReset    int64
+}
+
+// Checkpoint and buffer write counters from pg_stat_bgwriter (before
+// PostgreSQL 17).
+func (q *Queries) LegacyCheckpointActivity(ctx context.Context) (LegacyCheckpointActivityRow, error) {
+	row := q.db.QueryRow(ctx, legacyCheckpointActivity)
+	var i LegacyCheckpointActivityRow
+	err := row.Scan(
+		&i.CheckpointsTimed,
+		&i.CheckpointsRequested,
+		&i.BuffersCheckpoint,
+		&i.BuffersClean,
+		&i.MaxwrittenClean,
+		&i.BuffersBackend,
+		&i.BackendFsyncs,
+		&i.WriteTimeMs,
+		&i.SecondsSinceReset,
+	)
+	return i, err
+}
+
+const legacyIOTiming = `-- name: LegacyIOTiming :one
+SELECT
+  coalesce(sum(reads), 0)::bigint AS reads
+  , coalesce(sum(reads * op_bytes), 0)::bigint AS read_bytes
+  , coalesce(sum(read_time), 0)::double precision AS read_time_ms
+  , coalesce(extract(EPOCH FROM (now() - min(stats_reset))), 0)::bigint AS seconds_since_reset
+FROM pg_stat_io
+WHERE backend_type = 'client backend' AND object = 'relation'
+`
+
+type LegacyIOTimingRow struct {
+	Reads             int64
+	ReadBytes         int64
+	ReadTimeMs        float64
+	SecondsSinceReset int64
+}
+
+// Relation reads by client backends on PostgreSQL 16 and 17, with bytes
+// derived from op_bytes.
+func (q *Queries) LegacyIOTiming(ctx context.Context) (LegacyIOTimingRow, error) {
+	row := q.db.QueryRow(ctx, legacyIOTiming)
+	var i LegacyIOTimingRow
+	err := row.Scan(
+		&i.Reads,
+		&i.ReadBytes,
+		&i.ReadTimeMs,
+		&i.SecondsSinceReset,
+	)
+	return i, err
+}
+
+const legacyWalFpiStats = `-- name: LegacyWalFpiStats :one
+SELECT
+  w.wal_records::bigint AS wal_records
+  , w.wal_fpi::bigint AS wal_fpi
+  , w.wal_bytes::bigint AS wal_bytes
+  , COALESCE(EXTRACT(EPOCH FROM (NOW() - w.stats_reset)), 0)::bigint AS wal_seconds_since_reset
+  , b.checkpoints_timed::bigint AS checkpoints_timed
+  , b.checkpoints_req::bigint AS checkpoints_requested
+  , COALESCE(EXTRACT(EPOCH FROM (NOW() - b.stats_reset)), 0)::bigint AS checkpoint_seconds_since_reset
+FROM pg_stat_wal AS w
+CROSS JOIN pg_stat_bgwriter AS b
+`
+
+type LegacyWalFpiStatsRow struct {
+	WalRecords                  int64
+	WalFpi                      int64
+	WalBytes                    int64
+	WalSecondsSinceReset        int64
+	CheckpointsTimed            int64
+	CheckpointsRequested        int64
+	CheckpointSecondsSinceReset int64
+}
+
+// WAL volume and full-page images from pg_stat_wal with checkpoint counters
+// from pg_stat_bgwriter (PostgreSQL 14 to 16).
+func (q *Queries) LegacyWalFpiStats(ctx context.Context) (LegacyWalFpiStatsRow, error) {
+	row := q.db.QueryRow(ctx, legacyWalFpiStats)
+	var i LegacyWalFpiStatsRow
+	err := row.Scan(
+		&i.WalRecords,
+		&i.WalFpi,
+		&i.WalBytes,
+		&i.WalSecondsSinceReset,
+		&i.CheckpointsTimed,
+		&i.CheckpointsRequested,
+		&i.CheckpointSecondsSinceReset,
+	)
+	return i, err
+}
+
+const lockHolders = `-- name: LockHolders :many
+WITH blockers AS (
+  SELECT DISTINCT UNNEST(PG_BLOCKING_PIDS(a.pid)) AS pid
+  FROM pg_stat_activity AS a
+  WHERE a.wait_event_type = 'Lock'
+)
+
+, exclusive AS (
+  SELECT
+    l.pid
+    , STRING_AGG(DISTINCT l.relation::regclass::text, ', ') AS relations
+  FROM pg_locks AS l
+  WHERE
+    l.locktype = 'relation'
+    AND l.mode = 'AccessExclusiveLock'
+    AND l.granted
+    AND l.pid IS NOT NULL
+    AND l.pid <> PG_BACKEND_PID()
+  GROUP BY l.pid
+)
+
+SELECT
+  a.pid::integer AS pid
+  , COALESCE(a.usename, '')::text AS username
+  , COALESCE(a.application_name, '')::text AS application_name
+  , COALESCE(a.state, '')::text AS state
+  , (b.pid IS NOT NULL) AS is_blocking
+  , COALESCE(e.relations, '')::text AS access_exclusive_relations
+  , COALESCE(EXTRACT(EPOCH FROM NOW() - a.xact_start), 0)::bigint AS xact_age_seconds
+  , COALESCE(EXTRACT(EPOCH FROM NOW() - a.state_change), 0)::bigint AS state_age_seconds
+  , LEFT(COALESCE(a.query, ''), 200)::text AS query
+FROM pg_stat_activity AS a
+LEFT JOIN blockers AS b ON a.pid = b.pid
+LEFT JOIN exclusive AS e ON a.pid = e.pid
+WHERE b.pid IS NOT NULL OR e.pid IS NOT NULL
+ORDER BY xact_age_seconds DESC
+LIMIT 200
+`
+
+type LockHoldersRow struct {
+	Pid                      int32
+	Username                 string
+	ApplicationName          string
+	State                    string
+	IsBlocking               bool
+	AccessExclusiveRelations string
+	XactAgeSeconds           int64
+	StateAgeSeconds          int64
+	Query                    string
+}
+
+// Sessions that block at least one other session, and sessions holding an
+// AccessExclusiveLock on a relation, which blocks even plain reads. Lock
+// acquisition time is not recorded, so transaction age is the best bound on
+// how long the locks have been held.
+func (q *Queries) LockHolders(ctx context.Context) ([]LockHoldersRow, error) {
+	rows, err := q.db.Query(ctx, lockHolders)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []LockHoldersRow
+	for rows.Next() {
+		var i LockHoldersRow
+		if err := rows.Scan(
+			&i.Pid,
+			&i.Username,
+			&i.ApplicationName,
+			&i.State,
+			&i.IsBlocking,
+			&i.AccessExclusiveRelations,
+			&i.XactAgeSeconds,
+			&i.StateAgeSeconds,
+			&i.Query,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const lockWaiters = `-- name: LockWaiters :many
+WITH waiting AS (
+  SELECT
+    a.pid
+    , PG_BLOCKING_PIDS(a.pid) AS blockers
+  FROM pg_stat_activity AS a
+  WHERE a.wait_event_type = 'Lock'
+)
+
+SELECT
+  a.pid::integer AS pid
+  , COALESCE(a.usename, '')::text AS username
+  , COALESCE(a.application_name, '')::text AS application_name
+  , w.blockers::text [] AS blocking_pids
+  , COALESCE(l.locktype, '')::text AS lock_type
+  , COALESCE(l.mode, '')::text AS lock_mode
+  , COALESCE(l.relation::regclass::text, '')::text AS relation_name
+  , COALESCE(EXTRACT(EPOCH FROM NOW() - COALESCE((TO_JSONB(l) ->> 'waitstart')::timestamptz, a.query_start)), 0)::bigint AS wait_seconds
+  , LEFT(COALESCE(a.query, ''), 200)::text AS query
+FROM waiting AS w
+INNER JOIN pg_stat_activity AS a ON w.pid = a.pid
+LEFT JOIN LATERAL (
+  SELECT *
+  FROM pg_locks AS pl
+  WHERE pl.pid = a.pid AND NOT pl.granted
+  LIMIT 1
+) AS l ON true
+WHERE CARDINALITY(w.blockers) > 0
+ORDER BY wait_seconds DESC
+LIMIT 200
+`
+
+type LockWaitersRow struct {
+	Pid             int32
+	Username        string
+	ApplicationName string
+	BlockingPids    []string
+	LockType        string
+	LockMode        string
+	RelationName    string
+	WaitSeconds     int64
+	Query           string
+}
+
+// Sessions waiting for a heavyweight lock that another session holds, with
+// the sessions blocking them and the lock they are waiting for. Wait start
+// comes from pg_locks.waitstart on PostgreSQL 14+ (read through to_jsonb so
+// the query also runs on older versions) and falls back to query start.
+func (q *Queries) LockWaiters(ctx context.Context) ([]LockWaitersRow, error) {
+	rows, err := q.db.Query(ctx, lockWaiters)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []LockWaitersRow
+	for rows.Next() {
+		var i LockWaitersRow
+		if err := rows.Scan(
+			&i.Pid,
+			&i.Username,
+			&i.ApplicationName,
+			&i.BlockingPids,
+			&i.LockType,
+			&i.LockMode,
+			&i.RelationName,
+			&i.WaitSeconds,
+			&i.Query,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const logicalReplicationUsage = `-- name: LogicalReplicationUsage :one
+SELECT
+  (SELECT COUNT(*) FROM pg_publication)::bigint AS publication_count
+  , (
+    SELECT COUNT(*) FROM pg_subscription
+    WHERE subdbid = (SELECT oid FROM pg_database WHERE datname = CURRENT_DATABASE())
+  )::bigint AS subscription_count
+  , (
+    SELECT COUNT(*) FROM pg_replication_slots
+    WHERE slot_type = 'logical' AND database = CURRENT_DATABASE()
+  )::bigint AS logical_slot_count
+`
+
+type LogicalReplicationUsageRow struct {
+	PublicationCount  int64
+	SubscriptionCount int64
+	LogicalSlotCount  int64
+}
+
+// Whether this database publishes or subscribes via logical replication.
+func (q *Queries) LogicalReplicationUsage(ctx context.Context) (LogicalReplicationUsageRow, error) {
+	row := q.db.QueryRow(ctx, logicalReplicationUsage)
+	var i LogicalReplicationUsageRow
+	err := row.Scan(&i.PublicationCount, &i.SubscriptionCount, &i.LogicalSlotCount)
+	return i, err
+}
+
+const longIdleConnections = `-- name: LongIdleConnections :many
+SELECT
+  pid
+  , usename::text AS username
+  , datname::text AS database_name
+  , application_name::text AS application_name
+  , client_addr::text AS client_address
+  , state::text AS state
+  , extract(EPOCH FROM (now() - state_change))::bigint AS idle_duration_seconds
+  , extract(EPOCH FROM (now() - backend_start))::bigint AS connection_age_seconds
+FROM pg_stat_activity
+WHERE
+  state = 'idle'
+  AND pid != pg_backend_pid()
+  AND (now() - state_change) > interval '30 minutes'
+ORDER BY state_change ASC
+`
+
+type LongIdleConnectionsRow struct {
+	Pid                  pgtype.Int4
+	Username             pgtype.Text
+	DatabaseName         pgtype.Text
+	ApplicationName      pgtype.Text
+	ClientAddress        pgtype.Text
+	State                pgtype.Text
+	IdleDurationSeconds  pgtype.Int8
+	ConnectionAgeSeconds pgtype.Int8
+}
+
+// Identifies connections that have been idle for too long (potential pool leak).
+func (q *Queries) LongIdleConnections(ctx context.Context) ([]LongIdleConnectionsRow, error) {
+	rows, err := q.db.Query(ctx, longIdleConnections)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []LongIdleConnectionsRow
+	for rows.Next() {
+		var i LongIdleConnectionsRow
+		if err := rows.Scan(
+			&i.Pid,
+			&i.Username,
+			&i.DatabaseName,
+			&i.ApplicationName,
+			&i.ClientAddress,
+			&i.State,
+			&i.IdleDurationSeconds,
+			&i.ConnectionAgeSeconds,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const lowCardinalityIndexes = `-- name: LowCardinalityIndexes :many
+SELECT
+  (n.nspname || '.' || tbl.relname)::text AS table_name
+  , psai.indexrelname::text AS index_name
+  , n.nspname::text AS schema_name
+  , a.attname::text AS column_name
+  , format_type(a.atttypid, a.atttypmod)::text AS column_type
+  , (CASE
+    WHEN s.n_distinct >= 0 THEN s.n_distinct
+    ELSE -s.n_distinct * greatest(tbl.reltuples, 0)
+  END)::float8 AS distinct_values
+  , coalesce(s.most_common_freqs[1], 0)::float8 AS top_value_fraction
+  , coalesce(s.most_common_vals::text, '')::text AS common_values
+  , pg_relation_size(psai.indexrelid)::bigint AS index_size_bytes
+  , coalesce(psai.idx_scan, 0)::bigint AS idx_scan
+  , (coalesce(ut.n_tup_ins, 0) + coalesce(ut.n_tup_upd, 0) + coalesce(ut.n_tup_del, 0))::bigint AS table_writes
+  -- Inserts and non-HOT updates add an entry to every index on the table.
+  , (coalesce(ut.n_tup_ins, 0) + coalesce(ut.n_tup_upd, 0) - coalesce(ut.n_tup_hot_upd, 0))::bigint AS index_entry_writes
+  , (SELECT count(*) FROM pg_index AS o WHERE o.indrelid = tbl.oid)::bigint AS table_index_count
 FROM pg_stat_user_indexes AS psai
 INNER JOIN pg_index AS x ON psai.indexrelid = x.indexrelid
+INNER JOIN pg_class AS ic ON x.indexrelid = ic.oid
+INNER JOIN pg_am AS am ON ic.relam = am.oid
 INNER JOIN pg_class AS tbl ON x.indrelid = tbl.oid
 INNER JOIN pg_namespace AS n ON tbl.relnamespace = n.oid
-LEFT JOIN pg_class AS c ON psai.relid = c.oid
+INNER JOIN pg_attribute AS a ON tbl.oid = a.attrelid AND x.indkey[0] = a.attnum
+INNER JOIN pg_stats AS s ON n.nspname = s.schemaname AND tbl.relname = s.tablename AND a.attname = s.attname
 LEFT JOIN pg_stat_user_tables AS ut ON tbl.oid = ut.relid
-LEFT JOIN pg_statio_user_indexes AS psaio ON psai.indexrelid = psaio.indexrelid
 WHERE
-  n.nspname = 'public'
-ORDER BY
-  pg_relation_size(psai.indexrelid) DESC
+  n.nspname = $1::text
+  AND am.amname = 'btree'
+  AND x.indnatts = 1
+  AND x.indpred IS NULL
+  AND x.indexprs IS NULL
+  AND NOT x.indisunique
+  AND NOT x.indisprimary
+  AND NOT s.inherited
+ORDER BY
+  index_entry_writes DESC
+`
+
+type LowCardinalityIndexesRow struct {
+	TableName        string
+	IndexName        string
+	SchemaName       string
+	ColumnName       string
+	ColumnType       string
+	DistinctValues   float64
+	TopValueFraction float64
+	CommonValues     string
+	IndexSizeBytes   int64
+	IdxScan          int64
+	TableWrites      int64
+	IndexEntryWrites int64
+	TableIndexCount  int64
+}
+
+// Single-column, non-unique, non-partial B-tree indexes whose column has very
+// few distinct values (per pg_stats), with the write activity that maintains them.
+// Returns data for subcheck: low-cardinality-indexes.
+func (q *Queries) LowCardinalityIndexes(ctx context.Context, schemaName string) ([]LowCardinalityIndexesRow, error) {
+	rows, err := q.db.Query(ctx, lowCardinalityIndexes, schemaName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []LowCardinalityIndexesRow
+	for rows.Next() {
+		var i LowCardinalityIndexesRow
+		if err := rows.Scan(
+			&i.TableName,
+			&i.IndexName,
+			&i.SchemaName,
+			&i.ColumnName,
+			&i.ColumnType,
+			&i.DistinctValues,
+			&i.TopValueFraction,
+			&i.CommonValues,
+			&i.IndexSizeBytes,
+			&i.IdxScan,
+			&i.TableWrites,
+			&i.IndexEntryWrites,
+			&i.TableIndexCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const lowResolutionColumns = `-- name: LowResolutionColumns :many
+SELECT
+  n.nspname::text AS schema_name
+  , c.relname::text AS table_name
+  , a.attname::text AS column_name
+  , c.reltuples::bigint AS estimated_rows
+  , (CASE
+    WHEN s.n_distinct < 0 THEN -s.n_distinct * c.reltuples
+    ELSE s.n_distinct
+  END)::bigint AS distinct_values
+  , COALESCE(CARDINALITY(s.most_common_vals), 0)::integer AS mcv_count
+  , COALESCE(CARDINALITY(s.histogram_bounds), 0)::integer AS histogram_bounds
+FROM pg_class AS c
+INNER JOIN pg_namespace AS n ON c.relnamespace = n.oid
+INNER JOIN pg_attribute AS a ON c.oid = a.attrelid
+INNER JOIN pg_stats AS s
+  ON
+    n.nspname = s.schemaname
+    AND c.relname = s.tablename
+    AND a.attname = s.attname
+    AND NOT s.inherited
+WHERE
+  c.relkind = 'r'
+  AND n.nspname NOT IN ('pg_catalog', 'information_schema', 'pg_toast')
+  -- An empty schema_name covers every schema.
+  AND ($1::text = '' OR n.nspname = $1::text)
+  AND c.reltuples >= 1000000
+  AND a.attnum > 0
+  AND NOT a.attisdropped
+  AND COALESCE(a.attstattarget, -1) < 0
+  AND (CASE WHEN s.n_distinct < 0 THEN -s.n_distinct * c.reltuples ELSE s.n_distinct END) >= 10000
+ORDER BY c.reltuples DESC, n.nspname, c.relname, a.attnum
+LIMIT 500
+`
+
+type LowResolutionColumnsRow struct {
+	SchemaName      string
+	TableName       string
+	ColumnName      string
+	EstimatedRows   int64
+	DistinctValues  int64
+	McvCount        int32
+	HistogramBounds int32
+}
+
+// Columns of large tables that use the default statistics target but have
+// many distinct values, with the size of their MCV list and histogram.
+// attstattarget is -1 for the default before PostgreSQL 17 and NULL after.
+func (q *Queries) LowResolutionColumns(ctx context.Context, schemaName string) ([]LowResolutionColumnsRow, error) {
+	rows, err := q.db.Query(ctx, lowResolutionColumns, schemaName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []LowResolutionColumnsRow
+	for rows.Next() {
+		var i LowResolutionColumnsRow
+		if err := rows.Scan(
+			&i.SchemaName,
+			&i.TableName,
+			&i.ColumnName,
+			&i.EstimatedRows,
+			&i.DistinctValues,
+			&i.McvCount,
+			&i.HistogramBounds,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const maxSlotWalKeepSize = `-- name: MaxSlotWalKeepSize :one
+SELECT PG_SIZE_BYTES(CURRENT_SETTING('max_slot_wal_keep_size'))::BIGINT AS max_slot_wal_keep_size_bytes
+`
+
+// Returns max_slot_wal_keep_size in bytes (-1 means unlimited).
+func (q *Queries) MaxSlotWalKeepSize(ctx context.Context) (pgtype.Int8, error) {
+	row := q.db.QueryRow(ctx, maxSlotWalKeepSize)
+	var max_slot_wal_keep_size_bytes pgtype.Int8
+	err := row.Scan(&max_slot_wal_keep_size_bytes)
+	return max_slot_wal_keep_size_bytes, err
+}
+
+const md5PasswordRoles = `-- name: Md5PasswordRoles :one
+SELECT
+  COUNT(*)::bigint AS role_count
+  , COALESCE(ARRAY_AGG(a.rolname::text ORDER BY a.rolname), '{}')::text [] AS role_names
+FROM pg_authid AS a
+WHERE
+  a.rolcanlogin
+  AND a.rolpassword LIKE 'md5%'
+`
+
+type Md5PasswordRolesRow struct {
+	RoleCount int64
+	RoleNames []string
+}
+
+// Login roles whose stored password is still an MD5 hash.
+func (q *Queries) Md5PasswordRoles(ctx context.Context) (Md5PasswordRolesRow, error) {
+	row := q.db.QueryRow(ctx, md5PasswordRoles)
+	var i Md5PasswordRolesRow
+	err := row.Scan(&i.RoleCount, &i.RoleNames)
+	return i, err
+}
+
+const missingProviderIdTables = `-- name: MissingProviderIdTables :many
+WITH user_tables AS (
+  SELECT
+    (n.nspname || '.' || c.relname)::text AS table_name
+    , c.oid AS table_oid
+    , pg_catalog.pg_table_size(c.oid) AS table_size_bytes
+    , CASE
+      WHEN c.relkind = 'p'
+        THEN (
+          -- For partitioned tables, sum stats from all child partitions
+          SELECT COALESCE(SUM(child_stats.n_live_tup), 0)::bigint
+          FROM pg_catalog.pg_inherits AS i
+          INNER JOIN pg_stat_user_tables AS child_stats ON i.inhrelid = child_stats.relid
+          WHERE i.inhparent = c.oid
+        )
+      ELSE COALESCE(s.n_live_tup, 0)
+    END AS estimated_rows
+  FROM pg_catalog.pg_class AS c
+  INNER JOIN pg_catalog.pg_namespace AS n ON c.relnamespace = n.oid
+  LEFT JOIN pg_stat_user_tables AS s ON c.oid = s.relid
+  WHERE
+    c.relkind IN ('r', 'p')
+    AND n.nspname = 'public'
+)
+
+, tables_with_provider_id AS (
+  SELECT DISTINCT a.attrelid AS table_oid
+  FROM pg_catalog.pg_attribute AS a
+  WHERE
+    a.attname = 'provider_id'
+    AND a.attnum > 0
+    AND NOT a.attisdropped
+)
+
+SELECT
+  CURRENT_DATABASE()::text AS database_name
+  , ut.table_name
+  , ut.table_size_bytes
+  , ut.estimated_rows
+FROM user_tables AS ut
+LEFT JOIN tables_with_provider_id AS t ON ut.table_oid = t.table_oid
+WHERE t.table_oid IS NULL
+ORDER BY ut.table_size_bytes DESC
+`
+
+type MissingProviderIdTablesRow struct {
+	DatabaseName   pgtype.Text
+	TableName      pgtype.Text
+	TableSizeBytes pgtype.Int8
+	EstimatedRows  pgtype.Int8
+}
+
+// Identifies tables without provider_id column for multi-tenancy support.
+func (q *Queries) MissingProviderIdTables(ctx context.Context) ([]MissingProviderIdTablesRow, error) {
+	rows, err := q.db.Query(ctx, missingProviderIdTables)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []MissingProviderIdTablesRow
+	for rows.Next() {
+		var i MissingProviderIdTablesRow
+		if err := rows.Scan(
+			&i.DatabaseName,
+			&i.TableName,
+			&i.TableSizeBytes,
+			&i.EstimatedRows,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const naiveTimestampColumns = `-- name: NaiveTimestampColumns :many
+SELECT
+  (n.nspname || '.' || c.relname)::text AS table_name
+  , a.attname::text AS column_name
+FROM pg_attribute AS a
+INNER JOIN pg_class AS c ON a.attrelid = c.oid
+INNER JOIN pg_namespace AS n ON c.relnamespace = n.oid
+WHERE
+  a.attnum > 0
+  AND NOT a.attisdropped
+  AND a.atttypid = 'timestamp'::regtype
+  AND c.relkind IN ('r', 'p')
+  AND NOT c.relispartition
+  AND n.nspname NOT IN ('pg_catalog', 'information_schema')
+  AND n.nspname !~ '^pg_toast'
+ORDER BY pg_total_relation_size(c.oid) DESC, table_name, a.attnum
+LIMIT 20
 `
 
-type IndexUsageStatsRow struct {
-	TableName      pgtype.Text
-	IndexName      pgtype.Text
-	NumRows        pgtype.Int8
-	IsPrimary      bool
-	IsUnique       bool
-	IndexSizeBytes pgtype.Int8
-	IdxScan        pgtype.Int8
-	IdxTupRead     pgtype.Int8
-	IdxTupFetch    pgtype.Int8
-	TableWrites    pgtype.Int8
-	IdxBlksHit     pgtype.Int8
-	IdxBlksRead    pgtype.Int8
-	CacheHitRatio  pgtype.Numeric
-	Indexdef       pgtype.Text
+type NaiveTimestampColumnsRow struct {
+	TableName  string
+	ColumnName string
 }
 
-// Identifies indexes with usage statistics for health analysis.
+// timestamp (without time zone) columns on the largest user tables.
+func (q *Queries) NaiveTimestampColumns(ctx context.Context) ([]NaiveTimestampColumnsRow, error) {
+	rows, err := q.db.Query(ctx, naiveTimestampColumns)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []NaiveTimestampColumnsRow
+	for rows.Next() {
+		var i NaiveTimestampColumnsRow
+		if err := rows.Scan(&i.TableName, &i.ColumnName); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const nondeterministicIndexedColumns = `-- name: NondeterministicIndexedColumns :many
+SELECT
+  n.nspname::text AS schema_name
+  , t.relname::text AS table_name
+  , i.relname::text AS index_name
+  , COALESCE(a.attname::text, '(expression)')::text AS column_name
+  , coll.collname::text AS collation_name
+  , x.indisunique AS is_unique
+FROM pg_index AS x
+INNER JOIN pg_class AS i ON x.indexrelid = i.oid
+INNER JOIN pg_class AS t ON x.indrelid = t.oid
+INNER JOIN pg_namespace AS n ON t.relnamespace = n.oid
+CROSS JOIN LATERAL UNNEST(x.indkey::int2 [], x.indcollation::oid []) AS k (attnum, collid)
+INNER JOIN pg_collation AS coll ON k.collid = coll.oid
+LEFT JOIN pg_attribute AS a ON t.oid = a.attrelid AND k.attnum = a.attnum AND k.attnum > 0
+WHERE
+  NOT coll.collisdeterministic
+  AND n.nspname NOT IN ('pg_catalog', 'information_schema')
+  AND n.nspname !~ '^pg_toast'
+ORDER BY n.nspname, t.relname, i.relname, column_name
+LIMIT 100
+`
+
+type NondeterministicIndexedColumnsRow struct {
+	SchemaName    string
+	TableName     string
+	IndexName     string
+	ColumnName    string
+	CollationName string
+	IsUnique      bool
+}
+
+// Index key columns that use a nondeterministic collation, such as a
+// case-insensitive ICU collation, in this database.
+func (q *Queries) NondeterministicIndexedColumns(ctx context.Context) ([]NondeterministicIndexedColumnsRow, error) {
+	rows, err := q.db.Query(ctx, nondeterministicIndexedColumns)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []NondeterministicIndexedColumnsRow
+	for rows.Next() {
+		var i NondeterministicIndexedColumnsRow
+		if err := rows.Scan(
+			&i.SchemaName,
+			&i.TableName,
+			&i.IndexName,
+			&i.ColumnName,
+			&i.CollationName,
+			&i.IsUnique,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const pGVersion = `-- name: PGVersion :one
+SELECT
+  current_setting('server_version_num')::integer / 10000 AS major
+  , current_setting('server_version_num')::integer % 100 AS minor
+`
+
+type PGVersionRow struct {
+	Major int32
+	Minor int32
+}
+
+func (q *Queries) PGVersion(ctx context.Context) (PGVersionRow, error) {
+	row := q.db.QueryRow(ctx, pGVersion)
+	var i PGVersionRow
+	err := row.Scan(&i.Major, &i.Minor)
+	return i, err
+}
+
+const partialExpressionIndexes = `-- name: PartialExpressionIndexes :many
+SELECT
+  (n.nspname || '.' || t.relname)::text AS table_name
+  , t.relname::text AS table_short_name
+  , i.relname::text AS index_name
+  , x.indisunique AS is_unique
+  , COALESCE(PG_GET_EXPR(x.indpred, x.indrelid), '')::text AS predicate
+  , COALESCE(PG_GET_EXPR(x.indexprs, x.indrelid), '')::text AS expressions
+  , COALESCE((
+    SELECT STRING_AGG(a.attname, ',' ORDER BY a.attnum)
+    FROM pg_attribute AS a
+    WHERE
+      a.attrelid = t.oid
+      AND a.attnum > 0
+      AND NOT a.attisdropped
+      AND PG_GET_EXPR(x.indpred, x.indrelid) ~ ('\m' || a.attname || '\M')
+  ), '')::text AS predicate_columns
+  , COALESCE((
+    SELECT STRING_AGG(a.attname, ',' ORDER BY a.attnum)
+    FROM pg_attribute AS a
+    WHERE
+      a.attrelid = t.oid
+      AND a.attnum > 0
+      AND NOT a.attisdropped
+      AND PG_GET_EXPR(x.indexprs, x.indrelid) ~ ('\m' || a.attname || '\M')
+  ), '')::text AS expression_columns
+  , PG_RELATION_SIZE(i.oid)::bigint AS index_bytes
+  , COALESCE(psi.idx_scan, 0)::bigint AS idx_scan
+  , (COALESCE(pst.n_tup_ins, 0) + COALESCE(pst.n_tup_upd, 0) + COALESCE(pst.n_tup_del, 0))::bigint AS table_writes
+FROM pg_index AS x
+INNER JOIN pg_class AS i ON x.indexrelid = i.oid
+INNER JOIN pg_class AS t ON x.indrelid = t.oid
+INNER JOIN pg_namespace AS n ON t.relnamespace = n.oid
+LEFT JOIN pg_stat_user_indexes AS psi ON x.indexrelid = psi.indexrelid
+LEFT JOIN pg_stat_user_tables AS pst ON t.oid = pst.relid
+WHERE
+  (x.indpred IS NOT NULL OR x.indexprs IS NOT NULL)
+  AND n.nspname NOT IN ('pg_catalog', 'information_schema')
+  AND n.nspname !~ '^pg_toast'
+ORDER BY t.relname, i.relname
+`
+
+type PartialExpressionIndexesRow struct {
+	TableName         string
+	TableShortName    string
+	IndexName         string
+	IsUnique          bool
+	Predicate         string
+	Expressions       string
+	PredicateColumns  string
+	ExpressionColumns string
+	IndexBytes        int64
+	IdxScan           int64
+	TableWrites       int64
+}
+
+// Partial and expression indexes with their predicate or expressions, the
+// table columns those reference, and usage statistics.
 // Excludes: system schemas.
-// Returns data for subchecks: unused-indexes, low-usage-indexes, index-cache-ratio.
-func (q *Queries) IndexUsageStats(ctx context.Context) ([]IndexUsageStatsRow, error) {
-	rows, err := q.db.Query(ctx, indexUsageStats)
+func (q *Queries) PartialExpressionIndexes(ctx context.Context) ([]PartialExpressionIndexesRow, error) {
+	rows, err := q.db.Query(ctx, partialExpressionIndexes)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []IndexUsageStatsRow
+	var items []PartialExpressionIndexesRow
 	for rows.Next() {
-		var i IndexUsageStatsRow
+		var i PartialExpressionIndexesRow
 		if err := rows.Scan(
 			&i.TableName,
+			&i.TableShortName,
 			&i.IndexName,
-			&i.NumRows,
-			&i.IsPrimary,
 			&i.IsUnique,
-			&i.IndexSizeBytes,
+			&i.Predicate,
+			&i.Expressions,
+			&i.PredicateColumns,
+			&i.ExpressionColumns,
+			&i.IndexBytes,
 			&i.IdxScan,
-			&i.IdxTupRead,
-			&i.IdxTupFetch,
 			&i.TableWrites,
-			&i.IdxBlksHit,
-			&i.IdxBlksRead,
-			&i.CacheHitRatio,
-			&i.Indexdef,
 		); err != nil {
 			return nil, err
 		}
@@ -710,117 +3793,262 @@ func (q *Queries) IndexUsageStats(ctx context.Context) ([]IndexUsageStatsRow, er
 	return items, nil
 }
 
-const invalidPrimaryKeyTypes = `-- name: InvalidPrimaryKeyTypes :many
-WITH pk_tables AS (
-  SELECT
-    n.nspname::text AS schema_name
-    , c.relname::text AS table_name
-    , a.attname::text AS column_name
-    , a.attnum AS column_num
-    , t.typname::text AS column_type
-    , c.oid AS table_oid
-    , COALESCE(s.n_live_tup, 0)::bigint AS estimated_rows
-    , CASE t.typname
-      WHEN 'int2' THEN 32767::bigint
-      WHEN 'int4' THEN 2147483647::bigint
-    END AS type_max_value
-  FROM pg_catalog.pg_constraint AS con
-  INNER JOIN pg_catalog.pg_class AS c ON con.conrelid = c.oid
-  INNER JOIN pg_catalog.pg_namespace AS n ON c.relnamespace = n.oid
-  INNER JOIN pg_catalog.pg_attribute AS a
-    ON
-      con.conrelid = a.attrelid
-      AND a.attnum = ANY(con.conkey)
-  INNER JOIN pg_catalog.pg_type AS t ON a.atttypid = t.oid
-  LEFT JOIN pg_stat_user_tables AS s ON c.oid = s.relid
-  WHERE
-    con.contype = 'p'
-    AND n.nspname NOT IN ('pg_catalog', 'information_schema', 'pgpartman', 'pgjobmon', 'cron')
-    AND t.typname IN ('int2', 'int4')
-    AND NOT EXISTS (
-      SELECT 1 FROM pg_inherits AS inh
-      WHERE inh.inhrelid = c.oid
-    )
-)
+const partialIndexStatStatementsAvailable = `-- name: PartialIndexStatStatementsAvailable :one
+SELECT EXISTS(
+  SELECT 1 FROM pg_extension
+  WHERE extname = 'pg_stat_statements'
+) AS available
+`
 
-, sequence_values AS (
-  SELECT
-    d.refobjid AS table_oid
-    , d.refobjsubid AS column_num
-    , seq.last_value::bigint AS sequence_current
-  FROM pg_depend AS d
-  INNER JOIN pg_class AS seq_class ON d.objid = seq_class.oid
-  INNER JOIN pg_sequences AS seq ON seq_class.relname = seq.sequencename
+// Checks if the pg_stat_statements extension is installed in this database.
+func (q *Queries) PartialIndexStatStatementsAvailable(ctx context.Context) (bool, error) {
+	row := q.db.QueryRow(ctx, partialIndexStatStatementsAvailable)
+	var available bool
+	err := row.Scan(&available)
+	return available, err
+}
+
+const partialIndexStatements = `-- name: PartialIndexStatements :many
+WITH indexed_tables AS (
+  SELECT DISTINCT t.relname
+  FROM pg_index AS x
+  INNER JOIN pg_class AS t ON x.indrelid = t.oid
+  INNER JOIN pg_namespace AS n ON t.relnamespace = n.oid
   WHERE
-    d.deptype = 'a'
-    AND seq_class.relkind = 'S'
+    (x.indpred IS NOT NULL OR x.indexprs IS NOT NULL)
+    AND n.nspname NOT IN ('pg_catalog', 'information_schema')
+    AND n.nspname !~ '^pg_toast'
 )
 
-, pk_with_usage AS (
-  SELECT
-    (p.schema_name || '.' || p.table_name)::text AS table_name
-    , p.column_name
-    , p.column_type
-    , p.estimated_rows
-    , sv.sequence_current
-    , p.type_max_value
-    , CASE
-      WHEN sv.sequence_current IS NOT NULL AND p.type_max_value > 0
-        THEN sv.sequence_current::numeric / p.type_max_value::numeric
-      WHEN p.estimated_rows > 0 AND p.type_max_value > 0
-        THEN p.estimated_rows::numeric / p.type_max_value::numeric
-      ELSE
-        0::numeric
-    END AS usage_pct
-  FROM pk_tables AS p
-  LEFT JOIN sequence_values AS sv
-    ON
-      p.table_oid = sv.table_oid
-      AND p.column_num = sv.column_num
-)
+SELECT
+  s.queryid::bigint AS query_id
+  , REGEXP_REPLACE(s.query, '\s+', ' ', 'g')::text AS query
+  , s.calls::bigint AS calls
+  , s.total_exec_time::float8 AS total_exec_time
+FROM pg_stat_statements AS s
+WHERE
+  s.calls > 10
+  AND s.query ~* '^\s*(SELECT|UPDATE|DELETE|WITH)'
+  AND EXISTS (
+    SELECT 1 FROM indexed_tables AS it
+    WHERE s.query ~* ('\m' || it.relname || '\M')
+  )
+ORDER BY s.total_exec_time DESC
+LIMIT 500
+`
+
+type PartialIndexStatementsRow struct {
+	QueryID       int64
+	Query         string
+	Calls         int64
+	TotalExecTime float64
+}
+
+// Frequent DML statements that mention a table carrying a partial or
+// expression index, for matching against index predicates and expressions.
+func (q *Queries) PartialIndexStatements(ctx context.Context) ([]PartialIndexStatementsRow, error) {
+	rows, err := q.db.Query(ctx, partialIndexStatements)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []PartialIndexStatementsRow
+	for rows.Next() {
+		var i PartialIndexStatementsRow
+		if err := rows.Scan(
+			&i.QueryID,
+			&i.Query,
+			&i.Calls,
+			&i.TotalExecTime,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
 
+const partitionedForeignKeys = `-- name: PartitionedForeignKeys :many
 SELECT
-  table_name
-  , column_name
-  , column_type
-  , estimated_rows
-  , sequence_current
-  , type_max_value
-  , usage_pct
-FROM pk_with_usage
-ORDER BY
-  usage_pct DESC NULLS LAST
-  , estimated_rows DESC NULLS LAST
+  con.conname::text AS constraint_name
+  , rn.nspname::text AS schema_name
+  , r.relname::text AS table_name
+  , fn.nspname::text AS referenced_schema
+  , f.relname::text AS referenced_table
+  , (
+    SELECT array_agg(a.attname::text ORDER BY k.n)
+    FROM unnest(con.conkey) WITH ORDINALITY AS k (attnum, n)
+    INNER JOIN pg_catalog.pg_attribute AS a ON a.attrelid = con.conrelid AND k.attnum = a.attnum
+  )::text [] AS columns
+  , (r.relkind = 'p') AS referencing_partitioned
+  , (f.relkind = 'p') AS referenced_partitioned
+  , con.confdeltype::text AS on_delete
+  -- An index supports the key when its leading columns are exactly the
+  -- referencing columns; indkey is zero-based.
+  , EXISTS (
+    SELECT 1
+    FROM pg_catalog.pg_index AS x
+    WHERE
+      x.indrelid = con.conrelid
+      AND x.indisvalid
+      AND x.indpred IS NULL
+      AND (x.indkey::int2 [])[0:cardinality(con.conkey) - 1] @> con.conkey
+      AND (x.indkey::int2 [])[0:cardinality(con.conkey) - 1] <@ con.conkey
+  ) AS has_index
+  , (
+    SELECT coalesce(sum(s.n_live_tup), 0)
+    FROM pg_partition_tree(con.conrelid) AS t
+    INNER JOIN pg_stat_user_tables AS s ON t.relid = s.relid
+  )::bigint AS referencing_rows
+  , (
+    SELECT coalesce(sum(s.n_tup_del + s.n_tup_upd), 0)
+    FROM pg_partition_tree(con.confrelid) AS t
+    INNER JOIN pg_stat_user_tables AS s ON t.relid = s.relid
+  )::bigint AS referenced_writes
+FROM pg_catalog.pg_constraint AS con
+INNER JOIN pg_catalog.pg_class AS r ON con.conrelid = r.oid
+INNER JOIN pg_catalog.pg_namespace AS rn ON r.relnamespace = rn.oid
+INNER JOIN pg_catalog.pg_class AS f ON con.confrelid = f.oid
+INNER JOIN pg_catalog.pg_namespace AS fn ON f.relnamespace = fn.oid
+WHERE
+  con.contype = 'f'
+  AND con.conparentid = 0
+  AND (r.relkind = 'p' OR f.relkind = 'p')
+  AND rn.nspname NOT IN ('pg_catalog', 'information_schema')
+ORDER BY referencing_rows DESC, rn.nspname, r.relname, con.conname
+`
+
+type PartitionedForeignKeysRow struct {
+	ConstraintName         string
+	SchemaName             string
+	TableName              string
+	ReferencedSchema       string
+	ReferencedTable        string
+	Columns                []string
+	ReferencingPartitioned bool
+	ReferencedPartitioned  bool
+	OnDelete               string
+	HasIndex               bool
+	ReferencingRows        int64
+	ReferencedWrites       int64
+}
+
+// Lists foreign keys where either side is a partitioned table, with what is
+// needed to score how much work a DELETE or key UPDATE on the referenced side
+// causes: whether the referencing columns are indexed, how many rows the
+// referencing table holds across all partitions, and how many deletes and
+// updates the referenced table has seen. Constraints cloned onto partitions
+// (conparentid <> 0) are folded into their parent.
+func (q *Queries) PartitionedForeignKeys(ctx context.Context) ([]PartitionedForeignKeysRow, error) {
+	rows, err := q.db.Query(ctx, partitionedForeignKeys)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []PartitionedForeignKeysRow
+	for rows.Next() {
+		var i PartitionedForeignKeysRow
+		if err := rows.Scan(
+			&i.ConstraintName,
+			&i.SchemaName,
+			&i.TableName,
+			&i.ReferencedSchema,
+			&i.ReferencedTable,
+			&i.Columns,
+			&i.ReferencingPartitioned,
+			&i.ReferencedPartitioned,
+			&i.OnDelete,
+			&i.HasIndex,
+			&i.ReferencingRows,
+			&i.ReferencedWrites,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const partitionedIndexGaps = `-- name: PartitionedIndexGaps :many
+SELECT
+  pn.nspname::text AS schema_name
+  , pt.relname::text AS table_name
+  , pic.relname::text AS index_name
+  , cn.nspname::text AS partition_schema
+  , ct.relname::text AS partition_name
+  , child.index_name AS child_index_name
+  , COALESCE(child.is_valid, false) AS child_valid
+  , pi.indisunique AS is_unique
+  , pg_catalog.pg_get_indexdef(pi.indexrelid)::text AS index_def
+FROM pg_catalog.pg_index AS pi
+INNER JOIN pg_catalog.pg_class AS pic ON pi.indexrelid = pic.oid
+INNER JOIN pg_catalog.pg_class AS pt ON pi.indrelid = pt.oid
+INNER JOIN pg_catalog.pg_namespace AS pn ON pt.relnamespace = pn.oid
+INNER JOIN pg_catalog.pg_inherits AS tinh ON pt.oid = tinh.inhparent
+INNER JOIN pg_catalog.pg_class AS ct ON tinh.inhrelid = ct.oid
+INNER JOIN pg_catalog.pg_namespace AS cn ON ct.relnamespace = cn.oid
+LEFT JOIN LATERAL (
+  SELECT
+    ci.relname::text AS index_name
+    , cx.indisvalid AS is_valid
+  FROM pg_catalog.pg_inherits AS iinh
+  INNER JOIN pg_catalog.pg_index AS cx ON iinh.inhrelid = cx.indexrelid
+  INNER JOIN pg_catalog.pg_class AS ci ON cx.indexrelid = ci.oid
+  WHERE
+    iinh.inhparent = pi.indexrelid
+    AND cx.indrelid = ct.oid
+) AS child ON true
+WHERE
+  pic.relkind = 'I'
+  AND ct.relkind IN ('r', 'p')
+  AND pn.nspname NOT IN ('pg_catalog', 'information_schema')
+  AND (child.index_name IS NULL OR NOT child.is_valid)
+ORDER BY pn.nspname, pt.relname, pic.relname, cn.nspname, ct.relname
 `
 
-type InvalidPrimaryKeyTypesRow struct {
-	TableName       pgtype.Text
-	ColumnName      pgtype.Text
-	ColumnType      pgtype.Text
-	EstimatedRows   pgtype.Int8
-	SequenceCurrent pgtype.Int8
-	TypeMaxValue    pgtype.Int8
-	UsagePct        pgtype.Numeric
+type PartitionedIndexGapsRow struct {
+	SchemaName      string
+	TableName       string
+	IndexName       string
+	PartitionSchema string
+	PartitionName   string
+	ChildIndexName  pgtype.Text
+	ChildValid      bool
+	IsUnique        bool
+	IndexDef        string
 }
 
-// Identifies tables with integer primary keys (int2/int4) that should use bigint.
-func (q *Queries) InvalidPrimaryKeyTypes(ctx context.Context) ([]InvalidPrimaryKeyTypesRow, error) {
-	rows, err := q.db.Query(ctx, invalidPrimaryKeyTypes)
+// Lists partitions of partitioned tables that lack a valid index attached to
+// each partitioned index: either no child index is attached (missing) or the
+// attached one is invalid, e.g. after a failed CREATE INDEX CONCURRENTLY.
+// Only direct partitions are listed; sub-partitioned children carry their own
+// partitioned index and are checked at their level. Foreign-table partitions
+// cannot hold indexes and are skipped.
+func (q *Queries) PartitionedIndexGaps(ctx context.Context) ([]PartitionedIndexGapsRow, error) {
+	rows, err := q.db.Query(ctx, partitionedIndexGaps)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []InvalidPrimaryKeyTypesRow
+	var items []PartitionedIndexGapsRow
 	for rows.Next() {
-		var i InvalidPrimaryKeyTypesRow
+		var i PartitionedIndexGapsRow
 		if err := rows.Scan(
+			&i.SchemaName,
 			&i.TableName,
-			&i.ColumnName,
-			&i.ColumnType,
-			&i.EstimatedRows,
-			&i.SequenceCurrent,
-			&i.TypeMaxValue,
-			&i.UsagePct,
+			&i.IndexName,
+			&i.PartitionSchema,
+			&i.PartitionName,
+			&i.ChildIndexName,
+			&i.ChildValid,
+			&i.IsUnique,
+			&i.IndexDef,
 		); err != nil {
 			return nil, err
 		}
@@ -832,74 +4060,86 @@ func (q *Queries) InvalidPrimaryKeyTypes(ctx context.Context) ([]InvalidPrimaryK
 	return items, nil
 }
 
-const largeTables = `-- name: LargeTables :many
-WITH inheritance_info AS (
-  SELECT DISTINCT ON (i.inhrelid)
-    i.inhrelid AS child_oid
-    , (pn.nspname || '.' || pc.relname)::text AS parent_table
+const partitionedTablesWithKeys = `-- name: PartitionedTablesWithKeys :many
+WITH partition_stats AS (
+  -- Single aggregation of all partition metrics from child tables
+  SELECT
+    i.inhparent
+    , COUNT(*)::bigint AS partition_count
+    , COALESCE(SUM(pg_catalog.pg_total_relation_size(i.inhrelid)), 0)::bigint AS total_size_bytes
+    , COALESCE(SUM(s.n_live_tup), 0)::bigint AS estimated_rows
+    , COALESCE(SUM(s.seq_scan), 0)::bigint AS total_seq_scans
+    , COALESCE(SUM(s.idx_scan), 0)::bigint AS total_idx_scans
   FROM pg_inherits AS i
-  INNER JOIN pg_class AS pc ON i.inhparent = pc.oid
-  INNER JOIN pg_namespace AS pn ON pc.relnamespace = pn.oid
-  ORDER BY i.inhrelid, i.inhparent
+  LEFT JOIN pg_stat_user_tables AS s ON i.inhrelid = s.relid
+  GROUP BY i.inhparent
 )
 
 SELECT
-  (n.nspname || '.' || c.relname)::text AS table_name
-  , ii.parent_table
-  , pg_catalog.pg_table_size(c.oid) AS table_size_bytes
-  , COALESCE(s.n_live_tup, 0) AS estimated_rows
-  , (c.relkind = 'p') AS is_partitioned
-  , (ii.parent_table IS NOT NULL) AS is_partition
-  , (c.relname ~ '(outbox|inbox|_jobs?$|^oban_|logs|events?$)') AS is_transient
-  , COALESCE(s.n_tup_ins, 0) AS n_tup_ins
-  , COALESCE(s.n_tup_upd, 0) AS n_tup_upd
-  , COALESCE(s.n_tup_del, 0) AS n_tup_del
+  n.nspname::text AS schema_name
+  , c.relname::text AS table_name
+  , pt.partstrat::text AS partition_strategy
+  -- Get partition key column names as comma-separated string
+  , (
+    SELECT STRING_AGG(a.attname, ',' ORDER BY k.n)
+    FROM UNNEST(pt.partattrs) WITH ORDINALITY AS k (attnum, n)
+    INNER JOIN pg_attribute AS a ON a.attrelid = c.oid AND k.attnum = a.attnum
+    WHERE k.attnum > 0
+  )::text AS partition_key_columns
+  -- Check if partition key includes expressions (attnum = 0 means expression)
+  , (SELECT BOOL_OR(k.attnum = 0) FROM UNNEST(pt.partattrs) AS k (attnum)) AS has_expression_key
+  -- All partition metrics from pre-aggregated CTE
+  , COALESCE(ps.partition_count, 0) AS partition_count
+  , COALESCE(ps.total_size_bytes, 0) AS total_size_bytes
+  , COALESCE(ps.estimated_rows, 0) AS estimated_rows
+  , COALESCE(ps.total_seq_scans, 0) AS total_seq_scans
+  , COALESCE(ps.total_idx_scans, 0) AS total_idx_scans
 FROM pg_catalog.pg_class AS c
 INNER JOIN pg_catalog.pg_namespace AS n ON c.relnamespace = n.oid
-LEFT JOIN pg_stat_user_tables AS s ON c.oid = s.relid
-LEFT JOIN inheritance_info AS ii ON c.oid = ii.child_oid
+INNER JOIN pg_partitioned_table AS pt ON c.oid = pt.partrelid
+LEFT JOIN partition_stats AS ps ON c.oid = ps.inhparent
 WHERE
-  c.relkind IN ('r', 'p')
+  c.relkind = 'p'
   AND n.nspname NOT IN ('pg_catalog', 'information_schema', 'pg_toast', 'pgpartman', 'debezium', 'cron')
-  AND COALESCE(s.n_live_tup, 0) >= 10000000
+ORDER BY ps.total_size_bytes DESC NULLS LAST
 `
 
-type LargeTablesRow struct {
-	TableName      pgtype.Text
-	ParentTable    pgtype.Text
-	TableSizeBytes pgtype.Int8
-	EstimatedRows  pgtype.Int8
-	IsPartitioned  pgtype.Bool
-	IsPartition    pgtype.Bool
-	IsTransient    pgtype.Bool
-	NTupIns        pgtype.Int8
-	NTupUpd        pgtype.Int8
-	NTupDel        pgtype.Int8
+type PartitionedTablesWithKeysRow struct {
+	SchemaName          pgtype.Text
+	TableName           pgtype.Text
+	PartitionStrategy   pgtype.Text
+	PartitionKeyColumns pgtype.Text
+	HasExpressionKey    pgtype.Bool
+	PartitionCount      pgtype.Int8
+	TotalSizeBytes      pgtype.Int8
+	EstimatedRows       pgtype.Int8
+	TotalSeqScans       pgtype.Int8
+	TotalIdxScans       pgtype.Int8
 }
 
-// Identifies all large tables (>= 10M rows) with partitioning and transient status.
-// Returns both regular and partitioned tables for unified analysis.
-// Includes activity metrics (inserts/updates/deletes) for activity-aware thresholds.
-func (q *Queries) LargeTables(ctx context.Context) ([]LargeTablesRow, error) {
-	rows, err := q.db.Query(ctx, largeTables)
+// Gets partitioned tables and their partition key column(s).
+// Pre-aggregates all partition statistics in a single CTE for better performance
+// compared to multiple correlated subqueries.
+func (q *Queries) PartitionedTablesWithKeys(ctx context.Context) ([]PartitionedTablesWithKeysRow, error) {
+	rows, err := q.db.Query(ctx, partitionedTablesWithKeys)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []LargeTablesRow
+	var items []PartitionedTablesWithKeysRow
 	for rows.Next() {
-		var i LargeTablesRow
+		var i PartitionedTablesWithKeysRow
 		if err := rows.Scan(
+			&i.SchemaName,
 			&i.TableName,
-			&i.ParentTable,
-			&i.TableSizeBytes,
+			&i.PartitionStrategy,
+			&i.PartitionKeyColumns,
+			&i.HasExpressionKey,
+			&i.PartitionCount,
+			&i.TotalSizeBytes,
 			&i.EstimatedRows,
-			&i.IsPartitioned,
-			&i.IsPartition,
-			&i.IsTransient,
-			&i.NTupIns,
-			&i.NTupUpd,
-			&i.NTupDel,
+			&i.TotalSeqScans,
+			&i.TotalIdxScans,
 		); err != nil {
 			return nil, err
 		}
@@ -911,55 +4151,68 @@ func (q *Queries) LargeTables(ctx context.Context) ([]LargeTablesRow, error) {
 	return items, nil
 }
 
-const longIdleConnections = `-- name: LongIdleConnections :many
+const pgstatindexAvailable = `-- name: PgstatindexAvailable :one
+SELECT EXISTS(
+  SELECT 1
+  FROM pg_proc
+  WHERE proname = 'pgstatindex' AND HAS_FUNCTION_PRIVILEGE(oid, 'EXECUTE')
+) AS available
+`
+
+// Checks whether pgstatindex is installed and executable by the current role.
+func (q *Queries) PgstatindexAvailable(ctx context.Context) (bool, error) {
+	row := q.db.QueryRow(ctx, pgstatindexAvailable)
+	var available bool
+	err := row.Scan(&available)
+	return available, err
+}
+
+const pgstattupleApproxAvailable = `-- name: PgstattupleApproxAvailable :one
+SELECT EXISTS(
+  SELECT 1
+  FROM pg_proc
+  WHERE proname = 'pgstattuple_approx' AND HAS_FUNCTION_PRIVILEGE(oid, 'EXECUTE')
+) AS available
+`
+
+// Checks whether pgstattuple_approx is installed and executable by the current role.
+func (q *Queries) PgstattupleApproxAvailable(ctx context.Context) (bool, error) {
+	row := q.db.QueryRow(ctx, pgstattupleApproxAvailable)
+	var available bool
+	err := row.Scan(&available)
+	return available, err
+}
+
+const predicateStatements = `-- name: PredicateStatements :many
 SELECT
-  pid
-  , usename::text AS username
-  , datname::text AS database_name
-  , application_name::text AS application_name
-  , client_addr::text AS client_address
-  , state::text AS state
-  , extract(EPOCH FROM (now() - state_change))::bigint AS idle_duration_seconds
-  , extract(EPOCH FROM (now() - backend_start))::bigint AS connection_age_seconds
-FROM pg_stat_activity
+  LOWER(LEFT(REGEXP_REPLACE(query, '\s+', ' ', 'g'), 4000))::text AS query
+  , calls::bigint AS calls
+FROM pg_stat_statements
 WHERE
-  state = 'idle'
-  AND pid != pg_backend_pid()
-  AND (now() - state_change) > interval '30 minutes'
-ORDER BY state_change ASC
+  calls >= 100
+  AND query ~* '\mwhere\M'
+  AND query !~* '^\s*(COPY|SET|BEGIN|COMMIT|ROLLBACK|SAVEPOINT|PREPARE|DEALLOCATE|VACUUM|ANALYZE|CREATE|DROP|ALTER)'
+ORDER BY calls DESC
+LIMIT 1000
 `
 
-type LongIdleConnectionsRow struct {
-	Pid                  pgtype.Int4
-	Username             pgtype.Text
-	DatabaseName         pgtype.Text
-	ApplicationName      pgtype.Text
-	ClientAddress        pgtype.Text
-	State                pgtype.Text
-	IdleDurationSeconds  pgtype.Int8
-	ConnectionAgeSeconds pgtype.Int8
+type PredicateStatementsRow struct {
+	Query string
+	Calls int64
 }
 
-// Identifies connections that have been idle for too long (potential pool leak).
-func (q *Queries) LongIdleConnections(ctx context.Context) ([]LongIdleConnectionsRow, error) {
-	rows, err := q.db.Query(ctx, longIdleConnections)
+// Frequently run statements with a WHERE clause, lowercased with whitespace
+// collapsed so column references can be matched in Go.
+func (q *Queries) PredicateStatements(ctx context.Context) ([]PredicateStatementsRow, error) {
+	rows, err := q.db.Query(ctx, predicateStatements)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []LongIdleConnectionsRow
+	var items []PredicateStatementsRow
 	for rows.Next() {
-		var i LongIdleConnectionsRow
-		if err := rows.Scan(
-			&i.Pid,
-			&i.Username,
-			&i.DatabaseName,
-			&i.ApplicationName,
-			&i.ClientAddress,
-			&i.State,
-			&i.IdleDurationSeconds,
-			&i.ConnectionAgeSeconds,
-		); err != nil {
+		var i PredicateStatementsRow
+		if err := rows.Scan(&i.Query, &i.Calls); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -970,73 +4223,49 @@ func (q *Queries) LongIdleConnections(ctx context.Context) ([]LongIdleConnection
 	return items, nil
 }
 
-const missingProviderIdTables = `-- name: MissingProviderIdTables :many
-WITH user_tables AS (
-  SELECT
-    (n.nspname || '.' || c.relname)::text AS table_name
-    , c.oid AS table_oid
-    , pg_catalog.pg_table_size(c.oid) AS table_size_bytes
-    , CASE
-      WHEN c.relkind = 'p'
-        THEN (
-          -- For partitioned tables, sum stats from all child partitions
-          SELECT COALESCE(SUM(child_stats.n_live_tup), 0)::bigint
-          FROM pg_catalog.pg_inherits AS i
-          INNER JOIN pg_stat_user_tables AS child_stats ON i.inhrelid = child_stats.relid
-          WHERE i.inhparent = c.oid
-        )
-      ELSE COALESCE(s.n_live_tup, 0)
-    END AS estimated_rows
-  FROM pg_catalog.pg_class AS c
-  INNER JOIN pg_catalog.pg_namespace AS n ON c.relnamespace = n.oid
-  LEFT JOIN pg_stat_user_tables AS s ON c.oid = s.relid
-  WHERE
-    c.relkind IN ('r', 'p')
-    AND n.nspname = 'public'
-)
-
-, tables_with_provider_id AS (
-  SELECT DISTINCT a.attrelid AS table_oid
-  FROM pg_catalog.pg_attribute AS a
-  WHERE
-    a.attname = 'provider_id'
-    AND a.attnum > 0
-    AND NOT a.attisdropped
-)
-
+const preparedXacts = `-- name: PreparedXacts :many
 SELECT
-  CURRENT_DATABASE()::text AS database_name
-  , ut.table_name
-  , ut.table_size_bytes
-  , ut.estimated_rows
-FROM user_tables AS ut
-LEFT JOIN tables_with_provider_id AS t ON ut.table_oid = t.table_oid
-WHERE t.table_oid IS NULL
-ORDER BY ut.table_size_bytes DESC
+  p.gid::text AS gid
+  , p.owner::text AS owner
+  , p.database::text AS database_name
+  , EXTRACT(EPOCH FROM (NOW() - p.prepared))::float8 AS age_seconds
+  , AGE(p.transaction)::bigint AS xid_age
+  , (
+    SELECT COUNT(*)
+    FROM pg_locks AS l
+    WHERE l.pid IS NULL AND l.virtualtransaction = '-1/' || p.transaction::text
+  )::bigint AS lock_count
+FROM pg_prepared_xacts AS p
+ORDER BY p.prepared
 `
 
-type MissingProviderIdTablesRow struct {
-	DatabaseName   pgtype.Text
-	TableName      pgtype.Text
-	TableSizeBytes pgtype.Int8
-	EstimatedRows  pgtype.Int8
+type PreparedXactsRow struct {
+	Gid          string
+	Owner        string
+	DatabaseName string
+	AgeSeconds   float64
+	XidAge       int64
+	LockCount    int64
 }
 
-// Identifies tables without provider_id column for multi-tenancy support.
-func (q *Queries) MissingProviderIdTables(ctx context.Context) ([]MissingProviderIdTablesRow, error) {
-	rows, err := q.db.Query(ctx, missingProviderIdTables)
+// Prepared (two-phase) transactions across all databases, oldest first, with
+// the XID age they pin and the locks they still hold.
+func (q *Queries) PreparedXacts(ctx context.Context) ([]PreparedXactsRow, error) {
+	rows, err := q.db.Query(ctx, preparedXacts)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []MissingProviderIdTablesRow
+	var items []PreparedXactsRow
 	for rows.Next() {
-		var i MissingProviderIdTablesRow
+		var i PreparedXactsRow
 		if err := rows.Scan(
+			&i.Gid,
+			&i.Owner,
 			&i.DatabaseName,
-			&i.TableName,
-			&i.TableSizeBytes,
-			&i.EstimatedRows,
+			&i.AgeSeconds,
+			&i.XidAge,
+			&i.LockCount,
 		); err != nil {
 			return nil, err
 		}
@@ -1048,104 +4277,100 @@ func (q *Queries) MissingProviderIdTables(ctx context.Context) ([]MissingProvide
 	return items, nil
 }
 
-const pGVersion = `-- name: PGVersion :one
+const publicCreateSchemas = `-- name: PublicCreateSchemas :many
 SELECT
-  current_setting('server_version_num')::integer / 10000 AS major
-  , current_setting('server_version_num')::integer % 100 AS minor
+  n.nspname::text AS schema_name
+  , pg_get_userbyid(n.nspowner)::text AS owner
+FROM pg_namespace AS n
+WHERE
+  n.nspname NOT IN ('information_schema')
+  AND n.nspname !~ '^pg_'
+  AND EXISTS (
+    SELECT 1 FROM ACLEXPLODE(n.nspacl) AS acl
+    WHERE acl.grantee = 0 AND acl.privilege_type = 'CREATE'
+  )
+ORDER BY n.nspname
 `
 
-type PGVersionRow struct {
-	Major int32
-	Minor int32
-}
-
-func (q *Queries) PGVersion(ctx context.Context) (PGVersionRow, error) {
-	row := q.db.QueryRow(ctx, pGVersion)
-	var i PGVersionRow
-	err := row.Scan(&i.Major, &i.Minor)
-	return i, err
+type PublicCreateSchemasRow struct {
+	SchemaName string
+	Owner      string
 }
 
-const partitionedTablesWithKeys = `-- name: PartitionedTablesWithKeys :many
-WITH partition_stats AS (
-  -- Single aggregation of all partition metrics from child tables
-  SELECT
-    i.inhparent
-    , COUNT(*)::bigint AS partition_count
-    , COALESCE(SUM(pg_catalog.pg_total_relation_size(i.inhrelid)), 0)::bigint AS total_size_bytes
-    , COALESCE(SUM(s.n_live_tup), 0)::bigint AS estimated_rows
-    , COALESCE(SUM(s.seq_scan), 0)::bigint AS total_seq_scans
-    , COALESCE(SUM(s.idx_scan), 0)::bigint AS total_idx_scans
-  FROM pg_inherits AS i
-  LEFT JOIN pg_stat_user_tables AS s ON i.inhrelid = s.relid
-  GROUP BY i.inhparent
-)
+// Schemas where PUBLIC, and so every role, may create objects. Until
+// PostgreSQL 15 the public schema granted this by default.
+func (q *Queries) PublicCreateSchemas(ctx context.Context) ([]PublicCreateSchemasRow, error) {
+	rows, err := q.db.Query(ctx, publicCreateSchemas)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []PublicCreateSchemasRow
+	for rows.Next() {
+		var i PublicCreateSchemasRow
+		if err := rows.Scan(&i.SchemaName, &i.Owner); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
 
+const queryStatsFromStatMonitor = `-- name: QueryStatsFromStatMonitor :many
 SELECT
-  n.nspname::text AS schema_name
-  , c.relname::text AS table_name
-  , pt.partstrat::text AS partition_strategy
-  -- Get partition key column names as comma-separated string
-  , (
-    SELECT STRING_AGG(a.attname, ',' ORDER BY k.n)
-    FROM UNNEST(pt.partattrs) WITH ORDINALITY AS k (attnum, n)
-    INNER JOIN pg_attribute AS a ON a.attrelid = c.oid AND k.attnum = a.attnum
-    WHERE k.attnum > 0
-  )::text AS partition_key_columns
-  -- Check if partition key includes expressions (attnum = 0 means expression)
-  , (SELECT BOOL_OR(k.attnum = 0) FROM UNNEST(pt.partattrs) AS k (attnum)) AS has_expression_key
-  -- All partition metrics from pre-aggregated CTE
-  , COALESCE(ps.partition_count, 0) AS partition_count
-  , COALESCE(ps.total_size_bytes, 0) AS total_size_bytes
-  , COALESCE(ps.estimated_rows, 0) AS estimated_rows
-  , COALESCE(ps.total_seq_scans, 0) AS total_seq_scans
-  , COALESCE(ps.total_idx_scans, 0) AS total_idx_scans
-FROM pg_catalog.pg_class AS c
-INNER JOIN pg_catalog.pg_namespace AS n ON c.relnamespace = n.oid
-INNER JOIN pg_partitioned_table AS pt ON c.oid = pt.partrelid
-LEFT JOIN partition_stats AS ps ON c.oid = ps.inhparent
+  queryid::bigint AS query_id
+  , LEFT(REGEXP_REPLACE(MIN(query), '\s+', ' ', 'g'), 80)::text AS query
+  , SUM(calls)::bigint AS calls
+  , SUM(total_exec_time)::double precision AS total_exec_time
+  , (SUM(total_exec_time) / NULLIF(SUM(calls), 0))::double precision AS mean_exec_time
+  , MAX(max_exec_time)::double precision AS max_exec_time
+  , SUM(rows)::bigint AS rows_returned
+FROM pg_stat_monitor
 WHERE
-  c.relkind = 'p'
-  AND n.nspname NOT IN ('pg_catalog', 'information_schema', 'pg_toast', 'pgpartman', 'debezium', 'cron')
-ORDER BY ps.total_size_bytes DESC NULLS LAST
+  query NOT LIKE 'COPY%'
+  AND query NOT LIKE 'SET %'
+  AND query !~ '^(BEGIN|COMMIT|ROLLBACK|SAVEPOINT|PREPARE|DEALLOCATE)'
+  AND query !~ '^(VACUUM|ANALYZE|REINDEX|CLUSTER)'
+  AND query !~ '^(CREATE|DROP|ALTER|TRUNCATE)'
+  AND (query ILIKE '%SELECT%' OR query ILIKE '%UPDATE%' OR query ILIKE '%DELETE%')
+GROUP BY queryid
+HAVING SUM(calls) > 10
+ORDER BY SUM(total_exec_time) DESC
+LIMIT 500
 `
 
-type PartitionedTablesWithKeysRow struct {
-	SchemaName          pgtype.Text
-	TableName           pgtype.Text
-	PartitionStrategy   pgtype.Text
-	PartitionKeyColumns pgtype.Text
-	HasExpressionKey    pgtype.Bool
-	PartitionCount      pgtype.Int8
-	TotalSizeBytes      pgtype.Int8
-	EstimatedRows       pgtype.Int8
-	TotalSeqScans       pgtype.Int8
-	TotalIdxScans       pgtype.Int8
+type QueryStatsFromStatMonitorRow struct {
+	QueryID       pgtype.Int8
+	Query         pgtype.Text
+	Calls         pgtype.Int8
+	TotalExecTime pgtype.Float8
+	MeanExecTime  pgtype.Float8
+	MaxExecTime   pgtype.Float8
+	RowsReturned  pgtype.Int8
 }
 
-// Gets partitioned tables and their partition key column(s).
-// Pre-aggregates all partition statistics in a single CTE for better performance
-// compared to multiple correlated subqueries.
-func (q *Queries) PartitionedTablesWithKeys(ctx context.Context) ([]PartitionedTablesWithKeysRow, error) {
-	rows, err := q.db.Query(ctx, partitionedTablesWithKeys)
+// Gets query statistics from pg_stat_monitor, aggregated across its time buckets.
+// Used when pg_stat_statements is not installed; adds worst-case latency per query.
+func (q *Queries) QueryStatsFromStatMonitor(ctx context.Context) ([]QueryStatsFromStatMonitorRow, error) {
+	rows, err := q.db.Query(ctx, queryStatsFromStatMonitor)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []PartitionedTablesWithKeysRow
+	var items []QueryStatsFromStatMonitorRow
 	for rows.Next() {
-		var i PartitionedTablesWithKeysRow
+		var i QueryStatsFromStatMonitorRow
 		if err := rows.Scan(
-			&i.SchemaName,
-			&i.TableName,
-			&i.PartitionStrategy,
-			&i.PartitionKeyColumns,
-			&i.HasExpressionKey,
-			&i.PartitionCount,
-			&i.TotalSizeBytes,
-			&i.EstimatedRows,
-			&i.TotalSeqScans,
-			&i.TotalIdxScans,
+			&i.QueryID,
+			&i.Query,
+			&i.Calls,
+			&i.TotalExecTime,
+			&i.MeanExecTime,
+			&i.MaxExecTime,
+			&i.RowsReturned,
 		); err != nil {
 			return nil, err
 		}
@@ -1216,6 +4441,115 @@ func (q *Queries) QueryStatsFromStatStatements(ctx context.Context) ([]QueryStat
 	return items, nil
 }
 
+const queueTableCandidates = `-- name: QueueTableCandidates :many
+WITH stats_age AS (
+  SELECT EXTRACT(EPOCH FROM (NOW() - COALESCE(d.stats_reset, PG_POSTMASTER_START_TIME())))::bigint AS seconds
+  FROM pg_stat_database AS d
+  WHERE d.datname = CURRENT_DATABASE()
+)
+
+SELECT
+  s.schemaname::text AS schema_name
+  , s.relname::text AS table_name
+  , COALESCE((
+    SELECT p.relname FROM pg_inherits AS inh
+    INNER JOIN pg_class AS p ON inh.inhparent = p.oid
+    WHERE inh.inhrelid = c.oid AND c.relispartition
+  ), '')::text AS parent_name
+  , s.n_tup_ins::bigint AS inserts
+  , s.n_tup_upd::bigint AS updates
+  , s.n_tup_del::bigint AS deletes
+  , s.n_live_tup::bigint AS live_rows
+  , s.n_dead_tup::bigint AS dead_rows
+  , PG_TABLE_SIZE(c.oid)::bigint AS table_size_bytes
+  , COALESCE(EXTRACT(EPOCH FROM (NOW() - GREATEST(s.last_vacuum, s.last_autovacuum))), -1)::bigint AS last_vacuum_age_seconds
+  , COALESCE(ARRAY_TO_STRING(c.reloptions, ','), '')::text AS reloptions
+  , EXISTS (
+    SELECT 1 FROM pg_index AS x
+    WHERE x.indrelid = c.oid AND x.indpred IS NOT NULL AND x.indisvalid
+  ) AS has_partial_index
+  , (SELECT seconds FROM stats_age)::bigint AS stats_age_seconds
+FROM pg_stat_user_tables AS s
+INNER JOIN pg_class AS c ON s.relid = c.oid
+WHERE s.n_tup_ins > 0
+ORDER BY s.n_tup_ins DESC
+LIMIT 500
+`
+
+type QueueTableCandidatesRow struct {
+	SchemaName           string
+	TableName            string
+	ParentName           string
+	Inserts              int64
+	Updates              int64
+	Deletes              int64
+	LiveRows             int64
+	DeadRows             int64
+	TableSizeBytes       int64
+	LastVacuumAgeSeconds int64
+	Reloptions           string
+	HasPartialIndex      bool
+	StatsAgeSeconds      int64
+}
+
+// Tables with write activity since the stats reset, with the counters, vacuum
+// history, and indexes needed to judge whether a queue-like table is kept
+// small. Partitions report their parent so naming patterns can match it.
+func (q *Queries) QueueTableCandidates(ctx context.Context) ([]QueueTableCandidatesRow, error) {
+	rows, err := q.db.Query(ctx, queueTableCandidates)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []QueueTableCandidatesRow
+	for rows.Next() {
+		var i QueueTableCandidatesRow
+		if err := rows.Scan(
+			&i.SchemaName,
+			&i.TableName,
+			&i.ParentName,
+			&i.Inserts,
+			&i.Updates,
+			&i.Deletes,
+			&i.LiveRows,
+			&i.DeadRows,
+			&i.TableSizeBytes,
+			&i.LastVacuumAgeSeconds,
+			&i.Reloptions,
+			&i.HasPartialIndex,
+			&i.StatsAgeSeconds,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const repackExtensions = `-- name: RepackExtensions :one
+SELECT
+  EXISTS(SELECT 1 FROM pg_extension WHERE extname = 'pg_repack') AS has_pg_repack
+  , EXISTS(SELECT 1 FROM pg_extension WHERE extname = 'pg_squeeze') AS has_pg_squeeze
+  , CURRENT_DATABASE()::text AS database_name
+`
+
+type RepackExtensionsRow struct {
+	HasPgRepack  bool
+	HasPgSqueeze bool
+	DatabaseName string
+}
+
+// Checks which online table rewrite extensions are installed.
+func (q *Queries) RepackExtensions(ctx context.Context) (RepackExtensionsRow, error) {
+	row := q.db.QueryRow(ctx, repackExtensions)
+	var i RepackExtensionsRow
+	err := row.Scan(&i.HasPgRepack, &i.HasPgSqueeze, &i.DatabaseName)
+	return i, err
+}
+
 const replicationLag = `-- name: ReplicationLag :many
 SELECT
   -- Consumer/replica identity
@@ -1235,10 +4569,16 @@ SELECT
   -- Lag metrics (seconds) - cast to float8 (double precision) to get native float64
   , COALESCE(EXTRACT(EPOCH FROM sr.replay_lag), 0)::float8 AS replay_lag_seconds
 
+  -- Time to write and flush WAL on the replica, before any apply delay (NULL when idle)
+  , EXTRACT(EPOCH FROM sr.flush_lag)::float8 AS flush_lag_seconds
+
   -- Associated replication slot (NULL if no slot)
   , rs.slot_name::text AS slot_name
   , rs.wal_status::text AS wal_status
 
+  -- Synchronous replication role: async, potential, sync, or quorum
+  , sr.sync_state::text AS sync_state
+
 FROM pg_stat_replication AS sr
 LEFT JOIN pg_replication_slots AS rs ON sr.pid = rs.active_pid
 ORDER BY
@@ -1252,8 +4592,10 @@ type ReplicationLagRow struct {
 	ReplicationType  pgtype.Text
 	ReplayLagBytes   pgtype.Int8
 	ReplayLagSeconds pgtype.Float8
+	FlushLagSeconds  pgtype.Float8
 	SlotName         pgtype.Text
 	WalStatus        pgtype.Text
+	SyncState        pgtype.Text
 }
 
 // Monitors replication lag for both physical and logical replication streams.
@@ -1273,8 +4615,10 @@ func (q *Queries) ReplicationLag(ctx context.Context) ([]ReplicationLagRow, erro
 			&i.ReplicationType,
 			&i.ReplayLagBytes,
 			&i.ReplayLagSeconds,
+			&i.FlushLagSeconds,
 			&i.SlotName,
 			&i.WalStatus,
+			&i.SyncState,
 		); err != nil {
 			return nil, err
 		}
@@ -1451,6 +4795,200 @@ func (q *Queries) ReplicationSlotsPG15(ctx context.Context) ([]ReplicationSlotsP
 	return items, nil
 }
 
+const reservedConnections = `-- name: ReservedConnections :one
+SELECT
+  current_setting('max_connections')::integer AS max_connections
+  , current_setting('superuser_reserved_connections')::integer AS superuser_reserved_connections
+  , COALESCE(current_setting('reserved_connections', true), '0')::integer AS reserved_connections
+  , (current_setting('server_version_num')::integer >= 160000) AS supports_reserved_connections
+  , COALESCE((
+    SELECT string_agg(r.rolname, ', ' ORDER BY r.rolname)
+    FROM pg_roles AS r
+    WHERE
+      r.rolcanlogin
+      AND NOT r.rolsuper
+      AND to_regrole('pg_use_reserved_connections') IS NOT NULL
+      AND pg_has_role(r.oid, to_regrole('pg_use_reserved_connections'), 'MEMBER')
+      AND NOT pg_has_role(r.oid, 'pg_monitor', 'MEMBER')
+  ), '')::text AS reserved_pool_roles
+`
+
+type ReservedConnectionsRow struct {
+	MaxConnections               int32
+	SuperuserReservedConnections int32
+	ReservedConnections          int32
+	SupportsReservedConnections  bool
+	ReservedPoolRoles            string
+}
+
+// Connection slots held back from ordinary roles, and the non-superuser login
+// roles outside pg_monitor that may use the PG16+ reserved_connections pool.
+func (q *Queries) ReservedConnections(ctx context.Context) (ReservedConnectionsRow, error) {
+	row := q.db.QueryRow(ctx, reservedConnections)
+	var i ReservedConnectionsRow
+	err := row.Scan(
+		&i.MaxConnections,
+		&i.SuperuserReservedConnections,
+		&i.ReservedConnections,
+		&i.SupportsReservedConnections,
+		&i.ReservedPoolRoles,
+	)
+	return i, err
+}
+
+const resetUnloggedTables = `-- name: ResetUnloggedTables :many
+SELECT
+  n.nspname::text AS schema_name
+  , c.relname::text AS table_name
+  , c.reltuples::bigint AS estimated_rows
+  , pg_relation_size(c.oid, 'init')::bigint AS init_fork_bytes
+FROM pg_class AS c
+INNER JOIN pg_namespace AS n ON c.relnamespace = n.oid
+WHERE
+  c.relpersistence = 'u'
+  AND c.relkind = 'r'
+  AND c.reltuples > 0
+  AND pg_relation_size(c.oid, 'main') = 0
+ORDER BY c.reltuples DESC
+`
+
+type ResetUnloggedTablesRow struct {
+	SchemaName    string
+	TableName     string
+	EstimatedRows int64
+	InitForkBytes int64
+}
+
+// Unlogged tables whose main fork is empty although the planner statistics
+// recorded rows: crash recovery resets unlogged tables to their init fork.
+func (q *Queries) ResetUnloggedTables(ctx context.Context) ([]ResetUnloggedTablesRow, error) {
+	rows, err := q.db.Query(ctx, resetUnloggedTables)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ResetUnloggedTablesRow
+	for rows.Next() {
+		var i ResetUnloggedTablesRow
+		if err := rows.Scan(
+			&i.SchemaName,
+			&i.TableName,
+			&i.EstimatedRows,
+			&i.InitForkBytes,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const roleConnections = `-- name: RoleConnections :many
+SELECT
+  r.rolname::text AS role_name
+  , r.rolconnlimit::integer AS connection_limit
+  , COUNT(a.pid)::bigint AS connections
+FROM pg_roles AS r
+LEFT JOIN pg_stat_activity AS a ON a.usesysid = r.oid AND a.backend_type = 'client backend'
+WHERE
+  r.rolcanlogin
+  AND NOT r.rolsuper
+GROUP BY r.rolname, r.rolconnlimit
+HAVING COUNT(a.pid) > 0
+ORDER BY connections DESC, role_name
+`
+
+type RoleConnectionsRow struct {
+	RoleName        string
+	ConnectionLimit int32
+	Connections     int64
+}
+
+// Current connections per non-superuser login role against its CONNECTION LIMIT
+// (-1 = unlimited). Superusers are not subject to role connection limits.
+func (q *Queries) RoleConnections(ctx context.Context) ([]RoleConnectionsRow, error) {
+	rows, err := q.db.Query(ctx, roleConnections)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []RoleConnectionsRow
+	for rows.Next() {
+		var i RoleConnectionsRow
+		if err := rows.Scan(&i.RoleName, &i.ConnectionLimit, &i.Connections); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const securitySettings = `-- name: SecuritySettings :one
+SELECT
+  current_setting('ssl')::text AS ssl
+  , COALESCE(current_setting('ssl_min_protocol_version', true), '')::text AS ssl_min_protocol_version
+  , current_setting('password_encryption')::text AS password_encryption
+  , current_setting('listen_addresses')::text AS listen_addresses
+  , current_setting('server_version_num')::integer AS server_version_num
+  , (
+    current_setting('is_superuser')::boolean
+    OR (
+      has_table_privilege('pg_catalog.pg_hba_file_rules', 'SELECT')
+      AND has_function_privilege('pg_catalog.pg_hba_file_rules()', 'EXECUTE')
+    )
+  )::boolean AS can_read_hba
+  , (current_setting('is_superuser')::boolean OR has_table_privilege('pg_catalog.pg_authid', 'SELECT'))::boolean AS can_read_authid
+`
+
+type SecuritySettingsRow struct {
+	Ssl                   string
+	SslMinProtocolVersion string
+	PasswordEncryption    string
+	ListenAddresses       string
+	ServerVersionNum      int32
+	CanReadHba            bool
+	CanReadAuthid         bool
+}
+
+// TLS and password settings, and whether the current role may read
+// pg_hba_file_rules and the password hashes in pg_authid (superuser, unless
+// granted explicitly).
+func (q *Queries) SecuritySettings(ctx context.Context) (SecuritySettingsRow, error) {
+	row := q.db.QueryRow(ctx, securitySettings)
+	var i SecuritySettingsRow
+	err := row.Scan(
+		&i.Ssl,
+		&i.SslMinProtocolVersion,
+		&i.PasswordEncryption,
+		&i.ListenAddresses,
+		&i.ServerVersionNum,
+		&i.CanReadHba,
+		&i.CanReadAuthid,
+	)
+	return i, err
+}
+
+const seqScanStatStatementsAvailable = `-- name: SeqScanStatStatementsAvailable :one
+SELECT EXISTS(
+  SELECT 1 FROM pg_extension
+  WHERE extname = 'pg_stat_statements'
+) AS available
+`
+
+// Checks if the pg_stat_statements extension is installed in this database.
+func (q *Queries) SeqScanStatStatementsAvailable(ctx context.Context) (bool, error) {
+	row := q.db.QueryRow(ctx, seqScanStatStatementsAvailable)
+	var available bool
+	err := row.Scan(&available)
+	return available, err
+}
+
 const sequenceHealth = `-- name: SequenceHealth :many
 WITH sequence_info AS (
   SELECT
@@ -1533,8 +5071,6 @@ SELECT
   , COALESCE(so.column_max_value, 0) AS column_max_value
   -- Flag if sequence can generate values that exceed column type
   , (so.column_max_value IS NOT NULL AND si.max_value > so.column_max_value) AS sequence_exceeds_column
-  -- Flag if this is an integer column that should probably be bigint
-  , (so.column_type != 'bigint' AND si.usage_percent > 50) AS should_be_bigint
   -- Flag if column is a primary key
   , (pk.table_oid IS NOT NULL) AS is_primary_key
   -- Count of foreign keys referencing this column
@@ -1571,7 +5107,6 @@ type SequenceHealthRow struct {
 	ColumnType            pgtype.Text
 	ColumnMaxValue        pgtype.Int8
 	SequenceExceedsColumn pgtype.Bool
-	ShouldBeBigint        pgtype.Bool
 	IsPrimaryKey          pgtype.Bool
 	FkReferenceCount      pgtype.Int8
 }
@@ -1604,7 +5139,6 @@ func (q *Queries) SequenceHealth(ctx context.Context) ([]SequenceHealthRow, erro
 			&i.ColumnType,
 			&i.ColumnMaxValue,
 			&i.SequenceExceedsColumn,
-			&i.ShouldBeBigint,
 			&i.IsPrimaryKey,
 			&i.FkReferenceCount,
 		); err != nil {
@@ -1797,6 +5331,168 @@ func (q *Queries) SessionStatistics(ctx context.Context) (SessionStatisticsRow,
 	return i, err
 }
 
+const standbyRecoverySettings = `-- name: StandbyRecoverySettings :one
+SELECT
+  PG_IS_IN_RECOVERY() AS in_recovery
+  , COALESCE((
+    SELECT setting::bigint FROM pg_settings WHERE name = 'recovery_min_apply_delay'
+  ), 0)::bigint AS apply_delay_ms
+  , current_setting('hot_standby_feedback')::boolean AS hot_standby_feedback
+  , (SELECT setting FROM pg_settings WHERE name = 'primary_conninfo')::text AS primary_conninfo
+  , (SELECT setting FROM pg_settings WHERE name = 'restore_command')::text AS restore_command
+  , COALESCE((
+    SELECT STRING_AGG(name || ' = ' || setting, ', ' ORDER BY name)
+    FROM pg_settings
+    WHERE
+      name IN (
+        'recovery_target', 'recovery_target_lsn', 'recovery_target_name'
+        , 'recovery_target_time', 'recovery_target_xid'
+      )
+      AND setting <> ''
+  ), '')::text AS recovery_targets
+`
+
+type StandbyRecoverySettingsRow struct {
+	InRecovery         bool
+	ApplyDelayMs       int64
+	HotStandbyFeedback bool
+	PrimaryConninfo    pgtype.Text
+	RestoreCommand     pgtype.Text
+	RecoveryTargets    string
+}
+
+// Recovery settings of a standby. primary_conninfo and restore_command are
+// hidden from pg_settings unless the role is a superuser or member of
+// pg_read_all_settings, so they are NULL for other roles.
+func (q *Queries) StandbyRecoverySettings(ctx context.Context) (StandbyRecoverySettingsRow, error) {
+	row := q.db.QueryRow(ctx, standbyRecoverySettings)
+	var i StandbyRecoverySettingsRow
+	err := row.Scan(
+		&i.InRecovery,
+		&i.ApplyDelayMs,
+		&i.HotStandbyFeedback,
+		&i.PrimaryConninfo,
+		&i.RestoreCommand,
+		&i.RecoveryTargets,
+	)
+	return i, err
+}
+
+const statMonitorNormalized = `-- name: StatMonitorNormalized :one
+SELECT COALESCE(current_setting('pg_stat_monitor.pgsm_normalized_query', true), '') = 'on' AS normalized
+`
+
+// Checks whether pg_stat_monitor records normalized query text ($1, $2, ...)
+// rather than the literal values of each call.
+func (q *Queries) StatMonitorNormalized(ctx context.Context) (bool, error) {
+	row := q.db.QueryRow(ctx, statMonitorNormalized)
+	var normalized bool
+	err := row.Scan(&normalized)
+	return normalized, err
+}
+
+const statStatementsAvailable = `-- name: StatStatementsAvailable :one
+SELECT EXISTS(
+  SELECT 1 FROM pg_extension
+  WHERE extname = 'pg_stat_statements'
+) AS available
+`
+
+// Checks if the pg_stat_statements extension is installed in this database.
+func (q *Queries) StatStatementsAvailable(ctx context.Context) (bool, error) {
+	row := q.db.QueryRow(ctx, statStatementsAvailable)
+	var available bool
+	err := row.Scan(&available)
+	return available, err
+}
+
+const statStatementsDeallocations = `-- name: StatStatementsDeallocations :one
+SELECT
+  dealloc::bigint AS deallocations
+  , COALESCE(EXTRACT(EPOCH FROM NOW() - stats_reset), 0)::bigint AS reset_age_seconds
+  , COALESCE(stats_reset::text, '')::text AS stats_reset
+FROM pg_stat_statements_info
+`
+
+type StatStatementsDeallocationsRow struct {
+	Deallocations   int64
+	ResetAgeSeconds int64
+	StatsReset      string
+}
+
+// Times pg_stat_statements evicted its least-used entries to make room for
+// new statements, since its statistics were last reset (PostgreSQL 14+).
+func (q *Queries) StatStatementsDeallocations(ctx context.Context) (StatStatementsDeallocationsRow, error) {
+	row := q.db.QueryRow(ctx, statStatementsDeallocations)
+	var i StatStatementsDeallocationsRow
+	err := row.Scan(&i.Deallocations, &i.ResetAgeSeconds, &i.StatsReset)
+	return i, err
+}
+
+const statStatementsSettings = `-- name: StatStatementsSettings :one
+SELECT
+  EXISTS(
+    SELECT 1 FROM pg_extension
+    WHERE extname = 'pg_stat_statements'
+  ) AS installed
+  , (CURRENT_SETTING('pg_stat_statements.max', true) IS NOT NULL) AS loaded
+  , COALESCE(CURRENT_SETTING('pg_stat_statements.max', true), '0')::bigint AS max_entries
+  , COALESCE(CURRENT_SETTING('pg_stat_statements.track', true), '')::text AS track
+  , COALESCE(CURRENT_SETTING('pg_stat_statements.save', true), '')::text AS save
+  , COALESCE(CURRENT_SETTING('compute_query_id', true), '')::text AS compute_query_id
+  , (TO_REGCLASS('pg_stat_statements_info') IS NOT NULL) AS has_info
+`
+
+type StatStatementsSettingsRow struct {
+	Installed      bool
+	Loaded         bool
+	MaxEntries     int64
+	Track          string
+	Save           string
+	ComputeQueryID string
+	HasInfo        bool
+}
+
+// Whether pg_stat_statements is installed in this database and loaded into
+// the server, and the settings deciding what it records. Settings read as
+// empty when the library is not loaded. has_info is false before
+// PostgreSQL 14 (extension 1.9), which lacks pg_stat_statements_info.
+func (q *Queries) StatStatementsSettings(ctx context.Context) (StatStatementsSettingsRow, error) {
+	row := q.db.QueryRow(ctx, statStatementsSettings)
+	var i StatStatementsSettingsRow
+	err := row.Scan(
+		&i.Installed,
+		&i.Loaded,
+		&i.MaxEntries,
+		&i.Track,
+		&i.Save,
+		&i.ComputeQueryID,
+		&i.HasInfo,
+	)
+	return i, err
+}
+
+const statStatementsUsage = `-- name: StatStatementsUsage :one
+SELECT
+  COUNT(*)::bigint AS entries
+  , COALESCE(SUM(OCTET_LENGTH(query)), 0)::bigint AS query_text_bytes
+FROM pg_stat_statements
+`
+
+type StatStatementsUsageRow struct {
+	Entries        int64
+	QueryTextBytes int64
+}
+
+// Entries in pg_stat_statements and the size of their query texts, which
+// live in an external file read in full on every scan of the view.
+func (q *Queries) StatStatementsUsage(ctx context.Context) (StatStatementsUsageRow, error) {
+	row := q.db.QueryRow(ctx, statStatementsUsage)
+	var i StatStatementsUsageRow
+	err := row.Scan(&i.Entries, &i.QueryTextBytes)
+	return i, err
+}
+
 const statisticsFreshness = `-- name: StatisticsFreshness :one
 SELECT
   stats_reset
@@ -1824,19 +5520,174 @@ func (q *Queries) StatisticsFreshness(ctx context.Context) (StatisticsFreshnessR
 	return i, err
 }
 
+const statsResetTimes = `-- name: StatsResetTimes :one
+SELECT
+  COALESCE(d.stats_reset::text, '')::text AS database_stats_reset
+  , COALESCE(EXTRACT(EPOCH FROM NOW() - d.stats_reset), -1)::bigint AS database_reset_age_seconds
+  , COALESCE(b.stats_reset::text, '')::text AS bgwriter_stats_reset
+  , COALESCE(EXTRACT(EPOCH FROM NOW() - b.stats_reset), -1)::bigint AS bgwriter_reset_age_seconds
+FROM pg_stat_database AS d
+CROSS JOIN pg_stat_bgwriter AS b
+WHERE d.datname = CURRENT_DATABASE()
+`
+
+type StatsResetTimesRow struct {
+	DatabaseStatsReset      string
+	DatabaseResetAgeSeconds int64
+	BgwriterStatsReset      string
+	BgwriterResetAgeSeconds int64
+}
+
+// When the cumulative statistics behind usage-based checks were last reset:
+// per-database counters (pg_stat_database) and the background writer and
+// checkpoint counters (pg_stat_bgwriter). Ages are -1 when never reset.
+func (q *Queries) StatsResetTimes(ctx context.Context) (StatsResetTimesRow, error) {
+	row := q.db.QueryRow(ctx, statsResetTimes)
+	var i StatsResetTimesRow
+	err := row.Scan(
+		&i.DatabaseStatsReset,
+		&i.DatabaseResetAgeSeconds,
+		&i.BgwriterStatsReset,
+		&i.BgwriterResetAgeSeconds,
+	)
+	return i, err
+}
+
+const synchronousCommitOverrides = `-- name: SynchronousCommitOverrides :many
+SELECT
+  COALESCE(d.datname, '')::text AS database_name
+  , COALESCE(r.rolname, '')::text AS role_name
+  , SPLIT_PART(cfg.setting, '=', 2)::text AS value
+FROM pg_db_role_setting AS s
+CROSS JOIN LATERAL UNNEST(s.setconfig) AS cfg (setting)
+LEFT JOIN pg_database AS d ON s.setdatabase = d.oid
+LEFT JOIN pg_roles AS r ON s.setrole = r.oid
+WHERE cfg.setting LIKE 'synchronous_commit=%'
+ORDER BY database_name, role_name
+`
+
+type SynchronousCommitOverridesRow struct {
+	DatabaseName string
+	RoleName     string
+	Value        string
+}
+
+// Per-database and per-role synchronous_commit overrides (ALTER DATABASE/ROLE ... SET).
+func (q *Queries) SynchronousCommitOverrides(ctx context.Context) ([]SynchronousCommitOverridesRow, error) {
+	rows, err := q.db.Query(ctx, synchronousCommitOverrides)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SynchronousCommitOverridesRow
+	for rows.Next() {
+		var i SynchronousCommitOverridesRow
+		if err := rows.Scan(&i.DatabaseName, &i.RoleName, &i.Value); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const synchronousStandbyConfig = `-- name: SynchronousStandbyConfig :one
+SELECT
+  current_setting('synchronous_standby_names')::text AS synchronous_standby_names
+  -- reset_val includes ALTER DATABASE/ROLE settings for this session, so it is
+  -- only the cluster default when the server configuration set it.
+  , (
+    SELECT CASE
+      WHEN source IN ('database', 'user', 'database user', 'client', 'session') THEN boot_val
+      ELSE reset_val
+    END
+    FROM pg_settings
+    WHERE name = 'synchronous_commit'
+  )::text AS synchronous_commit
+  , pg_is_in_recovery()::boolean AS in_recovery
+`
+
+type SynchronousStandbyConfigRow struct {
+	SynchronousStandbyNames string
+	SynchronousCommit       string
+	InRecovery              bool
+}
+
+// Configured synchronous standbys, the cluster-wide synchronous_commit default,
+// and whether this server is a standby, where neither applies.
+func (q *Queries) SynchronousStandbyConfig(ctx context.Context) (SynchronousStandbyConfigRow, error) {
+	row := q.db.QueryRow(ctx, synchronousStandbyConfig)
+	var i SynchronousStandbyConfigRow
+	err := row.Scan(&i.SynchronousStandbyNames, &i.SynchronousCommit, &i.InRecovery)
+	return i, err
+}
+
+const tLSSettings = `-- name: TLSSettings :one
+SELECT
+  CURRENT_SETTING('ssl')::bool AS ssl_enabled
+  , CURRENT_SETTING('ssl_cert_file')::text AS cert_file
+  , CURRENT_SETTING('ssl_ca_file')::text AS ca_file
+  , COALESCE((SELECT setting FROM pg_settings WHERE name = 'primary_conninfo'), '')::text AS primary_conninfo
+  , PG_IS_IN_RECOVERY() AS in_recovery
+  , PG_HAS_ROLE(CURRENT_USER, 'pg_read_server_files', 'USAGE') AS can_read_files
+`
+
+type TLSSettingsRow struct {
+	SslEnabled      bool
+	CertFile        string
+	CaFile          string
+	PrimaryConninfo string
+	InRecovery      bool
+	CanReadFiles    bool
+}
+
+// Reads the certificate file settings and whether this role may read the files.
+// primary_conninfo is only visible to roles with pg_read_all_settings.
+func (q *Queries) TLSSettings(ctx context.Context) (TLSSettingsRow, error) {
+	row := q.db.QueryRow(ctx, tLSSettings)
+	var i TLSSettingsRow
+	err := row.Scan(
+		&i.SslEnabled,
+		&i.CertFile,
+		&i.CaFile,
+		&i.PrimaryConninfo,
+		&i.InRecovery,
+		&i.CanReadFiles,
+	)
+	return i, err
+}
+
 const tableActivity = `-- name: TableActivity :many
 SELECT
-  schemaname
-  , relname
-  , n_tup_ins
-  , n_tup_upd
-  , n_tup_del
-  , n_tup_hot_upd
-  , n_live_tup
-  , pg_table_size(relid) AS table_size_bytes
-FROM pg_stat_user_tables
-WHERE n_tup_ins + n_tup_upd + n_tup_del > 0
-ORDER BY n_tup_ins + n_tup_upd + n_tup_del DESC
+  s.schemaname
+  , s.relname
+  , s.n_tup_ins
+  , s.n_tup_upd
+  , s.n_tup_del
+  , s.n_tup_hot_upd
+  , s.n_live_tup
+  , pg_table_size(s.relid) AS table_size_bytes
+  , COALESCE(SUBSTRING(ARRAY_TO_STRING(c.reloptions, ',') FROM 'fillfactor=([0-9]+)')::integer, 100)::integer AS fillfactor
+  , (SELECT COUNT(*) FROM pg_index AS x WHERE x.indrelid = s.relid)::bigint AS index_count
+  -- Indexes never scanned that back no constraint: each one still has to be
+  -- updated whenever an update of its columns cannot be HOT
+  , ARRAY(
+    SELECT ui.indexrelname::text
+    FROM pg_stat_user_indexes AS ui
+    INNER JOIN pg_index AS x ON ui.indexrelid = x.indexrelid
+    WHERE
+      ui.relid = s.relid
+      AND ui.idx_scan = 0
+      AND NOT x.indisunique
+      AND NOT EXISTS (SELECT 1 FROM pg_constraint AS con WHERE con.conindid = ui.indexrelid)
+    ORDER BY pg_relation_size(ui.indexrelid) DESC
+  )::text [] AS unused_indexes
+FROM pg_stat_user_tables AS s
+INNER JOIN pg_class AS c ON s.relid = c.oid
+WHERE s.n_tup_ins + s.n_tup_upd + s.n_tup_del > 0
+ORDER BY s.n_tup_ins + s.n_tup_upd + s.n_tup_del DESC
 `
 
 type TableActivityRow struct {
@@ -1848,10 +5699,14 @@ type TableActivityRow struct {
 	NTupHotUpd     pgtype.Int8
 	NLiveTup       pgtype.Int8
 	TableSizeBytes pgtype.Int8
+	Fillfactor     int32
+	IndexCount     int64
+	UnusedIndexes  []string
 }
 
 // Retrieves table write activity metrics from pg_stat_user_tables
 // Used to identify high-churn tables and HOT update efficiency issues
+// Fillfactor and unused indexes explain why updates are not HOT
 func (q *Queries) TableActivity(ctx context.Context) ([]TableActivityRow, error) {
 	rows, err := q.db.Query(ctx, tableActivity)
 	if err != nil {
@@ -1870,6 +5725,9 @@ func (q *Queries) TableActivity(ctx context.Context) ([]TableActivityRow, error)
 			&i.NTupHotUpd,
 			&i.NLiveTup,
 			&i.TableSizeBytes,
+			&i.Fillfactor,
+			&i.IndexCount,
+			&i.UnusedIndexes,
 		); err != nil {
 			return nil, err
 		}
@@ -1884,6 +5742,8 @@ func (q *Queries) TableActivity(ctx context.Context) ([]TableActivityRow, error)
 const tableBloat = `-- name: TableBloat :many
 SELECT
   (schemaname || '.' || relname)::text AS table_name
+  , schemaname::text AS schema_name
+  , relname::text AS relname
   , n_live_tup AS live_tuples
   , n_dead_tup AS dead_tuples
   , last_autovacuum
@@ -1898,7 +5758,7 @@ SELECT
       THEN ROUND((n_dead_tup::numeric / (n_live_tup + n_dead_tup)::numeric) * 100, 2)
     ELSE 0
   END AS dead_tuple_percent
-  , PG_TOTAL_RELATION_SIZE(schemaname || '.' || relname) AS total_size_bytes
+  , PG_TOTAL_RELATION_SIZE(relid) AS total_size_bytes
 FROM pg_stat_user_tables
 WHERE
   schemaname NOT IN ('pg_catalog', 'information_schema')
@@ -1908,6 +5768,8 @@ ORDER BY dead_tuple_percent DESC, n_dead_tup DESC
 
 type TableBloatRow struct {
 	TableName                 pgtype.Text
+	SchemaName                pgtype.Text
+	Relname                   pgtype.Text
 	LiveTuples                pgtype.Int8
 	DeadTuples                pgtype.Int8
 	LastAutovacuum            pgtype.Timestamptz
@@ -1933,6 +5795,8 @@ func (q *Queries) TableBloat(ctx context.Context) ([]TableBloatRow, error) {
 		var i TableBloatRow
 		if err := rows.Scan(
 			&i.TableName,
+			&i.SchemaName,
+			&i.Relname,
 			&i.LiveTuples,
 			&i.DeadTuples,
 			&i.LastAutovacuum,
@@ -1955,57 +5819,365 @@ func (q *Queries) TableBloat(ctx context.Context) ([]TableBloatRow, error) {
 	return items, nil
 }
 
-const tableFreezeAge = `-- name: TableFreezeAge :many
+const tableFreezeAge = `-- name: TableFreezeAge :many
+SELECT
+  (n.nspname || '.' || c.relname)::text AS table_name
+  , c.relfrozenxid::text AS frozen_xid
+  , s.last_autovacuum
+  , s.last_vacuum
+  , s.autovacuum_count
+  , s.vacuum_count
+  , age(c.relfrozenxid) AS freeze_age
+  , pg_total_relation_size(c.oid) AS table_size_bytes
+FROM pg_class AS c
+INNER JOIN pg_namespace AS n ON c.relnamespace = n.oid
+LEFT JOIN pg_stat_user_tables AS s ON c.oid = s.relid
+WHERE
+  c.relkind = 'r'
+  AND n.nspname = $1::text
+  AND c.relfrozenxid != '0'
+ORDER BY age(c.relfrozenxid) DESC
+LIMIT 50
+`
+
+type TableFreezeAgeRow struct {
+	TableName       pgtype.Text
+	FrozenXid       pgtype.Text
+	LastAutovacuum  pgtype.Timestamptz
+	LastVacuum      pgtype.Timestamptz
+	AutovacuumCount pgtype.Int8
+	VacuumCount     pgtype.Int8
+	FreezeAge       pgtype.Int4
+	TableSizeBytes  pgtype.Int8
+}
+
+// Gets transaction ID age for tables with oldest frozen XIDs.
+func (q *Queries) TableFreezeAge(ctx context.Context, schemaName string) ([]TableFreezeAgeRow, error) {
+	rows, err := q.db.Query(ctx, tableFreezeAge, schemaName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []TableFreezeAgeRow
+	for rows.Next() {
+		var i TableFreezeAgeRow
+		if err := rows.Scan(
+			&i.TableName,
+			&i.FrozenXid,
+			&i.LastAutovacuum,
+			&i.LastVacuum,
+			&i.AutovacuumCount,
+			&i.VacuumCount,
+			&i.FreezeAge,
+			&i.TableSizeBytes,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const tableSpaceUsage = `-- name: TableSpaceUsage :one
+SELECT
+  table_len
+  , dead_tuple_len
+  , dead_tuple_percent
+  , approx_free_space AS free_space
+  , approx_free_percent AS free_percent
+FROM PGSTATTUPLE_APPROX(FORMAT('%I.%I', $1::text, $2::text)::regclass)
+`
+
+type TableSpaceUsageParams struct {
+	SchemaName string
+	TableName  string
+}
+
+type TableSpaceUsageRow struct {
+	TableLen         int64
+	DeadTupleLen     int64
+	DeadTuplePercent float64
+	FreeSpace        int64
+	FreePercent      float64
+}
+
+// Measures a table's dead tuples and free space with pgstattuple_approx, which
+// reads every heap page not marked all-visible.
+func (q *Queries) TableSpaceUsage(ctx context.Context, arg TableSpaceUsageParams) (TableSpaceUsageRow, error) {
+	row := q.db.QueryRow(ctx, tableSpaceUsage, arg.SchemaName, arg.TableName)
+	var i TableSpaceUsageRow
+	err := row.Scan(
+		&i.TableLen,
+		&i.DeadTupleLen,
+		&i.DeadTuplePercent,
+		&i.FreeSpace,
+		&i.FreePercent,
+	)
+	return i, err
+}
+
+const tableVacuumHealth = `-- name: TableVacuumHealth :many
+SELECT
+  (n.nspname || '.' || c.relname)::text AS table_name
+  , n.nspname::text AS schema_name
+  , c.relname::text AS relname
+  , s.last_autovacuum
+  , COALESCE(s.n_live_tup, c.reltuples::bigint) AS estimated_rows
+  , PG_TOTAL_RELATION_SIZE(c.oid) AS table_size_bytes
+  , COALESCE(s.n_dead_tup, 0) AS n_dead_tup
+  , COALESCE(s.autovacuum_count, 0) AS autovacuum_count
+  , ARRAY_TO_STRING(c.reloptions, ',') AS reloptions
+  , GREATEST(s.last_vacuum, s.last_autovacuum) AS last_vacuum_any
+  , GREATEST(s.last_analyze, s.last_autoanalyze) AS last_analyze_any
+  -- Stats staleness indicators
+  , COALESCE(s.n_mod_since_analyze, 0) AS n_mod_since_analyze
+  , COALESCE(s.autoanalyze_count, 0) AS autoanalyze_count
+  -- PG14+ columns for insert tracking (will be 0 on older versions via COALESCE)
+  , COALESCE(s.n_ins_since_vacuum, 0) AS n_ins_since_vacuum
+  -- Write counters, for the table's write pattern
+  , COALESCE(s.n_tup_ins, 0) AS n_tup_ins
+  , COALESCE(s.n_tup_upd, 0) AS n_tup_upd
+  , COALESCE(s.n_tup_del, 0) AS n_tup_del
+FROM pg_class AS c
+INNER JOIN pg_namespace AS n ON c.relnamespace = n.oid
+LEFT JOIN pg_stat_user_tables AS s ON c.oid = s.relid
+WHERE
+  c.relkind IN ('r', 'p')
+  AND n.nspname = $1::text
+ORDER BY COALESCE(s.n_live_tup, c.reltuples::bigint) DESC
+`
+
+type TableVacuumHealthRow struct {
+	TableName        pgtype.Text
+	SchemaName       pgtype.Text
+	Relname          pgtype.Text
+	LastAutovacuum   pgtype.Timestamptz
+	EstimatedRows    pgtype.Int8
+	TableSizeBytes   pgtype.Int8
+	NDeadTup         pgtype.Int8
+	AutovacuumCount  pgtype.Int8
+	Reloptions       pgtype.Text
+	LastVacuumAny    pgtype.Timestamptz
+	LastAnalyzeAny   pgtype.Timestamptz
+	NModSinceAnalyze pgtype.Int8
+	AutoanalyzeCount pgtype.Int8
+	NInsSinceVacuum  pgtype.Int8
+	NTupIns          pgtype.Int8
+	NTupUpd          pgtype.Int8
+	NTupDel          pgtype.Int8
+}
+
+// Returns all tables with vacuum-related health metrics.
+// Used by multiple subchecks: autovacuum-disabled, large-table-defaults, vacuum-stale, analyze-needed.
+func (q *Queries) TableVacuumHealth(ctx context.Context, schemaName string) ([]TableVacuumHealthRow, error) {
+	rows, err := q.db.Query(ctx, tableVacuumHealth, schemaName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []TableVacuumHealthRow
+	for rows.Next() {
+		var i TableVacuumHealthRow
+		if err := rows.Scan(
+			&i.TableName,
+			&i.SchemaName,
+			&i.Relname,
+			&i.LastAutovacuum,
+			&i.EstimatedRows,
+			&i.TableSizeBytes,
+			&i.NDeadTup,
+			&i.AutovacuumCount,
+			&i.Reloptions,
+			&i.LastVacuumAny,
+			&i.LastAnalyzeAny,
+			&i.NModSinceAnalyze,
+			&i.AutoanalyzeCount,
+			&i.NInsSinceVacuum,
+			&i.NTupIns,
+			&i.NTupUpd,
+			&i.NTupDel,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const tableVacuumServerVersion = `-- name: TableVacuumServerVersion :one
+SELECT CURRENT_SETTING('server_version_num')::integer AS server_version_num
+`
+
+// Server version, to decide whether pg_stat_user_tables has vacuum timing.
+func (q *Queries) TableVacuumServerVersion(ctx context.Context) (int32, error) {
+	row := q.db.QueryRow(ctx, tableVacuumServerVersion)
+	var server_version_num int32
+	err := row.Scan(&server_version_num)
+	return server_version_num, err
+}
+
+const tableVacuumTiming = `-- name: TableVacuumTiming :many
+SELECT
+  (s.schemaname || '.' || s.relname)::text AS table_name
+  , s.schemaname::text AS schema_name
+  , s.relname::text AS relname
+  , PG_TOTAL_RELATION_SIZE(s.relid)::bigint AS table_size_bytes
+  , s.autovacuum_count::bigint AS autovacuum_count
+  , s.total_autovacuum_time::double precision AS total_autovacuum_time_ms
+  , s.autoanalyze_count::bigint AS autoanalyze_count
+  , s.total_autoanalyze_time::double precision AS total_autoanalyze_time_ms
+  , s.last_autovacuum
+FROM pg_stat_user_tables AS s
+WHERE
+  s.schemaname = $1::text
+  AND s.autovacuum_count > 0
+ORDER BY s.total_autovacuum_time / s.autovacuum_count DESC
+LIMIT 20
+`
+
+type TableVacuumTimingRow struct {
+	TableName              string
+	SchemaName             string
+	Relname                string
+	TableSizeBytes         int64
+	AutovacuumCount        int64
+	TotalAutovacuumTimeMs  float64
+	AutoanalyzeCount       int64
+	TotalAutoanalyzeTimeMs float64
+	LastAutovacuum         pgtype.Timestamptz
+}
+
+// Tables whose autovacuum runs take longest on average, from the cumulative
+// vacuum and analyze times added to pg_stat_user_tables in PostgreSQL 18.
+func (q *Queries) TableVacuumTiming(ctx context.Context, schemaName string) ([]TableVacuumTimingRow, error) {
+	rows, err := q.db.Query(ctx, tableVacuumTiming, schemaName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []TableVacuumTimingRow
+	for rows.Next() {
+		var i TableVacuumTimingRow
+		if err := rows.Scan(
+			&i.TableName,
+			&i.SchemaName,
+			&i.Relname,
+			&i.TableSizeBytes,
+			&i.AutovacuumCount,
+			&i.TotalAutovacuumTimeMs,
+			&i.AutoanalyzeCount,
+			&i.TotalAutoanalyzeTimeMs,
+			&i.LastAutovacuum,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const tablespaceRelations = `-- name: TablespaceRelations :many
+WITH stats_window AS (
+  SELECT
+    EXTRACT(EPOCH FROM NOW() - COALESCE(sd.stats_reset, PG_POSTMASTER_START_TIME()))::float8 AS age_seconds
+  FROM pg_stat_database AS sd
+  WHERE sd.datname = CURRENT_DATABASE()
+)
+
+, default_tablespace AS (
+  SELECT d.dattablespace AS oid
+  FROM pg_database AS d
+  WHERE d.datname = CURRENT_DATABASE()
+)
+
+, relations AS (
+  SELECT
+    st.relid AS oid
+    , 'table' AS relation_kind
+    , st.seq_scan + COALESCE(st.idx_scan, 0) AS scans
+    , COALESCE(sio.heap_blks_read, 0) + COALESCE(sio.toast_blks_read, 0) AS blocks_read
+    , st.n_tup_ins + st.n_tup_upd + st.n_tup_del AS writes
+  FROM pg_stat_user_tables AS st
+  LEFT JOIN pg_statio_user_tables AS sio ON st.relid = sio.relid
+  UNION ALL
+  -- Inserts and non-HOT updates add an entry to every index on the table.
+  SELECT
+    si.indexrelid AS oid
+    , 'index' AS relation_kind
+    , si.idx_scan AS scans
+    , COALESCE(sio.idx_blks_read, 0) AS blocks_read
+    , COALESCE(st.n_tup_ins + st.n_tup_upd - st.n_tup_hot_upd, 0) AS writes
+  FROM pg_stat_user_indexes AS si
+  LEFT JOIN pg_statio_user_indexes AS sio ON si.indexrelid = sio.indexrelid
+  LEFT JOIN pg_stat_user_tables AS st ON si.relid = st.relid
+)
+
 SELECT
-  (n.nspname || '.' || c.relname)::text AS table_name
-  , c.relfrozenxid::text AS frozen_xid
-  , s.last_autovacuum
-  , s.last_vacuum
-  , s.autovacuum_count
-  , s.vacuum_count
-  , age(c.relfrozenxid) AS freeze_age
-  , pg_total_relation_size(c.oid) AS table_size_bytes
-FROM pg_class AS c
+  (n.nspname || '.' || c.relname)::text AS relation_name
+  , n.nspname::text AS schema_name
+  , c.relname::text AS relname
+  , r.relation_kind::text AS relation_kind
+  , ts.spcname::text AS tablespace_name
+  , PG_RELATION_SIZE(c.oid)::bigint AS size_bytes
+  , r.scans::bigint AS scans
+  , r.blocks_read::bigint AS blocks_read
+  , r.writes::bigint AS writes
+  , COALESCE(sw.age_seconds, 0)::float8 AS stats_age_seconds
+FROM relations AS r
+INNER JOIN pg_class AS c ON r.oid = c.oid
 INNER JOIN pg_namespace AS n ON c.relnamespace = n.oid
-LEFT JOIN pg_stat_user_tables AS s ON c.oid = s.relid
-WHERE
-  c.relkind = 'r'
-  AND n.nspname = 'public'
-  AND c.relfrozenxid != '0'
-ORDER BY age(c.relfrozenxid) DESC
-LIMIT 50
+CROSS JOIN default_tablespace AS dt
+INNER JOIN pg_tablespace AS ts ON COALESCE(NULLIF(c.reltablespace, 0), dt.oid) = ts.oid
+LEFT JOIN stats_window AS sw ON TRUE
+WHERE c.relkind IN ('r', 'm', 'i')
+ORDER BY size_bytes DESC
 `
 
-type TableFreezeAgeRow struct {
-	TableName       pgtype.Text
-	FrozenXid       pgtype.Text
-	LastAutovacuum  pgtype.Timestamptz
-	LastVacuum      pgtype.Timestamptz
-	AutovacuumCount pgtype.Int8
-	VacuumCount     pgtype.Int8
-	FreezeAge       pgtype.Int4
-	TableSizeBytes  pgtype.Int8
+type TablespaceRelationsRow struct {
+	RelationName    string
+	SchemaName      string
+	Relname         string
+	RelationKind    string
+	TablespaceName  string
+	SizeBytes       int64
+	Scans           int64
+	BlocksRead      int64
+	Writes          int64
+	StatsAgeSeconds float64
 }
 
-// Gets transaction ID age for tables with oldest frozen XIDs.
-func (q *Queries) TableFreezeAge(ctx context.Context) ([]TableFreezeAgeRow, error) {
-	rows, err := q.db.Query(ctx, tableFreezeAge)
+// User tables and indexes with their tablespace, size, and activity since the
+// statistics reset. Relations in the database's default tablespace report its name.
+// Returns data for subchecks: tablespace-layout, hot-on-slow, cold-on-fast.
+func (q *Queries) TablespaceRelations(ctx context.Context) ([]TablespaceRelationsRow, error) {
+	rows, err := q.db.Query(ctx, tablespaceRelations)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []TableFreezeAgeRow
+	var items []TablespaceRelationsRow
 	for rows.Next() {
-		var i TableFreezeAgeRow
+		var i TablespaceRelationsRow
 		if err := rows.Scan(
-			&i.TableName,
-			&i.FrozenXid,
-			&i.LastAutovacuum,
-			&i.LastVacuum,
-			&i.AutovacuumCount,
-			&i.VacuumCount,
-			&i.FreezeAge,
-			&i.TableSizeBytes,
+			&i.RelationName,
+			&i.SchemaName,
+			&i.Relname,
+			&i.RelationKind,
+			&i.TablespaceName,
+			&i.SizeBytes,
+			&i.Scans,
+			&i.BlocksRead,
+			&i.Writes,
+			&i.StatsAgeSeconds,
 		); err != nil {
 			return nil, err
 		}
@@ -2017,70 +6189,86 @@ func (q *Queries) TableFreezeAge(ctx context.Context) ([]TableFreezeAgeRow, erro
 	return items, nil
 }
 
-const tableVacuumHealth = `-- name: TableVacuumHealth :many
+const tempFileAccess = `-- name: TempFileAccess :one
 SELECT
-  (n.nspname || '.' || c.relname)::text AS table_name
-  , s.last_autovacuum
-  , COALESCE(s.n_live_tup, c.reltuples::bigint) AS estimated_rows
-  , PG_TOTAL_RELATION_SIZE(c.oid) AS table_size_bytes
-  , COALESCE(s.n_dead_tup, 0) AS n_dead_tup
-  , COALESCE(s.autovacuum_count, 0) AS autovacuum_count
-  , ARRAY_TO_STRING(c.reloptions, ',') AS reloptions
-  , GREATEST(s.last_vacuum, s.last_autovacuum) AS last_vacuum_any
-  , GREATEST(s.last_analyze, s.last_autoanalyze) AS last_analyze_any
-  -- Stats staleness indicators
-  , COALESCE(s.n_mod_since_analyze, 0) AS n_mod_since_analyze
-  , COALESCE(s.autoanalyze_count, 0) AS autoanalyze_count
-  -- PG14+ columns for insert tracking (will be 0 on older versions via COALESCE)
-  , COALESCE(s.n_ins_since_vacuum, 0) AS n_ins_since_vacuum
-FROM pg_class AS c
-INNER JOIN pg_namespace AS n ON c.relnamespace = n.oid
-LEFT JOIN pg_stat_user_tables AS s ON c.oid = s.relid
+  (current_setting('is_superuser')::boolean OR pg_has_role('pg_monitor', 'MEMBER'))::boolean AS can_list_tmp_dir
+  , COALESCE(current_setting('remove_temp_files_after_crash', true), 'on')::text AS remove_temp_files_after_crash
+`
+
+type TempFileAccessRow struct {
+	CanListTmpDir             bool
+	RemoveTempFilesAfterCrash string
+}
+
+// Whether pg_ls_tmpdir() can be called (superuser or pg_monitor) and whether
+// the server removes temporary files after a backend crash (PostgreSQL 14+).
+func (q *Queries) TempFileAccess(ctx context.Context) (TempFileAccessRow, error) {
+	row := q.db.QueryRow(ctx, tempFileAccess)
+	var i TempFileAccessRow
+	err := row.Scan(&i.CanListTmpDir, &i.RemoveTempFilesAfterCrash)
+	return i, err
+}
+
+const tempSchemas = `-- name: TempSchemas :many
+WITH live_backends AS (
+  SELECT backend_id
+  FROM pg_stat_get_backend_idset() AS backend_id
+  WHERE pg_stat_get_backend_dbid(backend_id) = (
+    SELECT oid FROM pg_database
+    WHERE datname = CURRENT_DATABASE()
+  )
+)
+
+SELECT
+  n.nspname::text AS schema_name
+  , COUNT(c.oid)::bigint AS table_count
+  , COALESCE(SUM(pg_total_relation_size(c.oid)), 0)::bigint AS total_bytes
+  , COALESCE(MAX(AGE(c.relfrozenxid)), 0)::bigint AS max_xid_age
+  , EXISTS (
+    SELECT 1 FROM live_backends AS lb
+    WHERE lb.backend_id = SUBSTRING(n.nspname FROM 'pg_temp_(\d+)')::integer
+  ) AS has_backend
+  -- Before PostgreSQL 16, pg_stat_get_backend_idset() returned positions, not backend IDs
+  , (current_setting('server_version_num')::integer >= 160000)::boolean AS backend_ids_reliable
+  , current_setting('autovacuum_freeze_max_age')::bigint AS autovacuum_freeze_max_age
+FROM pg_namespace AS n
+INNER JOIN pg_class AS c ON n.oid = c.relnamespace
 WHERE
-  c.relkind IN ('r', 'p')
-  AND n.nspname = 'public'
-ORDER BY COALESCE(s.n_live_tup, c.reltuples::bigint) DESC
+  n.nspname ~ '^pg_temp_\d+$'
+  AND c.relkind = 'r'
+GROUP BY n.nspname
+ORDER BY max_xid_age DESC
 `
 
-type TableVacuumHealthRow struct {
-	TableName        pgtype.Text
-	LastAutovacuum   pgtype.Timestamptz
-	EstimatedRows    pgtype.Int8
-	TableSizeBytes   pgtype.Int8
-	NDeadTup         pgtype.Int8
-	AutovacuumCount  pgtype.Int8
-	Reloptions       pgtype.Text
-	LastVacuumAny    pgtype.Timestamptz
-	LastAnalyzeAny   pgtype.Timestamptz
-	NModSinceAnalyze pgtype.Int8
-	AutoanalyzeCount pgtype.Int8
-	NInsSinceVacuum  pgtype.Int8
+type TempSchemasRow struct {
+	SchemaName             string
+	TableCount             int64
+	TotalBytes             int64
+	MaxXidAge              int64
+	HasBackend             bool
+	BackendIdsReliable     bool
+	AutovacuumFreezeMaxAge int64
 }
 
-// Returns all tables with vacuum-related health metrics.
-// Used by multiple subchecks: autovacuum-disabled, large-table-defaults, vacuum-stale, analyze-needed.
-func (q *Queries) TableVacuumHealth(ctx context.Context) ([]TableVacuumHealthRow, error) {
-	rows, err := q.db.Query(ctx, tableVacuumHealth)
+// Temporary schemas holding tables, and whether a backend connected to this
+// database still owns them. pg_temp_N is named after the owning backend ID.
+func (q *Queries) TempSchemas(ctx context.Context) ([]TempSchemasRow, error) {
+	rows, err := q.db.Query(ctx, tempSchemas)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []TableVacuumHealthRow
+	var items []TempSchemasRow
 	for rows.Next() {
-		var i TableVacuumHealthRow
+		var i TempSchemasRow
 		if err := rows.Scan(
-			&i.TableName,
-			&i.LastAutovacuum,
-			&i.EstimatedRows,
-			&i.TableSizeBytes,
-			&i.NDeadTup,
-			&i.AutovacuumCount,
-			&i.Reloptions,
-			&i.LastVacuumAny,
-			&i.LastAnalyzeAny,
-			&i.NModSinceAnalyze,
-			&i.AutoanalyzeCount,
-			&i.NInsSinceVacuum,
+			&i.SchemaName,
+			&i.TableCount,
+			&i.TotalBytes,
+			&i.MaxXidAge,
+			&i.HasBackend,
+			&i.BackendIdsReliable,
+			&i.AutovacuumFreezeMaxAge,
 		); err != nil {
 			return nil, err
 		}
@@ -2191,6 +6379,137 @@ func (q *Queries) TempUsage(ctx context.Context) (TempUsageRow, error) {
 	return i, err
 }
 
+const tempUsageByDatabase = `-- name: TempUsageByDatabase :many
+SELECT
+  d.datname::text AS database_name
+  , d.temp_files::bigint AS temp_files
+  , d.temp_bytes::bigint AS temp_bytes
+  , COALESCE(EXTRACT(EPOCH FROM (NOW() - d.stats_reset)), 0)::bigint AS seconds_since_reset
+  , COALESCE(o.work_mem_bytes, (SELECT setting::bigint * 1024 FROM pg_settings WHERE name = 'work_mem'))::bigint AS work_mem_bytes
+  , (o.work_mem_bytes IS NOT NULL) AS work_mem_overridden
+FROM pg_stat_database AS d
+LEFT JOIN LATERAL (
+  SELECT
+    CASE
+      WHEN SPLIT_PART(cfg, '=', 2) ~ '^\d+$' THEN SPLIT_PART(cfg, '=', 2)::bigint * 1024
+      ELSE PG_SIZE_BYTES(SPLIT_PART(cfg, '=', 2))
+    END AS work_mem_bytes
+  FROM pg_db_role_setting AS s
+  CROSS JOIN UNNEST(s.setconfig) AS cfg
+  WHERE s.setdatabase = d.datid AND s.setrole = 0 AND cfg LIKE 'work_mem=%'
+  LIMIT 1
+) AS o ON true
+WHERE d.datname IS NOT NULL
+ORDER BY d.temp_bytes DESC
+`
+
+type TempUsageByDatabaseRow struct {
+	DatabaseName      string
+	TempFiles         int64
+	TempBytes         int64
+	SecondsSinceReset int64
+	WorkMemBytes      int64
+	WorkMemOverridden bool
+}
+
+// Temp file counters for every database, with the work_mem that applies to
+// it: an ALTER DATABASE ... SET work_mem override, or the server setting.
+// Role-level overrides are not considered. Bare numbers in an override are
+// in kB, like the setting itself.
+func (q *Queries) TempUsageByDatabase(ctx context.Context) ([]TempUsageByDatabaseRow, error) {
+	rows, err := q.db.Query(ctx, tempUsageByDatabase)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []TempUsageByDatabaseRow
+	for rows.Next() {
+		var i TempUsageByDatabaseRow
+		if err := rows.Scan(
+			&i.DatabaseName,
+			&i.TempFiles,
+			&i.TempBytes,
+			&i.SecondsSinceReset,
+			&i.WorkMemBytes,
+			&i.WorkMemOverridden,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const timestampColumnCounts = `-- name: TimestampColumnCounts :one
+SELECT
+  COUNT(*) FILTER (WHERE a.atttypid = 'timestamp'::regtype)::bigint AS naive_columns
+  , COUNT(*) FILTER (WHERE a.atttypid = 'timestamptz'::regtype)::bigint AS aware_columns
+FROM pg_attribute AS a
+INNER JOIN pg_class AS c ON a.attrelid = c.oid
+INNER JOIN pg_namespace AS n ON c.relnamespace = n.oid
+WHERE
+  a.attnum > 0
+  AND NOT a.attisdropped
+  AND c.relkind IN ('r', 'p')
+  AND NOT c.relispartition
+  AND n.nspname NOT IN ('pg_catalog', 'information_schema')
+  AND n.nspname !~ '^pg_toast'
+`
+
+type TimestampColumnCountsRow struct {
+	NaiveColumns int64
+	AwareColumns int64
+}
+
+// Counts timestamp (without time zone) and timestamptz columns in user tables.
+func (q *Queries) TimestampColumnCounts(ctx context.Context) (TimestampColumnCountsRow, error) {
+	row := q.db.QueryRow(ctx, timestampColumnCounts)
+	var i TimestampColumnCountsRow
+	err := row.Scan(&i.NaiveColumns, &i.AwareColumns)
+	return i, err
+}
+
+const timezoneSettings = `-- name: TimezoneSettings :one
+SELECT
+  (SELECT reset_val FROM pg_settings WHERE name = 'TimeZone')::text AS timezone
+  , (SELECT reset_val FROM pg_settings WHERE name = 'log_timezone')::text AS log_timezone
+  , (SELECT reset_val FROM pg_settings WHERE name = 'DateStyle')::text AS datestyle
+  , COALESCE((
+    SELECT string_agg(
+      COALESCE(d.datname, '(all)') || '/' || COALESCE(r.rolname, '(all)') || '=' || SPLIT_PART(cfg.setting, '=', 2)
+      , ', ' ORDER BY d.datname, r.rolname
+    )
+    FROM pg_db_role_setting AS s
+    CROSS JOIN LATERAL UNNEST(s.setconfig) AS cfg (setting)
+    LEFT JOIN pg_database AS d ON s.setdatabase = d.oid
+    LEFT JOIN pg_roles AS r ON s.setrole = r.oid
+    WHERE LOWER(cfg.setting) LIKE 'timezone=%'
+  ), '')::text AS timezone_overrides
+`
+
+type TimezoneSettingsRow struct {
+	Timezone          string
+	LogTimezone       string
+	Datestyle         string
+	TimezoneOverrides string
+}
+
+// Server time zone, log time zone, DateStyle, and per-database/role TimeZone overrides.
+func (q *Queries) TimezoneSettings(ctx context.Context) (TimezoneSettingsRow, error) {
+	row := q.db.QueryRow(ctx, timezoneSettings)
+	var i TimezoneSettingsRow
+	err := row.Scan(
+		&i.Timezone,
+		&i.LogTimezone,
+		&i.Datestyle,
+		&i.TimezoneOverrides,
+	)
+	return i, err
+}
+
 const toastStorage = `-- name: ToastStorage :many
 WITH toast_info AS (
   SELECT
@@ -2357,6 +6676,106 @@ func (q *Queries) ToastStorage(ctx context.Context) ([]ToastStorageRow, error) {
 	return items, nil
 }
 
+const transactionRates = `-- name: TransactionRates :one
+SELECT
+  xact_commit AS commits
+  , xact_rollback AS rollbacks
+  , stats_reset
+  , extract(EPOCH FROM (now() - stats_reset))::float8 AS stats_age_seconds
+FROM pg_stat_database
+WHERE datname = current_database()
+`
+
+type TransactionRatesRow struct {
+	Commits         int64
+	Rollbacks       int64
+	StatsReset      pgtype.Timestamptz
+	StatsAgeSeconds pgtype.Float8
+}
+
+// Returns commit and rollback counters for the current database and how long
+// they have been accumulating.
+// stats_age_seconds is NULL when statistics have never been reset, since the
+// counters then cover an unknown period.
+func (q *Queries) TransactionRates(ctx context.Context) (TransactionRatesRow, error) {
+	row := q.db.QueryRow(ctx, transactionRates)
+	var i TransactionRatesRow
+	err := row.Scan(
+		&i.Commits,
+		&i.Rollbacks,
+		&i.StatsReset,
+		&i.StatsAgeSeconds,
+	)
+	return i, err
+}
+
+const tuneSettings = `-- name: TuneSettings :many
+SELECT
+  name::text AS name
+  , current_setting(name)::text AS display
+  , (
+    CASE
+      WHEN unit IN ('B', 'kB', '8kB', '16kB', 'MB') THEN pg_size_bytes(current_setting(name))::float8
+      ELSE setting::float8
+    END
+  )::float8 AS value
+  , (context = 'postmaster')::boolean AS needs_restart
+FROM pg_settings
+WHERE
+  name IN (
+    'checkpoint_completion_target'
+    , 'default_statistics_target'
+    , 'effective_cache_size'
+    , 'effective_io_concurrency'
+    , 'maintenance_work_mem'
+    , 'max_connections'
+    , 'max_parallel_maintenance_workers'
+    , 'max_parallel_workers'
+    , 'max_parallel_workers_per_gather'
+    , 'max_wal_size'
+    , 'max_worker_processes'
+    , 'min_wal_size'
+    , 'random_page_cost'
+    , 'shared_buffers'
+    , 'wal_buffers'
+    , 'work_mem'
+  )
+ORDER BY name
+`
+
+type TuneSettingsRow struct {
+	Name         string
+	Display      string
+	Value        float64
+	NeedsRestart bool
+}
+
+// The settings pgdoctor tune recommends, with memory settings in bytes.
+func (q *Queries) TuneSettings(ctx context.Context) ([]TuneSettingsRow, error) {
+	rows, err := q.db.Query(ctx, tuneSettings)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []TuneSettingsRow
+	for rows.Next() {
+		var i TuneSettingsRow
+		if err := rows.Scan(
+			&i.Name,
+			&i.Display,
+			&i.Value,
+			&i.NeedsRestart,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const uuidColumnDefaults = `-- name: UuidColumnDefaults :many
 WITH indexed_columns AS (
   SELECT
@@ -2472,6 +6891,33 @@ func (q *Queries) UuidColumnsAsString(ctx context.Context) ([]UuidColumnsAsStrin
 	return items, nil
 }
 
+const vacuumFailsafeAge = `-- name: VacuumFailsafeAge :one
+SELECT
+  (
+    SELECT s.setting::bigint FROM pg_settings AS s
+    WHERE s.name = 'vacuum_failsafe_age'
+  ) AS vacuum_failsafe_age
+  , (
+    SELECT s.setting::bigint FROM pg_settings AS s
+    WHERE s.name = 'autovacuum_freeze_max_age'
+  ) AS autovacuum_freeze_max_age
+`
+
+type VacuumFailsafeAgeRow struct {
+	VacuumFailsafeAge      pgtype.Int8
+	AutovacuumFreezeMaxAge pgtype.Int8
+}
+
+// Gets vacuum_failsafe_age (PostgreSQL 14+, NULL on older versions) and
+// autovacuum_freeze_max_age, which together set the age the failsafe
+// triggers at.
+func (q *Queries) VacuumFailsafeAge(ctx context.Context) (VacuumFailsafeAgeRow, error) {
+	row := q.db.QueryRow(ctx, vacuumFailsafeAge)
+	var i VacuumFailsafeAgeRow
+	err := row.Scan(&i.VacuumFailsafeAge, &i.AutovacuumFreezeMaxAge)
+	return i, err
+}
+
 const vacuumSettings = `-- name: VacuumSettings :many
 SELECT
   name::varchar
@@ -2523,3 +6969,165 @@ func (q *Queries) VacuumSettings(ctx context.Context) ([]VacuumSettingsRow, erro
 	}
 	return items, nil
 }
+
+const walCompressionSettings = `-- name: WalCompressionSettings :one
+SELECT
+  CURRENT_SETTING('server_version_num')::integer AS server_version_num
+  , CURRENT_SETTING('wal_compression') AS wal_compression
+  , CURRENT_SETTING('full_page_writes')::boolean AS full_page_writes
+  , CURRENT_SETTING('wal_init_zero')::boolean AS wal_init_zero
+  , CURRENT_SETTING('wal_recycle')::boolean AS wal_recycle
+  , CURRENT_SETTING('block_size')::bigint AS block_size
+  , (SELECT setting::bigint FROM pg_settings WHERE name = 'checkpoint_timeout') AS checkpoint_timeout_seconds
+`
+
+type WalCompressionSettingsRow struct {
+	ServerVersionNum         int32
+	WalCompression           string
+	FullPageWrites           bool
+	WalInitZero              bool
+	WalRecycle               bool
+	BlockSize                int64
+	CheckpointTimeoutSeconds int64
+}
+
+// Settings that decide how large each full-page image is and how WAL
+// segments are created, with the server version to pick the statistics views.
+func (q *Queries) WalCompressionSettings(ctx context.Context) (WalCompressionSettingsRow, error) {
+	row := q.db.QueryRow(ctx, walCompressionSettings)
+	var i WalCompressionSettingsRow
+	err := row.Scan(
+		&i.ServerVersionNum,
+		&i.WalCompression,
+		&i.FullPageWrites,
+		&i.WalInitZero,
+		&i.WalRecycle,
+		&i.BlockSize,
+		&i.CheckpointTimeoutSeconds,
+	)
+	return i, err
+}
+
+const walDirStats = `-- name: WalDirStats :one
+WITH current_segment AS (
+  SELECT
+    CASE
+      WHEN pg_is_in_recovery() THEN NULL
+      ELSE pg_walfile_name(pg_current_wal_lsn())
+    END AS name
+)
+
+SELECT
+  COALESCE(SUM(w.size), 0)::bigint AS total_bytes
+  , COUNT(*) FILTER (WHERE w.name ~ '^[0-9A-F]{24}$')::bigint AS segment_count
+  , COUNT(*) FILTER (WHERE w.name ~ '^[0-9A-F]{24}$' AND w.name > cs.name)::bigint AS recycled_count
+FROM pg_ls_waldir() AS w
+CROSS JOIN current_segment AS cs
+`
+
+type WalDirStatsRow struct {
+	TotalBytes    int64
+	SegmentCount  int64
+	RecycledCount int64
+}
+
+// Size of pg_wal and its segments. Segments named after the current insert
+// segment have been recycled (renamed for reuse) rather than removed.
+func (q *Queries) WalDirStats(ctx context.Context) (WalDirStatsRow, error) {
+	row := q.db.QueryRow(ctx, walDirStats)
+	var i WalDirStatsRow
+	err := row.Scan(&i.TotalBytes, &i.SegmentCount, &i.RecycledCount)
+	return i, err
+}
+
+const walFpiStats = `-- name: WalFpiStats :one
+SELECT
+  w.wal_records::bigint AS wal_records
+  , w.wal_fpi::bigint AS wal_fpi
+  , w.wal_bytes::bigint AS wal_bytes
+  , COALESCE(EXTRACT(EPOCH FROM (NOW() - w.stats_reset)), 0)::bigint AS wal_seconds_since_reset
+  , c.num_timed::bigint AS checkpoints_timed
+  , c.num_requested::bigint AS checkpoints_requested
+  , COALESCE(EXTRACT(EPOCH FROM (NOW() - c.stats_reset)), 0)::bigint AS checkpoint_seconds_since_reset
+FROM pg_stat_wal AS w
+CROSS JOIN pg_stat_checkpointer AS c
+`
+
+type WalFpiStatsRow struct {
+	WalRecords                  int64
+	WalFpi                      int64
+	WalBytes                    int64
+	WalSecondsSinceReset        int64
+	CheckpointsTimed            int64
+	CheckpointsRequested        int64
+	CheckpointSecondsSinceReset int64
+}
+
+// WAL volume and full-page images from pg_stat_wal with checkpoint counters
+// from pg_stat_checkpointer (PostgreSQL 17+).
+func (q *Queries) WalFpiStats(ctx context.Context) (WalFpiStatsRow, error) {
+	row := q.db.QueryRow(ctx, walFpiStats)
+	var i WalFpiStatsRow
+	err := row.Scan(
+		&i.WalRecords,
+		&i.WalFpi,
+		&i.WalBytes,
+		&i.WalSecondsSinceReset,
+		&i.CheckpointsTimed,
+		&i.CheckpointsRequested,
+		&i.CheckpointSecondsSinceReset,
+	)
+	return i, err
+}
+
+const walSettings = `-- name: WalSettings :one
+SELECT
+  current_setting('server_version_num')::integer AS server_version_num
+  , pg_size_bytes(current_setting('max_wal_size'))::bigint AS max_wal_size_bytes
+  , pg_size_bytes(current_setting('min_wal_size'))::bigint AS min_wal_size_bytes
+  , pg_size_bytes(current_setting('wal_segment_size'))::bigint AS wal_segment_size_bytes
+  , pg_size_bytes(current_setting('wal_keep_size'))::bigint AS wal_keep_size_bytes
+  , current_setting('wal_recycle')::boolean AS wal_recycle
+  , pg_is_in_recovery()::boolean AS in_recovery
+  -- pg_ls_waldir() is restricted to superusers and pg_monitor members
+  , (current_setting('is_superuser')::boolean OR pg_has_role('pg_monitor', 'MEMBER'))::boolean AS can_list_wal_dir
+  , COALESCE((
+    SELECT
+      MAX(pg_wal_lsn_diff(
+        CASE WHEN pg_is_in_recovery() THEN pg_last_wal_replay_lsn() ELSE pg_current_wal_lsn() END
+        , restart_lsn
+      ))
+    FROM pg_replication_slots
+    WHERE restart_lsn IS NOT NULL
+  ), 0)::bigint AS slot_retained_bytes
+`
+
+type WalSettingsRow struct {
+	ServerVersionNum    int32
+	MaxWalSizeBytes     int64
+	MinWalSizeBytes     int64
+	WalSegmentSizeBytes int64
+	WalKeepSizeBytes    int64
+	WalRecycle          bool
+	InRecovery          bool
+	CanListWalDir       bool
+	SlotRetainedBytes   int64
+}
+
+// WAL sizing settings and the WAL retained by replication slots, from LSN math.
+func (q *Queries) WalSettings(ctx context.Context) (WalSettingsRow, error) {
+	row := q.db.QueryRow(ctx, walSettings)
+	var i WalSettingsRow
+	err := row.Scan(
+		&i.ServerVersionNum,
+		&i.MaxWalSizeBytes,
+		&i.MinWalSizeBytes,
+		&i.WalSegmentSizeBytes,
+		&i.WalKeepSizeBytes,
+		&i.WalRecycle,
+		&i.InRecovery,
+		&i.CanListWalDir,
+		&i.SlotRetainedBytes,
+	)
+	return i, err
+}
@@ -0,0 +1,47 @@
+package db
+
+// This file is written by hand; sqlc generates the rest of the package.
+// sqlc's :many queries collect every row into a slice before returning,
+// which on catalogs with hundreds of thousands of relations holds them all
+// in memory at once. The Each variants below run the same generated query
+// and hand rows to a callback as they are read, so a check keeps only the
+// rows it reports.
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// forEachRow runs query with args and calls fn with each row, scanned into
+// T by column position as the generated code does. It stops at the first
+// error from fn and returns it.
+func forEachRow[T any](ctx context.Context, db DBTX, query string, fn func(T) error, args ...interface{}) error {
+	rows, err := db.Query(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		row, err := pgx.RowToStructByPos[T](rows)
+		if err != nil {
+			return err
+		}
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// TableVacuumHealthEach streams the rows of TableVacuumHealth, one per
+// table in schemaName.
+func (q *Queries) TableVacuumHealthEach(ctx context.Context, schemaName string, fn func(TableVacuumHealthRow) error) error {
+	return forEachRow(ctx, q.db, tableVacuumHealth, fn, schemaName)
+}
+
+// ColumnStorageStrategiesEach streams the rows of ColumnStorageStrategies,
+// one per wide or non-default-storage column in every schema.
+func (q *Queries) ColumnStorageStrategiesEach(ctx context.Context, fn func(ColumnStorageStrategiesRow) error) error {
+	return forEachRow(ctx, q.db, columnStorageStrategies, fn)
+}
@@ -0,0 +1,75 @@
+package pgdoctor
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/fresha/pgdoctor/check"
+)
+
+// applyHysteresis keeps a finding at its previous severity while its metric
+// sits between the clear threshold and the trigger threshold, so a value
+// hovering around a threshold does not flip the finding on every run.
+//
+// Clear thresholds are configured per check as "clear_" followed by the
+// threshold's own key, e.g. clear_physical_warn_seconds next to
+// physical_warn_seconds. The FAIL key is the WARN key with "_warn" replaced
+// by "_fail". Only findings whose checks report observations, and only
+// thresholds that have a clear_* value, are affected.
+func applyHysteresis(report *check.Report, observations []check.Observation, cfg check.Config, previous map[string]check.Severity) {
+	held := false
+	for _, o := range observations {
+		prev, ok := previous[o.CheckID+"/"+o.FindingID]
+		if !ok {
+			continue
+		}
+
+		for i := range report.Results {
+			f := &report.Results[i]
+			if f.ID != o.FindingID || f.Severity >= prev {
+				continue
+			}
+
+			severity, key, clearAt := heldSeverity(o, cfg, prev, f.Severity)
+			if severity == f.Severity {
+				continue
+			}
+
+			f.Details = strings.TrimSpace(f.Details + fmt.Sprintf("\nHeld at %s: %s is still at or above %s = %s (last run was %s)",
+				strings.ToUpper(severity.String()), formatValue(o.Value), key, formatValue(clearAt), strings.ToUpper(prev.String())))
+			f.Severity = severity
+			held = true
+		}
+	}
+
+	if held {
+		report.Severity = check.SeverityOK
+		for _, f := range report.Results {
+			report.Severity = max(report.Severity, f.Severity)
+		}
+	}
+}
+
+// heldSeverity returns the severity a finding keeps given its previous one,
+// and the clear threshold that held it. current is returned when no clear
+// threshold applies or the value has dropped below it.
+func heldSeverity(o check.Observation, cfg check.Config, prev, current check.Severity) (check.Severity, string, float64) {
+	if prev >= check.SeverityFail && strings.Contains(o.Key, "_warn") {
+		key := "clear_" + strings.Replace(o.Key, "_warn", "_fail", 1)
+		if clearAt := cfg.Float(o.CheckID, key, 0); clearAt > 0 && o.Value >= clearAt && o.Value < o.Fail {
+			return check.SeverityFail, key, clearAt
+		}
+	}
+	if current < check.SeverityWarn {
+		key := "clear_" + o.Key
+		if clearAt := cfg.Float(o.CheckID, key, 0); clearAt > 0 && o.Value >= clearAt && o.Value < o.Warn {
+			return check.SeverityWarn, key, clearAt
+		}
+	}
+	return current, "", 0
+}
+
+func formatValue(v float64) string {
+	return strconv.FormatFloat(v, 'g', 4, 64)
+}
@@ -0,0 +1,117 @@
+package pgdoctor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/fresha/pgdoctor/check"
+	"github.com/fresha/pgdoctor/db"
+)
+
+// thresholdChecker reports one metric against WARN 75 and FAIL 90, the way
+// configurable checks do.
+type thresholdChecker struct {
+	value float64
+}
+
+func (c *thresholdChecker) Metadata() check.Metadata {
+	return check.Metadata{CheckID: "usage", Name: "Usage", Category: check.CategoryConfigs}
+}
+
+func (c *thresholdChecker) Check(ctx context.Context) (*check.Report, error) {
+	check.Observe(ctx, check.Observation{CheckID: "usage", FindingID: "usage-percent", Key: "usage_warn_percent", Value: c.value, Warn: 75, Fail: 90})
+
+	severity := check.SeverityOK
+	switch {
+	case c.value >= 90:
+		severity = check.SeverityFail
+	case c.value >= 75:
+		severity = check.SeverityWarn
+	}
+
+	report := check.NewReport(c.Metadata())
+	report.AddFinding(check.Finding{ID: "usage-percent", Name: "Usage", Severity: severity, Details: "usage"})
+	report.AddFinding(check.Finding{ID: "other", Name: "Other", Severity: check.SeverityOK})
+	return report, nil
+}
+
+func runUsage(t *testing.T, value float64, cfg check.Config, previous map[string]check.Severity) *check.Report {
+	t.Helper()
+
+	checker := &thresholdChecker{value: value}
+	var reports []*check.Report
+	Run(context.Background(), nil, Options{
+		Checks: []check.Package{{
+			Metadata: checker.Metadata,
+			New:      func(db.DBTX, check.Config) check.Checker { return checker },
+		}},
+		Config:   cfg,
+		Previous: previous,
+		OnReport: Collect(&reports),
+	})
+	require.Len(t, reports, 1)
+	return reports[0]
+}
+
+func TestRun_Hysteresis(t *testing.T) {
+	t.Parallel()
+
+	clearCfg := check.Config{"usage": {"clear_usage_warn_percent": "70", "clear_usage_fail_percent": "85"}}
+
+	tests := []struct {
+		name     string
+		value    float64
+		cfg      check.Config
+		previous check.Severity
+		want     check.Severity
+		held     bool
+	}{
+		{name: "warn held above clear", value: 72, cfg: clearCfg, previous: check.SeverityWarn, want: check.SeverityWarn, held: true},
+		{name: "warn clears below clear", value: 69, cfg: clearCfg, previous: check.SeverityWarn, want: check.SeverityOK},
+		{name: "fail held above clear", value: 87, cfg: clearCfg, previous: check.SeverityFail, want: check.SeverityFail, held: true},
+		{name: "fail drops to held warn", value: 72, cfg: clearCfg, previous: check.SeverityFail, want: check.SeverityWarn, held: true},
+		{name: "fail drops to warn below fail clear", value: 80, cfg: clearCfg, previous: check.SeverityFail, want: check.SeverityWarn},
+		{name: "no trigger without previous finding", value: 72, cfg: clearCfg, previous: check.SeverityOK, want: check.SeverityOK},
+		{name: "no clear threshold configured", value: 72, previous: check.SeverityWarn, want: check.SeverityOK},
+		{name: "escalation is never held back", value: 95, cfg: clearCfg, previous: check.SeverityWarn, want: check.SeverityFail},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			report := runUsage(t, tt.value, tt.cfg, map[string]check.Severity{"usage/usage-percent": tt.previous})
+
+			f := report.Results[0]
+			assert.Equal(t, tt.want, f.Severity)
+			assert.Equal(t, tt.want, report.Severity)
+			if tt.held {
+				assert.Contains(t, f.Details, "Held at "+map[check.Severity]string{check.SeverityWarn: "WARN", check.SeverityFail: "FAIL"}[tt.want])
+				assert.Contains(t, f.Details, "last run was")
+			} else {
+				assert.NotContains(t, f.Details, "Held at")
+			}
+			assert.Equal(t, check.SeverityOK, report.Results[1].Severity)
+		})
+	}
+}
+
+func TestRun_HysteresisDetails(t *testing.T) {
+	t.Parallel()
+
+	cfg := check.Config{"usage": {"clear_usage_warn_percent": "70"}}
+	report := runUsage(t, 72.5, cfg, map[string]check.Severity{"usage/usage-percent": check.SeverityWarn})
+
+	assert.Equal(t, "usage\nHeld at WARN: 72.5 is still at or above clear_usage_warn_percent = 70 (last run was WARN)", report.Results[0].Details)
+}
+
+func TestRun_NoPreviousLeavesReportsAlone(t *testing.T) {
+	t.Parallel()
+
+	cfg := check.Config{"usage": {"clear_usage_warn_percent": "70"}}
+	report := runUsage(t, 72, cfg, nil)
+	assert.Equal(t, check.SeverityOK, report.Severity)
+}
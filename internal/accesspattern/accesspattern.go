@@ -0,0 +1,76 @@
+// Package accesspattern classifies tables by how they are written, from the
+// cumulative counters in pg_stat_user_tables. Checks that tune advice to a
+// table's activity (partitioning, vacuum settings, HOT updates, queues)
+// share it, so "append-only" or "queue-like" means the same thing in every
+// report. Counters accumulate since the last statistics reset; checks that
+// need a minimum amount of evidence apply their own size gates on top.
+package accesspattern
+
+// Counters are a table's write counters and live row estimate.
+type Counters struct {
+	Inserts  int64 // n_tup_ins
+	Updates  int64 // n_tup_upd, including HOT updates
+	Deletes  int64 // n_tup_del
+	LiveRows int64 // n_live_tup
+}
+
+// Writes is the number of rows inserted, updated, or deleted.
+func (c Counters) Writes() int64 {
+	return c.Inserts + c.Updates + c.Deletes
+}
+
+// Pattern is how a table is written.
+type Pattern string
+
+const (
+	// Static tables have seen too few writes to classify.
+	Static Pattern = "static"
+	// QueueLike tables have rows pass through them: most inserted rows are
+	// deleted again and far more are inserted than are alive at once.
+	QueueLike Pattern = "queue-like"
+	// DeleteHeavy tables delete a large share of what they insert, such as
+	// tables purged by retention jobs.
+	DeleteHeavy Pattern = "delete-heavy"
+	// AppendOnly tables mostly receive inserts, such as events and logs.
+	AppendOnly Pattern = "append-only"
+	// UpdateHeavy tables update rows more often than they insert them.
+	UpdateHeavy Pattern = "update-heavy"
+	// Mixed tables match none of the other patterns.
+	Mixed Pattern = "mixed"
+)
+
+const (
+	// MinWrites is the number of writes below which a table is static.
+	MinWrites = int64(1000)
+	// QueueMinDeleteRatio is the share of inserted rows a queue deletes.
+	QueueMinDeleteRatio = 0.5
+	// QueueMinTurnover is how many times over a queue's live rows are
+	// inserted.
+	QueueMinTurnover = int64(10)
+	// DeleteHeavyMinDeleteRatio is the share of inserted rows a
+	// delete-heavy table deletes.
+	DeleteHeavyMinDeleteRatio = 0.2
+	// AppendOnlyMinInsertShare is the share of writes that are inserts on
+	// an append-only table.
+	AppendOnlyMinInsertShare = 0.8
+)
+
+// Classify returns the table's pattern. Rules are tried in order, so a
+// queue, which also deletes heavily, is reported as queue-like.
+func Classify(c Counters) Pattern {
+	writes := c.Writes()
+	switch {
+	case writes < MinWrites:
+		return Static
+	case float64(c.Deletes) >= float64(c.Inserts)*QueueMinDeleteRatio && c.Inserts >= QueueMinTurnover*max(c.LiveRows, 1):
+		return QueueLike
+	case float64(c.Deletes) > float64(c.Inserts)*DeleteHeavyMinDeleteRatio:
+		return DeleteHeavy
+	case float64(c.Inserts) > float64(writes)*AppendOnlyMinInsertShare:
+		return AppendOnly
+	case c.Updates > c.Inserts:
+		return UpdateHeavy
+	default:
+		return Mixed
+	}
+}
@@ -0,0 +1,41 @@
+package accesspattern
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClassify(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		counters Counters
+		want     Pattern
+	}{
+		{name: "no writes", counters: Counters{LiveRows: 5000000}, want: Static},
+		{name: "few writes", counters: Counters{Inserts: 600, Updates: 300, LiveRows: 5000000}, want: Static},
+		{name: "job queue", counters: Counters{Inserts: 2000000, Deletes: 1990000, LiveRows: 10000}, want: QueueLike},
+		{name: "churn with low turnover", counters: Counters{Inserts: 20000, Deletes: 15000, LiveRows: 5000}, want: DeleteHeavy},
+		{name: "retention purge", counters: Counters{Inserts: 1000000, Deletes: 300000, LiveRows: 5000000}, want: DeleteHeavy},
+		{name: "purge only", counters: Counters{Deletes: 50000, LiveRows: 100000}, want: DeleteHeavy},
+		{name: "event log", counters: Counters{Inserts: 1000000, Updates: 1000, LiveRows: 1000000}, want: AppendOnly},
+		{name: "insert share at the boundary", counters: Counters{Inserts: 8000, Updates: 2000, LiveRows: 8000}, want: Mixed},
+		{name: "status updates", counters: Counters{Inserts: 100000, Updates: 2000000, LiveRows: 100000}, want: UpdateHeavy},
+		{name: "balanced", counters: Counters{Inserts: 500000, Updates: 400000, Deletes: 50000, LiveRows: 2000000}, want: Mixed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.want, Classify(tt.counters))
+		})
+	}
+}
+
+func TestWrites(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, int64(60), Counters{Inserts: 10, Updates: 20, Deletes: 30, LiveRows: 1000}.Writes())
+}
@@ -0,0 +1,69 @@
+package awsauth
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// From the AWS SigV4 test suite (get-vanilla and post-x-www-form-urlencoded).
+func TestSign_TestSuite(t *testing.T) {
+	t.Parallel()
+
+	creds := Credentials{AccessKeyID: "AKIDEXAMPLE", SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY"}
+	now := time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		method      string
+		contentType string
+		body        string
+		expected    string
+	}{
+		{
+			name:     "get-vanilla",
+			method:   http.MethodGet,
+			expected: "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, SignedHeaders=host;x-amz-date, Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31",
+		},
+		{
+			name:        "post-x-www-form-urlencoded",
+			method:      http.MethodPost,
+			contentType: "application/x-www-form-urlencoded",
+			body:        "Param1=value1",
+			expected:    "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, SignedHeaders=content-type;host;x-amz-date, Signature=ff11897932ad3f4e8b18135d722051e5ac45fc38421b1da7b9d196a0fe09473a",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			req, err := http.NewRequest(tt.method, "https://example.amazonaws.com/", nil)
+			require.NoError(t, err)
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+
+			Sign(req, []byte(tt.body), creds, "service", "us-east-1", now)
+
+			assert.Equal(t, "20150830T123600Z", req.Header.Get("X-Amz-Date"))
+			assert.Equal(t, tt.expected, req.Header.Get("Authorization"))
+		})
+	}
+}
+
+func TestSign_SessionToken(t *testing.T) {
+	t.Parallel()
+
+	req, err := http.NewRequest(http.MethodPut, "https://bucket.s3.eu-west-1.amazonaws.com/key", nil)
+	require.NoError(t, err)
+
+	Sign(req, []byte("x"), Credentials{AccessKeyID: "AKID", SecretAccessKey: "secret", SessionToken: "token"}, "s3", "eu-west-1", time.Now())
+
+	assert.Equal(t, "token", req.Header.Get("X-Amz-Security-Token"))
+	assert.NotEmpty(t, req.Header.Get("X-Amz-Content-Sha256"))
+	assert.Contains(t, req.Header.Get("Authorization"), "SignedHeaders=host;x-amz-content-sha256;x-amz-date;x-amz-security-token,")
+}
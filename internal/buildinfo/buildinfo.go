@@ -0,0 +1,79 @@
+// Package buildinfo reports which pgdoctor build is running, so findings can
+// be matched to the release that produced them.
+package buildinfo
+
+import (
+	"runtime"
+	"runtime/debug"
+)
+
+// Set at release time with
+// -ldflags "-X github.com/fresha/pgdoctor/internal/buildinfo.version=v1.2.3 ...".
+var (
+	version string
+	commit  string
+	date    string
+)
+
+// Info describes a pgdoctor build.
+type Info struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit,omitempty"`
+	Date      string `json:"date,omitempty"`
+	GoVersion string `json:"go_version"`
+	// Modified is set when the binary was built from a dirty working tree.
+	Modified bool `json:"modified,omitempty"`
+}
+
+// Get returns the build's version information. Values injected at link time
+// win; otherwise they come from the module and VCS data Go embeds in the
+// binary (go install or go build in a checkout), and the version falls
+// back to "dev".
+func Get() Info {
+	info := Info{
+		Version:   version,
+		Commit:    commit,
+		Date:      date,
+		GoVersion: runtime.Version(),
+	}
+
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		if info.Version == "" && bi.Main.Version != "" && bi.Main.Version != "(devel)" {
+			info.Version = bi.Main.Version
+		}
+		for _, s := range bi.Settings {
+			switch s.Key {
+			case "vcs.revision":
+				if info.Commit == "" {
+					info.Commit = s.Value
+				}
+			case "vcs.time":
+				if info.Date == "" {
+					info.Date = s.Value
+				}
+			case "vcs.modified":
+				info.Modified = s.Value == "true"
+			}
+		}
+	}
+
+	if info.Version == "" {
+		info.Version = "dev"
+	}
+	return info
+}
+
+// String formats the version with an abbreviated commit, e.g. "v1.2.3 (3f1c2a9)".
+func (i Info) String() string {
+	if i.Commit == "" {
+		return i.Version
+	}
+	c := i.Commit
+	if len(c) > 7 {
+		c = c[:7]
+	}
+	if i.Modified {
+		c += "-dirty"
+	}
+	return i.Version + " (" + c + ")"
+}
@@ -0,0 +1,38 @@
+package buildinfo_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/fresha/pgdoctor/internal/buildinfo"
+)
+
+func TestInfoString(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		info buildinfo.Info
+		want string
+	}{
+		{name: "version only", info: buildinfo.Info{Version: "v1.2.3"}, want: "v1.2.3"},
+		{name: "abbreviated commit", info: buildinfo.Info{Version: "v1.2.3", Commit: "3f1c2a9e0b7d4c55aa"}, want: "v1.2.3 (3f1c2a9)"},
+		{name: "dirty tree", info: buildinfo.Info{Version: "dev", Commit: "3f1c2a9e0b7d", Modified: true}, want: "dev (3f1c2a9-dirty)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.want, tt.info.String())
+		})
+	}
+}
+
+func TestGet(t *testing.T) {
+	t.Parallel()
+
+	info := buildinfo.Get()
+	assert.NotEmpty(t, info.Version)
+	assert.NotEmpty(t, info.GoVersion)
+}
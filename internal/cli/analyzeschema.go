@@ -0,0 +1,244 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/spf13/cobra"
+
+	"github.com/fresha/pgdoctor"
+	"github.com/fresha/pgdoctor/check"
+	"github.com/fresha/pgdoctor/internal/report"
+)
+
+// defaultSchemaChecks are the checks that only read the catalog, so they give
+// meaningful results on a database loaded from a schema-only dump.
+var defaultSchemaChecks = []string{string(check.CategorySchema), "duplicate-indexes"}
+
+type analyzeSchemaOptions struct {
+	runOptions
+	dump  string
+	pgBin string
+}
+
+func newAnalyzeSchemaCommand() *cobra.Command {
+	opts := &analyzeSchemaOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "analyze-schema --dump <file>",
+		Short: "Run schema checks against a dump loaded into a scratch PostgreSQL",
+		Long: `Load a schema-only dump (pg_dump --schema-only) into a temporary local
+PostgreSQL instance and run the catalog-only checks against it, so schema
+problems can be caught in CI without connecting to a real database.
+
+The scratch instance is created with initdb and pg_ctl from --pg-bin (or
+PATH), listens only on a Unix socket in a temporary directory, and is removed
+afterwards. Checks that depend on statistics or activity are not run by
+default because a freshly loaded dump has neither.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if opts.dump == "" {
+				return fmt.Errorf("--dump is required")
+			}
+			if _, err := os.Stat(opts.dump); err != nil {
+				return fmt.Errorf("reading dump: %w", err)
+			}
+
+			format, dest, err := parseOutput(opts.output)
+			if err != nil {
+				return err
+			}
+			if err := validateTextOrder(&opts.runOptions); err != nil {
+				return err
+			}
+			if err := validateFailOn(&opts.runOptions); err != nil {
+				return err
+			}
+
+			allChecks := pgdoctor.AllChecks()
+			if len(opts.only) == 0 {
+				opts.only = defaultSchemaChecks
+			}
+			validOnly, validIgnored, err := validateFilters(allChecks, opts.only, opts.ignored)
+			if err != nil {
+				return err
+			}
+
+			checks := pgdoctor.Filter(allChecks, validOnly, validIgnored)
+			pgdoctor.SortByPriority(checks, nil)
+
+			ctx := cmd.Context()
+
+			server, err := startScratchServer(ctx, opts.pgBin)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				return &SilentError{ExitCode: 2}
+			}
+			defer server.stop()
+
+			if err := server.load(ctx, opts.dump); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				return &SilentError{ExitCode: 2}
+			}
+
+			conn, err := pgx.Connect(ctx, server.dsn())
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: connecting to scratch server: %v\n", err)
+				return &SilentError{ExitCode: 2}
+			}
+			defer conn.Close(context.Background())
+
+			runOpts := pgdoctor.Options{Checks: checks}
+			w := cmd.OutOrStdout()
+
+			if format == "json" || format == "markdown" || format == "confluence" || format == "html" || format == "sarif" {
+				var reports []*check.Report
+				runOpts.OnReport = pgdoctor.Collect(&reports)
+				pgdoctor.Run(ctx, conn, runOpts)
+
+				render := func(w io.Writer) error {
+					switch format {
+					case "markdown":
+						return formatMarkdown(w, filepath.Base(opts.dump), "scratch server", reports)
+					case "confluence":
+						return formatConfluence(w, filepath.Base(opts.dump), "scratch server", reports)
+					case "html":
+						return formatHTML(w, filepath.Base(opts.dump), "scratch server", reports)
+					case "sarif":
+						return report.WriteSARIF(w, filepath.Base(opts.dump), reports)
+					default:
+						return report.WriteJSON(w, reports)
+					}
+				}
+
+				var renderErr error
+				if dest != nil {
+					dump := filepath.Base(opts.dump)
+					dest.Expand(time.Now(), "scratch", strings.TrimSuffix(dump, filepath.Ext(dump)))
+					if renderErr = dest.Write(ctx, render); renderErr == nil {
+						fmt.Fprintf(os.Stderr, "Report written to %s\n", dest.Location)
+					}
+				} else {
+					renderErr = render(w)
+				}
+				if renderErr != nil {
+					fmt.Fprintf(os.Stderr, "Error: %v\n", renderErr)
+					return &SilentError{ExitCode: 1}
+				}
+				return failOnExit(opts.failOn, maxReportSeverity(reports))
+			}
+
+			fmt.Fprintf(w, "Schema Check: %s\n\n", filepath.Base(opts.dump))
+
+			tr := &textReporter{w: w, opts: &opts.runOptions}
+			runOpts.OnReport = tr.onReport
+			pgdoctor.Run(ctx, conn, runOpts)
+			tr.flush()
+
+			fmt.Fprintln(w)
+			printSummary(w, tr.reports)
+
+			return failOnExit(opts.failOn, tr.maxSeverity)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.dump, "dump", "", "Schema-only SQL dump to load (pg_dump --schema-only)")
+	cmd.Flags().StringVar(&opts.pgBin, "pg-bin", "", "Directory containing initdb, pg_ctl and psql (default: PATH)")
+	cmd.Flags().StringSliceVar(&opts.only, "only", nil, "Only run these checks or categories (default: schema,duplicate-indexes)")
+	cmd.Flags().StringSliceVar(&opts.ignored, "ignore", nil, "Checks or categories to ignore")
+	cmd.Flags().StringVar(&opts.detail, "detail", string(detailBrief), "Detail level: summary, brief (default), verbose, debug")
+	cmd.Flags().BoolVar(&opts.hidePassing, "hide-passing", false, "Hide passing checks")
+	addTextOrderFlags(cmd, &opts.runOptions)
+	addFailOnFlag(cmd, &opts.runOptions)
+	cmd.Flags().StringVar(&opts.output, "output", "text", "Output format: text (default), json, markdown, confluence, html, sarif; or a .json/.md/.xhtml/.html/.sarif destination path or URL")
+
+	return cmd
+}
+
+func maxReportSeverity(reports []*check.Report) check.Severity {
+	severity := check.SeverityOK
+	for _, r := range reports {
+		severity = max(severity, r.Severity)
+	}
+	return severity
+}
+
+// scratchServer is a throwaway PostgreSQL cluster in a temporary directory,
+// reachable only through a Unix socket in that directory.
+type scratchServer struct {
+	dir   string
+	pgBin string
+}
+
+func startScratchServer(ctx context.Context, pgBin string) (*scratchServer, error) {
+	dir, err := os.MkdirTemp("", "pgdoctor-schema-")
+	if err != nil {
+		return nil, fmt.Errorf("creating scratch directory: %w", err)
+	}
+	s := &scratchServer{dir: dir, pgBin: pgBin}
+
+	if err := s.exec(ctx, "initdb", s.initdbArgs()...); err != nil {
+		_ = os.RemoveAll(dir)
+		return nil, err
+	}
+	if err := s.exec(ctx, "pg_ctl", s.startArgs()...); err != nil {
+		_ = os.RemoveAll(dir)
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *scratchServer) dataDir() string {
+	return filepath.Join(s.dir, "data")
+}
+
+func (s *scratchServer) initdbArgs() []string {
+	return []string{"-D", s.dataDir(), "-U", "postgres", "--auth=trust", "--encoding=UTF8", "--no-sync"}
+}
+
+func (s *scratchServer) startArgs() []string {
+	// No TCP listener and no durability: the cluster only lives for this run.
+	serverOpts := fmt.Sprintf("-c listen_addresses='' -k %s -c fsync=off -c full_page_writes=off", s.dir)
+	return []string{"-D", s.dataDir(), "-l", filepath.Join(s.dir, "server.log"), "-o", serverOpts, "-w", "start"}
+}
+
+func (s *scratchServer) dsn() string {
+	return fmt.Sprintf("host=%s user=postgres dbname=postgres", s.dir)
+}
+
+// load applies the dump with psql, stopping at the first error so a
+// partially loaded schema is never analyzed.
+func (s *scratchServer) load(ctx context.Context, dump string) error {
+	return s.exec(ctx, "psql", "-h", s.dir, "-U", "postgres", "-d", "postgres",
+		"-X", "-q", "-v", "ON_ERROR_STOP=1", "-f", dump)
+}
+
+func (s *scratchServer) stop() {
+	_ = s.exec(context.Background(), "pg_ctl", "-D", s.dataDir(), "-m", "immediate", "stop")
+	_ = os.RemoveAll(s.dir)
+}
+
+func (s *scratchServer) exec(ctx context.Context, name string, args ...string) error {
+	path := name
+	if s.pgBin != "" {
+		path = filepath.Join(s.pgBin, name)
+	}
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, path, args...)
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("%s: %w: %s", name, err, msg)
+		}
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	return nil
+}
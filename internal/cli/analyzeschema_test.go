@@ -0,0 +1,49 @@
+package cli
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/fresha/pgdoctor"
+	"github.com/fresha/pgdoctor/check"
+)
+
+func TestDefaultSchemaChecksAreCatalogOnly(t *testing.T) {
+	t.Parallel()
+
+	allChecks := pgdoctor.AllChecks()
+	validOnly, invalid := pgdoctor.ValidateFilters(allChecks, defaultSchemaChecks)
+	require.Empty(t, invalid)
+
+	checks := pgdoctor.Filter(allChecks, validOnly, nil)
+	require.NotEmpty(t, checks)
+	for _, c := range checks {
+		meta := c.Metadata()
+		assert.True(t, meta.Category == check.CategorySchema || meta.CheckID == "duplicate-indexes",
+			"unexpected check %s", meta.CheckID)
+	}
+}
+
+func TestScratchServerArgs(t *testing.T) {
+	t.Parallel()
+
+	s := &scratchServer{dir: "/tmp/pgdoctor-schema-1"}
+
+	assert.Equal(t, "host=/tmp/pgdoctor-schema-1 user=postgres dbname=postgres", s.dsn())
+	assert.Contains(t, s.initdbArgs(), "--auth=trust")
+	start := s.startArgs()
+	assert.Equal(t, "start", start[len(start)-1])
+	assert.Contains(t, start, "-c listen_addresses='' -k /tmp/pgdoctor-schema-1 -c fsync=off -c full_page_writes=off")
+}
+
+func TestScratchServerMissingBinaries(t *testing.T) {
+	t.Parallel()
+
+	_, err := startScratchServer(context.Background(), filepath.Join(t.TempDir(), "missing"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "initdb")
+}
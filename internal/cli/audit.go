@@ -0,0 +1,93 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/user"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/fresha/pgdoctor/check"
+)
+
+// auditTableDDL creates the audit table on first use. db_user and client_addr
+// default to the server's view of the session so they cannot be spoofed by the client.
+var auditTableDDL = []string{
+	`CREATE SCHEMA IF NOT EXISTS pgdoctor`,
+	`CREATE TABLE IF NOT EXISTS pgdoctor.audit_runs (
+  id bigint GENERATED ALWAYS AS IDENTITY PRIMARY KEY
+  , started_at timestamptz NOT NULL
+  , duration_ms bigint NOT NULL
+  , db_user text NOT NULL DEFAULT current_user
+  , client_addr inet DEFAULT inet_client_addr()
+  , os_user text
+  , client_host text
+  , pgdoctor_version text
+  , checks_run integer NOT NULL
+  , severity text NOT NULL
+  , fail_count integer NOT NULL
+  , warn_count integer NOT NULL
+)`,
+}
+
+const auditInsert = `INSERT INTO pgdoctor.audit_runs (
+  started_at, duration_ms, os_user, client_host, pgdoctor_version, checks_run, severity, fail_count, warn_count
+) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`
+
+// auditRun is the summary of one run recorded by --audit-log.
+type auditRun struct {
+	startedAt  time.Time
+	duration   time.Duration
+	osUser     string
+	clientHost string
+	version    string
+	checksRun  int
+	severity   check.Severity
+	failCount  int
+	warnCount  int
+}
+
+func newAuditRun(startedAt time.Time, version string, reports []*check.Report) auditRun {
+	run := auditRun{
+		startedAt: startedAt,
+		duration:  time.Since(startedAt),
+		version:   version,
+		checksRun: len(reports),
+		severity:  check.SeverityOK,
+	}
+
+	if u, err := user.Current(); err == nil {
+		run.osUser = u.Username
+	}
+	run.clientHost, _ = os.Hostname()
+
+	for _, r := range reports {
+		run.severity = max(run.severity, r.Severity)
+		switch r.Severity {
+		case check.SeverityFail:
+			run.failCount++
+		case check.SeverityWarn:
+			run.warnCount++
+		}
+	}
+	return run
+}
+
+// writeAuditRun appends the run summary to pgdoctor.audit_runs on the target.
+func writeAuditRun(ctx context.Context, conn *pgx.Conn, run auditRun) error {
+	for _, stmt := range auditTableDDL {
+		if _, err := conn.Exec(ctx, stmt); err != nil {
+			return fmt.Errorf("creating audit table: %w", err)
+		}
+	}
+
+	_, err := conn.Exec(ctx, auditInsert,
+		run.startedAt, run.duration.Milliseconds(), run.osUser, run.clientHost, run.version,
+		run.checksRun, run.severity.String(), run.failCount, run.warnCount)
+	if err != nil {
+		return fmt.Errorf("recording audit run: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,30 @@
+package cli
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/fresha/pgdoctor/check"
+)
+
+func TestNewAuditRun(t *testing.T) {
+	t.Parallel()
+
+	reports := []*check.Report{
+		{Severity: check.SeverityOK},
+		{Severity: check.SeverityWarn},
+		{Severity: check.SeverityFail},
+		{Severity: check.SeveritySkip},
+	}
+
+	run := newAuditRun(time.Now().Add(-2*time.Second), "v1.2.3", reports)
+
+	assert.Equal(t, 4, run.checksRun)
+	assert.Equal(t, check.SeverityFail, run.severity)
+	assert.Equal(t, 1, run.failCount)
+	assert.Equal(t, 1, run.warnCount)
+	assert.Equal(t, "v1.2.3", run.version)
+	assert.GreaterOrEqual(t, run.duration, 2*time.Second)
+}
@@ -0,0 +1,202 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/fresha/pgdoctor"
+	"github.com/fresha/pgdoctor/check"
+	"github.com/fresha/pgdoctor/internal/dsn"
+)
+
+// calibrateHeadroom is how far above the observed steady state a proposed
+// WARN threshold sits.
+const calibrateHeadroom = 1.2
+
+type calibrateOptions struct {
+	connectionFlags
+	configPath string
+	samples    int
+	interval   time.Duration
+}
+
+func newCalibrateCommand() *cobra.Command {
+	opts := &calibrateOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "calibrate [DSN]",
+		Short: "Propose WARN thresholds from a database's current metric values",
+		Long: `Run the checks in observation-only mode, record the metric values each
+configurable threshold is compared against, and print a checks section for
+pgdoctor.yaml that raises WARN thresholds slightly above the observed steady
+state. Values already at FAIL level are reported but never calibrated away.
+
+Take several samples with --samples and --interval so a momentary dip does not
+hide the usual peak.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if opts.samples < 1 {
+				return fmt.Errorf("--samples must be at least 1")
+			}
+
+			cfg, err := loadConfig(opts.configPath)
+			if err != nil {
+				return err
+			}
+
+			connString, err := resolveDSN(args, cfg)
+			if err != nil {
+				return err
+			}
+
+			allChecks := pgdoctor.AllChecks()
+			only, ignored, err := validateFilters(allChecks, cfg.Only, cfg.Ignore)
+			if err != nil {
+				return err
+			}
+
+			ctx := cmd.Context()
+			conn, _, closeConn, err := openConnection(ctx, connString, opts.connectionFlags)
+			if err != nil {
+				return err
+			}
+			defer closeConn()
+
+			calibrations := map[string]*calibration{}
+			ctx = check.ContextWithObserver(ctx, func(o check.Observation) {
+				key := o.CheckID + "/" + o.Key
+				if c, ok := calibrations[key]; ok {
+					c.peak = max(c.peak, o.Value)
+					c.samples++
+					return
+				}
+				calibrations[key] = &calibration{Observation: o, peak: o.Value, samples: 1}
+			})
+
+			runOpts := pgdoctor.Options{
+				Checks:        pgdoctor.Filter(allChecks, only, ignored),
+				Config:        cfg.Checks,
+				IgnoreObjects: cfg.ObjectFilter(),
+			}
+
+			for i := range opts.samples {
+				if i > 0 {
+					select {
+					case <-ctx.Done():
+						return ctx.Err()
+					case <-time.After(opts.interval):
+					}
+				}
+				fmt.Fprintf(os.Stderr, "Sample %d/%d...\n", i+1, opts.samples)
+				pgdoctor.Run(ctx, conn, runOpts)
+			}
+
+			results := make([]*calibration, 0, len(calibrations))
+			for _, c := range calibrations {
+				c.propose()
+				results = append(results, c)
+			}
+			sort.Slice(results, func(i, j int) bool {
+				if results[i].CheckID != results[j].CheckID {
+					return results[i].CheckID < results[j].CheckID
+				}
+				return results[i].Key < results[j].Key
+			})
+
+			writeCalibration(cmd.OutOrStdout(), dsn.Label(connString), opts.samples, results)
+			return nil
+		},
+	}
+
+	addConnectionFlags(cmd, &opts.connectionFlags)
+	addConfigFlag(cmd, &opts.configPath)
+	cmd.Flags().IntVar(&opts.samples, "samples", 1, "Number of times to run the checks")
+	cmd.Flags().DurationVar(&opts.interval, "interval", time.Minute, "Time between samples")
+
+	return cmd
+}
+
+// calibration is the peak value observed for one configurable threshold.
+type calibration struct {
+	check.Observation
+	peak    float64
+	samples int
+
+	proposed float64 // zero when the current threshold should be kept
+	note     string
+}
+
+// propose sets a WARN threshold just above the peak, staying below FAIL so
+// calibration can quiet baseline noise but never hide a failing metric.
+func (c *calibration) propose() {
+	switch {
+	case c.peak >= c.Fail:
+		c.note = fmt.Sprintf("observed %s is at FAIL level (%s); fix it rather than raising WARN",
+			formatThreshold(c.peak), formatThreshold(c.Fail))
+	case c.peak < c.Warn:
+		c.note = fmt.Sprintf("observed %s is below WARN %s; keep the current threshold",
+			formatThreshold(c.peak), formatThreshold(c.Warn))
+	default:
+		midpoint := (c.peak + c.Fail) / 2
+		proposed := roundUpSignificant(min(c.peak*calibrateHeadroom, midpoint), 2)
+		if proposed >= c.Fail {
+			proposed = midpoint
+		}
+		c.proposed = proposed
+		c.note = fmt.Sprintf("observed %s, was %s, FAIL at %s",
+			formatThreshold(c.peak), formatThreshold(c.Warn), formatThreshold(c.Fail))
+	}
+}
+
+// writeCalibration prints the proposals as a checks section for pgdoctor.yaml.
+// Thresholds that should be kept are listed as comments for review.
+func writeCalibration(w io.Writer, dbLabel string, samples int, results []*calibration) {
+	fmt.Fprintf(w, "# Thresholds proposed by pgdoctor calibrate for %s (%d sample(s)).\n", dbLabel, samples)
+	fmt.Fprintf(w, "# Review, then merge into the checks section of pgdoctor.yaml.\n")
+
+	var proposals []*calibration
+	for _, c := range results {
+		if c.proposed == 0 {
+			fmt.Fprintf(w, "#   %s %s: %s\n", c.CheckID, c.Key, c.note)
+			continue
+		}
+		proposals = append(proposals, c)
+	}
+
+	if len(proposals) == 0 {
+		fmt.Fprintln(w, "# No WARN thresholds need raising.")
+		return
+	}
+
+	fmt.Fprintln(w, "checks:")
+	var currentCheck string
+	for _, c := range proposals {
+		if c.CheckID != currentCheck {
+			fmt.Fprintf(w, "  %s:\n", c.CheckID)
+			currentCheck = c.CheckID
+		}
+		fmt.Fprintf(w, "    %s: %q  # %s\n", c.Key, formatThreshold(c.proposed), c.note)
+	}
+}
+
+func formatThreshold(v float64) string {
+	return strconv.FormatFloat(v, 'g', 4, 64)
+}
+
+// roundUpSignificant rounds v up to the given number of significant digits,
+// so proposals read as 0.6 or 83 rather than 0.5999 or 82.44.
+func roundUpSignificant(v float64, digits int) float64 {
+	if v <= 0 {
+		return v
+	}
+	scale := math.Pow(10, math.Floor(math.Log10(v))-float64(digits-1))
+	// Trim floating-point noise so exact values are not bumped up a step.
+	return math.Ceil(math.Round(v/scale*1e6)/1e6) * scale
+}
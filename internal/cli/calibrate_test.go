@@ -0,0 +1,72 @@
+package cli
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+
+	"github.com/fresha/pgdoctor/check"
+)
+
+func TestCalibrationPropose(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		peak     float64
+		warn     float64
+		fail     float64
+		proposed float64
+	}{
+		{name: "above warn gets headroom", peak: 0.5, warn: 0.25, fail: 1, proposed: 0.6},
+		{name: "headroom capped below fail", peak: 80, warn: 70, fail: 85, proposed: 83},
+		{name: "rounding never reaches fail", peak: 84, warn: 70, fail: 85, proposed: 84.5},
+		{name: "below warn is kept", peak: 40, warn: 70, fail: 85, proposed: 0},
+		{name: "at fail is not calibrated", peak: 92, warn: 75, fail: 90, proposed: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			c := &calibration{Observation: check.Observation{Warn: tt.warn, Fail: tt.fail}, peak: tt.peak}
+			c.propose()
+			assert.InDelta(t, tt.proposed, c.proposed, 1e-9)
+			assert.NotEmpty(t, c.note)
+		})
+	}
+}
+
+func TestWriteCalibration_LoadsAsConfig(t *testing.T) {
+	t.Parallel()
+
+	results := []*calibration{
+		{Observation: check.Observation{CheckID: "connection-health", Key: "saturation_warn_percent", Warn: 70, Fail: 85}, peak: 40},
+		{Observation: check.Observation{CheckID: "replication-lag", Key: "physical_warn_seconds", Warn: 0.25, Fail: 1}, peak: 0.5},
+	}
+	for _, c := range results {
+		c.propose()
+	}
+
+	var out bytes.Buffer
+	writeCalibration(&out, "db/app", 3, results)
+
+	assert.Contains(t, out.String(), "#   connection-health saturation_warn_percent: observed 40 is below WARN 70")
+
+	var parsed struct {
+		Checks check.Config `yaml:"checks"`
+	}
+	require.NoError(t, yaml.Unmarshal(out.Bytes(), &parsed))
+	assert.Equal(t, check.Config{"replication-lag": {"physical_warn_seconds": "0.6"}}, parsed.Checks)
+}
+
+func TestRoundUpSignificant(t *testing.T) {
+	t.Parallel()
+
+	assert.InDelta(t, 0.6, roundUpSignificant(0.6, 2), 1e-9)
+	assert.InDelta(t, 83.0, roundUpSignificant(82.44, 2), 1e-9)
+	assert.InDelta(t, 1300.0, roundUpSignificant(1234, 2), 1e-9)
+}
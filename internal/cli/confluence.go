@@ -0,0 +1,154 @@
+package cli
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"strings"
+
+	"github.com/fresha/pgdoctor/check"
+	"github.com/fresha/pgdoctor/internal/buildinfo"
+	"github.com/fresha/pgdoctor/internal/report"
+)
+
+// formatConfluence renders the report in Confluence storage format, the
+// XHTML dialect the Confluence REST API accepts as a page body. Severities
+// are status lozenges, SQL goes in code macros, and each check and finding
+// gets an anchor macro with the same id as the markdown report.
+func formatConfluence(w io.Writer, title, connection string, reports []*check.Report) error {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "<h1>Database Health Report: %s</h1>\n", html.EscapeString(title))
+	if connection != "" {
+		fmt.Fprintf(&b, "<p>Connection: %s</p>\n", html.EscapeString(connection))
+	}
+	fmt.Fprintf(&b, "<p>Generated by pgdoctor %s</p>\n", html.EscapeString(buildinfo.Get().String()))
+	writeConfluenceRisks(&b, report.TopRisks(reports, report.DefaultRiskLimit))
+	b.WriteString(`<ac:structured-macro ac:name="toc"><ac:parameter ac:name="maxLevel">3</ac:parameter></ac:structured-macro>` + "\n")
+
+	order, grouped := reportsByCategory(reports)
+	for _, cat := range order {
+		writeConfluenceAnchor(&b, categoryAnchor(cat))
+		fmt.Fprintf(&b, "<h2>%s</h2>\n", html.EscapeString(string(cat)))
+
+		for _, r := range grouped[cat] {
+			writeConfluenceAnchor(&b, anchorID(r.CheckID, ""))
+			fmt.Fprintf(&b, "<h3>%s %s (<code>%s</code>)</h3>\n",
+				confluenceStatus(r.Severity), html.EscapeString(r.Name), html.EscapeString(r.CheckID))
+
+			for _, f := range r.Results {
+				if f.ID != r.CheckID {
+					writeConfluenceAnchor(&b, anchorID(r.CheckID, f.ID))
+					fmt.Fprintf(&b, "<h4>%s %s (<code>%s</code>)</h4>\n",
+						confluenceStatus(f.Severity), html.EscapeString(f.Name), html.EscapeString(anchorID(r.CheckID, f.ID)))
+				}
+
+				if f.Details != "" {
+					fmt.Fprintf(&b, "<pre>%s</pre>\n", html.EscapeString(strings.TrimRight(f.Details, "\n")))
+				}
+
+				if f.Severity > check.SeverityOK && f.Confidence != check.ConfidenceHigh {
+					fmt.Fprintf(&b, "<p><em>Confidence: %s. Verify before acting.</em></p>\n", f.Confidence)
+				}
+
+				if f.Severity > check.SeverityOK && f.DocsURL != "" {
+					fmt.Fprintf(&b, "<p><a href=\"%s\">How to fix</a></p>\n", html.EscapeString(f.DocsURL))
+				}
+
+				if f.Table != nil && len(f.Table.Rows) > 0 {
+					writeConfluenceTable(&b, f.Table)
+				}
+
+				if f.Severity > check.SeverityOK {
+					writeConfluenceFixes(&b, f.Fixes)
+				}
+			}
+		}
+	}
+
+	if _, err := io.WriteString(w, b.String()); err != nil {
+		return fmt.Errorf("writing confluence report: %w", err)
+	}
+	return nil
+}
+
+// writeConfluenceRisks writes the executive summary: the top risks, worst
+// first, each linking to its finding's anchor.
+func writeConfluenceRisks(b *strings.Builder, risks []report.Risk) {
+	b.WriteString("<h2>Top Risks</h2>\n")
+	if len(risks) == 0 {
+		b.WriteString("<p>No warnings or failures: every check passed or was skipped.</p>\n")
+		return
+	}
+	b.WriteString("<ol>\n")
+	for _, risk := range risks {
+		fmt.Fprintf(b, `<li>%s <ac:link ac:anchor="%s"><ac:plain-text-link-body><![CDATA[%s]]></ac:plain-text-link-body></ac:link>: %s</li>`+"\n",
+			confluenceStatus(risk.Severity), html.EscapeString(anchorID(risk.CheckID, risk.FindingID)),
+			strings.ReplaceAll(risk.Name, "]]>", "]]]]><![CDATA[>"), html.EscapeString(risk.Impact))
+	}
+	b.WriteString("</ol>\n")
+}
+
+func writeConfluenceAnchor(b *strings.Builder, id string) {
+	fmt.Fprintf(b, `<ac:structured-macro ac:name="anchor"><ac:parameter ac:name="">%s</ac:parameter></ac:structured-macro>`+"\n",
+		html.EscapeString(id))
+}
+
+// confluenceStatus renders a severity as a status lozenge.
+func confluenceStatus(s check.Severity) string {
+	colour := "Grey"
+	switch s {
+	case check.SeverityOK:
+		colour = "Green"
+	case check.SeverityWarn:
+		colour = "Yellow"
+	case check.SeverityFail:
+		colour = "Red"
+	}
+	return fmt.Sprintf(`<ac:structured-macro ac:name="status"><ac:parameter ac:name="colour">%s</ac:parameter><ac:parameter ac:name="title">%s</ac:parameter></ac:structured-macro>`,
+		colour, strings.ToUpper(s.String()))
+}
+
+func writeConfluenceFixes(b *strings.Builder, fixes []check.Fix) {
+	now, window := splitFixesByLock(fixes)
+	for _, group := range []struct {
+		title string
+		fixes []check.Fix
+	}{
+		{"Safe to run now", now},
+		{"Requires a maintenance window", window},
+	} {
+		if len(group.fixes) == 0 {
+			continue
+		}
+		var sql strings.Builder
+		for _, fix := range group.fixes {
+			sql.WriteString(fixStatement(fix) + "\n")
+		}
+		fmt.Fprintf(b, "<p><strong>%s:</strong></p>\n", group.title)
+		fmt.Fprintf(b, `<ac:structured-macro ac:name="code"><ac:parameter ac:name="language">sql</ac:parameter><ac:plain-text-body><![CDATA[%s]]></ac:plain-text-body></ac:structured-macro>`+"\n",
+			cdata(sql.String()))
+	}
+}
+
+// cdata keeps a "]]>" in the text from closing the CDATA section early.
+func cdata(s string) string {
+	return strings.ReplaceAll(s, "]]>", "]]]]><![CDATA[>")
+}
+
+func writeConfluenceTable(b *strings.Builder, table *check.Table) {
+	b.WriteString("<table><tbody>\n<tr>")
+	for _, h := range table.Headers {
+		fmt.Fprintf(b, "<th>%s</th>", html.EscapeString(h))
+	}
+	b.WriteString("</tr>\n")
+
+	for _, row := range table.Rows {
+		b.WriteString("<tr>")
+		for _, cell := range row.Cells {
+			fmt.Fprintf(b, "<td>%s</td>", html.EscapeString(cell))
+		}
+		b.WriteString("</tr>\n")
+	}
+	b.WriteString("</tbody></table>\n")
+}
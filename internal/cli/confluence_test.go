@@ -0,0 +1,65 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/fresha/pgdoctor/check"
+)
+
+func TestFormatConfluence(t *testing.T) {
+	t.Parallel()
+
+	seq := check.NewReport(check.Metadata{CheckID: "sequence-health", Name: "Sequence Health", Category: check.CategorySchema})
+	seq.AddFinding(check.Finding{
+		ID:         "near-exhaustion",
+		Name:       "Sequences Near Exhaustion",
+		Severity:   check.SeverityFail,
+		Details:    "1 sequence above 90% & <rising>",
+		Confidence: check.ConfidenceMedium,
+		Table: &check.Table{
+			Headers: []string{"Sequence", "Usage"},
+			Rows:    []check.TableRow{{Cells: []string{"public.orders_id_seq", "95%"}, Severity: check.SeverityFail}},
+		},
+		Fixes: []check.Fix{
+			{SQL: `ALTER SEQUENCE "public"."orders_id_seq" AS bigint`, Risk: check.RiskLow, Lock: check.LockOnline},
+			{SQL: `SELECT ']]>'`, Risk: check.RiskHigh, Lock: check.LockExclusive},
+		},
+	})
+
+	ver := check.NewReport(check.Metadata{CheckID: "pg-version", Name: "PG Version", Category: check.CategoryConfigs})
+	ver.AddFinding(check.Finding{ID: "pg-version", Name: "PG Version", Severity: check.SeverityOK})
+
+	var buf bytes.Buffer
+	require.NoError(t, formatConfluence(&buf, "db.internal/app", "tcp db.internal:5432", []*check.Report{ver, seq}))
+	out := buf.String()
+
+	assert.Contains(t, out, "<h1>Database Health Report: db.internal/app</h1>")
+	assert.Contains(t, out, `<ac:parameter ac:name="">sequence-health/near-exhaustion</ac:parameter>`)
+	assert.Contains(t, out, `<ac:parameter ac:name="colour">Red</ac:parameter><ac:parameter ac:name="title">FAIL</ac:parameter>`)
+	assert.Contains(t, out, "<pre>1 sequence above 90% &amp; &lt;rising&gt;</pre>")
+	assert.Contains(t, out, `<ac:link ac:anchor="sequence-health/near-exhaustion"><ac:plain-text-link-body><![CDATA[Sequences Near Exhaustion]]></ac:plain-text-link-body></ac:link>: 1 sequence above 90% &amp; &lt;rising&gt;</li>`)
+	assert.Contains(t, out, "<tr><td>public.orders_id_seq</td><td>95%</td></tr>")
+	assert.Contains(t, out, "<em>Confidence: medium. Verify before acting.</em>")
+	assert.Contains(t, out, "<p><strong>Safe to run now:</strong></p>")
+	assert.Contains(t, out, `<![CDATA[ALTER SEQUENCE "public"."orders_id_seq" AS bigint;`)
+	assert.Contains(t, out, `SELECT ']]]]><![CDATA[>';  -- high risk`)
+	assert.Less(t, strings.Index(out, "<h2>configs</h2>"), strings.Index(out, "<h2>schema</h2>"))
+
+	// Storage format is XML with the ac: namespace; the page must parse.
+	doc := `<root xmlns:ac="http://atlassian.com/content">` + out + `</root>`
+	dec := xml.NewDecoder(strings.NewReader(doc))
+	for {
+		_, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+	}
+}
@@ -0,0 +1,204 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/spf13/cobra"
+
+	"github.com/fresha/pgdoctor"
+	"github.com/fresha/pgdoctor/internal/dsn"
+	"github.com/fresha/pgdoctor/internal/sshtunnel"
+)
+
+const (
+	// defaultCloudSQLSocketDir is where the Cloud SQL Auth Proxy creates
+	// per-instance socket directories when started with --unix-socket.
+	defaultCloudSQLSocketDir = "/cloudsql"
+
+	// rdsProxyKeepAlive keeps TCP keepalives well inside the idle timeouts of
+	// RDS Proxy and the load balancers in front of it, so a long check run
+	// is not silently dropped between queries.
+	rdsProxyKeepAlive = 30 * time.Second
+)
+
+// connectionFlags are the connection options shared by every command that
+// talks to a database.
+type connectionFlags struct {
+	sshTarget string
+	sshKey    string
+	sshKnown  string
+	socketDir string
+	cloudSQL  string
+}
+
+func addConnectionFlags(cmd *cobra.Command, f *connectionFlags) {
+	cmd.Flags().StringVar(&f.sshTarget, "ssh", "", "Connect through an SSH bastion (user@host[:port])")
+	cmd.Flags().StringVar(&f.sshKey, "ssh-key", "", "Private key for --ssh (default: use ssh-agent)")
+	cmd.Flags().StringVar(&f.sshKnown, "ssh-known-hosts", "", "known_hosts file for --ssh (default: ~/.ssh/known_hosts)")
+	cmd.Flags().StringVar(&f.socketDir, "socket-dir", "", "Connect over the unix socket in this directory (overrides the DSN host)")
+	cmd.Flags().StringVar(&f.cloudSQL, "cloudsql-instance", "", "Connect through the Cloud SQL Auth Proxy socket for project:region:instance")
+}
+
+// openConnection connects to connString with the socket, proxy, and SSH options
+// applied and pgdoctor's statement_timeout set. It returns the connection
+// path for report headers and a function that closes the connection and any
+// tunnel. Failures are printed and returned as a SilentError with exit code 2.
+func openConnection(ctx context.Context, connString string, f connectionFlags) (*pgx.Conn, string, func(), error) {
+	conn, connPath, closeConn, err := connect(ctx, connString, f)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return nil, "", nil, &SilentError{ExitCode: 2}
+	}
+	return conn, connPath, closeConn, nil
+}
+
+// connect is openConnection without the printing, for callers that report
+// connection failures themselves. Errors never include the password.
+func connect(ctx context.Context, connString string, f connectionFlags) (*pgx.Conn, string, func(), error) {
+	connConfig, err := pgx.ParseConfig(connString)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("invalid connection string: %s", dsn.Scrub(err.Error()))
+	}
+
+	connPath, err := configureConnection(connConfig, connectOptions{
+		socketDir:        f.socketDir,
+		cloudSQLInstance: f.cloudSQL,
+		viaSSH:           f.sshTarget != "",
+	})
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	closeTunnel := func() {}
+	if f.sshTarget != "" {
+		connPath += " via SSH " + f.sshTarget
+		tunnel, err := sshtunnel.Open(ctx, sshtunnel.Options{
+			Target:         f.sshTarget,
+			KeyFile:        f.sshKey,
+			KnownHostsFile: f.sshKnown,
+		})
+		if err != nil {
+			return nil, "", nil, fmt.Errorf("failed to open ssh tunnel: %w", err)
+		}
+		closeTunnel = func() { _ = tunnel.Close() }
+		connConfig.DialFunc = tunnel.DialContext
+	}
+
+	conn, err := pgx.ConnectConfig(ctx, connConfig)
+	if err != nil {
+		closeTunnel()
+		return nil, "", nil, fmt.Errorf("failed to connect to %s: %s", dsn.RedactConfig(&connConfig.Config), dsn.Scrub(err.Error()))
+	}
+
+	closeConn := func() {
+		_ = conn.Close(ctx)
+		closeTunnel()
+	}
+
+	// Set statement_timeout so PostgreSQL kills individual slow queries.
+	if _, err := conn.Exec(ctx, fmt.Sprintf("SET statement_timeout = %d", pgdoctor.DefaultStatementTimeoutMs)); err != nil {
+		closeConn()
+		return nil, "", nil, fmt.Errorf("failed to set statement_timeout: %w", err)
+	}
+
+	return conn, connPath, closeConn, nil
+}
+
+type connectOptions struct {
+	socketDir        string
+	cloudSQLInstance string
+	viaSSH           bool
+}
+
+// configureConnection applies socket and proxy options to a parsed connection
+// config and returns a description of the connection path for the report header.
+func configureConnection(cfg *pgx.ConnConfig, opts connectOptions) (string, error) {
+	if opts.cloudSQLInstance != "" {
+		if err := validateCloudSQLInstance(opts.cloudSQLInstance); err != nil {
+			return "", err
+		}
+		root := opts.socketDir
+		if root == "" {
+			root = defaultCloudSQLSocketDir
+		}
+		opts.socketDir = filepath.Join(root, opts.cloudSQLInstance)
+	}
+
+	if opts.socketDir != "" {
+		if opts.viaSSH {
+			return "", errors.New("--ssh cannot be combined with a unix socket connection")
+		}
+		cfg.Host = opts.socketDir
+		cfg.Fallbacks = nil
+	}
+
+	if isUnixSocket(cfg.Host) {
+		// TLS is never negotiated over unix sockets.
+		cfg.TLSConfig = nil
+
+		socket := filepath.Join(cfg.Host, fmt.Sprintf(".s.PGSQL.%d", cfg.Port))
+		if _, err := os.Stat(socket); err != nil {
+			if opts.cloudSQLInstance != "" {
+				return "", fmt.Errorf("no socket for %s at %s: is the Cloud SQL Auth Proxy running with --unix-socket?", opts.cloudSQLInstance, socket)
+			}
+			return "", fmt.Errorf("no PostgreSQL socket at %s: %w", socket, err)
+		}
+
+		if opts.cloudSQLInstance != "" {
+			return fmt.Sprintf("Cloud SQL Auth Proxy socket %s", cfg.Host), nil
+		}
+		return fmt.Sprintf("unix socket %s", cfg.Host), nil
+	}
+
+	addr := net.JoinHostPort(cfg.Host, fmt.Sprint(cfg.Port))
+
+	if isRDSProxyHost(cfg.Host) {
+		if !opts.viaSSH {
+			dialer := &net.Dialer{KeepAlive: rdsProxyKeepAlive}
+			cfg.DialFunc = dialer.DialContext
+		}
+		if cfg.TLSConfig == nil {
+			fmt.Fprintf(os.Stderr, "Warning: RDS Proxy endpoints usually require TLS; connection uses sslmode=disable\n")
+		}
+		return fmt.Sprintf("RDS Proxy %s", addr), nil
+	}
+
+	if cfg.TLSConfig != nil {
+		return fmt.Sprintf("tcp %s (TLS)", addr), nil
+	}
+	return fmt.Sprintf("tcp %s", addr), nil
+}
+
+// validateCloudSQLInstance checks the project:region:instance connection name form.
+func validateCloudSQLInstance(name string) error {
+	parts := strings.Split(name, ":")
+	// Legacy domain-scoped projects look like example.com:project:region:instance.
+	if len(parts) != 3 && len(parts) != 4 {
+		return fmt.Errorf("invalid Cloud SQL instance %q: expected project:region:instance", name)
+	}
+	for _, p := range parts {
+		if p == "" {
+			return fmt.Errorf("invalid Cloud SQL instance %q: expected project:region:instance", name)
+		}
+	}
+	return nil
+}
+
+func isUnixSocket(host string) bool {
+	return strings.HasPrefix(host, "/")
+}
+
+// isRDSProxyHost matches RDS Proxy endpoints such as
+// my-proxy.proxy-abc123.eu-west-1.rds.amazonaws.com.
+func isRDSProxyHost(host string) bool {
+	host = strings.ToLower(host)
+	return strings.Contains(host, ".proxy-") && strings.HasSuffix(host, ".rds.amazonaws.com")
+}
@@ -0,0 +1,93 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigureConnection_TCP(t *testing.T) {
+	t.Parallel()
+
+	cfg, err := pgx.ParseConfig("postgres://u:p@db.internal:5433/app?sslmode=disable")
+	require.NoError(t, err)
+
+	path, err := configureConnection(cfg, connectOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "tcp db.internal:5433", path)
+}
+
+func TestConfigureConnection_RDSProxy(t *testing.T) {
+	t.Parallel()
+
+	cfg, err := pgx.ParseConfig("postgres://u:p@app.proxy-abc123.eu-west-1.rds.amazonaws.com:5432/app?sslmode=require")
+	require.NoError(t, err)
+
+	path, err := configureConnection(cfg, connectOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "RDS Proxy app.proxy-abc123.eu-west-1.rds.amazonaws.com:5432", path)
+	assert.NotNil(t, cfg.DialFunc)
+}
+
+func TestConfigureConnection_SocketDir(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".s.PGSQL.5432"), nil, 0o600))
+
+	cfg, err := pgx.ParseConfig("postgres://u:p@db.internal:5432/app")
+	require.NoError(t, err)
+
+	path, err := configureConnection(cfg, connectOptions{socketDir: dir})
+	require.NoError(t, err)
+	assert.Equal(t, "unix socket "+dir, path)
+	assert.Equal(t, dir, cfg.Host)
+	assert.Nil(t, cfg.TLSConfig)
+}
+
+func TestConfigureConnection_CloudSQL(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	instanceDir := filepath.Join(root, "proj:europe-west1:main")
+	require.NoError(t, os.Mkdir(instanceDir, 0o700))
+	require.NoError(t, os.WriteFile(filepath.Join(instanceDir, ".s.PGSQL.5432"), nil, 0o600))
+
+	cfg, err := pgx.ParseConfig("postgres://u:p@localhost/app")
+	require.NoError(t, err)
+
+	path, err := configureConnection(cfg, connectOptions{socketDir: root, cloudSQLInstance: "proj:europe-west1:main"})
+	require.NoError(t, err)
+	assert.Equal(t, "Cloud SQL Auth Proxy socket "+instanceDir, path)
+}
+
+func TestConfigureConnection_Errors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		opts connectOptions
+		want string
+	}{
+		{name: "malformed instance", opts: connectOptions{cloudSQLInstance: "proj-main"}, want: "expected project:region:instance"},
+		{name: "missing socket", opts: connectOptions{socketDir: t.TempDir()}, want: "no PostgreSQL socket"},
+		{name: "socket with ssh", opts: connectOptions{socketDir: "/tmp", viaSSH: true}, want: "--ssh cannot be combined"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			cfg, err := pgx.ParseConfig("postgres://u:p@db.internal/app")
+			require.NoError(t, err)
+
+			_, err = configureConnection(cfg, tt.opts)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.want)
+		})
+	}
+}
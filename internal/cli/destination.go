@@ -0,0 +1,26 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fresha/pgdoctor/internal/storage"
+)
+
+// parseOutput splits --output into a format and an optional destination.
+// Bare values (text, json, markdown, confluence, html, sarif) select a format
+// written to stdout; any value with a path separator or extension is a
+// destination whose format is taken from its extension (.json, .md, .xhtml,
+// .html, or .sarif, optionally followed by .gz). confluence://page-id
+// publishes to a page.
+func parseOutput(output string) (string, *storage.Destination, error) {
+	if !strings.ContainsAny(output, "/.") {
+		return output, nil, nil
+	}
+
+	dest, err := storage.ParseDestination(output)
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid --output: %w", err)
+	}
+	return dest.Format, dest, nil
+}
@@ -0,0 +1,63 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseOutput(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		output  string
+		format  string
+		dest    bool
+		gzip    bool
+		bucket  string
+		scheme  string
+		wantErr bool
+	}{
+		{output: "text", format: "text"},
+		{output: "json", format: "json"},
+		{output: "markdown", format: "markdown"},
+		{output: "report.json", format: "json", dest: true, scheme: "file"},
+		{output: "out/report.md.gz", format: "markdown", dest: true, gzip: true, scheme: "file"},
+		{output: "s3://ops/pgdoctor/run-{timestamp}.json.gz", format: "json", dest: true, gzip: true, scheme: "s3", bucket: "ops"},
+		{output: "gs://ops/run.md", format: "markdown", dest: true, scheme: "gs", bucket: "ops"},
+		{output: "confluence", format: "confluence"},
+		{output: "reports/weekly.xhtml", format: "confluence", dest: true, scheme: "file"},
+		{output: "confluence://123456", format: "confluence", dest: true, scheme: "confluence"},
+		{output: "html", format: "html"},
+		{output: "s3://ops/incident-{date}.html.gz", format: "html", dest: true, gzip: true, scheme: "s3", bucket: "ops"},
+		{output: "sarif", format: "sarif"},
+		{output: "results/pgdoctor.sarif", format: "sarif", dest: true, scheme: "file"},
+		{output: "confluence://spaces/OPS", wantErr: true},
+		{output: "s3://ops/run.txt", wantErr: true},
+		{output: "report.gz", wantErr: true},
+		{output: "ftp://host/run.json", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.output, func(t *testing.T) {
+			t.Parallel()
+
+			format, dest, err := parseOutput(tt.output)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.format, format)
+			if !tt.dest {
+				assert.Nil(t, dest)
+				return
+			}
+			require.NotNil(t, dest)
+			assert.Equal(t, tt.gzip, dest.Gzip)
+			assert.Equal(t, tt.scheme, dest.Location.Scheme)
+			assert.Equal(t, tt.bucket, dest.Location.Bucket)
+		})
+	}
+}
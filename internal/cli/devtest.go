@@ -0,0 +1,289 @@
+//go:build devtest
+
+package cli
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/spf13/cobra"
+
+	"github.com/fresha/pgdoctor"
+	"github.com/fresha/pgdoctor/check"
+)
+
+//go:embed devtest.sql
+var devtestFixtures string
+
+func init() {
+	devCommands = append(devCommands, newDevtestCommand)
+}
+
+// devtestExpected lists findings the fixtures set up. Each must come out
+// WARN or FAIL on every version.
+var devtestExpected = []string{
+	"duplicate-indexes/exact-duplicates",
+	"sequence-health/near-exhaustion",
+	"table-vacuum-health/autovacuum-disabled",
+}
+
+// incompatibleSQLStates are the errors a query written for another
+// PostgreSQL version raises: a column, table, or function that does not
+// exist on this one. A check skipped with one of them picked the wrong
+// version-gated query.
+var incompatibleSQLStates = []string{
+	"SQLSTATE 42703", // undefined_column
+	"SQLSTATE 42P01", // undefined_table
+	"SQLSTATE 42883", // undefined_function
+}
+
+type devtestOptions struct {
+	versions []string
+	image    string
+	timeout  time.Duration
+	keep     bool
+}
+
+func newDevtestCommand() *cobra.Command {
+	opts := &devtestOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "devtest",
+		Short: "Run the check suite against PostgreSQL containers, one per version",
+		Long: `Start a PostgreSQL container for each --pg-versions entry with Docker,
+load fixture scenarios, run every check, and report per version whether:
+
+  - every check's queries ran, so version-gated queries picked the right
+    variant (a skipped check with an undefined column, table, or function
+    error fails the version);
+  - the findings the fixtures set up were reported.
+
+Versions are image tags, so exact minor versions (16.4) can be tested.
+This command is only built with -tags devtest.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			w := cmd.OutOrStdout()
+			failed := 0
+			for _, version := range opts.versions {
+				image := opts.image + ":" + strings.TrimSpace(version)
+				fmt.Fprintf(w, "%s\n", image)
+
+				result, err := runDevtest(cmd.Context(), image, opts)
+				if err != nil {
+					fmt.Fprintf(w, "  %s %v\n\n", colorForSeverity(check.SeverityFail)("ERROR"), err)
+					failed++
+					continue
+				}
+				printDevtestResult(w, result)
+				if !result.ok() {
+					failed++
+				}
+			}
+
+			if failed > 0 {
+				fmt.Fprintf(w, "%d of %d version(s) failed\n", failed, len(opts.versions))
+				return &SilentError{ExitCode: 1}
+			}
+			fmt.Fprintf(w, "All %d version(s) passed\n", len(opts.versions))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringSliceVar(&opts.versions, "pg-versions", []string{"13", "14", "15", "16", "17", "18"}, "PostgreSQL image tags to test, e.g. 13,14,16.4")
+	cmd.Flags().StringVar(&opts.image, "image", "postgres", "Container image to run")
+	cmd.Flags().DurationVar(&opts.timeout, "startup-timeout", time.Minute, "How long to wait for each server to accept connections")
+	cmd.Flags().BoolVar(&opts.keep, "keep", false, "Leave containers running for inspection")
+
+	return cmd
+}
+
+// devtestResult is the outcome of the suite against one server.
+type devtestResult struct {
+	serverVersion string
+	checks        int
+	// incompatible lists checks skipped because a query does not fit the
+	// server version.
+	incompatible []string
+	// skipped lists checks skipped for other reasons, such as timeouts.
+	skipped []string
+	// missing lists expected fixture findings that were not reported.
+	missing []string
+}
+
+func (r devtestResult) ok() bool {
+	return len(r.incompatible) == 0 && len(r.missing) == 0
+}
+
+func printDevtestResult(w io.Writer, r devtestResult) {
+	status := colorForSeverity(check.SeverityOK)("PASS")
+	if !r.ok() {
+		status = colorForSeverity(check.SeverityFail)("FAIL")
+	}
+	fmt.Fprintf(w, "  %s server %s, %d checks\n", status, r.serverVersion, r.checks)
+	for _, s := range r.incompatible {
+		fmt.Fprintf(w, "    incompatible query: %s\n", s)
+	}
+	for _, s := range r.missing {
+		fmt.Fprintf(w, "    expected finding not reported: %s\n", s)
+	}
+	for _, s := range r.skipped {
+		fmt.Fprintf(w, "    %s %s\n", dimColor()("skipped:"), s)
+	}
+	fmt.Fprintln(w)
+}
+
+func runDevtest(ctx context.Context, image string, opts *devtestOptions) (devtestResult, error) {
+	c, err := startPostgresContainer(ctx, image)
+	if err != nil {
+		return devtestResult{}, err
+	}
+	if opts.keep {
+		fmt.Fprintf(os.Stderr, "Container %s left running at %s\n", c.id, c.dsn)
+	} else {
+		defer c.stop()
+	}
+
+	conn, err := waitForPostgres(ctx, c.dsn, opts.timeout)
+	if err != nil {
+		return devtestResult{}, err
+	}
+	defer conn.Close(context.Background())
+
+	if _, err := conn.Exec(ctx, devtestFixtures); err != nil {
+		return devtestResult{}, fmt.Errorf("loading fixtures: %w", err)
+	}
+	if _, err := conn.Exec(ctx, fmt.Sprintf("SET statement_timeout = %d", pgdoctor.DefaultStatementTimeoutMs)); err != nil {
+		return devtestResult{}, fmt.Errorf("setting statement_timeout: %w", err)
+	}
+	var serverVersion string
+	if err := conn.QueryRow(ctx, "SHOW server_version").Scan(&serverVersion); err != nil {
+		return devtestResult{}, fmt.Errorf("reading server version: %w", err)
+	}
+
+	var reports []*check.Report
+	pgdoctor.Run(ctx, conn, pgdoctor.Options{
+		Checks:   pgdoctor.AllChecks(),
+		OnReport: pgdoctor.Collect(&reports),
+	})
+
+	result := evaluateDevtest(reports)
+	result.serverVersion = serverVersion
+	return result, nil
+}
+
+// evaluateDevtest sorts skipped checks into version incompatibilities and
+// other skips, and lists the expected fixture findings that are missing.
+func evaluateDevtest(reports []*check.Report) devtestResult {
+	result := devtestResult{checks: len(reports)}
+	reported := map[string]bool{}
+	for _, r := range reports {
+		for _, f := range r.Results {
+			if f.Severity > check.SeverityOK {
+				reported[r.CheckID+"/"+f.ID] = true
+			}
+			if f.Severity != check.SeveritySkip {
+				continue
+			}
+			entry := r.CheckID + ": " + f.Details
+			if isIncompatibleQuery(f.Details) {
+				result.incompatible = append(result.incompatible, entry)
+			} else {
+				result.skipped = append(result.skipped, entry)
+			}
+		}
+	}
+	for _, id := range devtestExpected {
+		if !reported[id] {
+			result.missing = append(result.missing, id)
+		}
+	}
+	return result
+}
+
+func isIncompatibleQuery(details string) bool {
+	for _, state := range incompatibleSQLStates {
+		if strings.Contains(details, state) {
+			return true
+		}
+	}
+	return false
+}
+
+// postgresContainer is a throwaway server started with the docker CLI,
+// published on a random loopback port.
+type postgresContainer struct {
+	id  string
+	dsn string
+}
+
+func startPostgresContainer(ctx context.Context, image string) (*postgresContainer, error) {
+	id, err := docker(ctx, "run", "--detach", "--rm",
+		"--publish", "127.0.0.1::5432",
+		"--env", "POSTGRES_HOST_AUTH_METHOD=trust",
+		image,
+		"-c", "shared_preload_libraries=pg_stat_statements",
+		"-c", "track_io_timing=on",
+		"-c", "fsync=off")
+	if err != nil {
+		return nil, err
+	}
+	c := &postgresContainer{id: id}
+
+	addr, err := docker(ctx, "port", id, "5432/tcp")
+	if err != nil {
+		c.stop()
+		return nil, err
+	}
+	// docker port prints one line per address family; the first is the
+	// loopback address it was published on.
+	addr, _, _ = strings.Cut(addr, "\n")
+	c.dsn = fmt.Sprintf("postgres://postgres@%s/postgres?sslmode=disable", addr)
+	return c, nil
+}
+
+func (c *postgresContainer) stop() {
+	_, _ = docker(context.Background(), "rm", "--force", c.id)
+}
+
+// waitForPostgres connects once the server accepts connections. The
+// image's entrypoint initialises the cluster with TCP disabled, so the
+// first successful connection is to the final server.
+func waitForPostgres(ctx context.Context, dsn string, timeout time.Duration) (*pgx.Conn, error) {
+	deadline := time.Now().Add(timeout)
+	for {
+		conn, err := pgx.Connect(ctx, dsn)
+		if err == nil {
+			return conn, nil
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("server did not accept connections within %s: %w", timeout, err)
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(500 * time.Millisecond):
+		}
+	}
+}
+
+func docker(ctx context.Context, args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "docker", args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("docker %s: %w: %s", args[0], err, msg)
+		}
+		return "", fmt.Errorf("docker %s: %w", args[0], err)
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
@@ -0,0 +1,64 @@
+-- Fixture scenarios for pgdoctor devtest. Each block sets up something a
+-- check should report, so the suite runs its queries against real rows on
+-- every PostgreSQL version and devtest can assert the findings appear.
+
+CREATE EXTENSION IF NOT EXISTS pg_stat_statements;
+CREATE EXTENSION IF NOT EXISTS pg_buffercache;
+
+-- duplicate-indexes/exact-duplicates: two identical indexes.
+CREATE TABLE devtest_orders (
+  id bigserial PRIMARY KEY
+  , customer_id bigint NOT NULL
+  , status text NOT NULL
+  , note text
+  , created_at timestamptz NOT NULL DEFAULT now()
+);
+CREATE INDEX devtest_orders_customer_idx ON devtest_orders (customer_id);
+CREATE INDEX devtest_orders_customer_dup_idx ON devtest_orders (customer_id);
+
+INSERT INTO devtest_orders (customer_id, status, note)
+SELECT g % 500, CASE WHEN g % 10 = 0 THEN 'cancelled' ELSE 'paid' END, REPEAT('x', g % 200)
+FROM GENERATE_SERIES(1, 20000) AS g;
+UPDATE devtest_orders SET status = 'refunded' WHERE id % 7 = 0;
+DELETE FROM devtest_orders WHERE id % 13 = 0;
+
+-- sequence-health/near-exhaustion: an integer sequence past 90% of its range.
+CREATE SEQUENCE devtest_ticket_seq AS integer;
+SELECT SETVAL('devtest_ticket_seq', 2000000000);
+
+-- table-vacuum-health/autovacuum-disabled.
+CREATE TABLE devtest_audit (
+  id bigint GENERATED ALWAYS AS IDENTITY PRIMARY KEY
+  , payload jsonb
+) WITH (autovacuum_enabled = false);
+INSERT INTO devtest_audit (payload)
+SELECT JSONB_BUILD_OBJECT('n', g) FROM GENERATE_SERIES(1, 5000) AS g;
+
+-- Queue-shaped churn for queue-tables and table-activity.
+CREATE TABLE devtest_jobs (
+  id bigserial PRIMARY KEY
+  , run_at timestamptz NOT NULL DEFAULT now()
+);
+INSERT INTO devtest_jobs (run_at) SELECT now() FROM GENERATE_SERIES(1, 20000);
+DELETE FROM devtest_jobs WHERE id <= 19900;
+
+-- A partitioned table for partitioning and partition-usage.
+CREATE TABLE devtest_events (
+  id bigint NOT NULL
+  , created_at date NOT NULL
+) PARTITION BY RANGE (created_at);
+CREATE TABLE devtest_events_2026 PARTITION OF devtest_events
+FOR VALUES FROM ('2026-01-01') TO ('2027-01-01');
+INSERT INTO devtest_events SELECT g, DATE '2026-01-01' + (g % 300) FROM GENERATE_SERIES(1, 5000) AS g;
+
+-- uuid-types: a uuid stored as text.
+CREATE TABLE devtest_sessions (
+  id text PRIMARY KEY DEFAULT GEN_RANDOM_UUID()::text
+  , user_id bigint
+);
+INSERT INTO devtest_sessions (user_id) SELECT g FROM GENERATE_SERIES(1, 1000) AS g;
+
+SELECT COUNT(*) FROM devtest_orders WHERE status = 'paid';
+SELECT * FROM devtest_orders WHERE note LIKE '%xx%' LIMIT 10;
+
+ANALYZE;
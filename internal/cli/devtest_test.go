@@ -0,0 +1,37 @@
+//go:build devtest
+
+package cli
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/fresha/pgdoctor/check"
+)
+
+func TestEvaluateDevtest(t *testing.T) {
+	t.Parallel()
+
+	dup := check.NewReport(check.Metadata{CheckID: "duplicate-indexes"})
+	dup.AddFinding(check.Finding{ID: "exact-duplicates", Severity: check.SeverityWarn})
+
+	seq := check.NewReport(check.Metadata{CheckID: "sequence-health"})
+	seq.AddFinding(check.Finding{ID: "near-exhaustion", Severity: check.SeverityOK})
+
+	ckpt := check.NewReport(check.Metadata{CheckID: "checkpoint-health"})
+	ckpt.AddFinding(check.Finding{ID: "error", Severity: check.SeveritySkip,
+		Details: `running configs/checkpoint-health (activity): ERROR: column "checkpoints_timed" does not exist (SQLSTATE 42703)`})
+
+	slow := check.NewReport(check.Metadata{CheckID: "table-bloat"})
+	slow.AddFinding(check.Finding{ID: "error", Severity: check.SeveritySkip, Details: "query cancelled by statement_timeout"})
+
+	result := evaluateDevtest([]*check.Report{dup, seq, ckpt, slow})
+
+	assert.False(t, result.ok())
+	assert.Equal(t, 4, result.checks)
+	assert.Len(t, result.incompatible, 1)
+	assert.Contains(t, result.incompatible[0], "checkpoint-health")
+	assert.Equal(t, []string{"table-bloat: query cancelled by statement_timeout"}, result.skipped)
+	assert.Equal(t, []string{"sequence-health/near-exhaustion", "table-vacuum-health/autovacuum-disabled"}, result.missing)
+}
@@ -0,0 +1,45 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/fresha/pgdoctor/check"
+)
+
+const (
+	failOnNever = "never"
+	failOnFail  = "fail"
+	failOnWarn  = "warn"
+)
+
+func addFailOnFlag(cmd *cobra.Command, opts *runOptions) {
+	cmd.Flags().StringVar(&opts.failOn, "fail-on", failOnFail, "Exit 1 when any check reaches this severity: fail (default), warn, never")
+}
+
+func validateFailOn(opts *runOptions) error {
+	switch opts.failOn {
+	case "", failOnNever, failOnFail, failOnWarn:
+		return nil
+	default:
+		return fmt.Errorf("invalid --fail-on %q: must be never, fail, or warn", opts.failOn)
+	}
+}
+
+// failOnExit maps the worst severity of a run to its exit under --fail-on:
+// a SilentError with exit code 1 when the severity reaches the threshold,
+// nil otherwise. Skipped checks never fail a run.
+func failOnExit(failOn string, worst check.Severity) error {
+	threshold := check.SeverityFail
+	switch failOn {
+	case failOnNever:
+		return nil
+	case failOnWarn:
+		threshold = check.SeverityWarn
+	}
+	if worst >= threshold {
+		return &SilentError{ExitCode: 1}
+	}
+	return nil
+}
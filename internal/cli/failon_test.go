@@ -0,0 +1,49 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/fresha/pgdoctor/check"
+)
+
+func TestFailOnExit(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		failOn string
+		worst  check.Severity
+		exit   bool
+	}{
+		{failOn: failOnFail, worst: check.SeverityFail, exit: true},
+		{failOn: failOnFail, worst: check.SeverityWarn},
+		{failOn: "", worst: check.SeverityFail, exit: true},
+		{failOn: failOnWarn, worst: check.SeverityWarn, exit: true},
+		{failOn: failOnWarn, worst: check.SeverityFail, exit: true},
+		{failOn: failOnWarn, worst: check.SeverityOK},
+		{failOn: failOnWarn, worst: check.SeveritySkip},
+		{failOn: failOnNever, worst: check.SeverityFail},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.failOn+"/"+tt.worst.String(), func(t *testing.T) {
+			t.Parallel()
+
+			err := failOnExit(tt.failOn, tt.worst)
+			if !tt.exit {
+				assert.NoError(t, err)
+				return
+			}
+			assert.Equal(t, &SilentError{ExitCode: 1}, err)
+		})
+	}
+}
+
+func TestValidateFailOn(t *testing.T) {
+	t.Parallel()
+
+	assert.NoError(t, validateFailOn(&runOptions{failOn: failOnWarn}))
+	assert.NoError(t, validateFailOn(&runOptions{}))
+	assert.ErrorContains(t, validateFailOn(&runOptions{failOn: "error"}), `invalid --fail-on "error"`)
+}
@@ -0,0 +1,370 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/spf13/cobra"
+
+	"github.com/fresha/pgdoctor"
+	"github.com/fresha/pgdoctor/check"
+	"github.com/fresha/pgdoctor/internal/config"
+)
+
+// fixLockTimeout bounds how long a fix waits for its lock, so an ALTER TABLE
+// queued behind a long transaction does not block every query behind it.
+const fixLockTimeout = "5s"
+
+type fixOptions struct {
+	ignored     []string
+	only        []string
+	interactive bool
+	baseline    string
+	schema      string
+	connectionFlags
+	configPath string
+}
+
+func newFixCommand() *cobra.Command {
+	opts := &fixOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "fix --interactive [DSN]",
+		Short: "Apply low-risk fixes one at a time, with confirmation",
+		Long: `Run the checks, then walk through the low-risk fixes they prescribe, such
+as dropping a confirmed unused index with DROP INDEX CONCURRENTLY or setting
+per-table autovacuum reloptions. Each fix shows its SQL and is applied only
+after an explicit "y"; the check is then re-run to confirm the finding is
+resolved.
+
+Fixes that block reads or writes are offered only inside one of the
+maintenance_windows in pgdoctor.yaml; outside a window they are held back.
+High-risk fixes (rewrites, heavy locks, or settings that are often
+deliberate) are never offered; apply them by hand after reading
+pgdoctor explain <check-id>.
+
+Findings accepted in the --baseline file, or in pgdoctor-baseline.json when
+it exists, offer no fixes. --schema limits the checks to one schema as it
+does for pgdoctor run.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !opts.interactive {
+				return fmt.Errorf("--interactive is required: pgdoctor fix only applies fixes after confirming each one")
+			}
+
+			cfg, err := loadConfig(opts.configPath)
+			if err != nil {
+				return err
+			}
+			if !cmd.Flags().Changed("only") && len(cfg.Only) > 0 {
+				opts.only = cfg.Only
+			}
+			if !cmd.Flags().Changed("ignore") && len(cfg.Ignore) > 0 {
+				opts.ignored = cfg.Ignore
+			}
+
+			connString, err := resolveDSN(args, cfg)
+			if err != nil {
+				return err
+			}
+			baseline, err := fixBaseline(opts.baseline)
+			if err != nil {
+				return err
+			}
+
+			checks, err := fixChecks(cfg, opts.only, opts.ignored)
+			if err != nil {
+				return err
+			}
+
+			ctx := cmd.Context()
+			conn, _, closeConn, err := openConnection(ctx, connString, opts.connectionFlags)
+			if err != nil {
+				return err
+			}
+			defer closeConn()
+			if opts.schema != "" {
+				if err := pgdoctor.ValidateSchema(ctx, conn, opts.schema); err != nil {
+					return err
+				}
+			}
+
+			var reports []*check.Report
+			runOpts := fixRunOptions(cfg, checks, opts.schema, baseline, &reports)
+			pgdoctor.Run(ctx, conn, runOpts)
+
+			window, inWindow := cfg.InMaintenanceWindow(time.Now())
+			fixes, held := collectFixes(checks, reports, inWindow)
+			printWindowNote(cmd.OutOrStdout(), cfg.MaintenanceWindows, window, inWindow, held)
+
+			f := &fixer{
+				prompt: &prompter{in: bufio.NewReader(cmd.InOrStdin()), out: cmd.OutOrStdout()},
+				out:    cmd.OutOrStdout(),
+				apply: func(ctx context.Context, sql string) error {
+					return applyFix(ctx, conn, sql)
+				},
+				verify: func(ctx context.Context, p pendingFix) (bool, error) {
+					return fixResolved(fixVerifyContext(ctx, runOpts), p.pkg.New(conn, cfg.Checks), p)
+				},
+			}
+			summary := f.run(ctx, fixes)
+			if summary.failed > 0 {
+				return &SilentError{ExitCode: 1}
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&opts.interactive, "interactive", false, "Confirm each fix before applying it (required)")
+	cmd.Flags().StringVar(&opts.baseline, "baseline", "", "Baseline file of accepted findings, which offer no fixes (default "+defaultBaselinePath+" when it exists)")
+	cmd.Flags().StringSliceVar(&opts.ignored, "ignore", nil, "Checks or categories to ignore")
+	cmd.Flags().StringSliceVar(&opts.only, "only", nil, "Only run these checks or categories")
+	cmd.Flags().StringVar(&opts.schema, "schema", "", "Only check and fix the objects of this schema, e.g. one tenant of a schema-per-tenant database")
+	addConnectionFlags(cmd, &opts.connectionFlags)
+	addConfigFlag(cmd, &opts.configPath)
+
+	return cmd
+}
+
+// fixChecks returns the checks to run, chosen from the built-in and custom
+// checks by --only and --ignore as pgdoctor run chooses them.
+func fixChecks(cfg *config.File, only, ignored []string) ([]check.Package, error) {
+	allChecks := append(pgdoctor.AllChecks(), cfg.CustomCheckPackages()...)
+	validOnly, validIgnored, err := validateFilters(allChecks, only, ignored)
+	if err != nil {
+		return nil, err
+	}
+	return pgdoctor.Filter(allChecks, validOnly, validIgnored), nil
+}
+
+// fixRunOptions runs the checks as pgdoctor run does with the same
+// configuration, schema, and baseline, so a finding that policy passes or
+// the baseline accepts offers no fixes.
+func fixRunOptions(cfg *config.File, checks []check.Package, schema string, baseline *pgdoctor.Baseline, reports *[]*check.Report) pgdoctor.Options {
+	return pgdoctor.Options{
+		Checks:   checks,
+		Config:   cfg.Checks,
+		OnReport: pgdoctor.Collect(reports),
+		Baseline: baseline,
+		Schema:   schema,
+
+		IgnoreObjects:     cfg.ObjectFilter(),
+		SeverityOverrides: cfg.SeverityOverrides(),
+	}
+}
+
+// fixVerifyContext scopes the re-run that confirms a fix to the objects and
+// schema of the run that offered it, so a check of one tenant is not
+// re-run against the default schema and reported resolved.
+func fixVerifyContext(ctx context.Context, opts pgdoctor.Options) context.Context {
+	filter := opts.IgnoreObjects
+	if opts.Schema != "" {
+		filter = filter.ScopedToSchema(opts.Schema)
+	}
+	if filter == nil {
+		return ctx
+	}
+	return check.ContextWithObjectFilter(ctx, filter)
+}
+
+// fixBaseline reads the --baseline file, or defaultBaselinePath when
+// --baseline is not given and the file exists.
+func fixBaseline(path string) (*pgdoctor.Baseline, error) {
+	if path == "" {
+		if _, err := os.Stat(defaultBaselinePath); errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		path = defaultBaselinePath
+	}
+	return readBaseline(path)
+}
+
+// pendingFix is a low-risk fix offered by one finding of a check.
+type pendingFix struct {
+	pkg       check.Package
+	checkID   string
+	findingID string
+	fix       check.Fix
+}
+
+// collectFixes returns the low-risk fixes in the reports, in run order.
+// High-risk fixes are dropped here so they can never be applied. Outside a
+// maintenance window, fixes that block reads or writes are held back and
+// counted instead.
+func collectFixes(checks []check.Package, reports []*check.Report, inWindow bool) ([]pendingFix, int) {
+	packages := make(map[string]check.Package, len(checks))
+	for _, pkg := range checks {
+		packages[pkg.Metadata().CheckID] = pkg
+	}
+
+	var fixes []pendingFix
+	var held int
+	for _, r := range reports {
+		pkg, ok := packages[r.CheckID]
+		if !ok {
+			continue
+		}
+		for _, finding := range r.Results {
+			if finding.Severity == check.SeverityOK {
+				continue
+			}
+			for _, fix := range finding.Fixes {
+				if fix.Risk != check.RiskLow {
+					continue
+				}
+				if fix.NeedsMaintenanceWindow() && !inWindow {
+					held++
+					continue
+				}
+				fixes = append(fixes, pendingFix{pkg: pkg, checkID: r.CheckID, findingID: finding.ID, fix: fix})
+			}
+		}
+	}
+	return fixes, held
+}
+
+// printWindowNote explains which fixes the current time allows.
+func printWindowNote(w io.Writer, windows []config.MaintenanceWindow, window config.MaintenanceWindow, inWindow bool, held int) {
+	switch {
+	case inWindow:
+		fmt.Fprintf(w, "Inside maintenance window %s: fixes that block reads or writes are included.\n\n", window)
+	case held == 0:
+	case len(windows) == 0:
+		fmt.Fprintf(w, "%d fix(es) block reads or writes and need a maintenance window; add maintenance_windows to %s to apply them.\n\n",
+			held, config.DefaultPath)
+	default:
+		names := make([]string, len(windows))
+		for i, mw := range windows {
+			names[i] = mw.String()
+		}
+		fmt.Fprintf(w, "%d fix(es) block reads or writes and are held until a maintenance window (%s).\n\n",
+			held, strings.Join(names, "; "))
+	}
+}
+
+// fixSummary counts what happened to the offered fixes.
+type fixSummary struct {
+	applied, skipped, failed, resolved int
+}
+
+// fixer walks through fixes, applying each one only after confirmation.
+type fixer struct {
+	prompt *prompter
+	out    io.Writer
+	apply  func(context.Context, string) error
+	verify func(context.Context, pendingFix) (bool, error)
+}
+
+func (f *fixer) run(ctx context.Context, fixes []pendingFix) fixSummary {
+	var s fixSummary
+	if len(fixes) == 0 {
+		fmt.Fprintln(f.out, "No low-risk fixes to apply.")
+		return s
+	}
+
+	fmt.Fprintf(f.out, "%d low-risk fix(es) available. Each is applied only after you answer y.\n", len(fixes))
+	for i, p := range fixes {
+		fmt.Fprintf(f.out, "\n[%d/%d] %s / %s: %s\n", i+1, len(fixes), p.checkID, p.findingID, p.fix.Object)
+		fmt.Fprintf(f.out, "  %s\n", p.fix.Description)
+		fmt.Fprintf(f.out, "  Lock: %s\n", lockDescription(p.fix.Lock))
+		fmt.Fprintf(f.out, "  SQL: %s;\n", p.fix.SQL)
+
+		answer := f.prompt.choose("Apply?", []string{"y", "n", "q"}, "n")
+		if f.prompt.err != nil || answer == "q" {
+			s.skipped += len(fixes) - i
+			break
+		}
+		if answer != "y" {
+			s.skipped++
+			continue
+		}
+
+		if err := f.apply(ctx, p.fix.SQL); err != nil {
+			s.failed++
+			fmt.Fprintf(f.out, "  %s %v\n", colorForSeverity(check.SeverityFail)("failed:"), err)
+			continue
+		}
+		s.applied++
+
+		resolved, err := f.verify(ctx, p)
+		switch {
+		case err != nil:
+			fmt.Fprintf(f.out, "  applied; re-running %s failed: %v\n", p.checkID, err)
+		case resolved:
+			s.resolved++
+			fmt.Fprintf(f.out, "  applied; %s no longer reports it\n", p.checkID)
+		default:
+			fmt.Fprintf(f.out, "  applied, but %s still reports it; statistics may lag, re-run pgdoctor run --only %s later\n",
+				p.checkID, p.checkID)
+		}
+	}
+
+	fmt.Fprintf(f.out, "\nApplied %d (%d verified resolved), skipped %d, failed %d.\n",
+		s.applied, s.resolved, s.skipped, s.failed)
+	return s
+}
+
+// applyFix runs one fix statement outside a transaction. The statement
+// timeout is lifted because CONCURRENTLY operations take as long as they
+// take, while lock_timeout keeps a fix from queueing behind long
+// transactions and blocking other sessions.
+func applyFix(ctx context.Context, conn *pgx.Conn, sql string) (err error) {
+	if _, err := conn.Exec(ctx, "SET statement_timeout = 0"); err != nil {
+		return err
+	}
+	defer func() {
+		_, resetErr := conn.Exec(ctx, fmt.Sprintf("SET statement_timeout = %d", pgdoctor.DefaultStatementTimeoutMs))
+		err = errors.Join(err, resetErr)
+	}()
+
+	if _, err := conn.Exec(ctx, "SET lock_timeout = '"+fixLockTimeout+"'"); err != nil {
+		return err
+	}
+	defer func() {
+		_, resetErr := conn.Exec(ctx, "RESET lock_timeout")
+		err = errors.Join(err, resetErr)
+	}()
+
+	_, err = conn.Exec(ctx, sql)
+	return err
+}
+
+// fixResolved re-runs a check and reports whether the finding no longer
+// offers the same statement.
+func fixResolved(ctx context.Context, checker check.Checker, p pendingFix) (bool, error) {
+	report, err := checker.Check(ctx)
+	if err != nil {
+		return false, err
+	}
+	for _, finding := range report.Results {
+		if finding.ID != p.findingID {
+			continue
+		}
+		for _, fix := range finding.Fixes {
+			if fix.SQL == p.fix.SQL {
+				return false, nil
+			}
+		}
+	}
+	return true, nil
+}
+
+// lockDescription says what a fix blocks, for prompts and reports.
+func lockDescription(l check.LockImpact) string {
+	switch l {
+	case check.LockOnline:
+		return "online, reads and writes continue"
+	case check.LockWrites:
+		return "blocks writes while it runs"
+	default:
+		return "blocks reads and writes while it runs"
+	}
+}
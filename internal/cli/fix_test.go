@@ -0,0 +1,308 @@
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/fresha/pgdoctor"
+	"github.com/fresha/pgdoctor/check"
+	"github.com/fresha/pgdoctor/internal/config"
+)
+
+func testFixReports() []*check.Report {
+	return []*check.Report{
+		{
+			Metadata: check.Metadata{CheckID: "index-usage"},
+			Results: []check.Finding{
+				{
+					ID:       "unused-indexes",
+					Severity: check.SeverityWarn,
+					Fixes: []check.Fix{
+						{Object: "public.idx_a", SQL: `DROP INDEX CONCURRENTLY "public"."idx_a"`, Risk: check.RiskLow, Lock: check.LockOnline},
+						{Object: "public.idx_b", SQL: `DROP INDEX CONCURRENTLY "public"."idx_b"`, Risk: check.RiskLow, Lock: check.LockOnline},
+						{Object: "public.idx_c", SQL: `DROP INDEX "public"."idx_c"`, Risk: check.RiskLow, Lock: check.LockExclusive},
+					},
+				},
+			},
+		},
+		{
+			Metadata: check.Metadata{CheckID: "table-vacuum-health"},
+			Results: []check.Finding{
+				{
+					ID:       "autovacuum-disabled",
+					Severity: check.SeverityWarn,
+					Fixes: []check.Fix{
+						{Object: "public.staging", SQL: `ALTER TABLE "public"."staging" RESET (autovacuum_enabled)`, Risk: check.RiskHigh},
+					},
+				},
+			},
+		},
+	}
+}
+
+func testFixPackages() []check.Package {
+	var pkgs []check.Package
+	for _, id := range []string{"index-usage", "table-vacuum-health"} {
+		pkgs = append(pkgs, check.Package{Metadata: func() check.Metadata { return check.Metadata{CheckID: id} }})
+	}
+	return pkgs
+}
+
+func testCollectFixes() []pendingFix {
+	fixes, _ := collectFixes(testFixPackages(), testFixReports(), false)
+	return fixes
+}
+
+func TestCollectFixes_ExcludesHighRisk(t *testing.T) {
+	t.Parallel()
+
+	for _, inWindow := range []bool{false, true} {
+		fixes, _ := collectFixes(testFixPackages(), testFixReports(), inWindow)
+		for _, f := range fixes {
+			assert.Equal(t, "index-usage", f.checkID)
+			assert.Equal(t, "unused-indexes", f.findingID)
+			assert.Equal(t, check.RiskLow, f.fix.Risk)
+		}
+	}
+}
+
+func TestCollectFixes_HoldsBlockingFixesOutsideWindow(t *testing.T) {
+	t.Parallel()
+
+	fixes, held := collectFixes(testFixPackages(), testFixReports(), false)
+	assert.Len(t, fixes, 2)
+	assert.Equal(t, 1, held)
+
+	fixes, held = collectFixes(testFixPackages(), testFixReports(), true)
+	require.Len(t, fixes, 3)
+	assert.Equal(t, `DROP INDEX "public"."idx_c"`, fixes[2].fix.SQL)
+	assert.Zero(t, held)
+}
+
+func TestPrintWindowNote(t *testing.T) {
+	t.Parallel()
+
+	windows := []config.MaintenanceWindow{{Days: []string{"sat"}, Start: "02:00", End: "05:00"}}
+
+	var out bytes.Buffer
+	printWindowNote(&out, nil, config.MaintenanceWindow{}, false, 0)
+	assert.Empty(t, out.String())
+
+	printWindowNote(&out, nil, config.MaintenanceWindow{}, false, 2)
+	assert.Contains(t, out.String(), "add maintenance_windows to pgdoctor.yaml")
+
+	out.Reset()
+	printWindowNote(&out, windows, config.MaintenanceWindow{}, false, 2)
+	assert.Contains(t, out.String(), "held until a maintenance window (sat 02:00-05:00 UTC)")
+
+	out.Reset()
+	printWindowNote(&out, windows, windows[0], true, 0)
+	assert.Contains(t, out.String(), "Inside maintenance window sat 02:00-05:00 UTC")
+}
+
+func TestFixer_AppliesOnlyConfirmedFixes(t *testing.T) {
+	t.Parallel()
+
+	var applied []string
+	var out bytes.Buffer
+	f := &fixer{
+		prompt: &prompter{in: bufio.NewReader(strings.NewReader("n\ny\n")), out: &out},
+		out:    &out,
+		apply: func(_ context.Context, sql string) error {
+			applied = append(applied, sql)
+			return nil
+		},
+		verify: func(context.Context, pendingFix) (bool, error) { return true, nil },
+	}
+
+	s := f.run(context.Background(), testCollectFixes())
+	assert.Equal(t, []string{`DROP INDEX CONCURRENTLY "public"."idx_b"`}, applied)
+	assert.Equal(t, fixSummary{applied: 1, skipped: 1, resolved: 1}, s)
+	assert.Contains(t, out.String(), `SQL: DROP INDEX CONCURRENTLY "public"."idx_a";`)
+	assert.Contains(t, out.String(), "no longer reports it")
+}
+
+func TestFixer_QuitAndEOFSkipTheRest(t *testing.T) {
+	t.Parallel()
+
+	for _, input := range []string{"q\n", ""} {
+		var out bytes.Buffer
+		f := &fixer{
+			prompt: &prompter{in: bufio.NewReader(strings.NewReader(input)), out: &out},
+			out:    &out,
+			apply: func(context.Context, string) error {
+				t.Fatal("no fix should be applied")
+				return nil
+			},
+		}
+
+		s := f.run(context.Background(), testCollectFixes())
+		assert.Equal(t, fixSummary{skipped: 2}, s, "input %q", input)
+	}
+}
+
+func TestFixer_ReportsFailuresAndUnresolved(t *testing.T) {
+	t.Parallel()
+
+	var out bytes.Buffer
+	calls := 0
+	f := &fixer{
+		prompt: &prompter{in: bufio.NewReader(strings.NewReader("y\ny\n")), out: &out},
+		out:    &out,
+		apply: func(context.Context, string) error {
+			calls++
+			if calls == 1 {
+				return errors.New("canceling statement due to lock timeout")
+			}
+			return nil
+		},
+		verify: func(context.Context, pendingFix) (bool, error) { return false, nil },
+	}
+
+	s := f.run(context.Background(), testCollectFixes())
+	assert.Equal(t, fixSummary{applied: 1, failed: 1}, s)
+	assert.Contains(t, out.String(), "lock timeout")
+	assert.Contains(t, out.String(), "still reports it")
+}
+
+type fixResolvedChecker struct {
+	report *check.Report
+}
+
+func (c fixResolvedChecker) Metadata() check.Metadata { return check.Metadata{} }
+
+func (c fixResolvedChecker) Check(context.Context) (*check.Report, error) { return c.report, nil }
+
+func TestFixResolved(t *testing.T) {
+	t.Parallel()
+
+	p := testCollectFixes()[0]
+
+	resolved, err := fixResolved(context.Background(), fixResolvedChecker{report: testFixReports()[0]}, p)
+	require.NoError(t, err)
+	assert.False(t, resolved, "the same SQL is still offered")
+
+	after := &check.Report{Metadata: check.Metadata{CheckID: "index-usage"}, Results: []check.Finding{
+		{ID: "unused-indexes", Severity: check.SeverityWarn, Fixes: []check.Fix{testFixReports()[0].Results[0].Fixes[1]}},
+	}}
+	resolved, err = fixResolved(context.Background(), fixResolvedChecker{report: after}, p)
+	require.NoError(t, err)
+	assert.True(t, resolved)
+}
+
+// fixChecker reports the first of testFixReports, whose finding offers
+// low-risk fixes.
+type fixChecker struct{}
+
+func (fixChecker) Metadata() check.Metadata { return check.Metadata{CheckID: "index-usage"} }
+
+func (fixChecker) Check(context.Context) (*check.Report, error) { return testFixReports()[0], nil }
+
+func TestFixRunOptions_SeverityOverrides(t *testing.T) {
+	t.Parallel()
+
+	checks := []check.Package{{
+		Metadata: fixChecker{}.Metadata,
+		New:      func(check.DBTX, check.Config) check.Checker { return fixChecker{} },
+	}}
+	run := func(cfg *config.File) []pendingFix {
+		var reports []*check.Report
+		pgdoctor.Run(context.Background(), nil, fixRunOptions(cfg, checks, "", nil, &reports))
+		fixes, _ := collectFixes(checks, reports, true)
+		return fixes
+	}
+
+	assert.Len(t, run(&config.File{}), 3)
+	assert.Empty(t, run(&config.File{Severity: map[string]string{"index-usage/unused-indexes": "pass"}}),
+		"a finding passed by policy offers no fixes")
+}
+
+func TestFixRunOptions_Baseline(t *testing.T) {
+	t.Parallel()
+
+	checks := []check.Package{{
+		Metadata: fixChecker{}.Metadata,
+		New:      func(check.DBTX, check.Config) check.Checker { return fixChecker{} },
+	}}
+	run := func(baseline *pgdoctor.Baseline) ([]*check.Report, []pendingFix) {
+		var reports []*check.Report
+		pgdoctor.Run(context.Background(), nil, fixRunOptions(&config.File{}, checks, "", baseline, &reports))
+		fixes, _ := collectFixes(checks, reports, true)
+		return reports, fixes
+	}
+
+	reports, fixes := run(nil)
+	require.Len(t, fixes, 3)
+
+	_, fixes = run(pgdoctor.NewBaseline(reports, time.Now()))
+	assert.Empty(t, fixes, "a finding accepted in the baseline offers no fixes")
+}
+
+func TestFixBaseline(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+
+	b, err := fixBaseline("")
+	require.NoError(t, err)
+	assert.Nil(t, b, "no baseline without the default file")
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, defaultBaselinePath), []byte(`{"version":1,"created_at":"2026-01-01T00:00:00Z","findings":[]}`), 0o600))
+	b, err = fixBaseline("")
+	require.NoError(t, err)
+	assert.NotNil(t, b, "the default file is read when it exists")
+
+	_, err = fixBaseline("missing.json")
+	require.ErrorContains(t, err, "reading --baseline")
+}
+
+// schemaChecker records the schema the run is scoped to.
+type schemaChecker struct{ schema *string }
+
+func (schemaChecker) Metadata() check.Metadata { return check.Metadata{CheckID: "index-usage"} }
+
+func (c schemaChecker) Check(ctx context.Context) (*check.Report, error) {
+	*c.schema = check.SchemaFromContext(ctx, "public")
+	return check.NewReport(c.Metadata()), nil
+}
+
+func TestFixRunOptions_Schema(t *testing.T) {
+	t.Parallel()
+
+	var schema string
+	checks := []check.Package{{
+		Metadata: schemaChecker{}.Metadata,
+		New:      func(check.DBTX, check.Config) check.Checker { return schemaChecker{schema: &schema} },
+	}}
+	var reports []*check.Report
+	opts := fixRunOptions(&config.File{}, checks, "tenant_42", nil, &reports)
+	pgdoctor.Run(context.Background(), nil, opts)
+	assert.Equal(t, "tenant_42", schema)
+
+	assert.Equal(t, "tenant_42", check.SchemaFromContext(fixVerifyContext(context.Background(), opts), "public"),
+		"the re-run that confirms a fix checks the same schema")
+	unscoped := fixRunOptions(&config.File{}, checks, "", nil, &reports)
+	assert.Equal(t, "public", check.SchemaFromContext(fixVerifyContext(context.Background(), unscoped), "public"))
+}
+
+func TestFixChecks_CustomChecks(t *testing.T) {
+	t.Parallel()
+
+	cfg := &config.File{CustomChecks: []config.CustomCheck{{ID: "acme-owner-comment", Category: "schema", SQL: "SELECT 1"}}}
+	checks, err := fixChecks(cfg, []string{"acme-owner-comment"}, nil)
+	require.NoError(t, err)
+	require.Len(t, checks, 1)
+	assert.Equal(t, "acme-owner-comment", checks[0].Metadata().CheckID)
+
+	_, err = fixChecks(&config.File{}, []string{"acme-owner-comment"}, nil)
+	assert.Error(t, err, "without the custom check the filter is unknown")
+}
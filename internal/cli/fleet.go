@@ -0,0 +1,448 @@
+package cli
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/fresha/pgdoctor"
+	"github.com/fresha/pgdoctor/check"
+	"github.com/fresha/pgdoctor/db"
+	"github.com/fresha/pgdoctor/internal/config"
+	"github.com/fresha/pgdoctor/internal/report"
+	"github.com/fresha/pgdoctor/internal/storage"
+)
+
+const (
+	defaultFleetConcurrency = 4
+	defaultHostTimeout      = 10 * time.Minute
+)
+
+// fleetResult is the outcome of running the checks against one host.
+type fleetResult struct {
+	host     config.Host
+	connPath string
+	reports  []*check.Report
+	// err is set when the host could not be checked, or timed out part way;
+	// reports then holds the checks that finished.
+	err      error
+	duration time.Duration
+
+	// identity is the host's system identifier and timeline, nil when
+	// identityErr kept them from being read.
+	identity    *db.ClusterIdentityRow
+	identityErr error
+	// divergence lists how the host differs from the rest of its group.
+	divergence []string
+}
+
+// severity is the worst severity of the host's reports, fail when it
+// diverges from its group, or skip when the host could not be checked
+// completely.
+func (r *fleetResult) severity() check.Severity {
+	if r.err != nil {
+		return check.SeveritySkip
+	}
+	if len(r.divergence) > 0 {
+		return check.SeverityFail
+	}
+	return maxReportSeverity(r.reports)
+}
+
+// validateFleet rejects options that only make sense for a single database.
+func validateFleet(opts *runOptions, args []string, format string) error {
+	switch {
+	case len(args) > 0:
+		return errors.New("--hosts-file cannot be combined with a DSN argument")
+	case format != "text" && format != "json":
+		return errors.New("--hosts-file supports --output text or json")
+	case opts.tickets != "":
+		return errors.New("--hosts-file cannot be combined with --tickets")
+	case opts.auditLog:
+		return errors.New("--hosts-file cannot be combined with --audit-log")
+	case opts.baseline != "" || opts.writeBase:
+		return errors.New("--hosts-file cannot be combined with --baseline or --write-baseline")
+	case opts.previous != "":
+		return errors.New("--hosts-file cannot be combined with --previous")
+	case opts.socketDir != "" || opts.cloudSQL != "":
+		return errors.New("--hosts-file cannot be combined with --socket-dir or --cloudsql-instance; set the host in each DSN")
+	case opts.awsInstanceID != "" || opts.awsMetrics:
+		return errors.New("--hosts-file cannot be combined with --aws-db-instance-id or --aws-metrics")
+	case opts.concurrency < 1:
+		return fmt.Errorf("invalid --concurrency %d: must be at least 1", opts.concurrency)
+	case opts.hostTimeout <= 0:
+		return fmt.Errorf("invalid --host-timeout %s: must be positive", opts.hostTimeout)
+	}
+	return nil
+}
+
+// runFleet runs the checks against every host, at most concurrency at a
+// time, giving each host timeout to connect and finish. Results keep the
+// order of hosts; progress receives a line as each host finishes.
+func runFleet(ctx context.Context, progress io.Writer, hosts []config.Host, runOpts pgdoctor.Options, f connectionFlags, concurrency int, timeout time.Duration) []fleetResult {
+	results := make([]fleetResult, len(hosts))
+	sem := make(chan struct{}, concurrency)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	for i, host := range hosts {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			res := &results[i]
+			res.host = host
+			runHost(ctx, res, runOpts, f, timeout)
+
+			mu.Lock()
+			defer mu.Unlock()
+			label, colorize := severityDisplay(res.severity())
+			if res.err != nil {
+				label = "ERROR"
+			}
+			fmt.Fprintf(progress, "%s %s %s\n", colorize(fmt.Sprintf("%-5s", label)), host.Label,
+				dimColor()(fmt.Sprintf("(%d checks in %s)", len(res.reports), check.FormatDurationMs(float64(res.duration.Milliseconds())))))
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+func runHost(ctx context.Context, res *fleetResult, runOpts pgdoctor.Options, f connectionFlags, timeout time.Duration) {
+	start := time.Now()
+	defer func() { res.duration = time.Since(start) }()
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	conn, connPath, closeConn, err := connect(ctx, res.host.DSN, f)
+	if err != nil {
+		res.err = err
+		return
+	}
+	defer closeConn()
+	res.connPath = connPath
+	if runOpts.QueryRateLimit > 0 {
+		if res.err = pgdoctor.ApplyLowImpactSettings(ctx, conn); res.err != nil {
+			return
+		}
+	}
+	if runOpts.Schema != "" {
+		if res.err = pgdoctor.ValidateSchema(ctx, conn, runOpts.Schema); res.err != nil {
+			return
+		}
+	}
+
+	if res.host.Group != "" {
+		if id, err := db.New(conn).ClusterIdentity(ctx); err != nil {
+			res.identityErr = err
+		} else {
+			res.identity = &id
+		}
+	}
+
+	runOpts.OnReport = pgdoctor.Collect(&res.reports)
+	pgdoctor.Run(ctx, conn, runOpts)
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		res.err = fmt.Errorf("timed out after %s with %d of %d checks finished", timeout, len(res.reports), len(runOpts.Checks))
+	}
+}
+
+// flagDivergence compares the hosts of each group. The group's system
+// identifier is the one most of its hosts share; a host with another one is
+// not a copy of the same cluster. Among the rest, a host on an older timeline
+// than the newest is still following the primary from before a failover,
+// and more than one host out of recovery means writes can reach both sides
+// of a split brain.
+func flagDivergence(results []fleetResult) {
+	groups := map[string][]*fleetResult{}
+	var order []string
+	for i := range results {
+		res := &results[i]
+		if res.host.Group == "" || res.identity == nil {
+			continue
+		}
+		if _, ok := groups[res.host.Group]; !ok {
+			order = append(order, res.host.Group)
+		}
+		groups[res.host.Group] = append(groups[res.host.Group], res)
+	}
+
+	for _, group := range order {
+		members := groups[group]
+		if len(members) < 2 {
+			continue
+		}
+
+		counts := map[string]int{}
+		systemID := members[0].identity.SystemIdentifier
+		for _, m := range members {
+			id := m.identity.SystemIdentifier
+			counts[id]++
+			if counts[id] > counts[systemID] {
+				systemID = id
+			}
+		}
+
+		var same, primaries []*fleetResult
+		var timeline int32
+		for _, m := range members {
+			if m.identity.SystemIdentifier != systemID {
+				m.divergence = append(m.divergence, fmt.Sprintf("system identifier %s differs from the group's %s: not a copy of the same cluster",
+					m.identity.SystemIdentifier, systemID))
+				continue
+			}
+			same = append(same, m)
+			timeline = max(timeline, m.identity.TimelineID)
+			if !m.identity.InRecovery {
+				primaries = append(primaries, m)
+			}
+		}
+
+		for _, m := range same {
+			if m.identity.TimelineID < timeline {
+				m.divergence = append(m.divergence, fmt.Sprintf("on timeline %d while the group is on %d: still following the primary from before a failover",
+					m.identity.TimelineID, timeline))
+			}
+		}
+		if len(primaries) > 1 {
+			labels := make([]string, len(primaries))
+			for i, p := range primaries {
+				labels[i] = p.host.Label
+			}
+			for _, p := range primaries {
+				p.divergence = append(p.divergence, fmt.Sprintf("one of %d primaries (%s): writes can reach both sides of a split brain",
+					len(primaries), strings.Join(labels, ", ")))
+			}
+		}
+	}
+}
+
+// fleetExit is the exit for a fleet run: exit code 2 when any host could not
+// be checked, otherwise --fail-on applied to the worst severity across hosts.
+func fleetExit(failOn string, results []fleetResult) error {
+	worst := check.SeverityOK
+	for i := range results {
+		if results[i].err != nil {
+			return &SilentError{ExitCode: 2}
+		}
+		worst = max(worst, results[i].severity())
+	}
+	return failOnExit(failOn, worst)
+}
+
+func writeFleetJSON(w io.Writer, results []fleetResult) error {
+	hosts := make([]report.Host, 0, len(results))
+	for i := range results {
+		res := &results[i]
+		h := report.Host{
+			Label:      res.host.Label,
+			Connection: res.connPath,
+			Group:      res.host.Group,
+			Divergence: res.divergence,
+			Severity:   res.severity().String(),
+			DurationMs: res.duration.Milliseconds(),
+			Checks:     report.FromChecks(res.reports),
+		}
+		if res.identity != nil {
+			h.SystemIdentifier = res.identity.SystemIdentifier
+			h.Timeline = res.identity.TimelineID
+			h.InRecovery = &res.identity.InRecovery
+		}
+		if res.err != nil {
+			h.Error = res.err.Error()
+		}
+		hosts = append(hosts, h)
+	}
+	return report.WriteFleetJSON(w, hosts)
+}
+
+// printFleetSummary prints one line per host, then every check that is not
+// passing on at least one host with its severity on each, worst first, so
+// differences between hosts stand out.
+func printFleetSummary(w io.Writer, hostsFile string, results []fleetResult) {
+	fmt.Fprintf(w, "Fleet Health Check: %d hosts from %s\n\n", len(results), filepath.Base(hostsFile))
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "HOST\tRESULT\tFAIL\tWARN\tPASS\tSKIP\tTIME")
+	for i := range results {
+		res := &results[i]
+		counts := map[check.Severity]int{}
+		for _, r := range res.reports {
+			counts[r.Severity]++
+		}
+		result, _ := severityDisplay(res.severity())
+		if res.err != nil {
+			result = "ERROR"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%d\t%d\t%d\t%s\n", res.host.Label, result,
+			counts[check.SeverityFail], counts[check.SeverityWarn], counts[check.SeverityOK], counts[check.SeveritySkip],
+			check.FormatDurationMs(float64(res.duration.Milliseconds())))
+	}
+	_ = tw.Flush()
+
+	for i := range results {
+		if err := results[i].err; err != nil {
+			fmt.Fprintf(w, "  %s: %v\n", results[i].host.Label, err)
+		}
+	}
+	fmt.Fprintln(w)
+
+	printGroupDivergence(w, results)
+
+	rows := fleetComparison(results)
+	if len(rows) == 0 {
+		fmt.Fprintln(w, colorForSeverity(check.SeverityOK)("All checks pass on every host."))
+		fmt.Fprintln(w)
+		return
+	}
+
+	tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprint(tw, "CHECK")
+	for i := range results {
+		fmt.Fprintf(tw, "\t%s", results[i].host.Label)
+	}
+	fmt.Fprintln(tw)
+	for _, row := range rows {
+		fmt.Fprint(tw, row.checkID)
+		for _, sev := range row.severities {
+			cell := "-"
+			if sev != nil {
+				cell, _ = severityDisplay(*sev)
+			}
+			fmt.Fprintf(tw, "\t%s", cell)
+		}
+		fmt.Fprintln(tw)
+	}
+	_ = tw.Flush()
+	fmt.Fprintln(w)
+
+	fmt.Fprintf(w, "%s\n", dimColor()("For each host's findings: pgdoctor run --hosts-file ... --output json"))
+	fmt.Fprintln(w)
+}
+
+// printGroupDivergence lists the hosts that differ from the rest of their
+// group, and those whose identity could not be read to compare.
+func printGroupDivergence(w io.Writer, results []fleetResult) {
+	var lines []string
+	for i := range results {
+		res := &results[i]
+		for _, d := range res.divergence {
+			lines = append(lines, fmt.Sprintf("  %s (%s): %s", res.host.Label, res.host.Group, d))
+		}
+		if res.identityErr != nil {
+			lines = append(lines, fmt.Sprintf("  %s (%s): not compared, reading the system identifier failed: %v", res.host.Label, res.host.Group, res.identityErr))
+		}
+	}
+	if len(lines) == 0 {
+		return
+	}
+
+	fmt.Fprintln(w, colorForSeverity(check.SeverityFail)("Group divergence:"))
+	for _, line := range lines {
+		fmt.Fprintln(w, line)
+	}
+	fmt.Fprintln(w)
+}
+
+// fleetRow is one check's severity on each host; nil where the host did not
+// report the check.
+type fleetRow struct {
+	checkID    string
+	worst      check.Severity
+	affected   int
+	severities []*check.Severity
+}
+
+// fleetComparison returns a row for every check that is not passing on some
+// host, ordered by worst severity, then by how many hosts share it.
+func fleetComparison(results []fleetResult) []fleetRow {
+	byCheck := map[string]*fleetRow{}
+	var rows []*fleetRow
+	for i := range results {
+		for _, r := range results[i].reports {
+			row, ok := byCheck[r.CheckID]
+			if !ok {
+				row = &fleetRow{checkID: r.CheckID, severities: make([]*check.Severity, len(results))}
+				byCheck[r.CheckID] = row
+				rows = append(rows, row)
+			}
+			row.severities[i] = &r.Severity
+		}
+	}
+
+	var out []fleetRow
+	for _, row := range rows {
+		row.worst = check.SeverityOK
+		for _, sev := range row.severities {
+			if sev != nil && *sev != check.SeverityOK && (row.worst == check.SeverityOK || *sev > row.worst) {
+				row.worst = *sev
+			}
+		}
+		if row.worst == check.SeverityOK {
+			continue
+		}
+		for _, sev := range row.severities {
+			if sev != nil && *sev == row.worst {
+				row.affected++
+			}
+		}
+		out = append(out, *row)
+	}
+	slices.SortFunc(out, func(a, b fleetRow) int {
+		return cmp.Or(cmp.Compare(b.worst, a.worst), cmp.Compare(b.affected, a.affected), strings.Compare(a.checkID, b.checkID))
+	})
+	return out
+}
+
+// fleetOutputName is the {host} of a --output destination in a fleet run:
+// the hosts file name without its extension.
+func fleetOutputName(hostsFile string) string {
+	name := filepath.Base(hostsFile)
+	return strings.TrimSuffix(name, filepath.Ext(name))
+}
+
+// runFleetCommand is `pgdoctor run --hosts-file`.
+func runFleetCommand(ctx context.Context, w io.Writer, opts *runOptions, format string, dest *storage.Destination, runOpts pgdoctor.Options) error {
+	hosts, err := config.LoadHosts(opts.hostsFile)
+	if err != nil {
+		return err
+	}
+
+	startedAt := time.Now()
+	results := runFleet(ctx, os.Stderr, hosts, runOpts, opts.connectionFlags, opts.concurrency, opts.hostTimeout)
+	fmt.Fprintln(os.Stderr)
+	flagDivergence(results)
+
+	if format == "json" {
+		render := func(w io.Writer) error { return writeFleetJSON(w, results) }
+		var renderErr error
+		if dest != nil {
+			dest.Expand(startedAt, fleetOutputName(opts.hostsFile), "")
+			if renderErr = dest.Write(ctx, render); renderErr == nil {
+				fmt.Fprintf(os.Stderr, "Report written to %s\n", dest.Location)
+			}
+		} else {
+			renderErr = render(w)
+		}
+		if renderErr != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", renderErr)
+			return &SilentError{ExitCode: 1}
+		}
+		return fleetExit(opts.failOn, results)
+	}
+
+	printFleetSummary(w, opts.hostsFile, results)
+	return fleetExit(opts.failOn, results)
+}
@@ -0,0 +1,132 @@
+package cli
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/fresha/pgdoctor"
+	"github.com/fresha/pgdoctor/check"
+	"github.com/fresha/pgdoctor/internal/pgident"
+)
+
+func newGrantsCommand() *cobra.Command {
+	var (
+		role    string
+		only    []string
+		ignored []string
+		schemas []string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "grants --role <role>",
+		Short: "Print the grants a role needs to run the selected checks",
+		Long: `Print the GRANT statements a monitoring role needs for pgdoctor to see
+everything the selected checks inspect, from the privileges each check
+declares. Checks run without them, but other roles' sessions and statements
+are hidden and some findings are skipped.
+
+Schema-level grants cover pg_stats and sequence values: with --schema they
+are granted per schema, otherwise through pg_read_all_data.
+
+The output is a SQL script to review and run as a superuser (or the
+provider's admin role), for example:
+
+  pgdoctor grants --role pgdoctor_ro --schema public,billing | psql`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			allChecks := pgdoctor.AllChecks()
+			validOnly, validIgnored, err := validateFilters(allChecks, only, ignored)
+			if err != nil {
+				return err
+			}
+
+			var metadata []check.Metadata
+			for _, pkg := range pgdoctor.Filter(allChecks, validOnly, validIgnored) {
+				metadata = append(metadata, pkg.Metadata())
+			}
+			fmt.Fprint(cmd.OutOrStdout(), grantScript(role, metadata, schemas))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&role, "role", "", "Role to grant the privileges to")
+	cmd.Flags().StringSliceVar(&only, "only", nil, "Only include these checks or categories")
+	cmd.Flags().StringSliceVar(&ignored, "ignore", nil, "Checks or categories to leave out")
+	cmd.Flags().StringSliceVar(&schemas, "schema", nil, "Grant read access on these schemas instead of pg_read_all_data")
+	_ = cmd.MarkFlagRequired("role")
+
+	return cmd
+}
+
+// grantScript renders the statements that give role every privilege the
+// checks declare, each preceded by the checks that need it. Roles included
+// in pg_monitor are folded into it when it is granted anyway.
+func grantScript(role string, checks []check.Metadata, schemas []string) string {
+	needed := map[check.Privilege][]string{}
+	for _, m := range checks {
+		for _, p := range m.Privileges {
+			if !slices.Contains(needed[p], m.CheckID) {
+				needed[p] = append(needed[p], m.CheckID)
+			}
+		}
+	}
+	if monitor, ok := needed[check.PrivilegeMonitor]; ok {
+		for p, ids := range needed {
+			if p.IncludedIn(check.PrivilegeMonitor) {
+				for _, id := range ids {
+					if !slices.Contains(monitor, id) {
+						monitor = append(monitor, id)
+					}
+				}
+				delete(needed, p)
+			}
+		}
+		needed[check.PrivilegeMonitor] = monitor
+	}
+
+	quoted := pgident.Quote(role)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "-- Grants for %s to run %d pgdoctor check(s).\n", quoted, len(checks))
+	if len(needed) == 0 {
+		b.WriteString("-- The selected checks need nothing beyond a role that can log in.\n")
+		return b.String()
+	}
+
+	for _, p := range check.Privileges {
+		ids, ok := needed[p]
+		if !ok {
+			continue
+		}
+		slices.Sort(ids)
+		fmt.Fprintf(&b, "\n-- %s.\n-- Needed by: %s\n", p.Description(), strings.Join(ids, ", "))
+
+		switch {
+		case p.IsPredefinedRole():
+			fmt.Fprintf(&b, "GRANT %s TO %s;\n", p, quoted)
+		case p == check.PrivilegeReadSchemas && len(schemas) == 0:
+			b.WriteString("-- Pass --schema to grant on specific schemas instead. PostgreSQL 14+.\n")
+			fmt.Fprintf(&b, "GRANT pg_read_all_data TO %s;\n", quoted)
+		case p == check.PrivilegeReadSchemas:
+			b.WriteString("-- ALTER DEFAULT PRIVILEGES only covers objects later created by the role running it.\n")
+			for _, s := range schemas {
+				schema := pgident.Quote(strings.TrimSpace(s))
+				fmt.Fprintf(&b, "GRANT USAGE ON SCHEMA %s TO %s;\n", schema, quoted)
+				fmt.Fprintf(&b, "GRANT SELECT ON ALL TABLES IN SCHEMA %s TO %s;\n", schema, quoted)
+				fmt.Fprintf(&b, "GRANT SELECT ON ALL SEQUENCES IN SCHEMA %s TO %s;\n", schema, quoted)
+				fmt.Fprintf(&b, "ALTER DEFAULT PRIVILEGES IN SCHEMA %s GRANT SELECT ON TABLES TO %s;\n", schema, quoted)
+				fmt.Fprintf(&b, "ALTER DEFAULT PRIVILEGES IN SCHEMA %s GRANT SELECT ON SEQUENCES TO %s;\n", schema, quoted)
+			}
+		case p == check.PrivilegeHbaRules:
+			fmt.Fprintf(&b, "GRANT SELECT ON pg_catalog.pg_hba_file_rules TO %s;\n", quoted)
+			fmt.Fprintf(&b, "GRANT EXECUTE ON FUNCTION pg_catalog.pg_hba_file_rules() TO %s;\n", quoted)
+		case p == check.PrivilegeBtreeMetapage:
+			b.WriteString("-- Needs CREATE EXTENSION pageinspect first.\n")
+			fmt.Fprintf(&b, "GRANT EXECUTE ON FUNCTION bt_metap(text) TO %s;\n", quoted)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,63 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/fresha/pgdoctor/check"
+)
+
+func TestGrantScript(t *testing.T) {
+	t.Parallel()
+
+	checks := []check.Metadata{
+		{CheckID: "wal-size", Privileges: []check.Privilege{check.PrivilegeMonitor}},
+		{CheckID: "lock-contention", Privileges: []check.Privilege{check.PrivilegeReadAllStats}},
+		{CheckID: "index-bloat", Privileges: []check.Privilege{check.PrivilegeReadSchemas}},
+		{CheckID: "vacuum-settings"},
+	}
+
+	t.Run("folds roles included in pg_monitor", func(t *testing.T) {
+		t.Parallel()
+
+		script := grantScript("pgdoctor_ro", checks, nil)
+		assert.Contains(t, script, `-- Grants for "pgdoctor_ro" to run 4 pgdoctor check(s).`)
+		assert.Contains(t, script, "-- Needed by: lock-contention, wal-size\nGRANT pg_monitor TO \"pgdoctor_ro\";")
+		assert.NotContains(t, script, "GRANT pg_read_all_stats")
+		assert.Contains(t, script, `GRANT pg_read_all_data TO "pgdoctor_ro";`)
+	})
+
+	t.Run("grants per schema", func(t *testing.T) {
+		t.Parallel()
+
+		script := grantScript("pgdoctor_ro", checks, []string{"public", " Billing"})
+		assert.NotContains(t, script, "pg_read_all_data")
+		assert.Contains(t, script, `GRANT SELECT ON ALL TABLES IN SCHEMA "public" TO "pgdoctor_ro";`)
+		assert.Contains(t, script, `GRANT USAGE ON SCHEMA "Billing" TO "pgdoctor_ro";`)
+		assert.Contains(t, script, `ALTER DEFAULT PRIVILEGES IN SCHEMA "Billing" GRANT SELECT ON SEQUENCES TO "pgdoctor_ro";`)
+	})
+
+	t.Run("keeps roles without pg_monitor", func(t *testing.T) {
+		t.Parallel()
+
+		script := grantScript("monitor", checks[1:2], nil)
+		assert.Contains(t, script, `GRANT pg_read_all_stats TO "monitor";`)
+		assert.NotContains(t, script, "pg_monitor")
+	})
+
+	t.Run("quotes the role", func(t *testing.T) {
+		t.Parallel()
+
+		script := grantScript(`Ops "RO"`, checks[:1], nil)
+		assert.Contains(t, script, `GRANT pg_monitor TO "Ops ""RO""";`)
+	})
+
+	t.Run("nothing needed", func(t *testing.T) {
+		t.Parallel()
+
+		script := grantScript("pgdoctor_ro", checks[3:], nil)
+		assert.Equal(t, "-- Grants for \"pgdoctor_ro\" to run 1 pgdoctor check(s).\n"+
+			"-- The selected checks need nothing beyond a role that can log in.\n", script)
+	})
+}
@@ -0,0 +1,175 @@
+package cli
+
+import (
+	_ "embed"
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+
+	"github.com/fresha/pgdoctor/check"
+	"github.com/fresha/pgdoctor/internal/buildinfo"
+	"github.com/fresha/pgdoctor/internal/report"
+)
+
+//go:embed report.html
+var htmlReportTemplate string
+
+var htmlReport = template.Must(template.New("report").Parse(htmlReportTemplate))
+
+type htmlPage struct {
+	Title      string
+	Connection string
+	Version    string
+	Counts     []htmlCount
+	Risks      []htmlRisk
+	Categories []htmlCategory
+}
+
+// htmlRisk is one entry of the executive summary at the top of the page.
+// Href is pre-built so the slash between check and finding IDs is not
+// escaped.
+type htmlRisk struct {
+	Name     string
+	Href     template.URL
+	Severity string
+	Impact   string
+}
+
+// htmlCount is the number of checks that ended in one severity. Class is
+// the severity's CSS class, shared with the badges and row colours.
+type htmlCount struct {
+	Label string
+	Class string
+	N     int
+}
+
+type htmlCategory struct {
+	Name   check.Category
+	Anchor string
+	Checks []htmlCheck
+}
+
+type htmlCheck struct {
+	Name     string
+	ID       string
+	Severity string
+	// Open expands the section on load; passing checks start collapsed.
+	Open     bool
+	Findings []htmlFinding
+}
+
+type htmlFinding struct {
+	Name        string
+	Anchor      string
+	Severity    string
+	Fingerprint string
+	// Heading is false for a check's single finding, whose section heading
+	// already names it.
+	Heading    bool
+	Details    string
+	Confidence string
+	DocsURL    string
+	Table      *check.Table
+	FixGroups  []htmlFixGroup
+}
+
+type htmlFixGroup struct {
+	Title string
+	SQL   string
+}
+
+// formatHTML renders the report as one self-contained HTML page, with its
+// styles and script inline so it can be attached to a ticket or emailed.
+// Checks are collapsible sections, open when they did not pass; finding
+// tables sort by a column when its header is clicked. Anchors match the
+// markdown report's.
+func formatHTML(w io.Writer, title, connection string, reports []*check.Report) error {
+	page := htmlPage{
+		Title:      title,
+		Connection: connection,
+		Version:    buildinfo.Get().String(),
+	}
+
+	counts := map[check.Severity]int{}
+	for _, r := range reports {
+		counts[r.Severity]++
+	}
+	for _, s := range []check.Severity{check.SeverityFail, check.SeverityWarn, check.SeverityOK, check.SeveritySkip} {
+		if counts[s] > 0 {
+			page.Counts = append(page.Counts, htmlCount{Label: strings.ToUpper(s.String()), Class: s.String(), N: counts[s]})
+		}
+	}
+
+	for _, risk := range report.TopRisks(reports, report.DefaultRiskLimit) {
+		page.Risks = append(page.Risks, htmlRisk{
+			Name:     risk.Name,
+			Href:     template.URL("#" + anchorID(risk.CheckID, risk.FindingID)),
+			Severity: risk.Severity.String(),
+			Impact:   risk.Impact,
+		})
+	}
+
+	order, grouped := reportsByCategory(reports)
+	for _, cat := range order {
+		category := htmlCategory{Name: cat, Anchor: categoryAnchor(cat)}
+		for _, r := range grouped[cat] {
+			c := htmlCheck{
+				Name:     r.Name,
+				ID:       r.CheckID,
+				Severity: r.Severity.String(),
+				Open:     r.Severity > check.SeverityOK,
+			}
+			for _, f := range r.Results {
+				finding := htmlFinding{
+					Name:        f.Name,
+					Anchor:      anchorID(r.CheckID, f.ID),
+					Severity:    f.Severity.String(),
+					Fingerprint: f.Fingerprint,
+					Heading:     f.ID != r.CheckID,
+					Details:     strings.TrimRight(f.Details, "\n"),
+				}
+				if f.Table != nil && len(f.Table.Rows) > 0 {
+					finding.Table = f.Table
+				}
+				if f.Severity > check.SeverityOK {
+					if f.Confidence != check.ConfidenceHigh {
+						finding.Confidence = f.Confidence.String()
+					}
+					finding.DocsURL = f.DocsURL
+					finding.FixGroups = htmlFixGroups(f.Fixes)
+				}
+				c.Findings = append(c.Findings, finding)
+			}
+			category.Checks = append(category.Checks, c)
+		}
+		page.Categories = append(page.Categories, category)
+	}
+
+	if err := htmlReport.Execute(w, page); err != nil {
+		return fmt.Errorf("writing html report: %w", err)
+	}
+	return nil
+}
+
+func htmlFixGroups(fixes []check.Fix) []htmlFixGroup {
+	now, window := splitFixesByLock(fixes)
+	var groups []htmlFixGroup
+	for _, group := range []struct {
+		title string
+		fixes []check.Fix
+	}{
+		{"Safe to run now", now},
+		{"Requires a maintenance window", window},
+	} {
+		if len(group.fixes) == 0 {
+			continue
+		}
+		var sql strings.Builder
+		for _, fix := range group.fixes {
+			sql.WriteString(fixStatement(fix) + "\n")
+		}
+		groups = append(groups, htmlFixGroup{Title: group.title, SQL: sql.String()})
+	}
+	return groups
+}
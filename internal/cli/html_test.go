@@ -0,0 +1,56 @@
+package cli
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/fresha/pgdoctor/check"
+)
+
+func TestFormatHTML(t *testing.T) {
+	t.Parallel()
+
+	seq := check.NewReport(check.Metadata{CheckID: "sequence-health", Name: "Sequence Health", Category: check.CategorySchema})
+	seq.AddFinding(check.Finding{
+		ID:         "near-exhaustion",
+		Name:       "Sequences Near Exhaustion",
+		Severity:   check.SeverityFail,
+		Details:    "1 sequence above 90% <see table>",
+		Confidence: check.ConfidenceMedium,
+		Table: &check.Table{
+			Headers: []string{"Sequence", "Usage"},
+			Rows:    []check.TableRow{{Cells: []string{"public.orders_id_seq", "95%"}, Severity: check.SeverityFail}},
+		},
+		Fixes: []check.Fix{
+			{SQL: `ALTER SEQUENCE "public"."orders_id_seq" AS bigint`, Risk: check.RiskLow, Lock: check.LockOnline},
+		},
+	})
+
+	ver := check.NewReport(check.Metadata{CheckID: "pg-version", Name: "PG Version", Category: check.CategoryConfigs})
+	ver.AddFinding(check.Finding{ID: "pg-version", Name: "PG Version", Severity: check.SeverityOK})
+
+	var buf bytes.Buffer
+	require.NoError(t, formatHTML(&buf, "db.internal/app", "tcp db.internal:5432", []*check.Report{ver, seq}))
+	out := buf.String()
+
+	assert.True(t, strings.HasPrefix(out, "<!DOCTYPE html>"))
+	assert.Contains(t, out, "<title>Database Health Report: db.internal/app</title>")
+	assert.Contains(t, out, `<span class="badge fail">1 FAIL</span><span class="badge pass">1 PASS</span>`)
+	assert.Contains(t, out, `<li><span class="badge fail">fail</span> <a href="#sequence-health/near-exhaustion">Sequences Near Exhaustion</a>: 1 sequence above 90% &lt;see table&gt;</li>`)
+	assert.Contains(t, out, `<h2 id="category-configs">configs</h2>`)
+	assert.Contains(t, out, `<details class="check pass" id="pg-version">`, "passing checks start collapsed")
+	assert.Contains(t, out, `<details class="check fail" id="sequence-health" open>`)
+	assert.Contains(t, out, `<div class="finding" id="sequence-health/near-exhaustion">`)
+	assert.Contains(t, out, "<pre>1 sequence above 90% &lt;see table&gt;</pre>")
+	assert.Contains(t, out, "Confidence: medium. Verify before acting.")
+	assert.Contains(t, out, `<tr class="fail"><td>public.orders_id_seq</td><td>95%</td></tr>`)
+	assert.Contains(t, out, "<p><strong>Safe to run now:</strong></p>\n<pre><code>ALTER SEQUENCE &#34;public&#34;.&#34;orders_id_seq&#34; AS bigint;\n</code></pre>")
+	assert.Contains(t, out, "fingerprint: "+check.Fingerprint("sequence-health", "near-exhaustion", ""))
+	assert.Equal(t, 1, strings.Count(out, `id="pg-version"`), "a single-finding check has one anchor")
+	assert.NotContains(t, out, "<link", "assets are inline")
+	assert.Less(t, strings.Index(out, `id="category-configs"`), strings.Index(out, `id="category-schema"`))
+}
@@ -0,0 +1,211 @@
+package cli
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/fresha/pgdoctor"
+	"github.com/fresha/pgdoctor/internal/config"
+)
+
+type initAnswers struct {
+	dsn        string
+	provider   string
+	categories []string
+	tracker    string
+	project    string
+}
+
+func newInitCommand() *cobra.Command {
+	var path string
+	var force bool
+
+	cmd := &cobra.Command{
+		Use:   "init",
+		Short: "Create a pgdoctor.yaml config file interactively",
+		Long: `Walk through the settings a team usually needs on its first run and
+write them to a commented pgdoctor.yaml. Every answer can be changed later by
+editing the file, and command-line flags always take precedence over it.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if !force {
+				if _, err := os.Stat(path); err == nil {
+					return fmt.Errorf("%s already exists (use --force to overwrite)", path)
+				} else if !errors.Is(err, fs.ErrNotExist) {
+					return err
+				}
+			}
+
+			answers, err := promptInit(cmd.InOrStdin(), cmd.OutOrStdout())
+			if err != nil {
+				return err
+			}
+
+			if err := os.WriteFile(path, []byte(renderConfig(answers)), 0o600); err != nil {
+				return fmt.Errorf("writing %s: %w", path, err)
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "\nWrote %s. Run checks with: pgdoctor run\n", path)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&path, "path", config.DefaultPath, "Where to write the config file")
+	cmd.Flags().BoolVar(&force, "force", false, "Overwrite an existing config file")
+
+	return cmd
+}
+
+func promptInit(in io.Reader, out io.Writer) (initAnswers, error) {
+	p := &prompter{in: bufio.NewReader(in), out: out}
+	var a initAnswers
+
+	fmt.Fprintln(out, "pgdoctor setup. Press Enter to accept the default shown in brackets.")
+	fmt.Fprintln(out)
+
+	a.dsn = p.ask("Connection string (use ${VAR} to read a secret from the environment)", "${PGDOCTOR_DSN}")
+	a.provider = p.choose("Cloud provider hosting the database", config.Providers, "none")
+
+	categories := availableCategories()
+	for {
+		answer := p.ask(fmt.Sprintf("Categories to run, comma-separated (%s)", strings.Join(categories, ", ")), "all")
+		if answer == "all" {
+			a.categories = nil
+			break
+		}
+		selected, unknown := splitKnown(answer, categories)
+		if len(unknown) == 0 {
+			a.categories = selected
+			break
+		}
+		fmt.Fprintf(out, "  unknown categories: %s\n", strings.Join(unknown, ", "))
+	}
+
+	a.tracker = p.choose("Open tickets for FAIL findings in", []string{"none", "jira", "linear"}, "none")
+	if a.tracker != "none" {
+		label := "Jira project key"
+		if a.tracker == "linear" {
+			label = "Linear team ID"
+		}
+		for a.project == "" && p.err == nil {
+			a.project = p.ask(label, "")
+		}
+	}
+
+	return a, p.err
+}
+
+// renderConfig writes the answers as a commented pgdoctor.yaml.
+func renderConfig(a initAnswers) string {
+	var b strings.Builder
+
+	b.WriteString("# pgdoctor configuration. Command-line flags override these settings.\n\n")
+
+	b.WriteString("# Connection string. ${VAR} references are read from the environment.\n")
+	fmt.Fprintf(&b, "dsn: %q\n\n", a.dsn)
+
+	b.WriteString("# Cloud provider hosting the database: none, aws, gcp.\n")
+	fmt.Fprintf(&b, "provider: %s\n\n", a.provider)
+
+	b.WriteString("# Check IDs or categories to run (like --only). Omit to run everything.\n")
+	if len(a.categories) == 0 {
+		b.WriteString("# only: [indexes, vacuum]\n\n")
+	} else {
+		fmt.Fprintf(&b, "only: [%s]\n\n", strings.Join(a.categories, ", "))
+	}
+
+	b.WriteString("# Check IDs or categories to skip (like --ignore).\n")
+	b.WriteString("# ignore: [partition-usage]\n\n")
+
+	b.WriteString("# Open a ticket per FAIL finding and close it once resolved (like --tickets).\n")
+	b.WriteString("# Credentials come from JIRA_BASE_URL, JIRA_EMAIL, JIRA_API_TOKEN or LINEAR_API_KEY.\n")
+	if a.tracker == "none" {
+		b.WriteString("# tickets:\n#   tracker: jira\n#   project: DBA\n\n")
+	} else {
+		fmt.Fprintf(&b, "tickets:\n  tracker: %s\n  project: %q\n\n", a.tracker, a.project)
+	}
+
+	b.WriteString("# Per-check settings, keyed by check ID. See `pgdoctor explain <check-id>`.\n")
+	b.WriteString("# checks:\n#   replication-slots:\n#     trend_sample_interval: 30s\n\n")
+
+	b.WriteString("# When pgdoctor fix may apply fixes that block reads or writes.\n")
+	b.WriteString("# maintenance_windows:\n#   - days: [sat, sun]\n#     start: \"02:00\"\n#     end: \"05:00\"\n#     timezone: Europe/London\n")
+
+	return b.String()
+}
+
+func availableCategories() []string {
+	var categories []string
+	for _, cat := range pgdoctor.Categories() {
+		categories = append(categories, string(cat))
+	}
+	return categories
+}
+
+func splitKnown(answer string, known []string) (selected, unknown []string) {
+	for _, item := range strings.Split(answer, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		if slices.Contains(known, item) {
+			selected = append(selected, item)
+		} else {
+			unknown = append(unknown, item)
+		}
+	}
+	return selected, unknown
+}
+
+// prompter reads answers line by line. After the first read error every
+// question returns its default, and the error is reported once at the end.
+type prompter struct {
+	in  *bufio.Reader
+	out io.Writer
+	err error
+}
+
+func (p *prompter) ask(question, def string) string {
+	if def != "" {
+		fmt.Fprintf(p.out, "%s [%s]: ", question, def)
+	} else {
+		fmt.Fprintf(p.out, "%s: ", question)
+	}
+
+	if p.err != nil {
+		fmt.Fprintln(p.out)
+		return def
+	}
+
+	line, err := p.in.ReadString('\n')
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			err = errors.New("input ended before setup finished")
+		}
+		p.err = err
+	}
+
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return def
+	}
+	return line
+}
+
+func (p *prompter) choose(question string, options []string, def string) string {
+	for {
+		answer := p.ask(fmt.Sprintf("%s (%s)", question, strings.Join(options, "/")), def)
+		if slices.Contains(options, answer) {
+			return answer
+		}
+		fmt.Fprintf(p.out, "  please answer one of: %s\n", strings.Join(options, ", "))
+	}
+}
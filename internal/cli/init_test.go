@@ -0,0 +1,51 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/fresha/pgdoctor/internal/config"
+)
+
+func TestPromptInit_WritesLoadableConfig(t *testing.T) {
+	input := strings.Join([]string{
+		"",                // default DSN
+		"azure",           // rejected provider
+		"aws",             //
+		"indexes, bogus",  // rejected category
+		"indexes, vacuum", //
+		"jira",            //
+		"DBA",             //
+	}, "\n") + "\n"
+
+	var out bytes.Buffer
+	answers, err := promptInit(strings.NewReader(input), &out)
+	require.NoError(t, err)
+	assert.Contains(t, out.String(), "please answer one of")
+	assert.Contains(t, out.String(), "unknown categories: bogus")
+
+	path := filepath.Join(t.TempDir(), "pgdoctor.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(renderConfig(answers)), 0o600))
+
+	t.Setenv("PGDOCTOR_DSN", "postgres://localhost/app")
+	cfg, err := config.Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, "postgres://localhost/app", cfg.DSN)
+	assert.Equal(t, "aws", cfg.Provider)
+	assert.Equal(t, []string{"indexes", "vacuum"}, cfg.Only)
+	assert.Equal(t, config.Tickets{Tracker: "jira", Project: "DBA"}, cfg.Tickets)
+	assert.Empty(t, cfg.Checks)
+}
+
+func TestPromptInit_EOF(t *testing.T) {
+	t.Parallel()
+
+	_, err := promptInit(strings.NewReader("postgres://localhost/app\n"), &bytes.Buffer{})
+	require.ErrorContains(t, err, "input ended")
+}
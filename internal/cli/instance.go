@@ -0,0 +1,77 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/fresha/pgdoctor/check"
+	"github.com/fresha/pgdoctor/internal/rds"
+)
+
+// instanceFlags name the cloud instance behind the database, so its
+// hardware can be read from the provider's API rather than passed by hand.
+type instanceFlags struct {
+	awsInstanceID string
+	awsRegion     string
+	awsMetrics    bool
+}
+
+func addInstanceFlags(cmd *cobra.Command, f *instanceFlags) {
+	cmd.Flags().StringVar(&f.awsInstanceID, "aws-db-instance-id", "", "Read instance class, memory, storage, and Multi-AZ from the RDS API for this DB instance identifier or ARN")
+	cmd.Flags().StringVar(&f.awsRegion, "aws-region", "", "Region of --aws-db-instance-id (default: AWS_REGION)")
+}
+
+func addHostMetricsFlag(cmd *cobra.Command, f *instanceFlags) {
+	cmd.Flags().BoolVar(&f.awsMetrics, "aws-metrics", false, "Read the last hour of CloudWatch CPU, memory, IOPS, and replica lag for --aws-db-instance-id so checks can tell host pressure from workload problems")
+}
+
+// describeInstance returns the metadata of the instance named by f, or nil
+// when none is named, with its host metrics when --aws-metrics is set.
+// Credentials come from the AWS environment variables.
+func describeInstance(ctx context.Context, f instanceFlags) (*check.InstanceMetadata, error) {
+	if f.awsInstanceID == "" {
+		if f.awsMetrics {
+			return nil, errors.New("--aws-metrics requires --aws-db-instance-id")
+		}
+		return nil, nil
+	}
+	client := &rds.Client{Region: f.awsRegion}
+	meta, err := client.DescribeInstance(ctx, f.awsInstanceID)
+	if err != nil {
+		return nil, fmt.Errorf("reading RDS instance %s: %w", f.awsInstanceID, err)
+	}
+	if f.awsMetrics {
+		if meta.HostMetrics, err = client.HostMetrics(ctx, f.awsInstanceID, rds.DefaultMetricsWindow); err != nil {
+			return nil, fmt.Errorf("reading CloudWatch metrics of %s: %w", f.awsInstanceID, err)
+		}
+	}
+	return meta, nil
+}
+
+// instanceSummary describes an instance in one line for report headers,
+// e.g. "db.r6g.xlarge, 4 vCPU, 32 GB RAM, 500 GB gp3, Multi-AZ".
+func instanceSummary(m *check.InstanceMetadata) string {
+	parts := []string{m.InstanceClass}
+	if m.VCPUCores > 0 {
+		parts = append(parts, fmt.Sprintf("%d vCPU", m.VCPUCores))
+	}
+	if m.MemoryGB > 0 {
+		parts = append(parts, fmt.Sprintf("%g GB RAM", m.MemoryGB))
+	}
+	storage := m.StorageType
+	if m.StorageGB > 0 {
+		storage = fmt.Sprintf("%d GB %s", m.StorageGB, m.StorageType)
+	}
+	if m.StorageIOPS > 0 {
+		storage += fmt.Sprintf(" (%d IOPS)", m.StorageIOPS)
+	}
+	parts = append(parts, storage)
+	if m.MultiAZ {
+		parts = append(parts, "Multi-AZ")
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,40 @@
+package cli
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/fresha/pgdoctor/check"
+)
+
+func TestInstanceSummary(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "db.r6g.xlarge, 4 vCPU, 32 GB RAM, 500 GB gp3 (12000 IOPS), Multi-AZ", instanceSummary(&check.InstanceMetadata{
+		InstanceClass: "db.r6g.xlarge",
+		VCPUCores:     4,
+		MemoryGB:      32,
+		StorageType:   "gp3",
+		StorageGB:     500,
+		StorageIOPS:   12000,
+		MultiAZ:       true,
+	}))
+	assert.Equal(t, "db.serverless, aurora", instanceSummary(&check.InstanceMetadata{
+		InstanceClass: "db.serverless",
+		StorageType:   "aurora",
+	}))
+}
+
+func TestDescribeInstance_MetricsRequireInstance(t *testing.T) {
+	t.Parallel()
+
+	meta, err := describeInstance(context.Background(), instanceFlags{})
+	require.NoError(t, err)
+	assert.Nil(t, meta)
+
+	_, err = describeInstance(context.Background(), instanceFlags{awsMetrics: true})
+	require.EqualError(t, err, "--aws-metrics requires --aws-db-instance-id")
+}
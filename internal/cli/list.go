@@ -2,7 +2,7 @@ package cli
 
 import (
 	"fmt"
-	"sort"
+	"slices"
 
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
@@ -19,54 +19,46 @@ func newListCommand() *cobra.Command {
 		Short: "List all available checks",
 		Long:  `List all available pgdoctor checks organized by category.`,
 		RunE: func(cmd *cobra.Command, _ []string) error {
-			checks := pgdoctor.AllChecks()
-
-			grouped := map[string][]check.Metadata{}
-			for _, pkg := range checks {
-				m := pkg.Metadata()
-				grouped[string(m.Category)] = append(grouped[string(m.Category)], m)
-			}
-
+			selected := pgdoctor.Categories()
 			if len(categories) > 0 {
-				filtered := map[string][]check.Metadata{}
+				var filtered []check.Category
 				for _, cat := range categories {
-					if checks, exists := grouped[cat]; exists {
-						filtered[cat] = checks
+					if slices.Contains(selected, check.Category(cat)) {
+						filtered = append(filtered, check.Category(cat))
 					} else {
 						fmt.Fprintf(cmd.OutOrStderr(), "Warning: unknown category '%s' - ignoring\n", cat)
 					}
 				}
 				if len(filtered) > 0 {
-					grouped = filtered
+					slices.Sort(filtered)
+					selected = slices.Compact(filtered)
 				}
 			}
 
-			categoryNames := make([]string, 0, len(grouped))
-			for cat := range grouped {
-				categoryNames = append(categoryNames, cat)
-			}
-			sort.Strings(categoryNames)
-
 			w := cmd.OutOrStdout()
 			fmt.Fprintln(w, "Available Checks:")
 			fmt.Fprintln(w, "─────────────────")
 			fmt.Fprintln(w)
 
-			for _, cat := range categoryNames {
+			total := 0
+			for _, cat := range selected {
 				categoryColor := color.New(color.FgCyan, color.Bold)
 				fmt.Fprintf(w, "%s:\n", categoryColor.Sprint(cat))
 
-				for _, c := range grouped[cat] {
-					fmt.Fprintf(w, "  • %s (%s/%s)\n",
+				for _, pkg := range pgdoctor.ChecksByCategory(cat) {
+					c := pkg.Metadata()
+					total++
+					fmt.Fprintf(w, "  • %s (%s/%s) %s\n",
 						color.New(color.Bold).Sprint(c.Name),
 						c.Category,
-						c.CheckID)
+						c.CheckID,
+						dimColor()(runtimeLabel(c)))
 					fmt.Fprintf(w, "    %s\n", c.Description)
 					fmt.Fprintln(w)
 				}
 			}
 
-			fmt.Fprintf(w, "Total: %d checks\n", len(checks))
+			fmt.Fprintf(w, "Total: %d checks\n", total)
 			fmt.Fprintln(w)
 			fmt.Fprintln(w, "Use 'pgdoctor explain <check-id>' for detailed information")
 			fmt.Fprintln(w)
@@ -79,3 +71,11 @@ func newListCommand() *cobra.Command {
 
 	return cmd
 }
+
+// runtimeLabel describes a check's estimated cost and production safety.
+func runtimeLabel(m check.Metadata) string {
+	if !m.ProductionSafe {
+		return fmt.Sprintf("[%s, not production-safe]", m.RuntimeClass)
+	}
+	return fmt.Sprintf("[%s]", m.RuntimeClass)
+}
@@ -0,0 +1,180 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/fresha/pgdoctor"
+	"github.com/fresha/pgdoctor/check"
+	"github.com/fresha/pgdoctor/internal/pglog"
+	"github.com/fresha/pgdoctor/internal/report"
+)
+
+type logsAnalyzeOptions struct {
+	runOptions
+	file   string
+	format string
+}
+
+func newLogsCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "logs",
+		Short: "Analyze PostgreSQL server logs",
+	}
+	cmd.AddCommand(newLogsAnalyzeCommand())
+	return cmd
+}
+
+func newLogsAnalyzeCommand() *cobra.Command {
+	opts := &logsAnalyzeOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "analyze --file <log> [DSN]",
+		Short: "Report deadlocks, autovacuum cancels, temp files, checkpoints, connection churn, and slow plans from a log",
+		Long: `Parse a PostgreSQL server log (stderr, csvlog, or jsonlog) and report
+problems that only show up there: deadlocks, autovacuum runs canceled by lock
+conflicts, queries spilling to temp files, checkpoints forced by WAL volume,
+connection churn, and the slowest auto_explain plans. Findings use the same
+categories and output formats as pgdoctor run.
+
+When a DSN is given, the live checks covering the same areas are run too and
+their results are noted on each log finding, and plans that sequentially scan
+tables flagged by table-seq-scans are reported.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if opts.file == "" {
+				return fmt.Errorf("--file is required")
+			}
+			logFormat, err := pglog.ParseFormat(opts.format)
+			if err != nil {
+				return fmt.Errorf("invalid --format: %w", err)
+			}
+			format, dest, err := parseOutput(opts.output)
+			if err != nil {
+				return err
+			}
+			if err := validateTextOrder(&opts.runOptions); err != nil {
+				return err
+			}
+			if err := validateFailOn(&opts.runOptions); err != nil {
+				return err
+			}
+
+			entries, err := readLog(opts.file, logFormat)
+			if err != nil {
+				return err
+			}
+			reports := pglog.Analyze(entries)
+			sort.SliceStable(reports, func(i, j int) bool {
+				return reports[i].Category < reports[j].Category
+			})
+
+			ctx := cmd.Context()
+			if len(args) > 0 {
+				conn, _, closeConn, err := openConnection(ctx, args[0], opts.connectionFlags)
+				if err != nil {
+					return err
+				}
+				defer closeConn()
+
+				var live []*check.Report
+				pgdoctor.Run(ctx, conn, pgdoctor.Options{
+					Checks:   pgdoctor.Filter(pgdoctor.AllChecks(), pglog.RelatedChecks(), nil),
+					OnReport: pgdoctor.Collect(&live),
+				})
+				pglog.CrossReference(entries, reports, live)
+			}
+
+			title := filepath.Base(opts.file)
+			window := describeLogWindow(entries)
+			w := cmd.OutOrStdout()
+
+			if format == "json" || format == "markdown" || format == "confluence" || format == "html" || format == "sarif" {
+				render := func(w io.Writer) error {
+					switch format {
+					case "markdown":
+						return formatMarkdown(w, title, window, reports)
+					case "confluence":
+						return formatConfluence(w, title, window, reports)
+					case "html":
+						return formatHTML(w, title, window, reports)
+					case "sarif":
+						return report.WriteSARIF(w, title, reports)
+					default:
+						return report.WriteJSON(w, reports)
+					}
+				}
+
+				var renderErr error
+				if dest != nil {
+					dest.Expand(time.Now(), "logs", strings.TrimSuffix(title, filepath.Ext(title)))
+					if renderErr = dest.Write(ctx, render); renderErr == nil {
+						fmt.Fprintf(os.Stderr, "Report written to %s\n", dest.Location)
+					}
+				} else {
+					renderErr = render(w)
+				}
+				if renderErr != nil {
+					fmt.Fprintf(os.Stderr, "Error: %v\n", renderErr)
+					return &SilentError{ExitCode: 1}
+				}
+				return failOnExit(opts.failOn, maxReportSeverity(reports))
+			}
+
+			fmt.Fprintf(w, "Log Analysis: %s\n", title)
+			fmt.Fprintf(w, "%s\n\n", dimColor()(window))
+
+			tr := &textReporter{w: w, opts: &opts.runOptions}
+			for _, r := range reports {
+				tr.onReport(r)
+			}
+			tr.flush()
+
+			fmt.Fprintln(w)
+			printSummary(w, tr.reports)
+
+			return failOnExit(opts.failOn, tr.maxSeverity)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.file, "file", "", "PostgreSQL log file to analyze (- for stdin)")
+	cmd.Flags().StringVar(&opts.format, "format", string(pglog.FormatAuto), "Log format: auto (default), stderr, csvlog, jsonlog")
+	cmd.Flags().StringVar(&opts.detail, "detail", string(detailBrief), "Detail level: summary, brief (default), verbose, debug")
+	cmd.Flags().BoolVar(&opts.hidePassing, "hide-passing", false, "Hide passing checks")
+	addTextOrderFlags(cmd, &opts.runOptions)
+	addFailOnFlag(cmd, &opts.runOptions)
+	cmd.Flags().StringVar(&opts.output, "output", "text", "Output format: text (default), json, markdown, confluence, html, sarif; or a .json/.md/.xhtml/.html/.sarif destination path or URL")
+	addConnectionFlags(cmd, &opts.connectionFlags)
+
+	return cmd
+}
+
+func readLog(path string, format pglog.Format) ([]pglog.Entry, error) {
+	var r io.Reader = os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("reading log: %w", err)
+		}
+		defer f.Close()
+		r = f
+	}
+	return pglog.Parse(r, format)
+}
+
+// describeLogWindow summarizes how much of the log was read, for report headers.
+func describeLogWindow(entries []pglog.Entry) string {
+	first, last := pglog.Window(entries)
+	if first.IsZero() {
+		return fmt.Sprintf("Entries: %d", len(entries))
+	}
+	return fmt.Sprintf("Entries: %d, %s to %s", len(entries),
+		first.Format(time.DateTime), last.Format(time.DateTime))
+}
@@ -0,0 +1,184 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/fresha/pgdoctor/check"
+	"github.com/fresha/pgdoctor/internal/buildinfo"
+	"github.com/fresha/pgdoctor/internal/report"
+)
+
+// anchorID returns the stable fragment identifier for a check or one of its findings.
+// It matches the check-id/finding-id form printed by the text renderer and accepted
+// by --only, so runbooks and alerts can link straight to a section of a published report.
+func anchorID(checkID, findingID string) string {
+	if findingID == "" || findingID == checkID {
+		return checkID
+	}
+	return checkID + "/" + findingID
+}
+
+// reportsByCategory groups reports by category, preserving the order in which
+// categories first appear.
+func reportsByCategory(reports []*check.Report) ([]check.Category, map[check.Category][]*check.Report) {
+	var order []check.Category
+	grouped := map[check.Category][]*check.Report{}
+	for _, r := range reports {
+		if _, ok := grouped[r.Category]; !ok {
+			order = append(order, r.Category)
+		}
+		grouped[r.Category] = append(grouped[r.Category], r)
+	}
+	return order, grouped
+}
+
+func formatMarkdown(w io.Writer, title, connection string, reports []*check.Report) error {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "# Database Health Report: %s\n\n", title)
+	if connection != "" {
+		fmt.Fprintf(&b, "Connection: %s\n\n", connection)
+	}
+	fmt.Fprintf(&b, "Generated by pgdoctor %s\n\n", buildinfo.Get())
+
+	writeMarkdownRisks(&b, report.TopRisks(reports, report.DefaultRiskLimit))
+
+	order, grouped := reportsByCategory(reports)
+
+	b.WriteString("## Contents\n\n")
+	for _, cat := range order {
+		fmt.Fprintf(&b, "- [%s](#%s)\n", cat, categoryAnchor(cat))
+		for _, r := range grouped[cat] {
+			fmt.Fprintf(&b, "  - [%s] [%s](#%s)\n", strings.ToUpper(r.Severity.String()), r.Name, anchorID(r.CheckID, ""))
+		}
+	}
+	b.WriteString("\n")
+
+	for _, cat := range order {
+		fmt.Fprintf(&b, "<a id=\"%s\"></a>\n\n## %s\n\n", categoryAnchor(cat), cat)
+
+		for _, r := range grouped[cat] {
+			fmt.Fprintf(&b, "<a id=\"%s\"></a>\n\n### [%s] %s (`%s`)\n\n",
+				anchorID(r.CheckID, ""), strings.ToUpper(r.Severity.String()), r.Name, r.CheckID)
+
+			for _, f := range r.Results {
+				if f.ID != r.CheckID {
+					fmt.Fprintf(&b, "<a id=\"%s\"></a>\n\n#### [%s] %s (`%s`)\n\n",
+						anchorID(r.CheckID, f.ID), strings.ToUpper(f.Severity.String()), f.Name, anchorID(r.CheckID, f.ID))
+				}
+				// Invisible when rendered, but lets tooling match findings across reports.
+				fmt.Fprintf(&b, "<!-- fingerprint: %s -->\n\n", f.Fingerprint)
+
+				if f.Details != "" {
+					details := strings.TrimRight(f.Details, "\n")
+					fence := codeFence(details)
+					fmt.Fprintf(&b, "%stext\n%s\n%s\n\n", fence, details, fence)
+				}
+
+				if f.Severity > check.SeverityOK && f.Confidence != check.ConfidenceHigh {
+					fmt.Fprintf(&b, "_Confidence: %s. Verify before acting._\n\n", f.Confidence)
+				}
+
+				if f.Severity > check.SeverityOK && f.DocsURL != "" {
+					fmt.Fprintf(&b, "[How to fix](%s)\n\n", f.DocsURL)
+				}
+
+				if f.Table != nil && len(f.Table.Rows) > 0 {
+					writeMarkdownTable(&b, f.Table)
+				}
+
+				if f.Severity > check.SeverityOK {
+					writeMarkdownFixes(&b, f.Fixes)
+				}
+			}
+		}
+	}
+
+	if _, err := io.WriteString(w, b.String()); err != nil {
+		return fmt.Errorf("writing markdown: %w", err)
+	}
+	return nil
+}
+
+// writeMarkdownRisks writes the executive summary: the top risks, worst
+// first, each linking to its finding.
+func writeMarkdownRisks(b *strings.Builder, risks []report.Risk) {
+	b.WriteString("## Top Risks\n\n")
+	if len(risks) == 0 {
+		b.WriteString("No warnings or failures: every check passed or was skipped.\n\n")
+		return
+	}
+	for i, risk := range risks {
+		fmt.Fprintf(b, "%d. **%s** [%s](#%s): %s\n", i+1, strings.ToUpper(risk.Severity.String()),
+			risk.Name, anchorID(risk.CheckID, risk.FindingID), risk.Impact)
+	}
+	b.WriteString("\n")
+}
+
+func categoryAnchor(cat check.Category) string {
+	return "category-" + string(cat)
+}
+
+func writeMarkdownFixes(b *strings.Builder, fixes []check.Fix) {
+	now, window := splitFixesByLock(fixes)
+	for _, group := range []struct {
+		title string
+		fixes []check.Fix
+	}{
+		{"Safe to run now", now},
+		{"Requires a maintenance window", window},
+	} {
+		if len(group.fixes) == 0 {
+			continue
+		}
+		var sql strings.Builder
+		for _, fix := range group.fixes {
+			sql.WriteString(fixStatement(fix) + "\n")
+		}
+		fence := codeFence(sql.String())
+		fmt.Fprintf(b, "**%s:**\n\n%ssql\n%s%s\n\n", group.title, fence, sql.String(), fence)
+	}
+}
+
+// codeFence returns a backtick fence longer than any run of backticks in
+// content, so details or SQL quoting Markdown cannot close the block early.
+func codeFence(content string) string {
+	longest, run := 0, 0
+	for _, r := range content {
+		if r == '`' {
+			run++
+			longest = max(longest, run)
+		} else {
+			run = 0
+		}
+	}
+	return strings.Repeat("`", max(3, longest+1))
+}
+
+func writeMarkdownTable(b *strings.Builder, table *check.Table) {
+	escape := func(s string) string {
+		s = strings.ReplaceAll(s, "|", `\|`)
+		return strings.ReplaceAll(s, "\n", " ")
+	}
+
+	b.WriteString("|")
+	for _, h := range table.Headers {
+		fmt.Fprintf(b, " %s |", escape(h))
+	}
+	b.WriteString("\n|")
+	for range table.Headers {
+		b.WriteString(" --- |")
+	}
+	b.WriteString("\n")
+
+	for _, row := range table.Rows {
+		b.WriteString("|")
+		for _, cell := range row.Cells {
+			fmt.Fprintf(b, " %s |", escape(cell))
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+}
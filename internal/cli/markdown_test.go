@@ -0,0 +1,87 @@
+package cli
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/fresha/pgdoctor/check"
+)
+
+func TestFormatMarkdown_Anchors(t *testing.T) {
+	t.Parallel()
+
+	seq := check.NewReport(check.Metadata{CheckID: "sequence-health", Name: "Sequence Health", Category: check.CategorySchema})
+	seq.AddFinding(check.Finding{
+		ID:       "near-exhaustion",
+		Name:     "Sequences Near Exhaustion",
+		Severity: check.SeverityFail,
+		Details:  "1 sequence above 90%",
+		Table: &check.Table{
+			Headers: []string{"Sequence", "Usage"},
+			Rows:    []check.TableRow{{Cells: []string{"public.orders_id_seq", "95%"}, Severity: check.SeverityFail}},
+		},
+	})
+
+	ver := check.NewReport(check.Metadata{CheckID: "pg-version", Name: "PG Version", Category: check.CategoryConfigs})
+	ver.AddFinding(check.Finding{ID: "pg-version", Name: "PG Version", Severity: check.SeverityOK})
+
+	var buf bytes.Buffer
+	require.NoError(t, formatMarkdown(&buf, "db.internal/app", "tcp db.internal:5432", []*check.Report{ver, seq}))
+	out := buf.String()
+
+	assert.Contains(t, out, `<a id="category-configs"></a>`)
+	assert.Contains(t, out, `<a id="pg-version"></a>`)
+	assert.Contains(t, out, `<a id="sequence-health/near-exhaustion"></a>`)
+	assert.Contains(t, out, "[Sequence Health](#sequence-health)")
+	assert.Contains(t, out, "## Top Risks\n\n1. **FAIL** [Sequences Near Exhaustion](#sequence-health/near-exhaustion): 1 sequence above 90%\n\n## Contents")
+	assert.Contains(t, out, "| public.orders_id_seq | 95% |")
+	assert.Contains(t, out, "<!-- fingerprint: "+check.Fingerprint("sequence-health", "near-exhaustion", "")+" -->")
+	assert.Less(t, bytes.Index(buf.Bytes(), []byte("## configs")), bytes.Index(buf.Bytes(), []byte("## schema")))
+}
+
+func TestFormatMarkdown_Confidence(t *testing.T) {
+	t.Parallel()
+
+	r := check.NewReport(check.Metadata{CheckID: "index-bloat", Name: "Index Bloat", Category: check.CategoryIndexes})
+	r.AddFinding(check.Finding{ID: "high-bloat", Name: "High Bloat", Severity: check.SeverityWarn, Confidence: check.ConfidenceMedium})
+	r.AddFinding(check.Finding{ID: "large-bloat", Name: "Large Bloat", Severity: check.SeverityOK, Confidence: check.ConfidenceMedium})
+
+	var buf bytes.Buffer
+	require.NoError(t, formatMarkdown(&buf, "db.internal/app", "", []*check.Report{r}))
+
+	assert.Equal(t, 1, strings.Count(buf.String(), "_Confidence: medium. Verify before acting._"), "only non-passing findings carry the note")
+}
+
+func TestFormatMarkdown_FixesByLock(t *testing.T) {
+	t.Parallel()
+
+	r := check.NewReport(check.Metadata{CheckID: "table-bloat", Name: "Table Bloat", Category: check.CategoryVacuum})
+	r.AddFinding(check.Finding{ID: "large-bloated-tables", Name: "Large Table Bloat", Severity: check.SeverityWarn, Fixes: []check.Fix{
+		{SQL: `VACUUM FULL "public"."orders"`, Risk: check.RiskHigh, Lock: check.LockExclusive},
+		{SQL: `ALTER TABLE "public"."orders" SET (autovacuum_vacuum_scale_factor = 0.01)`, Risk: check.RiskLow, Lock: check.LockOnline},
+	}})
+
+	var buf bytes.Buffer
+	require.NoError(t, formatMarkdown(&buf, "db.internal/app", "", []*check.Report{r}))
+
+	out := buf.String()
+	assert.Contains(t, out, "**Safe to run now:**\n\n```sql\nALTER TABLE \"public\".\"orders\" SET (autovacuum_vacuum_scale_factor = 0.01);\n```")
+	assert.Contains(t, out, "**Requires a maintenance window:**\n\n```sql\nVACUUM FULL \"public\".\"orders\";  -- high risk: review and apply by hand\n```")
+}
+
+func TestFormatMarkdown_FenceLongerThanContent(t *testing.T) {
+	t.Parallel()
+
+	r := check.NewReport(check.Metadata{CheckID: "query-patterns", Name: "Query Patterns", Category: check.CategoryPerformance})
+	r.AddFinding(check.Finding{ID: "slow", Name: "Slow", Severity: check.SeverityWarn, Details: "Query:\n```\nSELECT 1\n````"})
+
+	var buf bytes.Buffer
+	require.NoError(t, formatMarkdown(&buf, "db.internal/app", "", []*check.Report{r}))
+
+	assert.Contains(t, buf.String(), "`````text\nQuery:\n```\nSELECT 1\n````\n`````\n", "the fence outgrows the longest backtick run")
+	assert.Equal(t, "```", codeFence("no backticks"))
+}
@@ -0,0 +1,134 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/fresha/pgdoctor/check"
+)
+
+const (
+	sortCategory = "category"
+	sortSeverity = "severity"
+	sortDuration = "duration"
+
+	groupSeverity = "severity"
+)
+
+// severityGroups is the order --group-by severity prints groups in.
+var severityGroups = []check.Severity{check.SeverityFail, check.SeverityWarn, check.SeverityOK, check.SeveritySkip}
+
+func addTextOrderFlags(cmd *cobra.Command, opts *runOptions) {
+	cmd.Flags().StringVar(&opts.sortBy, "sort", sortCategory, "Text output order: category (default), severity, duration")
+	cmd.Flags().StringVar(&opts.groupBy, "group-by", "", "Group text output findings across checks: severity")
+}
+
+func validateTextOrder(opts *runOptions) error {
+	switch opts.sortBy {
+	case "", sortCategory, sortSeverity, sortDuration:
+	default:
+		return fmt.Errorf("invalid --sort %q: must be category, severity, or duration", opts.sortBy)
+	}
+	switch opts.groupBy {
+	case "", groupSeverity:
+	default:
+		return fmt.Errorf("invalid --group-by %q: must be severity", opts.groupBy)
+	}
+	return nil
+}
+
+// streamsText reports whether text output can be printed as each check
+// finishes. Any other order needs every report before printing starts.
+func streamsText(opts *runOptions) bool {
+	return (opts.sortBy == "" || opts.sortBy == sortCategory) && opts.groupBy == ""
+}
+
+// sortReports returns reports in --sort order. Reports arrive in category
+// order, so ties keep it.
+func sortReports(reports []*check.Report, by string) []*check.Report {
+	sorted := make([]*check.Report, len(reports))
+	copy(sorted, reports)
+	switch by {
+	case sortSeverity:
+		sort.SliceStable(sorted, func(i, j int) bool {
+			return sorted[i].Severity > sorted[j].Severity
+		})
+	case sortDuration:
+		sort.SliceStable(sorted, func(i, j int) bool {
+			return sorted[i].Duration > sorted[j].Duration
+		})
+	}
+	return sorted
+}
+
+// printSorted prints reports in --sort order without category headers.
+func printSorted(w io.Writer, reports []*check.Report, opts *runOptions) {
+	for _, r := range sortReports(reports, opts.sortBy) {
+		if r.Severity == check.SeverityOK && opts.hidePassing {
+			continue
+		}
+		if opts.detail == string(detailSummary) {
+			printCheckSummary(w, r, opts)
+		} else {
+			printCheckReport(w, r, opts)
+		}
+	}
+}
+
+// printGroupedBySeverity prints every FAIL finding across checks first, then
+// WARN, PASS and SKIP. In summary mode whole checks are grouped by their
+// overall severity instead of individual findings.
+func printGroupedBySeverity(w io.Writer, reports []*check.Report, opts *runOptions) {
+	sorted := sortReports(reports, opts.sortBy)
+	first := true
+
+	for _, severity := range severityGroups {
+		if severity == check.SeverityOK && opts.hidePassing {
+			continue
+		}
+
+		var lines strings.Builder
+		count := 0
+		for _, r := range sorted {
+			switch {
+			case severity == check.SeveritySkip || opts.detail == string(detailSummary):
+				if r.Severity != severity {
+					continue
+				}
+				if opts.detail == string(detailSummary) {
+					printCheckSummary(&lines, r, opts)
+				} else {
+					printCheckReport(&lines, r, opts)
+				}
+				count++
+			case r.Severity != check.SeveritySkip:
+				for _, result := range sortedFindings(r.Results) {
+					if result.Severity != severity {
+						continue
+					}
+					printSubcheck(&lines, r, result, opts)
+					count++
+				}
+			}
+		}
+		if count == 0 {
+			continue
+		}
+
+		// Buffered so the header can show the count.
+
+		if !first {
+			fmt.Fprintln(w)
+		}
+		first = false
+		label, colorFunc := severityDisplay(severity)
+		title := fmt.Sprintf("%s (%d)", label, count)
+		fmt.Fprintln(w, colorFunc(title))
+		fmt.Fprintln(w, strings.Repeat("─", len(title)))
+		fmt.Fprint(w, lines.String())
+	}
+}
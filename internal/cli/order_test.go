@@ -0,0 +1,111 @@
+package cli
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/fresha/pgdoctor/check"
+)
+
+func orderTestReports() []*check.Report {
+	ver := check.NewReport(check.Metadata{CheckID: "pg-version", Name: "PG Version", Category: check.CategoryConfigs})
+	ver.AddFinding(check.Finding{ID: "pg-version", Name: "PG Version", Severity: check.SeverityOK})
+	ver.Duration = 5 * time.Millisecond
+
+	idx := check.NewReport(check.Metadata{CheckID: "index-bloat", Name: "Index Bloat", Category: check.CategoryIndexes})
+	idx.AddFinding(check.Finding{ID: "high-bloat", Name: "High Bloat", Severity: check.SeverityWarn, Details: "2 indexes"})
+	idx.AddFinding(check.Finding{ID: "large-bloat", Name: "Large Bloat", Severity: check.SeverityOK})
+	idx.Duration = 900 * time.Millisecond
+
+	seq := check.NewReport(check.Metadata{CheckID: "sequence-health", Name: "Sequence Health", Category: check.CategorySchema})
+	seq.AddFinding(check.Finding{ID: "near-exhaustion", Name: "Sequences Near Exhaustion", Severity: check.SeverityFail, Details: "1 sequence above 90%"})
+	seq.AddFinding(check.Finding{ID: "high-usage", Name: "High Usage", Severity: check.SeverityWarn, Details: "3 sequences above 75%"})
+	seq.Duration = 40 * time.Millisecond
+
+	return []*check.Report{ver, idx, seq}
+}
+
+func reportIDs(reports []*check.Report) []string {
+	ids := make([]string, len(reports))
+	for i, r := range reports {
+		ids[i] = r.CheckID
+	}
+	return ids
+}
+
+func TestSortReports(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		by       string
+		expected []string
+	}{
+		{by: sortCategory, expected: []string{"pg-version", "index-bloat", "sequence-health"}},
+		{by: sortSeverity, expected: []string{"sequence-health", "index-bloat", "pg-version"}},
+		{by: sortDuration, expected: []string{"index-bloat", "sequence-health", "pg-version"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.by, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.expected, reportIDs(sortReports(orderTestReports(), tt.by)))
+		})
+	}
+}
+
+func TestValidateTextOrder(t *testing.T) {
+	t.Parallel()
+
+	assert.NoError(t, validateTextOrder(&runOptions{sortBy: sortDuration, groupBy: groupSeverity}))
+	assert.NoError(t, validateTextOrder(&runOptions{}))
+	assert.ErrorContains(t, validateTextOrder(&runOptions{sortBy: "name"}), `invalid --sort "name"`)
+	assert.ErrorContains(t, validateTextOrder(&runOptions{groupBy: "category"}), `invalid --group-by "category"`)
+}
+
+func TestTextReporter_GroupBySeverity(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	opts := &runOptions{detail: string(detailBrief), sortBy: sortCategory, groupBy: groupSeverity, hidePassing: true}
+	tr := &textReporter{w: &buf, opts: opts}
+	for _, r := range orderTestReports() {
+		tr.onReport(r)
+	}
+	assert.Empty(t, buf.String(), "grouped output waits for flush")
+
+	tr.flush()
+	out := buf.String()
+
+	failAt := strings.Index(out, "FAIL (1)")
+	warnAt := strings.Index(out, "WARN (2)")
+	assert.GreaterOrEqual(t, failAt, 0)
+	assert.Greater(t, warnAt, failAt)
+	assert.Greater(t, strings.Index(out, "(sequence-health/near-exhaustion)"), failAt)
+	assert.Less(t, strings.Index(out, "(index-bloat/high-bloat)"), strings.Index(out, "(sequence-health/high-usage)"))
+	assert.Greater(t, strings.Index(out, "(index-bloat/high-bloat)"), warnAt)
+	assert.NotContains(t, out, "PASS")
+	assert.NotContains(t, out, "INDEXES", "category headers are not printed when grouping")
+	assert.Equal(t, check.SeverityFail, tr.maxSeverity)
+}
+
+func TestTextReporter_SortStreamsOnlyByCategory(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	tr := &textReporter{w: &buf, opts: &runOptions{detail: string(detailSummary), sortBy: sortSeverity}}
+	for _, r := range orderTestReports() {
+		tr.onReport(r)
+	}
+	assert.Empty(t, buf.String())
+
+	tr.flush()
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Len(t, lines, 3)
+	assert.Contains(t, lines[0], "(sequence-health)")
+	assert.Contains(t, lines[2], "(pg-version)")
+}
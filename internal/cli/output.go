@@ -85,10 +85,13 @@ func printCheckReport(w io.Writer, report *check.Report, opts *runOptions) {
 		if result.Severity != check.SeverityOK && result.Details != "" {
 			fmt.Fprintf(w, "%s\n", indent(result.Details, 2))
 		}
+		printConfidence(w, result)
 		if result.Table != nil {
 			fmt.Fprintln(w)
 			printTable(w, result.Table, 2, opts)
 		}
+		printFixes(w, result, opts)
+		printDocsLink(w, result, opts)
 	} else {
 		fmt.Fprintf(w, "%s %s %s%s\n",
 			colorFunc(fmt.Sprintf("[%s]", label)),
@@ -96,16 +99,7 @@ func printCheckReport(w io.Writer, report *check.Report, opts *runOptions) {
 			dimFunc(fmt.Sprintf("(%s)", report.CheckID)),
 			timingStr)
 
-		sortedResults := make([]check.Finding, len(report.Results))
-		copy(sortedResults, report.Results)
-		sort.Slice(sortedResults, func(i, j int) bool {
-			if sortedResults[i].Severity != sortedResults[j].Severity {
-				return sortedResults[i].Severity < sortedResults[j].Severity
-			}
-			return sortedResults[i].Name < sortedResults[j].Name
-		})
-
-		for _, result := range sortedResults {
+		for _, result := range sortedFindings(report.Results) {
 			printSubcheck(w, report, result, opts)
 		}
 	}
@@ -117,6 +111,19 @@ func printCheckReport(w io.Writer, report *check.Report, opts *runOptions) {
 	}
 }
 
+// sortedFindings orders a check's findings by severity, then name.
+func sortedFindings(results []check.Finding) []check.Finding {
+	sorted := make([]check.Finding, len(results))
+	copy(sorted, results)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Severity != sorted[j].Severity {
+			return sorted[i].Severity < sorted[j].Severity
+		}
+		return sorted[i].Name < sorted[j].Name
+	})
+	return sorted
+}
+
 func printSubcheck(w io.Writer, report *check.Report, result check.Finding, opts *runOptions) {
 	label, colorFunc := severityDisplay(result.Severity)
 	dimFunc := dimColor()
@@ -134,12 +141,16 @@ func printSubcheck(w io.Writer, report *check.Report, result check.Finding, opts
 	if result.Severity != check.SeverityOK && result.Details != "" {
 		fmt.Fprintf(w, "%s\n", indent(result.Details, 2))
 	}
+	printConfidence(w, result)
 
 	if result.Table != nil {
 		fmt.Fprintln(w)
 		printTable(w, result.Table, 2, opts)
 	}
 
+	printFixes(w, result, opts)
+	printDocsLink(w, result, opts)
+
 	if opts.detail == string(detailDebug) && result.Debug != "" {
 		fmt.Fprintln(w)
 		fmt.Fprintln(w, "  Debug:")
@@ -147,6 +158,70 @@ func printSubcheck(w io.Writer, report *check.Report, result check.Finding, opts
 	}
 }
 
+// printConfidence flags non-passing findings that rest on estimates or
+// heuristics, so responders verify them before acting.
+func printConfidence(w io.Writer, result check.Finding) {
+	if result.Severity <= check.SeverityOK || result.Confidence == check.ConfidenceHigh {
+		return
+	}
+	fmt.Fprintf(w, "  %s\n", dimColor()(fmt.Sprintf("Confidence: %s (verify before acting)", result.Confidence)))
+}
+
+// printDocsLink points responders at remediation guidance for non-passing findings.
+func printDocsLink(w io.Writer, result check.Finding, opts *runOptions) {
+	if !showTiming(opts) || result.Severity <= check.SeverityOK || result.DocsURL == "" {
+		return
+	}
+	fmt.Fprintf(w, "  %s\n", dimColor()("Docs: "+result.DocsURL))
+}
+
+// printFixes lists a non-passing finding's fix statements at verbose detail,
+// separating those safe to run now from those needing a maintenance window.
+func printFixes(w io.Writer, result check.Finding, opts *runOptions) {
+	if !showTiming(opts) || result.Severity <= check.SeverityOK || len(result.Fixes) == 0 {
+		return
+	}
+	now, window := splitFixesByLock(result.Fixes)
+	for _, group := range []struct {
+		title string
+		fixes []check.Fix
+	}{
+		{"Fixes, safe to run now:", now},
+		{"Fixes, require a maintenance window:", window},
+	} {
+		if len(group.fixes) == 0 {
+			continue
+		}
+		fmt.Fprintln(w)
+		fmt.Fprintf(w, "  %s\n", group.title)
+		for _, fix := range group.fixes {
+			fmt.Fprintf(w, "    %s\n", fixStatement(fix))
+		}
+	}
+}
+
+// splitFixesByLock separates online fixes from those that block reads or
+// writes.
+func splitFixesByLock(fixes []check.Fix) (now, window []check.Fix) {
+	for _, fix := range fixes {
+		if fix.NeedsMaintenanceWindow() {
+			window = append(window, fix)
+		} else {
+			now = append(now, fix)
+		}
+	}
+	return now, window
+}
+
+// fixStatement renders a fix as SQL, noting high-risk fixes that
+// pgdoctor fix never applies.
+func fixStatement(fix check.Fix) string {
+	if fix.Risk == check.RiskHigh {
+		return fix.SQL + ";  -- high risk: review and apply by hand"
+	}
+	return fix.SQL + ";"
+}
+
 func printTable(w io.Writer, table *check.Table, indentSpaces int, opts *runOptions) {
 	if len(table.Rows) == 0 {
 		return
@@ -0,0 +1,14 @@
+-- name: ClusterIdentity :one
+-- The cluster a host belongs to and the timeline it is on, so fleet runs can
+-- spot hosts that stopped following their group. A standby reports the
+-- timeline its WAL receiver is streaming, which moves to the new timeline
+-- as soon as it follows a promoted primary.
+SELECT
+  s.system_identifier::text AS system_identifier
+  , COALESCE(
+    (SELECT r.received_tli FROM pg_stat_wal_receiver AS r WHERE r.received_tli IS NOT NULL)
+    , c.timeline_id
+  )::integer AS timeline_id
+  , PG_IS_IN_RECOVERY()::boolean AS in_recovery
+FROM pg_control_system() AS s
+CROSS JOIN pg_control_checkpoint() AS c;
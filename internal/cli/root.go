@@ -1,3 +1,4 @@
+// Package cli implements the pgdoctor command-line interface.
 package cli
 
 import (
@@ -5,6 +6,10 @@ import (
 	"github.com/spf13/cobra"
 )
 
+// devCommands are developer commands compiled in by build tags, such as
+// devtest (-tags devtest).
+var devCommands []func() *cobra.Command
+
 func Execute(version string) error {
 	cmd := &cobra.Command{
 		Use:   "pgdoctor",
@@ -39,6 +44,18 @@ all production databases should pass.`,
 	cmd.AddCommand(newRunCommand())
 	cmd.AddCommand(newListCommand())
 	cmd.AddCommand(newExplainCommand())
+	cmd.AddCommand(newGrantsCommand())
+	cmd.AddCommand(newTuneCommand())
+	cmd.AddCommand(newInitCommand())
+	cmd.AddCommand(newCalibrateCommand())
+	cmd.AddCommand(newAnalyzeSchemaCommand())
+	cmd.AddCommand(newLogsCommand())
+	cmd.AddCommand(newFixCommand())
+	cmd.AddCommand(newSimulateCommand())
+	cmd.AddCommand(newVersionCommand())
+	for _, newCommand := range devCommands {
+		cmd.AddCommand(newCommand())
+	}
 
 	cmd.SetHelpCommand(&cobra.Command{Hidden: true})
 
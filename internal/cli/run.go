@@ -2,16 +2,19 @@ package cli
 
 import (
 	"fmt"
-	"net/url"
+	"io"
 	"os"
-	"sort"
+	"strconv"
 	"strings"
+	"time"
 
-	"github.com/jackc/pgx/v5"
 	"github.com/spf13/cobra"
 
 	"github.com/fresha/pgdoctor"
 	"github.com/fresha/pgdoctor/check"
+	"github.com/fresha/pgdoctor/internal/config"
+	"github.com/fresha/pgdoctor/internal/dsn"
+	"github.com/fresha/pgdoctor/internal/report"
 )
 
 type detailLevel string
@@ -29,7 +32,29 @@ type runOptions struct {
 	preset      string
 	detail      string
 	hidePassing bool
+	sortBy      string
+	groupBy     string
 	output      string
+	maxRuntime  string
+	deepBloat   int
+	deepToast   int
+	schema      string
+	queryRate   float64
+	previous    string
+	baseline    string
+	writeBase   bool
+	connectionFlags
+	instanceFlags
+	tickets    string
+	ticketProj string
+	ticketType string
+	auditLog   bool
+	configPath string
+	failOn     string
+	// Fleet mode: run against every host in hostsFile instead of one DSN.
+	hostsFile   string
+	concurrency int
+	hostTimeout time.Duration
 }
 
 func newRunCommand() *cobra.Command {
@@ -42,41 +67,81 @@ func newRunCommand() *cobra.Command {
 potential issues, misconfigurations, or areas for optimization.
 
 By default, all checks are shown in summary mode. Use --detail to control
-the level of detail, and --hide-passing to only show failures and warnings.`,
+the level of detail, and --hide-passing to only show failures and warnings.
+
+With --hosts-file, the checks run against every database in the file, a few
+hosts at a time, and the output compares severities across hosts.`,
 		Args: cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			// Resolve DSN: positional argument > environment variable
-			var dsn string
-			if len(args) > 0 {
-				dsn = args[0]
-			} else {
-				dsn = os.Getenv("PGDOCTOR_DSN")
+			cfg, err := loadConfig(opts.configPath)
+			if err != nil {
+				return err
 			}
-			if dsn == "" {
-				return fmt.Errorf("connection string required: pgdoctor run <DSN> or set PGDOCTOR_DSN environment variable")
+			applyConfigDefaults(cmd, opts, cfg)
+
+			var connString string
+			if opts.hostsFile == "" {
+				connString, err = resolveDSN(args, cfg)
+				if err != nil {
+					return err
+				}
 			}
 
-			// Default to 'brief' detail when --only is used
-			if len(opts.only) > 0 && !cmd.Flags().Changed("detail") {
-				opts.detail = string(detailBrief)
+			maxRuntime, err := check.ParseRuntimeClass(opts.maxRuntime)
+			if err != nil {
+				return fmt.Errorf("invalid --max-runtime-class: %w", err)
 			}
 
-			ctx := cmd.Context()
+			tracker, err := newTracker(opts.tickets, opts.ticketProj, opts.ticketType)
+			if err != nil {
+				return err
+			}
 
-			conn, err := pgx.Connect(ctx, dsn)
+			format, dest, err := parseOutput(opts.output)
 			if err != nil {
-				fmt.Fprintf(os.Stderr, "Error: failed to connect to database: %v\n", err)
-				return &SilentError{ExitCode: 2}
+				return err
+			}
+			if err := validateTextOrder(opts); err != nil {
+				return err
+			}
+			if err := validateFailOn(opts); err != nil {
+				return err
+			}
+			if opts.queryRate < 0 {
+				return fmt.Errorf("invalid --query-rate-limit %g: must be positive", opts.queryRate)
+			}
+			if opts.hostsFile != "" {
+				if err := validateFleet(opts, args, format); err != nil {
+					return err
+				}
 			}
-			defer conn.Close(ctx)
 
-			// Set statement_timeout so PostgreSQL kills individual slow queries.
-			if _, err := conn.Exec(ctx, fmt.Sprintf("SET statement_timeout = %d", pgdoctor.DefaultStatementTimeoutMs)); err != nil {
-				fmt.Fprintf(os.Stderr, "Error: failed to set statement_timeout: %v\n", err)
-				return &SilentError{ExitCode: 2}
+			var previous map[string]check.Severity
+			if opts.previous != "" {
+				previous, err = readPrevious(opts.previous)
+				if err != nil {
+					return err
+				}
+			}
+			var baseline *pgdoctor.Baseline
+			if opts.writeBase && opts.baseline == "" {
+				opts.baseline = defaultBaselinePath
 			}
+			if opts.baseline != "" && !opts.writeBase {
+				baseline, err = readBaseline(opts.baseline)
+				if err != nil {
+					return err
+				}
+			}
+
+			// Default to 'brief' detail when --only is used
+			if len(opts.only) > 0 && !cmd.Flags().Changed("detail") {
+				opts.detail = string(detailBrief)
+			}
+
+			ctx := cmd.Context()
 
-			allChecks := pgdoctor.AllChecks()
+			allChecks := append(pgdoctor.AllChecks(), cfg.CustomCheckPackages()...)
 
 			// Apply preset filter
 			if opts.preset != presetAll {
@@ -89,84 +154,151 @@ the level of detail, and --hide-passing to only show failures and warnings.`,
 			}
 
 			// Validate and apply filters
-			validOnly, invalidOnly := pgdoctor.ValidateFilters(allChecks, opts.only)
-			validIgnored, invalidIgnored := pgdoctor.ValidateFilters(allChecks, opts.ignored)
+			validOnly, validIgnored, err := validateFilters(allChecks, opts.only, opts.ignored)
+			if err != nil {
+				return err
+			}
+
+			checks := pgdoctor.Filter(allChecks, validOnly, validIgnored)
+			checks = pgdoctor.FilterByRuntimeClass(checks, maxRuntime)
+			pgdoctor.SortByPriority(checks, cfg.Priorities())
 
-			var allInvalid []string
-			allInvalid = append(allInvalid, invalidOnly...)
-			allInvalid = append(allInvalid, invalidIgnored...)
+			runOpts := pgdoctor.Options{
+				Checks:   checks,
+				Config:   cfg.Checks,
+				Previous: previous,
+				Baseline: baseline,
+
+				SeverityOverrides: cfg.SeverityOverrides(),
 
-			if len(allInvalid) > 0 {
-				fmt.Fprintf(os.Stderr, "Warning: ignoring invalid filter(s): %v\n\n", allInvalid)
+				IgnoreObjects: cfg.ObjectFilter(),
+				Schema:        opts.schema,
+
+				QueryRateLimit: opts.queryRate,
+			}
+			if opts.deepBloat > 0 {
+				runOpts.Config = withSetting(runOpts.Config, "deep_bloat_top", strconv.Itoa(opts.deepBloat), "table-bloat", "index-bloat")
+			}
+			if opts.deepToast > 0 {
+				runOpts.Config = withSetting(runOpts.Config, "deep_sample_columns", strconv.Itoa(opts.deepToast), "toast-storage")
+			}
+			if err := validateDeepModes(checks, runOpts.Config, maxRuntime); err != nil {
+				return err
 			}
 
-			if len(opts.only) > 0 && len(validOnly) == 0 {
-				fmt.Fprintf(os.Stderr, "Error: no valid checks found for --only filter(s): %v\n", invalidOnly)
-				return &SilentError{ExitCode: 1}
+			if opts.hostsFile != "" {
+				return runFleetCommand(ctx, cmd.OutOrStdout(), opts, format, dest, runOpts)
 			}
 
-			checks := pgdoctor.Filter(allChecks, validOnly, validIgnored)
-			sortChecksByCategory(checks)
+			runOpts.Instance, err = describeInstance(ctx, opts.instanceFlags)
+			if err != nil {
+				return err
+			}
 
-			runOpts := pgdoctor.Options{
-				Checks: checks,
+			conn, connPath, closeConn, err := openConnection(ctx, connString, opts.connectionFlags)
+			if err != nil {
+				return err
+			}
+			defer closeConn()
+			if opts.queryRate > 0 {
+				if err := pgdoctor.ApplyLowImpactSettings(ctx, conn); err != nil {
+					return err
+				}
+			}
+			if opts.schema != "" {
+				if err := pgdoctor.ValidateSchema(ctx, conn, opts.schema); err != nil {
+					return err
+				}
+			}
+			dbLabel := schemaLabel(dsn.Label(connString), opts.schema)
+
+			startedAt := time.Now()
+			recordAudit := func(reports []*check.Report) {
+				if !opts.auditLog {
+					return
+				}
+				run := newAuditRun(startedAt, cmd.Root().Version, reports)
+				if err := writeAuditRun(ctx, conn, run); err != nil {
+					fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+				}
 			}
 
-			// JSON output: batch collect then render
-			if opts.output == "json" {
+			// Structured output: batch collect then render
+			if format == "json" || format == "markdown" || format == "confluence" || format == "html" || format == "sarif" {
 				var reports []*check.Report
 				runOpts.OnReport = pgdoctor.Collect(&reports)
 				pgdoctor.Run(ctx, conn, runOpts)
+				recordAudit(reports)
+
+				render := func(w io.Writer) error {
+					switch format {
+					case "markdown":
+						return formatMarkdown(w, dbLabel, connPath, reports)
+					case "confluence":
+						return formatConfluence(w, dbLabel, connPath, reports)
+					case "html":
+						return formatHTML(w, dbLabel, connPath, reports)
+					case "sarif":
+						return report.WriteSARIF(w, dbLabel, reports)
+					default:
+						return report.WriteJSON(w, reports)
+					}
+				}
 
-				w := cmd.OutOrStdout()
-				if err := formatJSON(w, reports); err != nil {
-					fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				var renderErr error
+				if dest != nil {
+					dest.Expand(startedAt, conn.Config().Host, conn.Config().Database)
+					if renderErr = dest.Write(ctx, render); renderErr == nil {
+						fmt.Fprintf(os.Stderr, "Report written to %s\n", dest.Location)
+					}
+				} else {
+					renderErr = render(cmd.OutOrStdout())
+				}
+				if renderErr != nil {
+					fmt.Fprintf(os.Stderr, "Error: %v\n", renderErr)
 					return &SilentError{ExitCode: 1}
 				}
-				return nil
+				if tracker != nil {
+					// Keep stdout machine-readable.
+					syncTickets(ctx, os.Stderr, tracker, dbLabel, reports)
+				}
+				if err := finishBaseline(os.Stderr, opts, baseline, reports, startedAt); err != nil {
+					return err
+				}
+				return failOnExit(opts.failOn, maxReportSeverity(reports))
 			}
 
 			// Text output: stream results with category headers
 			w := cmd.OutOrStdout()
-			dbLabel := parseDSNLabel(dsn)
-			fmt.Fprintf(w, "Database Health Check: %s\n\n", dbLabel)
-
-			var reports []*check.Report
-			var currentCategory string
-			maxSeverity := check.SeverityOK
-
-			runOpts.OnReport = func(r *check.Report) {
-				reports = append(reports, r)
-				if r.Severity > maxSeverity {
-					maxSeverity = r.Severity
-				}
-
-				// Print category header on transition
-				cat := string(r.Category)
-				if cat != currentCategory {
-					if currentCategory != "" {
-						fmt.Fprintln(w)
-					}
-					title := strings.ToUpper(cat)
-					fmt.Fprintln(w, title)
-					fmt.Fprintln(w, strings.Repeat("─", len(title)))
-					currentCategory = cat
-				}
-
-				if r.Severity == check.SeverityOK && opts.hidePassing {
-					return
-				}
-
-				if opts.detail == string(detailSummary) {
-					printCheckSummary(w, r, opts)
-				} else {
-					printCheckReport(w, r, opts)
+			fmt.Fprintf(w, "Database Health Check: %s\n", dbLabel)
+			fmt.Fprintf(w, "%s\n", dimColor()("Connection: "+connPath))
+			if runOpts.Instance != nil {
+				fmt.Fprintf(w, "%s\n", dimColor()("Instance: "+instanceSummary(runOpts.Instance)))
+				if m := runOpts.Instance.HostMetrics; m != nil {
+					fmt.Fprintf(w, "%s\n", dimColor()("Host: "+m.Summary()))
 				}
 			}
+			for _, p := range cfg.Packs {
+				fmt.Fprintf(w, "%s\n", dimColor()("Policy: "+p.String()))
+			}
+			fmt.Fprintln(w)
+
+			tr := &textReporter{w: w, opts: opts}
+			runOpts.OnReport = tr.onReport
 			pgdoctor.Run(ctx, conn, runOpts)
+			tr.flush()
+			recordAudit(tr.reports)
 
 			fmt.Fprintln(w)
-			printSummary(w, reports)
+			printSummary(w, tr.reports)
+
+			if tracker != nil {
+				syncTickets(ctx, w, tracker, dbLabel, tr.reports)
+				fmt.Fprintln(w)
+			}
+			if err := finishBaseline(w, opts, baseline, tr.reports, startedAt); err != nil {
+				return err
+			}
 
 			if opts.detail == string(detailSummary) || opts.detail == string(detailBrief) {
 				dimFunc := dimColor()
@@ -175,11 +307,7 @@ the level of detail, and --hide-passing to only show failures and warnings.`,
 				fmt.Fprintln(w)
 			}
 
-			if maxSeverity == check.SeverityFail {
-				return &SilentError{ExitCode: 1}
-			}
-
-			return nil
+			return failOnExit(opts.failOn, tr.maxSeverity)
 		},
 	}
 
@@ -188,36 +316,271 @@ the level of detail, and --hide-passing to only show failures and warnings.`,
 	cmd.Flags().StringVar(&opts.preset, "preset", presetAll, "Check preset: all (default), triage")
 	cmd.Flags().StringVar(&opts.detail, "detail", string(detailBrief), "Detail level: summary, brief (default), verbose, debug")
 	cmd.Flags().BoolVar(&opts.hidePassing, "hide-passing", false, "Hide passing checks")
-	cmd.Flags().StringVar(&opts.output, "output", "text", "Output format: text (default), json")
+	addTextOrderFlags(cmd, opts)
+	addFailOnFlag(cmd, opts)
+	cmd.Flags().StringVar(&opts.output, "output", "text", "Output format: text (default), json, markdown, confluence, html, sarif; or a destination like s3://bucket/run-{timestamp}.json.gz or confluence://page-id")
+	addConnectionFlags(cmd, &opts.connectionFlags)
+	addInstanceFlags(cmd, &opts.instanceFlags)
+	addHostMetricsFlag(cmd, &opts.instanceFlags)
+	cmd.Flags().StringVar(&opts.tickets, "tickets", "", "Open tickets for FAIL findings and close resolved ones: jira, linear")
+	cmd.Flags().StringVar(&opts.ticketProj, "ticket-project", "", "Jira project key or Linear team ID for --tickets")
+	cmd.Flags().StringVar(&opts.ticketType, "ticket-issue-type", "Task", "Jira issue type for --tickets")
+	cmd.Flags().BoolVar(&opts.auditLog, "audit-log", false, "Record this run in pgdoctor.audit_runs on the target database")
+	cmd.Flags().StringVar(&opts.schema, "schema", "", "Only check the tables, indexes, sequences, and other objects of this schema, e.g. one tenant of a schema-per-tenant database")
+	cmd.Flags().Float64Var(&opts.queryRate, "query-rate-limit", 0, "Start at most this many queries per second, with low-impact session settings (small work_mem, no parallel workers or JIT), to keep load down during an incident")
+	addConfigFlag(cmd, &opts.configPath)
+	cmd.Flags().StringVar(&opts.hostsFile, "hosts-file", "", "Run against every database listed in this YAML file, in parallel, and summarize the results across hosts")
+	cmd.Flags().IntVar(&opts.concurrency, "concurrency", defaultFleetConcurrency, "Hosts checked at once with --hosts-file")
+	cmd.Flags().DurationVar(&opts.hostTimeout, "host-timeout", defaultHostTimeout, "Time allowed per host with --hosts-file, including connecting")
+	cmd.Flags().StringVar(&opts.maxRuntime, "max-runtime-class", "heavy", "Skip checks more expensive than: fast, medium, heavy (default)")
+	cmd.Flags().IntVar(&opts.deepBloat, "deep-bloat", 0, "Measure the top N bloat offenders with pgstattuple before failing them (default 5 when given without a value)")
+	cmd.Flags().Lookup("deep-bloat").NoOptDefVal = strconv.Itoa(defaultDeepBloatTop)
+	cmd.Flags().IntVar(&opts.deepToast, "deep-toast", 0, "Sample values from the N widest columns to check their TOAST storage strategy (default 5 when given without a value)")
+	cmd.Flags().Lookup("deep-toast").NoOptDefVal = strconv.Itoa(defaultDeepToastColumns)
+	cmd.Flags().StringVar(&opts.baseline, "baseline", "", "Baseline file of accepted findings; only findings new or worse than it are reported")
+	cmd.Flags().BoolVar(&opts.writeBase, "write-baseline", false, "Record this run's WARN and FAIL findings in the --baseline file (default "+defaultBaselinePath+")")
+	cmd.Flags().StringVar(&opts.previous, "previous", "", "JSON report from the last run; findings keep their severity until their metric drops below its clear_* threshold")
 
 	return cmd
 }
 
-func sortChecksByCategory(checks []check.Package) {
-	sort.SliceStable(checks, func(i, j int) bool {
-		return checks[i].Metadata().Category < checks[j].Metadata().Category
-	})
+// textReporter streams reports as text, printing a header whenever the
+// category changes. Checks arrive in priority order, so a category can
+// appear once per priority. When --sort or
+// --group-by asks for another order, reports are held until flush.
+type textReporter struct {
+	w               io.Writer
+	opts            *runOptions
+	currentCategory string
+	reports         []*check.Report
+	maxSeverity     check.Severity
+}
+
+func (t *textReporter) onReport(r *check.Report) {
+	t.reports = append(t.reports, r)
+	if r.Severity > t.maxSeverity {
+		t.maxSeverity = r.Severity
+	}
+
+	if !streamsText(t.opts) {
+		return
+	}
+
+	// Print category header on transition
+	cat := string(r.Category)
+	if cat != t.currentCategory {
+		if t.currentCategory != "" {
+			fmt.Fprintln(t.w)
+		}
+		title := strings.ToUpper(cat)
+		fmt.Fprintln(t.w, title)
+		fmt.Fprintln(t.w, strings.Repeat("─", len(title)))
+		t.currentCategory = cat
+	}
+
+	if r.Severity == check.SeverityOK && t.opts.hidePassing {
+		return
+	}
+
+	if t.opts.detail == string(detailSummary) {
+		printCheckSummary(t.w, r, t.opts)
+	} else {
+		printCheckReport(t.w, r, t.opts)
+	}
+}
+
+// flush prints the held reports once the run has finished. It does nothing
+// when reports were streamed.
+func (t *textReporter) flush() {
+	switch {
+	case streamsText(t.opts):
+	case t.opts.groupBy == groupSeverity:
+		printGroupedBySeverity(t.w, t.reports, t.opts)
+	default:
+		printSorted(t.w, t.reports, t.opts)
+	}
+}
+
+func addConfigFlag(cmd *cobra.Command, path *string) {
+	cmd.Flags().StringVar(path, "config", "", "Config file (default: ./pgdoctor.yaml if present)")
+}
+
+// defaultDeepBloatTop is how many offenders --deep-bloat measures per check
+// when given without a value.
+const defaultDeepBloatTop = 5
+
+// defaultDeepToastColumns is how many columns --deep-toast samples when
+// given without a value.
+const defaultDeepToastColumns = 5
+
+// deepModes maps each check with a deep mode to the setting that enables it.
+// Deep modes read table and index pages rather than statistics, some under
+// their own statement_timeout, so they are not production-safe even when
+// the check itself is.
+var deepModes = map[string]string{
+	"table-bloat":   "deep_bloat_top",
+	"index-bloat":   "deep_bloat_top",
+	"toast-storage": "deep_sample_columns",
+}
+
+// validateDeepModes rejects a deep mode, from a flag or the config file, on
+// a run capped below heavy, which promises only production-safe queries.
+func validateDeepModes(checks []check.Package, cfg check.Config, maxRuntime check.RuntimeClass) error {
+	if maxRuntime >= check.RuntimeHeavy {
+		return nil
+	}
+	for _, pkg := range checks {
+		id := pkg.Metadata().CheckID
+		key, ok := deepModes[id]
+		if !ok || cfg[id][key] == "" {
+			continue
+		}
+		return fmt.Errorf("%s %s reads relation pages and is not production-safe: drop it or use --max-runtime-class heavy", id, key)
+	}
+	return nil
+}
+
+// withSetting returns a copy of cfg with key set to value for each check in
+// ids, so flags can override the loaded config without modifying it.
+func withSetting(cfg check.Config, key, value string, ids ...string) check.Config {
+	out := make(check.Config, len(cfg)+len(ids))
+	for id, settings := range cfg {
+		out[id] = settings
+	}
+	for _, id := range ids {
+		settings := make(map[string]string, len(out[id])+1)
+		for k, v := range out[id] {
+			settings[k] = v
+		}
+		settings[key] = value
+		out[id] = settings
+	}
+	return out
+}
+
+// loadConfig reads the config file, returning an empty config when the
+// default file does not exist.
+func loadConfig(path string) (*config.File, error) {
+	cfg, err := config.Load(path)
+	if err != nil {
+		return nil, err
+	}
+	if cfg == nil {
+		cfg = &config.File{}
+	}
+	return cfg, nil
+}
+
+// resolveDSN picks the connection string: positional argument > PGDOCTOR_DSN
+// environment variable > config file.
+func resolveDSN(args []string, cfg *config.File) (string, error) {
+	if len(args) > 0 {
+		return args[0], nil
+	}
+	if connString := os.Getenv("PGDOCTOR_DSN"); connString != "" {
+		return connString, nil
+	}
+	if cfg.DSN != "" {
+		return cfg.DSN, nil
+	}
+	return "", fmt.Errorf("connection string required: pass a DSN, set PGDOCTOR_DSN, or add dsn to %s", config.DefaultPath)
+}
+
+// readPrevious loads finding severities from an earlier --output json report
+// for hysteresis.
+func readPrevious(path string) (map[string]check.Severity, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading --previous: %w", err)
+	}
+	defer f.Close()
+
+	severities, err := report.ReadSeverities(f)
+	if err != nil {
+		return nil, fmt.Errorf("reading --previous %s: %w", path, err)
+	}
+	return severities, nil
 }
 
-// parseDSNLabel extracts a human-readable label from a DSN.
-func parseDSNLabel(dsn string) string {
-	u, err := url.Parse(dsn)
+// defaultBaselinePath is where --write-baseline writes without --baseline.
+const defaultBaselinePath = "pgdoctor-baseline.json"
+
+func readBaseline(path string) (*pgdoctor.Baseline, error) {
+	f, err := os.Open(path)
 	if err != nil {
-		return dsn
+		return nil, fmt.Errorf("reading --baseline: %w", err)
 	}
+	defer f.Close()
 
-	host := u.Hostname()
-	if host == "" {
-		return dsn
+	b, err := pgdoctor.ReadBaseline(f)
+	if err != nil {
+		return nil, fmt.Errorf("reading --baseline %s: %w", path, err)
 	}
+	return b, nil
+}
 
-	db := ""
-	if u.Path != "" && u.Path != "/" {
-		db = u.Path[1:] // strip leading /
+// finishBaseline writes the baseline file for --write-baseline, or notes
+// how many findings the baseline suppressed.
+func finishBaseline(w io.Writer, opts *runOptions, baseline *pgdoctor.Baseline, reports []*check.Report, now time.Time) error {
+	if opts.writeBase {
+		b := pgdoctor.NewBaseline(reports, now)
+		f, err := os.Create(opts.baseline)
+		if err != nil {
+			return fmt.Errorf("writing baseline: %w", err)
+		}
+		if err := b.Write(f); err != nil {
+			_ = f.Close()
+			return err
+		}
+		if err := f.Close(); err != nil {
+			return fmt.Errorf("writing baseline: %w", err)
+		}
+		fmt.Fprintf(w, "Baseline of %d finding(s) written to %s\n", len(b.Findings), opts.baseline)
+		return nil
+	}
+	if baseline != nil && baseline.Suppressed() > 0 {
+		fmt.Fprintf(w, "%s\n", dimColor()(fmt.Sprintf("%d finding(s) accepted in %s reported as passing", baseline.Suppressed(), opts.baseline)))
+	}
+	return nil
+}
+
+// validateFilters checks --only and --ignore against the available checks and
+// categories. A typo is an error rather than a warning: silently running the
+// wrong set of checks is worse than not running at all.
+func validateFilters(allChecks []check.Package, only, ignored []string) (validOnly, validIgnored []string, err error) {
+	validOnly, validIgnored, err = pgdoctor.ResolveFilters(allChecks, only, ignored)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w; run 'pgdoctor list' to see them all", err)
 	}
+	return validOnly, validIgnored, nil
+}
+
+// applyConfigDefaults fills in options from the config file for every flag
+// that was not set on the command line.
+func applyConfigDefaults(cmd *cobra.Command, opts *runOptions, cfg *config.File) {
+	flags := cmd.Flags()
+	if !flags.Changed("only") && len(cfg.Only) > 0 {
+		opts.only = cfg.Only
+	}
+	if !flags.Changed("ignore") && len(cfg.Ignore) > 0 {
+		opts.ignored = cfg.Ignore
+	}
+	if !flags.Changed("tickets") && cfg.Tickets.Tracker != "" {
+		opts.tickets = cfg.Tickets.Tracker
+	}
+	if !flags.Changed("ticket-project") && cfg.Tickets.Project != "" {
+		opts.ticketProj = cfg.Tickets.Project
+	}
+	if !flags.Changed("ticket-issue-type") && cfg.Tickets.IssueType != "" {
+		opts.ticketType = cfg.Tickets.IssueType
+	}
+}
 
-	if db != "" {
-		return fmt.Sprintf("%s/%s", host, db)
+// schemaLabel names the database in report titles and ticket fingerprints,
+// with the schema a run is scoped to, so each tenant gets its own.
+func schemaLabel(label, schema string) string {
+	if schema == "" {
+		return label
 	}
-	return host
+	return label + " (schema " + schema + ")"
 }
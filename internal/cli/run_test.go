@@ -0,0 +1,93 @@
+package cli
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/fresha/pgdoctor"
+	"github.com/fresha/pgdoctor/check"
+)
+
+func TestWithSetting(t *testing.T) {
+	t.Parallel()
+
+	cfg := check.Config{
+		"table-bloat":       {"deep_bloat_top": "2"},
+		"replication-slots": {"trend_sample_interval": "30s"},
+	}
+
+	got := withSetting(cfg, "deep_bloat_top", "7", "table-bloat", "index-bloat")
+
+	assert.Equal(t, check.Config{
+		"table-bloat":       {"deep_bloat_top": "7"},
+		"index-bloat":       {"deep_bloat_top": "7"},
+		"replication-slots": {"trend_sample_interval": "30s"},
+	}, got)
+	assert.Equal(t, "2", cfg["table-bloat"]["deep_bloat_top"], "the loaded config is not modified")
+}
+
+func TestValidateDeepModes(t *testing.T) {
+	t.Parallel()
+
+	checks := pgdoctor.Filter(pgdoctor.AllChecks(), []string{"table-bloat", "index-usage"}, nil)
+	deep := check.Config{"table-bloat": {"deep_bloat_top": "5"}}
+
+	require.NoError(t, validateDeepModes(checks, deep, check.RuntimeHeavy))
+	require.NoError(t, validateDeepModes(checks, check.Config{"table-bloat": {"deep_bloat_timeout_ms": "5000"}}, check.RuntimeMedium))
+	require.NoError(t, validateDeepModes(pgdoctor.Filter(checks, nil, []string{"table-bloat"}), deep, check.RuntimeMedium),
+		"settings of checks that do not run are ignored")
+
+	err := validateDeepModes(checks, deep, check.RuntimeMedium)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "table-bloat deep_bloat_top")
+}
+
+func TestSchemaLabel(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "db.example.com/app", schemaLabel("db.example.com/app", ""))
+	assert.Equal(t, "db.example.com/app (schema tenant_42)", schemaLabel("db.example.com/app", "tenant_42"))
+}
+
+func TestValidateFilters_UnknownIsError(t *testing.T) {
+	t.Parallel()
+
+	allChecks := pgdoctor.AllChecks()
+
+	only, ignored, err := validateFilters(allChecks, []string{"vacuum", "freeze-age/table-freeze-age"}, []string{"index-usage"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"vacuum", "freeze-age"}, only)
+	assert.Equal(t, []string{"index-usage"}, ignored)
+
+	_, _, err = validateFilters(allChecks, []string{"sequnce-health"}, []string{"not-a-check"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `"sequnce-health" (did you mean "sequence-health"?)`)
+	assert.Contains(t, err.Error(), `"not-a-check"`)
+	assert.Contains(t, err.Error(), "pgdoctor list")
+}
+
+func TestFinishBaseline(t *testing.T) {
+	t.Parallel()
+
+	r := check.NewReport(check.Metadata{CheckID: "pg-version", Name: "PG Version", Category: check.CategoryConfigs})
+	r.AddFinding(check.Finding{ID: "pg-version", Name: "PG Version", Severity: check.SeverityWarn, Details: "Minor version is behind"})
+
+	path := filepath.Join(t.TempDir(), "baseline.json")
+	var out bytes.Buffer
+	require.NoError(t, finishBaseline(&out, &runOptions{baseline: path, writeBase: true}, nil, []*check.Report{r}, time.Now()))
+	assert.Equal(t, "Baseline of 1 finding(s) written to "+path+"\n", out.String())
+
+	baseline, err := readBaseline(path)
+	require.NoError(t, err)
+	baseline.Apply(r)
+	assert.Equal(t, check.SeverityOK, r.Severity)
+
+	out.Reset()
+	require.NoError(t, finishBaseline(&out, &runOptions{baseline: path}, baseline, []*check.Report{r}, time.Now()))
+	assert.Contains(t, out.String(), "1 finding(s) accepted in "+path+" reported as passing")
+}
@@ -0,0 +1,268 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/fresha/pgdoctor/check"
+	"github.com/fresha/pgdoctor/db"
+	"github.com/fresha/pgdoctor/internal/dsn"
+)
+
+const (
+	// xidWarnAge is the age at which PostgreSQL starts logging "database must
+	// be vacuumed within N transactions" warnings (40M XIDs before the limit).
+	xidWarnAge = int64(math.MaxInt32) - 40_000_000
+	// xidStopAge is the age at which PostgreSQL refuses to assign new XIDs
+	// (3M XIDs before the limit), which takes the database read-only.
+	xidStopAge = int64(math.MaxInt32) - 3_000_000
+
+	// defaultFailsafeAge is vacuum_failsafe_age's default, used for the
+	// timeline on PostgreSQL 13 and older where the setting does not exist.
+	defaultFailsafeAge = int64(1_600_000_000)
+
+	// Crossings further out than this are shown as "never" rather than a date.
+	simulateHorizon = 10 * 365 * 24 * time.Hour
+)
+
+type simulateWraparoundOptions struct {
+	connectionFlags
+	configPath string
+	xidRate    string
+	tables     int
+}
+
+func newSimulateCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "simulate",
+		Short: "Project how the database evolves under an assumed workload",
+	}
+	cmd.AddCommand(newSimulateWraparoundCommand())
+	return cmd
+}
+
+func newSimulateWraparoundCommand() *cobra.Command {
+	opts := &simulateWraparoundOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "wraparound [DSN] --xid-rate <rate>",
+		Short: "Print when each database and table crosses the wraparound thresholds",
+		Long: `Read the current transaction ID age of every database and of the oldest
+tables, assume XIDs keep being consumed at --xid-rate with no freezing vacuum
+completing, and print when each one crosses:
+
+  autovacuum   autovacuum_freeze_max_age, where anti-wraparound autovacuum is forced
+  failsafe     vacuum_failsafe_age, where vacuum drops cost limits and index cleanup
+  warnings     40M XIDs before the limit, where PostgreSQL starts logging warnings
+  shutdown     3M XIDs before the limit, where PostgreSQL stops assigning XIDs
+
+The rate is a number with an optional k/M/B suffix per second, minute, hour,
+day or week, e.g. 5M/day or 200/s. Estimate it by reading
+pg_current_xact_id() twice some hours apart; the txn-rates check's commit
+rate is an upper bound, since read-only transactions do not consume XIDs.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if opts.xidRate == "" {
+				return fmt.Errorf("--xid-rate is required")
+			}
+			rate, err := parseXIDRate(opts.xidRate)
+			if err != nil {
+				return err
+			}
+			if opts.tables < 0 {
+				return fmt.Errorf("--tables must not be negative")
+			}
+
+			cfg, err := loadConfig(opts.configPath)
+			if err != nil {
+				return err
+			}
+
+			connString, err := resolveDSN(args, cfg)
+			if err != nil {
+				return err
+			}
+
+			ctx := cmd.Context()
+			conn, _, closeConn, err := openConnection(ctx, connString, opts.connectionFlags)
+			if err != nil {
+				return err
+			}
+			defer closeConn()
+
+			q := db.New(conn)
+			databases, err := q.DatabaseFreezeAge(ctx)
+			if err != nil {
+				return fmt.Errorf("reading database freeze ages: %w", err)
+			}
+			// Every schema, TOAST tables included: any of them can trip the
+			// failsafe or wraparound first.
+			tables, err := q.FailsafeFreezeAge(ctx, "")
+			if err != nil {
+				return fmt.Errorf("reading table freeze ages: %w", err)
+			}
+			failsafe, err := q.VacuumFailsafeAge(ctx)
+			if err != nil {
+				return fmt.Errorf("reading vacuum_failsafe_age: %w", err)
+			}
+
+			limits := wraparoundLimits{
+				freezeMaxAge: 200_000_000,
+				failsafeAge:  defaultFailsafeAge,
+			}
+			if failsafe.VacuumFailsafeAge.Valid {
+				limits.failsafeAge = failsafe.VacuumFailsafeAge.Int64
+			}
+
+			var objects []wraparoundObject
+			for _, d := range databases {
+				if d.FreezeMaxAge.Valid {
+					limits.freezeMaxAge = d.FreezeMaxAge.Int64
+				}
+				objects = append(objects, wraparoundObject{
+					kind: "database",
+					name: d.DatabaseName.String,
+					age:  int64(d.FreezeAge.Int32),
+				})
+			}
+			// PostgreSQL raises a lower vacuum_failsafe_age to 105% of
+			// autovacuum_freeze_max_age.
+			limits.failsafeAge = max(limits.failsafeAge, limits.freezeMaxAge*105/100)
+			for i, t := range tables {
+				if i >= opts.tables {
+					break
+				}
+				objects = append(objects, wraparoundObject{
+					kind: "table",
+					name: t.TableName.String,
+					age:  int64(t.FreezeAge.Int32),
+				})
+			}
+
+			writeWraparoundTimeline(cmd.OutOrStdout(), dsn.Label(connString), rate, time.Now(), limits, objects)
+			return nil
+		},
+	}
+
+	addConnectionFlags(cmd, &opts.connectionFlags)
+	addConfigFlag(cmd, &opts.configPath)
+	cmd.Flags().StringVar(&opts.xidRate, "xid-rate", "", "Assumed XID consumption rate, e.g. 5M/day or 200/s (required)")
+	cmd.Flags().IntVar(&opts.tables, "tables", 10, "Number of oldest tables to include")
+
+	return cmd
+}
+
+// wraparoundLimits are the server's settings the timeline is measured against.
+type wraparoundLimits struct {
+	freezeMaxAge int64
+	failsafeAge  int64
+}
+
+// wraparoundObject is a database or table and its current XID age.
+type wraparoundObject struct {
+	kind string
+	name string
+	age  int64
+}
+
+var xidRatePeriods = map[string]time.Duration{
+	"s": time.Second, "sec": time.Second, "second": time.Second,
+	"m": time.Minute, "min": time.Minute, "minute": time.Minute,
+	"h": time.Hour, "hour": time.Hour,
+	"d": 24 * time.Hour, "day": 24 * time.Hour,
+	"w": 7 * 24 * time.Hour, "week": 7 * 24 * time.Hour,
+}
+
+// parseXIDRate parses a rate such as "5M/day" or "200/s" into XIDs per second.
+func parseXIDRate(s string) (float64, error) {
+	count, period, ok := strings.Cut(strings.TrimSpace(s), "/")
+	if !ok {
+		return 0, fmt.Errorf("invalid --xid-rate %q: expected <count>/<period>, e.g. 5M/day", s)
+	}
+
+	unit, ok := xidRatePeriods[strings.ToLower(strings.TrimSuffix(strings.TrimSpace(period), "s"))]
+	if !ok {
+		unit, ok = xidRatePeriods[strings.ToLower(strings.TrimSpace(period))]
+	}
+	if !ok {
+		return 0, fmt.Errorf("invalid --xid-rate %q: period must be s, m, h, d or w", s)
+	}
+
+	count = strings.TrimSpace(count)
+	multiplier := 1.0
+	if n := len(count); n > 0 {
+		switch count[n-1] {
+		case 'k', 'K':
+			multiplier = 1e3
+		case 'm', 'M':
+			multiplier = 1e6
+		case 'b', 'B', 'g', 'G':
+			multiplier = 1e9
+		}
+		if multiplier != 1 {
+			count = count[:n-1]
+		}
+	}
+
+	n, err := strconv.ParseFloat(count, 64)
+	if err != nil || n <= 0 || math.IsInf(n, 0) || math.IsNaN(n) {
+		return 0, fmt.Errorf("invalid --xid-rate %q: count must be a positive number", s)
+	}
+
+	return n * multiplier / unit.Seconds(), nil
+}
+
+// wraparoundCrossing describes when an object at age reaches threshold at
+// rate XIDs per second.
+func wraparoundCrossing(age, threshold int64, rate float64, now time.Time) string {
+	if age >= threshold {
+		return "crossed"
+	}
+	seconds := float64(threshold-age) / rate
+	if seconds > simulateHorizon.Seconds() {
+		return "never (10y+)"
+	}
+	at := now.Add(time.Duration(seconds * float64(time.Second)))
+	return fmt.Sprintf("%s (%s)", check.FormatDurationSec(int64(seconds)), at.Format("2006-01-02"))
+}
+
+// writeWraparoundTimeline prints when each object crosses each threshold,
+// followed by the earliest shutdown, which is the number that matters.
+func writeWraparoundTimeline(w io.Writer, dbLabel string, rate float64, now time.Time, limits wraparoundLimits, objects []wraparoundObject) {
+	fmt.Fprintf(w, "Wraparound timeline for %s at %s XIDs/day\n", dbLabel, check.FormatNumber(int64(rate*86400)))
+	fmt.Fprintf(w, "Assumes a constant rate and that no freezing vacuum completes in the meantime.\n\n")
+
+	if len(objects) == 0 {
+		fmt.Fprintln(w, "No databases or tables found.")
+		return
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "OBJECT\tAGE\tAUTOVACUUM (%s)\tFAILSAFE (%s)\tWARNINGS (%s)\tSHUTDOWN (%s)\n",
+		check.FormatNumber(limits.freezeMaxAge), check.FormatNumber(limits.failsafeAge),
+		check.FormatNumber(xidWarnAge), check.FormatNumber(xidStopAge))
+
+	var first *wraparoundObject
+	for i, o := range objects {
+		fmt.Fprintf(tw, "%s %s\t%s\t%s\t%s\t%s\t%s\n",
+			o.kind, o.name, check.FormatNumber(o.age),
+			wraparoundCrossing(o.age, limits.freezeMaxAge, rate, now),
+			wraparoundCrossing(o.age, limits.failsafeAge, rate, now),
+			wraparoundCrossing(o.age, xidWarnAge, rate, now),
+			wraparoundCrossing(o.age, xidStopAge, rate, now))
+		if first == nil || o.age > first.age {
+			first = &objects[i]
+		}
+	}
+	_ = tw.Flush()
+
+	fmt.Fprintf(w, "\nFirst to shut down: %s %s, %s\n", first.kind, first.name,
+		wraparoundCrossing(first.age, xidStopAge, rate, now))
+}
@@ -0,0 +1,86 @@
+package cli
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseXIDRate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		in   string
+		want float64
+	}{
+		{in: "5M/day", want: 5e6 / 86400},
+		{in: "100k/hour", want: 1e5 / 3600},
+		{in: "200/s", want: 200},
+		{in: "1.5B/week", want: 1.5e9 / (7 * 86400)},
+		{in: "60 / min", want: 1},
+		{in: "2M/days", want: 2e6 / 86400},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := parseXIDRate(tt.in)
+			require.NoError(t, err)
+			assert.InDelta(t, tt.want, got, 1e-9)
+		})
+	}
+}
+
+func TestParseXIDRate_Invalid(t *testing.T) {
+	t.Parallel()
+
+	for _, in := range []string{"", "5M", "5M/fortnight", "fast/day", "0/day", "-1/s"} {
+		_, err := parseXIDRate(in)
+		assert.Error(t, err, in)
+	}
+}
+
+func TestWraparoundCrossing(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	rate := 10e6 / 86400.0
+
+	assert.Equal(t, "crossed", wraparoundCrossing(250_000_000, 200_000_000, rate, now))
+	assert.Equal(t, "10d (2026-01-11)", wraparoundCrossing(100_000_000, 200_000_000, rate, now))
+	assert.Equal(t, "never (10y+)", wraparoundCrossing(0, xidStopAge, 1, now))
+}
+
+func TestWriteWraparoundTimeline(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	limits := wraparoundLimits{freezeMaxAge: 200_000_000, failsafeAge: 1_600_000_000}
+	objects := []wraparoundObject{
+		{kind: "database", name: "app", age: 1_544_483_647},
+		{kind: "table", name: "public.orders", age: 1_844_483_647},
+	}
+
+	var buf bytes.Buffer
+	writeWraparoundTimeline(&buf, "db.example:5432/app", 10e6/86400.0, now, limits, objects)
+	out := buf.String()
+
+	assert.Contains(t, out, "Wraparound timeline for db.example:5432/app at 10.0M XIDs/day")
+	assert.Contains(t, out, "AUTOVACUUM (200.0M)")
+	assert.Contains(t, out, "FAILSAFE (1.6B)")
+	assert.Regexp(t, `database app\s+1\.5B\s+crossed\s+5d \(2026-01-06\)`, out)
+	assert.Regexp(t, `table public\.orders\s+1\.8B\s+crossed\s+crossed`, out)
+	assert.Contains(t, out, "First to shut down: table public.orders, 30d (2026-01-31)")
+}
+
+func TestWriteWraparoundTimeline_Empty(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	writeWraparoundTimeline(&buf, "app", 1, time.Now(), wraparoundLimits{}, nil)
+	assert.Contains(t, buf.String(), "No databases or tables found.")
+}
@@ -0,0 +1,53 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/fresha/pgdoctor/check"
+	"github.com/fresha/pgdoctor/internal/tickets"
+)
+
+// newTracker builds the issue tracker selected by --tickets, reading
+// credentials from the environment so they never appear in shell history.
+func newTracker(kind, project, issueType string) (tickets.Tracker, error) {
+	if kind == "" {
+		return nil, nil
+	}
+	if project == "" {
+		return nil, fmt.Errorf("--tickets requires --ticket-project")
+	}
+
+	switch kind {
+	case "jira":
+		baseURL, email, token := os.Getenv("JIRA_BASE_URL"), os.Getenv("JIRA_EMAIL"), os.Getenv("JIRA_API_TOKEN")
+		if baseURL == "" || email == "" || token == "" {
+			return nil, fmt.Errorf("--tickets jira requires JIRA_BASE_URL, JIRA_EMAIL, and JIRA_API_TOKEN")
+		}
+		return &tickets.Jira{BaseURL: baseURL, Email: email, APIToken: token, Project: project, IssueType: issueType}, nil
+	case "linear":
+		apiKey := os.Getenv("LINEAR_API_KEY")
+		if apiKey == "" {
+			return nil, fmt.Errorf("--tickets linear requires LINEAR_API_KEY")
+		}
+		return &tickets.Linear{APIKey: apiKey, TeamID: project}, nil
+	default:
+		return nil, fmt.Errorf("unknown ticket tracker %q (expected jira or linear)", kind)
+	}
+}
+
+// syncTickets files tickets for FAIL findings and reports what changed.
+// Tracker errors are printed as warnings; they never change the exit code.
+func syncTickets(ctx context.Context, w io.Writer, tracker tickets.Tracker, database string, reports []*check.Report) {
+	result, err := tickets.Sync(ctx, tracker, database, reports)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: ticket sync failed: %v\n", err)
+	}
+	if len(result.Created)+len(result.Existing)+len(result.Closed) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "Tickets: %d created, %d already open, %d closed\n",
+		len(result.Created), len(result.Existing), len(result.Closed))
+}
@@ -0,0 +1,193 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/fresha/pgdoctor/check"
+	"github.com/fresha/pgdoctor/db"
+	"github.com/fresha/pgdoctor/internal/dsn"
+	"github.com/fresha/pgdoctor/internal/report"
+	"github.com/fresha/pgdoctor/internal/tune"
+)
+
+type tuneOptions struct {
+	connectionFlags
+	instanceFlags
+	configPath  string
+	workload    string
+	connections int
+	memory      string
+	cpus        int
+	storage     string
+	output      string
+}
+
+func newTuneCommand() *cobra.Command {
+	opts := &tuneOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "tune [DSN] --memory <size> | --aws-db-instance-id <id>",
+		Short: "Compare settings with recommendations for the instance and workload",
+		Long: `Recommend memory, WAL, planner, and parallel query settings for the
+instance's RAM, CPUs, and storage and the expected workload, following the
+rules of thumb PGTune popularised, and compare them with the server's
+current settings. Settings more than 25% off are reported with an ALTER
+SYSTEM statement; on managed services set them in the parameter group.
+
+Workloads:
+
+  web      many short queries from a web application (default)
+  oltp     many short transactions, write heavy
+  dw       few large analytical queries and bulk loads
+  desktop  a developer machine sharing RAM with other programs
+  mixed    both transactional and analytical queries
+
+work_mem is sized for --connections, which defaults to the current
+max_connections. Without --cpus parallel query settings are not tuned.
+For RDS and Aurora, --aws-db-instance-id reads the memory, vCPUs, and
+storage type of the instance class from the RDS API instead; flags given
+explicitly take precedence.
+
+  pgdoctor tune --memory 64GB --cpus 16 --workload oltp --connections 300
+  pgdoctor tune --aws-db-instance-id orders-primary --workload oltp`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			workload, err := tune.ParseWorkload(opts.workload)
+			if err != nil {
+				return fmt.Errorf("invalid --workload: %w", err)
+			}
+			ctx := cmd.Context()
+			if err := applyInstance(ctx, cmd, opts); err != nil {
+				return err
+			}
+			if opts.memory == "" {
+				return fmt.Errorf("--memory is required unless --aws-db-instance-id reports it")
+			}
+			memoryGB, err := parseMemoryGB(opts.memory)
+			if err != nil {
+				return err
+			}
+			if opts.cpus < 0 || opts.connections < 0 {
+				return fmt.Errorf("--cpus and --connections must not be negative")
+			}
+			if opts.output != "text" && opts.output != "json" {
+				return fmt.Errorf("invalid --output %q (expected text or json)", opts.output)
+			}
+
+			cfg, err := loadConfig(opts.configPath)
+			if err != nil {
+				return err
+			}
+
+			connString, err := resolveDSN(args, cfg)
+			if err != nil {
+				return err
+			}
+
+			conn, _, closeConn, err := openConnection(ctx, connString, opts.connectionFlags)
+			if err != nil {
+				return err
+			}
+			defer closeConn()
+
+			settings, err := db.New(conn).TuneSettings(ctx)
+			if err != nil {
+				return fmt.Errorf("reading settings: %w", err)
+			}
+
+			in := tune.Input{
+				Instance: check.InstanceMetadata{
+					MemoryGB:    memoryGB,
+					VCPUCores:   opts.cpus,
+					StorageType: opts.storage,
+				},
+				Workload:    workload,
+				Connections: opts.connections,
+			}
+			if in.Connections == 0 {
+				for _, s := range settings {
+					if s.Name == "max_connections" {
+						in.Connections = int(s.Value)
+					}
+				}
+			}
+			r := tune.Compare(tune.Recommend(in), settings)
+
+			w := cmd.OutOrStdout()
+			if opts.output == "json" {
+				return report.WriteJSON(w, []*check.Report{r})
+			}
+
+			fmt.Fprintf(w, "Settings tuning for %s\n", dsn.Label(connString))
+			fmt.Fprintf(w, "%s\n\n", dimColor()(fmt.Sprintf("%s workload, %d connections, %s RAM, %s storage",
+				workload, in.Connections, check.FormatBytes(int64(memoryGB*(1<<30))), opts.storage)))
+			printCheckReport(w, r, &runOptions{detail: string(detailVerbose)})
+			if opts.cpus == 0 {
+				fmt.Fprintf(w, "\n%s\n", dimColor()("Parallel query settings were not tuned: pass --cpus."))
+			}
+			return nil
+		},
+	}
+
+	addConnectionFlags(cmd, &opts.connectionFlags)
+	addInstanceFlags(cmd, &opts.instanceFlags)
+	addConfigFlag(cmd, &opts.configPath)
+	cmd.Flags().StringVar(&opts.workload, "workload", string(tune.WorkloadWeb), "Workload: web (default), oltp, dw, desktop, mixed")
+	cmd.Flags().IntVar(&opts.connections, "connections", 0, "Connections to size work_mem for (default: current max_connections)")
+	cmd.Flags().StringVar(&opts.memory, "memory", "", "Instance RAM, e.g. 64GB or 512MB (required without --aws-db-instance-id)")
+	cmd.Flags().IntVar(&opts.cpus, "cpus", 0, "Instance vCPUs; parallel query settings are tuned from 4")
+	cmd.Flags().StringVar(&opts.storage, "storage", "ssd", "Storage: ssd (default), hdd, san, or a provider type such as gp3 or io2")
+	cmd.Flags().StringVar(&opts.output, "output", "text", "Output format: text (default), json")
+
+	return cmd
+}
+
+// applyInstance fills in --memory, --cpus, and --storage from the instance
+// named by --aws-db-instance-id, leaving flags set on the command line alone.
+func applyInstance(ctx context.Context, cmd *cobra.Command, opts *tuneOptions) error {
+	meta, err := describeInstance(ctx, opts.instanceFlags)
+	if err != nil || meta == nil {
+		return err
+	}
+	if !cmd.Flags().Changed("memory") && meta.MemoryGB > 0 {
+		opts.memory = fmt.Sprintf("%gGB", meta.MemoryGB)
+	}
+	if !cmd.Flags().Changed("cpus") {
+		opts.cpus = meta.VCPUCores
+	}
+	if !cmd.Flags().Changed("storage") && meta.StorageType != "" {
+		opts.storage = meta.StorageType
+	}
+	return nil
+}
+
+// parseMemoryGB parses a RAM size such as "64GB", "512MB", or "1TB" into
+// gigabytes. A bare number is taken as gigabytes.
+func parseMemoryGB(s string) (float64, error) {
+	size := strings.ToUpper(strings.TrimSpace(s))
+	size = strings.TrimSuffix(strings.Replace(size, "IB", "B", 1), "B")
+
+	scale := 1.0
+	if n := len(size); n > 0 {
+		switch size[n-1] {
+		case 'M':
+			scale = 1.0 / 1024
+		case 'G':
+			scale = 1
+		case 'T':
+			scale = 1024
+		}
+		size = strings.TrimRight(size, "MGT")
+	}
+
+	n, err := strconv.ParseFloat(strings.TrimSpace(size), 64)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid --memory %q: expected a size such as 64GB or 512MB", s)
+	}
+	return n * scale, nil
+}
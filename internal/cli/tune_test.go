@@ -0,0 +1,34 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMemoryGB(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		in   string
+		want float64
+	}{
+		{in: "64GB", want: 64},
+		{in: "64GiB", want: 64},
+		{in: "512MB", want: 0.5},
+		{in: "1tb", want: 1024},
+		{in: "16", want: 16},
+		{in: " 7.5 GB", want: 7.5},
+	}
+	for _, tt := range tests {
+		got, err := parseMemoryGB(tt.in)
+		require.NoError(t, err, tt.in)
+		assert.InDelta(t, tt.want, got, 1e-9, tt.in)
+	}
+
+	for _, in := range []string{"", "GB", "-4GB", "lots"} {
+		_, err := parseMemoryGB(in)
+		assert.Error(t, err, in)
+	}
+}
@@ -0,0 +1,51 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/fresha/pgdoctor/internal/buildinfo"
+)
+
+func newVersionCommand() *cobra.Command {
+	var asJSON bool
+
+	cmd := &cobra.Command{
+		Use:   "version",
+		Short: "Print version and build information",
+		Long: `Print the pgdoctor version, the commit it was built from, and the Go
+toolchain. The same version is recorded in every report, so findings can be
+matched to the release that produced them.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			info := buildinfo.Get()
+			w := cmd.OutOrStdout()
+
+			if asJSON {
+				enc := json.NewEncoder(w)
+				enc.SetIndent("", "  ")
+				return enc.Encode(info)
+			}
+
+			fmt.Fprintf(w, "pgdoctor %s\n", info.Version)
+			if info.Commit != "" {
+				commit := info.Commit
+				if info.Modified {
+					commit += " (modified)"
+				}
+				fmt.Fprintf(w, "commit:  %s\n", commit)
+			}
+			if info.Date != "" {
+				fmt.Fprintf(w, "built:   %s\n", info.Date)
+			}
+			fmt.Fprintf(w, "go:      %s\n", info.GoVersion)
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&asJSON, "json", false, "Print build information as JSON")
+
+	return cmd
+}
@@ -0,0 +1,263 @@
+// Package config loads the pgdoctor.yaml project configuration file.
+//
+// Every setting is optional and command-line flags always take precedence,
+// so a config file only supplies defaults for a team's usual runs.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/fresha/pgdoctor/check"
+)
+
+// DefaultPath is the config file looked up in the working directory.
+const DefaultPath = "pgdoctor.yaml"
+
+// File is the contents of pgdoctor.yaml.
+type File struct {
+	// Policies are policy packs whose settings this file builds on: local
+	// files or directories, relative to this file, or GitHub repositories
+	// as github.com/owner/repo[/dir][@ref].
+	Policies []string `yaml:"policies,omitempty"`
+	// DSN is the connection string. ${VAR} references are expanded so
+	// credentials can stay in the environment.
+	DSN string `yaml:"dsn,omitempty"`
+	// Provider names the cloud platform hosting the database (aws, gcp, none).
+	Provider string `yaml:"provider,omitempty"`
+	// Only and Ignore hold check IDs or categories, as for --only and --ignore.
+	Only   []string `yaml:"only,omitempty"`
+	Ignore []string `yaml:"ignore,omitempty"`
+	// IgnoreObjects lists schemas, tables, indexes, and sequences that
+	// checks leave out of their analysis.
+	IgnoreObjects check.IgnoreRules `yaml:"ignore_objects,omitempty"`
+	// Tickets configures --tickets.
+	Tickets Tickets `yaml:"tickets,omitempty"`
+	// Checks holds per-check settings keyed by check ID.
+	Checks check.Config `yaml:"checks,omitempty"`
+	// Priority overrides execution priority (critical, normal, deferred)
+	// keyed by check ID or category.
+	Priority map[string]string `yaml:"priority,omitempty"`
+	// Severity overrides the severity (pass, warn, fail) of failing
+	// findings, keyed by check ID or check-id/finding-id.
+	Severity map[string]string `yaml:"severity,omitempty"`
+	// CustomChecks are SQL checks run alongside the built-in ones.
+	CustomChecks []CustomCheck `yaml:"custom_checks,omitempty"`
+	// MaintenanceWindows are the times when fixes that block reads or
+	// writes may be applied.
+	MaintenanceWindows []MaintenanceWindow `yaml:"maintenance_windows,omitempty"`
+
+	// Packs are the policy packs merged into this file by Load, in order.
+	Packs []*Policy `yaml:"-"`
+}
+
+// ObjectFilter returns the compiled IgnoreObjects patterns, or nil when none
+// are set. Patterns were checked by Load.
+func (f *File) ObjectFilter() *check.ObjectFilter {
+	filter, _ := f.IgnoreObjects.Compile()
+	return filter
+}
+
+// Priorities returns the parsed Priority overrides. Values were checked by Load.
+func (f *File) Priorities() map[string]check.Priority {
+	if len(f.Priority) == 0 {
+		return nil
+	}
+	priorities := make(map[string]check.Priority, len(f.Priority))
+	for key, value := range f.Priority {
+		priorities[key], _ = check.ParsePriority(value)
+	}
+	return priorities
+}
+
+// SeverityOverrides returns the parsed Severity overrides. Values were
+// checked by Load.
+func (f *File) SeverityOverrides() map[string]check.Severity {
+	if len(f.Severity) == 0 {
+		return nil
+	}
+	overrides := make(map[string]check.Severity, len(f.Severity))
+	for key, value := range f.Severity {
+		overrides[key], _ = check.ParseSeverity(value)
+	}
+	return overrides
+}
+
+// CustomCheckPackages returns the custom checks as packages to run with
+// the built-in ones.
+func (f *File) CustomCheckPackages() []check.Package {
+	packages := make([]check.Package, 0, len(f.CustomChecks))
+	for _, c := range f.CustomChecks {
+		packages = append(packages, c.SQLCheck().Package())
+	}
+	return packages
+}
+
+// InMaintenanceWindow returns the configured window containing t, if any.
+func (f *File) InMaintenanceWindow(t time.Time) (MaintenanceWindow, bool) {
+	for _, w := range f.MaintenanceWindows {
+		if w.Contains(t) {
+			return w, true
+		}
+	}
+	return MaintenanceWindow{}, false
+}
+
+// MaintenanceWindow is a recurring daily time range. A window whose end is
+// not after its start runs past midnight into the next day.
+type MaintenanceWindow struct {
+	// Days are three-letter weekday names (mon..sun) the window starts on;
+	// empty means every day.
+	Days []string `yaml:"days,omitempty"`
+	// Start and End are HH:MM wall-clock times in Timezone.
+	Start string `yaml:"start"`
+	End   string `yaml:"end"`
+	// Timezone is an IANA zone name; empty means UTC.
+	Timezone string `yaml:"timezone,omitempty"`
+}
+
+var weekdays = []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}
+
+// Contains reports whether t falls inside the window. The window must have
+// passed validation.
+func (w MaintenanceWindow) Contains(t time.Time) bool {
+	loc, _ := loadTimezone(w.Timezone)
+	start, _ := parseClock(w.Start)
+	end, _ := parseClock(w.End)
+
+	t = t.In(loc)
+	minute := t.Hour()*60 + t.Minute()
+	if start < end {
+		return w.startsOn(t.Weekday()) && minute >= start && minute < end
+	}
+	yesterday := (t.Weekday() + 6) % 7
+	return (w.startsOn(t.Weekday()) && minute >= start) || (w.startsOn(yesterday) && minute < end)
+}
+
+func (w MaintenanceWindow) String() string {
+	days := "daily"
+	if len(w.Days) > 0 {
+		days = strings.Join(w.Days, ",")
+	}
+	tz := w.Timezone
+	if tz == "" {
+		tz = "UTC"
+	}
+	return fmt.Sprintf("%s %s-%s %s", days, w.Start, w.End, tz)
+}
+
+func (w MaintenanceWindow) startsOn(day time.Weekday) bool {
+	return len(w.Days) == 0 || slices.Contains(w.Days, weekdays[day])
+}
+
+func (w MaintenanceWindow) validate() error {
+	for _, day := range w.Days {
+		if !slices.Contains(weekdays, day) {
+			return fmt.Errorf("unknown day %q (expected mon, tue, wed, thu, fri, sat, or sun)", day)
+		}
+	}
+	if _, err := parseClock(w.Start); err != nil {
+		return fmt.Errorf("start: %w", err)
+	}
+	if _, err := parseClock(w.End); err != nil {
+		return fmt.Errorf("end: %w", err)
+	}
+	if _, err := loadTimezone(w.Timezone); err != nil {
+		return fmt.Errorf("timezone: %w", err)
+	}
+	return nil
+}
+
+// parseClock returns minutes since midnight for an HH:MM time.
+func parseClock(s string) (int, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q (expected HH:MM)", s)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+func loadTimezone(name string) (*time.Location, error) {
+	if name == "" {
+		return time.UTC, nil
+	}
+	return time.LoadLocation(name)
+}
+
+// Tickets configures the issue tracker integration.
+type Tickets struct {
+	Tracker   string `yaml:"tracker,omitempty"`
+	Project   string `yaml:"project,omitempty"`
+	IssueType string `yaml:"issue_type,omitempty"`
+}
+
+// Providers lists the accepted values for File.Provider.
+var Providers = []string{"none", "aws", "gcp"}
+
+// Load reads a config file. A missing file is not an error when path is the
+// default, so projects without a config keep working; it returns nil instead.
+func Load(path string) (*File, error) {
+	explicit := path != ""
+	if !explicit {
+		path = DefaultPath
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if !explicit && errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("reading config: %w", err)
+	}
+
+	var f File
+	if err := yaml.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	if err := f.validate(); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", path, err)
+	}
+	if err := f.applyPolicies(filepath.Dir(path)); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", path, err)
+	}
+
+	f.DSN = os.ExpandEnv(f.DSN)
+	return &f, nil
+}
+
+func (f *File) validate() error {
+	if f.Provider != "" && !slices.Contains(Providers, f.Provider) {
+		return fmt.Errorf("unknown provider %q (expected none, aws, or gcp)", f.Provider)
+	}
+	switch f.Tickets.Tracker {
+	case "", "jira", "linear":
+	default:
+		return fmt.Errorf("unknown tickets.tracker %q (expected jira or linear)", f.Tickets.Tracker)
+	}
+	if _, err := f.IgnoreObjects.Compile(); err != nil {
+		return fmt.Errorf("ignore_objects: %w", err)
+	}
+	if err := validatePriority(f.Priority); err != nil {
+		return err
+	}
+	if err := validateSeverity(f.Severity); err != nil {
+		return err
+	}
+	if err := validateCustomChecks(f.CustomChecks); err != nil {
+		return err
+	}
+	for i, w := range f.MaintenanceWindows {
+		if err := w.validate(); err != nil {
+			return fmt.Errorf("maintenance_windows[%d]: %w", i, err)
+		}
+	}
+	return nil
+}
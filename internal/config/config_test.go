@@ -0,0 +1,127 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/fresha/pgdoctor/check"
+)
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "pgdoctor.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	t.Setenv("TEST_PGDOCTOR_PASSWORD", "s3cret")
+
+	path := writeConfig(t, `
+dsn: "postgres://app:${TEST_PGDOCTOR_PASSWORD}@db/app"
+provider: gcp
+ignore: [partition-usage]
+ignore_objects:
+  schemas: [partman]
+  tables: ["legacy_%"]
+checks:
+  replication-slots:
+    trend_sample_interval: 30s
+priority:
+  sequence-health: normal
+  schema: deferred
+`)
+
+	cfg, err := Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, "postgres://app:s3cret@db/app", cfg.DSN)
+	assert.Equal(t, "gcp", cfg.Provider)
+	assert.Equal(t, []string{"partition-usage"}, cfg.Ignore)
+	assert.Equal(t, "30s", cfg.Checks["replication-slots"]["trend_sample_interval"])
+	assert.Equal(t, map[string]check.Priority{"sequence-health": check.PriorityNormal, "schema": check.PriorityDeferred}, cfg.Priorities())
+	assert.True(t, cfg.ObjectFilter().Table("partman", "template_orders"))
+	assert.True(t, cfg.ObjectFilter().Table("public", "legacy_users"))
+	assert.False(t, cfg.ObjectFilter().Table("public", "users"))
+	assert.Nil(t, (&File{}).ObjectFilter())
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	t.Parallel()
+
+	_, err := Load(writeConfig(t, "provider: azure\n"))
+	require.ErrorContains(t, err, "unknown provider")
+
+	_, err = Load(writeConfig(t, "tickets:\n  tracker: github\n"))
+	require.ErrorContains(t, err, "unknown tickets.tracker")
+
+	_, err = Load(writeConfig(t, "priority:\n  freeze-age: urgent\n"))
+	require.ErrorContains(t, err, `priority.freeze-age: unknown priority "urgent"`)
+
+	_, err = Load(writeConfig(t, "maintenance_windows:\n  - days: [saturday]\n    start: \"02:00\"\n    end: \"05:00\"\n"))
+	require.ErrorContains(t, err, `maintenance_windows[0]: unknown day "saturday"`)
+
+	_, err = Load(writeConfig(t, "maintenance_windows:\n  - start: \"2am\"\n    end: \"05:00\"\n"))
+	require.ErrorContains(t, err, `maintenance_windows[0]: start: invalid time "2am"`)
+
+	_, err = Load(writeConfig(t, "maintenance_windows:\n  - start: \"02:00\"\n    end: \"05:00\"\n    timezone: Mars/Olympus\n"))
+	require.ErrorContains(t, err, "maintenance_windows[0]: timezone")
+
+	_, err = Load(writeConfig(t, "ignore_objects:\n  tables: [\"re:legacy_(\"]\n"))
+	require.ErrorContains(t, err, `ignore_objects: tables pattern "re:legacy_("`)
+}
+
+func TestLoad_MissingExplicitPath(t *testing.T) {
+	t.Parallel()
+
+	_, err := Load(filepath.Join(t.TempDir(), "missing.yaml"))
+	require.Error(t, err)
+}
+
+func TestMaintenanceWindow_Contains(t *testing.T) {
+	t.Parallel()
+
+	path := writeConfig(t, `
+maintenance_windows:
+  - days: [sat]
+    start: "23:00"
+    end: "02:00"
+    timezone: Europe/London
+  - days: [tue, thu]
+    start: "03:00"
+    end: "04:30"
+`)
+	cfg, err := Load(path)
+	require.NoError(t, err)
+
+	london, err := time.LoadLocation("Europe/London")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name string
+		at   time.Time
+		want string
+	}{
+		{"saturday before start", time.Date(2026, 10, 17, 22, 59, 0, 0, london), ""},
+		{"saturday after start", time.Date(2026, 10, 17, 23, 30, 0, 0, london), "sat 23:00-02:00 Europe/London"},
+		{"past midnight into sunday", time.Date(2026, 10, 18, 1, 59, 0, 0, london), "sat 23:00-02:00 Europe/London"},
+		{"sunday at end", time.Date(2026, 10, 18, 2, 0, 0, 0, london), ""},
+		{"friday night", time.Date(2026, 10, 16, 23, 30, 0, 0, london), ""},
+		{"tuesday in UTC", time.Date(2026, 10, 20, 3, 15, 0, 0, time.UTC), "tue,thu 03:00-04:30 UTC"},
+		{"tuesday in UTC given in another zone", time.Date(2026, 10, 20, 5, 15, 0, 0, time.FixedZone("CEST", 2*3600)), "tue,thu 03:00-04:30 UTC"},
+		{"wednesday", time.Date(2026, 10, 21, 3, 15, 0, 0, time.UTC), ""},
+	}
+	for _, tt := range tests {
+		w, ok := cfg.InMaintenanceWindow(tt.at)
+		if tt.want == "" {
+			assert.False(t, ok, tt.name)
+			continue
+		}
+		require.True(t, ok, tt.name)
+		assert.Equal(t, tt.want, w.String(), tt.name)
+	}
+}
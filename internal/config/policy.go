@@ -0,0 +1,346 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/fresha/pgdoctor"
+	"github.com/fresha/pgdoctor/check"
+)
+
+// PolicyFile is the file a policy pack directory or repository holds.
+const PolicyFile = "pgdoctor-policy.yaml"
+
+// githubPrefix marks a policy reference as a GitHub repository.
+const githubPrefix = "github.com/"
+
+var (
+	// githubRawURL serves the files of GitHub repositories at a ref.
+	githubRawURL = "https://raw.githubusercontent.com"
+
+	policyClient = &http.Client{Timeout: 30 * time.Second}
+
+	checkIDPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+)
+
+// Policy is a policy pack: thresholds, severity overrides, ignore rules, and
+// custom checks that a central team maintains once and every team's
+// pgdoctor.yaml imports with policies. The settings of pgdoctor.yaml take
+// precedence over those of its packs, and later packs over earlier ones.
+type Policy struct {
+	// Name and Version identify the pack in output, so a run shows which
+	// revision of the policy it applied.
+	Name    string `yaml:"name"`
+	Version string `yaml:"version,omitempty"`
+	// Source is where the pack was loaded from.
+	Source string `yaml:"-"`
+
+	Ignore        []string          `yaml:"ignore,omitempty"`
+	IgnoreObjects check.IgnoreRules `yaml:"ignore_objects,omitempty"`
+	Checks        check.Config      `yaml:"checks,omitempty"`
+	Priority      map[string]string `yaml:"priority,omitempty"`
+	Severity      map[string]string `yaml:"severity,omitempty"`
+	CustomChecks  []CustomCheck     `yaml:"custom_checks,omitempty"`
+}
+
+func (p *Policy) String() string {
+	s := p.Name
+	if p.Version != "" {
+		s += " " + p.Version
+	}
+	return s + " (" + p.Source + ")"
+}
+
+// CustomCheck is a check defined by a query; see pgdoctor.SQLCheck.
+type CustomCheck struct {
+	ID          string `yaml:"id"`
+	Name        string `yaml:"name,omitempty"`
+	Category    string `yaml:"category"`
+	Description string `yaml:"description,omitempty"`
+	SQL         string `yaml:"sql"`
+	// Severity is reported when the query returns rows: warn (the default)
+	// or fail.
+	Severity string `yaml:"severity,omitempty"`
+	Details  string `yaml:"details,omitempty"`
+	DocsURL  string `yaml:"docs_url,omitempty"`
+	// RuntimeClass is fast (the default), medium, or heavy, for
+	// --max-runtime-class.
+	RuntimeClass string `yaml:"runtime_class,omitempty"`
+	// ProductionSafe vouches that the query is cheap enough for capped runs;
+	// see pgdoctor.SQLCheck.
+	ProductionSafe bool `yaml:"production_safe,omitempty"`
+}
+
+// SQLCheck returns the check to run. The fields were checked by Load.
+func (c CustomCheck) SQLCheck() pgdoctor.SQLCheck {
+	s := pgdoctor.SQLCheck{
+		ID:             c.ID,
+		Name:           c.Name,
+		Category:       check.Category(c.Category),
+		Description:    c.Description,
+		SQL:            c.SQL,
+		Severity:       check.SeverityWarn,
+		Details:        c.Details,
+		DocsURL:        c.DocsURL,
+		ProductionSafe: c.ProductionSafe,
+	}
+	if s.Name == "" {
+		s.Name = c.ID
+	}
+	if c.Severity != "" {
+		s.Severity, _ = check.ParseSeverity(c.Severity)
+	}
+	if c.RuntimeClass != "" {
+		s.RuntimeClass, _ = check.ParseRuntimeClass(c.RuntimeClass)
+	}
+	return s
+}
+
+func (c CustomCheck) validate() error {
+	if !checkIDPattern.MatchString(c.ID) {
+		return fmt.Errorf("id %q must be kebab-case", c.ID)
+	}
+	if _, ok := pgdoctor.CheckByID(c.ID); ok {
+		return fmt.Errorf("id %q is a built-in check", c.ID)
+	}
+	if !slices.Contains(pgdoctor.Categories(), check.Category(c.Category)) {
+		return fmt.Errorf("%s: unknown category %q", c.ID, c.Category)
+	}
+	if strings.TrimSpace(c.SQL) == "" {
+		return fmt.Errorf("%s: sql is required", c.ID)
+	}
+	switch c.Severity {
+	case "", "warn", "fail":
+	default:
+		return fmt.Errorf("%s: unknown severity %q (expected warn or fail)", c.ID, c.Severity)
+	}
+	if c.RuntimeClass != "" {
+		if _, err := check.ParseRuntimeClass(c.RuntimeClass); err != nil {
+			return fmt.Errorf("%s: %w", c.ID, err)
+		}
+	}
+	return nil
+}
+
+func (p *Policy) validate() error {
+	if p.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if _, err := p.IgnoreObjects.Compile(); err != nil {
+		return fmt.Errorf("ignore_objects: %w", err)
+	}
+	if err := validatePriority(p.Priority); err != nil {
+		return err
+	}
+	if err := validateSeverity(p.Severity); err != nil {
+		return err
+	}
+	return validateCustomChecks(p.CustomChecks)
+}
+
+func validatePriority(priority map[string]string) error {
+	for key, value := range priority {
+		if _, err := check.ParsePriority(value); err != nil {
+			return fmt.Errorf("priority.%s: %w", key, err)
+		}
+	}
+	return nil
+}
+
+func validateSeverity(severity map[string]string) error {
+	for key, value := range severity {
+		switch value {
+		case "pass", "warn", "fail":
+		default:
+			return fmt.Errorf("severity.%s: unknown severity %q (expected pass, warn, or fail)", key, value)
+		}
+	}
+	return nil
+}
+
+func validateCustomChecks(checks []CustomCheck) error {
+	seen := map[string]bool{}
+	for i, c := range checks {
+		if err := c.validate(); err != nil {
+			return fmt.Errorf("custom_checks[%d]: %w", i, err)
+		}
+		if seen[c.ID] {
+			return fmt.Errorf("custom_checks[%d]: duplicate id %q", i, c.ID)
+		}
+		seen[c.ID] = true
+	}
+	return nil
+}
+
+// loadPolicy reads the pack named by ref: a GitHub repository
+// (github.com/owner/repo[/dir][@ref]) or a local file or directory, relative
+// to dir.
+func loadPolicy(ref, dir string) (*Policy, error) {
+	var data []byte
+	var err error
+	if strings.HasPrefix(ref, githubPrefix) {
+		data, err = fetchGitHubPolicy(ref)
+	} else {
+		path := ref
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(dir, path)
+		}
+		if !isYAML(path) {
+			path = filepath.Join(path, PolicyFile)
+		}
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("policy %s: %w", ref, err)
+	}
+
+	p := &Policy{Source: ref}
+	if err := yaml.Unmarshal(data, p); err != nil {
+		return nil, fmt.Errorf("policy %s: parsing: %w", ref, err)
+	}
+	if err := p.validate(); err != nil {
+		return nil, fmt.Errorf("policy %s: %w", ref, err)
+	}
+	return p, nil
+}
+
+// fetchGitHubPolicy downloads a pack from GitHub. Without @ref it follows
+// the default branch; pin a tag or commit so every team applies the same
+// revision. GITHUB_TOKEN, when set, authenticates access to private
+// repositories.
+func fetchGitHubPolicy(ref string) ([]byte, error) {
+	repo, version, _ := strings.Cut(strings.TrimPrefix(ref, githubPrefix), "@")
+	if version == "" {
+		version = "HEAD"
+	}
+	parts := strings.SplitN(repo, "/", 3)
+	if len(parts) < 2 || parts[0] == "" || parts[1] == "" {
+		return nil, fmt.Errorf("expected github.com/owner/repo[/dir][@ref]")
+	}
+	file := PolicyFile
+	if len(parts) == 3 {
+		file = parts[2]
+		if !isYAML(file) {
+			file = strings.TrimSuffix(file, "/") + "/" + PolicyFile
+		}
+	}
+
+	req, err := http.NewRequest(http.MethodGet, strings.Join([]string{githubRawURL, parts[0], parts[1], version, file}, "/"), nil)
+	if err != nil {
+		return nil, err
+	}
+	if token := os.Getenv("GITHUB_TOKEN"); token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := policyClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetching %s: %s", req.URL, resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+func isYAML(path string) bool {
+	ext := filepath.Ext(path)
+	return ext == ".yaml" || ext == ".yml"
+}
+
+// applyPolicies loads f.Policies and merges them under f's own settings.
+// Relative pack paths are resolved against dir.
+func (f *File) applyPolicies(dir string) error {
+	if len(f.Policies) == 0 {
+		return nil
+	}
+
+	var merged Policy
+	for _, ref := range f.Policies {
+		p, err := loadPolicy(ref, dir)
+		if err != nil {
+			return err
+		}
+		merged.merge(p)
+		f.Packs = append(f.Packs, p)
+	}
+	merged.merge(&Policy{
+		Ignore:        f.Ignore,
+		IgnoreObjects: f.IgnoreObjects,
+		Checks:        f.Checks,
+		Priority:      f.Priority,
+		Severity:      f.Severity,
+		CustomChecks:  f.CustomChecks,
+	})
+
+	f.Ignore = merged.Ignore
+	f.IgnoreObjects = merged.IgnoreObjects
+	f.Checks = merged.Checks
+	f.Priority = merged.Priority
+	f.Severity = merged.Severity
+	f.CustomChecks = merged.CustomChecks
+	return nil
+}
+
+// merge layers o over p: ignore lists are combined, and o's settings and
+// custom checks replace p's with the same key or ID.
+func (p *Policy) merge(o *Policy) {
+	for _, id := range o.Ignore {
+		if !slices.Contains(p.Ignore, id) {
+			p.Ignore = append(p.Ignore, id)
+		}
+	}
+
+	p.IgnoreObjects.Schemas = append(p.IgnoreObjects.Schemas, o.IgnoreObjects.Schemas...)
+	p.IgnoreObjects.Tables = append(p.IgnoreObjects.Tables, o.IgnoreObjects.Tables...)
+	p.IgnoreObjects.Indexes = append(p.IgnoreObjects.Indexes, o.IgnoreObjects.Indexes...)
+	p.IgnoreObjects.Sequences = append(p.IgnoreObjects.Sequences, o.IgnoreObjects.Sequences...)
+
+	for id, settings := range o.Checks {
+		if p.Checks == nil {
+			p.Checks = check.Config{}
+		}
+		merged := make(map[string]string, len(p.Checks[id])+len(settings))
+		for k, v := range p.Checks[id] {
+			merged[k] = v
+		}
+		for k, v := range settings {
+			merged[k] = v
+		}
+		p.Checks[id] = merged
+	}
+
+	p.Priority = mergeMap(p.Priority, o.Priority)
+	p.Severity = mergeMap(p.Severity, o.Severity)
+
+	for _, c := range o.CustomChecks {
+		if i := slices.IndexFunc(p.CustomChecks, func(e CustomCheck) bool { return e.ID == c.ID }); i >= 0 {
+			p.CustomChecks[i] = c
+		} else {
+			p.CustomChecks = append(p.CustomChecks, c)
+		}
+	}
+}
+
+func mergeMap(base, over map[string]string) map[string]string {
+	if len(over) == 0 {
+		return base
+	}
+	if base == nil {
+		base = make(map[string]string, len(over))
+	}
+	for k, v := range over {
+		base[k] = v
+	}
+	return base
+}
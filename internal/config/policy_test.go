@@ -0,0 +1,165 @@
+package config
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/fresha/pgdoctor/check"
+)
+
+const basePolicy = `
+name: acme-base
+version: 1.2.0
+ignore: [partition-usage]
+ignore_objects:
+  schemas: [partman]
+checks:
+  replication-lag:
+    physical_warn_seconds: "0.5"
+    physical_fail_seconds: "2"
+priority:
+  sequence-health: critical
+severity:
+  index-bloat/bloated-indexes: warn
+custom_checks:
+  - id: acme-owner-comment
+    name: Owner Comment
+    category: schema
+    sql: SELECT relname FROM pg_stat_user_tables WHERE obj_description(relid) IS NULL
+    severity: fail
+    production_safe: true
+`
+
+func writePolicy(t *testing.T, dir, name, content string) {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+}
+
+func TestLoad_Policies(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	writePolicy(t, dir, "policies/base/"+PolicyFile, basePolicy)
+	writePolicy(t, dir, "policies/prod.yaml", `
+name: acme-prod
+ignore: [partition-usage, table-bloat]
+checks:
+  replication-lag:
+    physical_fail_seconds: "1.5"
+`)
+	writePolicy(t, dir, "pgdoctor.yaml", `
+policies: [policies/base, policies/prod.yaml]
+ignore_objects:
+  tables: ["tmp_%"]
+checks:
+  replication-lag:
+    physical_warn_seconds: "0.8"
+severity:
+  index-bloat/bloated-indexes: fail
+  table-bloat: pass
+`)
+
+	cfg, err := Load(filepath.Join(dir, "pgdoctor.yaml"))
+	require.NoError(t, err)
+
+	require.Len(t, cfg.Packs, 2)
+	assert.Equal(t, "acme-base 1.2.0 (policies/base)", cfg.Packs[0].String())
+	assert.Equal(t, "acme-prod (policies/prod.yaml)", cfg.Packs[1].String())
+
+	assert.Equal(t, []string{"partition-usage", "table-bloat"}, cfg.Ignore)
+	assert.True(t, cfg.ObjectFilter().Schema("partman"))
+	assert.True(t, cfg.ObjectFilter().Table("public", "tmp_import"))
+	assert.Equal(t, map[string]string{"physical_warn_seconds": "0.8", "physical_fail_seconds": "1.5"}, cfg.Checks["replication-lag"],
+		"this file overrides the packs, and later packs override earlier ones")
+	assert.Equal(t, map[string]check.Priority{"sequence-health": check.PriorityCritical}, cfg.Priorities())
+	assert.Equal(t, map[string]check.Severity{"index-bloat/bloated-indexes": check.SeverityFail, "table-bloat": check.SeverityOK}, cfg.SeverityOverrides())
+
+	packages := cfg.CustomCheckPackages()
+	require.Len(t, packages, 1)
+	meta := packages[0].Metadata()
+	assert.Equal(t, "acme-owner-comment", meta.CheckID)
+	assert.Equal(t, check.CategorySchema, meta.Category)
+	assert.Equal(t, "FAIL any rows returned", meta.Findings[0].Thresholds)
+	assert.True(t, meta.ProductionSafe)
+}
+
+func TestLoad_PolicyFromGitHub(t *testing.T) {
+	t.Setenv("GITHUB_TOKEN", "ghp_test")
+
+	var paths []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		if r.Header.Get("Authorization") != "Bearer ghp_test" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		if r.URL.Path == "/acme/pgdoctor-policy-prod/v1.2.0/"+PolicyFile {
+			_, _ = w.Write([]byte(basePolicy))
+			return
+		}
+		http.NotFound(w, r)
+	}))
+	defer srv.Close()
+
+	prev := githubRawURL
+	githubRawURL = srv.URL
+	defer func() { githubRawURL = prev }()
+
+	cfg, err := Load(writeConfig(t, "policies: [github.com/acme/pgdoctor-policy-prod@v1.2.0]\n"))
+	require.NoError(t, err)
+	require.Len(t, cfg.Packs, 1)
+	assert.Equal(t, "acme-base", cfg.Packs[0].Name)
+	assert.Equal(t, "0.5", cfg.Checks["replication-lag"]["physical_warn_seconds"])
+
+	_, err = Load(writeConfig(t, "policies: [github.com/acme/pgdoctor-policy-prod/teams/payments]\n"))
+	require.ErrorContains(t, err, "policy github.com/acme/pgdoctor-policy-prod/teams/payments: fetching")
+	require.ErrorContains(t, err, "404 Not Found")
+	assert.Equal(t, "/acme/pgdoctor-policy-prod/HEAD/teams/payments/"+PolicyFile, paths[len(paths)-1],
+		"without @ref the default branch is used")
+
+	_, err = Load(writeConfig(t, "policies: [github.com/acme]\n"))
+	require.ErrorContains(t, err, "expected github.com/owner/repo[/dir][@ref]")
+}
+
+func TestLoad_InvalidPolicies(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		policy string
+		err    string
+	}{
+		{name: "missing name", policy: "version: 1.0.0\n", err: "policy pack.yaml: name is required"},
+		{name: "severity", policy: "name: p\nseverity:\n  index-bloat: critical\n", err: `severity.index-bloat: unknown severity "critical"`},
+		{name: "priority", policy: "name: p\npriority:\n  index-bloat: urgent\n", err: `priority.index-bloat: unknown priority "urgent"`},
+		{name: "built-in id", policy: "name: p\ncustom_checks:\n  - id: index-bloat\n    category: indexes\n    sql: SELECT 1\n", err: `custom_checks[0]: id "index-bloat" is a built-in check`},
+		{name: "category", policy: "name: p\ncustom_checks:\n  - id: acme-x\n    category: replication\n    sql: SELECT 1\n", err: `custom_checks[0]: acme-x: unknown category "replication"`},
+		{name: "sql", policy: "name: p\ncustom_checks:\n  - id: acme-x\n    category: schema\n", err: "custom_checks[0]: acme-x: sql is required"},
+		{name: "check severity", policy: "name: p\ncustom_checks:\n  - id: acme-x\n    category: schema\n    sql: SELECT 1\n    severity: pass\n", err: `acme-x: unknown severity "pass" (expected warn or fail)`},
+		{name: "duplicate", policy: "name: p\ncustom_checks:\n  - id: acme-x\n    category: schema\n    sql: SELECT 1\n  - id: acme-x\n    category: schema\n    sql: SELECT 2\n", err: `custom_checks[1]: duplicate id "acme-x"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			dir := t.TempDir()
+			writePolicy(t, dir, "pack.yaml", tt.policy)
+			writePolicy(t, dir, "pgdoctor.yaml", "policies: [pack.yaml]\n")
+
+			_, err := Load(filepath.Join(dir, "pgdoctor.yaml"))
+			require.ErrorContains(t, err, tt.err)
+		})
+	}
+
+	_, err := Load(writeConfig(t, "policies: [missing]\n"))
+	require.ErrorContains(t, err, "policy missing: open")
+}
@@ -0,0 +1,102 @@
+// Package dsn turns PostgreSQL connection strings into forms that are safe to
+// print. Reports, logs, and error messages show a connection as
+// postgres://user@host:port/db (or the shorter host/db label) and never
+// include the password or the raw connection string.
+package dsn
+
+import (
+	"net"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Invalid is shown in place of a connection string that cannot be parsed.
+const Invalid = "<invalid connection string>"
+
+var (
+	urlPasswordPattern = regexp.MustCompile(`(?i)(postgres(?:ql)?://[^:@/\s]*):[^@\s]*@`)
+	kvPasswordPattern  = regexp.MustCompile(`(?i)\bpassword\s*=\s*('(?:[^'\\]|\\.)*'|[^\s&]+)`)
+)
+
+// Redact returns connString as postgres://user@host:port/db. Both URL and
+// keyword/value connection strings are accepted.
+func Redact(connString string) string {
+	if connString == "" {
+		return ""
+	}
+	cfg, ok := parse(connString)
+	if !ok {
+		return Invalid
+	}
+	return RedactConfig(cfg)
+}
+
+// RedactConfig formats a parsed connection config as postgres://user@host:port/db.
+// Unix socket directories are shown in the host query parameter.
+func RedactConfig(cfg *pgconn.Config) string {
+	u := url.URL{Scheme: "postgres", Path: "/" + cfg.Database}
+	if cfg.User != "" {
+		u.User = url.User(cfg.User)
+	}
+	port := strconv.Itoa(int(cfg.Port))
+	if strings.HasPrefix(cfg.Host, "/") {
+		u.RawQuery = "host=" + url.QueryEscape(cfg.Host) + "&port=" + port
+	} else {
+		u.Host = net.JoinHostPort(cfg.Host, port)
+	}
+	return u.String()
+}
+
+// Label returns a short host/db label for report headers and ticket scopes.
+func Label(connString string) string {
+	cfg, ok := parse(connString)
+	if !ok {
+		return Invalid
+	}
+	return LabelConfig(cfg)
+}
+
+// LabelConfig returns the host/db label of a parsed connection config, or
+// just the host when no database is named.
+func LabelConfig(cfg *pgconn.Config) string {
+	if cfg.Database == "" {
+		return cfg.Host
+	}
+	return cfg.Host + "/" + cfg.Database
+}
+
+// Scrub masks passwords in free text such as error messages, whether they
+// appear in a URL's user info, a password= query parameter, or a
+// keyword/value connection string.
+func Scrub(s string) string {
+	s = urlPasswordPattern.ReplaceAllString(s, "${1}@")
+	return kvPasswordPattern.ReplaceAllString(s, "password=xxxxx")
+}
+
+// parse reads connString with pgconn, falling back to plain URL parsing when
+// pgconn rejects it for reasons unrelated to the address (for example a
+// missing sslrootcert file).
+func parse(connString string) (*pgconn.Config, bool) {
+	if cfg, err := pgconn.ParseConfig(connString); err == nil {
+		return cfg, true
+	}
+
+	u, err := url.Parse(connString)
+	if err != nil || (u.Scheme != "postgres" && u.Scheme != "postgresql") || u.Hostname() == "" {
+		return nil, false
+	}
+	cfg := &pgconn.Config{
+		Host:     u.Hostname(),
+		Port:     5432,
+		User:     u.User.Username(),
+		Database: strings.TrimPrefix(u.Path, "/"),
+	}
+	if p, err := strconv.ParseUint(u.Port(), 10, 16); err == nil {
+		cfg.Port = uint16(p)
+	}
+	return cfg, true
+}
@@ -1,5 +1,5 @@
 // Package main generates the docs/ directory for pgdoctor's GitHub Pages landing page.
-// It reads check metadata from the Go runtime (via AllChecks()) and produces:
+// It reads check metadata from the Go runtime (via AllMetadata()) and produces:
 //   - docs/checks.json — a JSON manifest of all checks
 //   - docs/checks/*.md — individual README files per check
 //   - docs/logo.png — copied from repo root
@@ -12,15 +12,27 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/fresha/pgdoctor"
+	"github.com/fresha/pgdoctor/check"
 )
 
 type checkEntry struct {
+	ID             string         `json:"id"`
+	Name           string         `json:"name"`
+	Category       string         `json:"category"`
+	Description    string         `json:"description"`
+	RuntimeClass   string         `json:"runtime_class"`
+	ProductionSafe bool           `json:"production_safe"`
+	Privileges     []string       `json:"privileges,omitempty"`
+	Findings       []findingEntry `json:"findings"`
+}
+
+type findingEntry struct {
 	ID          string `json:"id"`
-	Name        string `json:"name"`
-	Category    string `json:"category"`
 	Description string `json:"description"`
+	Thresholds  string `json:"thresholds,omitempty"`
 }
 
 type checksManifest struct {
@@ -49,22 +61,34 @@ func run() error {
 	}
 
 	// Gather check metadata from the Go runtime
-	allChecks := pgdoctor.AllChecks()
-	manifest := checksManifest{Checks: make([]checkEntry, 0, len(allChecks))}
+	allMetadata := pgdoctor.AllMetadata()
+	manifest := checksManifest{Checks: make([]checkEntry, 0, len(allMetadata))}
 
-	for _, pkg := range allChecks {
-		meta := pkg.Metadata()
+	for _, meta := range allMetadata {
+		findings := make([]findingEntry, 0, len(meta.Findings))
+		for _, f := range meta.Findings {
+			findings = append(findings, findingEntry(f))
+		}
+
+		var privileges []string
+		for _, p := range meta.Privileges {
+			privileges = append(privileges, string(p))
+		}
 
 		manifest.Checks = append(manifest.Checks, checkEntry{
-			ID:          meta.CheckID,
-			Name:        meta.Name,
-			Category:    string(meta.Category),
-			Description: meta.Description,
+			ID:             meta.CheckID,
+			Name:           meta.Name,
+			Category:       string(meta.Category),
+			Description:    meta.Description,
+			RuntimeClass:   meta.RuntimeClass.String(),
+			ProductionSafe: meta.ProductionSafe,
+			Privileges:     privileges,
+			Findings:       findings,
 		})
 
-		// Write individual README markdown
+		// Write individual README markdown with the generated Findings table
 		mdPath := filepath.Join(checksDir, meta.CheckID+".md")
-		if err := os.WriteFile(mdPath, []byte(meta.Readme), 0o644); err != nil {
+		if err := os.WriteFile(mdPath, []byte(withFindingsTable(meta)), 0o644); err != nil {
 			return fmt.Errorf("writing %s: %w", mdPath, err)
 		}
 	}
@@ -92,10 +116,51 @@ func run() error {
 		return fmt.Errorf("copying index.html: %w", err)
 	}
 
-	fmt.Fprintf(os.Stdout, "✓ Generated docs/ with %d checks\n", len(allChecks))
+	fmt.Fprintf(os.Stdout, "✓ Generated docs/ with %d checks\n", len(allMetadata))
 	return nil
 }
 
+// withFindingsTable inserts a "Findings" section, and a "Required Privileges"
+// section when the check declares any, generated from the check's metadata
+// after the README's introduction, ahead of its first ## heading.
+func withFindingsTable(meta check.Metadata) string {
+	if len(meta.Findings) == 0 {
+		return meta.Readme
+	}
+
+	var b strings.Builder
+	b.WriteString("## Findings\n\n")
+	b.WriteString("| Finding | Description | Default thresholds |\n")
+	b.WriteString("| --- | --- | --- |\n")
+	for _, f := range meta.Findings {
+		thresholds := f.Thresholds
+		if thresholds == "" {
+			thresholds = "Informational"
+		}
+		fmt.Fprintf(&b, "| `%s` | %s | %s |\n", f.ID, escapeCell(f.Description), escapeCell(thresholds))
+	}
+	b.WriteString("\n")
+
+	if len(meta.Privileges) > 0 {
+		b.WriteString("## Required Privileges\n\n")
+		for _, p := range meta.Privileges {
+			fmt.Fprintf(&b, "- `%s`: %s\n", p, p.Description())
+		}
+		fmt.Fprintf(&b, "\n`pgdoctor grants --role <role> --only %s` prints the statements.\n\n", meta.CheckID)
+	}
+
+	readme := meta.Readme
+	idx := strings.Index(readme, "\n## ")
+	if idx < 0 {
+		return strings.TrimRight(readme, "\n") + "\n\n" + b.String()
+	}
+	return readme[:idx+1] + b.String() + readme[idx+1:]
+}
+
+func escapeCell(s string) string {
+	return strings.ReplaceAll(s, "|", `\|`)
+}
+
 // findRepoRoot finds the repository root by looking for go.mod.
 func findRepoRoot() (string, error) {
 	dir, err := os.Getwd()
@@ -0,0 +1,83 @@
+// Package indexsafety decides whether an index can be dropped without
+// breaking a constraint, logical replication, a partitioned index, or
+// foreign key enforcement. Checks gather the catalog facts with their own
+// queries and pass them to Verify before prescribing DROP INDEX.
+package indexsafety
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Index is what the rules need to know about one index.
+type Index struct {
+	// Constraints are the constraints using the index: a primary key,
+	// unique, or exclusion constraint it enforces, and foreign keys on other
+	// tables that reference it (pg_constraint.conindid).
+	Constraints []string
+	// ReplicaIdentity is set when the index is the table's REPLICA IDENTITY.
+	ReplicaIdentity bool
+	// PartitionOf is the partitioned index this index is attached to, if any.
+	PartitionOf string
+	// SoleForeignKeyIndex lists foreign keys on the index's table for which
+	// it is the only index covering the referencing columns.
+	SoleForeignKeyIndex []string
+}
+
+// Rule identifies one safety rule.
+type Rule string
+
+const (
+	RuleConstraint      Rule = "constraint"
+	RuleReplicaIdentity Rule = "replica-identity"
+	RulePartition       Rule = "partition"
+	RuleForeignKey      Rule = "foreign-key"
+)
+
+// Blocker is a rule that rules out dropping the index.
+type Blocker struct {
+	Rule   Rule
+	Reason string
+}
+
+// Verify returns every reason the index must not be dropped; none means
+// dropping it is safe as far as the catalog can tell. Whether it is used
+// is for the caller to judge.
+func Verify(idx Index) []Blocker {
+	var blockers []Blocker
+	if len(idx.Constraints) > 0 {
+		blockers = append(blockers, Blocker{
+			Rule:   RuleConstraint,
+			Reason: fmt.Sprintf("backs constraint %s", strings.Join(idx.Constraints, ", ")),
+		})
+	}
+	if idx.ReplicaIdentity {
+		blockers = append(blockers, Blocker{
+			Rule:   RuleReplicaIdentity,
+			Reason: "is the table's replica identity; logical replication of updates and deletes would fail",
+		})
+	}
+	if idx.PartitionOf != "" {
+		blockers = append(blockers, Blocker{
+			Rule:   RulePartition,
+			Reason: fmt.Sprintf("is a partition of %s; drop the parent index instead", idx.PartitionOf),
+		})
+	}
+	if len(idx.SoleForeignKeyIndex) > 0 {
+		blockers = append(blockers, Blocker{
+			Rule: RuleForeignKey,
+			Reason: fmt.Sprintf("is the only index for foreign key %s; deletes and key updates on the referenced table would scan this table",
+				strings.Join(idx.SoleForeignKeyIndex, ", ")),
+		})
+	}
+	return blockers
+}
+
+// Reasons joins the blockers' reasons for a report.
+func Reasons(blockers []Blocker) string {
+	reasons := make([]string, len(blockers))
+	for i, b := range blockers {
+		reasons[i] = b.Reason
+	}
+	return strings.Join(reasons, "; ")
+}
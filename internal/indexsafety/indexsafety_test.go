@@ -0,0 +1,76 @@
+package indexsafety
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerify(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		index Index
+		rules []Rule
+	}{
+		{
+			name: "safe",
+		},
+		{
+			name:  "backs a constraint",
+			index: Index{Constraints: []string{"orders_ref_key"}},
+			rules: []Rule{RuleConstraint},
+		},
+		{
+			name:  "replica identity",
+			index: Index{ReplicaIdentity: true},
+			rules: []Rule{RuleReplicaIdentity},
+		},
+		{
+			name:  "partition of a partitioned index",
+			index: Index{PartitionOf: "public.events_created_at_idx"},
+			rules: []Rule{RulePartition},
+		},
+		{
+			name:  "only index for a foreign key",
+			index: Index{SoleForeignKeyIndex: []string{"orders_customer_id_fkey"}},
+			rules: []Rule{RuleForeignKey},
+		},
+		{
+			name: "every rule",
+			index: Index{
+				Constraints:         []string{"a_key"},
+				ReplicaIdentity:     true,
+				PartitionOf:         "public.parent_idx",
+				SoleForeignKeyIndex: []string{"a_fkey"},
+			},
+			rules: []Rule{RuleConstraint, RuleReplicaIdentity, RulePartition, RuleForeignKey},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var rules []Rule
+			for _, b := range Verify(tt.index) {
+				rules = append(rules, b.Rule)
+			}
+			assert.Equal(t, tt.rules, rules)
+		})
+	}
+}
+
+func TestReasons(t *testing.T) {
+	t.Parallel()
+
+	blockers := Verify(Index{
+		Constraints:         []string{"orders_ref_key", "payments_order_ref_fkey"},
+		SoleForeignKeyIndex: []string{"orders_customer_id_fkey"},
+	})
+	assert.Equal(t, "backs constraint orders_ref_key, payments_order_ref_fkey; "+
+		"is the only index for foreign key orders_customer_id_fkey; deletes and key updates on the referenced table would scan this table",
+		Reasons(blockers))
+	assert.Empty(t, Reasons(nil))
+}
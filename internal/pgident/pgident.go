@@ -0,0 +1,35 @@
+// Package pgident quotes identifiers and literals for the SQL that checks
+// generate as fixes and prescriptions. Catalog names can hold mixed case,
+// spaces, dots, quotes, or semicolons, so every generated statement quotes
+// them rather than pasting names in as they were read.
+//
+// Names must be passed as separate parts (schema, then object) straight from
+// the catalogs. A joined "schema.table" string cannot be split back safely:
+// either part may contain a dot.
+package pgident
+
+import "strings"
+
+// Quote returns the parts as a dot-separated, double-quoted identifier,
+// e.g. Quote("public", "Orders") is "public"."Orders". Embedded double quotes
+// are doubled and NUL bytes, which PostgreSQL rejects in identifiers, are
+// dropped. Empty parts are skipped, so a missing schema leaves the name
+// unqualified.
+func Quote(parts ...string) string {
+	quoted := make([]string, 0, len(parts))
+	for _, part := range parts {
+		part = strings.ReplaceAll(part, "\x00", "")
+		if part == "" {
+			continue
+		}
+		quoted = append(quoted, `"`+strings.ReplaceAll(part, `"`, `""`)+`"`)
+	}
+	return strings.Join(quoted, ".")
+}
+
+// Literal returns s as a standard-conforming SQL string literal, for names
+// passed to functions that take text, such as squeeze.squeeze_table().
+func Literal(s string) string {
+	s = strings.ReplaceAll(s, "\x00", "")
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
@@ -0,0 +1,137 @@
+package pgident
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuote(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		parts    []string
+		expected string
+	}{
+		{parts: []string{"public", "orders"}, expected: `"public"."orders"`},
+		{parts: []string{"public", "Orders"}, expected: `"public"."Orders"`},
+		{parts: []string{"my.schema", "order items"}, expected: `"my.schema"."order items"`},
+		{parts: []string{"public", `say "hi"`}, expected: `"public"."say ""hi"""`},
+		{parts: []string{"public", `x"; DROP TABLE users; --`}, expected: `"public"."x""; DROP TABLE users; --"`},
+		{parts: []string{"", "orders_idx"}, expected: `"orders_idx"`},
+		{parts: []string{"public", "nul\x00name"}, expected: `"public"."nulname"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expected, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.expected, Quote(tt.parts...))
+		})
+	}
+}
+
+func TestLiteral(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, `'public'`, Literal("public"))
+	assert.Equal(t, `'O''Brien''s table'`, Literal("O'Brien's table"))
+	assert.Equal(t, `'x''); DROP TABLE users; --'`, Literal("x'); DROP TABLE users; --"))
+}
+
+// parseQuoted reads a sequence of double-quoted identifiers separated by dots
+// the way the PostgreSQL lexer does, returning the parts and whether the
+// whole input was consumed.
+func parseQuoted(s string) ([]string, bool) {
+	var parts []string
+	for {
+		if !strings.HasPrefix(s, `"`) {
+			return nil, false
+		}
+		s = s[1:]
+		var b strings.Builder
+		for {
+			i := strings.IndexByte(s, '"')
+			if i < 0 {
+				return nil, false
+			}
+			b.WriteString(s[:i])
+			s = s[i+1:]
+			if strings.HasPrefix(s, `"`) {
+				b.WriteByte('"')
+				s = s[1:]
+				continue
+			}
+			break
+		}
+		parts = append(parts, b.String())
+		if s == "" {
+			return parts, true
+		}
+		if !strings.HasPrefix(s, ".") {
+			return nil, false
+		}
+		s = s[1:]
+	}
+}
+
+// parseLiteral reads one standard-conforming string literal.
+func parseLiteral(s string) (string, bool) {
+	if len(s) < 2 || s[0] != '\'' || s[len(s)-1] != '\'' {
+		return "", false
+	}
+	body := s[1 : len(s)-1]
+	if strings.Count(body, "'")%2 != 0 || strings.Contains(strings.ReplaceAll(body, "''", ""), "'") {
+		return "", false
+	}
+	return strings.ReplaceAll(body, "''", "'"), true
+}
+
+func FuzzQuote(f *testing.F) {
+	for _, seed := range [][2]string{
+		{"public", "orders"},
+		{"My Schema", "Order.Items"},
+		{"public", `a"b`},
+		{`"`, `""`},
+		{"public", `x"; DROP TABLE users; --`},
+		{"", "idx"},
+	} {
+		f.Add(seed[0], seed[1])
+	}
+
+	f.Fuzz(func(t *testing.T, schema, name string) {
+		quoted := Quote(schema, name)
+
+		var want []string
+		for _, part := range []string{schema, name} {
+			if part = strings.ReplaceAll(part, "\x00", ""); part != "" {
+				want = append(want, part)
+			}
+		}
+		if len(want) == 0 {
+			assert.Empty(t, quoted)
+			return
+		}
+
+		// The quoted form must lex back to exactly the original parts: nothing
+		// in a name can end the identifier early or add another statement.
+		parts, ok := parseQuoted(quoted)
+		require.True(t, ok, "unparseable identifier %s", quoted)
+		assert.Equal(t, want, parts)
+		assert.NotContains(t, quoted, "\x00")
+	})
+}
+
+func FuzzLiteral(f *testing.F) {
+	for _, seed := range []string{"public", "O'Brien", "'", "''", "x'); DROP TABLE users; --"} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, s string) {
+		lit := Literal(s)
+		got, ok := parseLiteral(lit)
+		require.True(t, ok, "unparseable literal %s", lit)
+		assert.Equal(t, strings.ReplaceAll(s, "\x00", ""), got)
+	})
+}
@@ -0,0 +1,388 @@
+package pglog
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fresha/pgdoctor/check"
+)
+
+// DocsURL documents the log findings; they are not checks on the docs site.
+const DocsURL = "https://github.com/fresha/pgdoctor#pgdoctor-logs-analyze"
+
+const (
+	deadlockFail = 10 // deadlocks in the analysed log
+
+	autovacuumCancelFail = 10 // cancels of a single table
+
+	tempFilesFailBytes = 10 << 30 // total temp file bytes
+
+	checkpointWarningFail = 10 // "occurring too frequently" warnings
+
+	churnWarnPerSecond = 1.0 // average new sessions per second
+	churnFailPerSecond = 10.0
+
+	shortSession = time.Second
+
+	maxRows = 10
+)
+
+var (
+	autovacuumTablePattern = regexp.MustCompile(`automatic (vacuum|analyze) of table "([^"]+)"`)
+	tempFilePattern        = regexp.MustCompile(`^temporary file: path "[^"]*", size (\d+)`)
+	checkpointFreqPattern  = regexp.MustCompile(`^checkpoints are occurring too frequently \((\d+) seconds? apart\)`)
+	checkpointStartPattern = regexp.MustCompile(`^checkpoint starting: (.*)$`)
+	connAuthorizedPattern  = regexp.MustCompile(`^connection authorized: (.*)$`)
+	sessionTimePattern     = regexp.MustCompile(`^disconnection: session time: (\d+):(\d{2}):(\d{2}(?:\.\d+)?)`)
+	kvPattern              = regexp.MustCompile(`(\w+)=(\S+)`)
+)
+
+// Analyze turns log entries into one report per log finding, using the
+// existing check categories.
+func Analyze(entries []Entry) []*check.Report {
+	plans := collectPlans(entries)
+	return []*check.Report{
+		analyzeDeadlocks(entries),
+		analyzeAutovacuumCancels(entries),
+		analyzeTempFiles(entries, plans),
+		analyzeCheckpoints(entries),
+		analyzeConnectionChurn(entries),
+		analyzeSlowPlans(plans),
+	}
+}
+
+// Window returns the first and last timestamps in the log, or zero times when
+// the lines carry none.
+func Window(entries []Entry) (time.Time, time.Time) {
+	var first, last time.Time
+	for _, e := range entries {
+		if e.Time.IsZero() {
+			continue
+		}
+		if first.IsZero() || e.Time.Before(first) {
+			first = e.Time
+		}
+		if e.Time.After(last) {
+			last = e.Time
+		}
+	}
+	return first, last
+}
+
+func newLogReport(id, name string, category check.Category, description string) *check.Report {
+	return check.NewReport(check.Metadata{
+		CheckID:     id,
+		Name:        name,
+		Category:    category,
+		Description: description,
+	})
+}
+
+func addLogFinding(r *check.Report, f check.Finding) {
+	f.ID = r.CheckID
+	f.Name = r.Name
+	f.DocsURL = DocsURL
+	r.AddFinding(f)
+}
+
+// counter tallies occurrences by key, keeping first-seen order for ties.
+type counter struct {
+	keys   []string
+	counts map[string]int
+}
+
+func (c *counter) add(key string, n int) {
+	if c.counts == nil {
+		c.counts = map[string]int{}
+	}
+	if _, ok := c.counts[key]; !ok {
+		c.keys = append(c.keys, key)
+	}
+	c.counts[key] += n
+}
+
+// top returns keys by descending count.
+func (c *counter) top() []string {
+	keys := append([]string(nil), c.keys...)
+	sort.SliceStable(keys, func(i, j int) bool { return c.counts[keys[i]] > c.counts[keys[j]] })
+	return keys
+}
+
+func analyzeDeadlocks(entries []Entry) *check.Report {
+	r := newLogReport("log-deadlocks", "Log: Deadlocks", check.CategoryPerformance,
+		"Deadlocks reported in the server log")
+
+	var statements counter
+	total := 0
+	for _, e := range entries {
+		if e.SQLState != "40P01" && e.Message != "deadlock detected" {
+			continue
+		}
+		total++
+		statements.add(oneLine(e.Statement, "(statement not logged)"), 1)
+	}
+
+	if total == 0 {
+		addLogFinding(r, check.Finding{Severity: check.SeverityOK})
+		return r
+	}
+
+	severity := check.SeverityWarn
+	if total >= deadlockFail {
+		severity = check.SeverityFail
+	}
+	addLogFinding(r, check.Finding{
+		Severity: severity,
+		Details: fmt.Sprintf("%d deadlocks in the log. Transactions that lock the same rows in different orders abort each other; "+
+			"lock rows in a consistent order (for example ORDER BY id FOR UPDATE) or shorten the transactions.", total),
+		Table: countTable([]string{"Statement", "Deadlocks"}, &statements, check.SeverityWarn),
+	})
+	return r
+}
+
+func analyzeAutovacuumCancels(entries []Entry) *check.Report {
+	r := newLogReport("log-autovacuum-cancels", "Log: Canceled Autovacuums", check.CategoryVacuum,
+		"Autovacuum and autoanalyze runs canceled by conflicting locks")
+
+	var tables counter
+	total := 0
+	for _, e := range entries {
+		if !strings.HasPrefix(e.Message, "canceling autovacuum task") {
+			continue
+		}
+		total++
+		table := "(unknown table)"
+		if m := autovacuumTablePattern.FindStringSubmatch(e.Context); m != nil {
+			table = m[2] + " (" + m[1] + ")"
+		}
+		tables.add(table, 1)
+	}
+
+	if total == 0 {
+		addLogFinding(r, check.Finding{Severity: check.SeverityOK})
+		return r
+	}
+
+	severity := check.SeverityWarn
+	worst := tables.top()[0]
+	if tables.counts[worst] >= autovacuumCancelFail {
+		severity = check.SeverityFail
+	}
+	addLogFinding(r, check.Finding{
+		Severity: severity,
+		Details: fmt.Sprintf("%d autovacuum runs canceled on %d tables. A canceled run restarts from scratch, so tables "+
+			"that are locked often (DDL, LOCK TABLE, long-running ShareUpdateExclusive holders) may never finish vacuuming.",
+			total, len(tables.keys)),
+		Table: countTable([]string{"Table", "Cancels"}, &tables, check.SeverityWarn),
+	})
+	return r
+}
+
+func analyzeTempFiles(entries []Entry, plans *plans) *check.Report {
+	r := newLogReport("log-temp-files", "Log: Temp File Spills", check.CategoryPerformance,
+		"Queries that spilled sorts or hashes to temporary files")
+
+	var statements counter
+	bytesByStatement := map[string]int64{}
+	fullStatement := map[string]string{}
+	var total int64
+	files := 0
+	for _, e := range entries {
+		m := tempFilePattern.FindStringSubmatch(e.Message)
+		if m == nil {
+			continue
+		}
+		size, _ := strconv.ParseInt(m[1], 10, 64)
+		stmt := oneLine(e.Statement, "(statement not logged)")
+		statements.add(stmt, 1)
+		bytesByStatement[stmt] += size
+		fullStatement[stmt] = e.Statement
+		total += size
+		files++
+	}
+
+	if files == 0 {
+		addLogFinding(r, check.Finding{
+			Severity: check.SeverityOK,
+			Details:  "No temp files logged (log_temp_files only logs files above its threshold; -1 disables it).",
+		})
+		return r
+	}
+
+	severity := check.SeverityWarn
+	if total >= tempFilesFailBytes {
+		severity = check.SeverityFail
+	}
+
+	keys := append([]string(nil), statements.keys...)
+	sort.SliceStable(keys, func(i, j int) bool { return bytesByStatement[keys[i]] > bytesByStatement[keys[j]] })
+	table := &check.Table{Headers: []string{"Statement", "Files", "Total"}}
+	for _, stmt := range keys[:min(len(keys), maxRows)] {
+		table.Rows = append(table.Rows, check.TableRow{
+			Cells:    []string{stmt, strconv.Itoa(statements.counts[stmt]), check.FormatBytes(bytesByStatement[stmt])},
+			Severity: check.SeverityWarn,
+		})
+	}
+
+	details := fmt.Sprintf("%d temp files totalling %s from %d statements. Raise work_mem for these queries "+
+		"(SET LOCAL or per role) or add indexes that avoid the sort.", files, check.FormatBytes(total), len(keys))
+	if plan, ok := plans.forStatement(fullStatement[keys[0]]); ok {
+		details += representativePlan(plan)
+	}
+	addLogFinding(r, check.Finding{
+		Severity: severity,
+		Details:  details,
+		Table:    table,
+	})
+	return r
+}
+
+func analyzeCheckpoints(entries []Entry) *check.Report {
+	r := newLogReport("log-checkpoints", "Log: Checkpoint Warnings", check.CategoryConfigs,
+		"Checkpoints forced by WAL volume before checkpoint_timeout")
+
+	warnings := 0
+	minApart := 0
+	var reasons counter
+	for _, e := range entries {
+		if m := checkpointFreqPattern.FindStringSubmatch(e.Message); m != nil {
+			warnings++
+			if apart, _ := strconv.Atoi(m[1]); minApart == 0 || apart < minApart {
+				minApart = apart
+			}
+			continue
+		}
+		if m := checkpointStartPattern.FindStringSubmatch(e.Message); m != nil {
+			for reason := range strings.FieldsSeq(m[1]) {
+				if reason == "time" || reason == "wal" || reason == "xlog" {
+					reasons.add(strings.Replace(reason, "xlog", "wal", 1), 1)
+				}
+			}
+		}
+	}
+
+	started := reasons.counts["time"] + reasons.counts["wal"]
+	var share string
+	if started > 0 {
+		share = fmt.Sprintf(" %d of %d logged checkpoints were started by WAL volume rather than checkpoint_timeout.",
+			reasons.counts["wal"], started)
+	}
+
+	if warnings == 0 {
+		addLogFinding(r, check.Finding{Severity: check.SeverityOK, Details: strings.TrimSpace(share)})
+		return r
+	}
+
+	severity := check.SeverityWarn
+	if warnings >= checkpointWarningFail {
+		severity = check.SeverityFail
+	}
+	addLogFinding(r, check.Finding{
+		Severity: severity,
+		Details: fmt.Sprintf("%d \"checkpoints are occurring too frequently\" warnings, as little as %ds apart.%s "+
+			"Raise max_wal_size so checkpoints are spread out by checkpoint_timeout instead.", warnings, minApart, share),
+	})
+	return r
+}
+
+func analyzeConnectionChurn(entries []Entry) *check.Report {
+	r := newLogReport("log-connection-churn", "Log: Connection Churn", check.CategoryPerformance,
+		"New sessions per second and short-lived sessions from log_connections")
+
+	var sources counter
+	sessions, disconnects, short := 0, 0, 0
+	for _, e := range entries {
+		if m := connAuthorizedPattern.FindStringSubmatch(e.Message); m != nil {
+			sessions++
+			fields := map[string]string{}
+			for _, kv := range kvPattern.FindAllStringSubmatch(m[1], -1) {
+				fields[kv[1]] = kv[2]
+			}
+			sources.add(fields["user"]+"@"+fields["database"]+" "+fields["application_name"], 1)
+			continue
+		}
+		if m := sessionTimePattern.FindStringSubmatch(e.Message); m != nil {
+			disconnects++
+			hours, _ := strconv.Atoi(m[1])
+			minutes, _ := strconv.Atoi(m[2])
+			seconds, _ := strconv.ParseFloat(m[3], 64)
+			length := time.Duration(hours)*time.Hour + time.Duration(minutes)*time.Minute + time.Duration(seconds*float64(time.Second))
+			if length < shortSession {
+				short++
+			}
+		}
+	}
+
+	if sessions == 0 {
+		addLogFinding(r, check.Finding{
+			Severity: check.SeverityOK,
+			Details:  "No connection lines in the log; enable log_connections and log_disconnections to measure churn.",
+		})
+		return r
+	}
+
+	first, last := Window(entries)
+	window := last.Sub(first)
+	if window <= 0 {
+		addLogFinding(r, check.Finding{
+			Severity: check.SeverityOK,
+			Details:  fmt.Sprintf("%d new sessions; the log has no timestamps to compute a rate from.", sessions),
+		})
+		return r
+	}
+
+	rate := float64(sessions) / window.Seconds()
+	severity := check.SeverityOK
+	switch {
+	case rate >= churnFailPerSecond:
+		severity = check.SeverityFail
+	case rate >= churnWarnPerSecond:
+		severity = check.SeverityWarn
+	}
+
+	details := fmt.Sprintf("%d new sessions in %s (%.1f/s).", sessions, check.FormatDurationSec(int64(window.Seconds())), rate)
+	if disconnects > 0 {
+		details += fmt.Sprintf(" %d of %d sessions lasted under %s.", short, disconnects, shortSession)
+	}
+	if severity > check.SeverityOK {
+		details += " Each new session forks a backend and re-authenticates; put a pooler (PgBouncer, RDS Proxy) " +
+			"or an application-side pool in front of these clients."
+	}
+
+	addLogFinding(r, check.Finding{
+		Severity: severity,
+		Details:  details,
+		Table:    countTable([]string{"Source", "Sessions"}, &sources, severity),
+	})
+	return r
+}
+
+// countTable renders the most frequent keys of c, one row each.
+func countTable(headers []string, c *counter, severity check.Severity) *check.Table {
+	table := &check.Table{Headers: headers}
+	keys := c.top()
+	for _, key := range keys[:min(len(keys), maxRows)] {
+		table.Rows = append(table.Rows, check.TableRow{
+			Cells:    []string{key, strconv.Itoa(c.counts[key])},
+			Severity: severity,
+		})
+	}
+	return table
+}
+
+// oneLine collapses whitespace and truncates a statement for a table cell.
+func oneLine(s, empty string) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if s == "" {
+		return empty
+	}
+	const maxLen = 80
+	if len(s) > maxLen {
+		return s[:maxLen-3] + "..."
+	}
+	return s
+}
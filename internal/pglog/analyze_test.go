@@ -0,0 +1,149 @@
+package pglog
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/fresha/pgdoctor/check"
+)
+
+func findReport(t *testing.T, reports []*check.Report, id string) *check.Report {
+	t.Helper()
+	for _, r := range reports {
+		if r.CheckID == id {
+			return r
+		}
+	}
+	require.Failf(t, "report not found", "%s", id)
+	return nil
+}
+
+func TestAnalyze_EmptyLog(t *testing.T) {
+	t.Parallel()
+
+	reports := Analyze(nil)
+	require.Len(t, reports, 6)
+	for _, r := range reports {
+		assert.Equal(t, check.SeverityOK, r.Severity, r.CheckID)
+		require.Len(t, r.Results, 1)
+		assert.Equal(t, r.CheckID, r.Results[0].ID)
+		assert.Equal(t, DocsURL, r.Results[0].DocsURL)
+	}
+	assert.Contains(t, findReport(t, reports, "log-connection-churn").Results[0].Details, "log_connections")
+}
+
+func TestAnalyze_Deadlocks(t *testing.T) {
+	t.Parallel()
+
+	var entries []Entry
+	for range 10 {
+		entries = append(entries, Entry{Severity: "ERROR", SQLState: "40P01", Message: "deadlock detected", Statement: "UPDATE accounts SET balance = 0\n WHERE id = $1"})
+	}
+	entries = append(entries, Entry{Severity: "ERROR", Message: "deadlock detected"})
+
+	r := findReport(t, Analyze(entries), "log-deadlocks")
+	assert.Equal(t, check.SeverityFail, r.Severity)
+	assert.Contains(t, r.Results[0].Details, "11 deadlocks")
+	require.Len(t, r.Results[0].Table.Rows, 2)
+	assert.Equal(t, []string{"UPDATE accounts SET balance = 0 WHERE id = $1", "10"}, r.Results[0].Table.Rows[0].Cells)
+	assert.Equal(t, []string{"(statement not logged)", "1"}, r.Results[0].Table.Rows[1].Cells)
+}
+
+func TestAnalyze_AutovacuumCancels(t *testing.T) {
+	t.Parallel()
+
+	entries := []Entry{
+		{Severity: "ERROR", Message: "canceling autovacuum task", Context: `automatic vacuum of table "orders.public.events"`},
+		{Severity: "ERROR", Message: "canceling autovacuum task", Context: `automatic analyze of table "orders.public.events"`},
+		{Severity: "ERROR", Message: "canceling autovacuum task", Context: `automatic vacuum of table "orders.public.events"`},
+	}
+
+	r := findReport(t, Analyze(entries), "log-autovacuum-cancels")
+	assert.Equal(t, check.SeverityWarn, r.Severity)
+	assert.Contains(t, r.Results[0].Details, "3 autovacuum runs canceled on 2 tables")
+	assert.Equal(t, []string{"orders.public.events (vacuum)", "2"}, r.Results[0].Table.Rows[0].Cells)
+}
+
+func TestAnalyze_TempFiles(t *testing.T) {
+	t.Parallel()
+
+	entries := []Entry{
+		{Message: `temporary file: path "base/pgsql_tmp/pgsql_tmp1.0", size 1048576`, Statement: "SELECT small"},
+		{Message: `temporary file: path "base/pgsql_tmp/pgsql_tmp2.0", size 6442450944`, Statement: "SELECT big"},
+		{Message: `temporary file: path "base/pgsql_tmp/pgsql_tmp3.0", size 6442450944`, Statement: "SELECT big"},
+	}
+
+	r := findReport(t, Analyze(entries), "log-temp-files")
+	assert.Equal(t, check.SeverityFail, r.Severity)
+	assert.Contains(t, r.Results[0].Details, "3 temp files")
+	assert.Equal(t, "SELECT big", r.Results[0].Table.Rows[0].Cells[0])
+	assert.Equal(t, "2", r.Results[0].Table.Rows[0].Cells[1])
+}
+
+func TestAnalyze_Checkpoints(t *testing.T) {
+	t.Parallel()
+
+	entries := []Entry{
+		{Message: "checkpoint starting: time"},
+		{Message: "checkpoint starting: wal"},
+		{Message: "checkpoints are occurring too frequently (25 seconds apart)"},
+		{Message: "checkpoints are occurring too frequently (9 seconds apart)"},
+		{Message: "checkpoint starting: immediate force wait wal"},
+	}
+
+	r := findReport(t, Analyze(entries), "log-checkpoints")
+	assert.Equal(t, check.SeverityWarn, r.Severity)
+	assert.Contains(t, r.Results[0].Details, "as little as 9s apart")
+	assert.Contains(t, r.Results[0].Details, "2 of 3 logged checkpoints were started by WAL volume")
+}
+
+func TestAnalyze_ConnectionChurn(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	var entries []Entry
+	for i := range 300 {
+		at := start.Add(time.Duration(i) * 200 * time.Millisecond)
+		entries = append(entries,
+			Entry{Time: at, Message: "connection authorized: user=app database=orders application_name=api"},
+			Entry{Time: at, Message: fmt.Sprintf("disconnection: session time: 0:00:00.%03d user=app database=orders host=10.0.0.7 port=5432", i%1000)},
+		)
+	}
+
+	r := findReport(t, Analyze(entries), "log-connection-churn")
+	assert.Equal(t, check.SeverityWarn, r.Severity)
+	assert.Contains(t, r.Results[0].Details, "300 new sessions")
+	assert.Contains(t, r.Results[0].Details, "300 of 300 sessions lasted under 1s")
+	assert.Equal(t, []string{"app@orders api", "300"}, r.Results[0].Table.Rows[0].Cells)
+}
+
+func TestCrossReference(t *testing.T) {
+	t.Parallel()
+
+	reports := Analyze([]Entry{
+		{Message: `temporary file: path "base/pgsql_tmp/pgsql_tmp1.0", size 1048576`},
+	})
+
+	temp := check.NewReport(check.Metadata{CheckID: "temp-usage", Name: "Temp Usage"})
+	temp.AddFinding(check.Finding{ID: "temp-bytes", Name: "Temp Bytes", Severity: check.SeverityWarn})
+	wal := check.NewReport(check.Metadata{CheckID: "wal-size", Name: "WAL Size"})
+	wal.AddFinding(check.Finding{ID: "wal-size", Name: "WAL Size", Severity: check.SeverityOK})
+
+	CrossReference(nil, reports, []*check.Report{temp, wal})
+
+	details := findReport(t, reports, "log-temp-files").Results[0].Details
+	assert.Contains(t, details, "Related live checks:\n  temp-usage: WARN (Temp Bytes)")
+	assert.False(t, strings.Contains(findReport(t, reports, "log-checkpoints").Results[0].Details, "wal-size"),
+		"passing log findings are left alone")
+}
+
+func TestRelatedChecks(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, []string{"table-vacuum-health", "vacuum-settings", "temp-usage", "wal-size", "checkpoint-health", "connection-health", "connection-efficiency", "table-seq-scans"}, RelatedChecks())
+}
@@ -0,0 +1,143 @@
+package pglog
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/fresha/pgdoctor/check"
+)
+
+// relatedChecks maps log findings to the live checks that look at the same
+// problem from the catalog and statistics side.
+var relatedChecks = map[string][]string{
+	"log-autovacuum-cancels": {"table-vacuum-health", "vacuum-settings"},
+	"log-temp-files":         {"temp-usage"},
+	"log-checkpoints":        {"wal-size", "checkpoint-health"},
+	"log-connection-churn":   {"connection-health", "connection-efficiency"},
+	"log-slow-plans":         {"table-seq-scans"},
+}
+
+// seqScanTablePattern reads the tables table-seq-scans lists in its details,
+// one per line as "schema.table (seq: ...".
+var seqScanTablePattern = regexp.MustCompile(`(?m)^(\S+\.\S+) \(seq: `)
+
+// RelatedChecks returns the IDs of every live check a log finding can be
+// cross-referenced with.
+func RelatedChecks() []string {
+	var ids []string
+	seen := map[string]bool{}
+	for _, report := range Analyze(nil) {
+		for _, id := range relatedChecks[report.CheckID] {
+			if !seen[id] {
+				seen[id] = true
+				ids = append(ids, id)
+			}
+		}
+	}
+	return ids
+}
+
+// CrossReference appends the results of related live checks to each
+// non-passing log finding, so a log symptom and its live cause are read
+// together. Sequential scans in auto_explain plans on tables that
+// table-seq-scans flagged are reported as a log-slow-plans finding.
+func CrossReference(entries []Entry, logReports, live []*check.Report) {
+	byID := make(map[string]*check.Report, len(live))
+	for _, r := range live {
+		byID[r.CheckID] = r
+	}
+
+	for _, r := range logReports {
+		var lines []string
+		for _, id := range relatedChecks[r.CheckID] {
+			if lr, ok := byID[id]; ok {
+				lines = append(lines, liveSummary(lr))
+			}
+		}
+		if len(lines) == 0 {
+			continue
+		}
+		for i := range r.Results {
+			if r.Results[i].Severity <= check.SeverityOK {
+				continue
+			}
+			r.Results[i].Details += "\n\nRelated live checks:\n" + strings.Join(lines, "\n")
+		}
+	}
+
+	seqScans, ok := byID["table-seq-scans"]
+	if !ok {
+		return
+	}
+	for _, r := range logReports {
+		if r.CheckID == "log-slow-plans" {
+			addFlaggedSeqScans(r, collectPlans(entries), seqScans)
+		}
+	}
+}
+
+// addFlaggedSeqScans reports logged plans that sequentially scan a table the
+// live table-seq-scans check flagged, with the slowest such plan attached.
+func addFlaggedSeqScans(r *check.Report, plans *plans, seqScans *check.Report) {
+	var flagged []string
+	for _, f := range seqScans.Results {
+		if f.Severity <= check.SeverityOK {
+			continue
+		}
+		for _, m := range seqScanTablePattern.FindAllStringSubmatch(f.Details, -1) {
+			flagged = append(flagged, m[1])
+		}
+	}
+
+	finding := check.Finding{
+		ID:       "flagged-seq-scans",
+		Name:     "Seq Scans on Flagged Tables",
+		Severity: check.SeverityOK,
+		DocsURL:  DocsURL,
+	}
+
+	table := &check.Table{Headers: []string{"Table", "Statement", "Plans", "Total"}}
+	var worst Plan
+	for _, s := range plans.byTotal() {
+		for _, tableName := range flagged {
+			if !slices.ContainsFunc(s.worst.SeqScans, func(rel string) bool { return seqScanMatches(rel, tableName) }) {
+				continue
+			}
+			table.Rows = append(table.Rows, check.TableRow{
+				Cells:    []string{tableName, oneLine(s.query, "(query text not logged)"), strconv.Itoa(s.calls), check.FormatDurationMs(s.totalMs)},
+				Severity: check.SeverityWarn,
+			})
+			if s.worst.DurationMs > worst.DurationMs {
+				worst = s.worst
+			}
+		}
+	}
+
+	if len(table.Rows) > 0 {
+		finding.Severity = check.SeverityWarn
+		finding.Details = fmt.Sprintf("%d logged statements sequentially scan tables that table-seq-scans flagged; "+
+			"these are the queries to index for.", len(table.Rows)) + representativePlan(worst)
+		table.Rows = table.Rows[:min(len(table.Rows), maxRows)]
+		finding.Table = table
+	}
+	r.AddFinding(finding)
+}
+
+// liveSummary describes a live report in one line, naming its non-passing
+// findings.
+func liveSummary(r *check.Report) string {
+	var names []string
+	for _, f := range r.Results {
+		if f.Severity > check.SeverityOK {
+			names = append(names, f.Name)
+		}
+	}
+	line := fmt.Sprintf("  %s: %s", r.CheckID, strings.ToUpper(r.Severity.String()))
+	if len(names) > 0 {
+		line += " (" + strings.Join(names, ", ") + ")"
+	}
+	return line
+}
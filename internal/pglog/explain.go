@@ -0,0 +1,228 @@
+package pglog
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/fresha/pgdoctor/check"
+)
+
+const (
+	slowPlanFailMs = 60_000 // slowest single execution of a statement
+
+	maxPlanLines = 40
+)
+
+var (
+	autoExplainPattern = regexp.MustCompile(`(?s)^duration: ([\d.]+) ms\s+plan:\n(.*)$`)
+	planNodePattern    = regexp.MustCompile(`\((?:cost|actual time)=|\(never executed\)`)
+	seqScanPattern     = regexp.MustCompile(`Seq Scan on (\S+)`)
+)
+
+// Plan is one auto_explain entry.
+type Plan struct {
+	Query      string
+	DurationMs float64
+	Text       string   // the plan as logged (text or JSON)
+	SeqScans   []string // relations read by sequential scans, as named in the plan
+}
+
+// planStats aggregates the plans logged for one statement.
+type planStats struct {
+	query   string
+	calls   int
+	totalMs float64
+	worst   Plan
+}
+
+// plans groups auto_explain plans by statement text.
+type plans struct {
+	order   []string
+	byQuery map[string]*planStats
+}
+
+func collectPlans(entries []Entry) *plans {
+	p := &plans{byQuery: map[string]*planStats{}}
+	for _, e := range entries {
+		plan, ok := parsePlan(e.Message)
+		if !ok {
+			continue
+		}
+		key := statementKey(plan.Query)
+		s, ok := p.byQuery[key]
+		if !ok {
+			s = &planStats{query: plan.Query}
+			p.byQuery[key] = s
+			p.order = append(p.order, key)
+		}
+		s.calls++
+		s.totalMs += plan.DurationMs
+		if plan.DurationMs > s.worst.DurationMs || s.calls == 1 {
+			s.worst = plan
+		}
+	}
+	return p
+}
+
+// byTotal returns the statements with the most total plan time first.
+func (p *plans) byTotal() []*planStats {
+	stats := make([]*planStats, 0, len(p.order))
+	for _, key := range p.order {
+		stats = append(stats, p.byQuery[key])
+	}
+	sort.SliceStable(stats, func(i, j int) bool { return stats[i].totalMs > stats[j].totalMs })
+	return stats
+}
+
+// forStatement returns the slowest plan logged for a statement, if any.
+func (p *plans) forStatement(statement string) (Plan, bool) {
+	s, ok := p.byQuery[statementKey(statement)]
+	if !ok {
+		return Plan{}, false
+	}
+	return s.worst, true
+}
+
+// statementKey compares statements regardless of whitespace.
+func statementKey(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// parsePlan reads an auto_explain message in text or JSON format.
+func parsePlan(message string) (Plan, bool) {
+	m := autoExplainPattern.FindStringSubmatch(message)
+	if m == nil {
+		return Plan{}, false
+	}
+	durationMs, _ := strconv.ParseFloat(m[1], 64)
+	body := strings.TrimSpace(m[2])
+	plan := Plan{DurationMs: durationMs, Text: body}
+
+	if strings.HasPrefix(body, "{") {
+		var doc struct {
+			QueryText string   `json:"Query Text"`
+			Plan      planNode `json:"Plan"`
+		}
+		if err := json.Unmarshal([]byte(body), &doc); err != nil {
+			return Plan{}, false
+		}
+		plan.Query = doc.QueryText
+		doc.Plan.seqScans(&plan.SeqScans)
+		return plan, true
+	}
+
+	lines := strings.Split(body, "\n")
+	var query []string
+	start := 0
+	if rest, ok := strings.CutPrefix(lines[0], "Query Text: "); ok {
+		query = append(query, rest)
+		start = 1
+		for start < len(lines) && !planNodePattern.MatchString(lines[start]) {
+			query = append(query, lines[start])
+			start++
+		}
+	}
+	plan.Query = strings.TrimSpace(strings.Join(query, "\n"))
+	plan.Text = strings.Join(lines[start:], "\n")
+	for _, line := range lines[start:] {
+		if sm := seqScanPattern.FindStringSubmatch(line); sm != nil {
+			plan.SeqScans = append(plan.SeqScans, sm[1])
+		}
+	}
+	return plan, true
+}
+
+// planNode is the part of an EXPLAIN (FORMAT JSON) node pgdoctor reads.
+type planNode struct {
+	NodeType     string     `json:"Node Type"`
+	RelationName string     `json:"Relation Name"`
+	Schema       string     `json:"Schema"`
+	Plans        []planNode `json:"Plans"`
+}
+
+func (n planNode) seqScans(out *[]string) {
+	if n.NodeType == "Seq Scan" && n.RelationName != "" {
+		name := n.RelationName
+		if n.Schema != "" {
+			name = n.Schema + "." + name
+		}
+		*out = append(*out, name)
+	}
+	for _, child := range n.Plans {
+		child.seqScans(out)
+	}
+}
+
+func analyzeSlowPlans(p *plans) *check.Report {
+	r := newLogReport("log-slow-plans", "Log: Slowest Plans", check.CategoryPerformance,
+		"Statements with the most execution time in auto_explain plans")
+
+	stats := p.byTotal()
+	if len(stats) == 0 {
+		addLogFinding(r, check.Finding{
+			Severity: check.SeverityOK,
+			Details:  "No auto_explain plans in the log; load auto_explain and set auto_explain.log_min_duration to capture them.",
+		})
+		return r
+	}
+
+	severity := check.SeverityWarn
+	table := &check.Table{Headers: []string{"Statement", "Plans", "Total", "Slowest", "Seq Scans"}}
+	for _, s := range stats[:min(len(stats), maxRows)] {
+		rowSeverity := check.SeverityWarn
+		if s.worst.DurationMs >= slowPlanFailMs {
+			rowSeverity = check.SeverityFail
+			severity = check.SeverityFail
+		}
+		table.Rows = append(table.Rows, check.TableRow{
+			Cells: []string{
+				oneLine(s.query, "(query text not logged)"),
+				strconv.Itoa(s.calls),
+				check.FormatDurationMs(s.totalMs),
+				check.FormatDurationMs(s.worst.DurationMs),
+				strings.Join(uniqueStrings(s.worst.SeqScans), ", "),
+			},
+			Severity: rowSeverity,
+		})
+	}
+
+	details := fmt.Sprintf("%d statements in auto_explain plans; the top one accounts for %s over %d runs.",
+		len(stats), check.FormatDurationMs(stats[0].totalMs), stats[0].calls)
+	addLogFinding(r, check.Finding{
+		Severity: severity,
+		Details:  details + representativePlan(stats[0].worst),
+		Table:    table,
+	})
+	return r
+}
+
+// representativePlan formats a plan for appending to a finding's details.
+func representativePlan(p Plan) string {
+	lines := strings.Split(p.Text, "\n")
+	if len(lines) > maxPlanLines {
+		lines = append(lines[:maxPlanLines], fmt.Sprintf("... (%d more lines)", len(lines)-maxPlanLines))
+	}
+	return fmt.Sprintf("\n\nRepresentative plan (%s):\n%s", check.FormatDurationMs(p.DurationMs), strings.Join(lines, "\n"))
+}
+
+// seqScanMatches reports whether a relation named in a plan (possibly
+// unqualified) is the schema-qualified table.
+func seqScanMatches(relation, table string) bool {
+	return relation == table || strings.HasSuffix(table, "."+relation)
+}
+
+func uniqueStrings(values []string) []string {
+	var out []string
+	seen := map[string]bool{}
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			out = append(out, v)
+		}
+	}
+	return out
+}
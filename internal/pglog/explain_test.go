@@ -0,0 +1,126 @@
+package pglog
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/fresha/pgdoctor/check"
+)
+
+const textPlanLog = `2026-03-01 10:00:00 UTC [4101] LOG:  duration: 1500.250 ms  plan:
+	Query Text: SELECT *
+	  FROM orders WHERE customer_id = 7
+	Seq Scan on orders  (cost=0.00..18334.00 rows=10 width=64) (actual time=0.02..1500.10 rows=12 loops=1)
+	  Filter: (customer_id = 7)
+	  Rows Removed by Filter: 999988
+2026-03-01 10:00:05 UTC [4102] LOG:  duration: 500.000 ms  plan:
+	Query Text: SELECT * FROM orders WHERE customer_id = 7
+	Seq Scan on orders  (cost=0.00..18334.00 rows=10 width=64) (actual time=0.02..500.00 rows=12 loops=1)
+2026-03-01 10:00:06 UTC [4103] LOG:  temporary file: path "base/pgsql_tmp/pgsql_tmp4103.0", size 1048576
+2026-03-01 10:00:06 UTC [4103] STATEMENT:  SELECT * FROM orders WHERE customer_id = 7
+`
+
+func TestParsePlan_Text(t *testing.T) {
+	t.Parallel()
+
+	entries, err := Parse(strings.NewReader(textPlanLog), FormatStderr)
+	require.NoError(t, err)
+
+	plan, ok := parsePlan(entries[0].Message)
+	require.True(t, ok)
+	assert.InDelta(t, 1500.25, plan.DurationMs, 0.001)
+	assert.Equal(t, "SELECT *\n  FROM orders WHERE customer_id = 7", plan.Query)
+	assert.Equal(t, []string{"orders"}, plan.SeqScans)
+	assert.True(t, strings.HasPrefix(plan.Text, "Seq Scan on orders"))
+
+	_, ok = parsePlan("duration: 1.2 ms")
+	assert.False(t, ok, "plain log_min_duration_statement lines are not plans")
+}
+
+func TestParsePlan_JSON(t *testing.T) {
+	t.Parallel()
+
+	message := "duration: 42.5 ms  plan:\n" + `{
+  "Query Text": "SELECT count(*) FROM public.events e JOIN accounts a USING (account_id)",
+  "Plan": {
+    "Node Type": "Aggregate",
+    "Plans": [
+      {"Node Type": "Hash Join", "Plans": [
+        {"Node Type": "Seq Scan", "Relation Name": "events", "Schema": "public"},
+        {"Node Type": "Index Scan", "Relation Name": "accounts"}
+      ]}
+    ]
+  }
+}`
+
+	plan, ok := parsePlan(message)
+	require.True(t, ok)
+	assert.Equal(t, "SELECT count(*) FROM public.events e JOIN accounts a USING (account_id)", plan.Query)
+	assert.Equal(t, []string{"public.events"}, plan.SeqScans)
+}
+
+func TestAnalyze_SlowPlans(t *testing.T) {
+	t.Parallel()
+
+	entries, err := Parse(strings.NewReader(textPlanLog), FormatStderr)
+	require.NoError(t, err)
+	reports := Analyze(entries)
+
+	r := findReport(t, reports, "log-slow-plans")
+	assert.Equal(t, check.SeverityWarn, r.Severity)
+	f := r.Results[0]
+	require.Len(t, f.Table.Rows, 1, "plans are grouped by statement regardless of whitespace")
+	assert.Equal(t, []string{"SELECT * FROM orders WHERE customer_id = 7", "2", "2.0s", "1.5s", "orders"}, f.Table.Rows[0].Cells)
+	assert.Contains(t, f.Details, "Representative plan (1.5s):\nSeq Scan on orders")
+
+	temp := findReport(t, reports, "log-temp-files").Results[0]
+	assert.Contains(t, temp.Details, "Representative plan (1.5s)", "spilling statements carry their plan")
+}
+
+func TestCrossReference_FlaggedSeqScans(t *testing.T) {
+	t.Parallel()
+
+	entries, err := Parse(strings.NewReader(textPlanLog), FormatStderr)
+	require.NoError(t, err)
+	reports := Analyze(entries)
+
+	seq := check.NewReport(check.Metadata{CheckID: "table-seq-scans", Name: "Table Sequential Scans"})
+	seq.AddFinding(check.Finding{
+		ID:       "high-seq-scans",
+		Name:     "High Sequential Scans",
+		Severity: check.SeverityFail,
+		Details:  "Found 2 tables with very high sequential scan ratios:\npublic.orders (seq: 900, idx: 2, ratio: 450.0, rows: 1000000, size: 120.0 MB)\npublic.audit (seq: 80, idx: 1, ratio: 80.0, rows: 60000, size: 8.0 MB)",
+	})
+
+	CrossReference(entries, reports, []*check.Report{seq})
+
+	r := findReport(t, reports, "log-slow-plans")
+	require.Len(t, r.Results, 2)
+	flagged := r.Results[1]
+	assert.Equal(t, "flagged-seq-scans", flagged.ID)
+	assert.Equal(t, check.SeverityWarn, flagged.Severity)
+	require.Len(t, flagged.Table.Rows, 1)
+	assert.Equal(t, "public.orders", flagged.Table.Rows[0].Cells[0])
+	assert.Contains(t, flagged.Details, "Representative plan (1.5s)")
+	assert.Contains(t, r.Results[0].Details, "table-seq-scans: FAIL (High Sequential Scans)")
+}
+
+func TestCrossReference_NoFlaggedTables(t *testing.T) {
+	t.Parallel()
+
+	entries, err := Parse(strings.NewReader(textPlanLog), FormatStderr)
+	require.NoError(t, err)
+	reports := Analyze(entries)
+
+	seq := check.NewReport(check.Metadata{CheckID: "table-seq-scans", Name: "Table Sequential Scans"})
+	seq.AddFinding(check.Finding{ID: "high-seq-scans", Name: "High Sequential Scans", Severity: check.SeverityOK})
+
+	CrossReference(entries, reports, []*check.Report{seq})
+
+	r := findReport(t, reports, "log-slow-plans")
+	require.Len(t, r.Results, 2)
+	assert.Equal(t, check.SeverityOK, r.Results[1].Severity)
+}
@@ -0,0 +1,309 @@
+// Package pglog parses PostgreSQL server logs and turns recurring problems
+// (deadlocks, canceled autovacuums, temp file spills, checkpoint warnings,
+// connection churn) into check reports that render like live check results.
+package pglog
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Format is a log_destination output format.
+type Format string
+
+const (
+	FormatAuto   Format = "auto"
+	FormatStderr Format = "stderr"
+	FormatCSV    Format = "csvlog"
+	FormatJSON   Format = "jsonlog"
+)
+
+// ParseFormat validates a --format value.
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(s); f {
+	case FormatAuto, FormatStderr, FormatCSV, FormatJSON:
+		return f, nil
+	default:
+		return "", fmt.Errorf("unknown log format %q (valid: auto, stderr, csvlog, jsonlog)", s)
+	}
+}
+
+// Entry is one log message with its DETAIL, HINT, CONTEXT and STATEMENT
+// lines attached.
+type Entry struct {
+	Time        time.Time // zero when the line prefix has no timestamp
+	PID         int
+	User        string
+	Database    string
+	Application string
+	Severity    string // LOG, ERROR, FATAL, ...
+	SQLState    string // csvlog and jsonlog only
+	Message     string
+	Detail      string
+	Hint        string
+	Context     string
+	Statement   string
+}
+
+// maxLineBytes bounds a single log line; multi-megabyte statements are cut.
+const maxLineBytes = 4 << 20
+
+var (
+	// PostgreSQL separates the severity from the message with two spaces,
+	// which keeps this from matching inside an arbitrary log_line_prefix.
+	stderrLinePattern = regexp.MustCompile(`^(.*?)\b(DEBUG[1-5]?|LOG|INFO|NOTICE|WARNING|ERROR|FATAL|PANIC|DETAIL|HINT|QUERY|CONTEXT|STATEMENT|LOCATION):  (.*)$`)
+	prefixTimePattern = regexp.MustCompile(`(\d{4}-\d{2}-\d{2}[ T]\d{2}:\d{2}:\d{2}(?:\.\d+)?)`)
+	prefixPIDPattern  = regexp.MustCompile(`\[(\d+)\]`)
+	prefixUserPattern = regexp.MustCompile(`(\w+)@(\w+)`)
+	csvStartPattern   = regexp.MustCompile(`^\d{4}-\d{2}-\d{2} \d{2}:\d{2}:\d{2}(?:\.\d+)? [^,]*,`)
+)
+
+// Parse reads a PostgreSQL log. FormatAuto detects the format from the first
+// non-empty line.
+func Parse(r io.Reader, format Format) ([]Entry, error) {
+	br := bufio.NewReaderSize(r, 64<<10)
+	if format == FormatAuto {
+		format = detectFormat(br)
+	}
+
+	switch format {
+	case FormatCSV:
+		return parseCSV(br)
+	case FormatJSON:
+		return parseJSON(br)
+	default:
+		return parseStderr(br)
+	}
+}
+
+func detectFormat(br *bufio.Reader) Format {
+	head, _ := br.Peek(br.Size())
+	for line := range bytes.SplitSeq(head, []byte("\n")) {
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+		switch {
+		case line[0] == '{':
+			return FormatJSON
+		case csvStartPattern.Match(line):
+			return FormatCSV
+		default:
+			return FormatStderr
+		}
+	}
+	return FormatStderr
+}
+
+func parseStderr(r io.Reader) ([]Entry, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64<<10), maxLineBytes)
+
+	var entries []Entry
+	lastByPID := map[int]int{} // pid -> index of its last primary entry
+	last := -1                 // index of the entry continuation lines belong to
+	var lastField *string
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		m := stderrLinePattern.FindStringSubmatch(line)
+		if m == nil {
+			// Continuation of a multi-line message or statement
+			if lastField != nil {
+				*lastField += "\n" + strings.TrimPrefix(line, "\t")
+			}
+			continue
+		}
+
+		prefix, severity, text := m[1], m[2], m[3]
+		pid := 0
+		if pm := prefixPIDPattern.FindStringSubmatch(prefix); pm != nil {
+			pid, _ = strconv.Atoi(pm[1])
+		}
+
+		switch severity {
+		case "LOCATION":
+			// Source positions from log_error_verbosity = verbose
+			lastField = nil
+			continue
+		case "DETAIL", "HINT", "CONTEXT", "STATEMENT", "QUERY":
+			idx, ok := lastByPID[pid]
+			if !ok {
+				idx = last
+			}
+			if idx < 0 {
+				lastField = nil
+				continue
+			}
+			lastField = entries[idx].attached(severity)
+			*lastField = text
+			last = idx
+			continue
+		}
+
+		e := Entry{PID: pid, Severity: severity, Message: text}
+		if tm := prefixTimePattern.FindStringSubmatch(prefix); tm != nil {
+			e.Time = parseTime(tm[1])
+		}
+		if um := prefixUserPattern.FindStringSubmatch(prefix); um != nil {
+			e.User, e.Database = um[1], um[2]
+		}
+		entries = append(entries, e)
+		last = len(entries) - 1
+		lastByPID[pid] = last
+		lastField = &entries[last].Message
+	}
+	if err := scanner.Err(); err != nil {
+		return entries, fmt.Errorf("reading log: %w", err)
+	}
+	return entries, nil
+}
+
+// attached returns the field a secondary stderr line fills.
+func (e *Entry) attached(severity string) *string {
+	switch severity {
+	case "DETAIL":
+		return &e.Detail
+	case "HINT":
+		return &e.Hint
+	case "CONTEXT":
+		return &e.Context
+	default:
+		return &e.Statement
+	}
+}
+
+// csvlog column positions (stable since PostgreSQL 9.0; later versions only
+// append columns).
+const (
+	csvTime        = 0
+	csvUser        = 1
+	csvDatabase    = 2
+	csvPID         = 3
+	csvSeverity    = 11
+	csvSQLState    = 12
+	csvMessage     = 13
+	csvDetail      = 14
+	csvHint        = 15
+	csvContext     = 18
+	csvQuery       = 19
+	csvApplication = 22
+)
+
+func parseCSV(r io.Reader) ([]Entry, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.LazyQuotes = true
+	cr.ReuseRecord = true
+
+	var entries []Entry
+	for {
+		rec, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			return entries, nil
+		}
+		if err != nil {
+			return entries, fmt.Errorf("reading csvlog: %w", err)
+		}
+		if len(rec) <= csvQuery {
+			continue
+		}
+		pid, _ := strconv.Atoi(rec[csvPID])
+		e := Entry{
+			Time:      parseTime(rec[csvTime]),
+			PID:       pid,
+			User:      rec[csvUser],
+			Database:  rec[csvDatabase],
+			Severity:  rec[csvSeverity],
+			SQLState:  rec[csvSQLState],
+			Message:   rec[csvMessage],
+			Detail:    rec[csvDetail],
+			Hint:      rec[csvHint],
+			Context:   rec[csvContext],
+			Statement: rec[csvQuery],
+		}
+		if len(rec) > csvApplication {
+			e.Application = rec[csvApplication]
+		}
+		entries = append(entries, e)
+	}
+}
+
+// jsonRecord holds the jsonlog keys pgdoctor reads (PostgreSQL 15+).
+type jsonRecord struct {
+	Timestamp   string `json:"timestamp"`
+	User        string `json:"user"`
+	Database    string `json:"dbname"`
+	PID         int    `json:"pid"`
+	Severity    string `json:"error_severity"`
+	SQLState    string `json:"state_code"`
+	Message     string `json:"message"`
+	Detail      string `json:"detail"`
+	Hint        string `json:"hint"`
+	Context     string `json:"context"`
+	Statement   string `json:"statement"`
+	Application string `json:"application_name"`
+}
+
+func parseJSON(r io.Reader) ([]Entry, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64<<10), maxLineBytes)
+
+	var entries []Entry
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var rec jsonRecord
+		if err := json.Unmarshal(line, &rec); err != nil {
+			return entries, fmt.Errorf("reading jsonlog line %d: %w", lineNo, err)
+		}
+		entries = append(entries, Entry{
+			Time:        parseTime(rec.Timestamp),
+			PID:         rec.PID,
+			User:        rec.User,
+			Database:    rec.Database,
+			Application: rec.Application,
+			Severity:    rec.Severity,
+			SQLState:    rec.SQLState,
+			Message:     rec.Message,
+			Detail:      rec.Detail,
+			Hint:        rec.Hint,
+			Context:     rec.Context,
+			Statement:   rec.Statement,
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return entries, fmt.Errorf("reading jsonlog: %w", err)
+	}
+	return entries, nil
+}
+
+// parseTime reads the timestamp PostgreSQL writes for %m/%t and in csvlog and
+// jsonlog. The zone abbreviation is ignored: only intervals between entries
+// of the same log are used.
+func parseTime(s string) time.Time {
+	s = strings.TrimSpace(s)
+	if i := strings.IndexByte(s, ' '); i >= 0 {
+		if j := strings.IndexByte(s[i+1:], ' '); j >= 0 {
+			s = s[:i+1+j]
+		}
+	}
+	s = strings.Replace(s, "T", " ", 1)
+	for _, layout := range []string{"2006-01-02 15:04:05.999999999", "2006-01-02 15:04:05"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
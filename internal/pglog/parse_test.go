@@ -0,0 +1,95 @@
+package pglog
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const stderrLog = `2026-03-01 10:00:00.123 UTC [4101] app@orders ERROR:  deadlock detected
+2026-03-01 10:00:00.123 UTC [4101] app@orders DETAIL:  Process 4101 waits for ShareLock on transaction 812; blocked by process 4102.
+	Process 4102 waits for ShareLock on transaction 811; blocked by process 4101.
+2026-03-01 10:00:00.124 UTC [4102] app@orders LOG:  duration: 1.2 ms
+2026-03-01 10:00:00.123 UTC [4101] app@orders HINT:  See server log for query details.
+2026-03-01 10:00:00.123 UTC [4101] app@orders STATEMENT:  UPDATE accounts
+	   SET balance = balance - 1 WHERE id = 2
+2026-03-01 10:05:00 UTC:10.0.0.7(51234):app@orders:[4200]:LOG:  checkpoints are occurring too frequently (12 seconds apart)
+2026-03-01 10:05:00 UTC:10.0.0.7(51234):app@orders:[4200]:HINT:  Consider increasing the configuration parameter "max_wal_size".
+`
+
+func TestParse_Stderr(t *testing.T) {
+	t.Parallel()
+
+	entries, err := Parse(strings.NewReader(stderrLog), FormatAuto)
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+
+	deadlock := entries[0]
+	assert.Equal(t, "ERROR", deadlock.Severity)
+	assert.Equal(t, 4101, deadlock.PID)
+	assert.Equal(t, "app", deadlock.User)
+	assert.Equal(t, "orders", deadlock.Database)
+	assert.Equal(t, "deadlock detected", deadlock.Message)
+	assert.Contains(t, deadlock.Detail, "blocked by process 4101.")
+	assert.Equal(t, "See server log for query details.", deadlock.Hint)
+	assert.Equal(t, "UPDATE accounts\n   SET balance = balance - 1 WHERE id = 2", deadlock.Statement)
+	assert.Equal(t, time.Date(2026, 3, 1, 10, 0, 0, 123000000, time.UTC), deadlock.Time)
+
+	assert.Equal(t, "duration: 1.2 ms", entries[1].Message, "secondary lines attach to their own backend")
+
+	rds := entries[2]
+	assert.Equal(t, 4200, rds.PID)
+	assert.Equal(t, "LOG", rds.Severity)
+	assert.Contains(t, rds.Hint, "max_wal_size")
+}
+
+func TestParse_CSV(t *testing.T) {
+	t.Parallel()
+
+	log := `2026-03-01 10:00:00.123 UTC,"app","orders",4101,"10.0.0.7:51234",65e1a1b0.1005,3,"UPDATE",2026-03-01 09:59:00 UTC,3/17,812,ERROR,40P01,"deadlock detected","Process 4101 waits for ShareLock on transaction 812.
+Process 4102 waits for ShareLock on transaction 811.","See server log for query details.",,,"while updating tuple (0,1) in relation ""accounts""","UPDATE accounts SET balance = 0",,,"api","client backend",,0
+`
+	entries, err := Parse(strings.NewReader(log), FormatAuto)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+
+	e := entries[0]
+	assert.Equal(t, "40P01", e.SQLState)
+	assert.Equal(t, "deadlock detected", e.Message)
+	assert.Contains(t, e.Detail, "\nProcess 4102")
+	assert.Equal(t, `while updating tuple (0,1) in relation "accounts"`, e.Context)
+	assert.Equal(t, "UPDATE accounts SET balance = 0", e.Statement)
+	assert.Equal(t, "api", e.Application)
+	assert.Equal(t, 4101, e.PID)
+}
+
+func TestParse_JSON(t *testing.T) {
+	t.Parallel()
+
+	log := `{"timestamp":"2026-03-01 10:00:00.123 UTC","user":"app","dbname":"orders","pid":4101,"error_severity":"LOG","message":"temporary file: path \"base/pgsql_tmp/pgsql_tmp4101.0\", size 1048576","statement":"SELECT * FROM orders ORDER BY created_at","application_name":"api"}
+
+{"timestamp":"2026-03-01 10:00:01.000 UTC","pid":4102,"error_severity":"LOG","message":"connection authorized: user=app database=orders"}
+`
+	entries, err := Parse(strings.NewReader(log), FormatAuto)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "SELECT * FROM orders ORDER BY created_at", entries[0].Statement)
+	assert.Equal(t, time.Second-123*time.Millisecond, entries[1].Time.Sub(entries[0].Time))
+
+	_, err = Parse(strings.NewReader("{not json}\n"), FormatJSON)
+	assert.ErrorContains(t, err, "jsonlog line 1")
+}
+
+func TestParseFormat(t *testing.T) {
+	t.Parallel()
+
+	f, err := ParseFormat("csvlog")
+	require.NoError(t, err)
+	assert.Equal(t, FormatCSV, f)
+
+	_, err = ParseFormat("syslog")
+	assert.ErrorContains(t, err, `unknown log format "syslog"`)
+}
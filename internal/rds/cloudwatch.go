@@ -0,0 +1,137 @@
+package rds
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fresha/pgdoctor/check"
+)
+
+// cloudWatchAPIVersion is the CloudWatch Query API version the request and
+// response follow.
+const cloudWatchAPIVersion = "2010-08-01"
+
+// DefaultMetricsWindow is how far back host metrics are read by default:
+// long enough to smooth over spikes, short enough to reflect the database's
+// state when checks run.
+const DefaultMetricsWindow = time.Hour
+
+// metricPeriod is the resolution metrics are read at; RDS publishes its
+// basic metrics every minute.
+const metricPeriod = time.Minute
+
+// metricQuery is one GetMetricData query: a metric of AWS/RDS and the
+// statistic to read over each period.
+type metricQuery struct {
+	ID     string
+	Metric string
+	Stat   string
+}
+
+var hostMetricQueries = []metricQuery{
+	{"cpu", "CPUUtilization", "Average"},
+	{"cpumax", "CPUUtilization", "Maximum"},
+	{"memory", "FreeableMemory", "Minimum"},
+	{"readiops", "ReadIOPS", "Average"},
+	{"writeiops", "WriteIOPS", "Average"},
+	{"replicalag", "ReplicaLag", "Maximum"},
+}
+
+// HostMetrics reads the CloudWatch metrics of the instance with the given
+// identifier or ARN over the window ending now.
+func (c *Client) HostMetrics(ctx context.Context, instanceID string, window time.Duration) (*check.HostMetrics, error) {
+	region, err := c.region(instanceID)
+	if err != nil {
+		return nil, err
+	}
+	endpoint := c.CloudWatchEndpoint
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://monitoring.%s.amazonaws.com", region)
+	}
+
+	now := time.Now
+	if c.now != nil {
+		now = c.now
+	}
+	end := now().UTC().Truncate(metricPeriod)
+
+	// Metrics are dimensioned by identifier, never by ARN.
+	id := instanceID
+	if arnRegion(instanceID) != "" {
+		id = instanceID[strings.LastIndex(instanceID, ":")+1:]
+	}
+
+	form := url.Values{
+		"Action":    {"GetMetricData"},
+		"Version":   {cloudWatchAPIVersion},
+		"StartTime": {end.Add(-window).Format(time.RFC3339)},
+		"EndTime":   {end.Format(time.RFC3339)},
+	}
+	for i, q := range hostMetricQueries {
+		p := "MetricDataQueries.member." + strconv.Itoa(i+1) + "."
+		form.Set(p+"Id", q.ID)
+		form.Set(p+"MetricStat.Metric.Namespace", "AWS/RDS")
+		form.Set(p+"MetricStat.Metric.MetricName", q.Metric)
+		form.Set(p+"MetricStat.Metric.Dimensions.member.1.Name", "DBInstanceIdentifier")
+		form.Set(p+"MetricStat.Metric.Dimensions.member.1.Value", id)
+		form.Set(p+"MetricStat.Period", strconv.Itoa(int(metricPeriod.Seconds())))
+		form.Set(p+"MetricStat.Stat", q.Stat)
+	}
+
+	var out metricDataResponse
+	if err := c.call(ctx, "monitoring", region, endpoint, form, &out); err != nil {
+		return nil, err
+	}
+
+	values := make(map[string][]float64, len(out.Results))
+	for _, r := range out.Results {
+		values[r.ID] = r.Values
+	}
+	if len(values["cpu"]) == 0 {
+		return nil, fmt.Errorf("GetMetricData: no CloudWatch datapoints for instance %s in the last %s", id, check.FormatDurationSec(int64(window.Seconds())))
+	}
+
+	m := &check.HostMetrics{
+		Window:        window,
+		CPUPercent:    mean(values["cpu"]),
+		MaxCPUPercent: maxOf(values["cpumax"]),
+		ReadIOPS:      mean(values["readiops"]),
+		WriteIOPS:     mean(values["writeiops"]),
+		// ReplicaLag is only published for replicas.
+		ReplicaLagSeconds: maxOf(values["replicalag"]),
+	}
+	if mem := values["memory"]; len(mem) > 0 {
+		m.FreeableMemoryBytes = int64(slices.Min(mem))
+	}
+	return m, nil
+}
+
+type metricDataResponse struct {
+	Results []struct {
+		ID     string    `xml:"Id"`
+		Values []float64 `xml:"Values>member"`
+	} `xml:"GetMetricDataResult>MetricDataResults>member"`
+}
+
+func mean(v []float64) float64 {
+	if len(v) == 0 {
+		return 0
+	}
+	var sum float64
+	for _, x := range v {
+		sum += x
+	}
+	return sum / float64(len(v))
+}
+
+func maxOf(v []float64) float64 {
+	if len(v) == 0 {
+		return 0
+	}
+	return slices.Max(v)
+}
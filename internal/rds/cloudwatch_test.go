@@ -0,0 +1,83 @@
+package rds
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/fresha/pgdoctor/check"
+	"github.com/fresha/pgdoctor/internal/awsauth"
+)
+
+const metricsOrders = `<GetMetricDataResponse xmlns="http://monitoring.amazonaws.com/doc/2010-08-01/">
+  <GetMetricDataResult>
+    <MetricDataResults>
+      <member><Id>cpu</Id><StatusCode>Complete</StatusCode><Values><member>40</member><member>50</member></Values></member>
+      <member><Id>cpumax</Id><StatusCode>Complete</StatusCode><Values><member>71.5</member><member>92</member></Values></member>
+      <member><Id>memory</Id><StatusCode>Complete</StatusCode><Values><member>4294967296</member><member>2147483648</member></Values></member>
+      <member><Id>readiops</Id><StatusCode>Complete</StatusCode><Values><member>1000</member><member>1400</member></Values></member>
+      <member><Id>writeiops</Id><StatusCode>Complete</StatusCode><Values><member>3000</member><member>3800</member></Values></member>
+      <member><Id>replicalag</Id><StatusCode>Complete</StatusCode><Values></Values></member>
+    </MetricDataResults>
+  </GetMetricDataResult>
+</GetMetricDataResponse>`
+
+func TestHostMetrics(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 3, 2, 10, 30, 45, 0, time.UTC)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Contains(t, r.Header.Get("Authorization"), "/us-east-2/monitoring/aws4_request", "the ARN's region wins")
+		assert.NoError(t, r.ParseForm())
+		assert.Equal(t, "GetMetricData", r.PostForm.Get("Action"))
+		assert.Equal(t, "2026-03-02T09:30:00Z", r.PostForm.Get("StartTime"))
+		assert.Equal(t, "2026-03-02T10:30:00Z", r.PostForm.Get("EndTime"))
+		assert.Equal(t, "CPUUtilization", r.PostForm.Get("MetricDataQueries.member.1.MetricStat.Metric.MetricName"))
+		assert.Equal(t, "orders", r.PostForm.Get("MetricDataQueries.member.1.MetricStat.Metric.Dimensions.member.1.Value"))
+		assert.Equal(t, "Minimum", r.PostForm.Get("MetricDataQueries.member.3.MetricStat.Stat"))
+
+		_, _ = w.Write([]byte(metricsOrders))
+	}))
+	defer srv.Close()
+
+	c := &Client{
+		Region:             "eu-west-1",
+		Credentials:        &awsauth.Credentials{AccessKeyID: "AKID", SecretAccessKey: "secret"},
+		CloudWatchEndpoint: srv.URL,
+		Client:             srv.Client(),
+		now:                func() time.Time { return now },
+	}
+	m, err := c.HostMetrics(context.Background(), "arn:aws:rds:us-east-2:123456789012:db:orders", time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, &check.HostMetrics{
+		Window:              time.Hour,
+		CPUPercent:          45,
+		MaxCPUPercent:       92,
+		FreeableMemoryBytes: 2147483648,
+		ReadIOPS:            1200,
+		WriteIOPS:           3400,
+	}, m)
+}
+
+func TestHostMetrics_NoData(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<GetMetricDataResponse><GetMetricDataResult><MetricDataResults/></GetMetricDataResult></GetMetricDataResponse>`))
+	}))
+	defer srv.Close()
+
+	c := &Client{
+		Region:             "eu-west-1",
+		Credentials:        &awsauth.Credentials{AccessKeyID: "AKID", SecretAccessKey: "secret"},
+		CloudWatchEndpoint: srv.URL,
+		Client:             srv.Client(),
+	}
+	_, err := c.HostMetrics(context.Background(), "orders", time.Hour)
+	require.EqualError(t, err, "GetMetricData: no CloudWatch datapoints for instance orders in the last 1h")
+}
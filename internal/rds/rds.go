@@ -0,0 +1,283 @@
+// Package rds reads instance metadata from the Amazon RDS API, so checks can
+// size their recommendations for RDS and Aurora instances without the
+// instance class, memory, and storage being passed by hand, and recent host
+// metrics from CloudWatch.
+package rds
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fresha/pgdoctor/check"
+	"github.com/fresha/pgdoctor/internal/awsauth"
+)
+
+// apiVersion is the RDS Query API version the request and response follow.
+const apiVersion = "2014-10-31"
+
+// Client calls DescribeDBInstances and CloudWatch GetMetricData. Credentials and region default to the
+// standard AWS environment variables; an instance ARN names its own region,
+// which takes precedence.
+type Client struct {
+	Region      string
+	Credentials *awsauth.Credentials
+	Endpoint    string // Defaults to https://rds.<region>.amazonaws.com
+
+	// CloudWatchEndpoint defaults to https://monitoring.<region>.amazonaws.com
+	CloudWatchEndpoint string
+	Client             *http.Client
+
+	now func() time.Time // For tests
+}
+
+// DescribeInstance returns the metadata of the RDS or Aurora PostgreSQL
+// instance with the given identifier or ARN.
+func (c *Client) DescribeInstance(ctx context.Context, instanceID string) (*check.InstanceMetadata, error) {
+	region, err := c.region(instanceID)
+	if err != nil {
+		return nil, err
+	}
+	endpoint := c.Endpoint
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://rds.%s.amazonaws.com", region)
+	}
+
+	var out describeResponse
+	err = c.call(ctx, "rds", region, endpoint, url.Values{
+		"Action":               {"DescribeDBInstances"},
+		"Version":              {apiVersion},
+		"DBInstanceIdentifier": {instanceID},
+	}, &out)
+	if err != nil {
+		return nil, err
+	}
+	if len(out.Instances) == 0 {
+		return nil, fmt.Errorf("DescribeDBInstances: no instance %s", instanceID)
+	}
+	return out.Instances[0].metadata()
+}
+
+// region is the region to call for instanceID.
+func (c *Client) region(instanceID string) (string, error) {
+	region := c.Region
+	if r := arnRegion(instanceID); r != "" {
+		region = r
+	}
+	if region == "" {
+		region = awsauth.Region()
+	}
+	if region == "" {
+		return "", fmt.Errorf("AWS_REGION must be set to describe RDS instance %s", instanceID)
+	}
+	return region, nil
+}
+
+// call sends a signed Query API request and decodes the XML response into
+// out.
+func (c *Client) call(ctx context.Context, service, region, endpoint string, form url.Values, out any) error {
+	var creds awsauth.Credentials
+	if c.Credentials != nil {
+		creds = *c.Credentials
+	} else {
+		var err error
+		if creds, err = awsauth.FromEnv(); err != nil {
+			return err
+		}
+	}
+
+	action := form.Get("Action")
+	body := []byte(form.Encode())
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimRight(endpoint, "/")+"/", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=utf-8")
+	now := time.Now
+	if c.now != nil {
+		now = c.now
+	}
+	awsauth.Sign(req, body, creds, service, region, now())
+
+	client := c.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		var e errorResponse
+		if xml.Unmarshal(msg, &e) == nil && e.Error.Code != "" {
+			return fmt.Errorf("%s: %s: %s", action, e.Error.Code, e.Error.Message)
+		}
+		return fmt.Errorf("%s: %s: %s", action, resp.Status, strings.TrimSpace(string(msg)))
+	}
+
+	if err := xml.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("decoding %s response: %w", action, err)
+	}
+	return nil
+}
+
+type errorResponse struct {
+	Error struct {
+		Code    string `xml:"Code"`
+		Message string `xml:"Message"`
+	} `xml:"Error"`
+}
+
+type describeResponse struct {
+	Instances []dbInstance `xml:"DescribeDBInstancesResult>DBInstances>DBInstance"`
+}
+
+// dbInstance holds the DBInstance fields pgdoctor uses.
+// https://docs.aws.amazon.com/AmazonRDS/latest/APIReference/API_DBInstance.html
+type dbInstance struct {
+	Identifier              string `xml:"DBInstanceIdentifier"`
+	Class                   string `xml:"DBInstanceClass"`
+	Engine                  string `xml:"Engine"`
+	EngineVersion           string `xml:"EngineVersion"`
+	AllocatedStorage        int    `xml:"AllocatedStorage"`
+	MaxAllocatedStorage     int    `xml:"MaxAllocatedStorage"`
+	StorageType             string `xml:"StorageType"`
+	IOPS                    int    `xml:"Iops"`
+	MultiAZ                 bool   `xml:"MultiAZ"`
+	AvailabilityZone        string `xml:"AvailabilityZone"`
+	SecondaryZone           string `xml:"SecondaryAvailabilityZone"`
+	StorageEncrypted        bool   `xml:"StorageEncrypted"`
+	PubliclyAccessible      bool   `xml:"PubliclyAccessible"`
+	DeletionProtection      bool   `xml:"DeletionProtection"`
+	BackupRetentionPeriod   int    `xml:"BackupRetentionPeriod"`
+	AutoMinorVersionUpgrade bool   `xml:"AutoMinorVersionUpgrade"`
+	Tags                    []struct {
+		Key   string `xml:"Key"`
+		Value string `xml:"Value"`
+	} `xml:"TagList>Tag"`
+}
+
+func (d *dbInstance) metadata() (*check.InstanceMetadata, error) {
+	if d.Engine != "postgres" && d.Engine != "aurora-postgresql" {
+		return nil, fmt.Errorf("RDS instance %s runs %s, not PostgreSQL", d.Identifier, d.Engine)
+	}
+
+	m := &check.InstanceMetadata{
+		InstanceID:              d.Identifier,
+		InstanceClass:           d.Class,
+		StorageType:             d.StorageType,
+		StorageGB:               d.AllocatedStorage,
+		StorageIOPS:             d.IOPS,
+		EngineVersion:           d.EngineVersion,
+		MultiAZ:                 d.MultiAZ,
+		AvailabilityZone:        d.AvailabilityZone,
+		SecondaryAZ:             d.SecondaryZone,
+		StorageAutoscaling:      d.MaxAllocatedStorage > d.AllocatedStorage,
+		MaxStorageThresholdGB:   d.MaxAllocatedStorage,
+		StorageEncrypted:        d.StorageEncrypted,
+		PubliclyAccessible:      d.PubliclyAccessible,
+		DeletionProtection:      d.DeletionProtection,
+		BackupRetentionDays:     d.BackupRetentionPeriod,
+		AutoMinorVersionUpgrade: d.AutoMinorVersionUpgrade,
+	}
+	if strings.HasPrefix(d.Engine, "aurora") {
+		// Aurora storage is shared by the cluster and grows on its own; the
+		// instance reports a placeholder size.
+		m.StorageGB = 0
+		m.StorageAutoscaling = false
+		m.MaxStorageThresholdGB = 0
+	}
+	m.EngineVersionMajor, m.EngineVersionMinor = parseVersion(d.EngineVersion)
+	m.VCPUCores, m.MemoryGB = classSpecs(d.Class)
+	if len(d.Tags) > 0 {
+		m.Tags = make(map[string]string, len(d.Tags))
+		for _, t := range d.Tags {
+			m.Tags[t.Key] = t.Value
+		}
+	}
+	return m, nil
+}
+
+// parseVersion splits an engine version such as "16.4" or
+// "15.4.R2" into its major and minor numbers.
+func parseVersion(v string) (major, minor int) {
+	parts := strings.SplitN(v, ".", 3)
+	major, _ = strconv.Atoi(parts[0])
+	if len(parts) > 1 {
+		minor, _ = strconv.Atoi(parts[1])
+	}
+	return major, minor
+}
+
+// classSpecs returns the vCPUs and memory of an instance class such as
+// db.r6g.xlarge, or zeros for classes it does not know (db.serverless,
+// optimized-CPU variants). The API does not report them; they follow from
+// the size and the family's memory per vCPU.
+func classSpecs(class string) (vcpus int, memoryGB float64) {
+	parts := strings.Split(strings.TrimPrefix(class, "db."), ".")
+	if len(parts) != 2 {
+		return 0, 0
+	}
+	family, size := parts[0], parts[1]
+
+	switch {
+	case size == "micro" || size == "small" || size == "medium" || size == "large":
+		vcpus = 2
+	case size == "xlarge":
+		vcpus = 4
+	case strings.HasSuffix(size, "xlarge"):
+		n, err := strconv.Atoi(strings.TrimSuffix(size, "xlarge"))
+		if err != nil {
+			return 0, 0
+		}
+		vcpus = 4 * n
+	default:
+		return 0, 0
+	}
+
+	var perCPU float64
+	switch {
+	case strings.HasPrefix(family, "t"):
+		// Burstable classes below large have less memory than their vCPUs
+		// suggest.
+		switch size {
+		case "micro":
+			return vcpus, 1
+		case "small":
+			return vcpus, 2
+		case "medium":
+			return vcpus, 4
+		}
+		perCPU = 4
+	case strings.HasPrefix(family, "x2i"):
+		perCPU = 32
+	case strings.HasPrefix(family, "x2"):
+		perCPU = 16
+	case strings.HasPrefix(family, "m"):
+		perCPU = 4
+	case strings.HasPrefix(family, "r"):
+		perCPU = 8
+	default:
+		return vcpus, 0
+	}
+	return vcpus, perCPU * float64(vcpus)
+}
+
+// arnRegion returns the region of an RDS ARN, or "" for identifiers.
+func arnRegion(instanceID string) string {
+	parts := strings.SplitN(instanceID, ":", 5)
+	if len(parts) < 5 || parts[0] != "arn" {
+		return ""
+	}
+	return parts[3]
+}
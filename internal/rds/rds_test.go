@@ -0,0 +1,165 @@
+package rds
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/fresha/pgdoctor/check"
+	"github.com/fresha/pgdoctor/internal/awsauth"
+)
+
+const describeOrders = `<DescribeDBInstancesResponse xmlns="http://rds.amazonaws.com/doc/2014-10-31/">
+  <DescribeDBInstancesResult>
+    <DBInstances>
+      <DBInstance>
+        <DBInstanceIdentifier>orders-primary</DBInstanceIdentifier>
+        <DBInstanceClass>db.r6g.xlarge</DBInstanceClass>
+        <Engine>postgres</Engine>
+        <EngineVersion>16.4</EngineVersion>
+        <AllocatedStorage>500</AllocatedStorage>
+        <MaxAllocatedStorage>1000</MaxAllocatedStorage>
+        <StorageType>gp3</StorageType>
+        <Iops>12000</Iops>
+        <MultiAZ>true</MultiAZ>
+        <AvailabilityZone>eu-west-1a</AvailabilityZone>
+        <SecondaryAvailabilityZone>eu-west-1b</SecondaryAvailabilityZone>
+        <StorageEncrypted>true</StorageEncrypted>
+        <PubliclyAccessible>false</PubliclyAccessible>
+        <DeletionProtection>true</DeletionProtection>
+        <BackupRetentionPeriod>7</BackupRetentionPeriod>
+        <AutoMinorVersionUpgrade>true</AutoMinorVersionUpgrade>
+        <TagList>
+          <Tag><Key>team</Key><Value>payments</Value></Tag>
+        </TagList>
+      </DBInstance>
+    </DBInstances>
+  </DescribeDBInstancesResult>
+</DescribeDBInstancesResponse>`
+
+func TestDescribeInstance(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Contains(t, r.Header.Get("Authorization"), "/eu-west-1/rds/aws4_request")
+		assert.NoError(t, r.ParseForm())
+		assert.Equal(t, "DescribeDBInstances", r.PostForm.Get("Action"))
+		assert.Equal(t, "orders-primary", r.PostForm.Get("DBInstanceIdentifier"))
+
+		_, _ = w.Write([]byte(describeOrders))
+	}))
+	defer srv.Close()
+
+	c := &Client{
+		Region:      "eu-west-1",
+		Credentials: &awsauth.Credentials{AccessKeyID: "AKID", SecretAccessKey: "secret"},
+		Endpoint:    srv.URL,
+		Client:      srv.Client(),
+		now:         time.Now,
+	}
+	meta, err := c.DescribeInstance(context.Background(), "orders-primary")
+	require.NoError(t, err)
+	assert.Equal(t, &check.InstanceMetadata{
+		InstanceID:              "orders-primary",
+		InstanceClass:           "db.r6g.xlarge",
+		Tags:                    map[string]string{"team": "payments"},
+		VCPUCores:               4,
+		MemoryGB:                32,
+		StorageType:             "gp3",
+		StorageGB:               500,
+		StorageIOPS:             12000,
+		EngineVersion:           "16.4",
+		EngineVersionMajor:      16,
+		EngineVersionMinor:      4,
+		MultiAZ:                 true,
+		AvailabilityZone:        "eu-west-1a",
+		SecondaryAZ:             "eu-west-1b",
+		StorageAutoscaling:      true,
+		MaxStorageThresholdGB:   1000,
+		StorageEncrypted:        true,
+		DeletionProtection:      true,
+		BackupRetentionDays:     7,
+		AutoMinorVersionUpgrade: true,
+	}, meta)
+}
+
+func TestDescribeInstance_Errors(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Contains(t, r.Header.Get("Authorization"), "/us-east-2/rds/aws4_request", "the ARN's region wins")
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`<ErrorResponse><Error><Type>Sender</Type><Code>DBInstanceNotFound</Code><Message>DBInstance orders not found.</Message></Error></ErrorResponse>`))
+	}))
+	defer srv.Close()
+
+	c := &Client{
+		Region:      "eu-west-1",
+		Credentials: &awsauth.Credentials{AccessKeyID: "AKID", SecretAccessKey: "secret"},
+		Endpoint:    srv.URL,
+		Client:      srv.Client(),
+	}
+	_, err := c.DescribeInstance(context.Background(), "arn:aws:rds:us-east-2:123456789012:db:orders")
+	require.EqualError(t, err, "DescribeDBInstances: DBInstanceNotFound: DBInstance orders not found.")
+
+	_, err = (&dbInstance{Identifier: "shop", Engine: "mysql"}).metadata()
+	require.EqualError(t, err, "RDS instance shop runs mysql, not PostgreSQL")
+}
+
+func TestMetadata_Aurora(t *testing.T) {
+	t.Parallel()
+
+	meta, err := (&dbInstance{
+		Identifier:       "events-1",
+		Class:            "db.r7g.2xlarge",
+		Engine:           "aurora-postgresql",
+		EngineVersion:    "15.4.R2",
+		AllocatedStorage: 1,
+		StorageType:      "aurora-iopt1",
+	}).metadata()
+	require.NoError(t, err)
+	assert.Equal(t, 0, meta.StorageGB, "aurora reports a placeholder size")
+	assert.Equal(t, 15, meta.EngineVersionMajor)
+	assert.Equal(t, 4, meta.EngineVersionMinor)
+	assert.Equal(t, 8, meta.VCPUCores)
+	assert.InDelta(t, 64.0, meta.MemoryGB, 0)
+}
+
+func TestClassSpecs(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		class  string
+		vcpus  int
+		memory float64
+	}{
+		{"db.t4g.micro", 2, 1},
+		{"db.t3.medium", 2, 4},
+		{"db.t4g.large", 2, 8},
+		{"db.m6i.large", 2, 8},
+		{"db.m7g.4xlarge", 16, 64},
+		{"db.r6g.xlarge", 4, 32},
+		{"db.r5b.24xlarge", 96, 768},
+		{"db.x2g.2xlarge", 8, 128},
+		{"db.x2iedn.xlarge", 4, 128},
+		{"db.r6g.metal", 0, 0},
+		{"db.serverless", 0, 0},
+		{"db.r5.large.tpc1.mem2x", 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.class, func(t *testing.T) {
+			t.Parallel()
+
+			vcpus, memory := classSpecs(tt.class)
+			assert.Equal(t, tt.vcpus, vcpus)
+			assert.InDelta(t, tt.memory, memory, 0)
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// Host is the JSON form of one database's results in a fleet run. Error is
+// set when the host could not be checked, or not completely; Checks then
+// holds the reports that finished.
+type Host struct {
+	Label            string   `json:"label"`
+	Connection       string   `json:"connection,omitempty"`
+	Group            string   `json:"group,omitempty"`
+	SystemIdentifier string   `json:"system_identifier,omitempty"`
+	Timeline         int32    `json:"timeline,omitempty"`
+	InRecovery       *bool    `json:"in_recovery,omitempty"`
+	Divergence       []string `json:"divergence,omitempty"`
+	Severity         string   `json:"severity"`
+	Error            string   `json:"error,omitempty"`
+	DurationMs       int64    `json:"duration_ms"`
+	Checks           []Report `json:"checks"`
+}
+
+// WriteFleetJSON writes the results of a fleet run as an indented JSON
+// object with one entry per host.
+func WriteFleetJSON(w io.Writer, hosts []Host) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(struct {
+		Hosts []Host `json:"hosts"`
+	}{hosts}); err != nil {
+		return fmt.Errorf("encoding JSON: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,160 @@
+// Package report renders check reports in the machine-readable formats
+// shared by the CLI and the Lambda handler.
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/fresha/pgdoctor/check"
+	"github.com/fresha/pgdoctor/internal/buildinfo"
+)
+
+// Report is the JSON form of a check report.
+type Report struct {
+	CheckID  string    `json:"check_id"`
+	Name     string    `json:"name"`
+	Category string    `json:"category"`
+	Severity string    `json:"severity"`
+	Results  []Finding `json:"results"`
+	// PgdoctorVersion is the pgdoctor build that produced the report.
+	PgdoctorVersion string `json:"pgdoctor_version"`
+}
+
+// Finding is the JSON form of a finding.
+type Finding struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Severity    string `json:"severity"`
+	Fingerprint string `json:"fingerprint"`
+	Confidence  string `json:"confidence"`
+	Object      string `json:"object,omitempty"`
+	Details     string `json:"details,omitempty"`
+	Impact      string `json:"impact,omitempty"`
+	Table       *Table `json:"table,omitempty"`
+	DocsURL     string `json:"docs_url,omitempty"`
+	Fixes       []Fix  `json:"fixes,omitempty"`
+}
+
+// Fix is the JSON form of a prescribed fix.
+type Fix struct {
+	Object      string `json:"object"`
+	Description string `json:"description"`
+	SQL         string `json:"sql"`
+	Risk        string `json:"risk"`
+	Lock        string `json:"lock"`
+	// MaintenanceWindow is set when the fix blocks reads or writes.
+	MaintenanceWindow bool `json:"maintenance_window"`
+}
+
+// Table is the JSON form of a finding table.
+type Table struct {
+	Headers []string `json:"headers"`
+	Rows    []Row    `json:"rows"`
+}
+
+// Row is the JSON form of a table row.
+type Row struct {
+	Cells    []string `json:"cells"`
+	Severity string   `json:"severity"`
+}
+
+// FromChecks converts check reports into their JSON representation.
+func FromChecks(reports []*check.Report) []Report {
+	output := make([]Report, 0, len(reports))
+	version := buildinfo.Get().String()
+
+	for _, report := range reports {
+		jr := Report{
+			CheckID:  report.CheckID,
+			Name:     report.Name,
+			Category: string(report.Category),
+			Severity: report.Severity.String(),
+			Results:  make([]Finding, 0, len(report.Results)),
+
+			PgdoctorVersion: version,
+		}
+
+		for _, result := range report.Results {
+			jf := Finding{
+				ID:          result.ID,
+				Name:        result.Name,
+				Severity:    result.Severity.String(),
+				Fingerprint: result.Fingerprint,
+				Confidence:  result.Confidence.String(),
+				Object:      result.Object,
+				Details:     result.Details,
+				Impact:      result.Impact,
+				DocsURL:     result.DocsURL,
+			}
+
+			if result.Table != nil {
+				jt := &Table{
+					Headers: result.Table.Headers,
+					Rows:    make([]Row, 0, len(result.Table.Rows)),
+				}
+				for _, row := range result.Table.Rows {
+					jt.Rows = append(jt.Rows, Row{
+						Cells:    row.Cells,
+						Severity: row.Severity.String(),
+					})
+				}
+				jf.Table = jt
+			}
+
+			for _, fix := range result.Fixes {
+				jf.Fixes = append(jf.Fixes, Fix{
+					Object:            fix.Object,
+					Description:       fix.Description,
+					SQL:               fix.SQL,
+					Risk:              fix.Risk.String(),
+					Lock:              fix.Lock.String(),
+					MaintenanceWindow: fix.NeedsMaintenanceWindow(),
+				})
+			}
+
+			jr.Results = append(jr.Results, jf)
+		}
+
+		output = append(output, jr)
+	}
+
+	return output
+}
+
+// WriteJSON writes reports as an indented JSON array.
+func WriteJSON(w io.Writer, reports []*check.Report) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(FromChecks(reports)); err != nil {
+		return fmt.Errorf("encoding JSON: %w", err)
+	}
+
+	return nil
+}
+
+// ReadSeverities reads a JSON report written by WriteJSON and returns the
+// severity of each finding keyed by "check-id/finding-id". When a finding ID
+// appears more than once, the worst severity wins.
+func ReadSeverities(r io.Reader) (map[string]check.Severity, error) {
+	var reports []Report
+	if err := json.NewDecoder(r).Decode(&reports); err != nil {
+		return nil, fmt.Errorf("decoding JSON report: %w", err)
+	}
+
+	severities := map[string]check.Severity{}
+	for _, jr := range reports {
+		for _, jf := range jr.Results {
+			sev, err := check.ParseSeverity(jf.Severity)
+			if err != nil {
+				return nil, fmt.Errorf("%s/%s: %w", jr.CheckID, jf.ID, err)
+			}
+			key := jr.CheckID + "/" + jf.ID
+			if prev, ok := severities[key]; !ok || sev > prev {
+				severities[key] = sev
+			}
+		}
+	}
+	return severities, nil
+}
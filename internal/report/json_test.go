@@ -0,0 +1,123 @@
+package report_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/fresha/pgdoctor/check"
+	"github.com/fresha/pgdoctor/internal/buildinfo"
+	"github.com/fresha/pgdoctor/internal/report"
+)
+
+// TestWriteJSON pins the documented JSON shape: consumers script against
+// these field names, so a change here is a breaking change.
+func TestWriteJSON(t *testing.T) {
+	t.Parallel()
+
+	r := check.NewReport(check.Metadata{
+		CheckID:  "index-usage",
+		Name:     "Index Usage",
+		Category: check.CategoryIndexes,
+	})
+	r.AddFinding(check.Finding{
+		ID:       "unused-indexes",
+		Name:     "Unused Indexes",
+		Severity: check.SeverityWarn,
+		Object:   "public.orders_status_idx",
+		Details:  "1 unused index",
+		Table: &check.Table{
+			Headers: []string{"Index", "Size"},
+			Rows:    []check.TableRow{{Cells: []string{"public.orders_status_idx", "1.2GiB"}, Severity: check.SeverityWarn}},
+		},
+		DocsURL: "https://example.com/index-usage",
+		Fixes: []check.Fix{{
+			Object:      "public.orders_status_idx",
+			Description: "Drop unused index",
+			SQL:         `DROP INDEX CONCURRENTLY "public"."orders_status_idx"`,
+			Lock:        check.LockOnline,
+		}},
+	})
+	r.AddFinding(check.Finding{
+		ID:       "invalid-indexes",
+		Name:     "Invalid Indexes",
+		Severity: check.SeverityOK,
+	})
+
+	var buf bytes.Buffer
+	require.NoError(t, report.WriteJSON(&buf, []*check.Report{r}))
+
+	assert.JSONEq(t, `[
+  {
+    "check_id": "index-usage",
+    "name": "Index Usage",
+    "category": "indexes",
+    "severity": "warn",
+    "results": [
+      {
+        "id": "unused-indexes",
+        "name": "Unused Indexes",
+        "severity": "warn",
+        "fingerprint": "`+check.Fingerprint("index-usage", "unused-indexes", "public.orders_status_idx")+`",
+        "confidence": "high",
+        "object": "public.orders_status_idx",
+        "details": "1 unused index",
+        "table": {"headers": ["Index", "Size"], "rows": [{"cells": ["public.orders_status_idx", "1.2GiB"], "severity": "warn"}]},
+        "docs_url": "https://example.com/index-usage",
+        "fixes": [
+          {"object": "public.orders_status_idx", "description": "Drop unused index", "sql": "DROP INDEX CONCURRENTLY \"public\".\"orders_status_idx\"", "risk": "high", "lock": "online", "maintenance_window": false}
+        ]
+      },
+      {
+        "id": "invalid-indexes",
+        "name": "Invalid Indexes",
+        "severity": "pass",
+        "fingerprint": "`+check.Fingerprint("index-usage", "invalid-indexes", "")+`",
+        "confidence": "high",
+        "docs_url": "https://fresha.github.io/pgdoctor/#index-usage/invalid-indexes"
+      }
+    ],
+    "pgdoctor_version": "`+buildinfo.Get().String()+`"
+  }
+]`, buf.String())
+}
+
+func TestWriteJSON_Empty(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	require.NoError(t, report.WriteJSON(&buf, nil))
+	assert.JSONEq(t, `[]`, buf.String())
+}
+
+func TestReadSeverities(t *testing.T) {
+	t.Parallel()
+
+	warn := check.NewReport(check.Metadata{CheckID: "replication-lag", Category: check.CategoryConfigs})
+	warn.AddFinding(check.Finding{ID: "physical-replication-lag", Severity: check.SeverityWarn, Object: "replica-1"})
+	warn.AddFinding(check.Finding{ID: "physical-replication-lag", Severity: check.SeverityFail, Object: "replica-2"})
+	warn.AddFinding(check.Finding{ID: "wal-retention", Severity: check.SeverityOK})
+
+	var buf bytes.Buffer
+	require.NoError(t, report.WriteJSON(&buf, []*check.Report{warn}))
+
+	got, err := report.ReadSeverities(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]check.Severity{
+		"replication-lag/physical-replication-lag": check.SeverityFail,
+		"replication-lag/wal-retention":            check.SeverityOK,
+	}, got)
+}
+
+func TestReadSeverities_Invalid(t *testing.T) {
+	t.Parallel()
+
+	_, err := report.ReadSeverities(strings.NewReader(`{"not": "a report"}`))
+	require.Error(t, err)
+
+	_, err = report.ReadSeverities(strings.NewReader(`[{"check_id": "x", "results": [{"id": "y", "severity": "bad"}]}]`))
+	require.ErrorContains(t, err, "x/y")
+}
@@ -0,0 +1,80 @@
+package report
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+
+	"github.com/fresha/pgdoctor/check"
+)
+
+// DefaultRiskLimit is how many risks the executive summary of a report lists.
+const DefaultRiskLimit = 10
+
+// Risk is one entry of a report's executive summary: a WARN or FAIL finding
+// and what it means in plain language.
+type Risk struct {
+	CheckID   string
+	FindingID string
+	Name      string
+	Severity  check.Severity
+	Impact    string
+	DocsURL   string
+}
+
+// TopRisks ranks the WARN and FAIL findings of reports and returns at most
+// limit of them: failures before warnings, then findings of critical checks
+// (wraparound, replication, connections) before the rest, then the ones
+// read directly from the catalogs before estimates. Ties keep report order.
+func TopRisks(reports []*check.Report, limit int) []Risk {
+	type ranked struct {
+		Risk
+		priority   check.Priority
+		confidence check.Confidence
+	}
+
+	var all []ranked
+	for _, r := range reports {
+		for _, f := range r.Results {
+			if f.Severity < check.SeverityWarn {
+				continue
+			}
+			all = append(all, ranked{
+				Risk: Risk{
+					CheckID:   r.CheckID,
+					FindingID: f.ID,
+					Name:      f.Name,
+					Severity:  f.Severity,
+					Impact:    impact(f),
+					DocsURL:   f.DocsURL,
+				},
+				priority:   r.Priority,
+				confidence: f.Confidence,
+			})
+		}
+	}
+
+	slices.SortStableFunc(all, func(a, b ranked) int {
+		return cmp.Or(
+			cmp.Compare(b.Severity, a.Severity),
+			cmp.Compare(b.priority, a.priority),
+			cmp.Compare(a.confidence, b.confidence),
+		)
+	})
+
+	risks := make([]Risk, 0, min(limit, len(all)))
+	for _, r := range all[:min(limit, len(all))] {
+		risks = append(risks, r.Risk)
+	}
+	return risks
+}
+
+// impact is the finding's impact statement, or the first line of its
+// details for findings that do not state one.
+func impact(f check.Finding) string {
+	if f.Impact != "" {
+		return f.Impact
+	}
+	line, _, _ := strings.Cut(strings.TrimSpace(f.Details), "\n")
+	return strings.TrimSuffix(strings.TrimSpace(line), ":")
+}
@@ -0,0 +1,58 @@
+package report_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/fresha/pgdoctor/check"
+	"github.com/fresha/pgdoctor/internal/report"
+)
+
+func TestTopRisks(t *testing.T) {
+	t.Parallel()
+
+	newReport := func(id string, priority check.Priority, findings ...check.Finding) *check.Report {
+		r := check.NewReport(check.Metadata{CheckID: id, Name: id, Priority: priority})
+		for _, f := range findings {
+			r.AddFinding(f)
+		}
+		return r
+	}
+
+	reports := []*check.Report{
+		newReport("index-bloat", check.PriorityNormal,
+			check.Finding{ID: "high-bloat", Name: "High Bloat", Severity: check.SeverityFail, Confidence: check.ConfidenceMedium, Details: "Found 2 bloated index(es):\n  a\n  b"},
+			check.Finding{ID: "large-bloat", Name: "Large Bloat", Severity: check.SeverityOK},
+		),
+		newReport("table-vacuum-health", check.PriorityNormal,
+			check.Finding{ID: "vacuum-stale", Name: "Stale Vacuum", Severity: check.SeverityWarn, Details: "Found 1 table"},
+			check.Finding{ID: "autovacuum-disabled", Name: "Autovacuum Disabled", Severity: check.SeverityFail, Details: "Found 1 table"},
+		),
+		newReport("sequence-health", check.PriorityCritical,
+			check.Finding{ID: "near-exhaustion", Name: "Sequence Exhaustion", Severity: check.SeverityFail, Details: "CRITICAL", Impact: "orders.id has used 92% of its sequence's range"},
+		),
+		newReport("pg-version", check.PriorityNormal,
+			check.Finding{ID: "error", Name: "Check Error", Severity: check.SeveritySkip, Details: "permission denied"},
+		),
+	}
+
+	risks := report.TopRisks(reports, report.DefaultRiskLimit)
+	ids := make([]string, 0, len(risks))
+	for _, r := range risks {
+		ids = append(ids, r.CheckID+"/"+r.FindingID)
+	}
+	assert.Equal(t, []string{
+		"sequence-health/near-exhaustion",
+		"table-vacuum-health/autovacuum-disabled",
+		"index-bloat/high-bloat",
+		"table-vacuum-health/vacuum-stale",
+	}, ids, "failures first, critical checks first, estimates after catalog reads")
+
+	assert.Equal(t, "orders.id has used 92% of its sequence's range", risks[0].Impact)
+	assert.Equal(t, "Found 2 bloated index(es)", risks[2].Impact, "falls back to the first line of details")
+	assert.Equal(t, check.DocsURL("sequence-health", "near-exhaustion"), risks[0].DocsURL)
+
+	assert.Len(t, report.TopRisks(reports, 2), 2)
+	assert.Empty(t, report.TopRisks(reports[3:], report.DefaultRiskLimit))
+}
@@ -0,0 +1,215 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/fresha/pgdoctor/check"
+	"github.com/fresha/pgdoctor/internal/buildinfo"
+)
+
+const (
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifVersion = "2.1.0"
+	// sarifFingerprintKey names pgdoctor's finding fingerprint among a
+	// result's partial fingerprints, so code scanning tracks an alert
+	// across runs.
+	sarifFingerprintKey = "pgdoctorFingerprint/v1"
+)
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool        sarifTool         `json:"tool"`
+	Invocations []sarifInvocation `json:"invocations"`
+	Results     []sarifResult     `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	Version        string      `json:"version"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID                   string             `json:"id"`
+	Name                 string             `json:"name"`
+	ShortDescription     sarifMessage       `json:"shortDescription"`
+	FullDescription      *sarifMessage      `json:"fullDescription,omitempty"`
+	HelpURI              string             `json:"helpUri,omitempty"`
+	DefaultConfiguration sarifConfiguration `json:"defaultConfiguration"`
+	Properties           sarifRuleProps     `json:"properties"`
+}
+
+type sarifConfiguration struct {
+	Level string `json:"level"`
+}
+
+type sarifRuleProps struct {
+	Tags []string `json:"tags"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifInvocation struct {
+	ExecutionSuccessful        bool                `json:"executionSuccessful"`
+	ToolExecutionNotifications []sarifNotification `json:"toolExecutionNotifications,omitempty"`
+}
+
+type sarifNotification struct {
+	Level   string       `json:"level"`
+	Message sarifMessage `json:"message"`
+}
+
+type sarifResult struct {
+	RuleID              string            `json:"ruleId"`
+	RuleIndex           int               `json:"ruleIndex"`
+	Level               string            `json:"level"`
+	Message             sarifMessage      `json:"message"`
+	Locations           []sarifLocation   `json:"locations"`
+	PartialFingerprints map[string]string `json:"partialFingerprints"`
+	Properties          sarifResultProps  `json:"properties"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation  `json:"physicalLocation"`
+	LogicalLocations []sarifLogicalLocation `json:"logicalLocations,omitempty"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifLogicalLocation struct {
+	Name               string `json:"name"`
+	FullyQualifiedName string `json:"fullyQualifiedName"`
+}
+
+type sarifResultProps struct {
+	Confidence string `json:"confidence"`
+	Fixes      []Fix  `json:"fixes,omitempty"`
+}
+
+// sarifRuleID identifies a finding type: "check-id/finding-id", or the
+// check ID alone for a check's single finding.
+func sarifRuleID(checkID, findingID string) string {
+	if findingID == "" || findingID == checkID {
+		return checkID
+	}
+	return checkID + "/" + findingID
+}
+
+// sarifLevel maps a severity onto a SARIF level: fail is an error and warn
+// a warning.
+func sarifLevel(s check.Severity) string {
+	if s == check.SeverityFail {
+		return "error"
+	}
+	return "warning"
+}
+
+// WriteSARIF writes reports as a SARIF 2.1.0 log for GitHub code scanning
+// and other SARIF consumers. Every finding type seen in the reports becomes
+// a rule; WARN and FAIL findings become results located at the database,
+// which stands in for a source file, and at the finding's object. Passing
+// findings are left out so fixed problems close on the next upload, and
+// skipped checks are reported as notifications.
+func WriteSARIF(w io.Writer, database string, reports []*check.Report) error {
+	run := sarifRun{
+		Tool: sarifTool{Driver: sarifDriver{
+			Name:           "pgdoctor",
+			Version:        buildinfo.Get().Version,
+			InformationURI: "https://github.com/fresha/pgdoctor",
+			Rules:          []sarifRule{},
+		}},
+		Invocations: []sarifInvocation{{ExecutionSuccessful: true}},
+		Results:     []sarifResult{},
+	}
+
+	ruleIndex := map[string]int{}
+	for _, r := range reports {
+		for _, f := range r.Results {
+			id := sarifRuleID(r.CheckID, f.ID)
+			index, ok := ruleIndex[id]
+			if !ok {
+				index = len(run.Tool.Driver.Rules)
+				ruleIndex[id] = index
+				rule := sarifRule{
+					ID:                   id,
+					Name:                 f.Name,
+					ShortDescription:     sarifMessage{Text: f.Name},
+					HelpURI:              f.DocsURL,
+					DefaultConfiguration: sarifConfiguration{Level: "warning"},
+					Properties:           sarifRuleProps{Tags: []string{"postgresql", string(r.Category)}},
+				}
+				if r.Description != "" {
+					rule.FullDescription = &sarifMessage{Text: r.Description}
+				}
+				run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, rule)
+			}
+
+			if f.Severity == check.SeveritySkip {
+				run.Invocations[0].ToolExecutionNotifications = append(run.Invocations[0].ToolExecutionNotifications, sarifNotification{
+					Level:   "warning",
+					Message: sarifMessage{Text: fmt.Sprintf("%s skipped: %s", id, f.Details)},
+				})
+				continue
+			}
+			if f.Severity <= check.SeverityOK {
+				continue
+			}
+
+			message := f.Details
+			if message == "" {
+				message = f.Name
+			}
+			location := sarifLocation{PhysicalLocation: sarifPhysicalLocation{ArtifactLocation: sarifArtifactLocation{URI: database}}}
+			if f.Object != "" {
+				location.LogicalLocations = []sarifLogicalLocation{{Name: f.Object, FullyQualifiedName: database + "/" + f.Object}}
+			}
+			result := sarifResult{
+				RuleID:              id,
+				RuleIndex:           index,
+				Level:               sarifLevel(f.Severity),
+				Message:             sarifMessage{Text: message},
+				Locations:           []sarifLocation{location},
+				PartialFingerprints: map[string]string{sarifFingerprintKey: f.Fingerprint},
+				Properties:          sarifResultProps{Confidence: f.Confidence.String()},
+			}
+			for _, fix := range f.Fixes {
+				result.Properties.Fixes = append(result.Properties.Fixes, Fix{
+					Object:            fix.Object,
+					Description:       fix.Description,
+					SQL:               fix.SQL,
+					Risk:              fix.Risk.String(),
+					Lock:              fix.Lock.String(),
+					MaintenanceWindow: fix.NeedsMaintenanceWindow(),
+				})
+			}
+			run.Results = append(run.Results, result)
+		}
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(sarifLog{Schema: sarifSchema, Version: sarifVersion, Runs: []sarifRun{run}}); err != nil {
+		return fmt.Errorf("encoding SARIF: %w", err)
+	}
+	return nil
+}
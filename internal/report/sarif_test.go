@@ -0,0 +1,120 @@
+package report_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/fresha/pgdoctor/check"
+	"github.com/fresha/pgdoctor/internal/report"
+)
+
+func TestWriteSARIF(t *testing.T) {
+	t.Parallel()
+
+	usage := check.NewReport(check.Metadata{
+		CheckID:     "index-usage",
+		Name:        "Index Usage",
+		Category:    check.CategoryIndexes,
+		Description: "Finds unused and redundant indexes",
+	})
+	usage.AddFinding(check.Finding{
+		ID:       "unused-indexes",
+		Name:     "Unused Indexes",
+		Severity: check.SeverityFail,
+		Object:   "public.orders_status_idx",
+		Details:  "1 unused index",
+		Fixes: []check.Fix{{
+			Object: "public.orders_status_idx",
+			SQL:    `DROP INDEX CONCURRENTLY "public"."orders_status_idx"`,
+			Lock:   check.LockOnline,
+		}},
+	})
+	usage.AddFinding(check.Finding{ID: "invalid-indexes", Name: "Invalid Indexes", Severity: check.SeverityOK})
+
+	version := check.NewReport(check.Metadata{CheckID: "pg-version", Name: "PostgreSQL Version", Category: check.CategoryConfigs})
+	version.AddFinding(check.Finding{ID: "pg-version", Name: "PostgreSQL Version", Severity: check.SeverityWarn, Details: "Minor version is behind"})
+
+	slots := check.NewReport(check.Metadata{CheckID: "replication-slots", Name: "Replication Slots", Category: check.CategoryConfigs})
+	slots.AddFinding(check.Finding{ID: "replication-slots", Name: "Replication Slots", Severity: check.SeveritySkip, Details: "permission denied"})
+
+	var buf bytes.Buffer
+	require.NoError(t, report.WriteSARIF(&buf, "db.example.com/orders", []*check.Report{usage, version, slots}))
+
+	var log struct {
+		Version string `json:"version"`
+		Runs    []struct {
+			Tool struct {
+				Driver struct {
+					Name  string `json:"name"`
+					Rules []struct {
+						ID      string `json:"id"`
+						HelpURI string `json:"helpUri"`
+					} `json:"rules"`
+				} `json:"driver"`
+			} `json:"tool"`
+			Invocations []struct {
+				Notifications []struct {
+					Message struct{ Text string } `json:"message"`
+				} `json:"toolExecutionNotifications"`
+			} `json:"invocations"`
+			Results []struct {
+				RuleID    string                `json:"ruleId"`
+				RuleIndex int                   `json:"ruleIndex"`
+				Level     string                `json:"level"`
+				Message   struct{ Text string } `json:"message"`
+				Locations []struct {
+					PhysicalLocation struct {
+						ArtifactLocation struct{ URI string } `json:"artifactLocation"`
+					} `json:"physicalLocation"`
+					LogicalLocations []struct {
+						FullyQualifiedName string `json:"fullyQualifiedName"`
+					} `json:"logicalLocations"`
+				} `json:"locations"`
+				PartialFingerprints map[string]string `json:"partialFingerprints"`
+				Properties          struct {
+					Fixes []report.Fix `json:"fixes"`
+				} `json:"properties"`
+			} `json:"results"`
+		} `json:"runs"`
+	}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &log))
+
+	assert.Equal(t, "2.1.0", log.Version)
+	require.Len(t, log.Runs, 1)
+	run := log.Runs[0]
+	assert.Equal(t, "pgdoctor", run.Tool.Driver.Name)
+
+	var ruleIDs []string
+	for _, r := range run.Tool.Driver.Rules {
+		ruleIDs = append(ruleIDs, r.ID)
+	}
+	assert.Equal(t, []string{"index-usage/unused-indexes", "index-usage/invalid-indexes", "pg-version", "replication-slots"}, ruleIDs)
+	assert.Equal(t, check.DocsURL("index-usage", "unused-indexes"), run.Tool.Driver.Rules[0].HelpURI)
+
+	// Passing and skipped findings produce no results.
+	require.Len(t, run.Results, 2)
+	unused := run.Results[0]
+	assert.Equal(t, "index-usage/unused-indexes", unused.RuleID)
+	assert.Equal(t, 0, unused.RuleIndex)
+	assert.Equal(t, "error", unused.Level)
+	assert.Equal(t, "1 unused index", unused.Message.Text)
+	assert.Equal(t, "db.example.com/orders", unused.Locations[0].PhysicalLocation.ArtifactLocation.URI)
+	assert.Equal(t, "db.example.com/orders/public.orders_status_idx", unused.Locations[0].LogicalLocations[0].FullyQualifiedName)
+	assert.Equal(t, usage.Results[0].Fingerprint, unused.PartialFingerprints["pgdoctorFingerprint/v1"])
+	require.Len(t, unused.Properties.Fixes, 1)
+	assert.Equal(t, "online", unused.Properties.Fixes[0].Lock)
+
+	minor := run.Results[1]
+	assert.Equal(t, "pg-version", minor.RuleID)
+	assert.Equal(t, 2, minor.RuleIndex)
+	assert.Equal(t, "warning", minor.Level)
+	assert.Empty(t, minor.Locations[0].LogicalLocations)
+
+	require.Len(t, run.Invocations, 1)
+	require.Len(t, run.Invocations[0].Notifications, 1)
+	assert.Equal(t, "replication-slots skipped: permission denied", run.Invocations[0].Notifications[0].Message.Text)
+}
@@ -0,0 +1,188 @@
+// Package sshtunnel forwards database connections through an SSH bastion host.
+//
+// Many production databases are only reachable through a jump host. Instead of
+// requiring operators to script `ssh -L` themselves, pgdoctor opens the SSH
+// session once and dials PostgreSQL through it for every connection attempt.
+package sshtunnel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+const defaultSSHPort = "22"
+
+// handshakeTimeout bounds the SSH handshake when ctx has no deadline, so a
+// bastion that accepts the TCP connection but never answers cannot hang a run.
+const handshakeTimeout = 30 * time.Second
+
+// Options configures the SSH connection to the bastion host.
+type Options struct {
+	// Target is the bastion in user@host[:port] form.
+	Target string
+	// KeyFile is a private key used for authentication. When empty, the SSH
+	// agent at $SSH_AUTH_SOCK is used instead.
+	KeyFile string
+	// KnownHostsFile verifies the bastion's host key. Defaults to ~/.ssh/known_hosts.
+	KnownHostsFile string
+}
+
+// Tunnel is an open SSH session to a bastion host.
+type Tunnel struct {
+	client *ssh.Client
+	// agent is the connection to ssh-agent, or nil when a key file is used.
+	agent io.Closer
+}
+
+// Open connects and authenticates to the bastion host.
+func Open(ctx context.Context, opts Options) (*Tunnel, error) {
+	user, addr, err := parseTarget(opts.Target)
+	if err != nil {
+		return nil, err
+	}
+
+	auth, agentConn, err := authMethod(opts.KeyFile)
+	if err != nil {
+		return nil, err
+	}
+	closeAgent := func() {
+		if agentConn != nil {
+			_ = agentConn.Close()
+		}
+	}
+
+	hostKeyCallback, err := hostKeyCallback(opts.KnownHostsFile)
+	if err != nil {
+		closeAgent()
+		return nil, err
+	}
+
+	config := &ssh.ClientConfig{
+		User:            user,
+		Auth:            []ssh.AuthMethod{auth},
+		HostKeyCallback: hostKeyCallback,
+	}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		closeAgent()
+		return nil, fmt.Errorf("connecting to bastion %s: %w", addr, err)
+	}
+
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(handshakeTimeout)
+	}
+	if err := conn.SetDeadline(deadline); err != nil {
+		_ = conn.Close()
+		closeAgent()
+		return nil, fmt.Errorf("ssh handshake with %s: %w", addr, err)
+	}
+	sshConn, chans, reqs, err := ssh.NewClientConn(conn, addr, config)
+	if err != nil {
+		_ = conn.Close()
+		closeAgent()
+		return nil, fmt.Errorf("ssh handshake with %s: %w", addr, err)
+	}
+	// The session outlives ctx, so forwarded connections must not inherit
+	// the handshake deadline.
+	if err := conn.SetDeadline(time.Time{}); err != nil {
+		_ = sshConn.Close()
+		closeAgent()
+		return nil, fmt.Errorf("ssh handshake with %s: %w", addr, err)
+	}
+
+	return &Tunnel{client: ssh.NewClient(sshConn, chans, reqs), agent: agentConn}, nil
+}
+
+// DialContext opens a forwarded connection from the bastion to addr.
+// Its signature matches pgconn.DialFunc so it can be set on a pgx config.
+func (t *Tunnel) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	conn, err := t.client.DialContext(ctx, network, addr)
+	if err != nil {
+		return nil, fmt.Errorf("forwarding to %s via bastion: %w", addr, err)
+	}
+	return conn, nil
+}
+
+// Close terminates the SSH session and all forwarded connections, and
+// disconnects from ssh-agent.
+func (t *Tunnel) Close() error {
+	err := t.client.Close()
+	if t.agent != nil {
+		err = errors.Join(err, t.agent.Close())
+	}
+	return err
+}
+
+// parseTarget splits user@host[:port] into the user and a dialable address.
+func parseTarget(target string) (user, addr string, err error) {
+	user, host, ok := strings.Cut(target, "@")
+	if !ok || user == "" || host == "" {
+		return "", "", fmt.Errorf("invalid ssh target %q: expected user@host[:port]", target)
+	}
+
+	if _, _, splitErr := net.SplitHostPort(host); splitErr != nil {
+		host = net.JoinHostPort(strings.Trim(host, "[]"), defaultSSHPort)
+	}
+	return user, host, nil
+}
+
+// authMethod authenticates with keyFile, or with ssh-agent when it is empty.
+// The connection to the agent is returned for the caller to close with the
+// session; it is nil for a key file.
+func authMethod(keyFile string) (ssh.AuthMethod, io.Closer, error) {
+	if keyFile != "" {
+		key, err := os.ReadFile(keyFile)
+		if err != nil {
+			return nil, nil, fmt.Errorf("reading ssh key: %w", err)
+		}
+		signer, err := ssh.ParsePrivateKey(key)
+		if err != nil {
+			var missing *ssh.PassphraseMissingError
+			if errors.As(err, &missing) {
+				return nil, nil, fmt.Errorf("ssh key %s is passphrase-protected: load it into ssh-agent and omit --ssh-key", keyFile)
+			}
+			return nil, nil, fmt.Errorf("parsing ssh key: %w", err)
+		}
+		return ssh.PublicKeys(signer), nil, nil
+	}
+
+	sock := os.Getenv("SSH_AUTH_SOCK")
+	if sock == "" {
+		return nil, nil, errors.New("no ssh credentials: pass --ssh-key or start ssh-agent")
+	}
+	conn, err := net.Dial("unix", sock)
+	if err != nil {
+		return nil, nil, fmt.Errorf("connecting to ssh-agent: %w", err)
+	}
+	return ssh.PublicKeysCallback(agent.NewClient(conn).Signers), conn, nil
+}
+
+func hostKeyCallback(knownHostsFile string) (ssh.HostKeyCallback, error) {
+	if knownHostsFile == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, fmt.Errorf("locating known_hosts: %w", err)
+		}
+		knownHostsFile = filepath.Join(home, ".ssh", "known_hosts")
+	}
+
+	callback, err := knownhosts.New(knownHostsFile)
+	if err != nil {
+		return nil, fmt.Errorf("loading known_hosts: %w", err)
+	}
+	return callback, nil
+}
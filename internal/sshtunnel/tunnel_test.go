@@ -0,0 +1,117 @@
+package sshtunnel
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+)
+
+func TestParseTarget(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		target   string
+		wantUser string
+		wantAddr string
+		wantErr  bool
+	}{
+		{name: "default port", target: "ops@bastion.example.com", wantUser: "ops", wantAddr: "bastion.example.com:22"},
+		{name: "explicit port", target: "ops@bastion.example.com:2222", wantUser: "ops", wantAddr: "bastion.example.com:2222"},
+		{name: "ipv6 without port", target: "ops@[::1]", wantUser: "ops", wantAddr: "[::1]:22"},
+		{name: "missing user", target: "bastion.example.com", wantErr: true},
+		{name: "empty host", target: "ops@", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			user, addr, err := parseTarget(tt.target)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantUser, user)
+			assert.Equal(t, tt.wantAddr, addr)
+		})
+	}
+}
+
+// silentBastion accepts TCP connections and never speaks SSH. It returns
+// the address to dial.
+func silentBastion(t *testing.T) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			t.Cleanup(func() { _ = conn.Close() })
+		}
+	}()
+	return ln.Addr().String()
+}
+
+func TestOpen_HandshakeDeadline(t *testing.T) {
+	t.Parallel()
+
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	block, err := ssh.MarshalPrivateKey(key, "")
+	require.NoError(t, err)
+	dir := t.TempDir()
+	keyFile := filepath.Join(dir, "id_ed25519")
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(block), 0o600))
+	knownHosts := filepath.Join(dir, "known_hosts")
+	require.NoError(t, os.WriteFile(knownHosts, nil, 0o600))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err = Open(ctx, Options{Target: "ops@" + silentBastion(t), KeyFile: keyFile, KnownHostsFile: knownHosts})
+	require.ErrorContains(t, err, "ssh handshake with")
+	assert.Less(t, time.Since(start), 5*time.Second, "the handshake gives up at the context deadline")
+}
+
+func TestOpen_ClosesAgentOnFailure(t *testing.T) {
+	sock := filepath.Join(t.TempDir(), "agent.sock")
+	ln, err := net.Listen("unix", sock)
+	require.NoError(t, err)
+	defer ln.Close()
+	t.Setenv("SSH_AUTH_SOCK", sock)
+
+	accepted := make(chan net.Conn, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err == nil {
+			accepted <- conn
+		}
+	}()
+
+	_, err = Open(context.Background(), Options{Target: "ops@127.0.0.1:22", KnownHostsFile: filepath.Join(t.TempDir(), "missing")})
+	require.ErrorContains(t, err, "loading known_hosts")
+
+	conn := <-accepted
+	defer conn.Close()
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	_, err = conn.Read(make([]byte, 1))
+	assert.ErrorIs(t, err, io.EOF, "the agent connection is closed")
+}
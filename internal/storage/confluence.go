@@ -0,0 +1,112 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Confluence replaces the body of an existing Confluence page with a report
+// in storage format, keeping the page's title. Each run adds a page
+// version, so earlier reports stay in the page history.
+type Confluence struct {
+	// BaseURL is the Confluence root, e.g. https://example.atlassian.net/wiki.
+	BaseURL string
+	// Email and APIToken authenticate to Confluence Cloud with basic auth.
+	// Without Email, APIToken is sent as a bearer personal access token
+	// (Confluence Data Center).
+	Email    string
+	APIToken string
+	Client   *http.Client
+}
+
+type confluencePage struct {
+	ID      string `json:"id"`
+	Type    string `json:"type"`
+	Title   string `json:"title"`
+	Version struct {
+		Number  int    `json:"number"`
+		Message string `json:"message,omitempty"`
+	} `json:"version"`
+	Body *confluenceBody `json:"body,omitempty"`
+}
+
+type confluenceBody struct {
+	Storage struct {
+		Value          string `json:"value"`
+		Representation string `json:"representation"`
+	} `json:"storage"`
+}
+
+func (c *Confluence) Put(ctx context.Context, pageID string, body []byte, _ string) error {
+	pagePath := "/rest/api/content/" + url.PathEscape(pageID)
+
+	var page confluencePage
+	if err := c.do(ctx, http.MethodGet, pagePath+"?expand=version", nil, &page); err != nil {
+		return fmt.Errorf("reading confluence page %s: %w", pageID, err)
+	}
+
+	update := confluencePage{ID: pageID, Type: "page", Title: page.Title}
+	update.Version.Number = page.Version.Number + 1
+	update.Version.Message = "pgdoctor report"
+	update.Body = &confluenceBody{}
+	update.Body.Storage.Value = string(body)
+	update.Body.Storage.Representation = "storage"
+
+	if err := c.do(ctx, http.MethodPut, pagePath, update, nil); err != nil {
+		return fmt.Errorf("updating confluence page %s: %w", pageID, err)
+	}
+	return nil
+}
+
+func (c *Confluence) do(ctx context.Context, method, path string, in, out any) error {
+	var reqBody io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return fmt.Errorf("encoding confluence request: %w", err)
+		}
+		reqBody = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimRight(c.BaseURL, "/")+path, reqBody)
+	if err != nil {
+		return err
+	}
+	if c.Email != "" {
+		req.SetBasicAuth(c.Email, c.APIToken)
+	} else {
+		req.Header.Set("Authorization", "Bearer "+c.APIToken)
+	}
+	req.Header.Set("Accept", "application/json")
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	client := c.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("%s %s: %s: %s", req.Method, req.URL.Path, resp.Status, strings.TrimSpace(string(msg)))
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("decoding confluence response: %w", err)
+	}
+	return nil
+}
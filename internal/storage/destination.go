@@ -0,0 +1,148 @@
+package storage
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/fresha/pgdoctor/internal/awsauth"
+)
+
+// Destination is a report destination with its format taken from the
+// extension: .json, .md, .xhtml (Confluence storage format), .html, or
+// .sarif, optionally followed by .gz for compression. A Confluence page always gets the
+// storage format.
+type Destination struct {
+	Location Location
+	Format   string // "json", "markdown", "confluence", "html", or "sarif"
+	Gzip     bool
+}
+
+// ParseDestination parses a destination URL or path.
+func ParseDestination(dest string) (*Destination, error) {
+	loc, err := ParseLocation(dest)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &Destination{Location: loc}
+	if loc.Scheme == "confluence" {
+		d.Format = "confluence"
+		return d, nil
+	}
+
+	name := loc.Key
+	if trimmed, ok := strings.CutSuffix(name, ".gz"); ok {
+		d.Gzip = true
+		name = trimmed
+	}
+	switch path.Ext(name) {
+	case ".json":
+		d.Format = "json"
+	case ".md":
+		d.Format = "markdown"
+	case ".xhtml":
+		d.Format = "confluence"
+	case ".html":
+		d.Format = "html"
+	case ".sarif":
+		d.Format = "sarif"
+	default:
+		return nil, fmt.Errorf("destination %q must end in .json, .md, .xhtml, .html, or .sarif (optionally .gz)", dest)
+	}
+	return d, nil
+}
+
+// Expand fills in the path template placeholders: {timestamp} (UTC,
+// 20060102T150405Z), {date} (2006-01-02), {host}, and {database}.
+func (d *Destination) Expand(now time.Time, host, database string) {
+	now = now.UTC()
+	r := strings.NewReplacer(
+		"{timestamp}", now.Format("20060102T150405Z"),
+		"{date}", now.Format("2006-01-02"),
+		"{host}", pathSafe(host),
+		"{database}", pathSafe(database),
+	)
+	d.Location.Key = r.Replace(d.Location.Key)
+}
+
+// pathSafe keeps a template value inside a single path segment, e.g. a Unix
+// socket directory used as the host.
+func pathSafe(s string) string {
+	s = strings.Trim(s, "/")
+	return strings.NewReplacer("/", "_", ":", "_", " ", "_").Replace(s)
+}
+
+// Write renders the report and stores it, compressing it first when the
+// destination ends in .gz.
+func (d *Destination) Write(ctx context.Context, render func(io.Writer) error) error {
+	store, err := NewStore(d.Location)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	contentType := "application/json"
+	switch d.Format {
+	case "markdown":
+		contentType = "text/markdown; charset=utf-8"
+	case "confluence":
+		contentType = "application/xhtml+xml"
+	case "html":
+		contentType = "text/html; charset=utf-8"
+	case "sarif":
+		contentType = "application/sarif+json"
+	}
+
+	if d.Gzip {
+		zw := gzip.NewWriter(&buf)
+		if err := render(zw); err != nil {
+			return err
+		}
+		if err := zw.Close(); err != nil {
+			return fmt.Errorf("compressing report: %w", err)
+		}
+		contentType = "application/gzip"
+	} else if err := render(&buf); err != nil {
+		return err
+	}
+
+	if err := store.Put(ctx, d.Location.Key, buf.Bytes(), contentType); err != nil {
+		return fmt.Errorf("writing report to %s: %w", d.Location, err)
+	}
+	return nil
+}
+
+// NewStore builds the backend for a location, reading credentials from the
+// environment the same way the cloud SDKs do.
+func NewStore(loc Location) (Store, error) {
+	switch loc.Scheme {
+	case "s3":
+		region := awsauth.Region()
+		creds, err := awsauth.FromEnv()
+		if region == "" || err != nil {
+			return nil, fmt.Errorf("s3:// output requires AWS_REGION, AWS_ACCESS_KEY_ID, and AWS_SECRET_ACCESS_KEY")
+		}
+		endpoint := os.Getenv("AWS_ENDPOINT_URL_S3")
+		if endpoint == "" {
+			endpoint = os.Getenv("AWS_ENDPOINT_URL")
+		}
+		return &S3{Bucket: loc.Bucket, Region: region, Credentials: creds, Endpoint: endpoint}, nil
+	case "gs":
+		return &GCS{Bucket: loc.Bucket, AccessToken: os.Getenv("GOOGLE_OAUTH_ACCESS_TOKEN")}, nil
+	case "confluence":
+		baseURL, token := os.Getenv("CONFLUENCE_BASE_URL"), os.Getenv("CONFLUENCE_API_TOKEN")
+		if baseURL == "" || token == "" {
+			return nil, fmt.Errorf("confluence:// output requires CONFLUENCE_BASE_URL and CONFLUENCE_API_TOKEN (and CONFLUENCE_EMAIL for Confluence Cloud)")
+		}
+		return &Confluence{BaseURL: baseURL, Email: os.Getenv("CONFLUENCE_EMAIL"), APIToken: token}, nil
+	default:
+		return Local{}, nil
+	}
+}
@@ -0,0 +1,93 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const (
+	gcsEndpoint      = "https://storage.googleapis.com"
+	gcsMetadataToken = "http://metadata.google.internal/computeMetadata/v1/instance/service-accounts/default/token"
+)
+
+// GCS uploads reports to Google Cloud Storage with a simple media upload.
+type GCS struct {
+	Bucket string
+	// AccessToken is an OAuth2 access token. When empty, a token for the
+	// default service account is fetched from the GCE metadata server
+	// (Cloud Run, Cloud Functions, GKE, Compute Engine).
+	AccessToken string
+	Endpoint    string // Defaults to https://storage.googleapis.com
+	MetadataURL string // Defaults to the GCE metadata token endpoint
+	Client      *http.Client
+}
+
+func (g *GCS) Put(ctx context.Context, key string, body []byte, contentType string) error {
+	token := g.AccessToken
+	if token == "" {
+		var err error
+		if token, err = g.metadataToken(ctx); err != nil {
+			return fmt.Errorf("getting GCS access token: %w", err)
+		}
+	}
+
+	endpoint := g.Endpoint
+	if endpoint == "" {
+		endpoint = gcsEndpoint
+	}
+	q := url.Values{}
+	q.Set("uploadType", "media")
+	q.Set("name", key)
+	uploadURL := fmt.Sprintf("%s/upload/storage/v1/b/%s/o?%s", strings.TrimRight(endpoint, "/"), url.PathEscape(g.Bucket), q.Encode())
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, uploadURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", contentType)
+
+	return do(g.Client, req)
+}
+
+func (g *GCS) metadataToken(ctx context.Context) (string, error) {
+	metadataURL := g.MetadataURL
+	if metadataURL == "" {
+		metadataURL = gcsMetadataToken
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, metadataURL, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Metadata-Flavor", "Google")
+
+	client := g.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return "", fmt.Errorf("metadata server: %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+
+	var token struct {
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return "", fmt.Errorf("decoding metadata token: %w", err)
+	}
+	return token.AccessToken, nil
+}
@@ -0,0 +1,64 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/fresha/pgdoctor/internal/awsauth"
+)
+
+// S3 uploads reports to Amazon S3 (or an S3-compatible store) with a
+// SigV4-signed PutObject request.
+type S3 struct {
+	Bucket      string
+	Region      string
+	Credentials awsauth.Credentials
+	// Endpoint overrides the AWS endpoint for S3-compatible stores. Requests
+	// then use path-style addressing: <Endpoint>/<bucket>/<key>.
+	Endpoint string
+	Client   *http.Client
+
+	now func() time.Time // For tests
+}
+
+func (s *S3) Put(ctx context.Context, key string, body []byte, contentType string) error {
+	endpoint := fmt.Sprintf("https://%s.s3.%s.amazonaws.com/%s", s.Bucket, s.Region, escapePath(key))
+	if s.Endpoint != "" {
+		endpoint = fmt.Sprintf("%s/%s/%s", strings.TrimRight(s.Endpoint, "/"), s.Bucket, escapePath(key))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+
+	now := time.Now
+	if s.now != nil {
+		now = s.now
+	}
+	awsauth.Sign(req, body, s.Credentials, "s3", s.Region, now())
+
+	return do(s.Client, req)
+}
+
+// escapePath percent-encodes an object key the way SigV4 canonicalizes it:
+// everything except unreserved characters and the slashes between segments.
+func escapePath(key string) string {
+	var b strings.Builder
+	for i := 0; i < len(key); i++ {
+		c := key[i]
+		switch {
+		case c >= 'A' && c <= 'Z', c >= 'a' && c <= 'z', c >= '0' && c <= '9',
+			c == '-', c == '.', c == '_', c == '~', c == '/':
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,90 @@
+// Package storage writes rendered reports to a local file or an object store,
+// so scheduled runs in ephemeral environments can keep their results.
+//
+// Backends talk to the object stores' HTTP APIs directly and read their
+// credentials from the environment the run executes in.
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Store is a destination reports can be written to.
+type Store interface {
+	// Put writes body to key, replacing any existing object.
+	Put(ctx context.Context, key string, body []byte, contentType string) error
+}
+
+// Location is a parsed destination URL.
+type Location struct {
+	Scheme string // "s3", "gs", "confluence", or "file"
+	Bucket string // Empty for "file" and "confluence"
+	Key    string // Object key, page ID for "confluence", or file path for "file"
+}
+
+// ParseLocation splits a destination into backend, bucket, and key.
+// s3://bucket/key and gs://bucket/key select the object stores,
+// confluence://page-id a Confluence page; file:///path and plain paths select
+// the local filesystem.
+func ParseLocation(dest string) (Location, error) {
+	scheme, rest, ok := strings.Cut(dest, "://")
+	if !ok {
+		return Location{Scheme: "file", Key: dest}, nil
+	}
+
+	switch scheme {
+	case "file":
+		u, err := url.Parse(dest)
+		if err != nil {
+			return Location{}, fmt.Errorf("parsing %q: %w", dest, err)
+		}
+		return Location{Scheme: "file", Key: u.Path}, nil
+	case "s3", "gs":
+		bucket, key, _ := strings.Cut(rest, "/")
+		if bucket == "" || key == "" {
+			return Location{}, fmt.Errorf("%q must be %s://bucket/key", dest, scheme)
+		}
+		return Location{Scheme: scheme, Bucket: bucket, Key: key}, nil
+	case "confluence":
+		if rest == "" || strings.Contains(rest, "/") {
+			return Location{}, fmt.Errorf("%q must be confluence://page-id", dest)
+		}
+		return Location{Scheme: scheme, Key: rest}, nil
+	default:
+		return Location{}, fmt.Errorf("unsupported destination %q (expected s3://, gs://, confluence://, file:// or a path)", dest)
+	}
+}
+
+func (l Location) String() string {
+	switch l.Scheme {
+	case "file":
+		return l.Key
+	case "confluence":
+		return "confluence://" + l.Key
+	}
+	return l.Scheme + "://" + l.Bucket + "/" + l.Key
+}
+
+// do sends an upload request and turns non-2xx responses into errors.
+func do(client *http.Client, req *http.Request) error {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("%s %s: %s: %s", req.Method, req.URL.Path, resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
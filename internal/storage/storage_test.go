@@ -0,0 +1,229 @@
+package storage
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/fresha/pgdoctor/internal/awsauth"
+)
+
+func TestParseLocation(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		dest     string
+		expected Location
+		wantErr  bool
+	}{
+		{dest: "reports/run.json", expected: Location{Scheme: "file", Key: "reports/run.json"}},
+		{dest: "file:///var/lib/pgdoctor/run.json.gz", expected: Location{Scheme: "file", Key: "/var/lib/pgdoctor/run.json.gz"}},
+		{dest: "s3://ops-reports/pgdoctor/run.json.gz", expected: Location{Scheme: "s3", Bucket: "ops-reports", Key: "pgdoctor/run.json.gz"}},
+		{dest: "gs://ops-reports/run.md", expected: Location{Scheme: "gs", Bucket: "ops-reports", Key: "run.md"}},
+		{dest: "confluence://123456", expected: Location{Scheme: "confluence", Key: "123456"}},
+		{dest: "confluence://", wantErr: true},
+		{dest: "s3://ops-reports", wantErr: true},
+		{dest: "s3:///run.json", wantErr: true},
+		{dest: "azure://container/run.json", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.dest, func(t *testing.T) {
+			t.Parallel()
+
+			loc, err := ParseLocation(tt.dest)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, loc)
+		})
+	}
+}
+
+func TestLocal_Put(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "nested", "dir", "run.json")
+	require.NoError(t, Local{}.Put(context.Background(), path, []byte(`{"ok":true}`), "application/json"))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, `{"ok":true}`, string(data))
+}
+
+func TestS3_Put(t *testing.T) {
+	t.Parallel()
+
+	var got *http.Request
+	var body []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		body, _ = io.ReadAll(r.Body)
+	}))
+	defer srv.Close()
+
+	s3 := &S3{
+		Bucket:      "ops-reports",
+		Region:      "eu-west-1",
+		Credentials: awsauth.Credentials{AccessKeyID: "AKIDEXAMPLE", SecretAccessKey: "secret", SessionToken: "session"},
+		Endpoint:    srv.URL,
+		Client:      srv.Client(),
+		now:         func() time.Time { return time.Date(2026, 3, 14, 9, 26, 53, 0, time.UTC) },
+	}
+	require.NoError(t, s3.Put(context.Background(), "pgdoctor/db 1/run.json.gz", []byte("payload"), "application/gzip"))
+
+	require.NotNil(t, got)
+	assert.Equal(t, http.MethodPut, got.Method)
+	assert.Equal(t, "/ops-reports/pgdoctor/db%201/run.json.gz", got.URL.EscapedPath())
+	assert.Equal(t, "payload", string(body))
+	assert.Equal(t, "application/gzip", got.Header.Get("Content-Type"))
+	assert.Equal(t, "20260314T092653Z", got.Header.Get("X-Amz-Date"))
+	assert.Equal(t, "239f59ed55e737c77147cf55ad0c1b030b6d7ee748a7426952f9b852d5a935e5", got.Header.Get("X-Amz-Content-Sha256"))
+	assert.Equal(t, "session", got.Header.Get("X-Amz-Security-Token"))
+
+	auth := got.Header.Get("Authorization")
+	assert.True(t, strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20260314/eu-west-1/s3/aws4_request, "))
+	assert.Contains(t, auth, "SignedHeaders=content-type;host;x-amz-content-sha256;x-amz-date;x-amz-security-token, ")
+	assert.Regexp(t, `Signature=[0-9a-f]{64}$`, auth)
+}
+
+func TestS3_PutError(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte("<Error><Code>AccessDenied</Code></Error>"))
+	}))
+	defer srv.Close()
+
+	s3 := &S3{Bucket: "b", Region: "us-east-1", Endpoint: srv.URL, Client: srv.Client()}
+	err := s3.Put(context.Background(), "run.json", []byte("{}"), "application/json")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "AccessDenied")
+}
+
+func TestGCS_PutWithMetadataToken(t *testing.T) {
+	t.Parallel()
+
+	var uploaded string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/token":
+			assert.Equal(t, "Google", r.Header.Get("Metadata-Flavor"))
+			_, _ = w.Write([]byte(`{"access_token":"ya29.token","expires_in":3599,"token_type":"Bearer"}`))
+		case "/upload/storage/v1/b/ops-reports/o":
+			assert.Equal(t, "Bearer ya29.token", r.Header.Get("Authorization"))
+			assert.Equal(t, "media", r.URL.Query().Get("uploadType"))
+			assert.Equal(t, "pgdoctor/run.md", r.URL.Query().Get("name"))
+			data, _ := io.ReadAll(r.Body)
+			uploaded = string(data)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	gcs := &GCS{Bucket: "ops-reports", Endpoint: srv.URL, MetadataURL: srv.URL + "/token", Client: srv.Client()}
+	require.NoError(t, gcs.Put(context.Background(), "pgdoctor/run.md", []byte("# Report"), "text/markdown"))
+	assert.Equal(t, "# Report", uploaded)
+}
+
+func TestConfluence_Put(t *testing.T) {
+	t.Parallel()
+
+	var update map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, pass, ok := r.BasicAuth()
+		assert.True(t, ok)
+		assert.Equal(t, "ops@example.com", user)
+		assert.Equal(t, "token", pass)
+		assert.Equal(t, "/wiki/rest/api/content/123456", r.URL.Path)
+
+		switch r.Method {
+		case http.MethodGet:
+			assert.Equal(t, "version", r.URL.Query().Get("expand"))
+			_, _ = w.Write([]byte(`{"id":"123456","type":"page","title":"Weekly DB health","version":{"number":7}}`))
+		case http.MethodPut:
+			assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&update))
+			_, _ = w.Write([]byte(`{}`))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer srv.Close()
+
+	c := &Confluence{BaseURL: srv.URL + "/wiki/", Email: "ops@example.com", APIToken: "token", Client: srv.Client()}
+	require.NoError(t, c.Put(context.Background(), "123456", []byte("<h1>Report</h1>"), "application/xhtml+xml"))
+
+	require.NotNil(t, update)
+	assert.Equal(t, "Weekly DB health", update["title"])
+	assert.Equal(t, float64(8), update["version"].(map[string]any)["number"])
+	assert.Equal(t, map[string]any{"value": "<h1>Report</h1>", "representation": "storage"}, update["body"].(map[string]any)["storage"])
+}
+
+func TestConfluence_PutBearerToken(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer pat", r.Header.Get("Authorization"))
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"message":"No content found with id 42"}`))
+	}))
+	defer srv.Close()
+
+	c := &Confluence{BaseURL: srv.URL, APIToken: "pat", Client: srv.Client()}
+	err := c.Put(context.Background(), "42", []byte("<p/>"), "application/xhtml+xml")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "reading confluence page 42")
+	assert.Contains(t, err.Error(), "No content found")
+}
+
+func TestDestination_Expand(t *testing.T) {
+	t.Parallel()
+
+	dest, err := ParseDestination("s3://ops/{host}/{database}/{date}/run-{timestamp}.json.gz")
+	require.NoError(t, err)
+
+	started := time.Date(2026, 3, 14, 9, 26, 53, 0, time.FixedZone("CET", 3600))
+	dest.Expand(started, "/var/run/postgresql", "orders")
+
+	assert.Equal(t, "var_run_postgresql/orders/2026-03-14/run-20260314T082653Z.json.gz", dest.Location.Key)
+}
+
+func TestDestination_WriteLocalGzip(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "reports", "run.json.gz")
+	dest, err := ParseDestination(path)
+	require.NoError(t, err)
+
+	err = dest.Write(context.Background(), func(w io.Writer) error {
+		_, err := io.WriteString(w, `{"reports":[]}`)
+		return err
+	})
+	require.NoError(t, err)
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+	zr, err := gzip.NewReader(f)
+	require.NoError(t, err)
+	data, err := io.ReadAll(zr)
+	require.NoError(t, err)
+	assert.Equal(t, `{"reports":[]}`, string(data))
+}
@@ -0,0 +1,162 @@
+package tickets
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Jira files tickets through the Jira REST API (v2), using labels for the
+// scope, check, and fingerprint markers.
+type Jira struct {
+	BaseURL   string // e.g. https://example.atlassian.net
+	Email     string
+	APIToken  string
+	Project   string // Project key
+	IssueType string // Defaults to Task
+	Client    *http.Client
+}
+
+const jiraCheckLabelPrefix = "pgdoctor-check-"
+
+func (j *Jira) OpenTickets(ctx context.Context, scope string) ([]OpenTicket, error) {
+	jql := fmt.Sprintf(`project = %q AND labels = %q AND statusCategory != Done`, j.Project, scope)
+	q := url.Values{}
+	q.Set("jql", jql)
+	q.Set("fields", "labels")
+	q.Set("maxResults", "1000")
+
+	var resp struct {
+		Issues []struct {
+			Key    string `json:"key"`
+			Fields struct {
+				Labels []string `json:"labels"`
+			} `json:"fields"`
+		} `json:"issues"`
+	}
+	if err := j.do(ctx, http.MethodGet, "/rest/api/2/search?"+q.Encode(), nil, &resp); err != nil {
+		return nil, err
+	}
+
+	var open []OpenTicket
+	for _, issue := range resp.Issues {
+		t := OpenTicket{Key: issue.Key}
+		for _, label := range issue.Fields.Labels {
+			switch {
+			case strings.HasPrefix(label, jiraCheckLabelPrefix):
+				t.CheckID = strings.TrimPrefix(label, jiraCheckLabelPrefix)
+			case strings.HasPrefix(label, "pgdoctor-") && label != scope:
+				t.Fingerprint = label
+			}
+		}
+		if t.Fingerprint != "" {
+			open = append(open, t)
+		}
+	}
+	return open, nil
+}
+
+func (j *Jira) Create(ctx context.Context, t Ticket) (string, error) {
+	issueType := j.IssueType
+	if issueType == "" {
+		issueType = "Task"
+	}
+
+	body := map[string]any{
+		"fields": map[string]any{
+			"project":     map[string]string{"key": j.Project},
+			"issuetype":   map[string]string{"name": issueType},
+			"summary":     t.Title,
+			"description": t.Description,
+			"labels":      []string{"pgdoctor", t.Scope, jiraCheckLabelPrefix + t.CheckID, t.Fingerprint},
+		},
+	}
+
+	var resp struct {
+		Key string `json:"key"`
+	}
+	if err := j.do(ctx, http.MethodPost, "/rest/api/2/issue", body, &resp); err != nil {
+		return "", err
+	}
+	return resp.Key, nil
+}
+
+func (j *Jira) Close(ctx context.Context, key string) error {
+	var resp struct {
+		Transitions []struct {
+			ID string `json:"id"`
+			To struct {
+				StatusCategory struct {
+					Key string `json:"key"`
+				} `json:"statusCategory"`
+			} `json:"to"`
+		} `json:"transitions"`
+	}
+	path := "/rest/api/2/issue/" + url.PathEscape(key) + "/transitions"
+	if err := j.do(ctx, http.MethodGet, path, nil, &resp); err != nil {
+		return err
+	}
+
+	for _, tr := range resp.Transitions {
+		if tr.To.StatusCategory.Key == "done" {
+			body := map[string]any{"transition": map[string]string{"id": tr.ID}}
+			return j.do(ctx, http.MethodPost, path, body, nil)
+		}
+	}
+	return errors.New("no transition to a done status is available")
+}
+
+func (j *Jira) do(ctx context.Context, method, path string, body, out any) error {
+	var reqBody io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("encoding jira request: %w", err)
+		}
+		reqBody = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimRight(j.BaseURL, "/")+path, reqBody)
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth(j.Email, j.APIToken)
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	return doJSON(j.Client, req, out)
+}
+
+// doJSON sends a request and decodes a JSON response into out, if non-nil.
+func doJSON(client *http.Client, req *http.Request, out any) error {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("%s %s: %s: %s", req.Method, req.URL.Path, resp.Status, strings.TrimSpace(string(msg)))
+	}
+
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("decoding response: %w", err)
+	}
+	return nil
+}
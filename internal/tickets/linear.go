@@ -0,0 +1,158 @@
+package tickets
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+const linearAPIURL = "https://api.linear.app/graphql"
+
+// Linear files tickets through the Linear GraphQL API. Linear labels are
+// workspace-managed, so the scope, check, and fingerprint markers live in
+// the issue description instead.
+type Linear struct {
+	APIKey string
+	TeamID string
+	Client *http.Client
+
+	// URL overrides the API endpoint (for tests).
+	URL string
+}
+
+func (l *Linear) OpenTickets(ctx context.Context, scope string) ([]OpenTicket, error) {
+	const query = `query($team: ID!, $scope: String!) {
+  issues(first: 250, filter: {
+    team: { id: { eq: $team } }
+    description: { contains: $scope }
+    state: { type: { nin: ["completed", "canceled"] } }
+  }) { nodes { id description } }
+}`
+
+	var data struct {
+		Issues struct {
+			Nodes []struct {
+				ID          string `json:"id"`
+				Description string `json:"description"`
+			} `json:"nodes"`
+		} `json:"issues"`
+	}
+	vars := map[string]any{"team": l.TeamID, "scope": markerScope + scope}
+	if err := l.do(ctx, query, vars, &data); err != nil {
+		return nil, err
+	}
+
+	var open []OpenTicket
+	for _, n := range data.Issues.Nodes {
+		checkID, fp := parseMarkers(n.Description)
+		if fp != "" {
+			open = append(open, OpenTicket{Key: n.ID, CheckID: checkID, Fingerprint: fp})
+		}
+	}
+	return open, nil
+}
+
+func (l *Linear) Create(ctx context.Context, t Ticket) (string, error) {
+	const mutation = `mutation($input: IssueCreateInput!) {
+  issueCreate(input: $input) { success issue { id } }
+}`
+
+	var data struct {
+		IssueCreate struct {
+			Success bool `json:"success"`
+			Issue   struct {
+				ID string `json:"id"`
+			} `json:"issue"`
+		} `json:"issueCreate"`
+	}
+	vars := map[string]any{"input": map[string]string{
+		"teamId":      l.TeamID,
+		"title":       t.Title,
+		"description": t.Description,
+	}}
+	if err := l.do(ctx, mutation, vars, &data); err != nil {
+		return "", err
+	}
+	if !data.IssueCreate.Success {
+		return "", errors.New("linear rejected issueCreate")
+	}
+	return data.IssueCreate.Issue.ID, nil
+}
+
+func (l *Linear) Close(ctx context.Context, key string) error {
+	const statesQuery = `query($team: String!) {
+  team(id: $team) { states { nodes { id type } } }
+}`
+
+	var states struct {
+		Team struct {
+			States struct {
+				Nodes []struct {
+					ID   string `json:"id"`
+					Type string `json:"type"`
+				} `json:"nodes"`
+			} `json:"states"`
+		} `json:"team"`
+	}
+	if err := l.do(ctx, statesQuery, map[string]any{"team": l.TeamID}, &states); err != nil {
+		return err
+	}
+
+	stateID := ""
+	for _, s := range states.Team.States.Nodes {
+		if s.Type == "completed" {
+			stateID = s.ID
+			break
+		}
+	}
+	if stateID == "" {
+		return errors.New("team has no completed workflow state")
+	}
+
+	const mutation = `mutation($id: String!, $state: String!) {
+  issueUpdate(id: $id, input: { stateId: $state }) { success }
+}`
+	return l.do(ctx, mutation, map[string]any{"id": key, "state": stateID}, nil)
+}
+
+func (l *Linear) do(ctx context.Context, query string, vars map[string]any, out any) error {
+	data, err := json.Marshal(map[string]any{"query": query, "variables": vars})
+	if err != nil {
+		return fmt.Errorf("encoding linear request: %w", err)
+	}
+
+	endpoint := l.URL
+	if endpoint == "" {
+		endpoint = linearAPIURL
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", l.APIKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	var resp struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := doJSON(l.Client, req, &resp); err != nil {
+		return err
+	}
+	if len(resp.Errors) > 0 {
+		return fmt.Errorf("linear: %s", resp.Errors[0].Message)
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(resp.Data, out); err != nil {
+		return fmt.Errorf("decoding linear response: %w", err)
+	}
+	return nil
+}
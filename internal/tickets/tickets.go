@@ -0,0 +1,169 @@
+// Package tickets opens issue-tracker tickets for failing findings and closes
+// them once a later run no longer reports the problem.
+//
+// Sync is stateless: every ticket carries markers for the database scope,
+// the check, and the finding fingerprint, so the tracker itself is the source
+// of truth for which findings already have an open ticket.
+package tickets
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/fresha/pgdoctor/check"
+)
+
+// Ticket is a ticket to be created for a failing finding.
+type Ticket struct {
+	Scope       string
+	CheckID     string
+	Fingerprint string
+	Title       string
+	Description string
+}
+
+// OpenTicket is an unresolved ticket previously created by pgdoctor.
+type OpenTicket struct {
+	Key         string
+	CheckID     string
+	Fingerprint string
+}
+
+// Tracker is an issue tracker pgdoctor can file tickets in.
+type Tracker interface {
+	// OpenTickets lists unresolved pgdoctor tickets for the given scope.
+	OpenTickets(ctx context.Context, scope string) ([]OpenTicket, error)
+	// Create files a ticket and returns its key.
+	Create(ctx context.Context, t Ticket) (string, error)
+	// Close resolves a ticket.
+	Close(ctx context.Context, key string) error
+}
+
+// Result summarizes the changes made by Sync.
+type Result struct {
+	Created  []string
+	Existing []string
+	Closed   []string
+}
+
+// Scope identifies a database so that tickets for different databases
+// sharing a project are never deduplicated or closed against each other.
+func Scope(database string) string {
+	return "pgdoctor-db-" + shortHash(database, 8)
+}
+
+// Fingerprint identifies a finding on a database across runs.
+func Fingerprint(database, checkID, findingID string) string {
+	return "pgdoctor-" + shortHash(database+"\x00"+checkID+"\x00"+findingID, 12)
+}
+
+// Sync creates tickets for FAIL findings that have none open, and closes open
+// tickets whose check ran but no longer fails. Tickets for checks that were
+// skipped or filtered out of this run are left alone.
+func Sync(ctx context.Context, tracker Tracker, database string, reports []*check.Report) (Result, error) {
+	var result Result
+	scope := Scope(database)
+
+	open, err := tracker.OpenTickets(ctx, scope)
+	if err != nil {
+		return result, fmt.Errorf("listing open tickets: %w", err)
+	}
+
+	openByFingerprint := make(map[string]OpenTicket, len(open))
+	for _, t := range open {
+		openByFingerprint[t.Fingerprint] = t
+	}
+
+	ranChecks := map[string]bool{}
+	failing := map[string]bool{}
+
+	for _, r := range reports {
+		if r.Severity == check.SeveritySkip {
+			continue
+		}
+		ranChecks[r.CheckID] = true
+
+		for _, f := range r.Results {
+			if f.Severity != check.SeverityFail {
+				continue
+			}
+
+			fp := Fingerprint(database, r.CheckID, f.ID)
+			failing[fp] = true
+
+			if existing, ok := openByFingerprint[fp]; ok {
+				result.Existing = append(result.Existing, existing.Key)
+				continue
+			}
+
+			key, err := tracker.Create(ctx, newTicket(database, scope, fp, r, f))
+			if err != nil {
+				return result, fmt.Errorf("creating ticket for %s/%s: %w", r.CheckID, f.ID, err)
+			}
+			result.Created = append(result.Created, key)
+		}
+	}
+
+	for _, t := range open {
+		if failing[t.Fingerprint] || !ranChecks[t.CheckID] {
+			continue
+		}
+		if err := tracker.Close(ctx, t.Key); err != nil {
+			return result, fmt.Errorf("closing ticket %s: %w", t.Key, err)
+		}
+		result.Closed = append(result.Closed, t.Key)
+	}
+
+	return result, nil
+}
+
+func newTicket(database, scope, fingerprint string, r *check.Report, f check.Finding) Ticket {
+	var b strings.Builder
+	fmt.Fprintf(&b, "pgdoctor reported a failing finding on %s.\n\n", database)
+	fmt.Fprintf(&b, "Check: %s (%s)\nFinding: %s (%s)\n\n", r.Name, r.CheckID, f.Name, f.ID)
+	if f.Details != "" {
+		fmt.Fprintf(&b, "%s\n\n", f.Details)
+	}
+	if f.DocsURL != "" {
+		fmt.Fprintf(&b, "How to fix: %s\n\n", f.DocsURL)
+	}
+	b.WriteString("This ticket is closed automatically when a later run no longer reports the finding.\n\n")
+	fmt.Fprintf(&b, "%s%s\n%s%s\n%s%s\n", markerScope, scope, markerCheck, r.CheckID, markerFingerprint, fingerprint)
+
+	return Ticket{
+		Scope:       scope,
+		CheckID:     r.CheckID,
+		Fingerprint: fingerprint,
+		Title:       fmt.Sprintf("[pgdoctor] %s: %s - %s", database, r.Name, f.Name),
+		Description: b.String(),
+	}
+}
+
+// Markers embedded in ticket descriptions for trackers without labels.
+const (
+	markerScope       = "pgdoctor-scope: "
+	markerCheck       = "pgdoctor-check: "
+	markerFingerprint = "pgdoctor-fingerprint: "
+)
+
+// parseMarkers extracts the check ID and fingerprint from a ticket description.
+func parseMarkers(description string) (checkID, fingerprint string) {
+	for _, line := range strings.Split(description, "\n") {
+		line = strings.TrimSpace(line)
+		if v, ok := strings.CutPrefix(line, markerCheck); ok {
+			checkID = v
+		}
+		if v, ok := strings.CutPrefix(line, markerFingerprint); ok {
+			fingerprint = v
+		}
+	}
+	return checkID, fingerprint
+}
+
+func shortHash(s string, n int) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])[:n]
+}
@@ -0,0 +1,128 @@
+package tickets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/fresha/pgdoctor/check"
+)
+
+type fakeTracker struct {
+	open    []OpenTicket
+	created []Ticket
+	closed  []string
+}
+
+func (f *fakeTracker) OpenTickets(context.Context, string) ([]OpenTicket, error) {
+	return f.open, nil
+}
+
+func (f *fakeTracker) Create(_ context.Context, t Ticket) (string, error) {
+	f.created = append(f.created, t)
+	return fmt.Sprintf("PG-%d", len(f.created)), nil
+}
+
+func (f *fakeTracker) Close(_ context.Context, key string) error {
+	f.closed = append(f.closed, key)
+	return nil
+}
+
+func report(checkID string, severity check.Severity, findingIDs ...string) *check.Report {
+	r := check.NewReport(check.Metadata{CheckID: checkID, Name: checkID})
+	if severity == check.SeveritySkip {
+		r.Severity = check.SeveritySkip
+		return r
+	}
+	for _, id := range findingIDs {
+		r.AddFinding(check.Finding{ID: id, Name: id, Severity: severity})
+	}
+	return r
+}
+
+func TestSync(t *testing.T) {
+	t.Parallel()
+
+	const database = "db.internal/app"
+	tracker := &fakeTracker{
+		open: []OpenTicket{
+			{Key: "PG-OLD-1", CheckID: "sequence-health", Fingerprint: Fingerprint(database, "sequence-health", "near-exhaustion")},
+			{Key: "PG-OLD-2", CheckID: "invalid-indexes", Fingerprint: Fingerprint(database, "invalid-indexes", "invalid-indexes")},
+			{Key: "PG-OLD-3", CheckID: "index-bloat", Fingerprint: Fingerprint(database, "index-bloat", "high-bloat")},
+		},
+	}
+
+	reports := []*check.Report{
+		report("sequence-health", check.SeverityFail, "near-exhaustion", "type-mismatch"),
+		report("invalid-indexes", check.SeverityOK, "invalid-indexes"),
+		report("index-bloat", check.SeveritySkip),
+		report("pg-version", check.SeverityWarn, "pg-version"),
+	}
+
+	result, err := Sync(context.Background(), tracker, database, reports)
+	require.NoError(t, err)
+
+	// Already-ticketed failure is deduplicated, the new one is filed.
+	assert.Equal(t, []string{"PG-OLD-1"}, result.Existing)
+	require.Len(t, tracker.created, 1)
+	assert.Equal(t, Fingerprint(database, "sequence-health", "type-mismatch"), tracker.created[0].Fingerprint)
+	assert.Contains(t, tracker.created[0].Description, markerFingerprint+tracker.created[0].Fingerprint)
+
+	// Resolved finding is closed; skipped check's ticket is left open.
+	assert.Equal(t, []string{"PG-OLD-2"}, tracker.closed)
+}
+
+func TestFingerprint_ScopedByDatabase(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, Fingerprint("a", "c", "f"), Fingerprint("a", "c", "f"))
+	assert.NotEqual(t, Fingerprint("a", "c", "f"), Fingerprint("b", "c", "f"))
+	assert.NotEqual(t, Scope("a"), Scope("b"))
+}
+
+func TestJira_OpenTicketsAndClose(t *testing.T) {
+	t.Parallel()
+
+	var transitioned string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, pass, _ := r.BasicAuth()
+		assert.Equal(t, "ops@example.com", user)
+		assert.Equal(t, "token", pass)
+
+		switch {
+		case r.URL.Path == "/rest/api/2/search":
+			assert.Contains(t, r.URL.Query().Get("jql"), `labels = "pgdoctor-db-1234"`)
+			_, _ = w.Write([]byte(`{"issues":[{"key":"DB-7","fields":{"labels":["pgdoctor","pgdoctor-db-1234","pgdoctor-check-pk-types","pgdoctor-abc"]}}]}`))
+		case r.URL.Path == "/rest/api/2/issue/DB-7/transitions" && r.Method == http.MethodGet:
+			_, _ = w.Write([]byte(`{"transitions":[{"id":"11","to":{"statusCategory":{"key":"indeterminate"}}},{"id":"31","to":{"statusCategory":{"key":"done"}}}]}`))
+		case r.URL.Path == "/rest/api/2/issue/DB-7/transitions" && r.Method == http.MethodPost:
+			var body struct {
+				Transition struct {
+					ID string `json:"id"`
+				} `json:"transition"`
+			}
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			transitioned = body.Transition.ID
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	jira := &Jira{BaseURL: srv.URL, Email: "ops@example.com", APIToken: "token", Project: "DB", Client: srv.Client()}
+
+	open, err := jira.OpenTickets(context.Background(), "pgdoctor-db-1234")
+	require.NoError(t, err)
+	assert.Equal(t, []OpenTicket{{Key: "DB-7", CheckID: "pk-types", Fingerprint: "pgdoctor-abc"}}, open)
+
+	require.NoError(t, jira.Close(context.Background(), "DB-7"))
+	assert.Equal(t, "31", transitioned)
+}
@@ -0,0 +1,33 @@
+-- name: TuneSettings :many
+-- The settings pgdoctor tune recommends, with memory settings in bytes.
+SELECT
+  name::text AS name
+  , current_setting(name)::text AS display
+  , (
+    CASE
+      WHEN unit IN ('B', 'kB', '8kB', '16kB', 'MB') THEN pg_size_bytes(current_setting(name))::float8
+      ELSE setting::float8
+    END
+  )::float8 AS value
+  , (context = 'postmaster')::boolean AS needs_restart
+FROM pg_settings
+WHERE
+  name IN (
+    'checkpoint_completion_target'
+    , 'default_statistics_target'
+    , 'effective_cache_size'
+    , 'effective_io_concurrency'
+    , 'maintenance_work_mem'
+    , 'max_connections'
+    , 'max_parallel_maintenance_workers'
+    , 'max_parallel_workers'
+    , 'max_parallel_workers_per_gather'
+    , 'max_wal_size'
+    , 'max_worker_processes'
+    , 'min_wal_size'
+    , 'random_page_cost'
+    , 'shared_buffers'
+    , 'wal_buffers'
+    , 'work_mem'
+  )
+ORDER BY name;
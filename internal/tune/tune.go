@@ -0,0 +1,349 @@
+// Package tune recommends memory, WAL, planner, and parallelism settings
+// for an instance's RAM, CPUs, storage, and workload, following the rules of
+// thumb PGTune popularised, and compares them with the server's current
+// settings. pgdoctor tune reads the settings and renders the comparison as
+// a report with ALTER SYSTEM prescriptions.
+package tune
+
+import (
+	_ "embed"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/fresha/pgdoctor/check"
+	"github.com/fresha/pgdoctor/db"
+)
+
+//go:embed query.sql
+var querySQL string
+
+const (
+	kB = 1024
+	mB = 1024 * kB
+	gB = 1024 * mB
+
+	// tolerance is how far a current setting may sit from the
+	// recommendation, as a ratio either way, before it is reported. The
+	// recommendations are rules of thumb, not exact targets.
+	tolerance = 1.25
+
+	// docsURL is the README section on pgdoctor tune; the report is not a
+	// check, so it has no page on the docs site.
+	docsURL = "https://github.com/fresha/pgdoctor#pgdoctor-tune-dsn---memory-size"
+)
+
+// Workload is the kind of traffic the instance serves.
+type Workload string
+
+const (
+	WorkloadWeb     Workload = "web"     // Many short queries from a web application
+	WorkloadOLTP    Workload = "oltp"    // Many short transactions, write heavy
+	WorkloadDW      Workload = "dw"      // Few large analytical queries and bulk loads
+	WorkloadDesktop Workload = "desktop" // A developer machine sharing RAM with other programs
+	WorkloadMixed   Workload = "mixed"   // Both transactional and analytical queries
+)
+
+// Workloads lists every workload, for flag help and validation.
+var Workloads = []Workload{WorkloadWeb, WorkloadOLTP, WorkloadDW, WorkloadDesktop, WorkloadMixed}
+
+// ParseWorkload converts a workload name to a Workload.
+func ParseWorkload(s string) (Workload, error) {
+	w := Workload(strings.ToLower(strings.TrimSpace(s)))
+	if !slices.Contains(Workloads, w) {
+		names := make([]string, len(Workloads))
+		for i, w := range Workloads {
+			names[i] = string(w)
+		}
+		return "", fmt.Errorf("unknown workload %q (expected %s)", s, strings.Join(names, ", "))
+	}
+	return w, nil
+}
+
+// Group is a finding of the tuning report; each setting belongs to one.
+type Group string
+
+const (
+	GroupMemory      Group = "memory"
+	GroupWAL         Group = "wal"
+	GroupPlanner     Group = "planner"
+	GroupParallelism Group = "parallelism"
+)
+
+var groupNames = map[Group]string{
+	GroupMemory:      "Memory Settings",
+	GroupWAL:         "WAL and Checkpoint Settings",
+	GroupPlanner:     "Planner Settings",
+	GroupParallelism: "Parallel Query Settings",
+}
+
+// Recommendation is the value one setting should have.
+type Recommendation struct {
+	Name  string
+	Group Group
+	// Value is in bytes for memory settings.
+	Value float64
+	// Bytes marks memory settings, rendered with a unit.
+	Bytes bool
+}
+
+// Input describes the instance being tuned.
+type Input struct {
+	// Instance supplies MemoryGB, VCPUCores, and StorageType. Without
+	// MemoryGB nothing is recommended; without VCPUCores parallelism is not
+	// tuned.
+	Instance check.InstanceMetadata
+	Workload Workload
+	// Connections is the max_connections to plan memory for.
+	Connections int
+}
+
+// Recommend returns the settings for the instance, in report order.
+func Recommend(in Input) []Recommendation {
+	memoryKB := int64(in.Instance.MemoryGB * gB / kB)
+	if memoryKB <= 0 {
+		return nil
+	}
+
+	mem := func(name string, group Group, valueKB int64) Recommendation {
+		return Recommendation{Name: name, Group: group, Value: float64(valueKB * kB), Bytes: true}
+	}
+	num := func(name string, group Group, value float64) Recommendation {
+		return Recommendation{Name: name, Group: group, Value: value}
+	}
+
+	sharedBuffersKB := memoryKB / 4
+	effectiveCacheKB := memoryKB * 3 / 4
+	if in.Workload == WorkloadDesktop {
+		sharedBuffersKB = memoryKB / 16
+		effectiveCacheKB = memoryKB / 4
+	}
+
+	maintenanceKB := memoryKB / 16
+	if in.Workload == WorkloadDW {
+		maintenanceKB = memoryKB / 8
+	}
+	maintenanceKB = min(maintenanceKB, 2*gB/kB)
+
+	// wal_buffers' own default: 3% of shared_buffers up to one 16MB WAL
+	// segment, rounded up when it is nearly there.
+	walBuffersKB := sharedBuffersKB * 3 / 100
+	if walBuffersKB > 14*mB/kB {
+		walBuffersKB = 16 * mB / kB
+	}
+	walBuffersKB = max(walBuffersKB, 32)
+
+	recs := []Recommendation{
+		num("max_connections", GroupMemory, float64(in.Connections)),
+		mem("shared_buffers", GroupMemory, sharedBuffersKB),
+		mem("effective_cache_size", GroupMemory, effectiveCacheKB),
+		mem("maintenance_work_mem", GroupMemory, maintenanceKB),
+	}
+
+	// Parallel workers only pay off with a few cores to spare.
+	workers, workersPerGather := 8, 1
+	var parallel []Recommendation
+	if cpus := in.Instance.VCPUCores; cpus >= 4 {
+		workers = cpus
+		workersPerGather = (cpus + 1) / 2
+		if in.Workload != WorkloadDW {
+			workersPerGather = min(workersPerGather, 4)
+		}
+		parallel = []Recommendation{
+			num("max_worker_processes", GroupParallelism, float64(cpus)),
+			num("max_parallel_workers", GroupParallelism, float64(cpus)),
+			num("max_parallel_workers_per_gather", GroupParallelism, float64(workersPerGather)),
+			num("max_parallel_maintenance_workers", GroupParallelism, float64(min((cpus+1)/2, 4))),
+		}
+	}
+
+	// Each connection and background worker may run a few sorts or hashes at
+	// once, each multiplied across a query's parallel workers.
+	workMemKB := (memoryKB - sharedBuffersKB) / (int64(in.Connections+workers) * 3) / int64(workersPerGather)
+	switch in.Workload {
+	case WorkloadDW, WorkloadMixed:
+		workMemKB /= 2
+	case WorkloadDesktop:
+		workMemKB /= 6
+	}
+	recs = append(recs,
+		mem("work_mem", GroupMemory, max(workMemKB, 64)),
+		mem("wal_buffers", GroupMemory, walBuffersKB),
+	)
+
+	minWalMB, maxWalMB := int64(1024), int64(4096)
+	switch in.Workload {
+	case WorkloadOLTP:
+		minWalMB, maxWalMB = 2048, 8192
+	case WorkloadDW:
+		minWalMB, maxWalMB = 4096, 16384
+	case WorkloadDesktop:
+		minWalMB, maxWalMB = 100, 2048
+	}
+	recs = append(recs,
+		mem("min_wal_size", GroupWAL, minWalMB*mB/kB),
+		mem("max_wal_size", GroupWAL, maxWalMB*mB/kB),
+		num("checkpoint_completion_target", GroupWAL, 0.9),
+	)
+
+	statisticsTarget := 100.0
+	if in.Workload == WorkloadDW {
+		statisticsTarget = 500
+	}
+	randomPageCost, ioConcurrency := 1.1, 200.0
+	switch storageClass(in.Instance.StorageType) {
+	case "hdd":
+		randomPageCost, ioConcurrency = 4, 2
+	case "san":
+		ioConcurrency = 300
+	}
+	recs = append(recs,
+		num("default_statistics_target", GroupPlanner, statisticsTarget),
+		num("random_page_cost", GroupPlanner, randomPageCost),
+		num("effective_io_concurrency", GroupPlanner, ioConcurrency),
+	)
+
+	return append(recs, parallel...)
+}
+
+// storageClass maps a storage type, as given on the command line or by a
+// cloud provider, to hdd, ssd, or san. Unknown types are treated as SSDs,
+// which nearly all managed instances use.
+func storageClass(storageType string) string {
+	switch strings.ToLower(storageType) {
+	case "hdd", "standard", "magnetic", "pd-standard", "st1", "sc1":
+		return "hdd"
+	case "san", "io1", "io2":
+		return "san"
+	default:
+		return "ssd"
+	}
+}
+
+// Metadata describes the report pgdoctor tune renders.
+func Metadata() check.Metadata {
+	return check.Metadata{
+		Category:       check.CategoryConfigs,
+		CheckID:        "tune",
+		Name:           "Settings Tuning",
+		Description:    "Compares memory, WAL, planner, and parallelism settings with recommendations for the instance and workload",
+		SQL:            querySQL,
+		RuntimeClass:   check.RuntimeFast,
+		ProductionSafe: true,
+		Findings: []check.FindingSpec{
+			{ID: string(GroupMemory), Description: "shared_buffers, work_mem, and other memory settings for the instance's RAM and connections", Thresholds: "WARN when a setting is more than 25% off"},
+			{ID: string(GroupWAL), Description: "WAL size and checkpoint spreading for the workload", Thresholds: "WARN when a setting is more than 25% off"},
+			{ID: string(GroupPlanner), Description: "Planner cost and statistics settings for the storage and workload", Thresholds: "WARN when a setting is more than 25% off"},
+			{ID: string(GroupParallelism), Description: "Parallel worker limits for the instance's CPUs", Thresholds: "WARN when a setting is more than 25% off"},
+		},
+	}
+}
+
+// Compare reports, per group, each recommended setting next to its current
+// value, with an ALTER SYSTEM fix for those too far off. Settings the
+// server does not have are left out.
+func Compare(recs []Recommendation, settings []db.TuneSettingsRow) *check.Report {
+	report := check.NewReport(Metadata())
+
+	current := make(map[string]db.TuneSettingsRow, len(settings))
+	for _, s := range settings {
+		current[s.Name] = s
+	}
+
+	for _, group := range []Group{GroupMemory, GroupWAL, GroupPlanner, GroupParallelism} {
+		var rows []check.TableRow
+		var fixes []check.Fix
+		for _, rec := range recs {
+			cur, ok := current[rec.Name]
+			if rec.Group != group || !ok {
+				continue
+			}
+
+			value := formatValue(rec)
+			severity := check.SeverityOK
+			if !within(cur.Value, rec.Value) {
+				severity = check.SeverityWarn
+				fixes = append(fixes, settingFix(rec.Name, value, cur.NeedsRestart))
+			}
+			applies := "reload"
+			if cur.NeedsRestart {
+				applies = "restart"
+			}
+			rows = append(rows, check.TableRow{
+				Cells:    []string{rec.Name, cur.Display, value, applies},
+				Severity: severity,
+			})
+		}
+		if len(rows) == 0 {
+			continue
+		}
+
+		finding := check.Finding{
+			ID:       string(group),
+			Name:     groupNames[group],
+			Severity: check.SeverityOK,
+			Details:  "All settings are within 25% of the recommendation",
+			DocsURL:  docsURL,
+			Table: &check.Table{
+				Headers: []string{"Setting", "Current", "Recommended", "Applies After"},
+				Rows:    rows,
+			},
+		}
+		if len(fixes) > 0 {
+			finding.Severity = check.SeverityWarn
+			finding.Details = fmt.Sprintf("%d setting(s) more than 25%% off the recommendation. The recommendations are starting points "+
+				"from RAM, CPUs, storage, and workload; keep a setting that was tuned against measurements", len(fixes))
+			finding.Fixes = fixes
+		}
+		report.AddFinding(finding)
+	}
+
+	return report
+}
+
+// within reports whether current is close enough to recommended.
+func within(current, recommended float64) bool {
+	if current <= 0 || recommended <= 0 {
+		return current == recommended
+	}
+	return max(current, recommended)/min(current, recommended) <= tolerance
+}
+
+// settingFix writes the recommendation to postgresql.auto.conf. Settings are
+// deliberate choices, so the fix is high risk and never applied by
+// pgdoctor fix; those needing a restart wait for a maintenance window.
+func settingFix(name, value string, needsRestart bool) check.Fix {
+	fix := check.Fix{
+		Object:      name,
+		Description: fmt.Sprintf("Set %s to %s, then run SELECT pg_reload_conf(). Managed services reject ALTER SYSTEM: set it in the parameter group instead", name, value),
+		SQL:         fmt.Sprintf("ALTER SYSTEM SET %s = '%s'", name, value),
+		Lock:        check.LockOnline,
+	}
+	if needsRestart {
+		fix.Description = fmt.Sprintf("Set %s to %s; it takes effect after a restart. Managed services reject ALTER SYSTEM: set it in the parameter group instead", name, value)
+		fix.Lock = check.LockExclusive
+	}
+	return fix
+}
+
+// formatValue renders a recommendation the way postgresql.conf accepts it,
+// with memory in the largest unit that divides it evenly.
+func formatValue(rec Recommendation) string {
+	if !rec.Bytes {
+		if rec.Value == math.Trunc(rec.Value) {
+			return fmt.Sprintf("%d", int64(rec.Value))
+		}
+		return fmt.Sprintf("%g", rec.Value)
+	}
+
+	valueKB := int64(rec.Value) / kB
+	switch {
+	case valueKB%(gB/kB) == 0:
+		return fmt.Sprintf("%dGB", valueKB/(gB/kB))
+	case valueKB%(mB/kB) == 0:
+		return fmt.Sprintf("%dMB", valueKB/(mB/kB))
+	default:
+		return fmt.Sprintf("%dkB", valueKB)
+	}
+}
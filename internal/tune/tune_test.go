@@ -0,0 +1,163 @@
+package tune
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/fresha/pgdoctor/check"
+	"github.com/fresha/pgdoctor/db"
+)
+
+func recommended(recs []Recommendation) map[string]string {
+	values := make(map[string]string, len(recs))
+	for _, r := range recs {
+		values[r.Name] = formatValue(r)
+	}
+	return values
+}
+
+func TestRecommend(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		in       Input
+		expected map[string]string
+		absent   []string
+	}{
+		{
+			name: "oltp on 64GB with 16 cores",
+			in: Input{
+				Instance:    check.InstanceMetadata{MemoryGB: 64, VCPUCores: 16, StorageType: "gp3"},
+				Workload:    WorkloadOLTP,
+				Connections: 300,
+			},
+			expected: map[string]string{
+				"max_connections":                  "300",
+				"shared_buffers":                   "16GB",
+				"effective_cache_size":             "48GB",
+				"maintenance_work_mem":             "2GB",
+				"work_mem":                         "13273kB",
+				"wal_buffers":                      "16MB",
+				"min_wal_size":                     "2GB",
+				"max_wal_size":                     "8GB",
+				"checkpoint_completion_target":     "0.9",
+				"random_page_cost":                 "1.1",
+				"effective_io_concurrency":         "200",
+				"default_statistics_target":        "100",
+				"max_worker_processes":             "16",
+				"max_parallel_workers_per_gather":  "4",
+				"max_parallel_maintenance_workers": "4",
+			},
+		},
+		{
+			name: "data warehouse on hdd",
+			in: Input{
+				Instance:    check.InstanceMetadata{MemoryGB: 32, VCPUCores: 12, StorageType: "hdd"},
+				Workload:    WorkloadDW,
+				Connections: 40,
+			},
+			expected: map[string]string{
+				"maintenance_work_mem":            "2GB",
+				"max_wal_size":                    "16GB",
+				"default_statistics_target":       "500",
+				"random_page_cost":                "4",
+				"effective_io_concurrency":        "2",
+				"max_parallel_workers_per_gather": "6",
+			},
+		},
+		{
+			name: "desktop without cpu count",
+			in: Input{
+				Instance:    check.InstanceMetadata{MemoryGB: 8},
+				Workload:    WorkloadDesktop,
+				Connections: 20,
+			},
+			expected: map[string]string{
+				"shared_buffers":       "512MB",
+				"effective_cache_size": "2GB",
+				"wal_buffers":          "16MB",
+				"min_wal_size":         "100MB",
+			},
+			absent: []string{"max_worker_processes", "max_parallel_workers_per_gather"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			values := recommended(Recommend(tt.in))
+			for name, want := range tt.expected {
+				assert.Equal(t, want, values[name], name)
+			}
+			for _, name := range tt.absent {
+				assert.NotContains(t, values, name)
+			}
+		})
+	}
+}
+
+func TestRecommend_NoMemory(t *testing.T) {
+	t.Parallel()
+
+	assert.Empty(t, Recommend(Input{Workload: WorkloadWeb, Connections: 100}))
+}
+
+func TestCompare(t *testing.T) {
+	t.Parallel()
+
+	recs := Recommend(Input{
+		Instance:    check.InstanceMetadata{MemoryGB: 16},
+		Workload:    WorkloadWeb,
+		Connections: 100,
+	})
+	settings := []db.TuneSettingsRow{
+		{Name: "shared_buffers", Display: "128MB", Value: 128 << 20, NeedsRestart: true},
+		{Name: "effective_cache_size", Display: "11GB", Value: 11 << 30},
+		{Name: "max_wal_size", Display: "1GB", Value: 1 << 30},
+		{Name: "min_wal_size", Display: "1GB", Value: 1 << 30},
+		{Name: "checkpoint_completion_target", Display: "0.9", Value: 0.9},
+		{Name: "random_page_cost", Display: "1.1", Value: 1.1},
+	}
+
+	report := Compare(recs, settings)
+	require.Len(t, report.Results, 3)
+	assert.Equal(t, check.SeverityWarn, report.Severity)
+
+	memory := report.Results[0]
+	assert.Equal(t, "memory", memory.ID)
+	assert.Equal(t, check.SeverityWarn, memory.Severity)
+	require.Len(t, memory.Table.Rows, 2)
+	assert.Equal(t, []string{"shared_buffers", "128MB", "4GB", "restart"}, memory.Table.Rows[0].Cells)
+	assert.Equal(t, check.SeverityOK, memory.Table.Rows[1].Severity)
+	require.Len(t, memory.Fixes, 1)
+	assert.Equal(t, "ALTER SYSTEM SET shared_buffers = '4GB'", memory.Fixes[0].SQL)
+	assert.Equal(t, check.RiskHigh, memory.Fixes[0].Risk)
+	assert.True(t, memory.Fixes[0].NeedsMaintenanceWindow())
+
+	wal := report.Results[1]
+	assert.Equal(t, "wal", wal.ID)
+	require.Len(t, wal.Fixes, 1)
+	assert.Equal(t, "ALTER SYSTEM SET max_wal_size = '4GB'", wal.Fixes[0].SQL)
+	assert.Equal(t, check.LockOnline, wal.Fixes[0].Lock)
+
+	planner := report.Results[2]
+	assert.Equal(t, "planner", planner.ID)
+	assert.Equal(t, check.SeverityOK, planner.Severity)
+	assert.Empty(t, planner.Fixes)
+}
+
+func TestParseWorkload(t *testing.T) {
+	t.Parallel()
+
+	w, err := ParseWorkload(" OLTP ")
+	require.NoError(t, err)
+	assert.Equal(t, WorkloadOLTP, w)
+
+	_, err = ParseWorkload("batch")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "web, oltp, dw, desktop, mixed")
+}
@@ -0,0 +1,275 @@
+// Package lambda runs pgdoctor as an AWS Lambda function, so scheduled audits
+// of many RDS instances need no long-running host.
+//
+// Each invocation checks one database. The event names the database by DSN,
+// by a Secrets Manager secret, or both (the secret then supplies credentials
+// for the DSN's host), and may store the JSON report in S3. A CloudWatch
+// Embedded Metric Format line with failure and warning counts is written to
+// stdout, which Lambda ships to CloudWatch Logs.
+package lambda
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	awslambda "github.com/aws/aws-lambda-go/lambda"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/fresha/pgdoctor"
+	"github.com/fresha/pgdoctor/check"
+	"github.com/fresha/pgdoctor/internal/buildinfo"
+	"github.com/fresha/pgdoctor/internal/dsn"
+	"github.com/fresha/pgdoctor/internal/rds"
+	"github.com/fresha/pgdoctor/internal/report"
+	"github.com/fresha/pgdoctor/internal/storage"
+)
+
+// Event is the invocation payload.
+type Event struct {
+	// DSN is a connection string. Optional when SecretARN holds the host.
+	DSN string `json:"dsn,omitempty"`
+	// SecretARN is a Secrets Manager secret holding either a DSN or RDS-style
+	// JSON ({"username", "password", "host", "port", "dbname"}).
+	SecretARN string `json:"secret_arn,omitempty"`
+	// AWSDBInstanceID is the RDS DB instance identifier or ARN of the
+	// database. When set, its class, memory, storage, and Multi-AZ setting
+	// are read from the RDS API and given to the checks.
+	AWSDBInstanceID string `json:"aws_db_instance_id,omitempty"`
+	// AWSMetrics also reads the instance's CloudWatch CPU, memory, IOPS, and
+	// replica lag over the last hour. Requires AWSDBInstanceID.
+	AWSMetrics bool `json:"aws_metrics,omitempty"`
+
+	Only            []string     `json:"only,omitempty"`
+	Ignore          []string     `json:"ignore,omitempty"`
+	MaxRuntimeClass string       `json:"max_runtime_class,omitempty"`
+	Config          check.Config `json:"config,omitempty"`
+	// IgnoreObjects lists schemas, tables, indexes, and sequences that
+	// checks leave out, as ignore_objects in pgdoctor.yaml.
+	IgnoreObjects check.IgnoreRules `json:"ignore_objects,omitempty"`
+	// Schema limits the checks of schema objects to one schema, as --schema.
+	Schema string `json:"schema,omitempty"`
+	// QueryRateLimit caps the statements started per second and applies
+	// pgdoctor.LowImpactSettings, as --query-rate-limit.
+	QueryRateLimit float64 `json:"query_rate_limit,omitempty"`
+
+	// Output optionally stores the report, e.g.
+	// s3://bucket/pgdoctor/{database}/run-{timestamp}.json.gz.
+	Output string `json:"output,omitempty"`
+	// MetricsNamespace is the CloudWatch namespace for the run metrics.
+	// Defaults to "pgdoctor".
+	MetricsNamespace string `json:"metrics_namespace,omitempty"`
+}
+
+// Response is the invocation result.
+type Response struct {
+	Database string          `json:"database"`
+	Severity string          `json:"severity"`
+	Failures int             `json:"failures"`
+	Warnings int             `json:"warnings"`
+	Location string          `json:"location,omitempty"`
+	Reports  []report.Report `json:"reports"`
+	// Version is the pgdoctor build that ran the checks.
+	Version string `json:"version"`
+}
+
+// SecretGetter fetches a secret's string value.
+type SecretGetter interface {
+	GetSecretString(ctx context.Context, secretID string) (string, error)
+}
+
+// InstanceDescriber fetches the metadata and recent host metrics of a cloud
+// database instance.
+type InstanceDescriber interface {
+	DescribeInstance(ctx context.Context, instanceID string) (*check.InstanceMetadata, error)
+	HostMetrics(ctx context.Context, instanceID string, window time.Duration) (*check.HostMetrics, error)
+}
+
+// Handler handles invocations. The zero value is not usable; use New.
+type Handler struct {
+	Secrets   SecretGetter
+	Instances InstanceDescriber
+	// Metrics receives the Embedded Metric Format line for each run.
+	Metrics io.Writer
+
+	now func() time.Time
+}
+
+// New returns a Handler that reads secrets with the function's execution
+// role and writes metrics to stdout.
+func New() *Handler {
+	return &Handler{
+		Secrets:   &SecretsManager{},
+		Instances: &rds.Client{},
+		Metrics:   os.Stdout,
+		now:       time.Now,
+	}
+}
+
+// Start runs the Lambda runtime loop. It is the whole of a function's main.
+func Start() {
+	awslambda.Start(New().Handle)
+}
+
+// Handle checks the database described by the event.
+func (h *Handler) Handle(ctx context.Context, e Event) (*Response, error) {
+	startedAt := h.now()
+
+	checks, err := selectChecks(e)
+	if err != nil {
+		return nil, err
+	}
+	ignoreObjects, err := e.IgnoreObjects.Compile()
+	if err != nil {
+		return nil, fmt.Errorf("invalid ignore_objects: %w", err)
+	}
+	if e.QueryRateLimit < 0 {
+		return nil, fmt.Errorf("invalid query_rate_limit %g: must be positive", e.QueryRateLimit)
+	}
+
+	var dest *storage.Destination
+	if e.Output != "" {
+		if dest, err = storage.ParseDestination(e.Output); err != nil {
+			return nil, fmt.Errorf("invalid output: %w", err)
+		}
+		if dest.Format != "json" {
+			return nil, fmt.Errorf("invalid output %q: only .json destinations are supported", e.Output)
+		}
+	}
+
+	var instance *check.InstanceMetadata
+	if e.AWSDBInstanceID != "" {
+		if instance, err = h.Instances.DescribeInstance(ctx, e.AWSDBInstanceID); err != nil {
+			return nil, fmt.Errorf("reading RDS instance %s: %w", e.AWSDBInstanceID, err)
+		}
+		if e.AWSMetrics {
+			if instance.HostMetrics, err = h.Instances.HostMetrics(ctx, e.AWSDBInstanceID, rds.DefaultMetricsWindow); err != nil {
+				return nil, fmt.Errorf("reading CloudWatch metrics of %s: %w", e.AWSDBInstanceID, err)
+			}
+		}
+	} else if e.AWSMetrics {
+		return nil, errors.New("aws_metrics requires aws_db_instance_id")
+	}
+
+	connConfig, err := h.connConfig(ctx, e)
+	if err != nil {
+		return nil, err
+	}
+	database := dsn.LabelConfig(&connConfig.Config)
+
+	conn, err := pgx.ConnectConfig(ctx, connConfig)
+	if err != nil {
+		return nil, fmt.Errorf("connecting to %s: %s", dsn.RedactConfig(&connConfig.Config), dsn.Scrub(err.Error()))
+	}
+	defer conn.Close(context.Background())
+
+	if _, err := conn.Exec(ctx, fmt.Sprintf("SET statement_timeout = %d", pgdoctor.DefaultStatementTimeoutMs)); err != nil {
+		return nil, fmt.Errorf("setting statement_timeout: %w", err)
+	}
+	if e.QueryRateLimit > 0 {
+		if err := pgdoctor.ApplyLowImpactSettings(ctx, conn); err != nil {
+			return nil, err
+		}
+	}
+	if e.Schema != "" {
+		if err := pgdoctor.ValidateSchema(ctx, conn, e.Schema); err != nil {
+			return nil, err
+		}
+		// Each tenant gets its own Database metric dimension.
+		database += " (schema " + e.Schema + ")"
+	}
+
+	var reports []*check.Report
+	pgdoctor.Run(ctx, conn, pgdoctor.Options{
+		Checks:   checks,
+		Config:   e.Config,
+		OnReport: pgdoctor.Collect(&reports),
+
+		IgnoreObjects: ignoreObjects,
+		Schema:        e.Schema,
+		Instance:      instance,
+
+		QueryRateLimit: e.QueryRateLimit,
+	})
+
+	resp := newResponse(database, reports)
+
+	if dest != nil {
+		dest.Expand(startedAt, connConfig.Host, connConfig.Database)
+		err := dest.Write(ctx, func(w io.Writer) error {
+			return report.WriteJSON(w, reports)
+		})
+		if err != nil {
+			return nil, err
+		}
+		resp.Location = dest.Location.String()
+	}
+
+	if h.Metrics != nil {
+		writeMetrics(h.Metrics, e.MetricsNamespace, resp, startedAt)
+	}
+
+	return resp, nil
+}
+
+func selectChecks(e Event) ([]check.Package, error) {
+	allChecks := pgdoctor.AllChecks()
+
+	validOnly, validIgnored, err := pgdoctor.ResolveFilters(allChecks, e.Only, e.Ignore)
+	if err != nil {
+		return nil, err
+	}
+
+	checks := pgdoctor.Filter(allChecks, validOnly, validIgnored)
+
+	if e.MaxRuntimeClass != "" {
+		maxRuntime, err := check.ParseRuntimeClass(e.MaxRuntimeClass)
+		if err != nil {
+			return nil, fmt.Errorf("invalid max_runtime_class: %w", err)
+		}
+		checks = pgdoctor.FilterByRuntimeClass(checks, maxRuntime)
+	}
+	// Critical checks first, so they finish even if the invocation times out.
+	pgdoctor.SortByPriority(checks, nil)
+	return checks, nil
+}
+
+// connConfig builds the connection config from the event's DSN and secret.
+func (h *Handler) connConfig(ctx context.Context, e Event) (*pgx.ConnConfig, error) {
+	if e.DSN == "" && e.SecretARN == "" {
+		return nil, fmt.Errorf("event needs dsn or secret_arn")
+	}
+
+	var secret string
+	if e.SecretARN != "" {
+		var err error
+		if secret, err = h.Secrets.GetSecretString(ctx, e.SecretARN); err != nil {
+			return nil, fmt.Errorf("reading secret %s: %w", e.SecretARN, err)
+		}
+	}
+	return applySecret(e.DSN, secret)
+}
+
+func newResponse(database string, reports []*check.Report) *Response {
+	resp := &Response{
+		Database: database,
+		Reports:  report.FromChecks(reports),
+		Version:  buildinfo.Get().String(),
+	}
+
+	severity := check.SeverityOK
+	for _, r := range reports {
+		severity = max(severity, r.Severity)
+		switch r.Severity {
+		case check.SeverityFail:
+			resp.Failures++
+		case check.SeverityWarn:
+			resp.Warnings++
+		}
+	}
+	resp.Severity = severity.String()
+	return resp
+}
@@ -0,0 +1,200 @@
+package lambda
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/fresha/pgdoctor/check"
+	"github.com/fresha/pgdoctor/internal/awsauth"
+)
+
+type staticSecrets map[string]string
+
+func (s staticSecrets) GetSecretString(_ context.Context, id string) (string, error) {
+	return s[id], nil
+}
+
+func TestApplySecret(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		dsn      string
+		secret   string
+		host     string
+		port     uint16
+		user     string
+		password string
+		database string
+		wantErr  bool
+	}{
+		{
+			name: "dsn only",
+			dsn:  "postgres://app:pw@db.internal:5433/orders",
+			host: "db.internal", port: 5433, user: "app", password: "pw", database: "orders",
+		},
+		{
+			name:   "rds secret without dsn",
+			secret: `{"engine":"postgres","username":"auditor","password":"s3cret","host":"orders.abc.eu-west-1.rds.amazonaws.com","port":5432,"dbname":"orders"}`,
+			host:   "orders.abc.eu-west-1.rds.amazonaws.com", port: 5432, user: "auditor", password: "s3cret", database: "orders",
+		},
+		{
+			name:   "managed master password fills in credentials",
+			dsn:    "postgres://orders.abc.eu-west-1.rds.amazonaws.com/orders",
+			secret: `{"username":"postgres","password":"rotated"}`,
+			host:   "orders.abc.eu-west-1.rds.amazonaws.com", port: 5432, user: "postgres", password: "rotated", database: "orders",
+		},
+		{
+			name:   "port as string",
+			secret: `{"username":"u","password":"p","host":"h","port":"6432","dbname":"d"}`,
+			host:   "h", port: 6432, user: "u", password: "p", database: "d",
+		},
+		{
+			name:   "secret holding a dsn",
+			secret: "postgres://u:p@h:5432/d",
+			host:   "h", port: 5432, user: "u", password: "p", database: "d",
+		},
+		{name: "dsn secret and dsn", dsn: "postgres://a/b", secret: "postgres://u:p@h/d", wantErr: true},
+		{name: "invalid port", secret: `{"host":"h","port":"x"}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			cfg, err := applySecret(tt.dsn, tt.secret)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.host, cfg.Host)
+			assert.Equal(t, tt.port, cfg.Port)
+			assert.Equal(t, tt.user, cfg.User)
+			assert.Equal(t, tt.password, cfg.Password)
+			assert.Equal(t, tt.database, cfg.Database)
+		})
+	}
+}
+
+func TestSecretsManager_GetSecretString(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "secretsmanager.GetSecretValue", r.Header.Get("X-Amz-Target"))
+		assert.Contains(t, r.Header.Get("Authorization"), "/eu-central-1/secretsmanager/aws4_request")
+
+		var body struct {
+			SecretID string `json:"SecretId"`
+		}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, "arn:aws:secretsmanager:eu-central-1:123456789012:secret:orders-db-AbCdEf", body.SecretID)
+
+		_, _ = w.Write([]byte(`{"Name":"orders-db","SecretString":"{\"username\":\"u\"}"}`))
+	}))
+	defer srv.Close()
+
+	sm := &SecretsManager{
+		Region:      "us-east-1",
+		Credentials: &awsauth.Credentials{AccessKeyID: "AKID", SecretAccessKey: "secret"},
+		Endpoint:    srv.URL,
+		Client:      srv.Client(),
+	}
+	secret, err := sm.GetSecretString(context.Background(), "arn:aws:secretsmanager:eu-central-1:123456789012:secret:orders-db-AbCdEf")
+	require.NoError(t, err)
+	assert.Equal(t, `{"username":"u"}`, secret)
+}
+
+func TestNewResponse(t *testing.T) {
+	t.Parallel()
+
+	reports := []*check.Report{
+		{Metadata: check.Metadata{CheckID: "a"}, Severity: check.SeverityOK},
+		{Metadata: check.Metadata{CheckID: "b"}, Severity: check.SeverityWarn},
+		{Metadata: check.Metadata{CheckID: "c"}, Severity: check.SeverityFail},
+		{Metadata: check.Metadata{CheckID: "d"}, Severity: check.SeverityWarn},
+	}
+
+	resp := newResponse("db.internal/orders", reports)
+	assert.Equal(t, "fail", resp.Severity)
+	assert.Equal(t, 1, resp.Failures)
+	assert.Equal(t, 2, resp.Warnings)
+	assert.Len(t, resp.Reports, 4)
+}
+
+func TestWriteMetrics(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	at := time.Date(2026, 3, 14, 9, 0, 0, 0, time.UTC)
+	writeMetrics(&buf, "", &Response{Database: "db/orders", Severity: "warn", Warnings: 3}, at)
+
+	var line struct {
+		AWS struct {
+			Timestamp         int64 `json:"Timestamp"`
+			CloudWatchMetrics []struct {
+				Namespace  string     `json:"Namespace"`
+				Dimensions [][]string `json:"Dimensions"`
+			} `json:"CloudWatchMetrics"`
+		} `json:"_aws"`
+		Database string `json:"Database"`
+		Failures int    `json:"Failures"`
+		Warnings int    `json:"Warnings"`
+	}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &line))
+	assert.Equal(t, at.UnixMilli(), line.AWS.Timestamp)
+	require.Len(t, line.AWS.CloudWatchMetrics, 1)
+	assert.Equal(t, "pgdoctor", line.AWS.CloudWatchMetrics[0].Namespace)
+	assert.Equal(t, [][]string{{"Database"}}, line.AWS.CloudWatchMetrics[0].Dimensions)
+	assert.Equal(t, "db/orders", line.Database)
+	assert.Equal(t, 3, line.Warnings)
+}
+
+type failingInstances struct{}
+
+func (failingInstances) DescribeInstance(context.Context, string) (*check.InstanceMetadata, error) {
+	return nil, errors.New("DescribeDBInstances: DBInstanceNotFound: DBInstance orders not found.")
+}
+
+func (failingInstances) HostMetrics(context.Context, string, time.Duration) (*check.HostMetrics, error) {
+	return nil, errors.New("GetMetricData: AccessDenied")
+}
+
+func TestHandle_InvalidEvent(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		event Event
+		msg   string
+	}{
+		{name: "no target", event: Event{}, msg: "dsn or secret_arn"},
+		{name: "unknown check", event: Event{DSN: "postgres://h/d", Only: []string{"nope"}}, msg: "nope"},
+		{name: "bad runtime class", event: Event{DSN: "postgres://h/d", MaxRuntimeClass: "slow"}, msg: "max_runtime_class"},
+		{name: "markdown output", event: Event{DSN: "postgres://h/d", Output: "s3://b/run.md"}, msg: ".json"},
+		{name: "unknown instance", event: Event{DSN: "postgres://h/d", AWSDBInstanceID: "orders"}, msg: "reading RDS instance orders: DescribeDBInstances: DBInstanceNotFound"},
+		{name: "negative rate limit", event: Event{DSN: "postgres://h/d", QueryRateLimit: -1}, msg: "query_rate_limit"},
+		{name: "metrics without instance", event: Event{DSN: "postgres://h/d", AWSMetrics: true}, msg: "aws_metrics requires aws_db_instance_id"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			h := &Handler{Secrets: staticSecrets{}, Instances: failingInstances{}, now: time.Now}
+			_, err := h.Handle(context.Background(), tt.event)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.msg)
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package lambda
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+const defaultMetricsNamespace = "pgdoctor"
+
+// writeMetrics writes the run summary as a CloudWatch Embedded Metric Format
+// log line. CloudWatch Logs extracts Failures and Warnings as metrics with a
+// Database dimension, so alarms need no extra infrastructure.
+// https://docs.aws.amazon.com/AmazonCloudWatch/latest/monitoring/CloudWatch_Embedded_Metric_Format_Specification.html
+func writeMetrics(w io.Writer, namespace string, resp *Response, at time.Time) {
+	if namespace == "" {
+		namespace = defaultMetricsNamespace
+	}
+
+	line := map[string]any{
+		"_aws": map[string]any{
+			"Timestamp": at.UnixMilli(),
+			"CloudWatchMetrics": []map[string]any{{
+				"Namespace":  namespace,
+				"Dimensions": [][]string{{"Database"}},
+				"Metrics": []map[string]string{
+					{"Name": "Failures", "Unit": "Count"},
+					{"Name": "Warnings", "Unit": "Count"},
+				},
+			}},
+		},
+		"Database": resp.Database,
+		"Failures": resp.Failures,
+		"Warnings": resp.Warnings,
+		"Severity": resp.Severity,
+	}
+	if resp.Location != "" {
+		line["Location"] = resp.Location
+	}
+
+	data, err := json.Marshal(line)
+	if err != nil {
+		return
+	}
+	fmt.Fprintln(w, string(data))
+}
@@ -0,0 +1,165 @@
+package lambda
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/fresha/pgdoctor/internal/awsauth"
+	"github.com/fresha/pgdoctor/internal/dsn"
+)
+
+// SecretsManager reads secrets with the GetSecretValue API. Credentials and
+// region default to the function's execution role and region; a secret ARN
+// names its own region, which takes precedence.
+type SecretsManager struct {
+	Region      string
+	Credentials *awsauth.Credentials
+	Endpoint    string // Defaults to https://secretsmanager.<region>.amazonaws.com
+	Client      *http.Client
+}
+
+func (s *SecretsManager) GetSecretString(ctx context.Context, secretID string) (string, error) {
+	region := s.Region
+	if r := arnRegion(secretID); r != "" {
+		region = r
+	}
+	if region == "" {
+		region = awsauth.Region()
+	}
+	if region == "" {
+		return "", fmt.Errorf("AWS_REGION must be set to read secrets by name")
+	}
+
+	var creds awsauth.Credentials
+	if s.Credentials != nil {
+		creds = *s.Credentials
+	} else {
+		var err error
+		if creds, err = awsauth.FromEnv(); err != nil {
+			return "", err
+		}
+	}
+
+	endpoint := s.Endpoint
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://secretsmanager.%s.amazonaws.com", region)
+	}
+
+	body, err := json.Marshal(map[string]string{"SecretId": secretID})
+	if err != nil {
+		return "", err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimRight(endpoint, "/")+"/", bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "secretsmanager.GetSecretValue")
+	awsauth.Sign(req, body, creds, "secretsmanager", region, time.Now())
+
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return "", fmt.Errorf("GetSecretValue: %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+
+	var out struct {
+		SecretString string `json:"SecretString"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return "", fmt.Errorf("decoding GetSecretValue response: %w", err)
+	}
+	if out.SecretString == "" {
+		return "", fmt.Errorf("secret has no string value")
+	}
+	return out.SecretString, nil
+}
+
+// arnRegion returns the region of a Secrets Manager ARN, or "" for names.
+func arnRegion(secretID string) string {
+	parts := strings.SplitN(secretID, ":", 5)
+	if len(parts) < 5 || parts[0] != "arn" {
+		return ""
+	}
+	return parts[3]
+}
+
+// rdsSecret is the JSON layout RDS and Secrets Manager rotation use for
+// database credentials. RDS-managed master passwords hold only the username
+// and password.
+type rdsSecret struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+	Host     string `json:"host"`
+	Port     any    `json:"port"` // Number or string, depending on who wrote it
+	DBName   string `json:"dbname"`
+}
+
+// applySecret parses connString and overrides it with the fields present in
+// secret. A secret that is not JSON is used as the DSN itself.
+func applySecret(connString, secret string) (*pgx.ConnConfig, error) {
+	secret = strings.TrimSpace(secret)
+	if secret != "" && !strings.HasPrefix(secret, "{") {
+		if connString != "" {
+			return nil, fmt.Errorf("secret holds a connection string; pass either dsn or secret_arn, not both")
+		}
+		connString = secret
+		secret = ""
+	}
+
+	cfg, err := pgx.ParseConfig(connString)
+	if err != nil {
+		return nil, fmt.Errorf("invalid connection string: %s", dsn.Scrub(err.Error()))
+	}
+	if secret == "" {
+		return cfg, nil
+	}
+
+	var s rdsSecret
+	if err := json.Unmarshal([]byte(secret), &s); err != nil {
+		return nil, fmt.Errorf("decoding secret: %w", err)
+	}
+	if s.Username != "" {
+		cfg.User = s.Username
+	}
+	if s.Password != "" {
+		cfg.Password = s.Password
+	}
+	if s.Host != "" {
+		cfg.Host = s.Host
+	}
+	if s.DBName != "" {
+		cfg.Database = s.DBName
+	}
+	switch p := s.Port.(type) {
+	case float64:
+		cfg.Port = uint16(p)
+	case string:
+		if p != "" {
+			port, err := strconv.ParseUint(p, 10, 16)
+			if err != nil {
+				return nil, fmt.Errorf("invalid port %q in secret", p)
+			}
+			cfg.Port = uint16(port)
+		}
+	}
+	return cfg, nil
+}
@@ -0,0 +1,44 @@
+package pgdoctor
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fresha/pgdoctor/check"
+)
+
+// applySeverityOverrides sets the severity of every WARN or FAIL finding
+// matched by overrides, so an organisation can decide how much a finding
+// matters to it. Keys are "check-id/finding-id" or a bare check ID; the
+// finding key takes precedence. Passing and skipped findings are left alone.
+func applySeverityOverrides(report *check.Report, overrides map[string]check.Severity) {
+	changed := false
+	for i := range report.Results {
+		f := &report.Results[i]
+		if f.Severity < check.SeverityWarn {
+			continue
+		}
+		severity, ok := overrides[report.CheckID+"/"+f.ID]
+		if !ok {
+			severity, ok = overrides[report.CheckID]
+		}
+		if !ok || severity == f.Severity {
+			continue
+		}
+
+		f.Details = strings.TrimSpace(f.Details + fmt.Sprintf("\nSeverity set to %s by policy (was %s)",
+			strings.ToUpper(severity.String()), strings.ToUpper(f.Severity.String())))
+		f.Severity = severity
+		if severity == check.SeverityOK {
+			f.Fixes = nil
+		}
+		changed = true
+	}
+
+	if changed {
+		report.Severity = check.SeverityOK
+		for _, f := range report.Results {
+			report.Severity = max(report.Severity, f.Severity)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package pgdoctor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/fresha/pgdoctor/check"
+)
+
+func TestApplySeverityOverrides(t *testing.T) {
+	t.Parallel()
+
+	newReport := func() *check.Report {
+		report := check.NewReport(check.Metadata{CheckID: "index-bloat"})
+		report.AddFinding(check.Finding{ID: "bloated-indexes", Severity: check.SeverityFail, Details: "3 bloated", Fixes: []check.Fix{{SQL: "REINDEX INDEX CONCURRENTLY i"}}})
+		report.AddFinding(check.Finding{ID: "btree-dedup", Severity: check.SeverityWarn})
+		report.AddFinding(check.Finding{ID: "ok-finding", Severity: check.SeverityOK})
+		return report
+	}
+
+	tests := []struct {
+		name      string
+		overrides map[string]check.Severity
+		expected  []check.Severity
+		report    check.Severity
+	}{
+		{
+			name:      "finding lowered",
+			overrides: map[string]check.Severity{"index-bloat/bloated-indexes": check.SeverityWarn},
+			expected:  []check.Severity{check.SeverityWarn, check.SeverityWarn, check.SeverityOK},
+			report:    check.SeverityWarn,
+		},
+		{
+			name:      "check raised, finding key wins",
+			overrides: map[string]check.Severity{"index-bloat": check.SeverityFail, "index-bloat/bloated-indexes": check.SeverityOK},
+			expected:  []check.Severity{check.SeverityOK, check.SeverityFail, check.SeverityOK},
+			report:    check.SeverityFail,
+		},
+		{
+			name:      "other checks untouched",
+			overrides: map[string]check.Severity{"table-bloat": check.SeverityOK},
+			expected:  []check.Severity{check.SeverityFail, check.SeverityWarn, check.SeverityOK},
+			report:    check.SeverityFail,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			report := newReport()
+			applySeverityOverrides(report, tt.overrides)
+
+			var got []check.Severity
+			for _, f := range report.Results {
+				got = append(got, f.Severity)
+			}
+			assert.Equal(t, tt.expected, got)
+			assert.Equal(t, tt.report, report.Severity)
+		})
+	}
+}
+
+func TestApplySeverityOverrides_Details(t *testing.T) {
+	t.Parallel()
+
+	report := check.NewReport(check.Metadata{CheckID: "index-bloat"})
+	report.AddFinding(check.Finding{ID: "bloated-indexes", Severity: check.SeverityFail, Details: "3 bloated", Fixes: []check.Fix{{SQL: "REINDEX INDEX CONCURRENTLY i"}}})
+	applySeverityOverrides(report, map[string]check.Severity{"index-bloat": check.SeverityOK})
+
+	f := report.Results[0]
+	assert.Equal(t, "3 bloated\nSeverity set to PASS by policy (was FAIL)", f.Details)
+	assert.Empty(t, f.Fixes, "a finding passed by policy has nothing to fix")
+}
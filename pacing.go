@@ -0,0 +1,96 @@
+package pgdoctor
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/fresha/pgdoctor/db"
+)
+
+// LowImpactSettings are session settings that keep pgdoctor's own queries
+// cheap for a server that is already struggling: small sort and hash
+// memory, no parallel workers or JIT compilation, and cost-based delay for
+// any VACUUM or ANALYZE the session runs.
+var LowImpactSettings = []string{
+	"SET work_mem = '4MB'",
+	"SET max_parallel_workers_per_gather = 0",
+	"SET jit = off",
+	"SET vacuum_cost_delay = '2ms'",
+}
+
+// ApplyLowImpactSettings applies LowImpactSettings to the session.
+func ApplyLowImpactSettings(ctx context.Context, conn db.DBTX) error {
+	for _, stmt := range LowImpactSettings {
+		if _, err := conn.Exec(ctx, stmt); err != nil {
+			return fmt.Errorf("applying %q: %w", stmt, err)
+		}
+	}
+	return nil
+}
+
+// pacedConn starts at most one statement per interval, so a run spreads its
+// queries out instead of issuing them back to back. Only starts are paced:
+// the interval counts from when a statement is sent, not from when it
+// finishes or its rows are closed, so a slow query is followed by the next
+// one as soon as its interval has passed.
+type pacedConn struct {
+	conn     db.DBTX
+	interval time.Duration
+
+	mu   sync.Mutex
+	next time.Time
+}
+
+// newPacedConn limits conn to rate statements per second.
+func newPacedConn(conn db.DBTX, rate float64) *pacedConn {
+	return &pacedConn{conn: conn, interval: time.Duration(float64(time.Second) / rate)}
+}
+
+// wait blocks until the next statement may start, or ctx is done.
+func (c *pacedConn) wait(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if d := time.Until(c.next); d > 0 {
+		t := time.NewTimer(d)
+		defer t.Stop()
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	c.next = time.Now().Add(c.interval)
+	return nil
+}
+
+func (c *pacedConn) Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error) {
+	if err := c.wait(ctx); err != nil {
+		return pgconn.CommandTag{}, err
+	}
+	return c.conn.Exec(ctx, sql, args...)
+}
+
+func (c *pacedConn) Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error) {
+	if err := c.wait(ctx); err != nil {
+		return nil, err
+	}
+	return c.conn.Query(ctx, sql, args...)
+}
+
+func (c *pacedConn) QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row {
+	if err := c.wait(ctx); err != nil {
+		return errRow{err}
+	}
+	return c.conn.QueryRow(ctx, sql, args...)
+}
+
+// errRow is a pgx.Row whose Scan fails with err.
+type errRow struct{ err error }
+
+func (r errRow) Scan(...any) error { return r.err }
@@ -0,0 +1,71 @@
+package pgdoctor
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingConn records the statements run on it and when they started.
+type recordingConn struct {
+	stmts []string
+	times []time.Time
+}
+
+func (c *recordingConn) Exec(_ context.Context, sql string, _ ...interface{}) (pgconn.CommandTag, error) {
+	c.stmts = append(c.stmts, sql)
+	c.times = append(c.times, time.Now())
+	return pgconn.CommandTag{}, nil
+}
+
+func (c *recordingConn) Query(context.Context, string, ...interface{}) (pgx.Rows, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (c *recordingConn) QueryRow(context.Context, string, ...interface{}) pgx.Row {
+	return errRow{errors.New("not implemented")}
+}
+
+func TestApplyLowImpactSettings(t *testing.T) {
+	t.Parallel()
+
+	conn := &recordingConn{}
+	require.NoError(t, ApplyLowImpactSettings(context.Background(), conn))
+	assert.Equal(t, LowImpactSettings, conn.stmts)
+}
+
+func TestPacedConn(t *testing.T) {
+	t.Parallel()
+
+	conn := &recordingConn{}
+	paced := newPacedConn(conn, 50) // 20ms apart
+	for range 3 {
+		_, err := paced.Exec(context.Background(), "SELECT 1")
+		require.NoError(t, err)
+	}
+
+	require.Len(t, conn.times, 3)
+	assert.GreaterOrEqual(t, conn.times[1].Sub(conn.times[0]), 20*time.Millisecond)
+	assert.GreaterOrEqual(t, conn.times[2].Sub(conn.times[1]), 20*time.Millisecond)
+}
+
+func TestPacedConn_Cancelled(t *testing.T) {
+	t.Parallel()
+
+	conn := &recordingConn{}
+	paced := newPacedConn(conn, 0.1) // 10s apart
+	_, err := paced.Exec(context.Background(), "SELECT 1")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err = paced.QueryRow(ctx, "SELECT 2").Scan()
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Len(t, conn.stmts, 1, "the waiting statement never ran")
+}
@@ -5,6 +5,8 @@ package pgdoctor
 import (
 	"context"
 	"errors"
+	"fmt"
+	"sort"
 	"strings"
 	"time"
 
@@ -30,6 +32,32 @@ type Options struct {
 	Checks   []check.Package
 	Config   check.Config
 	OnReport ReportHandler
+	// Previous holds the severities of the last run's findings, keyed by
+	// "check-id/finding-id". When set, findings with a clear_* threshold in
+	// Config keep their previous severity until the metric drops below it.
+	Previous map[string]check.Severity
+	// SeverityOverrides sets the severity of WARN and FAIL findings after
+	// hysteresis, keyed by "check-id/finding-id" or check ID, so a policy
+	// can raise or lower how much a finding matters.
+	SeverityOverrides map[string]check.Severity
+	// Baseline, when set, lowers findings accepted in it to OK after
+	// hysteresis, so only regressions are reported.
+	Baseline *Baseline
+	// IgnoreObjects, when set, makes checks leave the schemas, tables,
+	// indexes, and sequences it matches out of their analysis.
+	IgnoreObjects *check.ObjectFilter
+	// Schema, when set, limits the checks of tables, indexes, sequences,
+	// and other schema objects to this schema, so a schema-per-tenant
+	// database can be reported on one tenant at a time. Checks of the
+	// server and its connections still cover everything.
+	Schema string
+	// QueryRateLimit, when positive, is the most statements per second the
+	// checks may start, spreading a run's load out over time. Statements
+	// always run one at a time on the connection.
+	QueryRateLimit float64
+	// Instance, when set, describes the server's hardware and configuration
+	// so checks can size their recommendations for it.
+	Instance *check.InstanceMetadata
 }
 
 // Run executes checks sequentially against the given connection.
@@ -42,9 +70,33 @@ func Run(ctx context.Context, conn db.DBTX, opts Options) {
 		onReport = func(*check.Report) {}
 	}
 
+	if opts.QueryRateLimit > 0 {
+		conn = newPacedConn(conn, opts.QueryRateLimit)
+	}
+
+	ignore := opts.IgnoreObjects
+	if opts.Schema != "" {
+		ignore = ignore.ScopedToSchema(opts.Schema)
+	}
+	if ignore != nil {
+		ctx = check.ContextWithObjectFilter(ctx, ignore)
+	}
+
+	if opts.Instance != nil {
+		ctx = check.ContextWithInstanceMetadata(ctx, opts.Instance)
+	}
+
+	var observed []check.Observation
+	if len(opts.Previous) > 0 {
+		ctx = check.ContextWithObserver(ctx, func(o check.Observation) {
+			observed = append(observed, o)
+		})
+	}
+
 	for _, pkg := range opts.Checks {
 		checker := pkg.New(conn, opts.Config)
 
+		observed = observed[:0]
 		start := time.Now()
 		report, err := checker.Check(ctx)
 		elapsed := time.Since(start)
@@ -65,6 +117,14 @@ func Run(ctx context.Context, conn db.DBTX, opts Options) {
 				Severity: check.SeveritySkip,
 				Details:  detail,
 			})
+		} else {
+			applyHysteresis(report, observed, opts.Config, opts.Previous)
+			if len(opts.SeverityOverrides) > 0 {
+				applySeverityOverrides(report, opts.SeverityOverrides)
+			}
+			if opts.Baseline != nil {
+				opts.Baseline.Apply(report)
+			}
 		}
 
 		report.Duration = elapsed
@@ -72,6 +132,19 @@ func Run(ctx context.Context, conn db.DBTX, opts Options) {
 	}
 }
 
+// ValidateSchema returns an error when schema does not exist, so a typo in
+// Options.Schema does not pass as a tenant with nothing wrong.
+func ValidateSchema(ctx context.Context, conn db.DBTX, schema string) error {
+	var exists bool
+	if err := conn.QueryRow(ctx, "SELECT EXISTS (SELECT 1 FROM pg_namespace WHERE nspname = $1)", schema).Scan(&exists); err != nil {
+		return fmt.Errorf("looking up schema %s: %w", schema, err)
+	}
+	if !exists {
+		return fmt.Errorf("schema %q does not exist", schema)
+	}
+	return nil
+}
+
 // Filter returns checks matching the only/ignored filters.
 // If only is non-empty, only checks matching those check IDs or categories are included.
 // Checks matching ignored check IDs or categories are excluded.
@@ -109,6 +182,53 @@ func Filter(checks []check.Package, only, ignored []string) []check.Package {
 	return filtered
 }
 
+// FilterByRuntimeClass returns checks whose estimated runtime class does not exceed max.
+// Checks not marked as production-safe are excluded whenever max is below RuntimeHeavy,
+// so a capped run only executes checks that are known to be cheap and safe.
+func FilterByRuntimeClass(checks []check.Package, maxClass check.RuntimeClass) []check.Package {
+	if maxClass >= check.RuntimeHeavy {
+		return checks
+	}
+
+	var filtered []check.Package
+	for _, pkg := range checks {
+		metadata := pkg.Metadata()
+		if metadata.RuntimeClass > maxClass || !metadata.ProductionSafe {
+			continue
+		}
+		filtered = append(filtered, pkg)
+	}
+	return filtered
+}
+
+// SortByPriority orders checks for execution: critical checks first, then
+// normal, then deferred, and by category within each priority so streamed
+// output stays grouped. Heavy checks left at normal priority count as
+// deferred. overrides maps check IDs or categories to a priority; a check ID
+// takes precedence over its category.
+func SortByPriority(checks []check.Package, overrides map[string]check.Priority) {
+	priority := func(m check.Metadata) check.Priority {
+		if p, ok := overrides[m.CheckID]; ok {
+			return p
+		}
+		if p, ok := overrides[string(m.Category)]; ok {
+			return p
+		}
+		if m.Priority == check.PriorityNormal && m.RuntimeClass == check.RuntimeHeavy {
+			return check.PriorityDeferred
+		}
+		return m.Priority
+	}
+
+	sort.SliceStable(checks, func(i, j int) bool {
+		mi, mj := checks[i].Metadata(), checks[j].Metadata()
+		if pi, pj := priority(mi), priority(mj); pi != pj {
+			return pi > pj
+		}
+		return mi.Category < mj.Category
+	})
+}
+
 func toSet(items []string) map[string]struct{} {
 	m := make(map[string]struct{}, len(items))
 	for _, item := range items {
@@ -172,29 +292,63 @@ func ValidateFilters(checks []check.Package, filters []string) (valid, invalid [
 	return valid, invalid
 }
 
-// AllFilters returns all valid filter values (check IDs and categories).
+// AllFilters returns all valid filter values: every check ID in order,
+// followed by every category.
 func AllFilters() []string {
-	checks := AllChecks()
+	metadata := AllMetadata()
+	filters := make([]string, 0, len(metadata))
+	for _, m := range metadata {
+		filters = append(filters, m.CheckID)
+	}
+	sort.Strings(filters)
+	for _, cat := range Categories() {
+		filters = append(filters, string(cat))
+	}
+	return filters
+}
 
-	seen := map[string]struct{}{}
-	var filters []string
+// SuggestFilter returns the check ID or category closest to an invalid
+// filter, for "did you mean" hints, or "" when nothing is close enough to be
+// a plausible typo.
+func SuggestFilter(checks []check.Package, filter string) string {
+	filter, _, _ = strings.Cut(filter, "/")
 
+	best, bestDistance := "", len(filter)/3+1
+	seen := map[string]struct{}{}
 	for _, pkg := range checks {
 		metadata := pkg.Metadata()
-
-		if _, ok := seen[metadata.CheckID]; !ok {
-			filters = append(filters, metadata.CheckID)
-			seen[metadata.CheckID] = struct{}{}
+		for _, candidate := range []string{metadata.CheckID, string(metadata.Category)} {
+			if _, ok := seen[candidate]; ok {
+				continue
+			}
+			seen[candidate] = struct{}{}
+			if d := editDistance(filter, candidate); d < bestDistance || (d == bestDistance && best != "" && candidate < best) {
+				best, bestDistance = candidate, d
+			}
 		}
+	}
+	return best
+}
 
-		category := string(metadata.Category)
-		if _, ok := seen[category]; !ok {
-			filters = append(filters, category)
-			seen[category] = struct{}{}
+// editDistance is the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
 		}
+		prev, curr = curr, prev
 	}
-
-	return filters
+	return prev[len(b)]
 }
 
 // isStatementTimeout checks if the error is a PostgreSQL statement_timeout (SQLSTATE 57014).
@@ -158,3 +158,277 @@ func TestRun_ContinuesAfterCheckError(t *testing.T) {
 	assert.Equal(t, check.SeverityOK, reports[1].Severity)
 	assert.Equal(t, "good-check", reports[1].CheckID)
 }
+
+// contextChecker records the context its check ran with.
+type contextChecker struct {
+	fakeChecker
+	ctx context.Context
+}
+
+func (c *contextChecker) Check(ctx context.Context) (*check.Report, error) {
+	c.ctx = ctx
+	return check.NewReport(c.metadata), nil
+}
+
+func TestRun_IgnoreObjects(t *testing.T) {
+	t.Parallel()
+
+	ignore, err := check.IgnoreRules{Tables: []string{"legacy_*"}}.Compile()
+	require.NoError(t, err)
+
+	checker := &contextChecker{fakeChecker: fakeChecker{metadata: check.Metadata{CheckID: "table-bloat"}}}
+	Run(context.Background(), nil, Options{
+		Checks: []check.Package{{
+			Metadata: func() check.Metadata { return checker.metadata },
+			New:      func(db.DBTX, check.Config) check.Checker { return checker },
+		}},
+		IgnoreObjects: ignore,
+	})
+
+	require.NotNil(t, checker.ctx)
+	assert.True(t, check.ObjectFilterFromContext(checker.ctx).Table("public", "legacy_orders"))
+}
+
+func TestRun_Instance(t *testing.T) {
+	t.Parallel()
+
+	checker := &contextChecker{fakeChecker: fakeChecker{metadata: check.Metadata{CheckID: "vacuum-settings"}}}
+	Run(context.Background(), nil, Options{
+		Checks: []check.Package{{
+			Metadata: func() check.Metadata { return checker.metadata },
+			New:      func(db.DBTX, check.Config) check.Checker { return checker },
+		}},
+		Instance: &check.InstanceMetadata{InstanceClass: "db.r6g.xlarge", MemoryGB: 32},
+	})
+
+	require.NotNil(t, checker.ctx)
+	meta := check.InstanceMetadataFromContext(checker.ctx)
+	require.NotNil(t, meta)
+	assert.Equal(t, "db.r6g.xlarge", meta.InstanceClass)
+}
+
+func TestRun_Schema(t *testing.T) {
+	t.Parallel()
+
+	ignore, err := check.IgnoreRules{Tables: []string{"tmp_*"}}.Compile()
+	require.NoError(t, err)
+
+	checker := &contextChecker{fakeChecker: fakeChecker{metadata: check.Metadata{CheckID: "table-bloat"}}}
+	Run(context.Background(), nil, Options{
+		Checks: []check.Package{{
+			Metadata: func() check.Metadata { return checker.metadata },
+			New:      func(db.DBTX, check.Config) check.Checker { return checker },
+		}},
+		IgnoreObjects: ignore,
+		Schema:        "tenant_42",
+	})
+
+	require.NotNil(t, checker.ctx)
+	f := check.ObjectFilterFromContext(checker.ctx)
+	assert.Equal(t, "tenant_42", f.Scope())
+	assert.True(t, f.Table("tenant_7", "orders"))
+	assert.True(t, f.Table("tenant_42", "tmp_import"))
+	assert.False(t, f.Table("tenant_42", "orders"))
+}
+
+func TestFilterByRuntimeClass(t *testing.T) {
+	t.Parallel()
+
+	pkg := func(id string, class check.RuntimeClass, safe bool) check.Package {
+		meta := check.Metadata{CheckID: id, RuntimeClass: class, ProductionSafe: safe}
+		return check.Package{Metadata: func() check.Metadata { return meta }}
+	}
+
+	checks := []check.Package{
+		pkg("fast-check", check.RuntimeFast, true),
+		pkg("medium-check", check.RuntimeMedium, true),
+		pkg("heavy-check", check.RuntimeHeavy, true),
+		pkg("unsafe-check", check.RuntimeFast, false),
+	}
+
+	ids := func(pkgs []check.Package) []string {
+		var out []string
+		for _, p := range pkgs {
+			out = append(out, p.Metadata().CheckID)
+		}
+		return out
+	}
+
+	tests := []struct {
+		name     string
+		maxClass check.RuntimeClass
+		expected []string
+	}{
+		{
+			name:     "heavy keeps everything",
+			maxClass: check.RuntimeHeavy,
+			expected: []string{"fast-check", "medium-check", "heavy-check", "unsafe-check"},
+		},
+		{
+			name:     "medium drops heavy and unsafe checks",
+			maxClass: check.RuntimeMedium,
+			expected: []string{"fast-check", "medium-check"},
+		},
+		{
+			name:     "fast keeps only fast safe checks",
+			maxClass: check.RuntimeFast,
+			expected: []string{"fast-check"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.expected, ids(FilterByRuntimeClass(checks, tt.maxClass)))
+		})
+	}
+}
+
+func TestSortByPriority(t *testing.T) {
+	t.Parallel()
+
+	pkg := func(id string, category check.Category, class check.RuntimeClass, priority check.Priority) check.Package {
+		meta := check.Metadata{CheckID: id, Category: category, RuntimeClass: class, Priority: priority}
+		return check.Package{Metadata: func() check.Metadata { return meta }}
+	}
+
+	newChecks := func() []check.Package {
+		return []check.Package{
+			pkg("pk-types", check.CategorySchema, check.RuntimeHeavy, check.PriorityNormal),
+			pkg("index-usage", check.CategoryIndexes, check.RuntimeMedium, check.PriorityNormal),
+			pkg("uuid-types", check.CategorySchema, check.RuntimeMedium, check.PriorityNormal),
+			pkg("freeze-age", check.CategoryVacuum, check.RuntimeMedium, check.PriorityCritical),
+			pkg("pg-version", check.CategoryConfigs, check.RuntimeFast, check.PriorityNormal),
+			pkg("connection-health", check.CategoryConfigs, check.RuntimeFast, check.PriorityCritical),
+		}
+	}
+
+	ids := func(pkgs []check.Package) []string {
+		var out []string
+		for _, p := range pkgs {
+			out = append(out, p.Metadata().CheckID)
+		}
+		return out
+	}
+
+	tests := []struct {
+		name      string
+		overrides map[string]check.Priority
+		expected  []string
+	}{
+		{
+			name:     "critical first, heavy last, category order within",
+			expected: []string{"connection-health", "freeze-age", "pg-version", "index-usage", "uuid-types", "pk-types"},
+		},
+		{
+			name: "check ID override beats category",
+			overrides: map[string]check.Priority{
+				string(check.CategoryConfigs): check.PriorityDeferred,
+				"pk-types":                    check.PriorityCritical,
+			},
+			expected: []string{"pk-types", "freeze-age", "index-usage", "uuid-types", "pg-version", "connection-health"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			checks := newChecks()
+			SortByPriority(checks, tt.overrides)
+			assert.Equal(t, tt.expected, ids(checks))
+		})
+	}
+}
+
+func TestAllChecks_RuntimeMetadata(t *testing.T) {
+	t.Parallel()
+
+	// RuntimeFast is the zero value, so a check that never sets RuntimeClass
+	// looks fast. Listing every check makes its class a deliberate choice.
+	expected := map[check.RuntimeClass][]string{
+		check.RuntimeFast: {
+			"advisory-locks", "cache-efficiency", "checkpoint-health", "connection-efficiency",
+			"connection-health", "encoding-locale", "event-triggers", "failover-readiness",
+			"invalid-indexes", "lock-contention", "pg-version", "prepared-xacts", "replication-lag",
+			"replication-slots", "security-settings", "session-settings", "standby-recovery",
+			"statistics-freshness", "temp-usage", "timezone", "tls-certs", "txn-rates", "vacuum-settings",
+			"wal-compression", "wal-size",
+		},
+		check.RuntimeMedium: {
+			"access-methods", "buffer-cache", "correlation", "durability", "freeze-age", "index-usage",
+			"orphaned-temp", "partial-indexes", "partitioning", "query-patterns", "queue-tables",
+			"sequence-health", "table-activity", "table-bloat", "table-seq-scans", "table-vacuum-health",
+			"tablespace-placement", "uuid-defaults", "uuid-types",
+		},
+		check.RuntimeHeavy: {
+			"duplicate-indexes", "index-bloat", "partition-usage", "pk-types", "toast-storage",
+		},
+	}
+	// Checks whose cost is not bounded by catalog size or statement_timeout.
+	// Capped runs skip them, so an empty list would mean the flag is unused.
+	unsafe := []string{"buffer-cache"}
+
+	classes := map[string]check.RuntimeClass{}
+	for class, ids := range expected {
+		for _, id := range ids {
+			classes[id] = class
+		}
+	}
+
+	var gotUnsafe []string
+	for _, pkg := range AllChecks() {
+		meta := pkg.Metadata()
+		if !meta.ProductionSafe {
+			gotUnsafe = append(gotUnsafe, meta.CheckID)
+		}
+		class, ok := classes[meta.CheckID]
+		if !assert.True(t, ok, "%s is not listed; add it under its runtime class", meta.CheckID) {
+			continue
+		}
+		assert.Equal(t, class, meta.RuntimeClass, "%s runtime class", meta.CheckID)
+		delete(classes, meta.CheckID)
+	}
+	assert.Empty(t, classes, "listed checks that do not exist")
+	assert.NotEmpty(t, gotUnsafe)
+	assert.ElementsMatch(t, unsafe, gotUnsafe, "checks not marked production-safe")
+}
+
+func TestAllChecks_FindingsMetadata(t *testing.T) {
+	t.Parallel()
+
+	for _, pkg := range AllChecks() {
+		meta := pkg.Metadata()
+		require.NotEmpty(t, meta.Findings, "%s declares no findings", meta.CheckID)
+
+		seen := map[string]bool{}
+		for _, f := range meta.Findings {
+			assert.NotEmpty(t, f.ID, "%s has a finding without an ID", meta.CheckID)
+			assert.NotEmpty(t, f.Description, "%s/%s has no description", meta.CheckID, f.ID)
+			assert.False(t, seen[f.ID], "%s declares %s twice", meta.CheckID, f.ID)
+			seen[f.ID] = true
+		}
+	}
+}
+
+func TestSuggestFilter(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		filter string
+		want   string
+	}{
+		{filter: "sequnce-health", want: "sequence-health"},
+		{filter: "freeze_age", want: "freeze-age"},
+		{filter: "vacum", want: "vacuum"},
+		{filter: "index-usage/unused-idx", want: "index-usage"},
+		{filter: "replication", want: ""},
+		{filter: "xyz", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.filter, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.want, SuggestFilter(AllChecks(), tt.filter))
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package pgdoctor
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/fresha/pgdoctor/check"
+)
+
+// CheckByID returns the built-in check with the given ID.
+func CheckByID(id string) (check.Package, bool) {
+	for _, pkg := range AllChecks() {
+		if pkg.Metadata().CheckID == id {
+			return pkg, true
+		}
+	}
+	return check.Package{}, false
+}
+
+// ChecksByCategory returns the built-in checks in category, ordered by check ID.
+func ChecksByCategory(cat check.Category) []check.Package {
+	var checks []check.Package
+	for _, pkg := range AllChecks() {
+		if pkg.Metadata().Category == cat {
+			checks = append(checks, pkg)
+		}
+	}
+	slices.SortFunc(checks, func(a, b check.Package) int {
+		return strings.Compare(a.Metadata().CheckID, b.Metadata().CheckID)
+	})
+	return checks
+}
+
+// Categories returns the distinct categories of the built-in checks, sorted.
+func Categories() []check.Category {
+	var categories []check.Category
+	for _, pkg := range AllChecks() {
+		categories = append(categories, pkg.Metadata().Category)
+	}
+	slices.Sort(categories)
+	return slices.Compact(categories)
+}
+
+// AllMetadata returns the metadata of every built-in check, ordered by
+// category and then check ID, the order list and the docs present them in.
+func AllMetadata() []check.Metadata {
+	checks := AllChecks()
+	metadata := make([]check.Metadata, 0, len(checks))
+	for _, pkg := range checks {
+		metadata = append(metadata, pkg.Metadata())
+	}
+	slices.SortFunc(metadata, func(a, b check.Metadata) int {
+		return cmp.Or(cmp.Compare(a.Category, b.Category), strings.Compare(a.CheckID, b.CheckID))
+	})
+	return metadata
+}
+
+// UnknownFilterError lists filters that match no check ID or category.
+type UnknownFilterError struct {
+	Filters []string
+	// Suggestions maps a filter to the closest valid one, when one is close
+	// enough to be a plausible typo.
+	Suggestions map[string]string
+}
+
+func (e *UnknownFilterError) Error() string {
+	problems := make([]string, 0, len(e.Filters))
+	for _, f := range e.Filters {
+		problem := fmt.Sprintf("%q", f)
+		if suggestion := e.Suggestions[f]; suggestion != "" {
+			problem += fmt.Sprintf(" (did you mean %q?)", suggestion)
+		}
+		problems = append(problems, problem)
+	}
+	return "unknown check or category " + strings.Join(problems, ", ")
+}
+
+// ResolveFilters validates only and ignored against checks and normalizes
+// them as ValidateFilters does. Any filter that matches nothing makes the
+// whole call fail with an *UnknownFilterError, since silently running the
+// wrong set of checks is worse than not running at all.
+func ResolveFilters(checks []check.Package, only, ignored []string) (validOnly, validIgnored []string, err error) {
+	validOnly, invalidOnly := ValidateFilters(checks, only)
+	validIgnored, invalidIgnored := ValidateFilters(checks, ignored)
+
+	invalid := append(invalidOnly, invalidIgnored...)
+	if len(invalid) == 0 {
+		return validOnly, validIgnored, nil
+	}
+
+	unknown := &UnknownFilterError{Filters: invalid, Suggestions: map[string]string{}}
+	for _, f := range invalid {
+		if suggestion := SuggestFilter(checks, f); suggestion != "" {
+			unknown.Suggestions[f] = suggestion
+		}
+	}
+	return nil, nil, unknown
+}
@@ -0,0 +1,86 @@
+package pgdoctor
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/fresha/pgdoctor/check"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckByID(t *testing.T) {
+	t.Parallel()
+
+	pkg, ok := CheckByID("sequence-health")
+	require.True(t, ok)
+	assert.Equal(t, "sequence-health", pkg.Metadata().CheckID)
+
+	_, ok = CheckByID("vacuum")
+	assert.False(t, ok, "categories are not check IDs")
+}
+
+func TestChecksByCategory(t *testing.T) {
+	t.Parallel()
+
+	total := 0
+	for _, cat := range Categories() {
+		checks := ChecksByCategory(cat)
+		require.NotEmpty(t, checks, cat)
+		total += len(checks)
+
+		var ids []string
+		for _, pkg := range checks {
+			assert.Equal(t, cat, pkg.Metadata().Category)
+			ids = append(ids, pkg.Metadata().CheckID)
+		}
+		assert.IsNonDecreasing(t, ids, cat)
+	}
+	assert.Equal(t, len(AllChecks()), total, "every check belongs to a listed category")
+	assert.Empty(t, ChecksByCategory("replication"))
+}
+
+func TestCategories(t *testing.T) {
+	t.Parallel()
+
+	categories := Categories()
+	assert.IsIncreasing(t, categories, "sorted without duplicates")
+	assert.Contains(t, categories, check.CategoryVacuum)
+}
+
+func TestAllMetadata(t *testing.T) {
+	t.Parallel()
+
+	metadata := AllMetadata()
+	require.Len(t, metadata, len(AllChecks()))
+	for i := 1; i < len(metadata); i++ {
+		prev, cur := metadata[i-1], metadata[i]
+		assert.True(t, prev.Category < cur.Category || (prev.Category == cur.Category && prev.CheckID < cur.CheckID),
+			"%s/%s sorts before %s/%s", prev.Category, prev.CheckID, cur.Category, cur.CheckID)
+	}
+}
+
+func TestAllFilters(t *testing.T) {
+	t.Parallel()
+
+	filters := AllFilters()
+	valid, invalid := ValidateFilters(AllChecks(), filters)
+	assert.Empty(t, invalid)
+	assert.Equal(t, filters, valid, "no duplicates")
+	assert.Len(t, filters, len(AllChecks())+len(Categories()))
+}
+
+func TestResolveFilters(t *testing.T) {
+	t.Parallel()
+
+	only, ignored, err := ResolveFilters(AllChecks(), []string{"vacuum", "freeze-age/table-freeze-age"}, []string{"index-usage"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"vacuum", "freeze-age"}, only)
+	assert.Equal(t, []string{"index-usage"}, ignored)
+
+	_, _, err = ResolveFilters(AllChecks(), []string{"sequnce-health"}, []string{"not-a-check"})
+	var unknown *UnknownFilterError
+	require.True(t, errors.As(err, &unknown))
+	assert.Equal(t, []string{"sequnce-health", "not-a-check"}, unknown.Filters)
+	assert.Equal(t, `unknown check or category "sequnce-health" (did you mean "sequence-health"?), "not-a-check"`, err.Error())
+}
@@ -0,0 +1,169 @@
+package pgdoctor
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"strings"
+
+	"github.com/fresha/pgdoctor/check"
+)
+
+// sqlCheckMaxRows caps the rows an SQLCheck lists in its finding's table.
+const sqlCheckMaxRows = 50
+
+// SQLCheck is a check defined by a single query, for rules that are
+// specific to one organisation and do not belong in the built-in suite.
+// Every row the query returns is a problem: the check reports Severity with
+// the rows as its table, or OK when there are none.
+//
+// The query runs as a subquery, so it must be a single SELECT, VALUES, or
+// TABLE statement, inside a READ ONLY transaction that is rolled back, so
+// PostgreSQL rejects data-modifying CTEs, nextval, and other writes to the
+// database. Functions with effects outside the transaction still run:
+// pg_terminate_backend, pg_cancel_backend, and dblink_exec, for example.
+// Review the query as you would any statement run with the role's
+// privileges.
+type SQLCheck struct {
+	ID          string
+	Name        string
+	Category    check.Category
+	Description string
+	SQL         string
+	// Severity is reported when the query returns rows: SeverityWarn or
+	// SeverityFail.
+	Severity check.Severity
+	// Details explains what the rows mean and what to do about them.
+	Details string
+	// DocsURL links to the rule's own documentation, if any.
+	DocsURL      string
+	RuntimeClass check.RuntimeClass
+	// ProductionSafe is set by the query's author to vouch that it is cheap
+	// and bounded by statement_timeout, as check.Metadata defines it. Checks
+	// that leave it unset are skipped by capped runs (--max-runtime-class).
+	ProductionSafe bool
+}
+
+// Package returns the check as a check.Package, to be run alongside the
+// built-in checks.
+func (s SQLCheck) Package() check.Package {
+	metadata := func() check.Metadata {
+		return check.Metadata{
+			CheckID:        s.ID,
+			Name:           s.Name,
+			Category:       s.Category,
+			Description:    s.Description,
+			SQL:            s.SQL,
+			RuntimeClass:   s.RuntimeClass,
+			ProductionSafe: s.ProductionSafe,
+			Findings: []check.FindingSpec{
+				{ID: s.ID, Description: s.Description, Thresholds: strings.ToUpper(s.Severity.String()) + " any rows returned"},
+			},
+		}
+	}
+	return check.Package{
+		Metadata: metadata,
+		New: func(conn check.DBTX, _ check.Config) check.Checker {
+			return &sqlChecker{spec: s, conn: conn, metadata: metadata}
+		},
+	}
+}
+
+type sqlChecker struct {
+	spec     SQLCheck
+	conn     check.DBTX
+	metadata func() check.Metadata
+}
+
+func (c *sqlChecker) Metadata() check.Metadata {
+	return c.metadata()
+}
+
+func (c *sqlChecker) Check(ctx context.Context) (*check.Report, error) {
+	report := check.NewReport(c.metadata())
+
+	if _, err := c.conn.Exec(ctx, "BEGIN READ ONLY"); err != nil {
+		return nil, fmt.Errorf("running %s/%s: %w", report.Category, report.CheckID, err)
+	}
+	// The transaction only guards the query, so it ends even when ctx is
+	// cancelled, leaving the connection usable for the next check.
+	defer func() {
+		_, _ = c.conn.Exec(context.WithoutCancel(ctx), "ROLLBACK")
+	}()
+
+	query := strings.TrimRight(strings.TrimSpace(c.spec.SQL), ";")
+	rows, err := c.conn.Query(ctx, "SELECT * FROM ("+query+"\n) AS q")
+	if err != nil {
+		return nil, fmt.Errorf("running %s/%s: %w", report.Category, report.CheckID, err)
+	}
+	defer rows.Close()
+
+	var headers []string
+	for _, fd := range rows.FieldDescriptions() {
+		headers = append(headers, fd.Name)
+	}
+
+	var tableRows []check.TableRow
+	count := 0
+	for rows.Next() {
+		count++
+		if count > sqlCheckMaxRows {
+			continue
+		}
+		values, err := rows.Values()
+		if err != nil {
+			return nil, fmt.Errorf("running %s/%s: %w", report.Category, report.CheckID, err)
+		}
+		cells := make([]string, len(values))
+		for i, v := range values {
+			cells[i] = formatSQLValue(v)
+		}
+		tableRows = append(tableRows, check.TableRow{Cells: cells, Severity: c.spec.Severity})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("running %s/%s: %w", report.Category, report.CheckID, err)
+	}
+
+	if count == 0 {
+		report.AddFinding(check.Finding{
+			ID:       c.spec.ID,
+			Name:     c.spec.Name,
+			Severity: check.SeverityOK,
+			Details:  "The query returned no rows",
+			DocsURL:  c.spec.DocsURL,
+		})
+		return report, nil
+	}
+
+	details := fmt.Sprintf("The query returned %d row(s)", count)
+	if count > sqlCheckMaxRows {
+		details += fmt.Sprintf(", the first %d shown", sqlCheckMaxRows)
+	}
+	if c.spec.Details != "" {
+		details += ". " + c.spec.Details
+	}
+
+	report.AddFinding(check.Finding{
+		ID:       c.spec.ID,
+		Name:     c.spec.Name,
+		Severity: c.spec.Severity,
+		Details:  details,
+		DocsURL:  c.spec.DocsURL,
+		Table:    &check.Table{Headers: headers, Rows: tableRows},
+	})
+	return report, nil
+}
+
+// formatSQLValue renders a column value for a table cell. Types such as
+// numeric decode to pgtype structs, which render through driver.Valuer.
+func formatSQLValue(v any) string {
+	if valuer, ok := v.(driver.Valuer); ok {
+		if dv, err := valuer.Value(); err == nil {
+			v = dv
+		}
+	}
+	if v == nil {
+		return "NULL"
+	}
+	return fmt.Sprint(v)
+}
@@ -0,0 +1,133 @@
+package pgdoctor
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/fresha/pgdoctor/check"
+)
+
+// rowsConn answers every query with the same columns and rows, and records
+// the statement it was given.
+type rowsConn struct {
+	recordingConn
+	columns []string
+	rows    [][]any
+	err     error
+}
+
+func (c *rowsConn) Query(_ context.Context, sql string, _ ...interface{}) (pgx.Rows, error) {
+	c.stmts = append(c.stmts, sql)
+	if c.err != nil {
+		return nil, c.err
+	}
+	return &fakeRows{columns: c.columns, rows: c.rows, pos: -1}, nil
+}
+
+type fakeRows struct {
+	columns []string
+	rows    [][]any
+	pos     int
+}
+
+func (r *fakeRows) Close()                        {}
+func (r *fakeRows) Err() error                    { return nil }
+func (r *fakeRows) CommandTag() pgconn.CommandTag { return pgconn.CommandTag{} }
+func (r *fakeRows) Scan(...any) error             { return errors.New("not implemented") }
+func (r *fakeRows) RawValues() [][]byte           { return nil }
+func (r *fakeRows) Conn() *pgx.Conn               { return nil }
+func (r *fakeRows) Values() ([]any, error)        { return r.rows[r.pos], nil }
+
+func (r *fakeRows) FieldDescriptions() []pgconn.FieldDescription {
+	fds := make([]pgconn.FieldDescription, len(r.columns))
+	for i, name := range r.columns {
+		fds[i].Name = name
+	}
+	return fds
+}
+
+func (r *fakeRows) Next() bool {
+	r.pos++
+	return r.pos < len(r.rows)
+}
+
+var tablesWithoutOwnerTag = SQLCheck{
+	ID:          "acme-owner-tag",
+	Name:        "Owner Tag",
+	Category:    check.CategorySchema,
+	Description: "Tables without an owner comment",
+	SQL:         "SELECT relname, n_live_tup FROM pg_stat_user_tables WHERE obj_description(relid) IS NULL;",
+	Severity:    check.SeverityFail,
+	Details:     "Add COMMENT ON TABLE naming the owning team",
+	DocsURL:     "https://wiki.acme.example/db/owner-tag",
+}
+
+func TestSQLCheck(t *testing.T) {
+	t.Parallel()
+
+	conn := &rowsConn{
+		columns: []string{"relname", "n_live_tup"},
+		rows: [][]any{
+			{"orders", pgtype.Numeric{Int: big.NewInt(12), Valid: true}},
+			{"payments", nil},
+		},
+	}
+	pkg := tablesWithoutOwnerTag.Package()
+	assert.Equal(t, "acme-owner-tag", pkg.Metadata().CheckID)
+	assert.False(t, pkg.Metadata().ProductionSafe, "only the query's author can vouch for it")
+	assert.Equal(t, "FAIL any rows returned", pkg.Metadata().Findings[0].Thresholds)
+
+	report, err := pkg.New(conn, nil).Check(context.Background())
+	require.NoError(t, err)
+
+	require.Len(t, conn.stmts, 3)
+	assert.Equal(t, "BEGIN READ ONLY", conn.stmts[0])
+	assert.Equal(t, "SELECT * FROM (SELECT relname, n_live_tup FROM pg_stat_user_tables WHERE obj_description(relid) IS NULL\n) AS q", conn.stmts[1],
+		"the query runs as a subquery, without its semicolon")
+	assert.Equal(t, "ROLLBACK", conn.stmts[2])
+
+	assert.Equal(t, check.SeverityFail, report.Severity)
+	require.Len(t, report.Results, 1)
+	f := report.Results[0]
+	assert.Equal(t, "The query returned 2 row(s). Add COMMENT ON TABLE naming the owning team", f.Details)
+	assert.Equal(t, "https://wiki.acme.example/db/owner-tag", f.DocsURL)
+	require.NotNil(t, f.Table)
+	assert.Equal(t, []string{"relname", "n_live_tup"}, f.Table.Headers)
+	assert.Equal(t, []string{"orders", "12"}, f.Table.Rows[0].Cells)
+	assert.Equal(t, []string{"payments", "NULL"}, f.Table.Rows[1].Cells)
+}
+
+func TestSQLCheck_NoRows(t *testing.T) {
+	t.Parallel()
+
+	report, err := tablesWithoutOwnerTag.Package().New(&rowsConn{columns: []string{"relname"}}, nil).Check(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, check.SeverityOK, report.Severity)
+	assert.Equal(t, "The query returned no rows", report.Results[0].Details)
+}
+
+func TestSQLCheck_Error(t *testing.T) {
+	t.Parallel()
+
+	conn := &rowsConn{err: errors.New("permission denied")}
+	_, err := tablesWithoutOwnerTag.Package().New(conn, nil).Check(context.Background())
+	require.ErrorContains(t, err, "running schema/acme-owner-tag: permission denied")
+	assert.Equal(t, "ROLLBACK", conn.stmts[len(conn.stmts)-1], "the transaction ends when the query fails")
+}
+
+func TestSQLCheck_ProductionSafe(t *testing.T) {
+	t.Parallel()
+
+	spec := tablesWithoutOwnerTag
+	spec.ProductionSafe = true
+	assert.True(t, spec.Package().Metadata().ProductionSafe)
+	assert.Len(t, FilterByRuntimeClass([]check.Package{spec.Package(), tablesWithoutOwnerTag.Package()}, check.RuntimeMedium), 1)
+}